@@ -0,0 +1,164 @@
+// cli is the operator's command line for a cv-search deployment: the common
+// tasks that otherwise take curl against the API or SQL against the database.
+//
+// Usage:
+//
+//	go run ./cmd/cli <command> [flags]
+//
+// Commands:
+//
+//	upload DIR           Upload every CV in a directory (bulk upload, in batches)
+//	search QUERY         Run a hybrid search and print the ranked candidates
+//	embeddings           Queue embedding generation for nodes without one
+//	communities          Run community detection
+//	jobs [JOB_ID]        Show the CV processing queue, or one job
+//	reindex              Rebuild the BM25 search vectors of all candidates
+//
+// upload, search, embeddings, communities and jobs JOB_ID talk to a running
+// API (--api, default $API_URL, else http://localhost:8080; $API_KEY is sent
+// as X-API-Key). jobs and reindex read the database directly and need
+// DATABASE_URL.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"cv-search/internal/storage"
+)
+
+// apiClient calls the cv-search HTTP API.
+type apiClient struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+}
+
+var (
+	apiURL string
+	client *apiClient
+)
+
+func main() {
+	root := &cobra.Command{
+		Use:           "cli",
+		Short:         "Operate a cv-search deployment",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			if apiURL == "" {
+				apiURL = "http://localhost:8080"
+			}
+			client = &apiClient{
+				baseURL: strings.TrimRight(apiURL, "/"),
+				apiKey:  os.Getenv("API_KEY"),
+				// Bulk uploads parse every file during the request.
+				http: &http.Client{Timeout: 15 * time.Minute},
+			}
+		},
+	}
+	root.PersistentFlags().StringVar(&apiURL, "api", os.Getenv("API_URL"), "API base URL")
+
+	root.AddCommand(uploadCmd(), searchCmd(), embeddingsCmd(), communitiesCmd(), jobsCmd(), reindexCmd())
+
+	if err := root.Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+// apiError is a non-2xx answer of the API.
+type apiError struct {
+	method, path string
+	status       int
+	message      string
+	retryAfter   time.Duration // Retry-After of a 429
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s %s: HTTP %d: %s", e.method, e.path, e.status, e.message)
+}
+
+// do sends a request to the API and decodes a JSON response into out (if
+// non-nil). Any status outside 2xx is an *apiError carrying the message of
+// the API's error body.
+func (c *apiClient) do(method, path, contentType string, body io.Reader, out any) error {
+	req, err := http.NewRequest(method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg := strings.TrimSpace(string(data))
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error != "" {
+			msg = apiErr.Error
+		}
+		e := &apiError{method: method, path: path, status: resp.StatusCode, message: msg}
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			e.retryAfter = time.Duration(secs) * time.Second
+		}
+		return e
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("%s %s: decode response: %w", method, path, err)
+	}
+	return nil
+}
+
+func (c *apiClient) postJSON(path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	return c.do(http.MethodPost, path, "application/json", body, out)
+}
+
+// openDB connects to DATABASE_URL for the commands that work on the database
+// directly.
+func openDB() (*storage.DB, error) {
+	dsn := os.Getenv("DATABASE_URL")
+	if dsn == "" {
+		return nil, fmt.Errorf("DATABASE_URL is required")
+	}
+	return storage.NewDB(dsn)
+}
+
+// printJSON writes v indented, for --json output.
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func embeddingsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "embeddings",
+		Short: "Queue embedding generation for graph nodes without one",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var out struct {
+				Message       string `json:"message"`
+				PendingNodes  int    `json:"pending_nodes"`
+				EstimatedTime string `json:"estimated_time"`
+			}
+			if err := client.postJSON("/api/graphrag/embeddings/generate", nil, &out); err != nil {
+				return err
+			}
+			fmt.Println(out.Message)
+			if out.EstimatedTime != "" {
+				fmt.Printf("estimated time: %s (runs in the API's background worker)\n", out.EstimatedTime)
+			}
+			return nil
+		},
+	}
+}
+
+func communitiesCmd() *cobra.Command {
+	var level int
+	cmd := &cobra.Command{
+		Use:   "communities",
+		Short: "Run community detection",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var out struct {
+				ProcessingTime string `json:"processing_time"`
+				Stats          struct {
+					TotalCommunities int `json:"total_communities"`
+					TotalMembers     int `json:"total_members"`
+				} `json:"stats"`
+				Unchanged int `json:"unchanged"`
+				Changes   []struct {
+					Key           string   `json:"key"`
+					Change        string   `json:"change"`
+					Reasons       []string `json:"reasons"`
+					Title         string   `json:"title"`
+					PreviousTitle string   `json:"previous_title"`
+				} `json:"changes"`
+			}
+			path := "/api/graphrag/communities/detect?level=" + strconv.Itoa(level)
+			if err := client.postJSON(path, nil, &out); err != nil {
+				return err
+			}
+			fmt.Printf("level %d: %d communities, %d members, %d unchanged (%s)\n",
+				level, out.Stats.TotalCommunities, out.Stats.TotalMembers, out.Unchanged, out.ProcessingTime)
+			for _, c := range out.Changes {
+				title := c.Title
+				if title == "" {
+					title = c.PreviousTitle
+				}
+				fmt.Printf("  %-9s %s %q", c.Change, c.Key, title)
+				if len(c.Reasons) > 0 {
+					fmt.Printf(" (%s)", strings.Join(c.Reasons, ", "))
+				}
+				fmt.Println()
+			}
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&level, "level", 0, "hierarchy level")
+	return cmd
+}
+
+func jobsCmd() *cobra.Command {
+	var asJSON bool
+	cmd := &cobra.Command{
+		Use:   "jobs [JOB_ID]",
+		Short: "Show the CV processing queue, or one job",
+		Long: "Without an argument, counts cv_upload_jobs by status from the database\n" +
+			"(needs DATABASE_URL). With a job ID, shows that job through the API.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				id, err := strconv.ParseInt(args[0], 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid job ID %q", args[0])
+				}
+				var job map[string]any
+				if err := client.do(http.MethodGet, "/api/cv/job/"+strconv.FormatInt(id, 10), "", nil, &job); err != nil {
+					return err
+				}
+				return printJSON(job)
+			}
+
+			db, err := openDB()
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+			st, err := db.JobQueueStatus(context.Background())
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				return printJSON(st)
+			}
+			statuses := make([]string, 0, len(st.ByStatus))
+			for s := range st.ByStatus {
+				statuses = append(statuses, s)
+			}
+			sort.Strings(statuses)
+			for _, s := range statuses {
+				fmt.Printf("%-16s %d\n", s, st.ByStatus[s])
+			}
+			if st.OldestPending != nil {
+				fmt.Printf("oldest pending job queued %s ago\n", time.Since(*st.OldestPending).Round(time.Second))
+			}
+			fmt.Printf("open Groq batches: %d\n", st.OpenBatches)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print JSON")
+	return cmd
+}
+
+func reindexCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "reindex",
+		Short: "Rebuild the BM25 search vectors of all candidates",
+		Long: "Recomputes candidates.search_vector through its trigger, e.g. after a\n" +
+			"migration changed how it is built. Needs DATABASE_URL.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			db, err := openDB()
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+			start := time.Now()
+			n, err := db.ReindexSearchVectors(context.Background())
+			if err != nil {
+				return err
+			}
+			fmt.Printf("reindexed %d candidates in %s\n", n, time.Since(start).Round(time.Millisecond))
+			return nil
+		},
+	}
+}
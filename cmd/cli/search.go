@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+type searchRequest struct {
+	Query      string  `json:"query"`
+	FinalTopN  int     `json:"final_top_n,omitempty"`
+	Experiment string  `json:"experiment,omitempty"`
+	Diversity  float64 `json:"diversity,omitempty"`
+}
+
+type searchCandidate struct {
+	Rank            int     `json:"rank"`
+	ID              int     `json:"id"`
+	Name            string  `json:"name"`
+	CurrentPosition string  `json:"current_position"`
+	Seniority       string  `json:"seniority"`
+	FusionScore     float64 `json:"fusion_score"`
+	LLMScore        float64 `json:"llm_score"`
+	LLMReasoning    string  `json:"llm_reasoning"`
+}
+
+type searchResponse struct {
+	Query          string            `json:"query"`
+	Candidates     []searchCandidate `json:"candidates"`
+	TotalFound     int               `json:"total_found"`
+	ProcessingTime string            `json:"processing_time"`
+	Experiment     string            `json:"experiment"`
+	Warnings       []string          `json:"warnings"`
+}
+
+func searchCmd() *cobra.Command {
+	var req searchRequest
+	var limit int
+	var asJSON, why bool
+	cmd := &cobra.Command{
+		Use:   "search QUERY...",
+		Short: "Run a hybrid search",
+		Long:  "Runs POST /api/search/hybrid (BM25 + vector + graph + LLM rerank) and prints the ranking.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			req.Query = strings.Join(args, " ")
+			var out searchResponse
+			if asJSON {
+				var raw map[string]any
+				if err := client.postJSON("/api/search/hybrid", req, &raw); err != nil {
+					return err
+				}
+				return printJSON(raw)
+			}
+			if err := client.postJSON("/api/search/hybrid", req, &out); err != nil {
+				return err
+			}
+
+			for _, w := range out.Warnings {
+				fmt.Println("warning:", w)
+			}
+			if limit > 0 && len(out.Candidates) > limit {
+				out.Candidates = out.Candidates[:limit]
+			}
+			for _, c := range out.Candidates {
+				role := c.CurrentPosition
+				if c.Seniority != "" {
+					role = strings.TrimSpace(c.Seniority + " " + role)
+				}
+				fmt.Printf("%3d. %-30s %-40s llm=%.2f fusion=%.3f  (#%d)\n", c.Rank, c.Name, role, c.LLMScore, c.FusionScore, c.ID)
+				if why && c.LLMReasoning != "" {
+					fmt.Printf("     %s\n", c.LLMReasoning)
+				}
+			}
+			experiment := ""
+			if out.Experiment != "" {
+				experiment = ", experiment " + out.Experiment
+			}
+			fmt.Printf("%d found in %s%s\n", out.TotalFound, out.ProcessingTime, experiment)
+			return nil
+		},
+	}
+	cmd.Flags().IntVarP(&limit, "limit", "n", 10, "candidates to print (0 = all)")
+	cmd.Flags().IntVar(&req.FinalTopN, "rerank", 0, "candidates sent to the LLM reranker (0 = server default)")
+	cmd.Flags().StringVar(&req.Experiment, "experiment", "", "named search experiment")
+	cmd.Flags().Float64Var(&req.Diversity, "diversity", 0, "MMR lambda in (0,1) for a more diverse slate")
+	cmd.Flags().BoolVar(&why, "why", false, "print the LLM's reasoning per candidate")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print the raw API response")
+	return cmd
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// cvExtensions are the formats the upload endpoints accept.
+var cvExtensions = map[string]bool{
+	".pdf": true, ".docx": true, ".doc": true, ".odt": true, ".rtf": true, ".pages": true,
+	".txt": true, ".md": true, ".html": true, ".htm": true, ".eml": true, ".msg": true,
+}
+
+type uploadResult struct {
+	Filename string `json:"filename"`
+	Status   string `json:"status"`
+	JobID    int64  `json:"job_id"`
+	CVFileID int64  `json:"cv_file_id"`
+	Error    string `json:"error"`
+}
+
+type bulkUploadResponse struct {
+	BatchID string         `json:"batch_id"`
+	Total   int            `json:"total"`
+	Queued  int            `json:"queued"`
+	Skipped int            `json:"skipped"`
+	Results []uploadResult `json:"results"`
+}
+
+func uploadCmd() *cobra.Command {
+	var recursive, anonymize, dryRun bool
+	var batchSize, maxRetries int
+	cmd := &cobra.Command{
+		Use:   "upload DIR",
+		Short: "Upload every CV in a directory",
+		Long: "Uploads the CVs in DIR through POST /api/cv/bulk-upload, batchSize files per\n" +
+			"request (keep it at or below the server's MAX_BULK_FILE_COUNT). Files in\n" +
+			"formats the API doesn't accept are skipped. While the server's processing\n" +
+			"queue is busy (HTTP 429) a batch is retried after the Retry-After it sends.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if batchSize < 1 {
+				return fmt.Errorf("--batch must be at least 1")
+			}
+			paths, err := findCVs(args[0], recursive)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%d CV files in %s\n", len(paths), args[0])
+			if dryRun || len(paths) == 0 {
+				return nil
+			}
+
+			var queued, skipped, failed int
+			for start := 0; start < len(paths); start += batchSize {
+				batch := paths[start:min(start+batchSize, len(paths))]
+				out, err := uploadBatchRetrying(batch, anonymize, maxRetries)
+				if err != nil {
+					// One bad request shouldn't lose the rest of the directory.
+					fmt.Fprintf(os.Stderr, "files %d-%d: %v\n", start+1, start+len(batch), err)
+					failed += len(batch)
+					continue
+				}
+				queued += out.Queued
+				skipped += out.Skipped
+				for _, res := range out.Results {
+					if res.Error != "" {
+						fmt.Printf("  %-10s %s: %s\n", res.Status, res.Filename, res.Error)
+					}
+				}
+				fmt.Printf("batch %s: %d queued, %d skipped (%d/%d files sent)\n",
+					out.BatchID, out.Queued, out.Skipped, start+len(batch), len(paths))
+			}
+			fmt.Printf("done: %d queued, %d skipped, %d failed to send\n", queued, skipped, failed)
+			if failed > 0 {
+				return fmt.Errorf("%d files were not uploaded", failed)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "include subdirectories")
+	cmd.Flags().IntVar(&batchSize, "batch", 20, "files per bulk upload request")
+	cmd.Flags().BoolVar(&anonymize, "anonymize", false, "mask PII (blind screening)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "only list what would be uploaded")
+	cmd.Flags().IntVar(&maxRetries, "retries", 10, "retries of a batch refused because the queue is busy")
+	return cmd
+}
+
+// findCVs lists the files in dir with an accepted CV extension, in name
+// order. Hidden files (editor lock files like ~$cv.docx included) are skipped.
+func findCVs(dir string, recursive bool) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if path != dir && (!recursive || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "~$") {
+			return nil
+		}
+		if cvExtensions[strings.ToLower(filepath.Ext(name))] {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	return paths, err
+}
+
+// uploadBatchRetrying uploads a batch, waiting out up to retries 429s from
+// the server's backpressure.
+func uploadBatchRetrying(paths []string, anonymize bool, retries int) (*bulkUploadResponse, error) {
+	for attempt := 0; ; attempt++ {
+		out, err := uploadBatch(paths, anonymize)
+		var apiErr *apiError
+		if attempt >= retries || !errors.As(err, &apiErr) || apiErr.status != http.StatusTooManyRequests {
+			return out, err
+		}
+		wait := apiErr.retryAfter
+		if wait <= 0 {
+			wait = 30 * time.Second
+		}
+		fmt.Printf("processing queue is busy, retrying in %s\n", wait)
+		time.Sleep(wait)
+	}
+}
+
+func uploadBatch(paths []string, anonymize bool) (*bulkUploadResponse, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, path := range paths {
+		if err := addFile(mw, path); err != nil {
+			return nil, err
+		}
+	}
+	if anonymize {
+		mw.WriteField("anonymize", "true")
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	var out bulkUploadResponse
+	if err := client.do(http.MethodPost, "/api/cv/bulk-upload", mw.FormDataContentType(), &body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func addFile(mw *multipart.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	part, err := mw.CreateFormFile("files", filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(part, f)
+	return err
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"cv-search/internal/graphrag"
+	"cv-search/internal/llm"
+)
+
+// field is one backfillable attribute of a person: which person nodes lack
+// it, how to read it from an extraction and where to write it.
+type field struct {
+	// missing is a SQL condition on the person node n that holds while the
+	// field is still empty.
+	missing string
+	// value returns the field from an extraction, false if the CV doesn't
+	// state it.
+	value func(e *llm.CVExtraction) (any, bool)
+	// save writes v for node n.
+	save func(ctx context.Context, t *tool, n nodeRow, v any) error
+}
+
+var fields = map[string]field{
+	"current_position": propertyField("current_position", func(e *llm.CVExtraction) (any, bool) {
+		pos := strings.TrimSpace(e.Candidate.CurrentPosition)
+		return pos, pos != ""
+	}),
+	"seniority": propertyField("seniority", func(e *llm.CVExtraction) (any, bool) {
+		s := strings.TrimSpace(e.Candidate.Seniority)
+		return s, s != ""
+	}),
+	"total_experience_years": propertyField("total_experience_years", func(e *llm.CVExtraction) (any, bool) {
+		// Same parsing as the graph build ("5+", "3.5 years").
+		p := graphrag.PersonPropertiesFrom(map[string]interface{}{"total_experience_years": e.Candidate.TotalExperienceYears})
+		if p.TotalExperienceYears == nil {
+			return nil, false
+		}
+		return *p.TotalExperienceYears, true
+	}),
+	"work_modes": propertyField("work_modes", func(e *llm.CVExtraction) (any, bool) {
+		modes := graphrag.NormalizeWorkModes(e.Candidate.WorkModes)
+		return modes, len(modes) > 0
+	}),
+	"employment_types": propertyField("employment_types", func(e *llm.CVExtraction) (any, bool) {
+		types := graphrag.NormalizeEmploymentTypes(e.Candidate.EmploymentTypes)
+		return types, len(types) > 0
+	}),
+	"notice_period_days": propertyField("notice_period_days", func(e *llm.CVExtraction) (any, bool) {
+		// Same parsing as the graph build ("1 month", "immediately").
+		return graphrag.NoticePeriodDays(e.Candidate.NoticePeriod)
+	}),
+	"available_from": propertyField("available_from", func(e *llm.CVExtraction) (any, bool) {
+		d := graphrag.NormalizeAvailableFrom(e.Candidate.AvailableFrom)
+		return d, d != ""
+	}),
+	"location": {
+		// Lives on the linked candidates row, not the node.
+		missing: `EXISTS (SELECT 1 FROM candidates c
+		                  WHERE c.graph_node_id = n.id AND c.deleted_at IS NULL AND COALESCE(c.location, '') = '')`,
+		value: func(e *llm.CVExtraction) (any, bool) {
+			for _, loc := range e.Locations {
+				if loc = strings.TrimSpace(loc); loc != "" {
+					return loc, true // the first one is where they live now
+				}
+			}
+			return nil, false
+		},
+		save: func(ctx context.Context, t *tool, n nodeRow, v any) error {
+			_, err := t.db.GetConnection().ExecContext(ctx, `
+				UPDATE candidates SET location = $1, updated_at = NOW()
+				WHERE graph_node_id = $2 AND deleted_at IS NULL AND COALESCE(location, '') = ''
+			`, v, n.id)
+			return err
+		},
+	},
+	"languages": {
+		missing: `NOT EXISTS (SELECT 1 FROM graph_edges e WHERE e.source_node_id = n.id AND e.edge_type = 'SPEAKS')`,
+		value: func(e *llm.CVExtraction) (any, bool) {
+			var langs []llm.Language
+			for _, l := range e.Languages {
+				if strings.TrimSpace(l.Name) != "" {
+					langs = append(langs, l)
+				}
+			}
+			return langs, len(langs) > 0
+		},
+		save: saveLanguages,
+	},
+}
+
+// fieldNames lists the --field values.
+func fieldNames() string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// propertyField is a field stored in the person node's properties.
+func propertyField(key string, value func(e *llm.CVExtraction) (any, bool)) field {
+	return field{
+		missing: fmt.Sprintf(`COALESCE(n.properties->>'%s', '') = ''`, key),
+		value:   value,
+		save: func(ctx context.Context, t *tool, n nodeRow, v any) error {
+			data, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			_, err = t.db.GetConnection().ExecContext(ctx, `
+				UPDATE graph_nodes SET properties = jsonb_set(properties, $1::text[], $2::jsonb, true) WHERE id = $3
+			`, "{"+key+"}", string(data), n.id)
+			return err
+		},
+	}
+}
+
+// saveLanguages adds the language nodes and SPEAKS edges the graph build
+// would have created.
+func saveLanguages(ctx context.Context, t *tool, n nodeRow, v any) error {
+	var entities []graphrag.Entity
+	var rels []graphrag.Relationship
+	for _, lang := range v.([]llm.Language) {
+		langID := "language_" + lang.Name
+		entities = append(entities, graphrag.Entity{
+			Type:       "language",
+			Value:      langID,
+			Properties: graphrag.LanguageProperties{Name: lang.Name}.Map(),
+		})
+		props := map[string]interface{}{}
+		if level := graphrag.NormalizeLanguageLevel(lang.Proficiency); level != "" {
+			props["proficiency"] = level
+		}
+		rels = append(rels, graphrag.Relationship{
+			SourceType: "person",
+			SourceID:   n.nodeID,
+			TargetType: "language",
+			TargetID:   langID,
+			EdgeType:   "SPEAKS",
+			Properties: props,
+		})
+	}
+	if err := t.graph.CreateNodes(ctx, entities); err != nil {
+		return err
+	}
+	return t.graph.CreateEdges(ctx, rels)
+}
+
+// cvText returns the text of the CV a person node was built from: the CV
+// its properties name (cv_id, or cv_file_id on older nodes), else the newest
+// CV of its candidate (candidate_id property or candidates.graph_node_id).
+// "" if none is found.
+func (t *tool) cvText(ctx context.Context, n nodeRow) (string, error) {
+	var text string
+	err := t.db.GetConnection().QueryRowContext(ctx, `
+		SELECT f.parsed_text
+		FROM graph_nodes n
+		JOIN cv_files f ON f.deleted_at IS NULL AND COALESCE(f.parsed_text, '') <> '' AND (
+		        f.id::text IN (n.properties->>'cv_id', n.properties->>'cv_file_id')
+		     OR f.candidate_id::text = n.properties->>'candidate_id'
+		     OR f.candidate_id IN (SELECT c.id FROM candidates c WHERE c.graph_node_id = n.id))
+		WHERE n.id = $1
+		ORDER BY (f.id::text IN (n.properties->>'cv_id', n.properties->>'cv_file_id')) DESC, f.uploaded_at DESC
+		LIMIT 1
+	`, n.id).Scan(&text)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return text, err
+}
@@ -0,0 +1,454 @@
+// backfill fills one missing person field from the candidate's CV with the
+// LLM: person nodes lacking the field are linked to their CV text,
+// re-extracted, and the field is written where the graph build would have
+// put it.
+//
+// Usage:
+//
+//	go run ./cmd/tools/backfill/ -field seniority [flags]
+//
+// Fields: current_position, seniority, total_experience_years, work_modes,
+// employment_types, notice_period_days, available_from (person node
+// properties), location (candidates.location), languages (language nodes +
+// SPEAKS edges).
+//
+// Flags:
+//
+//	-field            Field to backfill (required)
+//	-dry-run          Only print what would be written (default true)
+//	-limit            Max person nodes per run, 0 = all (default 200)
+//	-workers          Concurrent LLM extractions (default 4)
+//	-checkpoint       Progress file (default backfill_<field>.checkpoint.json)
+//	-reset            Ignore the checkpoint and start over
+//	-batch-threshold  Above this many CVs, use the Groq Batch API (default
+//	                  $BACKFILL_BATCH_THRESHOLD, else 15)
+//
+// Nodes are processed in id order. Every node that was written, or whose CV
+// doesn't state the field, or that has no CV, is recorded in the checkpoint
+// and skipped by later runs, so an interrupted or limited run resumes where
+// it left off; nodes whose extraction failed are retried.
+//
+// Required env vars: DATABASE_URL, LLM_PROVIDER, LLM_MODEL (+ GROQ_API_KEY or
+// OPENAI_API_KEY). GROQ_RPM_LIMIT paces Groq like the API server does.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"cv-search/internal/graphrag"
+	"cv-search/internal/llm"
+	"cv-search/internal/storage"
+)
+
+type nodeRow struct {
+	id     int
+	nodeID string
+}
+
+type tool struct {
+	db       *storage.DB
+	graph    *graphrag.GraphBuilder
+	llm      *llm.Service
+	provider string
+	field    field
+	name     string
+	dryRun   bool
+}
+
+type item struct {
+	node nodeRow
+	text string
+}
+
+func main() {
+	var fieldName, checkpointPath string
+	var dryRun, reset bool
+	var limit, workers, batchThreshold int
+	batchThreshold = 15
+	if v := os.Getenv("BACKFILL_BATCH_THRESHOLD"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			batchThreshold = n
+		}
+	}
+	flag.StringVar(&fieldName, "field", "", "Field to backfill: "+fieldNames())
+	flag.BoolVar(&dryRun, "dry-run", true, "If true, do not persist updates; just print changes")
+	flag.IntVar(&limit, "limit", 200, "Max number of person nodes to process in one run (0 = all)")
+	flag.IntVar(&workers, "workers", 4, "Concurrent LLM extractions")
+	flag.StringVar(&checkpointPath, "checkpoint", "", "Progress file (default backfill_<field>.checkpoint.json)")
+	flag.BoolVar(&reset, "reset", false, "Ignore the checkpoint and start over")
+	flag.IntVar(&batchThreshold, "batch-threshold", batchThreshold, "Above this many CVs, use the Groq Batch API")
+	flag.Parse()
+
+	f, ok := fields[fieldName]
+	if !ok {
+		log.Fatalf("-field must be one of: %s", fieldNames())
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	if checkpointPath == "" {
+		checkpointPath = "backfill_" + fieldName + ".checkpoint.json"
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		log.Fatal("DATABASE_URL is required")
+	}
+
+	llmProvider := os.Getenv("LLM_PROVIDER")
+	llmModel := os.Getenv("LLM_MODEL")
+	if llmProvider == "" || llmProvider == "none" {
+		log.Fatal("LLM_PROVIDER must be set (e.g. openai|ollama|groq) and configured")
+	}
+	var llmAPIKey string
+	switch llmProvider {
+	case "groq":
+		llmAPIKey = os.Getenv("GROQ_API_KEY")
+	case "ollama":
+		llmAPIKey = os.Getenv("OLLAMA_API_KEY")
+	default:
+		llmAPIKey = os.Getenv("OPENAI_API_KEY")
+	}
+	if llmAPIKey == "" && llmProvider != "ollama" {
+		log.Fatalf("API key not set for provider %q (set GROQ_API_KEY or OPENAI_API_KEY)", llmProvider)
+	}
+
+	log.Printf("Connecting to DB...")
+	db, err := storage.NewDB(dbURL)
+	if err != nil {
+		log.Fatalf("failed to connect to db: %v", err)
+	}
+	defer db.Close()
+
+	log.Printf("Creating LLM service (provider=%s, model=%s)", llmProvider, llmModel)
+	llmSvc := llm.NewService(llmProvider, llmAPIKey, llmModel)
+	if v, err := strconv.Atoi(os.Getenv("GROQ_RPM_LIMIT")); err == nil {
+		llmSvc.SetRPMLimit(v)
+	}
+	llmSvc.SetBaseURL(os.Getenv("OLLAMA_URL"))
+
+	cp := &checkpoint{path: checkpointPath, Field: fieldName}
+	if !reset {
+		if err := cp.load(); err != nil {
+			log.Fatalf("checkpoint: %v", err)
+		}
+		if len(cp.Done) > 0 {
+			log.Printf("Resuming from %s: %d nodes already done", checkpointPath, len(cp.Done))
+		}
+	}
+	if dryRun {
+		cp.path = "" // a dry run changes nothing, so it doesn't count as progress
+	}
+
+	// Ctrl-C stops handing out work; what finished is checkpointed.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	t := &tool{
+		db:       db,
+		graph:    graphrag.NewGraphBuilder(db.GetConnection()),
+		llm:      llmSvc,
+		provider: llmProvider,
+		field:    f,
+		name:     fieldName,
+		dryRun:   dryRun,
+	}
+	items, err := t.pending(ctx, cp, limit)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	if len(items) == 0 {
+		log.Println("No nodes with a resolvable CV to backfill.")
+		cp.save()
+		return
+	}
+
+	extractions := t.batchExtract(ctx, items, batchThreshold)
+	t.run(ctx, items, extractions, workers, cp)
+}
+
+// pending returns up to limit person nodes still missing the field, not in
+// the checkpoint, with their CV text. Nodes without a CV are checkpointed.
+func (t *tool) pending(ctx context.Context, cp *checkpoint, limit int) ([]item, error) {
+	q := `SELECT n.id, n.node_id FROM graph_nodes n
+	      WHERE n.node_type = 'person' AND n.deleted_at IS NULL AND ` + t.field.missing + `
+	        AND NOT (n.id = ANY($1))
+	      ORDER BY n.id`
+	args := []any{cp.doneIDs()}
+	if limit > 0 {
+		q += ` LIMIT $2`
+		args = append(args, limit)
+	}
+	rows, err := t.db.GetConnection().QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query person nodes: %w", err)
+	}
+	var nodes []nodeRow
+	for rows.Next() {
+		var n nodeRow
+		if err := rows.Scan(&n.id, &n.nodeID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan person node: %w", err)
+		}
+		nodes = append(nodes, n)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query person nodes: %w", err)
+	}
+	log.Printf("Found %d person nodes with empty %s (limit %d)", len(nodes), t.name, limit)
+
+	var items []item
+	for _, n := range nodes {
+		text, err := t.cvText(ctx, n)
+		if err != nil {
+			log.Printf("CV lookup failed for node %s: %v", n.nodeID, err)
+			continue
+		}
+		if text == "" {
+			log.Printf("No CV found for node %s (id=%d) — skipping", n.nodeID, n.id)
+			cp.mark(n.id)
+			continue
+		}
+		items = append(items, item{node: n, text: text})
+	}
+	return items, nil
+}
+
+// batchExtract submits the CVs as one Groq Batch API job when there are more
+// than threshold of them — avoids the standard per-model rate limit
+// entirely (separate quota) and is 50% cheaper. Skipped for dry runs (no
+// point paying for a real batch just to print predictions) and for small
+// runs (already self-throttled by llm.Service's rate limiter). Returns the
+// extractions by node_id; anything missing is extracted synchronously.
+func (t *tool) batchExtract(ctx context.Context, items []item, threshold int) map[string]*llm.CVExtraction {
+	extractions := make(map[string]*llm.CVExtraction, len(items))
+	if t.dryRun || t.provider != "groq" || len(items) <= threshold {
+		return extractions
+	}
+
+	log.Printf("Submitting %d CVs as a Groq Batch API job (threshold=%d)...", len(items), threshold)
+	batchItems := make(map[string]string, len(items))
+	for _, it := range items {
+		batchItems[it.node.nodeID] = it.text
+	}
+	groqBatchID, _, err := t.llm.SubmitExtractionBatch(batchItems, "24h")
+	if err != nil {
+		log.Printf("Groq batch submission failed, falling back to synchronous processing for all %d items: %v", len(items), err)
+		return extractions
+	}
+	log.Printf("Batch submitted: %s — polling every 30s until complete...", groqBatchID)
+
+	var outputFileID string
+	for outputFileID == "" {
+		select {
+		case <-ctx.Done():
+			log.Printf("Interrupted while waiting for batch %s", groqBatchID)
+			return extractions
+		case <-time.After(30 * time.Second):
+		}
+		status, err := t.llm.GetGroqBatchStatus(groqBatchID)
+		if err != nil {
+			log.Printf("  status check failed: %v (retrying)", err)
+			continue
+		}
+		log.Printf("  batch status=%s (%d/%d completed)", status.Status, status.RequestCounts.Completed, status.RequestCounts.Total)
+		if status.Status == "completed" || status.Status == "failed" || status.Status == "expired" || status.Status == "cancelled" {
+			if status.OutputFileID == "" {
+				return extractions
+			}
+			outputFileID = status.OutputFileID
+		}
+	}
+
+	results, lineErrors, err := t.llm.FetchExtractionBatchResults(ctx, outputFileID)
+	if err != nil {
+		log.Printf("  failed to fetch batch results: %v", err)
+	}
+	for customID, extraction := range results {
+		extractions[customID] = extraction
+	}
+	for customID, msg := range lineErrors {
+		log.Printf("  batch line failed for node %s: %s (will retry synchronously)", customID, msg)
+	}
+	return extractions
+}
+
+// run extracts (where the batch didn't) and writes the field for every item
+// on workers goroutines, logging progress and checkpointing as it goes.
+func (t *tool) run(ctx context.Context, items []item, extractions map[string]*llm.CVExtraction, workers int, cp *checkpoint) {
+	var mu sync.Mutex
+	var processed int
+	counts := map[outcome]int{}
+	progress := func(o outcome) {
+		mu.Lock()
+		defer mu.Unlock()
+		counts[o]++
+		processed++
+		if processed%10 == 0 || processed == len(items) {
+			log.Printf("Progress: %d/%d (updated %d, not in CV %d, failed %d)",
+				processed, len(items), counts[updated], counts[notInCV], counts[failed])
+		}
+	}
+
+	work := make(chan item)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for it := range work {
+				progress(t.process(ctx, it, extractions[it.node.nodeID], cp))
+			}
+		}()
+	}
+feed:
+	for _, it := range items {
+		select {
+		case work <- it:
+		case <-ctx.Done():
+			log.Printf("Interrupted — finishing in-flight nodes")
+			break feed
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	cp.save()
+	log.Printf("Backfill run complete: %d processed, %d updated, %d not in CV, %d failed",
+		processed, counts[updated], counts[notInCV], counts[failed])
+	if cp.path != "" {
+		log.Printf("Checkpoint: %s (%d nodes done)", cp.path, len(cp.Done))
+	}
+}
+
+type outcome int
+
+const (
+	updated outcome = iota
+	notInCV         // the CV doesn't state the field
+	failed          // retried next run
+)
+
+// process extracts (unless the batch already did) and writes the field for
+// one node.
+func (t *tool) process(ctx context.Context, it item, extraction *llm.CVExtraction, cp *checkpoint) outcome {
+	if extraction == nil {
+		var err error
+		extraction, err = t.llm.ExtractEntities(ctx, it.text)
+		if err != nil {
+			log.Printf("LLM extraction failed for node %s: %v", it.node.nodeID, err)
+			return failed
+		}
+	}
+
+	v, ok := t.field.value(extraction)
+	if !ok {
+		log.Printf("LLM did not extract %s for node %s", t.name, it.node.nodeID)
+		cp.mark(it.node.id)
+		return notInCV
+	}
+
+	shown, _ := json.Marshal(v)
+	if t.dryRun {
+		log.Printf("[dry-run] Would update node %s: set %s=%s", it.node.nodeID, t.name, shown)
+		return updated
+	}
+	if err := t.field.save(ctx, t, it.node, v); err != nil {
+		log.Printf("failed to update node %s: %v", it.node.nodeID, err)
+		return failed
+	}
+	log.Printf("Node %s -> %s=%s", it.node.nodeID, t.name, shown)
+	cp.mark(it.node.id)
+	return updated
+}
+
+// checkpoint is the set of person nodes a backfill of one field is done
+// with, saved to a JSON file so later runs skip them. A checkpoint without
+// a path is kept in memory only.
+type checkpoint struct {
+	path  string
+	mu    sync.Mutex
+	dirty int
+
+	Field     string    `json:"field"`
+	Done      []int     `json:"done"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// checkpointEvery is how many newly done nodes trigger a save.
+const checkpointEvery = 25
+
+func (c *checkpoint) load() error {
+	data, err := os.ReadFile(c.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	field := c.Field
+	if err := json.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("%s: %w", c.path, err)
+	}
+	if c.Field != field {
+		return fmt.Errorf("%s is for field %q, not %q (use -checkpoint or -reset)", c.path, c.Field, field)
+	}
+	return nil
+}
+
+func (c *checkpoint) doneIDs() []int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ids := make([]int64, len(c.Done))
+	for i, id := range c.Done {
+		ids[i] = int64(id)
+	}
+	return ids
+}
+
+func (c *checkpoint) mark(id int) {
+	c.mu.Lock()
+	c.Done = append(c.Done, id)
+	c.dirty++
+	due := c.dirty >= checkpointEvery
+	c.mu.Unlock()
+	if due {
+		c.save()
+	}
+}
+
+// save writes the checkpoint atomically (temp file + rename).
+func (c *checkpoint) save() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.path == "" {
+		return
+	}
+	c.UpdatedAt = time.Now()
+	data, err := json.Marshal(c)
+	if err != nil {
+		log.Printf("checkpoint: %v", err)
+		return
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		log.Printf("checkpoint: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		log.Printf("checkpoint: %v", err)
+		return
+	}
+	c.dirty = 0
+}
@@ -0,0 +1,74 @@
+// cleanup_blobs runs one CV file retention pass: prunes uploads beyond the
+// newest N per candidate and deletes blob-store objects that no cv_files row
+// references any more (after the orphan TTL). The API server runs the same
+// pass hourly; use this tool for a one-off cleanup or to preview one.
+//
+// Usage:
+//
+//	go run ./cmd/tools/cleanup_blobs/ [flags]
+//
+// Flags:
+//
+//	-dry-run      Only report what would be removed (default true)
+//	-keep         Versions to keep per candidate (default CV_KEEP_VERSIONS, 0 = all)
+//	-orphan-ttl   Grace period for unreferenced blobs (default BLOB_ORPHAN_TTL_HOURS or 24h)
+//
+// Required env vars: DATABASE_URL, plus BLOB_* for a non-local blob backend
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"cv-search/internal/config"
+	"cv-search/internal/retention"
+	"cv-search/internal/storage"
+)
+
+func main() {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	dryRun := flag.Bool("dry-run", true, "only report")
+	keep := flag.Int("keep", cfg.CVKeepVersions, "versions to keep per candidate (0 = all)")
+	orphanTTL := flag.Duration("orphan-ttl", cfg.BlobOrphanTTL, "grace period before an unreferenced blob is deleted")
+	flag.Parse()
+
+	db, err := storage.NewDB(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("DB: %v", err)
+	}
+	defer db.Close()
+
+	blobs, err := storage.NewBlobStore(storage.BlobConfig{
+		Backend:         cfg.BlobBackend,
+		LocalDir:        cfg.UploadsDir,
+		Bucket:          cfg.BlobBucket,
+		Region:          cfg.BlobRegion,
+		Endpoint:        cfg.BlobEndpoint,
+		AccessKeyID:     cfg.BlobAccessKeyID,
+		SecretAccessKey: cfg.BlobSecretAccessKey,
+	})
+	if err != nil {
+		log.Fatalf("blob store: %v", err)
+	}
+
+	rep, err := retention.Run(context.Background(), db, blobs, retention.Policy{
+		KeepVersions: *keep,
+		OrphanTTL:    *orphanTTL,
+		DryRun:       *dryRun,
+	})
+	if err != nil {
+		log.Fatalf("cleanup failed: %v", err)
+	}
+
+	prefix := ""
+	if *dryRun {
+		prefix = "[DRY RUN] would remove: "
+	}
+	log.Printf("%sversions_pruned=%d orphans_deleted=%d orphans_failed=%d",
+		prefix, rep.VersionsPruned, rep.OrphansDeleted, rep.OrphansFailed)
+}
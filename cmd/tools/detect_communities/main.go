@@ -9,9 +9,11 @@
 //
 //	--k       Number of clusters (default 10)
 //	--level   Community level stored in graph_communities.level (default 0)
+//	--org     Organization whose people are clustered (default 1)
 //	--dry-run Print cluster summaries without writing to DB
 //
-// Required env vars: DATABASE_URL, OPENAI_API_KEY, LLM_PROVIDER, LLM_MODEL (+ GROQ_API_KEY if provider=groq)
+// Required env vars: DATABASE_URL, OPENAI_API_KEY, LLM_PROVIDER, LLM_MODEL (+ GROQ_API_KEY if provider=groq);
+// EMBEDDING_MODEL / EMBEDDING_DIMENSIONS when not on the default model
 package main
 
 import (
@@ -24,12 +26,14 @@ import (
 	"math"
 	"math/rand"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"cv-search/internal/graphrag"
 	"cv-search/internal/llm"
 	"cv-search/internal/storage"
+	"cv-search/internal/tenant"
 )
 
 // personNode holds the data loaded from graph_nodes for clustering.
@@ -45,9 +49,11 @@ func main() {
 	var k int
 	var level int
 	var dryRun bool
+	var orgID int
 	flag.IntVar(&k, "k", 10, "Number of clusters for k-means")
 	flag.IntVar(&level, "level", 0, "Community level (stored in graph_communities.level)")
 	flag.BoolVar(&dryRun, "dry-run", false, "Print cluster info without writing to DB")
+	flag.IntVar(&orgID, "org", tenant.DefaultOrgID, "Organization whose people are clustered")
 	flag.Parse()
 
 	dbURL := os.Getenv("DATABASE_URL")
@@ -79,11 +85,13 @@ func main() {
 	conn := db.GetConnection()
 	llmSvc := llm.NewService(llmProvider, llmAPIKey, llmModel)
 	embSvc := graphrag.NewEmbeddingService(openAIKey, conn)
+	dims, _ := strconv.Atoi(os.Getenv("EMBEDDING_DIMENSIONS"))
+	embSvc.SetModel(os.Getenv("EMBEDDING_MODEL"), dims)
 	ctx := context.Background()
 
 	// Step 1: Load all person nodes with embeddings.
 	log.Println("Loading person embeddings from graph_nodes...")
-	persons, err := loadPersonEmbeddings(ctx, conn)
+	persons, err := loadPersonEmbeddings(ctx, conn, orgID)
 	if err != nil {
 		log.Fatalf("failed to load embeddings: %v", err)
 	}
@@ -155,7 +163,7 @@ func main() {
 		communityID := fmt.Sprintf("cluster_%d", cl.idx)
 		topPositions := uniqueTop(cl.data.positions, 5)
 
-		title, summary, err := generateCommunitySummary(llmSvc, cl.skills, topPositions)
+		title, summary, err := generateCommunitySummary(ctx, llmSvc, cl.skills, topPositions)
 		if err != nil {
 			log.Printf("[cluster %d] LLM failed: %v — using fallback", cl.idx, err)
 			topN := min(3, len(cl.skills))
@@ -202,27 +210,27 @@ func main() {
 		if summaryEmb != nil {
 			embBytes, _ := json.Marshal(summaryEmb)
 			upsertErr = conn.QueryRowContext(ctx, `
-				INSERT INTO graph_communities (level, community_id, title, summary, node_count, embedding, updated_at)
-				VALUES ($1, $2, $3, $4, $5, $6::vector, NOW())
-				ON CONFLICT (level, community_id) DO UPDATE
+				INSERT INTO graph_communities (level, community_id, title, summary, node_count, embedding, org_id, updated_at)
+				VALUES ($1, $2, $3, $4, $5, $6::vector, $7, NOW())
+				ON CONFLICT (org_id, level, community_id) DO UPDATE
 				  SET title = EXCLUDED.title,
 				      summary = EXCLUDED.summary,
 				      node_count = EXCLUDED.node_count,
 				      embedding = EXCLUDED.embedding,
 				      updated_at = NOW()
 				RETURNING id
-			`, level, r.communityID, r.title, r.summary, r.nodeCount, string(embBytes)).Scan(&gcID)
+			`, level, r.communityID, r.title, r.summary, r.nodeCount, string(embBytes), orgID).Scan(&gcID)
 		} else {
 			upsertErr = conn.QueryRowContext(ctx, `
-				INSERT INTO graph_communities (level, community_id, title, summary, node_count, updated_at)
-				VALUES ($1, $2, $3, $4, $5, NOW())
-				ON CONFLICT (level, community_id) DO UPDATE
+				INSERT INTO graph_communities (level, community_id, title, summary, node_count, org_id, updated_at)
+				VALUES ($1, $2, $3, $4, $5, $6, NOW())
+				ON CONFLICT (org_id, level, community_id) DO UPDATE
 				  SET title = EXCLUDED.title,
 				      summary = EXCLUDED.summary,
 				      node_count = EXCLUDED.node_count,
 				      updated_at = NOW()
 				RETURNING id
-			`, level, r.communityID, r.title, r.summary, r.nodeCount).Scan(&gcID)
+			`, level, r.communityID, r.title, r.summary, r.nodeCount, orgID).Scan(&gcID)
 		}
 		if upsertErr != nil {
 			log.Printf("[cluster %d] failed to upsert graph_communities: %v", r.clusterIdx, upsertErr)
@@ -253,11 +261,17 @@ func main() {
 		}
 	}
 
+	// Step 7: Centroids of the members' embeddings, for query-time relevance.
+	if err := graphrag.RefreshCommunityCentroids(tenant.WithOrg(ctx, orgID), conn, level); err != nil {
+		log.Printf("Failed to refresh community centroids: %v", err)
+	}
+
 	log.Printf("Done. %d communities written to DB.", len(results))
 }
 
-// loadPersonEmbeddings fetches all person nodes that have embeddings.
-func loadPersonEmbeddings(ctx context.Context, db *sql.DB) ([]personNode, error) {
+// loadPersonEmbeddings fetches all of an organization's person nodes that
+// have embeddings.
+func loadPersonEmbeddings(ctx context.Context, db *sql.DB, orgID int) ([]personNode, error) {
 	rows, err := db.QueryContext(ctx, `
 		SELECT id,
 		       node_id,
@@ -267,8 +281,10 @@ func loadPersonEmbeddings(ctx context.Context, db *sql.DB) ([]personNode, error)
 		FROM graph_nodes
 		WHERE node_type = 'person'
 		  AND embedding IS NOT NULL
+		  AND deleted_at IS NULL
+		  AND org_id = $1
 		ORDER BY id
-	`)
+	`, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -339,7 +355,7 @@ func loadTopSkillsForPersons(ctx context.Context, db *sql.DB, personIntIDs []int
 }
 
 // generateCommunitySummary calls the LLM to produce a short title and summary for a cluster.
-func generateCommunitySummary(svc *llm.Service, skills []string, positions []string) (string, string, error) {
+func generateCommunitySummary(ctx context.Context, svc *llm.Service, skills []string, positions []string) (string, string, error) {
 	skillStr := strings.Join(skills, ", ")
 	if skillStr == "" {
 		skillStr = "(no skills data)"
@@ -362,7 +378,7 @@ Generate a community profile in the following JSON format:
 
 Respond with valid JSON only. No markdown, no explanation.`, skillStr, posStr)
 
-	raw, err := svc.Generate(prompt)
+	raw, err := svc.Generate(ctx, prompt)
 	if err != nil {
 		return "", "", err
 	}
@@ -0,0 +1,134 @@
+// eval runs a labeled query set through the search engines and reports
+// nDCG@k, recall@k and MRR, so prompt or weight changes can be validated
+// before deploy.
+//
+// Usage:
+//
+//	go run ./cmd/tools/eval/ -queries cmd/tools/eval/queries.example.yaml [flags]
+//
+// Flags:
+//
+//	-queries        Path to the YAML query set (required)
+//	-engines        Comma-separated engines to run: hybrid, enhanced, llm (default "hybrid")
+//	-bm25           Hybrid BM25 weight (default from DefaultHybridConfig)
+//	-vector         Hybrid vector weight
+//	-graph          Hybrid graph weight
+//	-top-n          Hybrid FinalTopN
+//	-json           Also write the full per-query report to this file
+//	-disable-cache  Bypass the semantic and LLM caches
+//
+// Required env vars: DATABASE_URL, OPENAI_API_KEY, LLM_PROVIDER, LLM_MODEL (+ GROQ_API_KEY if provider=groq);
+// EMBEDDING_MODEL / EMBEDDING_DIMENSIONS when not on the default model
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"cv-search/internal/eval"
+	"cv-search/internal/graphrag"
+	"cv-search/internal/llm"
+	"cv-search/internal/storage"
+)
+
+func main() {
+	defaults := graphrag.DefaultHybridConfig()
+
+	var queriesPath, enginesFlag, jsonOut string
+	var disableCache bool
+	hybridCfg := defaults
+	flag.StringVar(&queriesPath, "queries", "", "Path to the YAML query set")
+	flag.StringVar(&enginesFlag, "engines", "hybrid", "Comma-separated engines: hybrid, enhanced, llm")
+	flag.Float64Var(&hybridCfg.BM25Weight, "bm25", defaults.BM25Weight, "Hybrid BM25 weight")
+	flag.Float64Var(&hybridCfg.VectorWeight, "vector", defaults.VectorWeight, "Hybrid vector weight")
+	flag.Float64Var(&hybridCfg.GraphWeight, "graph", defaults.GraphWeight, "Hybrid graph weight")
+	flag.IntVar(&hybridCfg.FinalTopN, "top-n", defaults.FinalTopN, "Hybrid FinalTopN")
+	flag.StringVar(&jsonOut, "json", "", "Write the full report as JSON to this file")
+	flag.BoolVar(&disableCache, "disable-cache", false, "Bypass semantic and LLM caches")
+	flag.Parse()
+
+	if queriesPath == "" {
+		log.Fatal("-queries is required")
+	}
+	qs, err := eval.LoadQuerySet(queriesPath)
+	if err != nil {
+		log.Fatalf("load query set: %v", err)
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		log.Fatal("DATABASE_URL is required")
+	}
+	openAIKey := os.Getenv("OPENAI_API_KEY")
+	if openAIKey == "" {
+		log.Fatal("OPENAI_API_KEY is required for query embeddings")
+	}
+	llmProvider := os.Getenv("LLM_PROVIDER")
+	llmModel := os.Getenv("LLM_MODEL")
+	var llmAPIKey string
+	switch llmProvider {
+	case "openai":
+		llmAPIKey = openAIKey
+	case "groq":
+		llmAPIKey = os.Getenv("GROQ_API_KEY")
+	default:
+		log.Fatalf("LLM_PROVIDER must be 'openai' or 'groq', got: %q", llmProvider)
+	}
+
+	db, err := storage.NewDB(dbURL)
+	if err != nil {
+		log.Fatalf("failed to connect to db: %v", err)
+	}
+	defer db.Close()
+
+	conn := db.GetConnection()
+	embeddingModel := os.Getenv("EMBEDDING_MODEL")
+	embeddingDims, _ := strconv.Atoi(os.Getenv("EMBEDDING_DIMENSIONS"))
+	llmAdapter := graphrag.NewLLMAdapter(llm.NewService(llmProvider, llmAPIKey, llmModel))
+
+	var engines []eval.Engine
+	for _, name := range strings.Split(enginesFlag, ",") {
+		switch strings.TrimSpace(name) {
+		case "hybrid":
+			engine := graphrag.NewHybridSearchEngine(conn, llmAdapter, openAIKey, disableCache)
+			engine.SetEmbeddingModel(embeddingModel, embeddingDims)
+			engines = append(engines, &eval.HybridEngine{Engine: engine, Config: hybridCfg})
+		case "enhanced":
+			engine := graphrag.NewEnhancedSearchEngine(conn, llmAdapter, openAIKey)
+			engine.SetEmbeddingModel(embeddingModel, embeddingDims)
+			engines = append(engines, &eval.EnhancedEngine{Engine: engine, DB: db})
+		case "llm":
+			engine := graphrag.NewLLMSearchEngine(conn, llmAdapter)
+			embeddings := graphrag.NewEmbeddingService(openAIKey, conn)
+			embeddings.SetModel(embeddingModel, embeddingDims)
+			engine.SetEmbeddingService(embeddings)
+			engines = append(engines, &eval.LLMEngine{Engine: engine, DB: db})
+		case "":
+		default:
+			log.Fatalf("unknown engine %q (want hybrid, enhanced or llm)", name)
+		}
+	}
+	if len(engines) == 0 {
+		log.Fatal("no engines selected")
+	}
+
+	log.Printf("Running %d queries from %q against %d engine(s)...", len(qs.Queries), qs.Name, len(engines))
+	report := eval.Run(context.Background(), qs, engines)
+	report.WriteTable(os.Stdout)
+
+	if jsonOut != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			log.Fatalf("marshal report: %v", err)
+		}
+		if err := os.WriteFile(jsonOut, data, 0644); err != nil {
+			log.Fatalf("write report: %v", err)
+		}
+		log.Printf("Full report written to %s", jsonOut)
+	}
+}
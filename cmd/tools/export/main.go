@@ -0,0 +1,71 @@
+// export writes a full snapshot of the system — candidates, CV file
+// metadata and parsed text, graph nodes and edges, communities and
+// embeddings — to a versioned zip archive (see internal/snapshot). Restore it
+// with cmd/tools/restore, e.g. to clone production into staging or after
+// losing the database. Uploaded files aren't included; copy the blob store
+// separately.
+//
+// Usage:
+//
+//	go run ./cmd/tools/export/ [flags]
+//
+// Flags:
+//
+//	-o   Archive path (default cv-search-<UTC time>.zip)
+//
+// Required env vars: DATABASE_URL
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"cv-search/internal/snapshot"
+	"cv-search/internal/storage"
+)
+
+func main() {
+	out := flag.String("o", "cv-search-"+time.Now().UTC().Format("20060102-150405")+".zip", "archive path")
+	flag.Parse()
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		log.Fatal("DATABASE_URL is required")
+	}
+	db, err := storage.NewDB(dbURL)
+	if err != nil {
+		log.Fatalf("DB: %v", err)
+	}
+	defer db.Close()
+
+	// Write next to the target and rename, so a failed export never leaves
+	// a truncated archive under the final name.
+	tmp, err := os.CreateTemp(filepath.Dir(*out), ".export-*.zip")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.Remove(tmp.Name())
+
+	start := time.Now()
+	m, err := snapshot.Export(context.Background(), db, tmp)
+	if err == nil {
+		err = tmp.Close()
+	} else {
+		tmp.Close()
+	}
+	if err != nil {
+		log.Fatalf("export failed: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), *out); err != nil {
+		log.Fatal(err)
+	}
+
+	for _, t := range m.Tables {
+		log.Printf("  %-18s %d", t.Name, t.Rows)
+	}
+	log.Printf("wrote %s: %d rows, schema version %d (%s)", *out, m.Rows(), m.SchemaVersion, time.Since(start).Round(time.Millisecond))
+}
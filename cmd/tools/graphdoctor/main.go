@@ -0,0 +1,102 @@
+// graphdoctor checks the knowledge graph for inconsistencies: dangling and
+// duplicate edges, orphan skill/company/... nodes, person nodes without a
+// CV, nodes without an embedding and nodes with malformed properties. It
+// prints a report and, with -fix, repairs what can be repaired safely (see
+// graphrag.CheckGraph). Persons without a CV and missing embeddings are only
+// reported; run `cli embeddings` for the latter.
+//
+// Usage:
+//
+//	go run ./cmd/tools/graphdoctor/ [flags]
+//
+// Flags:
+//
+//	-fix    Delete dangling/duplicate edges and orphan nodes, normalise properties
+//	-json   Print the report as JSON
+//
+// Exits with status 1 when issues were found and -fix wasn't given.
+//
+// Required env vars: DATABASE_URL
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"cv-search/internal/config"
+	"cv-search/internal/graphrag"
+	"cv-search/internal/storage"
+)
+
+func main() {
+	fix := flag.Bool("fix", false, "repair what can be repaired")
+	asJSON := flag.Bool("json", false, "print JSON")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	db, err := storage.NewDB(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("DB: %v", err)
+	}
+	defer db.Close()
+
+	builder := graphrag.NewGraphBuilder(db.GetConnection())
+	rep, err := builder.CheckGraph(context.Background(), *fix)
+	if err != nil {
+		log.Fatalf("check failed: %v", err)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(rep); err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		printIssue("dangling edges", rep.DanglingEdges, *fix)
+		printIssue("duplicate edges", rep.DuplicateEdges, *fix)
+		printIssue("orphan nodes", rep.OrphanNodes, *fix)
+		printIssue("persons without CV", rep.PersonsWithoutCV, false)
+		printIssue("missing embeddings", rep.MissingEmbeddings, false)
+		if len(rep.EmbeddingsByType) > 0 {
+			types := make([]string, 0, len(rep.EmbeddingsByType))
+			for t := range rep.EmbeddingsByType {
+				types = append(types, t)
+			}
+			sort.Strings(types)
+			for _, t := range types {
+				fmt.Printf("    %-12s %d\n", t, rep.EmbeddingsByType[t])
+			}
+		}
+		printIssue("malformed properties", rep.MalformedProperties, *fix)
+		fmt.Printf("%d issues\n", rep.Total())
+	}
+
+	if rep.Total() > 0 && !*fix {
+		os.Exit(1)
+	}
+}
+
+func printIssue(name string, issue graphrag.GraphIssue, fixable bool) {
+	line := fmt.Sprintf("%-22s %d", name, issue.Count)
+	if fixable && issue.Count > 0 {
+		line += fmt.Sprintf(" (%d fixed)", issue.Fixed)
+	}
+	fmt.Println(line)
+	if len(issue.Sample) > 0 {
+		more := ""
+		if issue.Count > len(issue.Sample) {
+			more = ", ..."
+		}
+		fmt.Printf("    %s%s\n", strings.Join(issue.Sample, ", "), more)
+	}
+}
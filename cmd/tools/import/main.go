@@ -0,0 +1,160 @@
+// import sends a candidate export from another ATS (CSV or JSON) to a
+// running API's POST /api/candidates/import, which creates the candidates,
+// downloads their resumes and queues them through the normal extraction
+// pipeline. The rows are checked locally first, so a malformed export fails
+// before anything is sent.
+//
+// Usage:
+//
+//	go run ./cmd/tools/import/ -file export.csv -source greenhouse [flags]
+//
+// Flags:
+//
+//	-file     Path to the export (.csv or .json; required)
+//	-source   Label stored as candidates.import_source (default "import")
+//	-format   csv or json (default: from the file extension / content)
+//	-api      API base URL (default $API_URL, else http://localhost:8080)
+//	-dry-run  Only validate on the server, create nothing (default true)
+//
+// Optional env vars: API_URL, API_KEY (sent as X-API-Key, recorded in the
+// audit log).
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"cv-search/internal/importer"
+)
+
+type rowResult struct {
+	Row         int    `json:"row"`
+	Name        string `json:"name"`
+	CandidateID int    `json:"candidate_id"`
+	Status      string `json:"status"`
+	Resume      string `json:"resume"`
+	Error       string `json:"error"`
+}
+
+type importResponse struct {
+	BatchID        string      `json:"batch_id"`
+	Total          int         `json:"total"`
+	Valid          int         `json:"valid"`
+	Created        int         `json:"created"`
+	Updated        int         `json:"updated"`
+	Invalid        int         `json:"invalid"`
+	Errors         int         `json:"errors"`
+	ResumesQueued  int         `json:"resumes_queued"`
+	ResumeFailed   int         `json:"resume_failed"`
+	CheckStatusURL string      `json:"check_status_url"`
+	Results        []rowResult `json:"results"`
+}
+
+func main() {
+	var path, source, format, apiURL string
+	var dryRun bool
+	flag.StringVar(&path, "file", "", "Path to the CSV/JSON export")
+	flag.StringVar(&source, "source", "import", "Label stored as candidates.import_source")
+	flag.StringVar(&format, "format", "", "csv or json (default: detected)")
+	flag.StringVar(&apiURL, "api", os.Getenv("API_URL"), "API base URL")
+	flag.BoolVar(&dryRun, "dry-run", true, "only validate, create nothing")
+	flag.Parse()
+
+	if path == "" {
+		log.Fatal("-file is required")
+	}
+	if apiURL == "" {
+		apiURL = "http://localhost:8080"
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatalf("read %s: %v", path, err)
+	}
+	hint := format
+	if hint == "" {
+		hint = path
+	}
+	detected, err := importer.DetectFormat(hint, data[:min(len(data), 512)])
+	if err != nil {
+		log.Fatalf("%s: %v", path, err)
+	}
+	rows, err := importer.Parse(bytes.NewReader(data), detected, 0)
+	if err != nil {
+		log.Fatalf("%s: %v", path, err)
+	}
+	invalid := 0
+	for _, row := range rows {
+		if row.Err != nil {
+			invalid++
+			log.Printf("[Import] row %d: %v", row.Line, row.Err)
+		}
+	}
+	log.Printf("[Import] %s: %d rows (%s), %d invalid locally", path, len(rows), detected, invalid)
+	if len(rows) == 0 {
+		return
+	}
+
+	q := url.Values{}
+	q.Set("source", source)
+	q.Set("format", string(detected))
+	if dryRun {
+		q.Set("dry_run", "true")
+	}
+	endpoint := strings.TrimRight(apiURL, "/") + "/api/candidates/import?" + q.Encode()
+
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		log.Fatalf("build request: %v", err)
+	}
+	if detected == importer.FormatJSON {
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req.Header.Set("Content-Type", "text/csv")
+	}
+	if key := os.Getenv("API_KEY"); key != "" {
+		req.Header.Set("X-API-Key", key)
+	}
+
+	// Resumes are downloaded during the request, so allow for a slow one.
+	client := &http.Client{Timeout: 15 * time.Minute}
+	resp, err := client.Do(req)
+	if err != nil {
+		log.Fatalf("POST %s: %v", endpoint, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusMultiStatus {
+		log.Fatalf("import failed: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var out importResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		log.Fatalf("decode response: %v", err)
+	}
+	for _, res := range out.Results {
+		if res.Error != "" {
+			fmt.Printf("row %d %-8s %s: %s\n", res.Row, res.Status, res.Name, res.Error)
+		}
+	}
+
+	if dryRun {
+		fmt.Printf("\nDry run: %d rows, %d valid, %d invalid. Re-run with -dry-run=false to import.\n",
+			out.Total, out.Valid, out.Invalid)
+		return
+	}
+	fmt.Printf("\nImported %d rows: %d created, %d updated, %d invalid, %d errors; %d resumes queued, %d failed.\n",
+		out.Total, out.Created, out.Updated, out.Invalid, out.Errors, out.ResumesQueued, out.ResumeFailed)
+	if out.CheckStatusURL != "" {
+		fmt.Printf("Processing status: %s%s\n", strings.TrimRight(apiURL, "/"), out.CheckStatusURL)
+	}
+}
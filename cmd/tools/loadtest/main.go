@@ -0,0 +1,296 @@
+// loadtest fires search requests at a running API server at a fixed rate
+// and reports latency percentiles per endpoint and, for hybrid search, per
+// pipeline stage and retrieval source (the response's stage_latency_ms and
+// source_latency_ms), so LLM and DB capacity can be sized from numbers.
+//
+// Requests are sent open-loop: one every 1/rps seconds whether or not
+// earlier ones have returned, like real traffic. When -concurrency requests
+// are already in flight the next one is dropped and counted, rather than
+// queued, so an overloaded server shows up as drops instead of a slower send
+// rate.
+//
+// Usage:
+//
+//	go run ./cmd/tools/loadtest/ [flags]
+//
+// Flags:
+//
+//	-api           API base URL (default $API_URL or http://localhost:8080)
+//	-rps           Requests per second (default 2)
+//	-duration      How long to send (default 1m)
+//	-concurrency   Max requests in flight (default 32)
+//	-mix           Endpoint weights (default hybrid=8,graphrag=2)
+//	-queries       File with one query per line (default: built-in mix)
+//	-experiment    Search experiment for hybrid requests; also bypasses the semantic cache
+//	-timeout       Per-request timeout (default 2m)
+//	-json          Print the report as JSON
+//
+// Env vars: API_KEY (sent as X-API-Key when set)
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// defaultQueries cover the default communities with and without location,
+// seniority and experience constraints.
+var defaultQueries = []string{
+	"senior go developer istanbul",
+	"java spring backend developer 5+ years",
+	"react typescript frontend developer",
+	"flutter or react native mobile developer",
+	"devops engineer kubernetes aws terraform",
+	"data engineer kafka spark",
+	"machine learning engineer nlp pytorch",
+	"qa automation engineer selenium cypress",
+	"business analyst jira agile ankara",
+	"lead python developer with django",
+	"junior frontend developer vue",
+	"site reliability engineer remote",
+}
+
+// endpoints are the searches -mix can name.
+var endpoints = map[string]string{
+	"hybrid":   "/api/search/hybrid",
+	"graphrag": "/api/graphrag/search",
+}
+
+type target struct {
+	name   string
+	weight int
+}
+
+// result is one request's outcome.
+type result struct {
+	endpoint string
+	latency  time.Duration
+	status   int // 0 when the request didn't get a response
+	cacheHit bool
+	stages   map[string]int64
+	sources  map[string]int64
+}
+
+func main() {
+	defaultAPI := os.Getenv("API_URL")
+	if defaultAPI == "" {
+		defaultAPI = "http://localhost:8080"
+	}
+	api := flag.String("api", defaultAPI, "API base URL")
+	rps := flag.Float64("rps", 2, "requests per second")
+	duration := flag.Duration("duration", time.Minute, "how long to send requests")
+	concurrency := flag.Int("concurrency", 32, "max requests in flight")
+	mixFlag := flag.String("mix", "hybrid=8,graphrag=2", "endpoint weights")
+	queriesFile := flag.String("queries", "", "file with one query per line")
+	experiment := flag.String("experiment", "", "search experiment for hybrid requests")
+	timeout := flag.Duration("timeout", 2*time.Minute, "per-request timeout")
+	asJSON := flag.Bool("json", false, "print JSON")
+	flag.Parse()
+
+	if *rps <= 0 || *concurrency < 1 {
+		log.Fatal("-rps and -concurrency must be positive")
+	}
+	mix, err := parseMix(*mixFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	queries := defaultQueries
+	if *queriesFile != "" {
+		if queries, err = readQueries(*queriesFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	c := &client{
+		base:       strings.TrimRight(*api, "/"),
+		apiKey:     os.Getenv("API_KEY"),
+		experiment: *experiment,
+		http:       &http.Client{Timeout: *timeout},
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, *duration)
+	defer cancel()
+
+	log.Printf("sending %.1f req/s to %s for %s (mix %s, %d queries)", *rps, c.base, *duration, *mixFlag, len(queries))
+
+	var (
+		mu      sync.Mutex
+		results []result
+		wg      sync.WaitGroup
+		dropped int
+	)
+	inflight := make(chan struct{}, *concurrency)
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / *rps))
+	defer ticker.Stop()
+	start := time.Now()
+
+send:
+	for {
+		select {
+		case <-ctx.Done():
+			break send
+		case <-ticker.C:
+		}
+		select {
+		case inflight <- struct{}{}:
+		default:
+			dropped++
+			continue
+		}
+		endpoint := pick(mix)
+		query := queries[rand.IntN(len(queries))]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-inflight }()
+			// Requests outlive the send window; only -timeout cuts them off.
+			res := c.search(context.Background(), endpoint, query)
+			mu.Lock()
+			results = append(results, res)
+			mu.Unlock()
+		}()
+	}
+	sendTime := time.Since(start)
+	log.Printf("waiting for %d requests in flight...", len(inflight))
+	wg.Wait()
+
+	rep := buildReport(results, dropped, sendTime)
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(rep); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	rep.print(os.Stdout)
+}
+
+// parseMix parses "hybrid=8,graphrag=2".
+func parseMix(s string) ([]target, error) {
+	var mix []target
+	for _, part := range strings.Split(s, ",") {
+		name, w, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			name, w = part, "1"
+		}
+		if _, known := endpoints[name]; !known {
+			return nil, fmt.Errorf("-mix: unknown endpoint %q (want hybrid or graphrag)", name)
+		}
+		weight, err := strconv.Atoi(w)
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("-mix: invalid weight %q for %s", w, name)
+		}
+		if weight > 0 {
+			mix = append(mix, target{name, weight})
+		}
+	}
+	if len(mix) == 0 {
+		return nil, fmt.Errorf("-mix: no endpoint with a positive weight")
+	}
+	return mix, nil
+}
+
+func pick(mix []target) string {
+	total := 0
+	for _, t := range mix {
+		total += t.weight
+	}
+	n := rand.IntN(total)
+	for _, t := range mix {
+		if n < t.weight {
+			return t.name
+		}
+		n -= t.weight
+	}
+	return mix[len(mix)-1].name
+}
+
+func readQueries(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var queries []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if q := strings.TrimSpace(sc.Text()); q != "" && !strings.HasPrefix(q, "#") {
+			queries = append(queries, q)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(queries) == 0 {
+		return nil, fmt.Errorf("%s: no queries", path)
+	}
+	return queries, nil
+}
+
+type client struct {
+	base       string
+	apiKey     string
+	experiment string
+	http       *http.Client
+}
+
+func (c *client) search(ctx context.Context, endpoint, query string) result {
+	res := result{endpoint: endpoint}
+	body := map[string]any{"query": query}
+	if endpoint == "hybrid" && c.experiment != "" {
+		body["experiment"] = c.experiment
+	}
+	data, _ := json.Marshal(body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.base+endpoints[endpoint], bytes.NewReader(data))
+	if err != nil {
+		return res
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+
+	start := time.Now()
+	resp, err := c.http.Do(req)
+	if err != nil {
+		res.latency = time.Since(start)
+		return res
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	res.latency = time.Since(start)
+	if err != nil {
+		return res
+	}
+	res.status = resp.StatusCode
+
+	if resp.StatusCode == http.StatusOK && endpoint == "hybrid" {
+		var out struct {
+			CacheHit bool             `json:"cache_hit"`
+			Stages   map[string]int64 `json:"stage_latency_ms"`
+			Sources  map[string]int64 `json:"source_latency_ms"`
+		}
+		if json.Unmarshal(respBody, &out) == nil {
+			res.cacheHit, res.stages, res.sources = out.CacheHit, out.Stages, out.Sources
+		}
+	}
+	return res
+}
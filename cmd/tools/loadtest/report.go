@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// percentiles summarises latencies in milliseconds.
+type percentiles struct {
+	Count int     `json:"count"`
+	P50   float64 `json:"p50"`
+	P95   float64 `json:"p95"`
+	P99   float64 `json:"p99"`
+	Max   float64 `json:"max"`
+}
+
+func summarize(ms []float64) percentiles {
+	if len(ms) == 0 {
+		return percentiles{}
+	}
+	sort.Float64s(ms)
+	// Nearest rank: the smallest value with at least p% of samples at or below it.
+	rank := func(p float64) float64 {
+		i := int(p*float64(len(ms))+0.999999) - 1
+		return ms[max(0, min(i, len(ms)-1))]
+	}
+	return percentiles{Count: len(ms), P50: rank(0.50), P95: rank(0.95), P99: rank(0.99), Max: ms[len(ms)-1]}
+}
+
+type endpointReport struct {
+	Endpoint  string                 `json:"endpoint"`
+	Requests  int                    `json:"requests"`
+	OK        int                    `json:"ok"`
+	CacheHits int                    `json:"cache_hits,omitempty"`
+	Status    map[string]int         `json:"status"` // HTTP status, or "error" without a response
+	Latency   percentiles            `json:"latency_ms"`
+	Stages    map[string]percentiles `json:"stage_latency_ms,omitempty"`
+	Sources   map[string]percentiles `json:"source_latency_ms,omitempty"`
+}
+
+type report struct {
+	Sent        int              `json:"sent"`
+	Dropped     int              `json:"dropped"`
+	SendTime    string           `json:"send_time"`
+	AchievedRPS float64          `json:"achieved_rps"`
+	Endpoints   []endpointReport `json:"endpoints"`
+}
+
+// buildReport groups results by endpoint. Latency percentiles cover
+// successful requests only; stage and source latencies only cache misses,
+// since a semantic cache hit skips everything after the query embedding.
+func buildReport(results []result, dropped int, sendTime time.Duration) *report {
+	rep := &report{
+		Sent:        len(results),
+		Dropped:     dropped,
+		SendTime:    sendTime.Round(time.Millisecond).String(),
+		AchievedRPS: float64(len(results)) / sendTime.Seconds(),
+	}
+
+	byEndpoint := make(map[string][]result)
+	for _, r := range results {
+		byEndpoint[r.endpoint] = append(byEndpoint[r.endpoint], r)
+	}
+	names := make([]string, 0, len(byEndpoint))
+	for name := range byEndpoint {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		er := endpointReport{Endpoint: name, Status: make(map[string]int)}
+		var latencies []float64
+		stages := make(map[string][]float64)
+		sources := make(map[string][]float64)
+		for _, r := range byEndpoint[name] {
+			er.Requests++
+			status := "error"
+			if r.status != 0 {
+				status = strconv.Itoa(r.status)
+			}
+			er.Status[status]++
+			if r.status != 200 {
+				continue
+			}
+			er.OK++
+			latencies = append(latencies, float64(r.latency)/float64(time.Millisecond))
+			if r.cacheHit {
+				er.CacheHits++
+				continue
+			}
+			for stage, ms := range r.stages {
+				stages[stage] = append(stages[stage], float64(ms))
+			}
+			for src, ms := range r.sources {
+				sources[src] = append(sources[src], float64(ms))
+			}
+		}
+		er.Latency = summarize(latencies)
+		if len(stages) > 0 {
+			er.Stages = make(map[string]percentiles, len(stages))
+			for stage, ms := range stages {
+				er.Stages[stage] = summarize(ms)
+			}
+		}
+		if len(sources) > 0 {
+			er.Sources = make(map[string]percentiles, len(sources))
+			for src, ms := range sources {
+				er.Sources[src] = summarize(ms)
+			}
+		}
+		rep.Endpoints = append(rep.Endpoints, er)
+	}
+	return rep
+}
+
+// stageOrder lists hybrid stages in pipeline order for printing.
+var stageOrder = []string{"embedding", "analysis", "retrieval", "fusion", "rerank"}
+
+func (r *report) print(w io.Writer) {
+	fmt.Fprintf(w, "sent %d in %s (%.2f req/s), dropped %d at the concurrency limit\n\n",
+		r.Sent, r.SendTime, r.AchievedRPS, r.Dropped)
+	fmt.Fprintf(w, "%-22s %7s %7s %9s %9s %9s %9s\n", "", "n", "ok", "p50 ms", "p95 ms", "p99 ms", "max ms")
+	line := func(label string, okCount int, p percentiles) {
+		fmt.Fprintf(w, "%-22s %7d %7d %9.0f %9.0f %9.0f %9.0f\n", label, p.Count, okCount, p.P50, p.P95, p.P99, p.Max)
+	}
+	for _, e := range r.Endpoints {
+		line(e.Endpoint, e.OK, e.Latency)
+		for _, stage := range stageOrder {
+			if p, ok := e.Stages[stage]; ok {
+				line("  stage "+stage, p.Count, p)
+			}
+		}
+		srcs := make([]string, 0, len(e.Sources))
+		for src := range e.Sources {
+			srcs = append(srcs, src)
+		}
+		sort.Strings(srcs)
+		for _, src := range srcs {
+			line("  source "+src, e.Sources[src].Count, e.Sources[src])
+		}
+		codes := make([]string, 0, len(e.Status))
+		for code := range e.Status {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		fmt.Fprintf(w, "  status:")
+		for _, code := range codes {
+			fmt.Fprintf(w, " %s=%d", code, e.Status[code])
+		}
+		if e.CacheHits > 0 {
+			fmt.Fprintf(w, "  (%d semantic cache hits, excluded from stages)", e.CacheHits)
+		}
+		fmt.Fprintln(w)
+	}
+}
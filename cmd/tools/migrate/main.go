@@ -0,0 +1,73 @@
+// migrate applies or inspects the embedded schema migrations (migrations/*.sql).
+// The API server runs "up" on startup unless AUTO_MIGRATE=false; use this tool
+// when auto-migration is off or to check what's applied.
+//
+// Usage:
+//
+//	go run ./cmd/tools/migrate/ [-action up|status|down-to] [-version N]
+//
+// Flags:
+//
+//	-action   up (default): apply pending migrations
+//	          status: list migrations and whether each is applied
+//	          down-to: roll back to -version (exclusive)
+//	-version  Target version for down-to
+//
+// Required env vars: DATABASE_URL
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"cv-search/internal/storage"
+)
+
+func main() {
+	action := flag.String("action", "up", "up | status | down-to")
+	version := flag.Int64("version", -1, "Target version for down-to")
+	flag.Parse()
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		log.Fatal("DATABASE_URL is required")
+	}
+
+	db, err := storage.NewDB(dbURL)
+	if err != nil {
+		log.Fatalf("db open: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	switch *action {
+	case "up":
+		if err := db.Migrate(ctx); err != nil {
+			log.Fatalf("migrate: %v", err)
+		}
+	case "status":
+		statuses, err := db.MigrationStatuses(ctx)
+		if err != nil {
+			log.Fatalf("status: %v", err)
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied"
+			}
+			fmt.Printf("%05d  %-8s  %s\n", s.Version, state, s.Path)
+		}
+	case "down-to":
+		if *version < 0 {
+			log.Fatal("-version is required for down-to")
+		}
+		if err := db.MigrateDownTo(ctx, *version); err != nil {
+			log.Fatalf("migrate down: %v", err)
+		}
+	default:
+		log.Fatalf("unknown -action %q (want up, status or down-to)", *action)
+	}
+}
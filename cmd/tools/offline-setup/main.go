@@ -0,0 +1,252 @@
+// offline-setup prepares a deployment that runs without internet access,
+// with Ollama for both CV extraction and embeddings. It checks that the
+// models are pulled, runs one extraction and one embedding through them,
+// creates the DB extensions and tables, sizes the embedding columns to the
+// embedding model and records the deployment as offline-capable (the API
+// warns at startup when its config no longer matches).
+//
+// Usage:
+//
+//	go run ./cmd/tools/offline-setup/ [flags]
+//
+// Flags:
+//
+//	-llm-model        Ollama model for extraction (default LLM_MODEL when LLM_PROVIDER=ollama, else llama3.1:8b)
+//	-embedding-model  Ollama embedding model (default EMBEDDING_MODEL when EMBEDDING_PROVIDER=ollama, else nomic-embed-text)
+//	-check            Only verify; don't change the database
+//
+// Run it on a fresh database: the embedding columns can only be resized
+// while they are empty (otherwise switch models with cmd/tools/reembed).
+// The API, seed and reembed work fully offline afterwards; eval,
+// detect_communities and reprocess_cvs still call hosted providers.
+//
+// Required env vars: DATABASE_URL, OLLAMA_URL (default http://localhost:11434);
+// OLLAMA_API_KEY for an Ollama behind an authenticating proxy
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"cv-search/internal/config"
+	"cv-search/internal/graphrag"
+	"cv-search/internal/llm"
+	"cv-search/internal/storage"
+)
+
+// defaultLLMModel is suggested when LLM_PROVIDER isn't ollama yet.
+const defaultLLMModel = "llama3.1:8b"
+
+// extensions are the Postgres extensions the migrations create; they have
+// to be installed on the server beforehand.
+var extensions = []string{"vector", "pg_trgm", "unaccent"}
+
+// sampleCV is extracted as the smoke test.
+const sampleCV = `Ayşe Demir
+Senior Backend Developer | İstanbul
+ayse.demir@example.com
+
+EXPERIENCE
+Senior Backend Developer — Trendyol (2019 – present)
+- Built order services in Go and PostgreSQL on Kubernetes.
+
+EDUCATION
+Bachelor's Degree in Computer Engineering, Istanbul Technical University (2015)
+
+SKILLS
+Go, PostgreSQL, Kubernetes, Docker, Kafka`
+
+func main() {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defaultLLM := defaultLLMModel
+	if cfg.LLMProvider == "ollama" {
+		defaultLLM = cfg.LLMModel
+	}
+	defaultEmbedding := graphrag.DefaultOllamaEmbeddingModel
+	if cfg.EmbeddingProvider == "ollama" {
+		defaultEmbedding = cfg.EmbeddingModel
+	}
+	llmModel := flag.String("llm-model", defaultLLM, "Ollama model for extraction")
+	embeddingModel := flag.String("embedding-model", defaultEmbedding, "Ollama embedding model")
+	checkOnly := flag.Bool("check", false, "only verify; don't change the database")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// 1. Models pulled
+	log.Printf("checking models on %s", cfg.OllamaURL)
+	if err := checkModels(ctx, cfg.OllamaURL, cfg.OllamaAPIKey, *llmModel, *embeddingModel); err != nil {
+		log.Fatal(err)
+	}
+
+	// 2. Smoke extraction
+	llmKey := cfg.OllamaAPIKey
+	if cfg.LLMProvider == "ollama" && cfg.LLMAPIKey != "" {
+		llmKey = cfg.LLMAPIKey
+	}
+	llmSvc := llm.NewService("ollama", llmKey, *llmModel)
+	llmSvc.SetBaseURL(cfg.OllamaURL)
+	start := time.Now()
+	extraction, err := llmSvc.ExtractEntities(ctx, sampleCV)
+	if err != nil {
+		log.Fatalf("extraction with %s: %v", *llmModel, err)
+	}
+	if extraction.Candidate.Name == "" || len(extraction.Skills) == 0 {
+		log.Fatalf("extraction with %s returned no name or skills; try a larger model", *llmModel)
+	}
+	log.Printf("extraction OK in %s: %s, %d skills", time.Since(start).Round(time.Millisecond), extraction.Candidate.Name, len(extraction.Skills))
+
+	// 3. Smoke embedding
+	db, err := storage.NewDB(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("DB: %v", err)
+	}
+	defer db.Close()
+	embeddings := graphrag.NewEmbeddingService("", db.GetConnection())
+	embeddings.UseOllama(cfg.OllamaURL, cfg.OllamaAPIKey)
+	embeddings.SetModel(*embeddingModel, 0)
+	start = time.Now()
+	vec, err := embeddings.GenerateEmbedding(ctx, "Senior Go developer in İstanbul")
+	if err != nil {
+		log.Fatalf("embedding with %s: %v", *embeddingModel, err)
+	}
+	dims := len(vec)
+	log.Printf("embedding OK in %s: %d dimensions", time.Since(start).Round(time.Millisecond), dims)
+
+	// 4. Extensions and schema
+	if err := checkExtensions(ctx, db); err != nil {
+		log.Fatal(err)
+	}
+	if *checkOnly {
+		log.Println("extensions available; -check: not migrating")
+	} else if err := db.Migrate(ctx); err != nil {
+		log.Fatalf("migrate: %v", err)
+	}
+
+	// 5. Embedding column size
+	current, err := embeddings.EmbeddingDimensions(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	switch {
+	case current == dims:
+		log.Printf("embedding columns already hold %d dimensions", dims)
+	case *checkOnly:
+		log.Fatalf("embedding columns hold %d dimensions, %s gives %d; run without -check to resize", current, *embeddingModel, dims)
+	default:
+		if err := embeddings.ResizeEmbeddings(ctx, dims); err != nil {
+			log.Fatalf("resize embedding columns: %v", err)
+		}
+		log.Printf("embedding columns resized from %d to %d dimensions", current, dims)
+	}
+
+	// 6. Record the deployment
+	if *checkOnly {
+		log.Println("-check: all checks passed, deployment not recorded")
+	} else {
+		err := db.SetDeploymentSetting(ctx, storage.DeploymentSettingOffline, storage.OfflineDeployment{
+			OllamaURL:           cfg.OllamaURL,
+			LLMModel:            *llmModel,
+			EmbeddingModel:      *embeddingModel,
+			EmbeddingDimensions: dims,
+			VerifiedAt:          time.Now().UTC(),
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Println("deployment recorded as offline-capable")
+	}
+
+	fmt.Printf(`
+Set these for the API and tools:
+
+  LLM_PROVIDER=ollama
+  LLM_MODEL=%s
+  EMBEDDING_PROVIDER=ollama
+  EMBEDDING_MODEL=%s
+  OLLAMA_URL=%s
+`, *llmModel, *embeddingModel, cfg.OllamaURL)
+}
+
+// checkModels fails, with the pull commands to run, unless every model is
+// on the Ollama server. A name without a tag matches its ":latest".
+func checkModels(ctx context.Context, baseURL, apiKey string, models ...string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/api/tags", nil)
+	if err != nil {
+		return err
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	resp, err := (&http.Client{Timeout: 30 * time.Second}).Do(req)
+	if err != nil {
+		return fmt.Errorf("reach Ollama: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("list Ollama models: status %d", resp.StatusCode)
+	}
+	var tags struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return fmt.Errorf("decode Ollama models: %w", err)
+	}
+	pulled := make(map[string]bool, len(tags.Models))
+	for _, m := range tags.Models {
+		pulled[m.Name] = true
+	}
+
+	var missing []string
+	for _, m := range models {
+		if pulled[m] || (!strings.Contains(m, ":") && pulled[m+":latest"]) {
+			continue
+		}
+		missing = append(missing, m)
+	}
+	if len(missing) > 0 {
+		fmt.Fprintln(os.Stderr, "Pull the missing models (on a machine with internet access, then copy ~/.ollama/models over):")
+		for _, m := range missing {
+			fmt.Fprintf(os.Stderr, "  ollama pull %s\n", m)
+		}
+		return fmt.Errorf("models not pulled: %s", strings.Join(missing, ", "))
+	}
+	log.Printf("models pulled: %s", strings.Join(models, ", "))
+	return nil
+}
+
+// checkExtensions fails unless the server has every extension installed;
+// CREATE EXTENSION in the migrations can't download them.
+func checkExtensions(ctx context.Context, db *storage.DB) error {
+	var missing []string
+	for _, ext := range extensions {
+		var available bool
+		err := db.GetConnection().QueryRowContext(ctx,
+			`SELECT EXISTS (SELECT 1 FROM pg_available_extensions WHERE name = $1)`, ext).Scan(&available)
+		if err != nil {
+			return fmt.Errorf("check extension %s: %w", ext, err)
+		}
+		if !available {
+			missing = append(missing, ext)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("extensions not installed on the Postgres server: %s (vector is pgvector; pg_trgm and unaccent come with postgresql-contrib)", strings.Join(missing, ", "))
+	}
+	return nil
+}
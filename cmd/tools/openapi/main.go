@@ -0,0 +1,52 @@
+// openapi writes the OpenAPI 3 document the API serves at /openapi.json to a
+// file, for clients and code generators that want it without a running
+// server. The document is generated from the handlers' request/response
+// types (internal/api/openapi.go), so rerun this after changing them.
+//
+// Usage:
+//
+//	go run ./cmd/tools/openapi/ [-out docs/openapi.json] [-check]
+//
+// Flags:
+//
+//	-out    File to write (default docs/openapi.json)
+//	-check  Don't write; exit 1 if the file differs from the generated document
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"cv-search/internal/api"
+)
+
+func main() {
+	out := flag.String("out", "docs/openapi.json", "File to write")
+	check := flag.Bool("check", false, "Exit 1 if the file is out of date instead of writing it")
+	flag.Parse()
+
+	data, err := json.MarshalIndent(api.OpenAPISpec(), "", "  ")
+	if err != nil {
+		log.Fatalf("encode: %v", err)
+	}
+	data = append(data, '\n')
+
+	if *check {
+		current, err := os.ReadFile(*out)
+		if err != nil {
+			log.Fatalf("read %s: %v", *out, err)
+		}
+		if !bytes.Equal(current, data) {
+			log.Fatalf("%s is out of date; run go run ./cmd/tools/openapi/", *out)
+		}
+		return
+	}
+
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		log.Fatalf("write %s: %v", *out, err)
+	}
+	log.Printf("wrote %s", *out)
+}
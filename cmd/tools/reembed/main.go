@@ -0,0 +1,196 @@
+// reembed moves every stored vector — graph nodes, CV chunks, community
+// summaries — to a new embedding model without taking vector search down.
+// The new vectors go into shadow columns next to the live ones (see
+// graphrag/reembed.go), are checked against a labeled query set and only
+// then swapped in, atomically.
+//
+// Usage:
+//
+//	go run ./cmd/tools/reembed/ -model text-embedding-3-large -dimensions 1536            # re-embed (resumable)
+//	go run ./cmd/tools/reembed/ -model ... -queries cmd/tools/eval/queries.example.yaml   # + compare with live
+//	go run ./cmd/tools/reembed/ -model ... -queries ... -swap                             # + swap if no worse
+//	go run ./cmd/tools/reembed/ -drop-shadow                                              # once settled
+//
+// Flags:
+//
+//	-model        New embedding model (required)
+//	-provider     Provider of the new model: openai or ollama (default EMBEDDING_PROVIDER)
+//	-dimensions   Output dimensions for models that support it (default: the model's)
+//	-batch        Texts per embeddings request (default 64)
+//	-status       Only report progress
+//	-queries      eval query set; compares vector search over live and shadow vectors
+//	-top-k        Vector search results per query (default 50)
+//	-max-drop     Largest nDCG drop at the set's biggest k that still allows -swap (default 0.02)
+//	-swap         Make the shadow vectors live (needs a passing -queries run, or -force)
+//	-force        Swap without validation
+//	-drop-shadow  Drop the shadow columns: the old vectors after a swap
+//
+// Re-runs only embed rows that are missing or changed since, so the tool can
+// be stopped at any time, and -swap does a final catch-up pass. After the
+// swap, restart the API with EMBEDDING_MODEL / EMBEDDING_DIMENSIONS set to
+// the new model: until then it embeds queries with the old one. The old
+// vectors stay in the shadow columns, so running -swap with the old model
+// rolls back. HNSW indexes take at most 2000 dimensions, and the hybrid
+// community-match threshold was tuned on text-embedding-3-small; check it
+// after switching.
+//
+// Required env vars: DATABASE_URL, OPENAI_API_KEY (or OLLAMA_URL for Ollama);
+// EMBEDDING_PROVIDER / EMBEDDING_MODEL / EMBEDDING_DIMENSIONS for the live
+// model when it isn't the default
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+
+	"cv-search/internal/config"
+	"cv-search/internal/eval"
+	"cv-search/internal/graphrag"
+	"cv-search/internal/storage"
+)
+
+func main() {
+	model := flag.String("model", "", "new embedding model")
+	provider := flag.String("provider", "", "provider of the new model: openai or ollama (default EMBEDDING_PROVIDER)")
+	dimensions := flag.Int("dimensions", 0, "output dimensions (0 = the model's default)")
+	batch := flag.Int("batch", 64, "texts per embeddings request")
+	statusOnly := flag.Bool("status", false, "only report progress")
+	queriesPath := flag.String("queries", "", "eval query set to validate with")
+	topK := flag.Int("top-k", 50, "vector search results per query")
+	maxDrop := flag.Float64("max-drop", 0.02, "largest nDCG drop that still allows -swap")
+	swap := flag.Bool("swap", false, "make the shadow vectors live")
+	force := flag.Bool("force", false, "swap without validation")
+	dropShadow := flag.Bool("drop-shadow", false, "drop the shadow columns")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *provider == "" {
+		*provider = cfg.EmbeddingProvider
+	}
+	if *provider != "openai" && *provider != "ollama" {
+		log.Fatalf("-provider: unknown provider %q (want openai or ollama)", *provider)
+	}
+	if cfg.OpenAIAPIKey == "" && (*provider == "openai" || cfg.EmbeddingProvider == "openai") {
+		log.Fatal("OPENAI_API_KEY is required")
+	}
+	db, err := storage.NewDB(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("DB: %v", err)
+	}
+	defer db.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	next := graphrag.NewEmbeddingService(cfg.OpenAIAPIKey, db.GetConnection())
+	if *dropShadow {
+		if err := next.DropShadow(ctx); err != nil {
+			log.Fatal(err)
+		}
+		log.Println("shadow columns dropped")
+		return
+	}
+	if *model == "" {
+		log.Fatal("-model is required")
+	}
+	if *provider == "ollama" {
+		next.UseOllama(cfg.OllamaURL, cfg.OllamaAPIKey)
+	}
+	next.SetModel(*model, *dimensions)
+
+	if !*statusOnly {
+		probe, err := next.GenerateEmbedding(ctx, "dimension probe")
+		if err != nil {
+			log.Fatalf("embed with %s: %v", *model, err)
+		}
+		log.Printf("%s gives %d-dimensional vectors", *model, len(probe))
+		if err := next.PrepareShadow(ctx, len(probe)); err != nil {
+			log.Fatal(err)
+		}
+		if err := next.EmbedShadow(ctx, *batch); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	progress, err := next.ShadowStatus(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%-18s %8s %8s\n", "table", "live", "pending")
+	for _, p := range progress {
+		fmt.Printf("%-18s %8d %8d\n", p.Table, p.Live, p.Pending)
+	}
+	if *statusOnly {
+		return
+	}
+
+	validated := false
+	if *queriesPath != "" {
+		validated = validate(ctx, db, cfg, next, *queriesPath, *topK, *maxDrop)
+	}
+
+	if !*swap {
+		return
+	}
+	if !validated && !*force {
+		log.Fatal("not swapping: validate with -queries first (or pass -force)")
+	}
+	// Catch up on rows written since the pass above.
+	if err := next.EmbedShadow(ctx, *batch); err != nil {
+		log.Fatal(err)
+	}
+	if err := next.SwapShadow(ctx); err != nil {
+		log.Fatalf("swap: %v", err)
+	}
+	dims := "the model's default"
+	if *dimensions > 0 {
+		dims = fmt.Sprint(*dimensions)
+	}
+	log.Printf("swapped: %s vectors are live. Restart the API with EMBEDDING_PROVIDER=%s, EMBEDDING_MODEL=%s and EMBEDDING_DIMENSIONS=%s now; "+
+		"the old vectors stay in the shadow columns until -drop-shadow", *model, *provider, *model, dims)
+}
+
+// validate runs the query set through vector search over the live vectors
+// (live model) and over the shadow ones (new model), and reports whether
+// the shadow's nDCG at the set's largest k is at most maxDrop lower.
+func validate(ctx context.Context, db *storage.DB, cfg *config.Config, next *graphrag.EmbeddingService, path string, topK int, maxDrop float64) bool {
+	qs, err := eval.LoadQuerySet(path)
+	if err != nil {
+		log.Fatalf("load query set: %v", err)
+	}
+	live := graphrag.NewEmbeddingService(cfg.OpenAIAPIKey, db.GetConnection())
+	if cfg.EmbeddingProvider == "ollama" {
+		live.UseOllama(cfg.OllamaURL, cfg.OllamaAPIKey)
+	}
+	live.SetModel(cfg.EmbeddingModel, cfg.EmbeddingDimensions)
+
+	report := eval.Run(ctx, qs, []eval.Engine{
+		&eval.VectorEngine{Label: "live", Similar: live.SimilaritySearch, TopK: topK, DB: db},
+		&eval.VectorEngine{Label: "shadow", Similar: next.ShadowSimilaritySearch, TopK: topK, DB: db},
+	})
+	fmt.Printf("\nlive: %s, shadow: %s\n", live.Model(), next.Model())
+	report.WriteTable(os.Stdout)
+
+	k := slices.Max(qs.K)
+	liveRep, shadowRep := report.Engines[0], report.Engines[1]
+	drop := liveRep.MeanNDCG[k] - shadowRep.MeanNDCG[k]
+	switch {
+	case shadowRep.Failed > 0:
+		log.Printf("validation failed: %d shadow queries failed", shadowRep.Failed)
+		return false
+	case drop > maxDrop:
+		log.Printf("validation failed: nDCG@%d drops by %.3f (max %.3f)", k, drop, maxDrop)
+		return false
+	}
+	log.Printf("validation passed: nDCG@%d %.3f → %.3f", k, liveRep.MeanNDCG[k], shadowRep.MeanNDCG[k])
+	return true
+}
@@ -0,0 +1,93 @@
+// repair_properties normalises graph node properties written before typed
+// property schemas existed: null values are dropped, numeric strings such as
+// "5+" or "2019" become numbers, and cv_id is stored as a number. Keys outside
+// the person/skill/company/education schemas are kept as they are.
+//
+// With -locations it also resolves person locations (migration 00029): the
+// node's location text, else its candidate's, is looked up in the location
+// aliases and the canonical location and its coordinates are stored on the
+// node. Texts no alias matches are listed, for adding aliases.
+//
+// With -experience it also computes person nodes' experience years from
+// their WORKS_AT / WORKED_AT date ranges; total_experience_years becomes the
+// computed value and the LLM's is kept as experience_years_llm.
+//
+// Usage:
+//
+//	go run ./cmd/tools/repair_properties/ [flags]
+//
+// Flags:
+//
+//	-dry-run    Only report how many nodes would change (default true)
+//	-locations  Also resolve person locations (default false)
+//	-experience Also compute person experience years (default false)
+//
+// Required env vars: DATABASE_URL
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"cv-search/internal/config"
+	"cv-search/internal/graphrag"
+	"cv-search/internal/storage"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", true, "only report")
+	locations := flag.Bool("locations", false, "also resolve person locations")
+	experience := flag.Bool("experience", false, "also compute person experience years")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	db, err := storage.NewDB(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("DB: %v", err)
+	}
+	defer db.Close()
+
+	builder := graphrag.NewGraphBuilder(db.GetConnection())
+	rep, err := builder.RepairNodeProperties(context.Background(), *dryRun)
+	if err != nil {
+		log.Fatalf("repair failed: %v", err)
+	}
+
+	prefix := ""
+	if *dryRun {
+		prefix = "[DRY RUN] would repair: "
+	}
+	log.Printf("%sscanned=%d repaired=%d by_type=%v invalid=%d",
+		prefix, rep.Scanned, rep.Repaired, rep.ByType, len(rep.Invalid))
+
+	if *locations {
+		locRep, err := builder.BackfillPersonLocations(context.Background(), *dryRun)
+		if err != nil {
+			log.Fatalf("location backfill failed: %v", err)
+		}
+		if *dryRun {
+			prefix = "[DRY RUN] would resolve: "
+		}
+		log.Printf("%slocations scanned=%d resolved=%d unresolved=%d",
+			prefix, locRep.Scanned, locRep.Resolved, len(locRep.Unresolved))
+		for _, text := range locRep.Unresolved {
+			log.Printf("  unresolved: %q", text)
+		}
+	}
+
+	if *experience {
+		expRep, err := builder.RecomputeExperienceYears(context.Background(), *dryRun)
+		if err != nil {
+			log.Fatalf("experience recompute failed: %v", err)
+		}
+		if *dryRun {
+			prefix = "[DRY RUN] would compute: "
+		}
+		log.Printf("%sexperience scanned=%d computed=%d changed=%d",
+			prefix, expRep.Scanned, expRep.Computed, expRep.Changed)
+	}
+}
@@ -11,13 +11,16 @@ import (
 	"cv-search/internal/llm"
 	"cv-search/internal/reprocess"
 	"cv-search/internal/storage"
+	"cv-search/internal/tenant"
 )
 
 func main() {
 	var dryRun bool
 	var onlyCandidateID int
+	var orgID int
 	flag.BoolVar(&dryRun, "dry-run", true, "only report")
 	flag.IntVar(&onlyCandidateID, "candidate-id", 0, "specific candidate")
+	flag.IntVar(&orgID, "org", tenant.DefaultOrgID, "organization")
 	flag.Parse()
 
 	dbURL := os.Getenv("DATABASE_URL")
@@ -55,8 +58,10 @@ func main() {
 	llmSvc := llm.NewService(llmProvider, llmAPIKey, llmModel)
 	graphBuilder := graphrag.NewGraphBuilder(db.GetConnection())
 	embeddingSvc := graphrag.NewEmbeddingService(openaiKey, db.GetConnection())
+	dims, _ := strconv.Atoi(os.Getenv("EMBEDDING_DIMENSIONS"))
+	embeddingSvc.SetModel(os.Getenv("EMBEDDING_MODEL"), dims)
 
-	ctx := context.Background()
+	ctx := tenant.WithOrg(context.Background(), orgID)
 
 	opts := reprocess.Options{
 		DryRun:          dryRun,
@@ -0,0 +1,88 @@
+// restore loads an archive written by cmd/tools/export into the database,
+// in one transaction. The database must already be migrated to the
+// snapshot's schema version (cmd/tools/migrate) and, without -replace, hold
+// no candidates, CVs or graph data yet.
+//
+// Usage:
+//
+//	go run ./cmd/tools/restore/ [flags] ARCHIVE
+//
+// Flags:
+//
+//	-replace   Truncate the snapshot tables first — and, by cascade, upload
+//	           jobs, scores and merges that reference them
+//	-info      Only print the archive's manifest
+//
+// Required env vars: DATABASE_URL
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"cv-search/internal/snapshot"
+	"cv-search/internal/storage"
+)
+
+func main() {
+	replace := flag.Bool("replace", false, "overwrite existing data")
+	info := flag.Bool("info", false, "only print the manifest")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: restore [-replace] [-info] ARCHIVE")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	f, err := os.Open(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *info {
+		m, err := snapshot.ReadManifest(f, st.Size())
+		if err != nil {
+			log.Fatal(err)
+		}
+		printManifest(m)
+		return
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		log.Fatal("DATABASE_URL is required")
+	}
+	db, err := storage.NewDB(dbURL)
+	if err != nil {
+		log.Fatalf("DB: %v", err)
+	}
+	defer db.Close()
+
+	start := time.Now()
+	m, err := snapshot.Restore(context.Background(), db, f, st.Size(), *replace)
+	if err != nil {
+		log.Fatalf("restore failed: %v", err)
+	}
+	printManifest(m)
+	log.Printf("restored %d rows in %s", m.Rows(), time.Since(start).Round(time.Millisecond))
+}
+
+func printManifest(m *snapshot.Manifest) {
+	log.Printf("snapshot of %s, format %d, schema version %d",
+		m.CreatedAt.Format(time.RFC3339), m.FormatVersion, m.SchemaVersion)
+	for _, t := range m.Tables {
+		log.Printf("  %-18s %d", t.Name, t.Rows)
+	}
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v7"
+
+	"cv-search/internal/graphrag"
+	"cv-search/internal/llm"
+)
+
+// profile is one synthetic candidate: the CV text and what it states, which
+// doubles as the extraction in -canned mode.
+type profile struct {
+	filename   string
+	text       string
+	extraction *llm.CVExtraction
+}
+
+// titles are the positions a community's members hold.
+var titles = map[string][]string{
+	"backend":  {"Backend Developer", "Software Engineer", "Java Developer", "Go Developer", "Python Developer"},
+	"frontend": {"Frontend Developer", "UI Developer", "React Developer", "Web Developer"},
+	"mobile":   {"Mobile Developer", "iOS Developer", "Android Developer", "Flutter Developer"},
+	"devops":   {"DevOps Engineer", "Site Reliability Engineer", "Cloud Engineer", "Platform Engineer"},
+	"data":     {"Data Engineer", "Database Administrator", "Big Data Engineer"},
+	"ml-ai":    {"Machine Learning Engineer", "Data Scientist", "AI Engineer", "NLP Engineer"},
+	"qa-test":  {"QA Engineer", "Test Automation Engineer", "Software Test Specialist"},
+	"analyst":  {"Business Analyst", "Data Analyst", "Product Analyst"},
+}
+
+// certifications a community's members may hold, with their issuers.
+var certifications = map[string][]llm.Certification{
+	"backend":  {{Name: "Oracle Certified Professional: Java SE 17 Developer", Issuer: "Oracle"}},
+	"frontend": {{Name: "Meta Front-End Developer Professional Certificate", Issuer: "Meta"}},
+	"mobile":   {{Name: "Associate Android Developer", Issuer: "Google"}},
+	"devops": {
+		{Name: "AWS Certified Solutions Architect – Associate", Issuer: "Amazon Web Services"},
+		{Name: "Certified Kubernetes Administrator (CKA)", Issuer: "The Linux Foundation"},
+	},
+	"data":    {{Name: "Databricks Certified Data Engineer Associate", Issuer: "Databricks"}},
+	"ml-ai":   {{Name: "TensorFlow Developer Certificate", Issuer: "Google"}},
+	"qa-test": {{Name: "ISTQB Certified Tester Foundation Level", Issuer: "ISTQB"}},
+	"analyst": {{Name: "PSPO I", Issuer: "Scrum.org"}},
+}
+
+var (
+	commonSkills = []string{"Git", "Linux", "REST APIs", "Agile", "Scrum", "Jira", "Docker", "SQL", "Microservices"}
+	locations    = []string{"İstanbul", "İstanbul", "İstanbul", "Ankara", "Ankara", "İzmir", "Bursa", "Antalya", "Kocaeli", "Remote"}
+	universities = []string{
+		"Boğaziçi University", "Middle East Technical University", "Istanbul Technical University",
+		"Bilkent University", "Hacettepe University", "Yıldız Technical University", "Ege University",
+		"Koç University", "Sabancı University", "Dokuz Eylül University", "Gazi University",
+	}
+	fields = []string{
+		"Computer Engineering", "Computer Science", "Software Engineering", "Electrical and Electronics Engineering",
+		"Industrial Engineering", "Mathematics", "Management Information Systems", "Statistics",
+	}
+	englishLevels = []string{"Intermediate", "Advanced", "Advanced", "Fluent"}
+	projectKinds  = []string{
+		"Internal reporting service", "Customer-facing web portal", "Payment integration", "Recommendation engine",
+		"Order event pipeline", "Mobile banking app", "Test automation framework", "Monitoring and alerting setup",
+		"Inventory management system", "Campaign analytics dashboard",
+	}
+)
+
+// generator produces profiles from a seeded faker, so the same -seed gives
+// the same candidates.
+type generator struct {
+	f           *gofakeit.Faker
+	communities []string
+	year        int
+}
+
+func newGenerator(seed uint64) *generator {
+	communities := make([]string, 0, len(graphrag.DefaultCommunities))
+	for id := range graphrag.DefaultCommunities {
+		communities = append(communities, id)
+	}
+	sort.Strings(communities)
+	return &generator{f: gofakeit.New(seed), communities: communities, year: time.Now().Year()}
+}
+
+// pick returns n distinct elements of from in random order.
+func (g *generator) pick(from []string, n int) []string {
+	s := append([]string(nil), from...)
+	g.f.ShuffleStrings(s)
+	return s[:min(n, len(s))]
+}
+
+// profile returns the i-th candidate. Candidates cycle through the
+// communities, so any N covers them evenly; about a third also have skills
+// from a second one.
+func (g *generator) profile(i int) profile {
+	f := g.f
+	community := g.communities[i%len(g.communities)]
+	first, last := f.FirstName(), f.LastName()
+	name := first + " " + last
+	years := f.IntRange(1, 16)
+	title := f.RandomString(titles[community])
+
+	e := &llm.CVExtraction{
+		Candidate: llm.Candidate{
+			Name:                 name,
+			CurrentPosition:      title,
+			Seniority:            seniorityFor(years),
+			TotalExperienceYears: years,
+			// example.com: the addresses can never reach anyone.
+			Email:       strings.ToLower(first+"."+last) + fmt.Sprintf("%d@example.com", i),
+			Phone:       fmt.Sprintf("+90 5%02d %03d %02d %02d", f.IntRange(30, 59), f.IntRange(0, 999), f.IntRange(0, 99), f.IntRange(0, 99)),
+			LinkedInURL: fmt.Sprintf("https://www.linkedin.com/in/%s-%s-%d", strings.ToLower(first), strings.ToLower(last), i),
+		},
+		Locations: []string{f.RandomString(locations)},
+	}
+
+	skills := g.pick(graphrag.DefaultCommunities[community].KeySkills, f.IntRange(4, 7))
+	if f.IntRange(0, 2) == 0 {
+		other := g.communities[(i+f.IntRange(1, len(g.communities)-1))%len(g.communities)]
+		skills = append(skills, g.pick(graphrag.DefaultCommunities[other].KeySkills, 2)...)
+	}
+	skills = append(skills, g.pick(commonSkills, f.IntRange(2, 4))...)
+	seen := make(map[string]bool, len(skills))
+	for _, s := range skills {
+		if seen[strings.ToLower(s)] {
+			continue
+		}
+		seen[strings.ToLower(s)] = true
+		y := float64(f.IntRange(1, years))
+		e.Skills = append(e.Skills, llm.Skill{
+			Name:         s,
+			Proficiency:  proficiencyFor(int(y)),
+			Years:        &y,
+			LastUsedYear: "present",
+			Confidence:   0.9,
+		})
+	}
+
+	// Jobs from the current one backwards, splitting the years of experience.
+	end, left := g.year, years
+	for j := 0; left > 0 && j < 4; j++ {
+		d := left
+		if j < 3 && left > 2 {
+			d = f.IntRange(1, left-1)
+		}
+		pos := title
+		if j > 0 {
+			pos = f.RandomString(titles[community])
+		}
+		job := llm.Company{
+			Name:          f.Company(),
+			Position:      pos,
+			DurationYears: d,
+			StartYear:     end - d,
+			EndYear:       end,
+			IsCurrent:     j == 0,
+			Confidence:    0.9,
+		}
+		if job.IsCurrent {
+			job.EndYear = "present"
+		}
+		e.Companies = append(e.Companies, job)
+		end, left = end-d, left-d
+	}
+
+	degree := "Bachelor's"
+	if f.IntRange(0, 3) == 0 {
+		degree = "Master's"
+	}
+	e.Education = []llm.Education{{
+		Degree:         degree,
+		Field:          f.RandomString(fields),
+		Institution:    f.RandomString(universities),
+		GraduationYear: g.year - years - f.IntRange(0, 2),
+	}}
+
+	e.Languages = []llm.Language{{Name: "Turkish", Proficiency: "Native"}, {Name: "English", Proficiency: f.RandomString(englishLevels)}}
+	if f.IntRange(0, 4) == 0 {
+		e.Languages = append(e.Languages, llm.Language{Name: f.RandomString([]string{"German", "French", "Spanish"}), Proficiency: "Basic"})
+	}
+
+	if certs := certifications[community]; years >= 3 && f.Bool() {
+		c := certs[f.IntRange(0, len(certs)-1)]
+		c.Year = g.year - f.IntRange(0, years-1)
+		e.Certifications = []llm.Certification{c}
+	}
+
+	for j := f.IntRange(1, 2); j > 0; j-- {
+		e.Projects = append(e.Projects, llm.Project{
+			Name:         f.AppName(),
+			Description:  f.RandomString(projectKinds) + " used by " + f.Company() + ".",
+			Role:         f.RandomString([]string{"Developer", "Lead developer", "Team member"}),
+			Impact:       fmt.Sprintf("Cut processing time by %d%%", f.IntRange(10, 70)),
+			Technologies: g.pick(skills, 3),
+			Company:      e.Companies[0].Name,
+		})
+	}
+
+	return profile{
+		filename:   strings.ReplaceAll(name, " ", "_") + "_CV.txt",
+		text:       render(e),
+		extraction: e,
+	}
+}
+
+// render writes e as a plain-text CV with the usual section headings.
+func render(e *llm.CVExtraction) string {
+	c := e.Candidate
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n%s | %s\n%s | %s | %s\n\n", c.Name, c.CurrentPosition, e.Locations[0], c.Email, c.Phone, c.LinkedInURL)
+
+	skillNames := make([]string, len(e.Skills))
+	for i, s := range e.Skills {
+		skillNames[i] = s.Name
+	}
+	fmt.Fprintf(&b, "SUMMARY\n%s %s with %d years of experience, mostly with %s.\n\n",
+		c.Seniority, c.CurrentPosition, c.TotalExperienceYears, strings.Join(skillNames[:min(3, len(skillNames))], ", "))
+
+	b.WriteString("EXPERIENCE\n")
+	for _, job := range e.Companies {
+		fmt.Fprintf(&b, "%s — %s (%v – %v)\n", job.Position, job.Name, job.StartYear, job.EndYear)
+		if job.IsCurrent {
+			fmt.Fprintf(&b, "- Working on %s with %s.\n", e.Projects[0].Name, strings.Join(e.Projects[0].Technologies, ", "))
+		} else {
+			fmt.Fprintf(&b, "- Developed and maintained internal services.\n")
+		}
+	}
+
+	b.WriteString("\nEDUCATION\n")
+	for _, ed := range e.Education {
+		fmt.Fprintf(&b, "%s Degree in %s, %s (%v)\n", ed.Degree, ed.Field, ed.Institution, ed.GraduationYear)
+	}
+
+	b.WriteString("\nSKILLS\n")
+	for _, s := range e.Skills {
+		fmt.Fprintf(&b, "%s (%s, %.0f years)\n", s.Name, s.Proficiency, *s.Years)
+	}
+
+	if len(e.Certifications) > 0 {
+		b.WriteString("\nCERTIFICATIONS\n")
+		for _, cert := range e.Certifications {
+			fmt.Fprintf(&b, "%s — %s, %v\n", cert.Name, cert.Issuer, cert.Year)
+		}
+	}
+
+	b.WriteString("\nPROJECTS\n")
+	for _, p := range e.Projects {
+		fmt.Fprintf(&b, "%s (%s): %s %s. Technologies: %s\n", p.Name, p.Role, p.Description, p.Impact, strings.Join(p.Technologies, ", "))
+	}
+
+	b.WriteString("\nLANGUAGES\n")
+	for _, l := range e.Languages {
+		fmt.Fprintf(&b, "%s (%s)\n", l.Name, l.Proficiency)
+	}
+	return b.String()
+}
+
+func seniorityFor(years int) string {
+	switch {
+	case years <= 2:
+		return "Junior"
+	case years <= 5:
+		return "Mid-level"
+	case years <= 10:
+		return "Senior"
+	default:
+		return "Lead"
+	}
+}
+
+func proficiencyFor(years int) string {
+	switch {
+	case years <= 1:
+		return "Beginner"
+	case years <= 3:
+		return "Intermediate"
+	case years <= 7:
+		return "Advanced"
+	default:
+		return "Expert"
+	}
+}
@@ -0,0 +1,298 @@
+// seed fills the database with synthetic candidates for demos and load
+// tests, so neither needs real CVs (or real PII). Each candidate gets a
+// plain-text CV generated with a faker — positions, skills, employers,
+// education, languages and projects drawn from the communities in
+// graphrag.DefaultCommunities, contact details on example.com — which goes
+// through the same steps as an upload: blob + cv_files row, extraction,
+// cv_entities, graph build, candidate linking and, at the end, node
+// embeddings.
+//
+// Usage:
+//
+//	go run ./cmd/tools/seed/ [flags]
+//
+// Flags:
+//
+//	-n         Candidates to generate (default 50)
+//	-seed      Faker seed; the same seed generates the same candidates (default 1)
+//	-canned    Use the generated profile as the extraction instead of calling the LLM
+//	-embed     Embed nodes without an embedding afterwards (default true; needs OPENAI_API_KEY or EMBEDDING_PROVIDER=ollama)
+//	-org       Organization the candidates are added to (default 1)
+//
+// CVs already in the database (same text) are skipped, so re-running with
+// the same seed only adds what's missing; use another -seed for more people.
+//
+// Required env vars: DATABASE_URL, plus LLM_PROVIDER / its key without
+// -canned, OPENAI_API_KEY for -embed and BLOB_* for a non-local blob backend
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"cv-search/internal/config"
+	"cv-search/internal/cv"
+	"cv-search/internal/graphrag"
+	"cv-search/internal/llm"
+	"cv-search/internal/storage"
+	"cv-search/internal/tenant"
+)
+
+type seeder struct {
+	db     *storage.DB
+	blobs  storage.BlobStore
+	graph  *graphrag.GraphBuilder
+	llm    *llm.Service // nil with -canned
+	canned bool
+}
+
+func main() {
+	n := flag.Int("n", 50, "candidates to generate")
+	seed := flag.Uint64("seed", 1, "faker seed")
+	canned := flag.Bool("canned", false, "use the generated profile as the extraction (no LLM)")
+	embed := flag.Bool("embed", true, "embed nodes without an embedding afterwards")
+	org := flag.Int("org", tenant.DefaultOrgID, "organization the candidates are added to")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	db, err := storage.NewDB(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("DB: %v", err)
+	}
+	defer db.Close()
+
+	blobs, err := storage.NewBlobStore(storage.BlobConfig{
+		Backend:         cfg.BlobBackend,
+		LocalDir:        cfg.UploadsDir,
+		Bucket:          cfg.BlobBucket,
+		Region:          cfg.BlobRegion,
+		Endpoint:        cfg.BlobEndpoint,
+		AccessKeyID:     cfg.BlobAccessKeyID,
+		SecretAccessKey: cfg.BlobSecretAccessKey,
+	})
+	if err != nil {
+		log.Fatalf("blob store: %v", err)
+	}
+
+	s := &seeder{db: db, blobs: blobs, graph: graphrag.NewGraphBuilder(db.GetConnection()), canned: *canned}
+	if !*canned {
+		if cfg.LLMProvider == "none" || (cfg.LLMAPIKey == "" && cfg.LLMProvider != "ollama") {
+			log.Fatal("no LLM configured (set LLM_PROVIDER and its API key), or run with -canned")
+		}
+		s.llm = llm.NewService(cfg.LLMProvider, cfg.LLMAPIKey, cfg.LLMModel)
+		s.llm.SetRPMLimit(cfg.GroqRPMLimit)
+		s.llm.SetBaseURL(cfg.OllamaURL)
+	}
+	if *embed && cfg.OpenAIAPIKey == "" && cfg.EmbeddingProvider != "ollama" {
+		log.Fatal("OPENAI_API_KEY is required to embed nodes (or run with -embed=false)")
+	}
+
+	ctx, stop := signal.NotifyContext(tenant.WithOrg(context.Background(), *org), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	gen := newGenerator(*seed)
+	start := time.Now()
+	var added, skipped, failed int
+	for i := 0; i < *n && ctx.Err() == nil; i++ {
+		p := gen.profile(i)
+		switch err := s.add(ctx, p); {
+		case errors.Is(err, errExists):
+			skipped++
+		case err != nil:
+			log.Printf("%s: %v", p.filename, err)
+			failed++
+		default:
+			added++
+		}
+		if (i+1)%10 == 0 {
+			log.Printf("%d/%d (%d added, %d skipped, %d failed)", i+1, *n, added, skipped, failed)
+		}
+	}
+	log.Printf("done: %d added, %d skipped, %d failed in %s", added, skipped, failed, time.Since(start).Round(time.Second))
+
+	if *embed && added > 0 && ctx.Err() == nil {
+		emb := graphrag.NewEmbeddingService(cfg.OpenAIAPIKey, db.GetConnection())
+		if cfg.EmbeddingProvider == "ollama" {
+			emb.UseOllama(cfg.OllamaURL, cfg.OllamaAPIKey)
+		}
+		emb.SetModel(cfg.EmbeddingModel, cfg.EmbeddingDimensions)
+		if err := emb.BatchEmbedAllNodes(ctx); err != nil {
+			log.Fatalf("embeddings: %v", err)
+		}
+	}
+}
+
+var errExists = errors.New("already seeded")
+
+// add stores p's CV like an upload and applies its extraction like the API's
+// CV worker does.
+func (s *seeder) add(ctx context.Context, p profile) error {
+	hash := sha256.Sum256([]byte(p.text))
+	contentHash := hex.EncodeToString(hash[:])
+	if existing, err := s.db.FindCVByHash(ctx, contentHash); err != nil {
+		return err
+	} else if existing != nil {
+		return errExists
+	}
+
+	key := fmt.Sprintf("cvs/seed/%s-%s", contentHash[:16], strings.ToLower(p.filename))
+	size := int64(len(p.text))
+	if err := s.db.TouchBlob(ctx, key, size); err != nil {
+		return err
+	}
+	if err := s.blobs.Put(ctx, key, strings.NewReader(p.text), size, "text/plain; charset=utf-8"); err != nil {
+		return err
+	}
+
+	var cvID int
+	var jobID int64
+	err := s.db.WithTx(ctx, func(tx *storage.DB) error {
+		var txErr error
+		if cvID, jobID, txErr = tx.SaveCVFileWithJob(ctx, nil, p.filename, key, "txt", p.text, size, contentHash); txErr != nil {
+			return txErr
+		}
+		if sections := cv.DetectSections(p.text); sections != nil {
+			data, err := cv.MarshalSections(sections)
+			if err != nil {
+				return err
+			}
+			if err := tx.SaveCVSections(ctx, int64(cvID), data); err != nil {
+				return err
+			}
+		}
+		var chunks []storage.CVChunk
+		for _, c := range cv.ChunkText(cv.SectionedText(p.text), cv.ChunkTokens) {
+			chunks = append(chunks, storage.CVChunk{Index: c.Index, Text: c.Text, Tokens: c.Tokens})
+		}
+		return tx.SaveCVChunks(ctx, int64(cvID), chunks)
+	})
+	if err != nil {
+		return fmt.Errorf("save CV: %w", err)
+	}
+
+	e := p.extraction
+	if !s.canned {
+		if e, err = s.llm.ExtractEntities(ctx, cv.SectionedText(p.text)); err != nil {
+			msg := err.Error()
+			s.db.UpdateJobStatus(ctx, jobID, "failed", &msg)
+			return fmt.Errorf("extract: %w", err)
+		}
+	}
+	if err := s.apply(ctx, int64(cvID), p.text, e); err != nil {
+		msg := err.Error()
+		s.db.UpdateJobStatus(ctx, jobID, "failed", &msg)
+		return err
+	}
+	return s.db.UpdateJobStatus(ctx, jobID, "completed", nil)
+}
+
+// apply writes an extraction's entities, graph and candidate, the way the
+// API's applyExtraction does for an upload.
+func (s *seeder) apply(ctx context.Context, cvID int64, text string, e *llm.CVExtraction) error {
+	q := cv.ScoreQuality(text, e, false)
+	if err := s.db.SetCVFileQuality(ctx, cvID, q.Status, q.Score, q.Issues); err != nil {
+		return err
+	}
+
+	err := s.db.WithTx(ctx, func(tx *storage.DB) error {
+		type entity struct {
+			kind, value string
+			confidence  float64
+		}
+		var entities []entity
+		for _, sk := range e.Skills {
+			entities = append(entities, entity{"skill", sk.Name, sk.Confidence})
+		}
+		for _, c := range e.Companies {
+			entities = append(entities, entity{"company", c.Name, c.Confidence})
+		}
+		for _, ed := range e.Education {
+			entities = append(entities, entity{"education", ed.Institution, 0.9})
+		}
+		for _, c := range e.Certifications {
+			entities = append(entities, entity{"certification", c.Name, 0.9})
+		}
+		for _, l := range e.Languages {
+			entities = append(entities, entity{"language", l.Name, 0.9})
+		}
+		for _, p := range e.Projects {
+			entities = append(entities, entity{"project", p.Name, 0.8})
+		}
+		for _, loc := range e.Locations {
+			entities = append(entities, entity{"location", loc, 0.85})
+		}
+		if e.Candidate.Seniority != "" {
+			entities = append(entities, entity{"seniority", e.Candidate.Seniority, 0.8})
+		}
+		if e.Candidate.CurrentPosition != "" {
+			entities = append(entities, entity{"position", e.Candidate.CurrentPosition, 0.8})
+		}
+		for _, en := range entities {
+			if err := tx.SaveCVEntity(ctx, int(cvID), en.kind, en.value, en.confidence); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("save entities: %w", err)
+	}
+
+	extractionMap := map[string]interface{}{
+		"candidate": map[string]interface{}{
+			"name":                   e.Candidate.Name,
+			"current_position":       e.Candidate.CurrentPosition,
+			"seniority":              e.Candidate.Seniority,
+			"total_experience_years": e.Candidate.TotalExperienceYears,
+			"work_modes":             []string(e.Candidate.WorkModes),
+			"employment_types":       []string(e.Candidate.EmploymentTypes),
+			"notice_period":          e.Candidate.NoticePeriod,
+			"available_from":         e.Candidate.AvailableFrom,
+		},
+		"skills":         e.Skills,
+		"companies":      e.Companies,
+		"education":      e.Education,
+		"certifications": e.Certifications,
+		"languages":      e.Languages,
+		"projects":       e.Projects,
+		"locations":      e.Locations,
+	}
+	if err := s.graph.BuildFromLLMExtraction(ctx, int(cvID), extractionMap); err != nil {
+		return fmt.Errorf("build graph: %w", err)
+	}
+	personNodeID, err := s.db.GetPersonGraphNodeIDByName(ctx, e.Candidate.Name)
+	if err != nil {
+		return fmt.Errorf("find person node: %w", err)
+	}
+
+	if _, err := s.db.LinkCandidateToCV(ctx, cvID, personNodeID, e.Candidate.Name, storage.CandidateContact{
+		Email:       e.Candidate.Email,
+		Phone:       e.Candidate.Phone,
+		LinkedInURL: e.Candidate.LinkedInURL,
+	}); err != nil {
+		return fmt.Errorf("link candidate: %w", err)
+	}
+	if len(e.Locations) > 0 {
+		// Uploads leave candidates.location to cmd/tools/backfill; seeded
+		// candidates get it right away so location filters work in a demo.
+		if _, err := s.db.GetConnection().ExecContext(ctx, `
+			UPDATE candidates SET location = $1
+			WHERE id = (SELECT candidate_id FROM cv_files WHERE id = $2) AND COALESCE(location, '') = ''
+		`, e.Locations[0], cvID); err != nil {
+			return fmt.Errorf("set location: %w", err)
+		}
+	}
+	return nil
+}
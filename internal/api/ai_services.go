@@ -0,0 +1,233 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"cv-search/internal/config"
+	"cv-search/internal/cv"
+	"cv-search/internal/graphrag"
+	"cv-search/internal/llm"
+	"cv-search/internal/storage"
+	"cv-search/internal/tenant"
+)
+
+// ─── LLM and embedding services ───────────────────────────────────────────────
+
+// aiServices are the services that call an LLM or embedding provider. The
+// API embeds the deployment's; an organization with settings of its own
+// (organization_ai_settings) gets a set built from them, see ai.
+type aiServices struct {
+	llmService           *llm.Service
+	sectionDetector      *cv.SectionDetector
+	llmSearchEngine      *graphrag.LLMSearchEngine      // LLM-only semantic search
+	enhancedSearchEngine *graphrag.EnhancedSearchEngine // Vector + Community + LLM search (Microsoft GraphRAG)
+	hybridSearchEngine   *graphrag.HybridSearchEngine   // BM25 + Vector + Graph + LLM reranking
+}
+
+// aiConfig is what a set of aiServices is built from.
+type aiConfig struct {
+	llmProvider string
+	llmModel    string
+	llmAPIKey   string
+
+	embeddingProvider   string // "openai" or "ollama"
+	embeddingModel      string
+	embeddingDimensions int
+	embeddingAPIKey     string // OpenAI key, or Ollama bearer token
+}
+
+// deploymentAIConfig is the deployment's own LLM and embedding setup.
+func deploymentAIConfig(cfg *config.Config) aiConfig {
+	ac := aiConfig{
+		llmProvider:         cfg.LLMProvider,
+		llmModel:            cfg.LLMModel,
+		llmAPIKey:           cfg.LLMAPIKey,
+		embeddingProvider:   cfg.EmbeddingProvider,
+		embeddingModel:      cfg.EmbeddingModel,
+		embeddingDimensions: cfg.EmbeddingDimensions,
+		embeddingAPIKey:     cfg.OpenAIAPIKey,
+	}
+	if ac.embeddingProvider == "ollama" {
+		ac.embeddingAPIKey = cfg.OllamaAPIKey
+	}
+	return ac
+}
+
+// newAIServices builds the LLM service and search engines for ac. Without a
+// usable LLM every field but the (heuristic) section detector is nil, and
+// without embeddings so are the enhanced and hybrid engines; callers check
+// for nil as they always have.
+func newAIServices(db *storage.DB, cfg *config.Config, ac aiConfig) *aiServices {
+	// Ollama runs without a key; the hosted providers need one.
+	if ac.llmProvider == "" || ac.llmProvider == "none" || (ac.llmAPIKey == "" && ac.llmProvider != "ollama") {
+		return &aiServices{sectionDetector: cv.NewSectionDetector(nil)}
+	}
+
+	llmSvc := llm.NewService(ac.llmProvider, ac.llmAPIKey, ac.llmModel)
+	llmSvc.SetRPMLimit(cfg.GroqRPMLimit)
+	llmSvc.SetBaseURL(cfg.OllamaURL)
+	llmSvc.SetUsageRecorder(func(ctx context.Context, u llm.Usage) {
+		var cost *float64
+		if c, ok := u.CostUSD(); ok {
+			cost = &c
+		}
+		// Recorded even if the request was cancelled meanwhile.
+		ctx = tenant.WithOrg(context.Background(), tenant.OrgID(ctx))
+		if err := db.RecordLLMUsage(ctx, u.Provider, u.Model, u.PromptTokens, u.CompletionTokens, cost); err != nil {
+			log.Printf("[API] %v", err)
+		}
+	})
+
+	llmAdapter := graphrag.NewLLMAdapter(llmSvc)
+	s := &aiServices{
+		llmService:      llmSvc,
+		sectionDetector: cv.NewSectionDetector(llmSvc),
+		llmSearchEngine: graphrag.NewLLMSearchEngine(db.ReadConnection(), llmAdapter),
+	}
+	s.llmSearchEngine.SetTextSearchConfig(cfg.TextSearchConfig)
+	s.llmSearchEngine.SetPrefilter(cfg.LLMSearchPrefilterK, cfg.LLMSearchBatchSize)
+
+	// Embeddings require an OpenAI key (even when LLM provider is Groq),
+	// unless they come from Ollama
+	ollamaEmbeddings := ac.embeddingProvider == "ollama"
+	if !ollamaEmbeddings && (ac.embeddingAPIKey == "" || ac.embeddingAPIKey == "your_openai_api_key_here") {
+		return s
+	}
+	s.enhancedSearchEngine = graphrag.NewEnhancedSearchEngine(db.GetConnection(), llmAdapter, ac.embeddingAPIKey)
+	s.hybridSearchEngine = graphrag.NewHybridSearchEngine(db.GetConnection(), llmAdapter, ac.embeddingAPIKey, cfg.DisableLLMCache)
+	if ollamaEmbeddings {
+		s.enhancedSearchEngine.UseOllamaEmbeddings(cfg.OllamaURL, ac.embeddingAPIKey)
+		s.hybridSearchEngine.UseOllamaEmbeddings(cfg.OllamaURL, ac.embeddingAPIKey)
+	}
+	s.enhancedSearchEngine.SetEmbeddingModel(ac.embeddingModel, ac.embeddingDimensions)
+	s.enhancedSearchEngine.GetCommunityDetector().SetDriftThresholds(graphrag.DriftThresholds{
+		SizeChange:   float64(cfg.CommunityDriftSizePercent) / 100,
+		Churn:        float64(cfg.CommunityDriftChurnPercent) / 100,
+		CohesionDrop: float64(cfg.CommunityDriftCohesionDropPercent) / 100,
+	})
+	s.hybridSearchEngine.SetEmbeddingModel(ac.embeddingModel, ac.embeddingDimensions)
+	s.hybridSearchEngine.SetReadDB(db.ReadConnection())
+	s.hybridSearchEngine.SetTextSearchConfig(cfg.TextSearchConfig)
+	s.llmSearchEngine.SetEmbeddingService(s.hybridSearchEngine.GetEmbeddingService())
+	return s
+}
+
+// ─── Per-organization resolution ──────────────────────────────────────────────
+
+// orgAIRefresh is how long an organization's resolved services are used
+// before its settings are read again, so a change made through another API
+// instance is picked up.
+const orgAIRefresh = time.Minute
+
+type orgAIEntry struct {
+	services  *aiServices
+	updatedAt time.Time // of the settings services was built from; zero = the deployment's
+	checked   time.Time
+}
+
+// ai returns the services ctx's organization uses: built from its own
+// settings if it has any, else the deployment's. Built services are reused
+// (their caches and rate limiters with them) until the settings change.
+//
+// An organization whose settings can't be read or decrypted gets no LLM or
+// embedding services at all rather than the deployment's, so its data never
+// goes to a provider it didn't choose.
+func (a *API) ai(ctx context.Context) *aiServices {
+	orgID := tenant.OrgID(ctx)
+
+	a.orgAIMu.Lock()
+	e, cached := a.orgAI[orgID]
+	a.orgAIMu.Unlock()
+	if cached && time.Since(e.checked) < orgAIRefresh {
+		return e.services
+	}
+
+	settings, err := a.db.GetOrgAISettings(ctx, orgID)
+	if err != nil {
+		log.Printf("[OrgAI] %v", err)
+		if cached {
+			return e.services
+		}
+		return &aiServices{sectionDetector: cv.NewSectionDetector(nil)}
+	}
+
+	switch {
+	case settings == nil:
+		e = orgAIEntry{services: a.aiServices}
+	case cached && e.updatedAt.Equal(settings.UpdatedAt):
+		// unchanged; keep the built services
+	default:
+		e = orgAIEntry{updatedAt: settings.UpdatedAt}
+		ac, err := a.orgAIConfig(settings)
+		if err != nil {
+			log.Printf("[OrgAI] Org %d: %v — LLM and embeddings disabled for it", orgID, err)
+			e.services = &aiServices{sectionDetector: cv.NewSectionDetector(nil)}
+		} else {
+			e.services = newAIServices(a.db, a.cfg, ac)
+			log.Printf("[OrgAI] Org %d: LLM %s/%s, embeddings %s/%s",
+				orgID, ac.llmProvider, ac.llmModel, ac.embeddingProvider, ac.embeddingModel)
+		}
+	}
+	e.checked = time.Now()
+
+	a.orgAIMu.Lock()
+	a.orgAI[orgID] = e
+	a.orgAIMu.Unlock()
+	return e.services
+}
+
+// forgetOrgAI drops an organization's resolved services, and the responses
+// they made, after its settings changed.
+func (a *API) forgetOrgAI(orgID int) {
+	a.orgAIMu.Lock()
+	delete(a.orgAI, orgID)
+	a.orgAIMu.Unlock()
+	a.invalidateResponses(tenant.WithOrg(context.Background(), orgID))
+}
+
+// orgAIConfig lays an organization's settings over the deployment's,
+// decrypting its API keys.
+func (a *API) orgAIConfig(s *storage.OrgAISettings) (aiConfig, error) {
+	ac := deploymentAIConfig(a.cfg)
+	if s.LLMProvider != "" {
+		key, err := a.openSecret(s.LLMAPIKey)
+		if err != nil {
+			return ac, fmt.Errorf("LLM API key: %w", err)
+		}
+		ac.llmProvider, ac.llmModel, ac.llmAPIKey = s.LLMProvider, s.LLMModel, key
+	}
+	if s.EmbeddingProvider != "" {
+		key, err := a.openSecret(s.EmbeddingAPIKey)
+		if err != nil {
+			return ac, fmt.Errorf("embedding API key: %w", err)
+		}
+		ac.embeddingProvider, ac.embeddingModel, ac.embeddingDimensions, ac.embeddingAPIKey =
+			s.EmbeddingProvider, s.EmbeddingModel, s.EmbeddingDimensions, key
+	}
+	return ac, nil
+}
+
+// openSecret decrypts a stored API key; nil is no key.
+func (a *API) openSecret(sealed []byte) (string, error) {
+	if sealed == nil {
+		return "", nil
+	}
+	if a.secrets == nil {
+		return "", fmt.Errorf("stored encrypted but SETTINGS_ENCRYPTION_KEY is not set")
+	}
+	return a.secrets.Open(sealed)
+}
+
+// usesDeploymentLLM reports whether every job's organization extracts with
+// the deployment's LLM service, which the Groq Batch API path is tied to.
+func (a *API) usesDeploymentLLM(ctx context.Context, jobs []CVProcessingJob) bool {
+	for _, j := range jobs {
+		if a.ai(tenant.WithOrg(ctx, j.OrgID)).llmService != a.llmService {
+			return false
+		}
+	}
+	return true
+}
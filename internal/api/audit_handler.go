@@ -0,0 +1,155 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"cv-search/internal/storage"
+)
+
+// ─── Audit helpers ────────────────────────────────────────────────────────────
+
+// actorFromRequest identifies who made a request for the audit trail. An
+// explicit X-User-ID wins ("user:<id>"); otherwise an API key from
+// X-API-Key or a Bearer token is recorded as "key:<sha256 prefix>" — the raw
+// key never reaches the database. Requests with neither are "anonymous".
+func actorFromRequest(r *http.Request) string {
+	if uid := strings.TrimSpace(r.Header.Get("X-User-ID")); uid != "" {
+		return "user:" + uid
+	}
+	if key := apiKeyFromRequest(r); key != "" {
+		return "key:" + hashAPIKey(key)[:12]
+	}
+	return "anonymous"
+}
+
+// apiKeyFromRequest returns the API key sent as X-API-Key or as a Bearer
+// token, "" if there is none.
+func apiKeyFromRequest(r *http.Request) string {
+	if key := strings.TrimSpace(r.Header.Get("X-API-Key")); key != "" {
+		return key
+	}
+	if auth := r.Header.Get("Authorization"); len(auth) > 7 && strings.EqualFold(auth[:7], "bearer ") {
+		return strings.TrimSpace(auth[7:])
+	}
+	return ""
+}
+
+// hashAPIKey is the hex SHA-256 of an API key, the form keys are stored and
+// logged in.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// clientIP returns the first X-Forwarded-For hop (set by the Railway/k8s
+// proxy), falling back to the connection's remote address.
+func clientIP(r *http.Request) string {
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		if first := strings.TrimSpace(strings.Split(xff, ",")[0]); first != "" {
+			return first
+		}
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// audit records a mutation made by r. A failed write is logged, not returned:
+// the mutation has already happened and the caller can't undo it.
+func (a *API) audit(r *http.Request, action, entityType, entityID string, details interface{}) {
+	a.logAudit(r.Context(), storage.AuditEntry{
+		Actor:      actorFromRequest(r),
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		IP:         clientIP(r),
+	}, details)
+}
+
+// logAudit writes entry with details marshalled into it; audit for callers
+// that aren't HTTP requests (the gRPC API).
+func (a *API) logAudit(ctx context.Context, entry storage.AuditEntry, details interface{}) {
+	if details != nil {
+		b, err := json.Marshal(details)
+		if err != nil {
+			log.Printf("[Audit] marshal details for %s %s/%s: %v", entry.Action, entry.EntityType, entry.EntityID, err)
+		} else {
+			entry.Details = b
+		}
+	}
+	if err := a.db.LogAudit(ctx, entry); err != nil {
+		log.Printf("[Audit] %v", err)
+	}
+}
+
+// ─── Handlers ─────────────────────────────────────────────────────────────────
+
+type auditLogResponse struct {
+	Entries []storage.AuditEntry `json:"entries"`
+	Limit   int                  `json:"limit"`
+	Offset  int                  `json:"offset"`
+}
+
+// ListAuditLogHandler returns audit entries, newest first.
+//
+//	GET /api/admin/audit-log?actor=&action=&entity_type=&entity_id=&since=&until=&limit=100&offset=0
+//
+// since/until are RFC 3339 timestamps; limit defaults to 100 (max 1000).
+func (a *API) ListAuditLogHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	f := storage.AuditFilter{
+		Actor:      q.Get("actor"),
+		Action:     q.Get("action"),
+		EntityType: q.Get("entity_type"),
+		EntityID:   q.Get("entity_id"),
+		Limit:      100,
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{{"since", &f.Since}, {"until", &f.Until}} {
+		v := q.Get(p.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "invalid "+p.name+": expected RFC 3339 timestamp", http.StatusBadRequest)
+			return
+		}
+		*p.dst = &t
+	}
+	if v := q.Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 1000 {
+			f.Limit = n
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			f.Offset = n
+		}
+	}
+
+	entries, err := a.db.ListAuditLog(r.Context(), f)
+	if err != nil {
+		log.Printf("[Audit] ListAuditLog failed: %v", err)
+		http.Error(w, "failed to list audit log", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSONList(w, struct {
+		auditLogResponse
+		Entries json.RawMessage `json:"entries,omitempty"`
+	}{auditLogResponse: auditLogResponse{Limit: f.Limit, Offset: f.Offset}}, "entries", entries)
+}
@@ -0,0 +1,385 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"cv-search/internal/cv"
+	"cv-search/internal/storage"
+	"cv-search/internal/tenant"
+)
+
+// bulkUploadFileResult is one file of a bulk upload; job_id and
+// check_status_url are set only for files that were queued.
+type bulkUploadFileResult struct {
+	Filename       string `json:"filename"`
+	CVID           *int64 `json:"cv_id,omitempty"`
+	JobID          *int64 `json:"job_id,omitempty"`
+	Status         string `json:"status"`
+	Error          string `json:"error,omitempty"`
+	CheckStatusURL string `json:"check_status_url,omitempty"`
+}
+
+type bulkUploadResponse struct {
+	BatchID        string                 `json:"batch_id"`
+	Total          int                    `json:"total"`
+	Queued         int                    `json:"queued"`
+	Skipped        int                    `json:"skipped"`
+	CheckStatusURL string                 `json:"check_status_url"`
+	Results        []bulkUploadFileResult `json:"results"`
+	Queue          QueueCapacity          `json:"queue"`
+}
+
+// BulkCVUploadHandler handles bulk CV file uploads (up to MaxBulkFileCount files, MaxFileSizeMB each).
+// Uploads at or below MaxRealtimeCVCount are processed via the real-time queue (seconds);
+// larger ones are submitted as a single Groq Batch API job (minutes-to-hours, but immune
+// to the standard per-model rate limit and 50% cheaper).
+func (a *API) BulkCVUploadHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	maxBulkSize := int64(a.cfg.MaxFileSizeMB*a.cfg.MaxBulkFileCount) << 20
+	r.Body = http.MaxBytesReader(w, r.Body, maxBulkSize+(1<<20))
+	if err := r.ParseMultipartForm(maxBulkSize); err != nil {
+		http.Error(w, fmt.Sprintf("request too large (max %d MB total)", a.cfg.MaxFileSizeMB*a.cfg.MaxBulkFileCount), http.StatusBadRequest)
+		return
+	}
+
+	files := r.MultipartForm.File["files"]
+	if len(files) == 0 {
+		http.Error(w, "no files uploaded (use field name: files)", http.StatusBadRequest)
+		return
+	}
+	if len(files) > a.cfg.MaxBulkFileCount {
+		http.Error(w, fmt.Sprintf("max %d files per request", a.cfg.MaxBulkFileCount), http.StatusBadRequest)
+		return
+	}
+	anonymize, err := a.anonymizeRequested(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// source and tags apply to every new file; a batch has no one candidate
+	// and duplicates are only reported.
+	opts, err := parseUploadOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if opts.CandidateID != nil || opts.Force {
+		http.Error(w, "candidate_id and force are only supported by /api/cv/upload", http.StatusBadRequest)
+		return
+	}
+	// Uploads bound for the real-time queue are refused up front when it
+	// can't take them; big ones for the Groq Batch API (see dispatchCVJobs)
+	// don't use it.
+	batchAPI := !a.cfg.DisableGroqBatch && a.llmService != nil && a.cfg.LLMProvider == "groq" &&
+		len(files) > a.cfg.MaxRealtimeCVCount && a.ai(r.Context()).llmService == a.llmService
+	if !batchAPI && a.rejectIfQueueBusy(w, len(files)) {
+		return
+	}
+	if !a.admitQuota(w, r, quotaUploads, len(files)) {
+		return
+	}
+
+	batchID := fmt.Sprintf("batch_%d", time.Now().UnixNano())
+	results := make([]bulkUploadFileResult, 0, len(files))
+	var batchJobs []BatchJob
+	queued, skipped := 0, 0
+
+	// Files that parsed/saved successfully are collected here first; whether
+	// they're queued for real-time processing or submitted as a single Groq
+	// Batch API job is decided AFTER the loop, based on how many there are.
+	type pendingUpload struct {
+		resultIdx int
+		job       CVProcessingJob
+	}
+	var pending []pendingUpload
+
+	maxFileSize := int64(a.cfg.MaxFileSizeMB) << 20
+	for _, fileHeader := range files {
+		res := bulkUploadFileResult{Filename: fileHeader.Filename}
+
+		// Per-file size limit
+		if fileHeader.Size > maxFileSize {
+			res.Status = "too_large"
+			res.Error = fmt.Sprintf("file too large (max %d MB)", a.cfg.MaxFileSizeMB)
+			skipped++
+			results = append(results, res)
+			continue
+		}
+
+		// File name and type validation
+		filename, err := checkCVUpload(fileHeader.Filename, fileHeader.Header.Get("Content-Type"))
+		if err != nil {
+			res.Status = "invalid_type"
+			res.Error = err.Error()
+			skipped++
+			results = append(results, res)
+			continue
+		}
+		res.Filename = filename
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			log.Printf("[BulkUpload] Cannot open %s: %v", fileHeader.Filename, err)
+			res.Status = "error"
+			skipped++
+			results = append(results, res)
+			continue
+		}
+
+		parsedCV, err := a.cvParser.ParseReader(filename, file)
+		file.Close()
+		if errors.Is(err, cv.ErrRejectedFile) {
+			a.audit(r, "reject", "cv_file", "", map[string]interface{}{"filename": filename, "reason": err.Error(), "batch_id": batchID})
+			res.Status = "rejected"
+			res.Error = err.Error()
+			skipped++
+			results = append(results, res)
+			continue
+		}
+		if err != nil {
+			log.Printf("[BulkUpload] Parse error %s: %v", fileHeader.Filename, err)
+			res.Status = "error"
+			skipped++
+			results = append(results, res)
+			continue
+		}
+
+		// Hash-based duplicate detection
+		hash := sha256.Sum256([]byte(parsedCV.FullText))
+		contentHash := hex.EncodeToString(hash[:])
+
+		existing, _ := a.db.FindCVByHash(r.Context(), contentHash)
+		if existing != nil {
+			cvID := int64(existing.ID)
+			res.CVID = &cvID
+			res.Status = "duplicate"
+			skipped++
+			results = append(results, res)
+			continue
+		}
+
+		blobKey, err := a.storeUploadedCV(r.Context(), fileHeader, filename)
+		if err != nil {
+			log.Printf("[BulkUpload] Store error %s: %v", fileHeader.Filename, err)
+			res.Status = "error"
+			skipped++
+			results = append(results, res)
+			continue
+		}
+		if anonymize {
+			parsedCV.Anonymize()
+		}
+
+		cvID, jobID, err := a.saveParsedCV(r.Context(), opts, parsedCV, blobKey, contentHash)
+		if err != nil {
+			log.Printf("[BulkUpload] DB save error %s: %v", fileHeader.Filename, err)
+			res.Status = "error"
+			skipped++
+			results = append(results, res)
+			continue
+		}
+
+		a.audit(r, "upload", "cv_file", strconv.Itoa(cvID), map[string]interface{}{
+			"filename": parsedCV.Filename, "file_size": parsedCV.FileSize, "job_id": jobID, "batch_id": batchID,
+		})
+
+		cvIDVal := int64(cvID)
+		res.CVID = &cvIDVal
+		res.JobID = &jobID
+		res.CheckStatusURL = fmt.Sprintf("/api/cv/job/%d", jobID)
+		queued++
+		batchJobs = append(batchJobs, BatchJob{JobID: jobID, Filename: filename, CVID: int64(cvID)})
+		resultIdx := len(results)
+		results = append(results, res)
+		pending = append(pending, pendingUpload{
+			resultIdx: resultIdx,
+			job: CVProcessingJob{
+				OrgID:     tenant.OrgID(r.Context()),
+				JobID:     jobID,
+				CVFileID:  int64(cvID),
+				CVText:    parsedCV.FullText,
+				Timestamp: time.Now(),
+			},
+		})
+	}
+
+	if len(pending) > 0 {
+		a.countUsage(r.Context(), len(pending), 0)
+	}
+
+	jobs := make([]CVProcessingJob, 0, len(pending))
+	for _, p := range pending {
+		jobs = append(jobs, p.job)
+	}
+	useBatchAPI, accepted := a.dispatchCVJobs(r.Context(), jobs)
+	queueFull := false
+	for i, p := range pending {
+		switch {
+		case useBatchAPI:
+			results[p.resultIdx].Status = "batch_submitted"
+		case accepted[i]:
+			results[p.resultIdx].Status = "queued"
+		default:
+			results[p.resultIdx].Status = "queue_full"
+			queueFull = true
+			results[p.resultIdx].JobID = nil
+			results[p.resultIdx].CheckStatusURL = ""
+			queued--
+			skipped++
+		}
+	}
+
+	// Persist batch to in-memory store
+	a.batchStore.set(&BatchEntry{
+		BatchID:   batchID,
+		Jobs:      batchJobs,
+		CreatedAt: time.Now(),
+	})
+
+	// Email the uploader once the queued files are processed, if they asked to
+	report := &storage.BatchNotification{BatchID: batchID, Files: len(files)}
+	for _, res := range results {
+		switch {
+		case res.Status == "duplicate":
+			report.Duplicates++
+		case res.JobID != nil:
+			report.JobIDs = append(report.JobIDs, *res.JobID)
+		default:
+			report.UploadFailures = append(report.UploadFailures, storage.BatchFileFailure{
+				Filename: res.Filename, Reason: uploadFailureReason(res.Status, res.Error),
+			})
+		}
+	}
+	a.queueBatchReport(r, report)
+
+	log.Printf("[BulkUpload] batch=%s total=%d queued=%d skipped=%d batch_api=%v", batchID, len(files), queued, skipped, useBatchAPI)
+
+	if queueFull {
+		w.Header().Set("Retry-After", strconv.Itoa(int(a.cfg.CVQueueRetryAfter.Seconds())))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusMultiStatus) // 207
+	json.NewEncoder(w).Encode(bulkUploadResponse{
+		BatchID:        batchID,
+		Total:          len(files),
+		Queued:         queued,
+		Skipped:        skipped,
+		CheckStatusURL: fmt.Sprintf("/api/cv/batch/%s", batchID),
+		Results:        results,
+		Queue:          a.cvQueueCapacity(),
+	})
+}
+
+// dispatchCVJobs hands freshly saved CVs to extraction. Small sets go
+// through the real-time queue (fast, seconds); more than MaxRealtimeCVCount
+// are submitted as a single Groq Batch API job instead, which doesn't count
+// against the standard rate limit (separate quota) and is 50% cheaper — at
+// the cost of results taking minutes-to-hours instead of seconds. Only
+// available when the LLM provider is Groq, and for organizations that use the
+// deployment's LLM rather than their own. Reports whether the batch API was
+// used and, otherwise, which jobs the queue accepted.
+func (a *API) dispatchCVJobs(ctx context.Context, jobs []CVProcessingJob) (batchAPI bool, accepted []bool) {
+	accepted = make([]bool, len(jobs))
+
+	useBatchAPI := !a.cfg.DisableGroqBatch &&
+		a.llmService != nil && a.cfg.LLMProvider == "groq" && len(jobs) > a.cfg.MaxRealtimeCVCount &&
+		a.usesDeploymentLLM(ctx, jobs)
+	if useBatchAPI {
+		groqBatchID, err := a.SubmitCVExtractionBatch(ctx, jobs)
+		if err == nil {
+			if groqBatchID != "" {
+				log.Printf("[BulkUpload] Submitted %d CVs as Groq batch %s", len(jobs), groqBatchID)
+			}
+			for i := range accepted {
+				accepted[i] = true
+			}
+			return true, accepted
+		}
+		log.Printf("[BulkUpload] Groq batch submission failed, falling back to real-time queue: %v", err)
+	}
+
+	for i, job := range jobs {
+		accepted[i] = a.queueCVProcessingJob(tenant.WithOrg(ctx, job.OrgID), job.JobID, job.CVFileID, job.CVText)
+	}
+	return false, accepted
+}
+
+type batchJobStatus struct {
+	JobID    int64  `json:"job_id"`
+	Filename string `json:"filename"`
+	CVID     int64  `json:"cv_file_id"`
+	Status   string `json:"status"`
+}
+
+// batchStatusResponse counts a batch's jobs by status in summary (keys
+// total, completed, processing, pending, failed).
+type batchStatusResponse struct {
+	BatchID string           `json:"batch_id"`
+	Summary map[string]int   `json:"summary"`
+	Jobs    []batchJobStatus `json:"jobs"`
+}
+
+// GetBatchStatusHandler returns the processing status for all jobs in a batch.
+func (a *API) GetBatchStatusHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	batchID := strings.TrimPrefix(r.URL.Path, "/api/cv/batch/")
+	if batchID == "" {
+		http.Error(w, "missing batch_id", http.StatusBadRequest)
+		return
+	}
+
+	entry, ok := a.batchStore.get(batchID)
+	if !ok {
+		http.Error(w, "batch not found (may have expired after 30 minutes)", http.StatusNotFound)
+		return
+	}
+
+	jobs := make([]batchJobStatus, 0, len(entry.Jobs))
+	summary := map[string]int{
+		"total": len(entry.Jobs), "completed": 0, "processing": 0, "pending": 0, "failed": 0,
+	}
+
+	for _, bj := range entry.Jobs {
+		job, err := a.db.GetJobByID(r.Context(), bj.JobID)
+		status := "unknown"
+		if err != nil {
+			log.Printf("[BatchStatus] GetJobByID(%d) error: %v", bj.JobID, err)
+		} else if job != nil {
+			status = job.Status
+		} else {
+			log.Printf("[BatchStatus] GetJobByID(%d) returned nil (not found)", bj.JobID)
+		}
+
+		if _, tracked := summary[status]; tracked {
+			summary[status]++
+		}
+		jobs = append(jobs, batchJobStatus{
+			JobID: bj.JobID, Filename: bj.Filename, CVID: bj.CVID, Status: status,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(batchStatusResponse{
+		BatchID: batchID,
+		Summary: summary,
+		Jobs:    jobs,
+	})
+}
@@ -7,10 +7,16 @@ import (
 	"errors"
 	"log"
 	"net/http"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
+	"cv-search/internal/graphrag"
+	"cv-search/internal/retention"
 	"cv-search/internal/storage"
+	"cv-search/internal/tenant"
 )
 
 // ─── Request/Response types ───────────────────────────────────────────────────
@@ -22,6 +28,17 @@ type listCandidatesResponse struct {
 	Offset     int                         `json:"offset"`
 }
 
+type createInterviewResponse struct {
+	ID          int    `json:"id"`
+	CandidateID int    `json:"candidate_id"`
+	Message     string `json:"message"`
+}
+
+// messageResponse acknowledges a change that has nothing else to return.
+type messageResponse struct {
+	Message string `json:"message"`
+}
+
 type interviewRequest struct {
 	InterviewDate   string `json:"interview_date"` // "2026-03-06"
 	Team            string `json:"team"`
@@ -92,15 +109,18 @@ func (req *interviewRequest) toInterview() (storage.Interview, error) {
 	}, nil
 }
 
-// reEmbed fetches current interview notes and re-embeds the person node.
+// reEmbed fetches current interview notes and re-embeds the person node of
+// a candidate of the organization orgID.
 // Runs in the background — does not block the HTTP response.
 // Non-fatal: all errors are logged, never propagated.
-func (a *API) reEmbed(candidateID int) {
-	if a.hybridSearchEngine == nil {
+func (a *API) reEmbed(orgID, candidateID int) {
+	ctx, cancel := context.WithTimeout(tenant.WithOrg(context.Background(), orgID), 30*time.Second)
+	defer cancel()
+
+	hybrid := a.ai(ctx).hybridSearchEngine
+	if hybrid == nil {
 		return
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
 
 	graphNodeID, err := a.db.GetGraphNodeIDForCandidate(ctx, candidateID)
 	if err != nil {
@@ -118,14 +138,18 @@ func (a *API) reEmbed(candidateID int) {
 		return
 	}
 
-	if err := a.hybridSearchEngine.ReEmbedPersonNode(ctx, graphNodeID, notes); err != nil {
+	if err := hybrid.ReEmbedPersonNode(ctx, graphNodeID, notes); err != nil {
 		log.Printf("[CandidateHandler] reEmbed: failed for node %d: %v", graphNodeID, err)
 	}
+	a.invalidateResponses(ctx) // the candidate's interviews and ranking changed
 }
 
 // ─── Handlers ─────────────────────────────────────────────────────────────────
 
-// ListCandidatesHandler returns a paginated list of candidates with basic enrichment.
+// ListCandidatesHandler returns a paginated list of candidates with basic
+// enrichment. incomplete=true lists only candidates whose profile fails a
+// completeness check, missing=<check> those failing that one, for backfill;
+// status=<status> only the candidates of that status.
 func (a *API) ListCandidatesHandler(w http.ResponseWriter, r *http.Request) {
 	limit := 50
 	offset := 0
@@ -141,7 +165,30 @@ func (a *API) ListCandidatesHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	candidates, err := a.db.ListCandidates(r.Context(), limit, offset)
+	missing := r.URL.Query().Get("missing")
+	if missing != "" && !slices.Contains(storage.ProfileChecks(), missing) {
+		http.Error(w, "missing must be one of: "+strings.Join(storage.ProfileChecks(), ", "), http.StatusBadRequest)
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if status != "" {
+		if err := validStatus(status); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	var candidates []storage.CandidateListItem
+	var err error
+	switch {
+	case missing != "" || r.URL.Query().Get("incomplete") == "true":
+		candidates, err = a.db.ListIncompleteCandidates(r.Context(), missing, limit, offset)
+	case status != "":
+		candidates, err = a.db.ListCandidatesByStatus(r.Context(), status, limit, offset)
+	default:
+		candidates, err = a.db.ListCandidates(r.Context(), limit, offset)
+	}
 	if err != nil {
 		log.Printf("[CandidateHandler] ListCandidates failed: %v", err)
 		http.Error(w, "failed to list candidates", http.StatusInternalServerError)
@@ -152,13 +199,14 @@ func (a *API) ListCandidatesHandler(w http.ResponseWriter, r *http.Request) {
 		candidates = []storage.CandidateListItem{}
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(listCandidatesResponse{
-		Candidates: candidates,
-		Total:      len(candidates),
-		Limit:      limit,
-		Offset:     offset,
-	})
+	writeJSONList(w, struct {
+		listCandidatesResponse
+		Candidates json.RawMessage `json:"candidates,omitempty"`
+	}{listCandidatesResponse: listCandidatesResponse{
+		Total:  len(candidates),
+		Limit:  limit,
+		Offset: offset,
+	}}, "candidates", candidates)
 }
 
 // GetCandidateHandler returns the full candidate profile including all interviews.
@@ -209,21 +257,27 @@ func (a *API) CreateInterviewHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	newID, err := a.db.CreateInterview(r.Context(), candidateID, iv)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "candidate not found", http.StatusNotFound)
+		return
+	}
 	if err != nil {
 		log.Printf("[CandidateHandler] CreateInterview(candidate=%d) failed: %v", candidateID, err)
 		http.Error(w, "failed to create interview", http.StatusInternalServerError)
 		return
 	}
 
+	a.audit(r, "create", "interview", strconv.Itoa(newID), map[string]int{"candidate_id": candidateID})
+
 	// Re-embed in background — don't block the HTTP response
-	go a.reEmbed(candidateID)
+	go a.reEmbed(tenant.OrgID(r.Context()), candidateID)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"id":           newID,
-		"candidate_id": candidateID,
-		"message":      "interview created; embedding update queued",
+	json.NewEncoder(w).Encode(createInterviewResponse{
+		ID:          newID,
+		CandidateID: candidateID,
+		Message:     "interview created; embedding update queued",
 	})
 }
 
@@ -261,11 +315,12 @@ func (a *API) UpdateInterviewHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "failed to update interview", http.StatusInternalServerError)
 		return
 	}
+	a.audit(r, "update", "interview", strconv.Itoa(interviewID), map[string]int{"candidate_id": candidateID})
 
-	go a.reEmbed(candidateID)
+	go a.reEmbed(tenant.OrgID(r.Context()), candidateID)
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"message": "interview updated; embedding update queued"})
+	json.NewEncoder(w).Encode(messageResponse{Message: "interview updated; embedding update queued"})
 }
 
 // DeleteInterviewHandler removes an interview record.
@@ -291,8 +346,9 @@ func (a *API) DeleteInterviewHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "failed to delete interview", http.StatusInternalServerError)
 		return
 	}
+	a.audit(r, "delete", "interview", strconv.Itoa(interviewID), map[string]int{"candidate_id": candidateID})
 
-	go a.reEmbed(candidateID)
+	go a.reEmbed(tenant.OrgID(r.Context()), candidateID)
 
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -305,22 +361,51 @@ type similarCandidatesResponse struct {
 	Similar           []storage.SimilarCandidate `json:"similar"`
 }
 
-// SimilarCandidatesHandler returns candidates whose embedding vector is closest
-// to the requested candidate's vector.
+// Similar-candidates blend: embedding similarity vs graph overlap, and the
+// weights of the overlap components (must each sum to 1).
+const (
+	similarEmbeddingWeight = 0.6
+	similarGraphWeight     = 0.4
+
+	overlapSkillWeight     = 0.5
+	overlapCompanyWeight   = 0.3
+	overlapCommunityWeight = 0.2
+)
+
+// SimilarCandidatesHandler returns "more like this person" look-alikes: the
+// nearest neighbours of the candidate's embedding blended with the people
+// who share the most skills, companies and communities in the graph.
 //
-//	GET /api/candidates/{id}/similar?top_k=5
+//	GET /api/candidates/{id}/similar?top_k=5&include_statuses=hired
 //
-// Requires the candidate to have been embedded (via a CV upload). Returns
-// at most top_k results (default 5, max 20). Candidates without an embedding
-// will receive an empty list.
+// {id} is a candidate ID or a person node ID ("person_12"). Returns at most
+// top_k results (default 5, max 20), ranked by score = 0.6·similarity +
+// 0.4·graph_overlap. Candidates without an embedding are ranked by graph
+// overlap alone; candidates not yet in the graph get an empty list. Like
+// searches, it leaves out hired, archived and do-not-contact candidates
+// unless include_statuses (comma-separated) asks for them.
 func (a *API) SimilarCandidatesHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	candidateID, err := parseCandidateID(r)
-	if err != nil {
+	ctx := r.Context()
+
+	var candidateID int
+	var err error
+	if raw := r.PathValue("id"); strings.HasPrefix(raw, "person_") {
+		ids, lookupErr := a.db.GetCandidateIDsByPersonNodeIDs(ctx, []string{raw})
+		if lookupErr != nil {
+			log.Printf("[Similar] GetCandidateIDsByPersonNodeIDs(%s): %v", raw, lookupErr)
+			http.Error(w, "candidate lookup failed", http.StatusInternalServerError)
+			return
+		}
+		if candidateID = ids[raw]; candidateID == 0 {
+			http.Error(w, "candidate not found", http.StatusNotFound)
+			return
+		}
+	} else if candidateID, err = parseCandidateID(r); err != nil {
 		http.Error(w, "invalid candidate id", http.StatusBadRequest)
 		return
 	}
@@ -332,8 +417,18 @@ func (a *API) SimilarCandidatesHandler(w http.ResponseWriter, r *http.Request) {
 			topK = n
 		}
 	}
+	statuses, errMsg := parseIncludeStatuses(strings.Split(r.URL.Query().Get("include_statuses"), ","))
+	if errMsg != "" {
+		http.Error(w, errMsg, http.StatusBadRequest)
+		return
+	}
+	ctx = graphrag.WithIncludedStatuses(ctx, statuses)
 
-	ctx := r.Context()
+	empty := similarCandidatesResponse{
+		SourceCandidateID: candidateID,
+		TopK:              topK,
+		Similar:           []storage.SimilarCandidate{},
+	}
 
 	// Step 1: get integer graph_node_id
 	graphNodeID, err := a.db.GetGraphNodeIDForCandidate(ctx, candidateID)
@@ -345,11 +440,7 @@ func (a *API) SimilarCandidatesHandler(w http.ResponseWriter, r *http.Request) {
 	if graphNodeID == 0 {
 		// Candidate exists but graph hasn't been built yet
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(similarCandidatesResponse{
-			SourceCandidateID: candidateID,
-			TopK:              topK,
-			Similar:           []storage.SimilarCandidate{},
-		})
+		json.NewEncoder(w).Encode(empty)
 		return
 	}
 
@@ -363,62 +454,89 @@ func (a *API) SimilarCandidatesHandler(w http.ResponseWriter, r *http.Request) {
 	if sourceNodeID == "" {
 		// Person node doesn't exist yet — graph not built
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(similarCandidatesResponse{
-			SourceCandidateID: candidateID,
-			TopK:              topK,
-			Similar:           []storage.SimilarCandidate{},
-		})
+		json.NewEncoder(w).Encode(empty)
 		return
 	}
 
-	// Step 3: load embedding vector
+	// Over-fetch from both sources so the blend can reorder across them.
+	poolSize := topK * 4
+
+	// Step 3: nearest-neighbour search via pgvector (skipped without an embedding)
+	simMap := make(map[string]float64)
 	embedding, err := a.db.GetPersonEmbedding(ctx, graphNodeID)
 	if err != nil {
 		log.Printf("[Similar] GetPersonEmbedding(graphNodeID=%d): %v", graphNodeID, err)
 		http.Error(w, "embedding lookup failed", http.StatusInternalServerError)
 		return
 	}
-	if len(embedding) == 0 {
-		// No embedding yet — return empty result set, not an error
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(similarCandidatesResponse{
-			SourceCandidateID: candidateID,
-			TopK:              topK,
-			Similar:           []storage.SimilarCandidate{},
-		})
-		return
-	}
-
-	// Step 4: nearest-neighbour search via pgvector
-	embSvc := a.hybridSearchEngine.GetEmbeddingService()
-	if embSvc == nil {
-		log.Printf("[Similar] embedding service unavailable")
-		http.Error(w, "embedding service unavailable", http.StatusServiceUnavailable)
-		return
+	if len(embedding) > 0 {
+		hybrid := a.ai(ctx).hybridSearchEngine
+		if hybrid == nil || hybrid.GetEmbeddingService() == nil {
+			log.Printf("[Similar] embedding service unavailable")
+			http.Error(w, "embedding service unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		// +1 so excluding the source candidate itself still leaves poolSize results
+		nodeIDs, sims, err := hybrid.GetEmbeddingService().SimilaritySearchByEmbedding(ctx, embedding, poolSize+1)
+		if err != nil {
+			log.Printf("[Similar] SimilaritySearchByEmbedding: %v", err)
+			http.Error(w, "similarity search failed", http.StatusInternalServerError)
+			return
+		}
+		for i, nid := range nodeIDs {
+			simMap[nid] = sims[i]
+		}
 	}
 
-	// Fetch topK+1 so we can exclude the source candidate itself from results
-	nodeIDs, sims, err := embSvc.SimilaritySearchByEmbedding(ctx, embedding, topK+1)
+	// Step 4: graph overlap (shared skills / companies / communities)
+	overlap, err := a.db.GetGraphOverlap(ctx, graphNodeID, poolSize)
 	if err != nil {
-		log.Printf("[Similar] SimilaritySearchByEmbedding: %v", err)
-		http.Error(w, "similarity search failed", http.StatusInternalServerError)
+		log.Printf("[Similar] GetGraphOverlap(graphNodeID=%d): %v", graphNodeID, err)
+		http.Error(w, "graph overlap query failed", http.StatusInternalServerError)
 		return
 	}
 
-	// Step 5: build similarity map and fetch full candidate info
-	simMap := make(map[string]float64, len(nodeIDs))
-	for i, nid := range nodeIDs {
-		simMap[nid] = sims[i]
+	pool := make([]string, 0, len(simMap)+len(overlap.Peers))
+	for nid := range simMap {
+		pool = append(pool, nid)
+	}
+	for nid := range overlap.Peers {
+		if _, ok := simMap[nid]; !ok {
+			pool = append(pool, nid)
+		}
 	}
 
-	similar, err := a.db.GetCandidatesByPersonNodeIDs(ctx, nodeIDs, sourceNodeID, simMap)
+	// Step 5: fetch candidate info and rank by the blended score
+	similar, err := a.db.GetCandidatesByPersonNodeIDs(ctx, pool, sourceNodeID, simMap)
 	if err != nil {
 		log.Printf("[Similar] GetCandidatesByPersonNodeIDs: %v", err)
 		http.Error(w, "enrichment query failed", http.StatusInternalServerError)
 		return
 	}
 
-	// Cap to topK (excludeNodeID may have already trimmed one result)
+	// The vector search already left out excluded statuses; graph peers
+	// are dropped here.
+	similar = slices.DeleteFunc(similar, func(sc storage.SimilarCandidate) bool {
+		return graphrag.StatusExcluded(ctx, sc.Status)
+	})
+
+	hasEmbedding := len(embedding) > 0
+	for i := range similar {
+		sc := &similar[i]
+		if peer := overlap.Peers[sc.PersonID]; peer != nil {
+			sc.SharedSkills = peer.SharedSkills
+			sc.SharedCompanies = peer.SharedCompanies
+			sc.SharedCommunities = peer.SharedCommunities
+			sc.GraphOverlap = graphOverlapScore(overlap, peer)
+		}
+		if hasEmbedding {
+			sc.Score = similarEmbeddingWeight*sc.Similarity + similarGraphWeight*sc.GraphOverlap
+		} else {
+			sc.Score = sc.GraphOverlap
+		}
+	}
+	sort.Slice(similar, func(i, j int) bool { return similar[i].Score > similar[j].Score })
+
 	if len(similar) > topK {
 		similar = similar[:topK]
 	}
@@ -433,3 +551,122 @@ func (a *API) SimilarCandidatesHandler(w http.ResponseWriter, r *http.Request) {
 		Similar:           similar,
 	})
 }
+
+// graphOverlapScore is the weighted share of the source's skills, companies
+// and communities that the peer also has.
+func graphOverlapScore(res *storage.GraphOverlapResult, peer *storage.GraphOverlap) float64 {
+	ratio := func(shared, total int) float64 {
+		if total == 0 {
+			return 0
+		}
+		if shared > total {
+			shared = total
+		}
+		return float64(shared) / float64(total)
+	}
+	return overlapSkillWeight*ratio(len(peer.SharedSkills), res.SourceSkills) +
+		overlapCompanyWeight*ratio(len(peer.SharedCompanies), res.SourceCompanies) +
+		overlapCommunityWeight*ratio(len(peer.SharedCommunities), res.SourceCommunities)
+}
+
+// DeleteCandidateHandler soft-deletes a candidate: the candidate, its CV files
+// and its person node disappear from search, listings and similarity, but the
+// data is kept (re-uploading the same CV restores it).
+// DELETE /api/candidates/{id}
+func (a *API) DeleteCandidateHandler(w http.ResponseWriter, r *http.Request) {
+	candidateID, err := parseCandidateID(r)
+	if err != nil {
+		http.Error(w, "invalid candidate id", http.StatusBadRequest)
+		return
+	}
+
+	found, err := a.db.SoftDeleteCandidate(r.Context(), candidateID)
+	if err != nil {
+		log.Printf("[CandidateHandler] SoftDeleteCandidate(%d) failed: %v", candidateID, err)
+		http.Error(w, "failed to delete candidate", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "candidate not found", http.StatusNotFound)
+		return
+	}
+	a.audit(r, "delete", "candidate", strconv.Itoa(candidateID), nil)
+
+	a.invalidateSearchCache(r.Context())
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type eraseCandidateRequest struct {
+	// KeepGraphStats keeps an anonymized person node (seniority, position,
+	// experience years and its skill/company edges) so aggregate statistics
+	// stay accurate. Default false: the node is deleted too.
+	KeepGraphStats bool `json:"keep_graph_stats"`
+}
+
+// EraseCandidateHandler performs a GDPR erasure: hard-deletes the candidate's
+// PII (candidate row, interviews, CV files on disk and in the database,
+// parsed text, extracted entities) and optionally keeps anonymized graph
+// statistics. Works on soft-deleted candidates as well. Irreversible.
+// POST /api/candidates/{id}/erase
+func (a *API) EraseCandidateHandler(w http.ResponseWriter, r *http.Request) {
+	candidateID, err := parseCandidateID(r)
+	if err != nil {
+		http.Error(w, "invalid candidate id", http.StatusBadRequest)
+		return
+	}
+
+	var req eraseCandidateRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	res, err := a.db.EraseCandidate(r.Context(), candidateID, req.KeepGraphStats)
+	if err != nil {
+		log.Printf("[CandidateHandler] EraseCandidate(%d) failed: %v", candidateID, err)
+		http.Error(w, "failed to erase candidate", http.StatusInternalServerError)
+		return
+	}
+	if res == nil {
+		http.Error(w, "candidate not found", http.StatusNotFound)
+		return
+	}
+
+	// The rows are gone; a leftover file is logged rather than failing the
+	// request, since the erasure itself can't be retried (the retention
+	// cleanup removes it later as an orphan). Blobs are content-addressed, so
+	// one still referenced by another CV row is kept.
+	filesRemoved := 0
+	for _, p := range res.FilePaths {
+		deleted, err := retention.DeleteOrphan(r.Context(), a.db, a.blobs, p, time.Now())
+		if err != nil {
+			log.Printf("[CandidateHandler] Erase(%d): failed to remove %s: %v", candidateID, p, err)
+			continue
+		}
+		if deleted {
+			filesRemoved++
+		}
+	}
+
+	// Only counts go in the trail — the erased data must not reappear here.
+	a.audit(r, "erase", "candidate", strconv.Itoa(candidateID), map[string]interface{}{
+		"keep_graph_stats": req.KeepGraphStats,
+		"cv_files_deleted": res.CVFilesDeleted,
+		"files_removed":    filesRemoved,
+		"graph_node":       res.GraphNode,
+	})
+
+	a.invalidateSearchCache(r.Context())
+	log.Printf("[CandidateHandler] Candidate %d erased (cv_files=%d, files=%d, graph_node=%q)",
+		candidateID, res.CVFilesDeleted, filesRemoved, res.GraphNode)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(eraseCandidateResponse{ErasureResult: *res, FilesRemoved: filesRemoved})
+}
+
+type eraseCandidateResponse struct {
+	storage.ErasureResult
+	FilesRemoved int `json:"files_removed"`
+}
@@ -0,0 +1,245 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"cv-search/internal/graphrag"
+	"cv-search/internal/storage"
+	"cv-search/internal/tenant"
+)
+
+// Limits of a custom community pattern.
+const (
+	maxPatternKeywords      = 100 // key skills, and key titles
+	maxPatternKeywordLength = 100 // characters, of the name and of each keyword
+)
+
+// patternIDPattern is what a community pattern ID looks like: the defaults'
+// are "backend", "ml-ai", "qa-test".
+var patternIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,39}$`)
+
+type communityPatternRequest struct {
+	Name      string   `json:"name"`
+	KeySkills []string `json:"key_skills"` // a candidate with these skills is in the community
+	KeyTitles []string `json:"key_titles"` // a search for these job titles is about the community
+}
+
+type communityPatternResponse struct {
+	ID               string     `json:"id"`
+	Name             string     `json:"name"`
+	KeySkills        []string   `json:"key_skills"`
+	KeyTitles        []string   `json:"key_titles"`
+	Source           string     `json:"source"`                      // default | custom
+	OverridesDefault bool       `json:"overrides_default,omitempty"` // a custom pattern replacing the default with its ID
+	UpdatedBy        string     `json:"updated_by,omitempty"`
+	UpdatedAt        *time.Time `json:"updated_at,omitempty"`
+}
+
+type communityPatternsResponse struct {
+	OrgID    int                        `json:"org_id"`
+	Patterns []communityPatternResponse `json:"patterns"` // what the organization's searches use, by ID
+}
+
+// validate normalizes the request and returns an error message if it is
+// invalid.
+func (req *communityPatternRequest) validate() string {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return "name is required"
+	}
+	if utf8.RuneCountInString(req.Name) > maxPatternKeywordLength {
+		return fmt.Sprintf("name exceeds %d characters", maxPatternKeywordLength)
+	}
+	for _, f := range []struct {
+		name  string
+		value *[]string
+	}{{"key_skills", &req.KeySkills}, {"key_titles", &req.KeyTitles}} {
+		seen := map[string]bool{}
+		out := []string{}
+		for _, kw := range *f.value {
+			kw = strings.Join(strings.Fields(kw), " ")
+			if kw == "" || seen[strings.ToLower(kw)] {
+				continue
+			}
+			// Keywords match as substrings both ways: a one-letter one
+			// would match nearly every skill.
+			if n := utf8.RuneCountInString(kw); n < 2 || n > maxPatternKeywordLength {
+				return fmt.Sprintf("%s: %q must be 2 to %d characters", f.name, kw, maxPatternKeywordLength)
+			}
+			seen[strings.ToLower(kw)] = true
+			out = append(out, kw)
+		}
+		if len(out) > maxPatternKeywords {
+			return fmt.Sprintf("%s exceeds %d entries", f.name, maxPatternKeywords)
+		}
+		*f.value = out
+	}
+	if len(req.KeySkills) == 0 && len(req.KeyTitles) == 0 {
+		return "key_skills or key_titles is required"
+	}
+	return ""
+}
+
+// communityPatterns returns the community patterns of ctx's organization's
+// searches: its own merged with the defaults, nil (the defaults) if it has
+// none or they can't be read.
+func (a *API) communityPatterns(ctx context.Context) graphrag.CommunityPatterns {
+	custom, err := a.db.ListOrgCommunityPatterns(ctx, tenant.OrgID(ctx))
+	if err != nil {
+		// Non-fatal: search with the defaults rather than fail.
+		log.Printf("[CommunityPatterns] %v", err)
+		return nil
+	}
+	if len(custom) == 0 {
+		return nil
+	}
+	patterns := make(graphrag.CommunityPatterns, len(custom))
+	for _, p := range custom {
+		patterns[p.PatternID] = graphrag.CommunityPattern{Name: p.Name, KeySkills: p.KeySkills, KeyTitles: p.KeyTitles}
+	}
+	return graphrag.MergeCommunityPatterns(patterns)
+}
+
+func customPatternResponse(p *storage.OrgCommunityPattern) communityPatternResponse {
+	_, isDefault := graphrag.DefaultCommunities[p.PatternID]
+	return communityPatternResponse{
+		ID:               p.PatternID,
+		Name:             p.Name,
+		KeySkills:        p.KeySkills,
+		KeyTitles:        p.KeyTitles,
+		Source:           "custom",
+		OverridesDefault: isDefault,
+		UpdatedBy:        p.UpdatedBy,
+		UpdatedAt:        &p.UpdatedAt,
+	}
+}
+
+// patternIDFromPath returns the {pattern} path value, "" (after answering
+// 400) if it isn't a valid ID.
+func patternIDFromPath(w http.ResponseWriter, r *http.Request) string {
+	id := strings.ToLower(r.PathValue("pattern"))
+	if !patternIDPattern.MatchString(id) || id == "general" {
+		http.Error(w, "invalid pattern id (lowercase letters, digits, - and _, at most 40; \"general\" is reserved)", http.StatusBadRequest)
+		return ""
+	}
+	return id
+}
+
+// ─── Organization community patterns (admin) ─────────────────────────────────
+
+// ListCommunityPatternsHandler lists the keyword community patterns an
+// organization's searches use: the defaults, and its own laid over them.
+//
+//	GET /api/admin/orgs/{id}/community-patterns
+func (a *API) ListCommunityPatternsHandler(w http.ResponseWriter, r *http.Request) {
+	orgID := a.orgIDFromPath(w, r)
+	if orgID == 0 {
+		return
+	}
+	custom, err := a.db.ListOrgCommunityPatterns(r.Context(), orgID)
+	if err != nil {
+		log.Printf("[CommunityPatterns] %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+
+	byID := map[string]communityPatternResponse{}
+	for id, p := range graphrag.DefaultCommunities {
+		byID[id] = communityPatternResponse{ID: id, Name: p.Name, KeySkills: p.KeySkills, KeyTitles: []string{}, Source: "default"}
+	}
+	for i := range custom {
+		byID[custom[i].PatternID] = customPatternResponse(&custom[i])
+	}
+	resp := communityPatternsResponse{OrgID: orgID, Patterns: make([]communityPatternResponse, 0, len(byID))}
+	for _, p := range byID {
+		resp.Patterns = append(resp.Patterns, p)
+	}
+	sort.Slice(resp.Patterns, func(i, j int) bool { return resp.Patterns[i].ID < resp.Patterns[j].ID })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// PutCommunityPatternHandler creates or replaces one of an organization's
+// community patterns; with a default's ID it replaces that default for the
+// organization. Its searches use it from the next one on.
+//
+//	PUT /api/admin/orgs/{id}/community-patterns/{pattern} {"name": "Payments Engineers", "key_skills": ["ISO 8583", "PCI DSS"], "key_titles": ["payments engineer"]}
+func (a *API) PutCommunityPatternHandler(w http.ResponseWriter, r *http.Request) {
+	orgID := a.orgIDFromPath(w, r)
+	if orgID == 0 {
+		return
+	}
+	patternID := patternIDFromPath(w, r)
+	if patternID == "" {
+		return
+	}
+	var req communityPatternRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	p := &storage.OrgCommunityPattern{
+		PatternID: patternID,
+		Name:      req.Name,
+		KeySkills: req.KeySkills,
+		KeyTitles: req.KeyTitles,
+		UpdatedBy: actorFromRequest(r),
+	}
+	if err := a.db.SaveOrgCommunityPattern(r.Context(), orgID, p); err != nil {
+		log.Printf("[CommunityPatterns] %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	a.audit(r, "update", "organization_community_pattern", strconv.Itoa(orgID), map[string]interface{}{
+		"pattern_id": patternID, "name": p.Name, "key_skills": len(p.KeySkills), "key_titles": len(p.KeyTitles),
+	})
+	a.invalidateSearchCache(tenant.WithOrg(r.Context(), orgID))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(customPatternResponse(p))
+}
+
+// DeleteCommunityPatternHandler removes one of an organization's community
+// patterns; a default it replaced applies again.
+//
+//	DELETE /api/admin/orgs/{id}/community-patterns/{pattern}
+func (a *API) DeleteCommunityPatternHandler(w http.ResponseWriter, r *http.Request) {
+	orgID := a.orgIDFromPath(w, r)
+	if orgID == 0 {
+		return
+	}
+	patternID := patternIDFromPath(w, r)
+	if patternID == "" {
+		return
+	}
+	deleted, err := a.db.DeleteOrgCommunityPattern(r.Context(), orgID, patternID)
+	if err != nil {
+		log.Printf("[CommunityPatterns] %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		http.Error(w, "community pattern not found", http.StatusNotFound)
+		return
+	}
+	a.audit(r, "delete", "organization_community_pattern", strconv.Itoa(orgID), map[string]interface{}{"pattern_id": patternID})
+	a.invalidateSearchCache(tenant.WithOrg(r.Context(), orgID))
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+)
+
+// compressMinBytes is the smallest response worth compressing; shorter ones
+// go out as they are.
+const compressMinBytes = 1024
+
+// compressEncoder is a pooled gzip or brotli stream.
+type compressEncoder interface {
+	io.Writer
+	Flush() error
+	Close() error
+	Reset(io.Writer)
+}
+
+// compressEncoders are the pools of each content coding's encoders.
+var compressEncoders = map[string]*sync.Pool{
+	"br":   {New: func() interface{} { return brotli.NewWriterLevel(nil, brotli.DefaultCompression) }},
+	"gzip": {New: func() interface{} { return gzip.NewWriter(nil) }},
+}
+
+// compressMiddleware compresses responses for clients that accept it
+// (Accept-Encoding: br or gzip, by q-value; brotli on a tie): a hybrid
+// search with 100+ enriched candidates is several MB of JSON and compresses
+// about tenfold. Responses shorter than compressMinBytes, already encoded
+// ones, partial content, event streams and formats that are compressed
+// already (CV downloads, photos) go out as they are.
+func compressMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if r.Method == http.MethodHead || encoding == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		cw := &compressWriter{ResponseWriter: w, encoding: encoding}
+		defer cw.close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// negotiateEncoding picks the content coding an Accept-Encoding header
+// prefers: "br", "gzip" (the one with the higher q-value, brotli on a tie;
+// "*" stands for a coding not listed) or "" when it allows neither.
+func negotiateEncoding(header string) string {
+	q := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "br" && coding != "gzip" && coding != "*" {
+			continue
+		}
+		weight := 1.0
+		if v, ok := strings.CutPrefix(strings.ReplaceAll(params, " ", ""), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				weight = f
+			}
+		}
+		q[coding] = weight
+	}
+	best, bestQ := "", 0.0
+	for _, coding := range []string{"br", "gzip"} {
+		weight, ok := q[coding]
+		if !ok {
+			weight, ok = q["*"]
+		}
+		if ok && weight > bestQ {
+			best, bestQ = coding, weight
+		}
+	}
+	return best
+}
+
+// compressibleType reports whether a Content-Type is worth compressing.
+func compressibleType(contentType string) bool {
+	ct, _, _ := strings.Cut(strings.ToLower(contentType), ";")
+	ct = strings.TrimSpace(ct)
+	switch {
+	case ct == "text/event-stream":
+		return false // flushed event by event; proxies handle it uncompressed best
+	case strings.HasPrefix(ct, "text/"),
+		ct == "application/json", ct == "application/javascript", ct == "application/xml",
+		ct == "image/svg+xml",
+		strings.HasSuffix(ct, "+json"), strings.HasSuffix(ct, "+xml"):
+		return true
+	}
+	return false
+}
+
+// compressWriter holds the status and the first compressMinBytes of a
+// response, then decides whether to compress it with encoding.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string // "br" or "gzip"
+	status   int
+	buf      []byte
+	decided  bool
+	enc      compressEncoder // nil when the response goes out as it is
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if w.decided || w.status != 0 {
+		return
+	}
+	w.status = code
+	if code < http.StatusOK || code == http.StatusNoContent || code == http.StatusNotModified {
+		w.decide(false)
+	}
+}
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if !w.decided {
+		w.buf = append(w.buf, p...)
+		if len(w.buf) >= compressMinBytes {
+			if err := w.decide(true); err != nil {
+				return 0, err
+			}
+		}
+		return len(p), nil
+	}
+	if w.enc != nil {
+		return w.enc.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// decide writes the header, compressed if large is set and the response
+// qualifies, and then the bytes held so far.
+func (w *compressWriter) decide(large bool) error {
+	w.decided = true
+	h := w.Header()
+	if h.Get("Content-Type") == "" && len(w.buf) > 0 {
+		h.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+	if large && w.status != http.StatusPartialContent && h.Get("Content-Encoding") == "" &&
+		h.Get("Content-Range") == "" && compressibleType(h.Get("Content-Type")) {
+		h.Set("Content-Encoding", w.encoding)
+		h.Del("Content-Length")
+		// The compressed body is a different representation of the same
+		// response (see response_cache.go's ETags).
+		if etag := h.Get("ETag"); strings.HasPrefix(etag, `"`) {
+			h.Set("ETag", "W/"+etag)
+		}
+		w.enc = compressEncoders[w.encoding].Get().(compressEncoder)
+		w.enc.Reset(w.ResponseWriter)
+	}
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	if len(w.buf) == 0 {
+		return nil
+	}
+	buf := w.buf
+	w.buf = nil
+	if w.enc != nil {
+		_, err := w.enc.Write(buf)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+// Flush sends what was written so far (an event stream's events).
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide(false)
+	}
+	if w.enc != nil {
+		w.enc.Flush()
+	}
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// close writes a response that stayed under compressMinBytes and finishes
+// the compressed stream.
+func (w *compressWriter) close() {
+	if !w.decided && (w.status != 0 || len(w.buf) > 0) {
+		w.decide(false)
+	}
+	if w.enc != nil {
+		w.enc.Close()
+		w.enc.Reset(nil)
+		compressEncoders[w.encoding].Put(w.enc)
+		w.enc = nil
+	}
+}
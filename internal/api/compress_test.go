@@ -0,0 +1,272 @@
+package api
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"identity", ""},
+		{"gzip", "gzip"},
+		{"br", "br"},
+		{"gzip, deflate, br", "br"},
+		{"GZIP", "gzip"},
+		{"gzip;q=1, br;q=0.5", "gzip"},
+		{"gzip;q=0.5, br;q=0.8", "br"},
+		{"gzip; q=0.7, br; q=0.7", "br"},
+		{"br;q=0, gzip", "gzip"},
+		{"gzip;q=0", ""},
+		{"gzip;q=0.0, br;q=0", ""},
+		{"*", "br"},
+		{"*;q=0", ""},
+		{"br;q=0, *", "gzip"},
+		{"*;q=0.2, gzip;q=0.5", "gzip"},
+		{"*;q=0, gzip", "gzip"},
+	}
+	for _, tt := range tests {
+		if got := negotiateEncoding(tt.header); got != tt.want {
+			t.Errorf("negotiateEncoding(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestCompressibleType(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        bool
+	}{
+		{"application/json", true},
+		{"application/json; charset=utf-8", true},
+		{"text/html; charset=utf-8", true},
+		{"text/csv", true},
+		{"application/javascript", true},
+		{"application/xml", true},
+		{"image/svg+xml", true},
+		{"application/problem+json", true},
+		{"text/event-stream", false},
+		{"application/pdf", false},
+		{"image/png", false},
+		{"application/octet-stream", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := compressibleType(tt.contentType); got != tt.want {
+			t.Errorf("compressibleType(%q) = %v, want %v", tt.contentType, got, tt.want)
+		}
+	}
+}
+
+// serveCompressed runs handler behind compressMiddleware for a method
+// request that sends acceptEncoding.
+func serveCompressed(t *testing.T, method, acceptEncoding string, handler http.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, "/api/search", nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	rec := httptest.NewRecorder()
+	compressMiddleware(handler).ServeHTTP(rec, req)
+	return rec
+}
+
+// decodeBody undoes the response's Content-Encoding.
+func decodeBody(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var r io.Reader = rec.Body
+	switch enc := rec.Header().Get("Content-Encoding"); enc {
+	case "":
+	case "gzip":
+		gz, err := gzip.NewReader(rec.Body)
+		if err != nil {
+			t.Fatalf("gzip.NewReader: %v", err)
+		}
+		r = gz
+	case "br":
+		r = brotli.NewReader(rec.Body)
+	default:
+		t.Fatalf("unexpected Content-Encoding %q", enc)
+	}
+	body, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("reading %s body: %v", rec.Header().Get("Content-Encoding"), err)
+	}
+	return string(body)
+}
+
+func jsonHandler(status int, body string, header map[string]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		for k, v := range header {
+			w.Header().Set(k, v)
+		}
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}
+}
+
+func TestCompressMiddleware(t *testing.T) {
+	large := `{"results":"` + strings.Repeat("candidate ", compressMinBytes/10+1) + `"}`
+	small := `{"results":[]}`
+
+	tests := []struct {
+		name           string
+		method         string
+		acceptEncoding string
+		handler        http.HandlerFunc
+		wantStatus     int
+		wantEncoding   string
+		wantETag       string
+		wantBody       string
+	}{
+		{
+			name: "large JSON, gzip", acceptEncoding: "gzip",
+			handler:    jsonHandler(http.StatusOK, large, nil),
+			wantStatus: http.StatusOK, wantEncoding: "gzip", wantBody: large,
+		},
+		{
+			name: "large JSON, brotli preferred", acceptEncoding: "gzip, deflate, br",
+			handler:    jsonHandler(http.StatusOK, large, nil),
+			wantStatus: http.StatusOK, wantEncoding: "br", wantBody: large,
+		},
+		{
+			name: "gzip weighed higher than brotli", acceptEncoding: "br;q=0.5, gzip",
+			handler:    jsonHandler(http.StatusOK, large, nil),
+			wantStatus: http.StatusOK, wantEncoding: "gzip", wantBody: large,
+		},
+		{
+			name:       "no Accept-Encoding",
+			handler:    jsonHandler(http.StatusOK, large, nil),
+			wantStatus: http.StatusOK, wantBody: large,
+		},
+		{
+			name: "gzip refused with q=0", acceptEncoding: "gzip;q=0",
+			handler:    jsonHandler(http.StatusOK, large, nil),
+			wantStatus: http.StatusOK, wantBody: large,
+		},
+		{
+			name: "under compressMinBytes", acceptEncoding: "gzip",
+			handler:    jsonHandler(http.StatusOK, small, nil),
+			wantStatus: http.StatusOK, wantBody: small,
+		},
+		{
+			name: "exactly compressMinBytes", acceptEncoding: "gzip",
+			handler:    jsonHandler(http.StatusOK, strings.Repeat("x", compressMinBytes), nil),
+			wantStatus: http.StatusOK, wantEncoding: "gzip", wantBody: strings.Repeat("x", compressMinBytes),
+		},
+		{
+			name: "strong ETag turns weak", acceptEncoding: "gzip",
+			handler:    jsonHandler(http.StatusOK, large, map[string]string{"ETag": `"abc123"`}),
+			wantStatus: http.StatusOK, wantEncoding: "gzip", wantETag: `W/"abc123"`, wantBody: large,
+		},
+		{
+			name: "weak ETag stays", acceptEncoding: "gzip",
+			handler:    jsonHandler(http.StatusOK, large, map[string]string{"ETag": `W/"abc123"`}),
+			wantStatus: http.StatusOK, wantEncoding: "gzip", wantETag: `W/"abc123"`, wantBody: large,
+		},
+		{
+			name: "uncompressed response keeps strong ETag", acceptEncoding: "gzip",
+			handler:    jsonHandler(http.StatusOK, small, map[string]string{"ETag": `"abc123"`}),
+			wantStatus: http.StatusOK, wantETag: `"abc123"`, wantBody: small,
+		},
+		{
+			name: "204", acceptEncoding: "gzip",
+			handler:    jsonHandler(http.StatusNoContent, "", nil),
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name: "304", acceptEncoding: "gzip",
+			handler:    jsonHandler(http.StatusNotModified, "", map[string]string{"ETag": `"abc123"`}),
+			wantStatus: http.StatusNotModified, wantETag: `"abc123"`,
+		},
+		{
+			name: "206", acceptEncoding: "gzip",
+			handler: jsonHandler(http.StatusPartialContent, large, map[string]string{
+				"Content-Range": "bytes 0-99/5000",
+			}),
+			wantStatus: http.StatusPartialContent, wantBody: large,
+		},
+		{
+			name: "already encoded", acceptEncoding: "gzip",
+			handler:    jsonHandler(http.StatusOK, large, map[string]string{"Content-Encoding": "identity"}),
+			wantStatus: http.StatusOK, wantEncoding: "identity",
+		},
+		{
+			name: "PDF download", acceptEncoding: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/pdf")
+				io.WriteString(w, large)
+			},
+			wantStatus: http.StatusOK, wantBody: large,
+		},
+		{
+			name: "event stream", acceptEncoding: "gzip, br",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				io.WriteString(w, large)
+				w.(http.Flusher).Flush()
+			},
+			wantStatus: http.StatusOK, wantBody: large,
+		},
+		{
+			name: "HEAD", method: http.MethodHead, acceptEncoding: "gzip",
+			handler:    jsonHandler(http.StatusOK, large, nil),
+			wantStatus: http.StatusOK, wantBody: large,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			rec := serveCompressed(t, method, tt.acceptEncoding, tt.handler)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Errorf("Content-Encoding = %q, want %q", got, tt.wantEncoding)
+			}
+			if got := rec.Header().Get("ETag"); got != tt.wantETag {
+				t.Errorf("ETag = %q, want %q", got, tt.wantETag)
+			}
+			if got := rec.Header().Values("Vary"); len(got) != 1 || got[0] != "Accept-Encoding" {
+				t.Errorf("Vary = %q, want [Accept-Encoding]", got)
+			}
+			if tt.wantEncoding == "identity" {
+				return
+			}
+			if body := decodeBody(t, rec); body != tt.wantBody {
+				t.Errorf("body = %d bytes, want %d", len(body), len(tt.wantBody))
+			}
+		})
+	}
+}
+
+// TestCompressMiddlewareReusesEncoders checks that pooled encoders are
+// reset between responses: a second response must not carry the first
+// one's stream.
+func TestCompressMiddlewareReusesEncoders(t *testing.T) {
+	for _, encoding := range []string{"gzip", "br"} {
+		for i := 0; i < 3; i++ {
+			body := strings.Repeat(string(rune('a'+i)), compressMinBytes*2)
+			rec := serveCompressed(t, http.MethodGet, encoding, jsonHandler(http.StatusOK, body, nil))
+			if got := rec.Header().Get("Content-Encoding"); got != encoding {
+				t.Fatalf("%s response %d: Content-Encoding = %q", encoding, i, got)
+			}
+			if got := decodeBody(t, rec); got != body {
+				t.Errorf("%s response %d: body does not round-trip", encoding, i)
+			}
+		}
+	}
+}
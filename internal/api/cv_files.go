@@ -0,0 +1,314 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"cv-search/internal/cv"
+	"cv-search/internal/storage"
+)
+
+// cvContentTypes maps accepted upload extensions to the MIME type used when
+// storing and serving them.
+var cvContentTypes = map[string]string{
+	".pdf":   "application/pdf",
+	".docx":  "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".doc":   "application/msword",
+	".odt":   "application/vnd.oasis.opendocument.text",
+	".rtf":   "application/rtf",
+	".pages": "application/vnd.apple.pages",
+	".txt":   "text/plain; charset=utf-8",
+	".md":    "text/markdown; charset=utf-8",
+	".html":  "text/html; charset=utf-8",
+	".htm":   "text/html; charset=utf-8",
+	".eml":   "message/rfc822",
+	".msg":   "application/vnd.ms-outlook",
+}
+
+// supportedCVTypes is the accepted-formats list used in error messages.
+const supportedCVTypes = "PDF, DOCX, DOC, ODT, RTF, Pages, TXT, Markdown, HTML, EML, MSG"
+
+func cvContentType(filename string) string {
+	if ct, ok := cvContentTypes[strings.ToLower(filepath.Ext(filename))]; ok {
+		return ct
+	}
+	return "application/octet-stream"
+}
+
+// cvDeclaredTypes lists, per extension, the Content-Types a client may send
+// for it besides the canonical one. Generic types are always accepted since
+// many clients send nothing more specific.
+var cvDeclaredTypes = map[string][]string{
+	".pdf":   {"application/x-pdf"},
+	".docx":  {"application/zip"},
+	".doc":   {"application/vnd.ms-word"},
+	".odt":   {"application/zip"},
+	".rtf":   {"text/rtf", "application/x-rtf"},
+	".pages": {"application/zip", "application/x-iwork-pages-sffpages"},
+	".txt":   {"text/plain"},
+	".md":    {"text/plain", "text/x-markdown"},
+	".html":  {"application/xhtml+xml"},
+	".htm":   {"application/xhtml+xml"},
+	".eml":   {"text/plain", "message/x-emlx"},
+	".msg":   {"application/x-msg", "application/vnd.ms-office"},
+}
+
+// dangerousExtensions are never accepted before the final extension, so
+// that "cv.exe.pdf" or "cv.html.pdf" can't be passed off as a CV.
+var dangerousExtensions = map[string]bool{
+	".exe": true, ".dll": true, ".com": true, ".bat": true, ".cmd": true, ".msi": true, ".scr": true,
+	".ps1": true, ".vbs": true, ".js": true, ".jar": true, ".sh": true, ".php": true, ".py": true,
+	".html": true, ".htm": true, ".svg": true, ".xhtml": true, ".hta": true, ".lnk": true,
+	".docm": true, ".dotm": true, ".xlsm": true,
+}
+
+// checkCVUpload validates an uploaded file's name and declared Content-Type
+// and returns the name to keep as metadata (base name, control characters
+// removed). The name never reaches a storage path; see cvObjectKey.
+func checkCVUpload(filename, declaredType string) (string, error) {
+	name := displayFilename(filename)
+	if name == "" {
+		return "", errors.New("missing file name")
+	}
+	lower := strings.ToLower(name)
+	ext := filepath.Ext(lower)
+	if _, ok := cvContentTypes[ext]; !ok {
+		return "", errors.New("invalid file type (supported: " + supportedCVTypes + ")")
+	}
+	parts := strings.Split(lower, ".")
+	for _, part := range parts[1 : len(parts)-1] {
+		if dangerousExtensions["."+part] {
+			return "", fmt.Errorf("file name %q has a disallowed extension .%s", name, part)
+		}
+	}
+
+	if declaredType != "" {
+		mt, _, err := mime.ParseMediaType(declaredType)
+		if err != nil {
+			return "", fmt.Errorf("invalid content type %q", declaredType)
+		}
+		canonical, _, _ := mime.ParseMediaType(cvContentTypes[ext])
+		ok := mt == canonical || mt == "application/octet-stream" || mt == "binary/octet-stream"
+		for _, alt := range cvDeclaredTypes[ext] {
+			ok = ok || mt == alt
+		}
+		if !ok {
+			return "", fmt.Errorf("content type %s does not match a %s file", mt, ext)
+		}
+	}
+	return name, nil
+}
+
+// displayFilename reduces a client-supplied name to its base name (either
+// path separator) without control characters, capped at 255 bytes.
+func displayFilename(filename string) string {
+	if i := strings.LastIndexAny(filename, `/\`); i >= 0 {
+		filename = filename[i+1:]
+	}
+	name := strings.TrimSpace(strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == utf8.RuneError {
+			return -1
+		}
+		return r
+	}, filename))
+	if name == "." || name == ".." {
+		return ""
+	}
+	if len(name) > 255 {
+		ext := filepath.Ext(name)
+		if len(ext) > 16 {
+			ext = ""
+		}
+		name = strings.ToValidUTF8(name[:255-len(ext)], "") + ext
+	}
+	return name
+}
+
+// safeObjectName turns a file name into [A-Za-z0-9._-] only, for use in
+// object keys: "Ayşe Yılmaz CV (2).pdf" -> "ayse-yilmaz-cv-2.pdf".
+func safeObjectName(filename string) string {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if _, ok := cvContentTypes[ext]; !ok {
+		ext = ""
+	}
+	base := strings.ToLower(strings.TrimSuffix(filename, filepath.Ext(filename)))
+	base = strings.NewReplacer("ç", "c", "ğ", "g", "ı", "i", "ö", "o", "ş", "s", "ü", "u", "i̇", "i").Replace(base)
+
+	var b strings.Builder
+	dash := false
+	for _, r := range base {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+		if b.Len() >= 64 {
+			break
+		}
+	}
+	name := strings.Trim(b.String(), "-")
+	if name == "" {
+		name = "cv"
+	}
+	return name + ext
+}
+
+// cvObjectKey returns a fresh blob key for an upload,
+// cvs/<yyyy>/<mm>/<random id>-<safe name>. The random part keeps two
+// uploads with the same name from overwriting each other.
+func cvObjectKey(filename string, now time.Time) (string, error) {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return "", fmt.Errorf("generate object key: %w", err)
+	}
+	return fmt.Sprintf("cvs/%s/%s-%s", now.UTC().Format("2006/01"), hex.EncodeToString(id[:]), safeObjectName(filename)), nil
+}
+
+// anonymizeRequested reports whether an upload's PII should be masked:
+// always with ANONYMIZE_PII=true, else when the anonymize form/query field is
+// true.
+func (a *API) anonymizeRequested(r *http.Request) (bool, error) {
+	if a.cfg.AnonymizePII {
+		return true, nil
+	}
+	v := r.FormValue("anonymize")
+	if v == "" {
+		return false, nil
+	}
+	anonymize, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid anonymize value %q", v)
+	}
+	return anonymize, nil
+}
+
+// storeUploadedCV copies an uploaded file into the blob store; see storeCVBlob.
+func (a *API) storeUploadedCV(ctx context.Context, fh *multipart.FileHeader, filename string) (string, error) {
+	f, err := fh.Open()
+	if err != nil {
+		return "", fmt.Errorf("open upload: %w", err)
+	}
+	defer f.Close()
+	return a.storeCVBlob(ctx, f, fh.Size, filename)
+}
+
+// storeCVBlob writes a CV file into the blob store under a new server-side
+// key (cvObjectKey) and returns it; that key is what cv_files.file_path
+// holds, while the client's file name is only kept in cv_files.filename. If
+// the DB row is never written, the blob is left as an orphan for
+// internal/retention to remove after the orphan TTL.
+func (a *API) storeCVBlob(ctx context.Context, f io.Reader, size int64, filename string) (string, error) {
+	key, err := cvObjectKey(filename, time.Now())
+	if err != nil {
+		return "", err
+	}
+	if err := a.db.TouchBlob(ctx, key, size); err != nil {
+		return "", err
+	}
+	if err := a.blobs.Put(ctx, key, f, size, cvContentType(filename)); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// storeCVPhoto writes a CV's embedded photo into the blob store, like
+// storeCVBlob, and returns its key.
+func (a *API) storeCVPhoto(ctx context.Context, photo *cv.Photo) (string, error) {
+	name := "photo.jpg"
+	if photo.ContentType == "image/png" {
+		name = "photo.png"
+	}
+	key, err := cvObjectKey(name, time.Now())
+	if err != nil {
+		return "", err
+	}
+	size := int64(len(photo.Data))
+	if err := a.db.TouchBlob(ctx, key, size); err != nil {
+		return "", err
+	}
+	if err := a.blobs.Put(ctx, key, bytes.NewReader(photo.Data), size, photo.ContentType); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// saveParsedCV stores a parsed CV's row and its pending processing job in
+// one transaction (see storage.SaveCVFileWithJob), flagging OCR'd and
+// anonymized text and keeping the sections and DOCX header the parser
+// recognized, and the upload's candidate, source and tags (opts.Force is
+// ignored). The embedded photo is only kept with KEEP_CV_PHOTOS.
+func (a *API) saveParsedCV(ctx context.Context, opts uploadOptions, parsedCV *cv.ParsedCV, blobKey, contentHash string) (cvID int, jobID int64, err error) {
+	var photoKey string
+	if parsedCV.Photo != nil && a.cfg.KeepCVPhotos {
+		if photoKey, err = a.storeCVPhoto(ctx, parsedCV.Photo); err != nil {
+			log.Printf("[CVUpload] %s: store photo: %v", parsedCV.Filename, err)
+			photoKey = ""
+		}
+	}
+	// Chunked after anonymization, so masked CVs have masked chunks.
+	chunkText := parsedCV.FullText
+	if parsedCV.Sections != nil {
+		chunkText = cv.FormatSections(parsedCV.Sections)
+	}
+	chunks := cv.ChunkText(chunkText, cv.ChunkTokens)
+	err = a.db.WithTx(ctx, func(tx *storage.DB) error {
+		var txErr error
+		cvID, jobID, txErr = tx.SaveCVFileWithJob(ctx, opts.CandidateID, parsedCV.Filename,
+			blobKey, parsedCV.FileType, parsedCV.FullText, parsedCV.FileSize, contentHash)
+		if txErr == nil && parsedCV.OCRUsed {
+			txErr = tx.MarkCVFileOCR(ctx, cvID)
+		}
+		if txErr == nil && parsedCV.Anonymized {
+			txErr = tx.MarkCVFileAnonymized(ctx, cvID)
+		}
+		if txErr == nil && parsedCV.Sections != nil {
+			var data []byte
+			if data, txErr = cv.MarshalSections(parsedCV.Sections); txErr == nil {
+				txErr = tx.SaveCVSections(ctx, int64(cvID), data)
+			}
+		}
+		if txErr == nil && !parsedCV.Header.Empty() {
+			var data []byte
+			if data, txErr = json.Marshal(parsedCV.Header); txErr == nil {
+				txErr = tx.SaveCVHeader(ctx, int64(cvID), data)
+			}
+		}
+		if txErr == nil && (opts.Source != "" || len(opts.Tags) > 0) {
+			txErr = tx.SetCVFileUploadMetadata(ctx, int64(cvID), opts.Source, opts.Tags)
+		}
+		if txErr == nil && photoKey != "" {
+			txErr = tx.SetCVFilePhoto(ctx, int64(cvID), photoKey)
+		}
+		if txErr == nil && len(chunks) > 0 {
+			txErr = tx.SaveCVChunks(ctx, int64(cvID), storageChunks(chunks))
+		}
+		return txErr
+	})
+	return cvID, jobID, err
+}
+
+// storageChunks converts chunks for SaveCVChunks.
+func storageChunks(chunks []cv.Chunk) []storage.CVChunk {
+	out := make([]storage.CVChunk, len(chunks))
+	for i, c := range chunks {
+		out[i] = storage.CVChunk{Index: c.Index, Text: c.Text, Tokens: c.Tokens}
+	}
+	return out
+}
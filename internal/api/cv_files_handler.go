@@ -0,0 +1,207 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"cv-search/internal/cv"
+	"cv-search/internal/storage"
+)
+
+type listCVFilesResponse struct {
+	Files  []storage.CVFileListItem `json:"files"`
+	Total  int                      `json:"total"`
+	Limit  int                      `json:"limit"`
+	Offset int                      `json:"offset"`
+}
+
+// ListCVFilesHandler lists uploaded CVs, newest first. quality=needs_review
+// returns the parses flagged by the quality scorer for a human to check.
+func (a *API) ListCVFilesHandler(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	offset := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 200 {
+			limit = n
+		}
+	}
+	if v := r.URL.Query().Get("offset"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			offset = n
+		}
+	}
+	quality := r.URL.Query().Get("quality")
+	switch quality {
+	case "", cv.QualityPending, cv.QualityOK, cv.QualityNeedsReview:
+	default:
+		http.Error(w, "invalid quality (use pending, ok or needs_review)", http.StatusBadRequest)
+		return
+	}
+
+	files, err := a.db.ListCVFiles(r.Context(), quality, limit, offset)
+	if err != nil {
+		log.Printf("[CVHandler] ListCVFiles failed: %v", err)
+		http.Error(w, "failed to list cv files", http.StatusInternalServerError)
+		return
+	}
+	if files == nil {
+		files = []storage.CVFileListItem{}
+	}
+
+	writeJSONList(w, struct {
+		listCVFilesResponse
+		Files json.RawMessage `json:"files,omitempty"`
+	}{listCVFilesResponse: listCVFilesResponse{
+		Total:  len(files),
+		Limit:  limit,
+		Offset: offset,
+	}}, "files", files)
+}
+
+type cvChangesResponse struct {
+	CVID         int64       `json:"cv_id"`
+	PreviousCVID *int64      `json:"previous_cv_id"`
+	Changed      bool        `json:"changed"`
+	Changes      *cv.Changes `json:"changes"`
+}
+
+// CVChangesHandler returns what changed in a CV compared with the same
+// candidate's previous upload (matched by email): added/removed skills, new
+// employers, certifications and languages, seniority and position changes.
+// previous_cv_id and changes are null for a first upload or a CV that hasn't
+// been extracted yet.
+func (a *API) CVChangesHandler(w http.ResponseWriter, r *http.Request) {
+	cvFileID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || cvFileID <= 0 {
+		http.Error(w, "invalid cv file id", http.StatusBadRequest)
+		return
+	}
+
+	previousID, changes, found, err := a.db.GetCVChanges(r.Context(), cvFileID)
+	if err != nil {
+		log.Printf("[CVChanges] GetCVChanges(%d) failed: %v", cvFileID, err)
+		http.Error(w, "failed to load cv changes", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "cv file not found", http.StatusNotFound)
+		return
+	}
+
+	var diff *cv.Changes
+	if changes != nil {
+		diff = &cv.Changes{}
+		if err := json.Unmarshal(changes, diff); err != nil {
+			log.Printf("[CVChanges] CV %d: decode changes: %v", cvFileID, err)
+			http.Error(w, "failed to load cv changes", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cvChangesResponse{
+		CVID:         cvFileID,
+		PreviousCVID: previousID,
+		Changed:      diff != nil && !diff.Empty(),
+		Changes:      diff,
+	})
+}
+
+// DownloadCVHandler streams the original uploaded file from the blob store.
+// GET /api/cv/files/{id}/download
+func (a *API) DownloadCVHandler(w http.ResponseWriter, r *http.Request) {
+	cvFileID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || cvFileID <= 0 {
+		http.Error(w, "invalid cv file id", http.StatusBadRequest)
+		return
+	}
+
+	info, err := a.db.GetCVFile(r.Context(), cvFileID)
+	if err != nil {
+		log.Printf("[CVDownload] GetCVFile(%d) failed: %v", cvFileID, err)
+		http.Error(w, "failed to load cv file", http.StatusInternalServerError)
+		return
+	}
+	if info == nil || info.FilePath == "" {
+		http.Error(w, "cv file not found", http.StatusNotFound)
+		return
+	}
+
+	body, err := a.blobs.Get(r.Context(), info.FilePath)
+	if errors.Is(err, storage.ErrBlobNotFound) {
+		http.Error(w, "stored file not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("[CVDownload] blob get %s failed: %v", info.FilePath, err)
+		http.Error(w, "failed to read stored file", http.StatusBadGateway)
+		return
+	}
+	defer body.Close()
+
+	w.Header().Set("Content-Type", cvContentType(info.Filename))
+	// HTML CVs come from untrusted candidates: never render them in our origin.
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "sandbox")
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": info.Filename})
+	if disposition == "" {
+		disposition = mime.FormatMediaType("attachment", map[string]string{"filename": safeObjectName(info.Filename)})
+	}
+	w.Header().Set("Content-Disposition", disposition)
+	if info.FileSize > 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(info.FileSize, 10))
+	}
+	if _, err := io.Copy(w, body); err != nil {
+		log.Printf("[CVDownload] streaming cv file %d interrupted: %v", cvFileID, err)
+	}
+}
+
+// CVPhotoHandler streams the photo embedded in a Word CV, if it was kept
+// (KEEP_CV_PHOTOS).
+func (a *API) CVPhotoHandler(w http.ResponseWriter, r *http.Request) {
+	cvFileID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || cvFileID <= 0 {
+		http.Error(w, "invalid cv file id", http.StatusBadRequest)
+		return
+	}
+
+	info, err := a.db.GetCVFile(r.Context(), cvFileID)
+	if err != nil {
+		log.Printf("[CVPhoto] GetCVFile(%d) failed: %v", cvFileID, err)
+		http.Error(w, "failed to load cv file", http.StatusInternalServerError)
+		return
+	}
+	if info == nil || info.PhotoKey == "" {
+		http.Error(w, "photo not found", http.StatusNotFound)
+		return
+	}
+
+	body, err := a.blobs.Get(r.Context(), info.PhotoKey)
+	if errors.Is(err, storage.ErrBlobNotFound) {
+		http.Error(w, "photo not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("[CVPhoto] blob get %s failed: %v", info.PhotoKey, err)
+		http.Error(w, "failed to read stored photo", http.StatusBadGateway)
+		return
+	}
+	defer body.Close()
+
+	contentType := "image/jpeg"
+	if strings.HasSuffix(info.PhotoKey, ".png") {
+		contentType = "image/png"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private")
+	if _, err := io.Copy(w, body); err != nil {
+		log.Printf("[CVPhoto] streaming photo of cv file %d interrupted: %v", cvFileID, err)
+	}
+}
@@ -5,27 +5,82 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
-	"os"
-	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
+
+	"cv-search/internal/cv"
+	"cv-search/internal/storage"
+	"cv-search/internal/tenant"
+)
+
+// cvUploadResponse is the 202 for an accepted upload: extraction runs in
+// the background and check_status_url polls its job.
+type cvUploadResponse struct {
+	CVID             int                `json:"cv_id"`
+	JobID            int64              `json:"job_id"`
+	Filename         string             `json:"filename"`
+	FileType         string             `json:"file_type"`
+	FileSize         int64              `json:"file_size"`
+	TextLength       int                `json:"text_length"`
+	OCRUsed          bool               `json:"ocr_used"`
+	Anonymized       bool               `json:"anonymized"`
+	Status           string             `json:"status"`
+	Message          string             `json:"message"`
+	ProcessingTimeMs int64              `json:"processing_time_ms"`
+	CheckStatusURL   string             `json:"check_status_url"`
+	PIIMasked        map[cv.PIIKind]int `json:"pii_masked,omitempty"`
+	Queue            QueueCapacity      `json:"queue"` // throttle as utilization nears reject_above
+}
+
+// cvDuplicateResponse answers an upload whose text was uploaded before:
+// 200 with the existing file and its latest job, or 202 when force=true
+// queued a new job (status "reprocessing"). Nothing is stored again; with
+// candidate_id the existing file is linked to that candidate.
+type cvDuplicateResponse struct {
+	CVID             int64              `json:"cv_id"`
+	Filename         string             `json:"filename"`
+	FileSize         int64              `json:"file_size"`
+	Status           string             `json:"status"`
+	Message          string             `json:"message"`
+	OriginalUploadAt time.Time          `json:"original_upload_at"`
+	Duplicate        bool               `json:"duplicate"`
+	CandidateID      *int               `json:"candidate_id,omitempty"`
+	Linked           bool               `json:"linked,omitempty"` // candidate_id was changed by this upload
+	ExistingJob      *duplicateJobState `json:"existing_job,omitempty"`
+	JobID            int64              `json:"job_id,omitempty"` // new job when reprocessing
+	CheckStatusURL   string             `json:"check_status_url,omitempty"`
+	Queue            *QueueCapacity     `json:"queue,omitempty"`
+}
+
+// duplicateJobState is the latest processing job of a duplicate's file.
+type duplicateJobState struct {
+	JobID       int64      `json:"job_id"`
+	Status      string     `json:"status"`
+	Error       string     `json:"error,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// uploadOptions are the form/query fields of an upload beyond the file.
+type uploadOptions struct {
+	Force       bool     // re-run extraction when the CV is a duplicate
+	CandidateID *int     // candidate the CV belongs to (new or duplicate)
+	Source      string   // where the CV came from, e.g. "referral"
+	Tags        []string // normalized tags for the CV's candidate
+}
+
+// Limits of the source and tags upload fields.
+const (
+	maxUploadSourceLength = 100
+	maxUploadTags         = 20
 )
 
 // CVUploadHandler handles CV file uploads and extraction
-// @Summary Upload and parse CV
-// @Description Upload a CV file (PDF/DOCX) and extract entities using LLM
-// @Tags cv
-// @Accept multipart/form-data
-// @Produce json
-// @Param file formData file true "CV file (PDF or DOCX)"
-// @Param candidate_id formData int false "Candidate ID (optional)"
-// @Success 200 {object} map[string]interface{}
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
-// @Router /cv/upload [post]
 func (a *API) CVUploadHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -34,7 +89,16 @@ func (a *API) CVUploadHandler(w http.ResponseWriter, r *http.Request) {
 
 	startTime := time.Now()
 
+	// Refuse before parsing: a CV saved but never queued would come back as
+	// a duplicate when the client retries.
+	if a.rejectIfQueueBusy(w, 1) || !a.admitQuota(w, r, quotaUploads, 1) {
+		return
+	}
+
 	maxFileSize := int64(a.cfg.MaxFileSizeMB) << 20
+	// ParseMultipartForm only bounds memory; cap the body itself (1MB of
+	// slack for the multipart framing and form fields).
+	r.Body = http.MaxBytesReader(w, r.Body, maxFileSize+(1<<20))
 
 	// Parse multipart form
 	if err := r.ParseMultipartForm(maxFileSize); err != nil {
@@ -55,15 +119,42 @@ func (a *API) CVUploadHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate file type
-	ext := filepath.Ext(header.Filename)
-	if ext != ".pdf" && ext != ".docx" && ext != ".doc" && ext != ".txt" {
-		http.Error(w, "invalid file type (supported: PDF, DOCX, TXT)", http.StatusBadRequest)
+	// Validate file name and type
+	filename, err := checkCVUpload(header.Filename, header.Header.Get("Content-Type"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	anonymize, err := a.anonymizeRequested(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	opts, err := parseUploadOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if opts.CandidateID != nil {
+		ok, err := a.db.CandidateInOrg(r.Context(), *opts.CandidateID)
+		if err != nil {
+			log.Printf("[CVUpload] %v", err)
+			http.Error(w, "database error", http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			http.Error(w, "candidate not found", http.StatusNotFound)
+			return
+		}
+	}
 
 	// Parse CV file (extract text)
-	parsedCV, err := a.cvParser.ParseFile(header.Filename, file)
+	parsedCV, err := a.cvParser.ParseReader(filename, file)
+	if errors.Is(err, cv.ErrRejectedFile) {
+		a.audit(r, "reject", "cv_file", "", map[string]interface{}{"filename": filename, "reason": err.Error()})
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
 	if err != nil {
 		http.Error(w, fmt.Sprintf("failed to parse CV: %v", err), http.StatusInternalServerError)
 		return
@@ -82,67 +173,62 @@ func (a *API) CVUploadHandler(w http.ResponseWriter, r *http.Request) {
 		log.Printf("[DUPLICATE CHECK] Error checking for duplicate CV: %v", err)
 		// Continue with upload even if duplicate check fails
 	} else if existingCV != nil {
-		// CV already exists - return existing info
 		log.Printf("[DUPLICATE CHECK] Duplicate CV detected: %s (existing ID: %d)", parsedCV.Filename, existingCV.ID)
-
-		response := map[string]interface{}{
-			"cv_id":              existingCV.ID,
-			"filename":           existingCV.Filename,
-			"file_size":          existingCV.FileSize,
-			"status":             "duplicate",
-			"message":            "This CV has already been uploaded",
-			"original_upload_at": existingCV.UploadedAt,
-			"duplicate":          true,
-		}
-
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(response)
+		a.handleDuplicateUpload(w, r, existingCV, opts)
 		return
 	}
 
-	// Save CV file to database with hash
-	log.Printf("[DUPLICATE CHECK] Saving CV with hash to database...")
-	cvID, err := a.db.SaveCVFileWithHash(r.Context(), nil, parsedCV.Filename,
-		parsedCV.Filename, parsedCV.FileType, parsedCV.FullText, parsedCV.FileSize, contentHash)
+	blobKey, err := a.storeUploadedCV(r.Context(), header, filename)
 	if err != nil {
-		log.Printf("Failed to save CV: %v", err)
-		http.Error(w, "failed to save CV", http.StatusInternalServerError)
+		log.Printf("Failed to store CV file: %v", err)
+		http.Error(w, "failed to store CV file", http.StatusInternalServerError)
 		return
 	}
 
-	log.Printf("CV saved to database with ID: %d (hash: %s...)", cvID, contentHash[:16])
+	var piiMasked map[cv.PIIKind]int
+	if anonymize {
+		piiMasked = parsedCV.Anonymize()
+	}
 
-	// Create async processing job
-	jobID, err := a.db.CreateCVUploadJob(r.Context(), int64(cvID))
+	// Save CV file with hash and create its async processing job in one transaction
+	log.Printf("[DUPLICATE CHECK] Saving CV with hash to database...")
+	cvID, jobID, err := a.saveParsedCV(r.Context(), opts, parsedCV, blobKey, contentHash)
 	if err != nil {
-		log.Printf("Failed to create job: %v", err)
-		http.Error(w, "failed to create processing job", http.StatusInternalServerError)
+		log.Printf("Failed to save CV / create job: %v", err)
+		http.Error(w, "failed to save CV", http.StatusInternalServerError)
 		return
 	}
 
-	log.Printf("Created job %d for CV %d", jobID, cvID)
+	log.Printf("CV saved to database with ID: %d (hash: %s...), job %d created", cvID, contentHash[:16], jobID)
+	a.countUsage(r.Context(), 1, 0)
+	a.audit(r, "upload", "cv_file", strconv.Itoa(cvID), map[string]interface{}{
+		"filename": parsedCV.Filename, "file_size": parsedCV.FileSize, "job_id": jobID,
+	})
 
 	// Queue job for background processing
-	if !a.queueCVProcessingJob(jobID, int64(cvID), parsedCV.FullText) {
-		http.Error(w, "processing queue is full, try again later", http.StatusServiceUnavailable)
+	if !a.queueCVProcessingJob(r.Context(), jobID, int64(cvID), parsedCV.FullText) {
+		a.writeQueueBusy(w, a.cvQueueCapacity())
 		return
 	}
 
 	processingTime := time.Since(startTime).Milliseconds()
 
 	// Return immediately with job info (async response)
-	response := map[string]interface{}{
-		"cv_id":              cvID,
-		"job_id":             jobID,
-		"filename":           parsedCV.Filename,
-		"file_type":          parsedCV.FileType,
-		"file_size":          parsedCV.FileSize,
-		"text_length":        len(parsedCV.FullText),
-		"status":             "pending",
-		"message":            "CV uploaded successfully. Processing in background.",
-		"processing_time_ms": processingTime,
-		"check_status_url":   fmt.Sprintf("/api/cv/job/%d", jobID),
+	response := cvUploadResponse{
+		CVID:             cvID,
+		JobID:            jobID,
+		Filename:         parsedCV.Filename,
+		FileType:         parsedCV.FileType,
+		FileSize:         parsedCV.FileSize,
+		TextLength:       len(parsedCV.FullText),
+		OCRUsed:          parsedCV.OCRUsed,
+		Anonymized:       parsedCV.Anonymized,
+		Status:           "pending",
+		Message:          "CV uploaded successfully. Processing in background.",
+		ProcessingTimeMs: processingTime,
+		CheckStatusURL:   fmt.Sprintf("/api/cv/job/%d", jobID),
+		PIIMasked:        piiMasked,
+		Queue:            a.cvQueueCapacity(),
 	}
 
 	log.Printf("CV upload complete - instant response in %dms (job %d queued for processing)", processingTime, jobID)
@@ -157,128 +243,159 @@ func (a *API) CVUploadHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// GetGraphStats returns graph statistics
-// @Summary Get graph statistics
-// @Description Get statistics about the knowledge graph (skill popularity, etc.)
-// @Tags graph
-// @Produce json
-// @Success 200 {object} map[string]interface{}
-// @Router /graph/stats [get]
-func (a *API) GetGraphStatsHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
+// parseUploadOptions reads the force, candidate_id, source and tags
+// form/query fields. tags may be repeated or comma-separated.
+func parseUploadOptions(r *http.Request) (uploadOptions, error) {
+	var opts uploadOptions
+	if v := r.FormValue("force"); v != "" {
+		force, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("invalid force value %q", v)
+		}
+		opts.Force = force
 	}
-
-	// Query graph statistics
-	stats := map[string]interface{}{
-		"total_nodes": 0,
-		"total_edges": 0,
-		"node_types":  map[string]int{},
+	if v := r.FormValue("candidate_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id <= 0 {
+			return opts, fmt.Errorf("invalid candidate_id %q", v)
+		}
+		opts.CandidateID = &id
 	}
-
-	// Count total nodes
-	var totalNodes int
-	err := a.db.GetConnection().QueryRow("SELECT COUNT(*) FROM graph_nodes").Scan(&totalNodes)
-	if err == nil {
-		stats["total_nodes"] = totalNodes
+	opts.Source = strings.TrimSpace(r.FormValue("source"))
+	if utf8.RuneCountInString(opts.Source) > maxUploadSourceLength {
+		return opts, fmt.Errorf("source is too long (max %d characters)", maxUploadSourceLength)
+	}
+	var raw []string
+	for _, v := range r.Form["tags"] {
+		for _, tag := range strings.Split(v, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				raw = append(raw, tag)
+			}
+		}
+	}
+	tags, err := normalizeTags(raw)
+	if err != nil {
+		return opts, fmt.Errorf("tags: %w", err)
 	}
+	if len(tags) > maxUploadTags {
+		return opts, fmt.Errorf("too many tags (max %d)", maxUploadTags)
+	}
+	opts.Tags = tags
+	return opts, nil
+}
 
-	// Count total edges
-	var totalEdges int
-	err = a.db.GetConnection().QueryRow("SELECT COUNT(*) FROM graph_edges").Scan(&totalEdges)
-	if err == nil {
-		stats["total_edges"] = totalEdges
+// handleDuplicateUpload answers an upload of a CV already stored as
+// existing: it reports the file and its latest job, links the file to
+// opts.CandidateID, tags the file's candidate with opts.Tags (the file's
+// source stays the first upload's) and, with opts.Force, queues a new extraction job (whose
+// result replaces the earlier entities and is linked to the file's
+// candidate). A file whose job is still running can't be reprocessed.
+func (a *API) handleDuplicateUpload(w http.ResponseWriter, r *http.Request, existing *storage.CVFileInfo, opts uploadOptions) {
+	ctx := r.Context()
+	response := cvDuplicateResponse{
+		CVID:             existing.ID,
+		Filename:         existing.Filename,
+		FileSize:         existing.FileSize,
+		Status:           "duplicate",
+		Message:          "This CV has already been uploaded",
+		OriginalUploadAt: existing.UploadedAt,
+		Duplicate:        true,
+		CandidateID:      existing.CandidateID,
+	}
+
+	job, err := a.db.GetLatestJobForCVFile(ctx, existing.ID)
+	if err != nil {
+		log.Printf("[CVUpload] %v", err)
+	}
+	if job != nil {
+		response.ExistingJob = &duplicateJobState{JobID: job.ID, Status: job.Status, CompletedAt: job.CompletedAt}
+		if job.ErrorMessage != nil {
+			response.ExistingJob.Error = *job.ErrorMessage
+		}
+		if opts.Force && (job.Status == "pending" || job.Status == "processing" || job.Status == "batch_submitted") {
+			http.Error(w, fmt.Sprintf("CV is still being processed (job %d)", job.ID), http.StatusConflict)
+			return
+		}
 	}
 
-	// Count nodes by type
-	rows, err := a.db.GetConnection().Query(`
-		SELECT node_type, COUNT(*) 
-		FROM graph_nodes 
-		GROUP BY node_type
-	`)
-	if err == nil {
-		defer rows.Close()
-		nodeTypes := make(map[string]int)
-		for rows.Next() {
-			var entityType string
-			var count int
-			rows.Scan(&entityType, &count)
-			nodeTypes[entityType] = count
+	if opts.CandidateID != nil && (existing.CandidateID == nil || *existing.CandidateID != *opts.CandidateID) {
+		if err := a.db.LinkCVFileToCandidate(ctx, existing.ID, *opts.CandidateID); err != nil {
+			log.Printf("[CVUpload] %v", err)
+			http.Error(w, "failed to link CV to candidate", http.StatusInternalServerError)
+			return
 		}
-		stats["node_types"] = nodeTypes
+		a.audit(r, "link", "cv_file", strconv.FormatInt(existing.ID, 10), map[string]interface{}{
+			"candidate_id": *opts.CandidateID, "previous_candidate_id": existing.CandidateID,
+		})
+		response.CandidateID = opts.CandidateID
+		response.Linked = true
+		response.Message = "This CV has already been uploaded; it is now linked to the candidate"
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(stats)
-}
+	// Tags go to the file's candidate, or wait on the file for extraction
+	// to link one.
+	if len(opts.Tags) > 0 {
+		var err error
+		if response.CandidateID != nil {
+			err = a.db.AddCandidateTags(ctx, *response.CandidateID, opts.Tags, actorFromRequest(r))
+		} else {
+			err = a.db.AddCVFileUploadTags(ctx, existing.ID, opts.Tags)
+		}
+		if err != nil {
+			log.Printf("[CVUpload] CV %d: tags: %v", existing.ID, err)
+			http.Error(w, "failed to tag candidate", http.StatusInternalServerError)
+			return
+		}
+	}
 
-// GetPopularSkills returns most popular skills from graph
-// @Summary Get popular skills
-// @Description Get most popular skills extracted from CVs
-// @Tags graph
-// @Produce json
-// @Param limit query int false "Limit results" default(20)
-// @Success 200 {object} map[string]interface{}
-// @Router /graph/skills/popular [get]
-func (a *API) GetPopularSkillsHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	if !opts.Force {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
 		return
 	}
 
-	// Default limit
-	limit := 20
-
-	rows, err := a.db.GetConnection().Query(`
-		SELECT 
-			n.properties->>'name' as skill,
-			COUNT(DISTINCT e.source_node_id) as candidate_count
-		FROM graph_nodes n
-		LEFT JOIN graph_edges e ON e.target_node_id = n.id AND e.edge_type = 'HAS_SKILL'
-		WHERE n.node_type = 'skill'
-		GROUP BY n.properties->>'name'
-		HAVING COUNT(DISTINCT e.source_node_id) > 0
-		ORDER BY candidate_count DESC
-		LIMIT $1
-	`, limit)
-
+	texts, err := a.db.GetCVTextsByFileIDs(ctx, []int64{existing.ID})
 	if err != nil {
-		log.Printf("Query error: %v", err)
+		log.Printf("[CVUpload] CV %d: %v", existing.ID, err)
 		http.Error(w, "database error", http.StatusInternalServerError)
 		return
 	}
-	defer rows.Close()
-
-	type SkillStat struct {
-		Skill string `json:"skill"`
-		Count int    `json:"count"`
+	jobID, err := a.db.CreateCVUploadJob(ctx, existing.ID)
+	if err != nil {
+		log.Printf("[CVUpload] CV %d: create job: %v", existing.ID, err)
+		http.Error(w, "failed to create job", http.StatusInternalServerError)
+		return
 	}
-
-	skills := []SkillStat{}
-	for rows.Next() {
-		var s SkillStat
-		rows.Scan(&s.Skill, &s.Count)
-		skills = append(skills, s)
+	if !a.queueCVProcessingJob(ctx, jobID, existing.ID, texts[existing.ID]) {
+		a.writeQueueBusy(w, a.cvQueueCapacity())
+		return
 	}
+	a.audit(r, "reprocess", "cv_file", strconv.FormatInt(existing.ID, 10), map[string]interface{}{"job_id": jobID})
+	log.Printf("[CVUpload] Duplicate CV %d queued for reprocessing (job %d)", existing.ID, jobID)
 
-	response := map[string]interface{}{
-		"total":  len(skills),
-		"skills": skills,
-	}
+	queue := a.cvQueueCapacity()
+	response.Status = "reprocessing"
+	response.Message = "This CV has already been uploaded; extraction is running again"
+	response.JobID = jobID
+	response.CheckStatusURL = fmt.Sprintf("/api/cv/job/%d", jobID)
+	response.Queue = &queue
 
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
 	json.NewEncoder(w).Encode(response)
 }
 
-// collectNewNodeIDs gets all nodes without embeddings (likely newly created from this CV)
+// collectNewNodeIDs gets all of ctx's organization's nodes without
+// embeddings (likely newly created from this CV)
 func (a *API) collectNewNodeIDs(ctx context.Context, cvID int64) []string {
 	rows, err := a.db.GetConnection().QueryContext(ctx, `
 		SELECT node_id 
 		FROM graph_nodes 
 		WHERE embedding IS NULL
+		  AND deleted_at IS NULL
+		  AND org_id = $1
 		ORDER BY created_at DESC
-	`)
+	`, tenant.OrgID(ctx))
 
 	if err != nil {
 		log.Printf("Failed to query unembedded nodes: %v", err)
@@ -298,16 +415,20 @@ func (a *API) collectNewNodeIDs(ctx context.Context, cvID int64) []string {
 	return nodeIDs
 }
 
+// jobStatusResponse is a CV processing job as polled by clients after an
+// upload's 202.
+type jobStatusResponse struct {
+	JobID       int64      `json:"job_id"`
+	CVFileID    int64      `json:"cv_file_id"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	Message     string     `json:"message,omitempty"`
+}
+
 // GetJobStatusHandler returns the status of a CV processing job
-// @Summary Get CV processing job status
-// @Description Get the current status of an async CV processing job
-// @Tags cv
-// @Produce json
-// @Param job_id path int true "Job ID"
-// @Success 200 {object} map[string]interface{}
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
-// @Router /cv/job/{job_id} [get]
 func (a *API) GetJobStatusHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -343,308 +464,27 @@ func (a *API) GetJobStatusHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Prepare response
-	response := map[string]interface{}{
-		"job_id":     job.ID,
-		"cv_file_id": job.CVFileID,
-		"status":     job.Status,
-		"created_at": job.CreatedAt,
-	}
-
-	if job.StartedAt != nil {
-		response["started_at"] = job.StartedAt
-	}
-	if job.CompletedAt != nil {
-		response["completed_at"] = job.CompletedAt
+	response := jobStatusResponse{
+		JobID:       job.ID,
+		CVFileID:    job.CVFileID,
+		Status:      job.Status,
+		CreatedAt:   job.CreatedAt,
+		StartedAt:   job.StartedAt,
+		CompletedAt: job.CompletedAt,
 	}
 	if job.ErrorMessage != nil {
-		response["error"] = *job.ErrorMessage
+		response.Error = *job.ErrorMessage
 	}
 	if job.Status == "completed" {
-		response["message"] = "CV processing completed successfully"
+		response.Message = "CV processing completed successfully"
 	} else if job.Status == "processing" {
-		response["message"] = "CV processing in progress"
+		response.Message = "CV processing in progress"
 	} else if job.Status == "pending" {
-		response["message"] = "CV processing queued"
+		response.Message = "CV processing queued"
 	} else if job.Status == "batch_submitted" {
-		response["message"] = "CV submitted as part of a bulk batch job; results typically arrive within minutes to a few hours"
+		response.Message = "CV submitted as part of a bulk batch job; results typically arrive within minutes to a few hours"
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
-
-// BulkCVUploadHandler handles bulk CV file uploads (up to MaxBulkFileCount files, MaxFileSizeMB each).
-// Uploads at or below MaxRealtimeCVCount are processed via the real-time queue (seconds);
-// larger ones are submitted as a single Groq Batch API job (minutes-to-hours, but immune
-// to the standard per-model rate limit and 50% cheaper).
-// @Summary Bulk upload CVs
-// @Description Upload multiple CV files at once (max configurable via MAX_BULK_FILE_COUNT)
-// @Tags cv
-// @Accept multipart/form-data
-// @Produce json
-// @Param files formData file true "CV files (PDF, DOCX, TXT) — field name: files"
-// @Success 207 {object} map[string]interface{}
-// @Failure 400 {object} map[string]string
-// @Router /cv/bulk-upload [post]
-func (a *API) BulkCVUploadHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	maxBulkSize := int64(a.cfg.MaxFileSizeMB*a.cfg.MaxBulkFileCount) << 20
-	if err := r.ParseMultipartForm(maxBulkSize); err != nil {
-		http.Error(w, fmt.Sprintf("request too large (max %d MB total)", a.cfg.MaxFileSizeMB*a.cfg.MaxBulkFileCount), http.StatusBadRequest)
-		return
-	}
-
-	files := r.MultipartForm.File["files"]
-	if len(files) == 0 {
-		http.Error(w, "no files uploaded (use field name: files)", http.StatusBadRequest)
-		return
-	}
-	if len(files) > a.cfg.MaxBulkFileCount {
-		http.Error(w, fmt.Sprintf("max %d files per request", a.cfg.MaxBulkFileCount), http.StatusBadRequest)
-		return
-	}
-
-	type FileResult struct {
-		Filename       string `json:"filename"`
-		CVID           *int64 `json:"cv_id,omitempty"`
-		JobID          *int64 `json:"job_id,omitempty"`
-		Status         string `json:"status"`
-		CheckStatusURL string `json:"check_status_url,omitempty"`
-	}
-
-	batchID := fmt.Sprintf("batch_%d", time.Now().UnixNano())
-	results := make([]FileResult, 0, len(files))
-	var batchJobs []BatchJob
-	queued, skipped := 0, 0
-
-	// Files that parsed/saved successfully are collected here first; whether
-	// they're queued for real-time processing or submitted as a single Groq
-	// Batch API job is decided AFTER the loop, based on how many there are.
-	type pendingUpload struct {
-		resultIdx int
-		job       CVProcessingJob
-	}
-	var pending []pendingUpload
-
-	maxFileSize := int64(a.cfg.MaxFileSizeMB) << 20
-	for _, fileHeader := range files {
-		res := FileResult{Filename: fileHeader.Filename}
-
-		// Per-file size limit
-		if fileHeader.Size > maxFileSize {
-			res.Status = "too_large"
-			skipped++
-			results = append(results, res)
-			continue
-		}
-
-		// File type validation
-		ext := strings.ToLower(filepath.Ext(fileHeader.Filename))
-		if ext != ".pdf" && ext != ".docx" && ext != ".doc" && ext != ".txt" {
-			res.Status = "invalid_type"
-			skipped++
-			results = append(results, res)
-			continue
-		}
-
-		file, err := fileHeader.Open()
-		if err != nil {
-			log.Printf("[BulkUpload] Cannot open %s: %v", fileHeader.Filename, err)
-			res.Status = "error"
-			skipped++
-			results = append(results, res)
-			continue
-		}
-
-		parsedCV, err := a.cvParser.ParseFile(fileHeader.Filename, file)
-		file.Close()
-		if err != nil {
-			log.Printf("[BulkUpload] Parse error %s: %v", fileHeader.Filename, err)
-			res.Status = "error"
-			skipped++
-			results = append(results, res)
-			continue
-		}
-
-		// Hash-based duplicate detection
-		hash := sha256.Sum256([]byte(parsedCV.FullText))
-		contentHash := hex.EncodeToString(hash[:])
-
-		existing, _ := a.db.FindCVByHash(r.Context(), contentHash)
-		if existing != nil {
-			cvID := int64(existing.ID)
-			res.CVID = &cvID
-			res.Status = "duplicate"
-			skipped++
-			results = append(results, res)
-			continue
-		}
-
-		cvID, err := a.db.SaveCVFileWithHash(r.Context(), nil, parsedCV.Filename,
-			parsedCV.Filename, parsedCV.FileType, parsedCV.FullText, parsedCV.FileSize, contentHash)
-		if err != nil {
-			log.Printf("[BulkUpload] DB save error %s: %v", fileHeader.Filename, err)
-			res.Status = "error"
-			skipped++
-			results = append(results, res)
-			continue
-		}
-
-		jobID, err := a.db.CreateCVUploadJob(r.Context(), int64(cvID))
-		if err != nil {
-			log.Printf("[BulkUpload] Job create error %s: %v", fileHeader.Filename, err)
-			res.Status = "error"
-			skipped++
-			results = append(results, res)
-			continue
-		}
-
-		cvIDVal := int64(cvID)
-		res.CVID = &cvIDVal
-		res.JobID = &jobID
-		res.CheckStatusURL = fmt.Sprintf("/api/cv/job/%d", jobID)
-		queued++
-		batchJobs = append(batchJobs, BatchJob{JobID: jobID, Filename: fileHeader.Filename, CVID: int64(cvID)})
-		resultIdx := len(results)
-		results = append(results, res)
-		pending = append(pending, pendingUpload{
-			resultIdx: resultIdx,
-			job: CVProcessingJob{
-				JobID:     jobID,
-				CVFileID:  int64(cvID),
-				CVText:    parsedCV.FullText,
-				Timestamp: time.Now(),
-			},
-		})
-	}
-
-	// Route: small bulk uploads go through the real-time queue (fast, seconds);
-	// large ones are submitted as a single Groq Batch API job instead, which
-	// doesn't count against the standard rate limit (separate quota) and is
-	// 50% cheaper — at the cost of results taking minutes-to-hours instead of
-	// seconds. Only available when the LLM provider is Groq.
-	useBatchAPI := os.Getenv("GROQ_BATCH_DISABLED") != "true" &&
-		a.llmService != nil && a.cfg.LLMProvider == "groq" && len(pending) > a.cfg.MaxRealtimeCVCount
-
-	if useBatchAPI {
-		jobs := make([]CVProcessingJob, 0, len(pending))
-		for _, p := range pending {
-			jobs = append(jobs, p.job)
-		}
-
-		groqBatchID, err := a.SubmitCVExtractionBatch(r.Context(), jobs)
-		if err != nil {
-			log.Printf("[BulkUpload] Groq batch submission failed, falling back to real-time queue: %v", err)
-			useBatchAPI = false
-		} else {
-			log.Printf("[BulkUpload] Submitted %d CVs as Groq batch %s", len(pending), groqBatchID)
-			for _, p := range pending {
-				results[p.resultIdx].Status = "batch_submitted"
-			}
-		}
-	}
-
-	if !useBatchAPI {
-		for _, p := range pending {
-			if !a.queueCVProcessingJob(p.job.JobID, p.job.CVFileID, p.job.CVText) {
-				results[p.resultIdx].Status = "queue_full"
-				results[p.resultIdx].JobID = nil
-				results[p.resultIdx].CheckStatusURL = ""
-				queued--
-				skipped++
-				continue
-			}
-			results[p.resultIdx].Status = "queued"
-		}
-	}
-
-	// Persist batch to in-memory store
-	a.batchStore.set(&BatchEntry{
-		BatchID:   batchID,
-		Jobs:      batchJobs,
-		CreatedAt: time.Now(),
-	})
-
-	log.Printf("[BulkUpload] batch=%s total=%d queued=%d skipped=%d batch_api=%v", batchID, len(files), queued, skipped, useBatchAPI)
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusMultiStatus) // 207
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"batch_id":         batchID,
-		"total":            len(files),
-		"queued":           queued,
-		"skipped":          skipped,
-		"check_status_url": fmt.Sprintf("/api/cv/batch/%s", batchID),
-		"results":          results,
-	})
-}
-
-// GetBatchStatusHandler returns the processing status for all jobs in a batch.
-// @Summary Get batch upload status
-// @Description Get the processing status of all CVs uploaded in a bulk upload batch
-// @Tags cv
-// @Produce json
-// @Param batch_id path string true "Batch ID returned by bulk-upload"
-// @Success 200 {object} map[string]interface{}
-// @Failure 404 {object} map[string]string
-// @Router /cv/batch/{batch_id} [get]
-func (a *API) GetBatchStatusHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	batchID := strings.TrimPrefix(r.URL.Path, "/api/cv/batch/")
-	if batchID == "" {
-		http.Error(w, "missing batch_id", http.StatusBadRequest)
-		return
-	}
-
-	entry, ok := a.batchStore.get(batchID)
-	if !ok {
-		http.Error(w, "batch not found (may have expired after 30 minutes)", http.StatusNotFound)
-		return
-	}
-
-	type JobStatus struct {
-		JobID    int64  `json:"job_id"`
-		Filename string `json:"filename"`
-		CVID     int64  `json:"cv_file_id"`
-		Status   string `json:"status"`
-	}
-
-	jobs := make([]JobStatus, 0, len(entry.Jobs))
-	summary := map[string]int{
-		"total": len(entry.Jobs), "completed": 0, "processing": 0, "pending": 0, "failed": 0,
-	}
-
-	for _, bj := range entry.Jobs {
-		job, err := a.db.GetJobByID(r.Context(), bj.JobID)
-		status := "unknown"
-		if err != nil {
-			log.Printf("[BatchStatus] GetJobByID(%d) error: %v", bj.JobID, err)
-		} else if job != nil {
-			status = job.Status
-		} else {
-			log.Printf("[BatchStatus] GetJobByID(%d) returned nil (not found)", bj.JobID)
-		}
-
-		if _, tracked := summary[status]; tracked {
-			summary[status]++
-		}
-		jobs = append(jobs, JobStatus{
-			JobID: bj.JobID, Filename: bj.Filename, CVID: bj.CVID, Status: status,
-		})
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"batch_id": batchID,
-		"summary":  summary,
-		"jobs":     jobs,
-	})
-}
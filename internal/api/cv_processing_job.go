@@ -0,0 +1,419 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"cv-search/internal/cv"
+	"cv-search/internal/llm"
+	"cv-search/internal/reprocess"
+	"cv-search/internal/storage"
+	"cv-search/internal/tenant"
+)
+
+// CVProcessingJob represents a background CV processing task (LLM + Graph)
+type CVProcessingJob struct {
+	OrgID     int
+	JobID     int64
+	CVFileID  int64
+	CVText    string
+	Timestamp time.Time
+}
+
+// cvProcessingWorker processes CV upload jobs from the queue
+func (a *API) cvProcessingWorker() {
+	log.Println("[CVProcessingWorker] Started")
+
+	for job := range a.cvProcessingQueue {
+		a.cvQueueStats.started(job.Timestamp)
+		a.cvQueueStats.finished(a.processCVJob(job))
+	}
+}
+
+// processCVJob extracts a CV's entities and builds its graph. It reports
+// false when the attempt failed, whether or not the job will be retried.
+func (a *API) processCVJob(job CVProcessingJob) bool {
+	log.Printf("[CVProcessingWorker] Processing job %d (CV file %d)", job.JobID, job.CVFileID)
+
+	ctx := tenant.WithOrg(context.Background(), job.OrgID)
+
+	// Update job status to processing
+	if err := a.db.UpdateJobStatus(ctx, job.JobID, "processing", nil); err != nil {
+		log.Printf("[CVProcessingWorker] Failed to update job status: %v", err)
+		return false
+	}
+
+	// Check if LLM service is available
+	if a.ai(ctx).llmService == nil {
+		errMsg := "LLM service not available"
+		log.Printf("[CVProcessingWorker] Job %d failed: %s", job.JobID, errMsg)
+		a.db.UpdateJobStatus(ctx, job.JobID, "failed", &errMsg)
+		return false
+	}
+
+	// A scanned CV without OCR (or OCR that found nothing) has no text;
+	// say so instead of sending an empty prompt to the LLM.
+	if strings.TrimSpace(job.CVText) == "" {
+		errMsg := "no text could be extracted from the CV (scanned document? set OCR_BACKEND)"
+		log.Printf("[CVProcessingWorker] Job %d failed: %s", job.JobID, errMsg)
+		a.db.UpdateJobStatus(ctx, job.JobID, "failed", &errMsg)
+		return false
+	}
+
+	// Extract entities using LLM
+	log.Printf("[CVProcessingWorker] Extracting entities for job %d...", job.JobID)
+	extraction, err := a.extractCVEntities(ctx, job.CVFileID, job.CVText)
+	if err != nil {
+		retryCount, maxRetries, rcErr := a.db.IncrementJobRetryCount(ctx, job.JobID)
+		if rcErr == nil && retryCount < maxRetries {
+			backoff := time.Duration(retryCount) * 30 * time.Second
+			log.Printf("[CVProcessingWorker] Job %d failed (attempt %d/%d): %v — retrying in %v",
+				job.JobID, retryCount, maxRetries, err, backoff)
+			if statusErr := a.db.UpdateJobStatus(ctx, job.JobID, "pending", nil); statusErr != nil {
+				log.Printf("[CVProcessingWorker] Failed to reset job %d to pending: %v", job.JobID, statusErr)
+			}
+			a.requeueCVProcessingJob(job, backoff)
+			return false
+		}
+		errMsg := fmt.Sprintf("LLM extraction failed after %d attempt(s): %v", retryCount, err)
+		log.Printf("[CVProcessingWorker] Job %d permanently failed: %s", job.JobID, errMsg)
+		a.db.UpdateJobStatus(ctx, job.JobID, "failed", &errMsg)
+		return false
+	}
+
+	log.Printf("[CVProcessingWorker] Job %d: Extracted %d skills, %d companies, %d education entries",
+		job.JobID, len(extraction.Skills), len(extraction.Companies), len(extraction.Education))
+
+	a.applyExtraction(ctx, job.JobID, job.CVFileID, extraction)
+
+	duration := time.Since(job.Timestamp)
+	log.Printf("[CVProcessingWorker] Job %d completed successfully (took %v)", job.JobID, duration)
+	return true
+}
+
+// extractCVEntities runs the LLM extraction of a CV. A CV too long for one
+// prompt is extracted chunk group by chunk group and the results merged
+// (cv.ExtractChunked).
+func (a *API) extractCVEntities(ctx context.Context, cvFileID int64, text string) (*llm.CVExtraction, error) {
+	llmSvc := a.ai(ctx).llmService
+	if chunks := a.cvChunks(ctx, cvFileID, text); cv.ChunksTokens(chunks) > cv.ExtractionPromptTokens {
+		log.Printf("[CVProcessingWorker] CV %d: ~%d tokens, extracting from %d chunks",
+			cvFileID, cv.ChunksTokens(chunks), len(chunks))
+		return cv.ExtractChunked(ctx, llmSvc, chunks, cv.FormatHeaderHint(a.cvHeader(ctx, cvFileID)))
+	}
+	return llmSvc.ExtractEntities(ctx, a.sectionedCVText(ctx, cvFileID, text, true))
+}
+
+// applyExtraction persists an LLM extraction result (entities, graph,
+// candidate linking, embedding queueing) and marks the job completed. Shared
+// between the real-time cvProcessingWorker and the Groq Batch API poller so
+// both paths apply identical downstream logic regardless of how the
+// extraction was obtained.
+func (a *API) applyExtraction(ctx context.Context, jobID, cvFileID int64, extraction *llm.CVExtraction) {
+	// Blind screening: the LLM only saw masked text, but may still have
+	// copied contact details into a field.
+	// Otherwise contact details the LLM missed are taken from the text.
+	anonymized := false
+	var uploadTags []string
+	if info, err := a.db.GetCVFile(ctx, cvFileID); err != nil {
+		log.Printf("[ApplyExtraction] CV %d: %v", cvFileID, err)
+	} else if info != nil {
+		anonymized = info.Anonymized
+		uploadTags = info.UploadTags
+	}
+	texts, err := a.db.GetCVTextsByFileIDs(ctx, []int64{cvFileID})
+	if err != nil {
+		log.Printf("[ApplyExtraction] CV %d: %v", cvFileID, err)
+	}
+	if anonymized {
+		cv.AnonymizeExtraction(extraction)
+	} else {
+		// Fields read from a Word CV's own header beat the text fallback.
+		cv.ApplyDocumentHeader(extraction, a.cvHeader(ctx, cvFileID))
+		if texts != nil {
+			cv.FillContactInfo(extraction, texts[cvFileID])
+		}
+	}
+
+	// Flag low-quality parses for review (GET /api/cv?quality=needs_review).
+	if texts != nil {
+		q := cv.ScoreQuality(texts[cvFileID], extraction, anonymized)
+		if err := a.db.SetCVFileQuality(ctx, cvFileID, q.Status, q.Score, q.Issues); err != nil {
+			log.Printf("[ApplyExtraction] Job %d: %v", jobID, err)
+		} else if q.Status == cv.QualityNeedsReview {
+			log.Printf("[ApplyExtraction] Job %d: CV %d needs review (score %.2f, %v)", jobID, cvFileID, q.Score, q.Issues)
+		}
+	}
+	contact := storage.CandidateContact{
+		Email:       extraction.Candidate.Email,
+		Phone:       extraction.Candidate.Phone,
+		LinkedInURL: extraction.Candidate.LinkedInURL,
+	}
+
+	// A new version of a known candidate's CV: diff it against the previous
+	// one before the graph build overwrites their person node.
+	if contact.Email != "" {
+		a.detectCVChanges(ctx, jobID, cvFileID, contact.Email, extraction)
+	}
+
+	// Save extracted entities to cv_entities table (all or nothing),
+	// replacing those of an earlier extraction (forced reprocess)
+	if err := a.db.WithTx(ctx, func(tx *storage.DB) error {
+		if err := tx.DeleteCVEntities(ctx, int(cvFileID)); err != nil {
+			return err
+		}
+		for _, skill := range extraction.Skills {
+			if err := tx.SaveCVEntity(ctx, int(cvFileID), "skill", skill.Name, skill.Confidence); err != nil {
+				return err
+			}
+		}
+		for _, company := range extraction.Companies {
+			if err := tx.SaveCVEntity(ctx, int(cvFileID), "company", company.Name, company.Confidence); err != nil {
+				return err
+			}
+		}
+		for _, edu := range extraction.Education {
+			if err := tx.SaveCVEntity(ctx, int(cvFileID), "education", edu.Institution, 0.9); err != nil {
+				return err
+			}
+		}
+		for _, cert := range extraction.Certifications {
+			if err := tx.SaveCVEntity(ctx, int(cvFileID), "certification", cert.Name, 0.9); err != nil {
+				return err
+			}
+		}
+		for _, lang := range extraction.Languages {
+			if err := tx.SaveCVEntity(ctx, int(cvFileID), "language", lang.Name, 0.9); err != nil {
+				return err
+			}
+		}
+		for _, project := range extraction.Projects {
+			if err := tx.SaveCVEntity(ctx, int(cvFileID), "project", project.Name, 0.8); err != nil {
+				return err
+			}
+		}
+		for _, loc := range extraction.Locations {
+			if err := tx.SaveCVEntity(ctx, int(cvFileID), "location", loc, 0.85); err != nil {
+				return err
+			}
+		}
+		// Kept per CV so the next version can be diffed against this one.
+		if seniority := extraction.Candidate.Seniority; seniority != "" {
+			if err := tx.SaveCVEntity(ctx, int(cvFileID), "seniority", seniority, 0.8); err != nil {
+				return err
+			}
+		}
+		if position := extraction.Candidate.CurrentPosition; position != "" {
+			if err := tx.SaveCVEntity(ctx, int(cvFileID), "position", position, 0.8); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		log.Printf("[ApplyExtraction] Job %d: Failed to save CV entities: %v", jobID, err)
+	}
+
+	// Build graph from extraction
+	personNodeID := 0
+	if a.graphBuilder != nil {
+		log.Printf("[ApplyExtraction] Building knowledge graph for job %d...", jobID)
+
+		extractionMap := map[string]interface{}{
+			"candidate": map[string]interface{}{
+				"name":                   extraction.Candidate.Name,
+				"current_position":       extraction.Candidate.CurrentPosition,
+				"seniority":              extraction.Candidate.Seniority,
+				"total_experience_years": extraction.Candidate.TotalExperienceYears,
+				"work_modes":             []string(extraction.Candidate.WorkModes),
+				"employment_types":       []string(extraction.Candidate.EmploymentTypes),
+				"notice_period":          extraction.Candidate.NoticePeriod,
+				"available_from":         extraction.Candidate.AvailableFrom,
+				"salary":                 extraction.Candidate.Salary,
+			},
+			"skills":         extraction.Skills,
+			"companies":      extraction.Companies,
+			"education":      extraction.Education,
+			"certifications": extraction.Certifications,
+			"languages":      extraction.Languages,
+			"projects":       extraction.Projects,
+			"locations":      extraction.Locations,
+		}
+
+		if err := a.graphBuilder.BuildFromLLMExtraction(ctx, int(cvFileID), extractionMap); err != nil {
+			log.Printf("[ApplyExtraction] Graph building failed for job %d: %v", jobID, err)
+		} else {
+			log.Printf("[ApplyExtraction] Job %d: Graph built successfully", jobID)
+
+			// Find the newly built person graph node for candidate linking
+			if candidateName := extraction.Candidate.Name; candidateName != "" {
+				var lookupErr error
+				if personNodeID, lookupErr = a.db.GetPersonGraphNodeIDByName(ctx, candidateName); lookupErr != nil {
+					log.Printf("[ApplyExtraction] Job %d: Failed to look up person node: %v", jobID, lookupErr)
+				}
+			}
+
+			// Queue background embedding job for newly created nodes
+			newNodeIDs := a.collectNewNodeIDs(ctx, cvFileID)
+			if len(newNodeIDs) > 0 {
+				a.QueueEmbeddingJob(ctx, cvFileID, newNodeIDs)
+				log.Printf("[ApplyExtraction] Job %d: Queued %d nodes for embedding", jobID, len(newNodeIDs))
+			}
+		}
+	}
+
+	// Upsert candidate (by email, else by person node), link cv_file and
+	// sync experience + skills for BM25 search in one transaction. Without
+	// a person node the CV is still linked by email.
+	candidateID, linkErr := a.db.LinkCandidateToCV(ctx, cvFileID, personNodeID, extraction.Candidate.Name, contact)
+	switch {
+	case linkErr != nil:
+		log.Printf("[ApplyExtraction] Job %d: Failed to link candidate: %v", jobID, linkErr)
+	case candidateID > 0:
+		log.Printf("[ApplyExtraction] Job %d: Candidate %d linked to CV %d (node %d)", jobID, candidateID, cvFileID, personNodeID)
+	}
+
+	// Tags given at upload (see parseUploadOptions) go to the candidate.
+	if candidateID > 0 && len(uploadTags) > 0 {
+		if err := a.db.AddCandidateTags(ctx, candidateID, uploadTags, fmt.Sprintf("cv_file:%d", cvFileID)); err != nil {
+			log.Printf("[ApplyExtraction] Job %d: Failed to tag candidate %d: %v", jobID, candidateID, err)
+		}
+	}
+
+	// Mark job as completed
+	if err := a.db.UpdateJobStatus(ctx, jobID, "completed", nil); err != nil {
+		log.Printf("[ApplyExtraction] Failed to mark job %d as completed: %v", jobID, err)
+	}
+	// The candidate is in the BM25 index now (search_vector trigger);
+	// cached searches from before would miss them.
+	a.invalidateSearchCache(ctx)
+}
+
+// detectCVChanges stores what changed since the previous CV of the
+// candidate with this email, if they have one.
+func (a *API) detectCVChanges(ctx context.Context, jobID, cvFileID int64, email string, extraction *llm.CVExtraction) {
+	prev, err := a.db.GetPreviousCVProfile(ctx, cvFileID, email)
+	if err != nil {
+		log.Printf("[ApplyExtraction] Job %d: %v", jobID, err)
+		return
+	}
+	if prev == nil {
+		return
+	}
+	changes := cv.DiffProfiles(cv.Profile{
+		Seniority:      prev.Seniority,
+		Position:       prev.Position,
+		Skills:         prev.Skills,
+		Companies:      prev.Companies,
+		Certifications: prev.Certifications,
+		Languages:      prev.Languages,
+	}, cv.ProfileOf(extraction))
+	changes.PreviousCVID = prev.CVFileID
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		log.Printf("[ApplyExtraction] Job %d: marshal changes: %v", jobID, err)
+		return
+	}
+	if err := a.db.SaveCVChanges(ctx, cvFileID, prev.CVFileID, data); err != nil {
+		log.Printf("[ApplyExtraction] Job %d: %v", jobID, err)
+		return
+	}
+	if !changes.Empty() {
+		log.Printf("[ApplyExtraction] Job %d: CV %d updates CV %d (+%d skills, %d new employers)",
+			jobID, cvFileID, prev.CVFileID, len(changes.AddedSkills), len(changes.NewEmployers))
+	}
+}
+
+// queueCVProcessingJob adds a new CV processing job for ctx's organization
+// to the background queue. Returns true if the job was queued, false if the
+// queue was full.
+func (a *API) queueCVProcessingJob(ctx context.Context, jobID, cvFileID int64, cvText string) bool {
+	if a.cvProcessingQueue == nil {
+		log.Printf("[BackgroundJobs] CV processing queue not initialized, skipping job %d", jobID)
+		return false
+	}
+
+	job := CVProcessingJob{
+		OrgID:     tenant.OrgID(ctx),
+		JobID:     jobID,
+		CVFileID:  cvFileID,
+		CVText:    cvText,
+		Timestamp: time.Now(),
+	}
+
+	// Non-blocking send
+	a.cvQueueStats.queued(job.Timestamp)
+	select {
+	case a.cvProcessingQueue <- job:
+		log.Printf("[BackgroundJobs] Queued CV processing job %d (CV file %d)", jobID, cvFileID)
+		return true
+	default:
+		a.cvQueueStats.drop(job.Timestamp)
+		log.Printf("[BackgroundJobs] Queue full! Dropping CV processing job %d", jobID)
+		// Update job status to failed
+		errMsg := "Queue full, job dropped"
+		a.db.UpdateJobStatus(ctx, jobID, "failed", &errMsg)
+		return false
+	}
+}
+
+// requeueCVProcessingJob re-queues a job after a backoff delay (system-level
+// retry, distinct from Groq's own internal retry inside callGroq). Runs in a
+// separate goroutine so the worker isn't blocked while waiting.
+func (a *API) requeueCVProcessingJob(job CVProcessingJob, delay time.Duration) {
+	go func() {
+		time.Sleep(delay)
+
+		a.cvQueueStats.queued(job.Timestamp)
+		select {
+		case a.cvProcessingQueue <- job:
+			log.Printf("[BackgroundJobs] Requeued CV processing job %d after %v backoff", job.JobID, delay)
+		default:
+			a.cvQueueStats.drop(job.Timestamp)
+			log.Printf("[BackgroundJobs] Queue full on requeue! Dropping CV processing job %d", job.JobID)
+			ctx := context.Background()
+			errMsg := "Queue full on retry, job dropped"
+			a.db.UpdateJobStatus(ctx, job.JobID, "failed", &errMsg)
+		}
+	}()
+}
+
+// RunReprocessJob runs the shared CV backlog reprocessing pass using this
+// API's already-constructed graphBuilder/embeddingService. By default it
+// reuses the SAME llm.Service instance (and therefore the SAME rate limiter)
+// used by search and the real-time CV upload path — avoiding a second,
+// uncoordinated rate limiter that could combine with live traffic to exceed
+// Groq's per-model RPM limit. Pass a non-nil llmSvcOverride (e.g. an
+// OpenAI-backed Service) to run the job against a different provider
+// entirely — safe to do since it doesn't share Groq's quota either way.
+// Every organization is reprocessed in turn, with its own LLM and embedding
+// services when it has configured them (the override then only applies to
+// organizations on the deployment's).
+func (a *API) RunReprocessJob(ctx context.Context, llmSvcOverride *llm.Service, opts reprocess.Options) error {
+	orgIDs, err := a.db.ListOrgIDs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, orgID := range orgIDs {
+		octx := tenant.WithOrg(ctx, orgID)
+		ai := a.ai(octx)
+		llmSvc := ai.llmService
+		if llmSvcOverride != nil && ai == a.aiServices {
+			llmSvc = llmSvcOverride
+		}
+		if llmSvc == nil {
+			return fmt.Errorf("org %d: LLM service not available", orgID)
+		}
+		if ai.enhancedSearchEngine == nil || ai.enhancedSearchEngine.GetEmbeddingService() == nil {
+			return fmt.Errorf("org %d: embedding service not available", orgID)
+		}
+		if err := reprocess.Run(octx, a.db, llmSvc, a.graphBuilder, ai.enhancedSearchEngine.GetEmbeddingService(), opts); err != nil {
+			return fmt.Errorf("org %d: %w", orgID, err)
+		}
+	}
+	return nil
+}
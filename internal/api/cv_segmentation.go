@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"cv-search/internal/cv"
+)
+
+// cvChunks returns a CV's stored chunks. CVs uploaded before chunking
+// existed are chunked now and their chunks saved.
+func (a *API) cvChunks(ctx context.Context, cvFileID int64, text string) []cv.Chunk {
+	stored, err := a.db.GetCVChunks(ctx, cvFileID)
+	if err != nil {
+		log.Printf("[Chunks] CV %d: %v", cvFileID, err)
+	}
+	if len(stored) > 0 {
+		chunks := make([]cv.Chunk, len(stored))
+		for i, c := range stored {
+			chunks[i] = cv.Chunk{Index: c.Index, Text: c.Text, Tokens: c.Tokens}
+		}
+		return chunks
+	}
+	chunks := cv.ChunkText(a.sectionsText(ctx, cvFileID, text, false), cv.ChunkTokens)
+	if len(chunks) > 0 {
+		if err := a.db.SaveCVChunks(ctx, cvFileID, storageChunks(chunks)); err != nil {
+			log.Printf("[Chunks] CV %d: %v", cvFileID, err)
+		}
+	}
+	return chunks
+}
+
+// sectionedCVText returns the text to extract entities from: the CV's
+// sections as "### KIND" blocks when it has been (or can now be) segmented,
+// else the plain text, followed by the fields read from a Word CV's header.
+// Newly detected sections are stored on the cv_files row; useLLM allows the
+// detector's LLM fallback.
+func (a *API) sectionedCVText(ctx context.Context, cvFileID int64, text string, useLLM bool) string {
+	if hint := cv.FormatHeaderHint(a.cvHeader(ctx, cvFileID)); hint != "" {
+		return a.sectionsText(ctx, cvFileID, text, useLLM) + "\n\n" + hint
+	}
+	return a.sectionsText(ctx, cvFileID, text, useLLM)
+}
+
+// cvHeader returns the stored DOCX header fields of a CV, or nil.
+func (a *API) cvHeader(ctx context.Context, cvFileID int64) *cv.DocumentHeader {
+	data, err := a.db.GetCVHeader(ctx, cvFileID)
+	if err != nil {
+		log.Printf("[Header] CV %d: %v", cvFileID, err)
+	}
+	if data == nil {
+		return nil
+	}
+	var h cv.DocumentHeader
+	if err := json.Unmarshal(data, &h); err != nil {
+		log.Printf("[Header] CV %d: %v", cvFileID, err)
+		return nil
+	}
+	return &h
+}
+
+func (a *API) sectionsText(ctx context.Context, cvFileID int64, text string, useLLM bool) string {
+	stored, err := a.db.GetCVSections(ctx, cvFileID)
+	if err != nil {
+		log.Printf("[Sections] CV %d: %v", cvFileID, err)
+	}
+	sections, err := cv.UnmarshalSections(stored)
+	if err != nil {
+		log.Printf("[Sections] CV %d: %v", cvFileID, err)
+	}
+	if sections == nil {
+		if useLLM {
+			sections = a.ai(ctx).sectionDetector.Detect(ctx, text)
+		} else {
+			sections = cv.DetectSections(text)
+		}
+		if sections == nil {
+			return text
+		}
+		if data, err := cv.MarshalSections(sections); err == nil {
+			if err := a.db.SaveCVSections(ctx, cvFileID, data); err != nil {
+				log.Printf("[Sections] CV %d: %v", cvFileID, err)
+			}
+		}
+	}
+	return cv.FormatSections(sections)
+}
@@ -5,18 +5,45 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"time"
+
+	"cv-search/internal/graphrag"
+	"cv-search/internal/storage"
+	"cv-search/internal/tenant"
 )
 
+// generateEmbeddingsResponse reports how many nodes were queued for
+// embedding; estimated_time is empty when none were.
+type generateEmbeddingsResponse struct {
+	Success       bool   `json:"success"`
+	Message       string `json:"message"`
+	PendingNodes  int    `json:"pending_nodes"`
+	EstimatedTime string `json:"estimated_time,omitempty"`
+	RateLimitInfo string `json:"rate_limit_info,omitempty"`
+}
+
+type communityDetectionStats struct {
+	TotalCommunities int `json:"total_communities"`
+	TotalMembers     int `json:"total_members"`
+}
+
+type detectCommunitiesResponse struct {
+	Success        bool                       `json:"success"`
+	ProcessingTime string                     `json:"processing_time"`
+	Level          int                        `json:"level"`
+	Stats          communityDetectionStats    `json:"stats"`
+	Unchanged      int                        `json:"unchanged"`    // communities that kept their title and summary
+	Resummarized   int                        `json:"resummarized"` // communities sent to the LLM
+	Changes        []graphrag.CommunityChange `json:"changes"`      // created, drifted and dissolved communities
+	Message        string                     `json:"message"`
+}
+
+type communityChangesResponse struct {
+	Changes []storage.CommunityChange `json:"changes"`
+}
+
 // GenerateEmbeddingsHandler generates embeddings for all nodes in the graph
-// @Summary Generate Vector Embeddings
-// @Description Generate OpenAI embeddings for all nodes in the knowledge graph
-// @Tags graphrag
-// @Accept json
-// @Produce json
-// @Success 200 {object} map[string]interface{}
-// @Failure 500 {object} map[string]string
-// @Router /graphrag/embeddings/generate [post]
 func (a *API) GenerateEmbeddingsHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -24,7 +51,8 @@ func (a *API) GenerateEmbeddingsHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Check if enhanced search engine is available
-	if a.enhancedSearchEngine == nil {
+	ai := a.ai(r.Context())
+	if ai.enhancedSearchEngine == nil {
 		http.Error(w, "Vector embeddings not available (OpenAI API key not configured)", http.StatusServiceUnavailable)
 		return
 	}
@@ -36,8 +64,10 @@ func (a *API) GenerateEmbeddingsHandler(w http.ResponseWriter, r *http.Request)
 		SELECT node_id 
 		FROM graph_nodes 
 		WHERE embedding IS NULL
+		  AND deleted_at IS NULL
+		  AND org_id = $1
 		ORDER BY created_at DESC
-	`)
+	`, tenant.OrgID(r.Context()))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -54,12 +84,9 @@ func (a *API) GenerateEmbeddingsHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	if len(nodeIDs) == 0 {
-		response := map[string]interface{}{
-			"success": true,
-			"message": "All nodes already have embeddings",
-			"stats": map[string]int{
-				"pending_nodes": 0,
-			},
+		response := generateEmbeddingsResponse{
+			Success: true,
+			Message: "All nodes already have embeddings",
 		}
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(response)
@@ -67,16 +94,16 @@ func (a *API) GenerateEmbeddingsHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Queue job for background processing
-	a.QueueEmbeddingJob(0, nodeIDs) // CV ID = 0 for batch jobs
+	a.QueueEmbeddingJob(r.Context(), 0, nodeIDs) // CV ID = 0 for batch jobs
 
 	estimatedTime := time.Duration(len(nodeIDs)*200) * time.Millisecond // 0.2 seconds per node
 
-	response := map[string]interface{}{
-		"success":         true,
-		"message":         fmt.Sprintf("Background embedding generation started for %d nodes", len(nodeIDs)),
-		"pending_nodes":   len(nodeIDs),
-		"estimated_time":  estimatedTime.String(),
-		"rate_limit_info": "0.2 seconds between requests (5 req/sec - Tier 2 safe)",
+	response := generateEmbeddingsResponse{
+		Success:       true,
+		Message:       fmt.Sprintf("Background embedding generation started for %d nodes", len(nodeIDs)),
+		PendingNodes:  len(nodeIDs),
+		EstimatedTime: estimatedTime.String(),
+		RateLimitInfo: "0.2 seconds between requests (5 req/sec - Tier 2 safe)",
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -84,15 +111,6 @@ func (a *API) GenerateEmbeddingsHandler(w http.ResponseWriter, r *http.Request)
 }
 
 // DetectCommunitiesHandler runs community detection using Leiden algorithm
-// @Summary Detect Communities
-// @Description Run Leiden algorithm to detect communities in the knowledge graph
-// @Tags graphrag
-// @Accept json
-// @Produce json
-// @Param level query int false "Hierarchy level (default: 0)"
-// @Success 200 {object} map[string]interface{}
-// @Failure 500 {object} map[string]string
-// @Router /graphrag/communities/detect [post]
 func (a *API) DetectCommunitiesHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -100,7 +118,8 @@ func (a *API) DetectCommunitiesHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check if enhanced search engine is available
-	if a.enhancedSearchEngine == nil {
+	ai := a.ai(r.Context())
+	if ai.enhancedSearchEngine == nil {
 		http.Error(w, "Community detection not available (LLM not configured)", http.StatusServiceUnavailable)
 		return
 	}
@@ -119,7 +138,7 @@ func (a *API) DetectCommunitiesHandler(w http.ResponseWriter, r *http.Request) {
 	startTime := time.Now()
 
 	// Run community detection
-	err := a.enhancedSearchEngine.GetCommunityDetector().DetectCommunities(r.Context(), level)
+	detection, err := ai.enhancedSearchEngine.GetCommunityDetector().DetectCommunities(r.Context(), level)
 	if err != nil {
 		log.Printf("[Communities API] Failed: %v", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -128,33 +147,72 @@ func (a *API) DetectCommunitiesHandler(w http.ResponseWriter, r *http.Request) {
 
 	processingTime := time.Since(startTime)
 	log.Printf("[Communities API] Completed in %v", processingTime)
+	a.invalidateResponses(r.Context())
 
 	// Get stats
-	var stats struct {
-		TotalCommunities int `json:"total_communities"`
-		TotalMembers     int `json:"total_members"`
-	}
+	var stats communityDetectionStats
 
 	err = a.db.GetConnection().QueryRowContext(r.Context(), `
 		SELECT 
-			COUNT(DISTINCT community_id) as communities,
+			COUNT(DISTINCT cm.community_id) as communities,
 			COUNT(*) as members
-		FROM community_members
-		WHERE level = $1
-	`, level).Scan(&stats.TotalCommunities, &stats.TotalMembers)
+		FROM community_members cm
+		JOIN graph_communities c ON c.id = cm.community_id
+		WHERE c.level = $1 AND c.org_id = $2
+	`, level, tenant.OrgID(r.Context())).Scan(&stats.TotalCommunities, &stats.TotalMembers)
 
 	if err != nil {
 		log.Printf("[Communities API] Stats query failed: %v", err)
 	}
 
-	response := map[string]interface{}{
-		"success":         true,
-		"processing_time": processingTime.String(),
-		"level":           level,
-		"stats":           stats,
-		"message":         "Community detection completed successfully",
+	response := detectCommunitiesResponse{
+		Success:        true,
+		ProcessingTime: processingTime.String(),
+		Level:          level,
+		Stats:          stats,
+		Unchanged:      detection.Unchanged,
+		Resummarized:   detection.Resummarized,
+		Changes:        detection.Changes,
+		Message:        "Community detection completed successfully",
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
+
+// ListCommunityChangesHandler returns the community detection changelog,
+// newest first: communities created, dissolved, or drifted past
+// COMMUNITY_DRIFT_* and re-summarized. level, since (RFC 3339) and limit
+// narrow it.
+// GET /api/graphrag/communities/changes?level=0&since=2026-01-01T00:00:00Z&limit=100
+func (a *API) ListCommunityChangesHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var level *int
+	if v := q.Get("level"); v != "" {
+		l, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "invalid level parameter", http.StatusBadRequest)
+			return
+		}
+		level = &l
+	}
+	var since *time.Time
+	if v := q.Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "invalid since: expected RFC 3339 timestamp", http.StatusBadRequest)
+			return
+		}
+		since = &t
+	}
+
+	changes, err := a.db.ListCommunityChanges(r.Context(), level, since, queryInt(r, "limit", 100, 1000))
+	if err != nil {
+		log.Printf("[Communities API] ListCommunityChanges failed: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(communityChangesResponse{Changes: changes})
+}
@@ -0,0 +1,228 @@
+package api
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"cv-search/internal/tenant"
+)
+
+const communityDetectDebounce = 30 * time.Second
+
+// EmbeddingJob represents a background embedding task
+type EmbeddingJob struct {
+	OrgID     int
+	CVID      int64
+	NodeIDs   []string
+	Timestamp time.Time
+	Persons   bool // person nodes only (personQueue): no chunks, no community detection
+}
+
+// embeddingWorker processes embedding jobs from the queues. New person
+// nodes (personQueue) go first, also between the nodes of a running job, so
+// a new candidate reaches vector search within seconds rather than after
+// every skill and company node queued before them.
+func (a *API) embeddingWorker() {
+	log.Println("[EmbeddingWorker] Started")
+
+	for {
+		select {
+		case job := <-a.personQueue:
+			a.runPersonEmbeddingJob(job)
+		case job := <-a.embeddingQueue:
+			a.embedQueuedPersons()
+			a.embeddingQueueStats.started(job.Timestamp)
+			a.embeddingQueueStats.finished(a.processEmbeddingJob(job))
+		}
+	}
+}
+
+// embedQueuedPersons runs the person embedding jobs waiting in personQueue.
+func (a *API) embedQueuedPersons() {
+	for {
+		select {
+		case job := <-a.personQueue:
+			a.runPersonEmbeddingJob(job)
+		default:
+			return
+		}
+	}
+}
+
+func (a *API) runPersonEmbeddingJob(job EmbeddingJob) {
+	a.personQueueStats.started(job.Timestamp)
+	a.personQueueStats.finished(a.processEmbeddingJob(job))
+}
+
+// processEmbeddingJob embeds a CV's nodes and chunks. It reports whether
+// every one of them was embedded.
+func (a *API) processEmbeddingJob(job EmbeddingJob) bool {
+	log.Printf("[EmbeddingWorker] Processing job for CV %d (%d nodes)", job.CVID, len(job.NodeIDs))
+
+	ctx := tenant.WithOrg(context.Background(), job.OrgID)
+
+	// Check if enhanced search engine is available
+	enhanced := a.ai(ctx).enhancedSearchEngine
+	if enhanced == nil || enhanced.GetEmbeddingService() == nil {
+		log.Printf("[EmbeddingWorker] Enhanced search engine not available, skipping embeddings for CV %d", job.CVID)
+		return false
+	}
+
+	embeddingService := enhanced.GetEmbeddingService()
+
+	// Embed each node with rate limiting
+	successCount := 0
+	failCount := 0
+
+	for i, nodeID := range job.NodeIDs {
+		if !job.Persons {
+			a.embedQueuedPersons()
+		}
+		err := embeddingService.EmbedNode(ctx, nodeID)
+		if err != nil {
+			log.Printf("[EmbeddingWorker] Failed to embed node %s: %v", nodeID, err)
+			failCount++
+		} else {
+			successCount++
+		}
+
+		// Rate limiting: OpenAI API throttling
+		// Tier 1 (free): 3 req/min → 20 seconds
+		// Tier 2 ($5+): 500 req/min → 5 req/sec (0.2s) is safe
+		if i < len(job.NodeIDs)-1 {
+			time.Sleep(200 * time.Millisecond)
+		}
+
+		// Progress logging every 5 nodes
+		if (i+1)%5 == 0 {
+			log.Printf("[EmbeddingWorker] Progress: %d/%d nodes embedded", i+1, len(job.NodeIDs))
+		}
+	}
+
+	if job.Persons {
+		log.Printf("[EmbeddingWorker] Embedded %d person nodes of CV %d (%d failed, %v after queueing)",
+			successCount, job.CVID, failCount, time.Since(job.Timestamp))
+		// Cached results from before the person was searchable would
+		// leave them out.
+		a.invalidateSearchCache(ctx)
+		return failCount == 0
+	}
+
+	// The CV's text chunks, for per-chunk vector search.
+	chunkIDs, err := embeddingService.UnembeddedCVChunkIDs(ctx, job.CVID)
+	if err != nil {
+		log.Printf("[EmbeddingWorker] CV %d: %v", job.CVID, err)
+	}
+	for _, chunkID := range chunkIDs {
+		time.Sleep(200 * time.Millisecond)
+		if err := embeddingService.EmbedCVChunk(ctx, chunkID); err != nil {
+			log.Printf("[EmbeddingWorker] Failed to embed chunk %d of CV %d: %v", chunkID, job.CVID, err)
+			failCount++
+		} else {
+			successCount++
+		}
+	}
+
+	duration := time.Since(job.Timestamp)
+	log.Printf("[EmbeddingWorker] Completed CV %d: %d success, %d failed (took %v)",
+		job.CVID, successCount, failCount, duration)
+	a.invalidateSearchCache(ctx)
+
+	// After embeddings are ready, rebuild communities so the new CV
+	// is assigned to the right cluster immediately.
+	a.triggerCommunityDetection()
+
+	return failCount == 0
+}
+
+// QueueEmbeddingJob adds a new embedding job for ctx's organization to the
+// background queue. Person nodes among nodeIDs go to personQueue, which is
+// embedded first; if it's full they stay in the job, ahead of the rest.
+func (a *API) QueueEmbeddingJob(ctx context.Context, cvID int64, nodeIDs []string) {
+	if a.embeddingQueue == nil {
+		log.Printf("[BackgroundJobs] Embedding queue not initialized, skipping CV %d", cvID)
+		return
+	}
+
+	var persons, others []string
+	for _, id := range nodeIDs {
+		if strings.HasPrefix(id, "person_") {
+			persons = append(persons, id)
+		} else {
+			others = append(others, id)
+		}
+	}
+	if len(persons) > 0 {
+		job := EmbeddingJob{OrgID: tenant.OrgID(ctx), CVID: cvID, NodeIDs: persons, Timestamp: time.Now(), Persons: true}
+		a.personQueueStats.queued(job.Timestamp)
+		select {
+		case a.personQueue <- job:
+			nodeIDs = others
+		default:
+			a.personQueueStats.unqueue(job.Timestamp)
+			log.Printf("[BackgroundJobs] Person queue full, embedding CV %d's person nodes with the rest", cvID)
+			nodeIDs = append(persons, others...)
+		}
+	}
+
+	job := EmbeddingJob{
+		OrgID:     tenant.OrgID(ctx),
+		CVID:      cvID,
+		NodeIDs:   nodeIDs,
+		Timestamp: time.Now(),
+	}
+
+	// Non-blocking send
+	a.embeddingQueueStats.queued(job.Timestamp)
+	select {
+	case a.embeddingQueue <- job:
+		log.Printf("[BackgroundJobs] Queued embedding job for CV %d (%d nodes)", cvID, len(nodeIDs))
+	default:
+		a.embeddingQueueStats.drop(job.Timestamp)
+		log.Printf("[BackgroundJobs] Queue full! Dropping embedding job for CV %d", cvID)
+	}
+}
+
+// triggerCommunityDetection runs a full community detection pass in a background
+// goroutine. Debounced by communityDetectDebounce — if it ran recently (e.g. bulk
+// upload of 10 CVs), the duplicate triggers are silently dropped.
+func (a *API) triggerCommunityDetection() {
+	a.commDetectMu.Lock()
+	if time.Since(a.lastCommDetect) < communityDetectDebounce {
+		a.commDetectMu.Unlock()
+		log.Printf("[CommunityDetect] Skipped (ran %.0fs ago)", time.Since(a.lastCommDetect).Seconds())
+		return
+	}
+	a.lastCommDetect = time.Now()
+	a.commDetectMu.Unlock()
+
+	go func() {
+		log.Printf("[CommunityDetect] Starting automatic community detection after CV upload...")
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+
+		// Each organization is clustered on its own, with its own LLM and
+		// embedding services.
+		orgIDs, err := a.db.ListOrgIDs(ctx)
+		if err != nil {
+			log.Printf("[CommunityDetect] Failed: %v", err)
+			return
+		}
+		for _, orgID := range orgIDs {
+			octx := tenant.WithOrg(ctx, orgID)
+			enhanced := a.ai(octx).enhancedSearchEngine
+			if enhanced == nil {
+				continue // community detection requires LLM to be configured
+			}
+			if detection, err := enhanced.GetCommunityDetector().DetectCommunities(octx, 0); err != nil {
+				log.Printf("[CommunityDetect] Org %d failed: %v", orgID, err)
+			} else {
+				log.Printf("[CommunityDetect] Org %d completed successfully (%d changes, %d re-summarized)",
+					orgID, len(detection.Changes), detection.Resummarized)
+				a.invalidateResponses(octx)
+			}
+		}
+	}()
+}
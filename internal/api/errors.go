@@ -0,0 +1,79 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// ErrorResponse is the body of every 4xx/5xx response:
+//
+//	{"error": "candidate not found", "status": 404}
+type ErrorResponse struct {
+	Error  string `json:"error"`
+	Status int    `json:"status"`
+}
+
+// errorEnvelopeMiddleware turns the plain-text errors written by http.Error
+// (by the handlers and by the mux itself for unknown routes and methods)
+// into an ErrorResponse, so clients parse one error shape everywhere.
+// Error responses that already set another Content-Type pass through.
+func errorEnvelopeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ew := &errorEnvelopeWriter{ResponseWriter: w}
+		next.ServeHTTP(ew, r)
+		ew.flushError()
+	})
+}
+
+// errorEnvelopeWriter buffers the body of a plain-text error response until
+// the handler returns.
+type errorEnvelopeWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	buf         *bytes.Buffer // non-nil while capturing an error
+}
+
+func (w *errorEnvelopeWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.status = code
+	if code >= 400 && strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain") {
+		w.buf = &bytes.Buffer{}
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *errorEnvelopeWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.buf != nil {
+		return w.buf.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *errorEnvelopeWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *errorEnvelopeWriter) flushError() {
+	if w.buf == nil {
+		return
+	}
+	h := w.Header()
+	h.Set("Content-Type", "application/json")
+	h.Del("Content-Length")
+	w.ResponseWriter.WriteHeader(w.status)
+	json.NewEncoder(w.ResponseWriter).Encode(ErrorResponse{
+		Error:  strings.TrimSpace(w.buf.String()),
+		Status: w.status,
+	})
+}
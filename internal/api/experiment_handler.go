@@ -0,0 +1,218 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"cv-search/internal/graphrag"
+	"cv-search/internal/storage"
+	"cv-search/internal/tenant"
+)
+
+type experimentRequest struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Config      json.RawMessage `json:"config"`
+	IsDefault   bool            `json:"is_default"`
+	Active      *bool           `json:"active"` // defaults to true
+}
+
+// resolveHybridConfig builds the hybrid config for a request: built-in
+// defaults with the deployment's stage budgets (SEARCH_*_TIMEOUT_SECONDS)
+// and the organization's community patterns, then the named experiment (or the DB default experiment when none
+// is named), then explicit per-request overrides. Returns a non-empty
+// message when the named experiment can't be used.
+func (a *API) resolveHybridConfig(ctx context.Context, req *HybridSearchRequest) (graphrag.HybridSearchConfig, string) {
+	config := graphrag.DefaultHybridConfig()
+	config.AnalysisTimeout = a.cfg.SearchAnalysisTimeout
+	config.RetrievalTimeout = a.cfg.SearchRetrievalTimeout
+	config.FusionTimeout = a.cfg.SearchFusionTimeout
+	config.RerankTimeout = a.cfg.SearchRerankTimeout
+	config.CommunityPatterns = a.communityPatterns(ctx)
+
+	var exp *storage.SearchExperiment
+	var err error
+	if req.Experiment != "" {
+		exp, err = a.db.GetSearchExperiment(ctx, req.Experiment)
+		if err != nil {
+			log.Printf("[API] Experiment lookup %q failed: %v", req.Experiment, err)
+			return config, "failed to load experiment"
+		}
+		if exp == nil {
+			return config, "unknown or inactive experiment: " + req.Experiment
+		}
+	} else {
+		exp, err = a.db.GetDefaultSearchExperiment(ctx)
+		if err != nil {
+			// Non-fatal: fall back to built-in defaults rather than failing the search.
+			log.Printf("[API] Default experiment lookup failed, using built-in config: %v", err)
+			exp = nil
+		}
+	}
+
+	if exp != nil {
+		ec, err := graphrag.ParseExperimentConfig(exp.Config)
+		if err != nil {
+			log.Printf("[API] Experiment %q has invalid config: %v", exp.Name, err)
+			if req.Experiment != "" {
+				return config, "experiment has invalid config"
+			}
+		} else {
+			ec.Apply(exp.Name, &config)
+		}
+	}
+
+	if req.BM25Weight > 0 {
+		config.BM25Weight = req.BM25Weight
+	}
+	if req.VectorWeight > 0 {
+		config.VectorWeight = req.VectorWeight
+	}
+	if req.GraphWeight > 0 {
+		config.GraphWeight = req.GraphWeight
+	}
+	if req.TopK > 0 {
+		config.TopK = req.TopK
+	}
+	if req.FinalTopN > 0 {
+		config.FinalTopN = req.FinalTopN
+	}
+	if req.Diversity != 0 {
+		if req.Diversity < 0 || req.Diversity >= 1 {
+			return config, "diversity must be between 0 and 1"
+		}
+		config.DiversityLambda = req.Diversity
+	}
+	for field, w := range req.BM25FieldWeights {
+		if err := config.BM25FieldWeights.Set(field, w); err != nil {
+			return config, "bm25_field_weights: " + err.Error()
+		}
+	}
+	if errMsg := applyTagOptions(&config, req); errMsg != "" {
+		return config, errMsg
+	}
+	if errMsg := a.applyLocationOptions(ctx, &config, req); errMsg != "" {
+		return config, errMsg
+	}
+	if errMsg := a.applySalaryOptions(&config, req); errMsg != "" {
+		return config, errMsg
+	}
+	statuses, errMsg := parseIncludeStatuses(req.IncludeStatuses)
+	if errMsg != "" {
+		return config, errMsg
+	}
+	config.IncludeStatuses = statuses
+	syntax, err := graphrag.ParseQuerySyntax(req.Query)
+	if err != nil {
+		return config, "invalid query: " + err.Error()
+	}
+	config.Syntax = syntax
+	return config, ""
+}
+
+// logExperimentRun records which config served a search for ctx's
+// organization, with the features of every result, and returns the search's
+// ID for feedback on them (POST /api/search/{search_id}/feedback). The insert
+// runs in the background — logging must never slow down or fail the search
+// response.
+func (a *API) logExperimentRun(ctx context.Context, query string, config graphrag.HybridSearchConfig, results []graphrag.FusedCandidate, elapsed time.Duration) string {
+	return a.logExperimentFeatures(ctx, query, config, resultFeatures(results), elapsed)
+}
+
+// logExperimentFeatures is logExperimentRun for results already reduced to
+// their features, e.g. those of a search response replayed from the cache.
+func (a *API) logExperimentFeatures(ctx context.Context, query string, config graphrag.HybridSearchConfig, features []searchResultFeatures, elapsed time.Duration) string {
+	searchID, err := newSearchID()
+	if err != nil {
+		log.Printf("[API] Search ID generation failed: %v", err)
+	}
+	ids := make([]int, 0, len(features))
+	for _, f := range features {
+		ids = append(ids, f.CandidateID)
+	}
+	orgID := tenant.OrgID(ctx)
+	go func() {
+		ctx, cancel := context.WithTimeout(tenant.WithOrg(context.Background(), orgID), 10*time.Second)
+		defer cancel()
+		if err := a.db.LogSearchExperiment(ctx, storage.SearchExperimentLog{
+			SearchID:       searchID,
+			ExperimentName: config.Experiment,
+			Query:          query,
+			Config:         config,
+			ResultIDs:      ids,
+			Results:        features,
+			DurationMS:     int(elapsed.Milliseconds()),
+		}); err != nil {
+			log.Printf("[API] Experiment log failed: %v", err)
+		}
+	}()
+	return searchID
+}
+
+// ListExperimentsHandler returns all configured search experiments.
+func (a *API) ListExperimentsHandler(w http.ResponseWriter, r *http.Request) {
+	experiments, err := a.db.ListSearchExperiments(r.Context())
+	if err != nil {
+		log.Printf("[API] ListSearchExperiments failed: %v", err)
+		http.Error(w, "failed to list experiments", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(experiments)
+}
+
+// UpsertExperimentHandler creates or updates a named search experiment.
+func (a *API) UpsertExperimentHandler(w http.ResponseWriter, r *http.Request) {
+	var req experimentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Name == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	}
+
+	// Validate up front so a broken config can never be stored (and then
+	// silently ignored at search time).
+	ec, err := graphrag.ParseExperimentConfig(req.Config)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	probe := graphrag.DefaultHybridConfig()
+	ec.Apply(req.Name, &probe)
+	if total := probe.BM25Weight + probe.VectorWeight + probe.GraphWeight; total < 0.9 || total > 1.1 {
+		http.Error(w, "Weights must sum to 1.0", http.StatusBadRequest)
+		return
+	}
+
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+
+	saved, err := a.db.UpsertSearchExperiment(r.Context(), &storage.SearchExperiment{
+		Name:        req.Name,
+		Description: req.Description,
+		Config:      req.Config,
+		IsDefault:   req.IsDefault,
+		Active:      active,
+	})
+	if err != nil {
+		log.Printf("[API] UpsertSearchExperiment failed: %v", err)
+		http.Error(w, "failed to save experiment", http.StatusInternalServerError)
+		return
+	}
+	a.audit(r, "upsert", "experiment", saved.Name, map[string]interface{}{
+		"config": saved.Config, "is_default": saved.IsDefault, "active": saved.Active,
+	})
+	a.invalidateResponses(r.Context()) // searches may run under another default experiment
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(saved)
+}
@@ -0,0 +1,246 @@
+package api
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"cv-search/internal/graphrag"
+	"cv-search/internal/storage"
+)
+
+// ─── Search feedback ─────────────────────────────────────────────────────────
+//
+// Every logged hybrid search gets a search_id, returned with its results.
+// Recruiters label results good, bad or hired (optionally with the score they
+// would have given); each label is stored with the result's features as
+// served, and the export pairs them with the query and config, so ranking
+// weights and prompts can be tuned against real outcomes.
+
+// maxFeedbackItems caps the results one feedback request labels.
+const maxFeedbackItems = 100
+
+// feedbackLabels are the verdicts a recruiter can give a result.
+var feedbackLabels = map[string]bool{"good": true, "bad": true, "hired": true}
+
+type searchFeedbackItem struct {
+	CandidateID   int      `json:"candidate_id"`
+	Label         string   `json:"label"`                    // good | bad | hired
+	ScoreOverride *float64 `json:"score_override,omitempty"` // 0-100, the score the candidate should have had
+	Comment       string   `json:"comment,omitempty"`
+}
+
+type searchFeedbackRequest struct {
+	Items []searchFeedbackItem `json:"items"`
+}
+
+type searchFeedbackResponse struct {
+	SearchID string                   `json:"search_id"`
+	Feedback []storage.SearchFeedback `json:"feedback"`
+}
+
+// searchResultFeatures is what the search log keeps of each served result:
+// its scores and the features ranking used, the inputs a tuned ranking
+// would see.
+type searchResultFeatures struct {
+	CandidateID          int                      `json:"candidate_id"`
+	Rank                 int                      `json:"rank"`
+	BM25Score            float64                  `json:"bm25_score"`
+	VectorScore          float64                  `json:"vector_score"`
+	GraphScore           float64                  `json:"graph_score"`
+	FusionScore          float64                  `json:"fusion_score"`
+	LLMScore             float64                  `json:"llm_score"`
+	Seniority            string                   `json:"seniority,omitempty"`
+	TotalExperienceYears int                      `json:"total_experience_years"`
+	SkillCount           int                      `json:"skill_count"`
+	CompanyCount         int                      `json:"company_count"`
+	InterviewCount       int                      `json:"interview_count"`
+	Tags                 []string                 `json:"tags,omitempty"`
+	Community            string                   `json:"community,omitempty"`
+	DistanceKm           *float64                 `json:"distance_km,omitempty"`
+	Signals              *graphrag.RankingSignals `json:"signals,omitempty"`
+}
+
+// newSearchID returns a random search ID, e.g. "srch_3f2a…".
+func newSearchID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "srch_" + hex.EncodeToString(b), nil
+}
+
+// resultFeatures returns the logged features of a search's results.
+func resultFeatures(results []graphrag.FusedCandidate) []searchResultFeatures {
+	features := make([]searchResultFeatures, 0, len(results))
+	for _, c := range results {
+		features = append(features, searchResultFeatures{
+			CandidateID:          c.CandidateID,
+			Rank:                 c.Rank,
+			BM25Score:            c.BM25Score,
+			VectorScore:          c.VectorScore,
+			GraphScore:           c.GraphScore,
+			FusionScore:          c.FusionScore,
+			LLMScore:             c.LLMScore,
+			Seniority:            c.Seniority,
+			TotalExperienceYears: c.TotalExperienceYears,
+			SkillCount:           len(c.Skills),
+			CompanyCount:         len(c.Companies),
+			InterviewCount:       len(c.Interviews),
+			Tags:                 c.Tags,
+			Community:            c.Community,
+			DistanceKm:           c.DistanceKm,
+			Signals:              c.Signals,
+		})
+	}
+	return features
+}
+
+// responseFeatures is resultFeatures of results already in the response
+// shape, e.g. a cached search response's.
+func responseFeatures(candidates []FusedCandidateResponse) []searchResultFeatures {
+	features := make([]searchResultFeatures, 0, len(candidates))
+	for _, c := range candidates {
+		features = append(features, searchResultFeatures{
+			CandidateID:          c.ID,
+			Rank:                 c.Rank,
+			BM25Score:            c.BM25Score,
+			VectorScore:          c.VectorScore,
+			GraphScore:           c.GraphScore,
+			FusionScore:          c.FusionScore,
+			LLMScore:             c.LLMScore,
+			Seniority:            c.Seniority,
+			TotalExperienceYears: c.TotalExperienceYears,
+			SkillCount:           len(c.Skills),
+			CompanyCount:         len(c.Companies),
+			InterviewCount:       len(c.Interviews),
+			Tags:                 c.Tags,
+			Community:            c.Community,
+			DistanceKm:           c.DistanceKm,
+			Signals:              c.Signals,
+		})
+	}
+	return features
+}
+
+// validateFeedbackItems normalizes labels and comments and returns an error
+// message for the first invalid item.
+func validateFeedbackItems(items []searchFeedbackItem) string {
+	if len(items) == 0 {
+		return "items cannot be empty"
+	}
+	if len(items) > maxFeedbackItems {
+		return fmt.Sprintf("at most %d items per request", maxFeedbackItems)
+	}
+	for i := range items {
+		it := &items[i]
+		it.Label = strings.ToLower(strings.TrimSpace(it.Label))
+		it.Comment = strings.TrimSpace(it.Comment)
+		switch {
+		case it.CandidateID <= 0:
+			return fmt.Sprintf("items[%d]: candidate_id is required", i)
+		case !feedbackLabels[it.Label]:
+			return fmt.Sprintf("items[%d]: label must be good, bad or hired", i)
+		case it.ScoreOverride != nil && (*it.ScoreOverride < 0 || *it.ScoreOverride > 100):
+			return fmt.Sprintf("items[%d]: score_override must be between 0 and 100", i)
+		case utf8.RuneCountInString(it.Comment) > maxNoteLength:
+			return fmt.Sprintf("items[%d]: comment exceeds %d characters", i, maxNoteLength)
+		}
+	}
+	return ""
+}
+
+// SearchFeedbackHandler labels results of a logged search good, bad or hired.
+// Feedback on a result given earlier is replaced.
+// POST /api/search/{search_id}/feedback
+func (a *API) SearchFeedbackHandler(w http.ResponseWriter, r *http.Request) {
+	searchID := r.PathValue("search_id")
+
+	var req searchFeedbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if msg := validateFeedbackItems(req.Items); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	items := make([]storage.SearchFeedback, len(req.Items))
+	for i, it := range req.Items {
+		items[i] = storage.SearchFeedback{
+			CandidateID:   it.CandidateID,
+			Label:         it.Label,
+			ScoreOverride: it.ScoreOverride,
+			Comment:       it.Comment,
+		}
+	}
+	saved, err := a.db.RecordSearchFeedback(r.Context(), searchID, items, actorFromRequest(r))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		http.Error(w, "search not found", http.StatusNotFound)
+		return
+	case errors.Is(err, storage.ErrNotInSearch):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	case err != nil:
+		log.Printf("[Feedback] RecordSearchFeedback(search=%s) failed: %v", searchID, err)
+		http.Error(w, "failed to record feedback", http.StatusInternalServerError)
+		return
+	}
+
+	labels := make(map[string]string, len(saved))
+	for _, f := range saved {
+		labels[fmt.Sprint(f.CandidateID)] = f.Label
+	}
+	a.audit(r, "feedback", "search", searchID, map[string]interface{}{"labels": labels})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(searchFeedbackResponse{SearchID: searchID, Feedback: saved})
+}
+
+// ExportSearchFeedbackHandler streams the organization's search feedback as
+// JSON Lines, oldest first, one labeled result per line with its features,
+// query and config. since / until (RFC 3339) bound when it was given.
+// GET /api/search/feedback/export
+func (a *API) ExportSearchFeedbackHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var since, until *time.Time
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{{"since", &since}, {"until", &until}} {
+		v := q.Get(p.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "invalid "+p.name+": expected RFC 3339 timestamp", http.StatusBadRequest)
+			return
+		}
+		*p.dst = &t
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	enc := json.NewEncoder(w)
+	n, err := a.db.ExportSearchFeedback(r.Context(), since, until, func(e storage.SearchFeedbackExport) error {
+		return enc.Encode(e)
+	})
+	if err != nil {
+		// Rows already sent can't be taken back; the truncated export ends
+		// without the rest.
+		log.Printf("[Feedback] ExportSearchFeedback failed after %d rows: %v", n, err)
+		if n == 0 {
+			http.Error(w, "failed to export feedback", http.StatusInternalServerError)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"cv-search/internal/graphrag"
+)
+
+// maxJobDescriptionLength caps a gap analysis' job description, in
+// characters.
+const maxJobDescriptionLength = 20000
+
+type gapAnalysisRequest struct {
+	JobDescription string `json:"job_description"`
+}
+
+type gapAnalysisResponse struct {
+	CandidateID int `json:"candidate_id"`
+	*graphrag.GapAnalysis
+}
+
+// GapAnalysisHandler holds a candidate against a job description: the JD's
+// required and preferred skills the candidate has, lacks, and has
+// transferable skills for, with an LLM-written development summary.
+// POST /api/candidates/{id}/gap-analysis
+func (a *API) GapAnalysisHandler(w http.ResponseWriter, r *http.Request) {
+	candidateID, err := parseCandidateID(r)
+	if err != nil {
+		http.Error(w, "invalid candidate id", http.StatusBadRequest)
+		return
+	}
+	var req gapAnalysisRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.JobDescription = strings.TrimSpace(req.JobDescription)
+	if req.JobDescription == "" {
+		http.Error(w, "job_description is required", http.StatusBadRequest)
+		return
+	}
+	if msg := checkJobDescription(req.JobDescription); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	engine := a.ai(r.Context()).llmSearchEngine
+	if engine == nil {
+		http.Error(w, "gap analysis not available (LLM not configured)", http.StatusServiceUnavailable)
+		return
+	}
+	graphNodeID, ok := a.candidateGraphNode(w, r, candidateID, "GapAnalysis")
+	if !ok {
+		return
+	}
+
+	gap, err := engine.AnalyzeGap(r.Context(), graphNodeID, req.JobDescription)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, errNoGraphProfile, http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("[GapAnalysis] AnalyzeGap(candidate=%d) failed: %v", candidateID, err)
+		http.Error(w, "gap analysis failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(gapAnalysisResponse{CandidateID: candidateID, GapAnalysis: gap})
+}
+
+// errNoGraphProfile is the 409 for a candidate whose CV hasn't been turned
+// into a graph yet.
+const errNoGraphProfile = "candidate has no graph profile yet (CV not processed)"
+
+// checkJobDescription returns an error message if a job description is too
+// long.
+func checkJobDescription(jd string) string {
+	if utf8.RuneCountInString(jd) > maxJobDescriptionLength {
+		return fmt.Sprintf("job_description exceeds %d characters", maxJobDescriptionLength)
+	}
+	return ""
+}
+
+// candidateGraphNode returns the person node of a candidate of the request's
+// organization. If there is none it writes the error (404, or 409 while the
+// CV is unprocessed) and returns false.
+func (a *API) candidateGraphNode(w http.ResponseWriter, r *http.Request, candidateID int, tag string) (int, bool) {
+	graphNodeID, err := a.db.GetGraphNodeIDForCandidate(r.Context(), candidateID)
+	if err != nil {
+		log.Printf("[%s] GetGraphNodeIDForCandidate(%d) failed: %v", tag, candidateID, err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return 0, false
+	}
+	if graphNodeID > 0 {
+		return graphNodeID, true
+	}
+	ok, err := a.db.CandidateInOrg(r.Context(), candidateID)
+	if err != nil {
+		log.Printf("[%s] %v", tag, err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return 0, false
+	}
+	if !ok {
+		http.Error(w, "candidate not found", http.StatusNotFound)
+		return 0, false
+	}
+	http.Error(w, errNoGraphProfile, http.StatusConflict)
+	return 0, false
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"cv-search/internal/graphrag"
+)
+
+// maxGraphQueryLength caps a graph query, in characters.
+const maxGraphQueryLength = 2000
+
+type graphQueryRequest struct {
+	Query string `json:"query"`
+}
+
+type graphQueryResponse struct {
+	Match string `json:"match"`
+	Count int    `json:"count"`
+	*graphrag.GraphQueryResult
+}
+
+// GraphQueryHandler runs a graph query (graphrag.ParseGraphQuery) over the
+// caller's organization's graph, compiled to parameterized SQL on the read
+// replica.
+// POST /api/graph/query
+func (a *API) GraphQueryHandler(w http.ResponseWriter, r *http.Request) {
+	var req graphQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.Query = strings.TrimSpace(req.Query)
+	if req.Query == "" {
+		http.Error(w, "query is required", http.StatusBadRequest)
+		return
+	}
+	if utf8.RuneCountInString(req.Query) > maxGraphQueryLength {
+		http.Error(w, fmt.Sprintf("query exceeds %d characters", maxGraphQueryLength), http.StatusBadRequest)
+		return
+	}
+	q, err := graphrag.ParseGraphQuery(req.Query)
+	if err != nil {
+		http.Error(w, "invalid query: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := graphrag.RunGraphQuery(r.Context(), a.db.ReadConnection(), q)
+	if err != nil {
+		log.Printf("[GraphQuery] %q failed: %v", req.Query, err)
+		http.Error(w, "graph query failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(graphQueryResponse{Match: q.Match, Count: len(result.Rows), GraphQueryResult: result})
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"cv-search/internal/storage"
+
+	"github.com/graph-gophers/dataloader/v7"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+//go:embed schema.graphql
+var graphqlSchema string
+
+// Limits on what one GraphQL request may ask for. graphqlMaxDepth allows
+// e.g. nodes → edges → target → edges → target → candidate → skills.
+const (
+	graphqlMaxDepth     = 10
+	graphqlMaxPageSize  = 100
+	graphqlMaxBodyBytes = 1 << 20
+	graphqlBatchWait    = 2 * time.Millisecond
+)
+
+// ─── Request/Response types ───────────────────────────────────────────────────
+
+type graphqlRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// ─── Dataloaders ─────────────────────────────────────────────────────────────
+
+// gqlLoaders batch and cache the lookups of one GraphQL request, so
+// resolving a field on every element of a list is one query.
+type gqlLoaders struct {
+	candidates     *dataloader.Loader[int, *storage.CandidateDetail]
+	skills         *dataloader.Loader[int, []storage.CandidateSkill]
+	tags           *dataloader.Loader[int, []string]
+	interviews     *dataloader.Loader[int, []storage.Interview]
+	candidateCVs   *dataloader.Loader[int, []storage.CVFileListItem]
+	cvFiles        *dataloader.Loader[int64, *storage.CVFileListItem]
+	nodes          *dataloader.Loader[int, *storage.GraphNode]
+	nodeCandidates *dataloader.Loader[int, int]
+	edges          *dataloader.Loader[int, []storage.GraphEdge]
+	members        *dataloader.Loader[int, []int]
+}
+
+type gqlLoadersKey struct{}
+
+func (a *API) newGQLLoaders() *gqlLoaders {
+	return &gqlLoaders{
+		candidates:     newBatchLoader(a.db.GetCandidatesByIDs),
+		skills:         newBatchLoader(a.db.GetSkillsByCandidateIDs),
+		tags:           newBatchLoader(a.db.GetTagsByCandidateIDs),
+		interviews:     newBatchLoader(a.db.GetInterviewsByCandidateIDs),
+		candidateCVs:   newBatchLoader(a.db.GetCVFilesByCandidateIDs),
+		cvFiles:        newBatchLoader(a.db.GetCVFilesByIDs),
+		nodes:          newBatchLoader(a.db.GetGraphNodesByIDs),
+		nodeCandidates: newBatchLoader(a.db.GetCandidateIDsByGraphNodeIDs),
+		edges:          newBatchLoader(a.db.GetGraphEdgesByNodeIDs),
+		members:        newBatchLoader(a.db.GetCommunityMemberNodeIDs),
+	}
+}
+
+// newBatchLoader wraps a storage lookup by many IDs; a key missing from its
+// result loads as V's zero value.
+func newBatchLoader[K comparable, V any](fetch func(context.Context, []K) (map[K]V, error)) *dataloader.Loader[K, V] {
+	return dataloader.NewBatchedLoader(func(ctx context.Context, keys []K) []*dataloader.Result[V] {
+		found, err := fetch(ctx, keys)
+		results := make([]*dataloader.Result[V], len(keys))
+		for i, k := range keys {
+			if err != nil {
+				results[i] = &dataloader.Result[V]{Error: err}
+			} else {
+				results[i] = &dataloader.Result[V]{Data: found[k]}
+			}
+		}
+		return results
+	}, dataloader.WithWait[K, V](graphqlBatchWait))
+}
+
+func loadersFrom(ctx context.Context) *gqlLoaders {
+	return ctx.Value(gqlLoadersKey{}).(*gqlLoaders)
+}
+
+// ─── Handler ─────────────────────────────────────────────────────────────────
+
+// newGraphQLSchema parses schema.graphql against the resolvers; a mismatch
+// between the two panics at startup.
+func (a *API) newGraphQLSchema() *graphql.Schema {
+	return graphql.MustParseSchema(graphqlSchema, &gqlQuery{a: a},
+		graphql.MaxDepth(graphqlMaxDepth),
+	)
+}
+
+// GraphQLHandler runs a GraphQL query over candidates, CV files, graph nodes
+// and edges, communities and hybrid search (see schema.graphql), scoped to
+// the caller's organization like the rest of /api/.
+//
+//	POST /api/graphql  {"query": "{ candidates(first: 10) { name skills { name } cvFiles { filename } } }"}
+func (a *API) GraphQLHandler(w http.ResponseWriter, r *http.Request) {
+	var req graphqlRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, graphqlMaxBodyBytes)).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Query == "" {
+		http.Error(w, "query is required", http.StatusBadRequest)
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), gqlLoadersKey{}, a.newGQLLoaders())
+	res := a.graphqlSchema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
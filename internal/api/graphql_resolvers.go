@@ -0,0 +1,519 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"cv-search/internal/storage"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// Resolvers for schema.graphql. Storage errors are logged and reported to
+// the client as "database error", like the REST handlers.
+
+var errGraphQLDatabase = errors.New("database error")
+
+func gqlDBError(err error) error {
+	log.Printf("[GraphQL] %v", err)
+	return errGraphQLDatabase
+}
+
+// gqlPage clamps first/offset arguments (defaulted in the schema) to a page
+// the storage layer accepts.
+func gqlPage(first, offset int32) (limit, skip int) {
+	limit = int(first)
+	if limit < 1 {
+		limit = 1
+	}
+	if limit > graphqlMaxPageSize {
+		limit = graphqlMaxPageSize
+	}
+	if offset > 0 {
+		skip = int(offset)
+	}
+	return limit, skip
+}
+
+func gqlIntID(id graphql.ID) (int, error) {
+	n, err := strconv.Atoi(string(id))
+	if err != nil || n <= 0 {
+		return 0, errors.New("invalid id " + strconv.Quote(string(id)))
+	}
+	return n, nil
+}
+
+func gqlID(id int64) graphql.ID {
+	return graphql.ID(strconv.FormatInt(id, 10))
+}
+
+// optString maps "" to null.
+func optString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// ─── Query ───────────────────────────────────────────────────────────────────
+
+type gqlQuery struct {
+	a *API
+}
+
+func (q *gqlQuery) Candidate(ctx context.Context, args struct{ ID graphql.ID }) (*gqlCandidate, error) {
+	id, err := gqlIntID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	return loadCandidate(ctx, id)
+}
+
+func (q *gqlQuery) Candidates(ctx context.Context, args struct{ First, Offset int32 }) ([]*gqlCandidate, error) {
+	limit, offset := gqlPage(args.First, args.Offset)
+	items, err := q.a.db.ListCandidates(ctx, limit, offset)
+	if err != nil {
+		return nil, gqlDBError(err)
+	}
+	// The list rows lack contact fields; load full ones in one batch.
+	ids := make([]int, len(items))
+	for i, it := range items {
+		ids[i] = it.ID
+	}
+	found, errs := loadersFrom(ctx).candidates.LoadMany(ctx, ids)()
+	if err := errors.Join(errs...); err != nil {
+		return nil, gqlDBError(err)
+	}
+	out := make([]*gqlCandidate, 0, len(found))
+	for _, c := range found {
+		if c != nil {
+			out = append(out, &gqlCandidate{c})
+		}
+	}
+	return out, nil
+}
+
+func (q *gqlQuery) CvFile(ctx context.Context, args struct{ ID graphql.ID }) (*gqlCVFile, error) {
+	id, err := gqlIntID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	f, err := loadersFrom(ctx).cvFiles.Load(ctx, int64(id))()
+	if err != nil {
+		return nil, gqlDBError(err)
+	}
+	if f == nil {
+		return nil, nil
+	}
+	return &gqlCVFile{f}, nil
+}
+
+func (q *gqlQuery) CvFiles(ctx context.Context, args struct {
+	First, Offset int32
+	Quality       *string
+}) ([]*gqlCVFile, error) {
+	limit, offset := gqlPage(args.First, args.Offset)
+	quality := ""
+	if args.Quality != nil {
+		quality = *args.Quality
+	}
+	items, err := q.a.db.ListCVFiles(ctx, quality, limit, offset)
+	if err != nil {
+		return nil, gqlDBError(err)
+	}
+	out := make([]*gqlCVFile, len(items))
+	for i := range items {
+		out[i] = &gqlCVFile{&items[i]}
+	}
+	return out, nil
+}
+
+func (q *gqlQuery) Node(ctx context.Context, args struct{ ID graphql.ID }) (*gqlNode, error) {
+	id, err := gqlIntID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	return loadNode(ctx, id)
+}
+
+func (q *gqlQuery) Nodes(ctx context.Context, args struct {
+	Type          *string
+	First, Offset int32
+}) ([]*gqlNode, error) {
+	limit, offset := gqlPage(args.First, args.Offset)
+	nodeType := ""
+	if args.Type != nil {
+		nodeType = *args.Type
+	}
+	nodes, err := q.a.db.ListGraphNodes(ctx, nodeType, limit, offset)
+	if err != nil {
+		return nil, gqlDBError(err)
+	}
+	out := make([]*gqlNode, len(nodes))
+	for i := range nodes {
+		out[i] = &gqlNode{&nodes[i]}
+	}
+	return out, nil
+}
+
+func (q *gqlQuery) Communities(ctx context.Context, args struct {
+	Level         *int32
+	First, Offset int32
+}) ([]*gqlCommunity, error) {
+	limit, offset := gqlPage(args.First, args.Offset)
+	var level *int
+	if args.Level != nil {
+		l := int(*args.Level)
+		level = &l
+	}
+	communities, err := q.a.db.ListCommunities(ctx, level, limit, offset)
+	if err != nil {
+		return nil, gqlDBError(err)
+	}
+	out := make([]*gqlCommunity, len(communities))
+	for i := range communities {
+		out[i] = &gqlCommunity{&communities[i]}
+	}
+	return out, nil
+}
+
+func (q *gqlQuery) Search(ctx context.Context, args struct {
+	Query       string
+	Experiment  *string
+	FinalTopN   *int32
+	Tags        *[]string
+	ExcludeTags *[]string
+}) (*gqlSearchResults, error) {
+	a := q.a
+	ai := a.ai(ctx)
+	if ai.hybridSearchEngine == nil {
+		return nil, errors.New("hybrid search not available (OpenAI API key required)")
+	}
+	if strings.TrimSpace(args.Query) == "" {
+		return nil, errors.New("query cannot be empty")
+	}
+	req := HybridSearchRequest{Query: args.Query}
+	if args.Experiment != nil {
+		req.Experiment = *args.Experiment
+	}
+	if args.FinalTopN != nil {
+		req.FinalTopN = int(*args.FinalTopN)
+	}
+	if args.Tags != nil {
+		req.Tags = *args.Tags
+	}
+	if args.ExcludeTags != nil {
+		req.ExcludeTags = *args.ExcludeTags
+	}
+	config, errMsg := a.resolveHybridConfig(ctx, &req)
+	if errMsg != "" {
+		return nil, errors.New(errMsg)
+	}
+	if total := config.BM25Weight + config.VectorWeight + config.GraphWeight; total < 0.9 || total > 1.1 {
+		return nil, errors.New("weights must sum to 1.0")
+	}
+	if denial, _, err := a.checkQuota(ctx, quotaSearches, 1); err != nil {
+		log.Printf("[GraphQL] %v", err)
+	} else if denial != nil {
+		return nil, denial
+	}
+
+	start := time.Now()
+	results, diag, err := ai.hybridSearchEngine.SearchWithDiagnostics(ctx, req.Query, config)
+	if err != nil {
+		log.Printf("[GraphQL] hybrid search failed: %v", err)
+		return nil, errors.New("search failed")
+	}
+	a.countUsage(ctx, 0, 1)
+	a.logExperimentRun(ctx, req.Query, config, results, time.Since(start))
+
+	res := &gqlSearchResults{experiment: config.Experiment, warnings: []string{}, hits: []*gqlSearchHit{}}
+	if diag != nil {
+		if diag.Warnings != nil {
+			res.warnings = diag.Warnings
+		}
+		res.cacheHit = diag.SemanticCacheHit
+	}
+	for _, c := range toFusedCandidateResponses(results) {
+		res.hits = append(res.hits, &gqlSearchHit{c})
+	}
+	return res, nil
+}
+
+// ─── Candidates ──────────────────────────────────────────────────────────────
+
+type gqlCandidate struct {
+	c *storage.CandidateDetail
+}
+
+func loadCandidate(ctx context.Context, id int) (*gqlCandidate, error) {
+	c, err := loadersFrom(ctx).candidates.Load(ctx, id)()
+	if err != nil {
+		return nil, gqlDBError(err)
+	}
+	if c == nil {
+		return nil, nil
+	}
+	return &gqlCandidate{c}, nil
+}
+
+func (r *gqlCandidate) ID() graphql.ID           { return gqlID(int64(r.c.ID)) }
+func (r *gqlCandidate) Name() string             { return r.c.Name }
+func (r *gqlCandidate) Email() *string           { return optString(r.c.Email) }
+func (r *gqlCandidate) Phone() *string           { return optString(r.c.Phone) }
+func (r *gqlCandidate) LinkedinURL() *string     { return optString(r.c.LinkedInURL) }
+func (r *gqlCandidate) Location() *string        { return optString(r.c.Location) }
+func (r *gqlCandidate) CurrentPosition() *string { return optString(r.c.CurrentPosition) }
+func (r *gqlCandidate) Seniority() *string       { return optString(r.c.Seniority) }
+func (r *gqlCandidate) CreatedAt() graphql.Time  { return graphql.Time{Time: r.c.CreatedAt} }
+
+func (r *gqlCandidate) Skills(ctx context.Context) ([]*gqlSkill, error) {
+	skills, err := loadersFrom(ctx).skills.Load(ctx, r.c.ID)()
+	if err != nil {
+		return nil, gqlDBError(err)
+	}
+	out := make([]*gqlSkill, len(skills))
+	for i := range skills {
+		out[i] = &gqlSkill{&skills[i]}
+	}
+	return out, nil
+}
+
+func (r *gqlCandidate) Tags(ctx context.Context) ([]string, error) {
+	tags, err := loadersFrom(ctx).tags.Load(ctx, r.c.ID)()
+	if err != nil {
+		return nil, gqlDBError(err)
+	}
+	if tags == nil {
+		tags = []string{}
+	}
+	return tags, nil
+}
+
+func (r *gqlCandidate) Interviews(ctx context.Context) ([]*gqlInterview, error) {
+	ivs, err := loadersFrom(ctx).interviews.Load(ctx, r.c.ID)()
+	if err != nil {
+		return nil, gqlDBError(err)
+	}
+	out := make([]*gqlInterview, len(ivs))
+	for i := range ivs {
+		out[i] = &gqlInterview{&ivs[i]}
+	}
+	return out, nil
+}
+
+func (r *gqlCandidate) CvFiles(ctx context.Context) ([]*gqlCVFile, error) {
+	files, err := loadersFrom(ctx).candidateCVs.Load(ctx, r.c.ID)()
+	if err != nil {
+		return nil, gqlDBError(err)
+	}
+	out := make([]*gqlCVFile, len(files))
+	for i := range files {
+		out[i] = &gqlCVFile{&files[i]}
+	}
+	return out, nil
+}
+
+func (r *gqlCandidate) GraphNode(ctx context.Context) (*gqlNode, error) {
+	if r.c.GraphNodeID == nil {
+		return nil, nil
+	}
+	return loadNode(ctx, *r.c.GraphNodeID)
+}
+
+type gqlSkill struct {
+	s *storage.CandidateSkill
+}
+
+func (r *gqlSkill) Name() string         { return r.s.Name }
+func (r *gqlSkill) Proficiency() *string { return optString(r.s.Proficiency) }
+func (r *gqlSkill) Years() *float64      { return r.s.Years }
+
+type gqlInterview struct {
+	iv *storage.Interview
+}
+
+func (r *gqlInterview) ID() graphql.ID              { return gqlID(int64(r.iv.ID)) }
+func (r *gqlInterview) InterviewDate() graphql.Time { return graphql.Time{Time: r.iv.InterviewDate} }
+func (r *gqlInterview) Team() *string               { return optString(r.iv.Team) }
+func (r *gqlInterview) InterviewerName() *string    { return optString(r.iv.InterviewerName) }
+func (r *gqlInterview) InterviewType() *string      { return optString(r.iv.InterviewType) }
+func (r *gqlInterview) Notes() *string              { return optString(r.iv.Notes) }
+func (r *gqlInterview) Outcome() *string            { return optString(r.iv.Outcome) }
+
+// ─── CV files ────────────────────────────────────────────────────────────────
+
+type gqlCVFile struct {
+	f *storage.CVFileListItem
+}
+
+func (r *gqlCVFile) ID() graphql.ID           { return gqlID(r.f.ID) }
+func (r *gqlCVFile) Filename() string         { return r.f.Filename }
+func (r *gqlCVFile) FileType() *string        { return optString(r.f.FileType) }
+func (r *gqlCVFile) FileSize() int32          { return int32(r.f.FileSize) }
+func (r *gqlCVFile) UploadedAt() graphql.Time { return graphql.Time{Time: r.f.UploadedAt} }
+func (r *gqlCVFile) OcrUsed() bool            { return r.f.OCRUsed }
+func (r *gqlCVFile) Anonymized() bool         { return r.f.Anonymized }
+func (r *gqlCVFile) QualityStatus() string    { return r.f.QualityStatus }
+func (r *gqlCVFile) QualityScore() *float64   { return r.f.QualityScore }
+
+func (r *gqlCVFile) QualityIssues() []string {
+	if r.f.QualityIssues == nil {
+		return []string{}
+	}
+	return r.f.QualityIssues
+}
+
+func (r *gqlCVFile) Candidate(ctx context.Context) (*gqlCandidate, error) {
+	if r.f.CandidateID == nil {
+		return nil, nil
+	}
+	return loadCandidate(ctx, *r.f.CandidateID)
+}
+
+// ─── Graph ───────────────────────────────────────────────────────────────────
+
+type gqlNode struct {
+	n *storage.GraphNode
+}
+
+func loadNode(ctx context.Context, id int) (*gqlNode, error) {
+	n, err := loadersFrom(ctx).nodes.Load(ctx, id)()
+	if err != nil {
+		return nil, gqlDBError(err)
+	}
+	if n == nil {
+		return nil, nil
+	}
+	return &gqlNode{n}, nil
+}
+
+func (r *gqlNode) ID() graphql.ID          { return gqlID(int64(r.n.ID)) }
+func (r *gqlNode) Type() string            { return r.n.NodeType }
+func (r *gqlNode) Key() string             { return r.n.NodeID }
+func (r *gqlNode) Properties() string      { return string(r.n.Properties) }
+func (r *gqlNode) CreatedAt() graphql.Time { return graphql.Time{Time: r.n.CreatedAt} }
+
+func (r *gqlNode) Name() *string {
+	var props struct {
+		Name string `json:"name"`
+	}
+	json.Unmarshal(r.n.Properties, &props)
+	return optString(props.Name)
+}
+
+func (r *gqlNode) Edges(ctx context.Context, args struct{ Type *string }) ([]*gqlEdge, error) {
+	edges, err := loadersFrom(ctx).edges.Load(ctx, r.n.ID)()
+	if err != nil {
+		return nil, gqlDBError(err)
+	}
+	out := make([]*gqlEdge, 0, len(edges))
+	for i := range edges {
+		if args.Type == nil || edges[i].EdgeType == *args.Type {
+			out = append(out, &gqlEdge{&edges[i]})
+		}
+	}
+	return out, nil
+}
+
+func (r *gqlNode) Candidate(ctx context.Context) (*gqlCandidate, error) {
+	if r.n.NodeType != "person" {
+		return nil, nil
+	}
+	id, err := loadersFrom(ctx).nodeCandidates.Load(ctx, r.n.ID)()
+	if err != nil {
+		return nil, gqlDBError(err)
+	}
+	if id == 0 {
+		return nil, nil
+	}
+	return loadCandidate(ctx, id)
+}
+
+type gqlEdge struct {
+	e *storage.GraphEdge
+}
+
+func (r *gqlEdge) ID() graphql.ID     { return gqlID(int64(r.e.ID)) }
+func (r *gqlEdge) Type() string       { return r.e.EdgeType }
+func (r *gqlEdge) Properties() string { return string(r.e.Properties) }
+
+func (r *gqlEdge) Source(ctx context.Context) (*gqlNode, error) {
+	return loadNode(ctx, r.e.SourceNodeID)
+}
+
+func (r *gqlEdge) Target(ctx context.Context) (*gqlNode, error) {
+	return loadNode(ctx, r.e.TargetNodeID)
+}
+
+type gqlCommunity struct {
+	c *storage.Community
+}
+
+func (r *gqlCommunity) ID() graphql.ID          { return gqlID(int64(r.c.ID)) }
+func (r *gqlCommunity) Level() int32            { return int32(r.c.Level) }
+func (r *gqlCommunity) Key() string             { return r.c.CommunityID }
+func (r *gqlCommunity) Title() *string          { return optString(r.c.Title) }
+func (r *gqlCommunity) Summary() *string        { return optString(r.c.Summary) }
+func (r *gqlCommunity) NodeCount() int32        { return int32(r.c.NodeCount) }
+func (r *gqlCommunity) UpdatedAt() graphql.Time { return graphql.Time{Time: r.c.UpdatedAt} }
+
+func (r *gqlCommunity) Members(ctx context.Context, args struct{ First int32 }) ([]*gqlNode, error) {
+	limit, _ := gqlPage(args.First, 0)
+	ids, err := loadersFrom(ctx).members.Load(ctx, r.c.ID)()
+	if err != nil {
+		return nil, gqlDBError(err)
+	}
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	nodes, errs := loadersFrom(ctx).nodes.LoadMany(ctx, ids)()
+	if err := errors.Join(errs...); err != nil {
+		return nil, gqlDBError(err)
+	}
+	out := make([]*gqlNode, 0, len(nodes))
+	for _, n := range nodes {
+		if n != nil {
+			out = append(out, &gqlNode{n})
+		}
+	}
+	return out, nil
+}
+
+// ─── Search ──────────────────────────────────────────────────────────────────
+
+type gqlSearchResults struct {
+	experiment string
+	warnings   []string
+	cacheHit   bool
+	hits       []*gqlSearchHit
+}
+
+func (r *gqlSearchResults) Experiment() *string   { return optString(r.experiment) }
+func (r *gqlSearchResults) Warnings() []string    { return r.warnings }
+func (r *gqlSearchResults) CacheHit() bool        { return r.cacheHit }
+func (r *gqlSearchResults) Hits() []*gqlSearchHit { return r.hits }
+
+type gqlSearchHit struct {
+	c FusedCandidateResponse
+}
+
+func (r *gqlSearchHit) Rank() int32           { return int32(r.c.Rank) }
+func (r *gqlSearchHit) FusionScore() float64  { return r.c.FusionScore }
+func (r *gqlSearchHit) Bm25Score() float64    { return r.c.BM25Score }
+func (r *gqlSearchHit) VectorScore() float64  { return r.c.VectorScore }
+func (r *gqlSearchHit) GraphScore() float64   { return r.c.GraphScore }
+func (r *gqlSearchHit) LlmScore() float64     { return r.c.LLMScore }
+func (r *gqlSearchHit) LlmReasoning() *string { return optString(r.c.LLMReasoning) }
+
+func (r *gqlSearchHit) Candidate(ctx context.Context) (*gqlCandidate, error) {
+	if r.c.ID == 0 {
+		return nil, nil
+	}
+	return loadCandidate(ctx, r.c.ID)
+}
@@ -1,108 +1,205 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"log"
 	"net/http"
 	"time"
+
+	"cv-search/internal/graphrag"
 )
 
 // GraphRAGSearchRequest represents a natural language search request
 type GraphRAGSearchRequest struct {
-	Query string `json:"query"`
+	Query           string   `json:"query"`
+	IncludeStatuses []string `json:"include_statuses,omitempty"` // Also return candidates of these statuses: hired | archived | do_not_contact
+}
+
+// GraphRAGSearchResponse is the answer of either engine: summary comes from
+// the LLM-only fallback, relevant_communities from the enhanced engine.
+type GraphRAGSearchResponse struct {
+	Query               string                        `json:"query"`
+	Candidates          []graphrag.LLMRankedCandidate `json:"candidates"`
+	Summary             string                        `json:"summary,omitempty"`
+	TotalFound          int                           `json:"total_found"`
+	SearchMethod        string                        `json:"search_method"`
+	RelevantCommunities []graphrag.CommunityInsight   `json:"relevant_communities,omitempty"`
+	Reasoning           string                        `json:"reasoning"`
+	ProcessingTime      string                        `json:"processing_time"`
+	VectorSearchUsed    bool                          `json:"vector_search_used"`
+}
+
+// GraphRAGSummaryEvent is the data of the "summary" event of a streamed
+// GraphRAG search.
+type GraphRAGSummaryEvent struct {
+	Summary   string `json:"summary"`    // written by the LLM about the best-ranked candidates
+	ElapsedMS int64  `json:"elapsed_ms"` // since the search started
 }
 
 // GraphRAGSearchHandler handles natural language candidate search with GraphRAG
-// @Summary GraphRAG Search (Vector + Community + LLM)
-// @Description Search candidates using natural language with Vector embeddings, Community detection, and LLM reasoning (Microsoft GraphRAG style)
-// @Tags graphrag
-// @Accept json
-// @Produce json
-// @Param request body GraphRAGSearchRequest true "Natural language search query"
-// @Success 200 {object} map[string]interface{}
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
-// @Router /graphrag/search [post]
 func (a *API) GraphRAGSearchHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	// Check if any search engine is available
-	if a.enhancedSearchEngine == nil && a.llmSearchEngine == nil {
-		http.Error(w, "GraphRAG search not available (LLM not configured)", http.StatusServiceUnavailable)
+	ai, req, ok := a.parseGraphRAGSearch(w, r)
+	if !ok {
+		return
+	}
+	response, err := a.runGraphRAGSearch(graphrag.WithIncludedStatuses(r.Context(), req.IncludeStatuses), ai, req.Query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	writeGraphRAGSearchResponse(w, response)
+}
 
+// GraphRAGSearchStreamHandler runs a GraphRAG search like
+// GraphRAGSearchHandler but answers with Server-Sent Events, so the ranked
+// candidates are shown while the LLM still writes their summary:
+//
+//	event: result     GraphRAGSearchResponse, as soon as ranking is done
+//	event: summary    GraphRAGSummaryEvent, last event on success
+//	event: error      ErrorResponse, last event if the search failed
+//
+// If the summary can't be written the stream ends after result, whose
+// summary field (LLM-only engine) is the counted one the plain endpoint
+// returns. Invalid requests get a plain JSON error before the stream starts.
+//
+// POST /api/graphrag/search/stream
+func (a *API) GraphRAGSearchStreamHandler(w http.ResponseWriter, r *http.Request) {
+	ai, req, ok := a.parseGraphRAGSearch(w, r)
+	if !ok {
+		return
+	}
+
+	stream := newEventStream(w)
+	defer stream.close()
+
+	startTime := time.Now()
+	response, err := a.runGraphRAGSearch(graphrag.WithIncludedStatuses(r.Context(), req.IncludeStatuses), ai, req.Query)
+	if err != nil {
+		stream.send("error", ErrorResponse{Error: err.Error(), Status: http.StatusInternalServerError})
+		return
+	}
+	stream.send("result", response)
+
+	var summary string
+	if ai.enhancedSearchEngine != nil {
+		summary, err = ai.enhancedSearchEngine.Summarize(r.Context(), req.Query, response.Candidates, response.Reasoning)
+	} else {
+		summary, err = ai.llmSearchEngine.Summarize(r.Context(), req.Query, response.Candidates, response.Reasoning)
+	}
+	if err != nil {
+		log.Printf("[GraphRAG Search API] Summary failed: %v", err)
+		return
+	}
+	stream.send("summary", GraphRAGSummaryEvent{Summary: summary, ElapsedMS: time.Since(startTime).Milliseconds()})
+}
+
+// parseGraphRAGSearch decodes and validates a GraphRAG search request and
+// answers it with an error if it is invalid or no engine is configured.
+func (a *API) parseGraphRAGSearch(w http.ResponseWriter, r *http.Request) (*aiServices, GraphRAGSearchRequest, bool) {
 	var req GraphRAGSearchRequest
+
+	// Check if any search engine is available
+	ai := a.ai(r.Context())
+	if ai.enhancedSearchEngine == nil && ai.llmSearchEngine == nil {
+		http.Error(w, "GraphRAG search not available (LLM not configured)", http.StatusServiceUnavailable)
+		return nil, req, false
+	}
+
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
-		return
+		return nil, req, false
 	}
 
 	if req.Query == "" {
 		http.Error(w, "Query cannot be empty", http.StatusBadRequest)
-		return
+		return nil, req, false
 	}
+	statuses, errMsg := parseIncludeStatuses(req.IncludeStatuses)
+	if errMsg != "" {
+		http.Error(w, errMsg, http.StatusBadRequest)
+		return nil, req, false
+	}
+	req.IncludeStatuses = statuses
+	return ai, req, true
+}
 
+// runGraphRAGSearch searches with the EnhancedSearchEngine if available
+// (Vector + Community + LLM), otherwise with the LLM-only engine.
+func (a *API) runGraphRAGSearch(ctx context.Context, ai *aiServices, query string) (*GraphRAGSearchResponse, error) {
 	startTime := time.Now()
 
-	// Use EnhancedSearchEngine if available (Vector + Community + LLM)
-	// Otherwise fall back to LLM-only search
-	if a.enhancedSearchEngine != nil {
-		log.Printf("[Enhanced Search API] Using Vector + Community + LLM search for: %s", req.Query)
+	if ai.enhancedSearchEngine != nil {
+		log.Printf("[Enhanced Search API] Using Vector + Community + LLM search for: %s", query)
 
-		enhancedResult, err := a.enhancedSearchEngine.Search(r.Context(), req.Query)
+		enhancedResult, err := ai.enhancedSearchEngine.Search(ctx, query)
 		if err != nil {
 			log.Printf("[Enhanced Search API] Search failed: %v", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
+			return nil, err
 		}
 
+		ai.addSnippets(ctx, query, enhancedResult.Candidates)
 		processingTime := time.Since(startTime)
 		log.Printf("[Enhanced Search API] Search completed in %v: %d candidates found", processingTime, len(enhancedResult.Candidates))
 
-		response := map[string]interface{}{
-			"query":                req.Query,
-			"candidates":           enhancedResult.Candidates,
-			"total_found":          len(enhancedResult.Candidates),
-			"search_method":        enhancedResult.SearchMethod,
-			"relevant_communities": enhancedResult.RelevantCommunities,
-			"reasoning":            enhancedResult.Reasoning,
-			"processing_time":      processingTime.String(),
-			"vector_search_used":   enhancedResult.SearchMethod == "vector+community+llm" || enhancedResult.SearchMethod == "vector+llm",
-		}
-
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(response)
-		return
+		return &GraphRAGSearchResponse{
+			Query:               query,
+			Candidates:          enhancedResult.Candidates,
+			TotalFound:          len(enhancedResult.Candidates),
+			SearchMethod:        enhancedResult.SearchMethod,
+			RelevantCommunities: enhancedResult.RelevantCommunities,
+			Reasoning:           enhancedResult.Reasoning,
+			ProcessingTime:      processingTime.String(),
+			VectorSearchUsed:    enhancedResult.SearchMethod == "vector+community+llm" || enhancedResult.SearchMethod == "vector+llm",
+		}, nil
 	}
 
 	// Fallback to LLM-only search
-	log.Printf("[LLM Search API] Using LLM-only search for: %s", req.Query)
+	log.Printf("[LLM Search API] Using LLM-only search for: %s", query)
 
-	result, err := a.llmSearchEngine.Search(r.Context(), req.Query)
+	result, err := ai.llmSearchEngine.Search(ctx, query)
 	if err != nil {
 		log.Printf("[LLM Search API] Search failed: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return nil, err
 	}
 
+	ai.addSnippets(ctx, query, result.Candidates)
 	processingTime := time.Since(startTime)
 	log.Printf("[LLM Search API] Search completed in %v: %d candidates found", processingTime, result.TotalFound)
 
-	response := map[string]interface{}{
-		"query":              req.Query,
-		"candidates":         result.Candidates,
-		"summary":            result.Summary,
-		"total_found":        result.TotalFound,
-		"reasoning":          result.Reasoning,
-		"processing_time":    processingTime.String(),
-		"search_method":      "llm-only",
-		"vector_search_used": false,
+	return &GraphRAGSearchResponse{
+		Query:          query,
+		Candidates:     result.Candidates,
+		Summary:        result.Summary,
+		TotalFound:     result.TotalFound,
+		Reasoning:      result.Reasoning,
+		ProcessingTime: processingTime.String(),
+		SearchMethod:   "llm-only",
+	}, nil
+}
+
+// addSnippets sets where query matched in each candidate's CV.
+func (ai *aiServices) addSnippets(ctx context.Context, query string, candidates []graphrag.LLMRankedCandidate) {
+	personIDs := make([]string, len(candidates))
+	for i, c := range candidates {
+		personIDs[i] = c.PersonID
 	}
+	snippets := ai.searchSnippets(ctx, query, personIDs)
+	for i := range candidates {
+		candidates[i].Snippet = snippets[candidates[i].PersonID]
+	}
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+// writeGraphRAGSearchResponse streams response's candidates (see
+// writeJSONList).
+func writeGraphRAGSearchResponse(w http.ResponseWriter, response *GraphRAGSearchResponse) {
+	writeJSONList(w, struct {
+		*GraphRAGSearchResponse
+		Candidates json.RawMessage `json:"candidates,omitempty"`
+	}{GraphRAGSearchResponse: response}, "candidates", response.Candidates)
 }
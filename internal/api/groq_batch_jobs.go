@@ -0,0 +1,198 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"cv-search/internal/cv"
+	"cv-search/internal/llm"
+	"cv-search/internal/storage"
+	"cv-search/internal/tenant"
+)
+
+const groqBatchPollInterval = 2 * time.Minute
+
+// batchOrgContext scopes ctx to the organization of a Groq batch's first CV,
+// which its LLM tokens are recorded for. Batches rarely mix organizations
+// (only resume fetches do).
+func (a *API) batchOrgContext(ctx context.Context, jobsByCVFileID map[int64]int64) context.Context {
+	first := int64(0)
+	for cvFileID := range jobsByCVFileID {
+		if first == 0 || cvFileID < first {
+			first = cvFileID
+		}
+	}
+	if first == 0 {
+		return ctx
+	}
+	orgID, err := a.db.CVFileOrgID(ctx, first)
+	if err != nil || orgID == 0 {
+		return ctx
+	}
+	return tenant.WithOrg(ctx, orgID)
+}
+
+// SubmitCVExtractionBatch submits a set of CV extraction jobs as a single Groq
+// Batch API job instead of queuing them individually into the real-time
+// worker. Used for bulk uploads above MaxRealtimeCVCount — trades a few
+// minutes-to-hours of latency for immunity to the standard per-model rate
+// limit (Batch API is a separate quota) at half the cost. Returns the Groq
+// batch ID for tracking, "" if every CV was too long for a batch line and
+// went to the real-time queue. The batch runs on the deployment's LLM
+// service, as does pollGroqBatch.
+func (a *API) SubmitCVExtractionBatch(ctx context.Context, jobs []CVProcessingJob) (string, error) {
+	if a.llmService == nil {
+		return "", fmt.Errorf("LLM service not available")
+	}
+	if len(jobs) == 0 {
+		return "", fmt.Errorf("no jobs to submit")
+	}
+
+	items := make(map[string]string, len(jobs))
+	jobIDs := make([]int64, 0, len(jobs))
+	for _, j := range jobs {
+		jctx := tenant.WithOrg(ctx, j.OrgID)
+		// A batch line is one prompt; CVs that need several go through the
+		// real-time worker's chunked extraction instead.
+		if cv.ChunksTokens(a.cvChunks(jctx, j.CVFileID, j.CVText)) > cv.ExtractionPromptTokens {
+			a.queueCVProcessingJob(jctx, j.JobID, j.CVFileID, j.CVText)
+			continue
+		}
+		// Heuristic sections only: an LLM fallback here would spend the
+		// real-time quota the Batch API is meant to spare.
+		items[fmt.Sprintf("%d", j.CVFileID)] = a.sectionedCVText(jctx, j.CVFileID, j.CVText, false)
+		jobIDs = append(jobIDs, j.JobID)
+	}
+
+	if len(items) == 0 {
+		return "", nil
+	}
+
+	groqBatchID, inputFileID, err := a.llmService.SubmitExtractionBatch(items, "24h")
+	if err != nil {
+		return "", fmt.Errorf("failed to submit Groq batch: %w", err)
+	}
+
+	if dbErr := a.db.WithTx(ctx, func(tx *storage.DB) error {
+		if _, err := tx.CreateGroqBatchJob(ctx, groqBatchID, inputFileID, len(items)); err != nil {
+			return fmt.Errorf("record batch job: %w", err)
+		}
+		if err := tx.LinkJobsToGroqBatch(ctx, groqBatchID, jobIDs); err != nil {
+			return fmt.Errorf("link jobs: %w", err)
+		}
+		return nil
+	}); dbErr != nil {
+		log.Printf("[GroqBatch] Warning: failed to track batch %s: %v", groqBatchID, dbErr)
+	}
+
+	log.Printf("[GroqBatch] Submitted batch %s with %d CVs (input_file=%s)", groqBatchID, len(items), inputFileID)
+	return groqBatchID, nil
+}
+
+// groqBatchPollWorker periodically checks in-flight Groq Batch API jobs and,
+// once a batch completes, applies its results through the same downstream
+// pipeline as the real-time worker (applyExtraction). Self-healing: any CV
+// missing or failed within the batch falls back to the normal real-time queue
+// instead of getting stuck.
+func (a *API) groqBatchPollWorker() {
+	log.Println("[GroqBatchPoller] Started")
+	ticker := time.NewTicker(groqBatchPollInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx := context.Background()
+		batches, err := a.db.ListOpenGroqBatchJobs(ctx)
+		if err != nil {
+			log.Printf("[GroqBatchPoller] Failed to list open batches: %v", err)
+			continue
+		}
+		for _, b := range batches {
+			a.pollGroqBatch(ctx, b.GroqBatchID)
+		}
+	}
+}
+
+// pollGroqBatch checks and, if complete, applies the results of a single
+// Groq batch job.
+func (a *API) pollGroqBatch(ctx context.Context, groqBatchID string) {
+	status, err := a.llmService.GetGroqBatchStatus(groqBatchID)
+	if err != nil {
+		log.Printf("[GroqBatchPoller] Failed to get status for batch %s: %v", groqBatchID, err)
+		return
+	}
+
+	var outputFileID, errorFileID *string
+	if status.OutputFileID != "" {
+		outputFileID = &status.OutputFileID
+	}
+	if status.ErrorFileID != "" {
+		errorFileID = &status.ErrorFileID
+	}
+	if dbErr := a.db.UpdateGroqBatchJobStatus(ctx, groqBatchID, status.Status, outputFileID, errorFileID); dbErr != nil {
+		log.Printf("[GroqBatchPoller] Failed to update batch %s status: %v", groqBatchID, dbErr)
+	}
+
+	log.Printf("[GroqBatchPoller] Batch %s status=%s (%d/%d completed)",
+		groqBatchID, status.Status, status.RequestCounts.Completed, status.RequestCounts.Total)
+
+	terminal := status.Status == "completed" || status.Status == "failed" ||
+		status.Status == "expired" || status.Status == "cancelled"
+	if !terminal {
+		return // still in progress, check again next tick
+	}
+
+	jobsByCVFileID, err := a.db.GetJobsByGroqBatchID(ctx, groqBatchID)
+	if err != nil {
+		log.Printf("[GroqBatchPoller] Failed to load jobs for batch %s: %v", groqBatchID, err)
+		return
+	}
+
+	var results map[string]*llm.CVExtraction
+	var lineErrors map[string]string
+	if status.OutputFileID != "" {
+		results, lineErrors, err = a.llmService.FetchExtractionBatchResults(a.batchOrgContext(ctx, jobsByCVFileID), status.OutputFileID)
+		if err != nil {
+			log.Printf("[GroqBatchPoller] Failed to fetch results for batch %s: %v", groqBatchID, err)
+		}
+	}
+
+	for cvFileID, jobID := range jobsByCVFileID {
+		customID := fmt.Sprintf("%d", cvFileID)
+
+		// A batch can mix organizations (resume fetches); each CV is
+		// applied for its own.
+		orgID, err := a.db.CVFileOrgID(ctx, cvFileID)
+		if err != nil || orgID == 0 {
+			log.Printf("[GroqBatchPoller] Job %d: CV %d not found (%v), skipping", jobID, cvFileID, err)
+			errMsg := "CV file no longer exists"
+			a.db.UpdateJobStatus(ctx, jobID, "failed", &errMsg)
+			continue
+		}
+		ctx := tenant.WithOrg(ctx, orgID)
+
+		if extraction, ok := results[customID]; ok {
+			log.Printf("[GroqBatchPoller] Applying batch result for job %d (CV %d)", jobID, cvFileID)
+			a.applyExtraction(ctx, jobID, cvFileID, extraction)
+			continue
+		}
+
+		// Missing or errored in the batch — self-heal via the real-time queue
+		// instead of leaving the job stuck in "batch_submitted".
+		if msg, ok := lineErrors[customID]; ok {
+			log.Printf("[GroqBatchPoller] Batch line failed for job %d (CV %d): %s — falling back to real-time queue", jobID, cvFileID, msg)
+		} else {
+			log.Printf("[GroqBatchPoller] No result for job %d (CV %d) in batch %s — falling back to real-time queue", jobID, cvFileID, groqBatchID)
+		}
+
+		texts, textErr := a.db.GetCVTextsByFileIDs(ctx, []int64{cvFileID})
+		if textErr != nil || texts[cvFileID] == "" {
+			errMsg := "batch extraction failed and CV text unavailable for fallback"
+			a.db.UpdateJobStatus(ctx, jobID, "failed", &errMsg)
+			continue
+		}
+		a.db.UpdateJobStatus(ctx, jobID, "pending", nil)
+		a.queueCVProcessingJob(ctx, jobID, cvFileID, texts[cvFileID])
+	}
+}
@@ -0,0 +1,369 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"cv-search/internal/cv"
+	"cv-search/internal/storage"
+	"cv-search/internal/tenant"
+	"cv-search/pkg/cvsearchpb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// grpcServer serves the cvsearchpb.CVSearch service on top of the same
+// storage, parsing and search code as the HTTP handlers.
+type grpcServer struct {
+	cvsearchpb.UnimplementedCVSearchServer
+	a *API
+}
+
+// grpcCallerKey carries who made a gRPC call, for the audit log.
+type grpcCallerKey struct{}
+
+type grpcCaller struct {
+	actor string
+	ip    string
+}
+
+// NewGRPCServer returns a gRPC server with the CVSearch service registered.
+// Every call is scoped to the organization of its API key, as orgMiddleware
+// does for HTTP. Messages may be as large as an uploaded CV (MAX_FILE_SIZE_MB)
+// plus some room for the other fields.
+func (a *API) NewGRPCServer() *grpc.Server {
+	s := grpc.NewServer(
+		grpc.MaxRecvMsgSize((a.cfg.MaxFileSizeMB<<20)+(1<<20)),
+		grpc.UnaryInterceptor(a.grpcOrgInterceptor),
+	)
+	cvsearchpb.RegisterCVSearchServer(s, &grpcServer{a: a})
+	return s
+}
+
+// grpcOrgInterceptor resolves the call's organization from its x-api-key (or
+// "authorization: Bearer") metadata and records its caller from x-user-id.
+func (a *API) grpcOrgInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if v := md.Get(key); len(v) > 0 {
+			return strings.TrimSpace(v[0])
+		}
+		return ""
+	}
+	key := first("x-api-key")
+	if key == "" {
+		if auth := first("authorization"); len(auth) > 7 && strings.EqualFold(auth[:7], "bearer ") {
+			key = strings.TrimSpace(auth[7:])
+		}
+	}
+
+	orgID := 0
+	if key != "" {
+		var err error
+		orgID, err = a.db.OrgIDByKeyHash(ctx, hashAPIKey(key))
+		if err != nil {
+			log.Printf("[gRPC] %v", err)
+			return nil, status.Error(codes.Internal, "database error")
+		}
+		if orgID == 0 {
+			return nil, status.Error(codes.Unauthenticated, "unknown organization API key")
+		}
+	}
+	if orgID == 0 {
+		if a.cfg.RequireOrgKey {
+			return nil, status.Error(codes.Unauthenticated, "a valid organization API key is required")
+		}
+		orgID = tenant.DefaultOrgID
+	}
+
+	caller := grpcCaller{actor: "anonymous"}
+	if uid := first("x-user-id"); uid != "" {
+		caller.actor = "user:" + uid
+	} else if key != "" {
+		caller.actor = "key:" + hashAPIKey(key)[:12]
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		caller.ip = p.Addr.String()
+		if host, _, err := net.SplitHostPort(caller.ip); err == nil {
+			caller.ip = host
+		}
+	}
+	ctx = context.WithValue(tenant.WithOrg(ctx, orgID), grpcCallerKey{}, caller)
+	return handler(ctx, req)
+}
+
+// audit records a mutation made by a gRPC call, like API.audit.
+func (s *grpcServer) audit(ctx context.Context, action, entityType, entityID string, details interface{}) {
+	caller, _ := ctx.Value(grpcCallerKey{}).(grpcCaller)
+	s.a.logAudit(ctx, storage.AuditEntry{
+		Actor:      caller.actor,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		IP:         caller.ip,
+	}, details)
+}
+
+// admitQuota is API.admitQuota for gRPC: an over-quota call fails with
+// ResourceExhausted.
+func (s *grpcServer) admitQuota(ctx context.Context, quota string, n int) error {
+	denial, _, err := s.a.checkQuota(ctx, quota, n)
+	if err != nil {
+		log.Printf("[gRPC] %v", err)
+		return nil
+	}
+	if denial != nil {
+		return status.Errorf(codes.ResourceExhausted, "%v, retry after %ds", denial, denial.retryAfter())
+	}
+	return nil
+}
+
+// ─── CV upload and jobs ──────────────────────────────────────────────────────
+
+func (s *grpcServer) UploadCV(ctx context.Context, req *cvsearchpb.UploadCVRequest) (*cvsearchpb.UploadCVResponse, error) {
+	a := s.a
+	if c := a.cvQueueCapacity(); !c.admits(1) {
+		a.cvQueueStats.reject(1)
+		return nil, status.Errorf(codes.ResourceExhausted, "processing queue is busy (%d/%d), retry after %s", c.Length, c.Capacity, a.cfg.CVQueueRetryAfter)
+	}
+	if err := s.admitQuota(ctx, quotaUploads, 1); err != nil {
+		return nil, err
+	}
+	if len(req.Content) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no file content")
+	}
+	if len(req.Content) > a.cfg.MaxFileSizeMB<<20 {
+		return nil, status.Errorf(codes.InvalidArgument, "file too large (max %d MB)", a.cfg.MaxFileSizeMB)
+	}
+	filename, err := checkCVUpload(req.Filename, "")
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	parsedCV, err := a.cvParser.ParseReader(filename, bytes.NewReader(req.Content))
+	if errors.Is(err, cv.ErrRejectedFile) {
+		s.audit(ctx, "reject", "cv_file", "", map[string]interface{}{"filename": filename, "reason": err.Error()})
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to parse CV: %v", err)
+	}
+
+	hash := sha256.Sum256([]byte(parsedCV.FullText))
+	contentHash := hex.EncodeToString(hash[:])
+	existingCV, err := a.db.FindCVByHash(ctx, contentHash)
+	if err != nil {
+		log.Printf("[gRPC] duplicate check: %v", err)
+	} else if existingCV != nil {
+		return &cvsearchpb.UploadCVResponse{
+			CvId:             existingCV.ID,
+			Filename:         existingCV.Filename,
+			FileType:         existingCV.FileType,
+			FileSize:         existingCV.FileSize,
+			OcrUsed:          existingCV.OCRUsed,
+			Anonymized:       existingCV.Anonymized,
+			Duplicate:        true,
+			OriginalUploadAt: timestamppb.New(existingCV.UploadedAt),
+		}, nil
+	}
+
+	blobKey, err := a.storeCVBlob(ctx, bytes.NewReader(req.Content), int64(len(req.Content)), filename)
+	if err != nil {
+		log.Printf("[gRPC] store CV file: %v", err)
+		return nil, status.Error(codes.Internal, "failed to store CV file")
+	}
+	if a.cfg.AnonymizePII || req.Anonymize {
+		parsedCV.Anonymize()
+	}
+	cvID, jobID, err := a.saveParsedCV(ctx, uploadOptions{}, parsedCV, blobKey, contentHash)
+	if err != nil {
+		log.Printf("[gRPC] save CV / create job: %v", err)
+		return nil, status.Error(codes.Internal, "failed to save CV")
+	}
+	a.countUsage(ctx, 1, 0)
+	s.audit(ctx, "upload", "cv_file", strconv.Itoa(cvID), map[string]interface{}{
+		"filename": parsedCV.Filename, "file_size": parsedCV.FileSize, "job_id": jobID,
+	})
+	if !a.queueCVProcessingJob(ctx, jobID, int64(cvID), parsedCV.FullText) {
+		return nil, status.Errorf(codes.ResourceExhausted, "processing queue is full, retry after %s", a.cfg.CVQueueRetryAfter)
+	}
+	log.Printf("[gRPC] CV %d uploaded (%s), job %d queued", cvID, parsedCV.Filename, jobID)
+
+	return &cvsearchpb.UploadCVResponse{
+		CvId:       int64(cvID),
+		JobId:      jobID,
+		Filename:   parsedCV.Filename,
+		FileType:   parsedCV.FileType,
+		FileSize:   parsedCV.FileSize,
+		TextLength: int64(len(parsedCV.FullText)),
+		OcrUsed:    parsedCV.OCRUsed,
+		Anonymized: parsedCV.Anonymized,
+	}, nil
+}
+
+func (s *grpcServer) GetJob(ctx context.Context, req *cvsearchpb.GetJobRequest) (*cvsearchpb.Job, error) {
+	job, err := s.a.db.GetJobByID(ctx, req.JobId)
+	if err != nil {
+		log.Printf("[gRPC] get job %d: %v", req.JobId, err)
+		return nil, status.Error(codes.Internal, "database error")
+	}
+	if job == nil {
+		return nil, status.Error(codes.NotFound, "job not found")
+	}
+	res := &cvsearchpb.Job{
+		Id:          job.ID,
+		CvFileId:    job.CVFileID,
+		Status:      job.Status,
+		CreatedAt:   timestamppb.New(job.CreatedAt),
+		StartedAt:   optionalTimestamp(job.StartedAt),
+		CompletedAt: optionalTimestamp(job.CompletedAt),
+		RetryCount:  int32(job.RetryCount),
+		MaxRetries:  int32(job.MaxRetries),
+	}
+	if job.ErrorMessage != nil {
+		res.Error = *job.ErrorMessage
+	}
+	return res, nil
+}
+
+// ─── Search and candidates ───────────────────────────────────────────────────
+
+func (s *grpcServer) HybridSearch(ctx context.Context, req *cvsearchpb.HybridSearchRequest) (*cvsearchpb.HybridSearchResponse, error) {
+	a := s.a
+	ai := a.ai(ctx)
+	if ai.hybridSearchEngine == nil {
+		return nil, status.Error(codes.Unavailable, "hybrid search not available (OpenAI API key required)")
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		return nil, status.Error(codes.InvalidArgument, "query cannot be empty")
+	}
+	if err := s.admitQuota(ctx, quotaSearches, 1); err != nil {
+		return nil, err
+	}
+
+	httpReq := HybridSearchRequest{
+		Query:        req.Query,
+		BM25Weight:   req.Bm25Weight,
+		VectorWeight: req.VectorWeight,
+		GraphWeight:  req.GraphWeight,
+		TopK:         int(req.TopK),
+		FinalTopN:    int(req.FinalTopN),
+		Experiment:   req.Experiment,
+		Diversity:    req.Diversity,
+		Tags:         req.Tags,
+		ExcludeTags:  req.ExcludeTags,
+		TagBoosts:    req.TagBoosts,
+	}
+	config, errMsg := a.resolveHybridConfig(ctx, &httpReq)
+	if errMsg != "" {
+		return nil, status.Error(codes.InvalidArgument, errMsg)
+	}
+	if total := config.BM25Weight + config.VectorWeight + config.GraphWeight; total < 0.9 || total > 1.1 {
+		return nil, status.Error(codes.InvalidArgument, "weights must sum to 1.0")
+	}
+
+	start := time.Now()
+	results, diag, err := ai.hybridSearchEngine.SearchWithDiagnostics(ctx, req.Query, config)
+	if err != nil {
+		log.Printf("[gRPC] hybrid search failed: %v", err)
+		return nil, status.Errorf(codes.Internal, "search failed: %v", err)
+	}
+	elapsed := time.Since(start)
+	a.countUsage(ctx, 0, 1)
+	a.logExperimentRun(ctx, req.Query, config, results, elapsed)
+
+	res := &cvsearchpb.HybridSearchResponse{
+		Experiment:       config.Experiment,
+		ProcessingTimeMs: elapsed.Milliseconds(),
+	}
+	if diag != nil {
+		res.Warnings = diag.Warnings
+		res.CacheHit = diag.SemanticCacheHit
+	}
+	for _, c := range toFusedCandidateResponses(results) {
+		r := &cvsearchpb.SearchResult{
+			Id:                   int64(c.ID),
+			PersonId:             c.PersonID,
+			Name:                 c.Name,
+			CurrentPosition:      c.CurrentPosition,
+			Seniority:            c.Seniority,
+			TotalExperienceYears: int32(c.TotalExperienceYears),
+			Tags:                 c.Tags,
+			Bm25Score:            c.BM25Score,
+			VectorScore:          c.VectorScore,
+			GraphScore:           c.GraphScore,
+			FusionScore:          c.FusionScore,
+			LlmScore:             c.LLMScore,
+			LlmReasoning:         c.LLMReasoning,
+			Rank:                 int32(c.Rank),
+		}
+		for _, sk := range c.Skills {
+			r.Skills = append(r.Skills, &cvsearchpb.Skill{Name: sk.Name, Proficiency: sk.Proficiency, Years: float64(sk.YearsOfExperience)})
+		}
+		res.Candidates = append(res.Candidates, r)
+	}
+	return res, nil
+}
+
+func (s *grpcServer) GetCandidate(ctx context.Context, req *cvsearchpb.GetCandidateRequest) (*cvsearchpb.Candidate, error) {
+	c, err := s.a.db.GetCandidateDetail(ctx, int(req.Id))
+	if err != nil {
+		log.Printf("[gRPC] get candidate %d: %v", req.Id, err)
+		return nil, status.Error(codes.Internal, "database error")
+	}
+	if c == nil {
+		return nil, status.Error(codes.NotFound, "candidate not found")
+	}
+	res := &cvsearchpb.Candidate{
+		Id:              int64(c.ID),
+		Name:            c.Name,
+		Email:           c.Email,
+		Phone:           c.Phone,
+		LinkedinUrl:     c.LinkedInURL,
+		Location:        c.Location,
+		CurrentPosition: c.CurrentPosition,
+		Seniority:       c.Seniority,
+		Tags:            c.Tags,
+		CreatedAt:       timestamppb.New(c.CreatedAt),
+	}
+	for _, sk := range c.Skills {
+		pb := &cvsearchpb.Skill{Name: sk.Name, Proficiency: sk.Proficiency}
+		if sk.Years != nil {
+			pb.Years = *sk.Years
+		}
+		res.Skills = append(res.Skills, pb)
+	}
+	for _, iv := range c.Interviews {
+		res.Interviews = append(res.Interviews, &cvsearchpb.Interview{
+			Id:              int64(iv.ID),
+			InterviewDate:   timestamppb.New(iv.InterviewDate),
+			Team:            iv.Team,
+			InterviewerName: iv.InterviewerName,
+			InterviewType:   iv.InterviewType,
+			Notes:           iv.Notes,
+			Outcome:         iv.Outcome,
+		})
+	}
+	return res, nil
+}
+
+// optionalTimestamp converts a nullable time, leaving nil unset.
+func optionalTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
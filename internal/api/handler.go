@@ -1,16 +1,22 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"log"
 	"net/http"
 	"sync"
 	"time"
 
+	"cv-search/internal/cache"
 	"cv-search/internal/config"
 	"cv-search/internal/cv"
 	"cv-search/internal/graphrag"
-	"cv-search/internal/llm"
+	"cv-search/internal/notify"
+	"cv-search/internal/secret"
 	"cv-search/internal/storage"
+
+	graphql "github.com/graph-gophers/graphql-go"
 )
 
 // BatchJob holds per-file info within a batch upload.
@@ -70,17 +76,30 @@ func (bs *BatchStore) cleanup() {
 }
 
 type API struct {
-	db                   *storage.DB
-	cfg                  *config.Config
-	cvParser             *cv.CVParser
-	llmService           *llm.Service
-	graphBuilder         *graphrag.GraphBuilder
-	llmSearchEngine      *graphrag.LLMSearchEngine      // LLM-only semantic search
-	enhancedSearchEngine *graphrag.EnhancedSearchEngine // Vector + Community + LLM search (Microsoft GraphRAG)
-	hybridSearchEngine   *graphrag.HybridSearchEngine   // BM25 + Vector + Graph + LLM reranking
-	cvProcessingQueue    chan CVProcessingJob           // Background queue for async CV processing (LLM + Graph)
-	embeddingQueue       chan EmbeddingJob              // Background queue for async embedding generation
-	batchStore           *BatchStore                    // In-memory store for bulk upload batches
+	db                  *storage.DB
+	cfg                 *config.Config
+	cvParser            *cv.CVParser
+	blobs               storage.BlobStore // where uploaded CV files live (cv_files.file_path = key)
+	graphBuilder        *graphrag.GraphBuilder
+	cvProcessingQueue   chan CVProcessingJob // Background queue for async CV processing (LLM + Graph)
+	embeddingQueue      chan EmbeddingJob    // Background queue for async embedding generation
+	personQueue         chan EmbeddingJob    // New person nodes, embedded ahead of embeddingQueue's jobs
+	cvQueueStats        *queueStats          // /metrics + /api/admin/queues numbers for cvProcessingQueue
+	embeddingQueueStats *queueStats          // ... and for embeddingQueue
+	personQueueStats    *queueStats          // ... and for personQueue
+	batchStore          *BatchStore          // In-memory store for bulk upload batches
+	mailer              notify.Mailer        // batch reports and digests; nil = notifications off
+	graphqlSchema       *graphql.Schema      // POST /api/graphql (schema.graphql)
+	responses           cache.Store          // cached responses of expensive reads (response_cache.go); nil = off
+
+	// The deployment's LLM and embedding services. Per-request and per-job
+	// code goes through ai(ctx), which returns an organization's own when it
+	// has configured them (orgAI caches those; secrets decrypts their keys,
+	// nil without SETTINGS_ENCRYPTION_KEY).
+	*aiServices
+	orgAIMu sync.Mutex
+	orgAI   map[int]orgAIEntry
+	secrets *secret.Box
 
 	// Community detection debounce — prevents redundant full recomputes when
 	// multiple CVs are uploaded in quick succession.
@@ -99,66 +118,147 @@ type API struct {
 // drops — with headroom on top for retried jobs — even when the Groq Batch
 // API is unavailable and everything falls back to this queue. Floors at 50
 // (the original fixed size) so small configs still get a reasonable buffer.
-func cvQueueBufferSize(maxBulkFileCount int) int {
-	size := maxBulkFileCount * 2
+// CV_QUEUE_SIZE overrides it.
+func cvQueueBufferSize(cfg *config.Config) int {
+	if cfg.CVQueueSize > 0 {
+		return cfg.CVQueueSize
+	}
+	size := cfg.MaxBulkFileCount * 2
 	if size < 50 {
 		size = 50
 	}
 	return size
 }
 
-func NewAPI(db *storage.DB, cfg *config.Config) *API {
-	// Initialize CV parser
-	uploadsDir := cfg.UploadsDir
-	if uploadsDir == "" {
-		uploadsDir = "./uploads"
+// warnOfflineDrift logs when cmd/tools/offline-setup marked the deployment
+// offline-capable but the config would now call a hosted provider or use
+// other models than the ones it verified.
+func warnOfflineDrift(db *storage.DB, cfg *config.Config) {
+	var offline storage.OfflineDeployment
+	ok, err := db.GetDeploymentSetting(context.Background(), storage.DeploymentSettingOffline, &offline)
+	if err != nil {
+		log.Printf("[API] %v", err)
+		return
 	}
-	cvParser := cv.NewCVParser(uploadsDir)
-
-	// Initialize LLM service (if configured)
-	var llmSvc *llm.Service
+	if !ok {
+		return
+	}
+	switch {
+	case cfg.LLMProvider != "ollama" || cfg.EmbeddingProvider != "ollama":
+		log.Printf("[API] Warning: deployment was set up offline, but LLM_PROVIDER=%s and EMBEDDING_PROVIDER=%s need internet access", cfg.LLMProvider, cfg.EmbeddingProvider)
+	case cfg.LLMModel != offline.LLMModel || cfg.EmbeddingModel != offline.EmbeddingModel:
+		log.Printf("[API] Warning: deployment was set up offline with %s / %s, but configured with %s / %s; rerun cmd/tools/offline-setup",
+			offline.LLMModel, offline.EmbeddingModel, cfg.LLMModel, cfg.EmbeddingModel)
+	}
+}
 
-	if cfg.LLMProvider != "" && cfg.LLMProvider != "none" && cfg.LLMAPIKey != "" {
-		llmSvc = llm.NewService(cfg.LLMProvider, cfg.LLMAPIKey, cfg.LLMModel)
+func NewAPI(db *storage.DB, cfg *config.Config) *API {
+	// Initialize CV parser (temp files only) and the store uploads are kept in
+	cvParser := cv.NewCVParser("")
+	if ocr, err := cv.NewOCR(cv.OCRConfig{
+		Backend:    cfg.OCRBackend,
+		Languages:  cfg.OCRLanguages,
+		ServiceURL: cfg.OCRServiceURL,
+	}); err != nil {
+		log.Printf("[API] %v — scanned CVs will not be OCR'd", err)
+	} else if ocr != nil {
+		cvParser.EnableOCR(ocr, cfg.OCRLanguages, cfg.OCRMinTextChars, cfg.OCRTimeout)
+		log.Printf("[API] OCR fallback enabled (%s, languages %v, below %d chars)", cfg.OCRBackend, cfg.OCRLanguages, cfg.OCRMinTextChars)
+	}
+	scanner, err := cv.NewScanner(cv.ScannerConfig{
+		Backend:    cfg.ScanBackend,
+		Address:    cfg.ClamAVAddress,
+		ServiceURL: cfg.ScanServiceURL,
+	})
+	if err != nil {
+		// Accepting uploads unscanned when a scan was asked for is worse
+		// than not starting.
+		log.Fatalf("[API] malware scanner: %v", err)
+	} else if scanner != nil {
+		cvParser.EnableScanner(scanner, cfg.ScanTimeout)
+		log.Printf("[API] Malware scanning enabled (%s)", cfg.ScanBackend)
+	}
+	blobs, err := storage.NewBlobStore(storage.BlobConfig{
+		Backend:         cfg.BlobBackend,
+		LocalDir:        cfg.UploadsDir,
+		Bucket:          cfg.BlobBucket,
+		Region:          cfg.BlobRegion,
+		Endpoint:        cfg.BlobEndpoint,
+		AccessKeyID:     cfg.BlobAccessKeyID,
+		SecretAccessKey: cfg.BlobSecretAccessKey,
+	})
+	if err != nil {
+		// Falling back to local disk would silently lose uploads on redeploy.
+		log.Fatalf("[API] blob store: %v", err)
 	}
 
 	// Initialize graph builder
 	graphBuilder := graphrag.NewGraphBuilder(db.GetConnection())
 
-	// Initialize GraphRAG search engines
-	var llmSearchEngine *graphrag.LLMSearchEngine           // LLM-only search
-	var enhancedSearchEngine *graphrag.EnhancedSearchEngine // Vector + Community + LLM
-	var hybridSearchEngine *graphrag.HybridSearchEngine     // Multi-source fusion + LLM
-
-	if llmSvc != nil {
-		llmAdapter := graphrag.NewLLMAdapter(llmSvc)
-		llmSearchEngine = graphrag.NewLLMSearchEngine(db.GetConnection(), llmAdapter)
+	// LLM service and GraphRAG search engines (if configured)
+	ai := newAIServices(db, cfg, deploymentAIConfig(cfg))
 
-		// Embeddings always require OpenAI key (even when LLM provider is Groq)
-		openaiKey := cfg.OpenAIAPIKey
-		if openaiKey != "" && openaiKey != "your_openai_api_key_here" {
-			enhancedSearchEngine = graphrag.NewEnhancedSearchEngine(db.GetConnection(), llmAdapter, openaiKey)
-			hybridSearchEngine = graphrag.NewHybridSearchEngine(db.GetConnection(), llmAdapter, openaiKey, cfg.DisableLLMCache)
+	var secrets *secret.Box
+	if cfg.SettingsEncryptionKey != "" {
+		secrets, err = secret.NewBox(cfg.SettingsEncryptionKey) // validated by config.LoadConfig
+		if err != nil {
+			log.Fatalf("[API] %v", err)
 		}
+		graphBuilder.SetSalaryBox(secrets)
+	}
+	warnOfflineDrift(db, cfg)
+
+	mailer, err := notify.NewMailer(notify.Config{
+		Backend:        cfg.NotifyBackend,
+		From:           cfg.NotifyFrom,
+		SMTPHost:       cfg.SMTPHost,
+		SMTPPort:       cfg.SMTPPort,
+		SMTPUsername:   cfg.SMTPUsername,
+		SMTPPassword:   cfg.SMTPPassword,
+		SendGridAPIKey: cfg.SendGridAPIKey,
+	})
+	if err != nil {
+		log.Printf("[API] %v — email notifications are off", err)
+	} else if mailer != nil {
+		log.Printf("[API] Email notifications enabled (%s)", cfg.NotifyBackend)
+	}
+
+	responses, err := cache.NewStore(cache.Config{
+		Backend:    cfg.ResponseCacheBackend,
+		MaxEntries: cfg.ResponseCacheMaxEntries,
+		RedisURL:   cfg.RedisURL,
+	})
+	if err != nil {
+		log.Printf("[API] %v — responses are not cached", err)
+	} else if responses != nil {
+		log.Printf("[API] Response cache enabled (%s, %v; searches %v)", cfg.ResponseCacheBackend, cfg.ResponseCacheTTL, cfg.ResponseCacheSearchTTL)
 	}
 
 	api := &API{
-		db:                   db,
-		cfg:                  cfg,
-		cvParser:             cvParser,
-		llmService:           llmSvc,
-		graphBuilder:         graphBuilder,
-		llmSearchEngine:      llmSearchEngine,
-		enhancedSearchEngine: enhancedSearchEngine,
-		hybridSearchEngine:   hybridSearchEngine,
+		db:           db,
+		cfg:          cfg,
+		cvParser:     cvParser,
+		blobs:        blobs,
+		aiServices:   ai,
+		orgAI:        map[int]orgAIEntry{},
+		secrets:      secrets,
+		graphBuilder: graphBuilder,
 		// Sized to comfortably hold a full bulk upload (MaxBulkFileCount) plus
 		// headroom for retried jobs piling up on top of fresh uploads — avoids
 		// "queue full" drops when the Groq Batch API isn't available and large
 		// bulk uploads fall back to the real-time queue.
-		cvProcessingQueue: make(chan CVProcessingJob, cvQueueBufferSize(cfg.MaxBulkFileCount)),
-		embeddingQueue:    make(chan EmbeddingJob, 100), // Buffer for 100 embedding jobs
+		cvProcessingQueue: make(chan CVProcessingJob, cvQueueBufferSize(cfg)),
+		embeddingQueue:    make(chan EmbeddingJob, cfg.EmbeddingQueueSize),
+		personQueue:       make(chan EmbeddingJob, cfg.EmbeddingQueueSize),
 		batchStore:        newBatchStore(30 * time.Minute),
+		mailer:            mailer,
+		responses:         responses,
+
+		cvQueueStats:        newQueueStats(),
+		embeddingQueueStats: newQueueStats(),
+		personQueueStats:    newQueueStats(),
 	}
+	api.graphqlSchema = api.newGraphQLSchema()
 
 	// Start background workers
 	api.StartBackgroundWorkers()
@@ -167,16 +267,6 @@ func NewAPI(db *storage.DB, cfg *config.Config) *API {
 }
 
 // SearchHandler searches for candidates in database
-// @Summary Search candidates
-// @Description Search for candidates based on criteria (name, location, skills)
-// @Tags candidates
-// @Accept json
-// @Produce json
-// @Param criteria body storage.Criteria true "Search criteria"
-// @Success 200 {array} storage.Candidate
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
-// @Router /search [post]
 func (a *API) SearchHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -192,6 +282,5 @@ func (a *API) SearchHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "search error", http.StatusInternalServerError)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(candidates)
+	writeJSONArray(w, candidates)
 }
@@ -1,12 +1,16 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"cv-search/internal/graphrag"
+	"cv-search/internal/storage"
 )
 
 // HybridSearchRequest represents a hybrid search request
@@ -17,16 +21,64 @@ type HybridSearchRequest struct {
 	GraphWeight  float64 `json:"graph_weight,omitempty"`  // Default: 0.3
 	TopK         int     `json:"top_k,omitempty"`         // Per-source retrieval limit (default: 100)
 	FinalTopN    int     `json:"final_top_n,omitempty"`   // Max candidates to send to LLM (default: 0 = all)
+	Experiment   string  `json:"experiment,omitempty"`    // Named search experiment (default: the DB default experiment, if any)
+	Diversity    float64 `json:"diversity,omitempty"`     // MMR lambda in (0,1) for a more diverse slate (default: 0 = off)
+
+	BM25FieldWeights map[string]float64 `json:"bm25_field_weights,omitempty"` // BM25 weight per field, 0–1: name (default 1), skills (1), experience (0.4), location (0.2)
+
+	Tags        []string           `json:"tags,omitempty"`         // Only candidates carrying all of these tags
+	ExcludeTags []string           `json:"exclude_tags,omitempty"` // Drop candidates carrying any of these, e.g. "do-not-contact"
+	TagBoosts   map[string]float64 `json:"tag_boosts,omitempty"`   // Score multiplier per tag, e.g. {"shortlisted-q3": 1.2}
+
+	Location string  `json:"location,omitempty"`  // Only candidates living in this city or country, e.g. "Ankara", "Türkiye"
+	RadiusKm float64 `json:"radius_km,omitempty"` // With a city location: anyone within this distance of it
+
+	// Salary band: candidates whose CV states a salary outside it are
+	// dropped; those who stated none, or one in another currency, are kept
+	// without salary_in_band.
+	SalaryMin      float64 `json:"salary_min,omitempty"`
+	SalaryMax      float64 `json:"salary_max,omitempty"`
+	SalaryCurrency string  `json:"salary_currency,omitempty"` // ISO 4217, required with a band
+	SalaryPeriod   string  `json:"salary_period,omitempty"`   // year (default) | month | day | hour
+
+	IncludeStatuses []string `json:"include_statuses,omitempty"` // Also return candidates of these statuses: hired | archived | do_not_contact
 }
 
 // HybridSearchResponse represents the response
 type HybridSearchResponse struct {
 	Query          string                      `json:"query"`
+	SearchID       string                      `json:"search_id,omitempty"` // For feedback on the results: POST /api/search/{search_id}/feedback
 	Candidates     []FusedCandidateResponse    `json:"candidates"`
 	TotalFound     int                         `json:"total_found"`
 	ProcessingTime string                      `json:"processing_time"`
 	Method         string                      `json:"method"`
+	Experiment     string                      `json:"experiment,omitempty"` // Experiment that served this search
 	Config         graphrag.HybridSearchConfig `json:"config"`
+	Warnings       []string                    `json:"warnings,omitempty"` // Degraded sources, e.g. "graph source timed out after 20s"
+	SourceLatency  map[string]int64            `json:"source_latency_ms,omitempty"`
+	StageLatency   map[string]int64            `json:"stage_latency_ms,omitempty"` // embedding, analysis, retrieval, fusion, rerank
+	CacheHit       bool                        `json:"cache_hit,omitempty"`
+
+	results []graphrag.FusedCandidate // as the engine returned them, for reports
+}
+
+// HybridSearchPlanResponse answers a dry run (?dry_run=true): what the
+// search would do and cost, without running it.
+type HybridSearchPlanResponse struct {
+	Query      string                      `json:"query"`
+	DryRun     bool                        `json:"dry_run"`
+	Experiment string                      `json:"experiment,omitempty"`
+	Config     graphrag.HybridSearchConfig `json:"config"`
+	Plan       *graphrag.SearchPlan        `json:"plan"`
+
+	// LLM cost of the planned calls, and with the most candidates reranked;
+	// unset when the model has no known price. Embeddings aren't counted.
+	EstimatedCostUSD *float64 `json:"estimated_cost_usd,omitempty"`
+	MaxCostUSD       *float64 `json:"max_cost_usd,omitempty"`
+
+	// How long the organization's recent searches with the same reranker
+	// took; unset before its first.
+	RecentLatency *storage.SearchLatency `json:"recent_latency,omitempty"`
 }
 
 // InterviewSummaryResponse is a lightweight interview view embedded in search results.
@@ -51,6 +103,11 @@ type FusedCandidateResponse struct {
 	Skills                   []graphrag.SkillNode       `json:"skills,omitempty"`
 	Companies                []graphrag.CompanyNode     `json:"companies,omitempty"`
 	Interviews               []InterviewSummaryResponse `json:"interviews,omitempty"`
+	Tags                     []string                   `json:"tags,omitempty"`
+	Location                 string                     `json:"location,omitempty"`
+	DistanceKm               *float64                   `json:"distance_km,omitempty"`    // to the requested city
+	SalaryInBand             *bool                      `json:"salary_in_band,omitempty"` // with a salary band, set when the CV states a salary
+	Status                   string                     `json:"status,omitempty"`         // unless active
 	Community                string                     `json:"community,omitempty"`
 	Communities              []string                   `json:"communities,omitempty"`
 	CommunityScores          map[string]float64         `json:"community_scores,omitempty"`
@@ -62,84 +119,345 @@ type FusedCandidateResponse struct {
 	FusionScore              float64                    `json:"fusion_score"`
 	LLMScore                 float64                    `json:"llm_score"`
 	LLMReasoning             string                     `json:"llm_reasoning,omitempty"`
+	Signals                  *graphrag.RankingSignals   `json:"signals,omitempty"`
+	Snippet                  string                     `json:"snippet,omitempty"` // where the query matched in the CV: HTML, matches in <mark>
 	Rank                     int                        `json:"rank"`
 }
 
+// maxLocationRadiusKm caps a location filter's radius_km.
+const maxLocationRadiusKm = 1000
+
+// applyLocationOptions resolves a search request's location filter into
+// config.
+func (a *API) applyLocationOptions(ctx context.Context, config *graphrag.HybridSearchConfig, req *HybridSearchRequest) string {
+	if strings.TrimSpace(req.Location) == "" {
+		if req.RadiusKm != 0 {
+			return "radius_km needs a location"
+		}
+		return ""
+	}
+	if req.RadiusKm < 0 || req.RadiusKm > maxLocationRadiusKm {
+		return fmt.Sprintf("radius_km must be between 0 and %d", maxLocationRadiusKm)
+	}
+	loc, err := graphrag.ResolveLocation(ctx, a.db.ReadConnection(), req.Location)
+	if err != nil {
+		log.Printf("[API] %v", err)
+		return "failed to resolve location"
+	}
+	if loc == nil {
+		return fmt.Sprintf("unknown location: %q", req.Location)
+	}
+	if req.RadiusKm > 0 && !loc.IsCity() {
+		return "radius_km needs a city location, not a country"
+	}
+	config.Location = loc
+	config.LocationRadiusKm = req.RadiusKm
+	return ""
+}
+
+// applySalaryOptions sets a search request's salary band filter in config.
+func (a *API) applySalaryOptions(config *graphrag.HybridSearchConfig, req *HybridSearchRequest) string {
+	if req.SalaryMin == 0 && req.SalaryMax == 0 {
+		if req.SalaryCurrency != "" || req.SalaryPeriod != "" {
+			return "salary_currency and salary_period need salary_min or salary_max"
+		}
+		return ""
+	}
+	if req.SalaryMin < 0 || req.SalaryMax < 0 || (req.SalaryMax > 0 && req.SalaryMax < req.SalaryMin) {
+		return "salary_min and salary_max must be positive, salary_min at most salary_max"
+	}
+	currency := strings.ToUpper(strings.TrimSpace(req.SalaryCurrency))
+	if len(currency) != 3 {
+		return "salary_currency is required with a salary band (ISO 4217 code, e.g. EUR)"
+	}
+	period := strings.ToLower(strings.TrimSpace(req.SalaryPeriod))
+	if period == "" {
+		period = "year"
+	}
+	if !graphrag.ValidSalaryPeriod(period) {
+		return "salary_period must be year, month, day or hour"
+	}
+	if a.secrets == nil {
+		// Salaries are only kept sealed; without the key there are none.
+		return "salary filtering is not available: SETTINGS_ENCRYPTION_KEY is not set"
+	}
+	config.SalaryBand = &graphrag.SalaryBand{
+		Min:      req.SalaryMin,
+		Max:      req.SalaryMax,
+		Currency: currency,
+		Period:   period,
+		Box:      a.secrets,
+	}
+	return ""
+}
+
 // HybridSearchHandler handles hybrid search requests
 // Combines BM25 + Vector + Graph + LLM scoring
-// @Summary Hybrid Search (BM25 + Vector + Graph + LLM)
-// @Description Search candidates using multi-source retrieval with fusion and pure LLM reranking
-// @Tags search
-// @Accept json
-// @Produce json
-// @Param request body HybridSearchRequest true "Hybrid search request"
-// @Success 200 {object} HybridSearchResponse
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
-// @Router /search/hybrid [post]
 func (a *API) HybridSearchHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	if a.hybridSearchEngine == nil {
-		http.Error(w, "Hybrid search not available (OpenAI API key required)", http.StatusServiceUnavailable)
+	ai, req, config, ok := a.parseHybridSearch(w, r)
+	if !ok {
 		return
 	}
 
-	var req HybridSearchRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	response, err := a.runHybridSearch(r.Context(), ai, req, config)
+	if err != nil {
+		http.Error(w, "Search failed: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	if req.Query == "" {
-		http.Error(w, "Query cannot be empty", http.StatusBadRequest)
+	if len(response.Warnings) > 0 {
+		// Partial results (a source or stage over its deadline): the next
+		// identical search should try again rather than get these.
+		w.Header().Set("Cache-Control", "no-store")
+	}
+	writeJSONList(w, struct {
+		*HybridSearchResponse
+		Candidates json.RawMessage `json:"candidates,omitempty"`
+	}{HybridSearchResponse: response}, "candidates", response.Candidates)
+}
+
+// searchRunFields are the fields of a hybrid search response that belong
+// to the one run that made it.
+var searchRunFields = []string{"search_id", "processing_time", "source_latency_ms", "stage_latency_ms"}
+
+// hybridSearchBodies caches hybrid search responses without their run's
+// fields and answers each cache hit as a run of its own: logged like one
+// (logExperimentFeatures), with a fresh search ID so feedback on it is
+// attributed to it.
+func (a *API) hybridSearchBodies() cachedBodies {
+	return cachedBodies{
+		store: func(body []byte) []byte {
+			fields, ok := responseFields(body)
+			if !ok {
+				return body
+			}
+			for _, f := range searchRunFields {
+				delete(fields, f)
+			}
+			return encodeResponseFields(fields, body)
+		},
+		replay: a.replayHybridSearch,
+	}
+}
+
+// replayHybridSearch logs a cached hybrid search response's run and
+// returns it with its search ID and processing time.
+func (a *API) replayHybridSearch(r *http.Request, body []byte) []byte {
+	start := time.Now()
+	fields, ok := responseFields(body)
+	var cached struct {
+		Query      string                      `json:"query"`
+		Config     graphrag.HybridSearchConfig `json:"config"`
+		Candidates []FusedCandidateResponse    `json:"candidates"`
+	}
+	if !ok || json.Unmarshal(body, &cached) != nil {
+		log.Printf("[ResponseCache] cached hybrid search response unreadable, replayed as is")
+		return body
+	}
+
+	searchID := a.logExperimentFeatures(r.Context(), cached.Query, cached.Config, responseFeatures(cached.Candidates), time.Since(start))
+	if searchID != "" {
+		fields["search_id"], _ = json.Marshal(searchID)
+	}
+	fields["processing_time"], _ = json.Marshal(time.Since(start).String())
+	return encodeResponseFields(fields, body)
+}
+
+// responseFields decodes a JSON object response's top-level fields.
+func responseFields(body []byte) (map[string]json.RawMessage, bool) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil || fields == nil {
+		return nil, false
+	}
+	return fields, true
+}
+
+// encodeResponseFields encodes fields as a response body, or returns
+// fallback if it can't.
+func encodeResponseFields(fields map[string]json.RawMessage, fallback []byte) []byte {
+	body, err := json.Marshal(fields)
+	if err != nil {
+		log.Printf("[ResponseCache] encode response: %v", err)
+		return fallback
+	}
+	return append(body, '\n')
+}
+
+// searchPlanLatencySample is how many recent searches a dry run's latency
+// estimate is taken from.
+const searchPlanLatencySample = 100
+
+// withDryRun serves ?dry_run=true requests with plan and the others with
+// run, so that dry runs skip run's metering and response cache.
+func withDryRun(plan, run http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("dry_run") == "true" {
+			plan(w, r)
+			return
+		}
+		run(w, r)
+	}
+}
+
+// HybridSearchPlanHandler answers a hybrid search dry run: the retrieval
+// sizes, LLM calls and tokens, estimated cost and latency of the search,
+// without running it, so TopK and FinalTopN can be tuned before spending
+// tokens. Not metered.
+func (a *API) HybridSearchPlanHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ai, req, config, ok := a.parseHybridSearch(w, r)
+	if !ok {
 		return
 	}
 
-	// Build config with defaults
-	config := graphrag.DefaultHybridConfig()
-	if req.BM25Weight > 0 {
-		config.BM25Weight = req.BM25Weight
+	plan := ai.hybridSearchEngine.PlanSearch(req.Query, config)
+	response := HybridSearchPlanResponse{
+		Query:      req.Query,
+		DryRun:     true,
+		Experiment: config.Experiment,
+		Config:     config,
+		Plan:       plan,
+	}
+	if cost, ok := ai.llmService.EstimateCostUSD(plan.PromptTokens, plan.CompletionTokens); ok {
+		response.EstimatedCostUSD = &cost
+	}
+	if cost, ok := ai.llmService.EstimateCostUSD(plan.MaxPromptTokens, plan.MaxCompletionTokens); ok {
+		response.MaxCostUSD = &cost
+	}
+	latency, err := a.db.RecentSearchLatency(r.Context(), config.Reranker, searchPlanLatencySample)
+	if err != nil {
+		// The plan stands without it.
+		log.Printf("[API] %v", err)
 	}
-	if req.VectorWeight > 0 {
-		config.VectorWeight = req.VectorWeight
+	response.RecentLatency = latency
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// parseHybridSearch decodes and validates a hybrid search request and
+// resolves its config. On failure it has written the error response.
+func (a *API) parseHybridSearch(w http.ResponseWriter, r *http.Request) (*aiServices, HybridSearchRequest, graphrag.HybridSearchConfig, bool) {
+	var req HybridSearchRequest
+	var config graphrag.HybridSearchConfig
+
+	ai := a.ai(r.Context())
+	if ai.hybridSearchEngine == nil {
+		http.Error(w, "Hybrid search not available (OpenAI API key required)", http.StatusServiceUnavailable)
+		return nil, req, config, false
 	}
-	if req.GraphWeight > 0 {
-		config.GraphWeight = req.GraphWeight
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return nil, req, config, false
 	}
-	if req.TopK > 0 {
-		config.TopK = req.TopK
+
+	if req.Query == "" {
+		http.Error(w, "Query cannot be empty", http.StatusBadRequest)
+		return nil, req, config, false
 	}
-	if req.FinalTopN > 0 {
-		config.FinalTopN = req.FinalTopN
+
+	// Build config: defaults → experiment → request overrides
+	config, errMsg := a.resolveHybridConfig(r.Context(), &req)
+	if errMsg != "" {
+		http.Error(w, errMsg, http.StatusBadRequest)
+		return nil, req, config, false
 	}
 
 	// Validate weights sum to ~1.0
 	totalWeight := config.BM25Weight + config.VectorWeight + config.GraphWeight
 	if totalWeight < 0.9 || totalWeight > 1.1 {
 		http.Error(w, "Weights must sum to 1.0", http.StatusBadRequest)
-		return
+		return nil, req, config, false
 	}
+	return ai, req, config, true
+}
 
+// runHybridSearch runs the search and builds its response.
+func (a *API) runHybridSearch(ctx context.Context, ai *aiServices, req HybridSearchRequest, config graphrag.HybridSearchConfig) (*HybridSearchResponse, error) {
 	startTime := time.Now()
 
-	log.Printf("[API] Hybrid search: %s (BM25=%.2f, Vector=%.2f, Graph=%.2f)",
-		req.Query, config.BM25Weight, config.VectorWeight, config.GraphWeight)
+	log.Printf("[API] Hybrid search: %s (BM25=%.2f, Vector=%.2f, Graph=%.2f, experiment=%q)",
+		req.Query, config.BM25Weight, config.VectorWeight, config.GraphWeight, config.Experiment)
 
 	// Perform hybrid search
-	results, err := a.hybridSearchEngine.Search(r.Context(), req.Query, config)
+	results, diag, err := ai.hybridSearchEngine.SearchWithDiagnostics(ctx, req.Query, config)
 	if err != nil {
 		log.Printf("[API] Hybrid search failed: %v", err)
-		http.Error(w, "Search failed: "+err.Error(), http.StatusInternalServerError)
-		return
+		return nil, err
 	}
 
 	processingTime := time.Since(startTime)
+	searchID := a.logExperimentRun(ctx, req.Query, config, results, processingTime)
 
-	// Convert to response format
+	candidates := toFusedCandidateResponses(results)
+	personIDs := make([]string, len(candidates))
+	for i, c := range candidates {
+		personIDs[i] = c.PersonID
+	}
+	snippets := ai.searchSnippets(ctx, req.Query, personIDs)
+	for i := range candidates {
+		candidates[i].Snippet = snippets[candidates[i].PersonID]
+	}
+
+	response := &HybridSearchResponse{
+		Query:          req.Query,
+		SearchID:       searchID,
+		Candidates:     candidates,
+		TotalFound:     len(candidates),
+		ProcessingTime: processingTime.String(),
+		Method:         "hybrid_fusion_llm",
+		Experiment:     config.Experiment,
+		Config:         config,
+		results:        results,
+	}
+	if diag != nil {
+		response.Warnings = diag.Warnings
+		response.CacheHit = diag.SemanticCacheHit
+		if !diag.SemanticCacheHit {
+			response.SourceLatency = make(map[string]int64, len(diag.SourceLatencies))
+			for src, d := range diag.SourceLatencies {
+				response.SourceLatency[src] = d.Milliseconds()
+			}
+		}
+		response.StageLatency = make(map[string]int64, len(diag.StageLatencies))
+		for stage, d := range diag.StageLatencies {
+			response.StageLatency[stage] = d.Milliseconds()
+		}
+	}
+
+	log.Printf("[API] Hybrid search completed in %s, found %d candidates", processingTime, len(candidates))
+	return response, nil
+}
+
+// searchSnippets returns where query matched in the latest CVs of
+// personIDs, by person ID. A search answers without them if they fail.
+func (ai *aiServices) searchSnippets(ctx context.Context, query string, personIDs []string) map[string]string {
+	var snippets map[string]string
+	var err error
+	switch {
+	case ai.hybridSearchEngine != nil:
+		snippets, err = ai.hybridSearchEngine.Snippets(ctx, query, personIDs)
+	case ai.llmSearchEngine != nil:
+		snippets, err = ai.llmSearchEngine.Snippets(ctx, query, personIDs)
+	}
+	if err != nil {
+		log.Printf("[API] %v", err)
+	}
+	return snippets
+}
+
+// toFusedCandidateResponses converts engine results to the API response shape.
+func toFusedCandidateResponses(results []graphrag.FusedCandidate) []FusedCandidateResponse {
 	var candidates []FusedCandidateResponse
 	for _, c := range results {
 		// Convert interview contexts to summary responses (exclude notes)
@@ -164,6 +482,11 @@ func (a *API) HybridSearchHandler(w http.ResponseWriter, r *http.Request) {
 			Skills:                   c.Skills,
 			Companies:                c.Companies,
 			Interviews:               ivSummaries,
+			Tags:                     c.Tags,
+			Location:                 c.Location,
+			DistanceKm:               c.DistanceKm,
+			SalaryInBand:             c.SalaryInBand,
+			Status:                   c.Status,
 			Community:                c.Community,
 			Communities:              c.Communities,
 			CommunityScores:          c.CommunityScores,
@@ -175,21 +498,9 @@ func (a *API) HybridSearchHandler(w http.ResponseWriter, r *http.Request) {
 			FusionScore:              c.FusionScore,
 			LLMScore:                 c.LLMScore,
 			LLMReasoning:             c.LLMReasoning,
+			Signals:                  c.Signals,
 			Rank:                     c.Rank,
 		})
 	}
-
-	response := HybridSearchResponse{
-		Query:          req.Query,
-		Candidates:     candidates,
-		TotalFound:     len(candidates),
-		ProcessingTime: processingTime.String(),
-		Method:         "hybrid_fusion_llm",
-		Config:         config,
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
-
-	log.Printf("[API] Hybrid search completed in %s, found %d candidates", processingTime, len(candidates))
+	return candidates
 }
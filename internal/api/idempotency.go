@@ -0,0 +1,213 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"cv-search/internal/storage"
+)
+
+const (
+	maxIdempotencyKeyLen = 255
+	// idempotencyLockTimeout is how long a key stays reserved by a request
+	// that never finished (its instance died) before a retry may run again.
+	idempotencyLockTimeout = 5 * time.Minute
+	// maxIdempotentResponseBytes bounds a stored response; a larger one isn't
+	// kept and its retries run again.
+	maxIdempotentResponseBytes = 1 << 20
+)
+
+// idempotencyMiddleware makes POST, PUT, PATCH and DELETE requests with an
+// Idempotency-Key header safe to retry. The first request with a key
+// reserves it and its response is stored for IDEMPOTENCY_KEY_TTL_HOURS; a
+// retry with the same key, method, URL and body gets that response back
+// (Idempotent-Replayed: true) instead of uploading or creating again. A
+// retry while the first is still running gets 409, the same key on a
+// different request 422. Server errors, 429s, event streams and responses
+// over 1MB aren't stored, so their retries run again.
+func (a *API) idempotencyMiddleware(next http.Handler) http.Handler {
+	return idempotent(a.db, a.cfg.IdempotencyKeyTTL, a.maxRequestBytes(), next)
+}
+
+// idempotencyStore holds reserved Idempotency-Keys and their stored
+// responses (*storage.DB).
+type idempotencyStore interface {
+	ReserveIdempotencyKey(ctx context.Context, key, method, path string, ttl, lockTimeout time.Duration) (*storage.IdempotencyRecord, error)
+	CompleteIdempotencyKey(ctx context.Context, rec *storage.IdempotencyRecord) error
+	ReleaseIdempotencyKey(ctx context.Context, key string) error
+}
+
+// idempotent is idempotencyMiddleware over store, keeping responses for ttl
+// (0 = off) and fingerprinting request bodies of up to maxRequestBytes.
+func idempotent(store idempotencyStore, ttl time.Duration, maxRequestBytes int64, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		switch r.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			key = ""
+		}
+		if key == "" || ttl <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !validIdempotencyKey(key) {
+			http.Error(w, fmt.Sprintf("invalid Idempotency-Key (1-%d printable ASCII characters)", maxIdempotencyKeyLen), http.StatusBadRequest)
+			return
+		}
+
+		rec, err := store.ReserveIdempotencyKey(r.Context(), key, r.Method, r.URL.RequestURI(), ttl, idempotencyLockTimeout)
+		if err != nil {
+			log.Printf("[Idempotency] %v", err)
+			http.Error(w, "database error", http.StatusInternalServerError)
+			return
+		}
+		if rec != nil {
+			replayIdempotent(w, r, rec, maxRequestBytes)
+			return
+		}
+
+		body := newFingerprintBody(r)
+		r.Body = body
+		rw := &idempotentWriter{ResponseWriter: w}
+		next.ServeHTTP(rw, r)
+
+		// The client may be gone (that's why it retries): record the outcome
+		// regardless.
+		ctx := context.WithoutCancel(r.Context())
+		status := rw.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		fingerprint, ok := body.finish(maxRequestBytes)
+		if !ok || status >= http.StatusInternalServerError || status == http.StatusTooManyRequests || rw.overflow ||
+			strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream") {
+			if err := store.ReleaseIdempotencyKey(ctx, key); err != nil {
+				log.Printf("[Idempotency] %v", err)
+			}
+			return
+		}
+		if err := store.CompleteIdempotencyKey(ctx, &storage.IdempotencyRecord{
+			Key:         key,
+			Fingerprint: fingerprint,
+			Status:      status,
+			ContentType: w.Header().Get("Content-Type"),
+			Body:        rw.body.Bytes(),
+		}); err != nil {
+			log.Printf("[Idempotency] %v", err)
+		}
+	})
+}
+
+// replayIdempotent answers a request whose Idempotency-Key is taken: with the
+// stored response if it is a retry of the same request.
+func replayIdempotent(w http.ResponseWriter, r *http.Request, rec *storage.IdempotencyRecord, maxRequestBytes int64) {
+	if rec.Status == 0 {
+		http.Error(w, "a request with this Idempotency-Key is still in progress", http.StatusConflict)
+		return
+	}
+	fingerprint, ok := newFingerprintBody(r).finish(maxRequestBytes)
+	if !ok || fingerprint != rec.Fingerprint {
+		http.Error(w, "Idempotency-Key was already used for a different request", http.StatusUnprocessableEntity)
+		return
+	}
+	log.Printf("[Idempotency] Replaying %s %s (key %q, status %d)", rec.Method, rec.Path, rec.Key, rec.Status)
+	if rec.ContentType != "" {
+		w.Header().Set("Content-Type", rec.ContentType)
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(rec.Status)
+	w.Write(rec.Body)
+}
+
+// maxRequestBytes is the largest body any endpoint accepts (a bulk upload);
+// a longer one can't be fingerprinted.
+func (a *API) maxRequestBytes() int64 {
+	return int64(a.cfg.MaxFileSizeMB*a.cfg.MaxBulkFileCount)<<20 + 1<<20
+}
+
+func validIdempotencyKey(key string) bool {
+	if len(key) > maxIdempotencyKeyLen {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x20 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// fingerprintBody hashes a request's method, URL and body as the handler
+// reads it.
+type fingerprintBody struct {
+	io.ReadCloser
+	h hash.Hash
+	n int64
+}
+
+func newFingerprintBody(r *http.Request) *fingerprintBody {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s\n", r.Method, r.URL.RequestURI())
+	return &fingerprintBody{ReadCloser: r.Body, h: h}
+}
+
+func (b *fingerprintBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.h.Write(p[:n])
+	b.n += int64(n)
+	return n, err
+}
+
+// finish reads what the handler left of the body and returns the
+// fingerprint; ok is false if the body is longer than limit or can't be read.
+func (b *fingerprintBody) finish(limit int64) (fingerprint string, ok bool) {
+	if _, err := io.Copy(io.Discard, io.LimitReader(b, limit+1-b.n)); err != nil || b.n > limit {
+		return "", false
+	}
+	return hex.EncodeToString(b.h.Sum(nil)), true
+}
+
+// idempotentWriter keeps a copy of the response it passes on, for retries.
+type idempotentWriter struct {
+	http.ResponseWriter
+	status   int
+	body     bytes.Buffer
+	overflow bool // longer than maxIdempotentResponseBytes, not kept
+}
+
+func (w *idempotentWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *idempotentWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if !w.overflow {
+		if w.body.Len()+len(p) > maxIdempotentResponseBytes {
+			w.overflow = true
+			w.body = bytes.Buffer{}
+		} else {
+			w.body.Write(p)
+		}
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *idempotentWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,268 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"cv-search/internal/storage"
+)
+
+// fakeIdempotencyStore is an in-memory idempotencyStore.
+type fakeIdempotencyStore struct {
+	records  map[string]*storage.IdempotencyRecord
+	released []string
+}
+
+func newFakeIdempotencyStore() *fakeIdempotencyStore {
+	return &fakeIdempotencyStore{records: make(map[string]*storage.IdempotencyRecord)}
+}
+
+func (s *fakeIdempotencyStore) ReserveIdempotencyKey(_ context.Context, key, method, path string, _, _ time.Duration) (*storage.IdempotencyRecord, error) {
+	if rec, ok := s.records[key]; ok {
+		found := *rec
+		return &found, nil
+	}
+	s.records[key] = &storage.IdempotencyRecord{Key: key, Method: method, Path: path}
+	return nil, nil
+}
+
+func (s *fakeIdempotencyStore) CompleteIdempotencyKey(_ context.Context, rec *storage.IdempotencyRecord) error {
+	stored := s.records[rec.Key]
+	stored.Fingerprint, stored.Status, stored.ContentType, stored.Body = rec.Fingerprint, rec.Status, rec.ContentType, rec.Body
+	return nil
+}
+
+func (s *fakeIdempotencyStore) ReleaseIdempotencyKey(_ context.Context, key string) error {
+	delete(s.records, key)
+	s.released = append(s.released, key)
+	return nil
+}
+
+func TestValidIdempotencyKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"8e03978e-40d5-43e8-bc93-6894a57f9324", true},
+		{"with space", true},
+		{strings.Repeat("k", maxIdempotencyKeyLen), true},
+		{strings.Repeat("k", maxIdempotencyKeyLen+1), false},
+		{"tab\tkey", false},
+		{"newline\n", false},
+		{"del\x7f", false},
+		{"ünicode", false},
+	}
+	for _, tt := range tests {
+		if got := validIdempotencyKey(tt.key); got != tt.want {
+			t.Errorf("validIdempotencyKey(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestFingerprintBodyFinish(t *testing.T) {
+	fingerprint := func(method, url, body string, read int, limit int64) (string, bool) {
+		r := httptest.NewRequest(method, url, strings.NewReader(body))
+		b := newFingerprintBody(r)
+		io.ReadFull(b, make([]byte, read)) // what the handler read
+		return b.finish(limit)
+	}
+	base, ok := fingerprint("POST", "/api/cv/upload", "hello", 0, 5)
+	if !ok {
+		t.Fatal("a body at the limit can't be fingerprinted")
+	}
+	tests := []struct {
+		name         string
+		method, url  string
+		body         string
+		read         int
+		limit        int64
+		wantOK       bool
+		wantSameAsOK bool // same fingerprint as POST /api/cv/upload "hello"
+	}{
+		{"unread body", "POST", "/api/cv/upload", "hello", 0, 5, true, true},
+		{"partly read by the handler", "POST", "/api/cv/upload", "hello", 2, 5, true, true},
+		{"fully read by the handler", "POST", "/api/cv/upload", "hello", 5, 5, true, true},
+		{"one byte over the limit", "POST", "/api/cv/upload", "hello!", 0, 5, false, false},
+		{"over the limit, read by the handler", "POST", "/api/cv/upload", "hello!", 6, 5, false, false},
+		{"other body", "POST", "/api/cv/upload", "hellO", 0, 5, true, false},
+		{"other url", "POST", "/api/cv/upload?x=1", "hello", 0, 5, true, false},
+		{"other method", "PUT", "/api/cv/upload", "hello", 0, 5, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := fingerprint(tt.method, tt.url, tt.body, tt.read, tt.limit)
+			if ok != tt.wantOK {
+				t.Fatalf("finish ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && (got == base) != tt.wantSameAsOK {
+				t.Errorf("fingerprint equal to the base one: %v, want %v", got == base, tt.wantSameAsOK)
+			}
+		})
+	}
+}
+
+func TestIdempotencyMiddlewareStores(t *testing.T) {
+	const maxRequest = 64
+	tests := []struct {
+		name       string
+		method     string
+		key        string
+		ttl        time.Duration
+		body       string
+		handler    http.HandlerFunc
+		wantStatus int
+		wantStored bool // the response was kept for retries
+		wantKept   bool // the key is still reserved or stored
+	}{
+		{
+			name: "created", method: "POST", key: "k", ttl: time.Hour, body: "{}",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte("made"))
+			},
+			wantStatus: http.StatusCreated, wantStored: true, wantKept: true,
+		},
+		{
+			name: "client error stored", method: "DELETE", key: "k", ttl: time.Hour,
+			handler:    func(w http.ResponseWriter, r *http.Request) { http.Error(w, "nope", http.StatusNotFound) },
+			wantStatus: http.StatusNotFound, wantStored: true, wantKept: true,
+		},
+		{
+			name: "server error released", method: "POST", key: "k", ttl: time.Hour,
+			handler:    func(w http.ResponseWriter, r *http.Request) { http.Error(w, "boom", http.StatusInternalServerError) },
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name: "rate limited released", method: "POST", key: "k", ttl: time.Hour,
+			handler:    func(w http.ResponseWriter, r *http.Request) { http.Error(w, "slow down", http.StatusTooManyRequests) },
+			wantStatus: http.StatusTooManyRequests,
+		},
+		{
+			name: "event stream released", method: "POST", key: "k", ttl: time.Hour,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				w.Write([]byte("data: 1\n\n"))
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "response overflow released", method: "POST", key: "k", ttl: time.Hour,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write(bytes.Repeat([]byte("x"), maxIdempotentResponseBytes))
+				w.Write([]byte("x"))
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "request body over the limit released", method: "POST", key: "k", ttl: time.Hour, body: strings.Repeat("b", maxRequest+1),
+			handler:    func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("ok")) },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "GET passes through", method: "GET", key: "k", ttl: time.Hour,
+			handler:    func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("ok")) },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "no key passes through", method: "POST", ttl: time.Hour,
+			handler:    func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("ok")) },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "disabled passes through", method: "POST", key: "k",
+			handler:    func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("ok")) },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "invalid key", method: "POST", key: "bad\tkey", ttl: time.Hour,
+			handler:    func(w http.ResponseWriter, r *http.Request) { t.Error("handler ran for an invalid key") },
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeIdempotencyStore()
+			h := idempotent(store, tt.ttl, maxRequest, tt.handler)
+			r := httptest.NewRequest(tt.method, "/api/things", strings.NewReader(tt.body))
+			if tt.key != "" {
+				r.Header.Set("Idempotency-Key", tt.key)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			rec, kept := store.records[tt.key]
+			if kept != tt.wantKept {
+				t.Fatalf("key kept = %v, want %v (released %v)", kept, tt.wantKept, store.released)
+			}
+			if stored := kept && rec.Status != 0; stored != tt.wantStored {
+				t.Errorf("response stored = %v, want %v", stored, tt.wantStored)
+			}
+			if tt.wantStored && (rec.Status != w.Code || !bytes.Equal(rec.Body, w.Body.Bytes())) {
+				t.Errorf("stored %d %q, sent %d %q", rec.Status, rec.Body, w.Code, w.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestIdempotencyMiddlewareRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		inProgress bool // the first request hasn't finished
+		retryURL   string
+		retryBody  string
+		wantStatus int
+		wantReplay bool
+	}{
+		{name: "same request replays", retryURL: "/api/things", retryBody: `{"a":1}`, wantStatus: http.StatusCreated, wantReplay: true},
+		{name: "other body", retryURL: "/api/things", retryBody: `{"a":2}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "other url", retryURL: "/api/things?x=1", retryBody: `{"a":1}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "still in progress", inProgress: true, retryURL: "/api/things", retryBody: `{"a":1}`, wantStatus: http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeIdempotencyStore()
+			calls := 0
+			h := idempotent(store, time.Hour, 1<<10, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"id":1}`))
+			}))
+			send := func(url, body string) *httptest.ResponseRecorder {
+				r := httptest.NewRequest(http.MethodPost, url, strings.NewReader(body))
+				r.Header.Set("Idempotency-Key", "retry-me")
+				w := httptest.NewRecorder()
+				h.ServeHTTP(w, r)
+				return w
+			}
+			if tt.inProgress {
+				store.records["retry-me"] = &storage.IdempotencyRecord{Key: "retry-me"}
+			} else {
+				send("/api/things", `{"a":1}`)
+			}
+
+			w := send(tt.retryURL, tt.retryBody)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("retry status = %d, want %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if replayed := w.Header().Get("Idempotent-Replayed") == "true"; replayed != tt.wantReplay {
+				t.Errorf("Idempotent-Replayed = %v, want %v", replayed, tt.wantReplay)
+			}
+			if tt.wantReplay && (w.Body.String() != `{"id":1}` || w.Header().Get("Content-Type") != "application/json") {
+				t.Errorf("replayed %q (%s)", w.Body.String(), w.Header().Get("Content-Type"))
+			}
+			if wantCalls := map[bool]int{true: 0, false: 1}[tt.inProgress]; calls != wantCalls {
+				t.Errorf("handler ran %d times, want %d", calls, wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,459 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cv-search/internal/importer"
+	"cv-search/internal/tenant"
+	httpclient "cv-search/pkg/http"
+)
+
+// resumeFetchTimeout bounds a single resume download attempt;
+// resumeFetchRetries is how often a network error, 429 or 5xx is retried
+// right away.
+const (
+	resumeFetchTimeout = 30 * time.Second
+	resumeFetchRetries = 2
+)
+
+// importResumeConcurrency is how many resumes one import downloads at once.
+const importResumeConcurrency = 4
+
+var resumeHTTPClient = httpclient.NewClient(resumeFetchTimeout)
+
+// importSourcePattern restricts the ?source= label stored in
+// candidates.import_source.
+var importSourcePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,63}$`)
+
+// ─── Request/Response types ───────────────────────────────────────────────────
+
+type importRowResult struct {
+	Row         int    `json:"row"`
+	Name        string `json:"name,omitempty"`
+	Email       string `json:"email,omitempty"`
+	ExternalID  string `json:"external_id,omitempty"`
+	CandidateID int    `json:"candidate_id,omitempty"`
+	Status      string `json:"status"`           // created | updated | invalid | error | valid (dry run)
+	Resume      string `json:"resume,omitempty"` // queued | batch_submitted | duplicate | queue_full | failed
+	CVID        *int64 `json:"cv_id,omitempty"`
+	JobID       *int64 `json:"job_id,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+type importDryRunResponse struct {
+	DryRun  bool              `json:"dry_run"`
+	Format  importer.Format   `json:"format"`
+	Source  string            `json:"source"`
+	Total   int               `json:"total"`
+	Valid   int               `json:"valid"`
+	Invalid int               `json:"invalid"`
+	Results []importRowResult `json:"results"`
+}
+
+// importResponse is the 207 of an import; check_status_url is set when
+// resumes were queued for extraction.
+type importResponse struct {
+	BatchID        string            `json:"batch_id"`
+	Source         string            `json:"source"`
+	Total          int               `json:"total"`
+	Created        int               `json:"created"`
+	Updated        int               `json:"updated"`
+	Invalid        int               `json:"invalid"`
+	Errors         int               `json:"errors"`
+	ResumesQueued  int               `json:"resumes_queued"`
+	ResumeFailed   int               `json:"resume_failed"`
+	CheckStatusURL string            `json:"check_status_url,omitempty"`
+	Results        []importRowResult `json:"results"`
+}
+
+// ─── Handlers ─────────────────────────────────────────────────────────────────
+
+// ImportCandidatesHandler creates candidates from another ATS's CSV or JSON
+// export (name, email, phone, location, skills, experience, resume_url,
+// external_id — see internal/importer for accepted column names). Rows are
+// matched to existing candidates by (source, external_id), then email, so
+// re-importing an export updates instead of duplicating. Resumes behind
+// resume_url are downloaded and queued through the normal extraction
+// pipeline; follow them with GET /api/cv/batch/{batch_id}.
+//
+// The export is the request body (Content-Type text/csv or application/json)
+// or a multipart "file" field. Query: source (label, default "import"),
+// format (csv|json, otherwise detected), dry_run=true (validate only).
+// POST /api/candidates/import
+func (a *API) ImportCandidatesHandler(w http.ResponseWriter, r *http.Request) {
+	source := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("source")))
+	if source == "" {
+		source = "import"
+	}
+	if !importSourcePattern.MatchString(source) {
+		http.Error(w, "invalid source (lowercase letters, digits, . _ -; max 64)", http.StatusBadRequest)
+		return
+	}
+
+	data, hint, err := a.readImportBody(w, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if q := r.URL.Query().Get("format"); q != "" {
+		hint = q
+	}
+	format, err := importer.DetectFormat(hint, data[:min(len(data), 512)])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rows, err := importer.Parse(bytes.NewReader(data), format, a.cfg.MaxImportRows)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid %s export: %v", format, err), http.StatusBadRequest)
+		return
+	}
+	if len(rows) == 0 {
+		http.Error(w, "export has no rows", http.StatusBadRequest)
+		return
+	}
+
+	results := make([]importRowResult, len(rows))
+	for i, row := range rows {
+		results[i] = importRowResult{
+			Row: row.Line, Name: row.Record.Name, Email: row.Record.Email, ExternalID: row.Record.ExternalID,
+		}
+		if row.Err != nil {
+			results[i].Status = "invalid"
+			results[i].Error = row.Err.Error()
+		}
+	}
+
+	if r.URL.Query().Get("dry_run") == "true" {
+		valid := 0
+		for i := range results {
+			if results[i].Status == "" {
+				results[i].Status = "valid"
+				valid++
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(importDryRunResponse{
+			DryRun:  true,
+			Format:  format,
+			Source:  source,
+			Total:   len(rows),
+			Valid:   valid,
+			Invalid: len(rows) - valid,
+			Results: results,
+		})
+		return
+	}
+
+	batchID := fmt.Sprintf("import_%d", time.Now().UnixNano())
+	counts := map[string]int{}
+	var withResume []int
+	for i := range rows {
+		if results[i].Status == "invalid" {
+			counts["invalid"]++
+			continue
+		}
+		rec := rows[i].Record
+		rec.Source = source
+
+		candidateID, created, err := a.db.UpsertImportedCandidate(r.Context(), &rec)
+		if err != nil {
+			log.Printf("[Import] row %d (%s): %v", rows[i].Line, rec.Name, err)
+			results[i].Status = "error"
+			results[i].Error = "failed to save candidate"
+			counts["error"]++
+			continue
+		}
+		results[i].CandidateID = candidateID
+		results[i].Status = "updated"
+		if created {
+			results[i].Status = "created"
+		}
+		counts[results[i].Status]++
+		a.audit(r, "import", "candidate", strconv.Itoa(candidateID), map[string]interface{}{
+			"source": source, "external_id": rec.ExternalID, "created": created, "batch_id": batchID,
+		})
+
+		if rec.ResumeURL != "" {
+			withResume = append(withResume, i)
+		}
+	}
+
+	// Download resumes a few at a time, then hand the new CVs to extraction
+	// together so a large import can go through the Groq Batch API.
+	type preparedResume struct {
+		idx      int
+		filename string
+		job      *CVProcessingJob
+	}
+	prepared := make([]preparedResume, len(withResume))
+	sem := make(chan struct{}, importResumeConcurrency)
+	var wg sync.WaitGroup
+	for n, idx := range withResume {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(n, idx int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			res := &results[idx]
+			cvID, filename, job, err := a.importResume(r, res.CandidateID, rows[idx].Record.ResumeURL, batchID)
+			prepared[n] = preparedResume{idx: idx, filename: filename, job: job}
+			switch {
+			case err != nil:
+				log.Printf("[Import] row %d resume %s: %v", res.Row, rows[idx].Record.ResumeURL, err)
+				res.Resume = "failed"
+				res.Error = err.Error()
+			case job == nil:
+				res.CVID = &cvID
+				res.Resume = "duplicate"
+			default:
+				res.CVID = &cvID
+			}
+		}(n, idx)
+	}
+	wg.Wait()
+
+	var jobs []CVProcessingJob
+	var jobRows []int
+	var batchJobs []BatchJob
+	for _, p := range prepared {
+		if p.job == nil {
+			continue
+		}
+		jobs = append(jobs, *p.job)
+		jobRows = append(jobRows, p.idx)
+		batchJobs = append(batchJobs, BatchJob{
+			JobID: p.job.JobID, Filename: p.filename, CVID: p.job.CVFileID,
+		})
+	}
+	useBatchAPI, accepted := a.dispatchCVJobs(r.Context(), jobs)
+	for n, idx := range jobRows {
+		res := &results[idx]
+		switch {
+		case useBatchAPI:
+			res.Resume = "batch_submitted"
+		case accepted[n]:
+			res.Resume = "queued"
+		default:
+			res.Resume = "queue_full"
+			counts["resume_failed"]++
+			continue
+		}
+		jobID := jobs[n].JobID
+		res.JobID = &jobID
+		counts["resumes_queued"]++
+	}
+	for _, res := range results {
+		if res.Resume == "failed" {
+			counts["resume_failed"]++
+		}
+	}
+	if len(batchJobs) > 0 {
+		a.batchStore.set(&BatchEntry{BatchID: batchID, Jobs: batchJobs, CreatedAt: time.Now()})
+	}
+	if counts["created"]+counts["updated"] > 0 {
+		a.invalidateSearchCache(r.Context())
+	}
+
+	log.Printf("[Import] batch=%s source=%s total=%d created=%d updated=%d invalid=%d errors=%d resumes_queued=%d resume_failed=%d batch_api=%v",
+		batchID, source, len(rows), counts["created"], counts["updated"], counts["invalid"], counts["error"],
+		counts["resumes_queued"], counts["resume_failed"], useBatchAPI)
+
+	body := importResponse{
+		BatchID:       batchID,
+		Source:        source,
+		Total:         len(rows),
+		Created:       counts["created"],
+		Updated:       counts["updated"],
+		Invalid:       counts["invalid"],
+		Errors:        counts["error"],
+		ResumesQueued: counts["resumes_queued"],
+		ResumeFailed:  counts["resume_failed"],
+		Results:       results,
+	}
+	if len(batchJobs) > 0 {
+		body.CheckStatusURL = fmt.Sprintf("/api/cv/batch/%s", batchID)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusMultiStatus) // 207
+	json.NewEncoder(w).Encode(body)
+}
+
+// readImportBody returns the export bytes and a format hint (the file name
+// or Content-Type), from a multipart "file" field or the raw body.
+func (a *API) readImportBody(w http.ResponseWriter, r *http.Request) ([]byte, string, error) {
+	maxSize := int64(a.cfg.MaxFileSizeMB) << 20
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+(1<<20))
+
+	if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt == "multipart/form-data" {
+		if err := r.ParseMultipartForm(maxSize); err != nil {
+			return nil, "", fmt.Errorf("file too large or invalid (max %dMB)", a.cfg.MaxFileSizeMB)
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			return nil, "", errors.New("no file uploaded (use field name: file)")
+		}
+		defer file.Close()
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return nil, "", errors.New("failed to read uploaded file")
+		}
+		return data, header.Filename, nil
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("request too large or unreadable (max %dMB)", a.cfg.MaxFileSizeMB)
+	}
+	return data, r.Header.Get("Content-Type"), nil
+}
+
+// importResume downloads a candidate's resume and saves it as a CV file of
+// that candidate with a pending processing job. job is nil when the same CV
+// was uploaded before; cvID is then the existing file, now linked to the
+// candidate if it had none.
+func (a *API) importResume(r *http.Request, candidateID int, resumeURL, batchID string) (cvID int64, filename string, job *CVProcessingJob, err error) {
+	res, err := a.ingestResume(r.Context(), candidateID, resumeURL)
+	if err != nil {
+		return 0, "", nil, err
+	}
+	if res.job != nil {
+		a.audit(r, "upload", "cv_file", strconv.FormatInt(res.cvID, 10), map[string]interface{}{
+			"filename": res.filename, "file_size": res.fileSize, "job_id": res.job.JobID,
+			"batch_id": batchID, "candidate_id": candidateID, "resume_url": resumeURL,
+		})
+	}
+	return res.cvID, res.filename, res.job, nil
+}
+
+// ingestedResume is a downloaded resume saved as a CV file.
+type ingestedResume struct {
+	cvID     int64
+	filename string
+	fileSize int64
+	job      *CVProcessingJob // nil: the same CV was uploaded before
+}
+
+// ingestResume downloads resumeURL, parses it and saves it as a CV file of
+// the candidate with a pending processing job, which the caller dispatches.
+// Either way the candidate's resume counts as downloaded afterwards.
+func (a *API) ingestResume(ctx context.Context, candidateID int, resumeURL string) (*ingestedResume, error) {
+	filename, data, err := a.fetchResume(ctx, resumeURL)
+	if err != nil {
+		return nil, err
+	}
+
+	parsedCV, err := a.cvParser.ParseReader(filename, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("parse resume: %w", err)
+	}
+	if strings.TrimSpace(parsedCV.FullText) == "" {
+		return nil, errors.New("resume has no extractable text")
+	}
+
+	hash := sha256.Sum256([]byte(parsedCV.FullText))
+	contentHash := hex.EncodeToString(hash[:])
+	if existing, err := a.db.FindCVByHash(ctx, contentHash); err != nil {
+		log.Printf("[Import] duplicate check for candidate %d failed: %v", candidateID, err)
+	} else if existing != nil {
+		if existing.CandidateID == nil {
+			if err := a.db.UpdateCVFileCandidateID(ctx, existing.ID, candidateID); err != nil {
+				return nil, fmt.Errorf("link existing cv file: %w", err)
+			}
+		}
+		if err := a.db.MarkResumeDownloaded(ctx, candidateID, existing.FilePath); err != nil {
+			log.Printf("[Import] candidate %d: %v", candidateID, err)
+		}
+		return &ingestedResume{cvID: existing.ID, filename: existing.Filename, fileSize: existing.FileSize}, nil
+	}
+
+	blobKey, err := a.storeCVBlob(ctx, bytes.NewReader(data), int64(len(data)), filename)
+	if err != nil {
+		return nil, fmt.Errorf("store resume: %w", err)
+	}
+	if a.cfg.AnonymizePII {
+		parsedCV.Anonymize()
+	}
+	id, jobID, err := a.saveParsedCV(ctx, uploadOptions{CandidateID: &candidateID}, parsedCV, blobKey, contentHash)
+	if err != nil {
+		return nil, fmt.Errorf("save resume: %w", err)
+	}
+	if err := a.db.MarkResumeDownloaded(ctx, candidateID, blobKey); err != nil {
+		log.Printf("[Import] candidate %d: %v", candidateID, err)
+	}
+
+	return &ingestedResume{
+		cvID:     int64(id),
+		filename: parsedCV.Filename,
+		fileSize: parsedCV.FileSize,
+		job: &CVProcessingJob{
+			OrgID:     tenant.OrgID(ctx),
+			JobID:     jobID,
+			CVFileID:  int64(id),
+			CVText:    parsedCV.FullText,
+			Timestamp: time.Now(),
+		},
+	}, nil
+}
+
+// fetchResume downloads a resume URL, capped at MaxFileSizeMB and retrying
+// transient failures (resumeFetchRetries), and names it with an extension
+// the CV parser accepts (from the URL, Content-Disposition or Content-Type).
+func (a *API) fetchResume(ctx context.Context, rawURL string) (string, []byte, error) {
+	maxSize := int64(a.cfg.MaxFileSizeMB) << 20
+	resp, err := resumeHTTPClient.Download(ctx, rawURL, maxSize, resumeFetchRetries)
+	if errors.Is(err, httpclient.ErrTooLarge) {
+		return "", nil, fmt.Errorf("resume too large (max %d MB)", a.cfg.MaxFileSizeMB)
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("download resume: %w", err)
+	}
+	data := resp.Body
+
+	filename := ""
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		filename = displayFilename(params["filename"])
+	}
+	if _, ok := cvContentTypes[strings.ToLower(filepath.Ext(filename))]; !ok {
+		if u, err := url.Parse(rawURL); err == nil {
+			filename = path.Base(u.Path)
+		}
+	}
+	if _, ok := cvContentTypes[strings.ToLower(filepath.Ext(filename))]; !ok {
+		ext := ""
+		if mt, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil {
+			for e, ct := range cvContentTypes {
+				if strings.HasPrefix(ct, mt) {
+					ext = e
+					break
+				}
+			}
+		}
+		if ext == "" {
+			return "", nil, fmt.Errorf("unsupported resume type %q (supported: "+supportedCVTypes+")", resp.Header.Get("Content-Type"))
+		}
+		filename = "resume" + ext
+	}
+	if filename, err = checkCVUpload(filename, ""); err != nil {
+		return "", nil, err
+	}
+	return filename, data, nil
+}
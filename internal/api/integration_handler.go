@@ -0,0 +1,379 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"cv-search/internal/integrations"
+	"cv-search/internal/storage"
+	"cv-search/internal/tenant"
+)
+
+// ─── Request/Response types ───────────────────────────────────────────────────
+
+type orgIntegrationRequest struct {
+	APIKey string `json:"api_key"` // "" keeps the stored key
+	UserID string `json:"user_id"`
+	JobID  string `json:"job_id"`
+}
+
+type orgIntegrationResponse struct {
+	*storage.OrgIntegration
+	HasAPIKey bool `json:"has_api_key"`
+}
+
+type orgIntegrationsResponse struct {
+	OrgID        int                      `json:"org_id"`
+	Integrations []orgIntegrationResponse `json:"integrations"`
+}
+
+type pushCandidateRequest struct {
+	MatchReasoning string `json:"match_reasoning"`
+}
+
+type pushCandidateResponse struct {
+	CandidateID    int      `json:"candidate_id"`
+	Target         string   `json:"target"`
+	ExternalID     string   `json:"external_id"`
+	URL            string   `json:"url,omitempty"`
+	ResumeAttached bool     `json:"resume_attached"`
+	NoteAdded      bool     `json:"note_added"`
+	Warnings       []string `json:"warnings,omitempty"`
+}
+
+// maxReasoningLength caps match_reasoning, in characters.
+const maxReasoningLength = 5000
+
+// ─── Helpers ──────────────────────────────────────────────────────────────────
+
+func knownTarget(target string) bool {
+	for _, t := range integrations.Targets {
+		if t == target {
+			return true
+		}
+	}
+	return false
+}
+
+// targetFromPath reads and checks {target}, writing a 400 and returning ""
+// if it isn't a supported ATS.
+func targetFromPath(w http.ResponseWriter, r *http.Request) string {
+	target := strings.ToLower(r.PathValue("target"))
+	if !knownTarget(target) {
+		http.Error(w, fmt.Sprintf("unknown target %q (want %s)", target, strings.Join(integrations.Targets, " or ")), http.StatusBadRequest)
+		return ""
+	}
+	return target
+}
+
+// connector builds the connector for a stored integration.
+func (a *API) connector(in *storage.OrgIntegration) (integrations.Connector, error) {
+	key, err := a.openSecret(in.APIKey)
+	if err != nil {
+		return nil, err
+	}
+	return integrations.New(integrations.Config{
+		Target: in.Target,
+		APIKey: key,
+		UserID: in.UserID,
+		JobID:  in.JobID,
+	})
+}
+
+// loadResume reads a candidate's latest CV file for attaching to a push. It
+// returns nil when there is nothing to attach: no file, an anonymized CV
+// (blind screening must not leak the original through the ATS) or one
+// larger than uploads may be.
+func (a *API) loadResume(ctx context.Context, candidateID int) (*integrations.Resume, error) {
+	info, err := a.db.GetLatestCVFileForCandidate(ctx, candidateID)
+	if err != nil {
+		return nil, err
+	}
+	maxSize := int64(a.cfg.MaxFileSizeMB) << 20
+	if info == nil || info.FilePath == "" || info.Anonymized || info.FileSize > maxSize {
+		return nil, nil
+	}
+	body, err := a.blobs.Get(ctx, info.FilePath)
+	if errors.Is(err, storage.ErrBlobNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read cv file %d: %w", info.ID, err)
+	}
+	defer body.Close()
+	data, err := io.ReadAll(io.LimitReader(body, maxSize))
+	if err != nil {
+		return nil, fmt.Errorf("read cv file %d: %w", info.ID, err)
+	}
+	return &integrations.Resume{
+		Filename:    info.Filename,
+		ContentType: cvContentType(info.Filename),
+		Data:        data,
+	}, nil
+}
+
+// ─── Organization integrations (admin) ───────────────────────────────────────
+
+// ListOrgIntegrationsHandler lists an organization's ATS integrations; the
+// API keys are never returned.
+//
+//	GET /api/admin/orgs/{id}/integrations
+func (a *API) ListOrgIntegrationsHandler(w http.ResponseWriter, r *http.Request) {
+	orgID := a.orgIDFromPath(w, r)
+	if orgID == 0 {
+		return
+	}
+	list, err := a.db.ListOrgIntegrations(r.Context(), orgID)
+	if err != nil {
+		log.Printf("[Integrations] %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	out := make([]orgIntegrationResponse, len(list))
+	for i := range list {
+		out[i] = orgIntegrationResponse{OrgIntegration: &list[i], HasAPIKey: len(list[i].APIKey) > 0}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(orgIntegrationsResponse{OrgID: orgID, Integrations: out})
+}
+
+// PutOrgIntegrationHandler sets an organization's credentials for one ATS.
+// The API key is encrypted (SETTINGS_ENCRYPTION_KEY) before it is stored;
+// omitting it keeps the stored one. The credentials are tried once before
+// saving.
+//
+//	PUT /api/admin/orgs/{id}/integrations/{target} {"api_key": "...", "user_id": "4080", "job_id": "127817"}
+func (a *API) PutOrgIntegrationHandler(w http.ResponseWriter, r *http.Request) {
+	orgID := a.orgIDFromPath(w, r)
+	if orgID == 0 {
+		return
+	}
+	target := targetFromPath(w, r)
+	if target == "" {
+		return
+	}
+	var req orgIntegrationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	ctx := r.Context()
+
+	current, err := a.db.GetOrgIntegration(ctx, orgID, target)
+	if err != nil {
+		log.Printf("[Integrations] %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	in := &storage.OrgIntegration{
+		OrgID:  orgID,
+		Target: target,
+		UserID: strings.TrimSpace(req.UserID),
+		JobID:  strings.TrimSpace(req.JobID),
+	}
+	if in.UserID == "" {
+		http.Error(w, "user_id is required", http.StatusBadRequest)
+		return
+	}
+	if key := strings.TrimSpace(req.APIKey); key != "" {
+		if a.secrets == nil {
+			http.Error(w, "API keys can't be stored: SETTINGS_ENCRYPTION_KEY is not set", http.StatusBadRequest)
+			return
+		}
+		if in.APIKey, err = a.secrets.Seal(key); err != nil {
+			log.Printf("[Integrations] seal %s key: %v", target, err)
+			http.Error(w, "failed to encrypt api_key", http.StatusInternalServerError)
+			return
+		}
+	} else if current != nil {
+		in.APIKey = current.APIKey
+	} else {
+		http.Error(w, "api_key is required", http.StatusBadRequest)
+		return
+	}
+
+	conn, err := a.connector(in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := conn.Check(ctx); err != nil {
+		http.Error(w, fmt.Sprintf("%s check failed: %v", target, err), http.StatusBadRequest)
+		return
+	}
+
+	if err := a.db.SaveOrgIntegration(ctx, in); err != nil {
+		log.Printf("[Integrations] %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	a.audit(r, "update", "organization_integration", strconv.Itoa(orgID), map[string]interface{}{
+		"target": target, "user_id": in.UserID, "job_id": in.JobID,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(orgIntegrationResponse{OrgIntegration: in, HasAPIKey: true})
+}
+
+// DeleteOrgIntegrationHandler removes an organization's credentials for one
+// ATS. Earlier pushes stay recorded.
+//
+//	DELETE /api/admin/orgs/{id}/integrations/{target}
+func (a *API) DeleteOrgIntegrationHandler(w http.ResponseWriter, r *http.Request) {
+	orgID := a.orgIDFromPath(w, r)
+	if orgID == 0 {
+		return
+	}
+	target := targetFromPath(w, r)
+	if target == "" {
+		return
+	}
+	deleted, err := a.db.DeleteOrgIntegration(r.Context(), orgID, target)
+	if err != nil {
+		log.Printf("[Integrations] %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	if deleted {
+		a.audit(r, "delete", "organization_integration", strconv.Itoa(orgID), map[string]interface{}{"target": target})
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ─── Candidate push ──────────────────────────────────────────────────────────
+
+// PushCandidateHandler creates a candidate in the organization's ATS: the
+// profile, the latest CV file and a note with the match reasoning (from the
+// optional body). A candidate already pushed to the target is refused
+// unless force=true, which creates it again.
+//
+//	POST /api/candidates/{id}/push?target=greenhouse[&force=true]  {"match_reasoning": "..."}
+func (a *API) PushCandidateHandler(w http.ResponseWriter, r *http.Request) {
+	candidateID, err := parseCandidateID(r)
+	if err != nil {
+		http.Error(w, "invalid candidate id", http.StatusBadRequest)
+		return
+	}
+	target := strings.ToLower(r.URL.Query().Get("target"))
+	if !knownTarget(target) {
+		http.Error(w, fmt.Sprintf("target must be %s", strings.Join(integrations.Targets, " or ")), http.StatusBadRequest)
+		return
+	}
+	force := r.URL.Query().Get("force") == "true"
+	var req pushCandidateRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+	req.MatchReasoning = strings.TrimSpace(req.MatchReasoning)
+	if len([]rune(req.MatchReasoning)) > maxReasoningLength {
+		http.Error(w, fmt.Sprintf("match_reasoning is longer than %d characters", maxReasoningLength), http.StatusBadRequest)
+		return
+	}
+	ctx := r.Context()
+
+	in, err := a.db.GetOrgIntegration(ctx, tenant.OrgID(ctx), target)
+	if err != nil {
+		log.Printf("[Integrations] %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	if in == nil {
+		http.Error(w, fmt.Sprintf("%s is not configured for this organization", target), http.StatusConflict)
+		return
+	}
+
+	candidate, err := a.db.GetCandidateDetail(ctx, candidateID)
+	if err != nil {
+		log.Printf("[Integrations] GetCandidateDetail(%d) failed: %v", candidateID, err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	if candidate == nil {
+		http.Error(w, "candidate not found", http.StatusNotFound)
+		return
+	}
+	if !force {
+		prev, err := a.db.GetCandidatePush(ctx, candidateID, target)
+		if err != nil {
+			log.Printf("[Integrations] %v", err)
+			http.Error(w, "database error", http.StatusInternalServerError)
+			return
+		}
+		if prev != nil {
+			http.Error(w, fmt.Sprintf("candidate was already pushed to %s as %s (use force=true to push again)", target, prev.ExternalID), http.StatusConflict)
+			return
+		}
+	}
+
+	conn, err := a.connector(in)
+	if err != nil {
+		log.Printf("[Integrations] org %d %s connector: %v", in.OrgID, target, err)
+		http.Error(w, fmt.Sprintf("%s integration is misconfigured", target), http.StatusInternalServerError)
+		return
+	}
+	resume, err := a.loadResume(ctx, candidateID)
+	if err != nil {
+		// Push without the file rather than not at all.
+		log.Printf("[Integrations] candidate %d resume: %v", candidateID, err)
+	}
+
+	c := &integrations.Candidate{
+		Name:            candidate.Name,
+		Email:           candidate.Email,
+		Phone:           candidate.Phone,
+		Location:        candidate.Location,
+		LinkedInURL:     candidate.LinkedInURL,
+		CurrentPosition: candidate.CurrentPosition,
+		Tags:            candidate.Tags,
+		Resume:          resume,
+		MatchReasoning:  req.MatchReasoning,
+	}
+	for _, s := range candidate.Skills {
+		c.Skills = append(c.Skills, s.Name)
+	}
+	res, err := conn.Push(ctx, c)
+	if err != nil {
+		log.Printf("[Integrations] push candidate %d to %s: %v", candidateID, target, err)
+		http.Error(w, fmt.Sprintf("push to %s failed: %v", target, err), http.StatusBadGateway)
+		return
+	}
+
+	push := &storage.CandidatePush{
+		CandidateID: candidateID,
+		Target:      target,
+		ExternalID:  res.ExternalID,
+		ExternalURL: res.URL,
+		PushedBy:    actorFromRequest(r),
+	}
+	if err := a.db.RecordCandidatePush(ctx, push); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		// The candidate exists in the ATS now; report it even if the record
+		// is lost.
+		log.Printf("[Integrations] %v", err)
+	}
+	a.audit(r, "push", "candidate", strconv.Itoa(candidateID), map[string]interface{}{
+		"target": target, "external_id": res.ExternalID, "resume_attached": res.ResumeAttached,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(pushCandidateResponse{
+		CandidateID:    candidateID,
+		Target:         target,
+		ExternalID:     res.ExternalID,
+		URL:            res.URL,
+		ResumeAttached: res.ResumeAttached,
+		NoteAdded:      res.NoteAdded,
+		Warnings:       res.Warnings,
+	})
+}
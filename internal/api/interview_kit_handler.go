@@ -0,0 +1,69 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"cv-search/internal/graphrag"
+)
+
+type interviewKitRequest struct {
+	JobDescription string `json:"job_description,omitempty"` // optional; tailors the questions to the role
+}
+
+type interviewKitResponse struct {
+	CandidateID int `json:"candidate_id"`
+	*graphrag.InterviewKit
+}
+
+// InterviewKitHandler writes technical and behavioral interview questions for
+// a candidate, grounded in their projects, companies and skills and quoting
+// their CV, optionally tailored to a job description.
+// POST /api/candidates/{id}/interview-kit
+func (a *API) InterviewKitHandler(w http.ResponseWriter, r *http.Request) {
+	candidateID, err := parseCandidateID(r)
+	if err != nil {
+		http.Error(w, "invalid candidate id", http.StatusBadRequest)
+		return
+	}
+	var req interviewKitRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+	req.JobDescription = strings.TrimSpace(req.JobDescription)
+	if msg := checkJobDescription(req.JobDescription); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	engine := a.ai(r.Context()).llmSearchEngine
+	if engine == nil {
+		http.Error(w, "interview kit not available (LLM not configured)", http.StatusServiceUnavailable)
+		return
+	}
+	graphNodeID, ok := a.candidateGraphNode(w, r, candidateID, "InterviewKit")
+	if !ok {
+		return
+	}
+
+	kit, err := engine.GenerateInterviewKit(r.Context(), graphNodeID, req.JobDescription)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, errNoGraphProfile, http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("[InterviewKit] GenerateInterviewKit(candidate=%d) failed: %v", candidateID, err)
+		http.Error(w, "interview kit generation failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(interviewKitResponse{CandidateID: candidateID, InterviewKit: kit})
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+// streamBufferSize is how much of a streamed JSON response is held before
+// it is written out.
+const streamBufferSize = 32 << 10
+
+// writeJSONList answers with a JSON object whose list is encoded one element
+// at a time, so a large response — a hybrid search with 100+ enriched
+// candidates — is never held encoded in memory whole, as
+// json.NewEncoder(w).Encode(resp) would. head is the object without the list,
+// usually the response embedded in a struct that hides the list under a
+// json.RawMessage field of the same name and omitempty; the list goes last,
+// as key. A nil list is written as [].
+func writeJSONList[T any](w http.ResponseWriter, head interface{}, key string, items []T) {
+	prefix, err := json.Marshal(head)
+	if err != nil || len(prefix) < 2 || prefix[len(prefix)-1] != '}' {
+		log.Printf("[API] encode %s response: %v", key, err)
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+	name, _ := json.Marshal(key)
+
+	w.Header().Set("Content-Type", "application/json")
+	bw := bufio.NewWriterSize(w, streamBufferSize)
+	bw.Write(prefix[:len(prefix)-1])
+	if !bytes.Equal(prefix, []byte("{}")) {
+		bw.WriteByte(',')
+	}
+	bw.Write(name)
+	bw.WriteByte(':')
+	if err := encodeJSONItems(bw, items); err != nil {
+		log.Printf("[API] encode %s response: %v", key, err)
+		return
+	}
+	bw.WriteString("}\n")
+	if err := bw.Flush(); err != nil {
+		log.Printf("[API] write %s response: %v", key, err)
+	}
+}
+
+// writeJSONArray answers with a JSON array encoded one element at a time
+// (see writeJSONList). A nil list is written as [].
+func writeJSONArray[T any](w http.ResponseWriter, items []T) {
+	w.Header().Set("Content-Type", "application/json")
+	bw := bufio.NewWriterSize(w, streamBufferSize)
+	if err := encodeJSONItems(bw, items); err != nil {
+		log.Printf("[API] encode response: %v", err)
+		return
+	}
+	bw.WriteByte('\n')
+	if err := bw.Flush(); err != nil {
+		log.Printf("[API] write response: %v", err)
+	}
+}
+
+// encodeJSONItems writes items as a JSON array. An element that fails to
+// encode leaves the response truncated, which the client sees as invalid
+// JSON rather than a silently shorter list.
+func encodeJSONItems[T any](bw *bufio.Writer, items []T) error {
+	bw.WriteByte('[')
+	for i := range items {
+		if i > 0 {
+			bw.WriteByte(',')
+		}
+		b, err := json.Marshal(&items[i])
+		if err != nil {
+			return err
+		}
+		bw.Write(b)
+	}
+	bw.WriteByte(']')
+	return nil
+}
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"cv-search/internal/storage"
+	"cv-search/internal/tenant"
+)
+
+// ─── Request/Response types ───────────────────────────────────────────────────
+
+type mergeCandidatesRequest struct {
+	PrimaryCandidateID   int `json:"primary_candidate_id"`   // survives the merge
+	DuplicateCandidateID int `json:"duplicate_candidate_id"` // folded into the primary, then soft-deleted
+}
+
+type listCandidateMergesResponse struct {
+	Merges []storage.CandidateMerge `json:"merges"`
+}
+
+// ─── Handlers ─────────────────────────────────────────────────────────────────
+
+// MergeCandidatesHandler merges a duplicate candidate into a primary one:
+// the union of both person nodes' edges ends up on the primary, properties
+// from the side with the most recent CV win, both candidates' CV files,
+// interviews and notes are linked to the primary, and it gets the union of
+// their tags and talent pools. Undo with
+// POST /api/candidates/merges/{id}/undo.
+// POST /api/candidates/merge
+func (a *API) MergeCandidatesHandler(w http.ResponseWriter, r *http.Request) {
+	var req mergeCandidatesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.PrimaryCandidateID <= 0 || req.DuplicateCandidateID <= 0 {
+		http.Error(w, "primary_candidate_id and duplicate_candidate_id are required", http.StatusBadRequest)
+		return
+	}
+
+	m, err := a.db.MergeCandidates(r.Context(), req.PrimaryCandidateID, req.DuplicateCandidateID, actorFromRequest(r))
+	if errors.Is(err, storage.ErrMergeNotAllowed) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("[CandidateHandler] MergeCandidates(%d <- %d) failed: %v", req.PrimaryCandidateID, req.DuplicateCandidateID, err)
+		http.Error(w, "failed to merge candidates", http.StatusInternalServerError)
+		return
+	}
+	if m == nil {
+		http.Error(w, "candidate not found", http.StatusNotFound)
+		return
+	}
+	a.audit(r, "merge", "candidate", strconv.Itoa(m.PrimaryCandidateID), map[string]interface{}{
+		"merge_id":            m.ID,
+		"merged_candidate_id": m.MergedCandidateID,
+		"newest_wins":         m.NewestWins,
+	})
+
+	a.afterMergeChange(r.Context(), m.PrimaryCandidateID)
+	log.Printf("[CandidateHandler] Candidate %d merged into %d (merge %d, edges moved=%d shared=%d, cv_files=%d, interviews=%d, notes=%d, tags=%d, pools=%d)",
+		m.MergedCandidateID, m.PrimaryCandidateID, m.ID, m.EdgesMoved, m.EdgesShared, m.CVFilesMoved, m.InterviewsMoved, m.NotesMoved, m.TagsAdded, m.PoolsAdded)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(m)
+}
+
+// UndoCandidateMergeHandler reverses a merge and restores the duplicate.
+// POST /api/candidates/merges/{id}/undo
+func (a *API) UndoCandidateMergeHandler(w http.ResponseWriter, r *http.Request) {
+	mergeID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid merge id", http.StatusBadRequest)
+		return
+	}
+
+	m, err := a.db.UndoCandidateMerge(r.Context(), mergeID, actorFromRequest(r))
+	if errors.Is(err, storage.ErrMergeNotAllowed) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("[CandidateHandler] UndoCandidateMerge(%d) failed: %v", mergeID, err)
+		http.Error(w, "failed to undo merge", http.StatusInternalServerError)
+		return
+	}
+	if m == nil {
+		http.Error(w, "merge not found", http.StatusNotFound)
+		return
+	}
+	a.audit(r, "merge_undo", "candidate", strconv.Itoa(m.PrimaryCandidateID), map[string]interface{}{
+		"merge_id":            m.ID,
+		"merged_candidate_id": m.MergedCandidateID,
+	})
+
+	a.afterMergeChange(r.Context(), m.PrimaryCandidateID, m.MergedCandidateID)
+	log.Printf("[CandidateHandler] Merge %d undone (candidate %d restored)", m.ID, m.MergedCandidateID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(m)
+}
+
+// ListCandidateMergesHandler lists the merges a candidate took part in.
+// GET /api/candidates/{id}/merges
+func (a *API) ListCandidateMergesHandler(w http.ResponseWriter, r *http.Request) {
+	candidateID, err := parseCandidateID(r)
+	if err != nil {
+		http.Error(w, "invalid candidate id", http.StatusBadRequest)
+		return
+	}
+
+	merges, err := a.db.ListCandidateMerges(r.Context(), candidateID)
+	if err != nil {
+		log.Printf("[CandidateHandler] ListCandidateMerges(%d) failed: %v", candidateID, err)
+		http.Error(w, "failed to list merges", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(listCandidateMergesResponse{Merges: merges})
+}
+
+// afterMergeChange drops cached search results and re-embeds the affected
+// person nodes of ctx's organization, whose skills and interview notes just
+// changed.
+func (a *API) afterMergeChange(ctx context.Context, candidateIDs ...int) {
+	a.invalidateSearchCache(ctx)
+	for _, id := range candidateIDs {
+		go a.reEmbed(tenant.OrgID(ctx), id)
+	}
+}
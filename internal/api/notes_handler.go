@@ -0,0 +1,349 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"cv-search/internal/graphrag"
+	"cv-search/internal/storage"
+)
+
+// ─── Request/Response types ───────────────────────────────────────────────────
+
+type candidateNoteRequest struct {
+	Body string `json:"body"`
+}
+
+type candidateNotesResponse struct {
+	CandidateID int                     `json:"candidate_id"`
+	Notes       []storage.CandidateNote `json:"notes"`
+}
+
+type candidateTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+type candidateTagsResponse struct {
+	CandidateID int                    `json:"candidate_id"`
+	Tags        []storage.CandidateTag `json:"tags"`
+}
+
+// maxNoteLength caps a note body, in characters.
+const maxNoteLength = 10000
+
+// maxTagBoost caps a tag_boosts factor, so one tag can't bury the relevance
+// ranking entirely.
+const maxTagBoost = 10.0
+
+// tagPattern is what a normalized tag looks like: lowercase letters, digits
+// and . _ : - , starting with a letter or digit.
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._:-]{0,63}$`)
+
+// ─── Helpers ──────────────────────────────────────────────────────────────────
+
+func parseNoteID(r *http.Request) (int, error) {
+	raw := r.PathValue("nid")
+	if raw == "" {
+		return 0, errors.New("missing note id")
+	}
+	return strconv.Atoi(raw)
+}
+
+// normalizeTag lowercases a tag and joins its words with "-", so
+// "Shortlisted Q3" and "shortlisted-q3" are the same tag.
+func normalizeTag(raw string) (string, error) {
+	tag := strings.ToLower(strings.Join(strings.Fields(raw), "-"))
+	if !tagPattern.MatchString(tag) {
+		return "", fmt.Errorf("invalid tag %q: use up to 64 letters, digits, '.', '_', ':' or '-'", raw)
+	}
+	return tag, nil
+}
+
+// normalizeTags normalizes and de-duplicates tags, keeping their order.
+func normalizeTags(raw []string) ([]string, error) {
+	seen := make(map[string]bool, len(raw))
+	tags := make([]string, 0, len(raw))
+	for _, r := range raw {
+		tag, err := normalizeTag(r)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
+
+// applyTagOptions validates a search request's tag filters and boosts into
+// config.
+func applyTagOptions(config *graphrag.HybridSearchConfig, req *HybridSearchRequest) string {
+	var err error
+	if config.RequireTags, err = normalizeTags(req.Tags); err != nil {
+		return "tags: " + err.Error()
+	}
+	if config.ExcludeTags, err = normalizeTags(req.ExcludeTags); err != nil {
+		return "exclude_tags: " + err.Error()
+	}
+	if len(req.TagBoosts) == 0 {
+		return ""
+	}
+	config.TagBoosts = make(map[string]float64, len(req.TagBoosts))
+	for raw, f := range req.TagBoosts {
+		tag, err := normalizeTag(raw)
+		if err != nil {
+			return "tag_boosts: " + err.Error()
+		}
+		if f <= 0 || f > maxTagBoost {
+			return fmt.Sprintf("tag_boosts: factor for %q must be greater than 0 and at most %g", raw, maxTagBoost)
+		}
+		config.TagBoosts[tag] = f
+	}
+	return ""
+}
+
+// ─── Notes ────────────────────────────────────────────────────────────────────
+
+// ListCandidateNotesHandler returns a candidate's notes, newest first.
+// GET /api/candidates/{id}/notes
+func (a *API) ListCandidateNotesHandler(w http.ResponseWriter, r *http.Request) {
+	candidateID, err := parseCandidateID(r)
+	if err != nil {
+		http.Error(w, "invalid candidate id", http.StatusBadRequest)
+		return
+	}
+
+	notes, err := a.db.ListCandidateNotes(r.Context(), candidateID)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "candidate not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("[NotesHandler] ListCandidateNotes(%d) failed: %v", candidateID, err)
+		http.Error(w, "failed to list notes", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(candidateNotesResponse{CandidateID: candidateID, Notes: notes})
+}
+
+// readNoteBody decodes and validates a note request, writing the error
+// response itself when it fails.
+func readNoteBody(w http.ResponseWriter, r *http.Request) (string, bool) {
+	var req candidateNoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return "", false
+	}
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		http.Error(w, "body is required", http.StatusUnprocessableEntity)
+		return "", false
+	}
+	if len([]rune(body)) > maxNoteLength {
+		http.Error(w, fmt.Sprintf("body must be at most %d characters", maxNoteLength), http.StatusUnprocessableEntity)
+		return "", false
+	}
+	return body, true
+}
+
+// CreateCandidateNoteHandler adds a note to a candidate; its author is the
+// caller (X-User-ID, else the API key).
+// POST /api/candidates/{id}/notes
+func (a *API) CreateCandidateNoteHandler(w http.ResponseWriter, r *http.Request) {
+	candidateID, err := parseCandidateID(r)
+	if err != nil {
+		http.Error(w, "invalid candidate id", http.StatusBadRequest)
+		return
+	}
+	body, ok := readNoteBody(w, r)
+	if !ok {
+		return
+	}
+
+	note, err := a.db.CreateCandidateNote(r.Context(), candidateID, body, actorFromRequest(r))
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "candidate not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("[NotesHandler] CreateCandidateNote(candidate=%d) failed: %v", candidateID, err)
+		http.Error(w, "failed to create note", http.StatusInternalServerError)
+		return
+	}
+	a.audit(r, "create", "candidate_note", strconv.Itoa(note.ID), map[string]int{"candidate_id": candidateID})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(note)
+}
+
+// UpdateCandidateNoteHandler replaces a note's body.
+// PUT /api/candidates/{id}/notes/{nid}
+func (a *API) UpdateCandidateNoteHandler(w http.ResponseWriter, r *http.Request) {
+	candidateID, err := parseCandidateID(r)
+	if err != nil {
+		http.Error(w, "invalid candidate id", http.StatusBadRequest)
+		return
+	}
+	noteID, err := parseNoteID(r)
+	if err != nil {
+		http.Error(w, "invalid note id", http.StatusBadRequest)
+		return
+	}
+	body, ok := readNoteBody(w, r)
+	if !ok {
+		return
+	}
+
+	note, err := a.db.UpdateCandidateNote(r.Context(), noteID, candidateID, body)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "note not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("[NotesHandler] UpdateCandidateNote(%d, candidate=%d) failed: %v", noteID, candidateID, err)
+		http.Error(w, "failed to update note", http.StatusInternalServerError)
+		return
+	}
+	a.audit(r, "update", "candidate_note", strconv.Itoa(noteID), map[string]int{"candidate_id": candidateID})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(note)
+}
+
+// DeleteCandidateNoteHandler removes a note.
+// DELETE /api/candidates/{id}/notes/{nid}
+func (a *API) DeleteCandidateNoteHandler(w http.ResponseWriter, r *http.Request) {
+	candidateID, err := parseCandidateID(r)
+	if err != nil {
+		http.Error(w, "invalid candidate id", http.StatusBadRequest)
+		return
+	}
+	noteID, err := parseNoteID(r)
+	if err != nil {
+		http.Error(w, "invalid note id", http.StatusBadRequest)
+		return
+	}
+
+	if err := a.db.DeleteCandidateNote(r.Context(), noteID, candidateID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "note not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("[NotesHandler] DeleteCandidateNote(%d, candidate=%d) failed: %v", noteID, candidateID, err)
+		http.Error(w, "failed to delete note", http.StatusInternalServerError)
+		return
+	}
+	a.audit(r, "delete", "candidate_note", strconv.Itoa(noteID), map[string]int{"candidate_id": candidateID})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ─── Tags ─────────────────────────────────────────────────────────────────────
+
+// ListCandidateTagsHandler returns a candidate's tags.
+// GET /api/candidates/{id}/tags
+func (a *API) ListCandidateTagsHandler(w http.ResponseWriter, r *http.Request) {
+	candidateID, err := parseCandidateID(r)
+	if err != nil {
+		http.Error(w, "invalid candidate id", http.StatusBadRequest)
+		return
+	}
+	a.writeCandidateTags(w, r, candidateID)
+}
+
+func (a *API) writeCandidateTags(w http.ResponseWriter, r *http.Request, candidateID int) {
+	tags, err := a.db.ListCandidateTags(r.Context(), candidateID)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "candidate not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("[NotesHandler] ListCandidateTags(%d) failed: %v", candidateID, err)
+		http.Error(w, "failed to list tags", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(candidateTagsResponse{CandidateID: candidateID, Tags: tags})
+}
+
+// AddCandidateTagsHandler tags a candidate and returns all its tags. Tags
+// are normalized ("Shortlisted Q3" → "shortlisted-q3"); ones the candidate
+// already has are kept as they are.
+// POST /api/candidates/{id}/tags  {"tags": ["shortlisted-q3", "contacted"]}
+func (a *API) AddCandidateTagsHandler(w http.ResponseWriter, r *http.Request) {
+	candidateID, err := parseCandidateID(r)
+	if err != nil {
+		http.Error(w, "invalid candidate id", http.StatusBadRequest)
+		return
+	}
+
+	var req candidateTagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	tags, err := normalizeTags(req.Tags)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if len(tags) == 0 {
+		http.Error(w, "tags is required", http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := a.db.AddCandidateTags(r.Context(), candidateID, tags, actorFromRequest(r)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "candidate not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("[NotesHandler] AddCandidateTags(%d) failed: %v", candidateID, err)
+		http.Error(w, "failed to add tags", http.StatusInternalServerError)
+		return
+	}
+	a.audit(r, "tag", "candidate", strconv.Itoa(candidateID), map[string]interface{}{"tags": tags})
+	a.invalidateSearchCache(r.Context())
+
+	a.writeCandidateTags(w, r, candidateID)
+}
+
+// RemoveCandidateTagHandler removes one tag from a candidate.
+// DELETE /api/candidates/{id}/tags/{tag}
+func (a *API) RemoveCandidateTagHandler(w http.ResponseWriter, r *http.Request) {
+	candidateID, err := parseCandidateID(r)
+	if err != nil {
+		http.Error(w, "invalid candidate id", http.StatusBadRequest)
+		return
+	}
+	tag, err := normalizeTag(r.PathValue("tag"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := a.db.RemoveCandidateTag(r.Context(), candidateID, tag); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "tag not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("[NotesHandler] RemoveCandidateTag(%d, %q) failed: %v", candidateID, tag, err)
+		http.Error(w, "failed to remove tag", http.StatusInternalServerError)
+		return
+	}
+	a.audit(r, "untag", "candidate", strconv.Itoa(candidateID), map[string]interface{}{"tag": tag})
+	a.invalidateSearchCache(r.Context())
+
+	w.WriteHeader(http.StatusNoContent)
+}
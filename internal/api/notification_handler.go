@@ -0,0 +1,315 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"cv-search/internal/notify"
+	"cv-search/internal/storage"
+	"cv-search/internal/tenant"
+)
+
+// How often the notification worker looks for finished batches and due
+// digests. A batch report waits at most batchReportMaxWait for unfinished
+// jobs (a Groq batch can take hours) and is given up after
+// batchReportMaxAttempts failed sends.
+const (
+	notifyPollInterval     = time.Minute
+	batchReportMaxWait     = 24 * time.Hour
+	batchReportMaxAttempts = 5
+	digestInterval         = 7 * 24 * time.Hour
+	digestListLimit        = 10
+)
+
+// ─── Request/Response types ───────────────────────────────────────────────────
+
+type notificationPreferencesRequest struct {
+	Email         string `json:"email"`
+	BatchComplete *bool  `json:"batch_complete"` // default true
+	WeeklyDigest  *bool  `json:"weekly_digest"`  // default false
+}
+
+type notificationPreferencesResponse struct {
+	*storage.NotificationPreferences
+	// EmailEnabled is false when the deployment has no NOTIFY_BACKEND, so
+	// nothing is sent whatever the preferences say.
+	EmailEnabled bool `json:"email_enabled"`
+}
+
+// ─── Helpers ──────────────────────────────────────────────────────────────────
+
+// notificationUser returns the X-User-ID preferences are kept under,
+// writing a 400 and returning "" if the request has none.
+func notificationUser(w http.ResponseWriter, r *http.Request) string {
+	uid := strings.TrimSpace(r.Header.Get("X-User-ID"))
+	if uid == "" {
+		http.Error(w, "X-User-ID header is required", http.StatusBadRequest)
+	}
+	return uid
+}
+
+// uploadFailureReason explains a bulk upload file status that left the file
+// without a job.
+func uploadFailureReason(status, errMsg string) string {
+	if errMsg != "" {
+		return errMsg
+	}
+	switch status {
+	case "queue_full":
+		return "processing queue was full; upload it again"
+	default:
+		return "could not be read or stored"
+	}
+}
+
+// queueBatchReport records a bulk upload for its completion email if the
+// uploader (X-User-ID) wants one.
+func (a *API) queueBatchReport(r *http.Request, n *storage.BatchNotification) {
+	if a.mailer == nil {
+		return
+	}
+	uid := strings.TrimSpace(r.Header.Get("X-User-ID"))
+	if uid == "" {
+		return
+	}
+	prefs, err := a.db.GetNotificationPreferences(r.Context(), uid)
+	if err != nil {
+		log.Printf("[Notify] %v", err)
+		return
+	}
+	if prefs == nil || !prefs.BatchComplete {
+		return
+	}
+	n.Email = prefs.Email
+	if err := a.db.CreateBatchNotification(r.Context(), n); err != nil {
+		log.Printf("[Notify] %v", err)
+	}
+}
+
+// ─── Preferences ─────────────────────────────────────────────────────────────
+
+// GetNotificationPreferencesHandler shows the caller's email notification
+// settings in their organization; without any, nothing is sent.
+//
+//	GET /api/notifications/preferences  (X-User-ID: alice)
+func (a *API) GetNotificationPreferencesHandler(w http.ResponseWriter, r *http.Request) {
+	uid := notificationUser(w, r)
+	if uid == "" {
+		return
+	}
+	prefs, err := a.db.GetNotificationPreferences(r.Context(), uid)
+	if err != nil {
+		log.Printf("[Notify] %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	if prefs == nil {
+		prefs = &storage.NotificationPreferences{OrgID: tenant.OrgID(r.Context()), UserID: uid}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(notificationPreferencesResponse{NotificationPreferences: prefs, EmailEnabled: a.mailer != nil})
+}
+
+// PutNotificationPreferencesHandler sets the caller's email address and which
+// emails they get: a report when their bulk upload has been processed
+// (batch_complete, default on) and a weekly digest of new candidates
+// (weekly_digest, default off).
+//
+//	PUT /api/notifications/preferences  {"email": "alice@example.com", "weekly_digest": true}
+func (a *API) PutNotificationPreferencesHandler(w http.ResponseWriter, r *http.Request) {
+	uid := notificationUser(w, r)
+	if uid == "" {
+		return
+	}
+	var req notificationPreferencesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	addr, err := mail.ParseAddress(strings.TrimSpace(req.Email))
+	if err != nil || addr.Name != "" {
+		http.Error(w, "email must be a plain email address", http.StatusBadRequest)
+		return
+	}
+	prefs := &storage.NotificationPreferences{
+		UserID:        uid,
+		Email:         addr.Address,
+		BatchComplete: true,
+	}
+	if req.BatchComplete != nil {
+		prefs.BatchComplete = *req.BatchComplete
+	}
+	if req.WeeklyDigest != nil {
+		prefs.WeeklyDigest = *req.WeeklyDigest
+	}
+	if err := a.db.SaveNotificationPreferences(r.Context(), prefs); err != nil {
+		log.Printf("[Notify] %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	a.audit(r, "update", "notification_preferences", uid, map[string]interface{}{
+		"batch_complete": prefs.BatchComplete, "weekly_digest": prefs.WeeklyDigest,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(notificationPreferencesResponse{NotificationPreferences: prefs, EmailEnabled: a.mailer != nil})
+}
+
+// DeleteNotificationPreferencesHandler removes the caller's settings, which
+// stops all their emails. Reports already queued for their uploads are still
+// sent.
+//
+//	DELETE /api/notifications/preferences
+func (a *API) DeleteNotificationPreferencesHandler(w http.ResponseWriter, r *http.Request) {
+	uid := notificationUser(w, r)
+	if uid == "" {
+		return
+	}
+	deleted, err := a.db.DeleteNotificationPreferences(r.Context(), uid)
+	if err != nil {
+		log.Printf("[Notify] %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	if deleted {
+		a.audit(r, "delete", "notification_preferences", uid, nil)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ─── Worker ──────────────────────────────────────────────────────────────────
+
+// notificationWorker emails the reports of finished bulk uploads and the
+// weekly digests that are due.
+func (a *API) notificationWorker() {
+	log.Println("[Notify] Started")
+	ticker := time.NewTicker(notifyPollInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx := context.Background()
+		a.sendBatchReports(ctx)
+		a.sendDueDigests(ctx)
+	}
+}
+
+// sendBatchReports emails the report of every queued bulk upload whose jobs
+// have all completed or failed, or that has waited batchReportMaxWait.
+func (a *API) sendBatchReports(ctx context.Context) {
+	pending, err := a.db.ListPendingBatchNotifications(ctx, batchReportMaxAttempts)
+	if err != nil {
+		log.Printf("[Notify] %v", err)
+		return
+	}
+	for _, n := range pending {
+		outcomes, err := a.db.GetBatchJobOutcomes(ctx, n.JobIDs)
+		if err != nil {
+			log.Printf("[Notify] batch %s: %v", n.BatchID, err)
+			continue
+		}
+		report := &notify.BatchReport{
+			BatchID:    n.BatchID,
+			UploadedAt: n.CreatedAt,
+			Files:      n.Files,
+			Duplicates: n.Duplicates,
+		}
+		for _, f := range n.UploadFailures {
+			report.Failures = append(report.Failures, notify.BatchFailure{Filename: f.Filename, Reason: f.Reason})
+		}
+		for _, o := range outcomes {
+			switch o.Status {
+			case "completed":
+				report.Processed++
+			case "failed":
+				reason := o.Error
+				if reason == "" {
+					reason = "processing failed"
+				}
+				report.Failures = append(report.Failures, notify.BatchFailure{Filename: o.Filename, Reason: reason})
+			default:
+				report.Unfinished++
+			}
+		}
+		if report.Unfinished > 0 && time.Since(n.CreatedAt) < batchReportMaxWait {
+			continue
+		}
+
+		msg, err := notify.BatchReportMessage(n.Email, report)
+		if err == nil {
+			sendCtx, cancel := context.WithTimeout(ctx, time.Minute)
+			err = a.mailer.Send(sendCtx, msg)
+			cancel()
+		}
+		if err != nil {
+			log.Printf("[Notify] batch %s report to %s (attempt %d/%d): %v", n.BatchID, n.Email, n.Attempts+1, batchReportMaxAttempts, err)
+			if err := a.db.RecordBatchNotificationFailure(ctx, n.BatchID); err != nil {
+				log.Printf("[Notify] %v", err)
+			}
+			continue
+		}
+		if err := a.db.MarkBatchNotificationSent(ctx, n.BatchID); err != nil {
+			log.Printf("[Notify] %v", err)
+		}
+		log.Printf("[Notify] batch %s report sent (processed=%d failed=%d unfinished=%d)",
+			n.BatchID, report.Processed, len(report.Failures), report.Unfinished)
+	}
+}
+
+// sendDueDigests emails the weekly digest to every user whose last one is
+// digestInterval old. A week without new candidates sends nothing but
+// still counts as the week's digest.
+func (a *API) sendDueDigests(ctx context.Context) {
+	due, err := a.db.ListDueDigests(ctx, digestInterval)
+	if err != nil {
+		log.Printf("[Notify] %v", err)
+		return
+	}
+	until := time.Now()
+	since := until.Add(-digestInterval)
+	digests := map[int]*storage.WeeklyDigest{} // per organization, for this pass
+	for _, p := range due {
+		d, ok := digests[p.OrgID]
+		if !ok {
+			d, err = a.db.GetWeeklyDigest(tenant.WithOrg(ctx, p.OrgID), since, digestListLimit)
+			if err != nil {
+				log.Printf("[Notify] org %d digest: %v", p.OrgID, err)
+				continue
+			}
+			digests[p.OrgID] = d
+		}
+
+		if d.NewCandidates > 0 {
+			digest := &notify.Digest{
+				OrgName:       p.OrgName,
+				Since:         since,
+				Until:         until,
+				NewCandidates: d.NewCandidates,
+			}
+			for _, c := range d.Newest {
+				digest.Candidates = append(digest.Candidates, notify.DigestCandidate{Name: c.Name, CurrentPosition: c.CurrentPosition})
+			}
+			for _, s := range d.TrendingSkills {
+				digest.TrendingSkills = append(digest.TrendingSkills, notify.DigestSkill{Name: s.Skill, Count: s.Count})
+			}
+			msg, err := notify.DigestMessage(p.Email, digest)
+			if err == nil {
+				sendCtx, cancel := context.WithTimeout(ctx, time.Minute)
+				err = a.mailer.Send(sendCtx, msg)
+				cancel()
+			}
+			if err != nil {
+				// Retried on the next pass.
+				log.Printf("[Notify] digest to %s (org %d): %v", p.Email, p.OrgID, err)
+				continue
+			}
+		}
+		if err := a.db.MarkDigestSent(ctx, p.OrgID, p.UserID); err != nil {
+			log.Printf("[Notify] %v", err)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"cv-search/internal/openapi"
+	"cv-search/internal/storage"
+)
+
+// The OpenAPI 3 document is generated from the route tables (apiRoutes, one
+// per area in openapi_*.go) and the request/response types the handlers
+// decode and encode, so a field added to a response struct shows up in the
+// spec without further edits. A route added to NewRouter needs an entry in
+// its area's table too.
+
+// Security scheme names used in the document.
+const (
+	orgKeyScheme   = "orgKey"
+	adminKeyScheme = "adminKey"
+)
+
+// specErrors are the usual error statuses of an endpoint addressing one
+// record by ID.
+var specErrors = []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError}
+
+// jobStatusLink follows a 202 (or a queued file) to polling its job.
+var jobStatusLink = map[string]openapi.Link{
+	"GetJobStatus": {
+		OperationID: "getJobStatus",
+		Parameters:  map[string]string{"job_id": "$response.body#/job_id"},
+		Description: "Poll the extraction job until its status is completed or failed.",
+	},
+}
+
+var batchStatusLink = map[string]openapi.Link{
+	"GetBatchStatus": {
+		OperationID: "getBatchStatus",
+		Parameters:  map[string]string{"batch_id": "$response.body#/batch_id"},
+		Description: "Follow every queued file of the batch.",
+	},
+}
+
+// retryAfterHeader and queueBusyResp describe the upload endpoints'
+// backpressure (CV_QUEUE_REJECT_PERCENT); the quota responses the uploads and
+// searches held to an organization's quotas (QUOTA_*).
+var (
+	retryAfterHeader = map[string]openapi.Header{
+		"Retry-After": {Description: "Seconds to wait before retrying", Schema: &openapi.Schema{Type: "integer"}},
+	}
+	queueBusyResp = openapi.Resp{
+		Status: http.StatusTooManyRequests, Description: "The processing queue is too full, or the daily upload quota is used up; retry after Retry-After seconds",
+		Body: openapi.OneOf{queueBusyResponse{}, quotaExceededResponse{}}, Headers: retryAfterHeader,
+	}
+	quotaTokensResp = openapi.Resp{
+		Status: http.StatusPaymentRequired, Description: "The month's LLM token quota is used up; Retry-After is the start of next month",
+		Body: quotaExceededResponse{}, Headers: retryAfterHeader,
+	}
+	searchQuotaResps = []openapi.Resp{
+		{Status: http.StatusTooManyRequests, Description: "The daily search quota is used up; retry after Retry-After seconds",
+			Body: quotaExceededResponse{}, Headers: retryAfterHeader},
+		quotaTokensResp,
+	}
+)
+
+// cachedHeaders and notModifiedResp describe the responses served through
+// the response cache (RESPONSE_CACHE).
+var (
+	cachedHeaders = map[string]openapi.Header{
+		"ETag":          {Description: "Send as If-None-Match to get 304 while the response is unchanged", Schema: &openapi.Schema{Type: "string"}},
+		"Cache-Control": {Description: "private, max-age=RESPONSE_CACHE_TTL_SECONDS", Schema: &openapi.Schema{Type: "string"}},
+		"X-Cache":       {Description: "HIT when served from the response cache, else MISS", Schema: &openapi.Schema{Type: "string"}},
+	}
+	notModifiedResp = openapi.Resp{Status: http.StatusNotModified, Description: "Unchanged since the If-None-Match ETag"}
+)
+
+var (
+	limitParam  = openapi.Query("limit", "integer", "Max results")
+	offsetParam = openapi.Query("offset", "integer", "Offset for pagination")
+	candidateID = openapi.Path("id", "integer", "Candidate ID")
+	poolID      = openapi.Path("id", "integer", "Talent pool ID")
+	orgID       = openapi.Path("id", "integer", "Organization ID")
+	cvFileID    = openapi.Path("id", "integer", "CV file ID")
+
+	communityPatternID = openapi.Path("pattern", "string", "Community pattern ID, e.g. backend (lowercase letters, digits, - and _)")
+
+	// idempotencyKeyParam is accepted by every POST, PUT, PATCH and DELETE
+	// (idempotencyMiddleware).
+	idempotencyKeyParam = openapi.HeaderParam("Idempotency-Key",
+		"Unique per operation (1-255 printable ASCII characters): a retry with the same key and request gets the "+
+			"first response back, with Idempotent-Replayed: true, instead of running again (IDEMPOTENCY_KEY_TTL_HOURS)")
+
+	userIDHeader = openapi.Parameter{
+		Name: "X-User-ID", In: "header", Required: true, Schema: &openapi.Schema{Type: "string"},
+		Description: "The caller, whose preferences these are",
+	}
+)
+
+// apiRoutes lists every endpoint of NewRouter.
+func apiRoutes() []openapi.Route {
+	return slices.Concat(
+		cvRoutes(),
+		searchRoutes(),
+		graphRAGRoutes(),
+		graphRoutes(),
+		candidateRoutes(),
+		poolRoutes(),
+		notificationRoutes(),
+		experimentRoutes(),
+		adminRoutes(),
+		opsRoutes(),
+	)
+}
+
+// opsRoutes lists the health, metrics and spec endpoints.
+func opsRoutes() []openapi.Route {
+	return []openapi.Route{
+		{
+			Method: "GET", Path: "/health", OperationID: "health", Tag: "ops",
+			Summary:   "Liveness check",
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: healthResponse{}}},
+			Public:    true,
+		},
+		{
+			Method: "GET", Path: "/metrics", OperationID: "metrics", Tag: "ops",
+			Summary:   "Prometheus metrics",
+			Responses: []openapi.Resp{{Status: http.StatusOK, ContentType: "text/plain"}},
+			Public:    true,
+		},
+		{
+			Method: "GET", Path: "/openapi.json", OperationID: "openapi", Tag: "ops",
+			Summary:   "This document",
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: map[string]interface{}{}}},
+			Public:    true,
+		},
+	}
+}
+
+type healthResponse struct {
+	Status string `json:"status"` // healthy
+}
+
+// OpenAPISpec builds the OpenAPI 3 document of every endpoint.
+func OpenAPISpec() *openapi.Document {
+	b := openapi.NewBuilder(openapi.Info{
+		Title:   "CV Search & GraphRAG API",
+		Version: "2.0",
+		Description: "AI-powered CV search with GraphRAG and hybrid search.\n\n" +
+			"Every 4xx/5xx response is an ErrorResponse. /api/ requests act for the organization of their X-API-Key " +
+			"(or Authorization: Bearer) and fall back to the default organization unless REQUIRE_ORG_KEY is set. " +
+			"CV extraction is asynchronous: uploads answer 202 with a job_id to poll at /api/cv/job/{job_id}. " +
+			"POST, PUT, PATCH and DELETE requests may carry an Idempotency-Key header to be retried safely.",
+	})
+	b.AddServer("/", "This deployment")
+	b.AddSecurityScheme(orgKeyScheme, openapi.SecurityScheme{
+		Type: "apiKey", In: "header", Name: "X-API-Key",
+		Description: "Organization API key; required with REQUIRE_ORG_KEY",
+	})
+	b.AddSecurityScheme(adminKeyScheme, openapi.SecurityScheme{
+		Type: "apiKey", In: "header", Name: "X-Admin-Key",
+		Description: "ADMIN_API_KEY; required on /api/admin/ when set",
+	})
+	b.SetDefaultSecurity(orgKeyScheme)
+	b.SetErrorBody(ErrorResponse{})
+	b.Schema(SearchProgressEvent{})          // data of /api/search/hybrid/stream's progress events
+	b.Schema(GraphRAGSummaryEvent{})         // data of /api/graphrag/search/stream's summary event
+	b.Schema(storage.SearchFeedbackExport{}) // a line of /api/search/feedback/export
+	for _, tag := range [][2]string{
+		{"cv", "CV upload, asynchronous extraction and files"},
+		{"search", "Structured, hybrid, conversational and GraphQL search"},
+		{"graphrag", "GraphRAG search, embeddings and communities"},
+		{"graph", "Knowledge graph statistics"},
+		{"candidates", "Candidates, interviews, notes, tags, merges and ATS push"},
+		{"pools", "Talent pools with pipeline stages"},
+		{"notifications", "Email notification preferences"},
+		{"experiments", "Search ranking experiments"},
+		{"admin", "Audit log, dashboards, organizations"},
+		{"ops", "Health, metrics and this spec"},
+	} {
+		b.AddTag(tag[0], tag[1])
+	}
+
+	for _, r := range apiRoutes() {
+		if strings.HasPrefix(r.Path, "/api/") {
+			// Missing organization key (REQUIRE_ORG_KEY) or admin key
+			r.Errors = append(r.Errors, http.StatusUnauthorized)
+		}
+		switch r.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			// Idempotency-Key still in progress (409) or used for another request (422)
+			r.Params = append(r.Params, idempotencyKeyParam)
+			r.Errors = append(r.Errors, http.StatusConflict, http.StatusUnprocessableEntity)
+		}
+		b.Add(r)
+	}
+	return b.Document()
+}
+
+// openAPIJSON is the encoded OpenAPISpec, built on first request.
+var openAPIJSON = sync.OnceValues(func() ([]byte, error) {
+	return json.MarshalIndent(OpenAPISpec(), "", "  ")
+})
+
+// OpenAPIHandler serves the OpenAPI 3 document, also read by the Swagger UI.
+//
+//	GET /openapi.json
+func (a *API) OpenAPIHandler(w http.ResponseWriter, r *http.Request) {
+	data, err := openAPIJSON()
+	if err != nil {
+		log.Printf("[OpenAPI] encode spec: %v", err)
+		http.Error(w, "failed to build OpenAPI document", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
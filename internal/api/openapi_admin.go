@@ -0,0 +1,230 @@
+package api
+
+import (
+	"net/http"
+
+	"cv-search/internal/openapi"
+	"cv-search/internal/storage"
+)
+
+// notificationRoutes lists the notification preference endpoints.
+func notificationRoutes() []openapi.Route {
+	return []openapi.Route{
+		{
+			Method: "GET", Path: "/api/notifications/preferences", OperationID: "getNotificationPreferences", Tag: "notifications",
+			Summary:   "The caller's email notification preferences",
+			Params:    []openapi.Parameter{userIDHeader},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: notificationPreferencesResponse{}}},
+			Errors:    []int{http.StatusBadRequest, http.StatusInternalServerError},
+		},
+		{
+			Method: "PUT", Path: "/api/notifications/preferences", OperationID: "putNotificationPreferences", Tag: "notifications",
+			Summary:   "Set the caller's email notification preferences",
+			Params:    []openapi.Parameter{userIDHeader},
+			Body:      notificationPreferencesRequest{},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: notificationPreferencesResponse{}}},
+			Errors:    []int{http.StatusBadRequest, http.StatusInternalServerError},
+		},
+		{
+			Method: "DELETE", Path: "/api/notifications/preferences", OperationID: "deleteNotificationPreferences", Tag: "notifications",
+			Summary:   "Stop the caller's email notifications",
+			Params:    []openapi.Parameter{userIDHeader},
+			Responses: []openapi.Resp{{Status: http.StatusNoContent}},
+			Errors:    []int{http.StatusBadRequest, http.StatusInternalServerError},
+		},
+	}
+}
+
+// experimentRoutes lists the search experiment endpoints.
+func experimentRoutes() []openapi.Route {
+	return []openapi.Route{
+		{
+			Method: "GET", Path: "/api/experiments", OperationID: "listExperiments", Tag: "experiments",
+			Summary:   "List search experiments",
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: []storage.SearchExperiment{}}},
+			Errors:    []int{http.StatusInternalServerError},
+		},
+		{
+			Method: "POST", Path: "/api/experiments", OperationID: "upsertExperiment", Tag: "experiments",
+			Summary:   "Create or update a search experiment",
+			Body:      experimentRequest{},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: storage.SearchExperiment{}}},
+			Errors:    []int{http.StatusBadRequest, http.StatusInternalServerError},
+		},
+	}
+}
+
+// adminRoutes lists the usage and admin endpoints.
+func adminRoutes() []openapi.Route {
+	return []openapi.Route{
+		{
+			Method: "GET", Path: "/api/usage", OperationID: "getUsage", Tag: "admin",
+			Summary: "The organization's uploads, searches and LLM tokens against its quotas",
+			Description: "Uploads and searches count per UTC day, LLM tokens per UTC month. Past soft_limit (QUOTA_SOFT_PERCENT) " +
+				"responses carry an X-Quota-Warning header; over a daily quota uploads and searches answer 429, " +
+				"with the month's LLM tokens used up 402.",
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: UsageResponse{}}},
+			Errors:    []int{http.StatusInternalServerError},
+		},
+		{
+			Method: "GET", Path: "/api/admin/audit-log", OperationID: "listAuditLog", Tag: "admin",
+			Summary: "Audit trail of data mutations, newest first",
+			Params: []openapi.Parameter{
+				openapi.Query("actor", "string", ""), openapi.Query("action", "string", ""),
+				openapi.Query("entity_type", "string", ""), openapi.Query("entity_id", "string", ""),
+				openapi.Query("since", "string", "RFC 3339"), openapi.Query("until", "string", "RFC 3339"),
+				openapi.Query("limit", "integer", "Max results (default 100, max 1000)"), offsetParam,
+			},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: auditLogResponse{}}},
+			Errors:    []int{http.StatusBadRequest, http.StatusInternalServerError},
+			Security:  []string{orgKeyScheme, adminKeyScheme},
+		},
+		{
+			Method: "POST", Path: "/api/admin/stats/refresh", OperationID: "refreshStats", Tag: "admin",
+			Summary:   "Rebuild the statistics views now",
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: refreshStatsResponse{}}},
+			Errors:    []int{http.StatusInternalServerError},
+			Security:  []string{orgKeyScheme, adminKeyScheme},
+		},
+		{
+			Method: "GET", Path: "/api/admin/overview", OperationID: "adminOverview", Tag: "admin",
+			Summary:   "Dashboard figures: uploads, errors, LLM spend, queues",
+			Params:    []openapi.Parameter{openapi.Query("failures", "integer", "Recent failed jobs to list (default 10, max 100)")},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: adminOverviewResponse{}}},
+			Errors:    []int{http.StatusInternalServerError},
+			Security:  []string{orgKeyScheme, adminKeyScheme},
+		},
+		{
+			Method: "GET", Path: "/api/admin/queues", OperationID: "listQueues", Tag: "admin",
+			Summary:   "Background queue gauges",
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: queuesResponse{}}},
+			Security:  []string{orgKeyScheme, adminKeyScheme},
+		},
+		{
+			Method: "GET", Path: "/api/admin/orgs", OperationID: "listOrganizations", Tag: "admin",
+			Summary:   "List organizations",
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: listOrganizationsResponse{}}},
+			Errors:    []int{http.StatusForbidden, http.StatusInternalServerError},
+			Security:  []string{adminKeyScheme},
+		},
+		{
+			Method: "POST", Path: "/api/admin/orgs", OperationID: "createOrganization", Tag: "admin",
+			Summary:     "Create an organization",
+			Description: "The API key is only ever returned here and by rotateOrganizationKey.",
+			Body:        createOrganizationRequest{},
+			Responses:   []openapi.Resp{{Status: http.StatusCreated, Body: organizationKeyResponse{}}},
+			Errors:      []int{http.StatusBadRequest, http.StatusForbidden, http.StatusConflict, http.StatusInternalServerError},
+			Security:    []string{adminKeyScheme},
+		},
+		{
+			Method: "POST", Path: "/api/admin/orgs/{id}/key", OperationID: "rotateOrganizationKey", Tag: "admin",
+			Summary:   "Issue a new API key; the old one stops working",
+			Params:    []openapi.Parameter{orgID},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: rotateOrganizationKeyResponse{}}},
+			Errors:    []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusInternalServerError},
+			Security:  []string{adminKeyScheme},
+		},
+		{
+			Method: "GET", Path: "/api/admin/orgs/{id}/ai-settings", OperationID: "getOrgAISettings", Tag: "admin",
+			Summary:   "An organization's own LLM and embedding provider",
+			Params:    []openapi.Parameter{orgID},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: orgAISettingsResponse{}}},
+			Errors:    []int{http.StatusBadRequest, http.StatusForbidden, http.StatusInternalServerError},
+			Security:  []string{adminKeyScheme},
+		},
+		{
+			Method: "PUT", Path: "/api/admin/orgs/{id}/ai-settings", OperationID: "putOrgAISettings", Tag: "admin",
+			Summary:   "Set an organization's LLM and embedding provider",
+			Params:    []openapi.Parameter{orgID},
+			Body:      orgAISettingsRequest{},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: orgAISettingsResponse{}}},
+			Errors:    []int{http.StatusBadRequest, http.StatusForbidden, http.StatusInternalServerError},
+			Security:  []string{adminKeyScheme},
+		},
+		{
+			Method: "DELETE", Path: "/api/admin/orgs/{id}/ai-settings", OperationID: "deleteOrgAISettings", Tag: "admin",
+			Summary:   "Go back to the deployment's providers",
+			Params:    []openapi.Parameter{orgID},
+			Responses: []openapi.Resp{{Status: http.StatusNoContent}},
+			Errors:    []int{http.StatusBadRequest, http.StatusForbidden, http.StatusInternalServerError},
+			Security:  []string{adminKeyScheme},
+		},
+		{
+			Method: "GET", Path: "/api/admin/orgs/{id}/quotas", OperationID: "getOrgQuotas", Tag: "admin",
+			Summary:   "An organization's own quotas and the ones it is held to",
+			Params:    []openapi.Parameter{orgID},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: orgQuotasResponse{}}},
+			Errors:    []int{http.StatusBadRequest, http.StatusForbidden, http.StatusInternalServerError},
+			Security:  []string{adminKeyScheme},
+		},
+		{
+			Method: "PUT", Path: "/api/admin/orgs/{id}/quotas", OperationID: "putOrgQuotas", Tag: "admin",
+			Summary:     "Set an organization's quotas",
+			Description: "A null limit keeps the deployment's default (QUOTA_*), 0 is unlimited.",
+			Params:      []openapi.Parameter{orgID},
+			Body:        orgQuotasRequest{},
+			Responses:   []openapi.Resp{{Status: http.StatusOK, Body: orgQuotasResponse{}}},
+			Errors:      []int{http.StatusBadRequest, http.StatusForbidden, http.StatusInternalServerError},
+			Security:    []string{adminKeyScheme},
+		},
+		{
+			Method: "DELETE", Path: "/api/admin/orgs/{id}/quotas", OperationID: "deleteOrgQuotas", Tag: "admin",
+			Summary:   "Go back to the deployment's quotas",
+			Params:    []openapi.Parameter{orgID},
+			Responses: []openapi.Resp{{Status: http.StatusNoContent}},
+			Errors:    []int{http.StatusBadRequest, http.StatusForbidden, http.StatusInternalServerError},
+			Security:  []string{adminKeyScheme},
+		},
+		{
+			Method: "GET", Path: "/api/admin/orgs/{id}/integrations", OperationID: "listOrgIntegrations", Tag: "admin",
+			Summary:   "An organization's ATS integrations (without keys)",
+			Params:    []openapi.Parameter{orgID},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: orgIntegrationsResponse{}}},
+			Errors:    []int{http.StatusBadRequest, http.StatusForbidden, http.StatusInternalServerError},
+			Security:  []string{adminKeyScheme},
+		},
+		{
+			Method: "PUT", Path: "/api/admin/orgs/{id}/integrations/{target}", OperationID: "putOrgIntegration", Tag: "admin",
+			Summary:   "Set an organization's credentials for one ATS",
+			Params:    []openapi.Parameter{orgID, openapi.Path("target", "string", "greenhouse or lever")},
+			Body:      orgIntegrationRequest{},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: orgIntegrationResponse{}}},
+			Errors:    []int{http.StatusBadRequest, http.StatusForbidden, http.StatusInternalServerError},
+			Security:  []string{adminKeyScheme},
+		},
+		{
+			Method: "DELETE", Path: "/api/admin/orgs/{id}/integrations/{target}", OperationID: "deleteOrgIntegration", Tag: "admin",
+			Summary:   "Remove an organization's ATS integration",
+			Params:    []openapi.Parameter{orgID, openapi.Path("target", "string", "greenhouse or lever")},
+			Responses: []openapi.Resp{{Status: http.StatusNoContent}},
+			Errors:    []int{http.StatusBadRequest, http.StatusForbidden, http.StatusInternalServerError},
+			Security:  []string{adminKeyScheme},
+		},
+		{
+			Method: "GET", Path: "/api/admin/orgs/{id}/community-patterns", OperationID: "listOrgCommunityPatterns", Tag: "admin",
+			Summary:   "The community patterns an organization's searches use",
+			Params:    []openapi.Parameter{orgID},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: communityPatternsResponse{}}},
+			Errors:    []int{http.StatusBadRequest, http.StatusForbidden, http.StatusInternalServerError},
+			Security:  []string{adminKeyScheme},
+		},
+		{
+			Method: "PUT", Path: "/api/admin/orgs/{id}/community-patterns/{pattern}", OperationID: "putOrgCommunityPattern", Tag: "admin",
+			Summary:     "Create or replace one of an organization's community patterns",
+			Description: "A default's ID replaces that default for the organization.",
+			Params:      []openapi.Parameter{orgID, communityPatternID},
+			Body:        communityPatternRequest{},
+			Responses:   []openapi.Resp{{Status: http.StatusOK, Body: communityPatternResponse{}}},
+			Errors:      []int{http.StatusBadRequest, http.StatusForbidden, http.StatusInternalServerError},
+			Security:    []string{adminKeyScheme},
+		},
+		{
+			Method: "DELETE", Path: "/api/admin/orgs/{id}/community-patterns/{pattern}", OperationID: "deleteOrgCommunityPattern", Tag: "admin",
+			Summary:   "Remove one of an organization's community patterns",
+			Params:    []openapi.Parameter{orgID, communityPatternID},
+			Responses: []openapi.Resp{{Status: http.StatusNoContent}},
+			Errors:    []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusInternalServerError},
+			Security:  []string{adminKeyScheme},
+		},
+	}
+}
@@ -0,0 +1,341 @@
+package api
+
+import (
+	"net/http"
+
+	"cv-search/internal/openapi"
+	"cv-search/internal/storage"
+)
+
+// candidateRoutes lists the candidate endpoints, with their interviews, notes, tags, merges, share links and ATS push.
+func candidateRoutes() []openapi.Route {
+	return []openapi.Route{
+		{
+			Method: "GET", Path: "/api/candidates", OperationID: "listCandidates", Tag: "candidates",
+			Summary: "List candidates, newest first",
+			Params: []openapi.Parameter{
+				openapi.Query("incomplete", "boolean", "Only candidates whose profile fails a completeness check"),
+				openapi.Query("missing", "string", "Only candidates missing this: current_position, education, skill_years or location"),
+				openapi.Query("status", "string", "Only candidates of this status: active, hired, archived or do_not_contact"),
+				limitParam, offsetParam,
+			},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: listCandidatesResponse{}}},
+			Errors:    []int{http.StatusBadRequest, http.StatusInternalServerError},
+		},
+		{
+			Method: "GET", Path: "/api/candidates/{id}", OperationID: "getCandidate", Tag: "candidates",
+			Summary:   "Get a candidate with skills and interviews",
+			Params:    []openapi.Parameter{candidateID},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: storage.CandidateDetail{}}},
+			Errors:    specErrors,
+		},
+		{
+			Method: "DELETE", Path: "/api/candidates/{id}", OperationID: "deleteCandidate", Tag: "candidates",
+			Summary:   "Soft-delete a candidate",
+			Params:    []openapi.Parameter{candidateID},
+			Responses: []openapi.Resp{{Status: http.StatusNoContent}},
+			Errors:    specErrors,
+		},
+		{
+			Method: "POST", Path: "/api/candidates/{id}/erase", OperationID: "eraseCandidate", Tag: "candidates",
+			Summary:   "GDPR erasure of a candidate's personal data",
+			Params:    []openapi.Parameter{candidateID},
+			Body:      eraseCandidateRequest{},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: eraseCandidateResponse{}}},
+			Errors:    specErrors,
+		},
+		{
+			Method: "GET", Path: "/api/candidates/{id}/similar", OperationID: "similarCandidates", Tag: "candidates",
+			Summary: "Candidates similar to this one",
+			Params: []openapi.Parameter{
+				openapi.Path("id", "string", "Candidate ID or person node ID (person_12)"),
+				openapi.Query("top_k", "integer", "Max results (default 5, max 20)"),
+				openapi.Query("include_statuses", "string", "Also return candidates of these statuses, comma-separated: hired, archived, do_not_contact"),
+			},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: similarCandidatesResponse{}}},
+			Errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError, http.StatusServiceUnavailable},
+		},
+		{
+			Method: "POST", Path: "/api/candidates/{id}/gap-analysis", OperationID: "gapAnalysis", Tag: "candidates",
+			Summary: "Hold a candidate against a job description",
+			Description: "The LLM reads the job description's required and preferred skills; the candidate's graph decides " +
+				"which they have and which are missing. A second LLM call names the candidate's skills that carry over to " +
+				"missing ones and writes a development summary; if it fails the response has a warning instead. " +
+				"Counts as a search against the searches quota.",
+			Params: []openapi.Parameter{candidateID},
+			Body:   gapAnalysisRequest{},
+			Responses: append([]openapi.Resp{
+				{Status: http.StatusOK, Body: gapAnalysisResponse{}},
+			}, searchQuotaResps...),
+			Errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError, http.StatusServiceUnavailable},
+		},
+		{
+			Method: "POST", Path: "/api/candidates/{id}/interview-kit", OperationID: "interviewKit", Tag: "candidates",
+			Summary: "Write interview questions for a candidate",
+			Description: "Technical and behavioral questions grounded in the candidate's projects, companies and skills, each " +
+				"naming what it is about and, where it applies, quoting the CV (quotes the CV doesn't contain are dropped, " +
+				"with a warning). An optional job description tailors them to the role. Counts as a search against the searches quota.",
+			Params: []openapi.Parameter{candidateID},
+			Body:   interviewKitRequest{},
+			Responses: append([]openapi.Resp{
+				{Status: http.StatusOK, Body: interviewKitResponse{}},
+			}, searchQuotaResps...),
+			Errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError, http.StatusServiceUnavailable},
+		},
+		{
+			Method: "POST", Path: "/api/candidates/{id}/outreach", OperationID: "draftOutreach", Tag: "candidates",
+			Summary: "Draft an outreach message to a candidate",
+			Description: "A personalized email (with subject) or LinkedIn message about a role, written from the candidate's " +
+				"background and the match reasoning, e.g. a search result's. channel, tone and language (en, tr) default " +
+				"to email, professional and en. Nothing is sent. Counts as a search against the searches quota.",
+			Params: []openapi.Parameter{candidateID},
+			Body:   outreachRequest{},
+			Responses: append([]openapi.Resp{
+				{Status: http.StatusOK, Body: outreachResponse{}},
+			}, searchQuotaResps...),
+			Errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError, http.StatusServiceUnavailable},
+		},
+		{
+			Method: "POST", Path: "/api/candidates/merge", OperationID: "mergeCandidates", Tag: "candidates",
+			Summary:   "Merge a duplicate candidate into another",
+			Body:      mergeCandidatesRequest{},
+			Responses: []openapi.Resp{{Status: http.StatusCreated, Body: storage.CandidateMerge{}}},
+			Errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError},
+		},
+		{
+			Method: "POST", Path: "/api/candidates/merges/{id}/undo", OperationID: "undoCandidateMerge", Tag: "candidates",
+			Summary:   "Undo a merge",
+			Params:    []openapi.Parameter{openapi.Path("id", "integer", "Merge ID")},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: storage.CandidateMerge{}}},
+			Errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError},
+		},
+		{
+			Method: "GET", Path: "/api/candidates/{id}/merges", OperationID: "listCandidateMerges", Tag: "candidates",
+			Summary:   "Merges involving a candidate",
+			Params:    []openapi.Parameter{candidateID},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: listCandidateMergesResponse{}}},
+			Errors:    []int{http.StatusBadRequest, http.StatusInternalServerError},
+		},
+		{
+			Method: "POST", Path: "/api/candidates/import", OperationID: "importCandidates", Tag: "candidates",
+			Summary:     "Import candidates from another ATS's CSV or JSON export",
+			Description: "The export is the request body or a multipart file field. Resumes behind resume_url are queued like uploads; follow them with getBatchStatus.",
+			Params: []openapi.Parameter{
+				openapi.Query("source", "string", "Source label (default import)"),
+				openapi.Query("format", "string", "csv or json (default detected)"),
+				openapi.Query("dry_run", "boolean", "Validate only; answers 200 without importing"),
+			},
+			Form:    []openapi.FormField{{Name: "file", Type: "file", Required: true, Description: "CSV or JSON export"}},
+			RawBody: []string{"text/csv"},
+			Responses: []openapi.Resp{
+				{Status: http.StatusMultiStatus, Description: "Per-row results", Body: importResponse{}, Links: batchStatusLink},
+				{Status: http.StatusOK, Description: "Dry run", Body: importDryRunResponse{}},
+			},
+			Errors: []int{http.StatusBadRequest},
+		},
+		{
+			Method: "POST", Path: "/api/candidates/{id}/interviews", OperationID: "createInterview", Tag: "candidates",
+			Summary:   "Add an interview",
+			Params:    []openapi.Parameter{candidateID},
+			Body:      interviewRequest{},
+			Responses: []openapi.Resp{{Status: http.StatusCreated, Body: createInterviewResponse{}}},
+			Errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusInternalServerError},
+		},
+		{
+			Method: "PUT", Path: "/api/candidates/{id}/interviews/{iid}", OperationID: "updateInterview", Tag: "candidates",
+			Summary:   "Update an interview",
+			Params:    []openapi.Parameter{candidateID, openapi.Path("iid", "integer", "Interview ID")},
+			Body:      interviewRequest{},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: messageResponse{}}},
+			Errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusInternalServerError},
+		},
+		{
+			Method: "DELETE", Path: "/api/candidates/{id}/interviews/{iid}", OperationID: "deleteInterview", Tag: "candidates",
+			Summary:   "Delete an interview",
+			Params:    []openapi.Parameter{candidateID, openapi.Path("iid", "integer", "Interview ID")},
+			Responses: []openapi.Resp{{Status: http.StatusNoContent}},
+			Errors:    specErrors,
+		},
+		{
+			Method: "GET", Path: "/api/candidates/{id}/notes", OperationID: "listCandidateNotes", Tag: "candidates",
+			Summary:   "List a candidate's notes",
+			Params:    []openapi.Parameter{candidateID},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: candidateNotesResponse{}}},
+			Errors:    specErrors,
+		},
+		{
+			Method: "POST", Path: "/api/candidates/{id}/notes", OperationID: "createCandidateNote", Tag: "candidates",
+			Summary:   "Add a note",
+			Params:    []openapi.Parameter{candidateID},
+			Body:      candidateNoteRequest{},
+			Responses: []openapi.Resp{{Status: http.StatusCreated, Body: storage.CandidateNote{}}},
+			Errors:    specErrors,
+		},
+		{
+			Method: "PUT", Path: "/api/candidates/{id}/notes/{nid}", OperationID: "updateCandidateNote", Tag: "candidates",
+			Summary:   "Edit a note",
+			Params:    []openapi.Parameter{candidateID, openapi.Path("nid", "integer", "Note ID")},
+			Body:      candidateNoteRequest{},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: storage.CandidateNote{}}},
+			Errors:    specErrors,
+		},
+		{
+			Method: "DELETE", Path: "/api/candidates/{id}/notes/{nid}", OperationID: "deleteCandidateNote", Tag: "candidates",
+			Summary:   "Delete a note",
+			Params:    []openapi.Parameter{candidateID, openapi.Path("nid", "integer", "Note ID")},
+			Responses: []openapi.Resp{{Status: http.StatusNoContent}},
+			Errors:    specErrors,
+		},
+		{
+			Method: "GET", Path: "/api/candidates/{id}/tags", OperationID: "listCandidateTags", Tag: "candidates",
+			Summary:   "List a candidate's tags",
+			Params:    []openapi.Parameter{candidateID},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: candidateTagsResponse{}}},
+			Errors:    specErrors,
+		},
+		{
+			Method: "POST", Path: "/api/candidates/{id}/tags", OperationID: "addCandidateTags", Tag: "candidates",
+			Summary:   "Tag a candidate",
+			Params:    []openapi.Parameter{candidateID},
+			Body:      candidateTagsRequest{},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: candidateTagsResponse{}}},
+			Errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusInternalServerError},
+		},
+		{
+			Method: "DELETE", Path: "/api/candidates/{id}/tags/{tag}", OperationID: "removeCandidateTag", Tag: "candidates",
+			Summary:   "Remove a tag",
+			Params:    []openapi.Parameter{candidateID},
+			Responses: []openapi.Resp{{Status: http.StatusNoContent}},
+			Errors:    specErrors,
+		},
+		{
+			Method: "PUT", Path: "/api/candidates/{id}/status", OperationID: "setCandidateStatus", Tag: "candidates",
+			Summary:     "Set a candidate's status",
+			Description: "Hired, archived and do_not_contact candidates are left out of searches and similar candidates unless the request's include_statuses asks for them. Every change is kept in the status history.",
+			Params:      []openapi.Parameter{candidateID},
+			Body:        candidateStatusRequest{},
+			Responses:   []openapi.Resp{{Status: http.StatusOK, Body: candidateStatusResponse{}}},
+			Errors:      []int{http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusInternalServerError},
+		},
+		{
+			Method: "GET", Path: "/api/candidates/{id}/status-history", OperationID: "listCandidateStatusHistory", Tag: "candidates",
+			Summary:   "A candidate's status changes, newest first",
+			Params:    []openapi.Parameter{candidateID},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: candidateStatusHistoryResponse{}}},
+			Errors:    specErrors,
+		},
+		{
+			Method: "POST", Path: "/api/candidates/{id}/push", OperationID: "pushCandidate", Tag: "candidates",
+			Summary: "Create the candidate in the organization's ATS",
+			Params: []openapi.Parameter{
+				candidateID,
+				openapi.Query("target", "string", "greenhouse or lever"),
+				openapi.Query("force", "boolean", "Push again even if already pushed to the target"),
+			},
+			Body:      pushCandidateRequest{},
+			Responses: []openapi.Resp{{Status: http.StatusCreated, Body: pushCandidateResponse{}}},
+			Errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError, http.StatusBadGateway},
+		},
+		{
+			Method: "GET", Path: "/api/candidates/{id}/share-links", OperationID: "listShareLinks", Tag: "candidates",
+			Summary:   "List a candidate's share links",
+			Params:    []openapi.Parameter{candidateID},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: shareLinksResponse{}}},
+			Errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError, http.StatusServiceUnavailable},
+		},
+		{
+			Method: "POST", Path: "/api/candidates/{id}/share-links", OperationID: "createShareLink", Tag: "candidates",
+			Summary:   "Create an expiring, read-only link to a candidate's profile",
+			Params:    []openapi.Parameter{candidateID},
+			Body:      createShareLinkRequest{},
+			Responses: []openapi.Resp{{Status: http.StatusCreated, Body: shareLinkResponse{}}},
+			Errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError, http.StatusServiceUnavailable},
+		},
+		{
+			Method: "DELETE", Path: "/api/candidates/{id}/share-links/{lid}", OperationID: "revokeShareLink", Tag: "candidates",
+			Summary:   "Revoke a share link",
+			Params:    []openapi.Parameter{candidateID, openapi.Path("lid", "integer", "Share link ID")},
+			Responses: []openapi.Resp{{Status: http.StatusNoContent}},
+			Errors:    specErrors,
+		},
+		{
+			Method: "GET", Path: "/share/{token}", OperationID: "getSharedProfile", Tag: "candidates",
+			Summary:   "A candidate profile behind a share link (no API key; the token is the credential)",
+			Params:    []openapi.Parameter{openapi.Path("token", "string", "Signed share link token")},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: sharedProfileResponse{}}},
+			Errors:    []int{http.StatusNotFound, http.StatusInternalServerError, http.StatusServiceUnavailable},
+			Public:    true,
+		},
+	}
+}
+
+// poolRoutes lists the talent pool endpoints.
+func poolRoutes() []openapi.Route {
+	return []openapi.Route{
+		{
+			Method: "GET", Path: "/api/pools", OperationID: "listTalentPools", Tag: "pools",
+			Summary:   "List talent pools",
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: []storage.TalentPool{}}},
+			Errors:    []int{http.StatusInternalServerError},
+		},
+		{
+			Method: "POST", Path: "/api/pools", OperationID: "createTalentPool", Tag: "pools",
+			Summary:   "Create a talent pool",
+			Body:      talentPoolRequest{},
+			Responses: []openapi.Resp{{Status: http.StatusCreated, Body: storage.TalentPool{}}},
+			Errors:    []int{http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity, http.StatusInternalServerError},
+		},
+		{
+			Method: "GET", Path: "/api/pools/{id}", OperationID: "getTalentPool", Tag: "pools",
+			Summary:   "Get a talent pool with counts per stage",
+			Params:    []openapi.Parameter{poolID},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: storage.TalentPool{}}},
+			Errors:    specErrors,
+		},
+		{
+			Method: "PUT", Path: "/api/pools/{id}", OperationID: "updateTalentPool", Tag: "pools",
+			Summary:   "Rename a talent pool",
+			Params:    []openapi.Parameter{poolID},
+			Body:      talentPoolRequest{},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: storage.TalentPool{}}},
+			Errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity, http.StatusInternalServerError},
+		},
+		{
+			Method: "DELETE", Path: "/api/pools/{id}", OperationID: "deleteTalentPool", Tag: "pools",
+			Summary:   "Delete a talent pool",
+			Params:    []openapi.Parameter{poolID},
+			Responses: []openapi.Resp{{Status: http.StatusNoContent}},
+			Errors:    specErrors,
+		},
+		{
+			Method: "GET", Path: "/api/pools/{id}/candidates", OperationID: "listPoolCandidates", Tag: "pools",
+			Summary:   "List a pool's candidates",
+			Params:    []openapi.Parameter{poolID, openapi.Query("stage", "string", "Only this pipeline stage"), limitParam, offsetParam},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: poolMembersResponse{}}},
+			Errors:    specErrors,
+		},
+		{
+			Method: "POST", Path: "/api/pools/{id}/candidates", OperationID: "addPoolCandidates", Tag: "pools",
+			Summary:   "Add candidates to a pool",
+			Params:    []openapi.Parameter{poolID},
+			Body:      addPoolCandidatesRequest{},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: addPoolCandidatesResponse{}}},
+			Errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusInternalServerError},
+		},
+		{
+			Method: "PUT", Path: "/api/pools/{id}/candidates/{cid}", OperationID: "setPoolCandidateStage", Tag: "pools",
+			Summary:   "Move a candidate to another stage",
+			Params:    []openapi.Parameter{poolID, openapi.Path("cid", "integer", "Candidate ID")},
+			Body:      poolStageRequest{},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: poolStageResponse{}}},
+			Errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusInternalServerError},
+		},
+		{
+			Method: "DELETE", Path: "/api/pools/{id}/candidates/{cid}", OperationID: "removePoolCandidate", Tag: "pools",
+			Summary:   "Take a candidate out of a pool",
+			Params:    []openapi.Parameter{poolID, openapi.Path("cid", "integer", "Candidate ID")},
+			Responses: []openapi.Resp{{Status: http.StatusNoContent}},
+			Errors:    specErrors,
+		},
+	}
+}
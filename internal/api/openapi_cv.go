@@ -0,0 +1,110 @@
+package api
+
+import (
+	"net/http"
+
+	"cv-search/internal/openapi"
+)
+
+// cvRoutes lists the CV upload and processing endpoints.
+func cvRoutes() []openapi.Route {
+	return []openapi.Route{
+		{
+			Method: "POST", Path: "/api/cv/upload", OperationID: "uploadCV", Tag: "cv",
+			Summary: "Upload a CV",
+			Description: "Parses the file (PDF, DOCX, DOC, ODT, RTF, Pages, TXT, MD, HTML, EML, MSG; OCR for scans) and queues LLM extraction. " +
+				"Answers 202 at once with a job to poll; a CV whose text was uploaded before answers 200 with the existing file and its latest job, " +
+				"or 202 (status reprocessing) when force re-runs its extraction. " +
+				"candidate_id (also as a query parameter) links a new or duplicate CV to that candidate; " +
+				"tags are added to the CV's candidate once it is linked.",
+			Form: []openapi.FormField{
+				{Name: "file", Type: "file", Required: true, Description: "CV file"},
+				{Name: "candidate_id", Type: "integer", Description: "Existing candidate the CV belongs to"},
+				{Name: "anonymize", Type: "boolean", Description: "Mask PII for blind screening (always on with ANONYMIZE_PII)"},
+				{Name: "force", Type: "boolean", Description: "Re-run extraction if the CV is a duplicate"},
+				{Name: "source", Type: "string", Description: "Where the CV came from, e.g. referral (max 100 characters)"},
+				{Name: "tags", Type: "string", Multiple: true, Description: "Candidate tags, repeated or comma-separated (max 20)"},
+			},
+			Responses: []openapi.Resp{
+				{Status: http.StatusAccepted, Description: "Stored and queued for extraction, or a duplicate queued again (force)",
+					Body: openapi.OneOf{cvUploadResponse{}, cvDuplicateResponse{}}, Links: jobStatusLink},
+				{Status: http.StatusOK, Description: "Already uploaded; nothing was queued", Body: cvDuplicateResponse{}},
+				queueBusyResp, quotaTokensResp,
+			},
+			Errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity, http.StatusInternalServerError},
+		},
+		{
+			Method: "POST", Path: "/api/cv/bulk-upload", OperationID: "bulkUploadCVs", Tag: "cv",
+			Summary:     "Upload several CVs",
+			Description: "Each file gets its own result. Up to MAX_REALTIME_CV_COUNT files are queued for real-time extraction; larger uploads go through the Groq batch API (status batch_submitted).",
+			Form: []openapi.FormField{
+				{Name: "files", Type: "file", Multiple: true, Required: true, Description: "CV files (max MAX_BULK_FILE_COUNT)"},
+				{Name: "anonymize", Type: "boolean", Description: "Mask PII for blind screening"},
+				{Name: "source", Type: "string", Description: "Where the CVs came from (new files only)"},
+				{Name: "tags", Type: "string", Multiple: true, Description: "Candidate tags for the new files, repeated or comma-separated (max 20)"},
+			},
+			Responses: []openapi.Resp{
+				{Status: http.StatusMultiStatus, Description: "Per-file results; Retry-After is set when some files were queue_full",
+					Body: bulkUploadResponse{}, Links: batchStatusLink, Headers: retryAfterHeader},
+				queueBusyResp, quotaTokensResp,
+			},
+			Errors: []int{http.StatusBadRequest},
+		},
+		{
+			Method: "GET", Path: "/api/cv/job/{job_id}", OperationID: "getJobStatus", Tag: "cv",
+			Summary: "Get a CV processing job",
+			Params:  []openapi.Parameter{openapi.Path("job_id", "integer", "Job ID from an upload")},
+			Responses: []openapi.Resp{
+				{Status: http.StatusOK, Body: jobStatusResponse{}},
+			},
+			Errors: specErrors,
+		},
+		{
+			Method: "GET", Path: "/api/cv/batch/{batch_id}", OperationID: "getBatchStatus", Tag: "cv",
+			Summary:     "Get a bulk upload's jobs",
+			Description: "Batches are kept in memory for 30 minutes.",
+			Params:      []openapi.Parameter{openapi.Path("batch_id", "string", "Batch ID from a bulk upload or import")},
+			Responses: []openapi.Resp{
+				{Status: http.StatusOK, Body: batchStatusResponse{}},
+			},
+			Errors: []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		{
+			Method: "GET", Path: "/api/cv", OperationID: "listCVFiles", Tag: "cv",
+			Summary: "List uploaded CVs",
+			Params: []openapi.Parameter{
+				openapi.Query("quality", "string", "Parse quality: pending, ok or needs_review"),
+				openapi.Query("limit", "integer", "Max results (default 50, max 200)"), offsetParam,
+			},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: listCVFilesResponse{}}},
+			Errors:    []int{http.StatusBadRequest, http.StatusInternalServerError},
+		},
+		{
+			Method: "GET", Path: "/api/cv/files/{id}/download", OperationID: "downloadCV", Tag: "cv",
+			Summary: "Download the original CV file",
+			Params:  []openapi.Parameter{cvFileID},
+			Responses: []openapi.Resp{
+				{Status: http.StatusOK, Description: "The file as uploaded, with its type's Content-Type", ContentType: "application/octet-stream"},
+			},
+			Errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError, http.StatusBadGateway},
+		},
+		{
+			Method: "GET", Path: "/api/cv/files/{id}/changes", OperationID: "getCVChanges", Tag: "cv",
+			Summary: "Changes since the candidate's previous CV",
+			Params:  []openapi.Parameter{cvFileID},
+			Responses: []openapi.Resp{
+				{Status: http.StatusOK, Body: cvChangesResponse{}},
+			},
+			Errors: specErrors,
+		},
+		{
+			Method: "GET", Path: "/api/cv/files/{id}/photo", OperationID: "getCVPhoto", Tag: "cv",
+			Summary: "Download the photo extracted from a CV (KEEP_CV_PHOTOS)",
+			Params:  []openapi.Parameter{cvFileID},
+			Responses: []openapi.Resp{
+				{Status: http.StatusOK, Description: "JPEG or PNG", ContentType: "image/*"},
+			},
+			Errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError, http.StatusBadGateway},
+		},
+	}
+}
@@ -0,0 +1,293 @@
+package api
+
+import (
+	"net/http"
+
+	"cv-search/internal/openapi"
+	"cv-search/internal/storage"
+)
+
+// searchRoutes lists the search endpoints.
+func searchRoutes() []openapi.Route {
+	return []openapi.Route{
+		{
+			Method: "POST", Path: "/api/search", OperationID: "searchCandidates", Tag: "search",
+			Summary: "Search candidates by structured criteria",
+			Body:    storage.Criteria{},
+			Responses: append([]openapi.Resp{
+				{Status: http.StatusOK, Body: []storage.Candidate{}},
+			}, searchQuotaResps...),
+			Errors: []int{http.StatusBadRequest, http.StatusInternalServerError},
+		},
+		{
+			Method: "POST", Path: "/api/search/hybrid", OperationID: "hybridSearch", Tag: "search",
+			Summary: "Hybrid search (BM25 + vector + graph + LLM rerank)",
+			Params: []openapi.Parameter{
+				openapi.Query("dry_run", "boolean", "Plan only: retrieval sizes, LLM calls and tokens, estimated cost and latency, without searching; not metered"),
+			},
+			Body: HybridSearchRequest{},
+			Responses: append([]openapi.Resp{
+				{Status: http.StatusOK, Description: "Identical requests are answered from the response cache for RESPONSE_CACHE_SEARCH_TTL_SECONDS, each with a fresh search_id; a dry run answers its plan",
+					Body: openapi.OneOf{HybridSearchResponse{}, HybridSearchPlanResponse{}}, Headers: cachedHeaders},
+			}, searchQuotaResps...),
+			Errors: []int{http.StatusBadRequest, http.StatusInternalServerError, http.StatusServiceUnavailable},
+		},
+		{
+			Method: "POST", Path: "/api/search/hybrid/stream", OperationID: "hybridSearchStream", Tag: "search",
+			Summary: "Hybrid search with live progress (Server-Sent Events)",
+			Description: "Streams `progress` events (SearchProgressEvent: embedding, each retrieval source, fusion, " +
+				"rerank batches), then one `result` event (HybridSearchResponse) or an `error` event (ErrorResponse). " +
+				"Request validation errors are answered before the stream starts.",
+			Body: HybridSearchRequest{},
+			Responses: append([]openapi.Resp{
+				{Status: http.StatusOK, Description: "Event stream", ContentType: "text/event-stream"},
+			}, searchQuotaResps...),
+			Errors: []int{http.StatusBadRequest, http.StatusServiceUnavailable},
+		},
+		{
+			Method: "POST", Path: "/api/search/hybrid/report", OperationID: "shortlistReport", Tag: "search",
+			Summary: "Shareable shortlist report of a hybrid search",
+			Description: "Runs the search and renders its top candidates for hiring managers without access: anonymized " +
+				"cards (no names, contact details or employers; reasoning has them masked) with scores, the LLM's " +
+				"reasoning and the shortlist's communities and common skills. Sent as an attachment; degraded " +
+				"searches carry an X-Search-Warning header.",
+			Params: []openapi.Parameter{
+				openapi.Query("format", "string", "markdown (default), html or pdf"),
+				openapi.Query("limit", "integer", "Candidates in the report (default 10, at most 50)"),
+				openapi.Query("title", "string", "Report title (default \"Shortlist: <query>\")"),
+			},
+			Body: HybridSearchRequest{},
+			Responses: append([]openapi.Resp{
+				{Status: http.StatusOK, Description: "text/markdown, text/html or application/pdf, by format", ContentType: "text/markdown"},
+			}, searchQuotaResps...),
+			Errors: []int{http.StatusBadRequest, http.StatusInternalServerError, http.StatusServiceUnavailable},
+		},
+		{
+			Method: "POST", Path: "/api/search/{search_id}/feedback", OperationID: "searchFeedback", Tag: "search",
+			Summary: "Label results of a search good, bad or hired",
+			Description: "search_id is the one the hybrid search response carried. Each label is stored with the result's " +
+				"scores and features as served; labeling a result again replaces its earlier feedback. " +
+				"A candidate that wasn't a result of the search is answered 422.",
+			Params:    []openapi.Parameter{openapi.Path("search_id", "string", "Search ID")},
+			Body:      searchFeedbackRequest{},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: searchFeedbackResponse{}}},
+			Errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusInternalServerError},
+		},
+		{
+			Method: "GET", Path: "/api/search/feedback/export", OperationID: "exportSearchFeedback", Tag: "search",
+			Summary: "Export search feedback for ranking tuning (JSON Lines)",
+			Description: "One SearchFeedbackExport per line, oldest first: the label, score override and comment with the " +
+				"result's features as served and the search's query and config.",
+			Params: []openapi.Parameter{
+				openapi.Query("since", "string", "RFC 3339"), openapi.Query("until", "string", "RFC 3339"),
+			},
+			Responses: []openapi.Resp{
+				{Status: http.StatusOK, Description: "SearchFeedbackExport per line", ContentType: "application/x-ndjson"},
+			},
+			Errors: []int{http.StatusBadRequest, http.StatusInternalServerError},
+		},
+		{
+			Method: "POST", Path: "/api/search/session", OperationID: "createSearchSession", Tag: "search",
+			Summary: "Start a conversational search session",
+			Body:    HybridSearchRequest{},
+			Responses: append([]openapi.Resp{
+				{Status: http.StatusOK, Body: SearchSessionTurnResponse{}},
+			}, searchQuotaResps...),
+			Errors: []int{http.StatusBadRequest, http.StatusInternalServerError, http.StatusServiceUnavailable},
+		},
+		{
+			Method: "GET", Path: "/api/search/session/{id}", OperationID: "getSearchSession", Tag: "search",
+			Summary: "Get a search session with every turn",
+			Params:  []openapi.Parameter{openapi.Path("id", "string", "Session ID")},
+			Responses: []openapi.Resp{
+				{Status: http.StatusOK, Body: storage.SearchSession{}},
+			},
+			Errors: []int{http.StatusNotFound, http.StatusInternalServerError},
+		},
+		{
+			Method: "POST", Path: "/api/search/session/{id}/query", OperationID: "searchSessionQuery", Tag: "search",
+			Summary: "Refine a session with a follow-up query",
+			Params:  []openapi.Parameter{openapi.Path("id", "string", "Session ID")},
+			Body:    HybridSearchRequest{},
+			Responses: append([]openapi.Resp{
+				{Status: http.StatusOK, Body: SearchSessionTurnResponse{}},
+			}, searchQuotaResps...),
+			Errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError, http.StatusServiceUnavailable},
+		},
+		{
+			Method: "GET", Path: "/api/search/suggest", OperationID: "suggest", Tag: "search",
+			Summary: "Autocomplete skills, companies and positions",
+			Params: []openapi.Parameter{
+				openapi.Query("q", "string", "Prefix"),
+				openapi.Query("limit", "integer", "Max results"),
+			},
+			Responses: []openapi.Resp{
+				{Status: http.StatusOK, Body: []storage.SuggestionResult{}},
+			},
+			Errors: []int{http.StatusBadRequest, http.StatusInternalServerError},
+		},
+		{
+			Method: "GET", Path: "/api/search/popular-queries", OperationID: "popularQueries", Tag: "search",
+			Summary: "Example queries built from the most common skills",
+			Responses: []openapi.Resp{
+				{Status: http.StatusOK, Body: []string{}},
+			},
+			Errors: []int{http.StatusInternalServerError},
+		},
+		{
+			Method: "POST", Path: "/api/graphql", OperationID: "graphql", Tag: "search",
+			Summary:     "GraphQL query over candidates, CVs, graph and search",
+			Description: "See internal/api/schema.graphql. Query errors are reported in the GraphQL response's errors, not as HTTP errors.",
+			Body:        graphqlRequest{},
+			Responses: []openapi.Resp{
+				{Status: http.StatusOK, Description: "GraphQL response (data, errors)", Body: map[string]interface{}{}},
+			},
+			Errors: []int{http.StatusBadRequest},
+		},
+	}
+}
+
+// graphRAGRoutes lists the GraphRAG endpoints.
+func graphRAGRoutes() []openapi.Route {
+	return []openapi.Route{
+		{
+			Method: "POST", Path: "/api/graphrag/search", OperationID: "graphRAGSearch", Tag: "graphrag",
+			Summary: "Natural-language search (vector + community + LLM)",
+			Body:    GraphRAGSearchRequest{},
+			Responses: append([]openapi.Resp{
+				{Status: http.StatusOK, Body: GraphRAGSearchResponse{}},
+			}, searchQuotaResps...),
+			Errors: []int{http.StatusBadRequest, http.StatusInternalServerError, http.StatusServiceUnavailable},
+		},
+		{
+			Method: "POST", Path: "/api/graphrag/search/stream", OperationID: "graphRAGSearchStream", Tag: "graphrag",
+			Summary: "Natural-language search with the summary streamed last (Server-Sent Events)",
+			Description: "Sends one `result` event (GraphRAGSearchResponse) as soon as the candidates are ranked, then a " +
+				"`summary` event (GraphRAGSummaryEvent) with an LLM-written summary of the best matches; an `error` event " +
+				"(ErrorResponse) replaces both if the search fails. If the summary fails the stream ends after `result`. " +
+				"Request validation errors are answered before the stream starts.",
+			Body: GraphRAGSearchRequest{},
+			Responses: append([]openapi.Resp{
+				{Status: http.StatusOK, Description: "Event stream", ContentType: "text/event-stream"},
+			}, searchQuotaResps...),
+			Errors: []int{http.StatusBadRequest, http.StatusServiceUnavailable},
+		},
+		{
+			Method: "POST", Path: "/api/graphrag/embeddings/generate", OperationID: "generateEmbeddings", Tag: "graphrag",
+			Summary: "Queue embeddings for every node without one",
+			Responses: []openapi.Resp{
+				{Status: http.StatusOK, Body: generateEmbeddingsResponse{}},
+			},
+			Errors: []int{http.StatusInternalServerError, http.StatusServiceUnavailable},
+		},
+		{
+			Method: "POST", Path: "/api/graphrag/communities/detect", OperationID: "detectCommunities", Tag: "graphrag",
+			Summary: "Run Leiden community detection",
+			Params:  []openapi.Parameter{openapi.Query("level", "integer", "Hierarchy level (default 0)")},
+			Responses: []openapi.Resp{
+				{Status: http.StatusOK, Body: detectCommunitiesResponse{}},
+			},
+			Errors: []int{http.StatusBadRequest, http.StatusInternalServerError, http.StatusServiceUnavailable},
+		},
+		{
+			Method: "GET", Path: "/api/graphrag/communities/changes", OperationID: "listCommunityChanges", Tag: "graphrag",
+			Summary:     "Community detection changelog",
+			Description: "Communities created, dissolved, or drifted past COMMUNITY_DRIFT_* (size change, member churn, cohesion drop) and re-summarized, newest first.",
+			Params: []openapi.Parameter{
+				openapi.Query("level", "integer", "Hierarchy level (default: all)"),
+				openapi.Query("since", "string", "RFC 3339"),
+				openapi.Query("limit", "integer", "Max entries (default 100, max 1000)"),
+			},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: communityChangesResponse{}}},
+			Errors:    []int{http.StatusBadRequest, http.StatusInternalServerError},
+		},
+	}
+}
+
+// graphRoutes lists the knowledge graph statistics endpoints.
+func graphRoutes() []openapi.Route {
+	return []openapi.Route{
+		{
+			Method: "GET", Path: "/api/graph/stats", OperationID: "getGraphStats", Tag: "graph",
+			Summary:   "Node and edge counts",
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: graphStatsResponse{}, Headers: cachedHeaders}, notModifiedResp},
+			Errors:    []int{http.StatusInternalServerError},
+		},
+		{
+			Method: "GET", Path: "/api/graph/skills/popular", OperationID: "getPopularSkills", Tag: "graph",
+			Summary:   "Most common skills",
+			Params:    []openapi.Parameter{openapi.Query("limit", "integer", "Max results (default 20, max 200)")},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: popularSkillsResponse{}, Headers: cachedHeaders}, notModifiedResp},
+			Errors:    []int{http.StatusInternalServerError},
+		},
+		{
+			Method: "GET", Path: "/api/graph/skills/trending", OperationID: "getTrendingSkills", Tag: "graph",
+			Summary:     "Fastest growing (or shrinking) skills",
+			Description: "People mentioning each skill in the last complete period against the period before, by the month their CV was built. Skills new in the period come first.",
+			Params: []openapi.Parameter{
+				openapi.Query("period", "string", "month, quarter (default) or year"),
+				openapi.Query("direction", "string", "up (default) or down"),
+				openapi.Query("min_mentions", "integer", "Min mentions in the period (down: in the period before; default 3)"),
+				openapi.Query("limit", "integer", "Max results (default 20, max 200)"),
+			},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: trendingSkillsResponse{}, Headers: cachedHeaders}, notModifiedResp},
+			Errors:    []int{http.StatusBadRequest, http.StatusInternalServerError},
+		},
+		{
+			Method: "GET", Path: "/api/graph/stats/skills-trend", OperationID: "getSkillTrend", Tag: "graph",
+			Summary: "New candidates per skill and month",
+			Params: []openapi.Parameter{
+				openapi.Query("months", "integer", "Months back (default 12, max 60)"),
+				openapi.Query("skills", "string", "Comma-separated skills; default the top `limit` of the period"),
+				openapi.Query("limit", "integer", "Skills when none are named (default 10, max 50)"),
+			},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: skillTrendResponse{}, Headers: cachedHeaders}, notModifiedResp},
+			Errors:    []int{http.StatusInternalServerError},
+		},
+		{
+			Method: "GET", Path: "/api/graph/stats/seniority", OperationID: "getSeniorityDistribution", Tag: "graph",
+			Summary:   "Candidates per seniority level",
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: seniorityResponse{}, Headers: cachedHeaders}, notModifiedResp},
+			Errors:    []int{http.StatusInternalServerError},
+		},
+		{
+			Method: "GET", Path: "/api/graph/stats/communities", OperationID: "getCommunitySizes", Tag: "graph",
+			Summary:   "Communities by member count",
+			Params:    []openapi.Parameter{openapi.Query("limit", "integer", "Max results (default 50, max 500)")},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: communitySizesResponse{}, Headers: cachedHeaders}, notModifiedResp},
+			Errors:    []int{http.StatusInternalServerError},
+		},
+		{
+			Method: "GET", Path: "/api/graph/stats/uploads", OperationID: "getWeeklyUploads", Tag: "graph",
+			Summary:   "CV uploads per week",
+			Params:    []openapi.Parameter{openapi.Query("weeks", "integer", "Weeks back (default 12, max 260)")},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: weeklyUploadsResponse{}, Headers: cachedHeaders}, notModifiedResp},
+			Errors:    []int{http.StatusInternalServerError},
+		},
+		{
+			Method: "GET", Path: "/api/graph/analytics", OperationID: "getGraphAnalytics", Tag: "graph",
+			Summary:     "Central people, feeder companies and skill combinations",
+			Description: "Last computed graph analytics: people by PageRank, companies by former employees, the most common skill pairs, and rare combinations of common skills (lowest lift). Recomputed every GRAPH_ANALYTICS_INTERVAL_MINUTES.",
+			Params: []openapi.Parameter{
+				openapi.Query("limit", "integer", "Max entries per list (default 20, max 200)"),
+				openapi.Query("skill", "string", "Only skill pairs including this skill"),
+			},
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: storage.GraphAnalytics{}, Headers: cachedHeaders}, notModifiedResp},
+			Errors:    []int{http.StatusInternalServerError},
+		},
+		{
+			Method: "POST", Path: "/api/graph/query", OperationID: "graphQuery", Tag: "graph",
+			Summary:     "Query the graph",
+			Description: "Runs a read-only graph query over the organization's graph, e.g. `MATCH person WITH skill \"Go\" AND WORKED_AT company \"Garanti\" RETURN name, location, skill LIMIT 20`. See internal/graphrag/graph_query.go for the language. Rows are keyed by the returned fields; a node type returns the names of the linked nodes of that type.",
+			Body:        graphQueryRequest{},
+			Responses:   []openapi.Resp{{Status: http.StatusOK, Body: graphQueryResponse{}}},
+			Errors:      []int{http.StatusBadRequest, http.StatusInternalServerError},
+		},
+		{
+			Method: "POST", Path: "/api/graph/analytics/refresh", OperationID: "refreshGraphAnalytics", Tag: "graph",
+			Summary:   "Recompute the graph analytics now",
+			Responses: []openapi.Resp{{Status: http.StatusOK, Body: refreshGraphAnalyticsResponse{}}},
+			Errors:    []int{http.StatusInternalServerError},
+		},
+	}
+}
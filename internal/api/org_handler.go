@@ -0,0 +1,532 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"context"
+	"cv-search/internal/graphrag"
+	"cv-search/internal/llm"
+	"cv-search/internal/storage"
+	"cv-search/internal/tenant"
+	"fmt"
+)
+
+// ─── Organization scoping ─────────────────────────────────────────────────────
+
+// orgMiddleware scopes every /api/ request to the organization whose API key
+// it sends (tenant.WithOrg), so storage and the search engines only see that
+// organization's candidates, CVs and graph. A request without a key acts
+// for the default organization, or is refused with REQUIRE_ORG_KEY; one
+// with an unknown key (mistyped, rotated or revoked) is always refused.
+// Organization management authenticates with the admin key instead.
+func (a *API) orgMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/") || strings.HasPrefix(r.URL.Path, "/api/admin/orgs") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		orgID := 0
+		if key := apiKeyFromRequest(r); key != "" {
+			var err error
+			orgID, err = a.db.OrgIDByKeyHash(r.Context(), hashAPIKey(key))
+			if err != nil {
+				log.Printf("[Org] %v", err)
+				http.Error(w, "database error", http.StatusInternalServerError)
+				return
+			}
+			if orgID == 0 {
+				http.Error(w, "unknown organization API key", http.StatusUnauthorized)
+				return
+			}
+		}
+		if orgID == 0 {
+			if a.cfg.RequireOrgKey {
+				http.Error(w, "a valid organization API key is required", http.StatusUnauthorized)
+				return
+			}
+			orgID = tenant.DefaultOrgID
+		}
+		next.ServeHTTP(w, r.WithContext(tenant.WithOrg(r.Context(), orgID)))
+	})
+}
+
+// adminMiddleware requires ADMIN_API_KEY, sent as X-Admin-Key, on every
+// /api/admin/ request when it is set. Without it the admin endpoints stay
+// open as before, except organization management (requireAdminKey).
+func (a *API) adminMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a.cfg.AdminAPIKey != "" && strings.HasPrefix(r.URL.Path, "/api/admin/") && !a.isAdmin(r) {
+			http.Error(w, "admin key required", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (a *API) isAdmin(r *http.Request) bool {
+	key := r.Header.Get("X-Admin-Key")
+	return key != "" && subtle.ConstantTimeCompare([]byte(key), []byte(a.cfg.AdminAPIKey)) == 1
+}
+
+// requireAdminKey guards an endpoint that must never be open: it is refused
+// outright unless ADMIN_API_KEY is configured (adminMiddleware then checks
+// the key).
+func (a *API) requireAdminKey(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if a.cfg.AdminAPIKey == "" {
+			http.Error(w, "organization management is disabled (set ADMIN_API_KEY)", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// ─── Organization management ──────────────────────────────────────────────────
+
+var orgSlugRe = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+type createOrganizationRequest struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+// organizationKeyResponse carries a newly issued API key. The key is only
+// ever returned here; the database keeps its hash.
+type organizationKeyResponse struct {
+	*storage.Organization
+	APIKey string `json:"api_key"`
+}
+
+type listOrganizationsResponse struct {
+	Organizations []storage.Organization `json:"organizations"`
+}
+
+type rotateOrganizationKeyResponse struct {
+	ID     int    `json:"id"`
+	APIKey string `json:"api_key"`
+}
+
+// newOrgAPIKey generates a random organization API key.
+func newOrgAPIKey() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "cvs_" + hex.EncodeToString(b), nil
+}
+
+// CreateOrganizationHandler adds an organization and issues its API key,
+// returned once in the response.
+//
+//	POST /api/admin/orgs {"slug": "acme", "name": "Acme Recruiting"}
+func (a *API) CreateOrganizationHandler(w http.ResponseWriter, r *http.Request) {
+	var req createOrganizationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.Slug = strings.ToLower(strings.TrimSpace(req.Slug))
+	req.Name = strings.TrimSpace(req.Name)
+	if !orgSlugRe.MatchString(req.Slug) {
+		http.Error(w, "slug must be 1-63 lowercase letters, digits or dashes", http.StatusBadRequest)
+		return
+	}
+	if req.Name == "" {
+		req.Name = req.Slug
+	}
+
+	key, err := newOrgAPIKey()
+	if err != nil {
+		log.Printf("[Org] generate key: %v", err)
+		http.Error(w, "failed to generate API key", http.StatusInternalServerError)
+		return
+	}
+	org, err := a.db.CreateOrganization(r.Context(), req.Slug, req.Name, hashAPIKey(key))
+	if err != nil {
+		log.Printf("[Org] CreateOrganization(%q) failed: %v", req.Slug, err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	if org == nil {
+		http.Error(w, "slug already taken", http.StatusConflict)
+		return
+	}
+	a.audit(r, "create", "organization", strconv.Itoa(org.ID), map[string]interface{}{
+		"slug": org.Slug, "name": org.Name,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(organizationKeyResponse{Organization: org, APIKey: key})
+}
+
+// ListOrganizationsHandler lists every organization (without keys).
+//
+//	GET /api/admin/orgs
+func (a *API) ListOrganizationsHandler(w http.ResponseWriter, r *http.Request) {
+	orgs, err := a.db.ListOrganizations(r.Context())
+	if err != nil {
+		log.Printf("[Org] ListOrganizations failed: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(listOrganizationsResponse{Organizations: orgs})
+}
+
+// RotateOrganizationKeyHandler issues a new API key for an organization;
+// the old one stops working immediately.
+//
+//	POST /api/admin/orgs/{id}/key
+func (a *API) RotateOrganizationKeyHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		http.Error(w, "invalid organization id", http.StatusBadRequest)
+		return
+	}
+	key, err := newOrgAPIKey()
+	if err != nil {
+		log.Printf("[Org] generate key: %v", err)
+		http.Error(w, "failed to generate API key", http.StatusInternalServerError)
+		return
+	}
+	ok, err := a.db.SetOrganizationKey(r.Context(), id, hashAPIKey(key))
+	if err != nil {
+		log.Printf("[Org] SetOrganizationKey(%d) failed: %v", id, err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		http.Error(w, "organization not found", http.StatusNotFound)
+		return
+	}
+	a.audit(r, "rotate_key", "organization", strconv.Itoa(id), nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rotateOrganizationKeyResponse{ID: id, APIKey: key})
+}
+
+// ─── Organization AI settings ─────────────────────────────────────────────────
+
+// orgAISettingsRequest sets an organization's own LLM and embedding
+// provider; an empty provider uses the deployment's. An omitted API key
+// keeps the stored one while the provider stays the same.
+type orgAISettingsRequest struct {
+	LLMProvider         string `json:"llm_provider"`
+	LLMModel            string `json:"llm_model"`
+	LLMAPIKey           string `json:"llm_api_key"`
+	EmbeddingProvider   string `json:"embedding_provider"`
+	EmbeddingModel      string `json:"embedding_model"`
+	EmbeddingDimensions int    `json:"embedding_dimensions"`
+	EmbeddingAPIKey     string `json:"embedding_api_key"`
+}
+
+// orgAISettingsResponse shows an organization's settings without its keys.
+type orgAISettingsResponse struct {
+	*storage.OrgAISettings
+	HasLLMAPIKey       bool `json:"has_llm_api_key"`
+	HasEmbeddingAPIKey bool `json:"has_embedding_api_key"`
+}
+
+func newOrgAISettingsResponse(s *storage.OrgAISettings) orgAISettingsResponse {
+	return orgAISettingsResponse{
+		OrgAISettings:      s,
+		HasLLMAPIKey:       s.LLMAPIKey != nil,
+		HasEmbeddingAPIKey: s.EmbeddingAPIKey != nil,
+	}
+}
+
+// orgIDFromPath reads the {id} path value of an organization that exists.
+// It writes the error response and returns 0 otherwise.
+func (a *API) orgIDFromPath(w http.ResponseWriter, r *http.Request) int {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		http.Error(w, "invalid organization id", http.StatusBadRequest)
+		return 0
+	}
+	ok, err := a.db.OrganizationExists(r.Context(), id)
+	if err != nil {
+		log.Printf("[Org] %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return 0
+	}
+	if !ok {
+		http.Error(w, "organization not found", http.StatusNotFound)
+		return 0
+	}
+	return id
+}
+
+// GetOrgAISettingsHandler shows an organization's own LLM and embedding
+// settings; the API keys are never returned.
+//
+//	GET /api/admin/orgs/{id}/ai-settings
+func (a *API) GetOrgAISettingsHandler(w http.ResponseWriter, r *http.Request) {
+	orgID := a.orgIDFromPath(w, r)
+	if orgID == 0 {
+		return
+	}
+	settings, err := a.db.GetOrgAISettings(r.Context(), orgID)
+	if err != nil {
+		log.Printf("[Org] %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	if settings == nil {
+		settings = &storage.OrgAISettings{OrgID: orgID}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newOrgAISettingsResponse(settings))
+}
+
+// PutOrgAISettingsHandler sets an organization's own LLM and embedding
+// provider. The API keys are encrypted (SETTINGS_ENCRYPTION_KEY) before they
+// are stored. Each provider it sets is tried once before saving, and the
+// embedding model must give vectors of the database's dimensions. The
+// embedding model can't change while the organization has embeddings made
+// with the current one.
+//
+//	PUT /api/admin/orgs/{id}/ai-settings {"llm_provider": "groq", "llm_model": "llama-3.3-70b-versatile", "llm_api_key": "gsk_..."}
+func (a *API) PutOrgAISettingsHandler(w http.ResponseWriter, r *http.Request) {
+	orgID := a.orgIDFromPath(w, r)
+	if orgID == 0 {
+		return
+	}
+	var req orgAISettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	ctx := r.Context()
+
+	current, err := a.db.GetOrgAISettings(ctx, orgID)
+	if err != nil {
+		log.Printf("[Org] %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	if current == nil {
+		current = &storage.OrgAISettings{OrgID: orgID}
+	}
+
+	settings, errMsg := a.buildOrgAISettings(current, &req)
+	if errMsg != "" {
+		http.Error(w, errMsg, http.StatusBadRequest)
+		return
+	}
+	next, err := a.orgAIConfig(settings)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	prev, err := a.orgAIConfig(current)
+	if err != nil {
+		prev = aiConfig{} // unreadable; treat the embedding setup as changed
+	}
+	if embeddingsChange(prev, next) && a.refuseEmbeddingChange(w, r, orgID) {
+		return
+	}
+	if msg := a.checkAIConfig(ctx, settings, next); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	if err := a.db.SaveOrgAISettings(ctx, settings); err != nil {
+		log.Printf("[Org] %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	a.forgetOrgAI(orgID)
+	a.audit(r, "update", "organization_ai_settings", strconv.Itoa(orgID), map[string]interface{}{
+		"llm_provider": settings.LLMProvider, "llm_model": settings.LLMModel,
+		"embedding_provider": settings.EmbeddingProvider, "embedding_model": settings.EmbeddingModel,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newOrgAISettingsResponse(settings))
+}
+
+// DeleteOrgAISettingsHandler puts an organization back on the deployment's
+// LLM and embedding provider.
+//
+//	DELETE /api/admin/orgs/{id}/ai-settings
+func (a *API) DeleteOrgAISettingsHandler(w http.ResponseWriter, r *http.Request) {
+	orgID := a.orgIDFromPath(w, r)
+	if orgID == 0 {
+		return
+	}
+	current, err := a.db.GetOrgAISettings(r.Context(), orgID)
+	if err != nil {
+		log.Printf("[Org] %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	if current == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	prev, err := a.orgAIConfig(current)
+	if err != nil {
+		prev = aiConfig{} // unreadable; treat the embedding setup as changed
+	}
+	if embeddingsChange(prev, deploymentAIConfig(a.cfg)) && a.refuseEmbeddingChange(w, r, orgID) {
+		return
+	}
+	if _, err := a.db.DeleteOrgAISettings(r.Context(), orgID); err != nil {
+		log.Printf("[Org] %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	a.forgetOrgAI(orgID)
+	a.audit(r, "delete", "organization_ai_settings", strconv.Itoa(orgID), nil)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// buildOrgAISettings validates req and turns it into the settings to store,
+// sealing new API keys and keeping current's where req omits them. It
+// returns a message for the client on invalid input.
+func (a *API) buildOrgAISettings(current *storage.OrgAISettings, req *orgAISettingsRequest) (*storage.OrgAISettings, string) {
+	s := &storage.OrgAISettings{
+		OrgID:               current.OrgID,
+		LLMProvider:         strings.ToLower(strings.TrimSpace(req.LLMProvider)),
+		LLMModel:            strings.TrimSpace(req.LLMModel),
+		EmbeddingProvider:   strings.ToLower(strings.TrimSpace(req.EmbeddingProvider)),
+		EmbeddingModel:      strings.TrimSpace(req.EmbeddingModel),
+		EmbeddingDimensions: req.EmbeddingDimensions,
+	}
+
+	switch s.LLMProvider {
+	case "":
+		s.LLMModel = ""
+	case "openai", "groq", "ollama":
+		if s.LLMModel == "" {
+			return nil, "llm_model is required with llm_provider"
+		}
+	default:
+		return nil, fmt.Sprintf("unknown llm_provider %q (want openai, groq or ollama, or \"\" for the deployment's)", s.LLMProvider)
+	}
+	switch s.EmbeddingProvider {
+	case "":
+		s.EmbeddingModel, s.EmbeddingDimensions = "", 0
+	case "openai":
+		if s.EmbeddingModel == "" {
+			s.EmbeddingModel = graphrag.DefaultEmbeddingModel
+		}
+	case "ollama":
+		if s.EmbeddingDimensions > 0 {
+			return nil, "embedding_dimensions is not supported with embedding_provider ollama"
+		}
+		if s.EmbeddingModel == "" {
+			s.EmbeddingModel = graphrag.DefaultOllamaEmbeddingModel
+		}
+	default:
+		return nil, fmt.Sprintf("unknown embedding_provider %q (want openai or ollama, or \"\" for the deployment's)", s.EmbeddingProvider)
+	}
+	if s.EmbeddingDimensions < 0 {
+		return nil, "embedding_dimensions can't be negative"
+	}
+
+	var msg string
+	if s.LLMAPIKey, msg = a.sealOrgKey("llm", s.LLMProvider, req.LLMAPIKey, current.LLMProvider, current.LLMAPIKey); msg != "" {
+		return nil, msg
+	}
+	if s.EmbeddingAPIKey, msg = a.sealOrgKey("embedding", s.EmbeddingProvider, req.EmbeddingAPIKey, current.EmbeddingProvider, current.EmbeddingAPIKey); msg != "" {
+		return nil, msg
+	}
+	return s, ""
+}
+
+// sealOrgKey returns the sealed API key to store for one provider (part is
+// "llm" or "embedding"): key if given, else the stored one if the provider
+// didn't change. The hosted providers need one; Ollama's is optional.
+func (a *API) sealOrgKey(part, provider, key, currentProvider string, currentKey []byte) ([]byte, string) {
+	key = strings.TrimSpace(key)
+	if provider == "" {
+		return nil, ""
+	}
+	if key == "" {
+		if provider == currentProvider && currentKey != nil {
+			return currentKey, ""
+		}
+		if provider == "ollama" {
+			return nil, ""
+		}
+		return nil, fmt.Sprintf("%s_api_key is required for %s", part, provider)
+	}
+	if a.secrets == nil {
+		return nil, "API keys can't be stored: SETTINGS_ENCRYPTION_KEY is not set"
+	}
+	sealed, err := a.secrets.Seal(key)
+	if err != nil {
+		log.Printf("[Org] seal %s key: %v", part, err)
+		return nil, "failed to encrypt " + part + "_api_key"
+	}
+	return sealed, ""
+}
+
+// checkAIConfig makes one call to each provider s sets, with the resolved
+// ac, so a wrong key or model is refused before it is saved. It returns a
+// message for the client when a call fails or the embeddings don't fit the
+// database.
+func (a *API) checkAIConfig(ctx context.Context, s *storage.OrgAISettings, ac aiConfig) string {
+	if s.LLMProvider != "" {
+		svc := llm.NewService(ac.llmProvider, ac.llmAPIKey, ac.llmModel)
+		svc.SetBaseURL(a.cfg.OllamaURL)
+		if _, err := svc.Generate(ctx, "Reply with OK."); err != nil {
+			return fmt.Sprintf("LLM check failed (%s/%s): %v", ac.llmProvider, ac.llmModel, err)
+		}
+	}
+	if s.EmbeddingProvider != "" {
+		embeddings := graphrag.NewEmbeddingService(ac.embeddingAPIKey, a.db.GetConnection())
+		if ac.embeddingProvider == "ollama" {
+			embeddings.UseOllama(a.cfg.OllamaURL, ac.embeddingAPIKey)
+		}
+		embeddings.SetModel(ac.embeddingModel, ac.embeddingDimensions)
+		vec, err := embeddings.GenerateEmbedding(ctx, "senior Go developer")
+		if err != nil {
+			return fmt.Sprintf("embedding check failed (%s/%s): %v", ac.embeddingProvider, ac.embeddingModel, err)
+		}
+		dims, err := embeddings.EmbeddingDimensions(ctx)
+		if err != nil {
+			log.Printf("[Org] %v", err)
+			return "failed to read the embedding column dimensions"
+		}
+		if dims > 0 && len(vec) != dims {
+			return fmt.Sprintf("%s gives %d-dimensional embeddings, the database stores %d", ac.embeddingModel, len(vec), dims)
+		}
+	}
+	return ""
+}
+
+// embeddingsChange reports whether switching an organization from prev to
+// next would embed with another model, which its stored vectors (if any)
+// wouldn't be comparable with.
+func embeddingsChange(prev, next aiConfig) bool {
+	return next.embeddingProvider != prev.embeddingProvider || next.embeddingModel != prev.embeddingModel ||
+		next.embeddingDimensions != prev.embeddingDimensions
+}
+
+// refuseEmbeddingChange writes a 409 and returns true if the organization
+// has embeddings, which a change of embedding model would strand.
+func (a *API) refuseEmbeddingChange(w http.ResponseWriter, r *http.Request, orgID int) bool {
+	has, err := a.db.OrgHasEmbeddings(r.Context(), orgID)
+	if err != nil {
+		log.Printf("[Org] %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return true
+	}
+	if has {
+		http.Error(w, "the organization already has embeddings made with its current embedding model, which can't change", http.StatusConflict)
+		return true
+	}
+	return false
+}
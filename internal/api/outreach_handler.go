@@ -0,0 +1,126 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"cv-search/internal/graphrag"
+)
+
+// maxOutreachFieldLength caps an outreach request's role, company and
+// sender name, in characters.
+const maxOutreachFieldLength = 200
+
+type outreachRequest struct {
+	Role           string `json:"role"`
+	JobDescription string `json:"job_description,omitempty"`
+	Company        string `json:"company,omitempty"`
+	SenderName     string `json:"sender_name,omitempty"`
+	MatchReasoning string `json:"match_reasoning,omitempty"` // e.g. a search result's
+	Channel        string `json:"channel,omitempty"`         // email (default) | linkedin
+	Tone           string `json:"tone,omitempty"`            // professional (default) | friendly | casual
+	Language       string `json:"language,omitempty"`        // en (default) | tr
+}
+
+type outreachResponse struct {
+	CandidateID int `json:"candidate_id"`
+	*graphrag.OutreachDraft
+}
+
+// validate normalizes the request and returns an error message if it is
+// invalid.
+func (req *outreachRequest) validate() string {
+	for _, f := range []*string{&req.Role, &req.JobDescription, &req.Company, &req.SenderName, &req.MatchReasoning} {
+		*f = strings.TrimSpace(*f)
+	}
+	for _, o := range []struct {
+		value   *string
+		name    string
+		allowed []string
+	}{
+		{&req.Channel, "channel", graphrag.OutreachChannels},
+		{&req.Tone, "tone", graphrag.OutreachTones},
+		{&req.Language, "language", graphrag.OutreachLanguages},
+	} {
+		*o.value = strings.ToLower(strings.TrimSpace(*o.value))
+		if *o.value == "" {
+			*o.value = o.allowed[0]
+		}
+		if !slices.Contains(o.allowed, *o.value) {
+			return fmt.Sprintf("%s must be one of %s", o.name, strings.Join(o.allowed, ", "))
+		}
+	}
+	if req.Role == "" {
+		return "role is required"
+	}
+	for _, f := range []struct{ name, value string }{{"role", req.Role}, {"company", req.Company}, {"sender_name", req.SenderName}} {
+		if utf8.RuneCountInString(f.value) > maxOutreachFieldLength {
+			return fmt.Sprintf("%s exceeds %d characters", f.name, maxOutreachFieldLength)
+		}
+	}
+	if utf8.RuneCountInString(req.MatchReasoning) > maxReasoningLength {
+		return fmt.Sprintf("match_reasoning exceeds %d characters", maxReasoningLength)
+	}
+	return checkJobDescription(req.JobDescription)
+}
+
+// OutreachHandler drafts a personalized outreach email or LinkedIn message to
+// a candidate about a role, from their background and the match reasoning.
+// Nothing is sent.
+// POST /api/candidates/{id}/outreach
+func (a *API) OutreachHandler(w http.ResponseWriter, r *http.Request) {
+	candidateID, err := parseCandidateID(r)
+	if err != nil {
+		http.Error(w, "invalid candidate id", http.StatusBadRequest)
+		return
+	}
+	var req outreachRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	engine := a.ai(r.Context()).llmSearchEngine
+	if engine == nil {
+		http.Error(w, "outreach drafting not available (LLM not configured)", http.StatusServiceUnavailable)
+		return
+	}
+	graphNodeID, ok := a.candidateGraphNode(w, r, candidateID, "Outreach")
+	if !ok {
+		return
+	}
+
+	draft, err := engine.DraftOutreach(r.Context(), graphNodeID, graphrag.OutreachRequest{
+		Role:           req.Role,
+		JobDescription: req.JobDescription,
+		Company:        req.Company,
+		SenderName:     req.SenderName,
+		MatchReasoning: req.MatchReasoning,
+		Channel:        req.Channel,
+		Tone:           req.Tone,
+		Language:       req.Language,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, errNoGraphProfile, http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("[Outreach] DraftOutreach(candidate=%d) failed: %v", candidateID, err)
+		http.Error(w, "outreach drafting failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(outreachResponse{CandidateID: candidateID, OutreachDraft: draft})
+}
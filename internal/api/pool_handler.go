@@ -0,0 +1,437 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"cv-search/internal/storage"
+)
+
+// ─── Request/Response types ───────────────────────────────────────────────────
+
+type talentPoolRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type addPoolCandidatesRequest struct {
+	CandidateIDs []int    `json:"candidate_ids"`
+	PersonIDs    []string `json:"person_ids"` // "person_12", as in search results
+	Stage        string   `json:"stage"`      // default: sourced
+}
+
+type addPoolCandidatesResponse struct {
+	PoolID  int   `json:"pool_id"`
+	Added   []int `json:"added"`
+	Skipped []int `json:"skipped"` // already in the pool, or not a candidate
+
+	UnknownPersonIDs []string `json:"unknown_person_ids,omitempty"` // no candidate for these
+}
+
+type poolStageRequest struct {
+	Stage string `json:"stage"`
+}
+
+type poolStageResponse struct {
+	PoolID        int    `json:"pool_id"`
+	CandidateID   int    `json:"candidate_id"`
+	Stage         string `json:"stage"`
+	PreviousStage string `json:"previous_stage"`
+}
+
+type poolMembersResponse struct {
+	PoolID     int                        `json:"pool_id"`
+	Stage      string                     `json:"stage,omitempty"`
+	Candidates []storage.TalentPoolMember `json:"candidates"`
+	Limit      int                        `json:"limit"`
+	Offset     int                        `json:"offset"`
+}
+
+// poolStages are a pool member's pipeline stages, in pipeline order.
+// rejected can be reached from any of them.
+var poolStages = []string{"sourced", "screened", "interviewed", "offered", "hired", "rejected"}
+
+// maxPoolNameLength caps a pool name, in characters.
+const maxPoolNameLength = 100
+
+// maxPoolAdd caps how many candidates one request adds to a pool.
+const maxPoolAdd = 500
+
+// ─── Helpers ──────────────────────────────────────────────────────────────────
+
+func parsePoolID(r *http.Request) (int, error) {
+	raw := r.PathValue("id")
+	if raw == "" {
+		return 0, errors.New("missing pool id")
+	}
+	return strconv.Atoi(raw)
+}
+
+func validPoolStage(stage string) bool {
+	for _, s := range poolStages {
+		if s == stage {
+			return true
+		}
+	}
+	return false
+}
+
+// validate trims a pool request and returns a message when it's invalid.
+func (req *talentPoolRequest) validate() string {
+	req.Name = strings.TrimSpace(req.Name)
+	req.Description = strings.TrimSpace(req.Description)
+	if req.Name == "" {
+		return "name is required"
+	}
+	if len([]rune(req.Name)) > maxPoolNameLength {
+		return fmt.Sprintf("name must be at most %d characters", maxPoolNameLength)
+	}
+	return ""
+}
+
+// ─── Pools ────────────────────────────────────────────────────────────────────
+
+// ListTalentPoolsHandler returns the organization's talent pools with their
+// size and member count per stage.
+// GET /api/pools
+func (a *API) ListTalentPoolsHandler(w http.ResponseWriter, r *http.Request) {
+	pools, err := a.db.ListTalentPools(r.Context())
+	if err != nil {
+		log.Printf("[PoolHandler] ListTalentPools failed: %v", err)
+		http.Error(w, "failed to list pools", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pools)
+}
+
+// CreateTalentPoolHandler creates an empty talent pool.
+// POST /api/pools  {"name": "Backend Q3", "description": "..."}
+func (a *API) CreateTalentPoolHandler(w http.ResponseWriter, r *http.Request) {
+	var req talentPoolRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		http.Error(w, msg, http.StatusUnprocessableEntity)
+		return
+	}
+
+	pool, err := a.db.CreateTalentPool(r.Context(), req.Name, req.Description, actorFromRequest(r))
+	if errors.Is(err, storage.ErrPoolNameTaken) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("[PoolHandler] CreateTalentPool(%q) failed: %v", req.Name, err)
+		http.Error(w, "failed to create pool", http.StatusInternalServerError)
+		return
+	}
+	a.audit(r, "create", "talent_pool", strconv.Itoa(pool.ID), map[string]string{"name": pool.Name})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(pool)
+}
+
+// GetTalentPoolHandler returns one talent pool with its member counts.
+// GET /api/pools/{id}
+func (a *API) GetTalentPoolHandler(w http.ResponseWriter, r *http.Request) {
+	poolID, err := parsePoolID(r)
+	if err != nil {
+		http.Error(w, "invalid pool id", http.StatusBadRequest)
+		return
+	}
+
+	pool, err := a.db.GetTalentPool(r.Context(), poolID)
+	if err != nil {
+		log.Printf("[PoolHandler] GetTalentPool(%d) failed: %v", poolID, err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	if pool == nil {
+		http.Error(w, "pool not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pool)
+}
+
+// UpdateTalentPoolHandler renames a talent pool and replaces its description.
+// PUT /api/pools/{id}
+func (a *API) UpdateTalentPoolHandler(w http.ResponseWriter, r *http.Request) {
+	poolID, err := parsePoolID(r)
+	if err != nil {
+		http.Error(w, "invalid pool id", http.StatusBadRequest)
+		return
+	}
+	var req talentPoolRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		http.Error(w, msg, http.StatusUnprocessableEntity)
+		return
+	}
+
+	pool, err := a.db.UpdateTalentPool(r.Context(), poolID, req.Name, req.Description)
+	if errors.Is(err, storage.ErrPoolNameTaken) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("[PoolHandler] UpdateTalentPool(%d) failed: %v", poolID, err)
+		http.Error(w, "failed to update pool", http.StatusInternalServerError)
+		return
+	}
+	if pool == nil {
+		http.Error(w, "pool not found", http.StatusNotFound)
+		return
+	}
+	a.audit(r, "update", "talent_pool", strconv.Itoa(poolID), map[string]string{"name": pool.Name})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pool)
+}
+
+// DeleteTalentPoolHandler deletes a talent pool; its candidates stay.
+// DELETE /api/pools/{id}
+func (a *API) DeleteTalentPoolHandler(w http.ResponseWriter, r *http.Request) {
+	poolID, err := parsePoolID(r)
+	if err != nil {
+		http.Error(w, "invalid pool id", http.StatusBadRequest)
+		return
+	}
+
+	found, err := a.db.DeleteTalentPool(r.Context(), poolID)
+	if err != nil {
+		log.Printf("[PoolHandler] DeleteTalentPool(%d) failed: %v", poolID, err)
+		http.Error(w, "failed to delete pool", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "pool not found", http.StatusNotFound)
+		return
+	}
+	a.audit(r, "delete", "talent_pool", strconv.Itoa(poolID), nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ─── Pool members ─────────────────────────────────────────────────────────────
+
+// ListPoolCandidatesHandler returns a pool's candidates, most recently moved
+// first.
+// GET /api/pools/{id}/candidates?stage=screened&limit=50&offset=0
+func (a *API) ListPoolCandidatesHandler(w http.ResponseWriter, r *http.Request) {
+	poolID, err := parsePoolID(r)
+	if err != nil {
+		http.Error(w, "invalid pool id", http.StatusBadRequest)
+		return
+	}
+	stage := r.URL.Query().Get("stage")
+	if stage != "" && !validPoolStage(stage) {
+		http.Error(w, "stage must be one of: "+strings.Join(poolStages, ", "), http.StatusBadRequest)
+		return
+	}
+
+	limit, offset := 50, 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 200 {
+			limit = n
+		}
+	}
+	if v := r.URL.Query().Get("offset"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			offset = n
+		}
+	}
+
+	members, err := a.db.ListTalentPoolMembers(r.Context(), poolID, stage, limit, offset)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "pool not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("[PoolHandler] ListTalentPoolMembers(%d) failed: %v", poolID, err)
+		http.Error(w, "failed to list pool candidates", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(poolMembersResponse{
+		PoolID:     poolID,
+		Stage:      stage,
+		Candidates: members,
+		Limit:      limit,
+		Offset:     offset,
+	})
+}
+
+// AddPoolCandidatesHandler adds candidates to a pool, by candidate ID or by
+// the person_id search results carry, so a result list can go straight into
+// a pool. Candidates already in the pool keep their stage.
+// POST /api/pools/{id}/candidates  {"candidate_ids": [3, 7], "person_ids": ["person_12"], "stage": "sourced"}
+func (a *API) AddPoolCandidatesHandler(w http.ResponseWriter, r *http.Request) {
+	poolID, err := parsePoolID(r)
+	if err != nil {
+		http.Error(w, "invalid pool id", http.StatusBadRequest)
+		return
+	}
+	var req addPoolCandidatesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Stage == "" {
+		req.Stage = poolStages[0]
+	}
+	if !validPoolStage(req.Stage) {
+		http.Error(w, "stage must be one of: "+strings.Join(poolStages, ", "), http.StatusUnprocessableEntity)
+		return
+	}
+	if len(req.CandidateIDs)+len(req.PersonIDs) == 0 {
+		http.Error(w, "candidate_ids or person_ids is required", http.StatusUnprocessableEntity)
+		return
+	}
+	if len(req.CandidateIDs)+len(req.PersonIDs) > maxPoolAdd {
+		http.Error(w, fmt.Sprintf("at most %d candidates per request", maxPoolAdd), http.StatusUnprocessableEntity)
+		return
+	}
+
+	ids := append([]int{}, req.CandidateIDs...)
+	var unknown []string
+	if len(req.PersonIDs) > 0 {
+		byPerson, err := a.db.GetCandidateIDsByPersonNodeIDs(r.Context(), req.PersonIDs)
+		if err != nil {
+			log.Printf("[PoolHandler] GetCandidateIDsByPersonNodeIDs failed: %v", err)
+			http.Error(w, "candidate lookup failed", http.StatusInternalServerError)
+			return
+		}
+		for _, pid := range req.PersonIDs {
+			if id, ok := byPerson[pid]; ok {
+				ids = append(ids, id)
+			} else {
+				unknown = append(unknown, pid)
+			}
+		}
+	}
+
+	added, err := a.db.AddTalentPoolMembers(r.Context(), poolID, ids, req.Stage, actorFromRequest(r))
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "pool not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("[PoolHandler] AddTalentPoolMembers(%d) failed: %v", poolID, err)
+		http.Error(w, "failed to add candidates", http.StatusInternalServerError)
+		return
+	}
+
+	isAdded := make(map[int]bool, len(added))
+	for _, id := range added {
+		isAdded[id] = true
+	}
+	skipped := []int{}
+	for _, id := range ids {
+		if !isAdded[id] {
+			skipped = append(skipped, id)
+		}
+	}
+	if len(added) > 0 {
+		a.audit(r, "add_members", "talent_pool", strconv.Itoa(poolID), map[string]interface{}{
+			"candidate_ids": added, "stage": req.Stage,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(addPoolCandidatesResponse{
+		PoolID: poolID, Added: added, Skipped: skipped, UnknownPersonIDs: unknown,
+	})
+}
+
+// SetPoolCandidateStageHandler moves a pool candidate to another stage.
+// PUT /api/pools/{id}/candidates/{cid}  {"stage": "screened"}
+func (a *API) SetPoolCandidateStageHandler(w http.ResponseWriter, r *http.Request) {
+	poolID, err := parsePoolID(r)
+	if err != nil {
+		http.Error(w, "invalid pool id", http.StatusBadRequest)
+		return
+	}
+	candidateID, err := strconv.Atoi(r.PathValue("cid"))
+	if err != nil {
+		http.Error(w, "invalid candidate id", http.StatusBadRequest)
+		return
+	}
+	var req poolStageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if !validPoolStage(req.Stage) {
+		http.Error(w, "stage must be one of: "+strings.Join(poolStages, ", "), http.StatusUnprocessableEntity)
+		return
+	}
+
+	previous, err := a.db.SetTalentPoolMemberStage(r.Context(), poolID, candidateID, req.Stage)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "candidate not in pool", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("[PoolHandler] SetTalentPoolMemberStage(%d, candidate=%d) failed: %v", poolID, candidateID, err)
+		http.Error(w, "failed to update stage", http.StatusInternalServerError)
+		return
+	}
+	if previous != req.Stage {
+		a.audit(r, "move_stage", "talent_pool", strconv.Itoa(poolID), map[string]interface{}{
+			"candidate_id": candidateID, "from": previous, "to": req.Stage,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(poolStageResponse{
+		PoolID:        poolID,
+		CandidateID:   candidateID,
+		Stage:         req.Stage,
+		PreviousStage: previous,
+	})
+}
+
+// RemovePoolCandidateHandler takes a candidate out of a pool.
+// DELETE /api/pools/{id}/candidates/{cid}
+func (a *API) RemovePoolCandidateHandler(w http.ResponseWriter, r *http.Request) {
+	poolID, err := parsePoolID(r)
+	if err != nil {
+		http.Error(w, "invalid pool id", http.StatusBadRequest)
+		return
+	}
+	candidateID, err := strconv.Atoi(r.PathValue("cid"))
+	if err != nil {
+		http.Error(w, "invalid candidate id", http.StatusBadRequest)
+		return
+	}
+
+	if err := a.db.RemoveTalentPoolMember(r.Context(), poolID, candidateID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "candidate not in pool", http.StatusNotFound)
+			return
+		}
+		log.Printf("[PoolHandler] RemoveTalentPoolMember(%d, candidate=%d) failed: %v", poolID, candidateID, err)
+		http.Error(w, "failed to remove candidate", http.StatusInternalServerError)
+		return
+	}
+	a.audit(r, "remove_member", "talent_pool", strconv.Itoa(poolID), map[string]int{"candidate_id": candidateID})
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,325 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ─── Queue metrics ────────────────────────────────────────────────────────────
+
+// recentJobs is how many finished jobs the failure rate is computed over;
+// the failure-rate alert waits for minFailureSamples of them so one failed
+// upload after a restart doesn't read as 100%.
+const (
+	recentJobs        = 100
+	minFailureSamples = 10
+)
+
+// queueStats tracks one background queue and its worker. Jobs are counted as
+// queued before they are sent on the channel, so the worker can never see a
+// job the stats don't know about yet.
+type queueStats struct {
+	mu        sync.Mutex
+	waiting   map[time.Time]int // enqueue time → jobs sitting in the channel
+	inFlight  int
+	processed uint64
+	failed    uint64
+	dropped   uint64
+	rejected  uint64
+	recent    [recentJobs]bool // ring of the last outcomes, true = failed
+	recentN   int
+	recentPos int
+}
+
+func newQueueStats() *queueStats {
+	return &queueStats{waiting: make(map[time.Time]int)}
+}
+
+// queued records a job enqueued at t, before it is sent on the channel.
+func (s *queueStats) queued(t time.Time) {
+	s.mu.Lock()
+	s.waiting[t]++
+	s.mu.Unlock()
+}
+
+// drop undoes queued for a job the full channel didn't take.
+func (s *queueStats) drop(t time.Time) {
+	s.mu.Lock()
+	s.unwait(t)
+	s.dropped++
+	s.mu.Unlock()
+}
+
+// unqueue undoes queued for a job the full channel didn't take that is done
+// some other way, so it doesn't count as dropped.
+func (s *queueStats) unqueue(t time.Time) {
+	s.mu.Lock()
+	s.unwait(t)
+	s.mu.Unlock()
+}
+
+// reject counts n jobs refused before they were queued (backpressure).
+func (s *queueStats) reject(n int) {
+	s.mu.Lock()
+	s.rejected += uint64(n)
+	s.mu.Unlock()
+}
+
+// started moves a job the worker picked up from waiting to in flight.
+func (s *queueStats) started(t time.Time) {
+	s.mu.Lock()
+	s.unwait(t)
+	s.inFlight++
+	s.mu.Unlock()
+}
+
+// finished records the outcome of a started job.
+func (s *queueStats) finished(ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inFlight--
+	s.processed++
+	if !ok {
+		s.failed++
+	}
+	s.recent[s.recentPos] = !ok
+	s.recentPos = (s.recentPos + 1) % recentJobs
+	s.recentN = min(s.recentN+1, recentJobs)
+}
+
+func (s *queueStats) unwait(t time.Time) {
+	if s.waiting[t] <= 1 {
+		delete(s.waiting, t)
+	} else {
+		s.waiting[t]--
+	}
+}
+
+// QueueStatus is a snapshot of one background queue.
+type QueueStatus struct {
+	Queue     string `json:"queue"`
+	Length    int    `json:"length"`
+	Capacity  int    `json:"capacity"`
+	InFlight  int    `json:"in_flight"`
+	Processed uint64 `json:"processed_total"`
+	Failed    uint64 `json:"failed_total"`
+	Dropped   uint64 `json:"dropped_total"`  // rejected because the queue was full
+	Rejected  uint64 `json:"rejected_total"` // refused with 429 before queueing (CV_QUEUE_REJECT_PERCENT)
+	// FailureRate is the share of the last (up to recentJobs) finished jobs
+	// that failed.
+	FailureRate float64 `json:"failure_rate"`
+	// OldestPendingSeconds is how long the oldest job in the queue has
+	// waited since it was first queued (a retried job keeps its first time).
+	OldestPendingSeconds float64  `json:"oldest_pending_seconds"`
+	Alerts               []string `json:"alerts,omitempty"` // breached thresholds: fill, failure_rate, pending_age
+}
+
+// QueueAlertThresholds are the limits QueueStatus.Alerts are checked against
+// (QUEUE_ALERT_* env vars).
+type QueueAlertThresholds struct {
+	FillPercent          int     `json:"fill_percent"`
+	FailurePercent       int     `json:"failure_percent"`
+	MaxPendingAgeSeconds float64 `json:"max_pending_age_seconds"`
+	MinFailureSamples    int     `json:"min_failure_samples"`
+	FailureRateWindow    int     `json:"failure_rate_window"`
+}
+
+var queueAlerts = []string{"fill", "failure_rate", "pending_age"}
+
+func (a *API) alertThresholds() QueueAlertThresholds {
+	return QueueAlertThresholds{
+		FillPercent:          a.cfg.QueueAlertFillPercent,
+		FailurePercent:       a.cfg.QueueAlertFailurePercent,
+		MaxPendingAgeSeconds: a.cfg.QueueAlertMaxAge.Seconds(),
+		MinFailureSamples:    minFailureSamples,
+		FailureRateWindow:    recentJobs,
+	}
+}
+
+func (s *queueStats) status(name string, length, capacity int, th QueueAlertThresholds, now time.Time) QueueStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := QueueStatus{
+		Queue:     name,
+		Length:    length,
+		Capacity:  capacity,
+		InFlight:  s.inFlight,
+		Processed: s.processed,
+		Failed:    s.failed,
+		Dropped:   s.dropped,
+		Rejected:  s.rejected,
+	}
+	failures := 0
+	for _, failed := range s.recent[:s.recentN] {
+		if failed {
+			failures++
+		}
+	}
+	if s.recentN > 0 {
+		st.FailureRate = float64(failures) / float64(s.recentN)
+	}
+	for t := range s.waiting {
+		if age := now.Sub(t).Seconds(); age > st.OldestPendingSeconds {
+			st.OldestPendingSeconds = age
+		}
+	}
+
+	if capacity > 0 && length*100 >= th.FillPercent*capacity {
+		st.Alerts = append(st.Alerts, "fill")
+	}
+	if s.recentN >= minFailureSamples && st.FailureRate*100 >= float64(th.FailurePercent) {
+		st.Alerts = append(st.Alerts, "failure_rate")
+	}
+	if th.MaxPendingAgeSeconds > 0 && st.OldestPendingSeconds >= th.MaxPendingAgeSeconds {
+		st.Alerts = append(st.Alerts, "pending_age")
+	}
+	return st
+}
+
+// queueStatuses snapshots the background queues.
+func (a *API) queueStatuses() []QueueStatus {
+	th, now := a.alertThresholds(), time.Now()
+	return []QueueStatus{
+		a.cvQueueStats.status("cv_processing", len(a.cvProcessingQueue), cap(a.cvProcessingQueue), th, now),
+		a.embeddingQueueStats.status("embedding", len(a.embeddingQueue), cap(a.embeddingQueue), th, now),
+		a.personQueueStats.status("person_embedding", len(a.personQueue), cap(a.personQueue), th, now),
+	}
+}
+
+// ─── Backpressure ─────────────────────────────────────────────────────────────
+
+// QueueCapacity is the fill of the CV processing queue, returned by the
+// upload endpoints so clients can throttle before they are refused.
+type QueueCapacity struct {
+	Length      int     `json:"length"`
+	Capacity    int     `json:"capacity"`
+	Utilization float64 `json:"utilization"` // length / capacity
+	// RejectAbove is the utilization past which uploads are answered with
+	// 429 (CV_QUEUE_REJECT_PERCENT).
+	RejectAbove float64 `json:"reject_above"`
+}
+
+func (a *API) cvQueueCapacity() QueueCapacity {
+	c := QueueCapacity{
+		Length:      len(a.cvProcessingQueue),
+		Capacity:    cap(a.cvProcessingQueue),
+		RejectAbove: float64(a.cfg.CVQueueRejectPercent) / 100,
+	}
+	if c.Capacity > 0 {
+		c.Utilization = float64(c.Length) / float64(c.Capacity)
+	}
+	return c
+}
+
+// admits reports whether n more jobs keep the queue within its reject
+// threshold. An empty queue admits any n, so an upload bigger than the
+// threshold isn't refused forever.
+func (c QueueCapacity) admits(n int) bool {
+	return c.Length == 0 || float64(c.Length+n) <= c.RejectAbove*float64(c.Capacity)
+}
+
+// queueBusyResponse is the 429 of an upload refused because the CV queue
+// is past its reject threshold.
+type queueBusyResponse struct {
+	ErrorResponse
+	RetryAfterSeconds int           `json:"retry_after_seconds"`
+	Queue             QueueCapacity `json:"queue"`
+}
+
+// rejectIfQueueBusy answers 429 with Retry-After when n more CV jobs don't
+// fit under CV_QUEUE_REJECT_PERCENT, so clients back off instead of having
+// their jobs dropped as "queue full". Reports whether it did.
+func (a *API) rejectIfQueueBusy(w http.ResponseWriter, n int) bool {
+	c := a.cvQueueCapacity()
+	if c.admits(n) {
+		return false
+	}
+	a.cvQueueStats.reject(n)
+	log.Printf("[BackgroundJobs] CV queue at %d/%d, refusing upload of %d CV(s)", c.Length, c.Capacity, n)
+	a.writeQueueBusy(w, c)
+	return true
+}
+
+// writeQueueBusy writes the 429 for a queue with capacity c.
+func (a *API) writeQueueBusy(w http.ResponseWriter, c QueueCapacity) {
+	retryAfter := int(a.cfg.CVQueueRetryAfter.Seconds())
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusTooManyRequests)
+	json.NewEncoder(w).Encode(queueBusyResponse{
+		ErrorResponse:     ErrorResponse{Error: "processing queue is busy, retry later", Status: http.StatusTooManyRequests},
+		RetryAfterSeconds: retryAfter,
+		Queue:             c,
+	})
+}
+
+// ─── Handlers ─────────────────────────────────────────────────────────────────
+
+type queuesResponse struct {
+	Queues     []QueueStatus        `json:"queues"`
+	Thresholds QueueAlertThresholds `json:"thresholds"`
+}
+
+// QueuesHandler reports the background queues and their workers as JSON,
+// for simple dashboards; /metrics has the same numbers for Prometheus.
+//
+//	GET /api/admin/queues
+func (a *API) QueuesHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(queuesResponse{Queues: a.queueStatuses(), Thresholds: a.alertThresholds()})
+}
+
+// MetricsHandler exposes the queue numbers in the Prometheus text format.
+// cvsearch_queue_alert is 1 while a threshold is breached, so alert rules
+// can use it directly or recompute from the gauges with their own limits.
+//
+//	GET /metrics
+func (a *API) MetricsHandler(w http.ResponseWriter, r *http.Request) {
+	queues := a.queueStatuses()
+	var b strings.Builder
+	metric := func(name, typ, help string, value func(QueueStatus) float64) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+		for _, q := range queues {
+			fmt.Fprintf(&b, "%s{queue=%q} %g\n", name, q.Queue, value(q))
+		}
+	}
+	metric("cvsearch_queue_length", "gauge", "Jobs waiting in the background queue.",
+		func(q QueueStatus) float64 { return float64(q.Length) })
+	metric("cvsearch_queue_capacity", "gauge", "Buffer size of the background queue.",
+		func(q QueueStatus) float64 { return float64(q.Capacity) })
+	metric("cvsearch_queue_in_flight", "gauge", "Jobs the worker is processing.",
+		func(q QueueStatus) float64 { return float64(q.InFlight) })
+	metric("cvsearch_queue_jobs_processed_total", "counter", "Jobs the worker finished, failed or not.",
+		func(q QueueStatus) float64 { return float64(q.Processed) })
+	metric("cvsearch_queue_jobs_failed_total", "counter", "Jobs that failed.",
+		func(q QueueStatus) float64 { return float64(q.Failed) })
+	metric("cvsearch_queue_jobs_dropped_total", "counter", "Jobs rejected because the queue was full.",
+		func(q QueueStatus) float64 { return float64(q.Dropped) })
+	metric("cvsearch_queue_jobs_rejected_total", "counter", "Jobs refused with 429 because the queue was past its reject threshold.",
+		func(q QueueStatus) float64 { return float64(q.Rejected) })
+	metric("cvsearch_queue_failure_ratio", "gauge", fmt.Sprintf("Share of the last %d finished jobs that failed.", recentJobs),
+		func(q QueueStatus) float64 { return q.FailureRate })
+	metric("cvsearch_queue_oldest_pending_seconds", "gauge", "Age of the oldest job waiting in the queue.",
+		func(q QueueStatus) float64 { return q.OldestPendingSeconds })
+
+	b.WriteString("# HELP cvsearch_queue_alert 1 while the queue breaches the QUEUE_ALERT_* threshold.\n# TYPE cvsearch_queue_alert gauge\n")
+	for _, q := range queues {
+		for _, alert := range queueAlerts {
+			v := 0
+			if slices.Contains(q.Alerts, alert) {
+				v = 1
+			}
+			fmt.Fprintf(&b, "cvsearch_queue_alert{queue=%q,alert=%q} %d\n", q.Queue, alert, v)
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write([]byte(b.String()))
+}
@@ -0,0 +1,360 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"cv-search/internal/storage"
+	"cv-search/internal/tenant"
+)
+
+// Quota names, as used in UsageResponse, quotaExceededResponse and the
+// X-Quota-Warning header.
+const (
+	quotaUploads   = "uploads"
+	quotaSearches  = "searches"
+	quotaLLMTokens = "llm_tokens"
+)
+
+// ─── Request/Response types ───
+
+// QuotaUsage is one quota of an organization and how much of it is used.
+type QuotaUsage struct {
+	Used      int64     `json:"used"`
+	Limit     int64     `json:"limit"`                // 0 = unlimited
+	Remaining *int64    `json:"remaining,omitempty"`  // nil when unlimited
+	SoftLimit int64     `json:"soft_limit,omitempty"` // X-Quota-Warning from here on
+	Period    string    `json:"period"`               // day or month
+	ResetsAt  time.Time `json:"resets_at"`
+}
+
+// UsageResponse is what the caller's organization used and may still use.
+type UsageResponse struct {
+	OrgID     int                `json:"org_id"`
+	Uploads   QuotaUsage         `json:"uploads"`
+	Searches  QuotaUsage         `json:"searches"`
+	LLMTokens QuotaUsage         `json:"llm_tokens"`
+	LLMUsage  []storage.LLMUsage `json:"llm_usage"` // this month, per provider and model
+}
+
+// quotaExceededResponse is the body of a 429 (daily quota) or 402 (monthly
+// LLM tokens).
+type quotaExceededResponse struct {
+	ErrorResponse
+	Quota             string     `json:"quota"`
+	Usage             QuotaUsage `json:"usage"`
+	RetryAfterSeconds int        `json:"retry_after_seconds"`
+}
+
+// orgQuotasRequest sets an organization's own quotas; a null (or missing)
+// limit keeps the deployment's default, 0 is unlimited.
+type orgQuotasRequest struct {
+	UploadsPerDay     *int   `json:"uploads_per_day"`
+	SearchesPerDay    *int   `json:"searches_per_day"`
+	LLMTokensPerMonth *int64 `json:"llm_tokens_per_month"`
+}
+
+// orgQuotasResponse is an organization's own quotas and the ones it is held
+// to (its own, else the deployment's).
+type orgQuotasResponse struct {
+	*storage.OrgQuotas
+	Effective effectiveQuotas `json:"effective"`
+}
+
+type effectiveQuotas struct {
+	UploadsPerDay     int64 `json:"uploads_per_day"`
+	SearchesPerDay    int64 `json:"searches_per_day"`
+	LLMTokensPerMonth int64 `json:"llm_tokens_per_month"`
+}
+
+// ─── Limits and usage ───
+
+// quotaLimits returns the quotas orgID is held to: its own where it has
+// them, else the deployment's (QUOTA_* env).
+func (a *API) quotaLimits(ctx context.Context, orgID int) (effectiveQuotas, *storage.OrgQuotas, error) {
+	limits := effectiveQuotas{
+		UploadsPerDay:     int64(a.cfg.QuotaUploadsPerDay),
+		SearchesPerDay:    int64(a.cfg.QuotaSearchesPerDay),
+		LLMTokensPerMonth: int64(a.cfg.QuotaLLMTokensPerMonth),
+	}
+	own, err := a.db.GetOrgQuotas(ctx, orgID)
+	if err != nil || own == nil {
+		return limits, own, err
+	}
+	if own.UploadsPerDay != nil {
+		limits.UploadsPerDay = int64(*own.UploadsPerDay)
+	}
+	if own.SearchesPerDay != nil {
+		limits.SearchesPerDay = int64(*own.SearchesPerDay)
+	}
+	if own.LLMTokensPerMonth != nil {
+		limits.LLMTokensPerMonth = *own.LLMTokensPerMonth
+	}
+	return limits, own, nil
+}
+
+// orgUsage returns ctx's organization's quotas and what it used of them.
+func (a *API) orgUsage(ctx context.Context) (*UsageResponse, error) {
+	orgID := tenant.OrgID(ctx)
+	limits, _, err := a.quotaLimits(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	used, err := a.db.GetOrgUsage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &UsageResponse{
+		OrgID:     orgID,
+		Uploads:   a.quotaUsage(int64(used.UploadsToday), limits.UploadsPerDay, "day", used.DayEnds),
+		Searches:  a.quotaUsage(int64(used.SearchesToday), limits.SearchesPerDay, "day", used.DayEnds),
+		LLMTokens: a.quotaUsage(used.LLMTokensThisMonth, limits.LLMTokensPerMonth, "month", used.MonthEnds),
+	}, nil
+}
+
+func (a *API) quotaUsage(used, limit int64, period string, resetsAt time.Time) QuotaUsage {
+	u := QuotaUsage{Used: used, Limit: limit, Period: period, ResetsAt: resetsAt}
+	if limit > 0 {
+		remaining := max(limit-used, 0)
+		u.Remaining = &remaining
+		u.SoftLimit = int64(math.Ceil(float64(limit) * float64(a.cfg.QuotaSoftPercent) / 100))
+	}
+	return u
+}
+
+// ─── Enforcement ───
+
+// quotaDenial is why a request was refused: the quota it would exceed.
+type quotaDenial struct {
+	status int // 429 for a daily quota, 402 for the monthly LLM tokens
+	quota  string
+	usage  QuotaUsage
+}
+
+func (d *quotaDenial) Error() string {
+	if d.quota == quotaLLMTokens {
+		return fmt.Sprintf("monthly LLM token quota exhausted (%d of %d used)", d.usage.Used, d.usage.Limit)
+	}
+	return fmt.Sprintf("daily %s quota exceeded (%d of %d used)", d.quota, d.usage.Used, d.usage.Limit)
+}
+
+// retryAfter is the time until the quota resets, at least a second.
+func (d *quotaDenial) retryAfter() int {
+	return max(int(math.Ceil(time.Until(d.usage.ResetsAt).Seconds())), 1)
+}
+
+// checkQuota decides whether ctx's organization may make n more uploads or
+// searches (quota). Both run the LLM, so once the month's LLM tokens are used
+// up neither is admitted. warnings are the quotas this request takes past
+// their soft limit. Quotas are soft: tokens of work already queued still
+// count after the limit is reached.
+func (a *API) checkQuota(ctx context.Context, quota string, n int) (denial *quotaDenial, warnings []string, err error) {
+	usage, err := a.orgUsage(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	denial, warnings = decideQuota(usage, quota, n)
+	return denial, warnings, nil
+}
+
+// decideQuota is checkQuota's decision on usage. A daily quota is checked
+// before the monthly LLM tokens, so a request over both gets 429.
+func decideQuota(usage *UsageResponse, quota string, n int) (denial *quotaDenial, warnings []string) {
+	counted := usage.Uploads
+	if quota == quotaSearches {
+		counted = usage.Searches
+	}
+	if counted.Limit > 0 && counted.Used+int64(n) > counted.Limit {
+		return &quotaDenial{status: http.StatusTooManyRequests, quota: quota, usage: counted}, nil
+	}
+	if t := usage.LLMTokens; t.Limit > 0 && t.Used >= t.Limit {
+		return &quotaDenial{status: http.StatusPaymentRequired, quota: quotaLLMTokens, usage: t}, nil
+	}
+	if counted.SoftLimit > 0 && counted.Used+int64(n) >= counted.SoftLimit {
+		warnings = append(warnings, fmt.Sprintf("%s=%d/%d", quota, counted.Used+int64(n), counted.Limit))
+	}
+	if t := usage.LLMTokens; t.SoftLimit > 0 && t.Used >= t.SoftLimit {
+		warnings = append(warnings, fmt.Sprintf("%s=%d/%d", quotaLLMTokens, t.Used, t.Limit))
+	}
+	return nil, warnings
+}
+
+// admitQuota refuses a request that would take its organization over quota
+// (see checkQuota) with 429 or 402 and a Retry-After until the quota resets,
+// and returns false. An admitted request near a quota gets an
+// X-Quota-Warning header. If usage can't be read the request is admitted.
+func (a *API) admitQuota(w http.ResponseWriter, r *http.Request, quota string, n int) bool {
+	denial, warnings, err := a.checkQuota(r.Context(), quota, n)
+	if err != nil {
+		log.Printf("[Quota] %v", err)
+		return true
+	}
+	if denial != nil {
+		retry := denial.retryAfter()
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", strconv.Itoa(retry))
+		w.WriteHeader(denial.status)
+		json.NewEncoder(w).Encode(quotaExceededResponse{
+			ErrorResponse:     ErrorResponse{Error: denial.Error(), Status: denial.status},
+			Quota:             denial.quota,
+			Usage:             denial.usage,
+			RetryAfterSeconds: retry,
+		})
+		return false
+	}
+	if len(warnings) > 0 {
+		w.Header().Set("X-Quota-Warning", strings.Join(warnings, ", "))
+	}
+	return true
+}
+
+// countUsage adds uploads and searches to ctx's organization's usage.
+func (a *API) countUsage(ctx context.Context, uploads, searches int) {
+	if err := a.db.AddOrgUsage(ctx, uploads, searches); err != nil {
+		log.Printf("[Quota] %v", err)
+	}
+}
+
+// meteredSearch holds a search endpoint to the searches quota: it is refused
+// over quota, and counted when it answers without an error.
+func (a *API) meteredSearch(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !a.admitQuota(w, r, quotaSearches, 1) {
+			return
+		}
+		sw := &statusWriter{ResponseWriter: w}
+		next(sw, r)
+		if sw.status < 400 {
+			a.countUsage(r.Context(), 0, 1)
+		}
+	}
+}
+
+// statusWriter remembers the status a handler answered with.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// ─── Handlers ───
+
+// UsageHandler shows the caller's organization what it used today and this
+// month, against its quotas.
+//
+//	GET /api/usage
+func (a *API) UsageHandler(w http.ResponseWriter, r *http.Request) {
+	usage, err := a.orgUsage(r.Context())
+	if err != nil {
+		log.Printf("[Quota] %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	if usage.LLMUsage, err = a.db.ListOrgLLMUsageThisMonth(r.Context()); err != nil {
+		log.Printf("[Quota] %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(usage)
+}
+
+// GetOrgQuotasHandler shows an organization's own quotas and the ones it is
+// held to.
+//
+//	GET /api/admin/orgs/{id}/quotas
+func (a *API) GetOrgQuotasHandler(w http.ResponseWriter, r *http.Request) {
+	orgID := a.orgIDFromPath(w, r)
+	if orgID == 0 {
+		return
+	}
+	a.writeOrgQuotas(w, r, orgID)
+}
+
+// PutOrgQuotasHandler sets an organization's own quotas. A null limit keeps
+// the deployment's default, 0 is unlimited.
+//
+//	PUT /api/admin/orgs/{id}/quotas {"searches_per_day": 500, "llm_tokens_per_month": 5000000}
+func (a *API) PutOrgQuotasHandler(w http.ResponseWriter, r *http.Request) {
+	orgID := a.orgIDFromPath(w, r)
+	if orgID == 0 {
+		return
+	}
+	var req orgQuotasRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if (req.UploadsPerDay != nil && *req.UploadsPerDay < 0) ||
+		(req.SearchesPerDay != nil && *req.SearchesPerDay < 0) ||
+		(req.LLMTokensPerMonth != nil && *req.LLMTokensPerMonth < 0) {
+		http.Error(w, "quotas must be 0 (unlimited) or more", http.StatusBadRequest)
+		return
+	}
+	q := &storage.OrgQuotas{
+		OrgID:             orgID,
+		UploadsPerDay:     req.UploadsPerDay,
+		SearchesPerDay:    req.SearchesPerDay,
+		LLMTokensPerMonth: req.LLMTokensPerMonth,
+	}
+	if err := a.db.SaveOrgQuotas(r.Context(), q); err != nil {
+		log.Printf("[Quota] %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	a.audit(r, "update", "organization_quotas", strconv.Itoa(orgID), req)
+	a.writeOrgQuotas(w, r, orgID)
+}
+
+// DeleteOrgQuotasHandler puts an organization back on the deployment's
+// default quotas.
+//
+//	DELETE /api/admin/orgs/{id}/quotas
+func (a *API) DeleteOrgQuotasHandler(w http.ResponseWriter, r *http.Request) {
+	orgID := a.orgIDFromPath(w, r)
+	if orgID == 0 {
+		return
+	}
+	deleted, err := a.db.DeleteOrgQuotas(r.Context(), orgID)
+	if err != nil {
+		log.Printf("[Quota] %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	if deleted {
+		a.audit(r, "delete", "organization_quotas", strconv.Itoa(orgID), nil)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) writeOrgQuotas(w http.ResponseWriter, r *http.Request, orgID int) {
+	limits, own, err := a.quotaLimits(r.Context(), orgID)
+	if err != nil {
+		log.Printf("[Quota] %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	if own == nil {
+		own = &storage.OrgQuotas{OrgID: orgID}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(orgQuotasResponse{OrgQuotas: own, Effective: limits})
+}
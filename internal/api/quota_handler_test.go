@@ -0,0 +1,137 @@
+package api
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"cv-search/internal/config"
+)
+
+func TestQuotaUsage(t *testing.T) {
+	a := &API{cfg: &config.Config{QuotaSoftPercent: 80}}
+	resets := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		used, limit   int64
+		wantRemaining int64 // -1 = unlimited
+		wantSoft      int64
+	}{
+		{used: 10, limit: 100, wantRemaining: 90, wantSoft: 80},
+		{used: 120, limit: 100, wantRemaining: 0, wantSoft: 80},
+		{used: 0, limit: 7, wantRemaining: 7, wantSoft: 6}, // 5.6 rounds up
+		{used: 500, limit: 0, wantRemaining: -1, wantSoft: 0},
+	}
+	for _, tt := range tests {
+		u := a.quotaUsage(tt.used, tt.limit, "day", resets)
+		if tt.wantRemaining < 0 {
+			if u.Remaining != nil {
+				t.Errorf("%d of %d: remaining %d, want unlimited", tt.used, tt.limit, *u.Remaining)
+			}
+		} else if u.Remaining == nil || *u.Remaining != tt.wantRemaining {
+			t.Errorf("%d of %d: remaining %v, want %d", tt.used, tt.limit, u.Remaining, tt.wantRemaining)
+		}
+		if u.SoftLimit != tt.wantSoft {
+			t.Errorf("%d of %d: soft limit %d, want %d", tt.used, tt.limit, u.SoftLimit, tt.wantSoft)
+		}
+	}
+}
+
+func TestDecideQuota(t *testing.T) {
+	a := &API{cfg: &config.Config{QuotaSoftPercent: 80}}
+	dayEnds := time.Now().Add(time.Hour)
+	monthEnds := time.Now().Add(10 * 24 * time.Hour)
+	usage := func(uploads, searches, tokens int64) *UsageResponse {
+		return &UsageResponse{
+			Uploads:   a.quotaUsage(uploads, 100, "day", dayEnds),
+			Searches:  a.quotaUsage(searches, 50, "day", dayEnds),
+			LLMTokens: a.quotaUsage(tokens, 1000, "month", monthEnds),
+		}
+	}
+
+	tests := []struct {
+		name         string
+		usage        *UsageResponse
+		quota        string
+		n            int
+		wantStatus   int // 0 = admitted
+		wantQuota    string
+		wantWarnings []string
+	}{
+		{name: "well under", usage: usage(10, 10, 100), quota: quotaUploads, n: 1},
+		{name: "reaching the limit", usage: usage(99, 0, 0), quota: quotaUploads, n: 1,
+			wantWarnings: []string{"uploads=100/100"}},
+		{name: "batch over the daily limit", usage: usage(90, 0, 0), quota: quotaUploads, n: 20,
+			wantStatus: http.StatusTooManyRequests, wantQuota: quotaUploads},
+		{name: "searches counted on their own", usage: usage(100, 10, 0), quota: quotaSearches, n: 1},
+		{name: "searches over", usage: usage(0, 50, 0), quota: quotaSearches, n: 1,
+			wantStatus: http.StatusTooManyRequests, wantQuota: quotaSearches},
+		{name: "LLM tokens used up", usage: usage(0, 0, 1000), quota: quotaSearches, n: 1,
+			wantStatus: http.StatusPaymentRequired, wantQuota: quotaLLMTokens},
+		{name: "LLM tokens past the limit", usage: usage(0, 0, 1400), quota: quotaUploads, n: 1,
+			wantStatus: http.StatusPaymentRequired, wantQuota: quotaLLMTokens},
+		{name: "daily quota before LLM tokens", usage: usage(100, 0, 1000), quota: quotaUploads, n: 1,
+			wantStatus: http.StatusTooManyRequests, wantQuota: quotaUploads},
+		{name: "just under the soft limit", usage: usage(78, 0, 0), quota: quotaUploads, n: 1},
+		{name: "soft limit reached", usage: usage(79, 0, 0), quota: quotaUploads, n: 1,
+			wantWarnings: []string{"uploads=80/100"}},
+		{name: "batch crossing the soft limit", usage: usage(70, 0, 0), quota: quotaUploads, n: 15,
+			wantWarnings: []string{"uploads=85/100"}},
+		{name: "LLM tokens soft limit", usage: usage(0, 0, 800), quota: quotaSearches, n: 1,
+			wantWarnings: []string{"llm_tokens=800/1000"}},
+		{name: "both soft limits", usage: usage(0, 45, 950), quota: quotaSearches, n: 1,
+			wantWarnings: []string{"searches=46/50", "llm_tokens=950/1000"}},
+		{name: "unlimited", quota: quotaUploads, n: 1000, usage: &UsageResponse{
+			Uploads:   a.quotaUsage(5000, 0, "day", dayEnds),
+			LLMTokens: a.quotaUsage(1e9, 0, "month", monthEnds),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			denial, warnings := decideQuota(tt.usage, tt.quota, tt.n)
+			if tt.wantStatus == 0 {
+				if denial != nil {
+					t.Fatalf("refused with %d (%v), want admitted", denial.status, denial)
+				}
+			} else if denial == nil {
+				t.Fatalf("admitted, want %d", tt.wantStatus)
+			} else if denial.status != tt.wantStatus || denial.quota != tt.wantQuota {
+				t.Errorf("refused with %d on %s, want %d on %s", denial.status, denial.quota, tt.wantStatus, tt.wantQuota)
+			}
+			if !slices.Equal(warnings, tt.wantWarnings) {
+				t.Errorf("warnings %q, want %q", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestQuotaDenialRetryAfter(t *testing.T) {
+	tests := []struct {
+		name     string
+		resetsIn time.Duration
+		want     int
+	}{
+		{"a minute and a half", 90 * time.Second, 90},
+		{"rounds up", 1500 * time.Millisecond, 2},
+		{"at least a second", 100 * time.Millisecond, 1},
+		{"already reset", -time.Minute, 1},
+	}
+	for _, tt := range tests {
+		d := &quotaDenial{usage: QuotaUsage{ResetsAt: time.Now().Add(tt.resetsIn)}}
+		if got := d.retryAfter(); got != tt.want {
+			t.Errorf("%s: retryAfter() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestQuotaDenialError(t *testing.T) {
+	daily := &quotaDenial{quota: quotaUploads, usage: QuotaUsage{Used: 100, Limit: 100}}
+	if got, want := daily.Error(), "daily uploads quota exceeded (100 of 100 used)"; got != want {
+		t.Errorf("daily: %q, want %q", got, want)
+	}
+	monthly := &quotaDenial{quota: quotaLLMTokens, usage: QuotaUsage{Used: 1200, Limit: 1000}}
+	if got, want := monthly.Error(), "monthly LLM token quota exhausted (1200 of 1000 used)"; got != want {
+		t.Errorf("monthly: %q, want %q", got, want)
+	}
+}
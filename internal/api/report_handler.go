@@ -0,0 +1,77 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"cv-search/internal/report"
+)
+
+// Shortlist report size: candidates per report by default and at most.
+const (
+	defaultReportCandidates = 10
+	maxReportCandidates     = 50
+)
+
+// ShortlistReportHandler runs a hybrid search and renders its top candidates
+// as a shareable, anonymized shortlist report for hiring managers without
+// access to the system.
+//
+// The request body is the same as /api/search/hybrid's. Query parameters:
+// format (markdown | html | pdf, default markdown), limit (candidates,
+// default 10, at most 50) and title.
+//
+// POST /api/search/hybrid/report
+func (a *API) ShortlistReportHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	format := strings.ToLower(q.Get("format"))
+	if format == "" {
+		format = report.FormatMarkdown
+	}
+	if !slices.Contains(report.Formats, format) {
+		http.Error(w, "format must be one of "+strings.Join(report.Formats, ", "), http.StatusBadRequest)
+		return
+	}
+	limit := defaultReportCandidates
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxReportCandidates {
+			http.Error(w, fmt.Sprintf("limit must be between 1 and %d", maxReportCandidates), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	title := strings.TrimSpace(q.Get("title"))
+
+	ai, req, config, ok := a.parseHybridSearch(w, r)
+	if !ok {
+		return
+	}
+	response, err := a.runHybridSearch(r.Context(), ai, req, config)
+	if err != nil {
+		http.Error(w, "Search failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	shortlist := report.NewShortlist(title, req.Query, response.SearchID, response.results, limit)
+	var buf bytes.Buffer
+	if err := report.Render(&buf, format, shortlist); err != nil {
+		log.Printf("[Report] render %s: %v", format, err)
+		http.Error(w, "failed to render report", http.StatusInternalServerError)
+		return
+	}
+
+	contentType, ext := report.ContentType(format)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="shortlist-%s.%s"`, time.Now().UTC().Format("20060102-1504"), ext))
+	if len(response.Warnings) > 0 {
+		w.Header().Set("X-Search-Warning", strings.Join(response.Warnings, ", "))
+	}
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,230 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"cv-search/internal/tenant"
+)
+
+// Keys of the response cache. A response is stored under the generations
+// current when it was made; a write bumps its organization's generation
+// (the statistics views' refresh bumps everyone's), so stale responses are
+// never read again and expire on their own.
+const (
+	responseKeyPrefix     = "cvsearch:resp:"
+	responseGenAll        = "cvsearch:gen:all"
+	responseGenOrgPrefix  = "cvsearch:gen:org:"
+	maxCachedRequestBytes = 64 << 10 // larger search bodies aren't cached
+)
+
+// cachedResponse is a stored 200 response.
+type cachedResponse struct {
+	ContentType string `json:"content_type"`
+	ETag        string `json:"etag"`
+	Body        []byte `json:"body"`
+}
+
+// cachedBodies adapts an endpoint's bodies to the response cache: store
+// returns what is cached of a fresh response, without fields that belong to
+// that one request, and replay the body a hit answers with.
+type cachedBodies struct {
+	store  func(body []byte) []byte
+	replay func(r *http.Request, body []byte) []byte
+}
+
+// cacheResponses serves an expensive read endpoint from the response cache
+// (RESPONSE_CACHE) for ttl: GET requests by URL, POST searches by their
+// exact body, per organization. Responses carry an ETag and
+// Cache-Control: private, max-age; a matching If-None-Match gets 304, and a
+// request with Cache-Control: no-cache skips the cached copy. X-Cache says
+// whether the response came from the cache. A handler keeps a response out
+// of the cache with Cache-Control: no-store (a degraded search).
+func (a *API) cacheResponses(ttl time.Duration, next http.HandlerFunc) http.HandlerFunc {
+	return a.cacheResponsesWith(ttl, cachedBodies{}, next)
+}
+
+// cacheResponsesWith is cacheResponses with bodies adapted by bodies.
+func (a *API) cacheResponsesWith(ttl time.Duration, bodies cachedBodies, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if a.responses == nil || ttl <= 0 || (r.Method != http.MethodGet && r.Method != http.MethodPost) {
+			next(w, r)
+			return
+		}
+		key, ok := a.responseCacheKey(r)
+		if !ok {
+			next(w, r)
+			return
+		}
+		maxAge := int(ttl.Seconds())
+
+		if !strings.Contains(r.Header.Get("Cache-Control"), "no-cache") {
+			data, found, err := a.responses.Get(r.Context(), key)
+			if err != nil {
+				log.Printf("[ResponseCache] %v", err)
+			}
+			var cached cachedResponse
+			if found && json.Unmarshal(data, &cached) == nil {
+				w.Header().Set("X-Cache", "HIT")
+				body := cached.Body
+				if bodies.replay != nil && !etagMatches(r.Header.Get("If-None-Match"), cached.ETag) {
+					body = bodies.replay(r, body)
+				}
+				writeCachedResponse(w, r, &cached, body, maxAge)
+				return
+			}
+		}
+
+		rec := &responseRecorder{header: w.Header()}
+		next(rec, r)
+		if (rec.status != 0 && rec.status != http.StatusOK) || w.Header().Get("Cache-Control") == "no-store" {
+			if rec.status != 0 {
+				w.WriteHeader(rec.status)
+			}
+			w.Write(rec.body.Bytes())
+			return
+		}
+
+		stored := rec.body.Bytes()
+		if bodies.store != nil {
+			stored = bodies.store(stored)
+		}
+		sum := sha256.Sum256(stored)
+		resp := &cachedResponse{
+			ContentType: w.Header().Get("Content-Type"),
+			ETag:        `"` + hex.EncodeToString(sum[:16]) + `"`,
+			Body:        stored,
+		}
+		if data, err := json.Marshal(resp); err == nil {
+			if err := a.responses.Set(r.Context(), key, data, ttl); err != nil {
+				log.Printf("[ResponseCache] %v", err)
+			}
+		}
+		w.Header().Set("X-Cache", "MISS")
+		writeCachedResponse(w, r, resp, rec.body.Bytes(), maxAge)
+	}
+}
+
+// writeCachedResponse answers with body, resp's as sent, or 304 if the
+// client has it.
+func writeCachedResponse(w http.ResponseWriter, r *http.Request, resp *cachedResponse, body []byte, maxAge int) {
+	h := w.Header()
+	if resp.ContentType != "" {
+		h.Set("Content-Type", resp.ContentType)
+	}
+	h.Set("ETag", resp.ETag)
+	h.Set("Cache-Control", "private, max-age="+strconv.Itoa(maxAge))
+	h.Add("Vary", "X-API-Key, Authorization") // one organization's answer
+	if etagMatches(r.Header.Get("If-None-Match"), resp.ETag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Write(body)
+}
+
+// etagMatches reports whether an If-None-Match header names etag.
+func etagMatches(header, etag string) bool {
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimPrefix(strings.TrimSpace(t), "W/")
+		if t == etag || t == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// responseCacheKey is where r's response is cached: its organization, the
+// current generations, and a hash of the method, path, sorted query and
+// body. ok is false when r can't be cached (body too large or unreadable).
+func (a *API) responseCacheKey(r *http.Request) (key string, ok bool) {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s?%s\n", r.Method, r.URL.Path, r.URL.Query().Encode())
+	if r.Method == http.MethodPost {
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxCachedRequestBytes+1))
+		r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+		if err != nil || len(body) > maxCachedRequestBytes {
+			return "", false
+		}
+		h.Write(body)
+	}
+
+	orgID := tenant.OrgID(r.Context())
+	all := a.responseGeneration(r.Context(), responseGenAll)
+	org := a.responseGeneration(r.Context(), responseGenOrgPrefix+strconv.Itoa(orgID))
+	return fmt.Sprintf("%s%d:%d.%d:%x", responseKeyPrefix, orgID, all, org, h.Sum(nil)), true
+}
+
+func (a *API) responseGeneration(ctx context.Context, key string) int64 {
+	data, found, err := a.responses.Get(ctx, key)
+	if err != nil {
+		log.Printf("[ResponseCache] %v", err)
+	}
+	if !found {
+		return 0
+	}
+	n, _ := strconv.ParseInt(string(data), 10, 64)
+	return n
+}
+
+// responseRecorder holds a response until cacheResponses has decided
+// whether to store it. Headers go straight to the real writer.
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *responseRecorder) Header() http.Header { return w.header }
+
+func (w *responseRecorder) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *responseRecorder) Write(p []byte) (int, error) {
+	return w.body.Write(p)
+}
+
+// ─── Invalidation ───
+
+// invalidateResponses drops ctx's organization's cached responses after a
+// write they may reflect.
+func (a *API) invalidateResponses(ctx context.Context) {
+	a.bumpResponseGeneration(ctx, responseGenOrgPrefix+strconv.Itoa(tenant.OrgID(ctx)))
+}
+
+// invalidateAllResponses drops every organization's cached responses, after
+// the statistics views were rebuilt.
+func (a *API) invalidateAllResponses(ctx context.Context) {
+	a.bumpResponseGeneration(ctx, responseGenAll)
+}
+
+func (a *API) bumpResponseGeneration(ctx context.Context, key string) {
+	if a.responses == nil {
+		return
+	}
+	if _, err := a.responses.Incr(ctx, key); err != nil {
+		log.Printf("[ResponseCache] invalidate %s: %v", key, err)
+	}
+}
+
+// invalidateSearchCache drops ctx's organization's cached search results,
+// in the hybrid search engine and in the response cache, after a change
+// they may reflect.
+func (a *API) invalidateSearchCache(ctx context.Context) {
+	if hybrid := a.ai(ctx).hybridSearchEngine; hybrid != nil {
+		hybrid.InvalidateResultCache()
+	}
+	a.invalidateResponses(ctx)
+}
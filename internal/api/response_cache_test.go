@@ -0,0 +1,237 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"cv-search/internal/cache"
+	"cv-search/internal/tenant"
+)
+
+func newResponseCacheAPI() *API {
+	return &API{responses: cache.NewMemory(100)}
+}
+
+// orgRequest is a request made by orgID's API key.
+func orgRequest(orgID int, method, target, body string) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	return r.WithContext(tenant.WithOrg(r.Context(), orgID))
+}
+
+func mustResponseCacheKey(t *testing.T, a *API, r *http.Request) string {
+	t.Helper()
+	key, ok := a.responseCacheKey(r)
+	if !ok {
+		t.Fatalf("%s %s: not cacheable", r.Method, r.URL)
+	}
+	return key
+}
+
+func TestResponseCacheKey(t *testing.T) {
+	a := newResponseCacheAPI()
+	ctx := tenant.WithOrg(context.Background(), 1)
+
+	org1 := mustResponseCacheKey(t, a, orgRequest(1, http.MethodGet, "/api/candidates?page=2&limit=20", ""))
+	if got := mustResponseCacheKey(t, a, orgRequest(1, http.MethodGet, "/api/candidates?limit=20&page=2", "")); got != org1 {
+		t.Errorf("reordered query: key %q, want %q", got, org1)
+	}
+	org2 := mustResponseCacheKey(t, a, orgRequest(2, http.MethodGet, "/api/candidates?page=2&limit=20", ""))
+	if org2 == org1 {
+		t.Error("two organizations share a key")
+	}
+	if got := mustResponseCacheKey(t, a, orgRequest(1, http.MethodGet, "/api/candidates?page=3&limit=20", "")); got == org1 {
+		t.Error("different queries share a key")
+	}
+
+	search := mustResponseCacheKey(t, a, orgRequest(1, http.MethodPost, "/api/search", `{"query":"go"}`))
+	if got := mustResponseCacheKey(t, a, orgRequest(1, http.MethodPost, "/api/search", `{"query":"rust"}`)); got == search {
+		t.Error("different search bodies share a key")
+	}
+
+	// A write bumps its organization's generation only.
+	a.invalidateResponses(ctx)
+	if got := mustResponseCacheKey(t, a, orgRequest(1, http.MethodGet, "/api/candidates?page=2&limit=20", "")); got == org1 {
+		t.Error("organization 1's key survived its invalidation")
+	}
+	if got := mustResponseCacheKey(t, a, orgRequest(2, http.MethodGet, "/api/candidates?page=2&limit=20", "")); got != org2 {
+		t.Errorf("organization 2's key changed with organization 1's invalidation: %q, want %q", got, org2)
+	}
+
+	// The statistics refresh bumps everyone's.
+	a.invalidateAllResponses(ctx)
+	if got := mustResponseCacheKey(t, a, orgRequest(2, http.MethodGet, "/api/candidates?page=2&limit=20", "")); got == org2 {
+		t.Error("organization 2's key survived invalidateAllResponses")
+	}
+}
+
+func TestResponseCacheKeyLargeBody(t *testing.T) {
+	a := newResponseCacheAPI()
+	body := `{"query":"` + strings.Repeat("x", maxCachedRequestBytes) + `"}`
+	r := orgRequest(1, http.MethodPost, "/api/search", body)
+	if _, ok := a.responseCacheKey(r); ok {
+		t.Fatal("a body over maxCachedRequestBytes is cacheable")
+	}
+	// The handler still gets the whole body.
+	got, err := io.ReadAll(r.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != body {
+		t.Errorf("body after responseCacheKey: %d bytes, want %d", len(got), len(body))
+	}
+}
+
+// countingHandler answers with body and counts its calls.
+func countingHandler(calls *int, body string, header map[string]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		w.Header().Set("Content-Type", "application/json")
+		for k, v := range header {
+			w.Header().Set(k, v)
+		}
+		io.WriteString(w, body)
+	}
+}
+
+func serveCached(h http.HandlerFunc, r *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h(rec, r)
+	return rec
+}
+
+func TestCacheResponses(t *testing.T) {
+	a := newResponseCacheAPI()
+	calls := 0
+	h := a.cacheResponses(time.Minute, countingHandler(&calls, `{"total":3}`, nil))
+
+	miss := serveCached(h, orgRequest(1, http.MethodGet, "/api/stats", ""))
+	if got := miss.Header().Get("X-Cache"); got != "MISS" {
+		t.Errorf("first request: X-Cache = %q, want MISS", got)
+	}
+	etag := miss.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("no ETag on a cached response")
+	}
+	if got := miss.Header().Get("Cache-Control"); got != "private, max-age=60" {
+		t.Errorf("Cache-Control = %q", got)
+	}
+
+	hit := serveCached(h, orgRequest(1, http.MethodGet, "/api/stats", ""))
+	if got := hit.Header().Get("X-Cache"); got != "HIT" {
+		t.Errorf("second request: X-Cache = %q, want HIT", got)
+	}
+	if hit.Body.String() != `{"total":3}` || hit.Header().Get("ETag") != etag {
+		t.Errorf("hit: body %q, ETag %q; want the first response's", hit.Body, hit.Header().Get("ETag"))
+	}
+	if calls != 1 {
+		t.Errorf("handler ran %d times, want 1", calls)
+	}
+
+	// Another organization gets its own answer.
+	serveCached(h, orgRequest(2, http.MethodGet, "/api/stats", ""))
+	if calls != 2 {
+		t.Errorf("handler ran %d times after another organization's request, want 2", calls)
+	}
+
+	for _, inm := range []string{etag, "W/" + etag, `"other", ` + etag, "*"} {
+		r := orgRequest(1, http.MethodGet, "/api/stats", "")
+		r.Header.Set("If-None-Match", inm)
+		rec := serveCached(h, r)
+		if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+			t.Errorf("If-None-Match %s: status %d with %d bytes, want an empty 304", inm, rec.Code, rec.Body.Len())
+		}
+	}
+	r := orgRequest(1, http.MethodGet, "/api/stats", "")
+	r.Header.Set("If-None-Match", `"other"`)
+	if rec := serveCached(h, r); rec.Code != http.StatusOK {
+		t.Errorf("If-None-Match with another ETag: status %d, want 200", rec.Code)
+	}
+
+	// no-cache skips the cached copy.
+	r = orgRequest(1, http.MethodGet, "/api/stats", "")
+	r.Header.Set("Cache-Control", "no-cache")
+	if rec := serveCached(h, r); rec.Header().Get("X-Cache") != "MISS" || calls != 3 {
+		t.Errorf("no-cache: X-Cache %q after %d calls, want MISS after 3", rec.Header().Get("X-Cache"), calls)
+	}
+
+	// A write invalidates.
+	a.invalidateResponses(tenant.WithOrg(context.Background(), 1))
+	if rec := serveCached(h, orgRequest(1, http.MethodGet, "/api/stats", "")); rec.Header().Get("X-Cache") != "MISS" {
+		t.Errorf("after invalidateResponses: X-Cache = %q, want MISS", rec.Header().Get("X-Cache"))
+	}
+}
+
+func TestCacheResponsesBypass(t *testing.T) {
+	tests := []struct {
+		name    string
+		request func() *http.Request
+		handler func(calls *int) http.HandlerFunc
+	}{
+		{
+			name:    "no-store response",
+			request: func() *http.Request { return orgRequest(1, http.MethodPost, "/api/search", `{"query":"go"}`) },
+			handler: func(calls *int) http.HandlerFunc {
+				return countingHandler(calls, `{"degraded":true}`, map[string]string{"Cache-Control": "no-store"})
+			},
+		},
+		{
+			name: "POST body over maxCachedRequestBytes",
+			request: func() *http.Request {
+				return orgRequest(1, http.MethodPost, "/api/search", `{"query":"`+strings.Repeat("x", maxCachedRequestBytes)+`"}`)
+			},
+			handler: func(calls *int) http.HandlerFunc { return countingHandler(calls, `{"results":[]}`, nil) },
+		},
+		{
+			name:    "error response",
+			request: func() *http.Request { return orgRequest(1, http.MethodGet, "/api/stats", "") },
+			handler: func(calls *int) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					*calls++
+					http.Error(w, "database unavailable", http.StatusServiceUnavailable)
+				}
+			},
+		},
+		{
+			name:    "DELETE",
+			request: func() *http.Request { return orgRequest(1, http.MethodDelete, "/api/candidates/1", "") },
+			handler: func(calls *int) http.HandlerFunc { return countingHandler(calls, `{}`, nil) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newResponseCacheAPI()
+			calls := 0
+			h := a.cacheResponses(time.Minute, tt.handler(&calls))
+			for i := 0; i < 2; i++ {
+				if rec := serveCached(h, tt.request()); rec.Header().Get("X-Cache") == "HIT" {
+					t.Errorf("request %d: served from the cache", i+1)
+				}
+			}
+			if calls != 2 {
+				t.Errorf("handler ran %d times, want 2", calls)
+			}
+		})
+	}
+}
+
+func TestCacheResponsesWithBodies(t *testing.T) {
+	a := newResponseCacheAPI()
+	calls := 0
+	bodies := cachedBodies{
+		store:  func(body []byte) []byte { return []byte(strings.ReplaceAll(string(body), "run-1", "")) },
+		replay: func(r *http.Request, body []byte) []byte { return append(body, " replayed"...) },
+	}
+	h := a.cacheResponsesWith(time.Minute, bodies, countingHandler(&calls, `{"run":"run-1"}`, nil))
+
+	if rec := serveCached(h, orgRequest(1, http.MethodGet, "/api/search", "")); rec.Body.String() != `{"run":"run-1"}` {
+		t.Errorf("miss: body %q, want the handler's", rec.Body)
+	}
+	if rec := serveCached(h, orgRequest(1, http.MethodGet, "/api/search", "")); rec.Body.String() != `{"run":""} replayed` {
+		t.Errorf("hit: body %q, want the stored body replayed", rec.Body)
+	}
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strconv"
+	"time"
+
+	"cv-search/internal/storage"
+	"cv-search/internal/tenant"
+)
+
+// One resume fetch pass downloads at most resumeFetchBatch resumes; a
+// claimed resume isn't retried for resumeFetchLease, and a failed one waits
+// resumeFetchBackoff, doubling per attempt.
+const (
+	resumeFetchBatch   = 20
+	resumeFetchLease   = 30 * time.Minute
+	resumeFetchBackoff = time.Hour
+)
+
+// resumeFetchWorker downloads the resume_url of candidates that have one but
+// no downloaded resume (an import's download failed, or they were saved with
+// a link only) and queues the CVs for extraction like any upload.
+func (a *API) resumeFetchWorker() {
+	log.Println("[ResumeFetch] Started")
+	ticker := time.NewTicker(a.cfg.ResumeFetchInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		a.fetchPendingResumes(context.Background())
+	}
+}
+
+// fetchPendingResumes runs one resume fetch pass.
+func (a *API) fetchPendingResumes(ctx context.Context) {
+	pending, err := a.db.ClaimPendingResumes(ctx, resumeFetchBatch, a.cfg.ResumeFetchMaxAttempts, resumeFetchLease)
+	if err != nil {
+		log.Printf("[ResumeFetch] %v", err)
+		return
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	var jobs []CVProcessingJob
+	failed := 0
+	for _, p := range pending {
+		ctx := tenant.WithOrg(ctx, p.OrgID)
+		res, err := a.ingestResume(ctx, p.CandidateID, p.URL)
+		if err != nil {
+			failed++
+			retryAfter := resumeFetchBackoff << min(p.Attempts, 5)
+			log.Printf("[ResumeFetch] candidate %d (attempt %d/%d): %v",
+				p.CandidateID, p.Attempts+1, a.cfg.ResumeFetchMaxAttempts, err)
+			if err := a.db.RecordResumeFetchFailure(ctx, p.CandidateID, err.Error(), retryAfter); err != nil {
+				log.Printf("[ResumeFetch] %v", err)
+			}
+			continue
+		}
+		if res.job == nil {
+			continue // already uploaded
+		}
+		details, _ := json.Marshal(map[string]interface{}{
+			"filename": res.filename, "file_size": res.fileSize, "job_id": res.job.JobID,
+			"candidate_id": p.CandidateID, "resume_url": p.URL,
+		})
+		if err := a.db.LogAudit(ctx, storage.AuditEntry{
+			Actor:      "system:resume-fetch",
+			Action:     "upload",
+			EntityType: "cv_file",
+			EntityID:   strconv.FormatInt(res.cvID, 10),
+			Details:    details,
+		}); err != nil {
+			log.Printf("[Audit] %v", err)
+		}
+		jobs = append(jobs, *res.job)
+	}
+
+	batchAPI, accepted := a.dispatchCVJobs(ctx, jobs)
+	queued := 0
+	for _, ok := range accepted {
+		if ok {
+			queued++
+		}
+	}
+	log.Printf("[ResumeFetch] %d due: %d queued for extraction, %d already uploaded, %d failed (batch_api=%v)",
+		len(pending), queued, len(pending)-len(jobs)-failed, failed, batchAPI)
+}
@@ -1,30 +1,25 @@
 package api
 
 import (
+	"encoding/json"
 	"net/http"
-	"os"
 	"strings"
 
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
 // corsMiddleware adds CORS headers to every response.
-// Allowed origins are read from the CORS_ORIGINS env variable as a comma-separated list.
-// Examples:
+// Allowed origins come from the CORS_ORIGINS env variable (config.CORSOrigins),
+// a comma-separated list. Examples:
 //
 //	CORS_ORIGINS=*                                          → allow all (dev default)
 //	CORS_ORIGINS=https://app.example.com                   → single origin
 //	CORS_ORIGINS=https://app.example.com,https://admin.example.com → multiple origins
-func corsMiddleware(next http.Handler) http.Handler {
-	raw := os.Getenv("CORS_ORIGINS")
-	if raw == "" {
-		raw = "*"
-	}
-
+func corsMiddleware(origins []string, next http.Handler) http.Handler {
 	// Parse once at startup into a set for O(1) lookup.
-	wildcard := false
+	wildcard := len(origins) == 0
 	allowedSet := make(map[string]struct{})
-	for _, o := range strings.Split(raw, ",") {
+	for _, o := range origins {
 		o = strings.TrimSpace(o)
 		if o == "*" {
 			wildcard = true
@@ -46,7 +41,7 @@ func corsMiddleware(next http.Handler) http.Handler {
 		}
 
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With, X-API-Key, X-Admin-Key, X-User-ID, Idempotency-Key")
 		w.Header().Set("Access-Control-Max-Age", "86400")
 
 		// Handle preflight
@@ -62,48 +57,163 @@ func corsMiddleware(next http.Handler) http.Handler {
 func NewRouter(a *API) http.Handler {
 	mux := http.NewServeMux()
 
-	// Swagger documentation - must be registered first
+	// Swagger UI over the generated OpenAPI document - must be registered first
 	mux.Handle("/swagger/", httpSwagger.Handler(
-		httpSwagger.URL("/swagger/doc.json"),
+		httpSwagger.URL("/openapi.json"),
 	))
 
 	// Health check (for Railway, k8s, etc.)
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`{"status":"healthy"}`))
+		json.NewEncoder(w).Encode(healthResponse{Status: "healthy"})
 	})
 
+	// OpenAPI 3 document generated from the handlers' types (see openapi.go)
+	mux.HandleFunc("GET /openapi.json", a.OpenAPIHandler)
+
+	// Public, read-only candidate profiles behind signed share links; the
+	// token is the credential, so this is outside /api/
+	mux.HandleFunc("GET /share/{token}", a.SharedProfileHandler)
+
 	// API endpoints
-	mux.HandleFunc("/api/search", a.SearchHandler)
+	mux.HandleFunc("/api/search", a.meteredSearch(a.SearchHandler))
 
 	// CV & Graph endpoints
+	mux.HandleFunc("GET /api/cv", a.ListCVFilesHandler) // ?quality=needs_review
 	mux.HandleFunc("/api/cv/upload", a.CVUploadHandler)
 	mux.HandleFunc("/api/cv/bulk-upload", a.BulkCVUploadHandler) // Bulk upload (up to 10 files)
 	mux.HandleFunc("/api/cv/batch/", a.GetBatchStatusHandler)    // Batch status
 	mux.HandleFunc("/api/cv/job/", a.GetJobStatusHandler)        // Job status endpoint
-	mux.HandleFunc("/api/graph/stats", a.GetGraphStatsHandler)
-	mux.HandleFunc("/api/graph/skills/popular", a.GetPopularSkillsHandler)
+	mux.HandleFunc("GET /api/cv/files/{id}/download", a.DownloadCVHandler)
+	mux.HandleFunc("GET /api/cv/files/{id}/changes", a.CVChangesHandler)
+	mux.HandleFunc("GET /api/cv/files/{id}/photo", a.CVPhotoHandler)
+
+	// Statistics: cached for RESPONSE_CACHE_TTL_SECONDS (ETag, Cache-Control)
+	statsTTL := a.cfg.ResponseCacheTTL
+	mux.HandleFunc("/api/graph/stats", a.cacheResponses(statsTTL, a.GetGraphStatsHandler))
+	mux.HandleFunc("/api/graph/skills/popular", a.cacheResponses(statsTTL, a.GetPopularSkillsHandler))
+	mux.HandleFunc("GET /api/graph/skills/trending", a.cacheResponses(statsTTL, a.GetTrendingSkillsHandler))
+	mux.HandleFunc("GET /api/graph/stats/skills-trend", a.cacheResponses(statsTTL, a.GetSkillTrendHandler))
+	mux.HandleFunc("GET /api/graph/stats/seniority", a.cacheResponses(statsTTL, a.GetSeniorityDistributionHandler))
+	mux.HandleFunc("GET /api/graph/stats/communities", a.cacheResponses(statsTTL, a.GetCommunitySizesHandler))
+	mux.HandleFunc("GET /api/graph/stats/uploads", a.cacheResponses(statsTTL, a.GetWeeklyUploadsHandler))
+	mux.HandleFunc("GET /api/graph/analytics", a.cacheResponses(statsTTL, a.GetGraphAnalyticsHandler))
+	mux.HandleFunc("POST /api/graph/analytics/refresh", a.RefreshGraphAnalyticsHandler) // recompute now
+	mux.HandleFunc("POST /api/graph/query", a.GraphQueryHandler)                        // graph query language, read-only
 
 	// GraphRAG endpoints
-	mux.HandleFunc("/api/graphrag/search", a.GraphRAGSearchHandler)
+	mux.HandleFunc("/api/graphrag/search", a.meteredSearch(a.GraphRAGSearchHandler))
+	mux.HandleFunc("POST /api/graphrag/search/stream", a.meteredSearch(a.GraphRAGSearchStreamHandler)) // Server-Sent Events: result, then summary
 	mux.HandleFunc("/api/graphrag/embeddings/generate", a.GenerateEmbeddingsHandler)
 	mux.HandleFunc("/api/graphrag/communities/detect", a.DetectCommunitiesHandler)
+	mux.HandleFunc("GET /api/graphrag/communities/changes", a.ListCommunityChangesHandler)
 
 	// Hybrid Search endpoint (BM25 + Vector + Graph + LLM)
-	mux.HandleFunc("/api/search/hybrid", a.HybridSearchHandler)
+	// Identical searches are answered from the response cache for RESPONSE_CACHE_SEARCH_TTL_SECONDS,
+	// each logged as a run of its own with a fresh search_id
+	mux.HandleFunc("/api/search/hybrid", withDryRun(a.HybridSearchPlanHandler,
+		a.meteredSearch(a.cacheResponsesWith(a.cfg.ResponseCacheSearchTTL, a.hybridSearchBodies(), a.HybridSearchHandler))))
+	mux.HandleFunc("POST /api/search/hybrid/stream", a.meteredSearch(a.HybridSearchStreamHandler)) // Server-Sent Events: progress, then result
+	mux.HandleFunc("POST /api/search/hybrid/report", a.meteredSearch(a.ShortlistReportHandler))    // Markdown, HTML or PDF
+
+	// Recruiter feedback on search results (good/bad/hired), exported for ranking tuning
+	mux.HandleFunc("POST /api/search/{search_id}/feedback", a.SearchFeedbackHandler)
+	mux.HandleFunc("GET /api/search/feedback/export", a.ExportSearchFeedbackHandler) // JSON Lines
+
+	// GraphQL (candidates, CV files, graph, communities, search)
+	mux.HandleFunc("POST /api/graphql", a.GraphQLHandler)
+
+	// Conversational search sessions (follow-ups refine the previous results)
+	mux.HandleFunc("POST /api/search/session", a.meteredSearch(a.CreateSearchSessionHandler))
+	mux.HandleFunc("GET /api/search/session/{id}", a.GetSearchSessionHandler)
+	mux.HandleFunc("POST /api/search/session/{id}/query", a.meteredSearch(a.SearchSessionQueryHandler))
 
 	// Candidate management + interview tracking
 	mux.HandleFunc("GET /api/candidates", a.ListCandidatesHandler)
 	mux.HandleFunc("GET /api/candidates/{id}", a.GetCandidateHandler)
+	mux.HandleFunc("DELETE /api/candidates/{id}", a.DeleteCandidateHandler)
+	mux.HandleFunc("POST /api/candidates/{id}/erase", a.EraseCandidateHandler)
+	mux.HandleFunc("GET /api/candidates/{id}/merges", a.ListCandidateMergesHandler)
+	mux.HandleFunc("POST /api/candidates/merge", a.MergeCandidatesHandler)
+	mux.HandleFunc("POST /api/candidates/import", a.ImportCandidatesHandler)
+	mux.HandleFunc("POST /api/candidates/merges/{id}/undo", a.UndoCandidateMergeHandler)
 	mux.HandleFunc("GET /api/candidates/{id}/similar", a.SimilarCandidatesHandler)
+	mux.HandleFunc("POST /api/candidates/{id}/gap-analysis", a.meteredSearch(a.GapAnalysisHandler))   // counts as a search
+	mux.HandleFunc("POST /api/candidates/{id}/interview-kit", a.meteredSearch(a.InterviewKitHandler)) // counts as a search
+	mux.HandleFunc("POST /api/candidates/{id}/outreach", a.meteredSearch(a.OutreachHandler))          // counts as a search
 	mux.HandleFunc("POST /api/candidates/{id}/interviews", a.CreateInterviewHandler)
 	mux.HandleFunc("PUT /api/candidates/{id}/interviews/{iid}", a.UpdateInterviewHandler)
 	mux.HandleFunc("DELETE /api/candidates/{id}/interviews/{iid}", a.DeleteInterviewHandler)
+	mux.HandleFunc("GET /api/candidates/{id}/notes", a.ListCandidateNotesHandler)
+	mux.HandleFunc("POST /api/candidates/{id}/notes", a.CreateCandidateNoteHandler)
+	mux.HandleFunc("PUT /api/candidates/{id}/notes/{nid}", a.UpdateCandidateNoteHandler)
+	mux.HandleFunc("DELETE /api/candidates/{id}/notes/{nid}", a.DeleteCandidateNoteHandler)
+	mux.HandleFunc("GET /api/candidates/{id}/tags", a.ListCandidateTagsHandler)
+	mux.HandleFunc("POST /api/candidates/{id}/tags", a.AddCandidateTagsHandler)
+	mux.HandleFunc("DELETE /api/candidates/{id}/tags/{tag}", a.RemoveCandidateTagHandler)
+	mux.HandleFunc("PUT /api/candidates/{id}/status", a.SetCandidateStatusHandler)
+	mux.HandleFunc("GET /api/candidates/{id}/status-history", a.ListCandidateStatusHistoryHandler)
+	mux.HandleFunc("POST /api/candidates/{id}/push", a.PushCandidateHandler)
+	mux.HandleFunc("GET /api/candidates/{id}/share-links", a.ListShareLinksHandler)
+	mux.HandleFunc("POST /api/candidates/{id}/share-links", a.CreateShareLinkHandler)
+	mux.HandleFunc("DELETE /api/candidates/{id}/share-links/{lid}", a.RevokeShareLinkHandler)
+
+	// Talent pools (shortlists with pipeline stages)
+	mux.HandleFunc("GET /api/pools", a.ListTalentPoolsHandler)
+	mux.HandleFunc("POST /api/pools", a.CreateTalentPoolHandler)
+	mux.HandleFunc("GET /api/pools/{id}", a.GetTalentPoolHandler)
+	mux.HandleFunc("PUT /api/pools/{id}", a.UpdateTalentPoolHandler)
+	mux.HandleFunc("DELETE /api/pools/{id}", a.DeleteTalentPoolHandler)
+	mux.HandleFunc("GET /api/pools/{id}/candidates", a.ListPoolCandidatesHandler)
+	mux.HandleFunc("POST /api/pools/{id}/candidates", a.AddPoolCandidatesHandler)
+	mux.HandleFunc("PUT /api/pools/{id}/candidates/{cid}", a.SetPoolCandidateStageHandler)
+	mux.HandleFunc("DELETE /api/pools/{id}/candidates/{cid}", a.RemovePoolCandidateHandler)
+
+	// Email notification preferences of the caller (X-User-ID)
+	mux.HandleFunc("GET /api/notifications/preferences", a.GetNotificationPreferencesHandler)
+	mux.HandleFunc("PUT /api/notifications/preferences", a.PutNotificationPreferencesHandler)
+	mux.HandleFunc("DELETE /api/notifications/preferences", a.DeleteNotificationPreferencesHandler)
+
+	// Uploads, searches and LLM tokens of the caller's organization vs. its quotas
+	mux.HandleFunc("GET /api/usage", a.UsageHandler)
+
+	// Search experiments (A/B ranking configurations)
+	mux.HandleFunc("GET /api/experiments", a.ListExperimentsHandler)
+	mux.HandleFunc("POST /api/experiments", a.UpsertExperimentHandler)
+
+	// Admin: audit trail of data mutations
+	mux.HandleFunc("GET /api/admin/audit-log", a.ListAuditLogHandler)
+	mux.HandleFunc("POST /api/admin/stats/refresh", a.RefreshStatsHandler)
+	mux.HandleFunc("GET /api/admin/overview", a.AdminOverviewHandler)
+
+	// Admin: organizations sharing the deployment (needs ADMIN_API_KEY)
+	mux.HandleFunc("GET /api/admin/orgs", a.requireAdminKey(a.ListOrganizationsHandler))
+	mux.HandleFunc("POST /api/admin/orgs", a.requireAdminKey(a.CreateOrganizationHandler))
+	mux.HandleFunc("POST /api/admin/orgs/{id}/key", a.requireAdminKey(a.RotateOrganizationKeyHandler))
+	mux.HandleFunc("GET /api/admin/orgs/{id}/ai-settings", a.requireAdminKey(a.GetOrgAISettingsHandler))
+	mux.HandleFunc("PUT /api/admin/orgs/{id}/ai-settings", a.requireAdminKey(a.PutOrgAISettingsHandler))
+	mux.HandleFunc("DELETE /api/admin/orgs/{id}/ai-settings", a.requireAdminKey(a.DeleteOrgAISettingsHandler))
+	mux.HandleFunc("GET /api/admin/orgs/{id}/quotas", a.requireAdminKey(a.GetOrgQuotasHandler))
+	mux.HandleFunc("PUT /api/admin/orgs/{id}/quotas", a.requireAdminKey(a.PutOrgQuotasHandler))
+	mux.HandleFunc("DELETE /api/admin/orgs/{id}/quotas", a.requireAdminKey(a.DeleteOrgQuotasHandler))
+	mux.HandleFunc("GET /api/admin/orgs/{id}/integrations", a.requireAdminKey(a.ListOrgIntegrationsHandler))
+	mux.HandleFunc("PUT /api/admin/orgs/{id}/integrations/{target}", a.requireAdminKey(a.PutOrgIntegrationHandler))
+	mux.HandleFunc("DELETE /api/admin/orgs/{id}/integrations/{target}", a.requireAdminKey(a.DeleteOrgIntegrationHandler))
+	mux.HandleFunc("GET /api/admin/orgs/{id}/community-patterns", a.requireAdminKey(a.ListCommunityPatternsHandler))
+	mux.HandleFunc("PUT /api/admin/orgs/{id}/community-patterns/{pattern}", a.requireAdminKey(a.PutCommunityPatternHandler))
+	mux.HandleFunc("DELETE /api/admin/orgs/{id}/community-patterns/{pattern}", a.requireAdminKey(a.DeleteCommunityPatternHandler))
+
+	// Background queue gauges: JSON for dashboards, Prometheus text format
+	mux.HandleFunc("GET /api/admin/queues", a.QueuesHandler)
+	mux.HandleFunc("GET /metrics", a.MetricsHandler)
 
 	// Autocomplete + popular queries
 	mux.HandleFunc("GET /api/search/suggest", a.SuggestHandler)
 	mux.HandleFunc("GET /api/search/popular-queries", a.PopularQueriesHandler)
 
-	return corsMiddleware(mux)
+	handler := errorEnvelopeMiddleware(a.adminMiddleware(a.orgMiddleware(a.idempotencyMiddleware(mux))))
+	if a.cfg.CompressResponses {
+		handler = compressMiddleware(handler)
+	}
+	return corsMiddleware(a.cfg.CORSOrigins, handler)
 }
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"cv-search/internal/graphrag"
+)
+
+// streamHeartbeat is how often an idle search stream sends a comment line,
+// so proxies don't close it while the LLM reranks.
+const streamHeartbeat = 15 * time.Second
+
+// ─── Request/Response types ───
+
+// SearchProgressEvent is the data of a "progress" event of a streamed
+// hybrid search.
+type SearchProgressEvent struct {
+	Stage     string `json:"stage"`            // embedding, retrieval, fusion, rerank
+	Source    string `json:"source,omitempty"` // bm25, vector or graph when one retrieval source finished
+	Done      bool   `json:"done"`             // false when the stage (or rerank batch) starts
+	Count     int    `json:"count,omitempty"`  // candidates the step produced, or is scoring
+	Batch     int    `json:"batch,omitempty"`  // rerank batch, 1-based
+	Batches   int    `json:"batches,omitempty"`
+	ElapsedMS int64  `json:"elapsed_ms"` // since the search started
+	Message   string `json:"message,omitempty"`
+}
+
+// HybridSearchStreamHandler runs a hybrid search like HybridSearchHandler
+// but answers with Server-Sent Events, so a UI can show progress during a
+// 30s+ search instead of a spinner:
+//
+//	event: progress   SearchProgressEvent, once per pipeline step
+//	event: result     HybridSearchResponse, last event on success
+//	event: error      ErrorResponse, last event if the search failed
+//
+// The request body is the same as /api/search/hybrid's; invalid requests
+// still get a plain JSON error before the stream starts. Browsers read the
+// stream with fetch (EventSource can't POST or send X-API-Key).
+//
+// POST /api/search/hybrid/stream
+func (a *API) HybridSearchStreamHandler(w http.ResponseWriter, r *http.Request) {
+	ai, req, config, ok := a.parseHybridSearch(w, r)
+	if !ok {
+		return
+	}
+
+	stream := newEventStream(w)
+	defer stream.close()
+
+	ctx := graphrag.WithProgress(r.Context(), func(p graphrag.SearchProgress) {
+		stream.send("progress", SearchProgressEvent{
+			Stage:     p.Stage,
+			Source:    p.Source,
+			Done:      p.Done,
+			Count:     p.Count,
+			Batch:     p.Batch,
+			Batches:   p.Batches,
+			ElapsedMS: p.Elapsed.Milliseconds(),
+			Message:   p.Message,
+		})
+	})
+
+	response, err := a.runHybridSearch(ctx, ai, req, config)
+	if err != nil {
+		stream.send("error", ErrorResponse{Error: "Search failed: " + err.Error(), Status: http.StatusInternalServerError})
+		return
+	}
+	stream.send("result", response)
+}
+
+// eventStream writes Server-Sent Events. send is safe for concurrent use:
+// retrieval sources report progress from their own goroutines.
+type eventStream struct {
+	mu     sync.Mutex
+	w      http.ResponseWriter
+	rc     *http.ResponseController
+	closed bool
+	done   chan struct{}
+}
+
+// newEventStream starts the event-stream response and its heartbeat.
+func newEventStream(w http.ResponseWriter) *eventStream {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // nginx: don't buffer the stream
+	w.WriteHeader(http.StatusOK)
+
+	s := &eventStream{w: w, rc: http.NewResponseController(w), done: make(chan struct{})}
+	if err := s.rc.Flush(); err != nil {
+		log.Printf("[API] Search stream can't flush, events arrive at the end: %v", err)
+	}
+	go s.heartbeat()
+	return s
+}
+
+func (s *eventStream) send(event string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("[API] Search stream: encode %s event: %v", event, err)
+		return
+	}
+	s.write(fmt.Sprintf("event: %s\ndata: %s\n\n", event, payload))
+}
+
+func (s *eventStream) write(text string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	if _, err := fmt.Fprint(s.w, text); err != nil {
+		return
+	}
+	s.rc.Flush()
+}
+
+func (s *eventStream) heartbeat() {
+	ticker := time.NewTicker(streamHeartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.write(": keep-alive\n\n")
+		}
+	}
+}
+
+// close stops the heartbeat and drops later events: the handler returning
+// ends the response.
+func (s *eventStream) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.done)
+	}
+}
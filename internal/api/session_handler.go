@@ -0,0 +1,255 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"cv-search/internal/graphrag"
+	"cv-search/internal/storage"
+)
+
+// SearchSessionTurnResponse is the result of one session turn.
+type SearchSessionTurnResponse struct {
+	SessionID      string                   `json:"session_id"`
+	Turn           int                      `json:"turn"`
+	Query          string                   `json:"query"`
+	Action         string                   `json:"action"` // search | filter
+	EffectiveQuery string                   `json:"effective_query,omitempty"`
+	Explanation    string                   `json:"explanation,omitempty"`
+	Candidates     []FusedCandidateResponse `json:"candidates"`
+	TotalFound     int                      `json:"total_found"`
+	ProcessingTime string                   `json:"processing_time"`
+	Warnings       []string                 `json:"warnings,omitempty"`
+}
+
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "sess_" + hex.EncodeToString(b), nil
+}
+
+// runSessionSearch executes one hybrid search for a session turn.
+func (a *API) runSessionSearch(r *http.Request, req *HybridSearchRequest) ([]FusedCandidateResponse, []string, string, int) {
+	config, errMsg := a.resolveHybridConfig(r.Context(), req)
+	if errMsg != "" {
+		return nil, nil, errMsg, http.StatusBadRequest
+	}
+	results, diag, err := a.ai(r.Context()).hybridSearchEngine.SearchWithDiagnostics(r.Context(), req.Query, config)
+	if err != nil {
+		log.Printf("[SearchSession] Hybrid search failed: %v", err)
+		return nil, nil, "Search failed: " + err.Error(), http.StatusInternalServerError
+	}
+	var warnings []string
+	if diag != nil {
+		warnings = diag.Warnings
+	}
+	candidates := toFusedCandidateResponses(results)
+	if candidates == nil {
+		candidates = []FusedCandidateResponse{}
+	}
+	return candidates, warnings, "", http.StatusOK
+}
+
+// CreateSearchSessionHandler starts a conversational search session with an
+// initial hybrid search.
+func (a *API) CreateSearchSessionHandler(w http.ResponseWriter, r *http.Request) {
+	ai := a.ai(r.Context())
+	if ai.hybridSearchEngine == nil {
+		http.Error(w, "Hybrid search not available (OpenAI API key required)", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req HybridSearchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Query == "" {
+		http.Error(w, "Query cannot be empty", http.StatusBadRequest)
+		return
+	}
+
+	start := time.Now()
+	candidates, warnings, errMsg, status := a.runSessionSearch(r, &req)
+	if errMsg != "" {
+		http.Error(w, errMsg, status)
+		return
+	}
+
+	sessionID, err := newSessionID()
+	if err != nil {
+		http.Error(w, "failed to create session", http.StatusInternalServerError)
+		return
+	}
+	if err := a.db.CreateSearchSession(r.Context(), sessionID); err != nil {
+		log.Printf("[SearchSession] CreateSearchSession failed: %v", err)
+		http.Error(w, "failed to create session", http.StatusInternalServerError)
+		return
+	}
+
+	resp := SearchSessionTurnResponse{
+		SessionID:      sessionID,
+		Query:          req.Query,
+		Action:         graphrag.SessionActionSearch,
+		EffectiveQuery: req.Query,
+		Candidates:     candidates,
+		Warnings:       warnings,
+	}
+	a.saveSessionTurn(w, r, &resp, start)
+}
+
+// SearchSessionQueryHandler applies a follow-up query to a session. The LLM
+// decides whether it filters the previous results or needs a new search.
+func (a *API) SearchSessionQueryHandler(w http.ResponseWriter, r *http.Request) {
+	ai := a.ai(r.Context())
+	if ai.hybridSearchEngine == nil {
+		http.Error(w, "Hybrid search not available (OpenAI API key required)", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req HybridSearchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Query == "" {
+		http.Error(w, "Query cannot be empty", http.StatusBadRequest)
+		return
+	}
+
+	session, err := a.db.GetSearchSession(r.Context(), r.PathValue("id"))
+	if err != nil {
+		log.Printf("[SearchSession] GetSearchSession failed: %v", err)
+		http.Error(w, "failed to load session", http.StatusInternalServerError)
+		return
+	}
+	if session == nil || len(session.Turns) == 0 {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+
+	start := time.Now()
+	last := session.Turns[len(session.Turns)-1]
+	var previous []FusedCandidateResponse
+	if err := json.Unmarshal(last.Results, &previous); err != nil {
+		log.Printf("[SearchSession] Corrupt results for session %s turn %d: %v", session.ID, last.Turn, err)
+		http.Error(w, "failed to load session", http.StatusInternalServerError)
+		return
+	}
+
+	history := make([]string, 0, len(session.Turns))
+	for _, t := range session.Turns {
+		history = append(history, t.Query)
+	}
+	interp := ai.hybridSearchEngine.InterpretFollowUp(r.Context(), history, toSessionCandidates(previous), req.Query)
+
+	resp := SearchSessionTurnResponse{
+		SessionID:   session.ID,
+		Query:       req.Query,
+		Action:      interp.Action,
+		Explanation: interp.Explanation,
+	}
+
+	switch interp.Action {
+	case graphrag.SessionActionFilter:
+		keep := make(map[string]bool, len(interp.KeepPersonIDs))
+		for _, id := range interp.KeepPersonIDs {
+			keep[id] = true
+		}
+		resp.Candidates = []FusedCandidateResponse{}
+		for _, c := range previous {
+			if keep[c.PersonID] {
+				c.Rank = len(resp.Candidates) + 1
+				resp.Candidates = append(resp.Candidates, c)
+			}
+		}
+	default:
+		search := HybridSearchRequest{Query: interp.EffectiveQuery}
+		candidates, warnings, errMsg, status := a.runSessionSearch(r, &search)
+		if errMsg != "" {
+			http.Error(w, errMsg, status)
+			return
+		}
+		resp.EffectiveQuery = interp.EffectiveQuery
+		resp.Candidates = candidates
+		resp.Warnings = warnings
+	}
+
+	a.saveSessionTurn(w, r, &resp, start)
+}
+
+// saveSessionTurn persists a turn and writes it as the response.
+func (a *API) saveSessionTurn(w http.ResponseWriter, r *http.Request, resp *SearchSessionTurnResponse, start time.Time) {
+	resultsJSON, err := json.Marshal(resp.Candidates)
+	if err != nil {
+		http.Error(w, "failed to save session turn", http.StatusInternalServerError)
+		return
+	}
+	turn, err := a.db.AppendSearchSessionTurn(r.Context(), resp.SessionID, storage.SearchSessionTurn{
+		Query:          resp.Query,
+		Action:         resp.Action,
+		EffectiveQuery: resp.EffectiveQuery,
+		Explanation:    resp.Explanation,
+		Results:        resultsJSON,
+	})
+	if err != nil {
+		log.Printf("[SearchSession] AppendSearchSessionTurn failed: %v", err)
+		http.Error(w, "failed to save session turn", http.StatusInternalServerError)
+		return
+	}
+
+	resp.Turn = turn
+	resp.TotalFound = len(resp.Candidates)
+	resp.ProcessingTime = time.Since(start).String()
+
+	log.Printf("[SearchSession] session=%s turn=%d action=%s results=%d", resp.SessionID, turn, resp.Action, resp.TotalFound)
+
+	writeJSONList(w, struct {
+		*SearchSessionTurnResponse
+		Candidates json.RawMessage `json:"candidates,omitempty"`
+	}{SearchSessionTurnResponse: resp}, "candidates", resp.Candidates)
+}
+
+// GetSearchSessionHandler returns a session with every turn and its results.
+func (a *API) GetSearchSessionHandler(w http.ResponseWriter, r *http.Request) {
+	session, err := a.db.GetSearchSession(r.Context(), r.PathValue("id"))
+	if err != nil {
+		log.Printf("[SearchSession] GetSearchSession failed: %v", err)
+		http.Error(w, "failed to load session", http.StatusInternalServerError)
+		return
+	}
+	if session == nil {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(session)
+}
+
+func toSessionCandidates(candidates []FusedCandidateResponse) []graphrag.SessionCandidate {
+	out := make([]graphrag.SessionCandidate, 0, len(candidates))
+	for _, c := range candidates {
+		sc := graphrag.SessionCandidate{
+			PersonID:             c.PersonID,
+			Name:                 c.Name,
+			CurrentPosition:      c.CurrentPosition,
+			Seniority:            c.Seniority,
+			TotalExperienceYears: c.TotalExperienceYears,
+		}
+		for _, s := range c.Skills {
+			sc.Skills = append(sc.Skills, s.Name)
+		}
+		for _, comp := range c.Companies {
+			sc.Companies = append(sc.Companies, comp.Name)
+		}
+		out = append(out, sc)
+	}
+	return out
+}
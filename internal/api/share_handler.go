@@ -0,0 +1,327 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"cv-search/internal/cv"
+	"cv-search/internal/graphrag"
+	"cv-search/internal/storage"
+	"cv-search/internal/tenant"
+)
+
+// Share link lifetimes, in hours.
+const (
+	defaultShareLinkHours = 72
+	maxShareLinkHours     = 30 * 24
+)
+
+// Masks of an anonymized shared profile's free text.
+const (
+	shareNameMask    = "[CANDIDATE]"
+	shareCompanyMask = "[COMPANY]"
+	shareSchoolMask  = "[SCHOOL]"
+)
+
+type createShareLinkRequest struct {
+	ExpiresInHours int  `json:"expires_in_hours,omitempty"` // default 72, at most 720
+	Anonymized     bool `json:"anonymized,omitempty"`       // leave out the name, employers and schools
+}
+
+// shareLinkResponse is a share link with its URL.
+type shareLinkResponse struct {
+	storage.ShareLink
+	URL string `json:"url"`
+}
+
+type shareLinksResponse struct {
+	CandidateID int                 `json:"candidate_id"`
+	Links       []shareLinkResponse `json:"links"`
+}
+
+// sharedProfileResponse is what a share link shows: the candidate's CV as
+// their graph has it, without contact details, interviews or notes.
+type sharedProfileResponse struct {
+	Anonymized bool      `json:"anonymized"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	*graphrag.PersonProfile
+}
+
+// ─── Tokens ───────────────────────────────────────────────────────────────────
+//
+// A share link's token is "<id>.<expiry unix>.<signature>", the signature an
+// HMAC-SHA256 of the first two with SHARE_LINK_SECRET. A valid signature only
+// proves the server issued the token; the share_links row decides whether it
+// is still good (not revoked, not expired).
+
+func (a *API) signShareLink(id int64, expiresAt time.Time) string {
+	payload := fmt.Sprintf("%d.%d", id, expiresAt.Unix())
+	mac := hmac.New(sha256.New, []byte(a.cfg.ShareLinkSecret))
+	mac.Write([]byte(payload))
+	return payload + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// parseShareToken returns the link id of a token signed by signShareLink
+// that hasn't expired.
+func (a *API) parseShareToken(token string) (int64, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	expiry, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || time.Now().Unix() >= expiry {
+		return 0, false
+	}
+	if !hmac.Equal([]byte(a.signShareLink(id, time.Unix(expiry, 0))), []byte(token)) {
+		return 0, false
+	}
+	return id, true
+}
+
+// shareLinkURL returns the public URL of a link: under PUBLIC_BASE_URL, else
+// on the host the request came in on.
+func (a *API) shareLinkURL(r *http.Request, l storage.ShareLink) string {
+	base := a.cfg.PublicBaseURL
+	if base == "" {
+		scheme := "http"
+		if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+			scheme = "https"
+		}
+		base = scheme + "://" + r.Host
+	}
+	return base + "/share/" + a.signShareLink(l.ID, l.ExpiresAt)
+}
+
+// shareLinksEnabled answers 503 when SHARE_LINK_SECRET isn't set.
+func (a *API) shareLinksEnabled(w http.ResponseWriter) bool {
+	if a.cfg.ShareLinkSecret == "" {
+		http.Error(w, "share links not available (SHARE_LINK_SECRET not configured)", http.StatusServiceUnavailable)
+		return false
+	}
+	return true
+}
+
+// ─── Handlers ─────────────────────────────────────────────────────────────────
+
+// CreateShareLinkHandler creates a read-only, expiring link to a candidate's
+// profile for someone without an account, optionally anonymized.
+// POST /api/candidates/{id}/share-links
+func (a *API) CreateShareLinkHandler(w http.ResponseWriter, r *http.Request) {
+	candidateID, err := parseCandidateID(r)
+	if err != nil {
+		http.Error(w, "invalid candidate id", http.StatusBadRequest)
+		return
+	}
+	var req createShareLinkRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+	if req.ExpiresInHours == 0 {
+		req.ExpiresInHours = defaultShareLinkHours
+	}
+	if req.ExpiresInHours < 1 || req.ExpiresInHours > maxShareLinkHours {
+		http.Error(w, fmt.Sprintf("expires_in_hours must be between 1 and %d", maxShareLinkHours), http.StatusBadRequest)
+		return
+	}
+	if !a.shareLinksEnabled(w) {
+		return
+	}
+	if _, ok := a.candidateGraphNode(w, r, candidateID, "ShareLinks"); !ok {
+		return
+	}
+
+	expiresAt := time.Now().Add(time.Duration(req.ExpiresInHours) * time.Hour).Truncate(time.Second)
+	link, err := a.db.CreateShareLink(r.Context(), candidateID, req.Anonymized, expiresAt, actorFromRequest(r))
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "candidate not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("[ShareLinks] CreateShareLink(candidate=%d) failed: %v", candidateID, err)
+		http.Error(w, "failed to create share link", http.StatusInternalServerError)
+		return
+	}
+	a.audit(r, "share", "candidate", strconv.Itoa(candidateID), map[string]interface{}{
+		"share_link_id": link.ID,
+		"anonymized":    link.Anonymized,
+		"expires_at":    link.ExpiresAt,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(shareLinkResponse{ShareLink: *link, URL: a.shareLinkURL(r, *link)})
+}
+
+// ListShareLinksHandler returns a candidate's share links with their view
+// counts, revoked and expired ones included.
+// GET /api/candidates/{id}/share-links
+func (a *API) ListShareLinksHandler(w http.ResponseWriter, r *http.Request) {
+	candidateID, err := parseCandidateID(r)
+	if err != nil {
+		http.Error(w, "invalid candidate id", http.StatusBadRequest)
+		return
+	}
+	if !a.shareLinksEnabled(w) {
+		return
+	}
+
+	links, err := a.db.ListShareLinks(r.Context(), candidateID)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "candidate not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("[ShareLinks] ListShareLinks(candidate=%d) failed: %v", candidateID, err)
+		http.Error(w, "failed to list share links", http.StatusInternalServerError)
+		return
+	}
+	resp := shareLinksResponse{CandidateID: candidateID, Links: make([]shareLinkResponse, len(links))}
+	for i, l := range links {
+		resp.Links[i] = shareLinkResponse{ShareLink: l, URL: a.shareLinkURL(r, l)}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// RevokeShareLinkHandler revokes a share link; its URL stops working at once.
+// DELETE /api/candidates/{id}/share-links/{lid}
+func (a *API) RevokeShareLinkHandler(w http.ResponseWriter, r *http.Request) {
+	candidateID, err := parseCandidateID(r)
+	if err != nil {
+		http.Error(w, "invalid candidate id", http.StatusBadRequest)
+		return
+	}
+	linkID, err := strconv.ParseInt(r.PathValue("lid"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid share link id", http.StatusBadRequest)
+		return
+	}
+
+	if err := a.db.RevokeShareLink(r.Context(), linkID, candidateID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "share link not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("[ShareLinks] RevokeShareLink(%d, candidate=%d) failed: %v", linkID, candidateID, err)
+		http.Error(w, "failed to revoke share link", http.StatusInternalServerError)
+		return
+	}
+	a.audit(r, "revoke", "share_link", strconv.FormatInt(linkID, 10), map[string]int{"candidate_id": candidateID})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SharedProfileHandler shows the profile behind a share link. It is public:
+// the signed token is the only credential, so every way a link can be bad
+// (forged, expired, revoked, candidate deleted) is the same 404.
+// GET /share/{token}
+func (a *API) SharedProfileHandler(w http.ResponseWriter, r *http.Request) {
+	if !a.shareLinksEnabled(w) {
+		return
+	}
+	linkID, ok := a.parseShareToken(r.PathValue("token"))
+	if !ok {
+		http.Error(w, "share link not found or expired", http.StatusNotFound)
+		return
+	}
+	link, err := a.db.ViewShareLink(r.Context(), linkID)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "share link not found or expired", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("[ShareLinks] ViewShareLink(%d) failed: %v", linkID, err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+
+	// /share/ is outside /api/, so no organization is set yet: the link's
+	// is the one to read the profile in.
+	ctx := tenant.WithOrg(r.Context(), link.OrgID)
+	graphNodeID, err := a.db.GetGraphNodeIDForCandidate(ctx, link.CandidateID)
+	if err != nil {
+		log.Printf("[ShareLinks] GetGraphNodeIDForCandidate(%d) failed: %v", link.CandidateID, err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	var profile *graphrag.PersonProfile
+	if graphNodeID > 0 {
+		profile, err = a.graphBuilder.PersonProfile(ctx, graphNodeID)
+	}
+	if graphNodeID == 0 || errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "share link not found or expired", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("[ShareLinks] PersonProfile(candidate=%d) failed: %v", link.CandidateID, err)
+		http.Error(w, "failed to load profile", http.StatusInternalServerError)
+		return
+	}
+	if link.Anonymized {
+		anonymizeProfile(profile)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Robots-Tag", "noindex")
+	json.NewEncoder(w).Encode(sharedProfileResponse{Anonymized: link.Anonymized, ExpiresAt: link.ExpiresAt, PersonProfile: profile})
+}
+
+// anonymizeProfile leaves out a profile's name, employers and schools, and
+// masks them and any PII in its free text.
+func anonymizeProfile(p *graphrag.PersonProfile) {
+	var names, companies, schools []string
+	for _, part := range append([]string{p.Name}, strings.Fields(p.Name)...) {
+		if utf8.RuneCountInString(part) >= 3 {
+			names = append(names, part)
+		}
+	}
+	for _, j := range p.Experience {
+		companies = append(companies, j.Company)
+	}
+	for _, e := range p.Education {
+		schools = append(schools, e.Institution)
+	}
+	mask := func(text string) string {
+		text, _ = cv.AnonymizeText(text)
+		text = cv.MaskNames(text, names, shareNameMask)
+		text = cv.MaskNames(text, companies, shareCompanyMask)
+		return cv.MaskNames(text, schools, shareSchoolMask)
+	}
+
+	p.Name = ""
+	p.CurrentPosition = mask(p.CurrentPosition)
+	for i := range p.Experience {
+		p.Experience[i].Company = ""
+		p.Experience[i].Position = mask(p.Experience[i].Position)
+	}
+	for i := range p.Education {
+		p.Education[i].Institution = ""
+	}
+	for i := range p.Projects {
+		pr := &p.Projects[i]
+		pr.Name = mask(pr.Name)
+		pr.Description = mask(pr.Description)
+		pr.Role = mask(pr.Role)
+		pr.Impact = mask(pr.Impact)
+	}
+}
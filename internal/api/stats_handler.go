@@ -0,0 +1,341 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"cv-search/internal/graphrag"
+	"cv-search/internal/storage"
+)
+
+// ─── Request/Response types ───────────────────────────────────────────────────
+
+// statsRefresh is embedded in every stats response: when the views were
+// last rebuilt, omitted before the first refresh.
+type statsRefresh struct {
+	RefreshedAt *time.Time `json:"refreshed_at,omitempty"`
+}
+
+func (s *statsRefresh) setRefreshedAt(t *time.Time) { s.RefreshedAt = t }
+
+type graphStatsResponse struct {
+	storage.GraphStats
+	statsRefresh
+}
+
+type popularSkillsResponse struct {
+	Total  int                  `json:"total"`
+	Skills []storage.SkillCount `json:"skills"`
+	statsRefresh
+}
+
+type skillTrendResponse struct {
+	Since  string                    `json:"since"` // "2006-01"
+	Points []storage.SkillTrendPoint `json:"points"`
+	statsRefresh
+}
+
+type trendingSkillsResponse struct {
+	Period       string                  `json:"period"`        // month | quarter | year
+	From         string                  `json:"from"`          // "2006-01", first month of the period
+	To           string                  `json:"to"`            // "2006-01", last month of the period
+	PreviousFrom string                  `json:"previous_from"` // "2006-01", first month of the period before
+	Direction    string                  `json:"direction"`     // up | down
+	Skills       []storage.TrendingSkill `json:"skills"`
+	statsRefresh
+}
+
+type seniorityResponse struct {
+	Seniority []storage.SeniorityCount `json:"seniority"`
+	statsRefresh
+}
+
+type communitySizesResponse struct {
+	Communities []storage.CommunitySize `json:"communities"`
+	statsRefresh
+}
+
+type weeklyUploadsResponse struct {
+	Weeks []storage.WeeklyUploads `json:"weeks"`
+	statsRefresh
+}
+
+type refreshGraphAnalyticsResponse struct {
+	*graphrag.GraphAnalyticsRun
+	DurationMs int64 `json:"duration_ms"`
+}
+
+type refreshStatsResponse struct {
+	DurationMs int64 `json:"duration_ms"`
+	statsRefresh
+}
+
+// ─── Helpers ──────────────────────────────────────────────────────────────────
+
+// queryInt reads a positive integer query parameter, falling back to def when
+// it's missing or invalid and capping it at max.
+func queryInt(r *http.Request, name string, def, max int) int {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return def
+	}
+	if n > max {
+		return max
+	}
+	return n
+}
+
+// writeStats encodes a stats response with the views' last refresh time
+// set as "refreshed_at".
+func (a *API) writeStats(w http.ResponseWriter, r *http.Request, body interface{ setRefreshedAt(*time.Time) }) {
+	if t, err := a.db.StatsRefreshedAt(r.Context()); err != nil {
+		log.Printf("[Stats] StatsRefreshedAt failed: %v", err)
+	} else {
+		body.setRefreshedAt(t)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
+
+// ─── Handlers ─────────────────────────────────────────────────────────────────
+
+// GetGraphStats returns graph statistics
+func (a *API) GetGraphStatsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	stats, err := a.db.GetGraphStats(r.Context())
+	if err != nil {
+		log.Printf("[Stats] GetGraphStats failed: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+
+	a.writeStats(w, r, &graphStatsResponse{GraphStats: *stats})
+}
+
+// GetPopularSkills returns most popular skills from graph
+func (a *API) GetPopularSkillsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	skills, err := a.db.GetPopularSkills(r.Context(), queryInt(r, "limit", 20, 200))
+	if err != nil {
+		log.Printf("[Stats] GetPopularSkills failed: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+
+	a.writeStats(w, r, &popularSkillsResponse{Total: len(skills), Skills: skills})
+}
+
+// GetSkillTrendHandler returns monthly new-candidate counts per skill.
+//
+//	GET /api/graph/stats/skills-trend?months=12&limit=10&skills=Go,Python
+//
+// Without skills, the top `limit` skills of the period are returned.
+func (a *API) GetSkillTrendHandler(w http.ResponseWriter, r *http.Request) {
+	months := queryInt(r, "months", 12, 60)
+	now := time.Now().UTC()
+	since := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -(months - 1), 0)
+
+	var names []string
+	for _, n := range strings.Split(r.URL.Query().Get("skills"), ",") {
+		if n = strings.TrimSpace(n); n != "" {
+			names = append(names, n)
+		}
+	}
+
+	points, err := a.db.GetSkillTrend(r.Context(), since, names, queryInt(r, "limit", 10, 50))
+	if err != nil {
+		log.Printf("[Stats] GetSkillTrend failed: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+
+	a.writeStats(w, r, &skillTrendResponse{Since: since.Format("2006-01"), Points: points})
+}
+
+// trendingPeriods are the periods trending skills are compared over, in
+// months.
+var trendingPeriods = map[string]int{"month": 1, "quarter": 3, "year": 12}
+
+// GetTrendingSkillsHandler returns the skills whose mentions grew (or, with
+// direction=down, shrank) the most over the last complete period compared
+// with the period before: "Rust mentions up 40% this quarter". Mentions are
+// people whose CV named the skill, by the month it was built.
+//
+//	GET /api/graph/skills/trending?period=quarter&direction=up&min_mentions=3&limit=20
+func (a *API) GetTrendingSkillsHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	period := q.Get("period")
+	if period == "" {
+		period = "quarter"
+	}
+	months, ok := trendingPeriods[period]
+	if !ok {
+		http.Error(w, "invalid period: expected month, quarter or year", http.StatusBadRequest)
+		return
+	}
+	direction := q.Get("direction")
+	if direction == "" {
+		direction = "up"
+	}
+	if direction != "up" && direction != "down" {
+		http.Error(w, "invalid direction: expected up or down", http.StatusBadRequest)
+		return
+	}
+
+	// Complete months only: the current one would make any period look
+	// like a drop.
+	now := time.Now().UTC()
+	to := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	from := to.AddDate(0, -months, 0)
+
+	skills, err := a.db.GetTrendingSkills(r.Context(), from, to, queryInt(r, "min_mentions", 3, 1000), direction == "down", queryInt(r, "limit", 20, 200))
+	if err != nil {
+		log.Printf("[Stats] GetTrendingSkills failed: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+
+	a.writeStats(w, r, &trendingSkillsResponse{
+		Period:       period,
+		From:         from.Format("2006-01"),
+		To:           to.AddDate(0, -1, 0).Format("2006-01"),
+		PreviousFrom: from.AddDate(0, -months, 0).Format("2006-01"),
+		Direction:    direction,
+		Skills:       skills,
+	})
+}
+
+// GetSeniorityDistributionHandler returns live candidates per seniority level.
+// GET /api/graph/stats/seniority
+func (a *API) GetSeniorityDistributionHandler(w http.ResponseWriter, r *http.Request) {
+	dist, err := a.db.GetSeniorityDistribution(r.Context())
+	if err != nil {
+		log.Printf("[Stats] GetSeniorityDistribution failed: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+
+	a.writeStats(w, r, &seniorityResponse{Seniority: dist})
+}
+
+// GetCommunitySizesHandler returns detected communities by member count.
+// GET /api/graph/stats/communities?limit=50
+func (a *API) GetCommunitySizesHandler(w http.ResponseWriter, r *http.Request) {
+	communities, err := a.db.GetCommunitySizes(r.Context(), queryInt(r, "limit", 50, 500))
+	if err != nil {
+		log.Printf("[Stats] GetCommunitySizes failed: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+
+	a.writeStats(w, r, &communitySizesResponse{Communities: communities})
+}
+
+// GetWeeklyUploadsHandler returns CV uploads per week.
+// GET /api/graph/stats/uploads?weeks=12
+func (a *API) GetWeeklyUploadsHandler(w http.ResponseWriter, r *http.Request) {
+	weeks := queryInt(r, "weeks", 12, 260)
+	since := time.Now().UTC().AddDate(0, 0, -7*weeks)
+
+	uploads, err := a.db.GetWeeklyUploads(r.Context(), since)
+	if err != nil {
+		log.Printf("[Stats] GetWeeklyUploads failed: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+
+	a.writeStats(w, r, &weeklyUploadsResponse{Weeks: uploads})
+}
+
+// GetGraphAnalyticsHandler returns the graph analytics last computed for the
+// organization: the most central people (PageRank), feeder companies (most
+// former employees), the most common skill pairs and rare combinations of
+// common skills. skill narrows the pairs to the ones including it.
+//
+//	GET /api/graph/analytics?limit=20&skill=Go
+func (a *API) GetGraphAnalyticsHandler(w http.ResponseWriter, r *http.Request) {
+	analytics, err := a.db.GetGraphAnalytics(r.Context(), queryInt(r, "limit", 20, 200), strings.TrimSpace(r.URL.Query().Get("skill")))
+	if err != nil {
+		log.Printf("[Stats] GetGraphAnalytics failed: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(analytics)
+}
+
+// RefreshGraphAnalyticsHandler recomputes the organization's graph analytics
+// now instead of waiting for GRAPH_ANALYTICS_INTERVAL_MINUTES.
+//
+//	POST /api/graph/analytics/refresh
+func (a *API) RefreshGraphAnalyticsHandler(w http.ResponseWriter, r *http.Request) {
+	run, err := graphrag.ComputeGraphAnalytics(r.Context(), a.db.GetConnection())
+	if err != nil {
+		log.Printf("[Stats] ComputeGraphAnalytics failed: %v", err)
+		http.Error(w, "failed to compute graph analytics", http.StatusInternalServerError)
+		return
+	}
+	a.invalidateResponses(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(refreshGraphAnalyticsResponse{GraphAnalyticsRun: run, DurationMs: run.Duration.Milliseconds()})
+}
+
+// RefreshStatsHandler rebuilds the statistics views now instead of waiting
+// for the next scheduled refresh.
+// POST /api/admin/stats/refresh
+func (a *API) RefreshStatsHandler(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	if err := a.db.RefreshStatsViews(r.Context()); err != nil {
+		log.Printf("[Stats] RefreshStatsViews failed: %v", err)
+		http.Error(w, "failed to refresh statistics", http.StatusInternalServerError)
+		return
+	}
+	a.invalidateAllResponses(r.Context())
+	a.writeStats(w, r, &refreshStatsResponse{DurationMs: time.Since(start).Milliseconds()})
+}
+
+type adminOverviewResponse struct {
+	*storage.AdminOverview
+	Queues      []QueueStatus `json:"queues"`
+	GeneratedAt time.Time     `json:"generated_at"`
+}
+
+// AdminOverviewHandler returns the system status a dashboard needs in one
+// call: today's uploads, extraction error rate and LLM spend, jobs by
+// status, graph and community counts, the embedding backlog, the most
+// recent failed jobs and the background queues. Figures are live, not from
+// the stats views; LLM spend is estimated from token usage (llm_usage).
+//
+//	GET /api/admin/overview?failures=10
+func (a *API) AdminOverviewHandler(w http.ResponseWriter, r *http.Request) {
+	overview, err := a.db.GetAdminOverview(r.Context(), queryInt(r, "failures", 10, 100))
+	if err != nil {
+		log.Printf("[Stats] GetAdminOverview failed: %v", err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(adminOverviewResponse{
+		AdminOverview: overview,
+		Queues:        a.queueStatuses(),
+		GeneratedAt:   time.Now().UTC(),
+	})
+}
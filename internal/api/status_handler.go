@@ -0,0 +1,143 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"cv-search/internal/graphrag"
+	"cv-search/internal/storage"
+)
+
+// ─── Request/Response types ───────────────────────────────────────────────────
+
+type candidateStatusRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type candidateStatusResponse struct {
+	CandidateID int                            `json:"candidate_id"`
+	Status      string                         `json:"status"`
+	Change      *storage.CandidateStatusChange `json:"change,omitempty"` // unset when the candidate already had the status
+}
+
+type candidateStatusHistoryResponse struct {
+	CandidateID int                             `json:"candidate_id"`
+	History     []storage.CandidateStatusChange `json:"history"`
+}
+
+// maxStatusReasonLength caps a status change's reason, in characters.
+const maxStatusReasonLength = 1000
+
+// ─── Helpers ──────────────────────────────────────────────────────────────────
+
+// validStatus reports an error for a status a candidate can't have.
+func validStatus(status string) error {
+	if !slices.Contains(graphrag.CandidateStatuses, status) {
+		return fmt.Errorf("invalid status %q: use one of %s", status, strings.Join(graphrag.CandidateStatuses, ", "))
+	}
+	return nil
+}
+
+// parseIncludeStatuses validates a search's include_statuses, the statuses
+// searches leave out by default that it wants back.
+func parseIncludeStatuses(raw []string) ([]string, string) {
+	var statuses []string
+	for _, s := range raw {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if s == "" || s == graphrag.StatusActive || slices.Contains(statuses, s) {
+			continue
+		}
+		if err := validStatus(s); err != nil {
+			return nil, "include_statuses: " + err.Error()
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, ""
+}
+
+// ─── Handlers ─────────────────────────────────────────────────────────────────
+
+// SetCandidateStatusHandler changes a candidate's status. Hired, archived
+// and do-not-contact candidates drop out of searches unless a search asks
+// for them (include_statuses).
+// PUT /api/candidates/{id}/status  {"status": "hired", "reason": "joined the payments team"}
+func (a *API) SetCandidateStatusHandler(w http.ResponseWriter, r *http.Request) {
+	candidateID, err := parseCandidateID(r)
+	if err != nil {
+		http.Error(w, "invalid candidate id", http.StatusBadRequest)
+		return
+	}
+
+	var req candidateStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	status := strings.ToLower(strings.TrimSpace(req.Status))
+	if status == "" {
+		http.Error(w, "status is required", http.StatusUnprocessableEntity)
+		return
+	}
+	if err := validStatus(status); err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if len([]rune(reason)) > maxStatusReasonLength {
+		http.Error(w, fmt.Sprintf("reason must be at most %d characters", maxStatusReasonLength), http.StatusUnprocessableEntity)
+		return
+	}
+
+	change, err := a.db.SetCandidateStatus(r.Context(), candidateID, status, reason, actorFromRequest(r))
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "candidate not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("[StatusHandler] SetCandidateStatus(%d, %q) failed: %v", candidateID, status, err)
+		http.Error(w, "failed to set status", http.StatusInternalServerError)
+		return
+	}
+	if change != nil {
+		a.audit(r, "status", "candidate", strconv.Itoa(candidateID), map[string]string{
+			"from": change.FromStatus, "to": change.ToStatus, "reason": reason,
+		})
+		a.invalidateSearchCache(r.Context())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(candidateStatusResponse{CandidateID: candidateID, Status: status, Change: change})
+}
+
+// ListCandidateStatusHistoryHandler returns a candidate's status changes,
+// newest first.
+// GET /api/candidates/{id}/status-history
+func (a *API) ListCandidateStatusHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	candidateID, err := parseCandidateID(r)
+	if err != nil {
+		http.Error(w, "invalid candidate id", http.StatusBadRequest)
+		return
+	}
+
+	history, err := a.db.ListCandidateStatusHistory(r.Context(), candidateID)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "candidate not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("[StatusHandler] ListCandidateStatusHistory(%d) failed: %v", candidateID, err)
+		http.Error(w, "failed to list status history", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(candidateStatusHistoryResponse{CandidateID: candidateID, History: history})
+}
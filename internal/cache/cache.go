@@ -0,0 +1,50 @@
+// Package cache keeps short-lived values — the API's cached responses and
+// the generation counters that invalidate them — in process memory or, when
+// several API instances must share them, in Redis (RESPONSE_CACHE).
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Store is a key-value store with per-key expiry.
+type Store interface {
+	// Get returns the value of key, and false if it is missing or expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value under key for ttl (0 = no expiry).
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Incr adds one to the integer under key (missing = 0), which never
+	// expires, and returns the new value.
+	Incr(ctx context.Context, key string) (int64, error)
+}
+
+// Config selects and configures the store.
+type Config struct {
+	Backend    string // "memory" (default), "redis" or "none"
+	MaxEntries int    // memory: entries kept, least recently used evicted first
+	RedisURL   string // redis: redis://[:password@]host:port[/db], rediss:// for TLS
+}
+
+// NewStore builds the configured store, or nil when caching is disabled.
+func NewStore(cfg Config) (Store, error) {
+	switch strings.ToLower(cfg.Backend) {
+	case "", "memory":
+		return NewMemory(cfg.MaxEntries), nil
+	case "none":
+		return nil, nil
+	case "redis":
+		if cfg.RedisURL == "" {
+			return nil, fmt.Errorf("cache: REDIS_URL is required for the redis backend")
+		}
+		r, err := NewRedis(cfg.RedisURL)
+		if err != nil {
+			return nil, err
+		}
+		return r, nil
+	default:
+		return nil, fmt.Errorf("cache: unknown backend %q (want memory, redis or none)", cfg.Backend)
+	}
+}
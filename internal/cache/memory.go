@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// defaultMaxEntries bounds a Memory store built without a size.
+const defaultMaxEntries = 1000
+
+// Memory is a Store in process memory, for a single API instance. It holds
+// at most maxEntries values and evicts the least recently used first;
+// counters (Incr) are kept apart and never evicted.
+type Memory struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List // front = most recently used
+	entries    map[string]*list.Element
+	counters   map[string]int64
+}
+
+type memoryEntry struct {
+	key     string
+	value   []byte
+	expires time.Time // zero = never
+}
+
+// NewMemory returns an empty Memory store of maxEntries (0 = 1000).
+func NewMemory(maxEntries int) *Memory {
+	if maxEntries <= 0 {
+		maxEntries = defaultMaxEntries
+	}
+	return &Memory{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    map[string]*list.Element{},
+		counters:   map[string]int64{},
+	}
+}
+
+func (m *Memory) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if n, ok := m.counters[key]; ok {
+		return []byte(strconv.FormatInt(n, 10)), true, nil
+	}
+	el, ok := m.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	e := el.Value.(*memoryEntry)
+	if !e.expires.IsZero() && time.Now().After(e.expires) {
+		m.remove(el)
+		return nil, false, nil
+	}
+	m.order.MoveToFront(el)
+	return e.value, true, nil
+}
+
+func (m *Memory) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	var expires time.Time
+	if ttl > 0 {
+		expires = time.Now().Add(ttl)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.put(key, value, expires)
+	return nil
+}
+
+func (m *Memory) Incr(ctx context.Context, key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counters[key]++
+	return m.counters[key], nil
+}
+
+// put stores an entry and evicts the oldest ones over maxEntries. m.mu must
+// be held.
+func (m *Memory) put(key string, value []byte, expires time.Time) {
+	if el, ok := m.entries[key]; ok {
+		e := el.Value.(*memoryEntry)
+		e.value, e.expires = value, expires
+		m.order.MoveToFront(el)
+		return
+	}
+	m.entries[key] = m.order.PushFront(&memoryEntry{key: key, value: value, expires: expires})
+	for m.order.Len() > m.maxEntries {
+		m.remove(m.order.Back())
+	}
+}
+
+func (m *Memory) remove(el *list.Element) {
+	m.order.Remove(el)
+	delete(m.entries, el.Value.(*memoryEntry).key)
+}
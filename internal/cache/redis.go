@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisTimeout bounds dialing and one command when ctx has no earlier
+// deadline: a slow cache must not hold up the request it would have sped up.
+const redisTimeout = 2 * time.Second
+
+// Redis is a Store in Redis, shared by every API instance that points at
+// the same server. go-redis pools, reconnects and retries (with backoff).
+type Redis struct {
+	client *redis.Client
+}
+
+// NewRedis connects to the server at rawURL
+// (redis://[[user]:password@]host[:port][/db], rediss:// for TLS) and checks
+// it answers.
+func NewRedis(rawURL string) (*Redis, error) {
+	opts, err := redis.ParseURL(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("redis: REDIS_URL: %w", err)
+	}
+	opts.DialTimeout = redisTimeout
+	opts.ReadTimeout = redisTimeout
+	opts.WriteTimeout = redisTimeout
+	opts.ContextTimeoutEnabled = true
+
+	r := &Redis{client: redis.NewClient(opts)}
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	if err := r.client.Ping(ctx).Err(); err != nil {
+		r.client.Close()
+		return nil, fmt.Errorf("redis: PING: %w", err)
+	}
+	return r, nil
+}
+
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := r.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("redis: GET: %w", err)
+	}
+	return value, true, nil
+}
+
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := r.client.Set(ctx, key, value, ttl).Err(); err != nil {
+		return fmt.Errorf("redis: SET: %w", err)
+	}
+	return nil
+}
+
+func (r *Redis) Incr(ctx context.Context, key string) (int64, error) {
+	n, err := r.client.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("redis: INCR %s: %w", key, err)
+	}
+	return n, nil
+}
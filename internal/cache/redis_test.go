@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestRedis(t *testing.T) {
+	srv := miniredis.RunT(t)
+	srv.RequireAuth("secret")
+	ctx := context.Background()
+
+	if _, err := NewRedis("redis://" + srv.Addr()); err == nil {
+		t.Fatal("NewRedis without the password: want an error")
+	}
+	r, err := NewRedis("redis://:secret@" + srv.Addr() + "/0")
+	if err != nil {
+		t.Fatalf("NewRedis: %v", err)
+	}
+
+	if _, found, err := r.Get(ctx, "missing"); err != nil || found {
+		t.Fatalf("Get(missing) = found %v, err %v; want not found", found, err)
+	}
+
+	if err := r.Set(ctx, "k", []byte("v"), time.Minute); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if v, found, err := r.Get(ctx, "k"); err != nil || !found || string(v) != "v" {
+		t.Fatalf("Get(k) = %q, %v, %v; want \"v\"", v, found, err)
+	}
+	srv.FastForward(2 * time.Minute)
+	if _, found, _ := r.Get(ctx, "k"); found {
+		t.Fatal("Get(k) after its ttl: want not found")
+	}
+
+	for want := int64(1); want <= 2; want++ {
+		if n, err := r.Incr(ctx, "gen"); err != nil || n != want {
+			t.Fatalf("Incr = %d, %v; want %d", n, err, want)
+		}
+	}
+}
+
+func TestRedisReconnects(t *testing.T) {
+	srv := miniredis.RunT(t)
+	ctx := context.Background()
+	r, err := NewRedis("redis://" + srv.Addr())
+	if err != nil {
+		t.Fatalf("NewRedis: %v", err)
+	}
+
+	addr := srv.Addr()
+	srv.Close()
+	if _, _, err := r.Get(ctx, "k"); err == nil {
+		t.Fatal("Get with the server down: want an error")
+	}
+	if err := srv.StartAddr(addr); err != nil {
+		t.Fatalf("restart: %v", err)
+	}
+	if err := r.Set(ctx, "k", []byte("v"), 0); err != nil {
+		t.Fatalf("Set after the server came back: %v", err)
+	}
+}
+
+func TestNewStore(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantNil bool
+		wantErr bool
+	}{
+		{name: "default", cfg: Config{}},
+		{name: "memory", cfg: Config{Backend: "Memory"}},
+		{name: "none", cfg: Config{Backend: "none"}, wantNil: true},
+		{name: "redis without url", cfg: Config{Backend: "redis"}, wantErr: true},
+		{name: "redis bad scheme", cfg: Config{Backend: "redis", RedisURL: "http://localhost"}, wantErr: true},
+		{name: "unknown", cfg: Config{Backend: "memcached"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewStore(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (s == nil) != tt.wantNil {
+				t.Fatalf("store = %v, want nil %v", s, tt.wantNil)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package cv
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"cv-search/internal/llm"
+)
+
+// PIIKind is a category of personal data masked by anonymization.
+type PIIKind string
+
+const (
+	PIIEmail     PIIKind = "email"
+	PIIPhone     PIIKind = "phone"
+	PIIAddress   PIIKind = "address"
+	PIIBirthDate PIIKind = "birth_date"
+	PIIPhoto     PIIKind = "photo"
+)
+
+// piiMask is what each kind of PII is replaced with.
+var piiMask = map[PIIKind]string{
+	PIIEmail:     "[EMAIL]",
+	PIIPhone:     "[PHONE]",
+	PIIAddress:   "[ADDRESS]",
+	PIIBirthDate: "[BIRTH_DATE]",
+	PIIPhoto:     "[PHOTO]",
+}
+
+var (
+	emailRe = regexp.MustCompile(`(?i)[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,}`)
+
+	// phoneCandidateRe finds digit runs with phone punctuation; isPhone then
+	// checks the digit count so years and date ranges are left alone.
+	phoneCandidateRe = regexp.MustCompile(`(?:\+|\b)\d[\d\s().\-/]{6,}\d\b`)
+	dateRangeRe      = regexp.MustCompile(`^(?:\d{1,2}[./])?(?:19|20)\d{2}(?:[./]\d{1,2})?(?:\s*[-–/]\s*(?:\d{1,2}[./])?(?:19|20)\d{2}(?:[./]\d{1,2})?)*$`)
+
+	// Labelled values: everything after "label:" up to the end of the line.
+	// Turkish capitals (İ, ı) don't case-fold to i, hence the classes.
+	birthDateRe = regexp.MustCompile(`(?im)(\b(?:date\s+of\s+birth|birth\s*date|d\.?\s?o\.?\s?b\.?|born(?:\s+on)?|do[gğ]um\s+(?:yer[iİı]\s+ve\s+)?tar[iİı]h[iİı]|d\.\s?tar[iİı]h[iİı])\s*[:\-–]\s*)(\S[^\n]*)`)
+	addressRe   = regexp.MustCompile(`(?im)(\b(?:home\s+|postal\s+|ev\s+)?(?:address|adres|adres[iİı]|[iİı]kamet(?:gah|gâh)?)\s*[:\-–]\s*)(\S[^\n]*)`)
+	photoRe     = regexp.MustCompile(`(?im)(\b(?:photo(?:graph)?|picture|foto[gğ]raf|res[iİı]m)\s*[:\-–]\s*)(\S[^\n]*)`)
+
+	// Unlabelled street addresses: a street word and a number on one line.
+	streetRe    = regexp.MustCompile(`(?i)(?:^|[\s,])(?:mah\.|mahallesi|mh\.|cad\.|caddesi|cd\.|sok\.|sokak|sokağı|sk\.|bulvar[ıi]|blv\.|apt\.|apartman[ıi]|street|st\.|avenue|ave\.|road|rd\.|lane|ln\.)(?:$|[\s,])`)
+	streetNumRe = regexp.MustCompile(`\d`)
+
+	imageFileRe = regexp.MustCompile(`(?i)\S+\.(?:jpe?g|png|gif|bmp|heic|webp|tiff?)\b`)
+)
+
+// maxAddressLine bounds the line length for unlabelled address detection,
+// so a long experience paragraph mentioning a road isn't masked wholesale.
+const maxAddressLine = 120
+
+// AnonymizeText masks emails, phone numbers, addresses, birth dates and
+// photo references in text. Returns the masked text and how many of each
+// kind were replaced.
+func AnonymizeText(text string) (string, map[PIIKind]int) {
+	counts := map[PIIKind]int{}
+	mask := func(kind PIIKind) func(string) string {
+		return func(string) string {
+			counts[kind]++
+			return piiMask[kind]
+		}
+	}
+	maskLabelled := func(re *regexp.Regexp, kind PIIKind, s string) string {
+		return re.ReplaceAllStringFunc(s, func(m string) string {
+			sub := re.FindStringSubmatch(m)
+			if strings.HasPrefix(sub[2], "[") { // already masked
+				return m
+			}
+			counts[kind]++
+			return sub[1] + piiMask[kind]
+		})
+	}
+
+	text = emailRe.ReplaceAllStringFunc(text, mask(PIIEmail))
+	text = maskLabelled(birthDateRe, PIIBirthDate, text)
+	text = maskLabelled(addressRe, PIIAddress, text)
+	text = maskLabelled(photoRe, PIIPhoto, text)
+	text = imageFileRe.ReplaceAllStringFunc(text, mask(PIIPhoto))
+	text = phoneCandidateRe.ReplaceAllStringFunc(text, func(m string) string {
+		if !isPhone(m) {
+			return m
+		}
+		counts[PIIPhone]++
+		return piiMask[PIIPhone]
+	})
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if len(trimmed) <= maxAddressLine && streetRe.MatchString(trimmed) && streetNumRe.MatchString(trimmed) {
+			counts[PIIAddress]++
+			lines[i] = piiMask[PIIAddress]
+		}
+	}
+	return strings.Join(lines, "\n"), counts
+}
+
+// isPhone reports whether a digit run looks like a phone number rather than
+// a year, a date range or a short number.
+func isPhone(s string) bool {
+	digits := 0
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			digits++
+		}
+	}
+	if digits < 9 || digits > 15 {
+		return false
+	}
+	return !dateRangeRe.MatchString(strings.TrimSpace(s))
+}
+
+// Anonymize masks PII in the parsed text and its sections (see
+// AnonymizeText), drops the document header and photo, and marks the CV as
+// anonymized. Hash the original text before calling it, so duplicate
+// detection still works.
+func (p *ParsedCV) Anonymize() map[PIIKind]int {
+	var counts map[PIIKind]int
+	p.FullText, counts = AnonymizeText(p.FullText)
+	for i := range p.Sections {
+		p.Sections[i].Text, _ = AnonymizeText(p.Sections[i].Text)
+	}
+	p.Header, p.Photo = nil, nil
+	p.Anonymized = true
+	return counts
+}
+
+// AnonymizeExtraction masks PII the LLM copied into an extraction's free-text
+// fields. Locations that are street addresses rather than places are
+// dropped; cities and countries are kept for location search.
+func AnonymizeExtraction(e *llm.CVExtraction) {
+	if e == nil {
+		return
+	}
+	mask := func(s *string) {
+		*s, _ = AnonymizeText(*s)
+	}
+	mask(&e.Candidate.Name)
+	mask(&e.Candidate.CurrentPosition)
+	e.Candidate.Email, e.Candidate.Phone, e.Candidate.LinkedInURL = "", "", ""
+	for i := range e.Companies {
+		mask(&e.Companies[i].Name)
+		mask(&e.Companies[i].Position)
+	}
+	for i := range e.Education {
+		mask(&e.Education[i].Institution)
+		mask(&e.Education[i].Field)
+	}
+	for i := range e.Projects {
+		mask(&e.Projects[i].Name)
+		mask(&e.Projects[i].Description)
+		mask(&e.Projects[i].Impact)
+		mask(&e.Projects[i].Company)
+	}
+	locations := e.Locations[:0]
+	for _, loc := range e.Locations {
+		if masked, _ := AnonymizeText(loc); masked == loc {
+			locations = append(locations, loc)
+		}
+	}
+	e.Locations = locations
+}
+
+// MaskNames replaces whole-word, case-insensitive occurrences of names in
+// text with mask, e.g. a candidate's employers in free text. Names shorter
+// than two letters are left alone.
+func MaskNames(text string, names []string, mask string) string {
+	mask = strings.ReplaceAll(mask, "$", "$$")
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if utf8.RuneCountInString(name) < 2 {
+			continue
+		}
+		re := regexp.MustCompile(`(?i)(^|[^\p{L}\p{N}])` + regexp.QuoteMeta(name) + `($|[^\p{L}\p{N}])`)
+		// Matches share their boundary characters, so adjacent occurrences
+		// need a second pass.
+		for i := 0; i < 2; i++ {
+			text = re.ReplaceAllString(text, "${1}"+mask+"${2}")
+		}
+	}
+	return text
+}
@@ -0,0 +1,106 @@
+package cv
+
+import (
+	"sort"
+	"strings"
+
+	"cv-search/internal/llm"
+)
+
+// Profile is what one CV says about a candidate, as far as change detection
+// between CV versions is concerned.
+type Profile struct {
+	Seniority      string
+	Position       string
+	Skills         []string
+	Companies      []string
+	Certifications []string
+	Languages      []string
+}
+
+// ProfileOf returns the profile of an extraction.
+func ProfileOf(e *llm.CVExtraction) Profile {
+	p := Profile{Seniority: e.Candidate.Seniority, Position: e.Candidate.CurrentPosition}
+	for _, s := range e.Skills {
+		p.Skills = append(p.Skills, s.Name)
+	}
+	for _, c := range e.Companies {
+		p.Companies = append(p.Companies, c.Name)
+	}
+	for _, c := range e.Certifications {
+		p.Certifications = append(p.Certifications, c.Name)
+	}
+	for _, l := range e.Languages {
+		p.Languages = append(p.Languages, l.Name)
+	}
+	return p
+}
+
+// FieldChange is a single value that changed between CV versions.
+type FieldChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Changes is what a candidate's new CV adds or drops compared with their
+// previous one (cv_files.changes).
+type Changes struct {
+	PreviousCVID      int64        `json:"previous_cv_id"`
+	AddedSkills       []string     `json:"added_skills,omitempty"`
+	RemovedSkills     []string     `json:"removed_skills,omitempty"`
+	NewEmployers      []string     `json:"new_employers,omitempty"`
+	NewCertifications []string     `json:"new_certifications,omitempty"`
+	NewLanguages      []string     `json:"new_languages,omitempty"`
+	Seniority         *FieldChange `json:"seniority,omitempty"`
+	Position          *FieldChange `json:"current_position,omitempty"`
+}
+
+// Empty reports whether nothing changed.
+func (c Changes) Empty() bool {
+	return len(c.AddedSkills) == 0 && len(c.RemovedSkills) == 0 && len(c.NewEmployers) == 0 &&
+		len(c.NewCertifications) == 0 && len(c.NewLanguages) == 0 && c.Seniority == nil && c.Position == nil
+}
+
+// DiffProfiles compares a candidate's previous CV with the new one. Names
+// are compared case-insensitively; values missing from either side (an
+// older CV without a seniority, say) don't count as a change.
+func DiffProfiles(prev, next Profile) Changes {
+	return Changes{
+		AddedSkills:       missingFrom(prev.Skills, next.Skills),
+		RemovedSkills:     missingFrom(next.Skills, prev.Skills),
+		NewEmployers:      missingFrom(prev.Companies, next.Companies),
+		NewCertifications: missingFrom(prev.Certifications, next.Certifications),
+		NewLanguages:      missingFrom(prev.Languages, next.Languages),
+		Seniority:         fieldChange(prev.Seniority, next.Seniority),
+		Position:          fieldChange(prev.Position, next.Position),
+	}
+}
+
+// missingFrom returns the names in b that aren't in a, sorted and deduplicated.
+func missingFrom(a, b []string) []string {
+	have := make(map[string]bool, len(a))
+	for _, s := range a {
+		have[changeKey(s)] = true
+	}
+	var out []string
+	for _, s := range b {
+		if k := changeKey(s); k != "" && !have[k] {
+			have[k] = true
+			out = append(out, strings.TrimSpace(s))
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func fieldChange(from, to string) *FieldChange {
+	from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+	if from == "" || to == "" || changeKey(from) == changeKey(to) {
+		return nil
+	}
+	return &FieldChange{From: from, To: to}
+}
+
+func changeKey(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
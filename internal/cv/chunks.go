@@ -0,0 +1,294 @@
+package cv
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"cv-search/internal/llm"
+)
+
+// Long CVs (40-page consultant profiles) are stored as chunks (cv_chunks) at
+// parse time. Each chunk is embedded for vector search on its own, and CVs
+// too long for one extraction prompt are extracted group of chunks by group
+// and the results merged (ExtractChunked).
+
+const (
+	// ChunkTokens is the target size of a stored chunk: a few jobs or
+	// projects, well under the embedding model's input limit.
+	ChunkTokens = 1000
+	// ExtractionPromptTokens is the most CV text sent in one extraction
+	// prompt; longer CVs are extracted with ExtractChunked.
+	ExtractionPromptTokens = 6000
+)
+
+// Chunk is one piece of a CV's text.
+type Chunk struct {
+	Index  int
+	Text   string
+	Tokens int // estimated, see EstimateTokens
+}
+
+// EstimateTokens approximates the LLM token count of s (about four
+// characters per token).
+func EstimateTokens(s string) int {
+	return (utf8.RuneCountInString(s) + 3) / 4
+}
+
+// ChunkText splits text into chunks of at most maxTokens, breaking between
+// paragraphs where possible. Text formatted by FormatSections keeps its
+// "### KIND" headings: a chunk that starts inside a section repeats the
+// section's heading so it still says what it's about. Blank text has no
+// chunks.
+func ChunkText(text string, maxTokens int) []Chunk {
+	var chunks []Chunk
+	var cur strings.Builder
+	heading := ""
+	flush := func() {
+		if s := strings.TrimSpace(cur.String()); s != "" {
+			chunks = append(chunks, Chunk{Index: len(chunks), Text: s, Tokens: EstimateTokens(s)})
+		}
+		cur.Reset()
+	}
+	add := func(block string) {
+		if cur.Len() > 0 && EstimateTokens(cur.String())+EstimateTokens(block) > maxTokens {
+			flush()
+		}
+		if cur.Len() == 0 && heading != "" && !strings.HasPrefix(block, heading) {
+			cur.WriteString(heading + "\n")
+		} else if cur.Len() > 0 {
+			cur.WriteString("\n\n")
+		}
+		cur.WriteString(block)
+	}
+
+	for _, block := range strings.Split(text, "\n\n") {
+		block = strings.TrimSpace(block)
+		if block == "" {
+			continue
+		}
+		if strings.HasPrefix(block, "### ") {
+			heading, _, _ = strings.Cut(block, "\n")
+		}
+		for _, piece := range splitBlock(block, maxTokens) {
+			add(piece)
+		}
+	}
+	flush()
+	return chunks
+}
+
+// splitBlock cuts a paragraph longer than maxTokens at line breaks, and a
+// line that is still too long at spaces (see splitLine).
+func splitBlock(block string, maxTokens int) []string {
+	if EstimateTokens(block) <= maxTokens {
+		return []string{block}
+	}
+	var pieces []string
+	var cur strings.Builder
+	for _, line := range strings.Split(block, "\n") {
+		for _, part := range splitLine(line, maxTokens) {
+			if cur.Len() > 0 && EstimateTokens(cur.String())+EstimateTokens(part) > maxTokens {
+				pieces = append(pieces, cur.String())
+				cur.Reset()
+			}
+			if cur.Len() > 0 {
+				cur.WriteByte('\n')
+			}
+			cur.WriteString(part)
+		}
+	}
+	if cur.Len() > 0 {
+		pieces = append(pieces, cur.String())
+	}
+	return pieces
+}
+
+func splitLine(line string, maxTokens int) []string {
+	if EstimateTokens(line) <= maxTokens {
+		return []string{line}
+	}
+	var words []string
+	for _, word := range strings.Fields(line) {
+		// A "word" that long is garbage (base64, a table flattened without
+		// spaces); cut it anyway.
+		for r := []rune(word); len(r) > 0; {
+			n := min(len(r), maxTokens*4)
+			words = append(words, string(r[:n]))
+			r = r[n:]
+		}
+	}
+	var parts []string
+	var cur strings.Builder
+	for _, word := range words {
+		if cur.Len() > 0 && EstimateTokens(cur.String())+EstimateTokens(word)+1 > maxTokens {
+			parts = append(parts, cur.String())
+			cur.Reset()
+		}
+		if cur.Len() > 0 {
+			cur.WriteByte(' ')
+		}
+		cur.WriteString(word)
+	}
+	if cur.Len() > 0 {
+		parts = append(parts, cur.String())
+	}
+	return parts
+}
+
+// ChunksTokens is the estimated token count of all chunks together.
+func ChunksTokens(chunks []Chunk) int {
+	n := 0
+	for _, c := range chunks {
+		n += c.Tokens
+	}
+	return n
+}
+
+// GroupChunks joins consecutive chunks into texts of at most maxTokens each
+// (a single larger chunk gets a group of its own).
+func GroupChunks(chunks []Chunk, maxTokens int) []string {
+	var groups []string
+	var cur strings.Builder
+	tokens := 0
+	for _, c := range chunks {
+		if cur.Len() > 0 && tokens+c.Tokens > maxTokens {
+			groups = append(groups, cur.String())
+			cur.Reset()
+			tokens = 0
+		}
+		if cur.Len() > 0 {
+			cur.WriteString("\n\n")
+		}
+		cur.WriteString(c.Text)
+		tokens += c.Tokens
+	}
+	if cur.Len() > 0 {
+		groups = append(groups, cur.String())
+	}
+	return groups
+}
+
+// ExtractChunked is the map-reduce extraction of a CV too long for one
+// prompt: every group of chunks (up to ExtractionPromptTokens) is extracted
+// on its own, with hint (FormatHeaderHint, may be "") appended, and the
+// results are merged with MergeExtractions.
+func ExtractChunked(ctx context.Context, svc *llm.Service, chunks []Chunk, hint string) (*llm.CVExtraction, error) {
+	groups := GroupChunks(chunks, ExtractionPromptTokens)
+	parts := make([]*llm.CVExtraction, 0, len(groups))
+	for i, text := range groups {
+		if hint != "" {
+			text += "\n\n" + hint
+		}
+		e, err := svc.ExtractEntities(ctx, text)
+		if err != nil {
+			return nil, fmt.Errorf("extract chunk group %d/%d: %w", i+1, len(groups), err)
+		}
+		parts = append(parts, e)
+	}
+	return MergeExtractions(parts), nil
+}
+
+// MergeExtractions combines extractions of different parts of one CV.
+// Candidate fields come from the first part that has them (the top of a CV
+// says who they are and what they do now); list entries are deduplicated by
+// name, keeping the first one seen and filling in what it lacks.
+func MergeExtractions(parts []*llm.CVExtraction) *llm.CVExtraction {
+	out := &llm.CVExtraction{}
+	skills := map[string]int{}
+	companies := map[string]int{}
+	education := map[string]bool{}
+	locations := map[string]bool{}
+	languages := map[string]int{}
+	certifications := map[string]bool{}
+	projects := map[string]bool{}
+
+	c := &out.Candidate
+	fill := func(dst *string, src string) {
+		if strings.TrimSpace(*dst) == "" {
+			*dst = src
+		}
+	}
+
+	for _, p := range parts {
+		if p == nil {
+			continue
+		}
+		fill(&c.Name, p.Candidate.Name)
+		fill(&c.CurrentPosition, p.Candidate.CurrentPosition)
+		fill(&c.Seniority, p.Candidate.Seniority)
+		fill(&c.Email, p.Candidate.Email)
+		fill(&c.Phone, p.Candidate.Phone)
+		fill(&c.LinkedInURL, p.Candidate.LinkedInURL)
+		if c.TotalExperienceYears == nil {
+			c.TotalExperienceYears = p.Candidate.TotalExperienceYears
+		}
+
+		for _, s := range p.Skills {
+			k := changeKey(s.Name)
+			i, ok := skills[k]
+			if !ok {
+				skills[k] = len(out.Skills)
+				out.Skills = append(out.Skills, s)
+				continue
+			}
+			have := &out.Skills[i]
+			if s.Years != nil && (have.Years == nil || *s.Years > *have.Years) {
+				have.Years = s.Years
+			}
+			if have.LastUsedYear == nil {
+				have.LastUsedYear = s.LastUsedYear
+			}
+			if have.Proficiency == "" {
+				have.Proficiency = s.Proficiency
+			}
+			have.Confidence = max(have.Confidence, s.Confidence)
+		}
+		for _, co := range p.Companies {
+			k := changeKey(co.Name) + "|" + changeKey(co.Position)
+			if i, ok := companies[k]; ok {
+				out.Companies[i].IsCurrent = out.Companies[i].IsCurrent || co.IsCurrent
+				continue
+			}
+			companies[k] = len(out.Companies)
+			out.Companies = append(out.Companies, co)
+		}
+		for _, ed := range p.Education {
+			if k := changeKey(ed.Institution) + "|" + changeKey(ed.Degree); !education[k] {
+				education[k] = true
+				out.Education = append(out.Education, ed)
+			}
+		}
+		for _, loc := range p.Locations {
+			if k := changeKey(loc); k != "" && !locations[k] {
+				locations[k] = true
+				out.Locations = append(out.Locations, loc)
+			}
+		}
+		for _, l := range p.Languages {
+			k := changeKey(l.Name)
+			if i, ok := languages[k]; ok {
+				if out.Languages[i].Proficiency == "" {
+					out.Languages[i].Proficiency = l.Proficiency
+				}
+				continue
+			}
+			languages[k] = len(out.Languages)
+			out.Languages = append(out.Languages, l)
+		}
+		for _, cert := range p.Certifications {
+			if k := changeKey(cert.Name); !certifications[k] {
+				certifications[k] = true
+				out.Certifications = append(out.Certifications, cert)
+			}
+		}
+		for _, pr := range p.Projects {
+			if k := changeKey(pr.Name) + "|" + changeKey(pr.Company); !projects[k] {
+				projects[k] = true
+				out.Projects = append(out.Projects, pr)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,74 @@
+package cv
+
+import (
+	"net/mail"
+	"regexp"
+	"strings"
+
+	"cv-search/internal/llm"
+)
+
+// ContactInfo is the candidate's contact details as found in a CV.
+type ContactInfo struct {
+	Email       string
+	Phone       string
+	LinkedInURL string
+}
+
+// linkedInURLRe matches a LinkedIn profile URL with or without scheme.
+var linkedInURLRe = regexp.MustCompile(`(?i)(?:https?://)?(?:[a-z]{2,3}\.)?linkedin\.com/in/([a-z0-9\-_%.]+)`)
+
+// ExtractContactInfo returns the first email address, phone number and
+// LinkedIn profile URL in text. Masked text (see AnonymizeText) yields none.
+func ExtractContactInfo(text string) ContactInfo {
+	var c ContactInfo
+	c.Email = NormalizeEmail(emailRe.FindString(text))
+	for _, m := range phoneCandidateRe.FindAllString(text, -1) {
+		if isPhone(m) {
+			c.Phone = strings.TrimSpace(m)
+			break
+		}
+	}
+	c.LinkedInURL = NormalizeLinkedInURL(linkedInURLRe.FindString(text))
+	return c
+}
+
+// NormalizeEmail lowercases a plain address and returns "" for anything
+// else (display names, masks, prose).
+func NormalizeEmail(s string) string {
+	s = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(s), "mailto:"))
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Address != s || !emailRe.MatchString(s) {
+		return ""
+	}
+	return strings.ToLower(s)
+}
+
+// NormalizeLinkedInURL returns the canonical https://www.linkedin.com/in/<id>
+// form of a profile URL, or "" if s isn't one.
+func NormalizeLinkedInURL(s string) string {
+	m := linkedInURLRe.FindStringSubmatch(s)
+	if m == nil {
+		return ""
+	}
+	return "https://www.linkedin.com/in/" + strings.ToLower(strings.TrimRight(m[1], "."))
+}
+
+// FillContactInfo checks the contact fields the LLM returned and fills the
+// missing or invalid ones from the CV text itself.
+func FillContactInfo(e *llm.CVExtraction, text string) {
+	if e == nil {
+		return
+	}
+	found := ExtractContactInfo(text)
+	c := &e.Candidate
+	if c.Email = NormalizeEmail(c.Email); c.Email == "" {
+		c.Email = found.Email
+	}
+	if c.Phone = strings.TrimSpace(c.Phone); !isPhone(c.Phone) {
+		c.Phone = found.Phone
+	}
+	if c.LinkedInURL = NormalizeLinkedInURL(c.LinkedInURL); c.LinkedInURL == "" {
+		c.LinkedInURL = found.LinkedInURL
+	}
+}
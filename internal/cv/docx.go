@@ -0,0 +1,332 @@
+package cv
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image"
+	_ "image/jpeg" // DecodeConfig of embedded photos
+	_ "image/png"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"cv-search/internal/llm"
+)
+
+// Word CVs often keep the candidate's name, title and contact details in
+// the page header (frequently a table next to a photo) and sometimes in the
+// document properties. Those are more reliable than what the LLM reads out
+// of the flattened text, so they are pulled out of the .docx directly and
+// used to seed the extraction (FormatHeaderHint, ApplyDocumentHeader).
+
+// DocumentHeader holds the fields read from a DOCX's page header and
+// document properties (cv_files.header).
+type DocumentHeader struct {
+	Name        string     `json:"name,omitempty"`
+	Title       string     `json:"title,omitempty"`
+	Email       string     `json:"email,omitempty"`
+	Phone       string     `json:"phone,omitempty"`
+	LinkedInURL string     `json:"linkedin_url,omitempty"`
+	Table       [][]string `json:"table,omitempty"` // header table cells, row by row
+}
+
+// Empty reports whether no field was found.
+func (h *DocumentHeader) Empty() bool {
+	return h == nil || (h.Name == "" && h.Title == "" && h.Email == "" && h.Phone == "" && h.LinkedInURL == "" && len(h.Table) == 0)
+}
+
+// Photo is an image embedded in a CV, most likely the candidate's photo.
+type Photo struct {
+	Data        []byte
+	ContentType string
+	Width       int
+	Height      int
+}
+
+// photoMinSide is the smallest width/height a photo has; smaller images are
+// icons and bullets. maxPhotoBytes skips anything too large to be one.
+const (
+	photoMinSide  = 80
+	maxPhotoBytes = 5 << 20
+)
+
+// genericAuthors are document authors that aren't a person's name.
+var genericAuthors = map[string]bool{
+	"microsoft office user": true, "office user": true, "windows user": true, "user": true,
+	"admin": true, "administrator": true, "owner": true, "author": true, "unknown": true,
+}
+
+// titleSeparatorRe splits "Jane Doe - Senior Developer" style document titles.
+var titleSeparatorRe = regexp.MustCompile(`\s+[-–—|•]\s+`)
+
+// DocxHeader reads the structured header of a .docx: the page header's
+// lines and table cells, then docProps/core.xml. text is the document's
+// extracted text; a document author only counts as the candidate's name if
+// it appears in it. Returns nil when nothing was found.
+func DocxHeader(data []byte, text string) *DocumentHeader {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil
+	}
+	h := &DocumentHeader{}
+	var lines []string
+	var coreTitle, coreCreator string
+	names := make([]string, 0, len(zr.File))
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		files[f.Name] = f
+	}
+	sort.Strings(names) // header1.xml, header2.xml, ...
+	for _, name := range names {
+		switch {
+		case strings.HasPrefix(name, "word/header") && strings.HasSuffix(name, ".xml"):
+			l, table, err := readWordPart(files[name])
+			if err != nil {
+				continue
+			}
+			lines = append(lines, l...)
+			h.Table = append(h.Table, table...)
+		case name == "docProps/core.xml":
+			coreTitle, coreCreator = readCoreProperties(files[name])
+		}
+	}
+
+	for i, line := range lines {
+		if looksLikePersonName(line) {
+			h.Name = line
+			if i+1 < len(lines) && looksLikeJobTitle(lines[i+1]) {
+				h.Title = lines[i+1]
+			}
+			break
+		}
+	}
+	if h.Name == "" && looksLikePersonName(coreCreator) && !genericAuthors[strings.ToLower(coreCreator)] &&
+		strings.Contains(strings.ToLower(text), strings.ToLower(coreCreator)) {
+		h.Name = coreCreator
+	}
+	if h.Title == "" && h.Name != "" {
+		// "Jane Doe - Senior Go Developer"
+		parts := titleSeparatorRe.Split(strings.TrimSpace(coreTitle), -1)
+		if len(parts) == 2 && strings.EqualFold(parts[0], h.Name) && looksLikeJobTitle(parts[1]) {
+			h.Title = parts[1]
+		}
+	}
+
+	contact := ExtractContactInfo(strings.Join(lines, "\n"))
+	h.Email, h.Phone, h.LinkedInURL = contact.Email, contact.Phone, contact.LinkedInURL
+	if h.Empty() {
+		return nil
+	}
+	return h
+}
+
+// readWordPart returns the paragraphs of a WordprocessingML part in order,
+// table cells included, and the rows of its top-level tables.
+func readWordPart(f *zip.File) (lines []string, table [][]string, err error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rc.Close()
+
+	dec := xml.NewDecoder(io.LimitReader(rc, 4<<20))
+	var para, cell strings.Builder
+	var row []string
+	inText, depth := false, 0 // depth = table nesting
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return lines, table, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				para.WriteByte(' ')
+			case "tbl":
+				depth++
+			case "tr":
+				row = nil
+			case "tc":
+				cell.Reset()
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				if s := strings.TrimSpace(para.String()); s != "" {
+					lines = append(lines, s)
+					if depth > 0 {
+						if cell.Len() > 0 {
+							cell.WriteByte('\n')
+						}
+						cell.WriteString(s)
+					}
+				}
+				para.Reset()
+			case "tc":
+				row = append(row, strings.TrimSpace(cell.String()))
+			case "tr":
+				if depth == 1 && len(row) > 0 {
+					table = append(table, row)
+				}
+			case "tbl":
+				depth--
+			}
+		case xml.CharData:
+			if inText {
+				para.Write(t)
+			}
+		}
+	}
+	return lines, table, nil
+}
+
+// readCoreProperties returns dc:title and dc:creator of docProps/core.xml.
+func readCoreProperties(f *zip.File) (title, creator string) {
+	rc, err := f.Open()
+	if err != nil {
+		return "", ""
+	}
+	defer rc.Close()
+	var props struct {
+		Title   string `xml:"title"`
+		Creator string `xml:"creator"`
+	}
+	if err := xml.NewDecoder(io.LimitReader(rc, 1<<20)).Decode(&props); err != nil {
+		return "", ""
+	}
+	return strings.TrimSpace(props.Title), strings.TrimSpace(props.Creator)
+}
+
+// looksLikePersonName: two to four capitalized words of letters (with
+// hyphens, apostrophes and initials), nothing else.
+func looksLikePersonName(s string) bool {
+	s = strings.TrimSpace(s)
+	words := strings.Fields(s)
+	if len(words) < 2 || len(words) > 4 || len(s) > 60 {
+		return false
+	}
+	if _, ok := sectionHeadings[normalizeHeading(s)]; ok {
+		return false
+	}
+	for _, w := range words {
+		r := []rune(w)
+		if !unicode.IsUpper(r[0]) {
+			return false
+		}
+		for _, c := range r {
+			if !unicode.IsLetter(c) && c != '-' && c != '\'' && c != '.' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// looksLikeJobTitle: a short line without contact details or digits.
+func looksLikeJobTitle(s string) bool {
+	s = strings.TrimSpace(s)
+	if s == "" || len(s) > 80 || len(strings.Fields(s)) > 8 {
+		return false
+	}
+	if strings.ContainsAny(s, "@/:") || strings.IndexFunc(s, unicode.IsDigit) >= 0 {
+		return false
+	}
+	_, heading := sectionHeadings[normalizeHeading(s)]
+	return !heading
+}
+
+// DocxPhoto returns the embedded image most likely to be the candidate's
+// photo: the largest PNG or JPEG in word/media that is at least
+// photoMinSide pixels and roughly portrait or square (logos are wide).
+func DocxPhoto(data []byte) *Photo {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil
+	}
+	var best *Photo
+	for _, f := range zr.File {
+		if !strings.HasPrefix(f.Name, "word/media/") || f.UncompressedSize64 > maxPhotoBytes {
+			continue
+		}
+		var contentType string
+		switch strings.ToLower(path.Ext(f.Name)) {
+		case ".jpg", ".jpeg":
+			contentType = "image/jpeg"
+		case ".png":
+			contentType = "image/png"
+		default:
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			continue
+		}
+		img, err := io.ReadAll(io.LimitReader(rc, maxPhotoBytes))
+		rc.Close()
+		if err != nil {
+			continue
+		}
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(img))
+		if err != nil || cfg.Width < photoMinSide || cfg.Height < photoMinSide {
+			continue
+		}
+		if ratio := float64(cfg.Height) / float64(cfg.Width); ratio < 0.75 || ratio > 2 {
+			continue
+		}
+		if best == nil || cfg.Width*cfg.Height > best.Width*best.Height {
+			best = &Photo{Data: img, ContentType: contentType, Width: cfg.Width, Height: cfg.Height}
+		}
+	}
+	return best
+}
+
+// FormatHeaderHint renders header fields as a block appended to the
+// extraction prompt's CV text, or "" if there are none.
+func FormatHeaderHint(h *DocumentHeader) string {
+	if h.Empty() {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("### " + llm.DocumentFieldsTag + "\n")
+	for _, f := range []struct{ label, value string }{
+		{"Name", h.Name}, {"Title", h.Title}, {"Email", h.Email}, {"Phone", h.Phone}, {"LinkedIn", h.LinkedInURL},
+	} {
+		if f.value != "" {
+			fmt.Fprintf(&b, "%s: %s\n", f.label, f.value)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// ApplyDocumentHeader fills the candidate fields the LLM left empty from the
+// document's own header.
+func ApplyDocumentHeader(e *llm.CVExtraction, h *DocumentHeader) {
+	if e == nil || h.Empty() {
+		return
+	}
+	c := &e.Candidate
+	fill := func(dst *string, src string) {
+		if strings.TrimSpace(*dst) == "" {
+			*dst = src
+		}
+	}
+	fill(&c.Name, h.Name)
+	fill(&c.CurrentPosition, h.Title)
+	fill(&c.Email, h.Email)
+	fill(&c.Phone, h.Phone)
+	fill(&c.LinkedInURL, h.LinkedInURL)
+}
@@ -0,0 +1,249 @@
+package cv
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/richardlehane/mscfb"
+)
+
+// Candidates often send their CV as an email attachment, and recruiters
+// forward those emails as-is. An email is parsed through the attachment that
+// looks most like a CV; the body text is used only when there is none.
+
+// maxEmailDepth bounds emails nested as attachments of emails.
+const maxEmailDepth = 3
+
+type emailAttachment struct {
+	filename string
+	data     []byte
+}
+
+// attachmentRank orders attachment formats by how likely they are to be the
+// CV; formats not listed are never picked (images, calendar invites, ...).
+var attachmentRank = map[string]int{
+	".pdf": 0, ".docx": 0, ".doc": 0, ".odt": 0, ".rtf": 0, ".pages": 0,
+	".eml": 1, ".msg": 1,
+	".txt": 2, ".md": 2, ".html": 2,
+}
+
+func (p *CVParser) parseEmail(format string, data []byte, depth int) (string, bool, error) {
+	if depth >= maxEmailDepth {
+		return "", false, errors.New("email attachments nested too deeply")
+	}
+	read := readEML
+	if format == ".msg" {
+		read = readMSG
+	}
+	body, attachments, err := read(data)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read email: %w", err)
+	}
+
+	var best *emailAttachment
+	bestRank := len(attachmentRank)
+	for i := range attachments {
+		a := &attachments[i]
+		rank, ok := attachmentRank[DetectFormat(a.filename, a.data)]
+		if ok && (rank < bestRank || (rank == bestRank && len(a.data) > len(best.data))) {
+			best, bestRank = a, rank
+		}
+	}
+	if best != nil {
+		text, _, ocrUsed, err := p.extract(best.filename, best.data, depth+1)
+		if err == nil && strings.TrimSpace(text) != "" {
+			return text, ocrUsed, nil
+		}
+		log.Printf("[CVParser] email attachment %s: %v", best.filename, err)
+	}
+
+	if strings.TrimSpace(body) == "" {
+		return "", false, errors.New("email has no CV attachment or body text")
+	}
+	return body, false, nil
+}
+
+// readEML returns the text body and attachments of an RFC 822 message.
+func readEML(data []byte) (string, []emailAttachment, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return "", nil, err
+	}
+	var e emlParts
+	if err := e.walk(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), "", msg.Body, 0); err != nil {
+		return "", nil, err
+	}
+	body := e.text.String()
+	if strings.TrimSpace(body) == "" && e.html.Len() > 0 {
+		body, _ = htmlText([]byte(e.html.String()))
+	}
+	return body, e.attachments, nil
+}
+
+type emlParts struct {
+	text, html  strings.Builder
+	attachments []emailAttachment
+}
+
+var wordDecoder = new(mime.WordDecoder)
+
+func (e *emlParts) walk(contentType, encoding, disposition string, body io.Reader, depth int) error {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		if depth >= 8 {
+			return errors.New("mime parts nested too deeply")
+		}
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			// multipart decodes quoted-printable itself and drops the header.
+			if err := e.walk(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"),
+				part.Header.Get("Content-Disposition"), part, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return fmt.Errorf("read %s part: %w", mediaType, err)
+	}
+
+	dispType, dispParams, _ := mime.ParseMediaType(disposition)
+	filename := dispParams["filename"]
+	if filename == "" {
+		filename = params["name"]
+	}
+	if decoded, err := wordDecoder.DecodeHeader(filename); err == nil {
+		filename = decoded
+	}
+	switch {
+	case mediaType == "message/rfc822":
+		if filename == "" {
+			filename = "forwarded.eml"
+		}
+		e.attachments = append(e.attachments, emailAttachment{filename: filename, data: content})
+	case filename != "" || dispType == "attachment":
+		e.attachments = append(e.attachments, emailAttachment{filename: filename, data: content})
+	case mediaType == "text/plain":
+		e.text.WriteString(strings.ToValidUTF8(string(content), ""))
+		e.text.WriteString("\n")
+	case mediaType == "text/html":
+		e.html.Write(content)
+	}
+	return nil
+}
+
+// Outlook .msg property streams: __substg1.0_<tag><type>, where type 001F is
+// a UTF-16 string, 001E an 8-bit string and 0102 binary.
+const (
+	msgPropBody           = "1000"
+	msgPropAttachData     = "3701"
+	msgPropAttachFilename = "3704"
+	msgPropAttachLongName = "3707"
+)
+
+// readMSG returns the text body and attachments of an Outlook .msg file.
+// Attached Outlook items (embedded messages) are skipped.
+func readMSG(data []byte) (string, []emailAttachment, error) {
+	doc, err := mscfb.New(bytes.NewReader(data))
+	if err != nil {
+		return "", nil, err
+	}
+
+	var body string
+	type msgAttachment struct {
+		shortName, longName string
+		data                []byte
+	}
+	attachments := map[string]*msgAttachment{}
+	var order []string
+
+	for entry, err := doc.Next(); err == nil; entry, err = doc.Next() {
+		name, ok := strings.CutPrefix(entry.Name, "__substg1.0_")
+		if !ok || len(name) != 8 || len(entry.Path) > 1 {
+			continue
+		}
+		tag, typ := name[:4], name[4:]
+		content := make([]byte, entry.Size)
+		if _, err := io.ReadFull(entry, content); err != nil {
+			return "", nil, fmt.Errorf("read msg stream %s: %w", entry.Name, err)
+		}
+
+		if len(entry.Path) == 0 {
+			if tag == msgPropBody {
+				body = msgString(typ, content)
+			}
+			continue
+		}
+		storage := entry.Path[0]
+		if !strings.HasPrefix(storage, "__attach_version1.0_") {
+			continue
+		}
+		a := attachments[storage]
+		if a == nil {
+			a = &msgAttachment{}
+			attachments[storage] = a
+			order = append(order, storage)
+		}
+		switch tag {
+		case msgPropAttachData:
+			a.data = content
+		case msgPropAttachFilename:
+			a.shortName = msgString(typ, content)
+		case msgPropAttachLongName:
+			a.longName = msgString(typ, content)
+		}
+	}
+
+	var out []emailAttachment
+	for _, storage := range order {
+		a := attachments[storage]
+		if a.data == nil {
+			continue
+		}
+		filename := a.longName
+		if filename == "" {
+			filename = a.shortName
+		}
+		out = append(out, emailAttachment{filename: filename, data: a.data})
+	}
+	return body, out, nil
+}
+
+func msgString(typ string, content []byte) string {
+	if typ != "001F" {
+		return strings.ToValidUTF8(strings.TrimRight(string(content), "\x00"), "")
+	}
+	u := make([]uint16, len(content)/2)
+	for i := range u {
+		u[i] = uint16(content[2*i]) | uint16(content[2*i+1])<<8
+	}
+	return strings.TrimRight(string(utf16.Decode(u)), "\x00")
+}
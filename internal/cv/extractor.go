@@ -1,6 +1,7 @@
 package cv
 
 import (
+	"context"
 	"cv-search/internal/llm"
 	"log"
 )
@@ -18,7 +19,7 @@ func NewExtractor(llmService *llm.Service, useLLM bool) *Extractor {
 }
 
 // Extract entities from CV text using LLM
-func (e *Extractor) Extract(cvText string) (*llm.CVExtraction, error) {
+func (e *Extractor) Extract(ctx context.Context, cvText string) (*llm.CVExtraction, error) {
 	if !e.useLLM || e.llmService == nil {
 		log.Println("LLM disabled, returning empty extraction")
 		return &llm.CVExtraction{
@@ -28,7 +29,7 @@ func (e *Extractor) Extract(cvText string) (*llm.CVExtraction, error) {
 	}
 
 	log.Println("Extracting entities using LLM...")
-	extraction, err := e.llmService.ExtractEntities(cvText)
+	extraction, err := e.llmService.ExtractEntities(ctx, cvText)
 	if err != nil {
 		log.Printf("LLM extraction failed: %v", err)
 		return nil, err
@@ -0,0 +1,302 @@
+package cv
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/mail"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"code.sajari.com/docconv"
+	"golang.org/x/net/html"
+)
+
+// Parser extracts the plain text of one document format.
+type Parser interface {
+	Parse(data []byte) (string, error)
+}
+
+// ParserFunc adapts a function to Parser.
+type ParserFunc func(data []byte) (string, error)
+
+func (f ParserFunc) Parse(data []byte) (string, error) { return f(data) }
+
+// docconvParser wraps one of docconv's reader-based converters.
+func docconvParser(convert func(io.Reader) (string, map[string]string, error)) Parser {
+	return ParserFunc(func(data []byte) (string, error) {
+		text, _, err := convert(bytes.NewReader(data))
+		return text, err
+	})
+}
+
+// defaultParsers are the built-in parsers by format. Emails (.eml, .msg)
+// aren't here: CVParser parses them through their attachments.
+func defaultParsers() map[string]Parser {
+	return map[string]Parser{
+		".pdf":   docconvParser(docconv.ConvertPDF),
+		".docx":  docconvParser(docconv.ConvertDocx),
+		".doc":   docconvParser(docconv.ConvertDoc),
+		".rtf":   docconvParser(docconv.ConvertRTF),
+		".odt":   docconvParser(docconv.ConvertODT),
+		".pages": ParserFunc(pagesText),
+		".html":  ParserFunc(htmlText),
+		".md":    ParserFunc(markdownText),
+		".txt":   ParserFunc(plainText),
+	}
+}
+
+// formatAliases maps extensions to the format name used by the parsers.
+var formatAliases = map[string]string{".htm": ".html", ".xhtml": ".html", ".markdown": ".md", ".text": ".txt"}
+
+var (
+	zipMagic = []byte("PK\x03\x04")
+	oleMagic = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+	// Outlook .msg files are OLE containers whose streams are named
+	// __substg1.0_<property> (UTF-16 in the directory).
+	msgStreamName = utf16LE("__substg1.0_")
+)
+
+// DetectFormat returns the format of a document (".pdf", ".docx", ...) from
+// its content, so a renamed or extension-less file is still parsed right.
+// The extension only decides between formats content can't tell apart
+// (.txt vs .md) and is returned as-is for content that isn't recognized.
+func DetectFormat(filename string, data []byte) string {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if alias, ok := formatAliases[ext]; ok {
+		ext = alias
+	}
+
+	switch {
+	case bytes.HasPrefix(data, []byte("%PDF-")):
+		return ".pdf"
+	case bytes.HasPrefix(data, []byte(`{\rtf`)):
+		return ".rtf"
+	case bytes.HasPrefix(data, zipMagic):
+		if format := zipFormat(data); format != "" {
+			return format
+		}
+		return ext
+	case bytes.HasPrefix(data, oleMagic):
+		if bytes.Contains(data, msgStreamName) {
+			return ".msg"
+		}
+		return ".doc"
+	}
+
+	contentType := http.DetectContentType(data)
+	if !strings.HasPrefix(contentType, "text/") {
+		return ext
+	}
+	switch ext {
+	case ".txt", ".md", ".html", ".eml":
+		return ext
+	}
+	if strings.HasPrefix(contentType, "text/html") {
+		return ".html"
+	}
+	if looksLikeEmail(data) {
+		return ".eml"
+	}
+	return ".txt"
+}
+
+// zipFormat tells the zip-based document formats apart by their entries.
+func zipFormat(data []byte) string {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return ""
+	}
+	for _, f := range zr.File {
+		switch {
+		case f.Name == "word/document.xml":
+			return ".docx"
+		case f.Name == "mimetype":
+			if rc, err := f.Open(); err == nil {
+				mt, _ := io.ReadAll(io.LimitReader(rc, 128))
+				rc.Close()
+				if strings.HasPrefix(string(mt), "application/vnd.oasis.opendocument.text") {
+					return ".odt"
+				}
+			}
+		case f.Name == "index.xml", strings.HasPrefix(f.Name, "Index/"), f.Name == "QuickLook/Preview.pdf":
+			return ".pages"
+		}
+	}
+	return ""
+}
+
+func looksLikeEmail(data []byte) bool {
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return false
+	}
+	return msg.Header.Get("From") != "" && (msg.Header.Get("Subject") != "" || msg.Header.Get("Mime-Version") != "")
+}
+
+func utf16LE(s string) []byte {
+	var b []byte
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = append(b, byte(u), byte(u>>8))
+	}
+	return b
+}
+
+// plainText strips a UTF-8 byte order mark and invalid UTF-8.
+func plainText(data []byte) (string, error) {
+	return strings.ToValidUTF8(strings.TrimPrefix(string(data), "\ufeff"), ""), nil
+}
+
+// errPagesNoPreview is returned for Pages documents saved without a preview.
+var errPagesNoPreview = errors.New("pages document has no text preview; export it as PDF or DOCX")
+
+// pagesText reads an Apple Pages document through its bundled PDF preview
+// (or the index.xml of pre-2013 versions). Current Pages files store the
+// text in a proprietary format we don't parse.
+func pagesText(data []byte) (string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", err
+	}
+	for _, f := range zr.File {
+		if f.Name != "QuickLook/Preview.pdf" && f.Name != "index.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return "", err
+		}
+		defer rc.Close()
+		var text string
+		if f.Name == "index.xml" {
+			text, _, err = docconv.ConvertXML(rc)
+		} else {
+			text, _, err = docconv.ConvertPDF(rc)
+		}
+		return text, err
+	}
+	return "", errPagesNoPreview
+}
+
+// htmlBlockTags end a line of text.
+var htmlBlockTags = map[string]bool{
+	"p": true, "div": true, "br": true, "li": true, "tr": true, "h1": true, "h2": true, "h3": true,
+	"h4": true, "h5": true, "h6": true, "section": true, "article": true, "header": true, "footer": true,
+	"ul": true, "ol": true, "table": true, "dt": true, "dd": true, "blockquote": true, "pre": true, "hr": true,
+}
+
+// htmlSkipTags have no visible text.
+var htmlSkipTags = map[string]bool{"script": true, "style": true, "noscript": true, "head": true, "template": true, "svg": true}
+
+// htmlText renders an HTML document as text, one block element per line.
+func htmlText(data []byte) (string, error) {
+	var b strings.Builder
+	skip := 0
+	z := html.NewTokenizer(bytes.NewReader(data))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if err := z.Err(); err != io.EOF {
+				return "", err
+			}
+			return tidyLines(b.String()), nil
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, _ := z.TagName()
+			tag := string(name)
+			if htmlSkipTags[tag] {
+				skip++
+			}
+			if htmlBlockTags[tag] {
+				b.WriteString("\n")
+			}
+			if tag == "li" {
+				b.WriteString("- ")
+			}
+			if tag == "td" || tag == "th" {
+				b.WriteString(" ")
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			tag := string(name)
+			if htmlSkipTags[tag] && skip > 0 {
+				skip--
+			}
+			if htmlBlockTags[tag] {
+				b.WriteString("\n")
+			}
+		case html.TextToken:
+			if skip == 0 {
+				writeHTMLText(&b, string(z.Text()))
+			}
+		}
+	}
+}
+
+// writeHTMLText appends a text node with its whitespace collapsed, keeping
+// one space at either edge so "<b>Go</b> developer" doesn't run together.
+func writeHTMLText(b *strings.Builder, text string) {
+	collapsed := strings.Join(strings.Fields(text), " ")
+	if collapsed == "" {
+		if text != "" {
+			b.WriteString(" ")
+		}
+		return
+	}
+	if unicode.IsSpace(rune(text[0])) {
+		b.WriteString(" ")
+	}
+	b.WriteString(collapsed)
+	if unicode.IsSpace(rune(text[len(text)-1])) {
+		b.WriteString(" ")
+	}
+}
+
+var (
+	mdFenceRe    = regexp.MustCompile("(?m)^\\s*(```|~~~).*$")
+	mdHeadingRe  = regexp.MustCompile(`(?m)^\s{0,3}#{1,6}\s+`)
+	mdQuoteRe    = regexp.MustCompile(`(?m)^\s{0,3}>\s?`)
+	mdRuleRe     = regexp.MustCompile(`(?m)^\s*([-*_])(\s*([-*_])){2,}\s*$`)
+	mdImageRe    = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	mdLinkRe     = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)[^)]*\)`)
+	mdEmphasisRe = regexp.MustCompile(`(\*\*|__)(\S(?:.*?\S)?)(\*\*|__)`)
+	mdCodeRe     = regexp.MustCompile("`([^`]*)`")
+)
+
+// markdownText strips Markdown syntax. Headings are kept as their own lines
+// so section detection can find them.
+func markdownText(data []byte) (string, error) {
+	text, _ := plainText(data)
+	text = mdFenceRe.ReplaceAllString(text, "")
+	text = mdRuleRe.ReplaceAllString(text, "")
+	text = mdHeadingRe.ReplaceAllString(text, "")
+	text = mdQuoteRe.ReplaceAllString(text, "")
+	text = mdImageRe.ReplaceAllString(text, "$1")
+	text = mdLinkRe.ReplaceAllString(text, "$1 ($2)")
+	text = mdEmphasisRe.ReplaceAllString(text, "$2")
+	text = mdCodeRe.ReplaceAllString(text, "$1")
+	return tidyLines(text), nil
+}
+
+// tidyLines trims every line and collapses runs of blank lines.
+func tidyLines(text string) string {
+	var out []string
+	blank := false
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if !blank && len(out) > 0 {
+				out = append(out, "")
+			}
+			blank = true
+			continue
+		}
+		blank = false
+		out = append(out, line)
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
@@ -0,0 +1,107 @@
+package cv
+
+import (
+	"regexp"
+	"strings"
+)
+
+// LinkedIn's "Save to PDF" export always has the same layout: a sidebar
+// (Contact, Top Skills, Languages, Certifications, ...) followed by the name,
+// headline and location, then Summary, Experience and Education. pdftotext
+// emits the sidebar first, which the generic prompt tends to read as the
+// candidate's first job, so these exports get their own segmentation and
+// extraction template (llm.LinkedInExportTag).
+
+var (
+	// linkedInProfileRe is the profile URL line of the Contact block.
+	linkedInProfileRe = regexp.MustCompile(`(?im)^\s*(?:https?://)?(?:[a-z]{2,3}\.)?linkedin\.com/in/\S+\s*\(LinkedIn\)\s*$`)
+	// linkedInPageRe is the page footer ("Page 1 of 3", "Sayfa 1 / 3").
+	linkedInPageRe = regexp.MustCompile(`(?i)^\s*(?:page\s+\d+\s+of\s+\d+|sayfa\s+\d+\s*/\s*\d+)\s*$`)
+)
+
+// linkedInHeadings maps the export's section headings (normalized, see
+// normalizeHeading) to their kind, in both UI languages we see.
+var linkedInHeadings = map[string]SectionKind{
+	"contact": SectionHeader, "iletisim": SectionHeader,
+	"top skills": SectionSkills, "en onemli yetenekler": SectionSkills,
+	"languages": SectionLanguages, "diller": SectionLanguages,
+	"certifications": SectionCertifications, "sertifikalar": SectionCertifications,
+	"honors awards": SectionOther, "onurlar oduller": SectionOther,
+	"publications": SectionOther, "yayinlar": SectionOther,
+	"patents": SectionOther, "patentler": SectionOther,
+	"summary": SectionSummary, "ozet": SectionSummary,
+	"experience": SectionExperience, "deneyim": SectionExperience,
+	"education": SectionEducation, "egitim": SectionEducation,
+}
+
+// IsLinkedInExport reports whether text was extracted from a LinkedIn
+// profile PDF: the "(LinkedIn)" profile line plus the Contact and Experience
+// headings.
+func IsLinkedInExport(text string) bool {
+	if !linkedInProfileRe.MatchString(text) {
+		return false
+	}
+	var contact, experience bool
+	for _, line := range strings.Split(text, "\n") {
+		if len(line) > maxHeadingLen {
+			continue
+		}
+		switch linkedInHeadings[normalizeHeading(line)] {
+		case SectionHeader:
+			contact = true
+		case SectionExperience:
+			experience = true
+		}
+	}
+	return contact && experience
+}
+
+// LinkedInSections splits a LinkedIn export at its fixed headings, dropping
+// page footers. Only whole-line headings count, so a summary sentence like
+// "Experience: 10 years" stays in its section. The name, headline and
+// location end up at the end of the last sidebar section; the LinkedIn
+// extraction template knows to look for them there.
+func LinkedInSections(text string) []Section {
+	var sections []Section
+	var current *Section
+	var body []string
+	flush := func() {
+		if current != nil {
+			current.Text = strings.TrimSpace(strings.Join(body, "\n"))
+			sections = append(sections, *current)
+		}
+		body = body[:0]
+	}
+	for _, line := range strings.Split(text, "\n") {
+		if linkedInPageRe.MatchString(line) {
+			continue
+		}
+		if len(line) <= maxHeadingLen {
+			if kind, ok := linkedInHeadings[normalizeHeading(line)]; ok {
+				flush()
+				current = &Section{Kind: kind, Heading: strings.TrimSpace(line)}
+				continue
+			}
+		}
+		if current == nil {
+			current = &Section{Kind: SectionHeader}
+		}
+		body = append(body, line)
+	}
+	flush()
+
+	if len(sections) < 2 {
+		return nil
+	}
+	return sections
+}
+
+// isLinkedInLayout reports whether sections came from LinkedInSections.
+func isLinkedInLayout(sections []Section) bool {
+	for _, s := range sections {
+		if s.Kind == SectionHeader && linkedInProfileRe.MatchString(s.Text) {
+			return linkedInHeadings[normalizeHeading(s.Heading)] == SectionHeader
+		}
+	}
+	return false
+}
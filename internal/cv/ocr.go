@@ -0,0 +1,156 @@
+package cv
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// OCR recognizes the text of a scanned document. path is a local file
+// (PDF or image); languages are Tesseract codes such as "eng" or "tur".
+type OCR interface {
+	Recognize(ctx context.Context, path string, languages []string) (string, error)
+}
+
+// OCRConfig selects and configures the OCR fallback.
+type OCRConfig struct {
+	Backend    string   // "" / "none", "tesseract" or "http"
+	Languages  []string // default: eng, tur
+	ServiceURL string   // http backend: multipart "file" POST, returns text/plain or {"text": "..."}
+}
+
+// NewOCR builds the configured OCR backend, or nil when OCR is disabled.
+func NewOCR(cfg OCRConfig) (OCR, error) {
+	switch strings.ToLower(cfg.Backend) {
+	case "", "none":
+		return nil, nil
+	case "tesseract":
+		if _, err := exec.LookPath("tesseract"); err != nil {
+			return nil, fmt.Errorf("ocr: tesseract not found in PATH: %w", err)
+		}
+		return &TesseractOCR{}, nil
+	case "http":
+		if cfg.ServiceURL == "" {
+			return nil, fmt.Errorf("ocr: OCR_SERVICE_URL is required for the http backend")
+		}
+		return &HTTPOCR{URL: cfg.ServiceURL, Client: &http.Client{}}, nil
+	}
+	return nil, fmt.Errorf("ocr: unknown backend %q (use tesseract or http)", cfg.Backend)
+}
+
+// TesseractOCR runs the tesseract CLI. PDFs are rasterized first with
+// pdftoppm (poppler-utils, already needed by docconv for pdftotext).
+type TesseractOCR struct{}
+
+// ocrDPI is the resolution scanned PDF pages are rendered at; below ~300
+// Tesseract's accuracy drops noticeably.
+const ocrDPI = "300"
+
+func (TesseractOCR) Recognize(ctx context.Context, path string, languages []string) (string, error) {
+	images := []string{path}
+	if strings.ToLower(filepath.Ext(path)) == ".pdf" {
+		dir, err := os.MkdirTemp("", "cv-ocr-*")
+		if err != nil {
+			return "", fmt.Errorf("ocr temp dir: %w", err)
+		}
+		defer os.RemoveAll(dir)
+
+		if out, err := exec.CommandContext(ctx, "pdftoppm", "-r", ocrDPI, "-png", path, filepath.Join(dir, "page")).CombinedOutput(); err != nil {
+			return "", fmt.Errorf("pdftoppm: %w: %s", err, strings.TrimSpace(string(out)))
+		}
+		if images, err = filepath.Glob(filepath.Join(dir, "page*.png")); err != nil {
+			return "", fmt.Errorf("ocr pages: %w", err)
+		}
+		if len(images) == 0 {
+			return "", fmt.Errorf("pdftoppm produced no pages")
+		}
+		// page-1.png, page-2.png, ... (zero-padded by pdftoppm for 10+ pages)
+		sort.Strings(images)
+	}
+
+	var text strings.Builder
+	for _, img := range images {
+		args := []string{img, "stdout"}
+		if len(languages) > 0 {
+			args = append(args, "-l", strings.Join(languages, "+"))
+		}
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, "tesseract", args...)
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("tesseract: %w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		text.Write(out)
+		text.WriteString("\n")
+	}
+	return text.String(), nil
+}
+
+// HTTPOCR posts the file to an external OCR service.
+type HTTPOCR struct {
+	URL    string
+	Client *http.Client
+}
+
+func (o *HTTPOCR) Recognize(ctx context.Context, path string, languages []string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("ocr open: %w", err)
+	}
+	defer f.Close()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("file", filepath.Base(path))
+	if err != nil {
+		return "", fmt.Errorf("ocr request: %w", err)
+	}
+	if _, err := io.Copy(part, f); err != nil {
+		return "", fmt.Errorf("ocr request: %w", err)
+	}
+	if len(languages) > 0 {
+		mw.WriteField("languages", strings.Join(languages, ","))
+	}
+	if err := mw.Close(); err != nil {
+		return "", fmt.Errorf("ocr request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.URL, &body)
+	if err != nil {
+		return "", fmt.Errorf("ocr request: %w", err)
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	resp, err := o.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("ocr service: %w", err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("ocr service: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("ocr service: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(raw)))
+	}
+
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		var out struct {
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(raw, &out); err != nil {
+			return "", fmt.Errorf("ocr service: decode response: %w", err)
+		}
+		return out.Text, nil
+	}
+	return string(raw), nil
+}
@@ -1,91 +1,234 @@
 package cv
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"log"
 	"os"
-	"path/filepath"
 	"strings"
+	"time"
 
-	"code.sajari.com/docconv"
+	"cv-search/internal/textnorm"
 )
 
 type CVParser struct {
-	uploadsDir string
+	tempDir string            // scratch space for text extraction; "" = os.TempDir()
+	parsers map[string]Parser // by format, see DetectFormat
+
+	// OCR fallback for scanned PDFs (nil = off), used when the extracted
+	// text is shorter than ocrMinChars.
+	ocr          OCR
+	ocrLanguages []string
+	ocrMinChars  int
+	ocrTimeout   time.Duration
+
+	// Malware scan run on every file before parsing (nil = off).
+	scanner     Scanner
+	scanTimeout time.Duration
 }
 
 type ParsedCV struct {
-	Filename    string
-	FileType    string
-	FileSize    int64
-	FullText    string
-	Entities    []Entity
-	Skills      []string
-	Companies   []string
-	Education   []string
+	Filename     string
+	FileType     string
+	FileSize     int64
+	FullText     string
+	Entities     []Entity
+	Skills       []string
+	Companies    []string
+	Education    []string
 	Certificates []string
+	OCRUsed      bool      // FullText came from the OCR fallback
+	Sections     []Section // heading-based segmentation of FullText; nil if not recognized
+	Anonymized   bool      // PII in FullText and Sections has been masked (see Anonymize)
+
+	// DOCX only: fields from the page header / document properties and the
+	// embedded photo, if any (see DocxHeader, DocxPhoto).
+	Header *DocumentHeader
+	Photo  *Photo
 }
 
 type Entity struct {
-	Type       string  // skill, company, education, certification
+	Type       string // skill, company, education, certification
 	Value      string
 	Confidence float64
 }
 
-func NewCVParser(uploadsDir string) *CVParser {
+// NewCVParser returns a parser whose OCR fallback spools files to tempDir
+// ("" = the OS temp dir). The uploaded file itself is persisted by the caller
+// through a storage.BlobStore, not here.
+func NewCVParser(tempDir string) *CVParser {
 	return &CVParser{
-		uploadsDir: uploadsDir,
+		tempDir: tempDir,
+		parsers: defaultParsers(),
 	}
 }
 
-// ParseFile extracts text from PDF/DOCX/TXT files
+// EnableOCR turns on the OCR fallback: PDFs whose extracted text is shorter
+// than minChars (after trimming) are run through ocr instead.
+func (p *CVParser) EnableOCR(ocr OCR, languages []string, minChars int, timeout time.Duration) {
+	p.ocr = ocr
+	p.ocrLanguages = languages
+	p.ocrMinChars = minChars
+	p.ocrTimeout = timeout
+}
+
+// EnableScanner scans every file with scanner before it is parsed. Infected
+// files, and files the scan fails on, are not parsed.
+func (p *CVParser) EnableScanner(scanner Scanner, timeout time.Duration) {
+	p.scanner = scanner
+	p.scanTimeout = timeout
+}
+
+// ParseFile is kept for backward compatibility and calls ParseReader.
 func (p *CVParser) ParseFile(filename string, reader io.Reader) (*ParsedCV, error) {
-	// Save file temporarily
-	filePath := filepath.Join(p.uploadsDir, filename)
-	if err := os.MkdirAll(p.uploadsDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create uploads dir: %w", err)
-	}
+	return p.ParseReader(filename, reader)
+}
 
-	file, err := os.Create(filePath)
+// ParseReader extracts text from a CV without keeping anything on disk. The
+// format is sniffed from the content (DetectFormat), the extension only
+// deciding between text formats, and parsed by the registered Parser for it;
+// .eml/.msg emails are parsed through their attached CV. Most formats are
+// parsed in memory; PDF, DOC and RTF go through poppler / wv / unrtf, which
+// docconv feeds from a temp file it removes when done, as does the OCR
+// fallback. Files are first screened (CheckContent, then the Scanner if
+// enabled); a refused file is an ErrRejectedFile. Storing the original file
+// is a separate step (storage.BlobStore), so parsing alone never persists an
+// upload.
+func (p *CVParser) ParseReader(filename string, reader io.Reader) (*ParsedCV, error) {
+	data, err := io.ReadAll(reader)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create file: %w", err)
+		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
-	defer file.Close()
 
-	size, err := io.Copy(file, reader)
+	text, fileType, ocrUsed, err := p.extract(filename, data, 0)
 	if err != nil {
-		return nil, fmt.Errorf("failed to save file: %w", err)
+		return nil, err
+	}
+	text = textnorm.Repair(text)
+
+	parsed := &ParsedCV{
+		Filename: filename,
+		FileType: fileType,
+		FileSize: int64(len(data)),
+		FullText: text,
+		OCRUsed:  ocrUsed,
+		Sections: DetectSections(text),
+	}
+	if fileType == ".docx" {
+		parsed.Header = DocxHeader(data, text)
+		parsed.Photo = DocxPhoto(data)
 	}
+	return parsed, nil
+}
 
-	// Extract text based on file type
-	fileType := strings.ToLower(filepath.Ext(filename))
-	var text string
+// RegisterParser sets the parser for a format (a lowercase extension such as
+// ".pdf"), replacing the built-in one.
+func (p *CVParser) RegisterParser(format string, parser Parser) {
+	p.parsers[format] = parser
+}
 
-	switch fileType {
-	case ".pdf", ".docx", ".doc", ".rtf", ".odt":
-		// Use docconv for PDF/DOCX parsing
-		res, err := docconv.ConvertPath(filePath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse document: %w", err)
+// extract returns the text of one document and its detected format. depth
+// counts the emails it is nested in.
+func (p *CVParser) extract(filename string, data []byte, depth int) (text, format string, ocrUsed bool, err error) {
+	format = DetectFormat(filename, data)
+	if err := CheckContent(filename, data); err != nil {
+		return "", format, false, err
+	}
+	if depth == 0 {
+		if err := p.scan(filename, data); err != nil {
+			return "", format, false, err
 		}
-		text = res.Body
-	case ".txt":
-		// Plain text
-		content, err := os.ReadFile(filePath)
+	}
+	if format == ".eml" || format == ".msg" {
+		text, ocrUsed, err = p.parseEmail(format, data, depth)
+		return text, format, ocrUsed, err
+	}
+
+	parser, ok := p.parsers[format]
+	if !ok {
+		return "", format, false, fmt.Errorf("unsupported file type: %s", format)
+	}
+	text, err = parser.Parse(data)
+	if format != ".pdf" {
 		if err != nil {
-			return nil, fmt.Errorf("failed to read text file: %w", err)
+			return "", format, false, fmt.Errorf("failed to parse document: %w", err)
 		}
-		text = string(content)
-	default:
-		return nil, fmt.Errorf("unsupported file type: %s", fileType)
+		return text, format, false, nil
 	}
 
-	return &ParsedCV{
-		Filename: filename,
-		FileType: fileType,
-		FileSize: size,
-		FullText: text,
-	}, nil
+	if err != nil && p.ocr == nil {
+		return "", format, false, fmt.Errorf("failed to parse document: %w", err)
+	}
+	// Scanned PDFs are images with no text layer.
+	if p.ocr != nil && len(strings.TrimSpace(text)) < p.ocrMinChars {
+		ocrText, ocrErr := p.recognize(data, format)
+		switch {
+		case ocrErr != nil && err != nil:
+			return "", format, false, fmt.Errorf("failed to parse document: %w (ocr: %v)", err, ocrErr)
+		case ocrErr != nil:
+			log.Printf("[CVParser] OCR fallback for %s failed: %v", filename, ocrErr)
+		case len(strings.TrimSpace(ocrText)) > len(strings.TrimSpace(text)):
+			text = ocrText
+			ocrUsed = true
+			log.Printf("[CVParser] %s: used OCR text (%d chars)", filename, len(text))
+		}
+	}
+	return text, format, ocrUsed, nil
+}
+
+// scan runs the malware scanner, if any, on a whole upload (emails with
+// their attachments).
+func (p *CVParser) scan(filename string, data []byte) error {
+	if p.scanner == nil {
+		return nil
+	}
+	ctx := context.Background()
+	if p.scanTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.scanTimeout)
+		defer cancel()
+	}
+	threat, err := p.scanner.Scan(ctx, filename, data)
+	if err != nil {
+		return fmt.Errorf("virus scan failed: %w", err)
+	}
+	if threat != "" {
+		log.Printf("[CVParser] %s rejected: %s", filename, threat)
+		return fmt.Errorf("%w: %s is infected (%s)", ErrRejectedFile, filename, threat)
+	}
+	return nil
+}
+
+// recognize runs the OCR fallback on data, which OCR backends read from a
+// temp file in tempDir (removed afterwards).
+func (p *CVParser) recognize(data []byte, fileType string) (string, error) {
+	if p.tempDir != "" {
+		if err := os.MkdirAll(p.tempDir, 0755); err != nil {
+			return "", fmt.Errorf("failed to create temp dir: %w", err)
+		}
+	}
+	file, err := os.CreateTemp(p.tempDir, "cv-*"+fileType)
+	if err != nil {
+		return "", fmt.Errorf("failed to create file: %w", err)
+	}
+	path := file.Name()
+	defer os.Remove(path)
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to write temp file: %w", err)
+	}
+
+	ctx := context.Background()
+	if p.ocrTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.ocrTimeout)
+		defer cancel()
+	}
+	return p.ocr.Recognize(ctx, path, p.ocrLanguages)
 }
 
 // ExtractBasicEntities performs basic entity extraction without LLM
@@ -0,0 +1,152 @@
+package cv
+
+import (
+	"math"
+	"strings"
+	"unicode"
+
+	"cv-search/internal/llm"
+)
+
+// Parse quality statuses (cv_files.quality_status).
+const (
+	QualityPending     = "pending"      // not scored yet (extraction hasn't run)
+	QualityOK          = "ok"           // looks like a usable parse
+	QualityNeedsReview = "needs_review" // a person should check the parse/extraction
+)
+
+// Quality issues found by ScoreQuality.
+const (
+	IssueShortText      = "short_text"      // too little text for a CV
+	IssueGibberish      = "gibberish"       // mostly non-words (bad encoding, OCR noise)
+	IssueMissingName    = "missing_name"    // extraction found no candidate name
+	IssueMissingContact = "missing_contact" // no email, phone or LinkedIn URL
+	IssueNoSkills       = "no_skills"       // extraction found no skills
+)
+
+// qualityPenalties is what each issue takes off the score of 1.
+var qualityPenalties = map[string]float64{
+	IssueShortText:      0.4,
+	IssueGibberish:      0.5,
+	IssueMissingName:    0.3,
+	IssueMissingContact: 0.15,
+	IssueNoSkills:       0.3,
+}
+
+const (
+	// minCVTextChars is the shortest text a real CV has.
+	minCVTextChars = 300
+	// maxGibberishRatio is the share of non-word tokens above which the text
+	// is considered garbled.
+	maxGibberishRatio = 0.3
+	// reviewBelowScore flags parses scoring under it for review.
+	reviewBelowScore = 0.6
+)
+
+// Quality is the result of scoring one parse.
+type Quality struct {
+	Status string   // QualityOK or QualityNeedsReview
+	Score  float64  // 0..1
+	Issues []string // Issue* constants, nil if none
+}
+
+// ScoreQuality rates a CV's parsed text together with its extraction, so bad
+// parses are flagged for review instead of silently feeding the graph.
+// anonymized CVs have their contact details masked and aren't penalized for
+// missing them. Short or garbled text always needs review.
+func ScoreQuality(text string, e *llm.CVExtraction, anonymized bool) Quality {
+	var q Quality
+	trimmed := strings.TrimSpace(text)
+	if len([]rune(trimmed)) < minCVTextChars {
+		q.Issues = append(q.Issues, IssueShortText)
+	}
+	if GibberishRatio(trimmed) > maxGibberishRatio {
+		q.Issues = append(q.Issues, IssueGibberish)
+	}
+	if e == nil || strings.TrimSpace(e.Candidate.Name) == "" {
+		q.Issues = append(q.Issues, IssueMissingName)
+	}
+	if !anonymized && (e == nil || e.Candidate.Email == "" && e.Candidate.Phone == "" && e.Candidate.LinkedInURL == "") {
+		q.Issues = append(q.Issues, IssueMissingContact)
+	}
+	if e == nil || len(e.Skills) == 0 {
+		q.Issues = append(q.Issues, IssueNoSkills)
+	}
+
+	q.Score = 1
+	hard := false
+	for _, issue := range q.Issues {
+		q.Score -= qualityPenalties[issue]
+		hard = hard || issue == IssueShortText || issue == IssueGibberish
+	}
+	q.Score = math.Round(math.Max(q.Score, 0)*100) / 100
+
+	q.Status = QualityOK
+	if hard || q.Score < reviewBelowScore {
+		q.Status = QualityNeedsReview
+	}
+	return q
+}
+
+// GibberishRatio returns the share of whitespace-separated tokens that don't
+// look like words, numbers, dates, emails or URLs: mostly symbols, Unicode
+// replacement characters, or long letter runs without a vowel. Empty text
+// scores 1.
+func GibberishRatio(text string) float64 {
+	tokens := strings.Fields(text)
+	if len(tokens) == 0 {
+		return 1
+	}
+	bad := 0
+	for _, t := range tokens {
+		if isGibberishToken(t) {
+			bad++
+		}
+	}
+	return float64(bad) / float64(len(tokens))
+}
+
+func isGibberishToken(t string) bool {
+	t = strings.TrimFunc(t, unicode.IsPunct)
+	if t == "" {
+		return false // bullets, dashes, lone punctuation
+	}
+	if strings.ContainsRune(t, unicode.ReplacementChar) {
+		return true
+	}
+	var letters, digits, other int
+	vowel := false
+	for _, r := range t {
+		switch {
+		case unicode.IsLetter(r):
+			letters++
+			vowel = vowel || isVowel(r)
+		case unicode.IsDigit(r):
+			digits++
+		case strings.ContainsRune("@./:-_+#&'%()", r):
+		default:
+			other++
+		}
+	}
+	n := letters + digits + other
+	if other*2 > n {
+		return true
+	}
+	// Acronyms (AWS, SQL, HTML5) are short; a long run of consonants isn't a word.
+	return letters >= 6 && !vowel && digits == 0
+}
+
+// isVowel reports Latin vowels, including Turkish and accented ones, and
+// treats non-Latin letters as vowels (the check only makes sense for Latin
+// script).
+func isVowel(r rune) bool {
+	if r > unicode.MaxLatin1 && !unicode.In(r, unicode.Latin) {
+		return true
+	}
+	switch unicode.ToLower(r) {
+	case 'a', 'e', 'i', 'o', 'u', 'y', 'ı', 'ö', 'ü', 'â', 'î', 'û',
+		'á', 'à', 'ä', 'é', 'è', 'ê', 'ë', 'í', 'ì', 'ï', 'ó', 'ò', 'ô', 'ú', 'ù':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,250 @@
+package cv
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Uploads come from untrusted candidates, so before a file is parsed its
+// content is checked against its claimed format (CheckContent) and, when a
+// Scanner is configured, scanned for malware. Either failure is an
+// ErrRejectedFile; handlers report it as the upload's fault, not ours.
+
+// ErrRejectedFile is wrapped by every error for a file refused on content
+// (disguised executable, format mismatch, malware).
+var ErrRejectedFile = errors.New("file rejected")
+
+// executableMagic are the headers of native executables and scripts. None of
+// them starts a supported document format.
+var executableMagic = []struct {
+	magic []byte
+	kind  string
+}{
+	{[]byte("MZ"), "a Windows executable"},
+	{[]byte("\x7fELF"), "a Linux executable"},
+	{[]byte{0xFE, 0xED, 0xFA, 0xCE}, "a macOS executable"},
+	{[]byte{0xFE, 0xED, 0xFA, 0xCF}, "a macOS executable"},
+	{[]byte{0xCE, 0xFA, 0xED, 0xFE}, "a macOS executable"},
+	{[]byte{0xCF, 0xFA, 0xED, 0xFE}, "a macOS executable"},
+	{[]byte{0xCA, 0xFE, 0xBA, 0xBE}, "a macOS or Java executable"},
+	{[]byte("#!"), "a script"},
+}
+
+// formatMagic is the header each binary format must start with.
+var formatMagic = map[string][]byte{
+	".pdf":   []byte("%PDF-"),
+	".rtf":   []byte(`{\rtf`),
+	".docx":  zipMagic,
+	".odt":   zipMagic,
+	".pages": zipMagic,
+	".doc":   oleMagic,
+	".msg":   oleMagic,
+}
+
+// Word documents are OLE containers with a WordDocument stream; other OLE
+// files (.msi installers, .xls) don't have one.
+var wordStreamName = utf16LE("WordDocument")
+
+// CheckContent rejects data that isn't the document it claims to be:
+// executables and scripts whatever their name, binary formats without their
+// header, and binary content in a text-format file.
+func CheckContent(filename string, data []byte) error {
+	for _, e := range executableMagic {
+		if bytes.HasPrefix(data, e.magic) {
+			return fmt.Errorf("%w: %s is %s, not a CV document", ErrRejectedFile, filename, e.kind)
+		}
+	}
+
+	format := DetectFormat(filename, data)
+	if magic, ok := formatMagic[format]; ok {
+		if !bytes.HasPrefix(data, magic) {
+			return fmt.Errorf("%w: %s is not a valid %s file", ErrRejectedFile, filename, strings.TrimPrefix(format, "."))
+		}
+		if format == ".doc" && !bytes.Contains(data, wordStreamName) {
+			return fmt.Errorf("%w: %s is not a Word document", ErrRejectedFile, filename)
+		}
+		return nil
+	}
+	if _, ok := defaultParsers()[format]; ok || format == ".eml" {
+		if !strings.HasPrefix(http.DetectContentType(data), "text/") {
+			return fmt.Errorf("%w: %s has binary content, not %s text", ErrRejectedFile, filename, strings.TrimPrefix(format, "."))
+		}
+	}
+	return nil
+}
+
+// Scanner checks a file for malware. threat names what was found and is
+// empty for a clean file; err means the scan itself failed.
+type Scanner interface {
+	Scan(ctx context.Context, filename string, data []byte) (threat string, err error)
+}
+
+// ScannerConfig selects and configures the malware scanner.
+type ScannerConfig struct {
+	Backend    string // "" / "none", "clamav" or "http"
+	Address    string // clamav: clamd socket, "tcp://host:3310" or "unix:///path"
+	ServiceURL string // http backend: multipart "file" POST, returns {"infected": bool, "threat": "..."}
+}
+
+// NewScanner builds the configured scanner, or nil when scanning is disabled.
+func NewScanner(cfg ScannerConfig) (Scanner, error) {
+	switch strings.ToLower(cfg.Backend) {
+	case "", "none":
+		return nil, nil
+	case "clamav":
+		network, address, err := clamdAddress(cfg.Address)
+		if err != nil {
+			return nil, err
+		}
+		return &ClamAVScanner{Network: network, Address: address}, nil
+	case "http":
+		if cfg.ServiceURL == "" {
+			return nil, fmt.Errorf("scanner: SCAN_SERVICE_URL is required for the http backend")
+		}
+		return &HTTPScanner{URL: cfg.ServiceURL, Client: &http.Client{}}, nil
+	}
+	return nil, fmt.Errorf("scanner: unknown backend %q (use clamav or http)", cfg.Backend)
+}
+
+// clamdAddress splits a CLAMAV_ADDRESS into a dial network and address;
+// a bare host:port is TCP.
+func clamdAddress(addr string) (string, string, error) {
+	if addr == "" {
+		return "tcp", "localhost:3310", nil
+	}
+	if !strings.Contains(addr, "://") {
+		return "tcp", addr, nil
+	}
+	u, err := url.Parse(addr)
+	if err != nil {
+		return "", "", fmt.Errorf("scanner: invalid clamd address %q: %w", addr, err)
+	}
+	switch u.Scheme {
+	case "tcp":
+		return "tcp", u.Host, nil
+	case "unix":
+		return "unix", u.Path, nil
+	}
+	return "", "", fmt.Errorf("scanner: clamd address %q must be tcp:// or unix://", addr)
+}
+
+// ClamAVScanner streams files to clamd with the INSTREAM command.
+type ClamAVScanner struct {
+	Network string
+	Address string
+}
+
+// clamdChunkSize is the INSTREAM chunk size; clamd's own limit is
+// StreamMaxLength for the whole stream, not per chunk.
+const clamdChunkSize = 64 << 10
+
+func (s *ClamAVScanner) Scan(ctx context.Context, filename string, data []byte) (string, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, s.Network, s.Address)
+	if err != nil {
+		return "", fmt.Errorf("clamd: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else {
+		conn.SetDeadline(time.Now().Add(time.Minute))
+	}
+
+	w := bufio.NewWriter(conn)
+	w.WriteString("zINSTREAM\x00")
+	var size [4]byte
+	for len(data) > 0 {
+		n := min(len(data), clamdChunkSize)
+		binary.BigEndian.PutUint32(size[:], uint32(n))
+		w.Write(size[:])
+		w.Write(data[:n])
+		data = data[n:]
+	}
+	binary.BigEndian.PutUint32(size[:], 0)
+	w.Write(size[:])
+	if err := w.Flush(); err != nil {
+		return "", fmt.Errorf("clamd: %w", err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && reply == "" {
+		return "", fmt.Errorf("clamd: %w", err)
+	}
+	// "stream: OK", "stream: Eicar-Signature FOUND" or "<reason> ERROR"
+	reply = strings.TrimSpace(strings.TrimSuffix(reply, "\x00"))
+	reply = strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+	switch {
+	case reply == "OK":
+		return "", nil
+	case strings.HasSuffix(reply, " FOUND"):
+		return strings.TrimSuffix(reply, " FOUND"), nil
+	}
+	return "", fmt.Errorf("clamd: %s", reply)
+}
+
+// HTTPScanner posts the file to an external scanning service.
+type HTTPScanner struct {
+	URL    string
+	Client *http.Client
+}
+
+func (s *HTTPScanner) Scan(ctx context.Context, filename string, data []byte) (string, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("file", filename)
+	if err != nil {
+		return "", fmt.Errorf("scan request: %w", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		return "", fmt.Errorf("scan request: %w", err)
+	}
+	if err := mw.Close(); err != nil {
+		return "", fmt.Errorf("scan request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, &body)
+	if err != nil {
+		return "", fmt.Errorf("scan request: %w", err)
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("scan service: %w", err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("scan service: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("scan service: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(raw)))
+	}
+
+	var out struct {
+		Infected bool   `json:"infected"`
+		Threat   string `json:"threat"`
+	}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return "", fmt.Errorf("scan service: decode response: %w", err)
+	}
+	if !out.Infected {
+		return "", nil
+	}
+	if out.Threat == "" {
+		out.Threat = "unknown threat"
+	}
+	return out.Threat, nil
+}
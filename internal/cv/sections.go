@@ -0,0 +1,302 @@
+package cv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"cv-search/internal/llm"
+)
+
+// SectionKind is the kind of a CV section.
+type SectionKind string
+
+const (
+	SectionHeader         SectionKind = "header" // text before the first heading (name, contact)
+	SectionSummary        SectionKind = "summary"
+	SectionExperience     SectionKind = "experience"
+	SectionEducation      SectionKind = "education"
+	SectionSkills         SectionKind = "skills"
+	SectionCertifications SectionKind = "certifications"
+	SectionLanguages      SectionKind = "languages"
+	SectionProjects       SectionKind = "projects"
+	SectionOther          SectionKind = "other" // references, hobbies, awards, ...
+)
+
+// Section is one segment of a CV's text. Heading is the heading line as
+// written in the CV ("" for the header).
+type Section struct {
+	Kind    SectionKind `json:"kind"`
+	Heading string      `json:"heading,omitempty"`
+	Text    string      `json:"text"`
+}
+
+// sectionHeadings maps normalized heading lines (see normalizeHeading) to
+// their kind. English and Turkish, since most of our CVs are one or the other.
+var sectionHeadings = map[string]SectionKind{}
+
+func init() {
+	for kind, headings := range map[SectionKind][]string{
+		SectionSummary: {
+			"summary", "professional summary", "career summary", "executive summary", "profile",
+			"professional profile", "personal profile", "about", "about me", "objective", "career objective",
+			"personal statement", "ozet", "profesyonel ozet", "hakkimda", "kariyer hedefi", "profil",
+		},
+		SectionExperience: {
+			"experience", "work experience", "professional experience", "relevant experience",
+			"employment", "employment history", "work history", "career history", "career",
+			"deneyim", "deneyimler", "is deneyimi", "is deneyimleri", "mesleki deneyim", "tecrube",
+			"is tecrubesi", "is tecrubeleri", "calisma gecmisi", "kariyer gecmisi",
+		},
+		SectionEducation: {
+			"education", "academic background", "education and training", "academic qualifications",
+			"qualifications", "egitim", "egitim bilgileri", "egitim durumu", "ogrenim", "ogrenim durumu",
+		},
+		SectionSkills: {
+			"skills", "technical skills", "core skills", "key skills", "skills and expertise", "competencies",
+			"core competencies", "technologies", "tech stack", "tools and technologies", "expertise",
+			"yetenekler", "beceriler", "yetkinlikler", "teknik beceriler", "teknik yetenekler",
+			"teknik bilgiler", "teknik yetkinlikler", "bilgisayar becerileri",
+		},
+		SectionCertifications: {
+			"certifications", "certificates", "certification", "licenses and certifications",
+			"courses", "courses and certifications", "training", "trainings",
+			"sertifikalar", "sertifikalar ve kurslar", "kurslar", "kurslar ve sertifikalar",
+			"egitimler ve sertifikalar", "belgeler",
+		},
+		SectionLanguages: {
+			"languages", "language skills", "language", "diller", "yabanci diller", "yabanci dil", "dil bilgisi",
+		},
+		SectionProjects: {
+			"projects", "personal projects", "selected projects", "side projects", "key projects",
+			"projeler", "kisisel projeler", "projelerim",
+		},
+		SectionOther: {
+			"references", "referanslar", "hobbies", "interests", "hobbies and interests", "hobiler",
+			"ilgi alanlari", "awards", "honors and awards", "oduller", "publications", "yayinlar",
+			"volunteering", "volunteer experience", "gonullu calismalar", "personal information",
+			"personal details", "kisisel bilgiler", "contact", "iletisim",
+		},
+	} {
+		for _, h := range headings {
+			sectionHeadings[h] = kind
+		}
+	}
+}
+
+// maxHeadingLen bounds how long a line can be and still count as a heading.
+const maxHeadingLen = 48
+
+// normalizeHeading lowercases a line, folds Turkish letters to ASCII and
+// drops decoration ("## WORK EXPERIENCE:" -> "work experience").
+func normalizeHeading(line string) string {
+	line = strings.ToLower(line)
+	line = strings.NewReplacer("i̇", "i", "ı", "i", "ğ", "g", "ş", "s", "ç", "c", "ö", "o", "ü", "u", "&", " and ").Replace(line)
+	fields := strings.FieldsFunc(line, func(r rune) bool { return !unicode.IsLetter(r) })
+	return strings.Join(fields, " ")
+}
+
+// headingKind reports whether line is a section heading. A heading may carry
+// content after a colon ("Skills: Go, Kubernetes"), which is returned as rest.
+func headingKind(line string) (kind SectionKind, heading, rest string, ok bool) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return "", "", "", false
+	}
+	if len(line) <= maxHeadingLen {
+		if kind, ok := sectionHeadings[normalizeHeading(line)]; ok {
+			return kind, line, "", true
+		}
+	}
+	if i := strings.IndexAny(line, ":："); i > 0 && i <= maxHeadingLen {
+		if kind, ok := sectionHeadings[normalizeHeading(line[:i])]; ok {
+			_, size := utf8.DecodeRuneInString(line[i:])
+			return kind, strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+size:]), true
+		}
+	}
+	return "", "", "", false
+}
+
+// DetectSections splits CV text at recognizable headings. It returns nil
+// when fewer than two sections are found, since the layout then isn't one
+// the heuristics understand (SectionDetector falls back to the LLM).
+// LinkedIn exports are split by LinkedInSections.
+func DetectSections(text string) []Section {
+	if IsLinkedInExport(text) {
+		if sections := LinkedInSections(text); sections != nil {
+			return sections
+		}
+	}
+
+	var sections []Section
+	current := Section{Kind: SectionHeader}
+	var body []string
+	found := 0
+
+	flush := func() {
+		current.Text = strings.TrimSpace(strings.Join(body, "\n"))
+		if current.Text != "" || current.Heading != "" {
+			sections = append(sections, current)
+		}
+	}
+	for _, line := range strings.Split(text, "\n") {
+		kind, heading, rest, ok := headingKind(line)
+		if !ok {
+			body = append(body, line)
+			continue
+		}
+		flush()
+		found++
+		current = Section{Kind: kind, Heading: heading}
+		body = body[:0]
+		if rest != "" {
+			body = append(body, rest)
+		}
+	}
+	flush()
+
+	if found < 2 {
+		return nil
+	}
+	return sections
+}
+
+// splitAtHeadings cuts text at the given heading lines, in order. Headings
+// that can't be found (after the previous one) are skipped.
+func splitAtHeadings(text string, headings []llm.SectionHeading) []Section {
+	lines := strings.Split(text, "\n")
+	type cut struct {
+		line int
+		kind SectionKind
+		head string
+	}
+	var cuts []cut
+	next := 0
+	for _, h := range headings {
+		want := normalizeHeading(h.Heading)
+		if want == "" {
+			continue
+		}
+		for i := next; i < len(lines); i++ {
+			if normalizeHeading(lines[i]) == want {
+				cuts = append(cuts, cut{line: i, kind: sectionKindOf(h.Kind), head: strings.TrimSpace(lines[i])})
+				next = i + 1
+				break
+			}
+		}
+	}
+	if len(cuts) < 2 {
+		return nil
+	}
+
+	var sections []Section
+	if header := strings.TrimSpace(strings.Join(lines[:cuts[0].line], "\n")); header != "" {
+		sections = append(sections, Section{Kind: SectionHeader, Text: header})
+	}
+	for i, c := range cuts {
+		end := len(lines)
+		if i+1 < len(cuts) {
+			end = cuts[i+1].line
+		}
+		sections = append(sections, Section{
+			Kind:    c.kind,
+			Heading: c.head,
+			Text:    strings.TrimSpace(strings.Join(lines[c.line+1:end], "\n")),
+		})
+	}
+	return sections
+}
+
+func sectionKindOf(s string) SectionKind {
+	switch k := SectionKind(strings.ToLower(strings.TrimSpace(s))); k {
+	case SectionSummary, SectionExperience, SectionEducation, SectionSkills,
+		SectionCertifications, SectionLanguages, SectionProjects:
+		return k
+	}
+	return SectionOther
+}
+
+// FormatSections renders sections as the "### KIND (heading)" blocks the
+// extraction prompt knows how to use. LinkedIn exports start with
+// llm.LinkedInExportTag so they get the LinkedIn template.
+func FormatSections(sections []Section) string {
+	var b strings.Builder
+	if isLinkedInLayout(sections) {
+		b.WriteString(llm.LinkedInExportTag)
+	}
+	for _, s := range sections {
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString("### " + strings.ToUpper(string(s.Kind)))
+		if s.Heading != "" {
+			b.WriteString(" (" + s.Heading + ")")
+		}
+		b.WriteString("\n")
+		b.WriteString(s.Text)
+	}
+	return b.String()
+}
+
+// SectionedText is FormatSections(DetectSections(text)), or text itself when
+// no sections are recognized.
+func SectionedText(text string) string {
+	if sections := DetectSections(text); sections != nil {
+		return FormatSections(sections)
+	}
+	return text
+}
+
+// MarshalSections encodes sections for cv_files.sections.
+func MarshalSections(sections []Section) ([]byte, error) {
+	data, err := json.Marshal(sections)
+	if err != nil {
+		return nil, fmt.Errorf("encode sections: %w", err)
+	}
+	return data, nil
+}
+
+// UnmarshalSections decodes cv_files.sections; empty input yields nil.
+func UnmarshalSections(data []byte) ([]Section, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var sections []Section
+	if err := json.Unmarshal(data, &sections); err != nil {
+		return nil, fmt.Errorf("decode sections: %w", err)
+	}
+	return sections, nil
+}
+
+// SectionDetector segments CVs with DetectSections and, when the headings
+// aren't recognizable, asks the LLM where they are.
+type SectionDetector struct {
+	llmService *llm.Service
+}
+
+// NewSectionDetector returns a detector; llmService may be nil to use the
+// heuristics only.
+func NewSectionDetector(llmService *llm.Service) *SectionDetector {
+	return &SectionDetector{llmService: llmService}
+}
+
+// Detect returns the sections of text, or nil if neither the heuristics nor
+// the LLM found a usable structure.
+func (d *SectionDetector) Detect(ctx context.Context, text string) []Section {
+	if sections := DetectSections(text); sections != nil {
+		return sections
+	}
+	if d.llmService == nil || strings.TrimSpace(text) == "" {
+		return nil
+	}
+	headings, err := d.llmService.DetectSectionHeadings(ctx, text)
+	if err != nil {
+		log.Printf("[Sections] LLM heading detection failed: %v", err)
+		return nil
+	}
+	return splitAtHeadings(text, headings)
+}
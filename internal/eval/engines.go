@@ -0,0 +1,127 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+
+	"cv-search/internal/graphrag"
+	"cv-search/internal/storage"
+)
+
+// Engine is a search strategy under evaluation. Search returns candidate IDs
+// (candidates.id) in ranked order, best first.
+type Engine interface {
+	Name() string
+	Search(ctx context.Context, query string) ([]int, error)
+}
+
+// HybridEngine evaluates the BM25 + vector + graph fusion pipeline with a
+// fixed configuration (so weight changes can be compared run over run).
+type HybridEngine struct {
+	Engine *graphrag.HybridSearchEngine
+	Config graphrag.HybridSearchConfig
+	Label  string
+}
+
+func (e *HybridEngine) Name() string {
+	if e.Label != "" {
+		return e.Label
+	}
+	return "hybrid"
+}
+
+func (e *HybridEngine) Search(ctx context.Context, query string) ([]int, error) {
+	results, err := e.Engine.Search(ctx, query, e.Config)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int, 0, len(results))
+	for _, c := range results {
+		if c.CandidateID > 0 {
+			ids = append(ids, c.CandidateID)
+		}
+	}
+	return ids, nil
+}
+
+// EnhancedEngine evaluates the vector + community + LLM engine.
+type EnhancedEngine struct {
+	Engine *graphrag.EnhancedSearchEngine
+	DB     storage.GraphRepo
+}
+
+func (e *EnhancedEngine) Name() string { return "enhanced" }
+
+func (e *EnhancedEngine) Search(ctx context.Context, query string) ([]int, error) {
+	result, err := e.Engine.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return resolveRanked(ctx, e.DB, result.Candidates)
+}
+
+// LLMEngine evaluates the LLM-only engine.
+type LLMEngine struct {
+	Engine *graphrag.LLMSearchEngine
+	DB     storage.GraphRepo
+}
+
+func (e *LLMEngine) Name() string { return "llm" }
+
+func (e *LLMEngine) Search(ctx context.Context, query string) ([]int, error) {
+	result, err := e.Engine.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return resolveRanked(ctx, e.DB, result.Candidates)
+}
+
+// VectorEngine evaluates plain vector search — no fusion, no reranking — to
+// compare embedding models (cmd/tools/reembed). Similar returns person
+// node_ids, best first.
+type VectorEngine struct {
+	Label   string
+	Similar func(ctx context.Context, query string, topK int) ([]string, []float64, error)
+	TopK    int
+	DB      storage.GraphRepo
+}
+
+func (e *VectorEngine) Name() string { return e.Label }
+
+func (e *VectorEngine) Search(ctx context.Context, query string) ([]int, error) {
+	personIDs, _, err := e.Similar(ctx, query, e.TopK)
+	if err != nil {
+		return nil, err
+	}
+	byPerson, err := e.DB.GetCandidateIDsByPersonNodeIDs(ctx, personIDs)
+	if err != nil {
+		return nil, fmt.Errorf("resolve candidate ids: %w", err)
+	}
+	ids := make([]int, 0, len(personIDs))
+	for _, p := range personIDs {
+		if id, ok := byPerson[p]; ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// resolveRanked maps LLM-ranked person nodes to candidate IDs, keeping order.
+// Person nodes without a linked candidate row are dropped.
+func resolveRanked(ctx context.Context, db storage.GraphRepo, ranked []graphrag.LLMRankedCandidate) ([]int, error) {
+	personIDs := make([]string, 0, len(ranked))
+	for _, c := range ranked {
+		personIDs = append(personIDs, c.PersonID)
+	}
+	byPerson, err := db.GetCandidateIDsByPersonNodeIDs(ctx, personIDs)
+	if err != nil {
+		return nil, fmt.Errorf("resolve candidate ids: %w", err)
+	}
+	ids := make([]int, 0, len(ranked))
+	for _, c := range ranked {
+		if id, ok := byPerson[c.PersonID]; ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
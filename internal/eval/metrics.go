@@ -0,0 +1,79 @@
+package eval
+
+import (
+	"math"
+	"sort"
+)
+
+// NDCGAtK computes normalized discounted cumulative gain over the first k
+// ranked candidate IDs, using graded relevance (0 = not relevant).
+func NDCGAtK(ranked []int, relevance map[int]float64, k int) float64 {
+	if k <= 0 || len(relevance) == 0 {
+		return 0
+	}
+
+	dcg := 0.0
+	for i, id := range ranked {
+		if i >= k {
+			break
+		}
+		if rel := relevance[id]; rel > 0 {
+			dcg += (math.Pow(2, rel) - 1) / math.Log2(float64(i+2))
+		}
+	}
+
+	// Ideal DCG: all relevant candidates sorted by grade, best first.
+	grades := make([]float64, 0, len(relevance))
+	for _, rel := range relevance {
+		if rel > 0 {
+			grades = append(grades, rel)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(grades)))
+	idcg := 0.0
+	for i, rel := range grades {
+		if i >= k {
+			break
+		}
+		idcg += (math.Pow(2, rel) - 1) / math.Log2(float64(i+2))
+	}
+	if idcg == 0 {
+		return 0
+	}
+	return dcg / idcg
+}
+
+// ReciprocalRank returns 1/rank of the first relevant candidate, or 0 if none
+// of the ranked IDs is relevant. Averaged over queries this is MRR.
+func ReciprocalRank(ranked []int, relevance map[int]float64) float64 {
+	for i, id := range ranked {
+		if relevance[id] > 0 {
+			return 1 / float64(i+1)
+		}
+	}
+	return 0
+}
+
+// RecallAtK is the share of relevant candidates found in the first k results.
+func RecallAtK(ranked []int, relevance map[int]float64, k int) float64 {
+	total := 0
+	for _, rel := range relevance {
+		if rel > 0 {
+			total++
+		}
+	}
+	if total == 0 || k <= 0 {
+		return 0
+	}
+
+	found := 0
+	for i, id := range ranked {
+		if i >= k {
+			break
+		}
+		if relevance[id] > 0 {
+			found++
+		}
+	}
+	return float64(found) / float64(total)
+}
@@ -0,0 +1,83 @@
+// Package eval measures search quality against labeled query sets, so that
+// prompt, weight or retrieval changes can be checked before they ship.
+//
+// A query set is a YAML file of queries with the candidate IDs a recruiter
+// would expect to see for each one. Every engine under test runs every query,
+// and the ranked results are scored with nDCG@k, MRR and recall@k.
+package eval
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// QuerySet is a named collection of labeled queries.
+//
+//	name: backend-roles
+//	k: [5, 10]
+//	queries:
+//	  - id: go-senior
+//	    query: "senior Go developer with Kubernetes"
+//	    expected: [12, 48, 7]
+//	    grades: {12: 3, 48: 2}   # optional, defaults to 1 per expected ID
+type QuerySet struct {
+	Name    string         `yaml:"name"`
+	K       []int          `yaml:"k"`
+	Queries []LabeledQuery `yaml:"queries"`
+}
+
+// LabeledQuery is a single query with its expected candidates (candidates.id).
+type LabeledQuery struct {
+	ID       string      `yaml:"id"`
+	Query    string      `yaml:"query"`
+	Expected []int       `yaml:"expected"`
+	Grades   map[int]int `yaml:"grades,omitempty"`
+}
+
+// Relevance returns the graded relevance per expected candidate ID.
+// Candidates listed in Expected without an explicit grade get 1.
+func (q LabeledQuery) Relevance() map[int]float64 {
+	rel := make(map[int]float64, len(q.Expected))
+	for _, id := range q.Expected {
+		rel[id] = 1
+	}
+	for id, g := range q.Grades {
+		if g > 0 {
+			rel[id] = float64(g)
+		}
+	}
+	return rel
+}
+
+// LoadQuerySet reads and validates a YAML query set.
+func LoadQuerySet(path string) (*QuerySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read query set: %w", err)
+	}
+
+	var qs QuerySet
+	if err := yaml.Unmarshal(data, &qs); err != nil {
+		return nil, fmt.Errorf("parse query set %s: %w", path, err)
+	}
+	if len(qs.Queries) == 0 {
+		return nil, fmt.Errorf("query set %s has no queries", path)
+	}
+	for i, q := range qs.Queries {
+		if q.Query == "" {
+			return nil, fmt.Errorf("query set %s: query #%d has empty text", path, i+1)
+		}
+		if len(q.Expected) == 0 && len(q.Grades) == 0 {
+			return nil, fmt.Errorf("query set %s: query %q has no expected candidates", path, q.Query)
+		}
+		if q.ID == "" {
+			qs.Queries[i].ID = fmt.Sprintf("q%d", i+1)
+		}
+	}
+	if len(qs.K) == 0 {
+		qs.K = []int{5, 10}
+	}
+	return &qs, nil
+}
@@ -0,0 +1,119 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// QueryResult holds the metrics for one query on one engine.
+type QueryResult struct {
+	QueryID  string          `json:"query_id"`
+	Query    string          `json:"query"`
+	Ranked   []int           `json:"ranked"`
+	NDCG     map[int]float64 `json:"ndcg"`
+	Recall   map[int]float64 `json:"recall"`
+	RR       float64         `json:"reciprocal_rank"`
+	Duration time.Duration   `json:"duration_ns"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// EngineReport aggregates per-query results for one engine.
+type EngineReport struct {
+	Engine   string          `json:"engine"`
+	Queries  []QueryResult   `json:"queries"`
+	MeanNDCG map[int]float64 `json:"mean_ndcg"`
+	Recall   map[int]float64 `json:"mean_recall"`
+	MRR      float64         `json:"mrr"`
+	Failed   int             `json:"failed"`
+}
+
+// Report is the outcome of running a query set against a list of engines.
+type Report struct {
+	QuerySet string         `json:"query_set"`
+	K        []int          `json:"k"`
+	Engines  []EngineReport `json:"engines"`
+}
+
+// Run executes every query in qs against every engine. A failing query is
+// recorded (and scores zero) rather than aborting the run.
+func Run(ctx context.Context, qs *QuerySet, engines []Engine) *Report {
+	report := &Report{QuerySet: qs.Name, K: qs.K}
+
+	for _, engine := range engines {
+		er := EngineReport{
+			Engine:   engine.Name(),
+			MeanNDCG: make(map[int]float64, len(qs.K)),
+			Recall:   make(map[int]float64, len(qs.K)),
+		}
+
+		for _, q := range qs.Queries {
+			rel := q.Relevance()
+			qr := QueryResult{
+				QueryID: q.ID,
+				Query:   q.Query,
+				NDCG:    make(map[int]float64, len(qs.K)),
+				Recall:  make(map[int]float64, len(qs.K)),
+			}
+
+			start := time.Now()
+			ranked, err := engine.Search(ctx, q.Query)
+			qr.Duration = time.Since(start)
+			if err != nil {
+				log.Printf("[Eval] %s / %s failed: %v", engine.Name(), q.ID, err)
+				qr.Error = err.Error()
+				er.Failed++
+			}
+			qr.Ranked = ranked
+
+			for _, k := range qs.K {
+				qr.NDCG[k] = NDCGAtK(ranked, rel, k)
+				qr.Recall[k] = RecallAtK(ranked, rel, k)
+				er.MeanNDCG[k] += qr.NDCG[k]
+				er.Recall[k] += qr.Recall[k]
+			}
+			qr.RR = ReciprocalRank(ranked, rel)
+			er.MRR += qr.RR
+
+			er.Queries = append(er.Queries, qr)
+		}
+
+		n := float64(len(qs.Queries))
+		for _, k := range qs.K {
+			er.MeanNDCG[k] /= n
+			er.Recall[k] /= n
+		}
+		er.MRR /= n
+
+		report.Engines = append(report.Engines, er)
+	}
+
+	return report
+}
+
+// WriteTable prints a compact comparison of engines to w.
+func (r *Report) WriteTable(w io.Writer) {
+	ks := append([]int(nil), r.K...)
+	sort.Ints(ks)
+
+	header := []string{fmt.Sprintf("%-12s", "engine")}
+	for _, k := range ks {
+		header = append(header, fmt.Sprintf("nDCG@%-3d", k), fmt.Sprintf("R@%-5d", k))
+	}
+	header = append(header, "MRR     ", "failed")
+	fmt.Fprintf(w, "Query set: %s\n", r.QuerySet)
+	fmt.Fprintln(w, strings.Join(header, " "))
+
+	for _, er := range r.Engines {
+		row := []string{fmt.Sprintf("%-12s", er.Engine)}
+		for _, k := range ks {
+			row = append(row, fmt.Sprintf("%-8.3f", er.MeanNDCG[k]), fmt.Sprintf("%-7.3f", er.Recall[k]))
+		}
+		row = append(row, fmt.Sprintf("%-8.3f", er.MRR), fmt.Sprintf("%d", er.Failed))
+		fmt.Fprintln(w, strings.Join(row, " "))
+	}
+}
@@ -1,12 +1,16 @@
 package graphrag
 
-import "cv-search/internal/llm"
+import (
+	"context"
+
+	"cv-search/internal/llm"
+)
 
 // LLMClient is the interface every LLM integration must satisfy within the graphrag package.
 type LLMClient interface {
 	ExtractEntities(text string) ([]Entity, error)
 	GenerateEmbedding(text string) ([]float64, error)
-	Generate(prompt string) (string, error)
+	Generate(ctx context.Context, prompt string) (string, error)
 }
 
 // Entity represents a node extracted from text (skill, company, education, etc.)
@@ -36,8 +40,8 @@ func NewLLMAdapter(service *llm.Service) *LLMAdapter {
 	return &LLMAdapter{service: service}
 }
 
-func (a *LLMAdapter) Generate(prompt string) (string, error) {
-	return a.service.Generate(prompt)
+func (a *LLMAdapter) Generate(ctx context.Context, prompt string) (string, error) {
+	return a.service.Generate(ctx, prompt)
 }
 
 func (a *LLMAdapter) ExtractEntities(text string) ([]Entity, error) {
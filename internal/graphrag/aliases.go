@@ -0,0 +1,106 @@
+package graphrag
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"cv-search/internal/textnorm"
+)
+
+// ─── Aliases ─────────────────────────────────────────────────────────────────
+//
+// Graph search matches skills and education institutions by name key
+// (aliasKey), not exact node key, so "Node.js", "NodeJS" and "node js" are
+// one skill, and through graph_aliases (migrations/00041_graph_aliases.sql),
+// which maps other keys of a name to it: golang → Go, ODTÜ → Middle East
+// Technical University. A search term matches every key of its name.
+
+// aliasKey is the name key of s: folded (textnorm.Fold) without spaces,
+// dots, dashes and underscores. graph_alias_key() is its SQL twin.
+func aliasKey(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '.' || r == '-' || r == '_' {
+			return -1
+		}
+		return r
+	}, textnorm.Fold(s))
+}
+
+// aliasKeySQL is the name key of SQL text expr.
+func aliasKeySQL(expr string) string {
+	return "graph_alias_key(" + expr + ")"
+}
+
+// resolveAliases returns the name keys each of terms matches among nodes of
+// nodeType: its own, its name's and the name's other aliases.
+func (q *GraphQuerier) resolveAliases(ctx context.Context, nodeType string, terms []string) (map[string][]string, error) {
+	keys := make(map[string][]string, len(terms))
+	var termKeys []string
+	for _, term := range terms {
+		if k := aliasKey(term); k != "" {
+			keys[term] = []string{k}
+			termKeys = append(termKeys, k)
+		}
+	}
+	if len(termKeys) == 0 {
+		return keys, nil
+	}
+
+	rows, err := q.db.QueryContext(ctx, `
+		WITH terms AS (
+			SELECT t.key, COALESCE(graph_alias_key(a.name), t.key) AS name_key
+			FROM unnest($2::text[]) AS t(key)
+			LEFT JOIN graph_aliases a ON a.node_type = $1 AND a.alias = t.key
+		)
+		SELECT terms.key, terms.name_key, COALESCE(b.alias, '')
+		FROM terms
+		LEFT JOIN graph_aliases b ON b.node_type = $1 AND graph_alias_key(b.name) = terms.name_key
+	`, nodeType, termKeys)
+	if err != nil {
+		return keys, fmt.Errorf("resolve %s aliases: %w", nodeType, err)
+	}
+	defer rows.Close()
+
+	found := make(map[string][]string, len(termKeys))
+	for rows.Next() {
+		var key, nameKey, alias string
+		if err := rows.Scan(&key, &nameKey, &alias); err != nil {
+			return keys, fmt.Errorf("scan %s alias: %w", nodeType, err)
+		}
+		found[key] = appendUnique(found[key], key, nameKey, alias)
+	}
+	if err := rows.Err(); err != nil {
+		return keys, fmt.Errorf("resolve %s aliases: %w", nodeType, err)
+	}
+	for term, k := range keys {
+		if f := found[k[0]]; len(f) > 0 {
+			keys[term] = f
+		}
+	}
+	return keys, nil
+}
+
+// nameKeys returns the name keys term matches, from resolved (see
+// resolveAliases) or, when it wasn't resolved, its own.
+func (c *SearchCriteria) nameKeys(resolved map[string][]string, term string) []string {
+	if keys, ok := resolved[term]; ok {
+		return keys
+	}
+	return []string{aliasKey(term)}
+}
+
+// appendUnique appends the non-empty values not in list yet.
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		if v == "" {
+			continue
+		}
+		if !slices.Contains(list, v) {
+			list = append(list, v)
+		}
+	}
+	return list
+}
@@ -0,0 +1,245 @@
+package graphrag
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"cv-search/internal/tenant"
+)
+
+// ─── Graph analytics ─────────────────────────────────────────────────────────
+//
+// Aggregates that are too expensive to compute per request, stored per
+// organization by ComputeGraphAnalytics and read by storage.GetGraphAnalytics:
+//
+//   - graph_skill_cooccurrence: for each pair of skills, how many people have
+//     both, how many have each, and the lift (observed / expected if the two
+//     were independent). Lift well below 1 between common skills is a rare
+//     combination.
+//   - graph_company_alumni: current and former employees of each company.
+//     Companies with many former employees are the feeder companies.
+//   - graph_person_centrality: each person's degree (distinct neighbours) and
+//     PageRank in the organization's graph.
+//
+// Each run replaces the organization's rows in one transaction.
+
+// PageRank parameters.
+const (
+	pageRankDamping    = 0.85
+	pageRankIterations = 50
+	pageRankTolerance  = 1e-6 // L1 change per node below which iteration stops
+)
+
+// GraphAnalyticsRun is what a ComputeGraphAnalytics run stored.
+type GraphAnalyticsRun struct {
+	SkillPairs int           `json:"skill_pairs"`
+	Companies  int           `json:"companies"`
+	People     int           `json:"people"`
+	Duration   time.Duration `json:"-"`
+}
+
+// ComputeGraphAnalytics recomputes ctx's organization's graph analytics.
+func ComputeGraphAnalytics(ctx context.Context, db *sql.DB) (*GraphAnalyticsRun, error) {
+	orgID := tenant.OrgID(ctx)
+	start := time.Now()
+
+	degree, rank, err := personCentrality(ctx, db, orgID)
+	if err != nil {
+		return nil, err
+	}
+	nodeIDs := make([]int64, 0, len(degree))
+	degrees := make([]int64, 0, len(degree))
+	ranks := make([]float64, 0, len(degree))
+	for id, d := range degree {
+		nodeIDs = append(nodeIDs, int64(id))
+		degrees = append(degrees, int64(d))
+		ranks = append(ranks, rank[id])
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	run := &GraphAnalyticsRun{People: len(nodeIDs)}
+	for _, table := range []string{"graph_skill_cooccurrence", "graph_company_alumni", "graph_person_centrality"} {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE org_id = $1`, orgID); err != nil {
+			return nil, fmt.Errorf("clear %s: %w", table, err)
+		}
+	}
+
+	// Skills are counted by name, once per person however many skill nodes
+	// spell it the same way.
+	res, err := tx.ExecContext(ctx, `
+		WITH person_skills AS (
+			SELECT DISTINCT p.id AS person_id, s.properties->>'name' AS skill
+			FROM graph_nodes p
+			JOIN graph_edges e ON e.source_node_id = p.id AND e.edge_type = 'HAS_SKILL'
+			JOIN graph_nodes s ON s.id = e.target_node_id AND s.node_type = 'skill' AND s.deleted_at IS NULL
+			WHERE p.node_type = 'person' AND p.deleted_at IS NULL AND p.org_id = $1
+			  AND COALESCE(s.properties->>'name', '') <> ''
+		),
+		support AS (
+			SELECT skill, COUNT(*) AS n FROM person_skills GROUP BY skill
+		),
+		people AS (
+			SELECT COUNT(DISTINCT person_id) AS n FROM person_skills
+		)
+		INSERT INTO graph_skill_cooccurrence
+			(org_id, skill_a, skill_b, candidates, skill_a_candidates, skill_b_candidates, lift)
+		SELECT $1::int, a.skill, b.skill, COUNT(*), sa.n, sb.n,
+		       COUNT(*)::float8 * people.n / (sa.n * sb.n)
+		FROM person_skills a
+		JOIN person_skills b ON b.person_id = a.person_id AND b.skill > a.skill
+		JOIN support sa ON sa.skill = a.skill
+		JOIN support sb ON sb.skill = b.skill
+		CROSS JOIN people
+		GROUP BY a.skill, b.skill, sa.n, sb.n, people.n
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("compute skill co-occurrence: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil {
+		run.SkillPairs = int(n)
+	}
+
+	res, err = tx.ExecContext(ctx, `
+		INSERT INTO graph_company_alumni (org_id, company, current_employees, former_employees)
+		SELECT $1::int, c.properties->>'name',
+		       COUNT(DISTINCT p.id) FILTER (WHERE e.edge_type = 'WORKS_AT'),
+		       COUNT(DISTINCT p.id) FILTER (WHERE e.edge_type = 'WORKED_AT')
+		FROM graph_nodes p
+		JOIN graph_edges e ON e.source_node_id = p.id AND e.edge_type IN ('WORKS_AT', 'WORKED_AT')
+		JOIN graph_nodes c ON c.id = e.target_node_id AND c.node_type = 'company' AND c.deleted_at IS NULL
+		WHERE p.node_type = 'person' AND p.deleted_at IS NULL AND p.org_id = $1
+		  AND COALESCE(c.properties->>'name', '') <> ''
+		GROUP BY c.properties->>'name'
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("compute company alumni: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil {
+		run.Companies = int(n)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO graph_person_centrality (org_id, node_id, degree, pagerank)
+		SELECT $1::int, v.node_id, v.degree, v.pagerank
+		FROM unnest($2::bigint[], $3::bigint[], $4::float8[]) AS v(node_id, degree, pagerank)
+	`, orgID, nodeIDs, degrees, ranks); err != nil {
+		return nil, fmt.Errorf("store person centrality: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO graph_analytics_runs (org_id, computed_at, duration_ms)
+		VALUES ($1, NOW(), $2)
+		ON CONFLICT (org_id) DO UPDATE SET computed_at = EXCLUDED.computed_at, duration_ms = EXCLUDED.duration_ms
+	`, orgID, time.Since(start).Milliseconds()); err != nil {
+		return nil, fmt.Errorf("record graph analytics run: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	run.Duration = time.Since(start)
+	log.Printf("[GraphAnalytics] org=%d: %d skill pairs, %d companies, %d people in %v",
+		orgID, run.SkillPairs, run.Companies, run.People, run.Duration)
+	return run, nil
+}
+
+// personCentrality returns the degree and PageRank of every live person node
+// of the organization. Edges count in both directions: the graph is person →
+// skill / company / school, so directed PageRank would leave people with
+// nothing but the teleport share. Ranks are scaled so that the average node
+// has 1.
+func personCentrality(ctx context.Context, db *sql.DB, orgID int) (map[int]int, map[int]float64, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT e.source_node_id, e.target_node_id, s.node_type = 'person', t.node_type = 'person'
+		FROM graph_edges e
+		JOIN graph_nodes s ON s.id = e.source_node_id AND s.deleted_at IS NULL
+		JOIN graph_nodes t ON t.id = e.target_node_id AND t.deleted_at IS NULL
+		WHERE e.org_id = $1 AND e.source_node_id <> e.target_node_id
+	`, orgID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load graph edges: %w", err)
+	}
+	defer rows.Close()
+
+	neighbours := map[int]map[int]bool{}
+	isPerson := map[int]bool{}
+	link := func(a, b int) {
+		if neighbours[a] == nil {
+			neighbours[a] = map[int]bool{}
+		}
+		neighbours[a][b] = true
+	}
+	for rows.Next() {
+		var src, dst int
+		var srcPerson, dstPerson bool
+		if err := rows.Scan(&src, &dst, &srcPerson, &dstPerson); err != nil {
+			return nil, nil, fmt.Errorf("scan graph edge: %w", err)
+		}
+		link(src, dst)
+		link(dst, src)
+		if srcPerson {
+			isPerson[src] = true
+		}
+		if dstPerson {
+			isPerson[dst] = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("load graph edges: %w", err)
+	}
+
+	rank := pageRank(neighbours)
+	degree := map[int]int{}
+	personRank := map[int]float64{}
+	for id := range isPerson {
+		degree[id] = len(neighbours[id])
+		personRank[id] = rank[id]
+	}
+	return degree, personRank, nil
+}
+
+// pageRank runs PageRank on an undirected graph given as adjacency sets,
+// scaled so that the average node has 1.
+func pageRank(neighbours map[int]map[int]bool) map[int]float64 {
+	n := float64(len(neighbours))
+	if n == 0 {
+		return map[int]float64{}
+	}
+	rank := make(map[int]float64, len(neighbours))
+	for id := range neighbours {
+		rank[id] = 1 / n
+	}
+	for i := 0; i < pageRankIterations; i++ {
+		next := make(map[int]float64, len(neighbours))
+		for id := range neighbours {
+			next[id] = (1 - pageRankDamping) / n
+		}
+		// Every node here has at least one neighbour, so no rank dangles.
+		for id, adj := range neighbours {
+			share := pageRankDamping * rank[id] / float64(len(adj))
+			for nb := range adj {
+				next[nb] += share
+			}
+		}
+		delta := 0.0
+		for id := range neighbours {
+			delta += math.Abs(next[id] - rank[id])
+		}
+		rank = next
+		if delta < pageRankTolerance*n {
+			break
+		}
+	}
+	for id := range rank {
+		rank[id] *= n
+	}
+	return rank
+}
@@ -21,7 +21,25 @@ func NewQueryAnalyzer(llmClient LLMClient) *QueryAnalyzer {
 func (a *QueryAnalyzer) AnalyzeQuery(ctx context.Context, query string) (*SearchCriteria, error) {
 	log.Printf("[GraphRAG] Analyzing query: %s", query)
 
-	prompt := fmt.Sprintf(`You are a talent search query analyzer. Extract structured search criteria from the user's natural language query.
+	response, err := a.llmClient.Generate(ctx, analysisPrompt(query))
+	if err != nil {
+		return nil, fmt.Errorf("LLM query analysis failed: %w", err)
+	}
+
+	log.Printf("[GraphRAG] LLM analysis response: %s", response)
+
+	var criteria SearchCriteria
+	if err := json.Unmarshal([]byte(response), &criteria); err != nil {
+		return nil, fmt.Errorf("failed to parse LLM response: %w\nResponse: %s", err, response)
+	}
+
+	log.Printf("[GraphRAG] Extracted criteria: %+v", criteria)
+	return &criteria, nil
+}
+
+// analysisPrompt is the prompt AnalyzeQuery sends for query.
+func analysisPrompt(query string) string {
+	return fmt.Sprintf(`You are a talent search query analyzer. Extract structured search criteria from the user's natural language query.
 
 User Query: "%s"
 
@@ -34,7 +52,14 @@ Extract and return ONLY valid JSON with this structure:
   "education": ["institution names or degree types"],
   "min_experience": null,
   "max_experience": null,
-  "location": ["city or country names"]
+  "location": ["city or country names"],
+  "radius_km": 0,
+  "certifications": ["certification names or issuers"],
+  "languages": [{"language": "Language name", "min_proficiency": "Basic|Intermediate|Advanced|Fluent|Native"}],
+  "projects": ["short phrases for what the candidate should have built or achieved"],
+  "work_modes": ["remote|hybrid|onsite"],
+  "employment_types": ["contract|permanent"],
+  "available_within_days": null
 }
 
 Rules:
@@ -42,25 +67,15 @@ Rules:
 - Extract implicit requirements (e.g., "senior Java dev" → skills: ["Java"], seniority: "Senior")
 - "developer", "engineer", "architect" are job titles/positions, NOT skills
 - For experience: "5+ years" → min_experience: 5, "3-5 years" → min_experience: 3, max_experience: 5
+- Certifications: "AWS certified" → certifications: ["AWS"], "PMP sertifikalı" → ["PMP"]; a certification is not a skill requirement
+- Spoken languages go in languages, never skills: "fluent German" → [{"language": "German", "min_proficiency": "Fluent"}], "speaks English" → [{"language": "English", "min_proficiency": ""}]. Use English language names
+- Projects: work the candidate should have done, as 1-3 word phrases: "built payment systems from scratch" → projects: ["payment system"], "ödeme sistemi geliştirmiş" → ["payment system"]. Not skills or job titles
+- Work mode, employment type and availability only when the query asks for them: "remote" / "uzaktan" → work_modes: ["remote"], "hybrid or remote" → ["hybrid", "remote"], "on-site" / "ofisten" → ["onsite"]; "contractor", "freelance" → employment_types: ["contract"], "permanent", "full-time", "kadrolu" → ["permanent"]; "available immediately" / "hemen başlayabilecek" → available_within_days: 0, "available within 30 days" → 30, "can start within a month" / "1 ay içinde başlayabilecek" → 30, "within 2 weeks" → 14
+- Location only where the candidate should live, one entry per place as named ("İstanbul'da" → ["İstanbul"]); "within 50km of Ankara" / "Ankara'ya 50 km mesafede" → location: ["Ankara"], radius_km: 50, "near Izmir" / "İzmir civarı" → radius_km: 50; otherwise radius_km: 0. "Remote" is a work mode, not a location
 - Return empty arrays for missing criteria, not null
 - If no specific seniority mentioned, leave it empty string ""
 
 Now analyze this query and return ONLY the JSON:`, query)
-
-	response, err := a.llmClient.Generate(prompt)
-	if err != nil {
-		return nil, fmt.Errorf("LLM query analysis failed: %w", err)
-	}
-
-	log.Printf("[GraphRAG] LLM analysis response: %s", response)
-
-	var criteria SearchCriteria
-	if err := json.Unmarshal([]byte(response), &criteria); err != nil {
-		return nil, fmt.Errorf("failed to parse LLM response: %w\nResponse: %s", err, response)
-	}
-
-	log.Printf("[GraphRAG] Extracted criteria: %+v", criteria)
-	return &criteria, nil
 }
 
 // FilterCandidatesWithLLM asks the LLM to filter the candidate list according to the
@@ -115,7 +130,7 @@ func (a *QueryAnalyzer) FilterCandidatesWithLLM(ctx context.Context, query strin
 	prompt := b.String()
 
 	// Use a deterministic LLM call (low temperature ideally set in the provider config)
-	resp, err := a.llmClient.Generate(prompt)
+	resp, err := a.llmClient.Generate(ctx, prompt)
 	if err != nil {
 		return candidates, fmt.Errorf("LLM filtering failed: %w", err)
 	}
@@ -0,0 +1,123 @@
+package graphrag
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ─── Availability ────────────────────────────────────────────────────────────
+//
+// When a CV says the candidate can start: a notice period ("1 month", "2
+// hafta", "immediately"), kept on the person node as notice_period_days, and
+// an availability date ("available from March 2025"), kept as available_from
+// (YYYY-MM-DD). A search asks for people available within some days of
+// today: the date when the CV gave one, else the notice period counted from
+// today.
+
+// noticeUnitDays is how many days a notice period unit is, English and
+// Turkish.
+var noticeUnitDays = map[string]float64{
+	"d": 1, "day": 1, "days": 1, "gun": 1,
+	"w": 7, "wk": 7, "wks": 7, "week": 7, "weeks": 7, "hafta": 7,
+	"mo": 30, "month": 30, "months": 30, "ay": 30,
+	"y": 365, "yr": 365, "year": 365, "years": 365, "yil": 365,
+}
+
+// noticeImmediately are ways of saying there's no notice period.
+var noticeImmediately = []string{"immediate", "asap", "right away", "right now", "no notice", "hemen", "derhal"}
+
+// noticePeriodPattern matches an amount, or a range of them, and a unit:
+// "30 days", "1-2 months", "3 ay".
+var noticePeriodPattern = regexp.MustCompile(`(\d+(?:[.,]\d+)?)(?:\s*(?:-|–|to)\s*(\d+(?:[.,]\d+)?))?\s*([a-z]+)`)
+
+// NoticePeriodDays reads a notice period as the CV stated it: a number of
+// days, or text such as "1 month", "2-4 weeks", "immediately". A range
+// counts as its upper end.
+func NoticePeriodDays(v interface{}) (float64, bool) {
+	str, ok := v.(string)
+	if !ok {
+		days, ok := propFloat(v)
+		return days, ok && days >= 0
+	}
+	str = strings.ToLower(strings.TrimSpace(str))
+	str = strings.NewReplacer("ı", "i", "ş", "s", "ç", "c", "ğ", "g", "ö", "o", "ü", "u").Replace(str)
+	for _, word := range []string{"a ", "an ", "one ", "bir "} {
+		if strings.HasPrefix(str, word) {
+			str = "1 " + strings.TrimPrefix(str, word)
+		}
+	}
+	if m := noticePeriodPattern.FindStringSubmatch(str); m != nil {
+		if unit, ok := noticeUnit(m[3]); ok {
+			amount := m[1]
+			if m[2] != "" {
+				amount = m[2]
+			}
+			n, _ := strconv.ParseFloat(strings.ReplaceAll(amount, ",", "."), 64)
+			return n * unit, true
+		}
+	}
+	for _, word := range noticeImmediately {
+		if strings.Contains(str, word) {
+			return 0, true
+		}
+	}
+	if days, err := strconv.ParseFloat(str, 64); err == nil && days >= 0 {
+		return days, true // a bare number is days
+	}
+	return 0, false
+}
+
+// noticeUnit returns the days of a notice period unit, also as the stem of
+// a longer word ("aylık", "haftalık").
+func noticeUnit(word string) (float64, bool) {
+	if days, ok := noticeUnitDays[word]; ok {
+		return days, true
+	}
+	for unit, days := range noticeUnitDays {
+		if len(unit) > 1 && strings.HasPrefix(word, unit) {
+			return days, true
+		}
+	}
+	return 0, false
+}
+
+// NormalizeAvailableFrom returns an availability date as YYYY-MM-DD, a month
+// ("2025-03") as its first day, or "" when it isn't a date.
+func NormalizeAvailableFrom(s string) string {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{"2006-01-02", "2006-01"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Format("2006-01-02")
+		}
+	}
+	return ""
+}
+
+// AvailableInDays returns how many days from now the person can start,
+// negative or 0 when they already can, nil when their CV didn't say.
+func (p PersonProperties) AvailableInDays(now time.Time) *float64 {
+	if p.AvailableFrom != "" {
+		if t, err := time.Parse("2006-01-02", p.AvailableFrom); err == nil {
+			today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+			days := t.Sub(today).Hours() / 24
+			return &days
+		}
+	}
+	return p.NoticePeriodDays
+}
+
+// personAvailableInDaysSQL is AvailableInDays of person p in SQL, NULL when
+// the CV didn't say.
+const personAvailableInDaysSQL = `COALESCE(
+	CASE WHEN p.properties->>'available_from' ~ '^\d{4}-\d{2}-\d{2}$'
+	     THEN ((p.properties->>'available_from')::date - CURRENT_DATE)::float END,
+	(p.properties->>'notice_period_days')::float)`
+
+// availableWithinSQL is the condition that person p can start within the
+// days of parameter arg.
+func availableWithinSQL(arg int) string {
+	return fmt.Sprintf("%s <= $%d", personAvailableInDaysSQL, arg)
+}
@@ -4,16 +4,43 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"log"
 	"strings"
+	"unicode"
+
+	"cv-search/internal/tenant"
+	"cv-search/internal/textnorm"
+)
+
+// Text search configurations accepted by BM25Searcher. candidates.search_vector
+// is built from both 'english' and 'simple_unaccent' lexemes (see the trigger in
+// migrations/00040_bm25_field_weights.sql), so either one can be used at query time.
+const (
+	TSConfigEnglish        = "english"         // stemmed, English stopwords
+	TSConfigSimpleUnaccent = "simple_unaccent" // no stemming, accents folded — better for Turkish CVs
 )
 
 // BM25Searcher performs full-text search using PostgreSQL tsvector
 type BM25Searcher struct {
-	db *sql.DB
+	db       *sql.DB
+	tsConfig string
 }
 
 func NewBM25Searcher(db *sql.DB) *BM25Searcher {
-	return &BM25Searcher{db: db}
+	return &BM25Searcher{db: db, tsConfig: TSConfigEnglish}
+}
+
+// SetTextSearchConfig selects the regconfig used to build the tsquery.
+// Unknown values are rejected and the current config is kept.
+func (b *BM25Searcher) SetTextSearchConfig(name string) {
+	switch name {
+	case "":
+		return
+	case TSConfigEnglish, TSConfigSimpleUnaccent:
+		b.tsConfig = name
+	default:
+		log.Printf("[BM25] Unknown text search config %q, keeping %q", name, b.tsConfig)
+	}
 }
 
 // BM25Result represents a candidate with BM25 relevance score
@@ -25,13 +52,87 @@ type BM25Result struct {
 	Headline    string  // First 100 chars of experience
 }
 
-// Search performs BM25-style full-text search
-// Returns top N candidates sorted by relevance
+// BM25 fields: the candidate columns candidates.search_vector is built
+// from, under the weight labels of migrations/00040_bm25_field_weights.sql.
+const (
+	BM25FieldName       = "name"       // A
+	BM25FieldSkills     = "skills"     // B
+	BM25FieldExperience = "experience" // C
+	BM25FieldLocation   = "location"   // D
+)
+
+// BM25FieldWeights weighs a match in each field for ts_rank, each in [0, 1]
+// (PostgreSQL rejects more).
+type BM25FieldWeights struct {
+	Name       float64 `json:"name"`
+	Skills     float64 `json:"skills"`
+	Experience float64 `json:"experience"`
+	Location   float64 `json:"location"`
+}
+
+// DefaultBM25FieldWeights ranks a name or skill match highest, then
+// experience, then location.
+func DefaultBM25FieldWeights() BM25FieldWeights {
+	return BM25FieldWeights{Name: 1, Skills: 1, Experience: 0.4, Location: 0.2}
+}
+
+// Set sets the weight of field, one of the BM25 fields.
+func (w *BM25FieldWeights) Set(field string, weight float64) error {
+	if weight < 0 || weight > 1 {
+		return fmt.Errorf("weight of %s must be between 0 and 1", field)
+	}
+	switch field {
+	case BM25FieldName:
+		w.Name = weight
+	case BM25FieldSkills:
+		w.Skills = weight
+	case BM25FieldExperience:
+		w.Experience = weight
+	case BM25FieldLocation:
+		w.Location = weight
+	default:
+		return fmt.Errorf("unknown field %q (want %s, %s, %s or %s)", field,
+			BM25FieldName, BM25FieldSkills, BM25FieldExperience, BM25FieldLocation)
+	}
+	return nil
+}
+
+// rankWeights is w as ts_rank's weights array, in label order {D, C, B, A}.
+func (w BM25FieldWeights) rankWeights() []float64 {
+	return []float64{w.Location, w.Experience, w.Skills, w.Name}
+}
+
+// Search performs BM25-style full-text search over ctx's organization with
+// the default field weights. Returns top N candidates sorted by relevance
 func (b *BM25Searcher) Search(ctx context.Context, query string, limit int) ([]BM25Result, error) {
-	// Convert query to tsquery format
-	// "Go developer" -> "Go | developer"
+	return b.SearchWithWeights(ctx, query, limit, DefaultBM25FieldWeights())
+}
+
+// SearchWithWeights is Search ranking a match in each field by weights.
+func (b *BM25Searcher) SearchWithWeights(ctx context.Context, query string, limit int, weights BM25FieldWeights) ([]BM25Result, error) {
+	// Convert query to websearch syntax
+	// "Go developer" -> "go or developer"
 	tsQuery := prepareTSQuery(query)
+	if tsQuery == "" {
+		// Nothing searchable left (empty / punctuation-only query) — no BM25 signal.
+		return nil, nil
+	}
+
+	// websearch_to_tsquery never raises a syntax error, whatever the user typed.
+	return b.search(ctx, "websearch_to_tsquery", tsQuery, limit, weights)
+}
 
+// SearchSyntax is SearchWithWeights of a query written as a boolean
+// expression: only candidates matching it are found.
+func (b *BM25Searcher) SearchSyntax(ctx context.Context, syntax *QuerySyntax, limit int, weights BM25FieldWeights) ([]BM25Result, error) {
+	return b.search(ctx, "to_tsquery", syntax.tsQuery, limit, weights)
+}
+
+// search ranks ctx's organization's candidates matching tsQuery, parsed by
+// parseFunc (websearch_to_tsquery or to_tsquery).
+func (b *BM25Searcher) search(ctx context.Context, parseFunc, tsQuery string, limit int, weights BM25FieldWeights) ([]BM25Result, error) {
+	// The regconfig and field weights are bound as parameters rather than
+	// spliced into SQL.
 	// Join graph_nodes to get node_id — the same key used by vector and graph searchers.
 	// Without this, BM25 results would never merge with the other two sources.
 	sqlQuery := `
@@ -39,16 +140,20 @@ func (b *BM25Searcher) Search(ctx context.Context, query string, limit int) ([]B
 			c.id,
 			COALESCE(gn.node_id, ''),
 			c.name,
-			ts_rank(c.search_vector, to_tsquery('english', $1)) as rank,
-			LEFT(c.experience, 100) as headline
+			ts_rank($5::float4[], c.search_vector, q.query) as rank,
+			LEFT(COALESCE(c.experience, ''), 100) as headline
 		FROM candidates c
+		CROSS JOIN ` + parseFunc + `($3::regconfig, $1) AS q(query)
 		LEFT JOIN graph_nodes gn ON gn.id = c.graph_node_id
-		WHERE c.search_vector @@ to_tsquery('english', $1)
+		WHERE c.search_vector @@ q.query
+		  AND c.deleted_at IS NULL
+		  AND c.org_id = $4
+		  AND ` + candidateStatusSQL(ctx, "c") + `
 		ORDER BY rank DESC
 		LIMIT $2
 	`
 
-	rows, err := b.db.QueryContext(ctx, sqlQuery, tsQuery, limit)
+	rows, err := b.db.QueryContext(ctx, sqlQuery, tsQuery, limit, b.tsConfig, tenant.OrgID(ctx), weights.rankWeights())
 	if err != nil {
 		return nil, fmt.Errorf("bm25 search failed: %w", err)
 	}
@@ -66,25 +171,69 @@ func (b *BM25Searcher) Search(ctx context.Context, query string, limit int) ([]B
 	return results, rows.Err()
 }
 
-// prepareTSQuery converts natural language query to PostgreSQL tsquery.
-// Uses OR logic so any term match counts; ts_rank handles relevance ordering.
-// Example: "senior golang developer" -> "senior | golang | develop"
-func prepareTSQuery(query string) string {
-	query = strings.ToLower(query)
-	words := strings.Fields(query)
+// MatchingSyntax returns which of personIDs (person node IDs) are
+// candidates whose search_vector matches syntax.
+func (b *BM25Searcher) MatchingSyntax(ctx context.Context, syntax *QuerySyntax, personIDs []string) (map[string]bool, error) {
+	rows, err := b.db.QueryContext(ctx, `
+		SELECT gn.node_id
+		FROM graph_nodes gn
+		JOIN candidates c ON c.graph_node_id = gn.id AND c.deleted_at IS NULL
+		WHERE gn.node_id = ANY($1) AND gn.org_id = $2
+		  AND c.search_vector @@ to_tsquery($3::regconfig, $4)
+	`, personIDs, tenant.OrgID(ctx), b.tsConfig, syntax.tsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("match query syntax: %w", err)
+	}
+	defer rows.Close()
 
-	filtered := make([]string, 0, len(words))
-	for _, word := range words {
-		if len(word) >= 2 { // Skip single-char terms; keep "Go", "C#", etc.
-			filtered = append(filtered, word)
+	matching := make(map[string]bool, len(personIDs))
+	for rows.Next() {
+		var personID string
+		if err := rows.Scan(&personID); err != nil {
+			return nil, fmt.Errorf("scan query syntax match: %w", err)
 		}
+		matching[personID] = true
 	}
+	return matching, rows.Err()
+}
 
-	if len(filtered) == 0 {
-		return "default"
-	}
+// prepareTSQuery converts a natural language query to websearch_to_tsquery input.
+// Uses OR logic so any term match counts; ts_rank handles relevance ordering.
+// Quotes, dashes and other operator characters are stripped so user input can't
+// turn into phrase/negation syntax by accident. Terms are folded like the
+// indexed text (textnorm.Fold / tr_fold), so "Yazılım" finds "YAZILIM" and
+// "yazilim". Returns "" when nothing is left.
+// Example: `senior "golang" developer!` -> "senior or golang or developer"
+func prepareTSQuery(query string) string {
+	return tsQueryTerms(textnorm.Fold(query))
+}
 
+// tsQueryTerms is prepareTSQuery of an already folded (or lower-cased)
+// query.
+func tsQueryTerms(query string) string {
 	// OR logic for maximum recall — ts_rank will sort by how many terms match.
 	// AND would require ALL terms in one row, which is too strict for skill lists.
-	return strings.Join(filtered, " | ")
+	return strings.Join(queryWords(query), " or ")
+}
+
+// queryWords returns the searchable words of query: letters, digits and
+// the + # . of "C++", "C#", "Node.js"; other characters separate words.
+func queryWords(query string) []string {
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '+' || r == '#' || r == '.' {
+			return r
+		}
+		return ' '
+	}, query)
+
+	words := strings.Fields(cleaned)
+	filtered := make([]string, 0, len(words))
+	for _, word := range words {
+		word = strings.Trim(word, ".")
+		if len([]rune(word)) < 2 || word == "or" { // Skip single-char terms; keep "Go", "C#", etc.
+			continue
+		}
+		filtered = append(filtered, word)
+	}
+	return filtered
 }
@@ -1,15 +1,25 @@
 package graphrag
 
-import "strings"
+import (
+	"sort"
+	"strings"
+)
 
 // CommunityPattern defines a community matching pattern
 type CommunityPattern struct {
 	Name      string
 	KeySkills []string
+	// Job titles that place a search in the community ("site reliability
+	// engineer"). The defaults have none: PositionsToCommunities' table
+	// covers them.
+	KeyTitles []string
 }
 
+// CommunityPatterns are keyword communities by ID.
+type CommunityPatterns map[string]CommunityPattern
+
 // DefaultCommunities defines Level 1 communities (manually curated)
-var DefaultCommunities = map[string]CommunityPattern{
+var DefaultCommunities = CommunityPatterns{
 	"backend": {
 		Name:      "Backend Developers",
 		KeySkills: []string{"Java", "Python", "Go", "Node.js", "PHP", "Ruby", "C#", ".NET", "Spring", "Django", "FastAPI", "Express"},
@@ -44,6 +54,40 @@ var DefaultCommunities = map[string]CommunityPattern{
 	},
 }
 
+// MergeCommunityPatterns returns the defaults with an organization's own
+// patterns laid over them; a custom pattern with a default's ID replaces it.
+func MergeCommunityPatterns(custom CommunityPatterns) CommunityPatterns {
+	merged := make(CommunityPatterns, len(DefaultCommunities)+len(custom))
+	for id, p := range DefaultCommunities {
+		merged[id] = p
+	}
+	for id, p := range custom {
+		merged[id] = p
+	}
+	return merged
+}
+
+// orDefault returns p, or DefaultCommunities when p is nil.
+func (p CommunityPatterns) orDefault() CommunityPatterns {
+	if p == nil {
+		return DefaultCommunities
+	}
+	return p
+}
+
+// Names returns the names of the given community IDs, skipping those
+// without a pattern (e.g. "general").
+func (p CommunityPatterns) Names(ids []string) []string {
+	p = p.orDefault()
+	var names []string
+	for _, id := range ids {
+		if pattern, ok := p[id]; ok && pattern.Name != "" {
+			names = append(names, pattern.Name)
+		}
+	}
+	return names
+}
+
 // CommunityMembership represents a candidate's membership in a community
 type CommunityMembership struct {
 	CommunityID string
@@ -60,13 +104,19 @@ func FindCommunity(skills []SkillNode) string {
 // FindCommunities determines all communities a candidate belongs to (Microsoft GraphRAG style)
 // Returns: primary community, all communities above threshold, community scores
 func FindCommunities(skills []SkillNode, threshold float64) (string, []string, map[string]float64) {
+	return DefaultCommunities.FindCommunities(skills, threshold)
+}
+
+// FindCommunities is the package-level FindCommunities over p's patterns.
+func (p CommunityPatterns) FindCommunities(skills []SkillNode, threshold float64) (string, []string, map[string]float64) {
+	p = p.orDefault()
 	// Track match scores for each community
 	rawScores := make(map[string]int)
 
 	for _, skill := range skills {
 		skillLower := strings.ToLower(skill.Name)
 
-		for communityID, pattern := range DefaultCommunities {
+		for communityID, pattern := range p {
 			for _, keySkill := range pattern.KeySkills {
 				if strings.Contains(skillLower, strings.ToLower(keySkill)) ||
 					strings.Contains(strings.ToLower(keySkill), skillLower) {
@@ -120,6 +170,12 @@ func FindCommunities(skills []SkillNode, threshold float64) (string, []string, m
 // Input is already normalized by the LLM (e.g. "trade bilen analist" → ["analyst"]),
 // so this only needs a simple substring table — no keyword scanning of raw query text.
 func PositionsToCommunities(positions []string) []string {
+	return DefaultCommunities.PositionsToCommunities(positions)
+}
+
+// PositionsToCommunities is the package-level PositionsToCommunities with
+// p's key titles tried first, most specific (longest) title first.
+func (p CommunityPatterns) PositionsToCommunities(positions []string) []string {
 	// Each entry: if the position contains this substring → map to that community.
 	// Ordered from most specific to least specific to avoid false matches.
 	type mapping struct {
@@ -174,6 +230,22 @@ func PositionsToCommunities(positions []string) []string {
 		{"software", "backend"},
 	}
 
+	var titles []mapping
+	for communityID, pattern := range p.orDefault() {
+		for _, title := range pattern.KeyTitles {
+			if t := strings.ToLower(strings.TrimSpace(title)); t != "" {
+				titles = append(titles, mapping{t, communityID})
+			}
+		}
+	}
+	sort.Slice(titles, func(i, j int) bool {
+		if len(titles[i].substr) != len(titles[j].substr) {
+			return len(titles[i].substr) > len(titles[j].substr)
+		}
+		return titles[i].community < titles[j].community
+	})
+	mappings = append(titles, mappings...)
+
 	seen := map[string]bool{}
 	var result []string
 	for _, pos := range positions {
@@ -191,12 +263,18 @@ func PositionsToCommunities(positions []string) []string {
 
 // FindCommunitiesByQuery infers relevant communities from search query
 func FindCommunitiesByQuery(query string) []string {
+	return DefaultCommunities.FindCommunitiesByQuery(query)
+}
+
+// FindCommunitiesByQuery is the package-level FindCommunitiesByQuery over
+// p's patterns, matching their key titles as well as their key skills.
+func (p CommunityPatterns) FindCommunitiesByQuery(query string) []string {
 	queryLower := strings.ToLower(query)
 	matches := make(map[string]bool)
 
-	for communityID, pattern := range DefaultCommunities {
-		for _, keySkill := range pattern.KeySkills {
-			if strings.Contains(queryLower, strings.ToLower(keySkill)) {
+	for communityID, pattern := range p.orDefault() {
+		for _, keyword := range append(append([]string(nil), pattern.KeySkills...), pattern.KeyTitles...) {
+			if keyword != "" && strings.Contains(queryLower, strings.ToLower(keyword)) {
 				matches[communityID] = true
 				break
 			}
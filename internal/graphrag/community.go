@@ -10,6 +10,8 @@ import (
 	"math/rand"
 	"strings"
 	"time"
+
+	"cv-search/internal/tenant"
 )
 
 // CommunityDetector detects professional communities via k-means on person embeddings.
@@ -22,6 +24,9 @@ type CommunityDetector struct {
 
 	// K is the number of clusters. 0 = auto-compute: max(6, nPersons/4).
 	K int
+
+	// drift decides which re-detected communities are re-summarized.
+	drift DriftThresholds
 }
 
 func NewCommunityDetector(db *sql.DB, llm LLMClient, embeddingService *EmbeddingService) *CommunityDetector {
@@ -29,9 +34,16 @@ func NewCommunityDetector(db *sql.DB, llm LLMClient, embeddingService *Embedding
 		db:               db,
 		llm:              llm,
 		embeddingService: embeddingService,
+		drift:            DefaultDriftThresholds(),
 	}
 }
 
+// SetDriftThresholds sets when a re-detected community counts as drifted
+// (see DriftThresholds).
+func (cd *CommunityDetector) SetDriftThresholds(t DriftThresholds) {
+	cd.drift = t
+}
+
 // communityPerson holds person node data for clustering.
 type communityPerson struct {
 	id        int       // graph_nodes.id (integer PK)
@@ -39,23 +51,30 @@ type communityPerson struct {
 	embedding []float32 // 1536-dimensional vector
 }
 
-// DetectCommunities runs k-means on person embeddings, generates LLM titles/summaries,
-// embeds the summaries, and upserts results to graph_communities + community_members.
+// DetectCommunities runs k-means on person embeddings, matches the clusters to
+// the level's previous communities (see community_drift.go), generates LLM
+// titles/summaries and embeds them for new and drifted communities,
+// upserts results to graph_communities + community_members and recomputes
+// the members' centroid embeddings. What changed is
+// returned and appended to community_changes.
+// Only ctx's organization's people are clustered, into its own communities.
 // Safe to run repeatedly — uses ON CONFLICT DO UPDATE, never hard-deletes.
-func (cd *CommunityDetector) DetectCommunities(ctx context.Context, level int) error {
-	log.Printf("[CommunityDetect] Starting embedding-based detection (level=%d)", level)
+func (cd *CommunityDetector) DetectCommunities(ctx context.Context, level int) (*CommunityDetection, error) {
+	orgID := tenant.OrgID(ctx)
+	log.Printf("[CommunityDetect] Starting embedding-based detection (org=%d, level=%d)", orgID, level)
 
 	if cd.embeddingService == nil {
-		return fmt.Errorf("embedding service not configured")
+		return nil, fmt.Errorf("embedding service not configured")
 	}
 
 	persons, err := cd.loadPersonEmbeddings(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to load person embeddings: %w", err)
+		return nil, fmt.Errorf("failed to load person embeddings: %w", err)
 	}
+	result := &CommunityDetection{Level: level, Changes: []CommunityChange{}}
 	if len(persons) == 0 {
 		log.Printf("[CommunityDetect] No person embeddings found — upload CVs first")
-		return nil
+		return result, nil
 	}
 
 	k := cd.K
@@ -70,6 +89,12 @@ func (cd *CommunityDetector) DetectCommunities(ctx context.Context, level int) e
 
 	log.Printf("[CommunityDetect] %d persons, k=%d", len(persons), k)
 
+	// The previous run's communities, to match the new clusters against.
+	previous, err := cd.loadPreviousCommunities(ctx, level)
+	if err != nil {
+		return nil, err
+	}
+
 	// Purge stale memberships for this level before re-clustering.
 	// K may change between runs (new CVs added), so old assignments can be wrong.
 	// graph_communities rows are kept and updated in-place (ON CONFLICT DO UPDATE);
@@ -77,9 +102,9 @@ func (cd *CommunityDetector) DetectCommunities(ctx context.Context, level int) e
 	_, err = cd.db.ExecContext(ctx, `
 		DELETE FROM community_members
 		WHERE community_id IN (
-			SELECT id FROM graph_communities WHERE level = $1
+			SELECT id FROM graph_communities WHERE level = $1 AND org_id = $2
 		)
-	`, level)
+	`, level, orgID)
 	if err != nil {
 		log.Printf("[CommunityDetect] Failed to purge stale memberships (non-fatal): %v", err)
 	} else {
@@ -99,103 +124,225 @@ func (cd *CommunityDetector) DetectCommunities(ctx context.Context, level int) e
 		clusters[ci].embeddings = append(clusters[ci].embeddings, p.embedding)
 	}
 
-	successCount := 0
+	members := make([][]int, k)
+	for ci, cl := range clusters {
+		members[ci] = cl.personIntIDs
+	}
+	matched := matchClusters(previous, members)
+
+	// A matched cluster keeps its community's ID; the others get unused ones.
+	usedKeys := map[string]bool{}
+	for _, p := range previous {
+		usedKeys[p.key] = true
+	}
+	nextKey := 0
+	newKey := func() string {
+		for usedKeys[fmt.Sprintf("cluster_%d", nextKey)] {
+			nextKey++
+		}
+		usedKeys[fmt.Sprintf("cluster_%d", nextKey)] = true
+		return fmt.Sprintf("cluster_%d", nextKey)
+	}
+
 	for ci, cl := range clusters {
 		if len(cl.personIntIDs) == 0 {
 			continue
 		}
-		communityID := fmt.Sprintf("cluster_%d", ci)
-
-		skills, _ := cd.loadTopSkills(ctx, cl.personIntIDs, 15)
-		positions, _ := cd.loadCurrentPositions(ctx, cl.personIntIDs, 5)
+		prev := matched[ci]
 
-		title, summary, err := cd.generateCommunityProfile(skills, positions)
-		if err != nil {
-			log.Printf("[CommunityDetect] cluster %d: LLM failed (%v) — fallback", ci, err)
-			n := 3
-			if len(skills) < n {
-				n = len(skills)
-			}
-			title = fmt.Sprintf("Cluster %d", ci)
-			if n > 0 {
-				title = strings.Join(skills[:n], ", ") + " Professionals"
-			}
-			summary = fmt.Sprintf("A group of %d professionals with shared technical skills.", len(cl.personIntIDs))
+		centroid := centroids[ci]
+		strengths := make([]float64, len(cl.personIntIDs))
+		cohesion := 0.0
+		for j := range cl.personIntIDs {
+			strengths[j] = cdCosineSimilarity(cl.embeddings[j], centroid)
+			cohesion += strengths[j]
 		}
+		cohesion /= float64(len(strengths))
 
-		log.Printf("[CommunityDetect] cluster %d (%d members): %q", ci, len(cl.personIntIDs), title)
-
-		var embeddingJSON string
-		summaryEmb, embErr := cd.embeddingService.GenerateEmbedding(ctx, title+" "+summary)
-		if embErr != nil {
-			log.Printf("[CommunityDetect] cluster %d: embed failed (non-fatal): %v", ci, embErr)
+		var change *CommunityChange
+		communityID := ""
+		if prev == nil {
+			communityID = newKey()
+			change = &CommunityChange{Key: communityID, Change: CommunityCreated, Size: len(cl.personIntIDs), Churn: 1, Cohesion: roundDrift(cohesion)}
 		} else {
-			b, _ := json.Marshal(summaryEmb)
-			embeddingJSON = string(b)
+			communityID = prev.key
+			churn, reasons := cd.drift.drift(prev, cl.personIntIDs, cohesion)
+			if len(reasons) > 0 {
+				change = &CommunityChange{
+					Key:              communityID,
+					Change:           CommunityDrifted,
+					Reasons:          reasons,
+					PreviousTitle:    prev.title,
+					Size:             len(cl.personIntIDs),
+					PreviousSize:     len(prev.members),
+					Churn:            roundDrift(churn),
+					Cohesion:         roundDrift(cohesion),
+					PreviousCohesion: roundDrift(prev.cohesion),
+				}
+			}
 		}
 
 		var gcID int
-		var upsertErr error
-		if embeddingJSON != "" {
-			upsertErr = cd.db.QueryRowContext(ctx, `
-				INSERT INTO graph_communities (level, community_id, title, summary, node_count, embedding, updated_at)
-				VALUES ($1, $2, $3, $4, $5, $6::vector, NOW())
-				ON CONFLICT (level, community_id) DO UPDATE
-				  SET title = EXCLUDED.title,
-				      summary = EXCLUDED.summary,
-				      node_count = EXCLUDED.node_count,
-				      embedding = EXCLUDED.embedding,
-				      updated_at = NOW()
-				RETURNING id
-			`, level, communityID, title, summary, len(cl.personIntIDs), embeddingJSON).Scan(&gcID)
+		if change == nil && prev.title != "" && prev.summary != "" && prev.hasEmbedding {
+			// Not drifted: the title, summary and embedding still hold.
+			_, err := cd.db.ExecContext(ctx, `
+				UPDATE graph_communities SET node_count = $1, updated_at = NOW() WHERE id = $2
+			`, len(cl.personIntIDs), prev.id)
+			if err != nil {
+				log.Printf("[CommunityDetect] cluster %d: update failed: %v", ci, err)
+				continue
+			}
+			gcID = prev.id
+			result.Unchanged++
+			log.Printf("[CommunityDetect] cluster %d (%d members): %q unchanged", ci, len(cl.personIntIDs), prev.title)
 		} else {
-			upsertErr = cd.db.QueryRowContext(ctx, `
-				INSERT INTO graph_communities (level, community_id, title, summary, node_count, updated_at)
-				VALUES ($1, $2, $3, $4, $5, NOW())
-				ON CONFLICT (level, community_id) DO UPDATE
-				  SET title = EXCLUDED.title,
-				      summary = EXCLUDED.summary,
-				      node_count = EXCLUDED.node_count,
-				      updated_at = NOW()
-				RETURNING id
-			`, level, communityID, title, summary, len(cl.personIntIDs)).Scan(&gcID)
-		}
-		if upsertErr != nil {
-			log.Printf("[CommunityDetect] cluster %d: upsert failed: %v", ci, upsertErr)
-			continue
+			skills, _ := cd.loadTopSkills(ctx, cl.personIntIDs, 15)
+			positions, _ := cd.loadCurrentPositions(ctx, cl.personIntIDs, 5)
+
+			title, summary, err := cd.generateCommunityProfile(ctx, skills, positions)
+			result.Resummarized++
+			switch {
+			case err != nil && prev != nil && prev.title != "" && prev.summary != "":
+				log.Printf("[CommunityDetect] cluster %d: LLM failed (%v) — keeping previous summary", ci, err)
+				title, summary = prev.title, prev.summary
+			case err != nil:
+				log.Printf("[CommunityDetect] cluster %d: LLM failed (%v) — fallback", ci, err)
+				n := 3
+				if len(skills) < n {
+					n = len(skills)
+				}
+				title = fmt.Sprintf("Cluster %d", ci)
+				if n > 0 {
+					title = strings.Join(skills[:n], ", ") + " Professionals"
+				}
+				summary = fmt.Sprintf("A group of %d professionals with shared technical skills.", len(cl.personIntIDs))
+			default:
+				if change != nil {
+					change.Resummarized = true
+				}
+			}
+
+			log.Printf("[CommunityDetect] cluster %d (%d members): %q", ci, len(cl.personIntIDs), title)
+
+			var embeddingJSON string
+			summaryEmb, embErr := cd.embeddingService.GenerateEmbedding(ctx, title+" "+summary)
+			if embErr != nil {
+				log.Printf("[CommunityDetect] cluster %d: embed failed (non-fatal): %v", ci, embErr)
+			} else {
+				b, _ := json.Marshal(summaryEmb)
+				embeddingJSON = string(b)
+			}
+
+			var upsertErr error
+			if embeddingJSON != "" {
+				upsertErr = cd.db.QueryRowContext(ctx, `
+					INSERT INTO graph_communities (level, community_id, title, summary, node_count, embedding, org_id, updated_at)
+					VALUES ($1, $2, $3, $4, $5, $6::vector, $7, NOW())
+					ON CONFLICT (org_id, level, community_id) DO UPDATE
+					  SET title = EXCLUDED.title,
+					      summary = EXCLUDED.summary,
+					      node_count = EXCLUDED.node_count,
+					      embedding = EXCLUDED.embedding,
+					      updated_at = NOW()
+					RETURNING id
+				`, level, communityID, title, summary, len(cl.personIntIDs), embeddingJSON, orgID).Scan(&gcID)
+			} else {
+				upsertErr = cd.db.QueryRowContext(ctx, `
+					INSERT INTO graph_communities (level, community_id, title, summary, node_count, org_id, updated_at)
+					VALUES ($1, $2, $3, $4, $5, $6, NOW())
+					ON CONFLICT (org_id, level, community_id) DO UPDATE
+					  SET title = EXCLUDED.title,
+					      summary = EXCLUDED.summary,
+					      node_count = EXCLUDED.node_count,
+					      updated_at = NOW()
+					RETURNING id
+				`, level, communityID, title, summary, len(cl.personIntIDs), orgID).Scan(&gcID)
+			}
+			if upsertErr != nil {
+				log.Printf("[CommunityDetect] cluster %d: upsert failed: %v", ci, upsertErr)
+				continue
+			}
+			if change != nil {
+				change.Title = title
+			}
+
+			if ci < k-1 {
+				time.Sleep(300 * time.Millisecond)
+			}
 		}
 
-		centroid := centroids[ci]
 		for j, nodeIntID := range cl.personIntIDs {
-			strength := cdCosineSimilarity(cl.embeddings[j], centroid)
 			_, err := cd.db.ExecContext(ctx, `
 				INSERT INTO community_members (community_id, node_id, membership_strength)
 				VALUES ($1, $2, $3)
 				ON CONFLICT (community_id, node_id) DO UPDATE
 				  SET membership_strength = EXCLUDED.membership_strength
-			`, gcID, nodeIntID, strength)
+			`, gcID, nodeIntID, strengths[j])
 			if err != nil {
 				log.Printf("[CommunityDetect] cluster %d member %d: insert failed: %v", ci, nodeIntID, err)
 			}
 		}
 
-		successCount++
-		if ci < k-1 {
-			time.Sleep(300 * time.Millisecond)
+		if change != nil {
+			change.CommunityID = gcID
+			result.Changes = append(result.Changes, *change)
+		}
+		result.Communities++
+	}
+
+	// Communities no cluster matched have no members left.
+	taken := map[int]bool{}
+	for _, p := range matched {
+		if p != nil {
+			taken[p.id] = true
+		}
+	}
+	for _, p := range previous {
+		if taken[p.id] || len(p.members) == 0 {
+			continue
 		}
+		if _, err := cd.db.ExecContext(ctx, `
+			UPDATE graph_communities SET node_count = 0, updated_at = NOW() WHERE id = $1
+		`, p.id); err != nil {
+			log.Printf("[CommunityDetect] community %d: dissolve failed: %v", p.id, err)
+			continue
+		}
+		result.Changes = append(result.Changes, CommunityChange{
+			CommunityID:      p.id,
+			Key:              p.key,
+			Change:           CommunityDissolved,
+			PreviousTitle:    p.title,
+			PreviousSize:     len(p.members),
+			Churn:            1,
+			PreviousCohesion: roundDrift(p.cohesion),
+		})
+	}
+
+	if err := RefreshCommunityCentroids(ctx, cd.db, level); err != nil {
+		log.Printf("[CommunityDetect] Failed to refresh centroids (non-fatal): %v", err)
 	}
+	if err := cd.recordCommunityChanges(ctx, level, result.Changes); err != nil {
+		log.Printf("[CommunityDetect] Failed to record changes (non-fatal): %v", err)
+	}
+
+	log.Printf("[CommunityDetect] Done: %d/%d communities written, %d unchanged, %d changes",
+		result.Communities, k, result.Unchanged, len(result.Changes))
+	return result, nil
+}
 
-	log.Printf("[CommunityDetect] Done: %d/%d communities written", successCount, k)
-	return nil
+// roundDrift rounds a drift measure for the changelog.
+func roundDrift(f float64) float64 {
+	return math.Round(f*1000) / 1000
 }
 
 func (cd *CommunityDetector) loadPersonEmbeddings(ctx context.Context) ([]communityPerson, error) {
 	rows, err := cd.db.QueryContext(ctx, `
 		SELECT id, node_id, embedding::text
 		FROM graph_nodes
-		WHERE node_type = 'person' AND embedding IS NOT NULL
+		WHERE node_type = 'person' AND embedding IS NOT NULL AND deleted_at IS NULL
+		  AND org_id = $1
 		ORDER BY id
-	`)
+	`, tenant.OrgID(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -302,7 +449,7 @@ func (cd *CommunityDetector) loadCurrentPositions(ctx context.Context, personInt
 	return positions, rows.Err()
 }
 
-func (cd *CommunityDetector) generateCommunityProfile(skills []string, positions []string) (string, string, error) {
+func (cd *CommunityDetector) generateCommunityProfile(ctx context.Context, skills []string, positions []string) (string, string, error) {
 	skillStr := strings.Join(skills, ", ")
 	if skillStr == "" {
 		skillStr = "(no skills data)"
@@ -325,7 +472,7 @@ Generate a community profile in JSON format:
 
 Respond with valid JSON only. No markdown.`, skillStr, posStr)
 
-	raw, err := cd.llm.Generate(prompt)
+	raw, err := cd.llm.Generate(ctx, prompt)
 	if err != nil {
 		return "", "", err
 	}
@@ -0,0 +1,212 @@
+package graphrag
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+
+	"cv-search/internal/tenant"
+)
+
+// ─── Community drift ─────────────────────────────────────────────────────────
+
+// Every detection run clusters from scratch, so its clusters are matched to
+// the communities of the previous run by shared members. A matched cluster
+// keeps the community's ID, and its LLM title and summary unless it has
+// drifted past DriftThresholds; only new and drifted communities go back to
+// the LLM. What changed is kept in community_changes.
+
+// DriftThresholds decide when a re-detected community has changed enough to
+// be flagged and re-summarized: past any of them, it has drifted.
+type DriftThresholds struct {
+	SizeChange   float64 // |new - old| / old member count
+	Churn        float64 // 1 - Jaccard similarity of the old and new members
+	CohesionDrop float64 // fall of the mean membership strength (cosine similarity to the centroid)
+}
+
+// DefaultDriftThresholds flags a community with a quarter more or fewer
+// members, 30% of its membership replaced, or cohesion down by 0.05.
+func DefaultDriftThresholds() DriftThresholds {
+	return DriftThresholds{SizeChange: 0.25, Churn: 0.30, CohesionDrop: 0.05}
+}
+
+// Kinds of community change.
+const (
+	CommunityCreated   = "created"
+	CommunityDrifted   = "drifted"
+	CommunityDissolved = "dissolved" // no cluster of the run matched it; it has no members left
+)
+
+// Reasons a community drifted.
+const (
+	DriftSize     = "size"
+	DriftChurn    = "churn"
+	DriftCohesion = "cohesion"
+)
+
+// CommunityChange is an entry of a detection run's changelog.
+type CommunityChange struct {
+	CommunityID      int      `json:"community_id"` // graph_communities.id
+	Key              string   `json:"key"`          // graph_communities.community_id
+	Change           string   `json:"change"`       // created | drifted | dissolved
+	Reasons          []string `json:"reasons,omitempty"`
+	Title            string   `json:"title,omitempty"`
+	PreviousTitle    string   `json:"previous_title,omitempty"`
+	Size             int      `json:"size"`
+	PreviousSize     int      `json:"previous_size"`
+	Churn            float64  `json:"churn"`
+	Cohesion         float64  `json:"cohesion"`
+	PreviousCohesion float64  `json:"previous_cohesion"`
+	Resummarized     bool     `json:"resummarized"` // got a new LLM title and summary
+}
+
+// CommunityDetection is what a detection run did.
+type CommunityDetection struct {
+	Level        int               `json:"level"`
+	Communities  int               `json:"communities"`  // written
+	Unchanged    int               `json:"unchanged"`    // kept their title and summary
+	Resummarized int               `json:"resummarized"` // LLM calls made
+	Changes      []CommunityChange `json:"changes"`
+}
+
+// previousCommunity is a community of a level before a detection run.
+type previousCommunity struct {
+	id           int
+	key          string
+	title        string
+	summary      string
+	hasEmbedding bool
+	members      map[int]bool // graph_nodes.id
+	cohesion     float64
+}
+
+// loadPreviousCommunities returns ctx's organization's communities of level
+// with their live members, dissolved ones (no members) included.
+func (cd *CommunityDetector) loadPreviousCommunities(ctx context.Context, level int) ([]*previousCommunity, error) {
+	rows, err := cd.db.QueryContext(ctx, `
+		SELECT c.id, c.community_id, COALESCE(c.title, ''), COALESCE(c.summary, ''), c.embedding IS NOT NULL,
+		       m.node_id, m.membership_strength
+		FROM graph_communities c
+		LEFT JOIN (
+			SELECT cm.community_id, cm.node_id, COALESCE(cm.membership_strength, 1.0) AS membership_strength
+			FROM community_members cm
+			JOIN graph_nodes gn ON gn.id = cm.node_id AND gn.deleted_at IS NULL
+		) m ON m.community_id = c.id
+		WHERE c.level = $1 AND c.org_id = $2
+		ORDER BY c.id
+	`, level, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("load communities of level %d: %w", level, err)
+	}
+	defer rows.Close()
+
+	var out []*previousCommunity
+	for rows.Next() {
+		var p previousCommunity
+		var nodeID sql.NullInt64
+		var strength sql.NullFloat64
+		if err := rows.Scan(&p.id, &p.key, &p.title, &p.summary, &p.hasEmbedding, &nodeID, &strength); err != nil {
+			return nil, fmt.Errorf("scan community: %w", err)
+		}
+		if len(out) == 0 || out[len(out)-1].id != p.id {
+			p.members = map[int]bool{}
+			out = append(out, &p)
+		}
+		if nodeID.Valid {
+			last := out[len(out)-1]
+			last.members[int(nodeID.Int64)] = true
+			last.cohesion += strength.Float64
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("load communities of level %d: %w", level, err)
+	}
+	for _, p := range out {
+		if len(p.members) > 0 {
+			p.cohesion /= float64(len(p.members))
+		}
+	}
+	return out, nil
+}
+
+// matchClusters pairs each cluster (members by graph_nodes.id) with the
+// previous community it shares the most members with, biggest overlaps
+// first; nil for a cluster that shares none with a still unpaired one.
+func matchClusters(previous []*previousCommunity, clusters [][]int) []*previousCommunity {
+	type pair struct{ cluster, prev, overlap int }
+	var pairs []pair
+	for ci, members := range clusters {
+		for pi, p := range previous {
+			overlap := 0
+			for _, id := range members {
+				if p.members[id] {
+					overlap++
+				}
+			}
+			if overlap > 0 {
+				pairs = append(pairs, pair{ci, pi, overlap})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].overlap > pairs[j].overlap })
+
+	matched := make([]*previousCommunity, len(clusters))
+	taken := make([]bool, len(previous))
+	for _, p := range pairs {
+		if matched[p.cluster] == nil && !taken[p.prev] {
+			matched[p.cluster] = previous[p.prev]
+			taken[p.prev] = true
+		}
+	}
+	return matched
+}
+
+// drift measures how far members, with mean membership strength cohesion,
+// are from previous, and returns the thresholds they passed.
+func (t DriftThresholds) drift(previous *previousCommunity, members []int, cohesion float64) (churn float64, reasons []string) {
+	common := 0
+	for _, id := range members {
+		if previous.members[id] {
+			common++
+		}
+	}
+	if union := len(previous.members) + len(members) - common; union > 0 {
+		churn = 1 - float64(common)/float64(union)
+	}
+
+	old := len(previous.members)
+	if old == 0 || math.Abs(float64(len(members)-old))/float64(old) > t.SizeChange {
+		reasons = append(reasons, DriftSize)
+	}
+	if churn > t.Churn {
+		reasons = append(reasons, DriftChurn)
+	}
+	if old > 0 && previous.cohesion-cohesion > t.CohesionDrop {
+		reasons = append(reasons, DriftCohesion)
+	}
+	return churn, reasons
+}
+
+// recordCommunityChanges appends changes to the changelog of ctx's
+// organization.
+func (cd *CommunityDetector) recordCommunityChanges(ctx context.Context, level int, changes []CommunityChange) error {
+	for _, c := range changes {
+		reasons := c.Reasons
+		if reasons == nil {
+			reasons = []string{}
+		}
+		_, err := cd.db.ExecContext(ctx, `
+			INSERT INTO community_changes
+				(org_id, community_id, level, change, reasons, title, previous_title,
+				 size, previous_size, churn, cohesion, previous_cohesion, resummarized)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		`, tenant.OrgID(ctx), c.CommunityID, level, c.Change, reasons, c.Title, c.PreviousTitle,
+			c.Size, c.PreviousSize, c.Churn, c.Cohesion, c.PreviousCohesion, c.Resummarized)
+		if err != nil {
+			return fmt.Errorf("record change of community %d: %w", c.CommunityID, err)
+		}
+	}
+	return nil
+}
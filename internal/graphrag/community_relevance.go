@@ -0,0 +1,114 @@
+package graphrag
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"cv-search/internal/tenant"
+)
+
+// ─── Community relevance ─────────────────────────────────────────────────────
+
+// A detected community has two vectors: embedding, of its LLM title and
+// summary, and centroid_embedding, the mean of its members' node embeddings.
+// The summary says what the LLM made of a sample of skills and titles; the
+// centroid is where the members actually are. A query is scored against both.
+
+// communityCentroidWeight is the centroid's share of a community's relevance;
+// the summary has the rest. A community with one of the two vectors is
+// scored on that one alone.
+const communityCentroidWeight = 0.6
+
+// communityCentroidSQL is the centroid of community c: the mean of its live
+// members' node embeddings, NULL without any.
+const communityCentroidSQL = `(
+	SELECT AVG(gn.embedding)
+	FROM community_members cm
+	JOIN graph_nodes gn ON gn.id = cm.node_id
+	WHERE cm.community_id = c.id AND gn.embedding IS NOT NULL AND gn.deleted_at IS NULL
+)`
+
+// RefreshCommunityCentroids recomputes the centroid embeddings of ctx's
+// organization's communities of level from their live members' embeddings;
+// a community without any gets none.
+func RefreshCommunityCentroids(ctx context.Context, db *sql.DB, level int) error {
+	_, err := db.ExecContext(ctx, `
+		UPDATE graph_communities c SET centroid_embedding = `+communityCentroidSQL+`
+		WHERE c.level = $1 AND c.org_id = $2
+	`, level, tenant.OrgID(ctx))
+	if err != nil {
+		return fmt.Errorf("refresh centroids of level %d: %w", level, err)
+	}
+	return nil
+}
+
+// communityRelevance combines a community's centroid and summary similarity
+// to a query; invalid ones are missing.
+func communityRelevance(centroid, summary sql.NullFloat64) float64 {
+	switch {
+	case centroid.Valid && summary.Valid:
+		return communityCentroidWeight*centroid.Float64 + (1-communityCentroidWeight)*summary.Float64
+	case centroid.Valid:
+		return centroid.Float64
+	}
+	return summary.Float64
+}
+
+// rankCommunities returns ctx's organization's communities of level with
+// members, most relevant to queryEmbedding first, at most limit.
+func rankCommunities(ctx context.Context, db *sql.DB, queryEmbedding []float32, level, limit int) ([]CommunityInsight, error) {
+	embeddingJSON, err := json.Marshal(queryEmbedding)
+	if err != nil {
+		return nil, err
+	}
+
+	// A centroid of other dimensions (computed before an embedding model
+	// swap, until the next detection) is left out.
+	rows, err := db.QueryContext(ctx, `
+		SELECT community_id, COALESCE(title, ''), COALESCE(summary, ''), COALESCE(node_count, 0),
+		       CASE WHEN centroid_embedding IS NOT NULL AND vector_dims(centroid_embedding) = vector_dims($1::vector)
+		            THEN 1 - (centroid_embedding <=> $1::vector) END,
+		       CASE WHEN embedding IS NOT NULL THEN 1 - (embedding <=> $1::vector) END
+		FROM graph_communities
+		WHERE (embedding IS NOT NULL OR centroid_embedding IS NOT NULL)
+		  AND COALESCE(node_count, 0) > 0
+		  AND level = $2
+		  AND org_id = $3
+	`, string(embeddingJSON), level, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var communities []CommunityInsight
+	for rows.Next() {
+		var c CommunityInsight
+		var centroid, summary sql.NullFloat64
+		if err := rows.Scan(&c.CommunityID, &c.Title, &c.Summary, &c.MemberCount, &centroid, &summary); err != nil {
+			continue
+		}
+		if !centroid.Valid && !summary.Valid {
+			continue
+		}
+		c.Relevance = communityRelevance(centroid, summary)
+		if centroid.Valid {
+			c.CentroidSimilarity = centroid.Float64
+		}
+		if summary.Valid {
+			c.SummarySimilarity = summary.Float64
+		}
+		communities = append(communities, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(communities, func(i, j int) bool { return communities[i].Relevance > communities[j].Relevance })
+	if len(communities) > limit {
+		communities = communities[:limit]
+	}
+	return communities, nil
+}
@@ -0,0 +1,224 @@
+package graphrag
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"cv-search/internal/tenant"
+)
+
+// ─── Criteria embeddings ─────────────────────────────────────────────────────
+//
+// The graph source found a criteria skill only by its exact node key
+// ("skill_Go"), so "Golang", "ReactJS" or "k8s" missed the people who have
+// it. EmbedCriteria embeds a query's criteria, each skill and the criteria
+// as a whole, in one request and caches the vectors (criteria repeat across
+// searches far more than queries do). Each skill then also matches the
+// organization's skill nodes close to it, in the graph query and the
+// post-fusion skill filter, and the whole criteria pick communities when the
+// query's own embedding matches none.
+
+// Criteria embedding cache and matching limits.
+const (
+	criteriaEmbeddingTTL     = 24 * time.Hour
+	criteriaEmbeddingMaxSize = 5000
+
+	// skillNodeDistanceThreshold is the largest cosine distance at which a
+	// skill node matches a criteria skill, kept tight: a spelling variant
+	// of the same skill, not a related one ("Java" isn't "JavaScript").
+	skillNodeDistanceThreshold = 0.2
+	skillNodeMatches           = 3 // most skill nodes a criteria skill matches
+)
+
+// embeddingCache caches embeddings of short texts by model and text.
+type embeddingCache struct {
+	mu      sync.Mutex
+	entries map[string]embeddingCacheEntry
+}
+
+type embeddingCacheEntry struct {
+	embedding []float32
+	expires   time.Time
+}
+
+func newEmbeddingCache() *embeddingCache {
+	return &embeddingCache{entries: make(map[string]embeddingCacheEntry)}
+}
+
+func (c *embeddingCache) get(model, text string) ([]float32, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[model+"\x00"+text]
+	if !ok || time.Now().After(e.expires) {
+		return nil, false
+	}
+	return e.embedding, true
+}
+
+// set stores an embedding, first evicting expired entries when the cache is
+// full and then, if it still is, arbitrary ones down to 75%.
+func (c *embeddingCache) set(model, text string, embedding []float32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if len(c.entries) >= criteriaEmbeddingMaxSize {
+		for k, e := range c.entries {
+			if now.After(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		for k := range c.entries {
+			if len(c.entries) < criteriaEmbeddingMaxSize*3/4 {
+				break
+			}
+			delete(c.entries, k)
+		}
+	}
+	c.entries[model+"\x00"+text] = embeddingCacheEntry{embedding: embedding, expires: now.Add(criteriaEmbeddingTTL)}
+}
+
+// cachedEmbeddings embeds texts, only those not in the cache, in one
+// request.
+func (s *EmbeddingService) cachedEmbeddings(ctx context.Context, texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+	var missing []string
+	var missingAt []int
+	for i, text := range texts {
+		if e, ok := s.criteriaCache.get(s.model, text); ok {
+			embeddings[i] = e
+			continue
+		}
+		missing = append(missing, text)
+		missingAt = append(missingAt, i)
+	}
+	if len(missing) == 0 {
+		return embeddings, nil
+	}
+	generated, err := s.GenerateEmbeddings(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	for j, e := range generated {
+		s.criteriaCache.set(s.model, missing[j], e)
+		embeddings[missingAt[j]] = e
+	}
+	return embeddings, nil
+}
+
+// criteriaText is criteria as text for embedding, in the shape of a person
+// node's text (nodeToText); "" when it has no positions or skills.
+func criteriaText(c *SearchCriteria) string {
+	if len(c.Positions) == 0 && len(c.Skills) == 0 {
+		return ""
+	}
+	var parts []string
+	if len(c.Positions) > 0 {
+		parts = append(parts, strings.Join(c.Positions, " / "))
+	}
+	if c.Seniority != "" {
+		parts = append(parts, "Seniority: "+c.Seniority)
+	}
+	if len(c.Skills) > 0 {
+		parts = append(parts, "Skills: "+strings.Join(c.Skills, ", "))
+	}
+	return strings.Join(parts, ". ")
+}
+
+// EmbedCriteria embeds criteria's skills and the criteria as a whole and
+// matches each skill against ctx's organization's skill nodes; the graph
+// query, the skill filter and community selection then use them. On error
+// criteria is left as it was, matched by exact skill names.
+func (s *EmbeddingService) EmbedCriteria(ctx context.Context, criteria *SearchCriteria) error {
+	text := criteriaText(criteria)
+	if text == "" {
+		return nil
+	}
+	texts := []string{text}
+	for _, skill := range criteria.Skills {
+		texts = append(texts, skill+" skill") // like a skill node's text
+	}
+	embeddings, err := s.cachedEmbeddings(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("embed criteria: %w", err)
+	}
+
+	vectors := make([]string, len(criteria.Skills))
+	for i, e := range embeddings[1:] {
+		v, _ := json.Marshal(e)
+		vectors[i] = string(v)
+	}
+	skillNodes := make(map[string][]string, len(criteria.Skills))
+	if len(vectors) > 0 {
+		rows, err := s.db.QueryContext(ctx, `
+			SELECT q.i, n.node_id
+			FROM unnest($1::text[]) WITH ORDINALITY AS q(vec, i)
+			CROSS JOIN LATERAL (
+				SELECT node_id
+				FROM graph_nodes
+				WHERE node_type = 'skill'
+				  AND org_id = $2
+				  AND deleted_at IS NULL
+				  AND embedding IS NOT NULL
+				  AND embedding <=> q.vec::vector <= $3
+				ORDER BY embedding <=> q.vec::vector
+				LIMIT $4
+			) n
+		`, vectors, tenant.OrgID(ctx), skillNodeDistanceThreshold, skillNodeMatches)
+		if err != nil {
+			return fmt.Errorf("match skill nodes: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var i int
+			var nodeID string
+			if err := rows.Scan(&i, &nodeID); err != nil {
+				return fmt.Errorf("scan skill node match: %w", err)
+			}
+			skill := criteria.Skills[i-1]
+			skillNodes[skill] = append(skillNodes[skill], nodeID)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("match skill nodes: %w", err)
+		}
+	}
+
+	criteria.embedding = embeddings[0]
+	criteria.skillNodes = skillNodes
+	for skill, nodeIDs := range skillNodes {
+		log.Printf("[CriteriaEmbed] skill %q → %v", skill, nodeIDs)
+	}
+	return nil
+}
+
+// skillNodeIDs returns the skill node keys skill matches: its own and those
+// EmbedCriteria found close to it.
+func (c *SearchCriteria) skillNodeIDs(skill string) []string {
+	ids := []string{"skill_" + skill}
+	for _, id := range c.skillNodes[skill] {
+		if id != ids[0] {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// skillKeySet returns the name keys (aliasKey) of the skills a candidate
+// may have to match criteria's skills: theirs, their aliases' and those of
+// the skill nodes close to them.
+func (c *SearchCriteria) skillKeySet() map[string]bool {
+	keys := make(map[string]bool, len(c.Skills))
+	for _, skill := range c.Skills {
+		for _, k := range c.nameKeys(c.skillKeys, skill) {
+			keys[k] = true
+		}
+		for _, id := range c.skillNodeIDs(skill) {
+			keys[aliasKey(strings.TrimPrefix(id, "skill_"))] = true
+		}
+	}
+	return keys
+}
@@ -0,0 +1,97 @@
+package graphrag
+
+import "strings"
+
+// diversifyMMR reorders a final ranking with maximal marginal relevance so the
+// top of the slate isn't filled with near-duplicates from the same community
+// or company. lambda in (0,1] weights relevance against diversity: 1 keeps the
+// relevance order, lower values push candidates that share communities or
+// employers with already-selected ones further down. lambda <= 0 or > 1
+// disables the pass.
+//
+// Relevance is LLMScore normalised to [0,1]; similarity is the Jaccard overlap
+// of each candidate's communities and companies. Returns a new slice with
+// Rank renumbered; the input (which may be a cached slice) is not modified.
+func diversifyMMR(candidates []FusedCandidate, lambda float64) []FusedCandidate {
+	if lambda <= 0 || lambda >= 1 || len(candidates) < 3 {
+		return candidates
+	}
+
+	maxScore := 0.0
+	for _, c := range candidates {
+		if c.LLMScore > maxScore {
+			maxScore = c.LLMScore
+		}
+	}
+	if maxScore == 0 {
+		return candidates
+	}
+
+	features := make([]map[string]bool, len(candidates))
+	for i, c := range candidates {
+		features[i] = diversityFeatures(c)
+	}
+
+	remaining := make([]int, len(candidates))
+	for i := range remaining {
+		remaining[i] = i
+	}
+	selected := make([]int, 0, len(candidates))
+
+	for len(remaining) > 0 {
+		bestPos, bestMMR := 0, -1e9
+		for pos, idx := range remaining {
+			maxSim := 0.0
+			for _, s := range selected {
+				if sim := jaccard(features[idx], features[s]); sim > maxSim {
+					maxSim = sim
+				}
+			}
+			mmr := lambda*(candidates[idx].LLMScore/maxScore) - (1-lambda)*maxSim
+			if mmr > bestMMR {
+				bestPos, bestMMR = pos, mmr
+			}
+		}
+		selected = append(selected, remaining[bestPos])
+		remaining = append(remaining[:bestPos], remaining[bestPos+1:]...)
+	}
+
+	out := make([]FusedCandidate, len(selected))
+	for i, idx := range selected {
+		out[i] = candidates[idx]
+		out[i].Rank = i + 1
+	}
+	return out
+}
+
+func diversityFeatures(c FusedCandidate) map[string]bool {
+	f := make(map[string]bool, len(c.Communities)+len(c.Companies)+1)
+	for _, comm := range c.Communities {
+		f["community:"+strings.ToLower(comm)] = true
+	}
+	if c.ComputedCommunityID != "" {
+		f["computed:"+c.ComputedCommunityID] = true
+	}
+	if c.Community != "" {
+		f["community:"+strings.ToLower(c.Community)] = true
+	}
+	for _, comp := range c.Companies {
+		if comp.Name != "" {
+			f["company:"+strings.ToLower(comp.Name)] = true
+		}
+	}
+	return f
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	inter := 0
+	for k := range a {
+		if b[k] {
+			inter++
+		}
+	}
+	return float64(inter) / float64(len(a)+len(b)-inter)
+}
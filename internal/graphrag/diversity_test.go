@@ -0,0 +1,53 @@
+package graphrag
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestDiversifyMMRAfterRerankFallback covers the final ranking when
+// reranking failed or ran out of its budget: no LLM scores, so candidates
+// keep their fusion scores, and the MMR pass still runs over the result.
+func TestDiversifyMMRAfterRerankFallback(t *testing.T) {
+	atCompany := func(id, name, company string, fusion float64, tags ...string) FusedCandidate {
+		c := FusedCandidate{PersonID: id, Name: name, FusionScore: fusion, Tags: tags}
+		if company != "" {
+			c.Companies = []CompanyNode{{Name: company}}
+		}
+		return c
+	}
+	tests := []struct {
+		name      string
+		lambda    float64
+		tagBoosts map[string]float64
+		want      []string
+	}{
+		{name: "mmr disabled keeps fusion order", lambda: 0, want: []string{"a", "b", "c"}},
+		{name: "relevance only", lambda: 1, want: []string{"a", "b", "c"}},
+		{name: "same employer pushed down", lambda: 0.5, want: []string{"a", "c", "b"}},
+		{name: "tag boost before mmr", lambda: 0.5, tagBoosts: map[string]float64{"shortlisted": 2}, want: []string{"c", "a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidates := []FusedCandidate{
+				atCompany("a", "Ada", "Acme", 0.9),
+				atCompany("b", "Bob", "Acme", 0.85),
+				atCompany("c", "Cem", "Globex", 0.8, "shortlisted"),
+				atCompany("d", "", "Initech", 0.95), // no name: dropped
+			}
+			ranked := rankCandidates(candidates, nil, tt.tagBoosts)
+			got := diversifyMMR(ranked, tt.lambda)
+			if ids := personIDs(got); !slices.Equal(ids, tt.want) {
+				t.Fatalf("ranking = %v, want %v", ids, tt.want)
+			}
+			for i, c := range got {
+				if c.Rank != i+1 {
+					t.Errorf("%s: Rank = %d, want %d", c.PersonID, c.Rank, i+1)
+				}
+				if c.LLMScore == 0 {
+					t.Errorf("%s: LLMScore not set from the fusion score", c.PersonID)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package graphrag
+
+import (
+	"context"
+	"fmt"
+)
+
+// doctorSampleSize caps how many offending IDs a GraphIssue lists.
+const doctorSampleSize = 20
+
+// GraphIssue is one kind of inconsistency found by CheckGraph.
+type GraphIssue struct {
+	Count  int      `json:"count"`
+	Fixed  int      `json:"fixed"`
+	Sample []string `json:"sample,omitempty"` // node_ids, or edge ids for edge issues
+}
+
+// GraphCheckReport is the result of a CheckGraph pass.
+type GraphCheckReport struct {
+	DanglingEdges       GraphIssue     `json:"dangling_edges"`
+	DuplicateEdges      GraphIssue     `json:"duplicate_edges"`
+	OrphanNodes         GraphIssue     `json:"orphan_nodes"`
+	PersonsWithoutCV    GraphIssue     `json:"persons_without_cv"`
+	MissingEmbeddings   GraphIssue     `json:"missing_embeddings"`
+	EmbeddingsByType    map[string]int `json:"missing_embeddings_by_type"`
+	MalformedProperties GraphIssue     `json:"malformed_properties"`
+}
+
+// Total is the number of issues found, fixed or not.
+func (r *GraphCheckReport) Total() int {
+	return r.DanglingEdges.Count + r.DuplicateEdges.Count + r.OrphanNodes.Count +
+		r.PersonsWithoutCV.Count + r.MissingEmbeddings.Count + r.MalformedProperties.Count
+}
+
+const (
+	// Edges that lost an endpoint. Edges to soft-deleted nodes are kept on
+	// purpose (merge undo, anonymised stats) and aren't counted.
+	danglingEdgesQuery = `
+		SELECT id::text FROM graph_edges
+		WHERE source_node_id IS NULL OR target_node_id IS NULL
+		ORDER BY id`
+	// Every copy of a (source, target, type) edge but the oldest.
+	duplicateEdgesQuery = `
+		SELECT id::text FROM (
+			SELECT id, ROW_NUMBER() OVER (PARTITION BY source_node_id, target_node_id, edge_type ORDER BY id) AS rn
+			FROM graph_edges
+			WHERE source_node_id IS NOT NULL AND target_node_id IS NOT NULL
+		) d
+		WHERE rn > 1
+		ORDER BY id`
+	// Live skill/company/... nodes nothing points to any more.
+	orphanNodesCondition = `
+		n.deleted_at IS NULL AND n.node_type <> 'person'
+		AND NOT EXISTS (SELECT 1 FROM graph_edges e WHERE e.source_node_id = n.id OR e.target_node_id = n.id)`
+	// Live person nodes with no live CV, by the same linkage the backfill
+	// tool uses: cv_id/cv_file_id, candidate_id or candidates.graph_node_id.
+	personsWithoutCVQuery = `
+		SELECT n.node_id FROM graph_nodes n
+		WHERE n.node_type = 'person' AND n.deleted_at IS NULL
+		  AND NOT EXISTS (
+			SELECT 1 FROM cv_files f
+			WHERE f.deleted_at IS NULL AND (
+			        f.id::text IN (n.properties->>'cv_id', n.properties->>'cv_file_id')
+			     OR f.candidate_id::text = n.properties->>'candidate_id'
+			     OR f.candidate_id IN (SELECT c.id FROM candidates c WHERE c.graph_node_id = n.id)))
+		ORDER BY n.id`
+	missingEmbeddingsQuery = `
+		SELECT node_type, node_id FROM graph_nodes
+		WHERE embedding IS NULL AND deleted_at IS NULL
+		ORDER BY id`
+)
+
+// CheckGraph looks for inconsistencies in graph_nodes/graph_edges. With fix
+// it also repairs the ones that are safe to repair without outside input:
+// dangling and duplicate edges are deleted (the oldest duplicate is kept),
+// orphan nodes are deleted, and malformed properties are normalised as in
+// RepairNodeProperties. Persons without a CV and missing embeddings are only
+// reported; the first need a human decision, the second the embedding
+// service (see EmbeddingService.BatchEmbedAllNodes).
+//
+// Edges are fixed before nodes are counted, so removing a dangling edge can
+// surface a new orphan in the same pass.
+func (g *GraphBuilder) CheckGraph(ctx context.Context, fix bool) (*GraphCheckReport, error) {
+	rep := &GraphCheckReport{EmbeddingsByType: make(map[string]int)}
+	var err error
+
+	if rep.DanglingEdges, err = g.checkIssue(ctx, danglingEdgesQuery); err != nil {
+		return rep, fmt.Errorf("check dangling edges: %w", err)
+	}
+	if fix && rep.DanglingEdges.Count > 0 {
+		if rep.DanglingEdges.Fixed, err = g.execCount(ctx,
+			`DELETE FROM graph_edges WHERE source_node_id IS NULL OR target_node_id IS NULL`,
+		); err != nil {
+			return rep, fmt.Errorf("delete dangling edges: %w", err)
+		}
+	}
+
+	if rep.DuplicateEdges, err = g.checkIssue(ctx, duplicateEdgesQuery); err != nil {
+		return rep, fmt.Errorf("check duplicate edges: %w", err)
+	}
+	if fix && rep.DuplicateEdges.Count > 0 {
+		if rep.DuplicateEdges.Fixed, err = g.execCount(ctx,
+			`DELETE FROM graph_edges WHERE id::text IN (`+duplicateEdgesQuery+`)`,
+		); err != nil {
+			return rep, fmt.Errorf("delete duplicate edges: %w", err)
+		}
+	}
+
+	if rep.OrphanNodes, err = g.checkIssue(ctx,
+		`SELECT n.node_id FROM graph_nodes n WHERE `+orphanNodesCondition+` ORDER BY n.id`,
+	); err != nil {
+		return rep, fmt.Errorf("check orphan nodes: %w", err)
+	}
+	if fix && rep.OrphanNodes.Count > 0 {
+		if rep.OrphanNodes.Fixed, err = g.execCount(ctx,
+			`DELETE FROM graph_nodes n WHERE `+orphanNodesCondition,
+		); err != nil {
+			return rep, fmt.Errorf("delete orphan nodes: %w", err)
+		}
+	}
+
+	if rep.PersonsWithoutCV, err = g.checkIssue(ctx, personsWithoutCVQuery); err != nil {
+		return rep, fmt.Errorf("check persons without CV: %w", err)
+	}
+
+	rows, err := g.db.QueryContext(ctx, missingEmbeddingsQuery)
+	if err != nil {
+		return rep, fmt.Errorf("check missing embeddings: %w", err)
+	}
+	for rows.Next() {
+		var nodeType, nodeID string
+		if err := rows.Scan(&nodeType, &nodeID); err != nil {
+			rows.Close()
+			return rep, fmt.Errorf("scan missing embedding: %w", err)
+		}
+		rep.EmbeddingsByType[nodeType]++
+		rep.MissingEmbeddings.add(nodeID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return rep, fmt.Errorf("check missing embeddings: %w", err)
+	}
+
+	props, err := g.RepairNodeProperties(ctx, !fix)
+	if err != nil {
+		return rep, fmt.Errorf("check node properties: %w", err)
+	}
+	rep.MalformedProperties = GraphIssue{
+		Count:  props.Repaired + len(props.Invalid),
+		Sample: props.Invalid,
+	}
+	if len(rep.MalformedProperties.Sample) > doctorSampleSize {
+		rep.MalformedProperties.Sample = rep.MalformedProperties.Sample[:doctorSampleSize]
+	}
+	if fix {
+		rep.MalformedProperties.Fixed = props.Repaired
+	}
+
+	return rep, nil
+}
+
+func (i *GraphIssue) add(id string) {
+	i.Count++
+	if len(i.Sample) < doctorSampleSize {
+		i.Sample = append(i.Sample, id)
+	}
+}
+
+// checkIssue runs a query returning one ID per offending row.
+func (g *GraphBuilder) checkIssue(ctx context.Context, query string) (GraphIssue, error) {
+	var issue GraphIssue
+	rows, err := g.db.QueryContext(ctx, query)
+	if err != nil {
+		return issue, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return issue, err
+		}
+		issue.add(id)
+	}
+	return issue, rows.Err()
+}
+
+func (g *GraphBuilder) execCount(ctx context.Context, query string) (int, error) {
+	res, err := g.db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
@@ -11,13 +11,31 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"cv-search/internal/llm"
+	"cv-search/internal/tenant"
 )
 
+// DefaultEmbeddingModel is the OpenAI model embeddings are generated with
+// unless EMBEDDING_MODEL selects another. Vectors from different models
+// aren't comparable: switching models means re-embedding everything
+// (cmd/tools/reembed).
+const DefaultEmbeddingModel = "text-embedding-3-small" // 1536 dimensions, cheaper than ada-002
+
+// DefaultOllamaEmbeddingModel is the embedding model used with Ollama
+// (UseOllama) unless EMBEDDING_MODEL selects another.
+const DefaultOllamaEmbeddingModel = "nomic-embed-text" // 768 dimensions
+
 // EmbeddingService generates vector embeddings for semantic search
 type EmbeddingService struct {
 	apiKey     string
 	httpClient *http.Client
 	db         *sql.DB
+	model      string
+	dimensions int    // 0 = the model's default
+	ollamaURL  string // set by UseOllama; empty = OpenAI
+
+	criteriaCache *embeddingCache // EmbedCriteria's texts
 }
 
 func NewEmbeddingService(apiKey string, db *sql.DB) *EmbeddingService {
@@ -26,12 +44,60 @@ func NewEmbeddingService(apiKey string, db *sql.DB) *EmbeddingService {
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		db: db,
+		db:            db,
+		model:         DefaultEmbeddingModel,
+		criteriaCache: newEmbeddingCache(),
+	}
+}
+
+// SetModel selects the embedding model and, for models that support it
+// (text-embedding-3-*), the output dimensions; 0 keeps the model's default.
+// An empty model keeps the current one.
+func (s *EmbeddingService) SetModel(model string, dimensions int) {
+	if model != "" {
+		s.model = model
+	}
+	s.dimensions = dimensions
+}
+
+// Model returns the embedding model in use.
+func (s *EmbeddingService) Model() string {
+	return s.model
+}
+
+// UseOllama generates embeddings with the Ollama server at baseURL
+// (llm.DefaultOllamaURL when empty) instead of OpenAI, for deployments
+// without internet access. apiKey, if any, is sent as a bearer token for an
+// authenticating proxy. The model becomes DefaultOllamaEmbeddingModel
+// unless SetModel picked another one.
+func (s *EmbeddingService) UseOllama(baseURL, apiKey string) {
+	if baseURL == "" {
+		baseURL = llm.DefaultOllamaURL
+	}
+	s.ollamaURL = strings.TrimRight(baseURL, "/")
+	s.apiKey = apiKey
+	s.httpClient.Timeout = 5 * time.Minute // local models, possibly on CPU
+	if s.model == DefaultEmbeddingModel {
+		s.model = DefaultOllamaEmbeddingModel
 	}
 }
 
 // GenerateEmbedding creates a vector embedding for text using Groq
 func (s *EmbeddingService) GenerateEmbedding(ctx context.Context, text string) ([]float32, error) {
+	embeddings, err := s.GenerateEmbeddings(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
+
+// GenerateEmbeddings embeds several texts in one request and returns their
+// embeddings in the same order.
+func (s *EmbeddingService) GenerateEmbeddings(ctx context.Context, texts []string) ([][]float32, error) {
+	if s.ollamaURL != "" {
+		return s.ollamaEmbeddings(ctx, texts)
+	}
+
 	// Note: Groq doesn't have embeddings API yet, so we'll use a workaround
 	// or integrate with OpenAI for embeddings specifically
 
@@ -39,8 +105,11 @@ func (s *EmbeddingService) GenerateEmbedding(ctx context.Context, text string) (
 	url := "https://api.openai.com/v1/embeddings"
 
 	requestBody := map[string]interface{}{
-		"input": text,
-		"model": "text-embedding-3-small", // 1536 dimensions, cheaper than ada-002
+		"input": texts,
+		"model": s.model,
+	}
+	if s.dimensions > 0 {
+		requestBody["dimensions"] = s.dimensions
 	}
 
 	jsonData, err := json.Marshal(requestBody)
@@ -69,6 +138,7 @@ func (s *EmbeddingService) GenerateEmbedding(ctx context.Context, text string) (
 
 	var result struct {
 		Data []struct {
+			Index     int       `json:"index"`
 			Embedding []float32 `json:"embedding"`
 		} `json:"data"`
 	}
@@ -77,15 +147,66 @@ func (s *EmbeddingService) GenerateEmbedding(ctx context.Context, text string) (
 		return nil, err
 	}
 
-	if len(result.Data) == 0 {
-		return nil, fmt.Errorf("no embedding returned")
+	if len(result.Data) != len(texts) {
+		return nil, fmt.Errorf("got %d embeddings for %d inputs", len(result.Data), len(texts))
 	}
 
-	return result.Data[0].Embedding, nil
+	embeddings := make([][]float32, len(texts))
+	for _, d := range result.Data {
+		if d.Index < 0 || d.Index >= len(texts) || embeddings[d.Index] != nil {
+			return nil, fmt.Errorf("unexpected embedding index %d", d.Index)
+		}
+		embeddings[d.Index] = d.Embedding
+	}
+	return embeddings, nil
+}
+
+// ollamaEmbeddings embeds texts with Ollama's /api/embed.
+func (s *EmbeddingService) ollamaEmbeddings(ctx context.Context, texts []string) ([][]float32, error) {
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"model": s.model,
+		"input": texts,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.ollamaURL+"/api/embed", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Ollama connection failed (is Ollama running?): %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Embeddings [][]float32 `json:"embeddings"`
+		Error      string      `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("embedding API error: %d - %w", resp.StatusCode, err)
+	}
+	if result.Error != "" || resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("embedding API error: %d - %s", resp.StatusCode, result.Error)
+	}
+	if len(result.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("got %d embeddings for %d inputs", len(result.Embeddings), len(texts))
+	}
+	return result.Embeddings, nil
 }
 
-// EmbedNode generates and stores embedding for a graph node
+// EmbedNode generates and stores embedding for a graph node of ctx's
+// organization
 func (s *EmbeddingService) EmbedNode(ctx context.Context, nodeID string) error {
+	orgID := tenant.OrgID(ctx)
+
 	// Get node info
 	var nodeType string
 	var properties []byte
@@ -93,8 +214,8 @@ func (s *EmbeddingService) EmbedNode(ctx context.Context, nodeID string) error {
 	err := s.db.QueryRowContext(ctx, `
 		SELECT node_type, properties 
 		FROM graph_nodes 
-		WHERE node_id = $1
-	`, nodeID).Scan(&nodeType, &properties)
+		WHERE node_id = $1 AND org_id = $2
+	`, nodeID, orgID).Scan(&nodeType, &properties)
 
 	if err != nil {
 		return fmt.Errorf("failed to get node: %w", err)
@@ -121,10 +242,60 @@ func (s *EmbeddingService) EmbedNode(ctx context.Context, nodeID string) error {
 	_, err = s.db.ExecContext(ctx, `
 		UPDATE graph_nodes 
 		SET embedding = $1,
-		    embedding_model = 'text-embedding-3-small',
+		    embedding_model = $3,
+		    embedding_created_at = NOW()
+		WHERE node_id = $2 AND org_id = $4
+	`, string(embeddingJSON), nodeID, s.model, orgID)
+
+	return err
+}
+
+// UnembeddedCVChunkIDs returns the chunks of a CV (cv_chunks) that have no
+// embedding yet.
+func (s *EmbeddingService) UnembeddedCVChunkIDs(ctx context.Context, cvFileID int64) ([]int64, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id FROM cv_chunks
+		WHERE cv_file_id = $1 AND embedding IS NULL
+		ORDER BY chunk_index
+	`, cvFileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chunks: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// EmbedCVChunk generates and stores the embedding of a CV text chunk
+func (s *EmbeddingService) EmbedCVChunk(ctx context.Context, chunkID int64) error {
+	var text string
+	err := s.db.QueryRowContext(ctx, `SELECT text FROM cv_chunks WHERE id = $1`, chunkID).Scan(&text)
+	if err != nil {
+		return fmt.Errorf("failed to get chunk: %w", err)
+	}
+
+	embedding, err := s.GenerateEmbedding(ctx, text)
+	if err != nil {
+		return fmt.Errorf("failed to generate embedding: %w", err)
+	}
+
+	embeddingJSON, _ := json.Marshal(embedding)
+
+	_, err = s.db.ExecContext(ctx, `
+		UPDATE cv_chunks
+		SET embedding = $1,
+		    embedding_model = $3,
 		    embedding_created_at = NOW()
-		WHERE node_id = $2
-	`, embeddingJSON, nodeID)
+		WHERE id = $2
+	`, string(embeddingJSON), chunkID, s.model)
 
 	return err
 }
@@ -133,42 +304,45 @@ func (s *EmbeddingService) EmbedNode(ctx context.Context, nodeID string) error {
 func (s *EmbeddingService) nodeToText(nodeType string, props map[string]interface{}) string {
 	switch nodeType {
 	case "person":
-		name := getString(props, "name")
-		position := getString(props, "current_position")
-		seniority := getString(props, "seniority")
-		exp := getString(props, "total_experience_years")
+		p := PersonPropertiesFrom(props)
+		exp := ""
+		if p.TotalExperienceYears != nil {
+			exp = propString(*p.TotalExperienceYears)
+		}
 		return fmt.Sprintf("%s: %s with %s years experience. Seniority: %s",
-			name, position, exp, seniority)
+			p.Name, p.CurrentPosition, exp, p.Seniority)
 
 	case "skill":
-		name := getString(props, "name")
-		proficiency := getString(props, "proficiency")
-		return fmt.Sprintf("%s skill (proficiency: %s)", name, proficiency)
+		sk := SkillPropertiesFrom(props)
+		return fmt.Sprintf("%s skill (proficiency: %s)", sk.Name, sk.Proficiency)
 
 	case "company":
-		name := getString(props, "name")
-		industry := getString(props, "industry")
-		return fmt.Sprintf("%s company in %s industry", name, industry)
+		c := CompanyPropertiesFrom(props)
+		return fmt.Sprintf("%s company in %s industry", c.Name, c.Industry)
 
 	case "education":
-		institution := getString(props, "institution")
-		degree := getString(props, "degree")
-		field := getString(props, "field")
-		return fmt.Sprintf("%s degree in %s from %s", degree, field, institution)
+		e := EducationPropertiesFrom(props)
+		return fmt.Sprintf("%s degree in %s from %s", e.Degree, e.Field, e.Institution)
+
+	case "project":
+		return "Project " + ProjectPropertiesFrom(props).Text()
 
 	default:
 		return fmt.Sprintf("%v", props)
 	}
 }
 
-// BatchEmbedAllNodes generates embeddings for all nodes without embeddings
+// BatchEmbedAllNodes generates embeddings for all of ctx's organization's
+// nodes without embeddings
 func (s *EmbeddingService) BatchEmbedAllNodes(ctx context.Context) error {
 	rows, err := s.db.QueryContext(ctx, `
 		SELECT node_id 
 		FROM graph_nodes 
 		WHERE embedding IS NULL
+		  AND deleted_at IS NULL
+		  AND org_id = $1
 		ORDER BY created_at DESC
-	`)
+	`, tenant.OrgID(ctx))
 	if err != nil {
 		return err
 	}
@@ -224,24 +398,70 @@ func (s *EmbeddingService) SimilaritySearch(ctx context.Context, queryText strin
 }
 
 func (s *EmbeddingService) similaritySearchWithEmbedding(ctx context.Context, queryEmbedding []float32, topK int) ([]string, []float64, error) {
+	return s.vectorSearch(ctx, queryEmbedding, topK, "embedding")
+}
 
+// vectorSearch runs the vector search over column, the live embeddings or
+// the shadow ones (see EmbedShadow), within ctx's organization.
+func (s *EmbeddingService) vectorSearch(ctx context.Context, queryEmbedding []float32, topK int, column string) ([]string, []float64, error) {
 	embeddingJSON, _ := json.Marshal(queryEmbedding)
 
-	// Vector similarity search (only person nodes for hybrid search)
-	query := `
-		SELECT 
-			node_id,
-			1 - (embedding <=> $1::vector) as similarity
-		FROM graph_nodes
-		WHERE embedding IS NOT NULL 
-		  AND node_type = 'person'
+	// Vector similarity search over person nodes, over project nodes
+	// scored as the person who worked on them, so "built payment systems
+	// from scratch" finds the people whose projects say so, and over CV
+	// text chunks scored as the candidate whose CV they're from, which
+	// reaches details deep inside long CVs. A person keeps their best
+	// similarity.
+	query := fmt.Sprintf(`
+		SELECT node_id, MAX(similarity) AS similarity
+		FROM (
+			(SELECT node_id, 1 - (%[1]s <=> $1::vector) AS similarity
+			 FROM graph_nodes p
+			 WHERE %[1]s IS NOT NULL
+			   AND node_type = 'person'
+			   AND deleted_at IS NULL
+			   AND org_id = $3
+			   AND %[2]s
+			 ORDER BY %[1]s <=> $1::vector
+			 LIMIT $2)
+			UNION ALL
+			(SELECT p.node_id, 1 - (pr.%[1]s <=> $1::vector) AS similarity
+			 FROM graph_nodes pr
+			 JOIN graph_edges e ON e.target_node_id = pr.id AND e.edge_type = 'WORKED_ON'
+			 JOIN graph_nodes p ON p.id = e.source_node_id
+			 WHERE pr.%[1]s IS NOT NULL
+			   AND pr.node_type = 'project'
+			   AND pr.deleted_at IS NULL
+			   AND pr.org_id = $3
+			   AND p.node_type = 'person'
+			   AND p.deleted_at IS NULL
+			   AND %[2]s
+			 ORDER BY pr.%[1]s <=> $1::vector
+			 LIMIT $2)
+			UNION ALL
+			(SELECT p.node_id, 1 - (ch.%[1]s <=> $1::vector) AS similarity
+			 FROM cv_chunks ch
+			 JOIN cv_files f ON f.id = ch.cv_file_id
+			 JOIN candidates c ON c.id = f.candidate_id
+			 JOIN graph_nodes p ON p.id = c.graph_node_id
+			 WHERE ch.%[1]s IS NOT NULL
+			   AND f.deleted_at IS NULL
+			   AND f.org_id = $3
+			   AND c.deleted_at IS NULL
+			   AND p.node_type = 'person'
+			   AND p.deleted_at IS NULL
+			   AND %[2]s
+			 ORDER BY ch.%[1]s <=> $1::vector
+			 LIMIT $2)
+		) matches
+		GROUP BY node_id
 		ORDER BY similarity DESC
 		LIMIT $2
-	`
+	`, column, personStatusSQL(ctx, "p"))
 
-	// lib/pq sends []byte as bytea; pgvector's ::vector cast requires text.
-	// Pass string(embeddingJSON) so it is sent as a text parameter.
-	rows, err := s.db.QueryContext(ctx, query, string(embeddingJSON), topK)
+	// A []byte argument would be encoded as bytea; pgvector's ::vector cast
+	// requires text. Pass string(embeddingJSON) so it is sent as a text parameter.
+	rows, err := s.db.QueryContext(ctx, query, string(embeddingJSON), topK, tenant.OrgID(ctx))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -265,16 +485,6 @@ func (s *EmbeddingService) similaritySearchWithEmbedding(ctx context.Context, qu
 	return nodeIDs, similarities, nil
 }
 
-func getString(m map[string]interface{}, key string) string {
-	if v, ok := m[key]; ok {
-		if s, ok := v.(string); ok {
-			return s
-		}
-		return fmt.Sprintf("%v", v)
-	}
-	return ""
-}
-
 // ReEmbedPersonNodeByID regenerates the embedding for a person node, enriching the text
 // with any interview notes so the vector reflects the candidate's full interview history.
 // Should be called after every interview create/update/delete.
@@ -319,10 +529,10 @@ func (s *EmbeddingService) ReEmbedPersonNodeByID(ctx context.Context, graphNodeI
 	_, err = s.db.ExecContext(ctx, `
 		UPDATE graph_nodes
 		SET embedding = $1,
-		    embedding_model = 'text-embedding-3-small',
+		    embedding_model = $3,
 		    embedding_created_at = NOW()
 		WHERE id = $2
-	`, string(embeddingJSON), graphNodeID)
+	`, string(embeddingJSON), graphNodeID, s.model)
 
 	if err != nil {
 		return fmt.Errorf("re-embed: DB update failed: %w", err)
@@ -381,12 +591,14 @@ func (s *EmbeddingService) FindCommunitiesByPositionTitles(ctx context.Context,
 			JOIN graph_nodes gn ON gn.id = cm.node_id
 			JOIN graph_communities gc ON gc.id = cm.community_id
 			WHERE gn.node_type = 'person'
+			  AND gn.deleted_at IS NULL
+			  AND gc.org_id = $2
 			  AND gc.community_id LIKE 'cluster_%'
 			  AND LOWER(gn.properties->>'current_position') LIKE '%' || $1 || '%'
 			GROUP BY gc.community_id
 			ORDER BY cnt DESC
 			LIMIT 3
-		`, term)
+		`, term, tenant.OrgID(ctx))
 		if err != nil {
 			log.Printf("[CommunityPositionLookup] query error for %q: %v", term, err)
 			return
@@ -418,15 +630,16 @@ func (s *EmbeddingService) FindCommunitiesByEmbedding(ctx context.Context, query
 		SELECT gc.community_id, gc.embedding <=> $1::vector AS dist
 		FROM graph_communities gc
 		WHERE gc.embedding IS NOT NULL
+		  AND gc.org_id = $3
 		  AND gc.community_id LIKE 'cluster_%'
 		  AND EXISTS (
 		    SELECT 1 FROM community_members cm
 		    JOIN graph_nodes gn ON gn.id = cm.node_id
-		    WHERE cm.community_id = gc.id AND gn.node_type = 'person'
+		    WHERE cm.community_id = gc.id AND gn.node_type = 'person' AND gn.deleted_at IS NULL
 		  )
 		ORDER BY dist
 		LIMIT $2
-	`, string(embeddingJSON), topK)
+	`, string(embeddingJSON), topK, tenant.OrgID(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -8,6 +8,8 @@ import (
 	"log"
 	"sort"
 	"strings"
+
+	"cv-search/internal/tenant"
 )
 
 // EnhancedSearchEngine combines Vector + Community + LLM search (Microsoft GraphRAG style)
@@ -27,6 +29,20 @@ func NewEnhancedSearchEngine(db *sql.DB, llm LLMClient, embeddingAPIKey string)
 	}
 }
 
+// SetEmbeddingModel selects the embedding model for queries, node
+// embeddings and community summaries (see EmbeddingService.SetModel).
+func (s *EnhancedSearchEngine) SetEmbeddingModel(model string, dimensions int) {
+	s.embeddingService.SetModel(model, dimensions)
+	s.communityDetector.embeddingService.SetModel(model, dimensions)
+}
+
+// UseOllamaEmbeddings generates embeddings with Ollama instead of OpenAI
+// (see EmbeddingService.UseOllama). Call before SetEmbeddingModel.
+func (s *EnhancedSearchEngine) UseOllamaEmbeddings(baseURL, apiKey string) {
+	s.embeddingService.UseOllama(baseURL, apiKey)
+	s.communityDetector.embeddingService.UseOllama(baseURL, apiKey)
+}
+
 // GetEmbeddingService returns the embedding service
 func (s *EnhancedSearchEngine) GetEmbeddingService() *EmbeddingService {
 	return s.embeddingService
@@ -50,11 +66,13 @@ type EnhancedSearchResult struct {
 
 // CommunityInsight represents a relevant community for the query
 type CommunityInsight struct {
-	CommunityID string  `json:"community_id"`
-	Title       string  `json:"title"`
-	Summary     string  `json:"summary"`
-	MemberCount int     `json:"member_count"`
-	Relevance   float64 `json:"relevance"`
+	CommunityID        string  `json:"community_id"`
+	Title              string  `json:"title"`
+	Summary            string  `json:"summary"`
+	MemberCount        int     `json:"member_count"`
+	Relevance          float64 `json:"relevance"`                     // centroid and summary similarity combined (see rankCommunities)
+	CentroidSimilarity float64 `json:"centroid_similarity,omitempty"` // to the mean of the members' embeddings
+	SummarySimilarity  float64 `json:"summary_similarity,omitempty"`  // to the LLM title and summary
 }
 
 // Search performs enhanced semantic search with vector similarity and community detection
@@ -62,7 +80,7 @@ func (s *EnhancedSearchEngine) Search(ctx context.Context, query string) (*Enhan
 	log.Printf("[Enhanced Search] Starting Microsoft GraphRAG-style search for: %s", query)
 
 	// Step 1: Vector similarity search to narrow candidates
-	candidateIDs, err := s.vectorSearch(ctx, query, 50) // Get top 50 by vector similarity
+	candidateIDs, similarities, err := s.vectorSearch(ctx, query, 50) // Get top 50 by vector similarity
 	if err != nil || len(candidateIDs) == 0 {
 		log.Printf("[Enhanced Search] Vector search failed or empty, falling back to all candidates")
 		// Fallback to LLM-only search
@@ -76,6 +94,14 @@ func (s *EnhancedSearchEngine) Search(ctx context.Context, query string) (*Enhan
 	if err != nil {
 		return nil, fmt.Errorf("failed to load candidates: %w", err)
 	}
+	// Carry vector similarity (0-100) as the retrieval score, used to order
+	// any candidates the LLM leaves out of its ranking.
+	for i := range candidates {
+		candidates[i].MatchScore = similarities[candidates[i].PersonID] * 100
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].MatchScore > candidates[j].MatchScore
+	})
 
 	// Step 3: Find relevant communities
 	communities, err := s.findRelevantCommunities(ctx, query)
@@ -106,11 +132,15 @@ func (s *EnhancedSearchEngine) Search(ctx context.Context, query string) (*Enhan
 	}, nil
 }
 
-// vectorSearch uses embedding similarity to find relevant candidates
-func (s *EnhancedSearchEngine) vectorSearch(ctx context.Context, query string, topK int) ([]string, error) {
+// vectorSearch uses embedding similarity to find relevant candidates.
+// Returns person node IDs in similarity order plus their similarity scores.
+func (s *EnhancedSearchEngine) vectorSearch(ctx context.Context, query string, topK int) ([]string, map[string]float64, error) {
 	nodeIDs, similarities, err := s.embeddingService.SimilaritySearch(ctx, query, topK)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	if len(nodeIDs) == 0 {
+		return nil, nil, nil
 	}
 
 	log.Printf("[Enhanced Search] Vector search returned %d results (top similarity: %.3f)",
@@ -118,13 +148,17 @@ func (s *EnhancedSearchEngine) vectorSearch(ctx context.Context, query string, t
 
 	// Filter to only person nodes
 	var personIDs []string
-	for _, nodeID := range nodeIDs {
+	scores := make(map[string]float64, len(nodeIDs))
+	for i, nodeID := range nodeIDs {
 		if strings.HasPrefix(nodeID, "person_") {
 			personIDs = append(personIDs, nodeID)
+			if i < len(similarities) {
+				scores[nodeID] = similarities[i]
+			}
 		}
 	}
 
-	return personIDs, nil
+	return personIDs, scores, nil
 }
 
 // loadCandidatesByIDs loads specific candidates by their node IDs
@@ -141,12 +175,17 @@ func (s *EnhancedSearchEngine) loadCandidatesByIDs(ctx context.Context, nodeIDs
 		args[i] = id
 	}
 
+	args = append(args, tenant.OrgID(ctx))
+
 	query := fmt.Sprintf(`
 		SELECT node_id, properties
 		FROM graph_nodes
 		WHERE node_type = 'person'
+		  AND deleted_at IS NULL
 		  AND node_id IN (%s)
-	`, strings.Join(placeholders, ","))
+		  AND org_id = $%d
+		  AND %s
+	`, strings.Join(placeholders, ","), len(args), personStatusSQL(ctx, "graph_nodes"))
 
 	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -164,25 +203,19 @@ func (s *EnhancedSearchEngine) loadCandidatesByIDs(ctx context.Context, nodeIDs
 		}
 
 		// Parse properties
-		var props map[string]interface{}
-		if err := json.Unmarshal(propsJSON, &props); err != nil {
+		props, err := DecodePersonProperties(propsJSON)
+		if err != nil {
 			continue
 		}
 
 		// Extract basic info
-		if cvID, ok := props["cv_id"].(float64); ok {
-			result.CVID = int(cvID)
-		}
-		if name, ok := props["name"].(string); ok {
-			result.Name = name
+		result.CVID = props.CVID
+		result.Name = props.Name
+		result.CurrentPosition = props.CurrentPosition
+		result.Seniority = props.Seniority
+		if props.TotalExperienceYears != nil {
+			result.TotalExperience = *props.TotalExperienceYears
 		}
-		if pos, ok := props["current_position"].(string); ok {
-			result.CurrentPosition = pos
-		}
-		if sen, ok := props["seniority"].(string); ok {
-			result.Seniority = sen
-		}
-		result.TotalExperience = props["total_experience_years"]
 
 		// Enrich with skills, companies, education
 		s.enrichCandidate(ctx, &result)
@@ -193,46 +226,15 @@ func (s *EnhancedSearchEngine) loadCandidatesByIDs(ctx context.Context, nodeIDs
 	return candidates, nil
 }
 
-// findRelevantCommunities uses vector similarity to find relevant communities
+// findRelevantCommunities finds the communities closest to the query by
+// their members' centroid and their summary (see rankCommunities).
 func (s *EnhancedSearchEngine) findRelevantCommunities(ctx context.Context, query string) ([]CommunityInsight, error) {
 	// Generate query embedding
 	queryEmbedding, err := s.embeddingService.GenerateEmbedding(ctx, query)
 	if err != nil {
 		return nil, err
 	}
-
-	embeddingJSON, _ := json.Marshal(queryEmbedding)
-
-	// Find similar communities
-	rows, err := s.db.QueryContext(ctx, `
-		SELECT 
-			community_id,
-			title,
-			summary,
-			node_count,
-			1 - (embedding <=> $1::vector) as relevance
-		FROM graph_communities
-		WHERE embedding IS NOT NULL
-		  AND level = 0
-		ORDER BY embedding <=> $1::vector
-		LIMIT 5
-	`, string(embeddingJSON))
-
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var communities []CommunityInsight
-	for rows.Next() {
-		var c CommunityInsight
-		if err := rows.Scan(&c.CommunityID, &c.Title, &c.Summary, &c.MemberCount, &c.Relevance); err != nil {
-			continue
-		}
-		communities = append(communities, c)
-	}
-
-	return communities, nil
+	return rankCommunities(ctx, s.db, queryEmbedding, 0, 5)
 }
 
 // llmRankWithCommunities ranks candidates with community context (Microsoft GraphRAG approach)
@@ -278,6 +280,7 @@ OUTPUT JSON (return ONLY valid JSON):
 {
   "top_matches": [
     {
+      "person_id": "person_xxx",
       "name": "Candidate Name",
       "fit": "excellent|good|fair|poor",
       "reasoning": "Why this candidate matches (2-3 sentences with specific evidence)",
@@ -288,9 +291,10 @@ OUTPUT JSON (return ONLY valid JSON):
 }
 
 Include only "excellent" or "good" fits.
+Always copy person_id exactly as given in the candidate list (names are not unique).
 `, query, communityContext, len(candidates), candidateProfiles)
 
-	response, err := s.llm.Generate(prompt)
+	response, err := s.llm.Generate(ctx, prompt)
 	if err != nil {
 		return nil, "", err
 	}
@@ -303,13 +307,8 @@ Include only "excellent" or "good" fits.
 	response = strings.TrimSpace(response)
 
 	var llmResult struct {
-		TopMatches []struct {
-			Name         string   `json:"name"`
-			Fit          string   `json:"fit"`
-			Reasoning    string   `json:"reasoning"`
-			KeyStrengths []string `json:"key_strengths"`
-		} `json:"top_matches"`
-		OverallReasoning string `json:"overall_reasoning"`
+		TopMatches       []llmMatch `json:"top_matches"`
+		OverallReasoning string     `json:"overall_reasoning"`
 	}
 
 	if err := json.Unmarshal([]byte(response), &llmResult); err != nil {
@@ -317,51 +316,9 @@ Include only "excellent" or "good" fits.
 		return nil, "", fmt.Errorf("failed to parse LLM response: %w", err)
 	}
 
-	// NO MORE LOCAL SCORING! Pure LLM approach
-	var rankedCandidates []LLMRankedCandidate
-	for _, match := range llmResult.TopMatches {
-		for _, candidate := range candidates {
-			if candidate.Name == match.Name {
-				// Pure LLM score (no local heuristics)
-				var llmScore float64
-				switch strings.ToLower(match.Fit) {
-				case "excellent":
-					llmScore = 95.0
-				case "good":
-					llmScore = 80.0
-				case "fair":
-					llmScore = 60.0
-				case "poor":
-					llmScore = 30.0
-				default:
-					llmScore = 50.0
-				}
-
-				rankedCandidates = append(rankedCandidates, LLMRankedCandidate{
-					CVID:            candidate.CVID,
-					PersonID:        candidate.PersonID,
-					Name:            candidate.Name,
-					CurrentPosition: candidate.CurrentPosition,
-					Seniority:       candidate.Seniority,
-					TotalExperience: candidate.TotalExperience,
-					Skills:          candidate.Skills,
-					Companies:       candidate.Companies,
-					Education:       candidate.Education,
-					Fit:             match.Fit,
-					Reasoning:       match.Reasoning,
-					KeyStrengths:    match.KeyStrengths,
-					MatchScore:      llmScore,
-					FinalScore:      llmScore, // Pure LLM score
-				})
-				break
-			}
-		}
-	}
-
-	// Sort by FinalScore descending (pure LLM ranking)
-	sort.Slice(rankedCandidates, func(i, j int) bool {
-		return rankedCandidates[i].FinalScore > rankedCandidates[j].FinalScore
-	})
+	// NO MORE LOCAL SCORING! Pure LLM approach — unmentioned candidates are
+	// appended by vector similarity and flagged unranked_by_llm.
+	rankedCandidates := mergeLLMMatches(candidates, llmResult.TopMatches)
 
 	return rankedCandidates, llmResult.OverallReasoning, nil
 }
@@ -390,8 +347,8 @@ func (s *EnhancedSearchEngine) enrichCandidate(ctx context.Context, candidate *C
 	// Get internal ID
 	var internalID int
 	err := s.db.QueryRowContext(ctx,
-		`SELECT id FROM graph_nodes WHERE node_id = $1`,
-		candidate.PersonID,
+		`SELECT id FROM graph_nodes WHERE node_id = $1 AND org_id = $2`,
+		candidate.PersonID, tenant.OrgID(ctx),
 	).Scan(&internalID)
 
 	if err != nil {
@@ -456,7 +413,8 @@ func (s *EnhancedSearchEngine) generateEnhancedSummary(
 	communities []CommunityInsight,
 	reasoning string,
 ) string {
-	if len(candidates) == 0 {
+	relevant := rankedByLLMCount(candidates)
+	if relevant == 0 {
 		return "No candidates found matching your query."
 	}
 
@@ -467,7 +425,7 @@ func (s *EnhancedSearchEngine) generateEnhancedSummary(
 		}
 	}
 
-	summary := fmt.Sprintf("Found %d relevant candidates", len(candidates))
+	summary := fmt.Sprintf("Found %d relevant candidates", relevant)
 	if excellentCount > 0 {
 		summary += fmt.Sprintf(" (%d excellent match", excellentCount)
 		if excellentCount > 1 {
@@ -492,7 +450,7 @@ func buildCandidateProfiles(candidates []CandidateResult) string {
 	var builder strings.Builder
 
 	for i, candidate := range candidates {
-		builder.WriteString(fmt.Sprintf("\n%d. %s\n", i+1, candidate.Name))
+		builder.WriteString(fmt.Sprintf("\n%d. %s [person_id: %s]\n", i+1, candidate.Name, candidate.PersonID))
 
 		if candidate.CurrentPosition != "" {
 			builder.WriteString(fmt.Sprintf("   Position: %s", candidate.CurrentPosition))
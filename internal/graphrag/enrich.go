@@ -0,0 +1,483 @@
+package graphrag
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"cv-search/internal/tenant"
+)
+
+// Candidate enrichment runs in chunks of enrichChunkSize candidates, each
+// one round of batched queries, with up to enrichWorkers chunks loading at
+// once (each holds one database connection at a time).
+const (
+	enrichChunkSize = 25
+	enrichWorkers   = 4
+)
+
+// enrichCandidates loads full candidate details (skills, companies, etc.),
+// a chunk of candidates per worker. A failed query leaves its chunk's
+// candidates without those details; it is logged, not fatal.
+func (h *HybridSearchEngine) enrichCandidates(ctx context.Context, candidates []FusedCandidate, patterns CommunityPatterns) {
+	if len(candidates) <= enrichChunkSize {
+		h.enrichBatch(ctx, candidates, patterns)
+		return
+	}
+	start := time.Now()
+	var g errgroup.Group
+	g.SetLimit(enrichWorkers)
+	for i := 0; i < len(candidates); i += enrichChunkSize {
+		chunk := candidates[i:min(i+enrichChunkSize, len(candidates))]
+		g.Go(func() error {
+			h.enrichBatch(ctx, chunk, patterns) // chunks don't overlap: no shared writes
+			return nil
+		})
+	}
+	g.Wait()
+	log.Printf("[HybridSearch] Enriched %d candidates in %d chunks (%s)",
+		len(candidates), (len(candidates)+enrichChunkSize-1)/enrichChunkSize, time.Since(start))
+}
+
+// enrichBatch loads the details of candidates with one query per kind of
+// detail (no N+1). Candidates without detected communities get patterns'.
+func (h *HybridSearchEngine) enrichBatch(ctx context.Context, candidates []FusedCandidate, patterns CommunityPatterns) {
+	if len(candidates) == 0 {
+		return
+	}
+
+	// Build person ID list for batch queries
+	personIDs := make([]interface{}, 0, len(candidates))
+	personIDToIndex := make(map[string]int)
+	for i := range candidates {
+		if candidates[i].PersonID != "" {
+			personIDs = append(personIDs, candidates[i].PersonID)
+			personIDToIndex[candidates[i].PersonID] = i
+		}
+	}
+
+	if len(personIDs) == 0 {
+		return
+	}
+
+	// Build placeholders for IN clause ($1, $2, $3, ...), followed by the
+	// organization every batch below is restricted to
+	placeholders := make([]string, len(personIDs))
+	for i := range personIDs {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+	inClause := "(" + strings.Join(placeholders, ",") + ")"
+	orgParam := fmt.Sprintf("$%d", len(personIDs)+1)
+	batchArgs := append(personIDs, tenant.OrgID(ctx))
+
+	// BATCH 1: Load all person details in one query
+	// Also fetch integer id so we can correlate with interviews.candidate_id via candidates.graph_node_id
+	personQuery := fmt.Sprintf(`
+		SELECT id, node_id, properties
+		FROM graph_nodes
+		WHERE node_id IN %s AND node_type = 'person' AND deleted_at IS NULL AND org_id = %s
+	`, inClause, orgParam)
+
+	personRows, err := h.db.QueryContext(ctx, personQuery, batchArgs...)
+	if err != nil {
+		log.Printf("[HybridSearch] Failed to batch load persons: %v", err)
+		return
+	}
+	defer personRows.Close()
+
+	// Track integer IDs for interview batch lookup
+	nodeIntIDIndex := make(map[int]int) // graphNodeIntID → candidate slice index
+
+	for personRows.Next() {
+		var nodeIntID int
+		var personID string
+		var propsJSON []byte
+		if err := personRows.Scan(&nodeIntID, &personID, &propsJSON); err != nil {
+			continue
+		}
+
+		idx, ok := personIDToIndex[personID]
+		if !ok {
+			continue
+		}
+		candidates[idx].GraphNodeIntID = nodeIntID
+		nodeIntIDIndex[nodeIntID] = idx
+
+		props, err := DecodePersonProperties(propsJSON)
+		if err != nil {
+			continue
+		}
+		applyPersonProperties(&candidates[idx], props)
+	}
+
+	// BATCH 1.5: Load candidate IDs for each GraphNodeIntID
+	var graphNodeIDs []interface{}
+	for _, c := range candidates {
+		if c.GraphNodeIntID != 0 {
+			graphNodeIDs = append(graphNodeIDs, c.GraphNodeIntID)
+		}
+	}
+	if len(graphNodeIDs) > 0 {
+		placeholders2 := make([]string, len(graphNodeIDs))
+		for i := range graphNodeIDs {
+			placeholders2[i] = fmt.Sprintf("$%d", i+1)
+		}
+		candidateQuery := fmt.Sprintf(`
+			SELECT id, graph_node_id
+			FROM candidates
+			WHERE graph_node_id IN (%s)
+			  AND deleted_at IS NULL
+		`, strings.Join(placeholders2, ","))
+		candRows, err := h.db.QueryContext(ctx, candidateQuery, graphNodeIDs...)
+		if err == nil {
+			defer candRows.Close()
+			for candRows.Next() {
+				var cID, gID int
+				if err := candRows.Scan(&cID, &gID); err == nil {
+					if idx, ok := nodeIntIDIndex[gID]; ok {
+						candidates[idx].CandidateID = cID
+					}
+				}
+			}
+		} else {
+			log.Printf("[HybridSearch] Failed to batch load candidates: %v", err)
+		}
+	}
+
+	// BATCH 2: Load all skills in one query
+	skillQuery := fmt.Sprintf(`
+		SELECT p.node_id, s.properties, e.properties
+		FROM graph_nodes p
+		JOIN graph_edges e ON p.id = e.source_node_id
+		JOIN graph_nodes s ON e.target_node_id = s.id
+		WHERE p.node_id IN %s
+		  AND p.org_id = %s
+		  AND e.edge_type = 'HAS_SKILL'
+		  AND s.node_type = 'skill'
+	`, inClause, orgParam)
+
+	skillRows, err := h.db.QueryContext(ctx, skillQuery, batchArgs...)
+	if err != nil {
+		log.Printf("[HybridSearch] Failed to batch load skills: %v", err)
+	} else {
+		defer skillRows.Close()
+		for skillRows.Next() {
+			var personID string
+			var skillPropsJSON, edgePropsJSON []byte
+			if err := skillRows.Scan(&personID, &skillPropsJSON, &edgePropsJSON); err != nil {
+				continue
+			}
+
+			idx, ok := personIDToIndex[personID]
+			if !ok {
+				continue
+			}
+
+			skillProps, err := DecodeSkillProperties(skillPropsJSON)
+			if err != nil || skillProps.Name == "" {
+				continue
+			}
+
+			skill := SkillNode{Name: skillProps.Name, Proficiency: skillProps.Proficiency}
+
+			// Load years from edge properties
+			if edgeProps, err := DecodeHasSkillProperties(edgePropsJSON); err == nil {
+				if skill.Proficiency == "" {
+					skill.Proficiency = edgeProps.Proficiency
+				}
+				if edgeProps.YearsOfExperience != nil {
+					skill.YearsOfExperience = int(*edgeProps.YearsOfExperience)
+				}
+			}
+
+			candidates[idx].Skills = append(candidates[idx].Skills, skill)
+		}
+	}
+
+	// BATCH 3: Load all companies in one query (both current and past employers)
+	companyQuery := fmt.Sprintf(`
+		SELECT p.node_id, c.properties, e.properties, e.edge_type
+		FROM graph_nodes p
+		JOIN graph_edges e ON p.id = e.source_node_id
+		JOIN graph_nodes c ON e.target_node_id = c.id
+		WHERE p.node_id IN %s
+		  AND p.org_id = %s
+		  AND (e.edge_type = 'WORKED_AT' OR e.edge_type = 'WORKS_AT')
+		  AND c.node_type = 'company'
+	`, inClause, orgParam)
+
+	companyRows, err := h.db.QueryContext(ctx, companyQuery, batchArgs...)
+	if err != nil {
+		log.Printf("[HybridSearch] Failed to batch load companies: %v", err)
+	} else {
+		defer companyRows.Close()
+		for companyRows.Next() {
+			var personID, edgeType string
+			var companyPropsJSON, edgePropsJSON []byte
+			if err := companyRows.Scan(&personID, &companyPropsJSON, &edgePropsJSON, &edgeType); err != nil {
+				continue
+			}
+
+			idx, ok := personIDToIndex[personID]
+			if !ok {
+				continue
+			}
+
+			companyProps, err := DecodeCompanyProperties(companyPropsJSON)
+			if err != nil || companyProps.Name == "" {
+				continue
+			}
+
+			company := CompanyNode{Name: companyProps.Name}
+			if edgeProps, err := DecodeWorkProperties(edgePropsJSON); err == nil {
+				company.Position = edgeProps.Position
+				company.IsCurrent = edgeProps.IsCurrent
+			}
+			// WORKS_AT always means current employer regardless of stored props
+			if edgeType == "WORKS_AT" {
+				company.IsCurrent = true
+			}
+
+			candidates[idx].Companies = append(candidates[idx].Companies, company)
+		}
+	}
+
+	// BATCH 3.5: Load all projects in one query
+	projectQuery := fmt.Sprintf(`
+		SELECT p.node_id, pr.properties
+		FROM graph_nodes p
+		JOIN graph_edges e ON p.id = e.source_node_id
+		JOIN graph_nodes pr ON e.target_node_id = pr.id
+		WHERE p.node_id IN %s
+		  AND p.org_id = %s
+		  AND e.edge_type = 'WORKED_ON'
+		  AND pr.node_type = 'project'
+		  AND pr.deleted_at IS NULL
+		ORDER BY pr.node_id
+	`, inClause, orgParam)
+
+	projectRows, err := h.db.QueryContext(ctx, projectQuery, batchArgs...)
+	if err != nil {
+		log.Printf("[HybridSearch] Failed to batch load projects: %v", err)
+	} else {
+		defer projectRows.Close()
+		for projectRows.Next() {
+			var personID string
+			var projectPropsJSON []byte
+			if err := projectRows.Scan(&personID, &projectPropsJSON); err != nil {
+				continue
+			}
+			idx, ok := personIDToIndex[personID]
+			if !ok {
+				continue
+			}
+			project, err := DecodeProjectProperties(projectPropsJSON)
+			if err != nil || (project.Name == "" && project.Description == "") {
+				continue
+			}
+			candidates[idx].Projects = append(candidates[idx].Projects, project.Text())
+		}
+	}
+
+	// BATCH 4: Load community memberships from graph_communities (written by detect_communities tool).
+	// Reads membership_strength per candidate so Steps 2.7 and 2.9 get real cluster-based scores.
+	// If the table is empty (tool hasn't run yet) no rows are returned and the keyword fallback below fires.
+	dbCommunityLoaded := make(map[string]bool) // personID → true if at least one DB community loaded
+	if len(personIDs) > 0 {
+		communityMemberQuery := fmt.Sprintf(`
+			SELECT p.node_id, gc.level, gc.community_id, gc.title, gc.summary, COALESCE(cm.membership_strength, 1.0)
+			FROM graph_nodes p
+			JOIN community_members cm ON p.id = cm.node_id
+			JOIN graph_communities gc ON cm.community_id = gc.id
+			WHERE p.node_id IN %s
+			  AND p.org_id = %s
+			  AND p.node_type = 'person'
+			  AND gc.summary IS NOT NULL
+			ORDER BY gc.node_count DESC
+		`, inClause, orgParam)
+
+		communityRows, err := h.db.QueryContext(ctx, communityMemberQuery, batchArgs...)
+		if err != nil {
+			log.Printf("[HybridSearch] Failed to batch load computed communities (non-fatal): %v", err)
+		} else {
+			defer communityRows.Close()
+			for communityRows.Next() {
+				var personID, communityID string
+				var level int
+				var title, summary sql.NullString
+				var strength float64
+				if err := communityRows.Scan(&personID, &level, &communityID, &title, &summary, &strength); err != nil {
+					continue
+				}
+				idx, ok := personIDToIndex[personID]
+				if !ok {
+					continue
+				}
+				// Primary community = first row (highest node_count due to ORDER BY).
+				if candidates[idx].ComputedCommunityID == "" {
+					candidates[idx].ComputedCommunityID = communityID
+					if summary.Valid {
+						candidates[idx].ComputedCommunitySummary = summary.String
+					}
+				}
+				// Populate CommunityScores and Communities from DB membership_strength.
+				if candidates[idx].CommunityScores == nil {
+					candidates[idx].CommunityScores = make(map[string]float64)
+				}
+				candidates[idx].CommunityScores[communityID] = strength
+				if level <= 1 {
+					candidates[idx].ComputedCommunities = append(candidates[idx].ComputedCommunities, ComputedCommunity{
+						Level:    level,
+						ID:       communityID,
+						Title:    title.String,
+						Summary:  summary.String,
+						Strength: strength,
+					})
+				}
+				alreadyInList := false
+				for _, c := range candidates[idx].Communities {
+					if c == communityID {
+						alreadyInList = true
+						break
+					}
+				}
+				if !alreadyInList {
+					candidates[idx].Communities = append(candidates[idx].Communities, communityID)
+				}
+				dbCommunityLoaded[personID] = true
+			}
+		}
+	}
+
+	for i := range candidates {
+		cc := candidates[i].ComputedCommunities
+		sort.SliceStable(cc, func(a, b int) bool { return cc[a].Strength > cc[b].Strength })
+	}
+
+	assignKeywordCommunities(candidates, dbCommunityLoaded, patterns)
+
+	// BATCH 5: Load interviews for all candidates via candidates.graph_node_id
+	if len(nodeIntIDIndex) > 0 {
+		nodeIntIDs := make([]interface{}, 0, len(nodeIntIDIndex))
+		for nodeIntID := range nodeIntIDIndex {
+			nodeIntIDs = append(nodeIntIDs, nodeIntID)
+		}
+		interviewPlaceholders := make([]string, len(nodeIntIDs))
+		for i := range nodeIntIDs {
+			interviewPlaceholders[i] = fmt.Sprintf("$%d", i+1)
+		}
+		interviewQuery := fmt.Sprintf(`
+			SELECT c.graph_node_id, i.id, i.interview_date,
+			       COALESCE(i.team,''), COALESCE(i.interviewer_name,''),
+			       COALESCE(i.interview_type,''), COALESCE(i.outcome,''), COALESCE(i.notes,'')
+			FROM interviews i
+			JOIN candidates c ON c.id = i.candidate_id
+			WHERE c.graph_node_id IN (%s)
+			ORDER BY i.interview_date DESC
+		`, strings.Join(interviewPlaceholders, ","))
+
+		ivRows, ivErr := h.db.QueryContext(ctx, interviewQuery, nodeIntIDs...)
+		if ivErr != nil {
+			log.Printf("[HybridSearch] Failed to batch load interviews (non-fatal): %v", ivErr)
+		} else {
+			defer ivRows.Close()
+			for ivRows.Next() {
+				var nodeIntID, ivID int
+				var iv InterviewContext
+				if err := ivRows.Scan(
+					&nodeIntID, &ivID, &iv.InterviewDate,
+					&iv.Team, &iv.InterviewerName, &iv.InterviewType, &iv.Outcome, &iv.Notes,
+				); err != nil {
+					continue
+				}
+				iv.ID = ivID
+				if idx, ok := nodeIntIDIndex[nodeIntID]; ok {
+					candidates[idx].Interviews = append(candidates[idx].Interviews, iv)
+				}
+			}
+		}
+
+		// BATCH 6: Load recruiter tags the same way
+		tagQuery := fmt.Sprintf(`
+			SELECT c.graph_node_id, t.tag
+			FROM candidate_tags t
+			JOIN candidates c ON c.id = t.candidate_id
+			WHERE c.graph_node_id IN (%s) AND c.deleted_at IS NULL
+			ORDER BY t.tag
+		`, strings.Join(interviewPlaceholders, ","))
+
+		tagRows, tagErr := h.db.QueryContext(ctx, tagQuery, nodeIntIDs...)
+		if tagErr != nil {
+			log.Printf("[HybridSearch] Failed to batch load tags (non-fatal): %v", tagErr)
+		} else {
+			defer tagRows.Close()
+			for tagRows.Next() {
+				var nodeIntID int
+				var tag string
+				if err := tagRows.Scan(&nodeIntID, &tag); err != nil {
+					continue
+				}
+				if idx, ok := nodeIntIDIndex[nodeIntID]; ok {
+					candidates[idx].Tags = append(candidates[idx].Tags, tag)
+				}
+			}
+		}
+	}
+}
+
+// applyPersonProperties fills c's details from its person node's
+// properties, keeping what fusion already knew where they are empty.
+func applyPersonProperties(c *FusedCandidate, props PersonProperties) {
+	if props.Name != "" {
+		c.Name = props.Name
+	}
+	if props.CurrentPosition != "" {
+		c.CurrentPosition = props.CurrentPosition
+	}
+	if props.Seniority != "" {
+		c.Seniority = props.Seniority
+	}
+	if props.TotalExperienceYears != nil {
+		c.TotalExperienceYears = int(*props.TotalExperienceYears)
+	}
+	c.Location = props.Location
+	if props.Status != StatusActive {
+		c.Status = props.Status
+	}
+	c.home = props
+	if props.Community != "" {
+		c.Community = props.Community
+	}
+	if props.Communities != nil {
+		c.Communities = props.Communities
+	}
+}
+
+// assignKeywordCommunities is the keyword-based community fallback for the
+// candidates without detected communities (dbLoaded, by person ID): when
+// detect_communities hasn't run, or didn't cover them. It derives their
+// communities from their skills with patterns. Candidates with detected
+// communities get their primary one from ComputedCommunityID.
+func assignKeywordCommunities(candidates []FusedCandidate, dbLoaded map[string]bool, patterns CommunityPatterns) {
+	for i := range candidates {
+		if dbLoaded[candidates[i].PersonID] {
+			if candidates[i].Community == "" {
+				candidates[i].Community = candidates[i].ComputedCommunityID
+			}
+			continue
+		}
+		if len(candidates[i].Skills) > 0 {
+			primary, communities, scores := patterns.FindCommunities(candidates[i].Skills, 0.3)
+			candidates[i].Community = primary
+			candidates[i].Communities = communities
+			candidates[i].CommunityScores = scores
+			candidates[i].keywordCommunities = patterns.Names(communities)
+		}
+	}
+}
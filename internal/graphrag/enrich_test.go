@@ -0,0 +1,127 @@
+package graphrag
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestApplyPersonProperties(t *testing.T) {
+	years := 7.9
+	tests := []struct {
+		name  string
+		props PersonProperties
+		check func(t *testing.T, c FusedCandidate)
+	}{
+		{
+			name:  "fills the candidate",
+			props: PersonProperties{Name: "Ada", CurrentPosition: "Engineer", Seniority: "senior", TotalExperienceYears: &years, Location: "Ankara"},
+			check: func(t *testing.T, c FusedCandidate) {
+				if c.Name != "Ada" || c.CurrentPosition != "Engineer" || c.Seniority != "senior" || c.TotalExperienceYears != 7 || c.Location != "Ankara" {
+					t.Errorf("candidate = %+v", c)
+				}
+			},
+		},
+		{
+			name:  "empty fields keep search values",
+			props: PersonProperties{},
+			check: func(t *testing.T, c FusedCandidate) {
+				if c.Name != "from search" || c.Community != "backend" || c.TotalExperienceYears != 3 {
+					t.Errorf("candidate = %+v, want the search values kept", c)
+				}
+			},
+		},
+		{
+			name:  "active status stays empty",
+			props: PersonProperties{Status: StatusActive},
+			check: func(t *testing.T, c FusedCandidate) {
+				if c.Status != "" {
+					t.Errorf("Status = %q, want empty", c.Status)
+				}
+			},
+		},
+		{
+			name:  "other status",
+			props: PersonProperties{Status: StatusHired},
+			check: func(t *testing.T, c FusedCandidate) {
+				if c.Status != StatusHired {
+					t.Errorf("Status = %q, want %q", c.Status, StatusHired)
+				}
+			},
+		},
+		{
+			name:  "stored communities",
+			props: PersonProperties{Community: "data", Communities: []string{"data", "backend"}},
+			check: func(t *testing.T, c FusedCandidate) {
+				if c.Community != "data" || !slices.Equal(c.Communities, []string{"data", "backend"}) {
+					t.Errorf("communities = %q, %v", c.Community, c.Communities)
+				}
+			},
+		},
+		{
+			name:  "keeps the properties",
+			props: PersonProperties{CountryCode: "TR", LocationID: 4},
+			check: func(t *testing.T, c FusedCandidate) {
+				if c.home.CountryCode != "TR" || c.home.LocationID != 4 {
+					t.Errorf("home = %+v", c.home)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := FusedCandidate{Name: "from search", Community: "backend", TotalExperienceYears: 3}
+			applyPersonProperties(&c, tt.props)
+			tt.check(t, c)
+		})
+	}
+}
+
+func TestAssignKeywordCommunities(t *testing.T) {
+	patterns := CommunityPatterns{"backend": {Name: "Backend Developers", KeySkills: []string{"Go"}}}
+	tests := []struct {
+		name          string
+		candidate     FusedCandidate
+		dbLoaded      bool
+		wantCommunity string
+		wantKeyword   []string
+	}{
+		{
+			name:          "matching skills",
+			candidate:     withSkills("a", "Go", "Docker"),
+			wantCommunity: "backend",
+			wantKeyword:   []string{"Backend Developers"},
+		},
+		{
+			name:          "no matching skill",
+			candidate:     withSkills("a", "Photoshop"),
+			wantCommunity: "general",
+		},
+		{
+			name:      "no skills",
+			candidate: FusedCandidate{PersonID: "a"},
+		},
+		{
+			name:          "loaded from the graph",
+			candidate:     FusedCandidate{PersonID: "a", Community: "data", Skills: []SkillNode{{Name: "Go"}}},
+			dbLoaded:      true,
+			wantCommunity: "data",
+		},
+		{
+			name:          "loaded without a community",
+			candidate:     FusedCandidate{PersonID: "a", ComputedCommunityID: "c7"},
+			dbLoaded:      true,
+			wantCommunity: "c7",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidates := []FusedCandidate{tt.candidate}
+			assignKeywordCommunities(candidates, map[string]bool{"a": tt.dbLoaded}, patterns)
+			c := candidates[0]
+			if c.Community != tt.wantCommunity || !slices.Equal(c.keywordCommunities, tt.wantKeyword) {
+				t.Errorf("community %q, keyword communities %v; want %q, %v",
+					c.Community, c.keywordCommunities, tt.wantCommunity, tt.wantKeyword)
+			}
+		})
+	}
+}
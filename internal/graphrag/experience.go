@@ -0,0 +1,227 @@
+package graphrag
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"cv-search/internal/llm"
+)
+
+// ─── Experience years ────────────────────────────────────────────────────────
+//
+// The LLM's total_experience_years is a guess and often null. The graph build
+// computes the figure from the CV's employment ranges instead: overlapping
+// jobs count once and a current job runs until today. Person nodes keep both
+// (experience_years_llm, experience_years_computed); total_experience_years,
+// which ranking, filters and embeddings read, is the computed one whenever
+// the jobs carry dates and the LLM's otherwise.
+
+// sameYearRoleYears is what a job that started and ended in the same year
+// counts for; the CV gives years only.
+const sameYearRoleYears = 0.5
+
+// computeExperienceYears sums the employment ranges of roles, counting
+// overlapping ranges once. Years are whole, so "2018 – 2021" counts 3 years
+// and a current job ("present", or no end year on a current role) runs to
+// today. A past end year wins over the current flag, which the graph build
+// also sets on the first job listed. Roles without a start year, or without
+// an end year that aren't current, can't be placed and are skipped; ok is
+// false when none can.
+func computeExperienceYears(roles []roleSpan, now time.Time) (years float64, ok bool) {
+	today := float64(now.Year()) + float64(now.YearDay()-1)/365
+
+	type span struct{ start, end float64 }
+	var spans []span
+	for _, r := range roles {
+		if r.startYear < 1950 || r.startYear > now.Year() {
+			continue
+		}
+		start := float64(r.startYear)
+		var end float64
+		switch {
+		case r.endYear >= now.Year() || (r.endYear == 0 && r.isCurrent):
+			end = today
+		case r.endYear < r.startYear:
+			continue
+		case r.endYear == r.startYear:
+			end = start + sameYearRoleYears
+		default:
+			end = float64(r.endYear)
+		}
+		spans = append(spans, span{start, max(end, start)})
+	}
+	if len(spans) == 0 {
+		return 0, false
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	cur := spans[0]
+	for _, s := range spans[1:] {
+		if s.start <= cur.end {
+			cur.end = max(cur.end, s.end)
+			continue
+		}
+		years += cur.end - cur.start
+		cur = s
+	}
+	years += cur.end - cur.start
+	return math.Round(years*10) / 10, true
+}
+
+// companyRoleSpans returns the employment ranges of a CV's extracted
+// companies.
+func companyRoleSpans(companies []llm.Company) []roleSpan {
+	roles := make([]roleSpan, 0, len(companies))
+	for _, c := range companies {
+		roles = append(roles, roleSpan{
+			position:  c.Position,
+			startYear: propYear(c.StartYear),
+			endYear:   propYear(c.EndYear),
+			isCurrent: c.IsCurrent,
+		})
+	}
+	return roles
+}
+
+// setExperienceYears records the LLM-reported years (nil if it gave none)
+// and the years computed from roles, and makes TotalExperienceYears the
+// computed value when there is one.
+func (p *PersonProperties) setExperienceYears(llmYears *float64, roles []roleSpan, now time.Time) {
+	p.ExperienceYearsLLM = llmYears
+	p.ExperienceYearsComputed = nil
+	if years, ok := computeExperienceYears(roles, now); ok {
+		p.ExperienceYearsComputed = &years
+	}
+	p.TotalExperienceYears = p.ExperienceYearsLLM
+	if p.ExperienceYearsComputed != nil {
+		p.TotalExperienceYears = p.ExperienceYearsComputed
+	}
+}
+
+// ExperienceRecomputeReport summarizes a RecomputeExperienceYears run.
+type ExperienceRecomputeReport struct {
+	Scanned  int
+	Computed int // nodes whose jobs carried dates
+	Changed  int // nodes whose total_experience_years changed
+}
+
+// RecomputeExperienceYears computes every organization's person nodes'
+// experience years from their WORKS_AT / WORKED_AT edges, for nodes built
+// before the graph build did. A node's existing total_experience_years is
+// kept as the LLM-reported value unless it already has one. With dryRun
+// nothing is written.
+func (g *GraphBuilder) RecomputeExperienceYears(ctx context.Context, dryRun bool) (*ExperienceRecomputeReport, error) {
+	const batchSize = 500
+	rep := &ExperienceRecomputeReport{}
+	now := time.Now()
+
+	lastID := 0
+	for {
+		rows, err := g.db.QueryContext(ctx, `
+			SELECT n.id, n.properties,
+			       COALESCE(jsonb_agg(jsonb_build_object('type', e.edge_type, 'props', COALESCE(e.properties, '{}'::jsonb)))
+			                FILTER (WHERE e.id IS NOT NULL), '[]'::jsonb)
+			FROM graph_nodes n
+			LEFT JOIN graph_edges e ON e.source_node_id = n.id AND e.edge_type IN ('WORKS_AT', 'WORKED_AT')
+			WHERE n.id > $1 AND n.node_type = 'person' AND n.deleted_at IS NULL
+			GROUP BY n.id
+			ORDER BY n.id
+			LIMIT $2
+		`, lastID, batchSize)
+		if err != nil {
+			return rep, fmt.Errorf("list person nodes: %w", err)
+		}
+
+		type fix struct {
+			id    int
+			props []byte
+		}
+		var fixes []fix
+		n := 0
+		for rows.Next() {
+			var id int
+			var propsJSON, edgesJSON []byte
+			if err := rows.Scan(&id, &propsJSON, &edgesJSON); err != nil {
+				rows.Close()
+				return rep, fmt.Errorf("scan person node: %w", err)
+			}
+			n++
+			lastID = id
+			rep.Scanned++
+
+			props, err := DecodePersonProperties(propsJSON)
+			if err != nil {
+				continue
+			}
+			var edges []struct {
+				Type  string          `json:"type"`
+				Props json.RawMessage `json:"props"`
+			}
+			if err := json.Unmarshal(edgesJSON, &edges); err != nil {
+				continue
+			}
+			roles := make([]roleSpan, 0, len(edges))
+			for _, e := range edges {
+				w, err := DecodeWorkProperties(e.Props)
+				if err != nil {
+					continue
+				}
+				roles = append(roles, roleSpan{
+					position:  w.Position,
+					startYear: w.StartYear,
+					endYear:   w.EndYear,
+					isCurrent: e.Type == "WORKS_AT" || w.IsCurrent,
+				})
+			}
+
+			llmYears := props.ExperienceYearsLLM
+			if llmYears == nil && props.ExperienceYearsComputed == nil {
+				llmYears = props.TotalExperienceYears
+			}
+			before := props.TotalExperienceYears
+			props.setExperienceYears(llmYears, roles, now)
+			if props.ExperienceYearsComputed == nil {
+				continue
+			}
+			rep.Computed++
+			if before == nil || *before != *props.TotalExperienceYears {
+				rep.Changed++
+			}
+
+			update := map[string]interface{}{
+				"total_experience_years":    *props.TotalExperienceYears,
+				"experience_years_computed": *props.ExperienceYearsComputed,
+			}
+			if props.ExperienceYearsLLM != nil {
+				update["experience_years_llm"] = *props.ExperienceYearsLLM
+			}
+			b, err := json.Marshal(update)
+			if err != nil {
+				rows.Close()
+				return rep, fmt.Errorf("marshal experience of node %d: %w", id, err)
+			}
+			fixes = append(fixes, fix{id: id, props: b})
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return rep, fmt.Errorf("list person nodes: %w", err)
+		}
+
+		if !dryRun {
+			for _, f := range fixes {
+				if _, err := g.db.ExecContext(ctx,
+					`UPDATE graph_nodes SET properties = properties || $2::jsonb WHERE id = $1`, f.id, f.props,
+				); err != nil {
+					return rep, fmt.Errorf("update graph node %d: %w", f.id, err)
+				}
+			}
+		}
+		if n < batchSize {
+			return rep, nil
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package graphrag
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Reranker values for HybridSearchConfig.Reranker.
+const (
+	RerankerLLM  = "llm"  // LLMScorer reranks the fused top N (default)
+	RerankerNone = "none" // serve the fusion ranking as-is, no LLM call
+)
+
+// ExperimentConfig is the JSON stored in search_experiments.config. Every field
+// is optional; unset fields keep the value from DefaultHybridConfig.
+type ExperimentConfig struct {
+	BM25Weight          *float64 `json:"bm25_weight,omitempty"`
+	VectorWeight        *float64 `json:"vector_weight,omitempty"`
+	GraphWeight         *float64 `json:"graph_weight,omitempty"`
+	TopK                *int     `json:"top_k,omitempty"`
+	FinalTopN           *int     `json:"final_top_n,omitempty"`
+	CommunityThreshold  *int     `json:"community_threshold,omitempty"`
+	Reranker            string   `json:"reranker,omitempty"`             // "llm" or "none"
+	ScoringInstructions string   `json:"scoring_instructions,omitempty"` // appended to the LLM scoring prompt
+	DiversityLambda     *float64 `json:"diversity_lambda,omitempty"`     // MMR lambda in (0,1); 0 disables
+
+	BM25FieldWeights map[string]float64 `json:"bm25_field_weights,omitempty"` // per BM25 field, in [0,1]; unset fields keep theirs
+}
+
+// ParseExperimentConfig decodes and validates a stored experiment config.
+func ParseExperimentConfig(raw []byte) (*ExperimentConfig, error) {
+	var ec ExperimentConfig
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &ec); err != nil {
+			return nil, fmt.Errorf("invalid experiment config: %w", err)
+		}
+	}
+	switch ec.Reranker {
+	case "", RerankerLLM, RerankerNone:
+	default:
+		return nil, fmt.Errorf("invalid reranker %q (want %q or %q)", ec.Reranker, RerankerLLM, RerankerNone)
+	}
+	if ec.DiversityLambda != nil && (*ec.DiversityLambda < 0 || *ec.DiversityLambda >= 1) {
+		return nil, fmt.Errorf("invalid diversity_lambda %v (want 0 <= lambda < 1)", *ec.DiversityLambda)
+	}
+	var weights BM25FieldWeights
+	for field, w := range ec.BM25FieldWeights {
+		if err := weights.Set(field, w); err != nil {
+			return nil, fmt.Errorf("invalid bm25_field_weights: %w", err)
+		}
+	}
+	return &ec, nil
+}
+
+// Apply overlays the experiment onto cfg and tags cfg with the experiment name.
+func (ec *ExperimentConfig) Apply(name string, cfg *HybridSearchConfig) {
+	if ec.BM25Weight != nil {
+		cfg.BM25Weight = *ec.BM25Weight
+	}
+	if ec.VectorWeight != nil {
+		cfg.VectorWeight = *ec.VectorWeight
+	}
+	if ec.GraphWeight != nil {
+		cfg.GraphWeight = *ec.GraphWeight
+	}
+	if ec.TopK != nil && *ec.TopK > 0 {
+		cfg.TopK = *ec.TopK
+	}
+	if ec.FinalTopN != nil && *ec.FinalTopN >= 0 {
+		cfg.FinalTopN = *ec.FinalTopN
+	}
+	if ec.CommunityThreshold != nil && *ec.CommunityThreshold > 0 {
+		cfg.CommunityThreshold = *ec.CommunityThreshold
+	}
+	if ec.Reranker != "" {
+		cfg.Reranker = ec.Reranker
+	}
+	if ec.ScoringInstructions != "" {
+		cfg.ScoringInstructions = ec.ScoringInstructions
+	}
+	if ec.DiversityLambda != nil {
+		cfg.DiversityLambda = *ec.DiversityLambda
+	}
+	for field, w := range ec.BM25FieldWeights {
+		cfg.BM25FieldWeights.Set(field, w) // validated by ParseExperimentConfig
+	}
+	cfg.Experiment = name
+}
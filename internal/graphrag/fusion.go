@@ -0,0 +1,174 @@
+package graphrag
+
+import (
+	"fmt"
+	"sort"
+)
+
+// fuseResults combines results using weighted scoring
+func (h *HybridSearchEngine) fuseResults(
+	bm25 []BM25Result,
+	vector []VectorSearchResult,
+	graph []CandidateResult,
+	config HybridSearchConfig,
+) []FusedCandidate {
+	// Create a map of candidate scores (keyed by PersonID string)
+	scoreMap := make(map[string]*FusedCandidate)
+
+	// Normalize and add BM25 scores.
+	// Use graph node_id as key so results merge with vector/graph sources.
+	// Fall back to cand_X if node_id is empty (shouldn't happen in practice).
+	maxBM25 := maxBM25Score(bm25)
+	for i, r := range bm25 {
+		candidateKey := r.NodeID
+		if candidateKey == "" {
+			candidateKey = fmt.Sprintf("cand_%d", r.CandidateID)
+		}
+		if _, exists := scoreMap[candidateKey]; !exists {
+			scoreMap[candidateKey] = &FusedCandidate{
+				PersonID:    candidateKey,
+				CandidateID: r.CandidateID,
+				Name:        r.Name,
+			}
+		}
+		normalizedScore := r.Rank / maxBM25
+		rrfScore := 1.0 / float64(60+i+1) // k=60 is standard RRF constant
+		scoreMap[candidateKey].BM25Score = (normalizedScore + rrfScore) / 2.0
+	}
+
+	// Normalize and add Vector scores
+	maxVector := maxVectorScore(vector)
+	for i, r := range vector {
+		if _, exists := scoreMap[r.PersonID]; !exists {
+			scoreMap[r.PersonID] = &FusedCandidate{
+				PersonID: r.PersonID,
+			}
+		}
+		normalizedScore := r.Similarity / maxVector
+		rrfScore := 1.0 / float64(60+i+1)
+		scoreMap[r.PersonID].VectorScore = (normalizedScore + rrfScore) / 2.0
+	}
+
+	// Normalize and add Graph scores
+	maxGraph := maxGraphScore(graph)
+	for i, r := range graph {
+		if _, exists := scoreMap[r.PersonID]; !exists {
+			scoreMap[r.PersonID] = &FusedCandidate{
+				PersonID: r.PersonID,
+				Name:     r.Name,
+			}
+		}
+		normalizedScore := r.MatchScore / maxGraph
+		rrfScore := 1.0 / float64(60+i+1)
+		scoreMap[r.PersonID].GraphScore = (normalizedScore + rrfScore) / 2.0
+		scoreMap[r.PersonID].Name = r.Name // Update name if not set
+	}
+
+	// Calculate fusion score
+	results := make([]FusedCandidate, 0, len(scoreMap))
+	for _, candidate := range scoreMap {
+		candidate.FusionScore = config.BM25Weight*candidate.BM25Score +
+			config.VectorWeight*candidate.VectorScore +
+			config.GraphWeight*candidate.GraphScore
+		results = append(results, *candidate)
+	}
+
+	// Sort by fusion score descending
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].FusionScore > results[j].FusionScore
+	})
+
+	// Assign ranks
+	for i := range results {
+		results[i].Rank = i + 1
+	}
+
+	return results
+}
+
+// Helper functions to find max scores for normalization
+func maxBM25Score(results []BM25Result) float64 {
+	if len(results) == 0 {
+		return 1.0
+	}
+	max := results[0].Rank
+	for _, r := range results {
+		if r.Rank > max {
+			max = r.Rank
+		}
+	}
+	if max == 0 {
+		return 1.0
+	}
+	return max
+}
+
+func maxVectorScore(results []VectorSearchResult) float64 {
+	if len(results) == 0 {
+		return 1.0
+	}
+	max := results[0].Similarity
+	for _, r := range results {
+		if r.Similarity > max {
+			max = r.Similarity
+		}
+	}
+	if max == 0 {
+		return 1.0
+	}
+	return max
+}
+
+func maxGraphScore(results []CandidateResult) float64 {
+	if len(results) == 0 {
+		return 1.0
+	}
+	max := results[0].MatchScore
+	for _, r := range results {
+		if r.MatchScore > max {
+			max = r.MatchScore
+		}
+	}
+	if max == 0 {
+		return 100.0 // Graph scores are 0-100
+	}
+	return max
+}
+
+// applyLLMScores sets each candidate's LLMScore and reasoning from scores;
+// candidates the reranker didn't score keep their fusion score.
+func applyLLMScores(candidates []FusedCandidate, scores []CandidateScore) {
+	scoreMap := make(map[string]CandidateScore, len(scores))
+	for _, score := range scores {
+		scoreMap[score.PersonID] = score
+	}
+	for i := range candidates {
+		if score, found := scoreMap[candidates[i].PersonID]; found {
+			candidates[i].LLMScore = score.Score
+			candidates[i].LLMReasoning = score.Reasoning
+		} else {
+			candidates[i].LLMScore = candidates[i].FusionScore
+		}
+	}
+}
+
+// rankCandidates is a search's final ranking: it merges the reranker's
+// scores (applyLLMScores; nil when reranking was skipped or failed), applies
+// tag boosts, sorts by the final score (stable, so fusion order breaks ties)
+// and numbers the candidates with a name and a PersonID, dropping the rest.
+func rankCandidates(candidates []FusedCandidate, scores []CandidateScore, tagBoosts map[string]float64) []FusedCandidate {
+	applyLLMScores(candidates, scores)
+	applyTagBoosts(candidates, tagBoosts)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].LLMScore > candidates[j].LLMScore
+	})
+
+	ranked := make([]FusedCandidate, 0, len(candidates))
+	for _, c := range candidates {
+		if c.Name != "" && c.PersonID != "" {
+			c.Rank = len(ranked) + 1
+			ranked = append(ranked, c)
+		}
+	}
+	return ranked
+}
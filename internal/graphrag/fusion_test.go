@@ -0,0 +1,112 @@
+package graphrag
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestFuseResults(t *testing.T) {
+	bm25 := []BM25Result{
+		{CandidateID: 1, NodeID: "a", Name: "A", Rank: 2},
+		{CandidateID: 2, Name: "B", Rank: 1},
+	}
+	vector := []VectorSearchResult{
+		{PersonID: "a", Similarity: 0.5},
+		{PersonID: "c", Similarity: 0.9},
+	}
+	graph := []CandidateResult{{PersonID: "d", Name: "D", MatchScore: 80}}
+
+	tests := []struct {
+		name    string
+		config  HybridSearchConfig
+		wantIDs []string
+	}{
+		{"bm25 only", HybridSearchConfig{BM25Weight: 1}, []string{"a", "cand_2"}},
+		{"vector only", HybridSearchConfig{VectorWeight: 1}, []string{"c", "a"}},
+		{"graph only", HybridSearchConfig{GraphWeight: 1}, []string{"d"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := (&HybridSearchEngine{}).fuseResults(bm25, vector, graph, tt.config)
+			if len(got) != 4 {
+				t.Fatalf("fuseResults returned %d candidates, want 4", len(got))
+			}
+			if ids := personIDs(got[:len(tt.wantIDs)]); !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("top candidates = %v, want %v", ids, tt.wantIDs)
+			}
+			for i, c := range got {
+				if c.Rank != i+1 {
+					t.Errorf("%s: Rank = %d, want %d", c.PersonID, c.Rank, i+1)
+				}
+			}
+		})
+	}
+
+	t.Run("scores", func(t *testing.T) {
+		config := HybridSearchConfig{BM25Weight: 0.5, VectorWeight: 0.5}
+		got := (&HybridSearchEngine{}).fuseResults(bm25, vector, graph, config)
+		var a FusedCandidate
+		for _, c := range got {
+			if c.PersonID == "a" {
+				a = c
+			}
+		}
+		// Each source score is (score/max + 1/(61+position)) / 2.
+		wantBM25 := (1 + 1.0/61) / 2
+		wantVector := (0.5/0.9 + 1.0/61) / 2
+		if math.Abs(a.BM25Score-wantBM25) > 1e-9 || math.Abs(a.VectorScore-wantVector) > 1e-9 {
+			t.Errorf("scores = %v, %v; want %v, %v", a.BM25Score, a.VectorScore, wantBM25, wantVector)
+		}
+		if want := 0.5*wantBM25 + 0.5*wantVector; math.Abs(a.FusionScore-want) > 1e-9 {
+			t.Errorf("FusionScore = %v, want %v", a.FusionScore, want)
+		}
+		if a.CandidateID != 1 || a.Name != "A" {
+			t.Errorf("candidate = %d %q, want the BM25 row's", a.CandidateID, a.Name)
+		}
+	})
+}
+
+func TestMaxScores(t *testing.T) {
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"bm25 empty", maxBM25Score(nil), 1},
+		{"bm25 zero", maxBM25Score([]BM25Result{{Rank: 0}}), 1},
+		{"bm25", maxBM25Score([]BM25Result{{Rank: 0.2}, {Rank: 0.7}}), 0.7},
+		{"vector empty", maxVectorScore(nil), 1},
+		{"vector", maxVectorScore([]VectorSearchResult{{Similarity: 0.4}, {Similarity: 0.8}}), 0.8},
+		{"graph empty", maxGraphScore(nil), 1},
+		{"graph zero", maxGraphScore([]CandidateResult{{MatchScore: 0}}), 100},
+		{"graph", maxGraphScore([]CandidateResult{{MatchScore: 40}, {MatchScore: 60}}), 60},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestApplyLLMScores(t *testing.T) {
+	tests := []struct {
+		name          string
+		scores        []CandidateScore
+		wantScore     float64
+		wantReasoning string
+	}{
+		{"scored", []CandidateScore{{PersonID: "a", Score: 90, Reasoning: "strong Go"}}, 90, "strong Go"},
+		{"not scored keeps fusion", []CandidateScore{{PersonID: "b", Score: 90}}, 0.4, ""},
+		{"no scores", nil, 0.4, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidates := []FusedCandidate{{PersonID: "a", FusionScore: 0.4}}
+			applyLLMScores(candidates, tt.scores)
+			if c := candidates[0]; c.LLMScore != tt.wantScore || c.LLMReasoning != tt.wantReasoning {
+				t.Errorf("LLMScore %v, reasoning %q; want %v, %q", c.LLMScore, c.LLMReasoning, tt.wantScore, tt.wantReasoning)
+			}
+		})
+	}
+}
@@ -0,0 +1,356 @@
+package graphrag
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"unicode"
+
+	"cv-search/internal/textnorm"
+)
+
+// ─── Skills gap analysis ─────────────────────────────────────────────────────
+//
+// A gap analysis holds one candidate against a job description, for internal
+// mobility: the LLM reads the JD's requirements, the candidate's HAS_SKILL
+// edges decide which skills match and which are missing, and a second LLM
+// call names the candidate's skills that carry over to the missing ones and
+// writes a development summary.
+
+// JobRequirements are what a job description asks for.
+type JobRequirements struct {
+	Title           string   `json:"title,omitempty"`
+	RequiredSkills  []string `json:"required_skills"`
+	PreferredSkills []string `json:"preferred_skills"`
+	MinExperience   *float64 `json:"min_experience_years,omitempty"`
+	Seniority       string   `json:"seniority,omitempty"` // Junior|Mid-level|Senior|Lead|Architect
+}
+
+// GapSkill is a JD skill the candidate has, or lacks.
+type GapSkill struct {
+	Skill       string   `json:"skill"` // as the JD names it
+	Required    bool     `json:"required"`
+	Proficiency string   `json:"proficiency,omitempty"` // the candidate's, when matched
+	Years       *float64 `json:"years,omitempty"`       // the candidate's years on it, when the CV gives them
+}
+
+// TransferableSkill is a missing JD skill that skills the candidate has
+// carry over to.
+type TransferableSkill struct {
+	Skill  string   `json:"skill"` // the missing JD skill
+	From   []string `json:"from"`  // the candidate's skills
+	Reason string   `json:"reason,omitempty"`
+}
+
+// GapAnalysis is a candidate held against a job description.
+type GapAnalysis struct {
+	Requirements     JobRequirements     `json:"requirements"`
+	Matched          []GapSkill          `json:"matched"`
+	Missing          []GapSkill          `json:"missing"`
+	Transferable     []TransferableSkill `json:"transferable"`
+	RequiredCoverage float64             `json:"required_coverage"`          // share of the required skills matched, 0-1
+	ExperienceYears  *float64            `json:"experience_years,omitempty"` // the candidate's total_experience_years
+	MeetsExperience  *bool               `json:"meets_experience,omitempty"` // nil when either side gives no years
+	Seniority        string              `json:"seniority,omitempty"`        // the candidate's
+	Summary          string              `json:"summary,omitempty"`
+	Warnings         []string            `json:"warnings,omitempty"` // e.g. the summary failed
+}
+
+// candidateSkill is a skill of the candidate's, from a HAS_SKILL edge.
+type candidateSkill struct {
+	name        string
+	proficiency string
+	years       *float64
+}
+
+// AnalyzeGap holds the person node personNodeID (graph_nodes.id) against a
+// job description. sql.ErrNoRows if the node doesn't exist. If the summary
+// call fails the analysis is returned without transferable skills or summary,
+// with a warning.
+func (s *LLMSearchEngine) AnalyzeGap(ctx context.Context, personNodeID int, jobDescription string) (*GapAnalysis, error) {
+	person, err := s.personProperties(ctx, personNodeID)
+	if err != nil {
+		return nil, err
+	}
+	skills, err := s.candidateSkills(ctx, personNodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := s.extractJobRequirements(ctx, jobDescription)
+	if err != nil {
+		return nil, err
+	}
+
+	gap := matchRequirements(req, skills)
+	gap.ExperienceYears = person.TotalExperienceYears
+	gap.Seniority = person.Seniority
+	if req.MinExperience != nil && person.TotalExperienceYears != nil {
+		meets := *person.TotalExperienceYears >= *req.MinExperience
+		gap.MeetsExperience = &meets
+	}
+
+	if err := s.assessGap(ctx, gap, person, skills); err != nil {
+		log.Printf("[GapAnalysis] %v", err)
+		gap.Warnings = append(gap.Warnings, "development summary unavailable")
+	}
+	return gap, nil
+}
+
+// personProperties returns the properties of a person node; sql.ErrNoRows
+// if it doesn't exist.
+func (s *LLMSearchEngine) personProperties(ctx context.Context, personNodeID int) (PersonProperties, error) {
+	var propsJSON []byte
+	err := s.db.QueryRowContext(ctx, `
+		SELECT properties FROM graph_nodes WHERE id = $1 AND node_type = 'person' AND deleted_at IS NULL
+	`, personNodeID).Scan(&propsJSON)
+	if err == sql.ErrNoRows {
+		return PersonProperties{}, err
+	}
+	if err != nil {
+		return PersonProperties{}, fmt.Errorf("get person node %d: %w", personNodeID, err)
+	}
+	person, err := DecodePersonProperties(propsJSON)
+	if err != nil {
+		return PersonProperties{}, fmt.Errorf("decode person node %d: %w", personNodeID, err)
+	}
+	return person, nil
+}
+
+// candidateSkills returns the skills of a person node.
+func (s *LLMSearchEngine) candidateSkills(ctx context.Context, personNodeID int) ([]candidateSkill, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT sk.properties->>'name', COALESCE(e.properties, '{}'::jsonb)
+		FROM graph_edges e
+		JOIN graph_nodes sk ON sk.id = e.target_node_id
+		WHERE e.source_node_id = $1 AND e.edge_type = 'HAS_SKILL'
+		  AND sk.node_type = 'skill' AND sk.deleted_at IS NULL
+		ORDER BY sk.properties->>'name'
+	`, personNodeID)
+	if err != nil {
+		return nil, fmt.Errorf("list skills of node %d: %w", personNodeID, err)
+	}
+	defer rows.Close()
+
+	var skills []candidateSkill
+	for rows.Next() {
+		var name sql.NullString
+		var edgeJSON []byte
+		if err := rows.Scan(&name, &edgeJSON); err != nil {
+			return nil, fmt.Errorf("scan skill of node %d: %w", personNodeID, err)
+		}
+		if strings.TrimSpace(name.String) == "" {
+			continue
+		}
+		edge, _ := DecodeHasSkillProperties(edgeJSON)
+		skills = append(skills, candidateSkill{name: name.String, proficiency: edge.Proficiency, years: edge.YearsOfExperience})
+	}
+	return skills, rows.Err()
+}
+
+// extractJobRequirements has the LLM read a job description's requirements.
+func (s *LLMSearchEngine) extractJobRequirements(ctx context.Context, jobDescription string) (JobRequirements, error) {
+	prompt := fmt.Sprintf(`You are a technical recruiter reading a job description. Extract what it requires.
+
+JOB DESCRIPTION:
+"""
+%s
+"""
+
+Return ONLY valid JSON with this structure:
+{
+  "title": "the role's title",
+  "required_skills": ["skills the role must have"],
+  "preferred_skills": ["skills that are a plus / nice to have"],
+  "min_experience_years": null,
+  "seniority": "Junior|Mid-level|Senior|Lead|Architect"
+}
+
+Rules:
+- Skills are technologies, tools, methods and domains, in canonical form ("JS" → "JavaScript", "K8s" → "Kubernetes"); not job titles, soft skills or spoken languages
+- A skill listed under requirements / "must" / "aranan nitelikler" is required; "nice to have", "a plus", "tercih sebebi" make it preferred. When the JD doesn't say, it is required
+- "5+ years" → min_experience_years: 5; leave null when no years are given
+- Return empty arrays, not null; seniority "" when not stated`, jobDescription)
+
+	response, err := s.llm.Generate(ctx, prompt)
+	if err != nil {
+		return JobRequirements{}, fmt.Errorf("LLM job description analysis failed: %w", err)
+	}
+	var req JobRequirements
+	if err := json.Unmarshal([]byte(extractJSON(response)), &req); err != nil {
+		return JobRequirements{}, fmt.Errorf("failed to parse job description analysis: %w", err)
+	}
+	req.RequiredSkills = dedupeSkills(req.RequiredSkills, nil)
+	req.PreferredSkills = dedupeSkills(req.PreferredSkills, req.RequiredSkills)
+	return req, nil
+}
+
+// matchRequirements sorts a JD's skills into the ones the candidate has and
+// the ones they lack.
+func matchRequirements(req JobRequirements, skills []candidateSkill) *GapAnalysis {
+	byKey := make(map[string]candidateSkill, len(skills))
+	for _, sk := range skills {
+		byKey[skillKey(sk.name)] = sk
+	}
+
+	gap := &GapAnalysis{Requirements: req, Matched: []GapSkill{}, Missing: []GapSkill{}, Transferable: []TransferableSkill{}}
+	matchedRequired := 0
+	for _, list := range []struct {
+		skills   []string
+		required bool
+	}{{req.RequiredSkills, true}, {req.PreferredSkills, false}} {
+		for _, name := range list.skills {
+			sk, ok := byKey[skillKey(name)]
+			if !ok {
+				gap.Missing = append(gap.Missing, GapSkill{Skill: name, Required: list.required})
+				continue
+			}
+			gap.Matched = append(gap.Matched, GapSkill{Skill: name, Required: list.required, Proficiency: sk.proficiency, Years: sk.years})
+			if list.required {
+				matchedRequired++
+			}
+		}
+	}
+	gap.RequiredCoverage = 1
+	if n := len(req.RequiredSkills); n > 0 {
+		gap.RequiredCoverage = math.Round(float64(matchedRequired)/float64(n)*100) / 100
+	}
+	return gap
+}
+
+// assessGap has the LLM name the candidate's skills that carry over to the
+// missing ones and write the development summary. Transferable skills the
+// candidate doesn't have, or for JD skills that aren't missing, are dropped.
+func (s *LLMSearchEngine) assessGap(ctx context.Context, gap *GapAnalysis, person PersonProperties, skills []candidateSkill) error {
+	var profile strings.Builder
+	if person.CurrentPosition != "" {
+		fmt.Fprintf(&profile, "Current position: %s\n", person.CurrentPosition)
+	}
+	if person.Seniority != "" {
+		fmt.Fprintf(&profile, "Seniority: %s\n", person.Seniority)
+	}
+	if person.TotalExperienceYears != nil {
+		fmt.Fprintf(&profile, "Experience: %.1f years\n", *person.TotalExperienceYears)
+	}
+	profile.WriteString("Skills:")
+	for _, sk := range skills {
+		fmt.Fprintf(&profile, "\n- %s", sk.name)
+		if sk.years != nil {
+			fmt.Fprintf(&profile, " (%.1f years)", *sk.years)
+		}
+	}
+	if len(skills) == 0 {
+		profile.WriteString(" none listed")
+	}
+
+	describe := func(list []GapSkill) string {
+		names := make([]string, 0, len(list))
+		for _, g := range list {
+			if g.Required {
+				names = append(names, g.Skill+" (required)")
+			} else {
+				names = append(names, g.Skill+" (preferred)")
+			}
+		}
+		if len(names) == 0 {
+			return "none"
+		}
+		return strings.Join(names, ", ")
+	}
+	experience := "not stated"
+	if gap.Requirements.MinExperience != nil {
+		experience = fmt.Sprintf("%.0f+ years", *gap.Requirements.MinExperience)
+	}
+
+	prompt := fmt.Sprintf(`You are a career development advisor assessing an internal candidate for a role.
+
+ROLE: %s (seniority: %s, experience: %s)
+MATCHED SKILLS: %s
+MISSING SKILLS: %s
+
+CANDIDATE:
+%s
+
+Return ONLY valid JSON:
+{
+  "transferable": [{"skill": "a missing skill", "from": ["candidate skills that carry over to it"], "reason": "one short sentence"}],
+  "summary": "3-5 sentences"
+}
+
+Rules:
+- transferable: only missing skills a candidate skill genuinely carries over to (Java → Kotlin, PostgreSQL → MySQL, Jenkins → GitHub Actions); "from" uses the candidate's skill names exactly as listed. Omit missing skills nothing carries over to
+- summary: how ready the candidate is for the role, which gaps matter most, and a concrete development plan (what to learn first, which existing skills to build on). Plain text, no markdown, in the language the role title is written in`,
+		gap.Requirements.Title, gap.Requirements.Seniority, experience,
+		describe(gap.Matched), describe(gap.Missing), profile.String())
+
+	response, err := s.llm.Generate(ctx, prompt)
+	if err != nil {
+		return fmt.Errorf("LLM gap assessment failed: %w", err)
+	}
+	var out struct {
+		Transferable []TransferableSkill `json:"transferable"`
+		Summary      string              `json:"summary"`
+	}
+	if err := json.Unmarshal([]byte(extractJSON(response)), &out); err != nil {
+		return fmt.Errorf("failed to parse gap assessment: %w", err)
+	}
+
+	missing := make(map[string]string, len(gap.Missing))
+	for _, g := range gap.Missing {
+		missing[skillKey(g.Skill)] = g.Skill
+	}
+	have := make(map[string]string, len(skills))
+	for _, sk := range skills {
+		have[skillKey(sk.name)] = sk.name
+	}
+	for _, t := range out.Transferable {
+		skill, ok := missing[skillKey(t.Skill)]
+		if !ok {
+			continue
+		}
+		var from []string
+		for _, f := range t.From {
+			if name, ok := have[skillKey(f)]; ok {
+				from = append(from, name)
+			}
+		}
+		if len(from) > 0 {
+			gap.Transferable = append(gap.Transferable, TransferableSkill{Skill: skill, From: from, Reason: strings.TrimSpace(t.Reason)})
+		}
+	}
+	gap.Summary = strings.TrimSpace(out.Summary)
+	return nil
+}
+
+// skillKey is what two spellings of a skill share: folded, letters, digits,
+// "+" and "#" only, so "Node.js", "NodeJS" and "node js" are one skill and
+// C, C++ and C# aren't.
+func skillKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '+' || r == '#' {
+			return r
+		}
+		return -1
+	}, textnorm.Fold(name))
+}
+
+// dedupeSkills drops empty names, repeats and names already in other.
+func dedupeSkills(names, other []string) []string {
+	seen := make(map[string]bool)
+	for _, o := range other {
+		seen[skillKey(o)] = true
+	}
+	out := []string{}
+	for _, n := range names {
+		n = strings.TrimSpace(n)
+		if k := skillKey(n); k != "" && !seen[k] {
+			seen[k] = true
+			out = append(out, n)
+		}
+	}
+	return out
+}
@@ -3,21 +3,34 @@ package graphrag
 import (
 	"context"
 	"cv-search/internal/llm"
+	"cv-search/internal/tenant"
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 )
 
 type GraphBuilder struct {
-	db *sql.DB
+	db        *sql.DB
+	salaryBox SalaryBox // nil: CVs' salaries aren't kept
 }
 
 func NewGraphBuilder(db *sql.DB) *GraphBuilder {
 	return &GraphBuilder{db: db}
 }
 
-// CreateNodes inserts or updates graph nodes
+// SetSalaryBox makes the builder keep the salaries CVs state, sealed with
+// box, on their person nodes.
+func (g *GraphBuilder) SetSalaryBox(box SalaryBox) {
+	g.salaryBox = box
+}
+
+// CreateNodes inserts or updates graph nodes of ctx's organization; an
+// existing node is marked seen again (last_seen_at) and keeps its status,
+// which comes from the candidate, not the CV
 func (g *GraphBuilder) CreateNodes(ctx context.Context, entities []Entity) error {
+	orgID := tenant.OrgID(ctx)
 	for _, entity := range entities {
 		props, err := json.Marshal(entity.Properties)
 		if err != nil {
@@ -25,11 +38,12 @@ func (g *GraphBuilder) CreateNodes(ctx context.Context, entities []Entity) error
 		}
 
 		_, err = g.db.ExecContext(ctx, `
-			INSERT INTO graph_nodes (node_type, node_id, properties)
-			VALUES ($1, $2, $3)
-			ON CONFLICT (node_type, node_id) 
-			DO UPDATE SET properties = EXCLUDED.properties
-		`, entity.Type, entity.Value, props)
+			INSERT INTO graph_nodes (node_type, node_id, properties, org_id)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (org_id, node_type, node_id) 
+			DO UPDATE SET properties = EXCLUDED.properties || jsonb_strip_nulls(jsonb_build_object('status', graph_nodes.properties->'status')),
+			              deleted_at = NULL, last_seen_at = NOW()
+		`, entity.Type, entity.Value, props, orgID)
 
 		if err != nil {
 			return fmt.Errorf("failed to create node %s:%s: %w", entity.Type, entity.Value, err)
@@ -39,14 +53,15 @@ func (g *GraphBuilder) CreateNodes(ctx context.Context, entities []Entity) error
 	return nil
 }
 
-// CreateEdges inserts relationships between nodes
+// CreateEdges inserts relationships between nodes of ctx's organization
 func (g *GraphBuilder) CreateEdges(ctx context.Context, relationships []Relationship) error {
+	orgID := tenant.OrgID(ctx)
 	for _, rel := range relationships {
 		// Get source node ID
 		var sourceID int
 		err := g.db.QueryRowContext(ctx, `
-			SELECT id FROM graph_nodes WHERE node_type = $1 AND node_id = $2
-		`, rel.SourceType, rel.SourceID).Scan(&sourceID)
+			SELECT id FROM graph_nodes WHERE node_type = $1 AND node_id = $2 AND org_id = $3
+		`, rel.SourceType, rel.SourceID, orgID).Scan(&sourceID)
 
 		if err != nil {
 			// Skip if source node doesn't exist
@@ -56,8 +71,8 @@ func (g *GraphBuilder) CreateEdges(ctx context.Context, relationships []Relation
 		// Get target node ID
 		var targetID int
 		err = g.db.QueryRowContext(ctx, `
-			SELECT id FROM graph_nodes WHERE node_type = $1 AND node_id = $2
-		`, rel.TargetType, rel.TargetID).Scan(&targetID)
+			SELECT id FROM graph_nodes WHERE node_type = $1 AND node_id = $2 AND org_id = $3
+		`, rel.TargetType, rel.TargetID, orgID).Scan(&targetID)
 
 		if err != nil {
 			// Skip if target node doesn't exist
@@ -67,9 +82,9 @@ func (g *GraphBuilder) CreateEdges(ctx context.Context, relationships []Relation
 		// Insert edge
 		props, _ := json.Marshal(rel.Properties)
 		_, err = g.db.ExecContext(ctx, `
-			INSERT INTO graph_edges (source_node_id, target_node_id, edge_type, properties)
-			VALUES ($1, $2, $3, $4)
-		`, sourceID, targetID, rel.EdgeType, props)
+			INSERT INTO graph_edges (source_node_id, target_node_id, edge_type, properties, org_id)
+			VALUES ($1, $2, $3, $4, $5)
+		`, sourceID, targetID, rel.EdgeType, props, orgID)
 
 		if err != nil {
 			return fmt.Errorf("failed to create edge: %w", err)
@@ -79,13 +94,14 @@ func (g *GraphBuilder) CreateEdges(ctx context.Context, relationships []Relation
 	return nil
 }
 
-// QueryGraph performs graph traversal queries
+// QueryGraph performs graph traversal queries from a node of ctx's
+// organization
 func (g *GraphBuilder) QueryGraph(ctx context.Context, nodeType, nodeID string, depth int) ([]Entity, []Relationship, error) {
 	// Start with the initial node
 	var startNodeDBID int
 	err := g.db.QueryRowContext(ctx, `
-		SELECT id FROM graph_nodes WHERE node_type = $1 AND node_id = $2
-	`, nodeType, nodeID).Scan(&startNodeDBID)
+		SELECT id FROM graph_nodes WHERE node_type = $1 AND node_id = $2 AND org_id = $3
+	`, nodeType, nodeID, tenant.OrgID(ctx)).Scan(&startNodeDBID)
 
 	if err != nil {
 		return nil, nil, fmt.Errorf("start node not found: %w", err)
@@ -179,16 +195,38 @@ func (g *GraphBuilder) BuildFromLLMExtraction(ctx context.Context, cvID int, ext
 	// 1. Create Person node from candidate
 	if candidate, ok := ext["candidate"].(map[string]interface{}); ok {
 		personID := fmt.Sprintf("person_%d", cvID)
+		person := PersonProperties{
+			CVID:            cvID,
+			Name:            propString(candidate["name"]),
+			CurrentPosition: propString(candidate["current_position"]),
+			Seniority:       propString(candidate["seniority"]),
+		}
+		var llmYears *float64
+		if years, ok := propFloat(candidate["total_experience_years"]); ok {
+			llmYears = &years
+		}
+		companies, _ := ext["companies"].([]llm.Company)
+		person.setExperienceYears(llmYears, companyRoleSpans(companies), time.Now())
+		person.WorkModes = NormalizeWorkModes(propStrings(candidate["work_modes"]))
+		person.EmploymentTypes = NormalizeEmploymentTypes(propStrings(candidate["employment_types"]))
+		if days, ok := NoticePeriodDays(candidate["notice_period"]); ok {
+			person.NoticePeriodDays = &days
+		}
+		person.AvailableFrom = NormalizeAvailableFrom(propString(candidate["available_from"]))
+		if salary, _ := candidate["salary"].(*llm.Salary); salary != nil && g.salaryBox != nil {
+			if s := NormalizeSalary(salary); s != nil {
+				sealed, err := SealSalary(g.salaryBox, s)
+				if err != nil {
+					return err
+				}
+				person.SalarySealed = sealed
+			}
+		}
+		g.resolvePersonLocation(ctx, &person, propStrings(ext["locations"]))
 		entities = append(entities, Entity{
-			Type:  "person",
-			Value: personID,
-			Properties: map[string]interface{}{
-				"cv_id":                  cvID,
-				"name":                   candidate["name"],
-				"current_position":       candidate["current_position"],
-				"seniority":              candidate["seniority"],
-				"total_experience_years": candidate["total_experience_years"],
-			},
+			Type:       "person",
+			Value:      personID,
+			Properties: person.Map(),
 		})
 
 		// 2. Create Skill nodes and HAS_SKILL relationships
@@ -203,10 +241,10 @@ func (g *GraphBuilder) BuildFromLLMExtraction(ctx context.Context, cvID int, ext
 					entities = append(entities, Entity{
 						Type:  "skill",
 						Value: skillID,
-						Properties: map[string]interface{}{
-							"name":        skill.Name,
-							"proficiency": skill.Proficiency,
-						},
+						Properties: SkillProperties{
+							Name:        skill.Name,
+							Proficiency: skill.Proficiency,
+						}.Map(),
 					})
 
 					// Create HAS_SKILL relationship
@@ -216,6 +254,9 @@ func (g *GraphBuilder) BuildFromLLMExtraction(ctx context.Context, cvID int, ext
 					if skill.Years != nil {
 						relProps["years_of_experience"] = *skill.Years
 					}
+					if skill.LastUsedYear != nil {
+						relProps["last_used_year"] = skill.LastUsedYear
+					}
 					relationships = append(relationships, Relationship{
 						SourceType: "person",
 						SourceID:   personID,
@@ -237,11 +278,9 @@ func (g *GraphBuilder) BuildFromLLMExtraction(ctx context.Context, cvID int, ext
 
 					// Create company node
 					entities = append(entities, Entity{
-						Type:  "company",
-						Value: companyID,
-						Properties: map[string]interface{}{
-							"name": company.Name,
-						},
+						Type:       "company",
+						Value:      companyID,
+						Properties: CompanyProperties{Name: company.Name}.Map(),
 					})
 
 					// Determine edge type based on is_current
@@ -260,7 +299,11 @@ func (g *GraphBuilder) BuildFromLLMExtraction(ctx context.Context, cvID int, ext
 						TargetID:   companyID,
 						EdgeType:   edgeType,
 						Properties: map[string]interface{}{
-							"position": company.Position,
+							"position":       company.Position,
+							"start_year":     company.StartYear,
+							"end_year":       company.EndYear,
+							"duration_years": company.DurationYears,
+							"is_current":     edgeType == "WORKS_AT",
 						},
 					})
 				}
@@ -275,15 +318,18 @@ func (g *GraphBuilder) BuildFromLLMExtraction(ctx context.Context, cvID int, ext
 					eduID := fmt.Sprintf("education_%s", edu.Institution)
 
 					// Create education node
+					eduProps := EducationProperties{
+						Institution: edu.Institution,
+						Degree:      edu.Degree,
+						Field:       edu.Field,
+					}
+					if y, ok := propInt(edu.GraduationYear); ok {
+						eduProps.GraduationYear = y
+					}
 					entities = append(entities, Entity{
-						Type:  "education",
-						Value: eduID,
-						Properties: map[string]interface{}{
-							"institution":     edu.Institution,
-							"degree":          edu.Degree,
-							"field":           edu.Field,
-							"graduation_year": edu.GraduationYear,
-						},
+						Type:       "education",
+						Value:      eduID,
+						Properties: eduProps.Map(),
 					})
 
 					relationships = append(relationships, Relationship{
@@ -300,6 +346,124 @@ func (g *GraphBuilder) BuildFromLLMExtraction(ctx context.Context, cvID int, ext
 				}
 			}
 		}
+
+		// 5. Create Certification nodes and HAS_CERTIFICATION relationships
+		if certs, ok := ext["certifications"].([]llm.Certification); ok {
+			for _, cert := range certs {
+				if strings.TrimSpace(cert.Name) == "" {
+					continue
+				}
+				certID := fmt.Sprintf("certification_%s", cert.Name)
+				entities = append(entities, Entity{
+					Type:       "certification",
+					Value:      certID,
+					Properties: CertificationProperties{Name: cert.Name, Issuer: cert.Issuer}.Map(),
+				})
+
+				relProps := map[string]interface{}{}
+				if y := propYear(cert.Year); y != 0 {
+					relProps["year"] = y
+				}
+				relationships = append(relationships, Relationship{
+					SourceType: "person",
+					SourceID:   personID,
+					TargetType: "certification",
+					TargetID:   certID,
+					EdgeType:   "HAS_CERTIFICATION",
+					Properties: relProps,
+				})
+			}
+		}
+
+		// 6. Create Language nodes and SPEAKS relationships
+		if languages, ok := ext["languages"].([]llm.Language); ok {
+			for _, lang := range languages {
+				if strings.TrimSpace(lang.Name) == "" {
+					continue
+				}
+				langID := fmt.Sprintf("language_%s", lang.Name)
+				entities = append(entities, Entity{
+					Type:       "language",
+					Value:      langID,
+					Properties: LanguageProperties{Name: lang.Name}.Map(),
+				})
+
+				relProps := map[string]interface{}{}
+				if level := NormalizeLanguageLevel(lang.Proficiency); level != "" {
+					relProps["proficiency"] = level
+				}
+				relationships = append(relationships, Relationship{
+					SourceType: "person",
+					SourceID:   personID,
+					TargetType: "language",
+					TargetID:   langID,
+					EdgeType:   "SPEAKS",
+					Properties: relProps,
+				})
+			}
+		}
+
+		// 7. Create Project nodes with WORKED_ON relationships from the person
+		// and USES_SKILL relationships to the skills they were built with
+		if projects, ok := ext["projects"].([]llm.Project); ok {
+			// Skill nodes already added above keep their properties.
+			haveSkill := map[string]bool{}
+			for _, e := range entities {
+				if e.Type == "skill" {
+					haveSkill[e.Value] = true
+				}
+			}
+			for i, project := range projects {
+				if strings.TrimSpace(project.Name) == "" && strings.TrimSpace(project.Description) == "" {
+					continue
+				}
+				projectID := fmt.Sprintf("project_%d_%d", cvID, i)
+				entities = append(entities, Entity{
+					Type:  "project",
+					Value: projectID,
+					Properties: ProjectProperties{
+						CVID:         cvID,
+						Name:         project.Name,
+						Description:  project.Description,
+						Role:         project.Role,
+						Impact:       project.Impact,
+						Technologies: project.Technologies,
+						Company:      project.Company,
+					}.Map(),
+				})
+				relationships = append(relationships, Relationship{
+					SourceType: "person",
+					SourceID:   personID,
+					TargetType: "project",
+					TargetID:   projectID,
+					EdgeType:   "WORKED_ON",
+					Properties: map[string]interface{}{},
+				})
+
+				for _, tech := range project.Technologies {
+					if strings.TrimSpace(tech) == "" {
+						continue
+					}
+					skillID := fmt.Sprintf("skill_%s", tech)
+					if !haveSkill[skillID] {
+						haveSkill[skillID] = true
+						entities = append(entities, Entity{
+							Type:       "skill",
+							Value:      skillID,
+							Properties: SkillProperties{Name: tech}.Map(),
+						})
+					}
+					relationships = append(relationships, Relationship{
+						SourceType: "project",
+						SourceID:   projectID,
+						TargetType: "skill",
+						TargetID:   skillID,
+						EdgeType:   "USES_SKILL",
+						Properties: map[string]interface{}{},
+					})
+				}
+			}
+		}
 	}
 
 	// Create all nodes
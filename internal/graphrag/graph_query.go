@@ -0,0 +1,571 @@
+package graphrag
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"cv-search/internal/tenant"
+)
+
+// ─── Graph queries ───────────────────────────────────────────────────────────
+//
+// A small declarative language over the organization's graph, for analysts
+// who need more than search without database access:
+//
+//	MATCH person
+//	WITH skill "Go" AND WORKED_AT company "Garanti" AND seniority = "Senior"
+//	RETURN name, location, skill
+//	LIMIT 20
+//
+// MATCH names the node type. WITH (or WHERE) conditions, ANDed, each
+// optionally NOT, are either a property compared to a value (=, !=, <, <=,
+// >, >=, CONTAINS), or a linked node: an optional edge type, the node type
+// and optionally its name (compared like a property, = when no operator is
+// given). Edges match in either direction. RETURN lists properties, id and
+// node types, the last as the names of the linked nodes of that type
+// (default: id, name). Keywords are case-insensitive; a value is a quoted
+// string, a number, true / false or a bare word.
+//
+// Node types and properties come from nodePropertySchemas, edge types from
+// graphEdgeTypes; anything else is an error. Every value and name is bound
+// as a parameter: the SQL's text only ever depends on the query's shape.
+// Deleted nodes are left out; person nodes of every status are matched.
+
+// graphEdgeTypes are the edge types GraphBuilder creates.
+var graphEdgeTypes = []string{"HAS_SKILL", "WORKS_AT", "WORKED_AT", "GRADUATED_FROM", "HAS_CERTIFICATION", "SPEAKS", "WORKED_ON", "USES_SKILL"}
+
+// graphQueryOps are a graph query's comparison operators, besides CONTAINS.
+var graphQueryOps = []string{"=", "!=", "<", "<=", ">", ">="}
+
+// graphQueryHiddenFields are properties a graph query can't read.
+var graphQueryHiddenFields = []string{"salary_sealed"}
+
+// Graph query limits.
+const (
+	GraphQueryDefaultLimit = 100
+	GraphQueryMaxLimit     = 1000
+	graphQueryMaxClauses   = 20 // conditions, and returned fields
+	graphQueryTimeout      = 10 * time.Second
+)
+
+// GraphQuery is a parsed graph query, see ParseGraphQuery.
+type GraphQuery struct {
+	Match      string
+	Conditions []GraphCondition
+	Return     []string
+	Limit      int
+}
+
+// GraphCondition is a condition of a graph query: on a property of the
+// matched node (Field), or on a node linked to it (NodeType).
+type GraphCondition struct {
+	Not      bool
+	Field    string // property conditions; "id" is the node's key
+	EdgeType string // link conditions: "" = any edge
+	NodeType string // link conditions: the linked node's type
+	Op       string // = != < <= > >= contains; "" = a link condition without a name
+	Value    interface{}
+	kind     propKind
+}
+
+// GraphQueryResult is what a graph query returned: one row per matched
+// node, keyed by the query's returned fields.
+type GraphQueryResult struct {
+	Fields []string                     `json:"fields"`
+	Rows   []map[string]json.RawMessage `json:"rows"`
+}
+
+type graphToken struct {
+	kind string // word | string | op | ,
+	text string
+}
+
+// ParseGraphQuery parses a graph query.
+func ParseGraphQuery(query string) (*GraphQuery, error) {
+	tokens, err := tokenizeGraphQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &graphQueryParser{tokens: tokens}
+	return p.parse()
+}
+
+// tokenizeGraphQuery splits query into words, quoted strings, comparison
+// operators and commas.
+func tokenizeGraphQuery(query string) ([]graphToken, error) {
+	var tokens []graphToken
+	rs := []rune(query)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			i++
+		case r == ',':
+			tokens = append(tokens, graphToken{kind: ",", text: ","})
+			i++
+		case r == '"' || r == '\'':
+			end := i + 1
+			for end < len(rs) && rs[end] != r {
+				end++
+			}
+			if end == len(rs) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			tokens = append(tokens, graphToken{kind: "string", text: string(rs[i+1 : end])})
+			i = end + 1
+		case r == '=' || r == '!' || r == '<' || r == '>':
+			op := string(r)
+			if i+1 < len(rs) && rs[i+1] == '=' {
+				op += "="
+			}
+			if op == "!" {
+				return nil, fmt.Errorf("unexpected !")
+			}
+			tokens = append(tokens, graphToken{kind: "op", text: op})
+			i += len(op)
+		default:
+			end := i
+			for end < len(rs) && !strings.ContainsRune(" \t\n\r,\"'=!<>", rs[end]) {
+				end++
+			}
+			tokens = append(tokens, graphToken{kind: "word", text: string(rs[i:end])})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+type graphQueryParser struct {
+	tokens []graphToken
+	pos    int
+}
+
+// keyword reports whether the next token is keyword kw (case-insensitive)
+// and consumes it if so.
+func (p *graphQueryParser) keyword(kw string) bool {
+	if p.pos < len(p.tokens) && p.tokens[p.pos].kind == "word" && strings.EqualFold(p.tokens[p.pos].text, kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// next returns the next token, ok false at the end of the query.
+func (p *graphQueryParser) next() (graphToken, bool) {
+	if p.pos == len(p.tokens) {
+		return graphToken{}, false
+	}
+	p.pos++
+	return p.tokens[p.pos-1], true
+}
+
+// word returns the next token as a word, what describing it in the error
+// when it isn't one.
+func (p *graphQueryParser) word(what string) (string, error) {
+	t, ok := p.next()
+	if !ok {
+		return "", fmt.Errorf("query ends where %s is expected", what)
+	}
+	if t.kind != "word" {
+		return "", fmt.Errorf("expected %s, got %q", what, t.text)
+	}
+	return t.text, nil
+}
+
+func (p *graphQueryParser) parse() (*GraphQuery, error) {
+	if !p.keyword("MATCH") {
+		return nil, fmt.Errorf("query must start with MATCH")
+	}
+	match, err := p.word("a node type")
+	if err != nil {
+		return nil, err
+	}
+	q := &GraphQuery{Match: strings.ToLower(match), Limit: GraphQueryDefaultLimit}
+	if _, ok := nodePropertySchemas[q.Match]; !ok {
+		return nil, fmt.Errorf("unknown node type %q (%s)", match, strings.Join(graphNodeTypes(), ", "))
+	}
+
+	if p.keyword("WITH") || p.keyword("WHERE") {
+		for {
+			c, err := p.parseCondition(q.Match)
+			if err != nil {
+				return nil, err
+			}
+			q.Conditions = append(q.Conditions, c)
+			if !p.keyword("AND") {
+				break
+			}
+		}
+		if len(q.Conditions) > graphQueryMaxClauses {
+			return nil, fmt.Errorf("more than %d conditions", graphQueryMaxClauses)
+		}
+	}
+
+	if p.keyword("RETURN") {
+		for {
+			field, err := p.word("a field")
+			if err != nil {
+				return nil, err
+			}
+			field = strings.ToLower(field)
+			if !linksTo(q.Match, field) {
+				if _, err := fieldKind(q.Match, field); err != nil {
+					return nil, err
+				}
+			}
+			if !slices.Contains(q.Return, field) {
+				q.Return = append(q.Return, field)
+			}
+			if p.pos == len(p.tokens) || p.tokens[p.pos].kind != "," {
+				break
+			}
+			p.pos++
+		}
+		if len(q.Return) > graphQueryMaxClauses {
+			return nil, fmt.Errorf("more than %d returned fields", graphQueryMaxClauses)
+		}
+	} else {
+		q.Return = []string{"id", "name"}
+		if q.Match == "education" {
+			q.Return[1] = "institution"
+		}
+	}
+
+	if p.keyword("LIMIT") {
+		text, err := p.word("a limit")
+		if err != nil {
+			return nil, err
+		}
+		n, err := strconv.Atoi(text)
+		if err != nil || n < 1 || n > GraphQueryMaxLimit {
+			return nil, fmt.Errorf("LIMIT must be between 1 and %d", GraphQueryMaxLimit)
+		}
+		q.Limit = n
+	}
+
+	if t, ok := p.next(); ok {
+		return nil, fmt.Errorf("unexpected %q", t.text)
+	}
+	return q, nil
+}
+
+// parseCondition parses a condition on a node of type match.
+func (p *graphQueryParser) parseCondition(match string) (GraphCondition, error) {
+	var c GraphCondition
+	c.Not = p.keyword("NOT")
+	word, err := p.word("a condition")
+	if err != nil {
+		return c, err
+	}
+
+	if edgeType := strings.ToUpper(word); slices.Contains(graphEdgeTypes, edgeType) {
+		c.EdgeType = edgeType
+		if word, err = p.word("a node type after " + edgeType); err != nil {
+			return c, err
+		}
+		if _, ok := nodePropertySchemas[strings.ToLower(word)]; !ok {
+			return c, fmt.Errorf("expected a node type after %s, got %q", edgeType, word)
+		}
+	}
+	if nodeType := strings.ToLower(word); c.EdgeType != "" || linksTo(match, nodeType) {
+		// A linked node, by name if one follows
+		c.NodeType = nodeType
+		c.kind = kindString
+		if p.pos == len(p.tokens) {
+			return c, nil
+		}
+		switch t := p.tokens[p.pos]; {
+		case t.kind == "op" || (t.kind == "word" && strings.EqualFold(t.text, "CONTAINS")):
+			return c, p.parseComparison(&c)
+		case t.kind == "string" || (t.kind == "word" && !isGraphQueryKeyword(t.text)):
+			p.pos++
+			c.Op, c.Value = "=", t.text
+		}
+		return c, nil
+	}
+
+	c.Field = strings.ToLower(word)
+	if c.kind, err = fieldKind(match, c.Field); err != nil {
+		return c, err
+	}
+	return c, p.parseComparison(&c)
+}
+
+// parseComparison parses the operator and value of c, a value of c.kind.
+func (p *graphQueryParser) parseComparison(c *GraphCondition) error {
+	subject := c.Field
+	if subject == "" {
+		subject = c.NodeType
+	}
+	t, ok := p.next()
+	switch {
+	case !ok:
+		return fmt.Errorf("query ends where an operator after %s is expected", subject)
+	case t.kind == "op" && slices.Contains(graphQueryOps, t.text):
+		c.Op = t.text
+	case t.kind == "op":
+		return fmt.Errorf("unknown operator %q after %s: use =, !=, <, <=, >, >= or CONTAINS", t.text, subject)
+	case t.kind == "word" && strings.EqualFold(t.text, "CONTAINS"):
+		c.Op = "contains"
+	default:
+		return fmt.Errorf("expected an operator after %s, got %q", subject, t.text)
+	}
+
+	t, ok = p.next()
+	if !ok || (t.kind != "word" && t.kind != "string") {
+		return fmt.Errorf("expected a value after %s %s", subject, c.Op)
+	}
+	switch c.kind {
+	case kindNumber:
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return fmt.Errorf("%s is a number, got %q", subject, t.text)
+		}
+		if c.Op == "contains" {
+			return fmt.Errorf("CONTAINS needs a text field, %s is a number", subject)
+		}
+		c.Value = f
+	case kindBool:
+		b, err := strconv.ParseBool(t.text)
+		if err != nil {
+			return fmt.Errorf("%s is true or false, got %q", subject, t.text)
+		}
+		if c.Op != "=" && c.Op != "!=" {
+			return fmt.Errorf("%s can only be compared with = or !=", subject)
+		}
+		c.Value = b
+	case kindStrings:
+		if c.Op != "=" && c.Op != "!=" && c.Op != "contains" {
+			return fmt.Errorf("%s is a list: use =, != or CONTAINS", subject)
+		}
+		c.Value = t.text
+	default:
+		c.Value = t.text
+	}
+	return nil
+}
+
+// fieldKind returns the kind of property field of nodeType's nodes.
+func fieldKind(nodeType, field string) (propKind, error) {
+	if field == "id" {
+		return kindString, nil
+	}
+	kind, ok := nodePropertySchemas[nodeType][field]
+	if !ok || slices.Contains(graphQueryHiddenFields, field) {
+		return 0, fmt.Errorf("unknown %s field %q", nodeType, field)
+	}
+	return kind, nil
+}
+
+// linksTo reports whether word, in a query matching nodeType, names the
+// linked nodes of a type rather than a property: a property of the same
+// name wins.
+func linksTo(nodeType, word string) bool {
+	_, isField := nodePropertySchemas[nodeType][word]
+	_, isType := nodePropertySchemas[word]
+	return isType && !isField
+}
+
+func isGraphQueryKeyword(word string) bool {
+	for _, kw := range []string{"AND", "NOT", "RETURN", "LIMIT", "CONTAINS"} {
+		if strings.EqualFold(word, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// graphNodeTypes returns the node types, sorted.
+func graphNodeTypes() []string {
+	types := make([]string, 0, len(nodePropertySchemas))
+	for t := range nodePropertySchemas {
+		types = append(types, t)
+	}
+	slices.Sort(types)
+	return types
+}
+
+// graphQueryArgs collects a query's parameters.
+type graphQueryArgs []interface{}
+
+// add binds v and returns its placeholder.
+func (a *graphQueryArgs) add(v interface{}) string {
+	*a = append(*a, v)
+	return fmt.Sprintf("$%d", len(*a))
+}
+
+// graphNodeNameSQL is the name of node alias: an education node's is its
+// institution.
+func graphNodeNameSQL(alias string) string {
+	return fmt.Sprintf("COALESCE(%[1]s.properties->>'name', %[1]s.properties->>'institution')", alias)
+}
+
+// graphLinkedSQL is the FROM / WHERE of the nodes t of type nodeType linked
+// to node n, by an edge e (of edgeType unless "") either way.
+func graphLinkedSQL(args *graphQueryArgs, edgeType, nodeType string) string {
+	from := `graph_edges e
+		JOIN graph_nodes t ON t.id = CASE WHEN e.source_node_id = n.id THEN e.target_node_id ELSE e.source_node_id END
+		WHERE (e.source_node_id = n.id OR e.target_node_id = n.id)
+		  AND t.node_type = ` + args.add(nodeType) + ` AND t.deleted_at IS NULL`
+	if edgeType != "" {
+		from += ` AND e.edge_type = ` + args.add(edgeType)
+	}
+	return from
+}
+
+// compareSQL is the condition that a property of kind, jsonExpr as jsonb
+// and textExpr as text, compares to value with op. An operator kind can't
+// be compared with is an error: only known operators reach the SQL.
+func compareSQL(args *graphQueryArgs, jsonExpr, textExpr string, kind propKind, op string, value interface{}) (string, error) {
+	ordering := slices.Contains([]string{"<", "<=", ">", ">="}, op)
+	switch kind {
+	case kindNumber:
+		num := fmt.Sprintf("(CASE WHEN jsonb_typeof(%s) = 'number' THEN (%s)::float END)", jsonExpr, textExpr)
+		switch {
+		case op == "!=":
+			return fmt.Sprintf("%s IS DISTINCT FROM %s::float", num, args.add(value)), nil
+		case op == "=" || ordering:
+			return fmt.Sprintf("%s %s %s::float", num, op, args.add(value)), nil
+		}
+	case kindBool:
+		b := fmt.Sprintf("(CASE WHEN jsonb_typeof(%s) = 'boolean' THEN (%s)::boolean END)", jsonExpr, textExpr)
+		switch op {
+		case "=":
+			return fmt.Sprintf("%s = %s::boolean", b, args.add(value)), nil
+		case "!=":
+			return fmt.Sprintf("%s IS DISTINCT FROM %s::boolean", b, args.add(value)), nil
+		}
+	case kindStrings:
+		var match string
+		switch op {
+		case "=", "!=":
+			match = fmt.Sprintf("lower(x) = lower(%s::text)", args.add(value))
+		case "contains":
+			match = fmt.Sprintf("strpos(lower(x), lower(%s::text)) > 0", args.add(value))
+		default:
+			return "", fmt.Errorf("operator %q can't compare a list", op)
+		}
+		exists := fmt.Sprintf("EXISTS (SELECT 1 FROM jsonb_array_elements_text(CASE WHEN jsonb_typeof(%s) = 'array' THEN %s END) x WHERE %s)", jsonExpr, jsonExpr, match)
+		if op == "!=" {
+			return "NOT " + exists, nil
+		}
+		return exists, nil
+	default:
+		switch {
+		case op == "=":
+			return fmt.Sprintf("lower(%s) = lower(%s::text)", textExpr, args.add(value)), nil
+		case op == "!=":
+			return fmt.Sprintf("lower(%s) IS DISTINCT FROM lower(%s::text)", textExpr, args.add(value)), nil
+		case op == "contains":
+			return fmt.Sprintf("strpos(lower(%s), lower(%s::text)) > 0", textExpr, args.add(value)), nil
+		case ordering:
+			return fmt.Sprintf("%s %s %s::text", textExpr, op, args.add(value)), nil
+		}
+	}
+	return "", fmt.Errorf("unknown operator %q", op)
+}
+
+// propertySQL returns node alias's property field as jsonb and as text.
+func propertySQL(args *graphQueryArgs, alias, field string) (string, string) {
+	if field == "id" {
+		return "to_jsonb(" + alias + ".node_id)", alias + ".node_id"
+	}
+	key := args.add(field)
+	return fmt.Sprintf("%s.properties->%s::text", alias, key), fmt.Sprintf("%s.properties->>%s::text", alias, key)
+}
+
+// SQL compiles q to a query over orgID's graph and its parameters. The
+// columns are q.Return's fields as jsonb. A condition with an operator its
+// property can't be compared with is an error.
+func (q *GraphQuery) SQL(orgID int) (string, []interface{}, error) {
+	args := &graphQueryArgs{}
+	var columns []string
+	for _, field := range q.Return {
+		if linksTo(q.Match, field) {
+			columns = append(columns, fmt.Sprintf("(SELECT COALESCE(jsonb_agg(DISTINCT %s), '[]'::jsonb) FROM %s)",
+				graphNodeNameSQL("t"), graphLinkedSQL(args, "", field)))
+			continue
+		}
+		jsonExpr, _ := propertySQL(args, "n", field)
+		columns = append(columns, jsonExpr)
+	}
+
+	conditions := []string{
+		"n.org_id = " + args.add(orgID),
+		"n.node_type = " + args.add(q.Match),
+		"n.deleted_at IS NULL",
+	}
+	for _, c := range q.Conditions {
+		var cond string
+		if c.NodeType != "" {
+			cond = "EXISTS (SELECT 1 FROM " + graphLinkedSQL(args, c.EdgeType, c.NodeType)
+			if c.Op != "" {
+				name, err := compareSQL(args, "", graphNodeNameSQL("t"), kindString, c.Op, c.Value)
+				if err != nil {
+					return "", nil, fmt.Errorf("%s name: %w", c.NodeType, err)
+				}
+				cond += " AND " + name
+			}
+			cond += ")"
+		} else {
+			jsonExpr, textExpr := propertySQL(args, "n", c.Field)
+			var err error
+			if cond, err = compareSQL(args, jsonExpr, textExpr, c.kind, c.Op, c.Value); err != nil {
+				return "", nil, fmt.Errorf("%s: %w", c.Field, err)
+			}
+		}
+		if c.Not {
+			cond = "NOT (" + cond + ")"
+		}
+		conditions = append(conditions, cond)
+	}
+
+	return fmt.Sprintf(`
+		SELECT %s
+		FROM graph_nodes n
+		WHERE %s
+		ORDER BY n.id
+		LIMIT %s`, strings.Join(columns, ", "), strings.Join(conditions, "\n\t\t  AND "), args.add(q.Limit)), *args, nil
+}
+
+// RunGraphQuery runs q over ctx's organization's graph.
+func RunGraphQuery(ctx context.Context, db *sql.DB, q *GraphQuery) (*GraphQueryResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, graphQueryTimeout)
+	defer cancel()
+	query, args, err := q.SQL(tenant.OrgID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("graph query: %w", err)
+	}
+	defer rows.Close()
+
+	result := &GraphQueryResult{Fields: q.Return, Rows: []map[string]json.RawMessage{}}
+	values := make([][]byte, len(q.Return))
+	dest := make([]interface{}, len(values))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("scan graph query row: %w", err)
+		}
+		row := make(map[string]json.RawMessage, len(values))
+		for i, field := range q.Return {
+			row[field] = json.RawMessage("null")
+			if values[i] != nil {
+				row[field] = append(json.RawMessage(nil), values[i]...)
+			}
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,142 @@
+package graphrag
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseGraphQueryErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr string
+	}{
+		{"unknown node type", `MATCH robot`, `unknown node type "robot"`},
+		{"unknown field", `MATCH person WITH shoe_size = 4`, `unknown person field "shoe_size"`},
+		{"unknown returned field", `MATCH person RETURN shoe_size`, `unknown person field "shoe_size"`},
+		{"hidden field in a condition", `MATCH person WITH salary_sealed = "x"`, `unknown person field "salary_sealed"`},
+		{"hidden field returned", `MATCH person RETURN salary_sealed`, `unknown person field "salary_sealed"`},
+		{"limit zero", `MATCH person LIMIT 0`, "LIMIT must be between 1 and 1000"},
+		{"limit too high", `MATCH person LIMIT 5000`, "LIMIT must be between 1 and 1000"},
+		{"limit not a number", `MATCH person LIMIT ten`, "LIMIT must be between 1 and 1000"},
+		{"double equals", `MATCH person WITH total_experience_years == 5`, `unknown operator "=="`},
+		{"double equals on a name", `MATCH person WITH skill == "Go"`, `unknown operator "=="`},
+		{"unterminated string", `MATCH person WITH name = "Ada`, "unterminated string"},
+		{"number expected", `MATCH person WITH total_experience_years > five`, "is a number"},
+		{"contains on a number", `MATCH person WITH total_experience_years CONTAINS 5`, "CONTAINS needs a text field"},
+		{"ordering a bool", `MATCH person WITH anonymized > true`, "can only be compared with = or !="},
+		{"ordering a list", `MATCH person WITH work_modes < "remote"`, "is a list"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseGraphQuery(tt.query)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseGraphQuery(%q) error = %v, want %q", tt.query, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// placeholder matches a bind parameter: a value in the SQL is only ever one.
+var placeholder = regexp.MustCompile(`\$(\d+)`)
+
+func TestGraphQuerySQL(t *testing.T) {
+	const orgID = 7
+	tests := []struct {
+		name   string
+		query  string
+		values []interface{} // bound, besides the org, node type and limit
+	}{
+		{"defaults", `MATCH person`, nil},
+		{"property", `MATCH person WITH seniority = "Senior"`, []interface{}{"seniority", "Senior"}},
+		{"number", `MATCH person WITH total_experience_years >= 5`, []interface{}{"total_experience_years", 5.0}},
+		{"bool", `MATCH person WITH anonymized != true`, []interface{}{"anonymized", true}},
+		{"list", `MATCH person WITH work_modes CONTAINS remote`, []interface{}{"work_modes", "remote"}},
+		{"linked node", `MATCH person WITH WORKED_AT company "Garanti"`, []interface{}{"company", "WORKED_AT", "Garanti"}},
+		{"negated link", `match person where not skill "Go"`, []interface{}{"skill", "Go"}},
+		{"injection stays a value", `MATCH person WITH name = "x'); DROP TABLE graph_nodes; --"`, []interface{}{"x'); DROP TABLE graph_nodes; --"}},
+		{"returned fields", `MATCH person RETURN location, skill LIMIT 5`, []interface{}{"location", "skill", 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := ParseGraphQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseGraphQuery: %v", err)
+			}
+			query, args, err := q.SQL(orgID)
+			if err != nil {
+				t.Fatalf("SQL: %v", err)
+			}
+
+			// Every placeholder is bound, and every argument is used.
+			used := make(map[string]bool)
+			for _, m := range placeholder.FindAllStringSubmatch(query, -1) {
+				used[m[1]] = true
+			}
+			if len(used) != len(args) {
+				t.Errorf("%d placeholders for %d arguments:\n%s", len(used), len(args), query)
+			}
+			for _, v := range tt.values {
+				if !containsArg(args, v) {
+					t.Errorf("%v not bound as a parameter: %v", v, args)
+				}
+				if s, ok := v.(string); ok && strings.Contains(query, s) {
+					t.Errorf("value %q in the SQL text:\n%s", s, query)
+				}
+			}
+
+			// The org is the first condition, bound to orgID.
+			at := strings.Index(query, "WHERE n.")
+			if at < 0 {
+				t.Fatalf("no top-level WHERE:\n%s", query)
+			}
+			where := strings.Fields(query[at:])
+			if len(where) < 4 || where[1] != "n.org_id" || where[2] != "=" {
+				t.Fatalf("first condition = %v, want n.org_id = $n", where[:4])
+			}
+			m := placeholder.FindStringSubmatch(where[3])
+			if m == nil {
+				t.Fatalf("org condition %s is not a placeholder", where[3])
+			}
+			if n, _ := strconv.Atoi(m[1]); args[n-1] != orgID {
+				t.Errorf("org condition %s bound to %v, want %d", where[3], args[n-1], orgID)
+			}
+			if strings.Count(query, "WHERE n.") != 1 {
+				t.Errorf("want one top-level WHERE:\n%s", query)
+			}
+		})
+	}
+}
+
+func TestGraphQuerySQLUnknownOperator(t *testing.T) {
+	tests := []struct {
+		name string
+		cond GraphCondition
+	}{
+		{"property", GraphCondition{Field: "seniority", Op: "==", Value: "x"}},
+		{"sql in the operator", GraphCondition{Field: "seniority", Op: "= 'x' OR 1=1 --", Value: "x"}},
+		{"linked node name", GraphCondition{NodeType: "skill", Op: "LIKE", Value: "Go"}},
+		{"number", GraphCondition{Field: "total_experience_years", Op: "contains", Value: 5.0, kind: kindNumber}},
+		{"bool", GraphCondition{Field: "anonymized", Op: "<", Value: true, kind: kindBool}},
+		{"list", GraphCondition{Field: "work_modes", Op: ">", Value: "remote", kind: kindStrings}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &GraphQuery{Match: "person", Conditions: []GraphCondition{tt.cond}, Return: []string{"id"}, Limit: 10}
+			if query, _, err := q.SQL(1); err == nil {
+				t.Errorf("SQL with operator %q = %s, want an error", tt.cond.Op, query)
+			}
+		})
+	}
+}
+
+func containsArg(args []interface{}, v interface{}) bool {
+	for _, a := range args {
+		if a == v {
+			return true
+		}
+	}
+	return false
+}
@@ -3,12 +3,13 @@ package graphrag
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
-	"sort"
-	"strings"
+	"sync"
 	"time"
+
+	"cv-search/internal/tenant"
 )
 
 // HybridSearchEngine combines BM25 + Vector + Graph search
@@ -37,6 +38,39 @@ func NewHybridSearchEngine(db *sql.DB, llm LLMClient, openaiKey string, disableC
 	}
 }
 
+// SetReadDB routes the engine's read-only queries (BM25, graph traversal,
+// result enrichment) to readDB, e.g. a replica pool. The embedding service
+// keeps the primary because it also writes embeddings.
+func (h *HybridSearchEngine) SetReadDB(readDB *sql.DB) {
+	h.db = readDB
+	h.bm25Searcher.db = readDB
+	h.graphQuerier.db = readDB
+}
+
+// SetTextSearchConfig selects the PostgreSQL text search config used by the
+// BM25 source (see TSConfigEnglish / TSConfigSimpleUnaccent).
+func (h *HybridSearchEngine) SetTextSearchConfig(name string) {
+	h.bm25Searcher.SetTextSearchConfig(name)
+}
+
+// SetEmbeddingModel selects the model queries are embedded with; it must be
+// the model the stored vectors were made with (see EmbeddingService.SetModel).
+func (h *HybridSearchEngine) SetEmbeddingModel(model string, dimensions int) {
+	h.embeddingService.SetModel(model, dimensions)
+}
+
+// UseOllamaEmbeddings embeds queries with Ollama instead of OpenAI (see
+// EmbeddingService.UseOllama). Call before SetEmbeddingModel.
+func (h *HybridSearchEngine) UseOllamaEmbeddings(baseURL, apiKey string) {
+	h.embeddingService.UseOllama(baseURL, apiKey)
+}
+
+// InvalidateResultCache drops cached search results so removed candidates
+// stop appearing in cache hits.
+func (h *HybridSearchEngine) InvalidateResultCache() {
+	h.semanticCache.Clear()
+}
+
 // ReEmbedPersonNode regenerates the vector embedding for a person node, enriching it with
 // current interview notes. Call this after any interview write to keep search signals fresh.
 // notes should be all interview notes for the candidate (fetched via DB).
@@ -47,6 +81,12 @@ func (h *HybridSearchEngine) ReEmbedPersonNode(ctx context.Context, graphNodeID
 	return h.embeddingService.ReEmbedPersonNodeByID(ctx, graphNodeID, notes)
 }
 
+// Snippets returns where query matched in the latest CVs of personIDs (see
+// BM25Searcher.Snippets).
+func (h *HybridSearchEngine) Snippets(ctx context.Context, query string, personIDs []string) (map[string]string, error) {
+	return h.bm25Searcher.Snippets(ctx, query, personIDs)
+}
+
 // GetEmbeddingService exposes the underlying EmbeddingService so callers
 // (e.g. the similar-candidates endpoint) can run embedding-based lookups
 // without going through the full search pipeline.
@@ -54,183 +94,202 @@ func (h *HybridSearchEngine) GetEmbeddingService() *EmbeddingService {
 	return h.embeddingService
 }
 
-// InterviewContext holds lightweight interview data attached to a search candidate.
-// Used for outcome-based score adjustments and LLM prompt enrichment.
-type InterviewContext struct {
-	ID              int
-	InterviewDate   time.Time
-	Team            string
-	InterviewerName string
-	InterviewType   string
-	Outcome         string // passed, failed, pending
-	Notes           string // available for re-embedding; excluded from API response
-}
-
-// FusedCandidate represents a candidate with scores from multiple sources
-type FusedCandidate struct {
-	CandidateID              int
-	GraphNodeIntID           int    // graph_nodes.id (integer PK), needed for interview lookup
-	PersonID                 string // Graph uses string IDs
-	Name                     string
-	CurrentPosition          string
-	Seniority                string
-	TotalExperienceYears     int
-	Skills                   []SkillNode
-	Companies                []CompanyNode
-	Interviews               []InterviewContext // loaded during enrichment
-	Community                string             // Primary community
-	Communities              []string           // All matching communities (Microsoft GraphRAG style)
-	CommunityScores          map[string]float64 // Normalized scores for each community
-	ComputedCommunityID      string             // ID of the graph-computed community this person belongs to
-	ComputedCommunitySummary string             // LLM summary of that community
-	BM25Score                float64            // 0-1 normalized
-	VectorScore              float64            // 0-1 normalized
-	GraphScore               float64            // 0-1 normalized
-	FusionScore              float64            // Weighted combination
-	LLMScore                 float64            // Final LLM reranking score (0-100)
-	LLMReasoning             string
-	Rank                     int
-}
-
-// VectorSearchResult represents a candidate from vector search
-type VectorSearchResult struct {
-	CandidateID int
-	PersonID    string
-	Similarity  float64
-}
-
-// HybridSearchConfig defines weights for fusion
-type HybridSearchConfig struct {
-	BM25Weight         float64 // Default: 0.3
-	VectorWeight       float64 // Default: 0.4
-	GraphWeight        float64 // Default: 0.3
-	TopK               int     // How many candidates to retrieve from each source
-	FinalTopN          int     // How many to send to LLM for reranking
-	UseCommunityFilter bool    // Enable community-based filtering (default: false, enabled at 50+ candidates)
-	CommunityThreshold int     // Auto-enable community filter at this candidate count (default: 50)
-}
-
-func DefaultHybridConfig() HybridSearchConfig {
-	return HybridSearchConfig{
-		BM25Weight:         0.0,
-		VectorWeight:       0.6,
-		GraphWeight:        0.4,
-		TopK:               100,
-		FinalTopN:          8, // Skill-based searches bypass this (see Step 2.55). Used only for skill-less queries as an LLM cost guard.
-		UseCommunityFilter: false,
-		CommunityThreshold: 10,
-	}
-}
-
 // Search performs hybrid search with fusion
 func (h *HybridSearchEngine) Search(ctx context.Context, query string, config HybridSearchConfig) ([]FusedCandidate, error) {
+	results, _, err := h.SearchWithDiagnostics(ctx, query, config)
+	return results, err
+}
+
+// SearchWithDiagnostics is Search plus per-source latencies and warnings for
+// degraded sources. The search only fails when every retrieval source fails.
+func (h *HybridSearchEngine) SearchWithDiagnostics(ctx context.Context, query string, config HybridSearchConfig) ([]FusedCandidate, *SearchDiagnostics, error) {
 	log.Printf("[HybridSearch] Starting search for: %s", query)
+	ctx = WithIncludedStatuses(ctx, config.IncludeStatuses)
+	diag := &SearchDiagnostics{
+		SourceLatencies: make(map[string]time.Duration, 3),
+		StageLatencies:  make(map[string]time.Duration, 5),
+	}
 
 	// Semantic cache: if a semantically identical query ran recently, return immediately (<5ms)
 	var queryEmbedding []float32
 	var embErr error
-	queryEmbedding, embErr = h.embeddingService.GenerateEmbedding(ctx, query)
-	if embErr == nil && !h.disableCache {
-		if cached, cachedQuery, found := h.semanticCache.Get(queryEmbedding); found {
+	stageStart := time.Now()
+	text := query // what embeddings and query analysis read
+	if config.Syntax != nil {
+		text = config.Syntax.Text
+	}
+	queryEmbedding, embErr = h.embeddingService.GenerateEmbedding(ctx, text)
+	diag.StageLatencies[StageEmbedding] = time.Since(stageStart)
+	reportProgress(ctx, SearchProgress{Stage: StageEmbedding, Done: true})
+	// The semantic cache is keyed on the query alone, so experiment runs and
+	// tag-filtered or -boosted, location- or salary-filtered searches, ones
+	// including excluded statuses, ones with their own BM25 field weights and
+	// boolean queries bypass it — otherwise a result ranked under one configuration would be
+	// served for another.
+	useSemanticCache := !h.disableCache && config.Experiment == "" && !config.hasTagOptions() &&
+		config.Location == nil && config.SalaryBand == nil && len(config.IncludeStatuses) == 0 &&
+		config.BM25FieldWeights == DefaultBM25FieldWeights() && config.Syntax == nil
+	if embErr == nil && useSemanticCache {
+		if cached, cachedQuery, found := h.semanticCache.Get(tenant.OrgID(ctx), queryEmbedding); found {
 			log.Printf("[HybridSearch] Semantic cache HIT (similar to: %q) → %d cached results", cachedQuery, len(cached))
-			return cached, nil
+			diag.SemanticCacheHit = true
+			reportProgress(ctx, SearchProgress{Stage: StageRerank, Done: true, Count: len(cached), Message: "semantic cache hit"})
+			return diversifyMMR(cached, config.DiversityLambda), diag, nil
 		}
 	} else {
 		log.Printf("[HybridSearch] Semantic cache embedding failed: %v", embErr)
 	}
 
-	// Clear ALL prepared statements once before parallel retrieval to prevent cache collisions
-	h.db.Exec("DEALLOCATE ALL")
-
-	// Step 1: Parallel retrieval from 3 sources
-	bm25ResultsChan := make(chan []BM25Result)
-	vectorResultsChan := make(chan []VectorSearchResult)
-
+	// Step 1: Parallel retrieval from 3 sources, each under its own deadline
+	// and all under the retrieval budget. A failed or slow source degrades to
+	// "no results" plus a warning; only when all three fail is the search
+	// itself an error.
 	type graphSearchResult struct {
-		criteria *SearchCriteria
-		results  []CandidateResult
+		criteria         *SearchCriteria
+		results          []CandidateResult
+		analysisLatency  time.Duration
+		analysisTimedOut bool
+	}
+
+	var (
+		wg            sync.WaitGroup
+		bm25Results   []BM25Result
+		vectorResults []VectorSearchResult
+		graphOut      graphSearchResult
+		bm25Err       error
+		vectorErr     error
+		graphErr      error
+		bm25Latency   time.Duration
+		vectorLatency time.Duration
+		graphLatency  time.Duration
+	)
+	wg.Add(3)
+	stageStart = time.Now()
+	rctx, cancelRetrieval := stageContext(ctx, config.RetrievalTimeout)
+	defer cancelRetrieval()
+	reportProgress(ctx, SearchProgress{Stage: StageRetrieval})
+	sourceDone := func(source string, count int, err error) {
+		p := SearchProgress{Stage: StageRetrieval, Source: source, Done: true, Count: count}
+		if err != nil {
+			p.Message = err.Error()
+		}
+		reportProgress(ctx, p)
 	}
-	graphResultsChan := make(chan graphSearchResult)
-	errChan := make(chan error, 3)
 
 	// BM25 search
 	go func() {
-		results, err := h.bm25Searcher.Search(ctx, query, config.TopK)
-		if err != nil {
-			errChan <- fmt.Errorf("bm25 failed: %w", err)
-			return
-		}
-		bm25ResultsChan <- results
+		defer wg.Done()
+		bm25Results, bm25Latency, bm25Err = runSource(rctx, config.BM25Timeout, func(sctx context.Context) ([]BM25Result, error) {
+			if config.Syntax != nil {
+				return h.bm25Searcher.SearchSyntax(sctx, config.Syntax, config.TopK, config.BM25FieldWeights)
+			}
+			return h.bm25Searcher.SearchWithWeights(sctx, query, config.TopK, config.BM25FieldWeights)
+		})
+		sourceDone(SourceBM25, len(bm25Results), bm25Err)
 	}()
 
 	// Vector search — reuse the embedding already generated for semantic cache (saves ~2s API call)
 	go func() {
-		var personIDs []string
-		var similarities []float64
-		var err error
-		if queryEmbedding != nil {
-			personIDs, similarities, err = h.embeddingService.SimilaritySearchByEmbedding(ctx, queryEmbedding, config.TopK)
-		} else {
-			personIDs, similarities, err = h.embeddingService.SimilaritySearch(ctx, query, config.TopK)
-		}
-		if err != nil {
-			errChan <- fmt.Errorf("vector failed: %w", err)
-			return
-		}
-		results := make([]VectorSearchResult, len(personIDs))
-		for i := range personIDs {
-			results[i] = VectorSearchResult{
-				PersonID:   personIDs[i],
-				Similarity: similarities[i],
-			}
-		}
-		vectorResultsChan <- results
+		defer wg.Done()
+		vectorResults, vectorLatency, vectorErr = runSource(rctx, config.VectorTimeout, func(sctx context.Context) ([]VectorSearchResult, error) {
+			return h.vectorSearch(sctx, queryEmbedding, text, config.TopK)
+		})
+		sourceDone(SourceVector, len(vectorResults), vectorErr)
 	}()
 
 	// Graph search (needs criteria extraction first; sends criteria alongside results for post-fusion filtering)
 	go func() {
-		analyzer := NewQueryAnalyzer(h.llm)
-		criteria, err := analyzer.AnalyzeQuery(ctx, query)
-		if err != nil {
-			log.Printf("[HybridSearch] Graph search skipped (criteria extraction failed): %v", err)
-			graphResultsChan <- graphSearchResult{criteria: &SearchCriteria{}, results: []CandidateResult{}}
-			return
-		}
+		defer wg.Done()
+		graphOut, graphLatency, graphErr = runSource(rctx, config.GraphTimeout, func(sctx context.Context) (graphSearchResult, error) {
+			analyzer := NewQueryAnalyzer(h.llm)
+			criteria, analysisLatency, err := runStage(sctx, config.AnalysisTimeout, func(actx context.Context) (*SearchCriteria, error) {
+				return analyzer.AnalyzeQuery(actx, text)
+			})
+			reportProgress(ctx, SearchProgress{Stage: StageAnalysis, Done: true})
+			if err != nil {
+				log.Printf("[HybridSearch] Graph search skipped (criteria extraction failed): %v", err)
+				return graphSearchResult{
+					criteria:         &SearchCriteria{},
+					results:          []CandidateResult{},
+					analysisLatency:  analysisLatency,
+					analysisTimedOut: errors.Is(err, context.DeadlineExceeded) && sctx.Err() == nil,
+				}, nil
+			}
 
-		results, err := h.graphQuerier.QueryGraph(ctx, criteria)
-		if err != nil {
-			errChan <- fmt.Errorf("graph failed: %w", err)
-			return
-		}
-		graphResultsChan <- graphSearchResult{criteria: criteria, results: results}
+			// Non-fatal: without it skills match by exact name only
+			if err := h.embeddingService.EmbedCriteria(sctx, criteria); err != nil {
+				log.Printf("[HybridSearch] Criteria embedding skipped: %v", err)
+			}
+			results, err := h.graphQuerier.QueryGraph(sctx, criteria)
+			if err != nil {
+				// Keep the criteria: the skill post-filter still needs them.
+				return graphSearchResult{criteria: criteria, analysisLatency: analysisLatency}, err
+			}
+			return graphSearchResult{criteria: criteria, results: results, analysisLatency: analysisLatency}, nil
+		})
+		sourceDone(SourceGraph, len(graphOut.results), graphErr)
 	}()
 
-	// Wait for all results
-	var bm25Results []BM25Result
-	var vectorResults []VectorSearchResult
-	var graphResults []CandidateResult
-	var searchCriteria *SearchCriteria
-
-	for i := 0; i < 3; i++ {
-		select {
-		case bm25Results = <-bm25ResultsChan:
-			log.Printf("[HybridSearch] BM25 returned %d results", len(bm25Results))
-		case vectorResults = <-vectorResultsChan:
-			log.Printf("[HybridSearch] Vector returned %d results", len(vectorResults))
-		case gr := <-graphResultsChan:
-			graphResults = gr.results
-			searchCriteria = gr.criteria
-			log.Printf("[HybridSearch] Graph returned %d results", len(graphResults))
-		case err := <-errChan:
-			return nil, err
+	wg.Wait()
+	retrievalOver := rctx.Err() == context.DeadlineExceeded
+	cancelRetrieval()
+	diag.StageLatencies[StageRetrieval] = time.Since(stageStart)
+	if graphOut.analysisLatency > 0 {
+		diag.StageLatencies[StageAnalysis] = graphOut.analysisLatency
+	}
+	if graphOut.analysisTimedOut {
+		diag.warn("query analysis exceeded its %s budget, graph source skipped", config.AnalysisTimeout)
+	}
+
+	diag.SourceLatencies[SourceBM25] = bm25Latency
+	diag.SourceLatencies[SourceVector] = vectorLatency
+	diag.SourceLatencies[SourceGraph] = graphLatency
+
+	failed := 0
+	for _, src := range []struct {
+		name    string
+		err     error
+		timeout time.Duration
+		latency time.Duration
+	}{
+		{SourceBM25, bm25Err, config.BM25Timeout, bm25Latency},
+		{SourceVector, vectorErr, config.VectorTimeout, vectorLatency},
+		{SourceGraph, graphErr, config.GraphTimeout, graphLatency},
+	} {
+		if src.err == nil {
+			continue
 		}
-	}
-
-	// Step 2: Fuse results using RRF (Reciprocal Rank Fusion)
+		failed++
+		switch {
+		case errors.Is(src.err, context.DeadlineExceeded) && retrievalOver && src.latency < src.timeout:
+			diag.warn("%s source cut off at the %s retrieval budget", src.name, config.RetrievalTimeout)
+		case errors.Is(src.err, context.DeadlineExceeded):
+			diag.warn("%s source timed out after %s", src.name, src.timeout)
+		default:
+			diag.warn("%s source failed: %v", src.name, src.err)
+		}
+	}
+	if failed == 3 {
+		return nil, diag, fmt.Errorf("all retrieval sources failed: bm25: %v; vector: %v; graph: %v", bm25Err, vectorErr, graphErr)
+	}
+
+	graphResults := graphOut.results
+	searchCriteria := graphOut.criteria
+	log.Printf("[HybridSearch] BM25 returned %d results (%s)", len(bm25Results), bm25Latency)
+	log.Printf("[HybridSearch] Vector returned %d results (%s)", len(vectorResults), vectorLatency)
+	log.Printf("[HybridSearch] Graph returned %d results (%s)", len(graphResults), graphLatency)
+	reportProgress(ctx, SearchProgress{Stage: StageRetrieval, Done: true, Count: len(bm25Results) + len(vectorResults) + len(graphResults)})
+
+	// Step 2: Fuse results using RRF (Reciprocal Rank Fusion). The database
+	// work of steps 2.5-2.7 runs under the fusion budget; once it is spent
+	// the remaining lookups are skipped.
+	stageStart = time.Now()
+	fctx, cancelFusion := stageContext(ctx, config.FusionTimeout)
+	defer cancelFusion()
 	fusedCandidates := h.fuseResults(bm25Results, vectorResults, graphResults, config)
 
 	// Step 2.5: Enrich candidates with full details (skills, companies, computed communities)
-	h.enrichCandidates(ctx, fusedCandidates)
+	h.enrichCandidates(fctx, fusedCandidates, config.CommunityPatterns)
 
 	// Step 2.55: Post-fusion skill filter.
 	// Vector search returns semantically similar CVs regardless of tech stack.
@@ -242,33 +301,54 @@ func (h *HybridSearchEngine) Search(ctx context.Context, query string, config Hy
 	// if skills are confirmed, domain is already guaranteed — further filtering only causes harm.
 	skillFilterActive := false
 	if searchCriteria != nil && len(searchCriteria.Skills) > 0 {
-		requiredSkills := make(map[string]bool, len(searchCriteria.Skills))
-		for _, s := range searchCriteria.Skills {
-			requiredSkills[strings.ToLower(s)] = true
-		}
-		skillFiltered := make([]FusedCandidate, 0, len(fusedCandidates))
-		for _, c := range fusedCandidates {
-			for _, sk := range c.Skills {
-				if requiredSkills[strings.ToLower(sk.Name)] {
-					skillFiltered = append(skillFiltered, c)
-					break
-				}
-			}
+		fusedCandidates, skillFilterActive = filterBySkills(fusedCandidates, searchCriteria.skillKeySet())
+	}
+
+	// Step 2.56: Tag filter. Unlike the skill filter this is the caller's
+	// explicit constraint, so it applies even when nothing is left.
+	if len(config.RequireTags) > 0 || len(config.ExcludeTags) > 0 {
+		fusedCandidates = filterByTags(fusedCandidates, config.RequireTags, config.ExcludeTags)
+	}
+
+	// Step 2.57: Location filter, also the caller's explicit constraint
+	if config.Location != nil {
+		fusedCandidates = filterByLocation(fusedCandidates, config.Location, config.LocationRadiusKm)
+	}
+
+	// Step 2.575: Salary band filter, the caller's explicit constraint
+	if config.SalaryBand != nil {
+		fusedCandidates = filterBySalary(fusedCandidates, config.SalaryBand)
+	}
+
+	// Step 2.577: Query syntax filter, the caller's explicit constraint
+	// too. Vector and graph results matched only in meaning; keep those
+	// matching the expression.
+	if config.Syntax != nil && len(fusedCandidates) > 0 && fctx.Err() == nil {
+		personIDs := make([]string, len(fusedCandidates))
+		for i, c := range fusedCandidates {
+			personIDs[i] = c.PersonID
 		}
-		if len(skillFiltered) > 0 {
-			skillFilterActive = len(skillFiltered) < len(fusedCandidates)
-			log.Printf("[HybridSearch] Skill post-filter: %d → %d candidates (kept skill-relevant only)",
-				len(fusedCandidates), len(skillFiltered))
-			fusedCandidates = skillFiltered
+		matching, err := h.bm25Searcher.MatchingSyntax(fctx, config.Syntax, personIDs)
+		if err != nil {
+			diag.warn("query syntax filter skipped: %v", err)
 		} else {
-			log.Printf("[HybridSearch] Skill post-filter matched 0 candidates, skipping filter")
+			fusedCandidates = filterBySyntax(fusedCandidates, config.Syntax, matching)
 		}
 	}
 
+	// Step 2.58: Recency / progression signals for the reranker (and the response)
+	var querySkills []string
+	if searchCriteria != nil {
+		querySkills = searchCriteria.Skills
+	}
+	if fctx.Err() == nil {
+		h.attachRankingSignals(fctx, fusedCandidates, querySkills)
+	}
+
 	// Step 2.6: Fetch global community context for this query (for LLM scoring context)
 	var queryCommunityContext []string
-	if queryEmbedding != nil {
-		queryCommunityContext = h.fetchQueryCommunities(ctx, queryEmbedding)
+	if queryEmbedding != nil && fctx.Err() == nil {
+		queryCommunityContext = h.fetchQueryCommunities(fctx, queryEmbedding)
 		if len(queryCommunityContext) > 0 {
 			log.Printf("[HybridSearch] Found %d relevant graph communities for query context", len(queryCommunityContext))
 		}
@@ -280,101 +360,34 @@ func (h *HybridSearchEngine) Search(ctx context.Context, query string, config Hy
 	//
 	// Community lookup priority:
 	//   1. Embedding similarity against graph_communities.embedding (populated by detect_communities tool)
+	//   1b. The same for the criteria's embedding (EmbedCriteria): positions and skills without the query's noise
 	//   2. Keyword mapping from LLM-extracted positions (PositionsToCommunities) — fallback
 	//   3. Keyword scan of raw query text (FindCommunitiesByQuery) — last resort
 	var queryCommunities []string
-	if queryEmbedding != nil {
-		dbCommunities, dbErr := h.embeddingService.FindCommunitiesByEmbedding(ctx, queryEmbedding, 2)
-		if dbErr != nil {
-			log.Printf("[HybridSearch] FindCommunitiesByEmbedding failed (non-fatal): %v", dbErr)
-		} else if len(dbCommunities) > 0 {
-			queryCommunities = dbCommunities
-			log.Printf("[HybridSearch] Communities from embedding similarity: %v", queryCommunities)
-		}
-	}
-	if len(queryCommunities) == 0 && searchCriteria != nil && len(searchCriteria.Positions) > 0 {
-		dbPosCommunities, posErr := h.embeddingService.FindCommunitiesByPositionTitles(ctx, searchCriteria.Positions)
-		if posErr != nil {
-			log.Printf("[HybridSearch] FindCommunitiesByPositionTitles failed (non-fatal): %v", posErr)
-		} else if len(dbPosCommunities) > 0 {
-			queryCommunities = dbPosCommunities
-			log.Printf("[HybridSearch] Communities from position title DB lookup %v: %v", searchCriteria.Positions, queryCommunities)
-		}
+	if fctx.Err() == nil {
+		queryCommunities = h.findQueryCommunities(fctx, queryEmbedding, searchCriteria)
 	}
 	if len(queryCommunities) == 0 {
-		queryCommunities = FindCommunitiesByQuery(query)
+		queryCommunities = config.CommunityPatterns.FindCommunitiesByQuery(text)
 		log.Printf("[HybridSearch] Communities from keyword fallback: %v", queryCommunities)
 	}
 	shouldUseCommunityFilter := !skillFilterActive && (config.UseCommunityFilter || len(fusedCandidates) >= config.CommunityThreshold)
 	if shouldUseCommunityFilter && len(fusedCandidates) > 0 {
 		log.Printf("[HybridSearch] Community filter enabled. Query matches communities: %v", queryCommunities)
-
-		// Filter candidates by overlapping communities (Microsoft GraphRAG approach)
-		filteredCandidates := make([]FusedCandidate, 0, len(fusedCandidates))
-		for _, candidate := range fusedCandidates {
-			// Check if candidate has ANY matching community
-			matched := false
-			for _, qc := range queryCommunities {
-				for _, candidateCommunity := range candidate.Communities {
-					if candidateCommunity == qc {
-						matched = true
-						break
-					}
-				}
-				if matched {
-					break
-				}
-			}
-
-			if matched {
-				filteredCandidates = append(filteredCandidates, candidate)
-			}
-		}
-
-		if len(filteredCandidates) > 0 {
-			log.Printf("[HybridSearch] Community filter reduced candidates from %d to %d",
-				len(fusedCandidates), len(filteredCandidates))
-			fusedCandidates = filteredCandidates
-		} else {
-			log.Printf("[HybridSearch] Community filter matched 0 candidates, keeping all")
-		}
+		fusedCandidates = filterByCommunities(fusedCandidates, queryCommunities)
 	}
 
 	// Step 2.8: Interview outcome modifier.
 	// Adjusts FusionScore based on the most recent interview result.
 	// Applied before top-N cut so outcome-boosted candidates rank higher going into LLM.
-	for i := range fusedCandidates {
-		if len(fusedCandidates[i].Interviews) == 0 {
-			continue
-		}
-		// Interviews are ordered DESC by date; first entry is the most recent
-		switch fusedCandidates[i].Interviews[0].Outcome {
-		case "passed":
-			fusedCandidates[i].FusionScore = min(1.0, fusedCandidates[i].FusionScore+0.05)
-		case "failed":
-			fusedCandidates[i].FusionScore = max(0.0, fusedCandidates[i].FusionScore-0.10)
-		}
-	}
+	applyInterviewOutcomes(fusedCandidates)
 
 	// Step 2.9: Deterministic community-score boost.
 	// Uses the same queryCommunities from Step 2.7 — no second keyword scan.
 	// Penalises candidates whose skills don't match the query's community.
-	// Formula: FusionScore *= (0.25 + 0.75 * bestMatchingCommunityScore)
-	// → perfect match (score=1.0): no change; zero match (score=0): 0.25x penalty.
 	if len(queryCommunities) > 0 {
 		log.Printf("[HybridSearch] Community score boost active for: %v", queryCommunities)
-		for i := range fusedCandidates {
-			bestScore := 0.0
-			for _, qc := range queryCommunities {
-				if s, ok := fusedCandidates[i].CommunityScores[qc]; ok && s > bestScore {
-					bestScore = s
-				}
-			}
-			fusedCandidates[i].FusionScore *= 0.25 + 0.75*bestScore
-		}
-		sort.Slice(fusedCandidates, func(i, j int) bool {
-			return fusedCandidates[i].FusionScore > fusedCandidates[j].FusionScore
-		})
+		boostByCommunityScores(fusedCandidates, queryCommunities)
 	}
 
 	// Step 3: Take top N for LLM reranking.
@@ -386,592 +399,63 @@ func (h *HybridSearchEngine) Search(ctx context.Context, query string, config Hy
 	}
 
 	log.Printf("[HybridSearch] Fusion complete. Top %d candidates ready for LLM reranking", len(fusedCandidates))
-
-	// Step 4: LLM Reranking — persistent scorer keeps its cache alive across requests
-	llmScores, err := h.scorer.ScoreCandidates(ctx, query, fusedCandidates, queryCommunityContext)
-	if err != nil {
-		log.Printf("[HybridSearch] LLM scoring failed, returning fusion scores: %v", err)
-		for i := range fusedCandidates {
-			fusedCandidates[i].LLMScore = fusedCandidates[i].FusionScore
-		}
-		return fusedCandidates, nil
-	}
-
-	// Step 5: Merge LLM scores back into fused candidates
-	scoreMap := make(map[string]CandidateScore)
-	for _, score := range llmScores {
-		scoreMap[score.PersonID] = score
+	diag.StageLatencies[StageFusion] = time.Since(stageStart)
+	if fctx.Err() == context.DeadlineExceeded {
+		diag.warn("fusion exceeded its %s budget, candidates may lack details, ranking signals or community context", config.FusionTimeout)
 	}
+	cancelFusion()
+	reportProgress(ctx, SearchProgress{Stage: StageFusion, Done: true, Count: len(fusedCandidates)})
 
-	for i := range fusedCandidates {
-		if score, found := scoreMap[fusedCandidates[i].PersonID]; found {
-			fusedCandidates[i].LLMScore = score.Score
-			fusedCandidates[i].LLMReasoning = score.Reasoning
-		} else {
-			fusedCandidates[i].LLMScore = fusedCandidates[i].FusionScore
-		}
-	}
-
-	// Step 6: Re-sort by LLM score (final ranking)
-	sort.Slice(fusedCandidates, func(i, j int) bool {
-		return fusedCandidates[i].LLMScore > fusedCandidates[j].LLMScore
-	})
-
-	// Filter out candidates with no name (empty/invalid results)
-	validCandidates := make([]FusedCandidate, 0, len(fusedCandidates))
-	for _, c := range fusedCandidates {
-		if c.Name != "" && c.PersonID != "" {
-			validCandidates = append(validCandidates, c)
+	// Step 4: LLM Reranking — persistent scorer keeps its cache alive across requests
+	// With RerankerNone (search experiments), or when reranking fails or runs
+	// out of its budget, every candidate falls through to its fusion score in
+	// Step 5.
+	var llmScores []CandidateScore
+	if config.Reranker == RerankerNone {
+		log.Printf("[HybridSearch] Reranker disabled (experiment %q), serving fusion ranking", config.Experiment)
+	} else {
+		var err error
+		var rerankLatency time.Duration
+		// The scorer gets its own copy: past the budget it may still be
+		// reading while the ranking below reorders fusedCandidates.
+		scoring := append([]FusedCandidate(nil), fusedCandidates...)
+		llmScores, rerankLatency, err = runStage(ctx, config.RerankTimeout, func(sctx context.Context) ([]CandidateScore, error) {
+			return h.scorer.ScoreCandidates(sctx, query, scoring, queryCommunityContext, config.ScoringInstructions)
+		})
+		diag.StageLatencies[StageRerank] = rerankLatency
+		reportProgress(ctx, SearchProgress{Stage: StageRerank, Done: true, Count: len(llmScores)})
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+				diag.warn("LLM reranking exceeded its %s budget, returning fusion scores", config.RerankTimeout)
+			} else {
+				diag.warn("LLM reranking failed, returning fusion scores: %v", err)
+			}
+			// Degraded, not failed: every candidate keeps its fusion score
+			// in Step 5 and goes through the same final ranking.
+			llmScores = nil
 		}
 	}
 
-	// Update ranks
-	for i := range validCandidates {
-		validCandidates[i].Rank = i + 1
-	}
+	// Steps 5-6: Merge LLM scores back into fused candidates, apply tag
+	// boosts and rank by the final score
+	validCandidates := rankCandidates(fusedCandidates, llmScores, config.TagBoosts)
 
 	if len(validCandidates) == 0 {
 		log.Printf("[HybridSearch] No valid candidates found")
-		return []FusedCandidate{}, nil
+		return []FusedCandidate{}, diag, nil
 	}
 
 	log.Printf("[HybridSearch] Final ranking complete. Top candidate: %s (LLM Score: %.2f)",
 		validCandidates[0].Name, validCandidates[0].LLMScore)
 
-	// Store results in semantic cache for future similar queries (skipped in local dev)
-	if embErr == nil && !h.disableCache {
-		h.semanticCache.Set(queryEmbedding, query, validCandidates)
+	// Store results in semantic cache for future similar queries (skipped in local dev).
+	// Degraded (partial) results are never cached — the next search should retry all sources.
+	if embErr == nil && useSemanticCache && len(diag.Warnings) == 0 {
+		h.semanticCache.Set(tenant.OrgID(ctx), queryEmbedding, query, validCandidates)
 		log.Printf("[HybridSearch] Results stored in semantic cache (30m TTL)")
 	}
 
-	return validCandidates, nil
-}
-
-// fetchQueryCommunities finds the most relevant graph-computed communities for a query
-// using vector similarity. Returns community summaries to use as global LLM context.
-func (h *HybridSearchEngine) fetchQueryCommunities(ctx context.Context, embedding []float32) []string {
-	if len(embedding) == 0 {
-		return nil
-	}
-
-	embeddingJSON, err := json.Marshal(embedding)
-	if err != nil {
-		return nil
-	}
-
-	rows, err := h.db.QueryContext(ctx, `
-		SELECT summary
-		FROM graph_communities
-		WHERE embedding IS NOT NULL AND level = 0
-		ORDER BY embedding <=> $1::vector
-		LIMIT 3
-	`, string(embeddingJSON))
-	if err != nil {
-		log.Printf("[HybridSearch] fetchQueryCommunities failed (non-fatal): %v", err)
-		return nil
-	}
-	defer rows.Close()
-
-	var summaries []string
-	for rows.Next() {
-		var summary sql.NullString
-		if err := rows.Scan(&summary); err != nil {
-			continue
-		}
-		if summary.Valid && summary.String != "" {
-			summaries = append(summaries, summary.String)
-		}
-	}
-	return summaries
-}
-
-// fuseResults combines results using weighted scoring
-func (h *HybridSearchEngine) fuseResults(
-	bm25 []BM25Result,
-	vector []VectorSearchResult,
-	graph []CandidateResult,
-	config HybridSearchConfig,
-) []FusedCandidate {
-	// Create a map of candidate scores (keyed by PersonID string)
-	scoreMap := make(map[string]*FusedCandidate)
-
-	// Normalize and add BM25 scores.
-	// Use graph node_id as key so results merge with vector/graph sources.
-	// Fall back to cand_X if node_id is empty (shouldn't happen in practice).
-	maxBM25 := maxBM25Score(bm25)
-	for i, r := range bm25 {
-		candidateKey := r.NodeID
-		if candidateKey == "" {
-			candidateKey = fmt.Sprintf("cand_%d", r.CandidateID)
-		}
-		if _, exists := scoreMap[candidateKey]; !exists {
-			scoreMap[candidateKey] = &FusedCandidate{
-				PersonID:    candidateKey,
-				CandidateID: r.CandidateID,
-				Name:        r.Name,
-			}
-		}
-		normalizedScore := r.Rank / maxBM25
-		rrfScore := 1.0 / float64(60+i+1) // k=60 is standard RRF constant
-		scoreMap[candidateKey].BM25Score = (normalizedScore + rrfScore) / 2.0
-	}
-
-	// Normalize and add Vector scores
-	maxVector := maxVectorScore(vector)
-	for i, r := range vector {
-		if _, exists := scoreMap[r.PersonID]; !exists {
-			scoreMap[r.PersonID] = &FusedCandidate{
-				PersonID: r.PersonID,
-			}
-		}
-		normalizedScore := r.Similarity / maxVector
-		rrfScore := 1.0 / float64(60+i+1)
-		scoreMap[r.PersonID].VectorScore = (normalizedScore + rrfScore) / 2.0
-	}
-
-	// Normalize and add Graph scores
-	maxGraph := maxGraphScore(graph)
-	for i, r := range graph {
-		if _, exists := scoreMap[r.PersonID]; !exists {
-			scoreMap[r.PersonID] = &FusedCandidate{
-				PersonID: r.PersonID,
-				Name:     r.Name,
-			}
-		}
-		normalizedScore := r.MatchScore / maxGraph
-		rrfScore := 1.0 / float64(60+i+1)
-		scoreMap[r.PersonID].GraphScore = (normalizedScore + rrfScore) / 2.0
-		scoreMap[r.PersonID].Name = r.Name // Update name if not set
-	}
-
-	// Calculate fusion score
-	results := make([]FusedCandidate, 0, len(scoreMap))
-	for _, candidate := range scoreMap {
-		candidate.FusionScore = config.BM25Weight*candidate.BM25Score +
-			config.VectorWeight*candidate.VectorScore +
-			config.GraphWeight*candidate.GraphScore
-		results = append(results, *candidate)
-	}
-
-	// Sort by fusion score descending
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].FusionScore > results[j].FusionScore
-	})
-
-	// Assign ranks
-	for i := range results {
-		results[i].Rank = i + 1
-	}
-
-	return results
-}
-
-// Helper functions to find max scores for normalization
-func maxBM25Score(results []BM25Result) float64 {
-	if len(results) == 0 {
-		return 1.0
-	}
-	max := results[0].Rank
-	for _, r := range results {
-		if r.Rank > max {
-			max = r.Rank
-		}
-	}
-	if max == 0 {
-		return 1.0
-	}
-	return max
-}
-
-func maxVectorScore(results []VectorSearchResult) float64 {
-	if len(results) == 0 {
-		return 1.0
-	}
-	max := results[0].Similarity
-	for _, r := range results {
-		if r.Similarity > max {
-			max = r.Similarity
-		}
-	}
-	if max == 0 {
-		return 1.0
-	}
-	return max
-}
-
-func maxGraphScore(results []CandidateResult) float64 {
-	if len(results) == 0 {
-		return 1.0
-	}
-	max := results[0].MatchScore
-	for _, r := range results {
-		if r.MatchScore > max {
-			max = r.MatchScore
-		}
-	}
-	if max == 0 {
-		return 100.0 // Graph scores are 0-100
-	}
-	return max
-}
-
-// enrichCandidates loads full candidate details (skills, companies, etc.)
-// OPTIMIZED: Batch loading to avoid N+1 queries
-func (h *HybridSearchEngine) enrichCandidates(ctx context.Context, candidates []FusedCandidate) {
-	if len(candidates) == 0 {
-		return
-	}
-
-	// Build person ID list for batch queries
-	personIDs := make([]interface{}, 0, len(candidates))
-	personIDToIndex := make(map[string]int)
-	for i := range candidates {
-		if candidates[i].PersonID != "" {
-			personIDs = append(personIDs, candidates[i].PersonID)
-			personIDToIndex[candidates[i].PersonID] = i
-		}
-	}
-
-	if len(personIDs) == 0 {
-		return
-	}
-
-	// Build placeholders for IN clause ($1, $2, $3, ...)
-	placeholders := make([]string, len(personIDs))
-	for i := range personIDs {
-		placeholders[i] = fmt.Sprintf("$%d", i+1)
-	}
-	inClause := "(" + strings.Join(placeholders, ",") + ")"
-
-	// BATCH 1: Load all person details in one query
-	// Also fetch integer id so we can correlate with interviews.candidate_id via candidates.graph_node_id
-	personQuery := fmt.Sprintf(`
-		SELECT id, node_id, properties
-		FROM graph_nodes
-		WHERE node_id IN %s AND node_type = 'person'
-	`, inClause)
-
-	personRows, err := h.db.QueryContext(ctx, personQuery, personIDs...)
-	if err != nil {
-		log.Printf("[HybridSearch] Failed to batch load persons: %v", err)
-		return
-	}
-	defer personRows.Close()
-
-	// Track integer IDs for interview batch lookup
-	nodeIntIDIndex := make(map[int]int) // graphNodeIntID → candidate slice index
-
-	for personRows.Next() {
-		var nodeIntID int
-		var personID string
-		var propsJSON []byte
-		if err := personRows.Scan(&nodeIntID, &personID, &propsJSON); err != nil {
-			continue
-		}
-
-		idx, ok := personIDToIndex[personID]
-		if !ok {
-			continue
-		}
-		candidates[idx].GraphNodeIntID = nodeIntID
-		nodeIntIDIndex[nodeIntID] = idx
-
-		var props map[string]interface{}
-		if err := json.Unmarshal(propsJSON, &props); err != nil {
-			continue
-		}
-
-		// Update candidate fields
-		if name, ok := props["name"].(string); ok {
-			candidates[idx].Name = name
-		}
-		if pos, ok := props["current_position"].(string); ok {
-			candidates[idx].CurrentPosition = pos
-		}
-		if sen, ok := props["seniority"].(string); ok {
-			candidates[idx].Seniority = sen
-		}
-		if exp, ok := props["total_experience_years"].(float64); ok {
-			candidates[idx].TotalExperienceYears = int(exp)
-		}
-		if comm, ok := props["community"].(string); ok {
-			candidates[idx].Community = comm
-		}
-		if communities, ok := props["communities"].([]interface{}); ok {
-			commList := make([]string, 0, len(communities))
-			for _, c := range communities {
-				if commStr, ok := c.(string); ok {
-					commList = append(commList, commStr)
-				}
-			}
-			candidates[idx].Communities = commList
-		}
-	}
-
-	// BATCH 1.5: Load candidate IDs for each GraphNodeIntID
-	var graphNodeIDs []interface{}
-	for _, c := range candidates {
-		if c.GraphNodeIntID != 0 {
-			graphNodeIDs = append(graphNodeIDs, c.GraphNodeIntID)
-		}
-	}
-	if len(graphNodeIDs) > 0 {
-		placeholders2 := make([]string, len(graphNodeIDs))
-		for i := range graphNodeIDs {
-			placeholders2[i] = fmt.Sprintf("$%d", i+1)
-		}
-		candidateQuery := fmt.Sprintf(`
-			SELECT id, graph_node_id
-			FROM candidates
-			WHERE graph_node_id IN (%s)
-		`, strings.Join(placeholders2, ","))
-		candRows, err := h.db.QueryContext(ctx, candidateQuery, graphNodeIDs...)
-		if err == nil {
-			defer candRows.Close()
-			for candRows.Next() {
-				var cID, gID int
-				if err := candRows.Scan(&cID, &gID); err == nil {
-					if idx, ok := nodeIntIDIndex[gID]; ok {
-						candidates[idx].CandidateID = cID
-					}
-				}
-			}
-		} else {
-			log.Printf("[HybridSearch] Failed to batch load candidates: %v", err)
-		}
-	}
-
-	// BATCH 2: Load all skills in one query
-	skillQuery := fmt.Sprintf(`
-		SELECT p.node_id, s.properties, e.properties
-		FROM graph_nodes p
-		JOIN graph_edges e ON p.id = e.source_node_id
-		JOIN graph_nodes s ON e.target_node_id = s.id
-		WHERE p.node_id IN %s
-		  AND e.edge_type = 'HAS_SKILL'
-		  AND s.node_type = 'skill'
-	`, inClause)
-
-	skillRows, err := h.db.QueryContext(ctx, skillQuery, personIDs...)
-	if err != nil {
-		log.Printf("[HybridSearch] Failed to batch load skills: %v", err)
-	} else {
-		defer skillRows.Close()
-		for skillRows.Next() {
-			var personID string
-			var skillPropsJSON, edgePropsJSON []byte
-			if err := skillRows.Scan(&personID, &skillPropsJSON, &edgePropsJSON); err != nil {
-				continue
-			}
-
-			idx, ok := personIDToIndex[personID]
-			if !ok {
-				continue
-			}
-
-			var skillProps, edgeProps map[string]interface{}
-			if err := json.Unmarshal(skillPropsJSON, &skillProps); err != nil {
-				continue
-			}
-
-			name, ok := skillProps["name"].(string)
-			if !ok {
-				continue
-			}
-
-			skill := SkillNode{Name: name}
-			if prof, ok := skillProps["proficiency"].(string); ok {
-				skill.Proficiency = prof
-			}
-
-			// Load years from edge properties
-			if err := json.Unmarshal(edgePropsJSON, &edgeProps); err == nil {
-				if prof, ok := edgeProps["proficiency"].(string); ok && skill.Proficiency == "" {
-					skill.Proficiency = prof
-				}
-				if years, ok := edgeProps["years_of_experience"].(float64); ok {
-					skill.YearsOfExperience = int(years)
-				}
-			}
-
-			candidates[idx].Skills = append(candidates[idx].Skills, skill)
-		}
-	}
-
-	// BATCH 3: Load all companies in one query (both current and past employers)
-	companyQuery := fmt.Sprintf(`
-		SELECT p.node_id, c.properties, e.properties, e.edge_type
-		FROM graph_nodes p
-		JOIN graph_edges e ON p.id = e.source_node_id
-		JOIN graph_nodes c ON e.target_node_id = c.id
-		WHERE p.node_id IN %s
-		  AND (e.edge_type = 'WORKED_AT' OR e.edge_type = 'WORKS_AT')
-		  AND c.node_type = 'company'
-	`, inClause)
-
-	companyRows, err := h.db.QueryContext(ctx, companyQuery, personIDs...)
-	if err != nil {
-		log.Printf("[HybridSearch] Failed to batch load companies: %v", err)
-	} else {
-		defer companyRows.Close()
-		for companyRows.Next() {
-			var personID, edgeType string
-			var companyPropsJSON, edgePropsJSON []byte
-			if err := companyRows.Scan(&personID, &companyPropsJSON, &edgePropsJSON, &edgeType); err != nil {
-				continue
-			}
-
-			idx, ok := personIDToIndex[personID]
-			if !ok {
-				continue
-			}
-
-			var companyProps, edgeProps map[string]interface{}
-			if err := json.Unmarshal(companyPropsJSON, &companyProps); err != nil {
-				continue
-			}
-
-			name, ok := companyProps["name"].(string)
-			if !ok {
-				continue
-			}
-
-			company := CompanyNode{Name: name}
-			if err := json.Unmarshal(edgePropsJSON, &edgeProps); err == nil {
-				if pos, ok := edgeProps["position"].(string); ok {
-					company.Position = pos
-				}
-				if isCurr, ok := edgeProps["is_current"].(bool); ok {
-					company.IsCurrent = isCurr
-				}
-			}
-			// WORKS_AT always means current employer regardless of stored props
-			if edgeType == "WORKS_AT" {
-				company.IsCurrent = true
-			}
-
-			candidates[idx].Companies = append(candidates[idx].Companies, company)
-		}
-	}
-
-	// BATCH 4: Load community memberships from graph_communities (written by detect_communities tool).
-	// Reads membership_strength per candidate so Steps 2.7 and 2.9 get real cluster-based scores.
-	// If the table is empty (tool hasn't run yet) no rows are returned and the keyword fallback below fires.
-	dbCommunityLoaded := make(map[string]bool) // personID → true if at least one DB community loaded
-	if len(personIDs) > 0 {
-		communityMemberQuery := fmt.Sprintf(`
-			SELECT p.node_id, gc.community_id, gc.summary, COALESCE(cm.membership_strength, 1.0)
-			FROM graph_nodes p
-			JOIN community_members cm ON p.id = cm.node_id
-			JOIN graph_communities gc ON cm.community_id = gc.id
-			WHERE p.node_id IN %s
-			  AND p.node_type = 'person'
-			  AND gc.summary IS NOT NULL
-			ORDER BY gc.node_count DESC
-		`, inClause)
-
-		communityRows, err := h.db.QueryContext(ctx, communityMemberQuery, personIDs...)
-		if err != nil {
-			log.Printf("[HybridSearch] Failed to batch load computed communities (non-fatal): %v", err)
-		} else {
-			defer communityRows.Close()
-			for communityRows.Next() {
-				var personID, communityID string
-				var summary sql.NullString
-				var strength float64
-				if err := communityRows.Scan(&personID, &communityID, &summary, &strength); err != nil {
-					continue
-				}
-				idx, ok := personIDToIndex[personID]
-				if !ok {
-					continue
-				}
-				// Primary community = first row (highest node_count due to ORDER BY).
-				if candidates[idx].ComputedCommunityID == "" {
-					candidates[idx].ComputedCommunityID = communityID
-					if summary.Valid {
-						candidates[idx].ComputedCommunitySummary = summary.String
-					}
-				}
-				// Populate CommunityScores and Communities from DB membership_strength.
-				if candidates[idx].CommunityScores == nil {
-					candidates[idx].CommunityScores = make(map[string]float64)
-				}
-				candidates[idx].CommunityScores[communityID] = strength
-				alreadyInList := false
-				for _, c := range candidates[idx].Communities {
-					if c == communityID {
-						alreadyInList = true
-						break
-					}
-				}
-				if !alreadyInList {
-					candidates[idx].Communities = append(candidates[idx].Communities, communityID)
-				}
-				dbCommunityLoaded[personID] = true
-			}
-		}
-	}
-
-	// Keyword-based community assignment: fallback for candidates with no DB community data.
-	// Runs when graph_communities table is empty (detect_communities tool hasn't run yet)
-	// or for any candidate that wasn't covered by community_members.
-	for i := range candidates {
-		if dbCommunityLoaded[candidates[i].PersonID] {
-			// DB communities already loaded; set primary from ComputedCommunityID.
-			if candidates[i].Community == "" {
-				candidates[i].Community = candidates[i].ComputedCommunityID
-			}
-			continue
-		}
-		// Fallback: derive community from skills via keyword matching.
-		if len(candidates[i].Skills) > 0 {
-			primary, communities, scores := FindCommunities(candidates[i].Skills, 0.3)
-			candidates[i].Community = primary
-			candidates[i].Communities = communities
-			candidates[i].CommunityScores = scores
-		}
-	}
-
-	// BATCH 5: Load interviews for all candidates via candidates.graph_node_id
-	if len(nodeIntIDIndex) > 0 {
-		nodeIntIDs := make([]interface{}, 0, len(nodeIntIDIndex))
-		for nodeIntID := range nodeIntIDIndex {
-			nodeIntIDs = append(nodeIntIDs, nodeIntID)
-		}
-		interviewPlaceholders := make([]string, len(nodeIntIDs))
-		for i := range nodeIntIDs {
-			interviewPlaceholders[i] = fmt.Sprintf("$%d", i+1)
-		}
-		interviewQuery := fmt.Sprintf(`
-			SELECT c.graph_node_id, i.id, i.interview_date,
-			       COALESCE(i.team,''), COALESCE(i.interviewer_name,''),
-			       COALESCE(i.interview_type,''), COALESCE(i.outcome,''), COALESCE(i.notes,'')
-			FROM interviews i
-			JOIN candidates c ON c.id = i.candidate_id
-			WHERE c.graph_node_id IN (%s)
-			ORDER BY i.interview_date DESC
-		`, strings.Join(interviewPlaceholders, ","))
-
-		ivRows, ivErr := h.db.QueryContext(ctx, interviewQuery, nodeIntIDs...)
-		if ivErr != nil {
-			log.Printf("[HybridSearch] Failed to batch load interviews (non-fatal): %v", ivErr)
-		} else {
-			defer ivRows.Close()
-			for ivRows.Next() {
-				var nodeIntID, ivID int
-				var iv InterviewContext
-				if err := ivRows.Scan(
-					&nodeIntID, &ivID, &iv.InterviewDate,
-					&iv.Team, &iv.InterviewerName, &iv.InterviewType, &iv.Outcome, &iv.Notes,
-				); err != nil {
-					continue
-				}
-				iv.ID = ivID
-				if idx, ok := nodeIntIDIndex[nodeIntID]; ok {
-					candidates[idx].Interviews = append(candidates[idx].Interviews, iv)
-				}
-			}
-		}
-	}
+	// Step 7: Optional diversity pass. Runs after caching so the cache always
+	// holds the relevance order and each request applies its own lambda.
+	return diversifyMMR(validCandidates, config.DiversityLambda), diag, nil
 }
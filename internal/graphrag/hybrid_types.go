@@ -0,0 +1,167 @@
+package graphrag
+
+import (
+	"time"
+)
+
+// InterviewContext holds lightweight interview data attached to a search candidate.
+// Used for outcome-based score adjustments and LLM prompt enrichment.
+type InterviewContext struct {
+	ID              int
+	InterviewDate   time.Time
+	Team            string
+	InterviewerName string
+	InterviewType   string
+	Outcome         string // passed, failed, pending
+	Notes           string // available for re-embedding; excluded from API response
+}
+
+// FusedCandidate represents a candidate with scores from multiple sources
+type FusedCandidate struct {
+	CandidateID              int
+	GraphNodeIntID           int    // graph_nodes.id (integer PK), needed for interview lookup
+	PersonID                 string // Graph uses string IDs
+	Name                     string
+	CurrentPosition          string
+	Seniority                string
+	TotalExperienceYears     int
+	Skills                   []SkillNode
+	Companies                []CompanyNode
+	Projects                 []string            // ProjectProperties.Text of each project
+	Interviews               []InterviewContext  // loaded during enrichment
+	Tags                     []string            // candidate_tags, loaded during enrichment
+	Location                 string              // where the person lives, canonical when resolved
+	DistanceKm               *float64            // to the Location filter's city, when both have coordinates
+	SalaryInBand             *bool               // true when the salary the CV states passed the SalaryBand filter; nil without either, or when it couldn't be compared
+	Status                   string              // the candidate's status when not active (see IncludeStatuses)
+	Community                string              // Primary community
+	Communities              []string            // All matching communities (Microsoft GraphRAG style)
+	CommunityScores          map[string]float64  // Normalized scores for each community
+	ComputedCommunityID      string              // ID of the graph-computed community this person belongs to
+	ComputedCommunitySummary string              // LLM summary of that community
+	ComputedCommunities      []ComputedCommunity // levels 0 and 1, for the scorer prompt; strongest membership first
+	BM25Score                float64             // 0-1 normalized
+	VectorScore              float64             // 0-1 normalized
+	GraphScore               float64             // 0-1 normalized
+	FusionScore              float64             // Weighted combination
+	LLMScore                 float64             // Final LLM reranking score (0-100)
+	LLMReasoning             string
+	Signals                  *RankingSignals // graph-derived recency / progression signals (nil when no dates)
+	Rank                     int
+
+	home               PersonProperties // the person node's properties, for the Location and SalaryBand filters
+	keywordCommunities []string         // names of the keyword communities, when none were detected
+}
+
+// ComputedCommunity is a graph_communities cluster a person belongs to, as
+// detect_communities titled and summarized it.
+type ComputedCommunity struct {
+	Level    int
+	ID       string // graph_communities.community_id
+	Title    string
+	Summary  string
+	Strength float64 // community_members.membership_strength
+}
+
+// VectorSearchResult represents a candidate from vector search
+type VectorSearchResult struct {
+	CandidateID int
+	PersonID    string
+	Similarity  float64
+}
+
+// HybridSearchConfig defines weights for fusion
+type HybridSearchConfig struct {
+	BM25Weight         float64 // Default: 0.3
+	VectorWeight       float64 // Default: 0.4
+	GraphWeight        float64 // Default: 0.3
+	TopK               int     // How many candidates to retrieve from each source
+	FinalTopN          int     // How many to send to LLM for reranking
+	UseCommunityFilter bool    // Enable community-based filtering (default: false, enabled at 50+ candidates)
+	CommunityThreshold int     // Auto-enable community filter at this candidate count (default: 50)
+
+	// How BM25 ranks a match in each candidate field.
+	BM25FieldWeights BM25FieldWeights
+
+	// Per-source retrieval deadlines. A source that misses its deadline is
+	// dropped from fusion (with a warning) instead of failing the search.
+	BM25Timeout   time.Duration
+	VectorTimeout time.Duration
+	GraphTimeout  time.Duration // includes LLM criteria extraction
+
+	// Pipeline stage budgets (0 = none). A stage over its budget is cut
+	// short and the search answers with what it has, plus a warning: graph
+	// retrieval is skipped without query analysis, retrieval keeps the
+	// sources that finished, fusion skips the enrichment left, and
+	// reranking falls back to fusion scores.
+	AnalysisTimeout  time.Duration // LLM criteria extraction, within GraphTimeout
+	RetrievalTimeout time.Duration // the three sources together
+	FusionTimeout    time.Duration
+	RerankTimeout    time.Duration
+
+	Reranker            string // RerankerLLM (default) or RerankerNone
+	ScoringInstructions string // Extra instructions appended to the LLM scoring prompt
+	Experiment          string // Name of the search experiment this config came from ("" = built-in)
+
+	// DiversityLambda enables an MMR pass over the final ranking: values in
+	// (0,1) trade relevance (1) against community/company diversity (0).
+	// 0 (default) keeps the pure relevance order.
+	DiversityLambda float64
+
+	// Recruiter tags (candidate_tags, lowercase). A candidate must carry
+	// every RequireTags tag and none of ExcludeTags; its final score is
+	// multiplied by the TagBoosts factor of each tag it carries (>1 promotes,
+	// <1 demotes).
+	RequireTags []string
+	ExcludeTags []string
+	TagBoosts   map[string]float64
+
+	// Location filter: a candidate must live in Location (a resolved city or
+	// country) or, for a city, within LocationRadiusKm of it.
+	Location         *Location
+	LocationRadiusKm float64
+
+	// Salary filter: a candidate whose CV states a salary must be within
+	// the band; those who stated none (most CVs don't), or one the band
+	// can't be compared with, are kept.
+	SalaryBand *SalaryBand
+
+	// Syntax is the query parsed as a boolean expression (ParseQuerySyntax),
+	// nil for plain text. Every result must match it.
+	Syntax *QuerySyntax
+
+	// Candidates of ExcludedStatuses (hired, archived, do-not-contact) are
+	// left out unless their status is in IncludeStatuses.
+	IncludeStatuses []string
+
+	// Keyword communities, for candidates without detected communities and
+	// queries no detected community matches: the organization's patterns
+	// merged with the defaults (MergeCommunityPatterns). nil = the defaults.
+	CommunityPatterns CommunityPatterns
+}
+
+// hasTagOptions reports whether c filters or boosts by tag.
+func (c HybridSearchConfig) hasTagOptions() bool {
+	return len(c.RequireTags) > 0 || len(c.ExcludeTags) > 0 || len(c.TagBoosts) > 0
+}
+
+func DefaultHybridConfig() HybridSearchConfig {
+	return HybridSearchConfig{
+		BM25Weight:         0.0,
+		VectorWeight:       0.6,
+		GraphWeight:        0.4,
+		TopK:               100,
+		FinalTopN:          8, // Skill-based searches bypass this (see Step 2.55). Used only for skill-less queries as an LLM cost guard.
+		UseCommunityFilter: false,
+		CommunityThreshold: 10,
+		BM25Timeout:        5 * time.Second,
+		VectorTimeout:      10 * time.Second,
+		GraphTimeout:       20 * time.Second,
+		AnalysisTimeout:    15 * time.Second,
+		RetrievalTimeout:   30 * time.Second,
+		FusionTimeout:      15 * time.Second,
+		RerankTimeout:      2 * time.Minute,
+		Reranker:           RerankerLLM,
+		BM25FieldWeights:   DefaultBM25FieldWeights(),
+	}
+}
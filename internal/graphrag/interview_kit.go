@@ -0,0 +1,367 @@
+package graphrag
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"cv-search/internal/textnorm"
+)
+
+// ─── Interview kit ───────────────────────────────────────────────────────────
+//
+// An interview kit is a set of questions for one candidate, written by the
+// LLM from what their graph says they did (companies, projects, skills) and,
+// when given, the job description they're interviewing for. Each question
+// names what it is about and may quote the CV; quotes the CV doesn't contain
+// are dropped, so an interviewer can rely on the ones that remain.
+
+// maxKitCVChars caps the CV text given to the LLM, in characters.
+const maxKitCVChars = 12000
+
+// Interview kit sections, in the order they're returned.
+const (
+	KitSectionTechnical  = "technical"
+	KitSectionBehavioral = "behavioral"
+)
+
+// Interview question bases: what in the candidate's graph a question is
+// about.
+const (
+	KitBasisProject = "project"
+	KitBasisCompany = "company"
+	KitBasisSkill   = "skill"
+)
+
+// InterviewQuestion is one question of an interview kit.
+type InterviewQuestion struct {
+	Question    string   `json:"question"`
+	BasisType   string   `json:"basis_type,omitempty"`   // project | company | skill
+	Basis       string   `json:"basis,omitempty"`        // the project, company or skill, as the graph names it
+	CVReference string   `json:"cv_reference,omitempty"` // a passage of the CV the question is about, verbatim
+	LookFor     string   `json:"look_for,omitempty"`     // what a strong answer shows
+	FollowUps   []string `json:"follow_ups,omitempty"`
+}
+
+// InterviewSection is a group of interview questions.
+type InterviewSection struct {
+	Name      string              `json:"name"` // technical | behavioral
+	Questions []InterviewQuestion `json:"questions"`
+}
+
+// InterviewKit is the questions for interviewing one candidate.
+type InterviewKit struct {
+	Role     string             `json:"role,omitempty"` // the job description's title, when one was given
+	Sections []InterviewSection `json:"sections"`
+	Warnings []string           `json:"warnings,omitempty"` // e.g. CV references that were dropped
+}
+
+// candidateCompany is a company the candidate worked at, from a WORKS_AT /
+// WORKED_AT edge.
+type candidateCompany struct {
+	company string
+	work    WorkProperties
+}
+
+// GenerateInterviewKit writes an interview kit for the person node
+// personNodeID (graph_nodes.id), tailored to jobDescription if it isn't
+// empty. sql.ErrNoRows if the node doesn't exist.
+func (s *LLMSearchEngine) GenerateInterviewKit(ctx context.Context, personNodeID int, jobDescription string) (*InterviewKit, error) {
+	person, err := s.personProperties(ctx, personNodeID)
+	if err != nil {
+		return nil, err
+	}
+	skills, err := s.candidateSkills(ctx, personNodeID)
+	if err != nil {
+		return nil, err
+	}
+	work, err := s.candidateWork(ctx, personNodeID)
+	if err != nil {
+		return nil, err
+	}
+	projects, err := s.candidateProjects(ctx, personNodeID)
+	if err != nil {
+		return nil, err
+	}
+	var cvText string
+	if person.CVID > 0 {
+		err := s.db.QueryRowContext(ctx, `
+			SELECT COALESCE(parsed_text, '') FROM cv_files WHERE id = $1 AND deleted_at IS NULL
+		`, person.CVID).Scan(&cvText)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, fmt.Errorf("get CV text of node %d: %w", personNodeID, err)
+		}
+	}
+	if r := []rune(cvText); len(r) > maxKitCVChars {
+		cvText = string(r[:maxKitCVChars])
+	}
+
+	prompt := interviewKitPrompt(person, skills, work, projects, cvText, jobDescription)
+	response, err := s.llm.Generate(ctx, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("LLM interview kit failed: %w", err)
+	}
+	var out struct {
+		Role     string             `json:"role"`
+		Sections []InterviewSection `json:"sections"`
+	}
+	if err := json.Unmarshal([]byte(extractJSON(response)), &out); err != nil {
+		return nil, fmt.Errorf("failed to parse interview kit: %w", err)
+	}
+
+	kit := &InterviewKit{Sections: []InterviewSection{}}
+	if strings.TrimSpace(jobDescription) != "" {
+		kit.Role = strings.TrimSpace(out.Role)
+	}
+	bases := kitBases(skills, work, projects)
+	cvKey := quoteKey(cvText)
+	dropped := 0
+	for _, name := range []string{KitSectionTechnical, KitSectionBehavioral} {
+		section := InterviewSection{Name: name, Questions: []InterviewQuestion{}}
+		for _, sec := range out.Sections {
+			if strings.ToLower(strings.TrimSpace(sec.Name)) != name {
+				continue
+			}
+			for _, q := range sec.Questions {
+				q.Question = strings.TrimSpace(q.Question)
+				if q.Question == "" {
+					continue
+				}
+				q.BasisType, q.Basis = bases.resolve(q.BasisType, q.Basis)
+				q.CVReference = strings.TrimSpace(q.CVReference)
+				if q.CVReference != "" && (cvKey == "" || !strings.Contains(cvKey, quoteKey(q.CVReference))) {
+					q.CVReference = ""
+					dropped++
+				}
+				q.LookFor = strings.TrimSpace(q.LookFor)
+				q.FollowUps = dedupeQuestions(q.FollowUps)
+				section.Questions = append(section.Questions, q)
+			}
+		}
+		kit.Sections = append(kit.Sections, section)
+	}
+	if dropped > 0 {
+		kit.Warnings = append(kit.Warnings, fmt.Sprintf("%d CV reference(s) not found in the CV text were removed", dropped))
+	}
+	if cvText == "" {
+		kit.Warnings = append(kit.Warnings, "CV text unavailable; questions are based on the graph profile only")
+	}
+	return kit, nil
+}
+
+// candidateWork returns the companies of a person node, most recent first.
+func (s *LLMSearchEngine) candidateWork(ctx context.Context, personNodeID int) ([]candidateCompany, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT c.properties->>'name', COALESCE(e.properties, '{}'::jsonb)
+		FROM graph_edges e
+		JOIN graph_nodes c ON c.id = e.target_node_id
+		WHERE e.source_node_id = $1 AND e.edge_type IN ('WORKS_AT', 'WORKED_AT')
+		  AND c.node_type = 'company' AND c.deleted_at IS NULL
+		ORDER BY (e.edge_type = 'WORKS_AT') DESC, COALESCE((e.properties->>'start_year')::int, 0) DESC
+	`, personNodeID)
+	if err != nil {
+		return nil, fmt.Errorf("list companies of node %d: %w", personNodeID, err)
+	}
+	defer rows.Close()
+
+	var work []candidateCompany
+	for rows.Next() {
+		var name sql.NullString
+		var edgeJSON []byte
+		if err := rows.Scan(&name, &edgeJSON); err != nil {
+			return nil, fmt.Errorf("scan company of node %d: %w", personNodeID, err)
+		}
+		if strings.TrimSpace(name.String) == "" {
+			continue
+		}
+		w, _ := DecodeWorkProperties(edgeJSON)
+		work = append(work, candidateCompany{company: name.String, work: w})
+	}
+	return work, rows.Err()
+}
+
+// candidateProjects returns the projects of a person node.
+func (s *LLMSearchEngine) candidateProjects(ctx context.Context, personNodeID int) ([]ProjectProperties, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT p.properties
+		FROM graph_edges e
+		JOIN graph_nodes p ON p.id = e.target_node_id
+		WHERE e.source_node_id = $1 AND e.edge_type = 'WORKED_ON'
+		  AND p.node_type = 'project' AND p.deleted_at IS NULL
+		ORDER BY p.id
+	`, personNodeID)
+	if err != nil {
+		return nil, fmt.Errorf("list projects of node %d: %w", personNodeID, err)
+	}
+	defer rows.Close()
+
+	var projects []ProjectProperties
+	for rows.Next() {
+		var propsJSON []byte
+		if err := rows.Scan(&propsJSON); err != nil {
+			return nil, fmt.Errorf("scan project of node %d: %w", personNodeID, err)
+		}
+		p, err := DecodeProjectProperties(propsJSON)
+		if err != nil || strings.TrimSpace(p.Name) == "" {
+			continue
+		}
+		projects = append(projects, p)
+	}
+	return projects, rows.Err()
+}
+
+// interviewKitPrompt asks for an interview kit grounded in the candidate's
+// profile and CV.
+func interviewKitPrompt(person PersonProperties, skills []candidateSkill, work []candidateCompany, projects []ProjectProperties, cvText, jobDescription string) string {
+	role := "No job description was given: probe the candidate's own claims and overall level."
+	if jd := strings.TrimSpace(jobDescription); jd != "" {
+		role = fmt.Sprintf("The candidate is interviewing for this role; weight questions towards what it needs:\n\"\"\"\n%s\n\"\"\"", jd)
+	}
+	cv := cvText
+	if strings.TrimSpace(cv) == "" {
+		cv = "(not available)"
+	}
+
+	return fmt.Sprintf(`You are a senior interviewer preparing an interview for one candidate.
+
+ROLE:
+%s
+
+CANDIDATE PROFILE:
+%s
+
+CV TEXT:
+"""
+%s
+"""
+
+Return ONLY valid JSON:
+{
+  "role": "the role's title from the job description, \"\" if none was given",
+  "sections": [
+    {"name": "technical", "questions": [
+      {"question": "...", "basis_type": "project|company|skill", "basis": "...", "cv_reference": "...", "look_for": "...", "follow_ups": ["..."]}
+    ]},
+    {"name": "behavioral", "questions": [ ... ]}
+  ]
+}
+
+Rules:
+- 6-8 technical and 4-5 behavioral questions
+- Ground every question in something the candidate actually did: a project, a company they worked at or a skill they claim. Ask about their decisions, trade-offs and results there, not textbook trivia
+- Technical questions test the depth of claimed skills (especially ones with many years or that the role needs); behavioral questions ask about situations at the listed companies and projects
+- basis_type / basis: what the question is about, with the name exactly as listed in the profile
+- cv_reference: a short passage copied verbatim from the CV text that the question is about (a sentence or phrase, not paraphrased); "" if none
+- look_for: what a strong answer shows, one sentence
+- follow_ups: 1-2 probing follow-up questions
+- Write the questions in the language the CV is written in`, role, describeCandidate(person, skills, work, projects), cv)
+}
+
+// describeCandidate is a candidate's profile for a prompt: position,
+// seniority, experience, companies, projects and skills.
+func describeCandidate(person PersonProperties, skills []candidateSkill, work []candidateCompany, projects []ProjectProperties) string {
+	var profile strings.Builder
+	if person.CurrentPosition != "" {
+		fmt.Fprintf(&profile, "Current position: %s\n", person.CurrentPosition)
+	}
+	if person.Seniority != "" {
+		fmt.Fprintf(&profile, "Seniority: %s\n", person.Seniority)
+	}
+	if person.TotalExperienceYears != nil {
+		fmt.Fprintf(&profile, "Experience: %.1f years\n", *person.TotalExperienceYears)
+	}
+	profile.WriteString("Companies:")
+	for _, w := range work {
+		fmt.Fprintf(&profile, "\n- %s", w.company)
+		if w.work.Position != "" {
+			fmt.Fprintf(&profile, ", %s", w.work.Position)
+		}
+		if w.work.StartYear > 0 {
+			fmt.Fprintf(&profile, " (%d-", w.work.StartYear)
+			if w.work.IsCurrent {
+				profile.WriteString("present)")
+			} else if w.work.EndYear > 0 {
+				fmt.Fprintf(&profile, "%d)", w.work.EndYear)
+			} else {
+				profile.WriteString(")")
+			}
+		}
+	}
+	if len(work) == 0 {
+		profile.WriteString(" none listed")
+	}
+	profile.WriteString("\nProjects:")
+	for _, p := range projects {
+		fmt.Fprintf(&profile, "\n- %s", p.Text())
+	}
+	if len(projects) == 0 {
+		profile.WriteString(" none listed")
+	}
+	profile.WriteString("\nSkills:")
+	for _, sk := range skills {
+		fmt.Fprintf(&profile, "\n- %s", sk.name)
+		if sk.years != nil {
+			fmt.Fprintf(&profile, " (%.1f years)", *sk.years)
+		}
+	}
+	if len(skills) == 0 {
+		profile.WriteString(" none listed")
+	}
+
+	return strings.TrimSuffix(profile.String(), "\n")
+}
+
+// kitBasisSet is the names a question may be about, per basis type, keyed by
+// skillKey.
+type kitBasisSet map[string]map[string]string
+
+func kitBases(skills []candidateSkill, work []candidateCompany, projects []ProjectProperties) kitBasisSet {
+	set := kitBasisSet{KitBasisProject: {}, KitBasisCompany: {}, KitBasisSkill: {}}
+	for _, sk := range skills {
+		set[KitBasisSkill][skillKey(sk.name)] = sk.name
+	}
+	for _, w := range work {
+		set[KitBasisCompany][skillKey(w.company)] = w.company
+	}
+	for _, p := range projects {
+		set[KitBasisProject][skillKey(p.Name)] = p.Name
+	}
+	return set
+}
+
+// resolve returns the basis as the graph names it, or empty strings if the
+// candidate has no such project, company or skill.
+func (set kitBasisSet) resolve(basisType, basis string) (string, string) {
+	basisType = strings.ToLower(strings.TrimSpace(basisType))
+	names, ok := set[basisType]
+	if !ok {
+		return "", ""
+	}
+	name, ok := names[skillKey(basis)]
+	if !ok {
+		return "", ""
+	}
+	return basisType, name
+}
+
+// quoteKey is what a CV passage and a quote of it share: folded, with runs
+// of whitespace collapsed, as PDF text breaks lines where the LLM doesn't.
+func quoteKey(s string) string {
+	return strings.Join(strings.Fields(textnorm.Fold(s)), " ")
+}
+
+// dedupeQuestions trims questions and drops empty ones and repeats.
+func dedupeQuestions(qs []string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, q := range qs {
+		q = strings.TrimSpace(q)
+		if k := quoteKey(q); k != "" && !seen[k] {
+			seen[k] = true
+			out = append(out, q)
+		}
+	}
+	return out
+}
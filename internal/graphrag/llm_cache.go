@@ -109,6 +109,7 @@ func (c *LLMCache) generateKey(query string, candidateIDs []string) string {
 // Stores query embeddings and results. On a new query, computes cosine similarity
 // against stored embeddings; if similarity >= threshold it is treated as the same
 // query and the cached results are returned immediately (bypasses full pipeline).
+// Entries belong to one organization and only match its own queries.
 
 // SemanticCache caches search results keyed by query embedding similarity.
 type SemanticCache struct {
@@ -119,6 +120,7 @@ type SemanticCache struct {
 }
 
 type semanticCacheEntry struct {
+	OrgID          int
 	QueryEmbedding []float32
 	QueryText      string
 	Results        []FusedCandidate
@@ -136,9 +138,10 @@ func NewSemanticCache(ttl time.Duration, threshold float64) *SemanticCache {
 	}
 }
 
-// Get returns cached results if a sufficiently similar query exists and has not expired.
+// Get returns cached results if a sufficiently similar query of the organization
+// orgID exists and has not expired.
 // Returns (results, originalQueryText, found).
-func (c *SemanticCache) Get(queryEmbedding []float32) ([]FusedCandidate, string, bool) {
+func (c *SemanticCache) Get(orgID int, queryEmbedding []float32) ([]FusedCandidate, string, bool) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
@@ -147,7 +150,7 @@ func (c *SemanticCache) Get(queryEmbedding []float32) ([]FusedCandidate, string,
 	var bestEntry *semanticCacheEntry
 
 	for _, entry := range c.entries {
-		if now.Sub(entry.Timestamp) > c.ttl {
+		if now.Sub(entry.Timestamp) > c.ttl || entry.OrgID != orgID {
 			continue
 		}
 		sim := cosineSimilarity32(queryEmbedding, entry.QueryEmbedding)
@@ -163,10 +166,11 @@ func (c *SemanticCache) Get(queryEmbedding []float32) ([]FusedCandidate, string,
 	return nil, "", false
 }
 
-// Set stores results for a query embedding, evicting expired entries first.
+// Set stores results for a query embedding of the organization orgID,
+// evicting expired entries first.
 // If the cache still exceeds semanticCacheMaxSize after expiry eviction, the oldest
 // entries are trimmed to keep memory bounded.
-func (c *SemanticCache) Set(queryEmbedding []float32, queryText string, results []FusedCandidate) {
+func (c *SemanticCache) Set(orgID int, queryEmbedding []float32, queryText string, results []FusedCandidate) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -187,6 +191,7 @@ func (c *SemanticCache) Set(queryEmbedding []float32, queryText string, results
 	}
 
 	c.entries = append(c.entries, &semanticCacheEntry{
+		OrgID:          orgID,
 		QueryEmbedding: queryEmbedding,
 		QueryText:      queryText,
 		Results:        results,
@@ -194,6 +199,13 @@ func (c *SemanticCache) Set(queryEmbedding []float32, queryText string, results
 	})
 }
 
+// Clear drops every cached result set (e.g. after a candidate is deleted).
+func (c *SemanticCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = c.entries[:0]
+}
+
 // cosineSimilarity32 computes cosine similarity between two float32 vectors.
 func cosineSimilarity32(a, b []float32) float64 {
 	if len(a) != len(b) || len(a) == 0 {
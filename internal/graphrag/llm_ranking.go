@@ -0,0 +1,131 @@
+package graphrag
+
+import (
+	"log"
+	"sort"
+	"strings"
+)
+
+// llmMatch is one entry of the "top_matches" array returned by the LLM
+// ranking prompts (LLM-only and enhanced search).
+type llmMatch struct {
+	PersonID     string   `json:"person_id"`
+	Name         string   `json:"name"`
+	Fit          string   `json:"fit"`
+	Reasoning    string   `json:"reasoning"`
+	KeyStrengths []string `json:"key_strengths"`
+}
+
+// fitScore converts the LLM fit label into a score (no local heuristics).
+func fitScore(fit string) float64 {
+	switch strings.ToLower(fit) {
+	case "excellent":
+		return 95.0
+	case "good":
+		return 80.0
+	case "fair":
+		return 60.0
+	case "poor":
+		return 30.0
+	default:
+		return 50.0
+	}
+}
+
+// mergeLLMMatches maps LLM matches back onto the candidates that were sent in
+// the prompt. Matching is by person_id; the name is only used as a fallback
+// when the model dropped the ID and the name is unambiguous in this batch.
+//
+// Candidates the LLM didn't mention are not dropped: they are appended after
+// every LLM-ranked candidate, ordered by their retrieval score (MatchScore —
+// vector similarity where available, otherwise input order), and flagged
+// UnrankedByLLM so clients can tell the two groups apart.
+func mergeLLMMatches(candidates []CandidateResult, matches []llmMatch) []LLMRankedCandidate {
+	byPersonID := make(map[string]int, len(candidates))
+	nameCount := make(map[string]int, len(candidates))
+	byName := make(map[string]int, len(candidates))
+	for i, c := range candidates {
+		byPersonID[c.PersonID] = i
+		key := strings.ToLower(strings.TrimSpace(c.Name))
+		nameCount[key]++
+		byName[key] = i
+	}
+
+	used := make(map[int]bool, len(candidates))
+	ranked := make([]LLMRankedCandidate, 0, len(candidates))
+	for _, match := range matches {
+		idx, ok := byPersonID[strings.TrimSpace(match.PersonID)]
+		if !ok {
+			key := strings.ToLower(strings.TrimSpace(match.Name))
+			if nameCount[key] != 1 {
+				log.Printf("[LLM Ranking] Could not resolve match (person_id=%q, name=%q) — skipping", match.PersonID, match.Name)
+				continue
+			}
+			idx = byName[key]
+		}
+		if used[idx] {
+			continue
+		}
+		used[idx] = true
+
+		score := fitScore(match.Fit)
+		rc := toRankedCandidate(candidates[idx])
+		rc.Fit = match.Fit
+		rc.Reasoning = match.Reasoning
+		rc.KeyStrengths = match.KeyStrengths
+		rc.MatchScore = score
+		rc.FinalScore = score // Pure LLM score (no local heuristics)
+		ranked = append(ranked, rc)
+	}
+
+	// Sort by FinalScore descending (pure LLM ranking)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].FinalScore > ranked[j].FinalScore
+	})
+
+	var unranked []LLMRankedCandidate
+	for i, c := range candidates {
+		if used[i] {
+			continue
+		}
+		rc := toRankedCandidate(c)
+		rc.MatchScore = c.MatchScore
+		rc.FinalScore = c.MatchScore
+		rc.UnrankedByLLM = true
+		unranked = append(unranked, rc)
+	}
+	sort.SliceStable(unranked, func(i, j int) bool {
+		return unranked[i].FinalScore > unranked[j].FinalScore
+	})
+
+	if len(unranked) > 0 {
+		log.Printf("[LLM Ranking] LLM ranked %d candidates, %d appended as unranked_by_llm", len(ranked), len(unranked))
+	}
+	return append(ranked, unranked...)
+}
+
+// rankedByLLMCount counts candidates the LLM actually ranked (summaries
+// shouldn't call appended fallback candidates "relevant").
+func rankedByLLMCount(candidates []LLMRankedCandidate) int {
+	n := 0
+	for _, c := range candidates {
+		if !c.UnrankedByLLM {
+			n++
+		}
+	}
+	return n
+}
+
+func toRankedCandidate(c CandidateResult) LLMRankedCandidate {
+	return LLMRankedCandidate{
+		CVID:            c.CVID,
+		PersonID:        c.PersonID,
+		Name:            c.Name,
+		CurrentPosition: c.CurrentPosition,
+		Seniority:       c.Seniority,
+		TotalExperience: c.TotalExperience,
+		Skills:          c.Skills,
+		Companies:       c.Companies,
+		Education:       c.Education,
+	}
+}
@@ -47,8 +47,10 @@ const llmBatchSize = 8 // single call; skill searches may send more, skill-less
 // ScoreCandidates sends candidates to LLM for scoring using parallel batches.
 // communitySummaries contains LLM-generated summaries of the most relevant graph communities
 // for this query — used as global context (GraphRAG global search style).
+// instructions, if set (search experiments), are appended to the scoring rules
+// and become part of the cache key.
 // Returns scored and sorted candidates.
-func (s *LLMScorer) ScoreCandidates(ctx context.Context, query string, candidates []FusedCandidate, communitySummaries []string) ([]CandidateScore, error) {
+func (s *LLMScorer) ScoreCandidates(ctx context.Context, query string, candidates []FusedCandidate, communitySummaries []string, instructions string) ([]CandidateScore, error) {
 	if len(candidates) == 0 {
 		return []CandidateScore{}, nil
 	}
@@ -59,8 +61,13 @@ func (s *LLMScorer) ScoreCandidates(ctx context.Context, query string, candidate
 		candidateIDs[i] = c.PersonID
 	}
 
+	cacheKey := query
+	if instructions != "" {
+		cacheKey = query + "\x00" + instructions
+	}
+
 	if !s.disableCache {
-		if cachedScores, found := s.cache.Get(query, candidateIDs); found {
+		if cachedScores, found := s.cache.Get(cacheKey, candidateIDs); found {
 			log.Printf("[LLMScorer] Cache HIT for query: %s (%d candidates)", query, len(cachedScores))
 			return cachedScores, nil
 		}
@@ -69,9 +76,12 @@ func (s *LLMScorer) ScoreCandidates(ctx context.Context, query string, candidate
 	}
 
 	log.Printf("[LLMScorer] Scoring %d candidates in a single call for consistent ranking", len(candidates))
+	// One call means one batch; progress still counts batches so UIs need no
+	// change if scoring is split again.
+	reportProgress(ctx, SearchProgress{Stage: StageRerank, Count: len(candidates), Batch: 1, Batches: 1})
 
-	prompt := s.buildScoringPrompt(query, candidates, communitySummaries)
-	response, err := s.llm.Generate(prompt)
+	prompt := s.buildScoringPrompt(query, candidates, communitySummaries, instructions)
+	response, err := s.llm.Generate(ctx, prompt)
 	if err != nil {
 		return nil, fmt.Errorf("LLM scoring call failed: %w", err)
 	}
@@ -89,7 +99,7 @@ func (s *LLMScorer) ScoreCandidates(ctx context.Context, query string, candidate
 
 	// Cache the combined results (skipped when cache is disabled, e.g. local dev)
 	if !s.disableCache {
-		s.cache.Set(query, candidateIDs, allScores)
+		s.cache.Set(cacheKey, candidateIDs, allScores)
 	}
 
 	return allScores, nil
@@ -99,7 +109,7 @@ func (s *LLMScorer) ScoreCandidates(ctx context.Context, query string, candidate
 // No hardcoded role rules — LLM evaluates fit based on skills, title, and experience.
 // communitySummaries contains LLM-generated summaries of the most relevant graph communities
 // for this query — injected as global context so the LLM understands the talent pool landscape.
-func (s *LLMScorer) buildScoringPrompt(query string, candidates []FusedCandidate, communitySummaries []string) string {
+func (s *LLMScorer) buildScoringPrompt(query string, candidates []FusedCandidate, communitySummaries []string, instructions string) string {
 	var b strings.Builder
 
 	b.WriteString("You are a senior technical recruiter. Score each candidate for the following search query.\n\n")
@@ -114,16 +124,37 @@ func (s *LLMScorer) buildScoringPrompt(query string, candidates []FusedCandidate
 	}
 	b.WriteString("Scoring rules (0-100):\n")
 	b.WriteString("- Role type match: if the query specifies a role (e.g. analyst, product owner, developer, architect), the candidate's PRIMARY role must match that type. A candidate with a mismatched primary role (e.g. a software architect for an 'analyst' query) must score NO HIGHER THAN 35, even if they have domain knowledge.\n")
-	b.WriteString("- Domain/skill match: does their skill set and work history align with the domain or skills mentioned in the query? (e.g. 'banking', 'trade finance', 'e-commerce')\n")
-	b.WriteString("- Seniority: does their seniority level match any level implied by the query?\n\n")
+	b.WriteString("- Domain/skill match: does their skill set, work history and projects align with the domain or skills mentioned in the query? (e.g. 'banking', 'trade finance', 'e-commerce', 'built a payment system')\n")
+	b.WriteString("- Seniority: does their seniority level match any level implied by the query?\n")
+	b.WriteString("- Recency: when Signals are given, prefer candidates currently using the requested skills over ones who used them years ago.\n")
+	if anyCommunities(candidates) {
+		b.WriteString("- Communities: the talent clusters detected in this organization's data, or the organization's role families, that a candidate belongs to. Use them as context for the candidate's domain; their own skills and history decide.\n")
+	}
+	if instructions != "" {
+		b.WriteString("- " + instructions + "\n")
+	}
+	b.WriteString("\n")
 	b.WriteString("Candidates:\n")
 
+	thisYear := time.Now().Year()
+
 	for i, c := range candidates {
 		b.WriteString(fmt.Sprintf("\n[%d] person_id: %s\n", i+1, c.PersonID))
 		b.WriteString(fmt.Sprintf("  Title: %s | Seniority: %s | Experience: %d yrs\n",
 			c.CurrentPosition, c.Seniority, c.TotalExperienceYears))
 		b.WriteString(fmt.Sprintf("  Skills: %s\n", skillNames(c.Skills)))
 		b.WriteString(fmt.Sprintf("  Work history: %s\n", companyNames(c.Companies)))
+		if len(c.Projects) > 0 {
+			b.WriteString(fmt.Sprintf("  Projects: %s\n", projectSummaries(c.Projects)))
+		}
+		if cs := describeCommunities(c.ComputedCommunities); cs != "" {
+			b.WriteString(fmt.Sprintf("  Communities: %s\n", cs))
+		} else if len(c.keywordCommunities) > 0 {
+			b.WriteString(fmt.Sprintf("  Communities: %s\n", strings.Join(c.keywordCommunities, ", ")))
+		}
+		if sig := describeSignals(c.Signals, thisYear); sig != "" {
+			b.WriteString(fmt.Sprintf("  Signals: %s\n", sig))
+		}
 		if len(c.Interviews) > 0 {
 			latest := c.Interviews[0] // ordered DESC by date
 			team := latest.Team
@@ -312,3 +343,66 @@ func companyNames(companies []CompanyNode) string {
 	}
 	return strings.Join(names, ", ")
 }
+
+// maxPromptProjects / maxProjectChars keep project descriptions from
+// crowding out the rest of the candidate in the reranking prompt.
+const (
+	maxPromptProjects = 3
+	maxProjectChars   = 160
+)
+
+func projectSummaries(projects []string) string {
+	if len(projects) > maxPromptProjects {
+		projects = projects[:maxPromptProjects]
+	}
+	out := make([]string, len(projects))
+	for i, p := range projects {
+		if r := []rune(p); len(r) > maxProjectChars {
+			p = string(r[:maxProjectChars]) + "…"
+		}
+		out[i] = p
+	}
+	return strings.Join(out, " | ")
+}
+
+// maxPromptCommunities / maxCommunityChars cap a candidate's detected
+// communities in the reranking prompt.
+const (
+	maxPromptCommunities = 2
+	maxCommunityChars    = 200
+)
+
+func anyCommunities(candidates []FusedCandidate) bool {
+	for _, c := range candidates {
+		if len(c.ComputedCommunities) > 0 || len(c.keywordCommunities) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// describeCommunities describes a candidate's detected communities as
+// "Title: summary", strongest membership first.
+func describeCommunities(communities []ComputedCommunity) string {
+	if len(communities) > maxPromptCommunities {
+		communities = communities[:maxPromptCommunities]
+	}
+	out := make([]string, 0, len(communities))
+	for _, c := range communities {
+		text := strings.TrimSpace(c.Title)
+		if summary := strings.TrimSpace(c.Summary); summary != "" {
+			if text != "" {
+				text += ": "
+			}
+			text += summary
+		}
+		if text == "" {
+			continue
+		}
+		if r := []rune(text); len(r) > maxCommunityChars {
+			text = string(r[:maxCommunityChars]) + "…"
+		}
+		out = append(out, text)
+	}
+	return strings.Join(out, " | ")
+}
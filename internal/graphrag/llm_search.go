@@ -8,12 +8,27 @@ import (
 	"log"
 	"sort"
 	"strings"
+
+	"cv-search/internal/tenant"
+)
+
+// Defaults of the LLM-only engine's prefilter and pagination (see
+// SetPrefilter).
+const (
+	DefaultLLMSearchPrefilterK = 100
+	DefaultLLMSearchBatchSize  = 50
 )
 
-// LLMSearchEngine performs semantic search using LLM reasoning instead of manual scoring
+// LLMSearchEngine performs semantic search using LLM reasoning instead of manual scoring.
+// The LLM never sees the whole organization: a BM25 + vector prefilter picks
+// at most prefilterK candidates, which are sent to it batchSize at a time.
 type LLMSearchEngine struct {
-	db  *sql.DB
-	llm LLMClient
+	db         *sql.DB
+	llm        LLMClient
+	bm25       *BM25Searcher
+	embeddings *EmbeddingService // nil: the prefilter is BM25 only
+	prefilterK int
+	batchSize  int
 }
 
 // DEPRECATED: fitToScore and adjustedLocalScore are NO LONGER USED
@@ -22,8 +37,40 @@ type LLMSearchEngine struct {
 
 func NewLLMSearchEngine(db *sql.DB, llm LLMClient) *LLMSearchEngine {
 	return &LLMSearchEngine{
-		db:  db,
-		llm: llm,
+		db:         db,
+		llm:        llm,
+		bm25:       NewBM25Searcher(db),
+		prefilterK: DefaultLLMSearchPrefilterK,
+		batchSize:  DefaultLLMSearchBatchSize,
+	}
+}
+
+// SetEmbeddingService adds vector search to the prefilter, so candidates
+// that match the query's meaning but none of its words still reach the LLM.
+func (s *LLMSearchEngine) SetEmbeddingService(embeddings *EmbeddingService) {
+	s.embeddings = embeddings
+}
+
+// SetTextSearchConfig selects the PostgreSQL text search config of the BM25
+// prefilter (see BM25Searcher.SetTextSearchConfig).
+func (s *LLMSearchEngine) SetTextSearchConfig(name string) {
+	s.bm25.SetTextSearchConfig(name)
+}
+
+// Snippets returns where query matched in the latest CVs of personIDs (see
+// BM25Searcher.Snippets).
+func (s *LLMSearchEngine) Snippets(ctx context.Context, query string, personIDs []string) (map[string]string, error) {
+	return s.bm25.Snippets(ctx, query, personIDs)
+}
+
+// SetPrefilter sets how many candidates at most are sent to the LLM and how
+// many go in one LLM call. Values below 1 keep the current ones.
+func (s *LLMSearchEngine) SetPrefilter(topK, batchSize int) {
+	if topK > 0 {
+		s.prefilterK = topK
+	}
+	if batchSize > 0 {
+		s.batchSize = batchSize
 	}
 }
 
@@ -52,21 +99,28 @@ type LLMRankedCandidate struct {
 	KeyStrengths    []string        `json:"key_strengths"`
 	MatchScore      float64         `json:"match_score"`
 	FinalScore      float64         `json:"final_score"`
+	UnrankedByLLM   bool            `json:"unranked_by_llm,omitempty"` // LLM didn't mention this candidate; scored by retrieval only
+	Snippet         string          `json:"snippet,omitempty"`         // where the query matched in the CV, see BM25Searcher.Snippets
 }
 
 // Search performs LLM-based semantic search
 func (s *LLMSearchEngine) Search(ctx context.Context, query string) (*LLMSearchResult, error) {
 	log.Printf("[LLM Search] Starting semantic search for: %s", query)
 
-	// Step 1: Fetch ALL candidates from database (no SQL filtering)
-	allCandidates, err := s.fetchAllCandidates(ctx)
+	// Step 1: Prefilter — at most prefilterK candidates, best BM25/vector
+	// matches first
+	personIDs, scores, err := s.prefilter(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prefilter candidates: %w", err)
+	}
+	candidates, err := s.fetchCandidates(ctx, personIDs, scores)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch candidates: %w", err)
 	}
 
-	log.Printf("[LLM Search] Loaded %d total candidates from database", len(allCandidates))
+	log.Printf("[LLM Search] Loaded %d prefiltered candidates from database", len(candidates))
 
-	if len(allCandidates) == 0 {
+	if len(candidates) == 0 {
 		return &LLMSearchResult{
 			Query:      query,
 			Candidates: []LLMRankedCandidate{},
@@ -75,8 +129,8 @@ func (s *LLMSearchEngine) Search(ctx context.Context, query string) (*LLMSearchR
 		}, nil
 	}
 
-	// Step 2: LLM analyzes and ranks candidates
-	rankedCandidates, reasoning, err := s.llmRankCandidates(ctx, query, allCandidates)
+	// Step 2: LLM analyzes and ranks candidates, one page at a time
+	rankedCandidates, reasoning, err := s.rankInPages(ctx, query, candidates)
 	if err != nil {
 		return nil, fmt.Errorf("LLM ranking failed: %w", err)
 	}
@@ -93,147 +147,308 @@ func (s *LLMSearchEngine) Search(ctx context.Context, query string) (*LLMSearchR
 	}, nil
 }
 
-// fetchAllCandidates loads all candidates from database (no filtering)
-func (s *LLMSearchEngine) fetchAllCandidates(ctx context.Context) ([]CandidateResult, error) {
-	query := `
-		SELECT DISTINCT p.node_id, p.properties
-		FROM graph_nodes p
-		WHERE p.node_type = 'person'
-		ORDER BY p.node_id
-	`
+// prefilter picks the person node IDs sent to the LLM: the BM25 and vector
+// top-K fused by reciprocal rank, then — for queries few CVs match word for
+// word — topped up to prefilterK with the organization's newest candidates,
+// so a small organization is still judged whole as before. A failing source
+// is logged and skipped. scores holds the fused score of each match (not
+// of the top-ups).
+func (s *LLMSearchEngine) prefilter(ctx context.Context, query string) ([]string, map[string]float64, error) {
+	scores := make(map[string]float64)
+	add := func(ids []string) {
+		for i, id := range ids {
+			if id != "" {
+				scores[id] += 1.0 / float64(60+i+1) // k=60 as in hybrid search's RRF
+			}
+		}
+	}
+
+	bm25Results, err := s.bm25.Search(ctx, query, s.prefilterK)
+	if err != nil {
+		log.Printf("[LLM Search] BM25 prefilter failed: %v", err)
+	}
+	bm25IDs := make([]string, 0, len(bm25Results))
+	for _, r := range bm25Results {
+		bm25IDs = append(bm25IDs, r.NodeID)
+	}
+	add(bm25IDs)
 
-	rows, err := s.db.QueryContext(ctx, query)
+	vectorCount := 0
+	if s.embeddings != nil {
+		ids, _, err := s.embeddings.SimilaritySearch(ctx, query, s.prefilterK)
+		if err != nil {
+			log.Printf("[LLM Search] Vector prefilter failed: %v", err)
+		}
+		vectorCount = len(ids)
+		add(ids)
+	}
+
+	personIDs := make([]string, 0, len(scores))
+	for id := range scores {
+		personIDs = append(personIDs, id)
+	}
+	sort.Slice(personIDs, func(i, j int) bool {
+		if scores[personIDs[i]] != scores[personIDs[j]] {
+			return scores[personIDs[i]] > scores[personIDs[j]]
+		}
+		return personIDs[i] < personIDs[j]
+	})
+	if len(personIDs) > s.prefilterK {
+		personIDs = personIDs[:s.prefilterK]
+	}
+	matched := len(personIDs)
+
+	if len(personIDs) < s.prefilterK {
+		rest, err := s.newestCandidates(ctx, personIDs, s.prefilterK-len(personIDs))
+		if err != nil {
+			return nil, nil, err
+		}
+		personIDs = append(personIDs, rest...)
+	}
+
+	log.Printf("[LLM Search] Prefilter: %d BM25 + %d vector matches -> %d candidates (%d topped up, limit %d)",
+		len(bm25IDs), vectorCount, len(personIDs), len(personIDs)-matched, s.prefilterK)
+	return personIDs, scores, nil
+}
+
+// newestCandidates returns up to limit of ctx's organization's person node
+// IDs not in exclude, newest first.
+func (s *LLMSearchEngine) newestCandidates(ctx context.Context, exclude []string, limit int) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT node_id
+		FROM graph_nodes
+		WHERE node_type = 'person'
+		  AND deleted_at IS NULL
+		  AND org_id = $1
+		  AND NOT (node_id = ANY($2))
+		  AND `+personStatusSQL(ctx, "graph_nodes")+`
+		ORDER BY created_at DESC, node_id
+		LIMIT $3
+	`, tenant.OrgID(ctx), exclude, limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var candidates []CandidateResult
+	var personIDs []string
 	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		personIDs = append(personIDs, id)
+	}
+	return personIDs, rows.Err()
+}
+
+// fetchCandidates loads the given persons of ctx's organization in the
+// order given, with their skills, companies and education. MatchScore is
+// the prefilter score (×100), which orders the candidates the LLM leaves
+// unranked.
+func (s *LLMSearchEngine) fetchCandidates(ctx context.Context, personIDs []string, scores map[string]float64) ([]CandidateResult, error) {
+	if len(personIDs) == 0 {
+		return nil, nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, node_id, properties
+		FROM graph_nodes
+		WHERE node_id = ANY($1)
+		  AND node_type = 'person'
+		  AND deleted_at IS NULL
+		  AND org_id = $2
+		  AND `+personStatusSQL(ctx, "graph_nodes")+`
+	`, personIDs, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byPersonID := make(map[string]*CandidateResult, len(personIDs))
+	byInternalID := make(map[int64]*CandidateResult, len(personIDs))
+	internalIDs := make([]int64, 0, len(personIDs))
+	for rows.Next() {
+		var internalID int64
 		var result CandidateResult
 		var propsJSON []byte
 
-		if err := rows.Scan(&result.PersonID, &propsJSON); err != nil {
+		if err := rows.Scan(&internalID, &result.PersonID, &propsJSON); err != nil {
 			log.Printf("[LLM Search] Scan error: %v", err)
 			continue
 		}
 
 		// Parse properties
-		var props map[string]interface{}
-		if err := json.Unmarshal(propsJSON, &props); err != nil {
+		props, err := DecodePersonProperties(propsJSON)
+		if err != nil {
 			log.Printf("[LLM Search] JSON unmarshal error: %v", err)
 			continue
 		}
 
 		// Extract basic info
-		if cvID, ok := props["cv_id"].(float64); ok {
-			result.CVID = int(cvID)
-		}
-		if name, ok := props["name"].(string); ok {
-			result.Name = name
-		}
-		if pos, ok := props["current_position"].(string); ok {
-			result.CurrentPosition = pos
+		result.CVID = props.CVID
+		result.Name = props.Name
+		result.CurrentPosition = props.CurrentPosition
+		result.Seniority = props.Seniority
+		if props.TotalExperienceYears != nil {
+			result.TotalExperience = *props.TotalExperienceYears
 		}
-		if sen, ok := props["seniority"].(string); ok {
-			result.Seniority = sen
-		}
-		result.TotalExperience = props["total_experience_years"]
-
-		// Fetch related nodes (skills, companies, education)
-		s.enrichCandidate(ctx, &result)
 
-		candidates = append(candidates, result)
+		c := &result
+		byPersonID[result.PersonID] = c
+		byInternalID[internalID] = c
+		internalIDs = append(internalIDs, internalID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
+	// Fetch related nodes (skills, companies, education)
+	s.enrichCandidates(ctx, internalIDs, byInternalID)
+
+	candidates := make([]CandidateResult, 0, len(byPersonID))
+	for _, id := range personIDs {
+		if c, ok := byPersonID[id]; ok {
+			c.MatchScore = scores[id] * 100
+			candidates = append(candidates, *c)
+		}
+	}
 	return candidates, nil
 }
 
-// enrichCandidate fetches skills, companies, education for a candidate
-func (s *LLMSearchEngine) enrichCandidate(ctx context.Context, candidate *CandidateResult) {
-	// First, get the internal ID from node_id
-	var internalID int
-	err := s.db.QueryRowContext(ctx,
-		`SELECT id FROM graph_nodes WHERE node_id = $1`,
-		candidate.PersonID,
-	).Scan(&internalID)
-
-	if err != nil {
-		log.Printf("[LLM Search] Failed to get internal ID for %s (node_id=%s): %v",
-			candidate.Name, candidate.PersonID, err)
+// enrichCandidates fetches skills, companies and education for a batch of
+// candidates, keyed by their internal graph_nodes.id — three queries for
+// the batch instead of three per candidate.
+func (s *LLMSearchEngine) enrichCandidates(ctx context.Context, internalIDs []int64, candidates map[int64]*CandidateResult) {
+	if len(internalIDs) == 0 {
 		return
 	}
 
 	// Fetch skills
 	skillQuery := `
-		SELECT s.properties->>'name' as skill_name,
+		SELECT e.source_node_id,
+		       s.properties->>'name' as skill_name,
 		       COALESCE(s.properties->>'proficiency', '') as proficiency
 		FROM graph_edges e
 		JOIN graph_nodes s ON e.target_node_id = s.id
-		WHERE e.source_node_id = $1
+		WHERE e.source_node_id = ANY($1)
 		  AND e.edge_type = 'HAS_SKILL'
 		  AND s.node_type = 'skill'
 	`
-	rows, err := s.db.QueryContext(ctx, skillQuery, internalID)
-	if err != nil {
-		log.Printf("[LLM Search] Skill query error for %s: %v", candidate.Name, err)
-	} else {
-		defer rows.Close()
-		for rows.Next() {
-			var skill SkillNode
-			if err := rows.Scan(&skill.Name, &skill.Proficiency); err == nil {
-				candidate.Skills = append(candidate.Skills, skill)
-			}
+	s.scanRelated(ctx, "Skill", skillQuery, internalIDs, func(rows *sql.Rows) error {
+		var id int64
+		var skill SkillNode
+		if err := rows.Scan(&id, &skill.Name, &skill.Proficiency); err != nil {
+			return err
 		}
-	}
+		candidates[id].Skills = append(candidates[id].Skills, skill)
+		return nil
+	})
 
 	// Fetch companies
 	companyQuery := `
-		SELECT c.properties->>'name' as company_name,
+		SELECT e.source_node_id,
+		       c.properties->>'name' as company_name,
 		       COALESCE(c.properties->>'position', '') as position,
 		       COALESCE((c.properties->>'is_current')::boolean, false) as is_current
 		FROM graph_edges e
 		JOIN graph_nodes c ON e.target_node_id = c.id
-		WHERE e.source_node_id = $1
+		WHERE e.source_node_id = ANY($1)
 		  AND e.edge_type = 'WORKS_AT'
 		  AND c.node_type = 'company'
 	`
-	rows, err = s.db.QueryContext(ctx, companyQuery, internalID)
-	if err != nil {
-		log.Printf("[LLM Search] Company query error for %s: %v", candidate.Name, err)
-	} else {
-		defer rows.Close()
-		for rows.Next() {
-			var company CompanyNode
-			if err := rows.Scan(&company.Name, &company.Position, &company.IsCurrent); err == nil {
-				candidate.Companies = append(candidate.Companies, company)
-			}
+	s.scanRelated(ctx, "Company", companyQuery, internalIDs, func(rows *sql.Rows) error {
+		var id int64
+		var company CompanyNode
+		if err := rows.Scan(&id, &company.Name, &company.Position, &company.IsCurrent); err != nil {
+			return err
 		}
-	}
+		candidates[id].Companies = append(candidates[id].Companies, company)
+		return nil
+	})
 
 	// Fetch education
 	eduQuery := `
-		SELECT e.properties->>'institution' as institution,
+		SELECT ed.source_node_id,
+		       e.properties->>'institution' as institution,
 		       COALESCE(e.properties->>'degree', '') as degree,
 		       COALESCE(e.properties->>'field', '') as field
 		FROM graph_edges ed
 		JOIN graph_nodes e ON ed.target_node_id = e.id
-		WHERE ed.source_node_id = $1
+		WHERE ed.source_node_id = ANY($1)
 		  AND ed.edge_type = 'GRADUATED_FROM'
 		  AND e.node_type = 'education'
 	`
-	rows, err = s.db.QueryContext(ctx, eduQuery, internalID)
+	s.scanRelated(ctx, "Education", eduQuery, internalIDs, func(rows *sql.Rows) error {
+		var id int64
+		var edu EducationNode
+		if err := rows.Scan(&id, &edu.Institution, &edu.Degree, &edu.Field); err != nil {
+			return err
+		}
+		candidates[id].Education = append(candidates[id].Education, edu)
+		return nil
+	})
+}
+
+// scanRelated runs one of enrichCandidates' queries; a failing query or row
+// is logged and leaves that part of the profiles empty.
+func (s *LLMSearchEngine) scanRelated(ctx context.Context, what, query string, internalIDs []int64, scan func(*sql.Rows) error) {
+	rows, err := s.db.QueryContext(ctx, query, internalIDs)
 	if err != nil {
-		log.Printf("[LLM Search] Education query error for %s: %v", candidate.Name, err)
-	} else {
-		defer rows.Close()
-		for rows.Next() {
-			var edu EducationNode
-			if err := rows.Scan(&edu.Institution, &edu.Degree, &edu.Field); err == nil {
-				candidate.Education = append(candidate.Education, edu)
+		log.Printf("[LLM Search] %s query error: %v", what, err)
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			log.Printf("[LLM Search] %s scan error: %v", what, err)
+		}
+	}
+}
+
+// rankInPages sends candidates to the LLM batchSize at a time and merges
+// the pages: every LLM-ranked candidate by fit (prefilter order within a
+// fit), then the unranked ones in prefilter order. A page whose call fails
+// comes back unranked; the search fails only if every page did. The
+// reasoning is the first page's, which holds the best prefilter matches.
+func (s *LLMSearchEngine) rankInPages(ctx context.Context, query string, candidates []CandidateResult) ([]LLMRankedCandidate, string, error) {
+	var ranked, unranked []LLMRankedCandidate
+	var reasoning string
+	var lastErr error
+	failed := 0
+	for start := 0; start < len(candidates); start += s.batchSize {
+		page := candidates[start:min(start+s.batchSize, len(candidates))]
+		pageRanked, pageReasoning, err := s.llmRankCandidates(ctx, query, page)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, "", err
+			}
+			log.Printf("[LLM Search] Page %d failed, candidates kept unranked: %v", start/s.batchSize+1, err)
+			lastErr = err
+			failed++
+			pageRanked = mergeLLMMatches(page, nil)
+		}
+		if reasoning == "" {
+			reasoning = pageReasoning
+		}
+		for _, c := range pageRanked {
+			if c.UnrankedByLLM {
+				unranked = append(unranked, c)
+			} else {
+				ranked = append(ranked, c)
 			}
 		}
 	}
+	if pages := (len(candidates) + s.batchSize - 1) / s.batchSize; failed == pages {
+		return nil, "", lastErr
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].FinalScore > ranked[j].FinalScore
+	})
+	sort.SliceStable(unranked, func(i, j int) bool {
+		return unranked[i].FinalScore > unranked[j].FinalScore
+	})
+	return append(ranked, unranked...), reasoning, nil
 }
 
 // llmRankCandidates uses LLM to analyze and rank candidates semantically
@@ -270,6 +485,7 @@ OUTPUT JSON (return ONLY valid JSON, no markdown):
 {
   "top_matches": [
     {
+      "person_id": "person_xxx",
       "name": "Candidate Name",
       "fit": "excellent|good|fair|poor",
       "reasoning": "Detailed explanation of why this candidate matches (2-3 sentences)",
@@ -280,11 +496,12 @@ OUTPUT JSON (return ONLY valid JSON, no markdown):
 }
 
 Return candidates sorted by relevance (best matches first). Include only candidates with "excellent" or "good" fit.
+Always copy person_id exactly as given in the candidate list (names are not unique).
 `, query, len(candidates), candidateProfiles)
 
 	log.Printf("[LLM Search] Sending %d candidates to LLM for analysis", len(candidates))
 
-	response, err := s.llm.Generate(prompt)
+	response, err := s.llm.Generate(ctx, prompt)
 	if err != nil {
 		return nil, "", fmt.Errorf("LLM generation failed: %w", err)
 	}
@@ -298,13 +515,8 @@ Return candidates sorted by relevance (best matches first). Include only candida
 	response = strings.TrimSpace(response)
 
 	var llmResult struct {
-		TopMatches []struct {
-			Name         string   `json:"name"`
-			Fit          string   `json:"fit"`
-			Reasoning    string   `json:"reasoning"`
-			KeyStrengths []string `json:"key_strengths"`
-		} `json:"top_matches"`
-		OverallReasoning string `json:"overall_reasoning"`
+		TopMatches       []llmMatch `json:"top_matches"`
+		OverallReasoning string     `json:"overall_reasoning"`
 	}
 
 	if err := json.Unmarshal([]byte(response), &llmResult); err != nil {
@@ -314,52 +526,9 @@ Return candidates sorted by relevance (best matches first). Include only candida
 	}
 
 	// NO MORE LOCAL SCORING! Pure LLM approach
-	// Map LLM results back to full candidate objects
-	var rankedCandidates []LLMRankedCandidate
-	for _, match := range llmResult.TopMatches {
-		// Find the candidate in original list
-		for _, candidate := range candidates {
-			if candidate.Name == match.Name {
-				// Use LLM fit directly as score (no more local heuristics!)
-				var llmScore float64
-				switch strings.ToLower(match.Fit) {
-				case "excellent":
-					llmScore = 95.0
-				case "good":
-					llmScore = 80.0
-				case "fair":
-					llmScore = 60.0
-				case "poor":
-					llmScore = 30.0
-				default:
-					llmScore = 50.0
-				}
-
-				rankedCandidates = append(rankedCandidates, LLMRankedCandidate{
-					CVID:            candidate.CVID,
-					PersonID:        candidate.PersonID,
-					Name:            candidate.Name,
-					CurrentPosition: candidate.CurrentPosition,
-					Seniority:       candidate.Seniority,
-					TotalExperience: candidate.TotalExperience,
-					Skills:          candidate.Skills,
-					Companies:       candidate.Companies,
-					Education:       candidate.Education,
-					Fit:             match.Fit,
-					Reasoning:       match.Reasoning,
-					KeyStrengths:    match.KeyStrengths,
-					MatchScore:      llmScore,
-					FinalScore:      llmScore, // Pure LLM score (no local heuristics)
-				})
-				break
-			}
-		}
-	}
-
-	// Sort by FinalScore descending (pure LLM ranking)
-	sort.Slice(rankedCandidates, func(i, j int) bool {
-		return rankedCandidates[i].FinalScore > rankedCandidates[j].FinalScore
-	})
+	// Map LLM results back to full candidate objects by person_id; unmentioned
+	// candidates are appended (flagged) instead of silently vanishing.
+	rankedCandidates := mergeLLMMatches(candidates, llmResult.TopMatches)
 
 	log.Printf("[LLM Search] LLM ranked %d candidates as relevant", len(rankedCandidates))
 
@@ -371,7 +540,7 @@ func (s *LLMSearchEngine) buildCandidateProfiles(candidates []CandidateResult) s
 	var builder strings.Builder
 
 	for i, candidate := range candidates {
-		builder.WriteString(fmt.Sprintf("\n%d. %s\n", i+1, candidate.Name))
+		builder.WriteString(fmt.Sprintf("\n%d. %s [person_id: %s]\n", i+1, candidate.Name, candidate.PersonID))
 
 		if candidate.CurrentPosition != "" {
 			builder.WriteString(fmt.Sprintf("   Position: %s", candidate.CurrentPosition))
@@ -429,7 +598,8 @@ func (s *LLMSearchEngine) buildCandidateProfiles(candidates []CandidateResult) s
 
 // generateSummary creates a natural language summary of search results
 func (s *LLMSearchEngine) generateSummary(query string, candidates []LLMRankedCandidate, reasoning string) string {
-	if len(candidates) == 0 {
+	relevant := rankedByLLMCount(candidates)
+	if relevant == 0 {
 		return "No candidates found matching your query."
 	}
 
@@ -443,7 +613,7 @@ func (s *LLMSearchEngine) generateSummary(query string, candidates []LLMRankedCa
 		}
 	}
 
-	summary := fmt.Sprintf("Found %d relevant candidates", len(candidates))
+	summary := fmt.Sprintf("Found %d relevant candidates", relevant)
 	if excellentCount > 0 {
 		summary += fmt.Sprintf(" (%d excellent match", excellentCount)
 		if excellentCount > 1 {
@@ -0,0 +1,351 @@
+package graphrag
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"unicode"
+
+	"cv-search/internal/textnorm"
+)
+
+// ─── Location normalization ──────────────────────────────────────────────────
+//
+// CVs and queries spell the same place many ways: "Istanbul", "İstanbul,
+// Türkiye", "Istanbul/Remote", "Kadıköy". The locations table holds canonical
+// cities and countries with coordinates and location_aliases every known
+// spelling of them, tr_fold()ed (migration 00029). Person nodes store the
+// location their CV resolves to, so searches match on it, or on the distance
+// to it, instead of on the CV's wording.
+
+// earthRadiusKm is the mean Earth radius used for distances.
+const earthRadiusKm = 6371.0
+
+// Location is a canonical city or country.
+type Location struct {
+	ID          int     `json:"id"`
+	Kind        string  `json:"kind"` // "city" or "country"
+	Name        string  `json:"name"`
+	Country     string  `json:"country"`
+	CountryCode string  `json:"country_code"`
+	Lat         float64 `json:"lat"`
+	Lon         float64 `json:"lon"`
+}
+
+// IsCity reports whether l is a city rather than a country.
+func (l *Location) IsCity() bool {
+	return l.Kind == "city"
+}
+
+// Display returns the name shown for l: "İstanbul, Türkiye" for a city, the
+// name alone for a country.
+func (l *Location) Display() string {
+	if l.IsCity() {
+		return l.Name + ", " + l.Country
+	}
+	return l.Name
+}
+
+// Matches reports whether person p lives in l: in the same country for a
+// country; for a city, within radiusKm of it, or in the city itself when
+// radiusKm is 0. distanceKm is set whenever both have coordinates.
+func (l *Location) Matches(p PersonProperties, radiusKm float64) (distanceKm *float64, ok bool) {
+	if l.IsCity() && p.Lat != nil && p.Lon != nil {
+		d := DistanceKm(l.Lat, l.Lon, *p.Lat, *p.Lon)
+		distanceKm = &d
+	}
+	switch {
+	case !l.IsCity():
+		return distanceKm, p.CountryCode == l.CountryCode
+	case radiusKm > 0:
+		return distanceKm, distanceKm != nil && *distanceKm <= radiusKm
+	default:
+		return distanceKm, p.LocationID == l.ID
+	}
+}
+
+// DistanceKm is the great-circle (haversine) distance between two points.
+func DistanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := rad(lat2 - lat1)
+	dLon := rad(lon2 - lon1)
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(rad(lat1))*math.Cos(rad(lat2))*math.Pow(math.Sin(dLon/2), 2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// personDistanceSQL is DistanceKm in SQL, from person node p to the point
+// given by query arguments latArg and lonArg. NULL when p has no coordinates.
+func personDistanceSQL(latArg, lonArg int) string {
+	return fmt.Sprintf(`(%[3]g * 2 * asin(least(1, sqrt(
+		power(sin(radians((p.properties->>'lat')::float - $%[1]d) / 2), 2)
+		+ cos(radians($%[1]d)) * cos(radians((p.properties->>'lat')::float))
+		  * power(sin(radians((p.properties->>'lon')::float - $%[2]d) / 2), 2)))))`, latArg, lonArg, earthRadiusKm)
+}
+
+// locationSeparators split a free-text location into its parts:
+// "Kadıköy, İstanbul (Hybrid)", "Istanbul/Remote", "Berlin - Germany".
+var locationSeparators = strings.NewReplacer(
+	",", "\n", "/", "\n", "|", "\n", ";", "\n", "(", "\n", ")", "\n",
+	"[", "\n", "]", "\n", "-", "\n", "–", "\n", "—", "\n", "•", "\n",
+)
+
+// maxLocationWords is the longest run of words looked up as one alias
+// ("itu ari teknokent", "united states of america").
+const maxLocationWords = 4
+
+// locationKeys returns the alias lookup keys of a free-text location in
+// reading order, each part as a whole before its runs of fewer words, so
+// "San Francisco Bay Area" tries "san francisco bay area", then "san
+// francisco bay", …, "san francisco", ….
+func locationKeys(raw string) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	add := func(k string) {
+		if k != "" && !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	for _, part := range strings.Split(locationSeparators.Replace(raw), "\n") {
+		words := strings.FieldsFunc(textnorm.Fold(part), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		add(strings.Join(words, " "))
+		for n := min(len(words)-1, maxLocationWords); n >= 1; n-- {
+			for i := 0; i+n <= len(words); i++ {
+				add(strings.Join(words[i:i+n], " "))
+			}
+		}
+	}
+	return keys
+}
+
+// ResolveLocation returns the canonical location free text names: the first
+// city it mentions, else the first country. "Remote" and other words that
+// aren't places are ignored. nil if nothing in raw is a known location.
+func ResolveLocation(ctx context.Context, db *sql.DB, raw string) (*Location, error) {
+	keys := locationKeys(raw)
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	rows, err := db.QueryContext(ctx, `
+		SELECT a.alias, l.id, l.kind, l.name, l.country, l.country_code, l.lat, l.lon
+		FROM location_aliases a
+		JOIN locations l ON l.id = a.location_id
+		WHERE a.alias = ANY($1)
+	`, keys)
+	if err != nil {
+		return nil, fmt.Errorf("resolve location: %w", err)
+	}
+	defer rows.Close()
+
+	byAlias := make(map[string]*Location)
+	for rows.Next() {
+		var alias string
+		var l Location
+		if err := rows.Scan(&alias, &l.ID, &l.Kind, &l.Name, &l.Country, &l.CountryCode, &l.Lat, &l.Lon); err != nil {
+			return nil, fmt.Errorf("resolve location: %w", err)
+		}
+		byAlias[alias] = &l
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("resolve location: %w", err)
+	}
+
+	var country *Location
+	for _, k := range keys {
+		l := byAlias[k]
+		switch {
+		case l == nil:
+		case l.IsCity():
+			return l, nil
+		case country == nil:
+			country = l
+		}
+	}
+	return country, nil
+}
+
+// setLocation records where p lives: loc if it resolved, else the CV's own
+// text raw.
+func (p *PersonProperties) setLocation(loc *Location, raw string) {
+	p.Location, p.LocationID, p.CountryCode, p.Lat, p.Lon = strings.TrimSpace(raw), 0, "", nil, nil
+	if loc == nil {
+		return
+	}
+	p.Location = loc.Display()
+	p.LocationID = loc.ID
+	p.CountryCode = loc.CountryCode
+	if loc.IsCity() {
+		lat, lon := loc.Lat, loc.Lon
+		p.Lat, p.Lon = &lat, &lon
+	}
+}
+
+// locationMap returns p's location properties in their stored JSON shape.
+func (p PersonProperties) locationMap() map[string]interface{} {
+	m := make(map[string]interface{})
+	if p.Location != "" {
+		m["location"] = p.Location
+	}
+	if p.LocationID > 0 {
+		m["location_id"] = p.LocationID
+	}
+	if p.CountryCode != "" {
+		m["country_code"] = p.CountryCode
+	}
+	if p.Lat != nil && p.Lon != nil {
+		m["lat"] = *p.Lat
+		m["lon"] = *p.Lon
+	}
+	return m
+}
+
+// resolvePersonLocation sets p's location from a CV's extracted locations:
+// the first that resolves, else the first as written (the first is where the
+// person lives now). A failed lookup keeps the text unresolved.
+func (g *GraphBuilder) resolvePersonLocation(ctx context.Context, p *PersonProperties, locations []string) {
+	raw := ""
+	for _, text := range locations {
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		if raw == "" {
+			raw = text
+		}
+		loc, err := ResolveLocation(ctx, g.db, text)
+		if err != nil {
+			log.Printf("[GraphRAG] %v", err)
+			break
+		}
+		if loc != nil {
+			p.setLocation(loc, text)
+			return
+		}
+	}
+	p.setLocation(nil, raw)
+}
+
+// LocationBackfillReport summarizes a BackfillPersonLocations run.
+type LocationBackfillReport struct {
+	Scanned    int
+	Resolved   int
+	Unresolved []string // location texts no alias matched, for new aliases
+}
+
+// BackfillPersonLocations resolves the location of every organization's
+// person nodes that don't have one resolved yet, from the node's location
+// text or else its candidate's (candidates.location). Unresolved texts are
+// stored as they are and retried by later runs, so aliases added since
+// pick them up. With dryRun nothing is written.
+func (g *GraphBuilder) BackfillPersonLocations(ctx context.Context, dryRun bool) (*LocationBackfillReport, error) {
+	const batchSize = 500
+	rep := &LocationBackfillReport{}
+	cache := make(map[string]*Location)
+	unresolved := make(map[string]bool)
+
+	lastID := 0
+	for {
+		rows, err := g.db.QueryContext(ctx, `
+			SELECT n.id, COALESCE(NULLIF(n.properties->>'location', ''), c.location, '')
+			FROM graph_nodes n
+			LEFT JOIN LATERAL (
+				SELECT c.location FROM candidates c
+				WHERE c.graph_node_id = n.id AND c.deleted_at IS NULL AND COALESCE(c.location, '') <> ''
+				ORDER BY c.id
+				LIMIT 1
+			) c ON true
+			WHERE n.id > $1 AND n.node_type = 'person' AND n.deleted_at IS NULL
+			  AND NOT n.properties ? 'location_id'
+			ORDER BY n.id
+			LIMIT $2
+		`, lastID, batchSize)
+		if err != nil {
+			return rep, fmt.Errorf("list person nodes: %w", err)
+		}
+
+		type fix struct {
+			id    int
+			props []byte
+		}
+		var fixes []fix
+		n := 0
+		for rows.Next() {
+			var id int
+			var raw string
+			if err := rows.Scan(&id, &raw); err != nil {
+				rows.Close()
+				return rep, fmt.Errorf("scan person node: %w", err)
+			}
+			n++
+			lastID = id
+			rep.Scanned++
+			if strings.TrimSpace(raw) == "" {
+				continue
+			}
+
+			loc, ok := cache[raw]
+			if !ok {
+				if loc, err = ResolveLocation(ctx, g.db, raw); err != nil {
+					rows.Close()
+					return rep, err
+				}
+				cache[raw] = loc
+			}
+			if loc == nil {
+				if !unresolved[raw] {
+					unresolved[raw] = true
+					rep.Unresolved = append(rep.Unresolved, raw)
+				}
+			} else {
+				rep.Resolved++
+			}
+
+			var p PersonProperties
+			p.setLocation(loc, raw)
+			b, err := json.Marshal(p.locationMap())
+			if err != nil {
+				rows.Close()
+				return rep, fmt.Errorf("marshal location of node %d: %w", id, err)
+			}
+			fixes = append(fixes, fix{id: id, props: b})
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return rep, fmt.Errorf("list person nodes: %w", err)
+		}
+
+		if !dryRun {
+			for _, f := range fixes {
+				if _, err := g.db.ExecContext(ctx,
+					`UPDATE graph_nodes SET properties = properties || $2::jsonb WHERE id = $1`, f.id, f.props,
+				); err != nil {
+					return rep, fmt.Errorf("update graph node %d: %w", f.id, err)
+				}
+			}
+		}
+		if n < batchSize {
+			return rep, nil
+		}
+	}
+}
+
+// filterByLocation is the search's location filter (HybridSearchConfig's
+// Location): it keeps the candidates living in loc or, for a city, within
+// radiusKm of it, with their distance to it.
+func filterByLocation(candidates []FusedCandidate, loc *Location, radiusKm float64) []FusedCandidate {
+	kept := make([]FusedCandidate, 0, len(candidates))
+	for _, c := range candidates {
+		if d, ok := loc.Matches(c.home, radiusKm); ok {
+			c.DistanceKm = d
+			kept = append(kept, c)
+		}
+	}
+	log.Printf("[HybridSearch] Location filter (%s, radius=%gkm): %d → %d candidates",
+		loc.Display(), radiusKm, len(candidates), len(kept))
+	return kept
+}
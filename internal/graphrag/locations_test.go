@@ -0,0 +1,59 @@
+package graphrag
+
+import (
+	"slices"
+	"testing"
+)
+
+func livingIn(id string, locationID int, countryCode string, lat, lon float64) FusedCandidate {
+	c := FusedCandidate{PersonID: id}
+	c.home.LocationID = locationID
+	c.home.CountryCode = countryCode
+	if lat != 0 || lon != 0 {
+		c.home.Lat, c.home.Lon = &lat, &lon
+	}
+	return c
+}
+
+func TestFilterByLocation(t *testing.T) {
+	ankara := &Location{ID: 1, Kind: "city", Name: "Ankara", Country: "Türkiye", CountryCode: "TR", Lat: 39.93, Lon: 32.86}
+	turkey := &Location{ID: 2, Kind: "country", Name: "Türkiye", CountryCode: "TR"}
+	candidates := []FusedCandidate{
+		livingIn("ankara", 1, "TR", 39.93, 32.86),
+		livingIn("eskisehir", 3, "TR", 39.78, 30.52), // ~200 km from Ankara
+		livingIn("istanbul", 4, "TR", 41.01, 28.98),  // ~350 km
+		livingIn("berlin", 5, "DE", 52.52, 13.40),
+		livingIn("ankara-no-coords", 1, "TR", 0, 0),
+		livingIn("unresolved", 0, "", 0, 0),
+	}
+	tests := []struct {
+		name     string
+		loc      *Location
+		radiusKm float64
+		want     []string
+	}{
+		{"city", ankara, 0, []string{"ankara", "ankara-no-coords"}},
+		{"city radius", ankara, 250, []string{"ankara", "eskisehir"}},
+		{"wider radius", ankara, 400, []string{"ankara", "eskisehir", "istanbul"}},
+		{"country", turkey, 0, []string{"ankara", "eskisehir", "istanbul", "ankara-no-coords"}},
+		{"country ignores radius", turkey, 10, []string{"ankara", "eskisehir", "istanbul", "ankara-no-coords"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := personIDs(filterByLocation(candidates, tt.loc, tt.radiusKm))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("filterByLocation = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("distance", func(t *testing.T) {
+		got := filterByLocation(candidates, ankara, 250)
+		if got[0].DistanceKm == nil || *got[0].DistanceKm > 1 {
+			t.Errorf("distance of a candidate in the city = %v, want ~0", got[0].DistanceKm)
+		}
+		if d := got[1].DistanceKm; d == nil || *d < 150 || *d > 250 {
+			t.Errorf("distance Ankara–Eskişehir = %v, want ~200", d)
+		}
+	})
+}
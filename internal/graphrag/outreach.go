@@ -0,0 +1,164 @@
+package graphrag
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ─── Outreach drafts ─────────────────────────────────────────────────────────
+//
+// An outreach draft is a first message to a candidate about a role, written
+// by the LLM from their graph profile and, when the recruiter passes it on
+// from a search result, the match reasoning. It is a draft: the recruiter
+// reviews and sends it.
+
+// Outreach channels, tones and languages.
+var (
+	OutreachChannels  = []string{"email", "linkedin"}
+	OutreachTones     = []string{"professional", "friendly", "casual"}
+	OutreachLanguages = []string{"en", "tr"}
+)
+
+// maxLinkedInMessageChars is the length a LinkedIn message is asked to stay
+// under; longer drafts get a warning.
+const maxLinkedInMessageChars = 1000
+
+// OutreachRequest is what an outreach draft is written for.
+type OutreachRequest struct {
+	Role           string // the role's title
+	JobDescription string // optional
+	Company        string // the hiring company, optional
+	SenderName     string // optional; the draft signs with a placeholder otherwise
+	MatchReasoning string // why the candidate fits, e.g. a search result's
+	Channel        string // one of OutreachChannels
+	Tone           string // one of OutreachTones
+	Language       string // one of OutreachLanguages
+}
+
+// OutreachDraft is a drafted outreach message.
+type OutreachDraft struct {
+	Channel  string   `json:"channel"`
+	Tone     string   `json:"tone"`
+	Language string   `json:"language"`
+	Subject  string   `json:"subject,omitempty"` // email only
+	Body     string   `json:"body"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// DraftOutreach drafts an outreach message to the person node personNodeID
+// (graph_nodes.id) about req's role. sql.ErrNoRows if the node doesn't
+// exist.
+func (s *LLMSearchEngine) DraftOutreach(ctx context.Context, personNodeID int, req OutreachRequest) (*OutreachDraft, error) {
+	person, err := s.personProperties(ctx, personNodeID)
+	if err != nil {
+		return nil, err
+	}
+	skills, err := s.candidateSkills(ctx, personNodeID)
+	if err != nil {
+		return nil, err
+	}
+	work, err := s.candidateWork(ctx, personNodeID)
+	if err != nil {
+		return nil, err
+	}
+	projects, err := s.candidateProjects(ctx, personNodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.llm.Generate(ctx, outreachPrompt(person, skills, work, projects, req))
+	if err != nil {
+		return nil, fmt.Errorf("LLM outreach draft failed: %w", err)
+	}
+	var out struct {
+		Subject string `json:"subject"`
+		Body    string `json:"body"`
+	}
+	if err := json.Unmarshal([]byte(extractJSON(response)), &out); err != nil {
+		return nil, fmt.Errorf("failed to parse outreach draft: %w", err)
+	}
+	draft := &OutreachDraft{
+		Channel:  req.Channel,
+		Tone:     req.Tone,
+		Language: req.Language,
+		Body:     strings.TrimSpace(out.Body),
+	}
+	if draft.Body == "" {
+		return nil, fmt.Errorf("outreach draft has no body")
+	}
+	if req.Channel == "email" {
+		draft.Subject = strings.TrimSpace(out.Subject)
+	} else if n := utf8.RuneCountInString(draft.Body); n > maxLinkedInMessageChars {
+		draft.Warnings = append(draft.Warnings, fmt.Sprintf("message is %d characters; LinkedIn messages read best under %d", n, maxLinkedInMessageChars))
+	}
+	return draft, nil
+}
+
+// outreachPrompt asks for an outreach message about req's role.
+func outreachPrompt(person PersonProperties, skills []candidateSkill, work []candidateCompany, projects []ProjectProperties, req OutreachRequest) string {
+	var role strings.Builder
+	fmt.Fprintf(&role, "Title: %s", req.Role)
+	if req.Company != "" {
+		fmt.Fprintf(&role, "\nCompany: %s", req.Company)
+	}
+	if req.JobDescription != "" {
+		fmt.Fprintf(&role, "\nDescription:\n\"\"\"\n%s\n\"\"\"", req.JobDescription)
+	}
+	reasoning := req.MatchReasoning
+	if reasoning == "" {
+		reasoning = "(not given; judge the fit from the profile)"
+	}
+	name := person.Name
+	if name == "" || person.Anonymized {
+		name = "(unknown; use a greeting without a name)"
+	}
+	sender := req.SenderName
+	if sender == "" {
+		sender = "(unknown; sign with the placeholder [Your Name])"
+	}
+
+	format := `an email. "subject": a short, specific subject line (no clickbait)`
+	if req.Channel == "linkedin" {
+		format = fmt.Sprintf(`a LinkedIn message, under %d characters. "subject": ""`, maxLinkedInMessageChars)
+	}
+	tone := map[string]string{
+		"professional": "professional and respectful, not stiff",
+		"friendly":     "warm and friendly, still professional",
+		"casual":       "casual and conversational, short sentences",
+	}[req.Tone]
+	language := "English"
+	if req.Language == "tr" {
+		language = `Turkish (use "siz", natural recruiter Turkish, not a translation)`
+	}
+
+	return fmt.Sprintf(`You are a recruiter writing a first outreach message to a candidate about a role.
+
+ROLE:
+%s
+
+CANDIDATE: %s
+%s
+
+WHY THEY FIT:
+%s
+
+SENDER: %s
+
+Write %s.
+Tone: %s.
+Language: %s.
+
+Return ONLY valid JSON:
+{"subject": "...", "body": "..."}
+
+Rules:
+- Personalize with one or two concrete things from the candidate's background (a company, project or skill) and say why they matter for this role; don't list their whole CV
+- Mention only what the profile says; never invent achievements, numbers or details about the role
+- Don't mention how the candidate was found (search, scores, AI) or any personal data beyond their name
+- End with a low-pressure call to action (a short call this week)
+- body is plain text with line breaks, no markdown`,
+		role.String(), name, describeCandidate(person, skills, work, projects), reasoning, sender, format, tone, language)
+}
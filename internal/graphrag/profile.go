@@ -0,0 +1,189 @@
+package graphrag
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ─── Person profile ──────────────────────────────────────────────────────────
+
+// PersonProfile is a person's CV as their graph has it: the person node and
+// its skills, jobs, education, certifications, languages and projects.
+type PersonProfile struct {
+	Name            string               `json:"name,omitempty"`
+	CurrentPosition string               `json:"current_position,omitempty"`
+	Seniority       string               `json:"seniority,omitempty"`
+	ExperienceYears *float64             `json:"experience_years,omitempty"`
+	Location        string               `json:"location,omitempty"`
+	Skills          []ProfileSkill       `json:"skills"`
+	Experience      []ProfileJob         `json:"experience"` // current first, then by start year, newest first
+	Education       []ProfileEducation   `json:"education"`
+	Certifications  []ProfileCertificate `json:"certifications,omitempty"`
+	Languages       []LanguageNode       `json:"languages,omitempty"`
+	Projects        []ProfileProject     `json:"projects,omitempty"`
+}
+
+// ProfileSkill is a skill of a profile.
+type ProfileSkill struct {
+	Name        string   `json:"name"`
+	Proficiency string   `json:"proficiency,omitempty"`
+	Years       *float64 `json:"years,omitempty"`
+}
+
+// ProfileJob is a company a person works or worked at.
+type ProfileJob struct {
+	Company   string `json:"company,omitempty"`
+	Position  string `json:"position,omitempty"`
+	StartYear int    `json:"start_year,omitempty"`
+	EndYear   int    `json:"end_year,omitempty"`
+	IsCurrent bool   `json:"is_current,omitempty"`
+}
+
+// ProfileEducation is a school a person graduated from.
+type ProfileEducation struct {
+	Institution    string `json:"institution,omitempty"`
+	Degree         string `json:"degree,omitempty"`
+	Field          string `json:"field,omitempty"`
+	GraduationYear int    `json:"graduation_year,omitempty"`
+}
+
+// ProfileCertificate is a certification a person holds.
+type ProfileCertificate struct {
+	Name   string `json:"name"`
+	Issuer string `json:"issuer,omitempty"`
+	Year   int    `json:"year,omitempty"`
+}
+
+// ProfileProject is a project a person worked on.
+type ProfileProject struct {
+	Name         string   `json:"name"`
+	Description  string   `json:"description,omitempty"`
+	Role         string   `json:"role,omitempty"`
+	Impact       string   `json:"impact,omitempty"`
+	Technologies []string `json:"technologies,omitempty"`
+}
+
+// PersonProfile returns the profile of the person node personNodeID
+// (graph_nodes.id); sql.ErrNoRows if the node doesn't exist.
+func (g *GraphBuilder) PersonProfile(ctx context.Context, personNodeID int) (*PersonProfile, error) {
+	var propsJSON []byte
+	err := g.db.QueryRowContext(ctx, `
+		SELECT properties FROM graph_nodes WHERE id = $1 AND node_type = 'person' AND deleted_at IS NULL
+	`, personNodeID).Scan(&propsJSON)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get person node %d: %w", personNodeID, err)
+	}
+	person, err := DecodePersonProperties(propsJSON)
+	if err != nil {
+		return nil, fmt.Errorf("decode person node %d: %w", personNodeID, err)
+	}
+	p := &PersonProfile{
+		Name:            person.Name,
+		CurrentPosition: person.CurrentPosition,
+		Seniority:       person.Seniority,
+		ExperienceYears: person.TotalExperienceYears,
+		Location:        person.Location,
+		Skills:          []ProfileSkill{},
+		Experience:      []ProfileJob{},
+		Education:       []ProfileEducation{},
+	}
+
+	rows, err := g.db.QueryContext(ctx, `
+		SELECT e.edge_type, t.properties, COALESCE(e.properties, '{}'::jsonb)
+		FROM graph_edges e
+		JOIN graph_nodes t ON t.id = e.target_node_id AND t.deleted_at IS NULL
+		WHERE e.source_node_id = $1
+		  AND e.edge_type IN ('HAS_SKILL', 'WORKS_AT', 'WORKED_AT', 'GRADUATED_FROM', 'HAS_CERTIFICATION', 'SPEAKS', 'WORKED_ON')
+		ORDER BY t.id
+	`, personNodeID)
+	if err != nil {
+		return nil, fmt.Errorf("get profile of node %d: %w", personNodeID, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var edgeType string
+		var nodeJSON, edgeJSON []byte
+		if err := rows.Scan(&edgeType, &nodeJSON, &edgeJSON); err != nil {
+			return nil, fmt.Errorf("scan profile of node %d: %w", personNodeID, err)
+		}
+		// Malformed rows are skipped, as in search enrichment.
+		switch edgeType {
+		case "HAS_SKILL":
+			node, err := DecodeSkillProperties(nodeJSON)
+			if err != nil || strings.TrimSpace(node.Name) == "" {
+				continue
+			}
+			edge, _ := DecodeHasSkillProperties(edgeJSON)
+			p.Skills = append(p.Skills, ProfileSkill{Name: node.Name, Proficiency: edge.Proficiency, Years: edge.YearsOfExperience})
+		case "WORKS_AT", "WORKED_AT":
+			node, err := DecodeCompanyProperties(nodeJSON)
+			if err != nil {
+				continue
+			}
+			edge, _ := DecodeWorkProperties(edgeJSON)
+			p.Experience = append(p.Experience, ProfileJob{
+				Company:   node.Name,
+				Position:  edge.Position,
+				StartYear: edge.StartYear,
+				EndYear:   edge.EndYear,
+				IsCurrent: edge.IsCurrent || edgeType == "WORKS_AT",
+			})
+		case "GRADUATED_FROM":
+			node, err := DecodeEducationProperties(nodeJSON)
+			if err != nil {
+				continue
+			}
+			p.Education = append(p.Education, ProfileEducation{
+				Institution:    node.Institution,
+				Degree:         node.Degree,
+				Field:          node.Field,
+				GraduationYear: node.GraduationYear,
+			})
+		case "HAS_CERTIFICATION":
+			node, err := DecodeCertificationProperties(nodeJSON)
+			if err != nil || strings.TrimSpace(node.Name) == "" {
+				continue
+			}
+			edge, _ := DecodeHasCertificationProperties(edgeJSON)
+			p.Certifications = append(p.Certifications, ProfileCertificate{Name: node.Name, Issuer: node.Issuer, Year: edge.Year})
+		case "SPEAKS":
+			node, err := DecodeLanguageProperties(nodeJSON)
+			if err != nil || strings.TrimSpace(node.Name) == "" {
+				continue
+			}
+			edge, _ := DecodeSpeaksProperties(edgeJSON)
+			p.Languages = append(p.Languages, LanguageNode{Name: node.Name, Proficiency: edge.Proficiency})
+		case "WORKED_ON":
+			node, err := DecodeProjectProperties(nodeJSON)
+			if err != nil || strings.TrimSpace(node.Name) == "" {
+				continue
+			}
+			p.Projects = append(p.Projects, ProfileProject{
+				Name:         node.Name,
+				Description:  node.Description,
+				Role:         node.Role,
+				Impact:       node.Impact,
+				Technologies: node.Technologies,
+			})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("get profile of node %d: %w", personNodeID, err)
+	}
+
+	sort.SliceStable(p.Experience, func(i, j int) bool {
+		a, b := p.Experience[i], p.Experience[j]
+		if a.IsCurrent != b.IsCurrent {
+			return a.IsCurrent
+		}
+		return a.StartYear > b.StartYear
+	})
+	return p, nil
+}
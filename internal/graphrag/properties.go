@@ -0,0 +1,482 @@
+package graphrag
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+)
+
+// ─── Typed node & edge properties ────────────────────────────────────────────
+//
+// graph_nodes.properties and graph_edges.properties are free-form JSONB and
+// the LLM extraction fills them with whatever it returned: names can be null,
+// years can be "5+" or "present", cv_id can be a string. Read paths decode
+// through the types below instead of asserting on map values, so a malformed
+// row degrades to zero values rather than a panic.
+
+// PersonProperties are the properties of a person node.
+type PersonProperties struct {
+	CVID                 int
+	Name                 string
+	CurrentPosition      string
+	Seniority            string
+	TotalExperienceYears *float64 // ExperienceYearsComputed when set, else ExperienceYearsLLM
+	// Experience years as the LLM reported them and as computed from the
+	// CV's employment ranges (see experience.go); nil when unknown
+	ExperienceYearsLLM      *float64
+	ExperienceYearsComputed *float64
+	Community               string
+	Communities             []string
+	Anonymized              bool
+	CandidateID             int    // candidates row the CV was linked to, 0 until linked
+	Status                  string // the candidate's status (status.go), "" = active
+
+	// Work preferences, empty when the CV didn't state them
+	WorkModes        []string // WorkModes values
+	EmploymentTypes  []string // EmploymentTypes values
+	NoticePeriodDays *float64 // 0 = available immediately
+	AvailableFrom    string   // YYYY-MM-DD the CV says they can start on
+
+	// SalarySealed is the CV's salary (see salary.go), sealed; "" when it
+	// stated none or salaries aren't kept
+	SalarySealed string
+
+	// Where the person lives: the canonical name of a resolved location
+	// (LocationID > 0), else the CV's own text. Lat/Lon are set for cities
+	// only; a country's centroid isn't a place to measure distance from.
+	Location    string
+	LocationID  int
+	CountryCode string
+	Lat, Lon    *float64
+}
+
+// SkillProperties are the properties of a skill node.
+type SkillProperties struct {
+	Name        string
+	Proficiency string
+}
+
+// CompanyProperties are the properties of a company node.
+type CompanyProperties struct {
+	Name     string
+	Industry string
+}
+
+// EducationProperties are the properties of an education node.
+type EducationProperties struct {
+	Institution    string
+	Degree         string
+	Field          string
+	GraduationYear int
+}
+
+// CertificationProperties are the properties of a certification node.
+type CertificationProperties struct {
+	Name   string
+	Issuer string
+}
+
+// LanguageProperties are the properties of a (spoken) language node.
+type LanguageProperties struct {
+	Name string
+}
+
+// ProjectProperties are the properties of a project node. Projects belong to
+// one CV, so unlike skills or companies they aren't shared between people.
+type ProjectProperties struct {
+	CVID         int
+	Name         string
+	Description  string
+	Role         string
+	Impact       string
+	Technologies []string
+	Company      string
+}
+
+// HasSkillProperties are the properties of a HAS_SKILL edge.
+type HasSkillProperties struct {
+	Proficiency       string
+	YearsOfExperience *float64
+	LastUsedYear      int // "present" resolves to the current year
+}
+
+// WorkProperties are the properties of a WORKS_AT / WORKED_AT edge.
+type WorkProperties struct {
+	Position  string
+	StartYear int
+	EndYear   int // "present" resolves to the current year
+	IsCurrent bool
+}
+
+// HasCertificationProperties are the properties of a HAS_CERTIFICATION edge.
+type HasCertificationProperties struct {
+	Year int
+}
+
+// SpeaksProperties are the properties of a SPEAKS edge.
+type SpeaksProperties struct {
+	Proficiency string // one of LanguageLevels, "" when the CV didn't say
+}
+
+// PersonPropertiesFrom reads a decoded person property map.
+func PersonPropertiesFrom(props map[string]interface{}) PersonProperties {
+	p := PersonProperties{
+		Name:            propString(props["name"]),
+		CurrentPosition: propString(props["current_position"]),
+		Seniority:       propString(props["seniority"]),
+		Community:       propString(props["community"]),
+		Communities:     propStrings(props["communities"]),
+		Anonymized:      propBool(props["anonymized"]),
+	}
+	if id, ok := propInt(props["cv_id"]); ok {
+		p.CVID = id
+	}
+	if id, ok := propInt(props["candidate_id"]); ok {
+		p.CandidateID = id
+	}
+	p.Status = propString(props["status"])
+	if years, ok := propFloat(props["total_experience_years"]); ok {
+		p.TotalExperienceYears = &years
+	}
+	if years, ok := propFloat(props["experience_years_llm"]); ok {
+		p.ExperienceYearsLLM = &years
+	}
+	if years, ok := propFloat(props["experience_years_computed"]); ok {
+		p.ExperienceYearsComputed = &years
+	}
+	p.WorkModes = NormalizeWorkModes(propStrings(props["work_modes"]))
+	p.EmploymentTypes = NormalizeEmploymentTypes(propStrings(props["employment_types"]))
+	if days, ok := propFloat(props["notice_period_days"]); ok {
+		p.NoticePeriodDays = &days
+	}
+	p.AvailableFrom = NormalizeAvailableFrom(propString(props["available_from"]))
+	p.SalarySealed = propString(props["salary_sealed"])
+	p.Location = propString(props["location"])
+	p.CountryCode = propString(props["country_code"])
+	if id, ok := propInt(props["location_id"]); ok {
+		p.LocationID = id
+	}
+	lat, latOK := propFloat(props["lat"])
+	lon, lonOK := propFloat(props["lon"])
+	if latOK && lonOK {
+		p.Lat, p.Lon = &lat, &lon
+	}
+	return p
+}
+
+// SkillPropertiesFrom reads a decoded skill property map.
+func SkillPropertiesFrom(props map[string]interface{}) SkillProperties {
+	return SkillProperties{
+		Name:        propString(props["name"]),
+		Proficiency: propString(props["proficiency"]),
+	}
+}
+
+// CompanyPropertiesFrom reads a decoded company property map.
+func CompanyPropertiesFrom(props map[string]interface{}) CompanyProperties {
+	return CompanyProperties{
+		Name:     propString(props["name"]),
+		Industry: propString(props["industry"]),
+	}
+}
+
+// EducationPropertiesFrom reads a decoded education property map.
+func EducationPropertiesFrom(props map[string]interface{}) EducationProperties {
+	e := EducationProperties{
+		Institution: propString(props["institution"]),
+		Degree:      propString(props["degree"]),
+		Field:       propString(props["field"]),
+	}
+	if y, ok := propInt(props["graduation_year"]); ok {
+		e.GraduationYear = y
+	}
+	return e
+}
+
+// CertificationPropertiesFrom reads a decoded certification property map.
+func CertificationPropertiesFrom(props map[string]interface{}) CertificationProperties {
+	return CertificationProperties{
+		Name:   propString(props["name"]),
+		Issuer: propString(props["issuer"]),
+	}
+}
+
+// LanguagePropertiesFrom reads a decoded language property map.
+func LanguagePropertiesFrom(props map[string]interface{}) LanguageProperties {
+	return LanguageProperties{Name: propString(props["name"])}
+}
+
+// ProjectPropertiesFrom reads a decoded project property map.
+func ProjectPropertiesFrom(props map[string]interface{}) ProjectProperties {
+	p := ProjectProperties{
+		Name:         propString(props["name"]),
+		Description:  propString(props["description"]),
+		Role:         propString(props["role"]),
+		Impact:       propString(props["impact"]),
+		Technologies: propStrings(props["technologies"]),
+		Company:      propString(props["company"]),
+	}
+	if id, ok := propInt(props["cv_id"]); ok {
+		p.CVID = id
+	}
+	return p
+}
+
+// HasSkillPropertiesFrom reads a decoded HAS_SKILL edge property map.
+func HasSkillPropertiesFrom(props map[string]interface{}) HasSkillProperties {
+	h := HasSkillProperties{
+		Proficiency:  propString(props["proficiency"]),
+		LastUsedYear: propYear(props["last_used_year"]),
+	}
+	if years, ok := propFloat(props["years_of_experience"]); ok {
+		h.YearsOfExperience = &years
+	}
+	return h
+}
+
+// WorkPropertiesFrom reads a decoded WORKS_AT / WORKED_AT edge property map.
+func WorkPropertiesFrom(props map[string]interface{}) WorkProperties {
+	return WorkProperties{
+		Position:  propString(props["position"]),
+		StartYear: propYear(props["start_year"]),
+		EndYear:   propYear(props["end_year"]),
+		IsCurrent: propBool(props["is_current"]),
+	}
+}
+
+// Map returns the properties in their stored JSON shape. Unset optional
+// values are omitted rather than written as null.
+func (p PersonProperties) Map() map[string]interface{} {
+	m := map[string]interface{}{
+		"cv_id":            p.CVID,
+		"name":             p.Name,
+		"current_position": p.CurrentPosition,
+		"seniority":        p.Seniority,
+	}
+	if p.TotalExperienceYears != nil {
+		m["total_experience_years"] = *p.TotalExperienceYears
+	}
+	if p.ExperienceYearsLLM != nil {
+		m["experience_years_llm"] = *p.ExperienceYearsLLM
+	}
+	if p.ExperienceYearsComputed != nil {
+		m["experience_years_computed"] = *p.ExperienceYearsComputed
+	}
+	if p.Community != "" {
+		m["community"] = p.Community
+	}
+	if len(p.Communities) > 0 {
+		m["communities"] = p.Communities
+	}
+	if p.Anonymized {
+		m["anonymized"] = true
+	}
+	if p.CandidateID > 0 {
+		m["candidate_id"] = p.CandidateID
+	}
+	if p.Status != "" {
+		m["status"] = p.Status
+	}
+	if len(p.WorkModes) > 0 {
+		m["work_modes"] = p.WorkModes
+	}
+	if len(p.EmploymentTypes) > 0 {
+		m["employment_types"] = p.EmploymentTypes
+	}
+	if p.NoticePeriodDays != nil {
+		m["notice_period_days"] = *p.NoticePeriodDays
+	}
+	if p.AvailableFrom != "" {
+		m["available_from"] = p.AvailableFrom
+	}
+	if p.SalarySealed != "" {
+		m["salary_sealed"] = p.SalarySealed
+	}
+	maps.Copy(m, p.locationMap())
+	return m
+}
+
+// HasCertificationPropertiesFrom reads a decoded HAS_CERTIFICATION edge
+// property map.
+func HasCertificationPropertiesFrom(props map[string]interface{}) HasCertificationProperties {
+	return HasCertificationProperties{Year: propYear(props["year"])}
+}
+
+// SpeaksPropertiesFrom reads a decoded SPEAKS edge property map.
+func SpeaksPropertiesFrom(props map[string]interface{}) SpeaksProperties {
+	return SpeaksProperties{Proficiency: NormalizeLanguageLevel(propString(props["proficiency"]))}
+}
+
+// Map returns the properties in their stored JSON shape.
+func (s SkillProperties) Map() map[string]interface{} {
+	return map[string]interface{}{
+		"name":        s.Name,
+		"proficiency": s.Proficiency,
+	}
+}
+
+// Map returns the properties in their stored JSON shape.
+func (c CompanyProperties) Map() map[string]interface{} {
+	m := map[string]interface{}{"name": c.Name}
+	if c.Industry != "" {
+		m["industry"] = c.Industry
+	}
+	return m
+}
+
+// Map returns the properties in their stored JSON shape.
+func (e EducationProperties) Map() map[string]interface{} {
+	m := map[string]interface{}{
+		"institution": e.Institution,
+		"degree":      e.Degree,
+		"field":       e.Field,
+	}
+	if e.GraduationYear != 0 {
+		m["graduation_year"] = e.GraduationYear
+	}
+	return m
+}
+
+// Map returns the properties in their stored JSON shape.
+func (c CertificationProperties) Map() map[string]interface{} {
+	m := map[string]interface{}{"name": c.Name}
+	if c.Issuer != "" {
+		m["issuer"] = c.Issuer
+	}
+	return m
+}
+
+// Map returns the properties in their stored JSON shape.
+func (l LanguageProperties) Map() map[string]interface{} {
+	return map[string]interface{}{"name": l.Name}
+}
+
+// Map returns the properties in their stored JSON shape.
+func (p ProjectProperties) Map() map[string]interface{} {
+	m := map[string]interface{}{
+		"cv_id":       p.CVID,
+		"name":        p.Name,
+		"description": p.Description,
+	}
+	if p.Role != "" {
+		m["role"] = p.Role
+	}
+	if p.Impact != "" {
+		m["impact"] = p.Impact
+	}
+	if len(p.Technologies) > 0 {
+		m["technologies"] = p.Technologies
+	}
+	if p.Company != "" {
+		m["company"] = p.Company
+	}
+	return m
+}
+
+// Text is the project as one line of prose, used for its embedding and in
+// search results.
+func (p ProjectProperties) Text() string {
+	var b strings.Builder
+	b.WriteString(p.Name)
+	if p.Description != "" {
+		b.WriteString(": " + p.Description)
+	}
+	if p.Role != "" {
+		b.WriteString(". Role: " + p.Role)
+	}
+	if p.Company != "" {
+		b.WriteString(". At " + p.Company)
+	}
+	if p.Impact != "" {
+		b.WriteString(". Impact: " + p.Impact)
+	}
+	if len(p.Technologies) > 0 {
+		b.WriteString(". Technologies: " + strings.Join(p.Technologies, ", "))
+	}
+	return b.String()
+}
+
+// DecodePersonProperties decodes raw person node properties. Only invalid
+// JSON is an error; missing or mistyped fields are left zero.
+func DecodePersonProperties(raw []byte) (PersonProperties, error) {
+	props, err := decodePropertyMap(raw)
+	return PersonPropertiesFrom(props), err
+}
+
+// DecodeSkillProperties decodes raw skill node properties.
+func DecodeSkillProperties(raw []byte) (SkillProperties, error) {
+	props, err := decodePropertyMap(raw)
+	return SkillPropertiesFrom(props), err
+}
+
+// DecodeCompanyProperties decodes raw company node properties.
+func DecodeCompanyProperties(raw []byte) (CompanyProperties, error) {
+	props, err := decodePropertyMap(raw)
+	return CompanyPropertiesFrom(props), err
+}
+
+// DecodeEducationProperties decodes raw education node properties.
+func DecodeEducationProperties(raw []byte) (EducationProperties, error) {
+	props, err := decodePropertyMap(raw)
+	return EducationPropertiesFrom(props), err
+}
+
+// DecodeCertificationProperties decodes raw certification node properties.
+func DecodeCertificationProperties(raw []byte) (CertificationProperties, error) {
+	props, err := decodePropertyMap(raw)
+	return CertificationPropertiesFrom(props), err
+}
+
+// DecodeLanguageProperties decodes raw language node properties.
+func DecodeLanguageProperties(raw []byte) (LanguageProperties, error) {
+	props, err := decodePropertyMap(raw)
+	return LanguagePropertiesFrom(props), err
+}
+
+// DecodeProjectProperties decodes raw project node properties.
+func DecodeProjectProperties(raw []byte) (ProjectProperties, error) {
+	props, err := decodePropertyMap(raw)
+	return ProjectPropertiesFrom(props), err
+}
+
+// DecodeHasSkillProperties decodes raw HAS_SKILL edge properties.
+func DecodeHasSkillProperties(raw []byte) (HasSkillProperties, error) {
+	props, err := decodePropertyMap(raw)
+	return HasSkillPropertiesFrom(props), err
+}
+
+// DecodeWorkProperties decodes raw WORKS_AT / WORKED_AT edge properties.
+func DecodeWorkProperties(raw []byte) (WorkProperties, error) {
+	props, err := decodePropertyMap(raw)
+	return WorkPropertiesFrom(props), err
+}
+
+// DecodeHasCertificationProperties decodes raw HAS_CERTIFICATION edge
+// properties.
+func DecodeHasCertificationProperties(raw []byte) (HasCertificationProperties, error) {
+	props, err := decodePropertyMap(raw)
+	return HasCertificationPropertiesFrom(props), err
+}
+
+// DecodeSpeaksProperties decodes raw SPEAKS edge properties.
+func DecodeSpeaksProperties(raw []byte) (SpeaksProperties, error) {
+	props, err := decodePropertyMap(raw)
+	return SpeaksPropertiesFrom(props), err
+}
+
+// decodePropertyMap unmarshals a JSONB properties value. SQL NULL and JSON
+// null both decode to an empty map.
+func decodePropertyMap(raw []byte) (map[string]interface{}, error) {
+	if len(raw) == 0 {
+		return map[string]interface{}{}, nil
+	}
+	var props map[string]interface{}
+	if err := json.Unmarshal(raw, &props); err != nil {
+		return map[string]interface{}{}, fmt.Errorf("decode properties: %w", err)
+	}
+	if props == nil {
+		props = map[string]interface{}{}
+	}
+	return props, nil
+}
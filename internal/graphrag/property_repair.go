@@ -0,0 +1,220 @@
+package graphrag
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+)
+
+type propKind int
+
+const (
+	kindString propKind = iota
+	kindNumber
+	kindBool
+	kindStrings
+)
+
+// nodePropertySchemas lists the typed keys of each node type. Keys not listed
+// (e.g. added by later features) are left untouched by repair.
+var nodePropertySchemas = map[string]map[string]propKind{
+	"person": {
+		"cv_id":                     kindNumber,
+		"name":                      kindString,
+		"current_position":          kindString,
+		"seniority":                 kindString,
+		"total_experience_years":    kindNumber,
+		"experience_years_llm":      kindNumber,
+		"experience_years_computed": kindNumber,
+		"community":                 kindString,
+		"communities":               kindStrings,
+		"anonymized":                kindBool,
+		"status":                    kindString,
+		"work_modes":                kindStrings,
+		"employment_types":          kindStrings,
+		"notice_period_days":        kindNumber,
+		"available_from":            kindString,
+		"salary_sealed":             kindString,
+		"location":                  kindString,
+		"location_id":               kindNumber,
+		"country_code":              kindString,
+		"lat":                       kindNumber,
+		"lon":                       kindNumber,
+	},
+	"skill": {
+		"name":        kindString,
+		"proficiency": kindString,
+	},
+	"company": {
+		"name":     kindString,
+		"industry": kindString,
+	},
+	"education": {
+		"institution":     kindString,
+		"degree":          kindString,
+		"field":           kindString,
+		"graduation_year": kindNumber,
+	},
+	"certification": {
+		"name":   kindString,
+		"issuer": kindString,
+	},
+	"language": {
+		"name": kindString,
+	},
+	"project": {
+		"cv_id":        kindNumber,
+		"name":         kindString,
+		"description":  kindString,
+		"role":         kindString,
+		"impact":       kindString,
+		"technologies": kindStrings,
+		"company":      kindString,
+	},
+}
+
+// normalizeNodeProperties coerces the typed keys of props to their schema
+// type in place: nulls and values that can't be converted are dropped,
+// numeric strings become numbers, numbers in string fields become strings.
+// Reports whether anything changed.
+func normalizeNodeProperties(nodeType string, props map[string]interface{}) bool {
+	schema, ok := nodePropertySchemas[nodeType]
+	if !ok {
+		return false
+	}
+	changed := false
+	for key, kind := range schema {
+		v, present := props[key]
+		if !present {
+			continue
+		}
+		var nv interface{}
+		switch kind {
+		case kindString:
+			if v != nil {
+				nv = propString(v)
+			}
+		case kindNumber:
+			if f, ok := propFloat(v); ok {
+				nv = f
+			}
+		case kindBool:
+			if v != nil {
+				nv = propBool(v)
+			}
+		case kindStrings:
+			if list := propStrings(v); list != nil {
+				items := make([]interface{}, len(list))
+				for i, s := range list {
+					items[i] = s
+				}
+				nv = items
+			}
+		}
+		if nv == nil {
+			delete(props, key)
+			changed = true
+		} else if !reflect.DeepEqual(v, nv) {
+			props[key] = nv
+			changed = true
+		}
+	}
+	return changed
+}
+
+// PropertyRepairReport summarises a RepairNodeProperties pass.
+type PropertyRepairReport struct {
+	Scanned  int            `json:"scanned"`
+	Repaired int            `json:"repaired"`
+	ByType   map[string]int `json:"repaired_by_type"`
+	Invalid  []string       `json:"invalid"` // node_ids whose properties aren't a JSON object
+}
+
+// RepairNodeProperties walks the nodes in nodePropertySchemas and
+// rewrites properties whose typed keys are null or have the wrong JSON type
+// (see normalizeNodeProperties). Rows that aren't a JSON object at all are
+// reported in Invalid and left as they are. With dryRun nothing is written.
+func (g *GraphBuilder) RepairNodeProperties(ctx context.Context, dryRun bool) (*PropertyRepairReport, error) {
+	const batchSize = 500
+	rep := &PropertyRepairReport{ByType: make(map[string]int)}
+
+	types := make([]string, 0, len(nodePropertySchemas))
+	for t := range nodePropertySchemas {
+		types = append(types, t)
+	}
+
+	lastID := 0
+	for {
+		rows, err := g.db.QueryContext(ctx, `
+			SELECT id, node_type, node_id, properties
+			FROM graph_nodes
+			WHERE id > $1 AND node_type = ANY($2)
+			ORDER BY id
+			LIMIT $3
+		`, lastID, types, batchSize)
+		if err != nil {
+			return rep, fmt.Errorf("list graph nodes: %w", err)
+		}
+
+		type fix struct {
+			id    int
+			props []byte
+		}
+		var fixes []fix
+		n := 0
+		for rows.Next() {
+			var id int
+			var nodeType, nodeID string
+			var raw []byte
+			if err := rows.Scan(&id, &nodeType, &nodeID, &raw); err != nil {
+				rows.Close()
+				return rep, fmt.Errorf("scan graph node: %w", err)
+			}
+			n++
+			lastID = id
+			rep.Scanned++
+
+			props, err := decodePropertyMap(raw)
+			if err != nil {
+				rep.Invalid = append(rep.Invalid, nodeID)
+				continue
+			}
+			if !normalizeNodeProperties(nodeType, props) {
+				continue
+			}
+			b, err := json.Marshal(props)
+			if err != nil {
+				rows.Close()
+				return rep, fmt.Errorf("marshal properties of %s: %w", nodeID, err)
+			}
+			rep.Repaired++
+			rep.ByType[nodeType]++
+			fixes = append(fixes, fix{id: id, props: b})
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return rep, fmt.Errorf("list graph nodes: %w", err)
+		}
+
+		if !dryRun {
+			for _, f := range fixes {
+				if _, err := g.db.ExecContext(ctx,
+					`UPDATE graph_nodes SET properties = $2 WHERE id = $1`, f.id, f.props,
+				); err != nil {
+					return rep, fmt.Errorf("update graph node %d: %w", f.id, err)
+				}
+			}
+		}
+
+		if n < batchSize {
+			break
+		}
+	}
+
+	if len(rep.Invalid) > 0 {
+		log.Printf("[GraphRAG] %d node(s) have non-object properties: %v", len(rep.Invalid), rep.Invalid)
+	}
+	return rep, nil
+}
@@ -0,0 +1,196 @@
+package graphrag
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// LanguageLevels are the SPEAKS proficiency values, lowest first.
+var LanguageLevels = []string{"Basic", "Intermediate", "Advanced", "Fluent", "Native"}
+
+// languageLevelAliases maps other ways of writing a level (CEFR, LinkedIn,
+// Turkish) to one of LanguageLevels.
+var languageLevelAliases = map[string]string{
+	"a1": "Basic", "a2": "Basic", "beginner": "Basic", "elementary": "Basic", "baslangic": "Basic", "temel": "Basic",
+	"b1": "Intermediate", "limited working": "Intermediate", "orta": "Intermediate",
+	"b2": "Advanced", "upper intermediate": "Advanced", "professional working": "Advanced", "iyi": "Advanced", "ileri": "Advanced",
+	"c1": "Fluent", "c2": "Fluent", "full professional": "Fluent", "proficient": "Fluent", "akici": "Fluent", "cok iyi": "Fluent",
+	"native or bilingual": "Native", "bilingual": "Native", "mother tongue": "Native", "ana dil": "Native", "anadil": "Native",
+}
+
+// NormalizeLanguageLevel returns level as one of LanguageLevels, or "" if it
+// isn't recognized.
+func NormalizeLanguageLevel(level string) string {
+	key := strings.ToLower(strings.TrimSpace(level))
+	key = strings.NewReplacer("ı", "i", "ş", "s", "ç", "c", "ğ", "g", "ö", "o", "ü", "u").Replace(key)
+	key = strings.TrimSuffix(strings.TrimSuffix(key, " proficiency"), " level")
+	for _, l := range LanguageLevels {
+		if strings.EqualFold(key, l) {
+			return l
+		}
+	}
+	return languageLevelAliases[key]
+}
+
+// languageLevelsFrom returns the levels at or above min (all of them when
+// min isn't a recognized level).
+func languageLevelsFrom(min string) []string {
+	min = NormalizeLanguageLevel(min)
+	for i, l := range LanguageLevels {
+		if l == min {
+			return LanguageLevels[i:]
+		}
+	}
+	return LanguageLevels
+}
+
+// ─── Work preferences ────────────────────────────────────────────────────────
+
+// Work modes and employment types a person node's work_modes and
+// employment_types hold.
+var (
+	WorkModes       = []string{"remote", "hybrid", "onsite"}
+	EmploymentTypes = []string{"contract", "permanent"}
+)
+
+// workPreferenceAliases maps other ways of writing a work mode or an
+// employment type (English and Turkish) to one of WorkModes or
+// EmploymentTypes.
+var workPreferenceAliases = map[string]string{
+	"remote only": "remote", "fully remote": "remote", "work from home": "remote", "wfh": "remote", "uzaktan": "remote", "evden": "remote",
+	"hibrit": "hybrid", "hybrid remote": "hybrid",
+	"on-site": "onsite", "on site": "onsite", "office": "onsite", "in office": "onsite", "ofis": "onsite", "ofisten": "onsite", "yerinde": "onsite",
+	"contractor": "contract", "freelance": "contract", "freelancer": "contract", "b2b": "contract", "sozlesmeli": "contract", "proje bazli": "contract", "serbest": "contract",
+	"permanent": "permanent", "full-time": "permanent", "full time": "permanent", "employee": "permanent", "kadrolu": "permanent", "tam zamanli": "permanent",
+}
+
+// NormalizeWorkModes returns modes as WorkModes values, dropping the ones it
+// doesn't recognize and duplicates.
+func NormalizeWorkModes(modes []string) []string {
+	return normalizeWorkPreferences(modes, WorkModes)
+}
+
+// NormalizeEmploymentTypes returns types as EmploymentTypes values, dropping
+// the ones it doesn't recognize and duplicates.
+func NormalizeEmploymentTypes(types []string) []string {
+	return normalizeWorkPreferences(types, EmploymentTypes)
+}
+
+func normalizeWorkPreferences(values, allowed []string) []string {
+	var out []string
+	for _, v := range values {
+		key := strings.ToLower(strings.TrimSpace(v))
+		key = strings.NewReplacer("ı", "i", "ş", "s", "ç", "c", "ğ", "g", "ö", "o", "ü", "u").Replace(key)
+		if alias, ok := workPreferenceAliases[key]; ok {
+			key = alias
+		}
+		for _, a := range allowed {
+			if key == a && !slices.Contains(out, a) {
+				out = append(out, a)
+			}
+		}
+	}
+	return out
+}
+
+// ─── Property value coercion ─────────────────────────────────────────────────
+
+// propString reads a string property. Numbers and booleans are formatted;
+// null and anything else read as "".
+func propString(v interface{}) string {
+	switch s := v.(type) {
+	case string:
+		return s
+	case float64:
+		return strconv.FormatFloat(s, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(s)
+	case bool:
+		return strconv.FormatBool(s)
+	}
+	return ""
+}
+
+// propFloat reads a numeric property. Strings are parsed from their leading
+// number, so "5+", "3.5 years" and "~4" all work.
+func propFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case string:
+		return leadingNumber(n)
+	}
+	return 0, false
+}
+
+// propInt reads an integer property, truncating fractional values.
+func propInt(v interface{}) (int, bool) {
+	f, ok := propFloat(v)
+	return int(f), ok
+}
+
+// propBool reads a boolean property; "true"/"yes" strings count as true.
+func propBool(v interface{}) bool {
+	switch b := v.(type) {
+	case bool:
+		return b
+	case string:
+		s := strings.ToLower(strings.TrimSpace(b))
+		return s == "true" || s == "yes" || s == "evet"
+	}
+	return false
+}
+
+// propStrings reads a string-list property, skipping non-string elements.
+// A single string reads as a one-element list.
+func propStrings(v interface{}) []string {
+	switch list := v.(type) {
+	case []interface{}:
+		out := make([]string, 0, len(list))
+		for _, item := range list {
+			if s, ok := item.(string); ok && s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	case []string:
+		return list
+	case string:
+		if list != "" {
+			return []string{list}
+		}
+	}
+	return nil
+}
+
+// leadingNumber parses the first number in s, ignoring any prefix or suffix
+// text around it.
+func leadingNumber(s string) (float64, bool) {
+	start := strings.IndexFunc(s, func(r rune) bool { return r >= '0' && r <= '9' })
+	if start < 0 {
+		return 0, false
+	}
+	end := start
+	seenDot := false
+	for end < len(s) {
+		c := s[end]
+		if c >= '0' && c <= '9' {
+			end++
+			continue
+		}
+		if (c == '.' || c == ',') && !seenDot && end+1 < len(s) && s[end+1] >= '0' && s[end+1] <= '9' {
+			seenDot = true
+			end++
+			continue
+		}
+		break
+	}
+	f, err := strconv.ParseFloat(strings.Replace(s[start:end], ",", ".", 1), 64)
+	if err != nil {
+		return 0, false
+	}
+	return f, true
+}
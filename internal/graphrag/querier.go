@@ -3,12 +3,13 @@ package graphrag
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"fmt"
 	"log"
 	"sort"
+	"strconv"
 	"strings"
-	"time"
+
+	"cv-search/internal/tenant"
 )
 
 // CandidateResult represents a candidate found in graph search
@@ -22,6 +23,15 @@ type CandidateResult struct {
 	Skills          []SkillNode     `json:"skills"`
 	Companies       []CompanyNode   `json:"companies"`
 	Education       []EducationNode `json:"education"`
+	Certifications  []string        `json:"certifications,omitempty"`
+	Languages       []LanguageNode  `json:"languages,omitempty"`
+	Projects        []string        `json:"projects,omitempty"`
+	WorkModes       []string        `json:"work_modes,omitempty"`
+	EmploymentTypes []string        `json:"employment_types,omitempty"`
+	NoticeDays      *float64        `json:"notice_period_days,omitempty"`
+	AvailableFrom   string          `json:"available_from,omitempty"`
+	Location        string          `json:"location,omitempty"`
+	DistanceKm      *float64        `json:"distance_km,omitempty"` // to the nearest searched city
 	MatchScore      float64         `json:"match_score"`
 	MatchReasons    []string        `json:"match_reasons"`
 }
@@ -44,6 +54,18 @@ type EducationNode struct {
 	Field       string `json:"field"`
 }
 
+type LanguageNode struct {
+	Name        string `json:"name"`
+	Proficiency string `json:"proficiency,omitempty"`
+}
+
+// LanguageRequirement is a spoken language a search asks for, with the
+// lowest acceptable level ("" = any, including unstated).
+type LanguageRequirement struct {
+	Language       string `json:"language"`
+	MinProficiency string `json:"min_proficiency"`
+}
+
 // SearchCriteria holds structured search parameters extracted from a natural language query.
 type SearchCriteria struct {
 	Skills        []string `json:"skills"`         // Required + preferred skills combined
@@ -53,13 +75,32 @@ type SearchCriteria struct {
 	Education     []string `json:"education"`      // Institution or degree
 	MinExperience *int     `json:"min_experience"` // Minimum years
 	MaxExperience *int     `json:"max_experience"` // Maximum years
-	Location      []string `json:"location"`       // Cities/countries
+	Location      []string `json:"location"`       // Cities/countries; any matches
+	RadiusKm      float64  `json:"radius_km"`      // With a city: anyone within this distance of it (0 = the city itself)
+
+	Certifications []string              `json:"certifications"` // Certification names or issuers ("AWS", "PMP"); all required
+	Languages      []LanguageRequirement `json:"languages"`      // Spoken languages; all required
+	Projects       []string              `json:"projects"`       // What the candidate built/achieved ("payment system"); any matches
+
+	WorkModes       []string `json:"work_modes"`            // remote|hybrid|onsite; any matches
+	EmploymentTypes []string `json:"employment_types"`      // contract|permanent; any matches
+	AvailableWithin *int     `json:"available_within_days"` // Latest start, in days from today (0 = immediately)
 
 	// Legacy fields kept for backward compatibility
 	RequiredSkills  []string               `json:"required_skills,omitempty"`
 	PreferredSkills []string               `json:"preferred_skills,omitempty"`
 	Weights         ScoringWeights         `json:"weights,omitempty"`
 	CustomFilters   map[string]interface{} `json:"custom_filters,omitempty"`
+
+	// Set by EmbedCriteria: the criteria's embedding, and each skill's
+	// close skill nodes (keys).
+	embedding  []float32
+	skillNodes map[string][]string
+
+	// Set by QueryGraph: the name keys each skill and education matches
+	// (aliases.go).
+	skillKeys     map[string][]string
+	educationKeys map[string][]string
 }
 
 // ScoringWeights configures relative importance of match dimensions (unused in LLM-scored path).
@@ -79,18 +120,28 @@ func NewGraphQuerier(db *sql.DB) *GraphQuerier {
 	return &GraphQuerier{db: db}
 }
 
-// QueryGraph searches the graph based on criteria
+// QueryGraph searches ctx's organization's graph based on criteria
 func (q *GraphQuerier) QueryGraph(ctx context.Context, criteria *SearchCriteria) ([]CandidateResult, error) {
 	log.Printf("[GraphRAG] Querying graph with criteria: %+v", criteria)
 
+	locations := q.resolveLocations(ctx, criteria.Location)
+
+	// Aliases failing to resolve leave each term its own name key
+	var err error
+	if criteria.skillKeys, err = q.resolveAliases(ctx, "skill", criteria.Skills); err != nil {
+		log.Printf("[GraphRAG] %v", err)
+	}
+	if criteria.educationKeys, err = q.resolveAliases(ctx, "education", criteria.Education); err != nil {
+		log.Printf("[GraphRAG] %v", err)
+	}
+
 	// Build SQL query dynamically based on criteria
-	query, args := q.buildQuery(criteria)
+	query, args := q.buildQuery(tenant.OrgID(ctx), criteria, locations, personStatusSQL(ctx, "p"))
 
 	log.Printf("[GraphRAG] Executing SQL: %s", query)
 	log.Printf("[GraphRAG] With args: %v", args)
 
-	// Use db.Query (non-context) to avoid prepared statement reuse
-	rows, err := q.db.Query(query, args...)
+	rows, err := q.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("graph query failed: %w", err)
 	}
@@ -108,26 +159,33 @@ func (q *GraphQuerier) QueryGraph(ctx context.Context, criteria *SearchCriteria)
 		}
 
 		// Parse properties
-		var props map[string]interface{}
-		if err := json.Unmarshal(propsJSON, &props); err != nil {
+		props, err := DecodePersonProperties(propsJSON)
+		if err != nil {
 			log.Printf("[GraphRAG] JSON unmarshal error: %v", err)
 			continue
 		}
 
 		// Extract basic info
-		if cvID, ok := props["cv_id"].(float64); ok {
-			result.CVID = int(cvID)
-		}
-		if name, ok := props["name"].(string); ok {
-			result.Name = name
-		}
-		if pos, ok := props["current_position"].(string); ok {
-			result.CurrentPosition = pos
+		result.CVID = props.CVID
+		result.Name = props.Name
+		result.CurrentPosition = props.CurrentPosition
+		result.Seniority = props.Seniority
+		if props.TotalExperienceYears != nil {
+			result.TotalExperience = *props.TotalExperienceYears
 		}
-		if sen, ok := props["seniority"].(string); ok {
-			result.Seniority = sen
+		result.WorkModes = props.WorkModes
+		result.EmploymentTypes = props.EmploymentTypes
+		result.NoticeDays = props.NoticePeriodDays
+		result.AvailableFrom = props.AvailableFrom
+		result.Location = props.Location
+		for _, l := range locations {
+			if l.loc == nil {
+				continue
+			}
+			if d, _ := l.loc.Matches(props, 0); d != nil && (result.DistanceKm == nil || *d < *result.DistanceKm) {
+				result.DistanceKm = d
+			}
 		}
-		result.TotalExperience = props["total_experience_years"]
 
 		// Fetch related nodes (skills, companies, education)
 		q.enrichCandidate(ctx, &result)
@@ -144,19 +202,67 @@ func (q *GraphQuerier) QueryGraph(ctx context.Context, criteria *SearchCriteria)
 	return results, nil
 }
 
-func (q *GraphQuerier) buildQuery(criteria *SearchCriteria) (string, []interface{}) {
-	// Add unique comment to prevent prepared statement cache collision
-	queryID := fmt.Sprintf("/* graphquery_%d */", time.Now().UnixNano())
+// searchLocation is one of a search's locations, loc nil when it isn't a
+// known location.
+type searchLocation struct {
+	text string
+	loc  *Location
+}
 
-	baseQuery := queryID + `
+// resolveLocations resolves a search's locations. One that fails to resolve
+// is matched as text.
+func (q *GraphQuerier) resolveLocations(ctx context.Context, texts []string) []searchLocation {
+	var locations []searchLocation
+	for _, text := range texts {
+		if text = strings.TrimSpace(text); text == "" {
+			continue
+		}
+		loc, err := ResolveLocation(ctx, q.db, text)
+		if err != nil {
+			log.Printf("[GraphRAG] %v", err)
+		}
+		locations = append(locations, searchLocation{text: text, loc: loc})
+	}
+	return locations
+}
+
+// fuzzyCompanyThreshold is the minimum pg_trgm (word_)similarity for a company
+// node to match a requested company name.
+const fuzzyCompanyThreshold = 0.5
+
+// fuzzyEducationThreshold is the minimum pg_trgm word_similarity for an
+// institution to match a requested one, stricter than companies':
+// university names share most of their words ("Istanbul University",
+// "Istanbul Technical University").
+const fuzzyEducationThreshold = 0.7
+
+// likeEscaper escapes LIKE's metacharacters (backslash is the default
+// ESCAPE).
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// likeContains is an ILIKE pattern matching s, taken literally, anywhere.
+func likeContains(s string) string {
+	return "%" + likeEscaper.Replace(s) + "%"
+}
+
+// graphQueryLimit is the most people a graph search returns.
+const graphQueryLimit = 50
+
+// buildQuery returns the graph search of criteria among the organization's
+// people meeting statusCond.
+func (q *GraphQuerier) buildQuery(orgID int, criteria *SearchCriteria, locations []searchLocation, statusCond string) (string, []interface{}) {
+	baseQuery := `
 		SELECT DISTINCT p.node_id, p.properties
 		FROM graph_nodes p
 		WHERE p.node_type = 'person'
+		  AND p.deleted_at IS NULL
+		  AND p.org_id = $1
+		  AND ` + statusCond + `
 	`
 
 	var conditions []string
-	var args []interface{}
-	argIndex := 1
+	args := []interface{}{orgID}
+	argIndex := 2
 
 	// Filter by seniority
 	if criteria.Seniority != "" {
@@ -165,40 +271,44 @@ func (q *GraphQuerier) buildQuery(criteria *SearchCriteria) (string, []interface
 		argIndex++
 	}
 
-	// Filter by skills
+	// Filter by skills: a node close to the skill (EmbedCriteria) or one
+	// with a name key of it, so "Golang" matches "Go" and "NodeJS" "Node.js"
 	if len(criteria.Skills) > 0 {
 		for _, skill := range criteria.Skills {
-			skillID := fmt.Sprintf("skill_%s", skill)
 			conditions = append(conditions, fmt.Sprintf(`
 				EXISTS (
 					SELECT 1 FROM graph_edges e
 					JOIN graph_nodes s ON e.target_node_id = s.id
 					WHERE e.source_node_id = p.id
 					  AND e.edge_type = 'HAS_SKILL'
-					  AND s.node_id = $%d
+					  AND s.node_type = 'skill'
+					  AND (s.node_id = ANY($%d) OR %s = ANY($%d))
 				)
-			`, argIndex))
-			args = append(args, skillID)
-			argIndex++
+			`, argIndex, aliasKeySQL("s.properties->>'name'"), argIndex+1))
+			args = append(args, criteria.skillNodeIDs(skill), criteria.nameKeys(criteria.skillKeys, skill))
+			argIndex += 2
 		}
 	}
 
-	// Filter by companies (partial match with LIKE for better matching)
+	// Filter by companies (partial match with LIKE, trigram fallback for variants)
 	if len(criteria.Companies) > 0 {
 		companyConditions := []string{}
 		for _, company := range criteria.Companies {
-			// Try both exact match and partial match
+			// Try exact/partial match, then fuzzy: word_similarity covers
+			// "Garanti" vs "Garanti BBVA", similarity covers typos/transliterations.
 			companyConditions = append(companyConditions, fmt.Sprintf(`
 				EXISTS (
 					SELECT 1 FROM graph_edges e
 					JOIN graph_nodes c ON e.target_node_id = c.id
 					WHERE e.source_node_id = p.id
 					  AND e.edge_type IN ('WORKS_AT', 'WORKED_AT')
-					  AND (c.node_id LIKE $%d OR c.properties->>'name' ILIKE $%d)
+					  AND (c.node_id LIKE $%d OR c.properties->>'name' ILIKE $%d
+					       OR word_similarity(immutable_unaccent($%d), immutable_unaccent(c.properties->>'name')) >= $%d
+					       OR similarity(immutable_unaccent($%d), immutable_unaccent(c.properties->>'name')) >= $%d)
 				)
-			`, argIndex, argIndex+1)) // Fixed: use argIndex+1 for second placeholder
-			args = append(args, fmt.Sprintf("%%company_%s%%", company), fmt.Sprintf("%%%s%%", company))
-			argIndex += 2
+			`, argIndex, argIndex+1, argIndex+2, argIndex+3, argIndex+2, argIndex+3))
+			args = append(args, fmt.Sprintf("%%company_%s%%", company), fmt.Sprintf("%%%s%%", company), company, fuzzyCompanyThreshold)
+			argIndex += 4
 		}
 		conditions = append(conditions, "("+strings.Join(companyConditions, " OR ")+")")
 	}
@@ -207,30 +317,145 @@ func (q *GraphQuerier) buildQuery(criteria *SearchCriteria) (string, []interface
 	// A "Java Developer" query should also return Java Architects, Tech Leads, Consultants.
 	// Skill post-filter (hybrid_search.go) and LLM reranking handle relevance filtering.
 
-	// Filter by education
+	// Filter by education: the institution by name key (so "ODTÜ" matches
+	// "Middle East Technical University"), else partially or fuzzily
 	if len(criteria.Education) > 0 {
 		eduConditions := []string{}
 		for _, edu := range criteria.Education {
-			eduID := fmt.Sprintf("education_%s", edu)
 			eduConditions = append(eduConditions, fmt.Sprintf(`
 				EXISTS (
 					SELECT 1 FROM graph_edges e
 					JOIN graph_nodes ed ON e.target_node_id = ed.id
 					WHERE e.source_node_id = p.id
 					  AND e.edge_type = 'GRADUATED_FROM'
-					  AND ed.node_id = $%d
+					  AND ed.node_type = 'education'
+					  AND (ed.node_id = $%d OR %s = ANY($%d)
+					       OR ed.properties->>'institution' ILIKE $%d
+					       OR word_similarity(immutable_unaccent($%d), immutable_unaccent(ed.properties->>'institution')) >= $%d)
 				)
-			`, argIndex))
-			args = append(args, eduID)
-			argIndex++
+			`, argIndex, aliasKeySQL("ed.properties->>'institution'"), argIndex+1, argIndex+2, argIndex+3, argIndex+4))
+			args = append(args, fmt.Sprintf("education_%s", edu), criteria.nameKeys(criteria.educationKeys, edu),
+				likeContains(edu), edu, fuzzyEducationThreshold)
+			argIndex += 5
 		}
 		conditions = append(conditions, "("+strings.Join(eduConditions, " OR ")+")")
 	}
 
+	// Filter by certifications: name or issuer, substring or fuzzy, so "AWS"
+	// matches "AWS Certified Solutions Architect – Associate"
+	for _, cert := range criteria.Certifications {
+		conditions = append(conditions, fmt.Sprintf(`
+			EXISTS (
+				SELECT 1 FROM graph_edges e
+				JOIN graph_nodes ce ON e.target_node_id = ce.id
+				WHERE e.source_node_id = p.id
+				  AND e.edge_type = 'HAS_CERTIFICATION'
+				  AND (ce.properties->>'name' ILIKE $%d OR ce.properties->>'issuer' ILIKE $%d
+				       OR word_similarity(immutable_unaccent($%d), immutable_unaccent(ce.properties->>'name')) >= $%d)
+			)
+		`, argIndex, argIndex, argIndex+1, argIndex+2))
+		args = append(args, fmt.Sprintf("%%%s%%", cert), cert, fuzzyCompanyThreshold)
+		argIndex += 3
+	}
+
+	// Filter by spoken languages, at or above the requested level
+	for _, lang := range criteria.Languages {
+		if strings.TrimSpace(lang.Language) == "" {
+			continue
+		}
+		levelCond := ""
+		if NormalizeLanguageLevel(lang.MinProficiency) != "" {
+			levelCond = fmt.Sprintf("AND e.properties->>'proficiency' = ANY($%d)", argIndex+1)
+		}
+		conditions = append(conditions, fmt.Sprintf(`
+			EXISTS (
+				SELECT 1 FROM graph_edges e
+				JOIN graph_nodes l ON e.target_node_id = l.id
+				WHERE e.source_node_id = p.id
+				  AND e.edge_type = 'SPEAKS'
+				  AND lower(l.properties->>'name') = lower($%d)
+				  %s
+			)
+		`, argIndex, levelCond))
+		args = append(args, lang.Language)
+		argIndex++
+		if levelCond != "" {
+			args = append(args, languageLevelsFrom(lang.MinProficiency))
+			argIndex++
+		}
+	}
+
+	// Filter by project descriptions (name, description or impact)
+	if len(criteria.Projects) > 0 {
+		projectConditions := []string{}
+		for _, project := range criteria.Projects {
+			projectConditions = append(projectConditions, fmt.Sprintf(`
+				EXISTS (
+					SELECT 1 FROM graph_edges e
+					JOIN graph_nodes pr ON e.target_node_id = pr.id
+					WHERE e.source_node_id = p.id
+					  AND e.edge_type = 'WORKED_ON'
+					  AND pr.deleted_at IS NULL
+					  AND (pr.properties->>'name' ILIKE $%d OR pr.properties->>'description' ILIKE $%d
+					       OR pr.properties->>'impact' ILIKE $%d
+					       OR word_similarity(immutable_unaccent($%d), immutable_unaccent(pr.properties->>'description')) >= $%d)
+				)
+			`, argIndex, argIndex, argIndex, argIndex+1, argIndex+2))
+			args = append(args, fmt.Sprintf("%%%s%%", project), project, fuzzyCompanyThreshold)
+			argIndex += 3
+		}
+		conditions = append(conditions, "("+strings.Join(projectConditions, " OR ")+")")
+	}
+
+	// Filter by work preferences; candidates whose CV didn't state them
+	// don't match
+	if modes := NormalizeWorkModes(criteria.WorkModes); len(modes) > 0 {
+		conditions = append(conditions, fmt.Sprintf("p.properties->'work_modes' ?| $%d::text[]", argIndex))
+		args = append(args, modes)
+		argIndex++
+	}
+	if types := NormalizeEmploymentTypes(criteria.EmploymentTypes); len(types) > 0 {
+		conditions = append(conditions, fmt.Sprintf("p.properties->'employment_types' ?| $%d::text[]", argIndex))
+		args = append(args, types)
+		argIndex++
+	}
+	if criteria.AvailableWithin != nil && *criteria.AvailableWithin >= 0 {
+		conditions = append(conditions, availableWithinSQL(argIndex))
+		args = append(args, *criteria.AvailableWithin)
+		argIndex++
+	}
+
+	// Filter by location: a city by its id or, with RadiusKm, by distance; a
+	// country by its code; an unknown place by the CV's text
+	if len(locations) > 0 {
+		locConditions := []string{}
+		for _, l := range locations {
+			switch {
+			case l.loc == nil:
+				locConditions = append(locConditions, fmt.Sprintf("p.properties->>'location' ILIKE $%d", argIndex))
+				args = append(args, fmt.Sprintf("%%%s%%", l.text))
+				argIndex++
+			case !l.loc.IsCity():
+				locConditions = append(locConditions, fmt.Sprintf("p.properties->>'country_code' = $%d", argIndex))
+				args = append(args, l.loc.CountryCode)
+				argIndex++
+			case criteria.RadiusKm > 0:
+				locConditions = append(locConditions, fmt.Sprintf("%s <= $%d", personDistanceSQL(argIndex, argIndex+1), argIndex+2))
+				args = append(args, l.loc.Lat, l.loc.Lon, criteria.RadiusKm)
+				argIndex += 3
+			default:
+				locConditions = append(locConditions, fmt.Sprintf("p.properties->>'location_id' = $%d", argIndex))
+				args = append(args, strconv.Itoa(l.loc.ID))
+				argIndex++
+			}
+		}
+		conditions = append(conditions, "("+strings.Join(locConditions, " OR ")+")")
+	}
+
 	// Filter by minimum experience years
 	if criteria.MinExperience != nil && *criteria.MinExperience > 0 {
 		conditions = append(conditions, fmt.Sprintf(
-			"(p.properties->>'total_experience_years')::int >= $%d", argIndex))
+			"(p.properties->>'total_experience_years')::float >= $%d", argIndex))
 		args = append(args, *criteria.MinExperience)
 		argIndex++
 	}
@@ -238,7 +463,7 @@ func (q *GraphQuerier) buildQuery(criteria *SearchCriteria) (string, []interface
 	// Filter by maximum experience years
 	if criteria.MaxExperience != nil && *criteria.MaxExperience > 0 {
 		conditions = append(conditions, fmt.Sprintf(
-			"(p.properties->>'total_experience_years')::int <= $%d", argIndex))
+			"(p.properties->>'total_experience_years')::float <= $%d", argIndex))
 		args = append(args, *criteria.MaxExperience)
 		argIndex++
 	}
@@ -247,22 +472,24 @@ func (q *GraphQuerier) buildQuery(criteria *SearchCriteria) (string, []interface
 		baseQuery += " AND " + strings.Join(conditions, " AND ")
 	}
 
-	baseQuery += " LIMIT 50" // Safety limit
+	baseQuery += fmt.Sprintf(" LIMIT %d", graphQueryLimit) // Safety limit
 
 	return baseQuery, args
 }
 
 func (q *GraphQuerier) enrichCandidate(ctx context.Context, result *CandidateResult) {
+	orgID := tenant.OrgID(ctx)
+
 	// Fetch skills
 	skillRows, err := q.db.QueryContext(ctx, `
 		SELECT s.node_id, s.properties
 		FROM graph_nodes p
 		JOIN graph_edges e ON p.id = e.source_node_id
 		JOIN graph_nodes s ON e.target_node_id = s.id
-		WHERE p.node_id = $1
+		WHERE p.node_id = $1 AND p.org_id = $2
 		  AND e.edge_type = 'HAS_SKILL'
 		  AND s.node_type = 'skill'
-	`, result.PersonID)
+	`, result.PersonID, orgID)
 
 	if err == nil {
 		defer skillRows.Close()
@@ -270,11 +497,10 @@ func (q *GraphQuerier) enrichCandidate(ctx context.Context, result *CandidateRes
 			var nodeID string
 			var propsJSON []byte
 			if err := skillRows.Scan(&nodeID, &propsJSON); err == nil {
-				var props map[string]interface{}
-				if err := json.Unmarshal(propsJSON, &props); err == nil {
+				if props, err := DecodeSkillProperties(propsJSON); err == nil && props.Name != "" {
 					skill := SkillNode{
-						Name:        props["name"].(string),
-						Proficiency: props["proficiency"].(string),
+						Name:        props.Name,
+						Proficiency: props.Proficiency,
 					}
 					result.Skills = append(result.Skills, skill)
 				}
@@ -288,10 +514,10 @@ func (q *GraphQuerier) enrichCandidate(ctx context.Context, result *CandidateRes
 		FROM graph_nodes p
 		JOIN graph_edges e ON p.id = e.source_node_id
 		JOIN graph_nodes c ON e.target_node_id = c.id
-		WHERE p.node_id = $1
+		WHERE p.node_id = $1 AND p.org_id = $2
 		  AND e.edge_type IN ('WORKS_AT', 'WORKED_AT')
 		  AND c.node_type = 'company'
-	`, result.PersonID)
+	`, result.PersonID, orgID)
 
 	if err == nil {
 		defer companyRows.Close()
@@ -299,31 +525,98 @@ func (q *GraphQuerier) enrichCandidate(ctx context.Context, result *CandidateRes
 			var nodeID, edgeType string
 			var propsJSON []byte
 			if err := companyRows.Scan(&nodeID, &propsJSON, &edgeType); err == nil {
-				var props map[string]interface{}
-				if err := json.Unmarshal(propsJSON, &props); err == nil {
+				if props, err := DecodeCompanyProperties(propsJSON); err == nil && props.Name != "" {
 					company := CompanyNode{
-						Name:      props["name"].(string),
+						Name:      props.Name,
 						IsCurrent: edgeType == "WORKS_AT",
 					}
-					if pos, ok := props["position"].(string); ok {
-						company.Position = pos
-					}
 					result.Companies = append(result.Companies, company)
 				}
 			}
 		}
 	}
 
+	// Fetch certifications
+	certRows, err := q.db.QueryContext(ctx, `
+		SELECT ce.properties
+		FROM graph_nodes p
+		JOIN graph_edges e ON p.id = e.source_node_id
+		JOIN graph_nodes ce ON e.target_node_id = ce.id
+		WHERE p.node_id = $1 AND p.org_id = $2
+		  AND e.edge_type = 'HAS_CERTIFICATION'
+		  AND ce.node_type = 'certification'
+	`, result.PersonID, orgID)
+
+	if err == nil {
+		defer certRows.Close()
+		for certRows.Next() {
+			var propsJSON []byte
+			if err := certRows.Scan(&propsJSON); err == nil {
+				if props, err := DecodeCertificationProperties(propsJSON); err == nil && props.Name != "" {
+					result.Certifications = append(result.Certifications, props.Name)
+				}
+			}
+		}
+	}
+
+	// Fetch spoken languages
+	langRows, err := q.db.QueryContext(ctx, `
+		SELECT l.properties, e.properties
+		FROM graph_nodes p
+		JOIN graph_edges e ON p.id = e.source_node_id
+		JOIN graph_nodes l ON e.target_node_id = l.id
+		WHERE p.node_id = $1 AND p.org_id = $2
+		  AND e.edge_type = 'SPEAKS'
+		  AND l.node_type = 'language'
+	`, result.PersonID, orgID)
+
+	if err == nil {
+		defer langRows.Close()
+		for langRows.Next() {
+			var nodeJSON, edgeJSON []byte
+			if err := langRows.Scan(&nodeJSON, &edgeJSON); err == nil {
+				if props, err := DecodeLanguageProperties(nodeJSON); err == nil && props.Name != "" {
+					speaks, _ := DecodeSpeaksProperties(edgeJSON)
+					result.Languages = append(result.Languages, LanguageNode{Name: props.Name, Proficiency: speaks.Proficiency})
+				}
+			}
+		}
+	}
+
+	// Fetch projects
+	projectRows, err := q.db.QueryContext(ctx, `
+		SELECT pr.properties
+		FROM graph_nodes p
+		JOIN graph_edges e ON p.id = e.source_node_id
+		JOIN graph_nodes pr ON e.target_node_id = pr.id
+		WHERE p.node_id = $1 AND p.org_id = $2
+		  AND e.edge_type = 'WORKED_ON'
+		  AND pr.node_type = 'project'
+		  AND pr.deleted_at IS NULL
+	`, result.PersonID, orgID)
+
+	if err == nil {
+		defer projectRows.Close()
+		for projectRows.Next() {
+			var propsJSON []byte
+			if err := projectRows.Scan(&propsJSON); err == nil {
+				if props, err := DecodeProjectProperties(propsJSON); err == nil && (props.Name != "" || props.Description != "") {
+					result.Projects = append(result.Projects, props.Text())
+				}
+			}
+		}
+	}
+
 	// Fetch education
 	eduRows, err := q.db.QueryContext(ctx, `
 		SELECT ed.node_id, ed.properties
 		FROM graph_nodes p
 		JOIN graph_edges e ON p.id = e.source_node_id
 		JOIN graph_nodes ed ON e.target_node_id = ed.id
-		WHERE p.node_id = $1
+		WHERE p.node_id = $1 AND p.org_id = $2
 		  AND e.edge_type = 'GRADUATED_FROM'
 		  AND ed.node_type = 'education'
-	`, result.PersonID)
+	`, result.PersonID, orgID)
 
 	if err == nil {
 		defer eduRows.Close()
@@ -331,16 +624,11 @@ func (q *GraphQuerier) enrichCandidate(ctx context.Context, result *CandidateRes
 			var nodeID string
 			var propsJSON []byte
 			if err := eduRows.Scan(&nodeID, &propsJSON); err == nil {
-				var props map[string]interface{}
-				if err := json.Unmarshal(propsJSON, &props); err == nil {
+				if props, err := DecodeEducationProperties(propsJSON); err == nil && props.Institution != "" {
 					edu := EducationNode{
-						Institution: props["institution"].(string),
-					}
-					if deg, ok := props["degree"].(string); ok {
-						edu.Degree = deg
-					}
-					if field, ok := props["field"].(string); ok {
-						edu.Field = field
+						Institution: props.Institution,
+						Degree:      props.Degree,
+						Field:       props.Field,
 					}
 					result.Education = append(result.Education, edu)
 				}
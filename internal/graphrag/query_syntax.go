@@ -0,0 +1,290 @@
+package graphrag
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+
+	"cv-search/internal/textnorm"
+)
+
+// ─── Query syntax ────────────────────────────────────────────────────────────
+//
+// A hybrid search query can be a boolean expression: "quoted phrases", AND,
+// OR and NOT (upper case) and parentheses; terms side by side are ANDed, AND
+// binds tighter than OR. A query with no quotes or operators is plain text,
+// searched as before (parentheses alone don't make it an expression).
+//
+// An expression becomes a to_tsquery for BM25 and a constraint every result
+// must meet, checked against candidates.search_vector. Embeddings and query
+// analysis get its terms outside NOT as plain text; the reranker gets the
+// query as written.
+
+// QuerySyntax is a query written as a boolean expression, see
+// ParseQuerySyntax.
+type QuerySyntax struct {
+	Text    string // terms outside NOT, as written: for embeddings and query analysis
+	tsQuery string // to_tsquery input, terms folded like search_vector
+}
+
+// queryNode is a node of a parsed query expression.
+type queryNode struct {
+	op       string   // term | and | or | not
+	raw      string   // term: as written
+	words    []string // term: its folded words, several for a phrase
+	children []*queryNode
+}
+
+type queryToken struct {
+	kind string // word | phrase | and | or | not | ( | )
+	text string
+}
+
+// ParseQuerySyntax parses query if it is a boolean expression; nil, nil when
+// it is plain text.
+func ParseQuerySyntax(query string) (*QuerySyntax, error) {
+	tokens, err := tokenizeQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	syntax := false
+	for _, t := range tokens {
+		if t.kind != "word" && t.kind != "(" && t.kind != ")" {
+			syntax = true
+			break
+		}
+	}
+	if !syntax {
+		return nil, nil
+	}
+
+	p := &queryParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	root = root.simplify()
+	var positive []string
+	root.positiveTerms(&positive)
+	if len(positive) == 0 {
+		return nil, fmt.Errorf("query needs a term outside NOT")
+	}
+	return &QuerySyntax{Text: strings.Join(positive, " "), tsQuery: root.tsQuery()}, nil
+}
+
+// tokenizeQuery splits query into words, "phrases", operators and
+// parentheses.
+func tokenizeQuery(query string) ([]queryToken, error) {
+	var tokens []queryToken
+	rs := []rune(query)
+	for i := 0; i < len(rs); {
+		switch r := rs[i]; {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			tokens = append(tokens, queryToken{kind: string(r), text: string(r)})
+			i++
+		case r == '"':
+			end := i + 1
+			for end < len(rs) && rs[end] != '"' {
+				end++
+			}
+			if end == len(rs) {
+				return nil, fmt.Errorf("unterminated quote")
+			}
+			tokens = append(tokens, queryToken{kind: "phrase", text: string(rs[i+1 : end])})
+			i = end + 1
+		default:
+			end := i
+			for end < len(rs) && !unicode.IsSpace(rs[end]) && rs[end] != '(' && rs[end] != ')' && rs[end] != '"' {
+				end++
+			}
+			word := string(rs[i:end])
+			kind := "word"
+			switch word {
+			case "AND", "OR", "NOT":
+				kind = strings.ToLower(word)
+			}
+			tokens = append(tokens, queryToken{kind: kind, text: word})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos].kind
+	}
+	return ""
+}
+
+// parseOr parses and-expressions separated by OR.
+func (p *queryParser) parseOr() (*queryNode, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	node := &queryNode{op: "or", children: []*queryNode{first}}
+	for p.peek() == "or" {
+		p.pos++
+		next, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		node.children = append(node.children, next)
+	}
+	return node, nil
+}
+
+// parseAnd parses unary expressions separated by AND or side by side.
+func (p *queryParser) parseAnd() (*queryNode, error) {
+	first, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	node := &queryNode{op: "and", children: []*queryNode{first}}
+	for {
+		switch p.peek() {
+		case "and":
+			p.pos++
+		case "word", "phrase", "not", "(":
+		default:
+			return node, nil
+		}
+		next, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		node.children = append(node.children, next)
+	}
+}
+
+// parseUnary parses a term, a phrase, a parenthesized expression or NOT
+// one of them.
+func (p *queryParser) parseUnary() (*queryNode, error) {
+	if p.pos == len(p.tokens) {
+		return nil, fmt.Errorf("query ends where a term is expected")
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	switch t.kind {
+	case "not":
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &queryNode{op: "not", children: []*queryNode{child}}, nil
+	case "(":
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return node, nil
+	case "word", "phrase":
+		return &queryNode{op: "term", raw: t.text, words: queryWords(textnorm.Fold(t.text))}, nil
+	}
+	return nil, fmt.Errorf("unexpected %q where a term is expected", t.text)
+}
+
+// simplify drops terms without searchable words and the operators left
+// without operands, and unwraps single-operand AND / OR. nil when nothing
+// is left.
+func (n *queryNode) simplify() *queryNode {
+	switch n.op {
+	case "term":
+		if len(n.words) == 0 {
+			return nil
+		}
+		return n
+	case "not":
+		child := n.children[0].simplify()
+		if child == nil {
+			return nil
+		}
+		return &queryNode{op: "not", children: []*queryNode{child}}
+	}
+	var children []*queryNode
+	for _, c := range n.children {
+		if c = c.simplify(); c != nil {
+			children = append(children, c)
+		}
+	}
+	switch len(children) {
+	case 0:
+		return nil
+	case 1:
+		return children[0]
+	}
+	return &queryNode{op: n.op, children: children}
+}
+
+// positiveTerms appends the terms of n outside NOT, as written.
+func (n *queryNode) positiveTerms(out *[]string) {
+	if n == nil {
+		return
+	}
+	switch n.op {
+	case "term":
+		*out = append(*out, n.raw)
+	case "and", "or":
+		for _, c := range n.children {
+			c.positiveTerms(out)
+		}
+	}
+}
+
+// tsQuery renders n as to_tsquery input. Words hold only letters, digits
+// and + # . (queryWords) and are quoted, so none reads as an operator.
+func (n *queryNode) tsQuery() string {
+	switch n.op {
+	case "term":
+		quoted := make([]string, len(n.words))
+		for i, w := range n.words {
+			quoted[i] = "'" + w + "'"
+		}
+		if len(quoted) == 1 {
+			return quoted[0]
+		}
+		return "(" + strings.Join(quoted, " <-> ") + ")"
+	case "not":
+		return "!" + n.children[0].tsQuery()
+	}
+	parts := make([]string, len(n.children))
+	for i, c := range n.children {
+		parts[i] = c.tsQuery()
+	}
+	sep := " & "
+	if n.op == "or" {
+		sep = " | "
+	}
+	return "(" + strings.Join(parts, sep) + ")"
+}
+
+// filterBySyntax is the search's query syntax filter: it keeps the
+// candidates whose CV matches syntax, matching by person ID (see
+// MatchingSyntax).
+func filterBySyntax(candidates []FusedCandidate, syntax *QuerySyntax, matching map[string]bool) []FusedCandidate {
+	kept := make([]FusedCandidate, 0, len(candidates))
+	for _, c := range candidates {
+		if matching[c.PersonID] {
+			kept = append(kept, c)
+		}
+	}
+	log.Printf("[HybridSearch] Query syntax filter (%s): %d → %d candidates",
+		syntax.tsQuery, len(candidates), len(kept))
+	return kept
+}
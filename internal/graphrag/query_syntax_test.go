@@ -0,0 +1,71 @@
+package graphrag
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseQuerySyntax(t *testing.T) {
+	tests := []struct {
+		query       string
+		wantText    string // "" = plain text (nil)
+		wantTSQuery string
+		wantErr     bool
+	}{
+		{query: "senior go developer"},
+		{query: "go and java"}, // operators are upper case
+		{query: "(go"},         // parentheses alone don't make an expression
+		{
+			query:       `"machine learning" AND (python OR scala) NOT php`,
+			wantText:    "machine learning python scala",
+			wantTSQuery: "(('machine' <-> 'learning') & ('python' | 'scala') & !'php')",
+		},
+		{query: "go OR java", wantText: "go java", wantTSQuery: "('go' | 'java')"},
+		{query: "Çağrı AND İstanbul", wantText: "Çağrı İstanbul", wantTSQuery: "('cagri' & 'istanbul')"},
+		{query: "go AND", wantErr: true},
+		{query: `"unterminated`, wantErr: true},
+		{query: "NOT php", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, err := ParseQuerySyntax(tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			switch {
+			case tt.wantErr:
+			case tt.wantText == "":
+				if got != nil {
+					t.Errorf("ParseQuerySyntax = %+v, want nil (plain text)", got)
+				}
+			case got == nil:
+				t.Errorf("ParseQuerySyntax = nil, want %q", tt.wantTSQuery)
+			case got.Text != tt.wantText || got.tsQuery != tt.wantTSQuery:
+				t.Errorf("ParseQuerySyntax = %q, %q; want %q, %q", got.Text, got.tsQuery, tt.wantText, tt.wantTSQuery)
+			}
+		})
+	}
+}
+
+func TestFilterBySyntax(t *testing.T) {
+	syntax := &QuerySyntax{Text: "go", tsQuery: "'go'"}
+	candidates := []FusedCandidate{{PersonID: "a"}, {PersonID: "b"}, {PersonID: "c"}}
+	tests := []struct {
+		name     string
+		matching map[string]bool
+		want     []string
+	}{
+		{"some", map[string]bool{"a": true, "c": true}, []string{"a", "c"}},
+		{"all", map[string]bool{"a": true, "b": true, "c": true}, []string{"a", "b", "c"}},
+		{"none", map[string]bool{}, []string{}},
+		{"false entries", map[string]bool{"a": false, "b": true}, []string{"b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := personIDs(filterBySyntax(candidates, syntax, tt.matching))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("filterBySyntax = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package graphrag
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RankingSignals are structured, graph-derived career signals that let the
+// reranker prefer "currently doing X" over "did X in 2012". All values are
+// computed from edge properties written by BuildFromLLMExtraction; fields are
+// nil/empty when the CV didn't carry the underlying dates.
+type RankingSignals struct {
+	YearsInCurrentRole *float64       `json:"years_in_current_role,omitempty"` // since the last role change
+	ProgressionSlope   *float64       `json:"progression_slope,omitempty"`     // seniority levels gained per year
+	SkillLastUsed      map[string]int `json:"skill_last_used,omitempty"`       // requested skill → last year used
+	CurrentSkills      []string       `json:"current_skills,omitempty"`        // requested skills used in the current role/year
+}
+
+// roleLevel maps a job title to a coarse seniority level for progression.
+// Returns 0 when the title carries no seniority hint.
+func roleLevel(title string) int {
+	t := strings.ToLower(title)
+	switch {
+	case containsAny(t, "cto", "vp ", "vice president", "director", "head of"):
+		return 6
+	case containsAny(t, "architect", "principal", "manager"):
+		return 5
+	case containsAny(t, "lead", "staff"):
+		return 4
+	case containsAny(t, "senior", "sr.", "sr "):
+		return 3
+	case containsAny(t, "junior", "jr.", "jr ", "intern", "trainee", "stajyer"):
+		return 1
+	case t != "":
+		return 2
+	}
+	return 0
+}
+
+func containsAny(s string, subs ...string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// propYear reads a year from a JSON property that the LLM may have emitted as
+// a number, a numeric string, or "present"/"current" (→ current year).
+func propYear(v interface{}) int {
+	switch y := v.(type) {
+	case float64:
+		return int(y)
+	case int:
+		return y
+	case string:
+		s := strings.ToLower(strings.TrimSpace(y))
+		if s == "present" || s == "current" || s == "now" || s == "günümüz" {
+			return time.Now().Year()
+		}
+		if len(s) >= 4 {
+			if n, err := strconv.Atoi(s[:4]); err == nil {
+				return n
+			}
+		}
+	}
+	return 0
+}
+
+type roleSpan struct {
+	position  string
+	startYear int
+	endYear   int
+	isCurrent bool
+}
+
+// attachRankingSignals computes RankingSignals for every candidate in one
+// batched edge query. querySkills are the skills extracted from the query;
+// skill recency is only reported for those. Non-fatal: errors are logged.
+func (h *HybridSearchEngine) attachRankingSignals(ctx context.Context, candidates []FusedCandidate, querySkills []string) {
+	if len(candidates) == 0 {
+		return
+	}
+
+	ids := make([]interface{}, 0, len(candidates))
+	byNode := make(map[int]int, len(candidates))
+	for i := range candidates {
+		if candidates[i].GraphNodeIntID > 0 {
+			byNode[candidates[i].GraphNodeIntID] = i
+			ids = append(ids, candidates[i].GraphNodeIntID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	placeholders := make([]string, len(ids))
+	for i := range ids {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+
+	rows, err := h.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT e.source_node_id, e.edge_type, COALESCE(t.properties->>'name', ''), COALESCE(e.properties, '{}'::jsonb)
+		FROM graph_edges e
+		JOIN graph_nodes t ON t.id = e.target_node_id
+		WHERE e.source_node_id IN (%s)
+		  AND e.edge_type IN ('WORKS_AT', 'WORKED_AT', 'HAS_SKILL')
+	`, strings.Join(placeholders, ",")), ids...)
+	if err != nil {
+		log.Printf("[HybridSearch] Ranking signals query failed (non-fatal): %v", err)
+		return
+	}
+	defer rows.Close()
+
+	wanted := make(map[string]string, len(querySkills))
+	for _, s := range querySkills {
+		wanted[strings.ToLower(s)] = s
+	}
+
+	roles := make(map[int][]roleSpan)
+	skillYears := make(map[int]map[string]int)
+	for rows.Next() {
+		var nodeID int
+		var edgeType, targetName string
+		var propsJSON []byte
+		if err := rows.Scan(&nodeID, &edgeType, &targetName, &propsJSON); err != nil {
+			continue
+		}
+		if edgeType == "HAS_SKILL" {
+			name, ok := wanted[strings.ToLower(targetName)]
+			if !ok {
+				continue
+			}
+			props, err := DecodeHasSkillProperties(propsJSON)
+			if err != nil {
+				continue
+			}
+			if y := props.LastUsedYear; y > 0 {
+				if skillYears[nodeID] == nil {
+					skillYears[nodeID] = make(map[string]int)
+				}
+				skillYears[nodeID][name] = y
+			}
+			continue
+		}
+
+		props, err := DecodeWorkProperties(propsJSON)
+		if err != nil {
+			continue
+		}
+		span := roleSpan{
+			startYear: props.StartYear,
+			endYear:   props.EndYear,
+			position:  props.Position,
+			isCurrent: edgeType == "WORKS_AT" || props.IsCurrent,
+		}
+		roles[nodeID] = append(roles[nodeID], span)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("[HybridSearch] Ranking signals iteration failed (non-fatal): %v", err)
+	}
+
+	thisYear := time.Now().Year()
+	for nodeID, idx := range byNode {
+		sig := computeRankingSignals(roles[nodeID], skillYears[nodeID], thisYear)
+		if sig != nil {
+			candidates[idx].Signals = sig
+		}
+	}
+}
+
+func computeRankingSignals(roles []roleSpan, skillYears map[string]int, thisYear int) *RankingSignals {
+	sig := &RankingSignals{}
+	empty := true
+
+	// Years since last role change: the most recent start year among current roles
+	// (or among all roles, if none is flagged current).
+	latestStart := 0
+	for _, r := range roles {
+		if r.isCurrent && r.startYear > latestStart {
+			latestStart = r.startYear
+		}
+	}
+	if latestStart == 0 {
+		for _, r := range roles {
+			if r.startYear > latestStart {
+				latestStart = r.startYear
+			}
+		}
+	}
+	if latestStart > 0 && latestStart <= thisYear {
+		y := float64(thisYear - latestStart)
+		sig.YearsInCurrentRole = &y
+		empty = false
+	}
+
+	// Career progression: least-squares slope of seniority level over start year.
+	var pts [][2]float64
+	for _, r := range roles {
+		if lvl := roleLevel(r.position); lvl > 0 && r.startYear > 0 {
+			pts = append(pts, [2]float64{float64(r.startYear), float64(lvl)})
+		}
+	}
+	if len(pts) >= 2 {
+		var sx, sy, sxx, sxy float64
+		for _, p := range pts {
+			sx += p[0]
+			sy += p[1]
+			sxx += p[0] * p[0]
+			sxy += p[0] * p[1]
+		}
+		n := float64(len(pts))
+		if den := n*sxx - sx*sx; den != 0 {
+			slope := math.Round((n*sxy-sx*sy)/den*100) / 100
+			sig.ProgressionSlope = &slope
+			empty = false
+		}
+	}
+
+	if len(skillYears) > 0 {
+		sig.SkillLastUsed = skillYears
+		for name, y := range skillYears {
+			if y >= thisYear-1 {
+				sig.CurrentSkills = append(sig.CurrentSkills, name)
+			}
+		}
+		sort.Strings(sig.CurrentSkills)
+		empty = false
+	}
+
+	if empty {
+		return nil
+	}
+	return sig
+}
+
+// describeSignals renders signals as one prompt line for the LLM scorer.
+func describeSignals(sig *RankingSignals, thisYear int) string {
+	if sig == nil {
+		return ""
+	}
+	var parts []string
+	if sig.YearsInCurrentRole != nil {
+		parts = append(parts, fmt.Sprintf("in current role %.0f yrs", *sig.YearsInCurrentRole))
+	}
+	if sig.ProgressionSlope != nil {
+		parts = append(parts, fmt.Sprintf("seniority progression %+.2f levels/yr", *sig.ProgressionSlope))
+	}
+	if len(sig.SkillLastUsed) > 0 {
+		names := make([]string, 0, len(sig.SkillLastUsed))
+		for name := range sig.SkillLastUsed {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			y := sig.SkillLastUsed[name]
+			if y >= thisYear-1 {
+				parts = append(parts, fmt.Sprintf("%s used currently", name))
+			} else {
+				parts = append(parts, fmt.Sprintf("%s last used %d", name, y))
+			}
+		}
+	}
+	return strings.Join(parts, " | ")
+}
@@ -0,0 +1,422 @@
+package graphrag
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// ─── Re-embedding into shadow columns ───
+//
+// Vectors from different embedding models can't be compared, so switching
+// models means re-embedding everything. That happens next to the live
+// vectors rather than over them, so search keeps working until the new ones
+// have been validated:
+//
+//   - PrepareShadow adds embedding_next, embedding_next_model and
+//     embedding_next_created_at to every table with an embedding column.
+//   - EmbedShadow fills them with this service's model for every row that has
+//     a live embedding. Re-running it only does rows that are missing or whose
+//     live embedding changed since.
+//   - ShadowSimilaritySearch is the vector search over the shadow vectors, to
+//     compare quality with the live ones before switching.
+//   - SwapShadow swaps live and shadow columns (and their HNSW indexes) in one
+//     transaction. The old vectors end up in the shadow columns, so swapping
+//     again with the old model is the rollback; DropShadow drops them.
+
+// shadowTable is a table with an embedding column.
+type shadowTable struct {
+	name  string
+	index string // HNSW index on embedding; the shadow one is index + "_next"
+	// text is the SQL expression for the embedded text; empty for
+	// graph_nodes, whose text is built from node_type and properties.
+	text string
+	// changedAt moves whenever the live embedding is rewritten, so a newer
+	// value than embedding_next_created_at marks the shadow vector stale.
+	changedAt string
+	// tracksModel: embedding_model exists and is swapped along with
+	// embedding.
+	tracksModel bool
+}
+
+var shadowTables = []shadowTable{
+	{name: "graph_nodes", index: "idx_graph_nodes_embedding", changedAt: "embedding_created_at", tracksModel: true},
+	{name: "cv_chunks", index: "idx_cv_chunks_embedding", text: "text", changedAt: "embedding_created_at", tracksModel: true},
+	{name: "graph_communities", index: "idx_communities_embedding", text: "COALESCE(title, '') || ' ' || COALESCE(summary, '')", changedAt: "updated_at"},
+}
+
+// ShadowProgress is how far EmbedShadow got for one table.
+type ShadowProgress struct {
+	Table   string `json:"table"`
+	Live    int    `json:"live"`    // rows with a live embedding
+	Pending int    `json:"pending"` // of those, rows without a current shadow embedding
+}
+
+// pendingShadow is the condition for rows EmbedShadow still has to do.
+func pendingShadow(t shadowTable) string {
+	return fmt.Sprintf(`embedding IS NOT NULL AND (embedding_next IS NULL
+		OR embedding_next_model IS DISTINCT FROM $1
+		OR %s > embedding_next_created_at)`, t.changedAt)
+}
+
+// ShadowDimensions returns the dimensions of the shadow columns, or 0 when
+// there are none.
+func (s *EmbeddingService) ShadowDimensions(ctx context.Context) (int, error) {
+	return s.columnDimensions(ctx, "embedding_next")
+}
+
+// columnDimensions returns the dimensions of graph_nodes' vector column, or
+// 0 when there is no such column.
+func (s *EmbeddingService) columnDimensions(ctx context.Context, column string) (int, error) {
+	// pgvector keeps a vector column's dimensions in atttypmod.
+	var dims int
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COALESCE(MAX(atttypmod), 0)
+		FROM pg_attribute
+		WHERE attrelid = 'graph_nodes'::regclass AND attname = $1 AND NOT attisdropped
+	`, column).Scan(&dims)
+	if err != nil {
+		return 0, fmt.Errorf("read %s column: %w", column, err)
+	}
+	return dims, nil
+}
+
+// PrepareShadow adds the shadow columns for vectors of the given dimensions.
+// Existing shadow columns of other dimensions are an error: drop them first.
+func (s *EmbeddingService) PrepareShadow(ctx context.Context, dimensions int) error {
+	existing, err := s.ShadowDimensions(ctx)
+	if err != nil {
+		return err
+	}
+	if existing > 0 && existing != dimensions {
+		return fmt.Errorf("shadow columns hold %d-dimensional vectors, %s gives %d; drop them first", existing, s.model, dimensions)
+	}
+	for _, t := range shadowTables {
+		if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+			ALTER TABLE %s
+				ADD COLUMN IF NOT EXISTS embedding_next vector(%d),
+				ADD COLUMN IF NOT EXISTS embedding_next_model TEXT,
+				ADD COLUMN IF NOT EXISTS embedding_next_created_at TIMESTAMP WITH TIME ZONE
+		`, t.name, dimensions)); err != nil {
+			return fmt.Errorf("add shadow columns to %s: %w", t.name, err)
+		}
+	}
+	return nil
+}
+
+// ShadowStatus reports, per table, how many live embeddings still lack a
+// current shadow embedding from this service's model.
+func (s *EmbeddingService) ShadowStatus(ctx context.Context) ([]ShadowProgress, error) {
+	var progress []ShadowProgress
+	for _, t := range shadowTables {
+		p := ShadowProgress{Table: t.name}
+		err := s.db.QueryRowContext(ctx, fmt.Sprintf(`
+			SELECT COUNT(*) FILTER (WHERE embedding IS NOT NULL),
+			       COUNT(*) FILTER (WHERE %s)
+			FROM %s
+		`, pendingShadow(t), t.name), s.model).Scan(&p.Live, &p.Pending)
+		if err != nil {
+			return nil, fmt.Errorf("count %s embeddings: %w", t.name, err)
+		}
+		progress = append(progress, p)
+	}
+	return progress, nil
+}
+
+// EmbedShadow embeds every pending row (see ShadowStatus) into the shadow
+// columns, batchSize texts per embeddings request. It can be stopped and
+// re-run at any point.
+func (s *EmbeddingService) EmbedShadow(ctx context.Context, batchSize int) error {
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	if err := pruneShadow(ctx, s.db); err != nil {
+		return err
+	}
+	for _, t := range shadowTables {
+		done := 0
+		for {
+			ids, texts, err := s.pendingShadowTexts(ctx, t, batchSize)
+			if err != nil {
+				return err
+			}
+			if len(ids) == 0 {
+				break
+			}
+			embeddings, err := s.GenerateEmbeddings(ctx, texts)
+			if err != nil {
+				return fmt.Errorf("embed %s: %w", t.name, err)
+			}
+			vectors := make([]string, len(embeddings))
+			for i, e := range embeddings {
+				b, _ := json.Marshal(e)
+				vectors[i] = string(b)
+			}
+			if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+				UPDATE %s t
+				SET embedding_next = v.embedding::vector,
+				    embedding_next_model = $3,
+				    embedding_next_created_at = NOW()
+				FROM unnest($1::bigint[], $2::text[]) AS v(id, embedding)
+				WHERE t.id = v.id
+			`, t.name), ids, vectors, s.model); err != nil {
+				return fmt.Errorf("store %s shadow embeddings: %w", t.name, err)
+			}
+			done += len(ids)
+			log.Printf("[Embeddings] %s: %d shadow embeddings (%s)", t.name, done, s.model)
+		}
+	}
+	return nil
+}
+
+// pendingShadowTexts returns up to limit pending rows of t and their texts.
+func (s *EmbeddingService) pendingShadowTexts(ctx context.Context, t shadowTable, limit int) ([]int64, []string, error) {
+	text := t.text
+	if text == "" {
+		// Person nodes are embedded with their interview notes, like
+		// ReEmbedPersonNodeByID does.
+		text = `jsonb_build_object('type', node_type, 'properties', properties, 'notes', (
+			SELECT string_agg(i.notes, ' | ' ORDER BY i.interview_date DESC)
+			FROM interviews i
+			JOIN candidates c ON c.id = i.candidate_id
+			WHERE c.graph_node_id = graph_nodes.id AND i.notes <> '' AND c.deleted_at IS NULL
+		))::text`
+	}
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT id, %s FROM %s WHERE %s ORDER BY id LIMIT $2
+	`, text, t.name, pendingShadow(t)), s.model, limit)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list pending %s: %w", t.name, err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	var texts []string
+	for rows.Next() {
+		var id int64
+		var text string
+		if err := rows.Scan(&id, &text); err != nil {
+			return nil, nil, err
+		}
+		if t.text == "" {
+			if text, err = s.shadowNodeText(text); err != nil {
+				return nil, nil, fmt.Errorf("node %d: %w", id, err)
+			}
+		}
+		if strings.TrimSpace(text) == "" {
+			text = "-" // the embeddings API rejects empty input
+		}
+		ids = append(ids, id)
+		texts = append(texts, text)
+	}
+	return ids, texts, rows.Err()
+}
+
+// shadowNodeText builds a graph node's text from pendingShadowTexts' JSON.
+func (s *EmbeddingService) shadowNodeText(raw string) (string, error) {
+	var node struct {
+		Type       string                 `json:"type"`
+		Properties map[string]interface{} `json:"properties"`
+		Notes      *string                `json:"notes"`
+	}
+	if err := json.Unmarshal([]byte(raw), &node); err != nil {
+		return "", err
+	}
+	text := s.nodeToText(node.Type, node.Properties)
+	if node.Type == "person" && node.Notes != nil {
+		text += " Interview notes: " + *node.Notes
+	}
+	return text, nil
+}
+
+// pruneShadow clears shadow vectors of rows whose live embedding was
+// removed (e.g. erased candidates), so a swap can't bring them back.
+func pruneShadow(ctx context.Context, db interface {
+	ExecContext(context.Context, string, ...any) (sql.Result, error)
+}) error {
+	for _, t := range shadowTables {
+		if _, err := db.ExecContext(ctx, fmt.Sprintf(`
+			UPDATE %s SET embedding_next = NULL, embedding_next_model = NULL, embedding_next_created_at = NULL
+			WHERE embedding IS NULL AND embedding_next IS NOT NULL
+		`, t.name)); err != nil {
+			return fmt.Errorf("prune %s shadow embeddings: %w", t.name, err)
+		}
+	}
+	return nil
+}
+
+// ShadowSimilaritySearch is SimilaritySearch over the shadow vectors.
+func (s *EmbeddingService) ShadowSimilaritySearch(ctx context.Context, queryText string, topK int) ([]string, []float64, error) {
+	queryEmbedding, err := s.GenerateEmbedding(ctx, queryText)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.vectorSearch(ctx, queryEmbedding, topK, "embedding_next")
+}
+
+// SwapShadow makes the shadow vectors live. It first builds the shadow HNSW
+// indexes (concurrently, so writes continue), then swaps columns and indexes
+// by renaming them in one transaction, which fails if any live embedding
+// still lacks a current shadow one — run EmbedShadow again and retry.
+// embedding_created_at isn't swapped: it keeps saying when a row was last
+// embedded outside this process, which is what marks old vectors stale when
+// swapping back.
+//
+// Queries must be embedded with the new model from then on: restart the API
+// with EMBEDDING_MODEL (and EMBEDDING_DIMENSIONS) set to match.
+func (s *EmbeddingService) SwapShadow(ctx context.Context) error {
+	for _, t := range shadowTables {
+		if err := s.ensureShadowIndex(ctx, t); err != nil {
+			return err
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, t := range shadowTables {
+		// Held until commit, so nothing turns pending between the check
+		// below and the renames. Search waits for the check.
+		if _, err := tx.ExecContext(ctx, "LOCK TABLE "+t.name+" IN ACCESS EXCLUSIVE MODE"); err != nil {
+			return fmt.Errorf("lock %s: %w", t.name, err)
+		}
+	}
+	if err := pruneShadow(ctx, tx); err != nil {
+		return err
+	}
+	for _, t := range shadowTables {
+		var pending int
+		if err := tx.QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE %s`, t.name, pendingShadow(t)), s.model).Scan(&pending); err != nil {
+			return fmt.Errorf("count pending %s: %w", t.name, err)
+		}
+		if pending > 0 {
+			return fmt.Errorf("%s: %d embeddings have no current %s shadow embedding; re-embed and retry", t.name, pending, s.model)
+		}
+	}
+
+	for _, t := range shadowTables {
+		columns := []string{"embedding"}
+		if t.tracksModel {
+			columns = append(columns, "embedding_model")
+		}
+		var stmts []string
+		for _, live := range columns {
+			shadow := strings.Replace(live, "embedding", "embedding_next", 1)
+			stmts = append(stmts,
+				fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s_swap", t.name, live, live),
+				fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", t.name, shadow, live),
+				fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s_swap TO %s", t.name, live, shadow),
+			)
+		}
+		stmts = append(stmts,
+			fmt.Sprintf("ALTER INDEX %s RENAME TO %s_swap", t.index, t.index),
+			fmt.Sprintf("ALTER INDEX %s_next RENAME TO %s", t.index, t.index),
+			fmt.Sprintf("ALTER INDEX %s_swap RENAME TO %s_next", t.index, t.index),
+		)
+		if !t.tracksModel {
+			// Without a model column to swap, the old vectors' model is
+			// unknown: a later EmbedShadow redoes them.
+			stmts = append(stmts, fmt.Sprintf("UPDATE %s SET embedding_next_model = NULL", t.name))
+		}
+		for _, stmt := range stmts {
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				return fmt.Errorf("swap %s: %w", t.name, err)
+			}
+		}
+	}
+	// Community centroids are means of the node embeddings just swapped in.
+	if _, err := tx.ExecContext(ctx, `UPDATE graph_communities c SET centroid_embedding = `+communityCentroidSQL); err != nil {
+		return fmt.Errorf("recompute community centroids: %w", err)
+	}
+	return tx.Commit()
+}
+
+// ensureShadowIndex builds the HNSW index on t's shadow column, unless a
+// valid one exists (an interrupted concurrent build leaves an invalid one).
+func (s *EmbeddingService) ensureShadowIndex(ctx context.Context, t shadowTable) error {
+	name := t.index + "_next"
+	var valid bool
+	err := s.db.QueryRowContext(ctx, `
+		SELECT i.indisvalid FROM pg_index i JOIN pg_class c ON c.oid = i.indexrelid WHERE c.relname = $1
+	`, name).Scan(&valid)
+	switch {
+	case err == nil && valid:
+		return nil
+	case err == nil:
+		if _, err := s.db.ExecContext(ctx, "DROP INDEX CONCURRENTLY "+name); err != nil {
+			return fmt.Errorf("drop invalid index %s: %w", name, err)
+		}
+	case !errors.Is(err, sql.ErrNoRows):
+		return fmt.Errorf("look up index %s: %w", name, err)
+	}
+	log.Printf("[Embeddings] building index %s", name)
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(
+		"CREATE INDEX CONCURRENTLY %s ON %s USING hnsw (embedding_next vector_cosine_ops)", name, t.name,
+	)); err != nil {
+		return fmt.Errorf("create index %s: %w", name, err)
+	}
+	return nil
+}
+
+// DropShadow drops the shadow columns and indexes — the old vectors after a
+// swap, or an abandoned re-embedding before one.
+func (s *EmbeddingService) DropShadow(ctx context.Context) error {
+	for _, t := range shadowTables {
+		if _, err := s.db.ExecContext(ctx, "DROP INDEX IF EXISTS "+t.index+"_next"); err != nil {
+			return fmt.Errorf("drop %s_next: %w", t.index, err)
+		}
+		if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+			ALTER TABLE %s
+				DROP COLUMN IF EXISTS embedding_next,
+				DROP COLUMN IF EXISTS embedding_next_model,
+				DROP COLUMN IF EXISTS embedding_next_created_at
+		`, t.name)); err != nil {
+			return fmt.Errorf("drop %s shadow columns: %w", t.name, err)
+		}
+	}
+	return nil
+}
+
+// ─── Embedding column dimensions ───
+
+// EmbeddingDimensions returns the dimensions of the live embedding columns.
+func (s *EmbeddingService) EmbeddingDimensions(ctx context.Context) (int, error) {
+	return s.columnDimensions(ctx, "embedding")
+}
+
+// ResizeEmbeddings changes the live embedding columns to the given
+// dimensions, for a new install whose model doesn't give the 1536 the
+// schema starts with (e.g. nomic-embed-text on Ollama). It refuses once any
+// embedding is stored: switching models with data goes through the shadow
+// columns (EmbedShadow, SwapShadow).
+func (s *EmbeddingService) ResizeEmbeddings(ctx context.Context, dimensions int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, t := range shadowTables {
+		if _, err := tx.ExecContext(ctx, "LOCK TABLE "+t.name+" IN ACCESS EXCLUSIVE MODE"); err != nil {
+			return fmt.Errorf("lock %s: %w", t.name, err)
+		}
+		var stored int
+		if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+t.name+" WHERE embedding IS NOT NULL").Scan(&stored); err != nil {
+			return fmt.Errorf("count %s embeddings: %w", t.name, err)
+		}
+		if stored > 0 {
+			return fmt.Errorf("%s already holds %d embeddings; re-embed them with cmd/tools/reembed instead", t.name, stored)
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN embedding TYPE vector(%d)", t.name, dimensions)); err != nil {
+			return fmt.Errorf("resize %s.embedding: %w", t.name, err)
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,224 @@
+package graphrag
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"cv-search/internal/llm"
+)
+
+// ─── Salaries ────────────────────────────────────────────────────────────────
+//
+// A salary a CV states is kept on the person node sealed (salary_sealed,
+// base64), never in the clear: person properties reach search responses,
+// GraphQL, exports and LLM prompts. Only the salary band filter opens it.
+// Without a SalaryBox (SETTINGS_ENCRYPTION_KEY) salaries aren't kept.
+
+// SalaryBox seals and opens salaries; *secret.Box is one.
+type SalaryBox interface {
+	Seal(plaintext string) ([]byte, error)
+	Open(sealed []byte) (string, error)
+}
+
+// Salary periods, and how many of each make a year: 52 weeks of 5 days of
+// 8 hours.
+var salaryPeriodsPerYear = map[string]float64{"year": 1, "month": 12, "day": 260, "hour": 2080}
+
+// Salary is a pay range a CV states, in Currency per Period.
+type Salary struct {
+	Min        float64 `json:"min"`
+	Max        float64 `json:"max"` // = Min for a single figure
+	Currency   string  `json:"currency"`
+	Period     string  `json:"period"`     // year | month | day | hour
+	Kind       string  `json:"kind"`       // expected | current
+	Confidence string  `json:"confidence"` // high | low
+}
+
+// NormalizeSalary returns what an extraction says about the candidate's
+// salary, nil if there's no usable amount. A missing period is taken as a
+// year and lowers the confidence, as does a missing currency.
+func NormalizeSalary(s *llm.Salary) *Salary {
+	if s == nil {
+		return nil
+	}
+	lo, loOK := salaryAmount(s.Min)
+	hi, hiOK := salaryAmount(s.Max)
+	switch {
+	case !loOK && !hiOK:
+		return nil
+	case !loOK:
+		lo = hi
+	case !hiOK:
+		hi = lo
+	}
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	if lo <= 0 {
+		return nil
+	}
+
+	out := &Salary{
+		Min:        lo,
+		Max:        hi,
+		Currency:   strings.ToUpper(strings.TrimSpace(s.Currency)),
+		Period:     strings.ToLower(strings.TrimSpace(s.Period)),
+		Kind:       strings.ToLower(strings.TrimSpace(s.Kind)),
+		Confidence: strings.ToLower(strings.TrimSpace(s.Confidence)),
+	}
+	if out.Kind != "current" {
+		out.Kind = "expected"
+	}
+	if out.Confidence != "high" {
+		out.Confidence = "low"
+	}
+	if _, ok := salaryPeriodsPerYear[out.Period]; !ok {
+		out.Period = "year"
+		out.Confidence = "low"
+	}
+	if out.Currency == "" {
+		out.Confidence = "low"
+	}
+	return out
+}
+
+// thousandsGrouped matches an amount grouped in thousands with dots or
+// commas: "120.000", "1,250,000".
+var thousandsGrouped = regexp.MustCompile(`^\d{1,3}([.,]\d{3})+$`)
+
+// salaryAmount reads an amount the model returned as a string despite the
+// prompt: "120.000", "85k", "1.2m".
+func salaryAmount(v interface{}) (float64, bool) {
+	str, ok := v.(string)
+	if !ok {
+		return propFloat(v)
+	}
+	str = strings.ToLower(strings.Join(strings.Fields(str), ""))
+	multiplier := 1.0
+	switch {
+	case strings.HasSuffix(str, "k"):
+		multiplier, str = 1e3, strings.TrimSuffix(str, "k")
+	case strings.HasSuffix(str, "m"):
+		multiplier, str = 1e6, strings.TrimSuffix(str, "m")
+	}
+	if thousandsGrouped.MatchString(str) {
+		str = strings.NewReplacer(".", "", ",", "").Replace(str)
+	}
+	n, ok := propFloat(str)
+	return n * multiplier, ok
+}
+
+// Yearly returns the salary's range per year.
+func (s Salary) Yearly() (lo, hi float64) {
+	n := salaryPeriodsPerYear[s.Period]
+	if n == 0 {
+		n = 1
+	}
+	return s.Min * n, s.Max * n
+}
+
+// SealSalary seals s for a person node's salary_sealed property.
+func SealSalary(box SalaryBox, s *Salary) (string, error) {
+	plain, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := box.Seal(string(plain))
+	if err != nil {
+		return "", fmt.Errorf("seal salary: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// OpenSalary opens a person node's salary_sealed property.
+func OpenSalary(box SalaryBox, sealed string) (*Salary, error) {
+	raw, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return nil, fmt.Errorf("decode salary: %w", err)
+	}
+	plain, err := box.Open(raw)
+	if err != nil {
+		return nil, fmt.Errorf("open salary: %w", err)
+	}
+	var s Salary
+	if err := json.Unmarshal([]byte(plain), &s); err != nil {
+		return nil, fmt.Errorf("decode salary: %w", err)
+	}
+	return &s, nil
+}
+
+// SalaryBand is a search's salary filter: candidates whose stated salary,
+// in Currency per Period, overlaps [Min, Max] (0 = unbounded). Candidates
+// whose salary can't be compared with it are kept: those who stated none,
+// one in another currency, or one that can't be opened (e.g. sealed with a
+// rotated key).
+type SalaryBand struct {
+	Min      float64
+	Max      float64
+	Currency string
+	Period   string
+
+	Box SalaryBox `json:"-"`
+}
+
+// Matches reports whether p passes the band, and whether its salary could
+// be compared with it: false when p states none, states one in another
+// currency, or one that fails to open (err).
+func (b *SalaryBand) Matches(p PersonProperties) (ok, compared bool, err error) {
+	if p.SalarySealed == "" {
+		return true, false, nil
+	}
+	s, err := OpenSalary(b.Box, p.SalarySealed)
+	if err != nil {
+		return true, false, err
+	}
+	if s.Currency != b.Currency {
+		return true, false, nil
+	}
+	n := salaryPeriodsPerYear[b.Period]
+	if n == 0 {
+		n = 1
+	}
+	lo, hi := s.Yearly()
+	if b.Min > 0 && hi < b.Min*n {
+		return false, true, nil
+	}
+	if b.Max > 0 && lo > b.Max*n {
+		return false, true, nil
+	}
+	return true, true, nil
+}
+
+// ValidSalaryPeriod reports whether period is one a SalaryBand takes.
+func ValidSalaryPeriod(period string) bool {
+	_, ok := salaryPeriodsPerYear[period]
+	return ok
+}
+
+// filterBySalary is the search's salary band filter: it drops the
+// candidates whose stated salary is outside band and marks those inside it
+// (SalaryInBand). Those whose salary can't be compared stay unmarked; a
+// salary that fails to open is logged.
+func filterBySalary(candidates []FusedCandidate, band *SalaryBand) []FusedCandidate {
+	kept := make([]FusedCandidate, 0, len(candidates))
+	for _, c := range candidates {
+		ok, compared, err := band.Matches(c.home)
+		if err != nil {
+			log.Printf("[HybridSearch] Salary of %s not compared with the band: %v", c.PersonID, err)
+		}
+		if !ok {
+			continue
+		}
+		if compared {
+			c.SalaryInBand = &ok
+		}
+		kept = append(kept, c)
+	}
+	log.Printf("[HybridSearch] Salary band filter (%g-%g %s/%s): %d → %d candidates",
+		band.Min, band.Max, band.Currency, band.Period, len(candidates), len(kept))
+	return kept
+}
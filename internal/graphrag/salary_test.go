@@ -0,0 +1,114 @@
+package graphrag
+
+import (
+	"encoding/base64"
+	"slices"
+	"strings"
+	"testing"
+
+	"cv-search/internal/secret"
+)
+
+func testBox(t *testing.T, fill string) *secret.Box {
+	t.Helper()
+	box, err := secret.NewBox(base64.StdEncoding.EncodeToString([]byte(strings.Repeat(fill, secret.KeySize))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return box
+}
+
+func withSalary(t *testing.T, box SalaryBox, id string, s *Salary) FusedCandidate {
+	t.Helper()
+	c := FusedCandidate{PersonID: id}
+	if s != nil {
+		sealed, err := SealSalary(box, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.home.SalarySealed = sealed
+	}
+	return c
+}
+
+func TestFilterBySalary(t *testing.T) {
+	box := testBox(t, "k")
+	rotated := testBox(t, "r")
+	candidates := []FusedCandidate{
+		withSalary(t, box, "in", &Salary{Min: 90000, Max: 110000, Currency: "EUR", Period: "year"}),
+		withSalary(t, box, "monthly-in", &Salary{Min: 8000, Max: 8000, Currency: "EUR", Period: "month"}),
+		withSalary(t, box, "above", &Salary{Min: 150000, Max: 170000, Currency: "EUR", Period: "year"}),
+		withSalary(t, box, "below", &Salary{Min: 40000, Max: 50000, Currency: "EUR", Period: "year"}),
+		withSalary(t, box, "usd", &Salary{Min: 500000, Max: 500000, Currency: "USD", Period: "year"}),
+		withSalary(t, rotated, "old-key", &Salary{Min: 500000, Max: 500000, Currency: "EUR", Period: "year"}),
+		withSalary(t, box, "none", nil),
+	}
+	tests := []struct {
+		name       string
+		band       SalaryBand
+		want       []string
+		wantMarked []string // SalaryInBand set
+	}{
+		{
+			name:       "yearly band",
+			band:       SalaryBand{Min: 80000, Max: 120000, Currency: "EUR", Period: "year"},
+			want:       []string{"in", "monthly-in", "usd", "old-key", "none"},
+			wantMarked: []string{"in", "monthly-in"},
+		},
+		{
+			name:       "monthly band",
+			band:       SalaryBand{Min: 7000, Max: 9000, Currency: "EUR", Period: "month"},
+			want:       []string{"in", "monthly-in", "usd", "old-key", "none"},
+			wantMarked: []string{"in", "monthly-in"},
+		},
+		{
+			name:       "minimum only",
+			band:       SalaryBand{Min: 140000, Currency: "EUR", Period: "year"},
+			want:       []string{"above", "usd", "old-key", "none"},
+			wantMarked: []string{"above"},
+		},
+		{
+			name:       "maximum only",
+			band:       SalaryBand{Max: 60000, Currency: "EUR", Period: "year"},
+			want:       []string{"below", "usd", "old-key", "none"},
+			wantMarked: []string{"below"},
+		},
+		{
+			name:       "other currency",
+			band:       SalaryBand{Min: 400000, Currency: "USD", Period: "year"},
+			want:       []string{"in", "monthly-in", "above", "below", "usd", "old-key", "none"},
+			wantMarked: []string{"usd"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			band := tt.band
+			band.Box = box
+			got := filterBySalary(candidates, &band)
+			if ids := personIDs(got); !slices.Equal(ids, tt.want) {
+				t.Errorf("filterBySalary = %v, want %v", ids, tt.want)
+			}
+			var marked []string
+			for _, c := range got {
+				if c.SalaryInBand != nil {
+					if !*c.SalaryInBand {
+						t.Errorf("%s: SalaryInBand = false on a kept candidate", c.PersonID)
+					}
+					marked = append(marked, c.PersonID)
+				}
+			}
+			if !slices.Equal(marked, tt.wantMarked) {
+				t.Errorf("marked in band = %v, want %v", marked, tt.wantMarked)
+			}
+		})
+	}
+}
+
+func TestSalaryBandMatchesOpenFailure(t *testing.T) {
+	c := withSalary(t, testBox(t, "r"), "old-key", &Salary{Min: 1, Max: 1, Currency: "EUR", Period: "year"})
+	band := SalaryBand{Min: 100, Currency: "EUR", Period: "year", Box: testBox(t, "k")}
+	ok, compared, err := band.Matches(c.home)
+	if !ok || compared || err == nil {
+		t.Errorf("Matches = %v, %v, %v; want true, false, an error", ok, compared, err)
+	}
+}
@@ -0,0 +1,143 @@
+package graphrag
+
+import (
+	"context"
+	"log"
+	"sort"
+)
+
+// ─── Search communities ──────────────────────────────────────────────────────
+//
+// The community stages of a hybrid search, after the post-fusion filters:
+// which communities the query is about (findQueryCommunities, else the
+// keyword patterns), narrowing to their members when the skill filter
+// didn't, and ranking by interview outcome and community score.
+
+// findQueryCommunities returns the detected communities a query is about,
+// nil when none is found:
+//  1. embedding similarity against graph_communities.embedding (populated
+//     by detect_communities)
+//  2. the same for the criteria's embedding (EmbedCriteria): positions and
+//     skills without the query's noise
+//  3. the LLM-extracted positions' titles
+func (h *HybridSearchEngine) findQueryCommunities(ctx context.Context, queryEmbedding []float32, criteria *SearchCriteria) []string {
+	if queryEmbedding != nil {
+		communities, err := h.embeddingService.FindCommunitiesByEmbedding(ctx, queryEmbedding, 2)
+		if err != nil {
+			log.Printf("[HybridSearch] FindCommunitiesByEmbedding failed (non-fatal): %v", err)
+		} else if len(communities) > 0 {
+			log.Printf("[HybridSearch] Communities from embedding similarity: %v", communities)
+			return communities
+		}
+	}
+	if criteria == nil {
+		return nil
+	}
+	if criteria.embedding != nil && ctx.Err() == nil {
+		communities, err := h.embeddingService.FindCommunitiesByEmbedding(ctx, criteria.embedding, 2)
+		if err != nil {
+			log.Printf("[HybridSearch] FindCommunitiesByEmbedding(criteria) failed (non-fatal): %v", err)
+		} else if len(communities) > 0 {
+			log.Printf("[HybridSearch] Communities from criteria embedding similarity: %v", communities)
+			return communities
+		}
+	}
+	if len(criteria.Positions) > 0 && ctx.Err() == nil {
+		communities, err := h.embeddingService.FindCommunitiesByPositionTitles(ctx, criteria.Positions)
+		if err != nil {
+			log.Printf("[HybridSearch] FindCommunitiesByPositionTitles failed (non-fatal): %v", err)
+		} else if len(communities) > 0 {
+			log.Printf("[HybridSearch] Communities from position title DB lookup %v: %v", criteria.Positions, communities)
+			return communities
+		}
+	}
+	return nil
+}
+
+// filterByCommunities keeps the candidates in any of communities
+// (Microsoft GraphRAG approach); when none is, it keeps them all.
+func filterByCommunities(candidates []FusedCandidate, communities []string) []FusedCandidate {
+	wanted := make(map[string]bool, len(communities))
+	for _, c := range communities {
+		wanted[c] = true
+	}
+	kept := make([]FusedCandidate, 0, len(candidates))
+	for _, c := range candidates {
+		for _, cc := range c.Communities {
+			if wanted[cc] {
+				kept = append(kept, c)
+				break
+			}
+		}
+	}
+	if len(kept) == 0 {
+		log.Printf("[HybridSearch] Community filter matched 0 candidates, keeping all")
+		return candidates
+	}
+	log.Printf("[HybridSearch] Community filter reduced candidates from %d to %d", len(candidates), len(kept))
+	return kept
+}
+
+// applyInterviewOutcomes moves each candidate's FusionScore by their most
+// recent interview's outcome: +0.05 passed (at most 1), -0.10 failed (at
+// least 0).
+func applyInterviewOutcomes(candidates []FusedCandidate) {
+	for i := range candidates {
+		if len(candidates[i].Interviews) == 0 {
+			continue
+		}
+		// Interviews are ordered DESC by date; first entry is the most recent
+		switch candidates[i].Interviews[0].Outcome {
+		case "passed":
+			candidates[i].FusionScore = min(1.0, candidates[i].FusionScore+0.05)
+		case "failed":
+			candidates[i].FusionScore = max(0.0, candidates[i].FusionScore-0.10)
+		}
+	}
+}
+
+// boostByCommunityScores scales each candidate's FusionScore by their best
+// score in communities and re-sorts them:
+// FusionScore *= (0.25 + 0.75 * bestMatchingCommunityScore), so a perfect
+// match (1.0) keeps it and no match (0) quarters it.
+func boostByCommunityScores(candidates []FusedCandidate, communities []string) {
+	for i := range candidates {
+		bestScore := 0.0
+		for _, qc := range communities {
+			if s, ok := candidates[i].CommunityScores[qc]; ok && s > bestScore {
+				bestScore = s
+			}
+		}
+		candidates[i].FusionScore *= 0.25 + 0.75*bestScore
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].FusionScore > candidates[j].FusionScore
+	})
+}
+
+// queryCommunityLimit is how many community summaries a search gives the
+// reranker as context.
+const queryCommunityLimit = 3
+
+// fetchQueryCommunities finds the most relevant graph-computed communities for a query
+// by centroid and summary similarity (see rankCommunities). Returns community summaries
+// to use as global LLM context.
+func (h *HybridSearchEngine) fetchQueryCommunities(ctx context.Context, embedding []float32) []string {
+	if len(embedding) == 0 {
+		return nil
+	}
+
+	communities, err := rankCommunities(ctx, h.db, embedding, 0, queryCommunityLimit)
+	if err != nil {
+		log.Printf("[HybridSearch] fetchQueryCommunities failed (non-fatal): %v", err)
+		return nil
+	}
+
+	var summaries []string
+	for _, c := range communities {
+		if c.Summary != "" {
+			summaries = append(summaries, c.Summary)
+		}
+	}
+	return summaries
+}
@@ -0,0 +1,99 @@
+package graphrag
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestFilterByCommunities(t *testing.T) {
+	candidates := []FusedCandidate{
+		{PersonID: "a", Communities: []string{"backend", "devops"}},
+		{PersonID: "b", Communities: []string{"frontend"}},
+		{PersonID: "c"},
+	}
+	tests := []struct {
+		name        string
+		communities []string
+		want        []string
+	}{
+		{"one", []string{"backend"}, []string{"a"}},
+		{"any", []string{"devops", "frontend"}, []string{"a", "b"}},
+		{"no match keeps all", []string{"data"}, []string{"a", "b", "c"}},
+		{"none asked keeps all", nil, []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := personIDs(filterByCommunities(candidates, tt.communities))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("filterByCommunities = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyInterviewOutcomes(t *testing.T) {
+	tests := []struct {
+		name       string
+		score      float64
+		interviews []InterviewContext
+		want       float64
+	}{
+		{"no interviews", 0.5, nil, 0.5},
+		{"passed", 0.5, []InterviewContext{{Outcome: "passed"}}, 0.55},
+		{"passed caps at 1", 0.98, []InterviewContext{{Outcome: "passed"}}, 1},
+		{"failed", 0.5, []InterviewContext{{Outcome: "failed"}}, 0.4},
+		{"failed floors at 0", 0.05, []InterviewContext{{Outcome: "failed"}}, 0},
+		{"pending", 0.5, []InterviewContext{{Outcome: "pending"}}, 0.5},
+		{"most recent counts", 0.5, []InterviewContext{{Outcome: "failed"}, {Outcome: "passed"}}, 0.4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidates := []FusedCandidate{{FusionScore: tt.score, Interviews: tt.interviews}}
+			applyInterviewOutcomes(candidates)
+			if got := candidates[0].FusionScore; math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("FusionScore = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBoostByCommunityScores(t *testing.T) {
+	tests := []struct {
+		name        string
+		communities []string
+		wantIDs     []string
+		wantScores  []float64
+	}{
+		{
+			name:        "best score wins",
+			communities: []string{"backend", "data"},
+			wantIDs:     []string{"a", "b", "c"},
+			wantScores:  []float64{0.8, 0.25 + 0.75*0.5, 0.25},
+		},
+		{
+			name:        "re-sorts",
+			communities: []string{"data"},
+			wantIDs:     []string{"b", "c", "a"},
+			wantScores:  []float64{0.25 + 0.75*0.5, 0.25, 0.25 * 0.8},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidates := []FusedCandidate{
+				{PersonID: "a", FusionScore: 0.8, CommunityScores: map[string]float64{"backend": 1}},
+				{PersonID: "b", FusionScore: 1, CommunityScores: map[string]float64{"backend": 0.2, "data": 0.5}},
+				{PersonID: "c", FusionScore: 1},
+			}
+			boostByCommunityScores(candidates, tt.communities)
+			if ids := personIDs(candidates); !slices.Equal(ids, tt.wantIDs) {
+				t.Fatalf("order = %v, want %v", ids, tt.wantIDs)
+			}
+			for i, c := range candidates {
+				if math.Abs(c.FusionScore-tt.wantScores[i]) > 1e-9 {
+					t.Errorf("%s: FusionScore = %v, want %v", c.PersonID, c.FusionScore, tt.wantScores[i])
+				}
+			}
+		})
+	}
+}
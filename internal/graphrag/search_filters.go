@@ -0,0 +1,88 @@
+package graphrag
+
+import (
+	"log"
+)
+
+// ─── Post-fusion filters ─────────────────────────────────────────────────────
+//
+// After fusion and enrichment a hybrid search narrows its candidates: the
+// skill filter drops vector noise, the caller's explicit constraints (tags,
+// location: locations.go, salary band: salary.go, query syntax:
+// query_syntax.go) drop whoever fails them. Each stage is a function of the
+// candidates, so it can be tested without the retrieval around it.
+
+// filterBySkills is the post-fusion skill filter: it keeps the candidates
+// with a skill whose name key (aliasKey) is in keys. When none has one it
+// keeps them all rather than answer nothing. active reports whether it
+// dropped anyone.
+func filterBySkills(candidates []FusedCandidate, keys map[string]bool) (kept []FusedCandidate, active bool) {
+	kept = make([]FusedCandidate, 0, len(candidates))
+	for _, c := range candidates {
+		for _, sk := range c.Skills {
+			if keys[aliasKey(sk.Name)] {
+				kept = append(kept, c)
+				break
+			}
+		}
+	}
+	if len(kept) == 0 {
+		log.Printf("[HybridSearch] Skill post-filter matched 0 candidates, skipping filter")
+		return candidates, false
+	}
+	log.Printf("[HybridSearch] Skill post-filter: %d → %d candidates (kept skill-relevant only)",
+		len(candidates), len(kept))
+	return kept, len(kept) < len(candidates)
+}
+
+// filterByTags is the tag filter: unlike the skill filter it is the
+// caller's explicit constraint, so it applies even when nothing is left.
+func filterByTags(candidates []FusedCandidate, require, exclude []string) []FusedCandidate {
+	kept := make([]FusedCandidate, 0, len(candidates))
+	for _, c := range candidates {
+		if matchesTags(c.Tags, require, exclude) {
+			kept = append(kept, c)
+		}
+	}
+	log.Printf("[HybridSearch] Tag filter (require=%v, exclude=%v): %d → %d candidates",
+		require, exclude, len(candidates), len(kept))
+	return kept
+}
+
+// matchesTags reports whether a candidate with tags carries every required
+// tag and no excluded one.
+func matchesTags(tags, require, exclude []string) bool {
+	has := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		has[t] = true
+	}
+	for _, t := range require {
+		if !has[t] {
+			return false
+		}
+	}
+	for _, t := range exclude {
+		if has[t] {
+			return false
+		}
+	}
+	return true
+}
+
+// applyTagBoosts multiplies each candidate's LLMScore by the boost of every
+// tag it carries and reports whether any score changed.
+func applyTagBoosts(candidates []FusedCandidate, boosts map[string]float64) bool {
+	if len(boosts) == 0 {
+		return false
+	}
+	changed := false
+	for i := range candidates {
+		for _, t := range candidates[i].Tags {
+			if f, ok := boosts[t]; ok && f != 1 {
+				candidates[i].LLMScore *= f
+				changed = true
+			}
+		}
+	}
+	return changed
+}
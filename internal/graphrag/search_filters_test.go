@@ -0,0 +1,113 @@
+package graphrag
+
+import (
+	"slices"
+	"testing"
+)
+
+// personIDs returns the PersonIDs of candidates, in order.
+func personIDs(candidates []FusedCandidate) []string {
+	ids := make([]string, len(candidates))
+	for i, c := range candidates {
+		ids[i] = c.PersonID
+	}
+	return ids
+}
+
+func withSkills(id string, skills ...string) FusedCandidate {
+	c := FusedCandidate{PersonID: id}
+	for _, s := range skills {
+		c.Skills = append(c.Skills, SkillNode{Name: s})
+	}
+	return c
+}
+
+func TestFilterBySkills(t *testing.T) {
+	candidates := []FusedCandidate{
+		withSkills("go", "Go", "Docker"),
+		withSkills("node", "Node.js"),
+		withSkills("java", "Java"),
+		withSkills("none"),
+	}
+	tests := []struct {
+		name       string
+		keys       map[string]bool
+		want       []string
+		wantActive bool
+	}{
+		{"one skill", map[string]bool{"go": true}, []string{"go"}, true},
+		{"by name key", map[string]bool{aliasKey("NodeJS"): true}, []string{"node"}, true},
+		{"any skill", map[string]bool{"go": true, "java": true}, []string{"go", "java"}, true},
+		{"no match keeps all", map[string]bool{"rust": true}, []string{"go", "node", "java", "none"}, false},
+		{"all match", map[string]bool{"go": true, "nodejs": true, "java": true}, []string{"go", "node", "java"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, active := filterBySkills(candidates, tt.keys)
+			if ids := personIDs(got); !slices.Equal(ids, tt.want) || active != tt.wantActive {
+				t.Errorf("filterBySkills = %v, %v; want %v, %v", ids, active, tt.want, tt.wantActive)
+			}
+		})
+	}
+
+	t.Run("everyone kept is not active", func(t *testing.T) {
+		got, active := filterBySkills(candidates[:1], map[string]bool{"go": true})
+		if len(got) != 1 || active {
+			t.Errorf("filterBySkills = %v, %v; want [go], false", personIDs(got), active)
+		}
+	})
+}
+
+func TestFilterByTags(t *testing.T) {
+	candidates := []FusedCandidate{
+		{PersonID: "a", Tags: []string{"shortlisted", "remote"}},
+		{PersonID: "b", Tags: []string{"shortlisted", "do-not-contact"}},
+		{PersonID: "c"},
+	}
+	tests := []struct {
+		name             string
+		require, exclude []string
+		want             []string
+	}{
+		{"require", []string{"shortlisted"}, nil, []string{"a", "b"}},
+		{"require all", []string{"shortlisted", "remote"}, nil, []string{"a"}},
+		{"exclude", nil, []string{"do-not-contact"}, []string{"a", "c"}},
+		{"both", []string{"shortlisted"}, []string{"do-not-contact"}, []string{"a"}},
+		{"nothing left", []string{"hired"}, nil, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := personIDs(filterByTags(candidates, tt.require, tt.exclude))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("filterByTags = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyTagBoosts(t *testing.T) {
+	tests := []struct {
+		name        string
+		tags        []string
+		boosts      map[string]float64
+		wantScore   float64
+		wantChanged bool
+	}{
+		{"no boosts", []string{"a"}, nil, 50, false},
+		{"boost", []string{"a"}, map[string]float64{"a": 1.2}, 60, true},
+		{"demote", []string{"a"}, map[string]float64{"a": 0.5}, 25, true},
+		{"every tag", []string{"a", "b"}, map[string]float64{"a": 2, "b": 0.5}, 50, true},
+		{"factor 1", []string{"a"}, map[string]float64{"a": 1}, 50, false},
+		{"other tag", []string{"a"}, map[string]float64{"b": 2}, 50, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidates := []FusedCandidate{{Tags: tt.tags, LLMScore: 50}}
+			changed := applyTagBoosts(candidates, tt.boosts)
+			if candidates[0].LLMScore != tt.wantScore || changed != tt.wantChanged {
+				t.Errorf("applyTagBoosts: score %v, changed %v; want %v, %v",
+					candidates[0].LLMScore, changed, tt.wantScore, tt.wantChanged)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package graphrag
+
+import (
+	"time"
+
+	"cv-search/internal/cv"
+)
+
+// ─── Search plans ────────────────────────────────────────────────────────────
+//
+// PlanSearch works out what a hybrid search would do without running it: how
+// many candidates each stage handles, the LLM calls it makes and their
+// tokens, and its stage budgets. Prompt tokens of the fixed parts are
+// measured (cv.EstimateTokens); candidates and answers count at typical
+// sizes. A semantic or rerank cache hit makes a search cheaper than planned.
+
+// Typical token sizes of what a plan can't measure before the search runs.
+const (
+	plannedCandidatePromptTokens     = 120 // a candidate's block in the scoring prompt
+	plannedCandidateCompletionTokens = 70  // a candidate's score, reasoning and evidence
+	plannedRerankSummaryTokens       = 30  // the scoring answer's summary
+	plannedCommunitySummaryTokens    = 80  // a community summary given as context
+	plannedAnalysisCompletionTokens  = 150 // the extracted criteria
+)
+
+// PlannedSource is a retrieval source of a planned search.
+type PlannedSource struct {
+	Name      string  `json:"name"`
+	Weight    float64 `json:"weight"`
+	Limit     int     `json:"limit"` // most candidates it returns
+	TimeoutMS int64   `json:"timeout_ms"`
+}
+
+// PlannedLLMCall is an LLM request a planned search makes.
+type PlannedLLMCall struct {
+	Stage            string `json:"stage"`                // StageAnalysis or StageRerank
+	Candidates       int    `json:"candidates,omitempty"` // rerank: candidates scored
+	PromptTokens     int    `json:"prompt_tokens"`
+	CompletionTokens int    `json:"completion_tokens"`
+}
+
+// SearchPlan is what a hybrid search would do, see PlanSearch.
+type SearchPlan struct {
+	EmbeddingCalls int             `json:"embedding_calls"` // of the query, and of its criteria unless cached
+	Sources        []PlannedSource `json:"sources"`
+	FusionPool     int             `json:"fusion_pool"` // most distinct candidates retrieval can bring
+
+	// RerankCandidates is how many candidates are reranked: FinalTopN, or
+	// the fusion pool without one. A query whose skills filter the pool
+	// bypasses FinalTopN and sends up to RerankCandidatesMax.
+	RerankCandidates    int `json:"rerank_candidates"`
+	RerankCandidatesMax int `json:"rerank_candidates_max"`
+
+	LLMCalls            []PlannedLLMCall `json:"llm_calls"` // the rerank call with RerankCandidates
+	PromptTokens        int              `json:"prompt_tokens"`
+	CompletionTokens    int              `json:"completion_tokens"`
+	MaxPromptTokens     int              `json:"max_prompt_tokens"` // with RerankCandidatesMax reranked
+	MaxCompletionTokens int              `json:"max_completion_tokens"`
+
+	// BudgetMS is the longest the search can take after embedding the
+	// query: its stage budgets summed, 0 when a stage has none.
+	BudgetMS int64 `json:"budget_ms"`
+}
+
+// PlanSearch returns what SearchWithDiagnostics would do for query under
+// config, without calling a provider or the database.
+func (h *HybridSearchEngine) PlanSearch(query string, config HybridSearchConfig) *SearchPlan {
+	plan := &SearchPlan{
+		EmbeddingCalls: 2,
+		Sources: []PlannedSource{
+			{Name: SourceBM25, Weight: config.BM25Weight, Limit: config.TopK, TimeoutMS: sourceTimeout(config.BM25Timeout).Milliseconds()},
+			{Name: SourceVector, Weight: config.VectorWeight, Limit: config.TopK, TimeoutMS: sourceTimeout(config.VectorTimeout).Milliseconds()},
+			{Name: SourceGraph, Weight: config.GraphWeight, Limit: graphQueryLimit, TimeoutMS: sourceTimeout(config.GraphTimeout).Milliseconds()},
+		},
+	}
+	for _, src := range plan.Sources {
+		plan.FusionPool += src.Limit
+	}
+
+	// The graph source starts with query analysis, whatever the reranker.
+	text := query
+	if config.Syntax != nil {
+		text = config.Syntax.Text
+	}
+	plan.LLMCalls = append(plan.LLMCalls, PlannedLLMCall{
+		Stage:            StageAnalysis,
+		PromptTokens:     cv.EstimateTokens(analysisPrompt(text)),
+		CompletionTokens: plannedAnalysisCompletionTokens,
+	})
+	plan.PromptTokens = plan.LLMCalls[0].PromptTokens
+	plan.CompletionTokens = plan.LLMCalls[0].CompletionTokens
+	plan.MaxPromptTokens, plan.MaxCompletionTokens = plan.PromptTokens, plan.CompletionTokens
+
+	if config.Reranker != RerankerNone {
+		plan.RerankCandidatesMax = plan.FusionPool
+		plan.RerankCandidates = plan.FusionPool
+		if config.FinalTopN > 0 && config.FinalTopN < plan.FusionPool {
+			plan.RerankCandidates = config.FinalTopN
+		}
+		base := cv.EstimateTokens(h.scorer.buildScoringPrompt(query, nil, nil, config.ScoringInstructions)) +
+			queryCommunityLimit*plannedCommunitySummaryTokens
+		prompt := func(n int) int { return base + n*plannedCandidatePromptTokens }
+		completion := func(n int) int { return plannedRerankSummaryTokens + n*plannedCandidateCompletionTokens }
+
+		call := PlannedLLMCall{
+			Stage:            StageRerank,
+			Candidates:       plan.RerankCandidates,
+			PromptTokens:     prompt(plan.RerankCandidates),
+			CompletionTokens: completion(plan.RerankCandidates),
+		}
+		plan.LLMCalls = append(plan.LLMCalls, call)
+		plan.PromptTokens += call.PromptTokens
+		plan.CompletionTokens += call.CompletionTokens
+		plan.MaxPromptTokens += prompt(plan.RerankCandidatesMax)
+		plan.MaxCompletionTokens += completion(plan.RerankCandidatesMax)
+	}
+
+	plan.BudgetMS = searchBudget(config).Milliseconds()
+	return plan
+}
+
+// sourceTimeout is the deadline runSource gives a retrieval source.
+func sourceTimeout(timeout time.Duration) time.Duration {
+	if timeout <= 0 {
+		return defaultSourceTimeout
+	}
+	return timeout
+}
+
+// searchBudget sums config's stage budgets: retrieval (its budget, or the
+// slowest source's deadline if sooner), fusion and reranking. 0 when fusion
+// or reranking has no budget.
+func searchBudget(config HybridSearchConfig) time.Duration {
+	retrieval := max(sourceTimeout(config.BM25Timeout), sourceTimeout(config.VectorTimeout), sourceTimeout(config.GraphTimeout))
+	if config.RetrievalTimeout > 0 {
+		retrieval = min(retrieval, config.RetrievalTimeout)
+	}
+	if config.FusionTimeout <= 0 {
+		return 0
+	}
+	budget := retrieval + config.FusionTimeout
+	if config.Reranker != RerankerNone {
+		if config.RerankTimeout <= 0 {
+			return 0
+		}
+		budget += config.RerankTimeout
+	}
+	return budget
+}
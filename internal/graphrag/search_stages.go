@@ -0,0 +1,159 @@
+package graphrag
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Retrieval source names used in SearchDiagnostics.
+const (
+	SourceBM25   = "bm25"
+	SourceVector = "vector"
+	SourceGraph  = "graph"
+)
+
+// Pipeline stage names used in SearchDiagnostics. Analysis is the LLM
+// criteria extraction the graph source starts with; retrieval is the wall
+// time of the three parallel sources; fusion covers everything between
+// retrieval and reranking (RRF, enrichment, filters, community context).
+const (
+	StageEmbedding = "embedding"
+	StageAnalysis  = "analysis"
+	StageRetrieval = "retrieval"
+	StageFusion    = "fusion"
+	StageRerank    = "rerank"
+)
+
+// SearchDiagnostics describes how a hybrid search was served: which sources
+// degraded and how long each source and pipeline stage took.
+type SearchDiagnostics struct {
+	Warnings         []string                 // e.g. "graph source timed out after 20s"
+	SourceLatencies  map[string]time.Duration // per retrieval source
+	StageLatencies   map[string]time.Duration // per stage that ran (a cache hit stops after embedding)
+	SemanticCacheHit bool
+}
+
+// SearchProgress is a step of a running hybrid search, reported to the
+// ProgressFunc in the search's context so a UI can show where a 30s+ search
+// is (retrieval, fusion, rerank batches) instead of a spinner.
+type SearchProgress struct {
+	Stage   string        // StageEmbedding, StageAnalysis, StageRetrieval, StageFusion or StageRerank
+	Source  string        // retrieval source that finished ("" for stage events)
+	Done    bool          // the stage (or source) finished; false when it starts
+	Count   int           // candidates it produced, or is scoring
+	Batch   int           // rerank batch being scored, 1-based
+	Batches int           // rerank batches in total
+	Elapsed time.Duration // since the search started
+	Message string        // e.g. "semantic cache hit", or the error of a failed source
+}
+
+// ProgressFunc receives SearchProgress events. Retrieval sources report
+// from their own goroutines, so it must be safe for concurrent use.
+type ProgressFunc func(SearchProgress)
+
+type progressKey struct{}
+
+// WithProgress returns a context whose hybrid searches report their steps to fn.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, progressReporter{fn: fn, start: time.Now()})
+}
+
+type progressReporter struct {
+	fn    ProgressFunc
+	start time.Time
+}
+
+// reportProgress sends p to the context's ProgressFunc, if any.
+func reportProgress(ctx context.Context, p SearchProgress) {
+	r, ok := ctx.Value(progressKey{}).(progressReporter)
+	if !ok {
+		return
+	}
+	p.Elapsed = time.Since(r.start)
+	r.fn(p)
+}
+
+func (d *SearchDiagnostics) warn(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	log.Printf("[HybridSearch] WARNING: %s", msg)
+	d.Warnings = append(d.Warnings, msg)
+}
+
+// defaultSourceTimeout is a retrieval source's deadline when its config
+// gives none.
+const defaultSourceTimeout = 30 * time.Second
+
+// runSource runs fn under its own deadline. fn's result is discarded if the
+// deadline passes first — some sources (LLM criteria extraction, legacy graph
+// query) don't honour ctx, so we can't rely on them returning promptly.
+func runSource[T any](ctx context.Context, timeout time.Duration, fn func(context.Context) (T, error)) (T, time.Duration, error) {
+	sctx, cancel := context.WithTimeout(ctx, sourceTimeout(timeout))
+	defer cancel()
+
+	type outcome struct {
+		val T
+		err error
+	}
+	done := make(chan outcome, 1) // buffered: a late result must not block the goroutine forever
+	start := time.Now()
+	go func() {
+		v, err := fn(sctx)
+		done <- outcome{v, err}
+	}()
+
+	select {
+	case o := <-done:
+		if o.err == nil && sctx.Err() == context.DeadlineExceeded {
+			o.err = context.DeadlineExceeded
+		}
+		return o.val, time.Since(start), o.err
+	case <-sctx.Done():
+		var zero T
+		return zero, time.Since(start), sctx.Err()
+	}
+}
+
+// runStage runs fn under a pipeline stage's budget (see runSource); a zero
+// budget leaves ctx's own deadline.
+func runStage[T any](ctx context.Context, budget time.Duration, fn func(context.Context) (T, error)) (T, time.Duration, error) {
+	if budget > 0 {
+		return runSource(ctx, budget, fn)
+	}
+	start := time.Now()
+	v, err := fn(ctx)
+	return v, time.Since(start), err
+}
+
+// stageContext is ctx under a pipeline stage's budget (0 = none).
+func stageContext(ctx context.Context, budget time.Duration) (context.Context, context.CancelFunc) {
+	if budget > 0 {
+		return context.WithTimeout(ctx, budget)
+	}
+	return context.WithCancel(ctx)
+}
+
+// vectorSearch is the vector source: it reuses the query's embedding when
+// there is one (saving an embedding call) and embeds text otherwise.
+func (h *HybridSearchEngine) vectorSearch(ctx context.Context, embedding []float32, text string, topK int) ([]VectorSearchResult, error) {
+	var personIDs []string
+	var similarities []float64
+	var err error
+	if embedding != nil {
+		personIDs, similarities, err = h.embeddingService.SimilaritySearchByEmbedding(ctx, embedding, topK)
+	} else {
+		personIDs, similarities, err = h.embeddingService.SimilaritySearch(ctx, text, topK)
+	}
+	if err != nil {
+		return nil, err
+	}
+	results := make([]VectorSearchResult, len(personIDs))
+	for i := range personIDs {
+		results[i] = VectorSearchResult{
+			PersonID:   personIDs[i],
+			Similarity: similarities[i],
+		}
+	}
+	return results, nil
+}
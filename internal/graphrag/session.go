@@ -0,0 +1,123 @@
+package graphrag
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Follow-up actions returned by InterpretFollowUp.
+const (
+	SessionActionSearch = "search" // run a fresh hybrid search with EffectiveQuery
+	SessionActionFilter = "filter" // keep only KeepPersonIDs from the previous results
+)
+
+// SessionCandidate is the compact view of a previous-turn result that the
+// follow-up interpreter sees.
+type SessionCandidate struct {
+	PersonID             string
+	Name                 string
+	CurrentPosition      string
+	Seniority            string
+	TotalExperienceYears int
+	Skills               []string
+	Companies            []string
+}
+
+// FollowUpInterpretation is how the LLM resolved a follow-up query against
+// the session so far.
+type FollowUpInterpretation struct {
+	Action         string   `json:"action"`
+	KeepPersonIDs  []string `json:"keep_person_ids"`
+	EffectiveQuery string   `json:"effective_query"`
+	Explanation    string   `json:"explanation"`
+}
+
+// InterpretFollowUp asks the LLM whether a follow-up narrows the previous
+// result set ("only the ones with AWS", "drop juniors") or needs a new
+// search, and in the latter case rewrites it into a standalone query using
+// the earlier turns. history holds the previous user queries, oldest first.
+//
+// When the LLM fails or returns something unusable, the follow-up falls back
+// to a new search over the concatenated queries.
+func (h *HybridSearchEngine) InterpretFollowUp(ctx context.Context, history []string, previous []SessionCandidate, followUp string) *FollowUpInterpretation {
+	fallback := &FollowUpInterpretation{
+		Action:         SessionActionSearch,
+		EffectiveQuery: strings.TrimSpace(strings.Join(append(append([]string{}, history...), followUp), ". ")),
+		Explanation:    "interpreted as a new search combining the session queries",
+	}
+	if h.llm == nil {
+		return fallback
+	}
+
+	response, err := h.llm.Generate(ctx, buildFollowUpPrompt(history, previous, followUp))
+	if err != nil {
+		log.Printf("[SearchSession] Follow-up interpretation failed, falling back to combined search: %v", err)
+		return fallback
+	}
+	if ctx.Err() != nil {
+		return fallback
+	}
+
+	jsonStr := extractJSON(response)
+	var out FollowUpInterpretation
+	if jsonStr == "" || json.Unmarshal([]byte(jsonStr), &out) != nil {
+		log.Printf("[SearchSession] Unparseable follow-up interpretation, falling back: %s", response)
+		return fallback
+	}
+
+	switch out.Action {
+	case SessionActionFilter:
+		// Drop ids the LLM invented — a filter can only narrow the previous set.
+		known := make(map[string]bool, len(previous))
+		for _, c := range previous {
+			known[c.PersonID] = true
+		}
+		kept := out.KeepPersonIDs[:0]
+		for _, id := range out.KeepPersonIDs {
+			if known[id] {
+				kept = append(kept, id)
+			}
+		}
+		out.KeepPersonIDs = kept
+		out.EffectiveQuery = ""
+	case SessionActionSearch:
+		out.KeepPersonIDs = nil
+		if strings.TrimSpace(out.EffectiveQuery) == "" {
+			out.EffectiveQuery = fallback.EffectiveQuery
+		}
+	default:
+		return fallback
+	}
+	return &out
+}
+
+func buildFollowUpPrompt(history []string, previous []SessionCandidate, followUp string) string {
+	var b strings.Builder
+	b.WriteString("You are refining a candidate search conversation for a recruiter.\n\n")
+	b.WriteString("Previous queries (oldest first):\n")
+	for i, q := range history {
+		b.WriteString(fmt.Sprintf("%d. %s\n", i+1, q))
+	}
+	b.WriteString("\nCurrent results:\n")
+	for i, c := range previous {
+		b.WriteString(fmt.Sprintf("%d. %s [person_id: %s] — %s | Seniority: %s | Experience: %d yrs\n",
+			i+1, c.Name, c.PersonID, c.CurrentPosition, c.Seniority, c.TotalExperienceYears))
+		if len(c.Skills) > 0 {
+			b.WriteString("   Skills: " + strings.Join(c.Skills, ", ") + "\n")
+		}
+		if len(c.Companies) > 0 {
+			b.WriteString("   Companies: " + strings.Join(c.Companies, ", ") + "\n")
+		}
+	}
+	b.WriteString(fmt.Sprintf("\nFollow-up: %q\n\n", followUp))
+	b.WriteString(`Decide how to apply the follow-up:
+- "filter": the follow-up only narrows or removes from the CURRENT results (e.g. "only the ones with AWS", "drop juniors"). List the person_ids to keep, in their current order.
+- "search": the follow-up asks for people who may not be in the current results (e.g. "also show Python developers", "what about Istanbul?"). Rewrite it as ONE standalone search query that carries over the still-relevant constraints from previous queries.
+
+Return ONLY valid JSON, no markdown:
+{"action": "filter|search", "keep_person_ids": ["person_..."], "effective_query": "standalone query (search only)", "explanation": "one sentence"}`)
+	return b.String()
+}
@@ -0,0 +1,96 @@
+package graphrag
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+
+	"cv-search/internal/tenant"
+)
+
+// ─── Result snippets ─────────────────────────────────────────────────────────
+//
+// A snippet shows where a search's terms matched in a candidate's latest
+// parsed CV, so a result can be checked without opening the CV: a few
+// ts_headline fragments, parsed with the BM25 text search config. The CV
+// text isn't folded like search_vector, so the query is matched both folded
+// ("yazilim") and as typed, lower-cased ("yazılım").
+
+// snippetOptions are the ts_headline options of a snippet. Matches are
+// marked with control characters, swapped for <mark> once the text around
+// them is HTML-escaped.
+const snippetOptions = "MaxFragments=2, MaxWords=18, MinWords=6, FragmentDelimiter=\" … \", StartSel=\x02, StopSel=\x03"
+
+// Snippets returns, per person node ID of personIDs, where query's terms
+// match in the candidate's latest parsed CV as HTML: the CV's text escaped,
+// matched terms in <mark>. People without a match are left out.
+func (b *BM25Searcher) Snippets(ctx context.Context, query string, personIDs []string) (map[string]string, error) {
+	if syntax, err := ParseQuerySyntax(query); err == nil && syntax != nil {
+		query = syntax.Text // not NOT's terms, nor the operators
+	}
+	folded, typed := prepareTSQuery(query), tsQueryTerms(strings.ToLower(query))
+	if folded == "" || len(personIDs) == 0 {
+		return nil, nil
+	}
+
+	rows, err := b.db.QueryContext(ctx, `
+		SELECT gn.node_id, ts_headline($1::regconfig, f.parsed_text, q.query, $2)
+		FROM graph_nodes gn
+		JOIN candidates c ON c.graph_node_id = gn.id AND c.deleted_at IS NULL
+		JOIN LATERAL (
+			SELECT parsed_text FROM cv_files
+			WHERE candidate_id = c.id AND COALESCE(parsed_text, '') <> ''
+			ORDER BY uploaded_at DESC
+			LIMIT 1
+		) f ON TRUE
+		CROSS JOIN (SELECT websearch_to_tsquery($1::regconfig, $3) || websearch_to_tsquery($1::regconfig, $4)) AS q(query)
+		WHERE gn.node_id = ANY($5) AND gn.org_id = $6
+	`, b.tsConfig, snippetOptions, folded, typed, personIDs, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("snippets: %w", err)
+	}
+	defer rows.Close()
+
+	snippets := make(map[string]string, len(personIDs))
+	for rows.Next() {
+		var personID, headline string
+		if err := rows.Scan(&personID, &headline); err != nil {
+			return nil, fmt.Errorf("scan snippet: %w", err)
+		}
+		// Without a match ts_headline gives the CV's first words instead.
+		if strings.ContainsRune(headline, '\x02') {
+			snippets[personID] = markSnippet(headline)
+		}
+	}
+	return snippets, rows.Err()
+}
+
+// markSnippet returns a ts_headline result of snippetOptions as HTML, its
+// whitespace collapsed.
+func markSnippet(headline string) string {
+	headline = strings.Join(strings.Fields(headline), " ")
+	var b strings.Builder
+	inMatch := false
+	for len(headline) > 0 {
+		i := strings.IndexAny(headline, "\x02\x03")
+		if i < 0 {
+			b.WriteString(html.EscapeString(headline))
+			break
+		}
+		b.WriteString(html.EscapeString(headline[:i]))
+		switch {
+		case headline[i] == '\x02' && !inMatch:
+			b.WriteString("<mark>")
+			inMatch = true
+		case headline[i] == '\x03' && inMatch:
+			b.WriteString("</mark>")
+			inMatch = false
+		}
+		headline = headline[i+1:]
+	}
+	if inMatch {
+		b.WriteString("</mark>")
+	}
+	return b.String()
+}
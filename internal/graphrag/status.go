@@ -0,0 +1,78 @@
+package graphrag
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ─── Candidate status ────────────────────────────────────────────────────────
+//
+// A candidate's lifecycle status lives on its candidates row and is mirrored
+// on its person node's status property (missing = active), which survives
+// graph rebuilds. Searches leave out candidates of ExcludedStatuses unless
+// the caller asks for them with WithIncludedStatuses.
+
+// Candidate statuses.
+const (
+	StatusActive       = "active"
+	StatusHired        = "hired"
+	StatusArchived     = "archived"
+	StatusDoNotContact = "do_not_contact"
+)
+
+// CandidateStatuses are the statuses a candidate can have.
+var CandidateStatuses = []string{StatusActive, StatusHired, StatusArchived, StatusDoNotContact}
+
+// ExcludedStatuses are the statuses searches leave out by default.
+var ExcludedStatuses = []string{StatusHired, StatusArchived, StatusDoNotContact}
+
+type includedStatusesKey struct{}
+
+// WithIncludedStatuses returns ctx under which searches also return
+// candidates of statuses, out of ExcludedStatuses; others are ignored.
+func WithIncludedStatuses(ctx context.Context, statuses []string) context.Context {
+	if len(statuses) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, includedStatusesKey{}, statuses)
+}
+
+// excludedStatuses returns the statuses searches under ctx leave out.
+func excludedStatuses(ctx context.Context) []string {
+	included, _ := ctx.Value(includedStatusesKey{}).([]string)
+	var out []string
+	for _, s := range ExcludedStatuses {
+		if !slices.Contains(included, s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// StatusExcluded reports whether searches under ctx leave out a candidate of
+// status ("" = active).
+func StatusExcluded(ctx context.Context, status string) bool {
+	return status != "" && slices.Contains(excludedStatuses(ctx), status)
+}
+
+// personStatusSQL is the condition that person node alias isn't of a status
+// searches under ctx leave out.
+func personStatusSQL(ctx context.Context, alias string) string {
+	return statusNotInSQL(fmt.Sprintf("COALESCE(%s.properties->>'status', '%s')", alias, StatusActive), excludedStatuses(ctx))
+}
+
+// candidateStatusSQL is personStatusSQL for candidates row alias.
+func candidateStatusSQL(ctx context.Context, alias string) string {
+	return statusNotInSQL(alias+".status", excludedStatuses(ctx))
+}
+
+// statusNotInSQL is the condition that expr is none of statuses, which are
+// constants (ExcludedStatuses), never caller input.
+func statusNotInSQL(expr string, statuses []string) string {
+	if len(statuses) == 0 {
+		return "TRUE"
+	}
+	return fmt.Sprintf("%s NOT IN ('%s')", expr, strings.Join(statuses, "', '"))
+}
@@ -0,0 +1,74 @@
+package graphrag
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// summaryCandidates is how many of the best-ranked candidates the LLM
+// summary is written about.
+const summaryCandidates = 10
+
+// Summarize has the LLM write a short natural-language summary of a search's
+// ranked candidates, for streamed search responses that send the candidates
+// first and the summary when it is ready.
+func (s *LLMSearchEngine) Summarize(ctx context.Context, query string, candidates []LLMRankedCandidate, reasoning string) (string, error) {
+	return summarizeCandidates(ctx, s.llm, query, candidates, reasoning)
+}
+
+// Summarize is LLMSearchEngine.Summarize for the enhanced engine's results.
+func (s *EnhancedSearchEngine) Summarize(ctx context.Context, query string, candidates []LLMRankedCandidate, reasoning string) (string, error) {
+	return summarizeCandidates(ctx, s.llm, query, candidates, reasoning)
+}
+
+// summarizeCandidates writes the summary from the LLM-ranked candidates
+// only; candidates the LLM left unranked aren't matches worth describing.
+func summarizeCandidates(ctx context.Context, llm LLMClient, query string, candidates []LLMRankedCandidate, reasoning string) (string, error) {
+	if rankedByLLMCount(candidates) == 0 {
+		return "No candidates found matching your query.", nil
+	}
+
+	var profiles strings.Builder
+	n := 0
+	for _, c := range candidates {
+		if c.UnrankedByLLM {
+			continue
+		}
+		if n++; n > summaryCandidates {
+			break
+		}
+		fmt.Fprintf(&profiles, "%d. %s — fit: %s", n, c.Name, c.Fit)
+		if c.CurrentPosition != "" {
+			fmt.Fprintf(&profiles, ", %s", c.CurrentPosition)
+		}
+		if len(c.KeyStrengths) > 0 {
+			fmt.Fprintf(&profiles, "; strengths: %s", strings.Join(c.KeyStrengths, ", "))
+		}
+		if c.Reasoning != "" {
+			fmt.Fprintf(&profiles, "\n   %s", c.Reasoning)
+		}
+		profiles.WriteString("\n")
+	}
+
+	prompt := fmt.Sprintf(`You are an expert technical recruiter summarizing candidate search results for a hiring manager.
+
+USER QUERY: "%s"
+
+RANKING NOTES: %s
+
+BEST-RANKED CANDIDATES (%d of %d relevant):
+%s
+Write a summary of 3-5 sentences: how well the candidate pool fits the query, who stands out and why, and any gaps (skills or seniority the query asks for that few candidates have). Write in the language of the query. Return plain text only, no markdown, no lists.`,
+		query, reasoning, min(n, summaryCandidates), rankedByLLMCount(candidates), profiles.String())
+
+	summary, err := llm.Generate(ctx, prompt)
+	if err != nil {
+		return "", fmt.Errorf("LLM summary failed: %w", err)
+	}
+	summary = strings.TrimSpace(summary)
+	if summary == "" {
+		return "", fmt.Errorf("LLM summary failed: empty response")
+	}
+	return summary, nil
+}
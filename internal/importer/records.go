@@ -0,0 +1,303 @@
+// Package importer reads candidate exports from other ATSes (CSV or JSON)
+// into storage.CandidateImport records. It only parses and validates; the
+// API's POST /api/candidates/import creates the candidates and queues their
+// resumes through the normal CV pipeline.
+//
+// Columns / keys are matched case-insensitively, with common aliases:
+//
+//	name        name, full_name, candidate_name (or first_name + last_name)
+//	email       email, email_address, e-mail
+//	phone       phone, phone_number, mobile
+//	location    location, city, address
+//	skills      skills, tags, keywords   — list, or a string split on , ; |
+//	experience  experience, years_of_experience, experience_years, total_experience
+//	resume_url  resume_url, resume, resume_link, cv_url, cv
+//	external_id external_id, id, candidate_id, ats_id
+//
+// Unknown columns are ignored.
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"cv-search/internal/storage"
+)
+
+// Format is the encoding of an export file.
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatJSON Format = "json"
+)
+
+// ErrUnknownFormat is returned when the format can't be told from the
+// caller's hint or the content.
+var ErrUnknownFormat = errors.New("unknown import format (use csv or json)")
+
+// Row is one parsed export row. Err is set when the row can't be imported;
+// Record is then only partially filled.
+type Row struct {
+	Line   int // CSV: line in the file (header = 1); JSON: array index + 1
+	Record storage.CandidateImport
+	Err    error
+}
+
+var fieldAliases = map[string]string{
+	"name": "name", "full_name": "name", "fullname": "name", "candidate_name": "name",
+	"first_name": "first_name", "firstname": "first_name", "given_name": "first_name",
+	"last_name": "last_name", "lastname": "last_name", "surname": "last_name", "family_name": "last_name",
+	"email": "email", "email_address": "email", "e_mail": "email", "mail": "email",
+	"phone": "phone", "phone_number": "phone", "mobile": "phone", "telephone": "phone",
+	"location": "location", "city": "location", "address": "location",
+	"skills": "skills", "tags": "skills", "keywords": "skills",
+	"experience": "experience", "years_of_experience": "experience", "experience_years": "experience", "total_experience": "experience",
+	"resume_url": "resume_url", "resume": "resume_url", "resume_link": "resume_url", "cv_url": "resume_url", "cv": "resume_url",
+	"external_id": "external_id", "id": "external_id", "candidate_id": "external_id", "ats_id": "external_id",
+}
+
+// canonicalField maps a header / key to its field name, or "" if unknown.
+func canonicalField(key string) string {
+	k := strings.ToLower(strings.TrimSpace(key))
+	k = strings.NewReplacer(" ", "_", "-", "_").Replace(k)
+	return fieldAliases[k]
+}
+
+// DetectFormat picks the format from a hint ("csv", "json", a MIME type or a
+// filename) and falls back to sniffing the first non-space byte.
+func DetectFormat(hint string, head []byte) (Format, error) {
+	h := strings.ToLower(hint)
+	switch {
+	case h == "csv" || strings.Contains(h, "text/csv") || strings.HasSuffix(h, ".csv"):
+		return FormatCSV, nil
+	case h == "json" || strings.Contains(h, "application/json") || strings.HasSuffix(h, ".json"):
+		return FormatJSON, nil
+	}
+	trimmed := bytes.TrimLeft(head, " \t\r\n\ufeff")
+	if len(trimmed) == 0 {
+		return "", ErrUnknownFormat
+	}
+	if trimmed[0] == '[' || trimmed[0] == '{' {
+		return FormatJSON, nil
+	}
+	return FormatCSV, nil
+}
+
+// Parse reads every row of an export. maxRows > 0 caps the number of rows;
+// exceeding it is an error rather than a silent truncation. Per-row problems
+// are reported on the Row, not as an error.
+func Parse(r io.Reader, format Format, maxRows int) ([]Row, error) {
+	var raw []rawRow
+	var err error
+	switch format {
+	case FormatCSV:
+		raw, err = readCSV(r)
+	case FormatJSON:
+		raw, err = readJSON(r)
+	default:
+		return nil, ErrUnknownFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	if maxRows > 0 && len(raw) > maxRows {
+		return nil, fmt.Errorf("too many rows: %d (max %d)", len(raw), maxRows)
+	}
+
+	rows := make([]Row, 0, len(raw))
+	for _, rr := range raw {
+		rec, err := toRecord(rr.fields)
+		rows = append(rows, Row{Line: rr.line, Record: rec, Err: err})
+	}
+	return rows, nil
+}
+
+// rawRow is one row's values keyed by canonical field name.
+type rawRow struct {
+	line   int
+	fields map[string]interface{}
+}
+
+func readCSV(r io.Reader) ([]rawRow, error) {
+	br := bufio.NewReader(r)
+	// Excel exports start with a UTF-8 BOM.
+	if b, err := br.Peek(3); err == nil && bytes.Equal(b, []byte("\ufeff")) {
+		br.Discard(3)
+	}
+	cr := csv.NewReader(br)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read csv header: %w", err)
+	}
+	fields := make([]string, len(header))
+	known := 0
+	for i, h := range header {
+		if fields[i] = canonicalField(h); fields[i] != "" {
+			known++
+		}
+	}
+	if known == 0 {
+		return nil, fmt.Errorf("csv header has no known columns (expected e.g. name, email, skills, resume_url)")
+	}
+
+	var out []rawRow
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read csv: %w", err)
+		}
+		m := map[string]interface{}{}
+		empty := true
+		for i, v := range rec {
+			if i >= len(fields) || fields[i] == "" {
+				continue
+			}
+			if v = strings.TrimSpace(v); v != "" {
+				m[fields[i]] = v
+				empty = false
+			}
+		}
+		if !empty {
+			line, _ := cr.FieldPos(0)
+			out = append(out, rawRow{line: line, fields: m})
+		}
+	}
+	return out, nil
+}
+
+func readJSON(r io.Reader) ([]rawRow, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("decode json: %w", err)
+	}
+
+	// Either a bare array or an object wrapping one ({"candidates": [...]}).
+	var items []interface{}
+	switch t := v.(type) {
+	case []interface{}:
+		items = t
+	case map[string]interface{}:
+		for _, key := range []string{"candidates", "data", "items", "results"} {
+			if arr, ok := t[key].([]interface{}); ok {
+				items = arr
+				break
+			}
+		}
+		if items == nil {
+			return nil, fmt.Errorf("json object has no candidates array")
+		}
+	default:
+		return nil, fmt.Errorf("json must be an array of candidates")
+	}
+
+	out := make([]rawRow, 0, len(items))
+	for i, it := range items {
+		obj, ok := it.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("json item %d is not an object", i+1)
+		}
+		m := map[string]interface{}{}
+		for k, val := range obj {
+			if f := canonicalField(k); f != "" && val != nil {
+				m[f] = val
+			}
+		}
+		out = append(out, rawRow{line: i + 1, fields: m})
+	}
+	return out, nil
+}
+
+// toRecord builds a record from canonical fields and validates it.
+func toRecord(m map[string]interface{}) (storage.CandidateImport, error) {
+	rec := storage.CandidateImport{
+		Name:       fieldString(m["name"]),
+		Email:      strings.ToLower(fieldString(m["email"])),
+		Phone:      fieldString(m["phone"]),
+		Location:   fieldString(m["location"]),
+		Skills:     fieldList(m["skills"]),
+		Experience: fieldString(m["experience"]),
+		ResumeURL:  fieldString(m["resume_url"]),
+		ExternalID: fieldString(m["external_id"]),
+	}
+	if rec.Name == "" {
+		rec.Name = strings.TrimSpace(fieldString(m["first_name"]) + " " + fieldString(m["last_name"]))
+	}
+
+	if rec.Name == "" && rec.Email == "" {
+		return rec, errors.New("name or email is required")
+	}
+	if rec.Email != "" {
+		if addr, err := mail.ParseAddress(rec.Email); err != nil || addr.Address != rec.Email {
+			return rec, fmt.Errorf("invalid email %q", rec.Email)
+		}
+	}
+	if rec.Name == "" {
+		rec.Name = rec.Email
+	}
+	if rec.ResumeURL != "" {
+		u, err := url.Parse(rec.ResumeURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return rec, fmt.Errorf("invalid resume_url %q (must be http or https)", rec.ResumeURL)
+		}
+	}
+	return rec, nil
+}
+
+func fieldString(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return strings.TrimSpace(t)
+	case json.Number:
+		return t.String()
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(t)
+	}
+	return ""
+}
+
+func fieldList(v interface{}) []string {
+	var parts []string
+	switch t := v.(type) {
+	case []interface{}:
+		for _, e := range t {
+			parts = append(parts, fieldString(e))
+		}
+	case string:
+		parts = strings.FieldsFunc(t, func(r rune) bool { return r == ',' || r == ';' || r == '|' })
+	}
+
+	seen := map[string]bool{}
+	var out []string
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p == "" || seen[strings.ToLower(p)] {
+			continue
+		}
+		seen[strings.ToLower(p)] = true
+		out = append(out, p)
+	}
+	return out
+}
@@ -0,0 +1,129 @@
+package integrations
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const greenhouseBaseURL = "https://harvest.greenhouse.io/v1"
+
+// greenhouse pushes through the Harvest API (v1). Writes need the
+// On-Behalf-Of header naming a Greenhouse user ID.
+type greenhouse struct {
+	api    apiClient
+	userID int64
+	jobID  int64 // 0 = create a prospect instead of an application
+}
+
+func newGreenhouse(cfg Config, client *http.Client) (*greenhouse, error) {
+	userID, err := strconv.ParseInt(cfg.UserID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("greenhouse: user ID must be a number, got %q", cfg.UserID)
+	}
+	var jobID int64
+	if cfg.JobID != "" {
+		if jobID, err = strconv.ParseInt(cfg.JobID, 10, 64); err != nil {
+			return nil, fmt.Errorf("greenhouse: job ID must be a number, got %q", cfg.JobID)
+		}
+	}
+	base := cfg.BaseURL
+	if base == "" {
+		base = greenhouseBaseURL
+	}
+	return &greenhouse{
+		api: apiClient{
+			target:  TargetGreenhouse,
+			baseURL: strings.TrimRight(base, "/"),
+			apiKey:  cfg.APIKey,
+			header:  http.Header{"On-Behalf-Of": []string{cfg.UserID}},
+			http:    client,
+		},
+		userID: userID,
+		jobID:  jobID,
+	}, nil
+}
+
+func (g *greenhouse) Check(ctx context.Context) error {
+	return g.api.send(ctx, "check credentials", http.MethodGet, fmt.Sprintf("/users/%d", g.userID), "", nil, nil)
+}
+
+type greenhouseValue struct {
+	Value string `json:"value"`
+	Type  string `json:"type"`
+}
+
+func (g *greenhouse) Push(ctx context.Context, c *Candidate) (*Result, error) {
+	first, last := splitName(c.Name)
+	body := map[string]interface{}{
+		"first_name": first,
+		"last_name":  last,
+		"title":      c.CurrentPosition,
+		"tags":       c.Tags,
+	}
+	if c.Email != "" {
+		body["email_addresses"] = []greenhouseValue{{Value: c.Email, Type: "personal"}}
+	}
+	if c.Phone != "" {
+		body["phone_numbers"] = []greenhouseValue{{Value: c.Phone, Type: "mobile"}}
+	}
+	if c.Location != "" {
+		body["addresses"] = []greenhouseValue{{Value: c.Location, Type: "home"}}
+	}
+	if c.LinkedInURL != "" {
+		body["social_media_addresses"] = []map[string]string{{"value": c.LinkedInURL}}
+	}
+
+	// With a job the candidate gets an application on it; without one
+	// Greenhouse only accepts the candidate as a prospect.
+	path := "/prospects"
+	if g.jobID != 0 {
+		path = "/candidates"
+		body["applications"] = []map[string]int64{{"job_id": g.jobID}}
+	}
+	var created struct {
+		ID int64 `json:"id"`
+	}
+	if err := g.api.sendJSON(ctx, "create candidate", http.MethodPost, path, body, &created); err != nil {
+		return nil, err
+	}
+	if created.ID == 0 {
+		return nil, fmt.Errorf("greenhouse create candidate: response has no candidate id")
+	}
+
+	res := &Result{
+		ExternalID: strconv.FormatInt(created.ID, 10),
+		URL:        fmt.Sprintf("https://app.greenhouse.io/people/%d", created.ID),
+	}
+	if c.Resume != nil {
+		err := g.api.sendJSON(ctx, "attach resume", http.MethodPost,
+			fmt.Sprintf("/candidates/%d/attachments", created.ID),
+			map[string]string{
+				"filename":     c.Resume.Filename,
+				"type":         "resume",
+				"content":      base64.StdEncoding.EncodeToString(c.Resume.Data),
+				"content_type": c.Resume.ContentType,
+			}, nil)
+		if err != nil {
+			res.Warnings = append(res.Warnings, err.Error())
+		} else {
+			res.ResumeAttached = true
+		}
+	}
+	err := g.api.sendJSON(ctx, "add note", http.MethodPost,
+		fmt.Sprintf("/candidates/%d/activity_feed/notes", created.ID),
+		map[string]interface{}{
+			"user_id":    g.userID,
+			"body":       noteText(c),
+			"visibility": "public",
+		}, nil)
+	if err != nil {
+		res.Warnings = append(res.Warnings, err.Error())
+	} else {
+		res.NoteAdded = true
+	}
+	return res, nil
+}
@@ -0,0 +1,197 @@
+// Package integrations pushes candidates from cv-search into another ATS.
+// Each target (Greenhouse, Lever) is a Connector built from an
+// organization's settings (organization_integrations); the API's
+// POST /api/candidates/{id}/push?target= fills a Candidate from storage —
+// profile, latest CV file and why the candidate was picked — and hands it to
+// the organization's connector.
+//
+// A push creates the candidate in the ATS, then attaches the resume and adds
+// the match reasoning as a note. Only the create has to succeed; a failed
+// attachment or note is reported in Result.Warnings, since the candidate
+// already exists on the other side by then.
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Push targets.
+const (
+	TargetGreenhouse = "greenhouse"
+	TargetLever      = "lever"
+)
+
+// Targets lists every supported target.
+var Targets = []string{TargetGreenhouse, TargetLever}
+
+// requestTimeout bounds each call to an ATS API.
+const requestTimeout = 30 * time.Second
+
+// Candidate is what a push sends.
+type Candidate struct {
+	Name            string
+	Email           string
+	Phone           string
+	Location        string
+	LinkedInURL     string
+	CurrentPosition string
+	Skills          []string
+	Tags            []string
+	Resume          *Resume // nil = no file attached
+	MatchReasoning  string  // why the candidate was picked; added as a note
+}
+
+// Resume is a CV file to attach.
+type Resume struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Result is the candidate created in the ATS.
+type Result struct {
+	ExternalID     string
+	URL            string // the candidate's page in the ATS, "" if unknown
+	ResumeAttached bool
+	NoteAdded      bool
+	Warnings       []string // steps after the create that failed
+}
+
+// Connector pushes candidates to one ATS account.
+type Connector interface {
+	// Check makes one read-only call with the configured credentials, so a
+	// wrong key or user ID is refused before the settings are saved.
+	Check(ctx context.Context) error
+	Push(ctx context.Context, c *Candidate) (*Result, error)
+}
+
+// Config configures a connector.
+type Config struct {
+	Target string
+	APIKey string
+	// UserID is the ATS user the writes are made as: Greenhouse's
+	// On-Behalf-Of user, Lever's perform_as user.
+	UserID string
+	// JobID optionally files the candidate under a job: a Greenhouse job
+	// (else the candidate is created as a prospect) or a Lever posting.
+	JobID string
+	// BaseURL overrides the vendor's API URL ("" = the vendor's).
+	BaseURL string
+}
+
+// New builds the connector for cfg.Target.
+func New(cfg Config) (Connector, error) {
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("%s: API key is required", cfg.Target)
+	}
+	if cfg.UserID == "" {
+		return nil, fmt.Errorf("%s: user ID is required", cfg.Target)
+	}
+	client := &http.Client{Timeout: requestTimeout}
+	switch cfg.Target {
+	case TargetGreenhouse:
+		return newGreenhouse(cfg, client)
+	case TargetLever:
+		return newLever(cfg, client), nil
+	}
+	return nil, fmt.Errorf("unknown target %q (use %s)", cfg.Target, strings.Join(Targets, " or "))
+}
+
+// APIError is a non-2xx response from an ATS API.
+type APIError struct {
+	Target string
+	Op     string // e.g. "create candidate"
+	Code   int
+	Body   string // start of the response body
+}
+
+func (e *APIError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("%s %s: HTTP %d", e.Target, e.Op, e.Code)
+	}
+	return fmt.Sprintf("%s %s: HTTP %d: %s", e.Target, e.Op, e.Code, e.Body)
+}
+
+// apiClient is the HTTP plumbing the connectors share: Basic auth with the
+// API key as user name, JSON in and out.
+type apiClient struct {
+	target  string
+	baseURL string
+	apiKey  string
+	header  http.Header // sent with every request
+	http    *http.Client
+}
+
+// send makes one request and decodes a JSON response into out (if non-nil).
+func (c *apiClient) send(ctx context.Context, op, method, path, contentType string, body io.Reader, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", c.target, op, err)
+	}
+	req.SetBasicAuth(c.apiKey, "")
+	for k, v := range c.header {
+		req.Header[k] = v
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", c.target, op, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &APIError{Target: c.target, Op: op, Code: resp.StatusCode, Body: strings.TrimSpace(string(snippet))}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%s %s: decode response: %w", c.target, op, err)
+	}
+	return nil
+}
+
+// sendJSON is send with a JSON-encoded body.
+func (c *apiClient) sendJSON(ctx context.Context, op, method, path string, in, out interface{}) error {
+	b, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", c.target, op, err)
+	}
+	return c.send(ctx, op, method, path, "application/json", bytes.NewReader(b), out)
+}
+
+// splitName splits a full name into first and last name at the last space.
+func splitName(name string) (first, last string) {
+	name = strings.Join(strings.Fields(name), " ")
+	if i := strings.LastIndex(name, " "); i > 0 {
+		return name[:i], name[i+1:]
+	}
+	return name, ""
+}
+
+// noteText is the note a push adds: the match reasoning and the profile
+// fields the ATS has no place for.
+func noteText(c *Candidate) string {
+	var b strings.Builder
+	b.WriteString("Pushed from cv-search.")
+	if c.MatchReasoning != "" {
+		b.WriteString("\n\nWhy this candidate: ")
+		b.WriteString(c.MatchReasoning)
+	}
+	if len(c.Skills) > 0 {
+		b.WriteString("\n\nSkills: ")
+		b.WriteString(strings.Join(c.Skills, ", "))
+	}
+	return b.String()
+}
@@ -0,0 +1,127 @@
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strings"
+)
+
+const leverBaseURL = "https://api.lever.co/v1"
+
+// lever pushes through the Lever API (v1). Writes take a perform_as query
+// parameter naming a Lever user ID.
+type lever struct {
+	api    apiClient
+	userID string
+	jobID  string // posting ID, "" = no posting
+}
+
+func newLever(cfg Config, client *http.Client) *lever {
+	base := cfg.BaseURL
+	if base == "" {
+		base = leverBaseURL
+	}
+	return &lever{
+		api: apiClient{
+			target:  TargetLever,
+			baseURL: strings.TrimRight(base, "/"),
+			apiKey:  cfg.APIKey,
+			http:    client,
+		},
+		userID: cfg.UserID,
+		jobID:  cfg.JobID,
+	}
+}
+
+func (l *lever) Check(ctx context.Context) error {
+	return l.api.send(ctx, "check credentials", http.MethodGet, "/users/"+url.PathEscape(l.userID), "", nil, nil)
+}
+
+// performAs is the query string every write carries.
+func (l *lever) performAs() string {
+	return "?perform_as=" + url.QueryEscape(l.userID)
+}
+
+func (l *lever) Push(ctx context.Context, c *Candidate) (*Result, error) {
+	body := map[string]interface{}{
+		"name":     strings.Join(strings.Fields(c.Name), " "),
+		"headline": c.CurrentPosition,
+		"location": c.Location,
+		"tags":     c.Tags,
+		"sources":  []string{"cv-search"},
+		"origin":   "sourced",
+	}
+	if c.Email != "" {
+		body["emails"] = []string{c.Email}
+	}
+	if c.Phone != "" {
+		body["phones"] = []map[string]string{{"value": c.Phone}}
+	}
+	if c.LinkedInURL != "" {
+		body["links"] = []string{c.LinkedInURL}
+	}
+	if l.jobID != "" {
+		body["postings"] = []string{l.jobID}
+	}
+	var created struct {
+		Data struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := l.api.sendJSON(ctx, "create opportunity", http.MethodPost, "/opportunities"+l.performAs(), body, &created); err != nil {
+		return nil, err
+	}
+	id := created.Data.ID
+	if id == "" {
+		return nil, fmt.Errorf("lever create opportunity: response has no opportunity id")
+	}
+
+	res := &Result{
+		ExternalID: id,
+		URL:        "https://hire.lever.co/candidates/" + url.PathEscape(id),
+	}
+	if c.Resume != nil {
+		if err := l.uploadResume(ctx, id, c.Resume); err != nil {
+			res.Warnings = append(res.Warnings, err.Error())
+		} else {
+			res.ResumeAttached = true
+		}
+	}
+	err := l.api.sendJSON(ctx, "add note", http.MethodPost,
+		"/opportunities/"+url.PathEscape(id)+"/notes"+l.performAs(),
+		map[string]string{"value": noteText(c)}, nil)
+	if err != nil {
+		res.Warnings = append(res.Warnings, err.Error())
+	} else {
+		res.NoteAdded = true
+	}
+	return res, nil
+}
+
+// uploadResume sends the file as multipart form data; Lever takes no JSON
+// body for resumes.
+func (l *lever) uploadResume(ctx context.Context, id string, r *Resume) error {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, r.Filename))
+	h.Set("Content-Type", r.ContentType)
+	part, err := mw.CreatePart(h)
+	if err != nil {
+		return fmt.Errorf("lever attach resume: %w", err)
+	}
+	if _, err := part.Write(r.Data); err != nil {
+		return fmt.Errorf("lever attach resume: %w", err)
+	}
+	if err := mw.Close(); err != nil {
+		return fmt.Errorf("lever attach resume: %w", err)
+	}
+	return l.api.send(ctx, "attach resume", http.MethodPost,
+		"/opportunities/"+url.PathEscape(id)+"/resumes"+l.performAs(),
+		mw.FormDataContentType(), &buf, nil)
+}
@@ -3,6 +3,7 @@ package llm
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -229,7 +230,8 @@ func (s *Service) GetGroqBatchStatus(groqBatchID string) (*GroqBatchStatus, erro
 // FetchExtractionBatchResults downloads and parses a completed batch's output
 // file, returning a map of custom_id -> extracted CV data, plus a map of
 // custom_id -> error message for any lines that failed within the batch.
-func (s *Service) FetchExtractionBatchResults(outputFileID string) (map[string]*CVExtraction, map[string]string, error) {
+// The tokens are recorded for ctx's organization.
+func (s *Service) FetchExtractionBatchResults(ctx context.Context, outputFileID string) (map[string]*CVExtraction, map[string]string, error) {
 	if outputFileID == "" {
 		return nil, nil, fmt.Errorf("empty output file id")
 	}
@@ -283,11 +285,13 @@ func (s *Service) FetchExtractionBatchResults(outputFileID string) (map[string]*
 					Content string `json:"content"`
 				} `json:"message"`
 			} `json:"choices"`
+			Usage tokenUsage `json:"usage"`
 		}
 		if err := json.Unmarshal(result.Response.Body, &chatResp); err != nil {
 			errorsByID[result.CustomID] = fmt.Sprintf("failed to parse chat completion body: %v", err)
 			continue
 		}
+		s.record(ctx, chatResp.Usage.PromptTokens, chatResp.Usage.CompletionTokens, true)
 		if len(chatResp.Choices) == 0 {
 			errorsByID[result.CustomID] = "no choices in chat completion"
 			continue
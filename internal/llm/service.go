@@ -8,11 +8,13 @@ import (
 	"io"
 	"log"
 	"net/http"
-	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"golang.org/x/time/rate"
+
+	"cv-search/internal/textnorm"
 )
 
 // Groq call sites have different latency tolerances: an interactive search
@@ -38,11 +40,14 @@ type Service struct {
 	apiKey   string
 	model    string
 	timeout  time.Duration
+	baseURL  string // Ollama server
 
 	// limiter proactively paces outgoing Groq requests so we stay under the
 	// model's published RPM instead of bursting and reacting to 429s after
 	// the fact. nil for non-Groq providers.
 	limiter *rate.Limiter
+
+	recordUsage func(context.Context, Usage) // see SetUsageRecorder
 }
 
 type CVExtraction struct {
@@ -51,7 +56,10 @@ type CVExtraction struct {
 	Companies []Company   `json:"companies"`
 	Education []Education `json:"education"`
 	Locations []string    `json:"locations"`
-	Languages []string    `json:"languages"`
+	Languages []Language  `json:"languages"`
+
+	Certifications []Certification `json:"certifications"`
+	Projects       []Project       `json:"projects"`
 }
 
 type Candidate struct {
@@ -59,14 +67,54 @@ type Candidate struct {
 	CurrentPosition      string      `json:"current_position"`
 	Seniority            string      `json:"seniority"`
 	TotalExperienceYears interface{} `json:"total_experience_years"` // Can be int, string, or null
+	Email                string      `json:"email"`
+	Phone                string      `json:"phone"`
+	LinkedInURL          string      `json:"linkedin_url"`
+
+	// Work preferences, only when the CV states them.
+	WorkModes       StringList  `json:"work_modes"`       // remote|hybrid|onsite
+	EmploymentTypes StringList  `json:"employment_types"` // contract|permanent
+	NoticePeriod    interface{} `json:"notice_period"`    // as stated ("1 month", "immediately") or days; can be int, string or null
+	AvailableFrom   string      `json:"available_from"`   // YYYY-MM-DD or YYYY-MM
+
+	// Salary expectation or current pay, only when the CV states it.
+	Salary *Salary `json:"salary"`
+}
+
+// Salary is a pay figure a CV states. Amounts can be numbers, strings
+// ("85k") or null, like the other numeric fields.
+type Salary struct {
+	Min        interface{} `json:"min"`
+	Max        interface{} `json:"max"`        // = min for a single figure
+	Currency   string      `json:"currency"`   // ISO 4217, e.g. EUR, TRY
+	Period     string      `json:"period"`     // year|month|day|hour
+	Kind       string      `json:"kind"`       // expected|current
+	Confidence string      `json:"confidence"` // high: stated plainly; low: currency, period or figure guessed
+}
+
+// StringList is a list of strings that also accepts a single string or
+// null, which models return for one-value lists at times.
+type StringList []string
+
+func (l *StringList) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*l = nil
+		if one != "" {
+			*l = StringList{one}
+		}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(l))
 }
 
 type Skill struct {
-	Name           string   `json:"skill"`
-	Proficiency    string   `json:"proficiency"`
-	Years          *float64 `json:"years"` // LLM sometimes returns fractional years (e.g. 0.3) — must not be *int or JSON unmarshal fails and discards the whole extraction
-	Confidence     float64  `json:"confidence"`
-	NormalizedFrom string   `json:"normalized_from,omitempty"`
+	Name           string      `json:"skill"`
+	Proficiency    string      `json:"proficiency"`
+	Years          *float64    `json:"years"`          // LLM sometimes returns fractional years (e.g. 0.3) — must not be *int or JSON unmarshal fails and discards the whole extraction
+	LastUsedYear   interface{} `json:"last_used_year"` // Can be int, string ("present"), or null
+	Confidence     float64     `json:"confidence"`
+	NormalizedFrom string      `json:"normalized_from,omitempty"`
 }
 
 type Company struct {
@@ -79,6 +127,42 @@ type Company struct {
 	Confidence    float64     `json:"confidence"`
 }
 
+// Project is a project or notable achievement described in a CV, whether a
+// side project or a piece of work within a job.
+type Project struct {
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`  // what was built, in one or two sentences
+	Role         string   `json:"role"`         // the candidate's part, e.g. "Lead developer"
+	Impact       string   `json:"impact"`       // outcome, with numbers when the CV gives them
+	Technologies []string `json:"technologies"` // canonical skill names
+	Company      string   `json:"company"`      // employer it was done at, "" for personal projects
+}
+
+// Certification is a certificate or license listed in a CV.
+type Certification struct {
+	Name   string      `json:"name"`   // e.g. "AWS Certified Solutions Architect – Associate"
+	Issuer string      `json:"issuer"` // e.g. "Amazon Web Services"
+	Year   interface{} `json:"year"`   // Can be int or string
+}
+
+// Language is a spoken language with the level stated in the CV.
+type Language struct {
+	Name        string `json:"name"`
+	Proficiency string `json:"proficiency"` // Native|Fluent|Advanced|Intermediate|Basic, "" if not stated
+}
+
+// UnmarshalJSON also accepts a bare language name, which is what the
+// extraction prompt used to ask for (and models still return at times).
+func (l *Language) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*l = Language{Name: name}
+		return nil
+	}
+	type plain Language
+	return json.Unmarshal(data, (*plain)(l))
+}
+
 type Education struct {
 	Degree         string      `json:"degree"`
 	Field          string      `json:"field"`
@@ -86,32 +170,50 @@ type Education struct {
 	GraduationYear interface{} `json:"graduation_year"` // Can be int or string
 }
 
+// DefaultGroqRPMLimit is the pace of a new Groq service. Groq's published
+// limit for llama-3.3-70b-versatile is 30 RPM, shared org-wide across search
+// + CV parsing + offline tools; stay a bit under it so we don't need to rely
+// on reactive 429 handling.
+const DefaultGroqRPMLimit = 25
+
+// DefaultOllamaURL is where a local Ollama server listens.
+const DefaultOllamaURL = "http://localhost:11434"
+
 func NewService(provider, apiKey, model string) *Service {
 	s := &Service{
 		provider: Provider(provider),
 		apiKey:   apiKey,
 		model:    model,
 		timeout:  600 * time.Second, // 10 minutes for large CVs and slower models
+		baseURL:  DefaultOllamaURL,
 	}
 
 	if s.provider == ProviderGroq {
-		// Conservative default: Groq's published limit for llama-3.3-70b-versatile
-		// is 30 RPM, shared org-wide across search + CV parsing + offline tools.
-		// Stay a bit under it so we don't need to rely on reactive 429 handling.
-		rpm := 25
-		if v := os.Getenv("GROQ_RPM_LIMIT"); v != "" {
-			if n, err := strconv.Atoi(v); err == nil && n > 0 {
-				rpm = n
-			}
-		}
-		s.limiter = rate.NewLimiter(rate.Limit(float64(rpm)/60.0), 1)
+		s.SetRPMLimit(DefaultGroqRPMLimit)
 	}
 
 	return s
 }
 
-// Generate sends a prompt to LLM and returns the response (for GraphRAG queries)
-func (s *Service) Generate(prompt string) (string, error) {
+// SetRPMLimit paces Groq requests to rpm per minute. No-op for other
+// providers.
+func (s *Service) SetRPMLimit(rpm int) {
+	if s.provider == ProviderGroq && rpm > 0 {
+		s.limiter = rate.NewLimiter(rate.Limit(float64(rpm)/60.0), 1)
+	}
+}
+
+// SetBaseURL points an Ollama service at a server other than
+// DefaultOllamaURL.
+func (s *Service) SetBaseURL(url string) {
+	if url != "" {
+		s.baseURL = strings.TrimRight(url, "/")
+	}
+}
+
+// Generate sends a prompt to LLM and returns the response (for GraphRAG
+// queries). ctx's organization is what the tokens are recorded for.
+func (s *Service) Generate(ctx context.Context, prompt string) (string, error) {
 	if s.provider == ProviderNone {
 		return "", fmt.Errorf("LLM provider not configured")
 	}
@@ -121,13 +223,13 @@ func (s *Service) Generate(prompt string) (string, error) {
 
 	switch s.provider {
 	case ProviderOpenAI:
-		response, err = s.callOpenAI(prompt)
+		response, err = s.callOpenAI(ctx, prompt)
 	case ProviderOllama:
-		response, err = s.callOllama(prompt)
+		response, err = s.callOllama(ctx, prompt)
 	case ProviderGroq:
 		// Interactive (search) call site: fail fast on rate limit rather than
 		// hanging the user's HTTP request.
-		response, err = s.callGroq(prompt, interactiveMaxWait)
+		response, err = s.callGroq(ctx, prompt, interactiveMaxWait)
 	default:
 		return "", fmt.Errorf("unknown provider: %s", s.provider)
 	}
@@ -135,7 +237,7 @@ func (s *Service) Generate(prompt string) (string, error) {
 	return response, err
 }
 
-func (s *Service) ExtractEntities(cvText string) (*CVExtraction, error) {
+func (s *Service) ExtractEntities(ctx context.Context, cvText string) (*CVExtraction, error) {
 	if s.provider == ProviderNone {
 		return nil, fmt.Errorf("LLM provider not configured")
 	}
@@ -147,13 +249,13 @@ func (s *Service) ExtractEntities(cvText string) (*CVExtraction, error) {
 
 	switch s.provider {
 	case ProviderOpenAI:
-		response, err = s.callOpenAI(prompt)
+		response, err = s.callOpenAI(ctx, prompt)
 	case ProviderOllama:
-		response, err = s.callOllama(prompt)
+		response, err = s.callOllama(ctx, prompt)
 	case ProviderGroq:
 		// Background call site (async worker/offline tools): safe to wait
 		// longer for a rate-limited request instead of aborting.
-		response, err = s.callGroq(prompt, backgroundMaxWait)
+		response, err = s.callGroq(ctx, prompt, backgroundMaxWait)
 	default:
 		return nil, fmt.Errorf("unknown provider: %s", s.provider)
 	}
@@ -171,27 +273,37 @@ func (s *Service) ExtractEntities(cvText string) (*CVExtraction, error) {
 	return &extraction, nil
 }
 
-func (s *Service) buildPrompt(cvText string) string {
-	return fmt.Sprintf(`You are an expert CV parser. Extract structured information from this CV.
+// LinkedInExportTag starts CV text that internal/cv recognized as a LinkedIn
+// "Save to PDF" export; buildPrompt then uses the LinkedIn template.
+const LinkedInExportTag = "### SOURCE: LINKEDIN PROFILE EXPORT"
 
-CV Text:
-"""
-%s
-"""
+// DocumentFieldsTag heads the block internal/cv appends to the CV text with
+// the fields read straight from a Word document's header and properties
+// (name, title, contact), which the prompt treats as reliable.
+const DocumentFieldsTag = "DOCUMENT FIELDS"
 
-Extract and return ONLY valid JSON (no markdown, no explanation) with this exact structure:
-{
+// extractionSchema is the JSON shape every extraction template asks for.
+const extractionSchema = `{
   "candidate": {
     "name": "Full name",
     "current_position": "Current job title",
     "seniority": "Junior|Mid-level|Senior|Lead|Architect",
-    "total_experience_years": 0
+    "total_experience_years": 0,
+    "email": "Email address",
+    "phone": "Phone number",
+    "linkedin_url": "LinkedIn profile URL",
+    "work_modes": ["remote|hybrid|onsite"],
+    "employment_types": ["contract|permanent"],
+    "notice_period": null,
+    "available_from": "",
+    "salary": null
   },
   "skills": [
     {
       "skill": "Canonical skill name",
       "proficiency": "Beginner|Intermediate|Advanced|Expert",
       "years": null,
+      "last_used_year": null,
       "confidence": 0.95,
       "normalized_from": "Original text if normalized"
     }
@@ -215,23 +327,162 @@ Extract and return ONLY valid JSON (no markdown, no explanation) with this exact
       "graduation_year": null
     }
   ],
+  "projects": [
+    {
+      "name": "Project name",
+      "description": "What was built",
+      "role": "Candidate's role",
+      "impact": "Measurable outcome",
+      "technologies": ["Canonical skill names"],
+      "company": "Employer or empty"
+    }
+  ],
+  "certifications": [
+    {
+      "name": "Certification name",
+      "issuer": "Issuing organization",
+      "year": null
+    }
+  ],
   "locations": ["City names"],
-  "languages": ["Language names"]
-}
+  "languages": [
+    {
+      "name": "Language name",
+      "proficiency": "Native|Fluent|Advanced|Intermediate|Basic"
+    }
+  ]
+}`
+
+func (s *Service) buildPrompt(cvText string) string {
+	// Turkish dates and degree names in one form, whatever the CV used.
+	cvText = textnorm.Normalize(cvText)
+	if strings.HasPrefix(cvText, LinkedInExportTag) {
+		return buildLinkedInPrompt(cvText)
+	}
+	return fmt.Sprintf(`You are an expert CV parser. Extract structured information from this CV.
+
+CV Text:
+"""
+%s
+"""
+
+Extract and return ONLY valid JSON (no markdown, no explanation) with this exact structure:
+%s
 
 Important:
 - Normalize skill names (e.g., "K8s" → "Kubernetes", "JS" → "JavaScript", "React.js" → "React")
 - Infer proficiency from context (e.g., "expert in Java" → "Expert", "familiar with Python" → "Beginner")
 - For skills, calculate years from work history (e.g., "Java at Company X (2018-2023)" → years: 5)
 - If skill mentioned multiple times, sum all usage periods
+- last_used_year: end year of the most recent role where the skill was used ("present" if used in the current role)
 - Calculate duration from date ranges if available
 - Extract implicit skills (e.g., "built microservices" → add "Microservices")
 - Return empty arrays if no data found for a category
+- Use null for missing numeric values and "" for missing contact details; copy email, phone and LinkedIn URL exactly as written
+- projects: named projects and concrete achievements from EXPERIENCE and PROJECTS (e.g. "built the payment system from scratch", "migrated 40 services to Kubernetes"); a short name if none is given, description in plain words, impact only if stated, technologies normalized like skills and also listed in skills; no generic duties ("responsible for backend development")
+- certifications: certificates, licenses and completed certification exams only (e.g. "AWS Certified Developer", "PMP", "CKA"), with the official name; not courses without a certificate, not degrees
+- work_modes, employment_types, notice_period, available_from: only when the CV states what the candidate is looking for or when they can start (e.g. "open to remote", "freelance", "available immediately", "1 ay ihbar süresi", "available from March 2025"), never inferred from past jobs: work modes remote|hybrid|onsite, employment types contract (freelance, contractor) or permanent (full-time employee), notice_period as stated in English ("1 month", "2-4 weeks", "immediately"), available_from as YYYY-MM-DD or YYYY-MM; [], null and "" otherwise
+- salary: only when the CV states a salary expectation or current pay (e.g. "expected salary: 80-90k EUR/year", "maaş beklentisi net 120.000 TL/ay"), else null: {"min": 80000, "max": 90000, "currency": "EUR", "period": "year|month|day|hour", "kind": "expected|current", "confidence": "high|low"}; full amounts ("85k" → 85000), max = min for one figure, ISO currency code; confidence low when the currency, period or amount had to be guessed
+- languages: spoken languages only (not programming languages); map levels to Native|Fluent|Advanced|Intermediate|Basic (C2/"mother tongue"/"ana dil" → Native, C1/"fluent"/"akıcı" → Fluent, B2 → Advanced, B1 → Intermediate, A1-A2 → Basic), "" if no level is given
+- If the CV text is split into sections marked "### KIND (original heading)", use them: companies only from EXPERIENCE, education from EDUCATION, the name from HEADER or SUMMARY; skills may come from any section, but courses and certifications are not employers or degrees
+- A final "### `+DocumentFieldsTag+`" block was read from the document file itself: use its Name, Title, Email, Phone and LinkedIn as candidate name, current_position (unless EXPERIENCE shows a newer role), email, phone and linkedin_url
+- For Turkish text, extract in English`, cvText, extractionSchema)
+}
+
+// buildLinkedInPrompt is the extraction template for LinkedIn profile
+// exports, whose fixed layout lets the sections map straight onto fields.
+func buildLinkedInPrompt(cvText string) string {
+	return fmt.Sprintf(`You are an expert CV parser. This is a LinkedIn profile exported with "Save to PDF", split into its sections as "### KIND (original heading)".
+
+CV Text:
+"""
+%s
+"""
+
+Extract and return ONLY valid JSON (no markdown, no explanation) with this exact structure:
+%s
+
+The export's layout is fixed; map it directly:
+- HEADER (Contact): email, phone and the "linkedin.com/in/... (LinkedIn)" line → candidate.email, phone, linkedin_url. Never a company, skill or name
+- The last sidebar section before SUMMARY or EXPERIENCE (usually LANGUAGES, CERTIFICATIONS or SKILLS) ends with three lines that are not part of it: the candidate's full name, the headline and the location. Name → candidate.name, location → locations. The headline is often "Title at Company"; use its title as current_position only if EXPERIENCE has no current role
+- SKILLS (Top Skills): one skill per line, all explicitly listed by the candidate (confidence 0.9+)
+- LANGUAGES: "Language (Level)" per line → languages. Levels: Native or Bilingual → Native, Full Professional → Fluent, Professional Working → Advanced, Limited Working → Intermediate, Elementary → Basic
+- CERTIFICATIONS: one certification per line → certifications (issuer only if written)
+- CERTIFICATIONS, OTHER (Honors-Awards, Publications, Patents): never employers or degrees; skills may be inferred from them
+- EXPERIENCE: each role is company, title, a date line "Month YYYY - Month YYYY (N years M months)" or "... - Present (...)", then an optional location line and description. When several titles follow one company line, the line under the company is the total tenure ("N years M months") and each title is a separate entry with the same company name
+  - start_year / end_year from the date line; "Present" → is_current: true, end_year: "present"
+  - duration_years from the parenthesized duration (e.g. "2 years 6 months" → 2.5)
+  - current_position is the title of the most recent current role
+- Role descriptions: named projects and concrete achievements → projects, with the role's company
+- EDUCATION: institution line, then "Degree, Field · (YYYY - YYYY)"; graduation_year is the second year
+- Ignore "Page N of M" lines
+- total_experience_years: sum of EXPERIENCE durations, overlapping periods counted once
+
+Also:
+- Normalize skill names (e.g., "K8s" → "Kubernetes", "JS" → "JavaScript", "React.js" → "React")
+- For skills used in roles, calculate years and last_used_year from those roles' dates
+- Extract implicit skills from role descriptions (e.g., "built microservices" → add "Microservices")
+- Return empty arrays if no data found for a category
 - Use null for missing numeric values
-- For Turkish text, extract in English`, cvText)
+- work_modes, employment_types, notice_period, available_from only if SUMMARY states them ("open to remote", "freelance", "available immediately", "available from March 2025"), as remote|hybrid|onsite, contract|permanent, the notice as stated in English ("1 month") and YYYY-MM-DD or YYYY-MM; [], null and "" otherwise
+- salary only if SUMMARY states an expectation or current pay, as in the schema (full amounts, ISO currency, confidence low when anything was guessed); null otherwise
+- For Turkish text (Deneyim, Eğitim, Özet, ...), extract in English`, cvText, extractionSchema)
+}
+
+// SectionHeading is one CV section heading found by DetectSectionHeadings.
+type SectionHeading struct {
+	Kind    string `json:"kind"`    // summary, experience, education, skills, certifications, languages, projects, other
+	Heading string `json:"heading"` // the heading line exactly as it appears in the text
 }
 
-func (s *Service) callOpenAI(prompt string) (string, error) {
+// DetectSectionHeadings asks the LLM for the section headings of a CV whose
+// layout the heading heuristics in internal/cv couldn't read. Only the
+// headings come back, not the section text, to keep the response small.
+func (s *Service) DetectSectionHeadings(ctx context.Context, cvText string) ([]SectionHeading, error) {
+	if s.provider == ProviderNone {
+		return nil, fmt.Errorf("LLM provider not configured")
+	}
+
+	prompt := fmt.Sprintf(`Find the section headings of this CV.
+
+CV Text:
+"""
+%s
+"""
+
+Return ONLY valid JSON: {"sections": [{"kind": "experience", "heading": "Work History"}]}
+- kind is one of: summary, experience, education, skills, certifications, languages, projects, other
+- heading is the heading line copied exactly from the text, in document order
+- Only lines that introduce a section; no job titles, company names or bullet points
+- Return {"sections": []} if the CV has no recognizable sections`, cvText)
+
+	var response string
+	var err error
+
+	switch s.provider {
+	case ProviderOpenAI:
+		response, err = s.callOpenAI(ctx, prompt)
+	case ProviderOllama:
+		response, err = s.callOllama(ctx, prompt)
+	case ProviderGroq:
+		response, err = s.callGroq(ctx, prompt, backgroundMaxWait)
+	default:
+		return nil, fmt.Errorf("unknown provider: %s", s.provider)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var out struct {
+		Sections []SectionHeading `json:"sections"`
+	}
+	if err := json.Unmarshal([]byte(response), &out); err != nil {
+		return nil, fmt.Errorf("failed to parse LLM response: %w", err)
+	}
+	return out.Sections, nil
+}
+
+func (s *Service) callOpenAI(ctx context.Context, prompt string) (string, error) {
 	reqBody := map[string]interface{}{
 		"model": s.model,
 		"messages": []map[string]string{
@@ -279,6 +530,7 @@ func (s *Service) callOpenAI(prompt string) (string, error) {
 				Content string `json:"content"`
 			} `json:"message"`
 		} `json:"choices"`
+		Usage tokenUsage `json:"usage"`
 		Error struct {
 			Message string `json:"message"`
 		} `json:"error"`
@@ -292,6 +544,7 @@ func (s *Service) callOpenAI(prompt string) (string, error) {
 	if result.Error.Message != "" {
 		return "", fmt.Errorf("OpenAI error: %s", result.Error.Message)
 	}
+	s.record(ctx, result.Usage.PromptTokens, result.Usage.CompletionTokens, false)
 
 	if len(result.Choices) == 0 {
 		return "", fmt.Errorf("no response from OpenAI")
@@ -300,7 +553,7 @@ func (s *Service) callOpenAI(prompt string) (string, error) {
 	return result.Choices[0].Message.Content, nil
 }
 
-func (s *Service) callOllama(prompt string) (string, error) {
+func (s *Service) callOllama(ctx context.Context, prompt string) (string, error) {
 	log.Printf("[DEBUG] Calling Ollama with model: %s", s.model)
 	log.Printf("[DEBUG] Prompt length: %d characters", len(prompt))
 	log.Printf("[DEBUG] Timeout: %v", s.timeout)
@@ -319,13 +572,17 @@ func (s *Service) callOllama(prompt string) (string, error) {
 	log.Printf("[DEBUG] Request body size: %d bytes", len(jsonData))
 
 	req, err := http.NewRequest("POST",
-		"http://localhost:11434/api/generate",
+		s.baseURL+"/api/generate",
 		bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	if s.apiKey != "" {
+		// Ollama itself has no auth; a proxy in front of it may.
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
 
 	log.Printf("[DEBUG] Sending request to Ollama...")
 	startTime := time.Now()
@@ -345,8 +602,10 @@ func (s *Service) callOllama(prompt string) (string, error) {
 	log.Printf("[DEBUG] Ollama response status: %d", resp.StatusCode)
 
 	var result struct {
-		Response string `json:"response"`
-		Error    string `json:"error"`
+		Response        string `json:"response"`
+		PromptEvalCount int    `json:"prompt_eval_count"`
+		EvalCount       int    `json:"eval_count"`
+		Error           string `json:"error"`
 	}
 
 	err = json.NewDecoder(resp.Body).Decode(&result)
@@ -359,6 +618,7 @@ func (s *Service) callOllama(prompt string) (string, error) {
 		log.Printf("[ERROR] Ollama returned error: %s", result.Error)
 		return "", fmt.Errorf("Ollama error: %s", result.Error)
 	}
+	s.record(ctx, result.PromptEvalCount, result.EvalCount, false)
 
 	log.Printf("[DEBUG] Ollama response length: %d characters", len(result.Response))
 	log.Printf("[DEBUG] Ollama response preview: %.200s...", result.Response)
@@ -370,7 +630,7 @@ func (s *Service) callOllama(prompt string) (string, error) {
 // we're willing to wait on a 429 (Retry-After) before giving up — interactive
 // callers pass a short cap (fail fast), background callers pass a longer one
 // (safe to wait since they run off a queue, not an HTTP request).
-func (s *Service) callGroq(prompt string, maxWait time.Duration) (string, error) {
+func (s *Service) callGroq(ctx context.Context, prompt string, maxWait time.Duration) (string, error) {
 	const maxRetries = 3
 
 	reqBody := map[string]interface{}{
@@ -477,6 +737,7 @@ func (s *Service) callGroq(prompt string, maxWait time.Duration) (string, error)
 					Content string `json:"content"`
 				} `json:"message"`
 			} `json:"choices"`
+			Usage tokenUsage `json:"usage"`
 			Error struct {
 				Message string `json:"message"`
 			} `json:"error"`
@@ -488,6 +749,7 @@ func (s *Service) callGroq(prompt string, maxWait time.Duration) (string, error)
 		if result.Error.Message != "" {
 			return "", fmt.Errorf("Groq error: %s", result.Error.Message)
 		}
+		s.record(ctx, result.Usage.PromptTokens, result.Usage.CompletionTokens, false)
 		if len(result.Choices) == 0 {
 			return "", fmt.Errorf("no response from Groq")
 		}
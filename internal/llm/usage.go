@@ -0,0 +1,79 @@
+package llm
+
+import "context"
+
+// Usage is the tokens one LLM request consumed, as reported by the provider.
+type Usage struct {
+	Provider         string
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+	// Batch marks a Groq Batch API request, billed at half the
+	// real-time price.
+	Batch bool
+}
+
+// tokenUsage is the "usage" object of OpenAI-compatible responses (OpenAI,
+// Groq).
+type tokenUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+// modelPrices are USD per million input and output tokens, from the
+// providers' price lists. Models missing here have no cost estimate.
+var modelPrices = map[string][2]float64{
+	"gpt-4o-mini":             {0.15, 0.60},
+	"gpt-4o":                  {2.50, 10.00},
+	"gpt-4.1-mini":            {0.40, 1.60},
+	"gpt-4.1":                 {2.00, 8.00},
+	"llama-3.3-70b-versatile": {0.59, 0.79},
+	"llama-3.1-8b-instant":    {0.05, 0.08},
+}
+
+// CostUSD estimates what the request cost. ok is false for a hosted model
+// without a known price; Ollama runs locally and costs nothing.
+func (u Usage) CostUSD() (cost float64, ok bool) {
+	if u.Provider == string(ProviderOllama) {
+		return 0, true
+	}
+	p, ok := modelPrices[u.Model]
+	if !ok {
+		return 0, false
+	}
+	cost = (float64(u.PromptTokens)*p[0] + float64(u.CompletionTokens)*p[1]) / 1e6
+	if u.Batch {
+		cost /= 2
+	}
+	return cost, true
+}
+
+// EstimateCostUSD is the CostUSD of a request to s's model of these tokens.
+func (s *Service) EstimateCostUSD(promptTokens, completionTokens int) (cost float64, ok bool) {
+	return Usage{
+		Provider:         string(s.provider),
+		Model:            s.model,
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+	}.CostUSD()
+}
+
+// SetUsageRecorder has fn called with the token usage of every request
+// whose response reports it, and the ctx the request was made with (whose
+// organization the tokens count against). fn runs on the calling goroutine.
+func (s *Service) SetUsageRecorder(fn func(context.Context, Usage)) {
+	s.recordUsage = fn
+}
+
+func (s *Service) record(ctx context.Context, promptTokens, completionTokens int, batch bool) {
+	if s.recordUsage == nil || promptTokens+completionTokens == 0 {
+		return
+	}
+	s.recordUsage(ctx, Usage{
+		Provider:         string(s.provider),
+		Model:            s.model,
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+		Batch:            batch,
+	})
+}
@@ -0,0 +1,85 @@
+// Package notify emails recruiters: a report when their bulk upload has
+// been processed, and an optional weekly digest of their organization's new
+// candidates. Mail goes out over SMTP or the SendGrid API (NOTIFY_BACKEND);
+// the bodies come from the templates under templates/, in plain text and
+// HTML.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Message is one email.
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer sends email.
+type Mailer interface {
+	Send(ctx context.Context, m *Message) error
+}
+
+// Config selects and configures the mailer.
+type Config struct {
+	Backend string // "" / "none", "smtp" or "sendgrid"
+	From    string // sender address, "Name <addr>" or bare
+
+	// smtp: server, port (587 = STARTTLS, 465 = implicit TLS) and optional
+	// credentials (PLAIN auth, only over TLS).
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+
+	// sendgrid: API key with Mail Send access.
+	SendGridAPIKey string
+}
+
+// sendTimeout bounds one send.
+const sendTimeout = 30 * time.Second
+
+// NewMailer builds the configured mailer, or nil when notifications are
+// disabled.
+func NewMailer(cfg Config) (Mailer, error) {
+	backend := strings.ToLower(cfg.Backend)
+	if backend == "" || backend == "none" {
+		return nil, nil
+	}
+	if cfg.From == "" {
+		return nil, fmt.Errorf("notify: NOTIFY_FROM is required for the %s backend", backend)
+	}
+	switch backend {
+	case "smtp":
+		if cfg.SMTPHost == "" {
+			return nil, fmt.Errorf("notify: SMTP_HOST is required for the smtp backend")
+		}
+		port := cfg.SMTPPort
+		if port == 0 {
+			port = 587
+		}
+		return &SMTPMailer{
+			Host:     cfg.SMTPHost,
+			Port:     port,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.From,
+		}, nil
+	case "sendgrid":
+		if cfg.SendGridAPIKey == "" {
+			return nil, fmt.Errorf("notify: SENDGRID_API_KEY is required for the sendgrid backend")
+		}
+		return &SendGridMailer{
+			APIKey: cfg.SendGridAPIKey,
+			From:   cfg.From,
+			Client: &http.Client{Timeout: sendTimeout},
+		}, nil
+	}
+	return nil, fmt.Errorf("notify: unknown backend %q (use smtp or sendgrid)", cfg.Backend)
+}
@@ -0,0 +1,78 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"strings"
+)
+
+const sendGridURL = "https://api.sendgrid.com/v3/mail/send"
+
+// SendGridMailer sends through the SendGrid v3 Mail Send API.
+type SendGridMailer struct {
+	APIKey string
+	From   string
+	Client *http.Client
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+func (s *SendGridMailer) Send(ctx context.Context, m *Message) error {
+	from, err := mail.ParseAddress(s.From)
+	if err != nil {
+		return fmt.Errorf("sendgrid: sender %q: %w", s.From, err)
+	}
+	to, err := mail.ParseAddress(m.To)
+	if err != nil {
+		return fmt.Errorf("sendgrid: recipient %q: %w", m.To, err)
+	}
+	// text/plain must come before text/html.
+	var content []sendGridContent
+	if m.Text != "" {
+		content = append(content, sendGridContent{Type: "text/plain", Value: m.Text})
+	}
+	if m.HTML != "" {
+		content = append(content, sendGridContent{Type: "text/html", Value: m.HTML})
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"personalizations": []map[string]interface{}{
+			{"to": []sendGridAddress{{Email: to.Address, Name: to.Name}}},
+		},
+		"from":    sendGridAddress{Email: from.Address, Name: from.Name},
+		"subject": m.Subject,
+		"content": content,
+	})
+	if err != nil {
+		return fmt.Errorf("sendgrid: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sendGridURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("sendgrid: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+s.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sendgrid: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("sendgrid: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package notify
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// Each email has a <name>.txt.tmpl and a <name>.html.tmpl template.
+//
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var (
+	textTemplates = texttemplate.Must(texttemplate.New("").Funcs(texttemplate.FuncMap{"date": formatDate}).ParseFS(templateFS, "templates/*.txt.tmpl"))
+	htmlTemplates = htmltemplate.Must(htmltemplate.New("").Funcs(htmltemplate.FuncMap{"date": formatDate}).ParseFS(templateFS, "templates/*.html.tmpl"))
+)
+
+func formatDate(t time.Time) string {
+	return t.UTC().Format("2 Jan 2006")
+}
+
+// BatchReport is what the batch-complete email says about one bulk upload.
+type BatchReport struct {
+	BatchID    string
+	UploadedAt time.Time
+	Files      int // files in the upload
+	Processed  int // extracted and in the graph
+	Duplicates int // already uploaded before
+	Unfinished int // still queued when the report gave up waiting
+	Failures   []BatchFailure
+}
+
+// BatchFailure is a file of the upload that didn't make it.
+type BatchFailure struct {
+	Filename string
+	Reason   string
+}
+
+// Digest is the weekly summary of an organization's new candidates.
+type Digest struct {
+	OrgName        string
+	Since          time.Time
+	Until          time.Time
+	NewCandidates  int
+	Candidates     []DigestCandidate // the newest few of NewCandidates
+	TrendingSkills []DigestSkill
+}
+
+// DigestCandidate is a new candidate listed in a digest.
+type DigestCandidate struct {
+	Name            string
+	CurrentPosition string
+}
+
+// DigestSkill is a skill and how many of the week's new candidates have it.
+type DigestSkill struct {
+	Name  string
+	Count int
+}
+
+// BatchReportMessage renders the batch-complete email to to.
+func BatchReportMessage(to string, r *BatchReport) (*Message, error) {
+	subject := fmt.Sprintf("Bulk upload processed: %d of %d CVs added", r.Processed, r.Files)
+	if len(r.Failures) > 0 {
+		subject += fmt.Sprintf(", %d failed", len(r.Failures))
+	}
+	return render(to, subject, "batch_complete", r)
+}
+
+// DigestMessage renders the weekly digest email to to.
+func DigestMessage(to string, d *Digest) (*Message, error) {
+	subject := fmt.Sprintf("Weekly digest: %d new candidates", d.NewCandidates)
+	if d.OrgName != "" {
+		subject += " at " + d.OrgName
+	}
+	return render(to, subject, "weekly_digest", d)
+}
+
+func render(to, subject, name string, data interface{}) (*Message, error) {
+	var text, html bytes.Buffer
+	if err := textTemplates.ExecuteTemplate(&text, name+".txt.tmpl", data); err != nil {
+		return nil, fmt.Errorf("render %s text: %w", name, err)
+	}
+	if err := htmlTemplates.ExecuteTemplate(&html, name+".html.tmpl", data); err != nil {
+		return nil, fmt.Errorf("render %s html: %w", name, err)
+	}
+	return &Message{
+		To:      to,
+		Subject: subject,
+		Text:    strings.TrimSpace(text.String()) + "\n",
+		HTML:    html.String(),
+	}, nil
+}
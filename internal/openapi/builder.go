@@ -0,0 +1,253 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Builder collects routes, and the schemas of the types they use, into a
+// Document.
+type Builder struct {
+	doc       Document
+	names     map[reflect.Type]string
+	errorBody *Schema
+}
+
+// NewBuilder starts a document described by info.
+func NewBuilder(info Info) *Builder {
+	return &Builder{
+		doc: Document{
+			OpenAPI:    Version,
+			Info:       info,
+			Paths:      map[string]PathItem{},
+			Components: Components{Schemas: map[string]*Schema{}},
+		},
+		names: map[reflect.Type]string{},
+	}
+}
+
+// OneOf is a Body that is one of several types, e.g. a status answered
+// with different shapes.
+type OneOf []interface{}
+
+// Schema returns the schema of v's type, or a oneOf of the schemas of a
+// OneOf's values.
+func (b *Builder) Schema(v interface{}) *Schema {
+	if alts, ok := v.(OneOf); ok {
+		s := &Schema{}
+		for _, alt := range alts {
+			s.OneOf = append(s.OneOf, b.Schema(alt))
+		}
+		return s
+	}
+	return b.schemaOf(reflect.TypeOf(v))
+}
+
+// SetErrorBody sets the type of the body of every Route.Errors status.
+func (b *Builder) SetErrorBody(v interface{}) {
+	b.errorBody = b.Schema(v)
+}
+
+// AddTag describes a tag used by the routes.
+func (b *Builder) AddTag(name, description string) {
+	b.doc.Tags = append(b.doc.Tags, Tag{Name: name, Description: description})
+}
+
+// AddServer lists a base URL the API is served at.
+func (b *Builder) AddServer(url, description string) {
+	b.doc.Servers = append(b.doc.Servers, Server{URL: url, Description: description})
+}
+
+// AddSecurityScheme declares a way of authenticating, e.g. an API key header.
+func (b *Builder) AddSecurityScheme(name string, scheme SecurityScheme) {
+	if b.doc.Components.SecuritySchemes == nil {
+		b.doc.Components.SecuritySchemes = map[string]SecurityScheme{}
+	}
+	b.doc.Components.SecuritySchemes[name] = scheme
+}
+
+// SetDefaultSecurity names the schemes every route requires unless it sets
+// Public or its own Security.
+func (b *Builder) SetDefaultSecurity(schemes ...string) {
+	b.doc.Security = []map[string][]string{requirement(schemes)}
+}
+
+func requirement(schemes []string) map[string][]string {
+	req := make(map[string][]string, len(schemes))
+	for _, s := range schemes {
+		req[s] = []string{}
+	}
+	return req
+}
+
+// Document returns the document built so far.
+func (b *Builder) Document() *Document {
+	return &b.doc
+}
+
+// Route describes one operation compactly; Add turns it into an Operation.
+type Route struct {
+	Method      string
+	Path        string // "/api/cv/job/{job_id}"
+	OperationID string
+	Summary     string
+	Description string
+	Tag         string
+
+	// Params lists the query and header parameters, and path parameters
+	// that aren't strings; the other path parameters are added from Path.
+	Params []Parameter
+	// Body is a value of the JSON request body's type; Form lists
+	// multipart/form-data fields and RawBody the content types of an
+	// unstructured body, each accepted alongside the others.
+	Body    interface{}
+	Form    []FormField
+	RawBody []string
+
+	Responses []Resp
+	// Errors are the statuses answered with the error body (SetErrorBody).
+	Errors []int
+
+	Public   bool     // needs no credentials
+	Security []string // schemes required instead of the default ones
+}
+
+// Resp is one response of a Route. Body is a value of the JSON body's type;
+// without a Body, ContentType names a text or binary body, and without
+// either the response has no body.
+type Resp struct {
+	Status      int
+	Description string
+	Body        interface{}
+	ContentType string
+	Headers     map[string]Header
+	Links       map[string]Link
+}
+
+// FormField is a multipart/form-data field.
+type FormField struct {
+	Name        string
+	Description string
+	Type        string // string, integer, boolean or file
+	Multiple    bool   // repeated field, e.g. several files
+	Required    bool
+}
+
+// Query describes a query parameter of type typ (string, integer, boolean).
+func Query(name, typ, description string) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: &Schema{Type: typ}}
+}
+
+// Path describes a path parameter of type typ.
+func Path(name, typ, description string) Parameter {
+	return Parameter{Name: name, In: "path", Description: description, Required: true, Schema: &Schema{Type: typ}}
+}
+
+// HeaderParam describes a request header.
+func HeaderParam(name, description string) Parameter {
+	return Parameter{Name: name, In: "header", Description: description, Schema: &Schema{Type: "string"}}
+}
+
+var pathParamPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
+// Add registers r. Adding the same method and path twice replaces the
+// first operation.
+func (b *Builder) Add(r Route) {
+	op := &Operation{
+		OperationID: r.OperationID,
+		Summary:     r.Summary,
+		Description: r.Description,
+		Parameters:  r.Params,
+		Responses:   map[string]*Response{},
+	}
+	if r.Tag != "" {
+		op.Tags = []string{r.Tag}
+	}
+	switch {
+	case r.Public:
+		op.Security = []map[string][]string{{}}
+	case len(r.Security) > 0:
+		op.Security = []map[string][]string{requirement(r.Security)}
+	}
+
+	for _, m := range pathParamPattern.FindAllStringSubmatch(r.Path, -1) {
+		if !hasParam(op.Parameters, m[1], "path") {
+			op.Parameters = append(op.Parameters, Path(m[1], "string", ""))
+		}
+	}
+
+	content := map[string]MediaType{}
+	if r.Body != nil {
+		content["application/json"] = MediaType{Schema: b.Schema(r.Body)}
+	}
+	if len(r.Form) > 0 {
+		content["multipart/form-data"] = MediaType{Schema: formSchema(r.Form)}
+	}
+	for _, ct := range r.RawBody {
+		content[ct] = MediaType{Schema: &Schema{Type: "string"}}
+	}
+	if len(content) > 0 {
+		op.RequestBody = &RequestBody{Required: true, Content: content}
+	}
+
+	for _, resp := range r.Responses {
+		out := &Response{Description: resp.Description, Headers: resp.Headers, Links: resp.Links}
+		if out.Description == "" {
+			out.Description = http.StatusText(resp.Status)
+		}
+		switch {
+		case resp.Body != nil:
+			out.Content = map[string]MediaType{"application/json": {Schema: b.Schema(resp.Body)}}
+		case strings.HasPrefix(resp.ContentType, "text/"):
+			out.Content = map[string]MediaType{resp.ContentType: {Schema: &Schema{Type: "string"}}}
+		case resp.ContentType != "":
+			out.Content = map[string]MediaType{resp.ContentType: {Schema: &Schema{Type: "string", Format: "binary"}}}
+		}
+		op.Responses[strconv.Itoa(resp.Status)] = out
+	}
+	for _, status := range r.Errors {
+		out := &Response{Description: http.StatusText(status)}
+		if b.errorBody != nil {
+			out.Content = map[string]MediaType{"application/json": {Schema: b.errorBody}}
+		}
+		op.Responses[strconv.Itoa(status)] = out
+	}
+
+	item := b.doc.Paths[r.Path]
+	if item == nil {
+		item = PathItem{}
+		b.doc.Paths[r.Path] = item
+	}
+	item[strings.ToLower(r.Method)] = op
+}
+
+func hasParam(params []Parameter, name, in string) bool {
+	for _, p := range params {
+		if p.Name == name && p.In == in {
+			return true
+		}
+	}
+	return false
+}
+
+func formSchema(fields []FormField) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for _, f := range fields {
+		fs := &Schema{Type: f.Type, Description: f.Description}
+		if f.Type == "file" {
+			fs = &Schema{Type: "string", Format: "binary", Description: f.Description}
+		}
+		if f.Multiple {
+			fs = &Schema{Type: "array", Items: fs, Description: f.Description}
+			fs.Items.Description = ""
+		}
+		s.Properties[f.Name] = fs
+		if f.Required {
+			s.Required = append(s.Required, f.Name)
+		}
+	}
+	return s
+}
@@ -0,0 +1,265 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ─── PDF ─────────────────────────────────────────────────────────────────────
+//
+// A shortlist PDF is text only: A4 pages of Helvetica, which every PDF reader
+// has, so nothing is embedded. Helvetica's WinAnsi encoding covers Latin-1;
+// the Turkish letters outside it (ğ, ş, ı, İ) are written without their
+// marks and anything else as "?".
+
+const (
+	pageWidth    = 595.0 // A4, in points
+	pageHeight   = 842.0
+	marginX      = 50.0
+	marginTop    = 56.0
+	marginBottom = 56.0
+	textWidth    = pageWidth - 2*marginX
+)
+
+// pdfBlock is a paragraph of the laid-out report.
+type pdfBlock struct {
+	text   string
+	size   float64
+	bold   bool
+	gray   bool
+	indent float64
+	before float64 // space above, in points
+	rule   bool    // a horizontal line instead of text
+}
+
+// shortlistBlocks is the shortlist as PDF paragraphs, in the order of the
+// Markdown report.
+func shortlistBlocks(s *Shortlist) []pdfBlock {
+	meta := fmt.Sprintf("Search: \"%s\" · %d of %d candidates · generated %s", s.Query, len(s.Cards), s.TotalFound, formatDate(s.GeneratedAt))
+	if s.SearchID != "" {
+		meta += " · search " + s.SearchID
+	}
+	blocks := []pdfBlock{
+		{text: s.Title, size: 18, bold: true},
+		{text: meta, size: 9, gray: true, before: 6},
+		{text: "Candidates are anonymized: names, contact details and employers are left out.", size: 9, gray: true, before: 2},
+	}
+
+	if len(s.TopSkills) > 0 || len(s.Communities) > 0 {
+		blocks = append(blocks, pdfBlock{text: "Shortlist at a glance", size: 13, bold: true, before: 16})
+	}
+	if len(s.TopSkills) > 0 {
+		names := make([]string, len(s.TopSkills))
+		for i, sk := range s.TopSkills {
+			names[i] = fmt.Sprintf("%s (%d)", sk.Name, sk.Count)
+		}
+		blocks = append(blocks, pdfBlock{text: "Most common skills: " + strings.Join(names, ", "), size: 10, before: 4})
+	}
+	for _, c := range s.Communities {
+		line := fmt.Sprintf("%s: %d candidates, avg. score %s", c.Name, c.Candidates, formatScore(c.AvgScore))
+		if len(c.TopSkills) > 0 {
+			line += "; " + strings.Join(c.TopSkills, ", ")
+		}
+		blocks = append(blocks, pdfBlock{text: "• " + line, size: 10, indent: 8, before: 3})
+	}
+
+	blocks = append(blocks, pdfBlock{text: "Candidates", size: 13, bold: true, before: 16})
+	for _, c := range s.Cards {
+		blocks = append(blocks,
+			pdfBlock{rule: true, before: 8},
+			pdfBlock{text: fmt.Sprintf("%s — score %s/100", c.Label, formatScore(c.Score)), size: 11, bold: true, before: 6},
+		)
+		field := func(name, value string) {
+			if value != "" {
+				blocks = append(blocks, pdfBlock{text: name + ": " + value, size: 10, indent: 8, before: 3})
+			}
+		}
+		position := c.Position
+		if position != "" && c.Seniority != "" {
+			position += " (" + c.Seniority + ")"
+		} else if position == "" && c.Seniority != "" {
+			field("Seniority", c.Seniority)
+		}
+		field("Position", position)
+		if c.ExperienceYears > 0 {
+			field("Experience", fmt.Sprintf("%d years", c.ExperienceYears))
+		}
+		field("Location", c.Location)
+		field("Community", c.Community)
+		field("Skills", strings.Join(c.Skills, ", "))
+		if c.Reasoning != "" {
+			blocks = append(blocks, pdfBlock{text: c.Reasoning, size: 10, gray: true, indent: 16, before: 6})
+		}
+		blocks = append(blocks, pdfBlock{text: fmt.Sprintf("Ref #%d", c.Ref), size: 8, gray: true, indent: 8, before: 4})
+	}
+	return blocks
+}
+
+// writePDF lays the shortlist out on pages and writes the PDF.
+func writePDF(w io.Writer, s *Shortlist) error {
+	var pages []*bytes.Buffer
+	var page *bytes.Buffer
+	y := 0.0
+	newPage := func() {
+		page = &bytes.Buffer{}
+		pages = append(pages, page)
+		y = pageHeight - marginTop
+	}
+	newPage()
+
+	for _, b := range shortlistBlocks(s) {
+		if b.rule {
+			y -= b.before
+			if y < marginBottom {
+				newPage()
+			}
+			fmt.Fprintf(page, "0.8 G 0.5 w %.2f %.2f m %.2f %.2f l S\n", marginX, y, pageWidth-marginX, y)
+			continue
+		}
+		lineHeight := b.size * 1.35
+		y -= b.before
+		for _, line := range wrapText(b.text, b.size, b.bold, textWidth-b.indent) {
+			if y-lineHeight < marginBottom {
+				newPage()
+			}
+			y -= lineHeight
+			font, color := "F1", "0 g"
+			if b.bold {
+				font = "F2"
+			}
+			if b.gray {
+				color = "0.4 g"
+			}
+			fmt.Fprintf(page, "BT %s /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", color, font, b.size, marginX+b.indent, y, pdfString(line))
+		}
+	}
+
+	// Page numbers, now that the count is known.
+	for i, p := range pages {
+		fmt.Fprintf(p, "BT 0.4 g /F1 8 Tf %.2f %.2f Td (%d / %d) Tj ET\n", pageWidth-marginX-20, marginBottom/2, i+1, len(pages))
+	}
+	return writePDFObjects(w, pages)
+}
+
+// writePDFObjects writes a PDF of the given page content streams.
+func writePDFObjects(w io.Writer, pages []*bytes.Buffer) error {
+	// Objects: 1 catalog, 2 page tree, 3-4 fonts, then a page and its
+	// content stream per page.
+	var buf bytes.Buffer
+	offsets := []int{}
+	obj := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, p := range pages {
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 6+2*i))
+		obj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", p.Len(), p.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// wrapText breaks text into lines no wider than width at size.
+func wrapText(text string, size float64, bold bool, width float64) []string {
+	var lines []string
+	for _, para := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if line != "" && textWidthOf(candidate, size, bold) > width {
+				lines = append(lines, line)
+				candidate = word
+			}
+			line = candidate
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// helveticaWidths are Helvetica's advance widths of ' ' through '~', in
+// thousandths of the font size.
+var helveticaWidths = [...]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // space-/
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556, // 0-?
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778, // @-O
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556, // P-_
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556, // `-o
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584, // p-~
+}
+
+// textWidthOf estimates the width of text at size: Helvetica's widths, 5%
+// wider in bold, letters outside ASCII as wide as an "n".
+func textWidthOf(text string, size float64, bold bool) float64 {
+	total := 0
+	for _, r := range text {
+		if r >= ' ' && r <= '~' {
+			total += helveticaWidths[r-' ']
+		} else {
+			total += 556
+		}
+	}
+	w := float64(total) * size / 1000
+	if bold {
+		w *= 1.05
+	}
+	return w
+}
+
+// winAnsi maps the characters outside Latin-1 that WinAnsiEncoding has, or
+// that are written as a near letter.
+var winAnsi = map[rune]byte{
+	'€': 0x80, '…': 0x85, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	'ğ': 'g', 'Ğ': 'G', 'ş': 's', 'Ş': 'S', 'ı': 'i', 'İ': 'I',
+}
+
+// pdfString encodes text as the body of a PDF string literal in
+// WinAnsiEncoding.
+func pdfString(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= ' ' && r <= '~':
+			b.WriteRune(r)
+		case r >= 0xA0 && r <= 0xFF:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			if c, ok := winAnsi[r]; ok {
+				if c < 0x80 {
+					b.WriteByte(c)
+				} else {
+					fmt.Fprintf(&b, "\\%03o", c)
+				}
+			} else {
+				b.WriteByte('?')
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,260 @@
+// Package report renders shortlists for people without access to the
+// system, e.g. hiring managers: a search's top candidates as anonymized
+// cards (no names, contact details or employers) with their scores and the
+// LLM's reasoning, and what the shortlist's communities have in common.
+// Markdown and HTML come from the templates under templates/; PDF is laid
+// out by pdf.go.
+package report
+
+import (
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"time"
+	"unicode/utf8"
+
+	"cv-search/internal/cv"
+	"cv-search/internal/graphrag"
+)
+
+// Report formats.
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	FormatPDF      = "pdf"
+)
+
+// Formats are the formats a shortlist renders to.
+var Formats = []string{FormatMarkdown, FormatHTML, FormatPDF}
+
+// maxCardSkills caps the skills on a card.
+const maxCardSkills = 10
+
+// companyMask replaces employer names in reasoning, as cv masks PII.
+const companyMask = "[COMPANY]"
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var (
+	funcs         = map[string]interface{}{"date": formatDate, "score": formatScore, "join": strings.Join}
+	textTemplates = texttemplate.Must(texttemplate.New("").Funcs(funcs).ParseFS(templateFS, "templates/*.md.tmpl"))
+	htmlTemplates = htmltemplate.Must(htmltemplate.New("").Funcs(funcs).ParseFS(templateFS, "templates/*.html.tmpl"))
+)
+
+func formatDate(t time.Time) string {
+	return t.UTC().Format("2 Jan 2006 15:04 MST")
+}
+
+func formatScore(f float64) string {
+	return fmt.Sprintf("%.0f", f)
+}
+
+// Shortlist is a search's top candidates, anonymized.
+type Shortlist struct {
+	Title       string
+	Query       string
+	SearchID    string // for feedback on the search, when it was logged
+	GeneratedAt time.Time
+	TotalFound  int // results of the search; Cards are the first of them
+	Cards       []Card
+	Communities []CommunityInsight
+	TopSkills   []SkillCount // the skills most cards have
+}
+
+// Card is one anonymized candidate of a shortlist.
+type Card struct {
+	Label           string // "Candidate 1", by rank
+	Ref             int    // candidates.id, for the recruiter to map feedback back
+	Rank            int
+	Position        string
+	Seniority       string
+	ExperienceYears int
+	Location        string // city or country
+	Community       string
+	Skills          []string
+	Score           float64 // 0-100: the LLM's score, else the fusion score
+	Reasoning       string  // the LLM's, with names and employers masked
+}
+
+// CommunityInsight is what a shortlist's candidates of one community have in
+// common.
+type CommunityInsight struct {
+	Name       string
+	Candidates int
+	AvgScore   float64
+	TopSkills  []string
+}
+
+// SkillCount is a skill and how many cards have it.
+type SkillCount struct {
+	Name  string
+	Count int
+}
+
+// NewShortlist builds the shortlist of the first limit results of a search.
+func NewShortlist(title, query, searchID string, results []graphrag.FusedCandidate, limit int) *Shortlist {
+	s := &Shortlist{
+		Title:       title,
+		Query:       query,
+		SearchID:    searchID,
+		GeneratedAt: time.Now(),
+		TotalFound:  len(results),
+	}
+	if s.Title == "" {
+		s.Title = "Shortlist: " + query
+	}
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	for i, c := range results {
+		label := fmt.Sprintf("Candidate %d", i+1)
+		position, _ := cv.AnonymizeText(c.CurrentPosition)
+		card := Card{
+			Label:           label,
+			Ref:             c.CandidateID,
+			Rank:            i + 1,
+			Position:        maskCompanies(position, c.Companies),
+			Seniority:       c.Seniority,
+			ExperienceYears: c.TotalExperienceYears,
+			Location:        c.Location,
+			Community:       c.Community,
+			Score:           c.LLMScore,
+			Reasoning:       anonymizeReasoning(c.LLMReasoning, label, c.Name, c.Companies),
+		}
+		if card.Score == 0 {
+			card.Score = math.Round(c.FusionScore * 100)
+		}
+		for _, sk := range c.Skills {
+			if len(card.Skills) == maxCardSkills {
+				break
+			}
+			if name := strings.TrimSpace(sk.Name); name != "" {
+				card.Skills = append(card.Skills, name)
+			}
+		}
+		s.Cards = append(s.Cards, card)
+	}
+	s.Communities = communityInsights(s.Cards)
+	s.TopSkills = topSkills(s.Cards, 10)
+	return s
+}
+
+// anonymizeReasoning replaces the candidate's name (and each part of it) with
+// their card label, masks employers and PII.
+func anonymizeReasoning(text, label, name string, companies []graphrag.CompanyNode) string {
+	text, _ = cv.AnonymizeText(strings.TrimSpace(text))
+	var parts []string
+	for _, p := range append([]string{name}, strings.Fields(name)...) {
+		if utf8.RuneCountInString(p) >= 3 {
+			parts = append(parts, p)
+		}
+	}
+	return maskCompanies(cv.MaskNames(text, parts, label), companies)
+}
+
+// maskCompanies replaces employer names with companyMask.
+func maskCompanies(text string, companies []graphrag.CompanyNode) string {
+	names := make([]string, len(companies))
+	for i, co := range companies {
+		names[i] = co.Name
+	}
+	return cv.MaskNames(text, names, companyMask)
+}
+
+// communityInsights groups cards by community, largest first.
+func communityInsights(cards []Card) []CommunityInsight {
+	byName := map[string][]Card{}
+	var names []string
+	for _, c := range cards {
+		if c.Community == "" {
+			continue
+		}
+		if _, ok := byName[c.Community]; !ok {
+			names = append(names, c.Community)
+		}
+		byName[c.Community] = append(byName[c.Community], c)
+	}
+
+	insights := make([]CommunityInsight, 0, len(names))
+	for _, name := range names {
+		group := byName[name]
+		total := 0.0
+		for _, c := range group {
+			total += c.Score
+		}
+		in := CommunityInsight{
+			Name:       name,
+			Candidates: len(group),
+			AvgScore:   math.Round(total / float64(len(group))),
+		}
+		for _, sk := range topSkills(group, 5) {
+			in.TopSkills = append(in.TopSkills, sk.Name)
+		}
+		insights = append(insights, in)
+	}
+	sort.SliceStable(insights, func(i, j int) bool { return insights[i].Candidates > insights[j].Candidates })
+	return insights
+}
+
+// topSkills returns the n skills most cards have, more than one card each
+// when there is more than one card.
+func topSkills(cards []Card, n int) []SkillCount {
+	counts := map[string]*SkillCount{}
+	var order []*SkillCount
+	for _, c := range cards {
+		seen := map[string]bool{}
+		for _, sk := range c.Skills {
+			key := strings.ToLower(sk)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			if sc, ok := counts[key]; ok {
+				sc.Count++
+				continue
+			}
+			counts[key] = &SkillCount{Name: sk, Count: 1}
+			order = append(order, counts[key])
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool { return order[i].Count > order[j].Count })
+	var out []SkillCount
+	for _, sc := range order {
+		if len(out) == n || (len(cards) > 1 && sc.Count < 2) {
+			break
+		}
+		out = append(out, *sc)
+	}
+	return out
+}
+
+// Render writes the shortlist in format, one of Formats.
+func Render(w io.Writer, format string, s *Shortlist) error {
+	switch format {
+	case FormatMarkdown:
+		return textTemplates.ExecuteTemplate(w, "shortlist.md.tmpl", s)
+	case FormatHTML:
+		return htmlTemplates.ExecuteTemplate(w, "shortlist.html.tmpl", s)
+	case FormatPDF:
+		return writePDF(w, s)
+	}
+	return fmt.Errorf("unknown report format %q", format)
+}
+
+// ContentType returns the Content-Type and file extension of format.
+func ContentType(format string) (contentType, ext string) {
+	switch format {
+	case FormatHTML:
+		return "text/html; charset=utf-8", "html"
+	case FormatPDF:
+		return "application/pdf", "pdf"
+	}
+	return "text/markdown; charset=utf-8", "md"
+}
@@ -10,6 +10,9 @@
 //     (cv_entities, graph_nodes, candidates) was ever built because
 //     extraction failed outright.
 //
+// A pass covers the organization of its context (tenant.OrgID); callers
+// run one per organization.
+//
 // Safe to re-run: once a record is fixed, it no longer matches the "broken"
 // criteria and is skipped on the next pass — so a partial/interrupted run can
 // simply be re-run to pick up where it left off.
@@ -17,14 +20,17 @@ package reprocess
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"strconv"
 	"time"
 
+	"cv-search/internal/cv"
 	"cv-search/internal/graphrag"
 	"cv-search/internal/llm"
 	"cv-search/internal/storage"
+	"cv-search/internal/tenant"
 )
 
 // Options controls a single reprocess run.
@@ -45,6 +51,9 @@ type Options struct {
 	// plan doesn't support Batch API — avoids a pointless submit-then-403
 	// round trip on every run.
 	DisableBatchAPI bool
+	// Actor is recorded in the audit log for every fixed record. Defaults to
+	// "system:reprocess".
+	Actor string
 }
 
 type brokenCandidate struct {
@@ -64,6 +73,9 @@ func Run(ctx context.Context, db *storage.DB, llmSvc *llm.Service, graphBuilder
 	if opts.BatchThreshold <= 0 {
 		opts.BatchThreshold = 15
 	}
+	if opts.Actor == "" {
+		opts.Actor = "system:reprocess"
+	}
 
 	whereExtra := ""
 	if opts.OnlyCandidateID > 0 {
@@ -81,12 +93,14 @@ func Run(ctx context.Context, db *storage.DB, llmSvc *llm.Service, graphBuilder
 			COALESCE(length(c.skills), 0) AS skills_len
 		FROM candidates c
 		JOIN graph_nodes gn ON gn.id = c.graph_node_id
-		WHERE (c.skills IS NULL OR length(c.skills) < 20
+		WHERE c.deleted_at IS NULL
+		  AND c.org_id = $1
+		  AND (c.skills IS NULL OR length(c.skills) < 20
 		       OR (SELECT count(*) FROM graph_edges ge WHERE ge.source_node_id = gn.id) < 3)
 		%s
 		ORDER BY c.id`, whereExtra)
 
-	rows, err := db.GetConnection().QueryContext(ctx, q)
+	rows, err := db.GetConnection().QueryContext(ctx, q, tenant.OrgID(ctx))
 	if err != nil {
 		return fmt.Errorf("weak-candidate query failed: %w", err)
 	}
@@ -117,9 +131,11 @@ func Run(ctx context.Context, db *storage.DB, llmSvc *llm.Service, graphBuilder
 			SELECT cf.id, cf.filename
 			FROM cv_files cf
 			WHERE cf.candidate_id IS NULL
+			  AND cf.org_id = $1
+			  AND cf.deleted_at IS NULL
 			  AND cf.parsed_text IS NOT NULL AND length(cf.parsed_text) > 0
 			ORDER BY cf.id`
-		backlogRows, err := db.GetConnection().QueryContext(ctx, backlogQ)
+		backlogRows, err := db.GetConnection().QueryContext(ctx, backlogQ, tenant.OrgID(ctx))
 		if err != nil {
 			return fmt.Errorf("backlog query failed: %w", err)
 		}
@@ -257,10 +273,19 @@ func Run(ctx context.Context, db *storage.DB, llmSvc *llm.Service, graphBuilder
 				"current_position":       extraction.Candidate.CurrentPosition,
 				"seniority":              extraction.Candidate.Seniority,
 				"total_experience_years": extraction.Candidate.TotalExperienceYears,
+				"work_modes":             []string(extraction.Candidate.WorkModes),
+				"employment_types":       []string(extraction.Candidate.EmploymentTypes),
+				"notice_period":          extraction.Candidate.NoticePeriod,
+				"available_from":         extraction.Candidate.AvailableFrom,
+				"salary":                 extraction.Candidate.Salary,
 			},
-			"skills":    extraction.Skills,
-			"companies": extraction.Companies,
-			"education": extraction.Education,
+			"skills":         extraction.Skills,
+			"companies":      extraction.Companies,
+			"education":      extraction.Education,
+			"certifications": extraction.Certifications,
+			"languages":      extraction.Languages,
+			"projects":       extraction.Projects,
+			"locations":      extraction.Locations,
 		}
 		if err := graphBuilder.BuildFromLLMExtraction(ctx, int(it.cvFileID), extractMap); err != nil {
 			log.Printf("[Reprocess]   graph build failed: %v", err)
@@ -320,6 +345,17 @@ func Run(ctx context.Context, db *storage.DB, llmSvc *llm.Service, graphBuilder
 			cancel()
 		}
 
+		details, _ := json.Marshal(map[string]int{"candidate_id": candidateID, "graph_node_id": graphNodeID})
+		if err := db.LogAudit(ctx, storage.AuditEntry{
+			Actor:      opts.Actor,
+			Action:     "reprocess",
+			EntityType: "cv_file",
+			EntityID:   strconv.FormatInt(it.cvFileID, 10),
+			Details:    details,
+		}); err != nil {
+			log.Printf("[Reprocess]   WARNING: %v", err)
+		}
+
 		log.Printf("[Reprocess] DONE [cand=%d] %s", candidateID, it.bc.Name)
 		fixed++
 	}
@@ -328,7 +364,10 @@ func Run(ctx context.Context, db *storage.DB, llmSvc *llm.Service, graphBuilder
 		log.Printf("[Reprocess] Submitting %d CVs as a Groq Batch API job (threshold=%d)...", len(items), opts.BatchThreshold)
 		batchItems := make(map[string]string, len(items))
 		for _, it := range items {
-			batchItems[fmt.Sprintf("%d", it.cvFileID)] = it.parsedText
+			// Too long for one prompt: left to the synchronous chunked pass.
+			if text := cv.SectionedText(it.parsedText); cv.EstimateTokens(text) <= cv.ExtractionPromptTokens {
+				batchItems[fmt.Sprintf("%d", it.cvFileID)] = text
+			}
 		}
 
 		groqBatchID, _, err := llmSvc.SubmitExtractionBatch(batchItems, "24h")
@@ -353,7 +392,7 @@ func Run(ctx context.Context, db *storage.DB, llmSvc *llm.Service, graphBuilder
 			}
 
 			if outputFileID != "" {
-				results, lineErrors, err := llmSvc.FetchExtractionBatchResults(outputFileID)
+				results, lineErrors, err := llmSvc.FetchExtractionBatchResults(ctx, outputFileID)
 				if err != nil {
 					log.Printf("[Reprocess]   failed to fetch batch results: %v", err)
 				}
@@ -386,7 +425,14 @@ func Run(ctx context.Context, db *storage.DB, llmSvc *llm.Service, graphBuilder
 		}
 		log.Printf("[Reprocess] [cand=%d] %s: running synchronous LLM extraction (cv_files id=%d, %d chars)...",
 			it.bc.CandID, it.bc.Name, it.cvFileID, len(it.parsedText))
-		extraction, err := llmSvc.ExtractEntities(it.parsedText)
+		var extraction *llm.CVExtraction
+		var err error
+		text := cv.SectionedText(it.parsedText)
+		if chunks := cv.ChunkText(text, cv.ChunkTokens); cv.ChunksTokens(chunks) > cv.ExtractionPromptTokens {
+			extraction, err = cv.ExtractChunked(ctx, llmSvc, chunks, "")
+		} else {
+			extraction, err = llmSvc.ExtractEntities(ctx, text)
+		}
 		if err != nil {
 			log.Printf("[Reprocess]   SKIP: extraction failed: %v", err)
 			failed++
@@ -0,0 +1,115 @@
+// Package retention enforces the CV file retention policy: it prunes old
+// upload versions per candidate and removes blob-store objects no cv_files
+// row references any more. It runs periodically inside the API server (see
+// api.StartBackgroundWorkers) and on demand via cmd/tools/cleanup_blobs.
+//
+// Safe to re-run and to run concurrently: each orphan is claimed under a row
+// lock before its object is deleted, and anything that fails is simply picked
+// up again on the next pass.
+package retention
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"cv-search/internal/storage"
+)
+
+// DefaultOrphanTTL is how long an unreferenced blob is kept before removal.
+const DefaultOrphanTTL = 24 * time.Hour
+
+// orphanBatchSize caps how many orphans one pass removes.
+const orphanBatchSize = 500
+
+// Policy controls one cleanup pass.
+type Policy struct {
+	// KeepVersions is how many CV uploads to keep per candidate (newest
+	// first). 0 keeps every version.
+	KeepVersions int
+	// OrphanTTL is the grace period before an unreferenced blob is deleted.
+	// It must comfortably exceed the time between storing an upload and
+	// inserting its cv_files row. Defaults to DefaultOrphanTTL.
+	OrphanTTL time.Duration
+	// DryRun only counts what would be removed.
+	DryRun bool
+}
+
+// Report summarises a cleanup pass.
+type Report struct {
+	VersionsPruned int `json:"versions_pruned"`
+	OrphansDeleted int `json:"orphans_deleted"`
+	OrphansFailed  int `json:"orphans_failed"`
+}
+
+// Run executes one cleanup pass. Per-blob failures are logged and counted;
+// only query failures are returned.
+func Run(ctx context.Context, db *storage.DB, blobs storage.BlobStore, p Policy) (*Report, error) {
+	if p.OrphanTTL <= 0 {
+		p.OrphanTTL = DefaultOrphanTTL
+	}
+	rep := &Report{}
+
+	if p.KeepVersions > 0 {
+		ids, err := db.ListPrunableCVVersions(ctx, p.KeepVersions)
+		if err != nil {
+			return rep, err
+		}
+		if p.DryRun {
+			rep.VersionsPruned = len(ids)
+		} else if rep.VersionsPruned, err = db.DeleteCVFiles(ctx, ids); err != nil {
+			return rep, err
+		}
+	}
+
+	cutoff := time.Now().Add(-p.OrphanTTL)
+	keys, err := db.ListOrphanedBlobs(ctx, cutoff, orphanBatchSize)
+	if err != nil {
+		return rep, err
+	}
+	// Dry runs report "would delete" counts; versions pruned above only
+	// become orphans once actually deleted, so they aren't included.
+	if p.DryRun {
+		rep.OrphansDeleted = len(keys)
+		return rep, nil
+	}
+	for _, key := range keys {
+		deleted, err := DeleteOrphan(ctx, db, blobs, key, cutoff)
+		if err != nil {
+			log.Printf("[Retention] %v", err)
+			rep.OrphansFailed++
+			continue
+		}
+		if deleted {
+			rep.OrphansDeleted++
+		}
+	}
+	return rep, nil
+}
+
+// DeleteOrphan removes key from the blob store and forgets it, provided it
+// is still unreferenced and untouched since cutoff. Returns false (and does
+// nothing) if the key was reused in the meantime. Erasure passes time.Now()
+// to delete an erased candidate's files immediately.
+func DeleteOrphan(ctx context.Context, db *storage.DB, blobs storage.BlobStore, key string, cutoff time.Time) (bool, error) {
+	deleted := false
+	err := db.WithTx(ctx, func(tx *storage.DB) error {
+		claimed, err := tx.ClaimOrphanedBlob(ctx, key, cutoff)
+		if err != nil || !claimed {
+			return err
+		}
+		if err := blobs.Delete(ctx, key); err != nil {
+			return err
+		}
+		if err := tx.DeleteBlobRecord(ctx, key); err != nil {
+			return err
+		}
+		deleted = true
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("delete orphaned blob %s: %w", key, err)
+	}
+	return deleted, nil
+}
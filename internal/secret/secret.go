@@ -0,0 +1,66 @@
+// Package secret encrypts the credentials the database keeps on behalf of
+// organizations (their own LLM and embedding API keys) with AES-256-GCM, so
+// a dump, snapshot or replica of the database doesn't give them away. The
+// key is SETTINGS_ENCRYPTION_KEY and never touches the database.
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// KeySize is the length of a key in bytes, before base64.
+const KeySize = 32
+
+// Box seals and opens values under one key.
+type Box struct {
+	aead cipher.AEAD
+}
+
+// NewBox returns a Box for key, KeySize random bytes in standard base64
+// (e.g. from `openssl rand -base64 32`).
+func NewBox(key string) (*Box, error) {
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("encryption key is not base64: %w", err)
+	}
+	if len(raw) != KeySize {
+		return nil, fmt.Errorf("encryption key is %d bytes, want %d", len(raw), KeySize)
+	}
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Box{aead: aead}, nil
+}
+
+// Seal encrypts plaintext. The random nonce is prepended to the result.
+func (b *Box) Seal(plaintext string) ([]byte, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return b.aead.Seal(nonce, nonce, []byte(plaintext), nil), nil
+}
+
+// Open decrypts what Seal returned. It fails if sealed was altered or
+// sealed under another key.
+func (b *Box) Open(sealed []byte) (string, error) {
+	n := b.aead.NonceSize()
+	if len(sealed) < n {
+		return "", errors.New("sealed value too short")
+	}
+	plaintext, err := b.aead.Open(nil, sealed[:n], sealed[n:], nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypt: %w", err)
+	}
+	return string(plaintext), nil
+}
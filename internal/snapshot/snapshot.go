@@ -0,0 +1,231 @@
+// Package snapshot writes and restores full system snapshots: candidates,
+// CV file metadata and parsed text, chunks, graph nodes and edges,
+// communities and every embedding (storage.SnapshotTables). It backs
+// cmd/tools/export and cmd/tools/restore, for cloning an environment or
+// recovering from a lost database without pg_dump.
+//
+// A snapshot is a zip archive with one JSON-lines file per table
+// (<table>.jsonl, one row per line) and a manifest.json naming the archive
+// format version, the schema version (latest applied migration) it was
+// taken at, and the row count of each table. Uploaded files aren't included;
+// copy the blob store separately.
+package snapshot
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"cv-search/internal/storage"
+)
+
+// FormatVersion is the archive layout written by Export. Restore rejects
+// other versions.
+const FormatVersion = 1
+
+const manifestName = "manifest.json"
+
+// importBatchSize is how many rows go into one INSERT on restore.
+const importBatchSize = 500
+
+// Manifest describes a snapshot archive.
+type Manifest struct {
+	FormatVersion int          `json:"format_version"`
+	SchemaVersion int64        `json:"schema_version"`
+	CreatedAt     time.Time    `json:"created_at"`
+	Tables        []TableCount `json:"tables"`
+}
+
+// TableCount is the number of rows a snapshot holds for one table.
+type TableCount struct {
+	Name string `json:"name"`
+	Rows int    `json:"rows"`
+}
+
+// Rows is the total row count.
+func (m *Manifest) Rows() int {
+	n := 0
+	for _, t := range m.Tables {
+		n += t.Rows
+	}
+	return n
+}
+
+// Export writes a snapshot of db to w. The tables are read in one
+// repeatable-read transaction, so the snapshot is consistent even while
+// the API keeps writing.
+func Export(ctx context.Context, db *storage.DB, w io.Writer) (*Manifest, error) {
+	version, err := db.SchemaVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m := &Manifest{FormatVersion: FormatVersion, SchemaVersion: version, CreatedAt: time.Now().UTC()}
+
+	zw := zip.NewWriter(w)
+	err = db.WithTx(ctx, func(tx *storage.DB) error {
+		if err := tx.BeginSnapshotRead(ctx); err != nil {
+			return err
+		}
+		for _, t := range storage.SnapshotTables {
+			f, err := zw.Create(t.Name + ".jsonl")
+			if err != nil {
+				return err
+			}
+			n, err := tx.ExportSnapshotTable(ctx, t, func(row []byte) error {
+				if _, err := f.Write(row); err != nil {
+					return err
+				}
+				_, err := f.Write([]byte{'\n'})
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			m.Tables = append(m.Tables, TableCount{Name: t.Name, Rows: n})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := zw.Create(manifestName)
+	if err != nil {
+		return nil, err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(m); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("write snapshot: %w", err)
+	}
+	return m, nil
+}
+
+// ReadManifest returns the manifest of the snapshot in r.
+func ReadManifest(r io.ReaderAt, size int64) (*Manifest, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("open snapshot: %w", err)
+	}
+	return readManifest(zr)
+}
+
+func readManifest(zr *zip.Reader) (*Manifest, error) {
+	f, err := zr.Open(manifestName)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot has no %s: %w", manifestName, err)
+	}
+	defer f.Close()
+	var m Manifest
+	if err := json.NewDecoder(f).Decode(&m); err != nil {
+		return nil, fmt.Errorf("read %s: %w", manifestName, err)
+	}
+	return &m, nil
+}
+
+// Restore loads the snapshot in r into db in one transaction: either every
+// table is restored or nothing changes. The database must be at the
+// snapshot's schema version (run the migrations up to it first). The
+// snapshot tables must be empty unless replace is set, which truncates them
+// — and whatever references them — first.
+func Restore(ctx context.Context, db *storage.DB, r io.ReaderAt, size int64, replace bool) (*Manifest, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("open snapshot: %w", err)
+	}
+	m, err := readManifest(zr)
+	if err != nil {
+		return nil, err
+	}
+	if m.FormatVersion != FormatVersion {
+		return nil, fmt.Errorf("snapshot format version %d is not supported (want %d)", m.FormatVersion, FormatVersion)
+	}
+	version, err := db.SchemaVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if version != m.SchemaVersion {
+		return nil, fmt.Errorf("snapshot was taken at schema version %d, database is at %d; migrate it to %d first",
+			m.SchemaVersion, version, m.SchemaVersion)
+	}
+	want := make(map[string]int, len(m.Tables))
+	for _, t := range m.Tables {
+		want[t.Name] = t.Rows
+	}
+
+	err = db.WithTx(ctx, func(tx *storage.DB) error {
+		if replace {
+			if err := tx.TruncateSnapshotTables(ctx); err != nil {
+				return err
+			}
+		} else {
+			n, err := tx.SnapshotRowCount(ctx)
+			if err != nil {
+				return err
+			}
+			if n > 0 {
+				return fmt.Errorf("database already holds %d rows in the snapshot tables; restore with replace to overwrite them", n)
+			}
+		}
+
+		for _, t := range storage.SnapshotTables {
+			rows, ok := want[t.Name]
+			if !ok {
+				return fmt.Errorf("snapshot has no %s table", t.Name)
+			}
+			n, err := restoreTable(ctx, tx, zr, t)
+			if err != nil {
+				return err
+			}
+			if n != rows {
+				return fmt.Errorf("%s: read %d rows, manifest says %d", t.Name, n, rows)
+			}
+		}
+		return tx.ResetSnapshotSequences(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func restoreTable(ctx context.Context, tx *storage.DB, zr *zip.Reader, t storage.SnapshotTable) (int, error) {
+	f, err := zr.Open(t.Name + ".jsonl")
+	if err != nil {
+		return 0, fmt.Errorf("snapshot has no %s.jsonl: %w", t.Name, err)
+	}
+	defer f.Close()
+
+	// Rows can be long (parsed text, embeddings), so no bufio.Scanner.
+	br := bufio.NewReader(f)
+	var batch [][]byte
+	n := 0
+	for {
+		line, err := br.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			batch = append(batch, line)
+			n++
+		}
+		if len(batch) == importBatchSize || (errors.Is(err, io.EOF) && len(batch) > 0) {
+			if err := tx.ImportSnapshotRows(ctx, t, batch); err != nil {
+				return n, err
+			}
+			batch = nil
+		}
+		if errors.Is(err, io.EOF) {
+			return n, nil
+		}
+		if err != nil {
+			return n, fmt.Errorf("read %s.jsonl: %w", t.Name, err)
+		}
+	}
+}
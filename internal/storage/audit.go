@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cv-search/internal/tenant"
+)
+
+// ─── Audit log ───────────────────────────────────────────────────────────────
+
+// LogAudit appends an entry to the audit trail. CreatedAt and ID are assigned
+// by the database.
+func (db *DB) LogAudit(ctx context.Context, e AuditEntry) error {
+	var details interface{}
+	if len(e.Details) > 0 {
+		details = []byte(e.Details)
+	}
+	_, err := db.q().ExecContext(ctx, `
+		INSERT INTO audit_log (actor, action, entity_type, entity_id, details, ip, org_id)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, NULLIF($6, ''), $7)`,
+		e.Actor, e.Action, e.EntityType, e.EntityID, details, e.IP, tenant.OrgID(ctx))
+	if err != nil {
+		return fmt.Errorf("log audit %s %s: %w", e.Action, e.EntityType, err)
+	}
+	return nil
+}
+
+// ListAuditLog returns ctx's organization's audit entries matching f,
+// newest first. Limit defaults to 100 and is capped at 1000.
+func (db *DB) ListAuditLog(ctx context.Context, f AuditFilter) ([]AuditEntry, error) {
+	var where []string
+	var args []interface{}
+	add := func(cond string, v interface{}) {
+		args = append(args, v)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+	add("org_id = $%d", tenant.OrgID(ctx))
+	if f.Actor != "" {
+		add("actor = $%d", f.Actor)
+	}
+	if f.Action != "" {
+		add("action = $%d", f.Action)
+	}
+	if f.EntityType != "" {
+		add("entity_type = $%d", f.EntityType)
+	}
+	if f.EntityID != "" {
+		add("entity_id = $%d", f.EntityID)
+	}
+	if f.Since != nil {
+		add("created_at >= $%d", *f.Since)
+	}
+	if f.Until != nil {
+		add("created_at < $%d", *f.Until)
+	}
+
+	limit := f.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 1000 {
+		limit = 1000
+	}
+	offset := f.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := `SELECT id, actor, action, entity_type, COALESCE(entity_id, ''), details, COALESCE(ip, ''), created_at FROM audit_log`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	args = append(args, limit, offset)
+	query += fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := db.r().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list audit log: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []AuditEntry{}
+	for rows.Next() {
+		var e AuditEntry
+		var details []byte
+		if err := rows.Scan(&e.ID, &e.Actor, &e.Action, &e.EntityType, &e.EntityID, &details, &e.IP, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan audit entry: %w", err)
+		}
+		if len(details) > 0 {
+			e.Details = details
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,361 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"cv-search/internal/tenant"
+)
+
+// Lookups by many IDs at once, for the GraphQL dataloaders: each returns a
+// map keyed by the requested ID and simply leaves out IDs that don't exist,
+// are deleted or belong to another organization.
+
+// ─── Candidates ──────────────────────────────────────────────────────────────
+
+// GetCandidatesByIDs returns the candidates' profile fields; Skills,
+// Interviews and Tags are left empty (see the ...ByCandidateIDs lookups).
+func (db *DB) GetCandidatesByIDs(ctx context.Context, ids []int) (map[int]*CandidateDetail, error) {
+	rows, err := db.r().QueryContext(ctx, `
+		SELECT c.id, c.name, COALESCE(c.email, ''), COALESCE(c.phone, ''), COALESCE(c.linkedin_url, ''),
+		       COALESCE(c.location, ''), c.graph_node_id,
+		       COALESCE(gn.properties->>'current_position', ''), COALESCE(gn.properties->>'seniority', ''),
+		       c.created_at
+		FROM candidates c
+		LEFT JOIN graph_nodes gn ON gn.id = c.graph_node_id
+		WHERE c.id = ANY($1) AND c.org_id = $2 AND c.deleted_at IS NULL
+	`, ids, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get candidates by ids: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[int]*CandidateDetail, len(ids))
+	for rows.Next() {
+		var c CandidateDetail
+		var graphNodeID sql.NullInt64
+		if err := rows.Scan(
+			&c.ID, &c.Name, &c.Email, &c.Phone, &c.LinkedInURL, &c.Location, &graphNodeID,
+			&c.CurrentPosition, &c.Seniority, &c.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan candidate: %w", err)
+		}
+		if graphNodeID.Valid {
+			id := int(graphNodeID.Int64)
+			c.GraphNodeID = &id
+		}
+		result[c.ID] = &c
+	}
+	return result, rows.Err()
+}
+
+// GetCandidateIDsByGraphNodeIDs maps person node IDs to their candidate.
+func (db *DB) GetCandidateIDsByGraphNodeIDs(ctx context.Context, nodeIDs []int) (map[int]int, error) {
+	rows, err := db.r().QueryContext(ctx, `
+		SELECT graph_node_id, id FROM candidates
+		WHERE graph_node_id = ANY($1) AND org_id = $2 AND deleted_at IS NULL
+	`, nodeIDs, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get candidate ids by graph nodes: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[int]int, len(nodeIDs))
+	for rows.Next() {
+		var nodeID, candidateID int
+		if err := rows.Scan(&nodeID, &candidateID); err != nil {
+			return nil, fmt.Errorf("scan candidate graph node: %w", err)
+		}
+		result[nodeID] = candidateID
+	}
+	return result, rows.Err()
+}
+
+// GetSkillsByCandidateIDs returns each candidate's skills, longest held first.
+func (db *DB) GetSkillsByCandidateIDs(ctx context.Context, candidateIDs []int) (map[int][]CandidateSkill, error) {
+	rows, err := db.r().QueryContext(ctx, `
+		SELECT cs.candidate_id, sk.name, COALESCE(cs.proficiency, ''), cs.years::float8
+		FROM candidate_skills cs
+		JOIN skills sk ON sk.id = cs.skill_id
+		JOIN candidates c ON c.id = cs.candidate_id
+		WHERE cs.candidate_id = ANY($1) AND c.org_id = $2
+		ORDER BY cs.candidate_id, cs.years DESC NULLS LAST, sk.name
+	`, candidateIDs, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get skills by candidates: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[int][]CandidateSkill, len(candidateIDs))
+	for rows.Next() {
+		var candidateID int
+		var cs CandidateSkill
+		if err := rows.Scan(&candidateID, &cs.Name, &cs.Proficiency, &cs.Years); err != nil {
+			return nil, fmt.Errorf("scan candidate skill: %w", err)
+		}
+		result[candidateID] = append(result[candidateID], cs)
+	}
+	return result, rows.Err()
+}
+
+// GetTagsByCandidateIDs returns each candidate's tags, sorted.
+func (db *DB) GetTagsByCandidateIDs(ctx context.Context, candidateIDs []int) (map[int][]string, error) {
+	rows, err := db.r().QueryContext(ctx, `
+		SELECT t.candidate_id, t.tag
+		FROM candidate_tags t
+		JOIN candidates c ON c.id = t.candidate_id
+		WHERE t.candidate_id = ANY($1) AND c.org_id = $2
+		ORDER BY t.candidate_id, t.tag
+	`, candidateIDs, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get tags by candidates: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[int][]string, len(candidateIDs))
+	for rows.Next() {
+		var candidateID int
+		var tag string
+		if err := rows.Scan(&candidateID, &tag); err != nil {
+			return nil, fmt.Errorf("scan candidate tag: %w", err)
+		}
+		result[candidateID] = append(result[candidateID], tag)
+	}
+	return result, rows.Err()
+}
+
+// GetInterviewsByCandidateIDs returns each candidate's interviews, newest
+// first.
+func (db *DB) GetInterviewsByCandidateIDs(ctx context.Context, candidateIDs []int) (map[int][]Interview, error) {
+	rows, err := db.r().QueryContext(ctx, `
+		SELECT i.id, i.candidate_id, i.interview_date, COALESCE(i.team,''), COALESCE(i.interviewer_name,''),
+		       COALESCE(i.interview_type,''), COALESCE(i.notes,''), COALESCE(i.outcome,''),
+		       i.created_at, i.updated_at
+		FROM interviews i
+		JOIN candidates c ON c.id = i.candidate_id
+		WHERE i.candidate_id = ANY($1) AND c.org_id = $2
+		ORDER BY i.candidate_id, i.interview_date DESC, i.id DESC
+	`, candidateIDs, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get interviews by candidates: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[int][]Interview, len(candidateIDs))
+	for rows.Next() {
+		var iv Interview
+		if err := rows.Scan(
+			&iv.ID, &iv.CandidateID, &iv.InterviewDate, &iv.Team, &iv.InterviewerName,
+			&iv.InterviewType, &iv.Notes, &iv.Outcome, &iv.CreatedAt, &iv.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan interview: %w", err)
+		}
+		result[iv.CandidateID] = append(result[iv.CandidateID], iv)
+	}
+	return result, rows.Err()
+}
+
+// ─── CV files ────────────────────────────────────────────────────────────────
+
+// GetCVFilesByIDs returns the CV files with the given IDs.
+func (db *DB) GetCVFilesByIDs(ctx context.Context, ids []int64) (map[int64]*CVFileListItem, error) {
+	rows, err := db.r().QueryContext(ctx, `
+		SELECT `+cvFileListColumns+`
+		FROM cv_files
+		WHERE id = ANY($1) AND org_id = $2 AND deleted_at IS NULL
+	`, ids, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get cv files by ids: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[int64]*CVFileListItem, len(ids))
+	for rows.Next() {
+		item, err := scanCVFileListItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		result[item.ID] = &item
+	}
+	return result, rows.Err()
+}
+
+// GetCVFilesByCandidateIDs returns each candidate's CV files, newest first.
+func (db *DB) GetCVFilesByCandidateIDs(ctx context.Context, candidateIDs []int) (map[int][]CVFileListItem, error) {
+	rows, err := db.r().QueryContext(ctx, `
+		SELECT `+cvFileListColumns+`
+		FROM cv_files
+		WHERE candidate_id = ANY($1) AND org_id = $2 AND deleted_at IS NULL
+		ORDER BY uploaded_at DESC, id DESC
+	`, candidateIDs, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get cv files by candidates: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[int][]CVFileListItem, len(candidateIDs))
+	for rows.Next() {
+		item, err := scanCVFileListItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		result[*item.CandidateID] = append(result[*item.CandidateID], item)
+	}
+	return result, rows.Err()
+}
+
+// ─── Graph ───────────────────────────────────────────────────────────────────
+
+const graphNodeColumns = `id, node_type, node_id, COALESCE(properties, '{}'::jsonb), created_at`
+
+func scanGraphNode(rows *sql.Rows) (GraphNode, error) {
+	var n GraphNode
+	var props []byte
+	if err := rows.Scan(&n.ID, &n.NodeType, &n.NodeID, &props, &n.CreatedAt); err != nil {
+		return n, fmt.Errorf("scan graph node: %w", err)
+	}
+	n.Properties = props
+	return n, nil
+}
+
+// ListGraphNodes returns live graph nodes, optionally of one type ("" =
+// all), oldest first.
+func (db *DB) ListGraphNodes(ctx context.Context, nodeType string, limit, offset int) ([]GraphNode, error) {
+	rows, err := db.r().QueryContext(ctx, `
+		SELECT `+graphNodeColumns+`
+		FROM graph_nodes
+		WHERE org_id = $1 AND deleted_at IS NULL AND ($2 = '' OR node_type = $2)
+		ORDER BY id
+		LIMIT $3 OFFSET $4
+	`, tenant.OrgID(ctx), nodeType, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("list graph nodes: %w", err)
+	}
+	defer rows.Close()
+
+	var result []GraphNode
+	for rows.Next() {
+		n, err := scanGraphNode(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, n)
+	}
+	return result, rows.Err()
+}
+
+// GetGraphNodesByIDs returns the live graph nodes with the given IDs.
+func (db *DB) GetGraphNodesByIDs(ctx context.Context, ids []int) (map[int]*GraphNode, error) {
+	rows, err := db.r().QueryContext(ctx, `
+		SELECT `+graphNodeColumns+`
+		FROM graph_nodes
+		WHERE id = ANY($1) AND org_id = $2 AND deleted_at IS NULL
+	`, ids, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get graph nodes by ids: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[int]*GraphNode, len(ids))
+	for rows.Next() {
+		n, err := scanGraphNode(rows)
+		if err != nil {
+			return nil, err
+		}
+		result[n.ID] = &n
+	}
+	return result, rows.Err()
+}
+
+// GetGraphEdgesByNodeIDs returns the edges touching each node, as source or
+// target; an edge between two requested nodes is listed under both.
+func (db *DB) GetGraphEdgesByNodeIDs(ctx context.Context, nodeIDs []int) (map[int][]GraphEdge, error) {
+	rows, err := db.r().QueryContext(ctx, `
+		SELECT id, source_node_id, target_node_id, edge_type, COALESCE(properties, '{}'::jsonb)
+		FROM graph_edges
+		WHERE org_id = $2 AND (source_node_id = ANY($1) OR target_node_id = ANY($1))
+		ORDER BY id
+	`, nodeIDs, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get graph edges by nodes: %w", err)
+	}
+	defer rows.Close()
+
+	wanted := make(map[int]bool, len(nodeIDs))
+	for _, id := range nodeIDs {
+		wanted[id] = true
+	}
+	result := make(map[int][]GraphEdge, len(nodeIDs))
+	for rows.Next() {
+		var e GraphEdge
+		var props []byte
+		if err := rows.Scan(&e.ID, &e.SourceNodeID, &e.TargetNodeID, &e.EdgeType, &props); err != nil {
+			return nil, fmt.Errorf("scan graph edge: %w", err)
+		}
+		e.Properties = props
+		if wanted[e.SourceNodeID] {
+			result[e.SourceNodeID] = append(result[e.SourceNodeID], e)
+		}
+		if wanted[e.TargetNodeID] && e.TargetNodeID != e.SourceNodeID {
+			result[e.TargetNodeID] = append(result[e.TargetNodeID], e)
+		}
+	}
+	return result, rows.Err()
+}
+
+// ─── Communities ─────────────────────────────────────────────────────────────
+
+// ListCommunities returns detected communities, largest first, optionally
+// only those of one level (nil = all).
+func (db *DB) ListCommunities(ctx context.Context, level *int, limit, offset int) ([]Community, error) {
+	rows, err := db.r().QueryContext(ctx, `
+		SELECT id, level, community_id, COALESCE(title, ''), COALESCE(summary, ''), COALESCE(node_count, 0),
+		       COALESCE(updated_at, created_at)
+		FROM graph_communities
+		WHERE org_id = $1 AND ($2::int IS NULL OR level = $2)
+		ORDER BY node_count DESC NULLS LAST, id
+		LIMIT $3 OFFSET $4
+	`, tenant.OrgID(ctx), level, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("list communities: %w", err)
+	}
+	defer rows.Close()
+
+	var result []Community
+	for rows.Next() {
+		var c Community
+		if err := rows.Scan(&c.ID, &c.Level, &c.CommunityID, &c.Title, &c.Summary, &c.NodeCount, &c.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan community: %w", err)
+		}
+		result = append(result, c)
+	}
+	return result, rows.Err()
+}
+
+// GetCommunityMemberNodeIDs returns the live member node IDs of each
+// community, strongest membership first.
+func (db *DB) GetCommunityMemberNodeIDs(ctx context.Context, communityIDs []int) (map[int][]int, error) {
+	rows, err := db.r().QueryContext(ctx, `
+		SELECT cm.community_id, cm.node_id
+		FROM community_members cm
+		JOIN graph_communities c ON c.id = cm.community_id
+		JOIN graph_nodes gn ON gn.id = cm.node_id
+		WHERE cm.community_id = ANY($1) AND c.org_id = $2 AND gn.deleted_at IS NULL
+		ORDER BY cm.community_id, cm.membership_strength DESC NULLS LAST, cm.node_id
+	`, communityIDs, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get community members: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[int][]int, len(communityIDs))
+	for rows.Next() {
+		var communityID, nodeID int
+		if err := rows.Scan(&communityID, &nodeID); err != nil {
+			return nil, fmt.Errorf("scan community member: %w", err)
+		}
+		result[communityID] = append(result[communityID], nodeID)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ─── Blob storage ────────────────────────────────────────────────────────────
+//
+// Uploaded CV files live in a BlobStore, addressed by an object key that is
+// stored in cv_files.file_path. Keys are generated server-side, never taken
+// from the client's file name. The local backend keeps files under
+// UPLOADS_DIR; the S3 and GCS backends survive redeploys on ephemeral hosts
+// like Railway.
+
+// ErrBlobNotFound is returned by BlobStore.Get when the key doesn't exist.
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore stores opaque objects by key. Keys use "/" as separator.
+type BlobStore interface {
+	// Put writes r under key, replacing any existing object. size is the
+	// exact byte length of r (object stores need it up front).
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens key for streaming. The caller must close the reader.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// BlobConfig selects and configures a BlobStore backend.
+type BlobConfig struct {
+	Backend         string // local (default) | s3 | gcs
+	LocalDir        string // local: base directory (default ./uploads)
+	Bucket          string // s3/gcs
+	Region          string // s3: bucket region (default us-east-1)
+	Endpoint        string // s3: custom endpoint for S3-compatible stores (MinIO, R2); path-style addressing
+	AccessKeyID     string // s3: access key; gcs: HMAC access ID
+	SecretAccessKey string // s3: secret key; gcs: HMAC secret
+}
+
+// NewBlobStore builds the backend named by cfg.Backend.
+func NewBlobStore(cfg BlobConfig) (BlobStore, error) {
+	switch strings.ToLower(cfg.Backend) {
+	case "", "local":
+		dir := cfg.LocalDir
+		if dir == "" {
+			dir = "./uploads"
+		}
+		return NewLocalBlobStore(dir), nil
+	case "s3":
+		return NewS3BlobStore(cfg)
+	case "gcs":
+		return NewGCSBlobStore(cfg)
+	default:
+		return nil, fmt.Errorf("unknown blob backend %q (want local, s3 or gcs)", cfg.Backend)
+	}
+}
+
+// LocalBlobStore keeps objects as files under a base directory.
+type LocalBlobStore struct {
+	dir string
+}
+
+// NewLocalBlobStore returns a store rooted at dir. The directory is created
+// on first write.
+func NewLocalBlobStore(dir string) *LocalBlobStore {
+	return &LocalBlobStore{dir: dir}
+}
+
+// path maps key to a file under the base directory, rejecting keys that
+// would escape it.
+func (s *LocalBlobStore) path(key string) (string, error) {
+	clean := filepath.Clean("/" + filepath.FromSlash(key))
+	if clean == string(filepath.Separator) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.dir, clean), nil
+}
+
+func (s *LocalBlobStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return fmt.Errorf("create blob dir: %w", err)
+	}
+
+	// Write to a temp file and rename so readers never see a partial object.
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("create blob file: %w", err)
+	}
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write blob %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write blob %s: %w", key, err)
+	}
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write blob %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *LocalBlobStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if os.IsNotExist(err) {
+		return nil, ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open blob %s: %w", key, err)
+	}
+	return f, nil
+}
+
+func (s *LocalBlobStore) Delete(ctx context.Context, key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("delete blob %s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3BlobStore talks to S3 (or any S3-compatible API) over plain HTTP with
+// SigV4 request signing, so no cloud SDK is pulled into the binary. Payloads
+// are sent as UNSIGNED-PAYLOAD, which S3 and GCS accept over HTTPS and which
+// lets uploads stream instead of being hashed up front.
+type S3BlobStore struct {
+	bucket    string
+	region    string
+	endpoint  string // scheme://host, without bucket
+	pathStyle bool   // bucket in the path rather than the host
+	accessKey string
+	secretKey string
+	client    *http.Client
+}
+
+// NewS3BlobStore returns an S3 store. Without cfg.Endpoint it addresses AWS
+// virtual-hosted style (bucket.s3.region.amazonaws.com); with one it uses
+// path-style addressing, as MinIO and most S3-compatible stores expect.
+func NewS3BlobStore(cfg BlobConfig) (*S3BlobStore, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("s3 blob store: bucket is required")
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, fmt.Errorf("s3 blob store: access key ID and secret are required")
+	}
+	region := cfg.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	s := &S3BlobStore{
+		bucket:    cfg.Bucket,
+		region:    region,
+		accessKey: cfg.AccessKeyID,
+		secretKey: cfg.SecretAccessKey,
+		client:    &http.Client{Timeout: 5 * time.Minute},
+	}
+	if cfg.Endpoint != "" {
+		s.endpoint = strings.TrimRight(cfg.Endpoint, "/")
+		s.pathStyle = true
+	} else {
+		s.endpoint = fmt.Sprintf("https://%s.s3.%s.amazonaws.com", cfg.Bucket, region)
+	}
+	return s, nil
+}
+
+// NewGCSBlobStore returns a store for a Google Cloud Storage bucket through
+// its S3-compatible XML API. Credentials are an HMAC key pair created for a
+// service account (Cloud Storage → Settings → Interoperability).
+func NewGCSBlobStore(cfg BlobConfig) (*S3BlobStore, error) {
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://storage.googleapis.com"
+	}
+	if cfg.Region == "" {
+		cfg.Region = "auto"
+	}
+	return NewS3BlobStore(cfg)
+}
+
+func (s *S3BlobStore) objectURL(key string) string {
+	escaped := s3EscapePath(strings.TrimLeft(key, "/"))
+	if s.pathStyle {
+		return s.endpoint + "/" + s3EscapePath(s.bucket) + "/" + escaped
+	}
+	return s.endpoint + "/" + escaped
+}
+
+func (s *S3BlobStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key), r)
+	if err != nil {
+		return fmt.Errorf("put blob %s: %w", key, err)
+	}
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return fmt.Errorf("put blob %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("put blob %s: %s", key, s3ErrorMessage(resp))
+	}
+	return nil
+}
+
+func (s *S3BlobStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(key), nil)
+	if err != nil {
+		return nil, fmt.Errorf("get blob %s: %w", key, err)
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("get blob %s: %w", key, err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrBlobNotFound
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		return nil, fmt.Errorf("get blob %s: %s", key, s3ErrorMessage(resp))
+	}
+	return resp.Body, nil
+}
+
+func (s *S3BlobStore) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key), nil)
+	if err != nil {
+		return fmt.Errorf("delete blob %s: %w", key, err)
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return fmt.Errorf("delete blob %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("delete blob %s: %s", key, s3ErrorMessage(resp))
+	}
+	return nil
+}
+
+func (s *S3BlobStore) do(req *http.Request) (*http.Response, error) {
+	s.sign(req, time.Now().UTC())
+	return s.client.Do(req)
+}
+
+// sign adds AWS Signature Version 4 headers to req.
+func (s *S3BlobStore) sign(req *http.Request, now time.Time) {
+	const payloadHash = "UNSIGNED-PAYLOAD"
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	// Host plus every x-amz-* / content-type header, lower-cased and sorted.
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		lk := strings.ToLower(k)
+		if strings.HasPrefix(lk, "x-amz-") || lk == "content-type" {
+			headers[lk] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonHeaders strings.Builder
+	for _, k := range names {
+		canonHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.Query().Encode(),
+		canonHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + s.region + "/s3/aws4_request"
+	crHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(crHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), day)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// s3EscapePath percent-encodes each path segment the way SigV4 expects
+// (RFC 3986 unreserved characters kept, "/" kept as separator).
+func s3EscapePath(p string) string {
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		if c == '/' || c == '-' || c == '_' || c == '.' || c == '~' ||
+			('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// s3ErrorMessage summarises an error response (status + the XML <Code>).
+func s3ErrorMessage(resp *http.Response) string {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	msg := resp.Status
+	if i := strings.Index(string(body), "<Code>"); i >= 0 {
+		rest := string(body)[i+len("<Code>"):]
+		if j := strings.Index(rest, "</Code>"); j >= 0 {
+			msg += " (" + rest[:j] + ")"
+		}
+	}
+	return msg
+}
@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SyncCandidateTextFields updates the candidates row (experience, skills, location) from
+// the linked graph_nodes data so the tsvector search_vector stays accurate for BM25,
+// and rebuilds candidate_skills from the HAS_SKILL edges (with proficiency/years).
+// The update fires the search_vector trigger, so the candidate is searchable by BM25
+// as soon as this commits. A location already set (recruiter-entered, imported) is kept.
+// Should be called after graph building and after any interview that may update the role.
+func (db *DB) SyncCandidateTextFields(ctx context.Context, candidateID, graphNodeID int) error {
+	return db.WithTx(ctx, func(tx *DB) error {
+		// Derive experience text from node properties (position + seniority)
+		// Derive skills as comma-joined skill names from HAS_SKILL edges
+		_, err := tx.q().ExecContext(ctx, `
+			UPDATE candidates c
+			SET
+			    experience = COALESCE(gn.properties->>'current_position','') ||
+			                 CASE WHEN COALESCE(gn.properties->>'seniority','') <> ''
+			                      THEN ' ' || (gn.properties->>'seniority')
+			                      ELSE '' END,
+			    skills = (
+			        SELECT string_agg(s.properties->>'name', ',')
+			        FROM graph_edges e
+			        JOIN graph_nodes s ON e.target_node_id = s.id
+			        WHERE e.source_node_id = gn.id
+			          AND e.edge_type = 'HAS_SKILL'
+			          AND s.node_type = 'skill'
+			    ),
+			    location = COALESCE(NULLIF(c.location, ''), NULLIF(gn.properties->>'location', ''))
+			FROM graph_nodes gn
+			WHERE gn.id = $1
+			  AND c.id = $2
+		`, graphNodeID, candidateID)
+		if err != nil {
+			return err
+		}
+
+		links, err := tx.graphSkillLinks(ctx, graphNodeID)
+		if err != nil {
+			return err
+		}
+		return tx.replaceCandidateSkills(ctx, candidateID, links)
+	})
+}
+
+// graphSkillLinks reads a person's HAS_SKILL edges as candidate_skills input.
+func (db *DB) graphSkillLinks(ctx context.Context, graphNodeID int) ([]CandidateSkill, error) {
+	rows, err := db.q().QueryContext(ctx, `
+		SELECT COALESCE(s.properties->>'name', ''),
+		       COALESCE(e.properties->>'proficiency', ''),
+		       e.properties->>'years_of_experience'
+		FROM graph_edges e
+		JOIN graph_nodes s ON e.target_node_id = s.id
+		WHERE e.source_node_id = $1
+		  AND e.edge_type = 'HAS_SKILL'
+		  AND s.node_type = 'skill'
+	`, graphNodeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links []CandidateSkill
+	for rows.Next() {
+		var cs CandidateSkill
+		var years sql.NullString
+		if err := rows.Scan(&cs.Name, &cs.Proficiency, &years); err != nil {
+			return nil, err
+		}
+		if v, err := strconv.ParseFloat(years.String, 64); err == nil {
+			cs.Years = &v
+		}
+		links = append(links, cs)
+	}
+	return links, rows.Err()
+}
+
+// normalizeSkillNames lower-cases and trims skill names into the
+// skills.normalized_name form, dropping blanks.
+func normalizeSkillNames(names []string) []string {
+	out := make([]string, 0, len(names))
+	for _, n := range names {
+		if t := strings.ToLower(strings.TrimSpace(n)); t != "" {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// replaceCandidateSkills swaps a candidate's candidate_skills rows for links
+// (call it on a transaction-bound DB),
+// creating skills dimension rows on first sight. Duplicate names collapse to
+// the first occurrence.
+func (db *DB) replaceCandidateSkills(ctx context.Context, candidateID int, links []CandidateSkill) error {
+	if _, err := db.q().ExecContext(ctx, `DELETE FROM candidate_skills WHERE candidate_id = $1`, candidateID); err != nil {
+		return fmt.Errorf("clear candidate skills: %w", err)
+	}
+
+	seen := make(map[string]bool, len(links))
+	for _, l := range links {
+		name := strings.TrimSpace(l.Name)
+		key := strings.ToLower(name)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		var skillID int
+		err := db.q().QueryRowContext(ctx, `
+			INSERT INTO skills (name, normalized_name) VALUES ($1, $2)
+			ON CONFLICT (normalized_name) DO UPDATE SET normalized_name = EXCLUDED.normalized_name
+			RETURNING id
+		`, name, key).Scan(&skillID)
+		if err != nil {
+			return fmt.Errorf("upsert skill %q: %w", name, err)
+		}
+
+		var proficiency interface{}
+		if l.Proficiency != "" {
+			proficiency = l.Proficiency
+		}
+		if _, err := db.q().ExecContext(ctx, `
+			INSERT INTO candidate_skills (candidate_id, skill_id, proficiency, years)
+			VALUES ($1, $2, $3, $4)
+		`, candidateID, skillID, proficiency, l.Years); err != nil {
+			return fmt.Errorf("link candidate skill %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// GetCandidateSkills returns a candidate's skills, most experienced first.
+func (db *DB) GetCandidateSkills(ctx context.Context, candidateID int) ([]CandidateSkill, error) {
+	rows, err := db.q().QueryContext(ctx, `
+		SELECT sk.name, COALESCE(cs.proficiency, ''), cs.years::float8
+		FROM candidate_skills cs
+		JOIN skills sk ON sk.id = cs.skill_id
+		WHERE cs.candidate_id = $1
+		ORDER BY cs.years DESC NULLS LAST, sk.name
+	`, candidateID)
+	if err != nil {
+		return nil, fmt.Errorf("get candidate skills: %w", err)
+	}
+	defer rows.Close()
+
+	var result []CandidateSkill
+	for rows.Next() {
+		var cs CandidateSkill
+		if err := rows.Scan(&cs.Name, &cs.Proficiency, &cs.Years); err != nil {
+			return nil, fmt.Errorf("scan candidate skill: %w", err)
+		}
+		result = append(result, cs)
+	}
+	return result, rows.Err()
+}
+
+// ReindexSearchVectors rebuilds every candidate's BM25 search_vector by
+// firing its update trigger, e.g. after the trigger or the text search
+// configuration changed. Returns the number of candidates reindexed.
+func (db *DB) ReindexSearchVectors(ctx context.Context) (int64, error) {
+	res, err := db.q().ExecContext(ctx, `UPDATE candidates SET name = name WHERE deleted_at IS NULL`)
+	if err != nil {
+		return 0, fmt.Errorf("reindex search vectors: %w", err)
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"cv-search/internal/tenant"
+)
+
+// ─── Candidate status ────────────────────────────────────────────────────────
+
+// SetCandidateStatus changes a candidate's status, records the change in its
+// history and mirrors it on the candidate's person node, which is where the
+// searches read it. Setting the status the candidate already has changes
+// nothing and returns nil. Returns sql.ErrNoRows if the candidate does not
+// exist in ctx's organization.
+func (db *DB) SetCandidateStatus(ctx context.Context, candidateID int, status, reason, actor string) (*CandidateStatusChange, error) {
+	var change *CandidateStatusChange
+	err := db.WithTx(ctx, func(tx *DB) error {
+		var from string
+		var nodeID sql.NullInt64
+		err := tx.q().QueryRowContext(ctx, `
+			SELECT status, graph_node_id FROM candidates
+			WHERE id = $1 AND org_id = $2 AND deleted_at IS NULL
+			FOR UPDATE
+		`, candidateID, tenant.OrgID(ctx)).Scan(&from, &nodeID)
+		if err == sql.ErrNoRows {
+			return err
+		}
+		if err != nil {
+			return fmt.Errorf("load candidate status: %w", err)
+		}
+		if from == status {
+			return nil
+		}
+
+		if _, err := tx.q().ExecContext(ctx, `
+			UPDATE candidates SET status = $2, status_changed_at = NOW() WHERE id = $1
+		`, candidateID, status); err != nil {
+			return fmt.Errorf("update candidate status: %w", err)
+		}
+		c := CandidateStatusChange{CandidateID: candidateID, FromStatus: from, ToStatus: status, Reason: reason, ChangedBy: actor}
+		if err := tx.q().QueryRowContext(ctx, `
+			INSERT INTO candidate_status_history (org_id, candidate_id, from_status, to_status, reason, changed_by)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id, changed_at
+		`, tenant.OrgID(ctx), candidateID, from, status, reason, actor).Scan(&c.ID, &c.ChangedAt); err != nil {
+			return fmt.Errorf("record candidate status change: %w", err)
+		}
+		if nodeID.Valid {
+			if _, err := tx.q().ExecContext(ctx, `
+				UPDATE graph_nodes SET properties = properties || jsonb_build_object('status', $2::text)
+				WHERE id = $1
+			`, nodeID.Int64, status); err != nil {
+				return fmt.Errorf("mirror candidate status: %w", err)
+			}
+		}
+		change = &c
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// ListCandidateStatusHistory returns a candidate's status changes, newest
+// first. Returns sql.ErrNoRows if the candidate does not exist in ctx's
+// organization.
+func (db *DB) ListCandidateStatusHistory(ctx context.Context, candidateID int) ([]CandidateStatusChange, error) {
+	ok, err := db.CandidateInOrg(ctx, candidateID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	rows, err := db.q().QueryContext(ctx, `
+		SELECT id, candidate_id, from_status, to_status, reason, changed_by, changed_at
+		FROM candidate_status_history
+		WHERE candidate_id = $1
+		ORDER BY changed_at DESC, id DESC
+	`, candidateID)
+	if err != nil {
+		return nil, fmt.Errorf("list candidate status history: %w", err)
+	}
+	defer rows.Close()
+
+	history := []CandidateStatusChange{}
+	for rows.Next() {
+		var c CandidateStatusChange
+		if err := rows.Scan(&c.ID, &c.CandidateID, &c.FromStatus, &c.ToStatus, &c.Reason, &c.ChangedBy, &c.ChangedAt); err != nil {
+			return nil, fmt.Errorf("scan candidate status change: %w", err)
+		}
+		history = append(history, c)
+	}
+	return history, rows.Err()
+}
@@ -0,0 +1,359 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"cv-search/internal/tenant"
+)
+
+// UpsertCandidateForGraphNode creates or links a candidate record for a graph person node.
+// If a candidate with the same graph_node_id already exists it's a no-op.
+// If a candidate with the same name exists but no graph_node_id, we link it.
+// Returns the candidate row ID.
+func (db *DB) UpsertCandidateForGraphNode(ctx context.Context, graphNodeID int, name string) (int, error) {
+	var candidateID int
+
+	// A re-upload of a soft-deleted candidate's CV brings the row back.
+	if _, err := db.q().ExecContext(ctx,
+		`UPDATE candidates SET deleted_at = NULL WHERE graph_node_id = $1 AND deleted_at IS NOT NULL`, graphNodeID,
+	); err != nil {
+		return 0, fmt.Errorf("upsert restore failed: %w", err)
+	}
+
+	// Check if already linked
+	err := db.q().QueryRowContext(ctx,
+		`SELECT id FROM candidates WHERE graph_node_id = $1`, graphNodeID,
+	).Scan(&candidateID)
+	if err == nil {
+		return candidateID, nil // already exists
+	}
+	if err != sql.ErrNoRows {
+		return 0, fmt.Errorf("upsert check failed: %w", err)
+	}
+
+	// Insert new candidate row
+	query := `
+		INSERT INTO candidates (name, graph_node_id, org_id, created_at, updated_at)
+		VALUES ($1, $2, $3, NOW(), NOW())
+		ON CONFLICT DO NOTHING
+		RETURNING id
+	`
+	err = db.q().QueryRowContext(ctx, query, name, graphNodeID, tenant.OrgID(ctx)).Scan(&candidateID)
+	if err != nil {
+		// Might have been inserted by a concurrent request; try fetching again
+		err2 := db.q().QueryRowContext(ctx,
+			`SELECT id FROM candidates WHERE graph_node_id = $1`, graphNodeID,
+		).Scan(&candidateID)
+		if err2 != nil {
+			return 0, fmt.Errorf("upsert candidate failed: %w", err)
+		}
+	}
+	return candidateID, nil
+}
+
+// claimCandidateByEmail returns the candidate with this email address and,
+// when graphNodeID > 0, links it to that person node. Returns 0 when there is
+// none, or it is already linked to a different person node, or another
+// candidate already has this one.
+func (db *DB) claimCandidateByEmail(ctx context.Context, email string, graphNodeID int) (int, error) {
+	var candidateID int
+	err := db.q().QueryRowContext(ctx, `
+		UPDATE candidates c
+		SET graph_node_id = COALESCE(NULLIF($2, 0), c.graph_node_id), updated_at = NOW()
+		WHERE c.id = (
+			SELECT id FROM candidates
+			WHERE lower(email) = lower($1) AND org_id = $3 AND deleted_at IS NULL
+			ORDER BY id
+			LIMIT 1
+		)
+		  AND ($2 = 0 OR c.graph_node_id IS NULL OR c.graph_node_id = $2)
+		  AND ($2 = 0 OR NOT EXISTS (SELECT 1 FROM candidates o WHERE o.graph_node_id = $2 AND o.id <> c.id))
+		RETURNING c.id
+	`, email, graphNodeID, tenant.OrgID(ctx)).Scan(&candidateID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("claim candidate by email: %w", err)
+	}
+	return candidateID, nil
+}
+
+// insertCandidateForContact creates a candidate for a CV that has contact
+// details but no person node yet.
+func (db *DB) insertCandidateForContact(ctx context.Context, name string, contact CandidateContact) (int, error) {
+	var candidateID int
+	if err := db.q().QueryRowContext(ctx, `
+		INSERT INTO candidates (name, email, phone, linkedin_url, org_id, created_at, updated_at)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), NULLIF($4, ''), $5, NOW(), NOW())
+		RETURNING id
+	`, name, contact.Email, contact.Phone, contact.LinkedInURL, tenant.OrgID(ctx)).Scan(&candidateID); err != nil {
+		return 0, fmt.Errorf("insert candidate: %w", err)
+	}
+	return candidateID, nil
+}
+
+// fillCandidateContact sets a candidate's email, phone and LinkedIn URL
+// where they are still empty; recruiter-entered values are never replaced.
+func (db *DB) fillCandidateContact(ctx context.Context, candidateID int, contact CandidateContact) error {
+	if contact == (CandidateContact{}) {
+		return nil
+	}
+	if _, err := db.q().ExecContext(ctx, `
+		UPDATE candidates SET
+			email        = COALESCE(NULLIF(email, ''), NULLIF($2, '')),
+			phone        = COALESCE(NULLIF(phone, ''), NULLIF($3, '')),
+			linkedin_url = COALESCE(NULLIF(linkedin_url, ''), NULLIF($4, '')),
+			updated_at   = NOW()
+		WHERE id = $1
+	`, candidateID, contact.Email, contact.Phone, contact.LinkedInURL); err != nil {
+		return fmt.Errorf("fill candidate contact: %w", err)
+	}
+	return nil
+}
+
+// ListCandidates returns a paginated list of candidates with basic enrichment from graph_nodes.
+func (db *DB) ListCandidates(ctx context.Context, limit, offset int) ([]CandidateListItem, error) {
+	return db.listCandidates(ctx, "TRUE", limit, offset)
+}
+
+// ListCandidatesByStatus is ListCandidates for the candidates of status.
+func (db *DB) ListCandidatesByStatus(ctx context.Context, status string, limit, offset int) ([]CandidateListItem, error) {
+	return db.listCandidates(ctx, "c.status = $4", limit, offset, status)
+}
+
+// listCandidates returns ctx's organization's candidates matching the SQL
+// condition cond, newest first. cond's parameters, if any, are args from $4.
+func (db *DB) listCandidates(ctx context.Context, cond string, limit, offset int, args ...interface{}) ([]CandidateListItem, error) {
+	rows, err := db.r().QueryContext(ctx, `
+		SELECT
+			c.id,
+			c.name,
+			COALESCE(gn.properties->>'current_position', '') AS current_position,
+			COALESCE(gn.properties->>'seniority', '')         AS seniority,
+			c.status,
+			(SELECT COUNT(*) FROM interviews i WHERE i.candidate_id = c.id) AS interview_count,
+			COALESCE(
+				(SELECT outcome FROM interviews
+				 WHERE candidate_id = c.id
+				 ORDER BY interview_date DESC, id DESC
+				 LIMIT 1),
+				''
+			)                                                  AS latest_outcome,
+			`+profileMissingSQL()+` AS missing,
+			c.created_at
+		FROM candidates c
+		LEFT JOIN graph_nodes gn ON gn.id = c.graph_node_id
+		WHERE c.deleted_at IS NULL AND c.org_id = $3 AND `+cond+`
+		ORDER BY c.created_at DESC
+		LIMIT $1 OFFSET $2
+	`, append([]interface{}{limit, offset, tenant.OrgID(ctx)}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("list candidates failed: %w", err)
+	}
+	defer rows.Close()
+
+	var result []CandidateListItem
+	for rows.Next() {
+		var item CandidateListItem
+		var missing []byte
+		if err := rows.Scan(
+			&item.ID, &item.Name, &item.CurrentPosition, &item.Seniority, &item.Status,
+			&item.InterviewCount, &item.LatestOutcome, &missing, &item.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan candidate list row: %w", err)
+		}
+		if item.Completeness, err = decodeProfileCompleteness(missing); err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+	return result, rows.Err()
+}
+
+// GetCandidateDetail returns the full candidate profile with all interviews.
+func (db *DB) GetCandidateDetail(ctx context.Context, candidateID int) (*CandidateDetail, error) {
+	var c CandidateDetail
+	var graphNodeID sql.NullInt64
+	var email, phone, linkedInURL, location sql.NullString
+	var statusChangedAt sql.NullTime
+	var missing []byte
+
+	err := db.q().QueryRowContext(ctx, `
+		SELECT
+			c.id, c.name, c.email, c.phone, c.linkedin_url, c.location, c.status, c.status_changed_at, c.graph_node_id,
+			COALESCE(gn.properties->>'current_position', '') AS current_position,
+			COALESCE(gn.properties->>'seniority', '')         AS seniority,
+			`+profileMissingSQL()+` AS missing,
+			c.created_at
+		FROM candidates c
+		LEFT JOIN graph_nodes gn ON gn.id = c.graph_node_id
+		WHERE c.id = $1 AND c.org_id = $2 AND c.deleted_at IS NULL
+	`, candidateID, tenant.OrgID(ctx)).Scan(
+		&c.ID, &c.Name, &email, &phone, &linkedInURL, &location, &c.Status, &statusChangedAt, &graphNodeID,
+		&c.CurrentPosition, &c.Seniority, &missing, &c.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get candidate detail: %w", err)
+	}
+	if c.Completeness, err = decodeProfileCompleteness(missing); err != nil {
+		return nil, err
+	}
+
+	if email.Valid {
+		c.Email = email.String
+	}
+	if phone.Valid {
+		c.Phone = phone.String
+	}
+	if linkedInURL.Valid {
+		c.LinkedInURL = linkedInURL.String
+	}
+	if location.Valid {
+		c.Location = location.String
+	}
+	if statusChangedAt.Valid {
+		c.StatusChangedAt = &statusChangedAt.Time
+	}
+	if graphNodeID.Valid {
+		id := int(graphNodeID.Int64)
+		c.GraphNodeID = &id
+	}
+
+	// Try to fill empty email, phone, location from parsed CV text & entities if they are empty
+	if c.Email == "" || c.Phone == "" || c.Location == "" {
+		var cvFileID int
+		var parsedText string
+		err := db.q().QueryRowContext(ctx, `
+			SELECT id, parsed_text FROM cv_files WHERE candidate_id = $1 AND deleted_at IS NULL LIMIT 1
+		`, candidateID).Scan(&cvFileID, &parsedText)
+		if err == nil {
+			if c.Email == "" {
+				emailMatch := regexp.MustCompile(`[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}`).FindString(parsedText)
+				if emailMatch != "" {
+					c.Email = emailMatch
+				}
+			}
+			if c.Phone == "" {
+				phoneMatch := regexp.MustCompile(`(?:(?:\+?90|0)\s*)?5\d{2}[-\s]?\d{3}[-\s]?\d{4}`).FindString(parsedText)
+				if phoneMatch != "" {
+					c.Phone = phoneMatch
+				}
+			}
+			if c.Location == "" {
+				var locVal string
+				errLoc := db.q().QueryRowContext(ctx, `
+					SELECT entity_value FROM cv_entities WHERE cv_file_id = $1 AND entity_type = 'location' LIMIT 1
+				`, cvFileID).Scan(&locVal)
+				if errLoc == nil && locVal != "" {
+					c.Location = locVal
+				}
+			}
+		}
+	}
+
+	skills, err := db.GetCandidateSkills(ctx, candidateID)
+	if err != nil {
+		return nil, err
+	}
+	c.Skills = skills
+
+	// Load interviews
+	interviews, err := db.getInterviewsByCandidate(ctx, candidateID)
+	if err != nil {
+		return nil, err
+	}
+	c.Interviews = interviews
+
+	tags, err := db.getTagNamesByCandidate(ctx, candidateID)
+	if err != nil {
+		return nil, err
+	}
+	c.Tags = tags
+
+	return &c, nil
+}
+
+// GetGraphNodeIDForCandidate returns the graph_node_id for a given candidate.
+// Returns 0 if the candidate has no linked graph node.
+func (db *DB) GetGraphNodeIDForCandidate(ctx context.Context, candidateID int) (int, error) {
+	var nodeID sql.NullInt64
+	err := db.q().QueryRowContext(ctx,
+		`SELECT graph_node_id FROM candidates WHERE id = $1 AND org_id = $2 AND deleted_at IS NULL`, candidateID, tenant.OrgID(ctx),
+	).Scan(&nodeID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !nodeID.Valid {
+		return 0, nil
+	}
+	return int(nodeID.Int64), nil
+}
+
+// GetPersonGraphNodeIDByName returns the graph_nodes.id for a person with the given name.
+// Returns 0 if not found.
+func (db *DB) GetPersonGraphNodeIDByName(ctx context.Context, name string) (int, error) {
+	var id int
+	err := db.q().QueryRowContext(ctx, `
+		SELECT id FROM graph_nodes
+		WHERE node_type = 'person' AND properties->>'name' = $1 AND org_id = $2 AND deleted_at IS NULL
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, name, tenant.OrgID(ctx)).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return id, err
+}
+
+// GetCandidateIDsByPersonNodeIDs maps person node_ids (e.g. "person_2") to
+// candidates.id. Node IDs with no linked candidate are absent from the map.
+func (db *DB) GetCandidateIDsByPersonNodeIDs(ctx context.Context, personNodeIDs []string) (map[string]int, error) {
+	result := make(map[string]int, len(personNodeIDs))
+	if len(personNodeIDs) == 0 {
+		return result, nil
+	}
+
+	placeholders := make([]string, len(personNodeIDs))
+	args := make([]interface{}, len(personNodeIDs))
+	for i, id := range personNodeIDs {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = id
+	}
+
+	query := fmt.Sprintf(`
+		SELECT gn.node_id, c.id
+		FROM candidates c
+		JOIN graph_nodes gn ON gn.id = c.graph_node_id
+		WHERE gn.node_id IN (%s)
+		  AND gn.org_id = $%d
+		  AND c.deleted_at IS NULL
+	`, strings.Join(placeholders, ","), len(personNodeIDs)+1)
+	args = append(args, tenant.OrgID(ctx))
+
+	rows, err := db.q().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("get candidate ids by node ids: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var nodeID string
+		var candidateID int
+		if err := rows.Scan(&nodeID, &candidateID); err != nil {
+			return nil, fmt.Errorf("scan candidate id: %w", err)
+		}
+		result[nodeID] = candidateID
+	}
+	return result, rows.Err()
+}
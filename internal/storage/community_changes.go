@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"cv-search/internal/tenant"
+)
+
+// ListCommunityChanges returns ctx's organization's community changelog,
+// newest first: of level and detected after since (all if nil), at most
+// limit entries.
+func (db *DB) ListCommunityChanges(ctx context.Context, level *int, since *time.Time, limit int) ([]CommunityChange, error) {
+	rows, err := db.r().QueryContext(ctx, `
+		SELECT ch.id, ch.community_id, c.community_id, ch.level, ch.change, array_to_json(ch.reasons),
+		       ch.title, ch.previous_title, ch.size, ch.previous_size,
+		       ch.churn, ch.cohesion, ch.previous_cohesion, ch.resummarized, ch.detected_at
+		FROM community_changes ch
+		JOIN graph_communities c ON c.id = ch.community_id
+		WHERE ch.org_id = $1
+		  AND ($2::int IS NULL OR ch.level = $2)
+		  AND ($3::timestamptz IS NULL OR ch.detected_at > $3)
+		ORDER BY ch.detected_at DESC, ch.id DESC
+		LIMIT $4
+	`, tenant.OrgID(ctx), level, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list community changes: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []CommunityChange{}
+	for rows.Next() {
+		var c CommunityChange
+		var reasonsJSON []byte
+		if err := rows.Scan(&c.ID, &c.CommunityID, &c.Key, &c.Level, &c.Change, &reasonsJSON,
+			&c.Title, &c.PreviousTitle, &c.Size, &c.PreviousSize,
+			&c.Churn, &c.Cohesion, &c.PreviousCohesion, &c.Resummarized, &c.DetectedAt); err != nil {
+			return nil, fmt.Errorf("scan community change: %w", err)
+		}
+		c.Reasons = []string{}
+		if len(reasonsJSON) > 0 {
+			if err := json.Unmarshal(reasonsJSON, &c.Reasons); err != nil {
+				return nil, fmt.Errorf("decode community change reasons: %w", err)
+			}
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ─── Community patterns ──────────────────────────────────────────────────────
+
+// ListOrgCommunityPatterns returns an organization's own community patterns
+// by ID. It reads the primary so a change is seen by the next search.
+func (db *DB) ListOrgCommunityPatterns(ctx context.Context, orgID int) ([]OrgCommunityPattern, error) {
+	rows, err := db.q().QueryContext(ctx, `
+		SELECT pattern_id, name, array_to_json(key_skills), array_to_json(key_titles), updated_by, updated_at
+		FROM organization_community_patterns
+		WHERE org_id = $1
+		ORDER BY pattern_id
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("list organization %d community patterns: %w", orgID, err)
+	}
+	defer rows.Close()
+
+	out := []OrgCommunityPattern{}
+	for rows.Next() {
+		var p OrgCommunityPattern
+		var skills, titles []byte
+		if err := rows.Scan(&p.PatternID, &p.Name, &skills, &titles, &p.UpdatedBy, &p.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan community pattern: %w", err)
+		}
+		if err := json.Unmarshal(skills, &p.KeySkills); err != nil {
+			return nil, fmt.Errorf("decode community pattern %s key_skills: %w", p.PatternID, err)
+		}
+		if err := json.Unmarshal(titles, &p.KeyTitles); err != nil {
+			return nil, fmt.Errorf("decode community pattern %s key_titles: %w", p.PatternID, err)
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// SaveOrgCommunityPattern creates or replaces an organization's community
+// pattern with p.PatternID and sets p.UpdatedAt.
+func (db *DB) SaveOrgCommunityPattern(ctx context.Context, orgID int, p *OrgCommunityPattern) error {
+	err := db.q().QueryRowContext(ctx, `
+		INSERT INTO organization_community_patterns (org_id, pattern_id, name, key_skills, key_titles, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		ON CONFLICT (org_id, pattern_id) DO UPDATE SET
+			name       = EXCLUDED.name,
+			key_skills = EXCLUDED.key_skills,
+			key_titles = EXCLUDED.key_titles,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = NOW()
+		RETURNING updated_at
+	`, orgID, p.PatternID, p.Name, p.KeySkills, p.KeyTitles, p.UpdatedBy).Scan(&p.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("save organization %d community pattern %s: %w", orgID, p.PatternID, err)
+	}
+	return nil
+}
+
+// DeleteOrgCommunityPattern removes an organization's community pattern.
+// It reports false if there was none.
+func (db *DB) DeleteOrgCommunityPattern(ctx context.Context, orgID int, patternID string) (bool, error) {
+	res, err := db.q().ExecContext(ctx,
+		`DELETE FROM organization_community_patterns WHERE org_id = $1 AND pattern_id = $2`, orgID, patternID)
+	if err != nil {
+		return false, fmt.Errorf("delete organization %d community pattern %s: %w", orgID, patternID, err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ─── Profile completeness ────────────────────────────────────────────────────
+
+// profileChecks are what a complete profile has, each with the SQL that is
+// true when candidate c (person node gn, NULL if the candidate has none)
+// has it. Backfill jobs fill most gaps: repair_properties -locations
+// resolves locations, a re-extraction (POST /api/cv/upload with force=true)
+// the rest.
+var profileChecks = []struct {
+	name    string
+	present string
+}{
+	{"current_position", `COALESCE(gn.properties->>'current_position', '') <> ''`},
+	{"education", `EXISTS (SELECT 1 FROM graph_edges e WHERE e.source_node_id = gn.id AND e.edge_type = 'GRADUATED_FROM')`},
+	{"skill_years", `EXISTS (SELECT 1 FROM candidate_skills cs WHERE cs.candidate_id = c.id AND cs.years IS NOT NULL)`},
+	{"location", `gn.properties ? 'location_id'`},
+}
+
+// ProfileChecks returns the names of the completeness checks, the values of
+// ProfileCompleteness.Missing.
+func ProfileChecks() []string {
+	names := make([]string, len(profileChecks))
+	for i, chk := range profileChecks {
+		names[i] = chk.name
+	}
+	return names
+}
+
+// profileMissingSQL is a jsonb array of the checks candidate c fails, in
+// profileChecks order.
+func profileMissingSQL() string {
+	cases := make([]string, len(profileChecks))
+	for i, chk := range profileChecks {
+		cases[i] = fmt.Sprintf("CASE WHEN %s THEN NULL ELSE '%s' END", chk.present, chk.name)
+	}
+	return "to_jsonb(ARRAY_REMOVE(ARRAY[" + strings.Join(cases, ", ") + "]::text[], NULL))"
+}
+
+// profileCheckSQL returns the SQL that is true when candidate c has the
+// check named name; ok is false for an unknown name.
+func profileCheckSQL(name string) (sql string, ok bool) {
+	for _, chk := range profileChecks {
+		if chk.name == name {
+			return chk.present, true
+		}
+	}
+	return "", false
+}
+
+// decodeProfileCompleteness builds a ProfileCompleteness from a
+// profileMissingSQL value.
+func decodeProfileCompleteness(missingJSON []byte) (*ProfileCompleteness, error) {
+	var missing []string
+	if err := json.Unmarshal(missingJSON, &missing); err != nil {
+		return nil, fmt.Errorf("decode missing profile fields: %w", err)
+	}
+	passed := float64(len(profileChecks) - len(missing))
+	return &ProfileCompleteness{
+		Score:   math.Round(passed/float64(len(profileChecks))*100) / 100,
+		Missing: missing,
+	}, nil
+}
+
+// ListIncompleteCandidates is ListCandidates for the candidates whose profile
+// fails a completeness check: missing if given (one of ProfileChecks),
+// any otherwise.
+func (db *DB) ListIncompleteCandidates(ctx context.Context, missing string, limit, offset int) ([]CandidateListItem, error) {
+	cond := `jsonb_array_length(` + profileMissingSQL() + `) > 0`
+	if missing != "" {
+		present, ok := profileCheckSQL(missing)
+		if !ok {
+			return nil, fmt.Errorf("unknown completeness check %q", missing)
+		}
+		cond = `NOT COALESCE(` + present + `, FALSE)`
+	}
+	return db.listCandidates(ctx, cond, limit, offset)
+}
@@ -0,0 +1,432 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"cv-search/internal/tenant"
+)
+
+// SaveCVFileWithHash saves CV file with content hash for duplicate detection
+func (db *DB) SaveCVFileWithHash(ctx context.Context, candidateID *int, filename, filePath, fileType, parsedText string, fileSize int64, contentHash string) (int, error) {
+	var cvID int
+	query := `
+        INSERT INTO cv_files (candidate_id, filename, file_path, file_type, file_size, parsed_text, content_hash, org_id, uploaded_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+        ON CONFLICT (org_id, content_hash) DO UPDATE 
+        SET uploaded_at = NOW(), deleted_at = NULL, file_path = EXCLUDED.file_path
+        RETURNING id
+    `
+
+	log.Printf("[DB] Saving CV with hash: %s (length: %d)", contentHash[:16], len(contentHash))
+
+	err := db.q().QueryRowContext(ctx, query,
+		candidateID, filename, filePath, fileType, fileSize, parsedText, contentHash, tenant.OrgID(ctx),
+	).Scan(&cvID)
+
+	if err != nil {
+		log.Printf("[DB] Error saving CV with hash: %v", err)
+		return 0, err
+	}
+
+	log.Printf("[DB] CV saved successfully with ID: %d", cvID)
+	return cvID, nil
+}
+
+// FindCVByHash checks if a CV with the same content hash already exists
+func (db *DB) FindCVByHash(ctx context.Context, contentHash string) (*CVFileInfo, error) {
+	var info CVFileInfo
+	query := `
+        SELECT id, filename, COALESCE(file_path, ''), COALESCE(file_type, ''), file_size, uploaded_at, candidate_id, ocr_used, anonymized
+        FROM cv_files
+        WHERE content_hash = $1 AND org_id = $2 AND deleted_at IS NULL
+        LIMIT 1
+    `
+	err := db.q().QueryRowContext(ctx, query, contentHash, tenant.OrgID(ctx)).Scan(
+		&info.ID, &info.Filename, &info.FilePath, &info.FileType, &info.FileSize, &info.UploadedAt, &info.CandidateID, &info.OCRUsed, &info.Anonymized,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil // Not found
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// GetCVFile returns an uploaded CV's metadata, or nil if it doesn't exist or
+// was deleted.
+func (db *DB) GetCVFile(ctx context.Context, cvFileID int64) (*CVFileInfo, error) {
+	var info CVFileInfo
+	var tags []byte
+	err := db.q().QueryRowContext(ctx, `
+		SELECT id, filename, COALESCE(file_path, ''), COALESCE(file_type, ''), file_size, uploaded_at, candidate_id, ocr_used, anonymized,
+		       COALESCE(photo_key, ''), COALESCE(source, ''), array_to_json(upload_tags)
+		FROM cv_files
+		WHERE id = $1 AND org_id = $2 AND deleted_at IS NULL
+	`, cvFileID, tenant.OrgID(ctx)).Scan(
+		&info.ID, &info.Filename, &info.FilePath, &info.FileType, &info.FileSize, &info.UploadedAt, &info.CandidateID, &info.OCRUsed, &info.Anonymized,
+		&info.PhotoKey, &info.Source, &tags,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get cv file %d: %w", cvFileID, err)
+	}
+	if err := json.Unmarshal(tags, &info.UploadTags); err != nil {
+		return nil, fmt.Errorf("decode cv file %d upload tags: %w", cvFileID, err)
+	}
+	return &info, nil
+}
+
+// SetCVFileUploadMetadata stores what the uploader said about a CV: its
+// source ("" = none) and the (normalized) tags for its candidate.
+func (db *DB) SetCVFileUploadMetadata(ctx context.Context, cvFileID int64, source string, tags []string) error {
+	if tags == nil {
+		tags = []string{}
+	}
+	if _, err := db.q().ExecContext(ctx, `
+		UPDATE cv_files SET source = NULLIF($2, ''), upload_tags = $3 WHERE id = $1 AND org_id = $4
+	`, cvFileID, source, tags, tenant.OrgID(ctx)); err != nil {
+		return fmt.Errorf("set cv file %d upload metadata: %w", cvFileID, err)
+	}
+	return nil
+}
+
+// AddCVFileUploadTags adds tags to a CV's upload tags (a duplicate upload's
+// tags, for the candidate extraction links the CV to).
+func (db *DB) AddCVFileUploadTags(ctx context.Context, cvFileID int64, tags []string) error {
+	if _, err := db.q().ExecContext(ctx, `
+		UPDATE cv_files
+		SET upload_tags = ARRAY(SELECT DISTINCT t FROM unnest(upload_tags || $2::text[]) AS t ORDER BY t)
+		WHERE id = $1 AND org_id = $3
+	`, cvFileID, tags, tenant.OrgID(ctx)); err != nil {
+		return fmt.Errorf("add cv file %d upload tags: %w", cvFileID, err)
+	}
+	return nil
+}
+
+// MarkCVFileOCR flags a CV whose text came from the OCR fallback.
+func (db *DB) MarkCVFileOCR(ctx context.Context, cvFileID int) error {
+	if _, err := db.q().ExecContext(ctx, `UPDATE cv_files SET ocr_used = TRUE WHERE id = $1 AND org_id = $2`, cvFileID, tenant.OrgID(ctx)); err != nil {
+		return fmt.Errorf("mark cv file %d ocr: %w", cvFileID, err)
+	}
+	return nil
+}
+
+// MarkCVFileAnonymized flags a CV whose parsed text had its PII masked.
+func (db *DB) MarkCVFileAnonymized(ctx context.Context, cvFileID int) error {
+	if _, err := db.q().ExecContext(ctx, `UPDATE cv_files SET anonymized = TRUE WHERE id = $1 AND org_id = $2`, cvFileID, tenant.OrgID(ctx)); err != nil {
+		return fmt.Errorf("mark cv file %d anonymized: %w", cvFileID, err)
+	}
+	return nil
+}
+
+// SetCVFileQuality stores the parse quality of a CV (see cv.ScoreQuality).
+func (db *DB) SetCVFileQuality(ctx context.Context, cvFileID int64, status string, score float64, issues []string) error {
+	if issues == nil {
+		issues = []string{}
+	}
+	if _, err := db.q().ExecContext(ctx, `
+		UPDATE cv_files SET quality_status = $2, quality_score = $3, quality_issues = $4 WHERE id = $1 AND org_id = $5
+	`, cvFileID, status, score, issues, tenant.OrgID(ctx)); err != nil {
+		return fmt.Errorf("set cv file %d quality: %w", cvFileID, err)
+	}
+	return nil
+}
+
+// ListCVFiles returns uploaded CVs, newest first, optionally only those with
+// the given quality status ("" = all).
+func (db *DB) ListCVFiles(ctx context.Context, quality string, limit, offset int) ([]CVFileListItem, error) {
+	rows, err := db.r().QueryContext(ctx, `
+		SELECT `+cvFileListColumns+`
+		FROM cv_files
+		WHERE deleted_at IS NULL AND org_id = $4 AND ($1 = '' OR quality_status = $1)
+		ORDER BY uploaded_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`, quality, limit, offset, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("list cv files: %w", err)
+	}
+	defer rows.Close()
+
+	var result []CVFileListItem
+	for rows.Next() {
+		item, err := scanCVFileListItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+	return result, rows.Err()
+}
+
+// cvFileListColumns are the cv_files columns scanCVFileListItem reads.
+const cvFileListColumns = `id, filename, COALESCE(file_type, ''), file_size, uploaded_at, candidate_id, ocr_used, anonymized,
+		       photo_key IS NOT NULL, quality_status, quality_score, array_to_json(quality_issues),
+		       COALESCE(source, ''), array_to_json(upload_tags)`
+
+func scanCVFileListItem(rows *sql.Rows) (CVFileListItem, error) {
+	var item CVFileListItem
+	var score sql.NullFloat64
+	var issues, tags []byte
+	if err := rows.Scan(
+		&item.ID, &item.Filename, &item.FileType, &item.FileSize, &item.UploadedAt, &item.CandidateID,
+		&item.OCRUsed, &item.Anonymized, &item.HasPhoto, &item.QualityStatus, &score, &issues,
+		&item.Source, &tags,
+	); err != nil {
+		return item, fmt.Errorf("scan cv file row: %w", err)
+	}
+	if score.Valid {
+		item.QualityScore = &score.Float64
+	}
+	if err := json.Unmarshal(issues, &item.QualityIssues); err != nil {
+		return item, fmt.Errorf("decode cv file %d quality issues: %w", item.ID, err)
+	}
+	if err := json.Unmarshal(tags, &item.UploadTags); err != nil {
+		return item, fmt.Errorf("decode cv file %d upload tags: %w", item.ID, err)
+	}
+	return item, nil
+}
+
+// SaveCVSections stores the section segmentation of a CV's parsed text
+// (a JSON array, see cv.MarshalSections).
+func (db *DB) SaveCVSections(ctx context.Context, cvFileID int64, sections []byte) error {
+	if _, err := db.q().ExecContext(ctx, `UPDATE cv_files SET sections = $2 WHERE id = $1 AND org_id = $3`, cvFileID, sections, tenant.OrgID(ctx)); err != nil {
+		return fmt.Errorf("save cv file %d sections: %w", cvFileID, err)
+	}
+	return nil
+}
+
+// GetCVSections returns a CV's stored sections, or nil if it hasn't been
+// segmented.
+func (db *DB) GetCVSections(ctx context.Context, cvFileID int64) ([]byte, error) {
+	var sections []byte
+	err := db.r().QueryRowContext(ctx, `SELECT sections FROM cv_files WHERE id = $1 AND org_id = $2`, cvFileID, tenant.OrgID(ctx)).Scan(&sections)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get cv file %d sections: %w", cvFileID, err)
+	}
+	return sections, nil
+}
+
+// SaveCVHeader stores the fields read from a Word CV's header (a JSON
+// cv.DocumentHeader).
+func (db *DB) SaveCVHeader(ctx context.Context, cvFileID int64, header []byte) error {
+	if _, err := db.q().ExecContext(ctx, `UPDATE cv_files SET header = $2 WHERE id = $1 AND org_id = $3`, cvFileID, header, tenant.OrgID(ctx)); err != nil {
+		return fmt.Errorf("save cv file %d header: %w", cvFileID, err)
+	}
+	return nil
+}
+
+// GetCVHeader returns a CV's stored header fields, or nil if it has none.
+func (db *DB) GetCVHeader(ctx context.Context, cvFileID int64) ([]byte, error) {
+	var header []byte
+	err := db.r().QueryRowContext(ctx, `SELECT header FROM cv_files WHERE id = $1 AND org_id = $2`, cvFileID, tenant.OrgID(ctx)).Scan(&header)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get cv file %d header: %w", cvFileID, err)
+	}
+	return header, nil
+}
+
+// SetCVFilePhoto records the blob key of a CV's embedded photo.
+func (db *DB) SetCVFilePhoto(ctx context.Context, cvFileID int64, key string) error {
+	if _, err := db.q().ExecContext(ctx, `UPDATE cv_files SET photo_key = $2 WHERE id = $1 AND org_id = $3`, cvFileID, key, tenant.OrgID(ctx)); err != nil {
+		return fmt.Errorf("set cv file %d photo: %w", cvFileID, err)
+	}
+	return nil
+}
+
+// SaveCVChunks replaces the stored chunks of a CV's text.
+func (db *DB) SaveCVChunks(ctx context.Context, cvFileID int64, chunks []CVChunk) error {
+	if _, err := db.q().ExecContext(ctx, `DELETE FROM cv_chunks WHERE cv_file_id = $1`, cvFileID); err != nil {
+		return fmt.Errorf("save cv file %d chunks: %w", cvFileID, err)
+	}
+	for _, c := range chunks {
+		if _, err := db.q().ExecContext(ctx, `
+			INSERT INTO cv_chunks (cv_file_id, chunk_index, text, token_count)
+			VALUES ($1, $2, $3, $4)
+		`, cvFileID, c.Index, c.Text, c.Tokens); err != nil {
+			return fmt.Errorf("save cv file %d chunk %d: %w", cvFileID, c.Index, err)
+		}
+	}
+	return nil
+}
+
+// GetCVChunks returns a CV's chunks in order, or nil if it has none.
+func (db *DB) GetCVChunks(ctx context.Context, cvFileID int64) ([]CVChunk, error) {
+	rows, err := db.r().QueryContext(ctx, `
+		SELECT chunk_index, text, token_count
+		FROM cv_chunks
+		WHERE cv_file_id = $1
+		ORDER BY chunk_index
+	`, cvFileID)
+	if err != nil {
+		return nil, fmt.Errorf("get cv file %d chunks: %w", cvFileID, err)
+	}
+	defer rows.Close()
+
+	var chunks []CVChunk
+	for rows.Next() {
+		var c CVChunk
+		if err := rows.Scan(&c.Index, &c.Text, &c.Tokens); err != nil {
+			return nil, fmt.Errorf("scan cv file %d chunk: %w", cvFileID, err)
+		}
+		chunks = append(chunks, c)
+	}
+	return chunks, rows.Err()
+}
+
+// GetPreviousCVProfile returns what the latest other CV of the candidate
+// with this email said (its cv_entities), or nil if there is none. Seniority
+// and position fall back to the candidate's person node for CVs extracted
+// before those were saved as entities. Call it before the new CV's graph is
+// built, which overwrites the node.
+func (db *DB) GetPreviousCVProfile(ctx context.Context, cvFileID int64, email string) (*PreviousCVProfile, error) {
+	var p PreviousCVProfile
+	var seniority, position string
+	err := db.q().QueryRowContext(ctx, `
+		SELECT cf.id,
+		       COALESCE(gn.properties->>'seniority', ''),
+		       COALESCE(gn.properties->>'current_position', '')
+		FROM candidates c
+		JOIN cv_files cf ON cf.candidate_id = c.id
+		LEFT JOIN graph_nodes gn ON gn.id = c.graph_node_id
+		WHERE lower(c.email) = lower($2) AND c.org_id = $3 AND c.deleted_at IS NULL
+		  AND cf.id <> $1 AND cf.deleted_at IS NULL
+		ORDER BY cf.uploaded_at DESC, cf.id DESC
+		LIMIT 1
+	`, cvFileID, email, tenant.OrgID(ctx)).Scan(&p.CVFileID, &seniority, &position)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get previous cv of cv file %d: %w", cvFileID, err)
+	}
+
+	rows, err := db.q().QueryContext(ctx, `
+		SELECT entity_type, entity_value FROM cv_entities WHERE cv_file_id = $1 ORDER BY id
+	`, p.CVFileID)
+	if err != nil {
+		return nil, fmt.Errorf("get entities of cv file %d: %w", p.CVFileID, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var entityType, value string
+		if err := rows.Scan(&entityType, &value); err != nil {
+			return nil, fmt.Errorf("scan cv entity: %w", err)
+		}
+		switch entityType {
+		case "skill":
+			p.Skills = append(p.Skills, value)
+		case "company":
+			p.Companies = append(p.Companies, value)
+		case "certification":
+			p.Certifications = append(p.Certifications, value)
+		case "language":
+			p.Languages = append(p.Languages, value)
+		case "seniority":
+			p.Seniority = value
+		case "position":
+			p.Position = value
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if p.Seniority == "" {
+		p.Seniority = seniority
+	}
+	if p.Position == "" {
+		p.Position = position
+	}
+	return &p, nil
+}
+
+// SaveCVChanges links a CV to the previous version it was compared with and
+// stores the diff (JSON, see cv.Changes).
+func (db *DB) SaveCVChanges(ctx context.Context, cvFileID, previousCVFileID int64, changes []byte) error {
+	if _, err := db.q().ExecContext(ctx, `
+		UPDATE cv_files SET previous_cv_file_id = $2, changes = $3 WHERE id = $1 AND org_id = $4
+	`, cvFileID, previousCVFileID, changes, tenant.OrgID(ctx)); err != nil {
+		return fmt.Errorf("save cv file %d changes: %w", cvFileID, err)
+	}
+	return nil
+}
+
+// GetCVChanges returns the previous version of a CV and the stored diff
+// against it; both are nil for a first upload. found is false if the CV
+// doesn't exist or was deleted.
+func (db *DB) GetCVChanges(ctx context.Context, cvFileID int64) (previousCVFileID *int64, changes []byte, found bool, err error) {
+	err = db.r().QueryRowContext(ctx, `
+		SELECT previous_cv_file_id, changes FROM cv_files WHERE id = $1 AND org_id = $2 AND deleted_at IS NULL
+	`, cvFileID, tenant.OrgID(ctx)).Scan(&previousCVFileID, &changes)
+	if err == sql.ErrNoRows {
+		return nil, nil, false, nil
+	}
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("get cv file %d changes: %w", cvFileID, err)
+	}
+	return previousCVFileID, changes, true, nil
+}
+
+// SaveCVEntity saves extracted entity from CV
+func (db *DB) SaveCVEntity(ctx context.Context, cvFileID int, entityType, entityValue string, confidence float64) error {
+	query := `
+        INSERT INTO cv_entities (cv_file_id, entity_type, entity_value, confidence)
+        VALUES ($1, $2, $3, $4)
+    `
+	_, err := db.q().ExecContext(ctx, query, cvFileID, entityType, entityValue, confidence)
+	return err
+}
+
+// DeleteCVEntities removes a CV's extracted entities, so a re-extraction
+// replaces them instead of adding a second set.
+func (db *DB) DeleteCVEntities(ctx context.Context, cvFileID int) error {
+	if _, err := db.q().ExecContext(ctx, `DELETE FROM cv_entities WHERE cv_file_id = $1`, cvFileID); err != nil {
+		return fmt.Errorf("delete entities of cv file %d: %w", cvFileID, err)
+	}
+	return nil
+}
+
+// UpdateCVFileCandidateID sets candidate_id on a cv_files row.
+func (db *DB) UpdateCVFileCandidateID(ctx context.Context, cvFileID int64, candidateID int) error {
+	_, err := db.q().ExecContext(ctx,
+		`UPDATE cv_files SET candidate_id = $1 WHERE id = $2`, candidateID, cvFileID,
+	)
+	return err
+}
+
+// LinkCVFileToCandidate points a CV file of ctx's organization at one of
+// its candidates. Returns sql.ErrNoRows if either doesn't exist.
+func (db *DB) LinkCVFileToCandidate(ctx context.Context, cvFileID int64, candidateID int) error {
+	ok, err := db.CandidateInOrg(ctx, candidateID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return sql.ErrNoRows
+	}
+	res, err := db.q().ExecContext(ctx, `
+		UPDATE cv_files SET candidate_id = $1
+		WHERE id = $2 AND org_id = $3 AND deleted_at IS NULL
+	`, candidateID, cvFileID, tenant.OrgID(ctx))
+	if err != nil {
+		return fmt.Errorf("link cv file %d to candidate %d: %w", cvFileID, candidateID, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -5,83 +5,58 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"regexp"
-	"strconv"
 	"strings"
 	"time"
 
-	"github.com/lib/pq"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+
+	"cv-search/internal/tenant"
 )
 
 type DB struct {
 	connection *sql.DB
+	reads      *sql.DB // replica pool (see NewDBWithReplica); same as connection without one
+	tx         *sql.Tx // set on the copy handed to WithTx callbacks
 }
 
+// NewDB opens a pgx-backed *sql.DB. pgx keeps a per-connection statement
+// cache, so concurrent queries no longer collide on prepared statement names.
+// Behind a transaction-mode pooler (PgBouncer, Supabase :6543) prepared
+// statements can't be reused across server connections; add
+// default_query_exec_mode=simple_protocol (or exec / describe_exec) to the
+// DSN there — pgx reads it from the connection string.
 func NewDB(dataSourceName string) (*DB, error) {
-	// Note: We use DEALLOCATE ALL in query methods to prevent prepared statement cache collisions
-	// instead of prefer_simple_protocol which is not supported by lib/pq driver
-
-	db, err := sql.Open("postgres", dataSourceName)
+	connConfig, err := pgx.ParseConfig(dataSourceName)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("parse database url: %w", err)
 	}
+	db := stdlib.OpenDB(*connConfig)
 
 	// Connection pool tuning
-	// Reduce pool size to minimize prepared statement cache collisions
-	db.SetMaxOpenConns(5)                   // Reduced from 25
-	db.SetMaxIdleConns(2)                   // Reduced from 10
-	db.SetConnMaxLifetime(1 * time.Minute)  // Reduced from 5 minutes
-	db.SetConnMaxIdleTime(30 * time.Second) // Force conn reset
+	db.SetMaxOpenConns(25)
+	db.SetMaxIdleConns(10)
+	db.SetConnMaxLifetime(5 * time.Minute)
+	db.SetConnMaxIdleTime(1 * time.Minute)
 
 	if err := db.Ping(); err != nil {
 		return nil, err
 	}
 
-	return &DB{connection: db}, nil
+	return &DB{connection: db, reads: db}, nil
 }
 
 func (db *DB) Close() {
+	if db.reads != db.connection {
+		if err := db.reads.Close(); err != nil {
+			log.Println("Error closing the replica connection:", err)
+		}
+	}
 	if err := db.connection.Close(); err != nil {
 		log.Println("Error closing the database connection:", err)
 	}
 }
 
-// SaveCandidate is kept for backward compatibility and calls the context-aware variant.
-func (db *DB) SaveCandidate(candidate *Candidate) error {
-	return db.SaveCandidateContext(context.Background(), candidate)
-}
-
-func (db *DB) SaveCandidateContext(ctx context.Context, candidate *Candidate) error {
-	query := `INSERT INTO candidates (name, email, experience, skills, location, resume_url, resume_file_path, resume_downloaded_at)
-              VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-              ON CONFLICT (email) DO UPDATE
-                SET name = EXCLUDED.name,
-                    experience = EXCLUDED.experience,
-                    skills = EXCLUDED.skills,
-                    location = EXCLUDED.location,
-                    resume_url = EXCLUDED.resume_url,
-                    resume_file_path = EXCLUDED.resume_file_path,
-                    resume_downloaded_at = EXCLUDED.resume_downloaded_at`
-	skills := strings.Join(candidate.Skills, ",")
-
-	var resumeDownloadedAt interface{}
-	if candidate.ResumeFilePath != "" {
-		resumeDownloadedAt = time.Now()
-	}
-
-	_, err := db.connection.ExecContext(ctx, query,
-		candidate.Name,
-		candidate.Email,
-		candidate.Experience,
-		skills,
-		candidate.Location,
-		candidate.ResumeURL,
-		candidate.ResumeFilePath,
-		resumeDownloadedAt,
-	)
-	return err
-}
-
 // GetCandidateByEmail is kept for backward compatibility and calls the context-aware variant.
 func (db *DB) GetCandidateByEmail(email string) (*Candidate, error) {
 	return db.GetCandidateByEmailContext(context.Background(), email)
@@ -89,8 +64,8 @@ func (db *DB) GetCandidateByEmail(email string) (*Candidate, error) {
 
 func (db *DB) GetCandidateByEmailContext(ctx context.Context, email string) (*Candidate, error) {
 	candidate := &Candidate{}
-	query := `SELECT name, email, experience, skills, location FROM candidates WHERE email = $1`
-	row := db.connection.QueryRowContext(ctx, query, email)
+	query := `SELECT name, email, experience, skills, location FROM candidates WHERE email = $1 AND org_id = $2 AND deleted_at IS NULL`
+	row := db.q().QueryRowContext(ctx, query, email, tenant.OrgID(ctx))
 	var skills string
 	err := row.Scan(&candidate.Name, &candidate.Email, &candidate.Experience, &skills, &candidate.Location)
 	if err != nil {
@@ -102,21 +77,32 @@ func (db *DB) GetCandidateByEmailContext(ctx context.Context, email string) (*Ca
 	return candidate, nil
 }
 
-// SearchCandidates returns candidates matching the provided criteria using ILIKE and simple skills match.
+// SearchCandidates returns candidates matching the provided criteria: ILIKE /
+// trigram on name, ILIKE on location, and exact normalized skill names.
+// FuzzyNameThreshold is the minimum pg_trgm similarity for a candidate name to
+// count as a match when the substring match fails.
+const FuzzyNameThreshold = 0.4
+
 func (db *DB) SearchCandidates(ctx context.Context, criteria *Criteria) ([]*Candidate, error) {
 	base := `SELECT name, email, experience, skills, location FROM candidates`
-	var where []string
-	var args []interface{}
-	i := 1
+	where := []string{"deleted_at IS NULL", "org_id = $1"}
+	args := []interface{}{tenant.OrgID(ctx)}
+	i := 2
 
 	if criteria == nil {
 		criteria = &Criteria{}
 	}
 
+	orderBy := ""
 	if criteria.Name != "" {
-		where = append(where, fmt.Sprintf("name ILIKE $%d", i))
-		args = append(args, "%"+criteria.Name+"%")
-		i++
+		// Substring match first; trigram similarity (accent-folded) catches
+		// transliterations and typos like "Yıldız" vs "Yildiz".
+		where = append(where, fmt.Sprintf(
+			"(name ILIKE $%d OR (immutable_unaccent(name) %% immutable_unaccent($%d) AND similarity(immutable_unaccent(name), immutable_unaccent($%d)) >= $%d))",
+			i, i+1, i+1, i+2))
+		args = append(args, "%"+criteria.Name+"%", criteria.Name, FuzzyNameThreshold)
+		orderBy = fmt.Sprintf(" ORDER BY similarity(immutable_unaccent(name), immutable_unaccent($%d)) DESC", i+1)
+		i += 3
 	}
 	if criteria.Location != "" {
 		where = append(where, fmt.Sprintf("location ILIKE $%d", i))
@@ -124,20 +110,21 @@ func (db *DB) SearchCandidates(ctx context.Context, criteria *Criteria) ([]*Cand
 		i++
 	}
 	if len(criteria.Skills) > 0 {
-		var skillConds []string
-		for _, s := range criteria.Skills {
-			skillConds = append(skillConds, fmt.Sprintf("skills ILIKE $%d", i))
-			args = append(args, "%"+s+"%")
-			i++
-		}
-		where = append(where, "("+strings.Join(skillConds, " OR ")+")")
+		// Any-of match on the normalized skill name (idx_candidate_skills_skill).
+		where = append(where, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM candidate_skills cs
+			JOIN skills sk ON sk.id = cs.skill_id
+			WHERE cs.candidate_id = candidates.id AND sk.normalized_name = ANY($%d))`, i))
+		args = append(args, normalizeSkillNames(criteria.Skills))
+		i++
 	}
 
 	if len(where) > 0 {
 		base += " WHERE " + strings.Join(where, " AND ")
 	}
+	base += orderBy
 
-	rows, err := db.connection.QueryContext(ctx, base, args...)
+	rows, err := db.r().QueryContext(ctx, base, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -161,16 +148,16 @@ func (db *DB) SearchCandidates(ctx context.Context, criteria *Criteria) ([]*Cand
 // CandidateExists checks if a candidate with the given email already exists
 func (db *DB) CandidateExists(ctx context.Context, email string) (bool, error) {
 	var exists bool
-	query := `SELECT EXISTS(SELECT 1 FROM candidates WHERE email = $1)`
-	err := db.connection.QueryRowContext(ctx, query, email).Scan(&exists)
+	query := `SELECT EXISTS(SELECT 1 FROM candidates WHERE email = $1 AND org_id = $2)`
+	err := db.q().QueryRowContext(ctx, query, email, tenant.OrgID(ctx)).Scan(&exists)
 	return exists, err
 }
 
 // GetCandidateLastUpdated returns the last update timestamp for a candidate
 func (db *DB) GetCandidateLastUpdated(ctx context.Context, email string) (time.Time, error) {
 	var updatedAt time.Time
-	query := `SELECT updated_at FROM candidates WHERE email = $1`
-	err := db.connection.QueryRowContext(ctx, query, email).Scan(&updatedAt)
+	query := `SELECT updated_at FROM candidates WHERE email = $1 AND org_id = $2`
+	err := db.q().QueryRowContext(ctx, query, email, tenant.OrgID(ctx)).Scan(&updatedAt)
 	return updatedAt, err
 }
 
@@ -209,933 +196,7 @@ func splitAndTrim(s string) []string {
 	return out
 }
 
-// SaveCVFileWithHash saves CV file with content hash for duplicate detection
-func (db *DB) SaveCVFileWithHash(ctx context.Context, candidateID *int, filename, filePath, fileType, parsedText string, fileSize int64, contentHash string) (int, error) {
-	var cvID int
-	query := `
-        INSERT INTO cv_files (candidate_id, filename, file_path, file_type, file_size, parsed_text, content_hash, uploaded_at)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
-        ON CONFLICT (content_hash) DO UPDATE 
-        SET uploaded_at = NOW()
-        RETURNING id
-    `
-
-	log.Printf("[DB] Saving CV with hash: %s (length: %d)", contentHash[:16], len(contentHash))
-
-	err := db.connection.QueryRowContext(ctx, query,
-		candidateID, filename, filePath, fileType, fileSize, parsedText, contentHash,
-	).Scan(&cvID)
-
-	if err != nil {
-		log.Printf("[DB] Error saving CV with hash: %v", err)
-		return 0, err
-	}
-
-	log.Printf("[DB] CV saved successfully with ID: %d", cvID)
-	return cvID, nil
-}
-
-// FindCVByHash checks if a CV with the same content hash already exists
-func (db *DB) FindCVByHash(ctx context.Context, contentHash string) (*CVFileInfo, error) {
-	var info CVFileInfo
-	query := `
-        SELECT id, filename, file_size, uploaded_at, candidate_id
-        FROM cv_files
-        WHERE content_hash = $1
-        LIMIT 1
-    `
-	err := db.connection.QueryRowContext(ctx, query, contentHash).Scan(
-		&info.ID, &info.Filename, &info.FileSize, &info.UploadedAt, &info.CandidateID,
-	)
-
-	if err == sql.ErrNoRows {
-		return nil, nil // Not found
-	}
-	if err != nil {
-		return nil, err
-	}
-
-	return &info, nil
-}
-
-// SaveCVEntity saves extracted entity from CV
-func (db *DB) SaveCVEntity(ctx context.Context, cvFileID int, entityType, entityValue string, confidence float64) error {
-	query := `
-        INSERT INTO cv_entities (cv_file_id, entity_type, entity_value, confidence)
-        VALUES ($1, $2, $3, $4)
-    `
-	_, err := db.connection.ExecContext(ctx, query, cvFileID, entityType, entityValue, confidence)
-	return err
-}
-
 // GetConnection returns the underlying database connection for advanced queries
 func (db *DB) GetConnection() *sql.DB {
 	return db.connection
 }
-
-// ─── Candidate Management ────────────────────────────────────────────────────
-
-// UpsertCandidateForGraphNode creates or links a candidate record for a graph person node.
-// If a candidate with the same graph_node_id already exists it's a no-op.
-// If a candidate with the same name exists but no graph_node_id, we link it.
-// Returns the candidate row ID.
-func (db *DB) UpsertCandidateForGraphNode(ctx context.Context, graphNodeID int, name string) (int, error) {
-	var candidateID int
-
-	// Check if already linked
-	err := db.connection.QueryRowContext(ctx,
-		`SELECT id FROM candidates WHERE graph_node_id = $1`, graphNodeID,
-	).Scan(&candidateID)
-	if err == nil {
-		return candidateID, nil // already exists
-	}
-	if err != sql.ErrNoRows {
-		return 0, fmt.Errorf("upsert check failed: %w", err)
-	}
-
-	// Insert new candidate row
-	query := `
-		INSERT INTO candidates (name, graph_node_id, created_at, updated_at)
-		VALUES ($1, $2, NOW(), NOW())
-		ON CONFLICT DO NOTHING
-		RETURNING id
-	`
-	err = db.connection.QueryRowContext(ctx, query, name, graphNodeID).Scan(&candidateID)
-	if err != nil {
-		// Might have been inserted by a concurrent request; try fetching again
-		err2 := db.connection.QueryRowContext(ctx,
-			`SELECT id FROM candidates WHERE graph_node_id = $1`, graphNodeID,
-		).Scan(&candidateID)
-		if err2 != nil {
-			return 0, fmt.Errorf("upsert candidate failed: %w", err)
-		}
-	}
-	return candidateID, nil
-}
-
-// ListCandidates returns a paginated list of candidates with basic enrichment from graph_nodes.
-func (db *DB) ListCandidates(ctx context.Context, limit, offset int) ([]CandidateListItem, error) {
-	db.connection.ExecContext(ctx, "DEALLOCATE ALL")
-
-	query := `
-		SELECT
-			c.id,
-			c.name,
-			COALESCE(gn.properties->>'current_position', '') AS current_position,
-			COALESCE(gn.properties->>'seniority', '')         AS seniority,
-			COUNT(i.id)                                        AS interview_count,
-			COALESCE(
-				(SELECT outcome FROM interviews
-				 WHERE candidate_id = c.id
-				 ORDER BY interview_date DESC, id DESC
-				 LIMIT 1),
-				''
-			)                                                  AS latest_outcome,
-			c.created_at
-		FROM candidates c
-		LEFT JOIN graph_nodes gn ON gn.id = c.graph_node_id
-		LEFT JOIN interviews i   ON i.candidate_id = c.id
-		GROUP BY c.id, gn.properties
-		ORDER BY c.created_at DESC
-		LIMIT $1 OFFSET $2
-	`
-	rows, err := db.connection.QueryContext(ctx, query, limit, offset)
-	if err != nil {
-		return nil, fmt.Errorf("list candidates failed: %w", err)
-	}
-	defer rows.Close()
-
-	var result []CandidateListItem
-	for rows.Next() {
-		var item CandidateListItem
-		if err := rows.Scan(
-			&item.ID, &item.Name, &item.CurrentPosition, &item.Seniority,
-			&item.InterviewCount, &item.LatestOutcome, &item.CreatedAt,
-		); err != nil {
-			return nil, fmt.Errorf("scan candidate list row: %w", err)
-		}
-		result = append(result, item)
-	}
-	return result, rows.Err()
-}
-
-// GetCandidateDetail returns the full candidate profile with all interviews.
-func (db *DB) GetCandidateDetail(ctx context.Context, candidateID int) (*CandidateDetail, error) {
-	db.connection.ExecContext(ctx, "DEALLOCATE ALL")
-
-	var c CandidateDetail
-	var graphNodeID sql.NullInt64
-	var email, phone, location sql.NullString
-
-	err := db.connection.QueryRowContext(ctx, `
-		SELECT
-			c.id, c.name, c.email, c.phone, c.location, c.graph_node_id,
-			COALESCE(gn.properties->>'current_position', '') AS current_position,
-			COALESCE(gn.properties->>'seniority', '')         AS seniority,
-			c.created_at
-		FROM candidates c
-		LEFT JOIN graph_nodes gn ON gn.id = c.graph_node_id
-		WHERE c.id = $1
-	`, candidateID).Scan(
-		&c.ID, &c.Name, &email, &phone, &location, &graphNodeID,
-		&c.CurrentPosition, &c.Seniority, &c.CreatedAt,
-	)
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
-	if err != nil {
-		return nil, fmt.Errorf("get candidate detail: %w", err)
-	}
-
-	if email.Valid {
-		c.Email = email.String
-	}
-	if phone.Valid {
-		c.Phone = phone.String
-	}
-	if location.Valid {
-		c.Location = location.String
-	}
-	if graphNodeID.Valid {
-		id := int(graphNodeID.Int64)
-		c.GraphNodeID = &id
-	}
-
-	// Try to fill empty email, phone, location from parsed CV text & entities if they are empty
-	if c.Email == "" || c.Phone == "" || c.Location == "" {
-		var cvFileID int
-		var parsedText string
-		err := db.connection.QueryRowContext(ctx, `
-			SELECT id, parsed_text FROM cv_files WHERE candidate_id = $1 LIMIT 1
-		`, candidateID).Scan(&cvFileID, &parsedText)
-		if err == nil {
-			if c.Email == "" {
-				emailMatch := regexp.MustCompile(`[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}`).FindString(parsedText)
-				if emailMatch != "" {
-					c.Email = emailMatch
-				}
-			}
-			if c.Phone == "" {
-				phoneMatch := regexp.MustCompile(`(?:(?:\+?90|0)\s*)?5\d{2}[-\s]?\d{3}[-\s]?\d{4}`).FindString(parsedText)
-				if phoneMatch != "" {
-					c.Phone = phoneMatch
-				}
-			}
-			if c.Location == "" {
-				var locVal string
-				errLoc := db.connection.QueryRowContext(ctx, `
-					SELECT entity_value FROM cv_entities WHERE cv_file_id = $1 AND entity_type = 'location' LIMIT 1
-				`, cvFileID).Scan(&locVal)
-				if errLoc == nil && locVal != "" {
-					c.Location = locVal
-				}
-			}
-		}
-	}
-
-	// Load interviews
-	interviews, err := db.getInterviewsByCandidate(ctx, candidateID)
-	if err != nil {
-		return nil, err
-	}
-	c.Interviews = interviews
-
-	return &c, nil
-}
-
-func (db *DB) getInterviewsByCandidate(ctx context.Context, candidateID int) ([]Interview, error) {
-	rows, err := db.connection.QueryContext(ctx, `
-		SELECT id, candidate_id, interview_date, COALESCE(team,''), COALESCE(interviewer_name,''),
-		       COALESCE(interview_type,''), COALESCE(notes,''), COALESCE(outcome,''),
-		       created_at, updated_at
-		FROM interviews
-		WHERE candidate_id = $1
-		ORDER BY interview_date DESC, id DESC
-	`, candidateID)
-	if err != nil {
-		return nil, fmt.Errorf("get interviews: %w", err)
-	}
-	defer rows.Close()
-
-	var result []Interview
-	for rows.Next() {
-		var iv Interview
-		if err := rows.Scan(
-			&iv.ID, &iv.CandidateID, &iv.InterviewDate, &iv.Team, &iv.InterviewerName,
-			&iv.InterviewType, &iv.Notes, &iv.Outcome, &iv.CreatedAt, &iv.UpdatedAt,
-		); err != nil {
-			return nil, fmt.Errorf("scan interview: %w", err)
-		}
-		result = append(result, iv)
-	}
-	return result, rows.Err()
-}
-
-// GetInterviewsByGraphNodeIDs batch-fetches interview summaries for a list of graph node IDs.
-// Returns a map of graphNodeID → []InterviewSummary for use in search result enrichment.
-func (db *DB) GetInterviewsByGraphNodeIDs(ctx context.Context, graphNodeIDs []int) (map[int][]InterviewSummary, error) {
-	if len(graphNodeIDs) == 0 {
-		return map[int][]InterviewSummary{}, nil
-	}
-
-	placeholders := make([]string, len(graphNodeIDs))
-	args := make([]interface{}, len(graphNodeIDs))
-	for i, id := range graphNodeIDs {
-		placeholders[i] = fmt.Sprintf("$%d", i+1)
-		args[i] = id
-	}
-	inClause := "(" + strings.Join(placeholders, ",") + ")"
-
-	query := fmt.Sprintf(`
-		SELECT c.graph_node_id, i.id, i.interview_date,
-		       COALESCE(i.team,''), COALESCE(i.interviewer_name,''),
-		       COALESCE(i.interview_type,''), COALESCE(i.outcome,'')
-		FROM interviews i
-		JOIN candidates c ON c.id = i.candidate_id
-		WHERE c.graph_node_id IN %s
-		ORDER BY i.interview_date DESC
-	`, inClause)
-
-	rows, err := db.connection.QueryContext(ctx, query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("batch get interviews: %w", err)
-	}
-	defer rows.Close()
-
-	result := make(map[int][]InterviewSummary)
-	for rows.Next() {
-		var nodeID int
-		var s InterviewSummary
-		if err := rows.Scan(
-			&nodeID, &s.ID, &s.InterviewDate, &s.Team, &s.InterviewerName, &s.InterviewType, &s.Outcome,
-		); err != nil {
-			return nil, fmt.Errorf("scan interview summary: %w", err)
-		}
-		result[nodeID] = append(result[nodeID], s)
-	}
-	return result, rows.Err()
-}
-
-// GetInterviewNotesByGraphNodeID returns all interview notes concatenated for re-embedding.
-func (db *DB) GetInterviewNotesByGraphNodeID(ctx context.Context, graphNodeID int) ([]string, error) {
-	rows, err := db.connection.QueryContext(ctx, `
-		SELECT COALESCE(i.notes, '')
-		FROM interviews i
-		JOIN candidates c ON c.id = i.candidate_id
-		WHERE c.graph_node_id = $1 AND i.notes <> ''
-		ORDER BY i.interview_date DESC
-	`, graphNodeID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var notes []string
-	for rows.Next() {
-		var n string
-		if err := rows.Scan(&n); err != nil {
-			return nil, err
-		}
-		notes = append(notes, n)
-	}
-	return notes, rows.Err()
-}
-
-// ─── Interview CRUD ───────────────────────────────────────────────────────────
-
-// CreateInterview inserts a new interview record and returns the new ID.
-// Returns an error if the candidate does not exist.
-func (db *DB) CreateInterview(ctx context.Context, candidateID int, iv Interview) (int, error) {
-	var newID int
-	err := db.connection.QueryRowContext(ctx, `
-		INSERT INTO interviews (candidate_id, interview_date, team, interviewer_name, interview_type, notes, outcome, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
-		RETURNING id
-	`, candidateID, iv.InterviewDate, iv.Team, iv.InterviewerName, iv.InterviewType, iv.Notes, iv.Outcome,
-	).Scan(&newID)
-	if err != nil {
-		return 0, fmt.Errorf("create interview: %w", err)
-	}
-	return newID, nil
-}
-
-// UpdateInterview updates an existing interview. Returns sql.ErrNoRows if not found
-// or if the interview doesn't belong to the given candidateID.
-func (db *DB) UpdateInterview(ctx context.Context, interviewID, candidateID int, iv Interview) error {
-	res, err := db.connection.ExecContext(ctx, `
-		UPDATE interviews
-		SET interview_date = $1, team = $2, interviewer_name = $3,
-		    interview_type = $4, notes = $5, outcome = $6, updated_at = NOW()
-		WHERE id = $7 AND candidate_id = $8
-	`, iv.InterviewDate, iv.Team, iv.InterviewerName, iv.InterviewType, iv.Notes, iv.Outcome,
-		interviewID, candidateID,
-	)
-	if err != nil {
-		return fmt.Errorf("update interview: %w", err)
-	}
-	n, _ := res.RowsAffected()
-	if n == 0 {
-		return sql.ErrNoRows
-	}
-	return nil
-}
-
-// DeleteInterview removes a specific interview. Enforces candidateID ownership.
-func (db *DB) DeleteInterview(ctx context.Context, interviewID, candidateID int) error {
-	res, err := db.connection.ExecContext(ctx, `
-		DELETE FROM interviews WHERE id = $1 AND candidate_id = $2
-	`, interviewID, candidateID)
-	if err != nil {
-		return fmt.Errorf("delete interview: %w", err)
-	}
-	n, _ := res.RowsAffected()
-	if n == 0 {
-		return sql.ErrNoRows
-	}
-	return nil
-}
-
-// GetGraphNodeIDForCandidate returns the graph_node_id for a given candidate.
-// Returns 0 if the candidate has no linked graph node.
-func (db *DB) GetGraphNodeIDForCandidate(ctx context.Context, candidateID int) (int, error) {
-	var nodeID sql.NullInt64
-	err := db.connection.QueryRowContext(ctx,
-		`SELECT graph_node_id FROM candidates WHERE id = $1`, candidateID,
-	).Scan(&nodeID)
-	if err == sql.ErrNoRows {
-		return 0, nil
-	}
-	if err != nil {
-		return 0, err
-	}
-	if !nodeID.Valid {
-		return 0, nil
-	}
-	return int(nodeID.Int64), nil
-}
-
-// UpdateCVFileCandidateID sets candidate_id on a cv_files row.
-func (db *DB) UpdateCVFileCandidateID(ctx context.Context, cvFileID int64, candidateID int) error {
-	_, err := db.connection.ExecContext(ctx,
-		`UPDATE cv_files SET candidate_id = $1 WHERE id = $2`, candidateID, cvFileID,
-	)
-	return err
-}
-
-// GetPersonGraphNodeIDByName returns the graph_nodes.id for a person with the given name.
-// Returns 0 if not found.
-func (db *DB) GetPersonGraphNodeIDByName(ctx context.Context, name string) (int, error) {
-	var id int
-	err := db.connection.QueryRowContext(ctx, `
-		SELECT id FROM graph_nodes
-		WHERE node_type = 'person' AND properties->>'name' = $1
-		ORDER BY created_at DESC
-		LIMIT 1
-	`, name).Scan(&id)
-	if err == sql.ErrNoRows {
-		return 0, nil
-	}
-	return id, err
-}
-
-// SyncCandidateTextFields updates the candidates row (experience, skills, location) from
-// the linked graph_nodes data so the tsvector search_vector stays accurate for BM25.
-// Should be called after graph building and after any interview that may update the role.
-func (db *DB) SyncCandidateTextFields(ctx context.Context, candidateID, graphNodeID int) error {
-	// Derive experience text from node properties (position + seniority)
-	// Derive skills as comma-joined skill names from HAS_SKILL edges
-	_, err := db.connection.ExecContext(ctx, `
-		UPDATE candidates c
-		SET
-		    experience = COALESCE(gn.properties->>'current_position','') ||
-		                 CASE WHEN COALESCE(gn.properties->>'seniority','') <> ''
-		                      THEN ' ' || (gn.properties->>'seniority')
-		                      ELSE '' END,
-		    skills = (
-		        SELECT string_agg(s.properties->>'name', ',')
-		        FROM graph_edges e
-		        JOIN graph_nodes s ON e.target_node_id = s.id
-		        WHERE e.source_node_id = gn.id
-		          AND e.edge_type = 'HAS_SKILL'
-		          AND s.node_type = 'skill'
-		    )
-		FROM graph_nodes gn
-		WHERE gn.id = $1
-		  AND c.id = $2
-	`, graphNodeID, candidateID)
-	return err
-}
-
-// CreateCVUploadJob creates a new async CV processing job
-func (db *DB) CreateCVUploadJob(ctx context.Context, cvFileID int64) (int64, error) {
-	query := `
-        INSERT INTO cv_upload_jobs (cv_file_id, status, created_at)
-        VALUES ($1, 'pending', NOW())
-        RETURNING id
-    `
-	var jobID int64
-	err := db.connection.QueryRowContext(ctx, query, cvFileID).Scan(&jobID)
-	if err != nil {
-		return 0, err
-	}
-
-	// Update cv_files with job_id
-	_, err = db.connection.ExecContext(ctx, `UPDATE cv_files SET job_id = $1 WHERE id = $2`, jobID, cvFileID)
-	if err != nil {
-		log.Printf("[DB] Warning: Failed to update cv_files.job_id: %v", err)
-	}
-
-	return jobID, nil
-}
-
-// UpdateJobStatus updates job status and timestamps
-func (db *DB) UpdateJobStatus(ctx context.Context, jobID int64, status string, errorMsg *string) error {
-	var query string
-	var args []interface{}
-
-	switch status {
-	case "processing":
-		query = `UPDATE cv_upload_jobs SET status = $1, started_at = NOW() WHERE id = $2`
-		args = []interface{}{status, jobID}
-	case "completed":
-		query = `UPDATE cv_upload_jobs SET status = $1, completed_at = NOW() WHERE id = $2`
-		args = []interface{}{status, jobID}
-	case "failed":
-		query = `UPDATE cv_upload_jobs SET status = $1, error_message = $2, completed_at = NOW() WHERE id = $3`
-		args = []interface{}{status, errorMsg, jobID}
-	default:
-		query = `UPDATE cv_upload_jobs SET status = $1 WHERE id = $2`
-		args = []interface{}{status, jobID}
-	}
-
-	_, err := db.connection.ExecContext(ctx, query, args...)
-	return err
-}
-
-// IncrementJobRetryCount increments retry_count for a job and returns the new
-// retry_count and the configured max_retries, so the caller can decide whether
-// to requeue the job or mark it permanently failed.
-func (db *DB) IncrementJobRetryCount(ctx context.Context, jobID int64) (retryCount int, maxRetries int, err error) {
-	err = db.connection.QueryRowContext(ctx, `
-		UPDATE cv_upload_jobs
-		SET retry_count = retry_count + 1
-		WHERE id = $1
-		RETURNING retry_count, max_retries
-	`, jobID).Scan(&retryCount, &maxRetries)
-	return retryCount, maxRetries, err
-}
-
-// CreateGroqBatchJob records a newly submitted Groq Batch API job.
-func (db *DB) CreateGroqBatchJob(ctx context.Context, groqBatchID, inputFileID string, requestCount int) (int64, error) {
-	var id int64
-	err := db.connection.QueryRowContext(ctx, `
-		INSERT INTO llm_batch_jobs (groq_batch_id, input_file_id, status, request_count, created_at)
-		VALUES ($1, $2, 'submitted', $3, NOW())
-		RETURNING id
-	`, groqBatchID, inputFileID, requestCount).Scan(&id)
-	return id, err
-}
-
-// LinkJobsToGroqBatch marks the given cv_upload_jobs as part of a Groq batch,
-// setting status to 'batch_submitted' so real-time polling clients see it's
-// no longer sitting in the immediate queue.
-func (db *DB) LinkJobsToGroqBatch(ctx context.Context, groqBatchID string, jobIDs []int64) error {
-	if len(jobIDs) == 0 {
-		return nil
-	}
-	_, err := db.connection.ExecContext(ctx, `
-		UPDATE cv_upload_jobs
-		SET status = 'batch_submitted', groq_batch_id = $1
-		WHERE id = ANY($2)
-	`, groqBatchID, pq.Array(jobIDs))
-	return err
-}
-
-// GroqBatchJobRow is a row from llm_batch_jobs.
-type GroqBatchJobRow struct {
-	ID           int64
-	GroqBatchID  string
-	InputFileID  string
-	OutputFileID *string
-	ErrorFileID  *string
-	Status       string
-}
-
-// ListOpenGroqBatchJobs returns all batch jobs that haven't reached a terminal
-// state yet (for the background poller to check on).
-func (db *DB) ListOpenGroqBatchJobs(ctx context.Context) ([]GroqBatchJobRow, error) {
-	rows, err := db.connection.QueryContext(ctx, `
-		SELECT id, groq_batch_id, input_file_id, output_file_id, error_file_id, status
-		FROM llm_batch_jobs
-		WHERE status NOT IN ('completed', 'failed', 'expired', 'cancelled')
-		ORDER BY created_at ASC
-	`)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var result []GroqBatchJobRow
-	for rows.Next() {
-		var r GroqBatchJobRow
-		if err := rows.Scan(&r.ID, &r.GroqBatchID, &r.InputFileID, &r.OutputFileID, &r.ErrorFileID, &r.Status); err != nil {
-			return nil, err
-		}
-		result = append(result, r)
-	}
-	return result, rows.Err()
-}
-
-// UpdateGroqBatchJobStatus updates the tracked status/output files for a batch.
-func (db *DB) UpdateGroqBatchJobStatus(ctx context.Context, groqBatchID, status string, outputFileID, errorFileID *string) error {
-	if status == "completed" || status == "failed" || status == "expired" || status == "cancelled" {
-		_, err := db.connection.ExecContext(ctx, `
-			UPDATE llm_batch_jobs
-			SET status = $1, output_file_id = $2, error_file_id = $3, completed_at = NOW()
-			WHERE groq_batch_id = $4
-		`, status, outputFileID, errorFileID, groqBatchID)
-		return err
-	}
-	_, err := db.connection.ExecContext(ctx, `
-		UPDATE llm_batch_jobs
-		SET status = $1, output_file_id = $2, error_file_id = $3
-		WHERE groq_batch_id = $4
-	`, status, outputFileID, errorFileID, groqBatchID)
-	return err
-}
-
-// GetJobsByGroqBatchID returns the cv_upload_jobs linked to a given batch,
-// keyed by cv_file_id (used as the batch's custom_id) so results can be
-// matched back to jobs.
-func (db *DB) GetJobsByGroqBatchID(ctx context.Context, groqBatchID string) (map[int64]int64, error) {
-	rows, err := db.connection.QueryContext(ctx, `
-		SELECT id, cv_file_id FROM cv_upload_jobs WHERE groq_batch_id = $1
-	`, groqBatchID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	result := make(map[int64]int64) // cv_file_id -> job_id
-	for rows.Next() {
-		var jobID, cvFileID int64
-		if err := rows.Scan(&jobID, &cvFileID); err != nil {
-			return nil, err
-		}
-		result[cvFileID] = jobID
-	}
-	return result, rows.Err()
-}
-
-// GetCVTextsByFileIDs fetches parsed_text for a set of cv_files, keyed by id.
-func (db *DB) GetCVTextsByFileIDs(ctx context.Context, cvFileIDs []int64) (map[int64]string, error) {
-	if len(cvFileIDs) == 0 {
-		return map[int64]string{}, nil
-	}
-	rows, err := db.connection.QueryContext(ctx, `
-		SELECT id, parsed_text FROM cv_files WHERE id = ANY($1)
-	`, pq.Array(cvFileIDs))
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	result := make(map[int64]string)
-	for rows.Next() {
-		var id int64
-		var text sql.NullString
-		if err := rows.Scan(&id, &text); err != nil {
-			return nil, err
-		}
-		result[id] = text.String
-	}
-	return result, rows.Err()
-}
-
-// GetJobByID retrieves a job by ID
-func (db *DB) GetJobByID(ctx context.Context, jobID int64) (*CVUploadJob, error) {
-	// Clear prepared statement cache to prevent binding errors
-	db.connection.Exec("DEALLOCATE ALL")
-
-	query := `
-        SELECT id, cv_file_id, status, error_message, progress,
-               created_at, started_at, completed_at, retry_count, max_retries
-        FROM cv_upload_jobs
-        WHERE id = $1
-    `
-	var job CVUploadJob
-	var progressJSON sql.NullString
-
-	err := db.connection.QueryRowContext(ctx, query, jobID).Scan(
-		&job.ID, &job.CVFileID, &job.Status, &job.ErrorMessage,
-		&progressJSON, &job.CreatedAt, &job.StartedAt, &job.CompletedAt,
-		&job.RetryCount, &job.MaxRetries,
-	)
-
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
-	if err != nil {
-		return nil, err
-	}
-
-	job.Progress = make(map[string]interface{})
-
-	return &job, nil
-}
-
-// ─── Search suggestions & popular queries ────────────────────────────────────
-
-// SuggestFromGraph returns autocomplete suggestions matching the given prefix.
-// Searches skill and company node names, and person node current positions.
-// Deduplicates and caps the result at limit.
-func (db *DB) SuggestFromGraph(ctx context.Context, prefix string, limit int) ([]SuggestionResult, error) {
-	if prefix == "" || limit <= 0 {
-		return []SuggestionResult{}, nil
-	}
-	pattern := prefix + "%"
-
-	rows, err := db.connection.QueryContext(ctx, `
-		(
-			SELECT properties->>'name' AS text, 'skill' AS type
-			FROM graph_nodes
-			WHERE node_type = 'skill'
-			  AND properties->>'name' ILIKE $1
-			  AND properties->>'name' IS NOT NULL
-			LIMIT $2
-		)
-		UNION
-		(
-			SELECT properties->>'name' AS text, 'company' AS type
-			FROM graph_nodes
-			WHERE node_type = 'company'
-			  AND properties->>'name' ILIKE $1
-			  AND properties->>'name' IS NOT NULL
-			LIMIT $2
-		)
-		UNION
-		(
-			SELECT DISTINCT properties->>'current_position' AS text, 'position' AS type
-			FROM graph_nodes
-			WHERE node_type = 'person'
-			  AND properties->>'current_position' ILIKE $1
-			  AND properties->>'current_position' IS NOT NULL
-			LIMIT $2
-		)
-		LIMIT $2
-	`, pattern, limit)
-	if err != nil {
-		return nil, fmt.Errorf("suggest query: %w", err)
-	}
-	defer rows.Close()
-
-	seen := make(map[string]struct{})
-	var results []SuggestionResult
-	for rows.Next() {
-		var s SuggestionResult
-		if err := rows.Scan(&s.Text, &s.Type); err != nil {
-			continue
-		}
-		key := strings.ToLower(s.Text)
-		if _, dup := seen[key]; dup {
-			continue
-		}
-		seen[key] = struct{}{}
-		results = append(results, s)
-	}
-	return results, rows.Err()
-}
-
-// GetTopSkillsForQueries returns the most common skill names (by HAS_SKILL edge count)
-// and the most common seniority levels, for use in generating popular query suggestions.
-func (db *DB) GetTopSkillsForQueries(ctx context.Context, skillLimit, seniorityLimit int) (skills []string, seniorities []string, err error) {
-	// Top skills by popularity (most candidates have them)
-	skillRows, err := db.connection.QueryContext(ctx, `
-		SELECT s.properties->>'name' AS skill_name
-		FROM graph_edges ge
-		JOIN graph_nodes s ON ge.target_node_id = s.id
-		WHERE ge.edge_type = 'HAS_SKILL'
-		  AND s.node_type = 'skill'
-		  AND s.properties->>'name' IS NOT NULL
-		GROUP BY skill_name
-		ORDER BY COUNT(*) DESC
-		LIMIT $1
-	`, skillLimit)
-	if err != nil {
-		return nil, nil, fmt.Errorf("top skills query: %w", err)
-	}
-	defer skillRows.Close()
-	for skillRows.Next() {
-		var name string
-		if err := skillRows.Scan(&name); err == nil && name != "" {
-			skills = append(skills, name)
-		}
-	}
-
-	// Top seniority levels
-	senRows, err := db.connection.QueryContext(ctx, `
-		SELECT properties->>'seniority', COUNT(*) AS cnt
-		FROM graph_nodes
-		WHERE node_type = 'person'
-		  AND properties->>'seniority' IS NOT NULL
-		  AND properties->>'seniority' != ''
-		GROUP BY properties->>'seniority'
-		ORDER BY cnt DESC
-		LIMIT $1
-	`, seniorityLimit)
-	if err != nil {
-		return skills, nil, fmt.Errorf("seniority query: %w", err)
-	}
-	defer senRows.Close()
-	for senRows.Next() {
-		var s string
-		var cnt int
-		if err := senRows.Scan(&s, &cnt); err == nil && s != "" {
-			seniorities = append(seniorities, s)
-		}
-	}
-	return skills, seniorities, nil
-}
-
-// GetPersonEmbedding reads the stored vector embedding for a person graph node,
-// returning it as []float32 for direct use in similarity search.
-// Returns nil if the node has no embedding.
-func (db *DB) GetPersonEmbedding(ctx context.Context, graphNodeID int) ([]float32, error) {
-	var embText sql.NullString
-	err := db.connection.QueryRowContext(ctx, `
-		SELECT embedding::text
-		FROM graph_nodes
-		WHERE id = $1 AND node_type = 'person' AND embedding IS NOT NULL
-	`, graphNodeID).Scan(&embText)
-	if err == sql.ErrNoRows || !embText.Valid {
-		return nil, nil
-	}
-	if err != nil {
-		return nil, fmt.Errorf("get person embedding: %w", err)
-	}
-
-	// pgvector text format: "[0.1,0.2,...]"
-	text := strings.TrimPrefix(strings.TrimSuffix(strings.TrimSpace(embText.String), "]"), "[")
-	parts := strings.Split(text, ",")
-	result := make([]float32, 0, len(parts))
-	for _, p := range parts {
-		v, err := strconv.ParseFloat(strings.TrimSpace(p), 32)
-		if err != nil {
-			continue
-		}
-		result = append(result, float32(v))
-	}
-	return result, nil
-}
-
-// GetCandidatesByPersonNodeIDs returns lightweight candidate info for the given person node_ids
-// (e.g. "person_2"). Used to enrich similar-candidate results with DB data.
-// excludeNodeID is filtered out from results (the source candidate).
-func (db *DB) GetCandidatesByPersonNodeIDs(ctx context.Context, personNodeIDs []string, excludeNodeID string, similarities map[string]float64) ([]SimilarCandidate, error) {
-	if len(personNodeIDs) == 0 {
-		return []SimilarCandidate{}, nil
-	}
-
-	placeholders := make([]string, len(personNodeIDs))
-	args := make([]interface{}, len(personNodeIDs))
-	for i, id := range personNodeIDs {
-		placeholders[i] = fmt.Sprintf("$%d", i+1)
-		args[i] = id
-	}
-	inClause := strings.Join(placeholders, ",")
-
-	query := fmt.Sprintf(`
-		SELECT c.id, c.name,
-		       COALESCE(gn.properties->>'current_position', '') AS current_position,
-		       COALESCE(gn.properties->>'seniority', '')        AS seniority,
-		       gn.node_id
-		FROM candidates c
-		JOIN graph_nodes gn ON gn.id = c.graph_node_id
-		WHERE gn.node_id IN (%s)
-		  AND gn.node_id <> $%d
-	`, inClause, len(personNodeIDs)+1)
-	args = append(args, excludeNodeID)
-
-	rows, err := db.connection.QueryContext(ctx, query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("get candidates by node ids: %w", err)
-	}
-	defer rows.Close()
-
-	var results []SimilarCandidate
-	nodeIDs := make([]int, 0)
-	candidateNodeMap := make(map[int]int) // candidateID -> index in results
-
-	for rows.Next() {
-		var sc SimilarCandidate
-		var nodeID string
-		if err := rows.Scan(&sc.CandidateID, &sc.Name, &sc.CurrentPosition, &sc.Seniority, &nodeID); err != nil {
-			continue
-		}
-		sc.Similarity = similarities[nodeID]
-		_ = nodeIDs
-		candidateNodeMap[sc.CandidateID] = len(results)
-		results = append(results, sc)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-
-	// Enrich with top 5 skills per candidate
-	if len(results) > 0 {
-		candidateIDs := make([]string, len(results))
-		for i, r := range results {
-			candidateIDs[i] = fmt.Sprintf("$%d", i+1)
-			_ = r
-		}
-		idArgs := make([]interface{}, len(results))
-		for i, r := range results {
-			idArgs[i] = r.CandidateID
-		}
-
-		skillRows, err := db.connection.QueryContext(ctx, fmt.Sprintf(`
-			SELECT c.id, s.properties->>'name'
-			FROM candidates c
-			JOIN graph_nodes gn ON gn.id = c.graph_node_id
-			JOIN graph_edges ge ON ge.source_node_id = gn.id
-			JOIN graph_nodes s  ON s.id = ge.target_node_id
-			WHERE c.id IN (%s)
-			  AND ge.edge_type = 'HAS_SKILL'
-			  AND s.node_type = 'skill'
-			ORDER BY c.id, ge.id
-		`, strings.Join(candidateIDs, ",")), idArgs...)
-		if err == nil {
-			defer skillRows.Close()
-			skillMap := make(map[int][]string)
-			for skillRows.Next() {
-				var cid int
-				var skill string
-				if err := skillRows.Scan(&cid, &skill); err == nil && skill != "" {
-					if len(skillMap[cid]) < 5 {
-						skillMap[cid] = append(skillMap[cid], skill)
-					}
-				}
-			}
-			for i := range results {
-				results[i].TopSkills = skillMap[results[i].CandidateID]
-			}
-		}
-	}
-
-	return results, nil
-}
-
-// GetPersonNodeIDString returns the string node_id (e.g. "person_2") for an integer
-// graph_node_id that is stored on the candidates table. Returns "" if not found.
-func (db *DB) GetPersonNodeIDString(ctx context.Context, graphNodeID int) (string, error) {
-	var nodeID string
-	err := db.connection.QueryRowContext(ctx, `
-		SELECT node_id FROM graph_nodes WHERE id = $1 AND node_type = 'person' LIMIT 1
-	`, graphNodeID).Scan(&nodeID)
-	if err == sql.ErrNoRows {
-		return "", nil
-	}
-	if err != nil {
-		return "", fmt.Errorf("get person node_id string: %w", err)
-	}
-	return nodeID, nil
-}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ─── Deployment settings ─────────────────────────────────────────────────────
+
+// DeploymentSettingOffline is the deployment_settings key under which
+// cmd/tools/offline-setup records an OfflineDeployment.
+const DeploymentSettingOffline = "offline"
+
+// OfflineDeployment records that a deployment was verified to run without
+// internet access: the Ollama server and models it was checked with.
+type OfflineDeployment struct {
+	OllamaURL           string    `json:"ollama_url"`
+	LLMModel            string    `json:"llm_model"`
+	EmbeddingModel      string    `json:"embedding_model"`
+	EmbeddingDimensions int       `json:"embedding_dimensions"`
+	VerifiedAt          time.Time `json:"verified_at"`
+}
+
+// SetDeploymentSetting stores value, as JSON, under key.
+func (db *DB) SetDeploymentSetting(ctx context.Context, key string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encode deployment setting %s: %w", key, err)
+	}
+	_, err = db.q().ExecContext(ctx, `
+		INSERT INTO deployment_settings (key, value) VALUES ($1, $2)
+		ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, updated_at = NOW()
+	`, key, string(data))
+	if err != nil {
+		return fmt.Errorf("save deployment setting %s: %w", key, err)
+	}
+	return nil
+}
+
+// GetDeploymentSetting decodes the value stored under key into dst. It
+// returns false, and leaves dst alone, when there is none.
+func (db *DB) GetDeploymentSetting(ctx context.Context, key string, dst any) (bool, error) {
+	var data []byte
+	err := db.q().QueryRowContext(ctx, `SELECT value FROM deployment_settings WHERE key = $1`, key).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("get deployment setting %s: %w", key, err)
+	}
+	if err := json.Unmarshal(data, dst); err != nil {
+		return false, fmt.Errorf("decode deployment setting %s: %w", key, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,215 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"cv-search/internal/tenant"
+)
+
+// ─── Soft delete & GDPR erasure ──────────────────────────────────────────────
+
+// anonymizedPersonProperties are the person-node properties kept when an
+// erasure preserves graph statistics. Everything else (name, contact details,
+// free text) is dropped.
+var anonymizedPersonProperties = []string{"seniority", "current_position", "total_experience_years", "experience_years_llm", "experience_years_computed"}
+
+// SoftDeleteCandidate hides a candidate, its CV files and its person node
+// from every read path. Returns false if the candidate doesn't exist or is
+// already deleted.
+func (db *DB) SoftDeleteCandidate(ctx context.Context, candidateID int) (bool, error) {
+	found := false
+	err := db.WithTx(ctx, func(tx *DB) error {
+		var graphNodeID sql.NullInt64
+		err := tx.q().QueryRowContext(ctx, `
+			UPDATE candidates SET deleted_at = NOW()
+			WHERE id = $1 AND org_id = $2 AND deleted_at IS NULL
+			RETURNING graph_node_id
+		`, candidateID, tenant.OrgID(ctx)).Scan(&graphNodeID)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("soft delete candidate: %w", err)
+		}
+		found = true
+
+		if _, err := tx.q().ExecContext(ctx, `
+			UPDATE cv_files SET deleted_at = NOW()
+			WHERE candidate_id = $1 AND deleted_at IS NULL
+		`, candidateID); err != nil {
+			return fmt.Errorf("soft delete cv files: %w", err)
+		}
+		if graphNodeID.Valid {
+			if _, err := tx.q().ExecContext(ctx, `
+				UPDATE graph_nodes SET deleted_at = NOW()
+				WHERE id = $1 AND node_type = 'person' AND deleted_at IS NULL
+			`, graphNodeID.Int64); err != nil {
+				return fmt.Errorf("soft delete person node: %w", err)
+			}
+		}
+		return nil
+	})
+	return found, err
+}
+
+// EraseCandidate permanently removes a candidate's personal data: the
+// candidate row (with interviews, skills and scores via cascade), every
+// linked CV file row (with parsed text, entities and jobs), and the
+// candidate's entries in stored search-session results. The person node is
+// deleted outright, or — when keepGraphStats is true — stripped to
+// anonymizedPersonProperties with its embedding cleared and left soft-deleted
+// so skill/company/seniority aggregates still count it.
+//
+// Candidates merged into this one (see MergeCandidates) are erased with it.
+// Works on soft-deleted candidates too. Returns nil, nil if the candidate
+// doesn't exist. Removing the stored files from the blob store is left to the
+// caller (see ErasureResult.FilePaths).
+func (db *DB) EraseCandidate(ctx context.Context, candidateID int, keepGraphStats bool) (*ErasureResult, error) {
+	var res *ErasureResult
+	err := db.WithTx(ctx, func(tx *DB) error {
+		var graphNodeID sql.NullInt64
+		err := tx.q().QueryRowContext(ctx,
+			`SELECT graph_node_id FROM candidates WHERE id = $1 AND org_id = $2 FOR UPDATE`, candidateID, tenant.OrgID(ctx),
+		).Scan(&graphNodeID)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("lock candidate: %w", err)
+		}
+		res = &ErasureResult{CandidateID: candidateID}
+
+		// Duplicates merged into this candidate hold the same person's data.
+		merged, err := tx.activeMergedCandidateIDs(ctx, candidateID)
+		if err != nil {
+			return err
+		}
+		for _, id := range merged {
+			sub, err := tx.EraseCandidate(ctx, id, keepGraphStats)
+			if err != nil {
+				return fmt.Errorf("erase merged candidate %d: %w", id, err)
+			}
+			if sub != nil {
+				res.CVFilesDeleted += sub.CVFilesDeleted
+				res.FilePaths = append(res.FilePaths, sub.FilePaths...)
+			}
+		}
+
+		// CV files linked directly, plus the CV the person node was built from
+		// (linking can fail after the graph build).
+		rows, err := tx.q().QueryContext(ctx, `
+			DELETE FROM cv_files
+			WHERE candidate_id = $1
+			   OR ($2::int IS NOT NULL AND id::text = (SELECT properties->>'cv_id' FROM graph_nodes WHERE id = $2))
+			RETURNING COALESCE(file_path, ''), COALESCE(photo_key, '')
+		`, candidateID, graphNodeID)
+		if err != nil {
+			return fmt.Errorf("delete cv files: %w", err)
+		}
+		for rows.Next() {
+			var path, photo string
+			if err := rows.Scan(&path, &photo); err != nil {
+				rows.Close()
+				return fmt.Errorf("scan cv file path: %w", err)
+			}
+			res.CVFilesDeleted++
+			if path != "" {
+				res.FilePaths = append(res.FilePaths, path)
+			}
+			if photo != "" {
+				res.FilePaths = append(res.FilePaths, photo)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("delete cv files: %w", err)
+		}
+
+		var personNodeID string
+		if graphNodeID.Valid {
+			if err := tx.q().QueryRowContext(ctx,
+				`SELECT node_id FROM graph_nodes WHERE id = $1`, graphNodeID.Int64,
+			).Scan(&personNodeID); err != nil && err != sql.ErrNoRows {
+				return fmt.Errorf("get person node: %w", err)
+			}
+		}
+
+		// Stored session results embed names and summaries.
+		if _, err := tx.q().ExecContext(ctx, `
+			UPDATE search_session_turns t
+			SET results = COALESCE((
+				SELECT jsonb_agg(r)
+				FROM jsonb_array_elements(t.results) r
+				WHERE NOT ((r->>'id') = $1::text OR ($2 <> '' AND (r->>'person_id') = $2))
+			), '[]'::jsonb)
+			WHERE jsonb_typeof(t.results) = 'array'
+			  AND t.session_id IN (SELECT id FROM search_sessions WHERE org_id = $3)
+			  AND EXISTS (
+				SELECT 1 FROM jsonb_array_elements(t.results) r
+				WHERE (r->>'id') = $1::text OR ($2 <> '' AND (r->>'person_id') = $2)
+			  )
+		`, candidateID, personNodeID, tenant.OrgID(ctx)); err != nil {
+			return fmt.Errorf("scrub search sessions: %w", err)
+		}
+
+		if _, err := tx.q().ExecContext(ctx, `DELETE FROM candidates WHERE id = $1`, candidateID); err != nil {
+			return fmt.Errorf("delete candidate: %w", err)
+		}
+
+		if personNodeID == "" {
+			return nil
+		}
+		id := int(graphNodeID.Int64)
+		res.GraphNodeID = &id
+		if keepGraphStats {
+			if _, err := tx.q().ExecContext(ctx, `
+				UPDATE graph_nodes
+				SET properties = COALESCE((
+				        SELECT jsonb_object_agg(key, value)
+				        FROM jsonb_each(properties)
+				        WHERE key = ANY($2)
+				    ), '{}'::jsonb) || '{"anonymized": true}'::jsonb,
+				    embedding = NULL,
+				    deleted_at = COALESCE(deleted_at, NOW())
+				WHERE id = $1
+			`, id, anonymizedPersonProperties); err != nil {
+				return fmt.Errorf("anonymize person node: %w", err)
+			}
+			res.GraphNode = "anonymized"
+			return nil
+		}
+		if _, err := tx.q().ExecContext(ctx, `DELETE FROM graph_nodes WHERE id = $1`, id); err != nil {
+			return fmt.Errorf("delete person node: %w", err)
+		}
+		res.GraphNode = "deleted"
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// activeMergedCandidateIDs returns the duplicates currently merged into
+// candidateID.
+func (db *DB) activeMergedCandidateIDs(ctx context.Context, candidateID int) ([]int, error) {
+	rows, err := db.q().QueryContext(ctx, `
+		SELECT merged_candidate_id FROM candidate_merges
+		WHERE primary_candidate_id = $1 AND undone_at IS NULL
+	`, candidateID)
+	if err != nil {
+		return nil, fmt.Errorf("list merged candidates: %w", err)
+	}
+	defer rows.Close()
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan merged candidate: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,165 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"cv-search/internal/tenant"
+)
+
+// ─── Search experiments ──────────────────────────────────────────────────────
+
+const searchExperimentColumns = `id, name, COALESCE(description, ''), config, is_default, active, created_at, updated_at`
+
+func scanSearchExperiment(row interface{ Scan(...interface{}) error }) (*SearchExperiment, error) {
+	var e SearchExperiment
+	var cfg []byte
+	if err := row.Scan(&e.ID, &e.Name, &e.Description, &cfg, &e.IsDefault, &e.Active, &e.CreatedAt, &e.UpdatedAt); err != nil {
+		return nil, err
+	}
+	e.Config = json.RawMessage(cfg)
+	return &e, nil
+}
+
+// ListSearchExperiments returns all experiments, default first.
+func (db *DB) ListSearchExperiments(ctx context.Context) ([]SearchExperiment, error) {
+	rows, err := db.q().QueryContext(ctx,
+		`SELECT `+searchExperimentColumns+` FROM search_experiments ORDER BY is_default DESC, name`)
+	if err != nil {
+		return nil, fmt.Errorf("list search experiments: %w", err)
+	}
+	defer rows.Close()
+
+	experiments := []SearchExperiment{}
+	for rows.Next() {
+		e, err := scanSearchExperiment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan search experiment: %w", err)
+		}
+		experiments = append(experiments, *e)
+	}
+	return experiments, rows.Err()
+}
+
+// GetSearchExperiment returns an active experiment by name, or nil if none exists.
+func (db *DB) GetSearchExperiment(ctx context.Context, name string) (*SearchExperiment, error) {
+	e, err := scanSearchExperiment(db.q().QueryRowContext(ctx,
+		`SELECT `+searchExperimentColumns+` FROM search_experiments WHERE name = $1 AND active`, name))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get search experiment %q: %w", name, err)
+	}
+	return e, nil
+}
+
+// GetDefaultSearchExperiment returns the active default experiment, or nil if
+// none is marked default (callers then use the built-in config).
+func (db *DB) GetDefaultSearchExperiment(ctx context.Context) (*SearchExperiment, error) {
+	e, err := scanSearchExperiment(db.q().QueryRowContext(ctx,
+		`SELECT `+searchExperimentColumns+` FROM search_experiments WHERE is_default AND active LIMIT 1`))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get default search experiment: %w", err)
+	}
+	return e, nil
+}
+
+// UpsertSearchExperiment creates or updates an experiment by name. Marking an
+// experiment as default clears the flag on every other row in the same transaction.
+func (db *DB) UpsertSearchExperiment(ctx context.Context, e *SearchExperiment) (*SearchExperiment, error) {
+	cfg := e.Config
+	if len(cfg) == 0 {
+		cfg = json.RawMessage(`{}`)
+	}
+
+	var saved *SearchExperiment
+	err := db.WithTx(ctx, func(tx *DB) error {
+		if e.IsDefault {
+			if _, err := tx.q().ExecContext(ctx,
+				`UPDATE search_experiments SET is_default = FALSE WHERE is_default AND name <> $1`, e.Name); err != nil {
+				return fmt.Errorf("clear default experiment: %w", err)
+			}
+		}
+
+		var err error
+		saved, err = scanSearchExperiment(tx.q().QueryRowContext(ctx, `
+			INSERT INTO search_experiments (name, description, config, is_default, active)
+			VALUES ($1, NULLIF($2, ''), $3, $4, $5)
+			ON CONFLICT (name) DO UPDATE SET
+				description = EXCLUDED.description,
+				config      = EXCLUDED.config,
+				is_default  = EXCLUDED.is_default,
+				active      = EXCLUDED.active
+			RETURNING `+searchExperimentColumns,
+			e.Name, e.Description, []byte(cfg), e.IsDefault, e.Active))
+		if err != nil {
+			return fmt.Errorf("upsert search experiment %q: %w", e.Name, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+// LogSearchExperiment records which configuration served a search for
+// ctx's organization. Experiments themselves are shared by every org.
+func (db *DB) LogSearchExperiment(ctx context.Context, entry SearchExperimentLog) error {
+	cfgJSON, err := json.Marshal(entry.Config)
+	if err != nil {
+		return fmt.Errorf("marshal experiment config: %w", err)
+	}
+	idsJSON, err := json.Marshal(entry.ResultIDs)
+	if err != nil {
+		return fmt.Errorf("marshal result ids: %w", err)
+	}
+	var resultsJSON []byte
+	if entry.Results != nil {
+		if resultsJSON, err = json.Marshal(entry.Results); err != nil {
+			return fmt.Errorf("marshal results: %w", err)
+		}
+	}
+
+	_, err = db.q().ExecContext(ctx, `
+		INSERT INTO search_experiment_log (search_id, experiment_name, query, config, result_ids, results, result_count, duration_ms, org_id)
+		VALUES (NULLIF($1, ''), NULLIF($2, ''), $3, $4, $5, $6, $7, $8, $9)`,
+		entry.SearchID, entry.ExperimentName, entry.Query, cfgJSON, idsJSON, resultsJSON, len(entry.ResultIDs), entry.DurationMS, tenant.OrgID(ctx))
+	if err != nil {
+		return fmt.Errorf("log search experiment: %w", err)
+	}
+	return nil
+}
+
+// RecentSearchLatency returns how long ctx's organization's last limit
+// logged searches with reranker took, semantic cache hits included; nil
+// without any.
+func (db *DB) RecentSearchLatency(ctx context.Context, reranker string, limit int) (*SearchLatency, error) {
+	var l SearchLatency
+	var p50, p90 sql.NullFloat64
+	err := db.r().QueryRowContext(ctx, `
+		SELECT COUNT(*),
+		       percentile_cont(0.5) WITHIN GROUP (ORDER BY duration_ms),
+		       percentile_cont(0.9) WITHIN GROUP (ORDER BY duration_ms)
+		FROM (
+			SELECT duration_ms FROM search_experiment_log
+			WHERE org_id = $1 AND duration_ms IS NOT NULL AND config->>'Reranker' = $2
+			ORDER BY created_at DESC
+			LIMIT $3
+		) recent
+	`, tenant.OrgID(ctx), reranker, limit).Scan(&l.Searches, &p50, &p90)
+	if err != nil {
+		return nil, fmt.Errorf("recent search latency: %w", err)
+	}
+	if l.Searches == 0 {
+		return nil, nil
+	}
+	l.P50MS, l.P90MS = int64(p50.Float64), int64(p90.Float64)
+	return &l, nil
+}
@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"cv-search/internal/tenant"
+)
+
+// ─── Search feedback ─────────────────────────────────────────────────────────
+
+// ErrNotInSearch is returned for feedback on a candidate the search didn't
+// return.
+var ErrNotInSearch = errors.New("candidate is not a result of this search")
+
+// RecordSearchFeedback stores feedback on results of ctx's organization's
+// search searchID, replacing earlier feedback on the same results, and
+// returns the stored rows. Each row keeps a copy of its result's features as
+// logged. Returns sql.ErrNoRows if the search isn't logged and
+// ErrNotInSearch (wrapped) if a candidate isn't one of its results; nothing
+// is stored then.
+func (db *DB) RecordSearchFeedback(ctx context.Context, searchID string, items []SearchFeedback, actor string) ([]SearchFeedback, error) {
+	saved := make([]SearchFeedback, 0, len(items))
+	err := db.WithTx(ctx, func(tx *DB) error {
+		var logID int64
+		err := tx.q().QueryRowContext(ctx,
+			`SELECT id FROM search_experiment_log WHERE org_id = $1 AND search_id = $2`, tenant.OrgID(ctx), searchID,
+		).Scan(&logID)
+		if err == sql.ErrNoRows {
+			return err
+		}
+		if err != nil {
+			return fmt.Errorf("get search %s: %w", searchID, err)
+		}
+
+		for _, item := range items {
+			f := item
+			f.SearchID, f.CreatedBy = searchID, actor
+			var features []byte
+			err := tx.q().QueryRowContext(ctx, `
+				INSERT INTO search_feedback (org_id, search_id, candidate_id, label, score_override, comment, features, created_by)
+				SELECT l.org_id, l.search_id, c.id, $3, $4, NULLIF($5, ''), r.value, $6
+				FROM search_experiment_log l
+				CROSS JOIN LATERAL jsonb_array_elements(COALESCE(l.results, '[]'::jsonb)) r
+				JOIN candidates c ON c.id = (r.value->>'candidate_id')::int AND c.org_id = l.org_id AND c.deleted_at IS NULL
+				WHERE l.id = $1 AND c.id = $2
+				LIMIT 1
+				ON CONFLICT (org_id, search_id, candidate_id) DO UPDATE SET
+					label          = EXCLUDED.label,
+					score_override = EXCLUDED.score_override,
+					comment        = EXCLUDED.comment,
+					created_by     = EXCLUDED.created_by,
+					updated_at     = NOW()
+				RETURNING id, features, created_at, updated_at
+			`, logID, f.CandidateID, f.Label, f.ScoreOverride, f.Comment, actor).Scan(&f.ID, &features, &f.CreatedAt, &f.UpdatedAt)
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("candidate %d: %w", f.CandidateID, ErrNotInSearch)
+			}
+			if err != nil {
+				return fmt.Errorf("record feedback on candidate %d: %w", f.CandidateID, err)
+			}
+			f.Features = features
+			saved = append(saved, f)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+// ExportSearchFeedback calls fn with ctx's organization's feedback given
+// between since and until (either may be nil), oldest first, each with the
+// search it was given on. Returns the number of rows.
+func (db *DB) ExportSearchFeedback(ctx context.Context, since, until *time.Time, fn func(SearchFeedbackExport) error) (int, error) {
+	rows, err := db.q().QueryContext(ctx, `
+		SELECT f.id, f.search_id, f.candidate_id, f.label, f.score_override, COALESCE(f.comment, ''),
+		       f.features, COALESCE(f.created_by, ''), f.created_at, f.updated_at,
+		       l.query, COALESCE(l.experiment_name, ''), l.config, COALESCE(l.result_count, 0), l.created_at
+		FROM search_feedback f
+		JOIN search_experiment_log l ON l.search_id = f.search_id AND l.org_id = f.org_id
+		WHERE f.org_id = $1
+		  AND ($2::timestamptz IS NULL OR f.created_at >= $2)
+		  AND ($3::timestamptz IS NULL OR f.created_at < $3)
+		ORDER BY f.created_at, f.id
+	`, tenant.OrgID(ctx), since, until)
+	if err != nil {
+		return 0, fmt.Errorf("export search feedback: %w", err)
+	}
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		var e SearchFeedbackExport
+		var features, cfg []byte
+		if err := rows.Scan(&e.ID, &e.SearchID, &e.CandidateID, &e.Label, &e.ScoreOverride, &e.Comment,
+			&features, &e.CreatedBy, &e.CreatedAt, &e.UpdatedAt,
+			&e.Query, &e.Experiment, &cfg, &e.ResultCount, &e.SearchedAt); err != nil {
+			return n, fmt.Errorf("scan search feedback: %w", err)
+		}
+		e.Features, e.Config = features, cfg
+		if err := fn(e); err != nil {
+			return n, err
+		}
+		n++
+	}
+	if err := rows.Err(); err != nil {
+		return n, fmt.Errorf("export search feedback: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"cv-search/internal/tenant"
+)
+
+// rareComboMinSupport is how many people each skill of a rare combination
+// must have: below it, any pair of the skill looks rare.
+const rareComboMinSupport = 3
+
+// GetGraphAnalytics returns ctx's organization's last computed graph
+// analytics, at most limit entries per list. With skill, the skill pairs
+// are the ones including it (case-insensitive). ComputedAt is nil if they
+// were never computed.
+func (db *DB) GetGraphAnalytics(ctx context.Context, limit int, skill string) (*GraphAnalytics, error) {
+	orgID := tenant.OrgID(ctx)
+	out := &GraphAnalytics{
+		CentralPeople:   []CentralPerson{},
+		FeederCompanies: []CompanyAlumni{},
+		TopSkillPairs:   []SkillPair{},
+		RareSkillCombos: []SkillPair{},
+	}
+
+	var computedAt time.Time
+	err := db.r().QueryRowContext(ctx, `
+		SELECT computed_at, duration_ms FROM graph_analytics_runs WHERE org_id = $1
+	`, orgID).Scan(&computedAt, &out.DurationMs)
+	if err == sql.ErrNoRows {
+		return out, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get graph analytics run: %w", err)
+	}
+	out.ComputedAt = &computedAt
+
+	rows, err := db.r().QueryContext(ctx, `
+		SELECT pc.node_id, COALESCE(gn.properties->>'name', ''), c.id, pc.degree, pc.pagerank
+		FROM graph_person_centrality pc
+		JOIN graph_nodes gn ON gn.id = pc.node_id AND gn.deleted_at IS NULL
+		LEFT JOIN candidates c ON c.graph_node_id = pc.node_id AND c.deleted_at IS NULL
+		WHERE pc.org_id = $1
+		ORDER BY pc.pagerank DESC, pc.degree DESC, pc.node_id
+		LIMIT $2
+	`, orgID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("central people: %w", err)
+	}
+	for rows.Next() {
+		var p CentralPerson
+		var candidateID sql.NullInt64
+		if err := rows.Scan(&p.NodeID, &p.Name, &candidateID, &p.Degree, &p.PageRank); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan central person: %w", err)
+		}
+		if candidateID.Valid {
+			p.CandidateID = &candidateID.Int64
+		}
+		out.CentralPeople = append(out.CentralPeople, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("central people: %w", err)
+	}
+
+	rows, err = db.r().QueryContext(ctx, `
+		SELECT company, current_employees, former_employees
+		FROM graph_company_alumni
+		WHERE org_id = $1 AND former_employees > 0
+		ORDER BY former_employees DESC, current_employees DESC, company
+		LIMIT $2
+	`, orgID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("feeder companies: %w", err)
+	}
+	for rows.Next() {
+		var c CompanyAlumni
+		if err := rows.Scan(&c.Company, &c.CurrentEmployees, &c.FormerEmployees); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan feeder company: %w", err)
+		}
+		out.FeederCompanies = append(out.FeederCompanies, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("feeder companies: %w", err)
+	}
+
+	for _, q := range []struct {
+		name  string
+		where string
+		order string
+		dst   *[]SkillPair
+	}{
+		{"top skill pairs", ``, `candidates DESC, lift DESC`, &out.TopSkillPairs},
+		// Both skills common, yet seldom held together.
+		{"rare skill combos", fmt.Sprintf(`AND skill_a_candidates >= %d AND skill_b_candidates >= %d AND lift < 1`, rareComboMinSupport, rareComboMinSupport), `lift, candidates`, &out.RareSkillCombos},
+	} {
+		rows, err := db.r().QueryContext(ctx, `
+			SELECT skill_a, skill_b, candidates, skill_a_candidates, skill_b_candidates, lift
+			FROM graph_skill_cooccurrence
+			WHERE org_id = $1
+			  AND ($3 = '' OR LOWER(skill_a) = LOWER($3) OR LOWER(skill_b) = LOWER($3))
+			  `+q.where+`
+			ORDER BY `+q.order+`, skill_a, skill_b
+			LIMIT $2
+		`, orgID, limit, skill)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", q.name, err)
+		}
+		for rows.Next() {
+			var p SkillPair
+			if err := rows.Scan(&p.SkillA, &p.SkillB, &p.Candidates, &p.SkillACandidates, &p.SkillBCandidates, &p.Lift); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("scan skill pair: %w", err)
+			}
+			*q.dst = append(*q.dst, p)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("%s: %w", q.name, err)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"cv-search/internal/tenant"
+)
+
+// ─── Idempotency keys ────────────────────────────────────────────────────────
+
+// ReserveIdempotencyKey claims key for a request of ctx's organization
+// before it runs. It returns nil if the key was free: new, older than ttl,
+// or held by a request that didn't finish within lockTimeout (the instance
+// running it died). Otherwise it returns the key's record — a stored
+// response, or one still in progress (Status 0).
+func (db *DB) ReserveIdempotencyKey(ctx context.Context, key, method, path string, ttl, lockTimeout time.Duration) (*IdempotencyRecord, error) {
+	orgID := tenant.OrgID(ctx)
+	if _, err := db.q().ExecContext(ctx, `
+		DELETE FROM idempotency_keys
+		WHERE org_id = $1 AND key = $2
+		  AND (created_at <= NOW() - make_interval(secs => $3)
+		       OR (status_code IS NULL AND created_at <= NOW() - make_interval(secs => $4)))
+	`, orgID, key, ttl.Seconds(), lockTimeout.Seconds()); err != nil {
+		return nil, fmt.Errorf("expire idempotency key: %w", err)
+	}
+
+	res, err := db.q().ExecContext(ctx, `
+		INSERT INTO idempotency_keys (org_id, key, method, path)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (org_id, key) DO NOTHING
+	`, orgID, key, method, path)
+	if err != nil {
+		return nil, fmt.Errorf("reserve idempotency key: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 1 {
+		return nil, nil
+	}
+
+	rec := IdempotencyRecord{Key: key}
+	var fingerprint, contentType sql.NullString
+	var status sql.NullInt64
+	err = db.q().QueryRowContext(ctx, `
+		SELECT method, path, fingerprint, status_code, content_type, COALESCE(body, ''::bytea), created_at
+		FROM idempotency_keys
+		WHERE org_id = $1 AND key = $2
+	`, orgID, key).Scan(&rec.Method, &rec.Path, &fingerprint, &status, &contentType, &rec.Body, &rec.CreatedAt)
+	if err == sql.ErrNoRows {
+		// Released between the insert and this read; the client retries.
+		return &IdempotencyRecord{Key: key, Method: method, Path: path}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get idempotency key: %w", err)
+	}
+	rec.Fingerprint = fingerprint.String
+	rec.Status = int(status.Int64)
+	rec.ContentType = contentType.String
+	return &rec, nil
+}
+
+// CompleteIdempotencyKey stores the response of the request that reserved
+// key, for its retries.
+func (db *DB) CompleteIdempotencyKey(ctx context.Context, rec *IdempotencyRecord) error {
+	_, err := db.q().ExecContext(ctx, `
+		UPDATE idempotency_keys
+		SET fingerprint = $3, status_code = $4, content_type = $5, body = $6, completed_at = NOW()
+		WHERE org_id = $1 AND key = $2
+	`, tenant.OrgID(ctx), rec.Key, rec.Fingerprint, rec.Status, rec.ContentType, rec.Body)
+	if err != nil {
+		return fmt.Errorf("complete idempotency key: %w", err)
+	}
+	return nil
+}
+
+// ReleaseIdempotencyKey frees a reserved key whose response isn't kept (a
+// server error, a busy queue), so a retry runs again.
+func (db *DB) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	_, err := db.q().ExecContext(ctx, `
+		DELETE FROM idempotency_keys WHERE org_id = $1 AND key = $2
+	`, tenant.OrgID(ctx), key)
+	if err != nil {
+		return fmt.Errorf("release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpiredIdempotencyKeys removes every organization's keys older than
+// ttl and returns how many.
+func (db *DB) DeleteExpiredIdempotencyKeys(ctx context.Context, ttl time.Duration) (int64, error) {
+	res, err := db.q().ExecContext(ctx, `
+		DELETE FROM idempotency_keys WHERE created_at <= NOW() - make_interval(secs => $1)
+	`, ttl.Seconds())
+	if err != nil {
+		return 0, fmt.Errorf("delete expired idempotency keys: %w", err)
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,201 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"cv-search/internal/tenant"
+)
+
+// ─── Candidate import ────────────────────────────────────────────────────────
+//
+// Candidates imported from another ATS are keyed by (import_source,
+// external_id), falling back to email, so importing the same export twice
+// updates rather than duplicates. Once a candidate's resume has been through
+// extraction, name, skills and experience come from its person node
+// (SyncCandidateTextFields); an import then only fills contact fields.
+
+// resumeFetchGrace is how long the background resume fetcher leaves a newly
+// imported resume_url alone, so the import's own download goes first.
+const resumeFetchGrace = `interval '15 minutes'`
+
+// UpsertImportedCandidate creates a candidate for rec or updates the one it
+// matches. Returns the candidate ID and whether it was created.
+func (db *DB) UpsertImportedCandidate(ctx context.Context, rec *CandidateImport) (candidateID int, created bool, err error) {
+	err = db.WithTx(ctx, func(tx *DB) error {
+		candidateID, created = 0, false
+
+		var graphNodeID sql.NullInt64
+		if rec.ExternalID != "" {
+			err := tx.q().QueryRowContext(ctx, `
+				SELECT id, graph_node_id FROM candidates
+				WHERE import_source = $1 AND external_id = $2 AND org_id = $3 AND deleted_at IS NULL
+			`, rec.Source, rec.ExternalID, tenant.OrgID(ctx)).Scan(&candidateID, &graphNodeID)
+			if err != nil && err != sql.ErrNoRows {
+				return fmt.Errorf("find imported candidate: %w", err)
+			}
+		}
+		if candidateID == 0 && rec.Email != "" {
+			err := tx.q().QueryRowContext(ctx, `
+				SELECT id, graph_node_id FROM candidates
+				WHERE lower(email) = lower($1) AND org_id = $2 AND deleted_at IS NULL
+				ORDER BY id
+				LIMIT 1
+			`, rec.Email, tenant.OrgID(ctx)).Scan(&candidateID, &graphNodeID)
+			if err != nil && err != sql.ErrNoRows {
+				return fmt.Errorf("find candidate by email: %w", err)
+			}
+		}
+
+		skills := strings.Join(rec.Skills, ",")
+		if candidateID == 0 {
+			if err := tx.q().QueryRowContext(ctx, `
+				INSERT INTO candidates (name, email, phone, location, experience, skills,
+				                        resume_url, resume_fetch_after, import_source, external_id, org_id, created_at, updated_at)
+				VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, ''),
+				        NULLIF($7, ''), NOW() + `+resumeFetchGrace+`, NULLIF($8, ''), NULLIF($9, ''), $10, NOW(), NOW())
+				RETURNING id
+			`, rec.Name, rec.Email, rec.Phone, rec.Location, rec.Experience, skills,
+				rec.ResumeURL, rec.Source, rec.ExternalID, tenant.OrgID(ctx),
+			).Scan(&candidateID); err != nil {
+				return fmt.Errorf("insert imported candidate: %w", err)
+			}
+			created = true
+		} else {
+			// A candidate matched by email keeps any import reference it
+			// already has. A new resume link is downloaded again.
+			if _, err := tx.q().ExecContext(ctx, `
+				UPDATE candidates SET
+					email         = COALESCE(NULLIF($2, ''), email),
+					phone         = COALESCE(NULLIF($3, ''), phone),
+					location      = COALESCE(NULLIF($4, ''), location),
+					resume_url    = COALESCE(NULLIF($5, ''), resume_url),
+					resume_downloaded_at  = CASE WHEN $5 <> '' AND $5 IS DISTINCT FROM resume_url THEN NULL ELSE resume_downloaded_at END,
+					resume_fetch_attempts = CASE WHEN $5 <> '' AND $5 IS DISTINCT FROM resume_url THEN 0 ELSE resume_fetch_attempts END,
+					resume_fetch_after    = CASE WHEN $5 <> '' AND $5 IS DISTINCT FROM resume_url THEN NOW() + `+resumeFetchGrace+` ELSE resume_fetch_after END,
+					import_source = COALESCE(import_source, NULLIF($6, '')),
+					external_id   = CASE WHEN import_source IS NULL THEN NULLIF($7, '') ELSE external_id END,
+					updated_at    = NOW()
+				WHERE id = $1
+			`, candidateID, rec.Email, rec.Phone, rec.Location, rec.ResumeURL, rec.Source, rec.ExternalID); err != nil {
+				return fmt.Errorf("update imported candidate: %w", err)
+			}
+			if graphNodeID.Valid {
+				return nil
+			}
+			if _, err := tx.q().ExecContext(ctx, `
+				UPDATE candidates SET
+					name       = COALESCE(NULLIF($2, ''), name),
+					experience = COALESCE(NULLIF($3, ''), experience),
+					skills     = COALESCE(NULLIF($4, ''), skills)
+				WHERE id = $1
+			`, candidateID, rec.Name, rec.Experience, skills); err != nil {
+				return fmt.Errorf("update imported candidate: %w", err)
+			}
+			if len(rec.Skills) == 0 {
+				return nil
+			}
+		}
+
+		links := make([]CandidateSkill, 0, len(rec.Skills))
+		for _, name := range rec.Skills {
+			links = append(links, CandidateSkill{Name: name})
+		}
+		return tx.replaceCandidateSkills(ctx, candidateID, links)
+	})
+	return candidateID, created, err
+}
+
+// MarkResumeDownloaded records that a candidate's resume_url was fetched and
+// stored under blobKey.
+func (db *DB) MarkResumeDownloaded(ctx context.Context, candidateID int, blobKey string) error {
+	if _, err := db.q().ExecContext(ctx, `
+		UPDATE candidates
+		SET resume_file_path = $2, resume_downloaded_at = NOW(), resume_fetch_error = NULL
+		WHERE id = $1
+	`, candidateID, blobKey); err != nil {
+		return fmt.Errorf("mark resume downloaded: %w", err)
+	}
+	return nil
+}
+
+// ClaimPendingResumes returns up to limit candidates whose resume_url is due
+// for download (fewer than maxAttempts failures) and pushes their next
+// attempt back by lease, so an overlapping pass doesn't pick them up too.
+// It claims across all organizations; each PendingResume carries its own.
+func (db *DB) ClaimPendingResumes(ctx context.Context, limit, maxAttempts int, lease time.Duration) ([]PendingResume, error) {
+	rows, err := db.q().QueryContext(ctx, `
+		UPDATE candidates c
+		SET resume_fetch_after = NOW() + $3 * interval '1 second'
+		FROM (
+			SELECT id FROM candidates
+			WHERE resume_url IS NOT NULL
+			  AND resume_downloaded_at IS NULL
+			  AND deleted_at IS NULL
+			  AND resume_fetch_attempts < $2
+			  AND (resume_fetch_after IS NULL OR resume_fetch_after <= NOW())
+			ORDER BY resume_fetch_after NULLS FIRST, id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		) due
+		WHERE c.id = due.id
+		RETURNING c.id, c.org_id, c.resume_url, c.resume_fetch_attempts
+	`, limit, maxAttempts, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("claim pending resumes: %w", err)
+	}
+	defer rows.Close()
+
+	var pending []PendingResume
+	for rows.Next() {
+		var p PendingResume
+		if err := rows.Scan(&p.CandidateID, &p.OrgID, &p.URL, &p.Attempts); err != nil {
+			return nil, fmt.Errorf("scan pending resume: %w", err)
+		}
+		pending = append(pending, p)
+	}
+	return pending, rows.Err()
+}
+
+// RecordResumeFetchFailure counts a failed resume_url download and schedules
+// the next attempt after retryAfter.
+func (db *DB) RecordResumeFetchFailure(ctx context.Context, candidateID int, errMsg string, retryAfter time.Duration) error {
+	if _, err := db.q().ExecContext(ctx, `
+		UPDATE candidates
+		SET resume_fetch_attempts = resume_fetch_attempts + 1,
+		    resume_fetch_error = $2,
+		    resume_fetch_after = NOW() + $3 * interval '1 second'
+		WHERE id = $1
+	`, candidateID, errMsg, retryAfter.Seconds()); err != nil {
+		return fmt.Errorf("record resume fetch failure: %w", err)
+	}
+	return nil
+}
+
+// claimCVFileCandidate links the candidate a CV was saved for (cv_files.
+// candidate_id, set by imports before extraction) to the person node built
+// from it, so LinkCandidateToCV doesn't create a second candidate. Returns 0
+// when the CV has no candidate, or that candidate is already linked to a
+// different person node, or another candidate already has this one.
+func (db *DB) claimCVFileCandidate(ctx context.Context, cvFileID int64, graphNodeID int) (int, error) {
+	var candidateID int
+	err := db.q().QueryRowContext(ctx, `
+		UPDATE candidates c
+		SET graph_node_id = $2, updated_at = NOW()
+		FROM cv_files f
+		WHERE f.id = $1 AND c.id = f.candidate_id AND c.deleted_at IS NULL
+		  AND (c.graph_node_id IS NULL OR c.graph_node_id = $2)
+		  AND NOT EXISTS (SELECT 1 FROM candidates o WHERE o.graph_node_id = $2 AND o.id <> c.id)
+		RETURNING c.id
+	`, cvFileID, graphNodeID).Scan(&candidateID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("claim cv file candidate: %w", err)
+	}
+	return candidateID, nil
+}
@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"cv-search/internal/tenant"
+)
+
+// ─── ATS integrations ────────────────────────────────────────────────────────
+
+// ListOrgIntegrations returns an organization's ATS integrations by target.
+func (db *DB) ListOrgIntegrations(ctx context.Context, orgID int) ([]OrgIntegration, error) {
+	rows, err := db.q().QueryContext(ctx, `
+		SELECT target, api_key, user_id, job_id, updated_at
+		FROM organization_integrations
+		WHERE org_id = $1
+		ORDER BY target
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("list organization %d integrations: %w", orgID, err)
+	}
+	defer rows.Close()
+
+	out := []OrgIntegration{}
+	for rows.Next() {
+		in := OrgIntegration{OrgID: orgID}
+		if err := rows.Scan(&in.Target, &in.APIKey, &in.UserID, &in.JobID, &in.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan organization integration: %w", err)
+		}
+		out = append(out, in)
+	}
+	return out, rows.Err()
+}
+
+// GetOrgIntegration returns an organization's integration with target, nil
+// if it has none. It reads the primary so a change is seen at once.
+func (db *DB) GetOrgIntegration(ctx context.Context, orgID int, target string) (*OrgIntegration, error) {
+	in := OrgIntegration{OrgID: orgID, Target: target}
+	err := db.q().QueryRowContext(ctx, `
+		SELECT api_key, user_id, job_id, updated_at
+		FROM organization_integrations
+		WHERE org_id = $1 AND target = $2
+	`, orgID, target).Scan(&in.APIKey, &in.UserID, &in.JobID, &in.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get organization %d %s integration: %w", orgID, target, err)
+	}
+	return &in, nil
+}
+
+// SaveOrgIntegration replaces an organization's integration with in.Target
+// and sets in.UpdatedAt.
+func (db *DB) SaveOrgIntegration(ctx context.Context, in *OrgIntegration) error {
+	err := db.q().QueryRowContext(ctx, `
+		INSERT INTO organization_integrations (org_id, target, api_key, user_id, job_id, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (org_id, target) DO UPDATE SET
+			api_key    = EXCLUDED.api_key,
+			user_id    = EXCLUDED.user_id,
+			job_id     = EXCLUDED.job_id,
+			updated_at = NOW()
+		RETURNING updated_at
+	`, in.OrgID, in.Target, in.APIKey, in.UserID, in.JobID).Scan(&in.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("save organization %d %s integration: %w", in.OrgID, in.Target, err)
+	}
+	return nil
+}
+
+// DeleteOrgIntegration removes an organization's integration with target.
+// It reports false if there was none.
+func (db *DB) DeleteOrgIntegration(ctx context.Context, orgID int, target string) (bool, error) {
+	res, err := db.q().ExecContext(ctx,
+		`DELETE FROM organization_integrations WHERE org_id = $1 AND target = $2`, orgID, target)
+	if err != nil {
+		return false, fmt.Errorf("delete organization %d %s integration: %w", orgID, target, err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// ─── Candidate pushes ────────────────────────────────────────────────────────
+
+// GetCandidatePush returns where a candidate of ctx's organization was
+// pushed to target, nil if it wasn't.
+func (db *DB) GetCandidatePush(ctx context.Context, candidateID int, target string) (*CandidatePush, error) {
+	p := CandidatePush{CandidateID: candidateID, Target: target}
+	err := db.q().QueryRowContext(ctx, `
+		SELECT p.id, p.external_id, p.external_url, p.pushed_by, p.pushed_at
+		FROM candidate_pushes p
+		JOIN candidates c ON c.id = p.candidate_id
+		WHERE p.candidate_id = $1 AND p.target = $2 AND c.org_id = $3
+	`, candidateID, target, tenant.OrgID(ctx)).Scan(&p.ID, &p.ExternalID, &p.ExternalURL, &p.PushedBy, &p.PushedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get candidate %d %s push: %w", candidateID, target, err)
+	}
+	return &p, nil
+}
+
+// RecordCandidatePush stores p, replacing an earlier push of the candidate to
+// the same target, and sets p.ID and p.PushedAt. Returns sql.ErrNoRows if the
+// candidate does not exist in ctx's organization.
+func (db *DB) RecordCandidatePush(ctx context.Context, p *CandidatePush) error {
+	err := db.q().QueryRowContext(ctx, `
+		INSERT INTO candidate_pushes (candidate_id, target, external_id, external_url, pushed_by)
+		SELECT id, $2, $3, $4, $5 FROM candidates WHERE id = $1 AND org_id = $6
+		ON CONFLICT (candidate_id, target) DO UPDATE SET
+			external_id  = EXCLUDED.external_id,
+			external_url = EXCLUDED.external_url,
+			pushed_by    = EXCLUDED.pushed_by,
+			pushed_at    = NOW()
+		RETURNING id, pushed_at
+	`, p.CandidateID, p.Target, p.ExternalID, p.ExternalURL, p.PushedBy, tenant.OrgID(ctx)).Scan(&p.ID, &p.PushedAt)
+	if err == sql.ErrNoRows {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("record candidate %d %s push: %w", p.CandidateID, p.Target, err)
+	}
+	return nil
+}
+
+// GetLatestCVFileForCandidate returns a candidate's most recently uploaded
+// CV, nil if it has none.
+func (db *DB) GetLatestCVFileForCandidate(ctx context.Context, candidateID int) (*CVFileInfo, error) {
+	var info CVFileInfo
+	err := db.q().QueryRowContext(ctx, `
+		SELECT id, filename, COALESCE(file_path, ''), COALESCE(file_type, ''), file_size, uploaded_at, candidate_id, ocr_used, anonymized,
+		       COALESCE(photo_key, '')
+		FROM cv_files
+		WHERE candidate_id = $1 AND org_id = $2 AND deleted_at IS NULL
+		ORDER BY uploaded_at DESC, id DESC
+		LIMIT 1
+	`, candidateID, tenant.OrgID(ctx)).Scan(
+		&info.ID, &info.Filename, &info.FilePath, &info.FileType, &info.FileSize, &info.UploadedAt, &info.CandidateID, &info.OCRUsed, &info.Anonymized,
+		&info.PhotoKey,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get latest cv file of candidate %d: %w", candidateID, err)
+	}
+	return &info, nil
+}
@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"cv-search/internal/tenant"
+)
+
+func (db *DB) getInterviewsByCandidate(ctx context.Context, candidateID int) ([]Interview, error) {
+	rows, err := db.q().QueryContext(ctx, `
+		SELECT id, candidate_id, interview_date, COALESCE(team,''), COALESCE(interviewer_name,''),
+		       COALESCE(interview_type,''), COALESCE(notes,''), COALESCE(outcome,''),
+		       created_at, updated_at
+		FROM interviews
+		WHERE candidate_id = $1
+		ORDER BY interview_date DESC, id DESC
+	`, candidateID)
+	if err != nil {
+		return nil, fmt.Errorf("get interviews: %w", err)
+	}
+	defer rows.Close()
+
+	var result []Interview
+	for rows.Next() {
+		var iv Interview
+		if err := rows.Scan(
+			&iv.ID, &iv.CandidateID, &iv.InterviewDate, &iv.Team, &iv.InterviewerName,
+			&iv.InterviewType, &iv.Notes, &iv.Outcome, &iv.CreatedAt, &iv.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan interview: %w", err)
+		}
+		result = append(result, iv)
+	}
+	return result, rows.Err()
+}
+
+// GetInterviewsByGraphNodeIDs batch-fetches interview summaries for a list of graph node IDs.
+// Returns a map of graphNodeID → []InterviewSummary for use in search result enrichment.
+func (db *DB) GetInterviewsByGraphNodeIDs(ctx context.Context, graphNodeIDs []int) (map[int][]InterviewSummary, error) {
+	if len(graphNodeIDs) == 0 {
+		return map[int][]InterviewSummary{}, nil
+	}
+
+	placeholders := make([]string, len(graphNodeIDs))
+	args := make([]interface{}, len(graphNodeIDs))
+	for i, id := range graphNodeIDs {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = id
+	}
+	inClause := "(" + strings.Join(placeholders, ",") + ")"
+
+	query := fmt.Sprintf(`
+		SELECT c.graph_node_id, i.id, i.interview_date,
+		       COALESCE(i.team,''), COALESCE(i.interviewer_name,''),
+		       COALESCE(i.interview_type,''), COALESCE(i.outcome,'')
+		FROM interviews i
+		JOIN candidates c ON c.id = i.candidate_id
+		WHERE c.graph_node_id IN %s
+		  AND c.deleted_at IS NULL
+		ORDER BY i.interview_date DESC
+	`, inClause)
+
+	rows, err := db.r().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("batch get interviews: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[int][]InterviewSummary)
+	for rows.Next() {
+		var nodeID int
+		var s InterviewSummary
+		if err := rows.Scan(
+			&nodeID, &s.ID, &s.InterviewDate, &s.Team, &s.InterviewerName, &s.InterviewType, &s.Outcome,
+		); err != nil {
+			return nil, fmt.Errorf("scan interview summary: %w", err)
+		}
+		result[nodeID] = append(result[nodeID], s)
+	}
+	return result, rows.Err()
+}
+
+// GetInterviewNotesByGraphNodeID returns all interview notes concatenated for re-embedding.
+func (db *DB) GetInterviewNotesByGraphNodeID(ctx context.Context, graphNodeID int) ([]string, error) {
+	rows, err := db.q().QueryContext(ctx, `
+		SELECT COALESCE(i.notes, '')
+		FROM interviews i
+		JOIN candidates c ON c.id = i.candidate_id
+		WHERE c.graph_node_id = $1 AND i.notes <> '' AND c.deleted_at IS NULL
+		ORDER BY i.interview_date DESC
+	`, graphNodeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var notes []string
+	for rows.Next() {
+		var n string
+		if err := rows.Scan(&n); err != nil {
+			return nil, err
+		}
+		notes = append(notes, n)
+	}
+	return notes, rows.Err()
+}
+
+// CreateInterview inserts a new interview record and returns the new ID.
+// Returns sql.ErrNoRows if the candidate does not exist in ctx's organization.
+func (db *DB) CreateInterview(ctx context.Context, candidateID int, iv Interview) (int, error) {
+	var newID int
+	err := db.q().QueryRowContext(ctx, `
+		INSERT INTO interviews (candidate_id, interview_date, team, interviewer_name, interview_type, notes, outcome, created_at, updated_at)
+		SELECT id, $2, $3, $4, $5, $6, $7, NOW(), NOW()
+		FROM candidates WHERE id = $1 AND org_id = $8
+		RETURNING id
+	`, candidateID, iv.InterviewDate, iv.Team, iv.InterviewerName, iv.InterviewType, iv.Notes, iv.Outcome, tenant.OrgID(ctx),
+	).Scan(&newID)
+	if err == sql.ErrNoRows {
+		return 0, err
+	}
+	if err != nil {
+		return 0, fmt.Errorf("create interview: %w", err)
+	}
+	return newID, nil
+}
+
+// UpdateInterview updates an existing interview. Returns sql.ErrNoRows if not found
+// or if the interview doesn't belong to the given candidateID.
+func (db *DB) UpdateInterview(ctx context.Context, interviewID, candidateID int, iv Interview) error {
+	res, err := db.q().ExecContext(ctx, `
+		UPDATE interviews
+		SET interview_date = $1, team = $2, interviewer_name = $3,
+		    interview_type = $4, notes = $5, outcome = $6, updated_at = NOW()
+		WHERE id = $7 AND candidate_id = $8
+		  AND candidate_id IN (SELECT id FROM candidates WHERE org_id = $9)
+	`, iv.InterviewDate, iv.Team, iv.InterviewerName, iv.InterviewType, iv.Notes, iv.Outcome,
+		interviewID, candidateID, tenant.OrgID(ctx),
+	)
+	if err != nil {
+		return fmt.Errorf("update interview: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteInterview removes a specific interview. Enforces candidateID ownership.
+func (db *DB) DeleteInterview(ctx context.Context, interviewID, candidateID int) error {
+	res, err := db.q().ExecContext(ctx, `
+		DELETE FROM interviews
+		WHERE id = $1 AND candidate_id = $2
+		  AND candidate_id IN (SELECT id FROM candidates WHERE org_id = $3)
+	`, interviewID, candidateID, tenant.OrgID(ctx))
+	if err != nil {
+		return fmt.Errorf("delete interview: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
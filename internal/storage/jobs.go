@@ -0,0 +1,281 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"cv-search/internal/tenant"
+)
+
+// CreateCVUploadJob creates a new async CV processing job
+func (db *DB) CreateCVUploadJob(ctx context.Context, cvFileID int64) (int64, error) {
+	query := `
+        INSERT INTO cv_upload_jobs (cv_file_id, status, created_at)
+        VALUES ($1, 'pending', NOW())
+        RETURNING id
+    `
+	var jobID int64
+	err := db.q().QueryRowContext(ctx, query, cvFileID).Scan(&jobID)
+	if err != nil {
+		return 0, err
+	}
+
+	// Update cv_files with job_id
+	_, err = db.q().ExecContext(ctx, `UPDATE cv_files SET job_id = $1 WHERE id = $2`, jobID, cvFileID)
+	if err != nil {
+		log.Printf("[DB] Warning: Failed to update cv_files.job_id: %v", err)
+	}
+
+	return jobID, nil
+}
+
+// UpdateJobStatus updates job status and timestamps
+func (db *DB) UpdateJobStatus(ctx context.Context, jobID int64, status string, errorMsg *string) error {
+	var query string
+	var args []interface{}
+
+	switch status {
+	case "processing":
+		query = `UPDATE cv_upload_jobs SET status = $1, started_at = NOW() WHERE id = $2`
+		args = []interface{}{status, jobID}
+	case "completed":
+		query = `UPDATE cv_upload_jobs SET status = $1, completed_at = NOW() WHERE id = $2`
+		args = []interface{}{status, jobID}
+	case "failed":
+		query = `UPDATE cv_upload_jobs SET status = $1, error_message = $2, completed_at = NOW() WHERE id = $3`
+		args = []interface{}{status, errorMsg, jobID}
+	default:
+		query = `UPDATE cv_upload_jobs SET status = $1 WHERE id = $2`
+		args = []interface{}{status, jobID}
+	}
+
+	_, err := db.q().ExecContext(ctx, query, args...)
+	return err
+}
+
+// IncrementJobRetryCount increments retry_count for a job and returns the new
+// retry_count and the configured max_retries, so the caller can decide whether
+// to requeue the job or mark it permanently failed.
+func (db *DB) IncrementJobRetryCount(ctx context.Context, jobID int64) (retryCount int, maxRetries int, err error) {
+	err = db.q().QueryRowContext(ctx, `
+		UPDATE cv_upload_jobs
+		SET retry_count = retry_count + 1
+		WHERE id = $1
+		RETURNING retry_count, max_retries
+	`, jobID).Scan(&retryCount, &maxRetries)
+	return retryCount, maxRetries, err
+}
+
+// CreateGroqBatchJob records a newly submitted Groq Batch API job.
+func (db *DB) CreateGroqBatchJob(ctx context.Context, groqBatchID, inputFileID string, requestCount int) (int64, error) {
+	var id int64
+	err := db.q().QueryRowContext(ctx, `
+		INSERT INTO llm_batch_jobs (groq_batch_id, input_file_id, status, request_count, created_at)
+		VALUES ($1, $2, 'submitted', $3, NOW())
+		RETURNING id
+	`, groqBatchID, inputFileID, requestCount).Scan(&id)
+	return id, err
+}
+
+// LinkJobsToGroqBatch marks the given cv_upload_jobs as part of a Groq batch,
+// setting status to 'batch_submitted' so real-time polling clients see it's
+// no longer sitting in the immediate queue.
+func (db *DB) LinkJobsToGroqBatch(ctx context.Context, groqBatchID string, jobIDs []int64) error {
+	if len(jobIDs) == 0 {
+		return nil
+	}
+	_, err := db.q().ExecContext(ctx, `
+		UPDATE cv_upload_jobs
+		SET status = 'batch_submitted', groq_batch_id = $1
+		WHERE id = ANY($2)
+	`, groqBatchID, jobIDs)
+	return err
+}
+
+// GroqBatchJobRow is a row from llm_batch_jobs.
+type GroqBatchJobRow struct {
+	ID           int64
+	GroqBatchID  string
+	InputFileID  string
+	OutputFileID *string
+	ErrorFileID  *string
+	Status       string
+}
+
+// ListOpenGroqBatchJobs returns all batch jobs that haven't reached a terminal
+// state yet (for the background poller to check on).
+func (db *DB) ListOpenGroqBatchJobs(ctx context.Context) ([]GroqBatchJobRow, error) {
+	rows, err := db.q().QueryContext(ctx, `
+		SELECT id, groq_batch_id, input_file_id, output_file_id, error_file_id, status
+		FROM llm_batch_jobs
+		WHERE status NOT IN ('completed', 'failed', 'expired', 'cancelled')
+		ORDER BY created_at ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []GroqBatchJobRow
+	for rows.Next() {
+		var r GroqBatchJobRow
+		if err := rows.Scan(&r.ID, &r.GroqBatchID, &r.InputFileID, &r.OutputFileID, &r.ErrorFileID, &r.Status); err != nil {
+			return nil, err
+		}
+		result = append(result, r)
+	}
+	return result, rows.Err()
+}
+
+// UpdateGroqBatchJobStatus updates the tracked status/output files for a batch.
+func (db *DB) UpdateGroqBatchJobStatus(ctx context.Context, groqBatchID, status string, outputFileID, errorFileID *string) error {
+	if status == "completed" || status == "failed" || status == "expired" || status == "cancelled" {
+		_, err := db.q().ExecContext(ctx, `
+			UPDATE llm_batch_jobs
+			SET status = $1, output_file_id = $2, error_file_id = $3, completed_at = NOW()
+			WHERE groq_batch_id = $4
+		`, status, outputFileID, errorFileID, groqBatchID)
+		return err
+	}
+	_, err := db.q().ExecContext(ctx, `
+		UPDATE llm_batch_jobs
+		SET status = $1, output_file_id = $2, error_file_id = $3
+		WHERE groq_batch_id = $4
+	`, status, outputFileID, errorFileID, groqBatchID)
+	return err
+}
+
+// GetJobsByGroqBatchID returns the cv_upload_jobs linked to a given batch,
+// keyed by cv_file_id (used as the batch's custom_id) so results can be
+// matched back to jobs.
+func (db *DB) GetJobsByGroqBatchID(ctx context.Context, groqBatchID string) (map[int64]int64, error) {
+	rows, err := db.q().QueryContext(ctx, `
+		SELECT id, cv_file_id FROM cv_upload_jobs WHERE groq_batch_id = $1
+	`, groqBatchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[int64]int64) // cv_file_id -> job_id
+	for rows.Next() {
+		var jobID, cvFileID int64
+		if err := rows.Scan(&jobID, &cvFileID); err != nil {
+			return nil, err
+		}
+		result[cvFileID] = jobID
+	}
+	return result, rows.Err()
+}
+
+// GetCVTextsByFileIDs fetches parsed_text for a set of cv_files, keyed by id.
+func (db *DB) GetCVTextsByFileIDs(ctx context.Context, cvFileIDs []int64) (map[int64]string, error) {
+	if len(cvFileIDs) == 0 {
+		return map[int64]string{}, nil
+	}
+	rows, err := db.q().QueryContext(ctx, `
+		SELECT id, parsed_text FROM cv_files WHERE id = ANY($1)
+	`, cvFileIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[int64]string)
+	for rows.Next() {
+		var id int64
+		var text sql.NullString
+		if err := rows.Scan(&id, &text); err != nil {
+			return nil, err
+		}
+		result[id] = text.String
+	}
+	return result, rows.Err()
+}
+
+// GetJobByID retrieves a job by ID
+func (db *DB) GetJobByID(ctx context.Context, jobID int64) (*CVUploadJob, error) {
+	query := `
+        SELECT j.id, j.cv_file_id, j.status, j.error_message, j.progress,
+               j.created_at, j.started_at, j.completed_at, j.retry_count, j.max_retries
+        FROM cv_upload_jobs j
+        JOIN cv_files f ON f.id = j.cv_file_id
+        WHERE j.id = $1 AND f.org_id = $2
+    `
+	var job CVUploadJob
+	var progressJSON sql.NullString
+
+	err := db.q().QueryRowContext(ctx, query, jobID, tenant.OrgID(ctx)).Scan(
+		&job.ID, &job.CVFileID, &job.Status, &job.ErrorMessage,
+		&progressJSON, &job.CreatedAt, &job.StartedAt, &job.CompletedAt,
+		&job.RetryCount, &job.MaxRetries,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	job.Progress = make(map[string]interface{})
+
+	return &job, nil
+}
+
+// GetLatestJobForCVFile returns the newest processing job of a CV file of
+// ctx's organization, or nil if it has none.
+func (db *DB) GetLatestJobForCVFile(ctx context.Context, cvFileID int64) (*CVUploadJob, error) {
+	var job CVUploadJob
+	err := db.q().QueryRowContext(ctx, `
+		SELECT j.id, j.cv_file_id, j.status, j.error_message,
+		       j.created_at, j.started_at, j.completed_at, j.retry_count, j.max_retries
+		FROM cv_upload_jobs j
+		JOIN cv_files f ON f.id = j.cv_file_id
+		WHERE j.cv_file_id = $1 AND f.org_id = $2
+		ORDER BY j.created_at DESC, j.id DESC
+		LIMIT 1
+	`, cvFileID, tenant.OrgID(ctx)).Scan(
+		&job.ID, &job.CVFileID, &job.Status, &job.ErrorMessage,
+		&job.CreatedAt, &job.StartedAt, &job.CompletedAt, &job.RetryCount, &job.MaxRetries,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get latest job of cv file %d: %w", cvFileID, err)
+	}
+	return &job, nil
+}
+
+// JobQueueStatus counts CV processing jobs by status.
+func (db *DB) JobQueueStatus(ctx context.Context) (*JobQueueStatus, error) {
+	rows, err := db.r().QueryContext(ctx, `SELECT status, COUNT(*) FROM cv_upload_jobs GROUP BY status`)
+	if err != nil {
+		return nil, fmt.Errorf("count jobs: %w", err)
+	}
+	defer rows.Close()
+	st := &JobQueueStatus{ByStatus: map[string]int{}}
+	for rows.Next() {
+		var status string
+		var n int
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, fmt.Errorf("scan job count: %w", err)
+		}
+		st.ByStatus[status] = n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("count jobs: %w", err)
+	}
+	err = db.r().QueryRowContext(ctx, `
+        SELECT (SELECT MIN(created_at) FROM cv_upload_jobs WHERE status = 'pending'),
+               (SELECT COUNT(*) FROM llm_batch_jobs
+                 WHERE status NOT IN ('completed', 'failed', 'expired', 'cancelled'))
+    `).Scan(&st.OldestPending, &st.OpenBatches)
+	if err != nil {
+		return nil, fmt.Errorf("job backlog: %w", err)
+	}
+	return st, nil
+}
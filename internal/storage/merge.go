@@ -0,0 +1,680 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"cv-search/internal/tenant"
+)
+
+// ─── Candidate merge ─────────────────────────────────────────────────────────
+//
+// Two CV versions with slightly different emails end up as two candidates
+// with two person nodes. A merge folds the duplicate into the primary: the
+// duplicate's edges, CV files, interviews and notes move over, its tags and
+// talent pool memberships are copied, and whichever side uploaded a CV most
+// recently wins on conflicting properties. The duplicate candidate and node
+// are soft-deleted and candidate_merges keeps a snapshot so the merge can be
+// undone.
+//
+// Reprocessing a CV that came from the duplicate rebuilds (and restores) its
+// own person node; undo the merge first or merge again afterwards.
+
+// ErrMergeNotAllowed is returned when a merge or undo can't be applied in the
+// current state (same candidate twice, deleted candidate, missing person
+// node, already undone, ...). The wrapping error says why.
+var ErrMergeNotAllowed = errors.New("merge not allowed")
+
+// mergeCandidateFields are the candidates columns a merge may overwrite.
+// skills and experience are derived from the graph and re-synced instead.
+type mergeCandidateFields struct {
+	Name        string `json:"name"`
+	Email       string `json:"email"`
+	Phone       string `json:"phone"`
+	Location    string `json:"location"`
+	LinkedInURL string `json:"linkedin_url"`
+	Education   string `json:"education"`
+	Summary     string `json:"summary"`
+}
+
+type mergeEdgePatch struct {
+	ID         int             `json:"id"`
+	Properties json.RawMessage `json:"properties"`
+}
+
+// mergeSnapshot is stored in candidate_merges.snapshot: what the merge
+// changed, so UndoCandidateMerge can put it back.
+type mergeSnapshot struct {
+	Primary           mergeCandidateFields `json:"primary"`
+	PrimaryProperties json.RawMessage      `json:"primary_properties"`
+	MergedWasNewer    bool                 `json:"merged_was_newer"`
+	MovedEdgeIDs      []int                `json:"moved_edge_ids"`
+	PatchedEdges      []mergeEdgePatch     `json:"patched_edges"` // shared edges whose properties were overwritten
+	SharedEdges       int                  `json:"shared_edges"`
+	CVFileIDs         []int                `json:"cv_file_ids"`
+	InterviewIDs      []int                `json:"interview_ids"`
+	NoteIDs           []int                `json:"note_ids"`
+	AddedTags         []string             `json:"added_tags"`     // copied to the primary; the duplicate keeps its own
+	AddedPoolIDs      []int                `json:"added_pool_ids"` // talent pools the primary was added to, likewise
+}
+
+type mergeSide struct {
+	id        int
+	nodeID    sql.NullInt64
+	deleted   bool
+	fields    mergeCandidateFields
+	updatedAt time.Time
+	latestCV  sql.NullTime
+}
+
+// newest is the time of the side's latest CV upload, or of its last update
+// when it has no CV files.
+func (s mergeSide) newest() time.Time {
+	if s.latestCV.Valid {
+		return s.latestCV.Time
+	}
+	return s.updatedAt
+}
+
+// MergeCandidates folds candidate mergedID into primaryID (see the section
+// comment). Returns nil, nil if either candidate doesn't exist.
+func (db *DB) MergeCandidates(ctx context.Context, primaryID, mergedID int, actor string) (*CandidateMerge, error) {
+	if primaryID == mergedID {
+		return nil, fmt.Errorf("%w: cannot merge a candidate into itself", ErrMergeNotAllowed)
+	}
+
+	var res *CandidateMerge
+	err := db.WithTx(ctx, func(tx *DB) error {
+		sides, err := tx.lockMergeSides(ctx, primaryID, mergedID)
+		if err != nil {
+			return err
+		}
+		if sides == nil {
+			return nil
+		}
+		primary, merged := sides[0], sides[1]
+		for _, s := range sides {
+			if s.deleted {
+				return fmt.Errorf("%w: candidate %d is deleted", ErrMergeNotAllowed, s.id)
+			}
+			if !s.nodeID.Valid {
+				return fmt.Errorf("%w: candidate %d has no person node yet", ErrMergeNotAllowed, s.id)
+			}
+		}
+		primaryNode, mergedNode := int(primary.nodeID.Int64), int(merged.nodeID.Int64)
+
+		snap := mergeSnapshot{
+			Primary:        primary.fields,
+			MergedWasNewer: merged.newest().After(primary.newest()),
+		}
+
+		// Person node properties: the newer side's non-empty values win.
+		var primaryProps, mergedProps []byte
+		if err := tx.q().QueryRowContext(ctx,
+			`SELECT COALESCE(properties, '{}'::jsonb) FROM graph_nodes WHERE id = $1 FOR UPDATE`, primaryNode,
+		).Scan(&primaryProps); err != nil {
+			return fmt.Errorf("get primary node: %w", err)
+		}
+		if err := tx.q().QueryRowContext(ctx,
+			`SELECT COALESCE(properties, '{}'::jsonb) FROM graph_nodes WHERE id = $1 FOR UPDATE`, mergedNode,
+		).Scan(&mergedProps); err != nil {
+			return fmt.Errorf("get merged node: %w", err)
+		}
+		snap.PrimaryProperties = primaryProps
+		older, newer := mergedProps, primaryProps
+		if snap.MergedWasNewer {
+			older, newer = primaryProps, mergedProps
+		}
+		props, err := overlayProperties(older, newer)
+		if err != nil {
+			return fmt.Errorf("merge node properties: %w", err)
+		}
+		if _, err := tx.q().ExecContext(ctx,
+			`UPDATE graph_nodes SET properties = $2 WHERE id = $1`, primaryNode, props,
+		); err != nil {
+			return fmt.Errorf("update primary node: %w", err)
+		}
+
+		if err := tx.mergeEdges(ctx, primaryNode, mergedNode, &snap); err != nil {
+			return err
+		}
+
+		if snap.CVFileIDs, err = tx.moveCandidateRows(ctx, "cv_files", mergedID, primaryID); err != nil {
+			return err
+		}
+		if snap.InterviewIDs, err = tx.moveCandidateRows(ctx, "interviews", mergedID, primaryID); err != nil {
+			return err
+		}
+		if snap.NoteIDs, err = tx.moveCandidateRows(ctx, "candidate_notes", mergedID, primaryID); err != nil {
+			return err
+		}
+		if snap.AddedTags, err = tx.copyCandidateTags(ctx, mergedID, primaryID); err != nil {
+			return err
+		}
+		if snap.AddedPoolIDs, err = tx.copyPoolMemberships(ctx, mergedID, primaryID); err != nil {
+			return err
+		}
+
+		fields := mergeFields(primary.fields, merged.fields, snap.MergedWasNewer)
+		if err := tx.setMergeFields(ctx, primaryID, fields); err != nil {
+			return err
+		}
+
+		if _, err := tx.q().ExecContext(ctx,
+			`UPDATE candidates SET deleted_at = NOW() WHERE id = $1`, mergedID,
+		); err != nil {
+			return fmt.Errorf("soft delete merged candidate: %w", err)
+		}
+		if _, err := tx.q().ExecContext(ctx,
+			`UPDATE graph_nodes SET deleted_at = NOW() WHERE id = $1`, mergedNode,
+		); err != nil {
+			return fmt.Errorf("soft delete merged node: %w", err)
+		}
+		if err := tx.SyncCandidateTextFields(ctx, primaryID, primaryNode); err != nil {
+			return fmt.Errorf("sync candidate text fields: %w", err)
+		}
+
+		snapJSON, err := json.Marshal(snap)
+		if err != nil {
+			return fmt.Errorf("marshal merge snapshot: %w", err)
+		}
+		m := &CandidateMerge{
+			PrimaryCandidateID: primaryID,
+			MergedCandidateID:  mergedID,
+			PrimaryNodeID:      &primaryNode,
+			MergedNodeID:       &mergedNode,
+			Actor:              actor,
+		}
+		if err := tx.q().QueryRowContext(ctx, `
+			INSERT INTO candidate_merges (primary_candidate_id, merged_candidate_id, primary_node_id, merged_node_id, snapshot, actor)
+			VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''))
+			RETURNING id, created_at
+		`, primaryID, mergedID, primaryNode, mergedNode, snapJSON, actor).Scan(&m.ID, &m.CreatedAt); err != nil {
+			return fmt.Errorf("record merge: %w", err)
+		}
+		m.applySnapshot(snap)
+		res = m
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// UndoCandidateMerge reverses a merge: moved edges, CV files, interviews and
+// notes go back to the duplicate, copied tags and pool memberships are
+// removed from the primary, the primary's fields and node properties are
+// restored from the snapshot, and the duplicate is undeleted. A merge can
+// only be undone while no later merge involving either candidate is active.
+// Returns nil, nil if the merge doesn't exist.
+func (db *DB) UndoCandidateMerge(ctx context.Context, mergeID int64, actor string) (*CandidateMerge, error) {
+	var res *CandidateMerge
+	err := db.WithTx(ctx, func(tx *DB) error {
+		m, snap, err := tx.lockCandidateMerge(ctx, mergeID)
+		if err != nil {
+			return err
+		}
+		if m == nil {
+			return nil
+		}
+		if m.UndoneAt != nil {
+			return fmt.Errorf("%w: merge %d was already undone", ErrMergeNotAllowed, mergeID)
+		}
+		if m.PrimaryNodeID == nil || m.MergedNodeID == nil {
+			return fmt.Errorf("%w: a person node of merge %d no longer exists", ErrMergeNotAllowed, mergeID)
+		}
+		var later int64
+		err = tx.q().QueryRowContext(ctx, `
+			SELECT id FROM candidate_merges
+			WHERE id > $1 AND undone_at IS NULL
+			  AND (primary_candidate_id IN ($2, $3) OR merged_candidate_id IN ($2, $3))
+			ORDER BY id DESC LIMIT 1
+		`, mergeID, m.PrimaryCandidateID, m.MergedCandidateID).Scan(&later)
+		if err == nil {
+			return fmt.Errorf("%w: undo the later merge %d first", ErrMergeNotAllowed, later)
+		}
+		if err != sql.ErrNoRows {
+			return fmt.Errorf("check later merges: %w", err)
+		}
+		primaryNode, mergedNode := *m.PrimaryNodeID, *m.MergedNodeID
+
+		if len(snap.MovedEdgeIDs) > 0 {
+			if _, err := tx.q().ExecContext(ctx, `
+				UPDATE graph_edges SET source_node_id = $2
+				WHERE id = ANY($1) AND source_node_id = $3
+			`, snap.MovedEdgeIDs, mergedNode, primaryNode); err != nil {
+				return fmt.Errorf("restore edges: %w", err)
+			}
+		}
+		for _, p := range snap.PatchedEdges {
+			if _, err := tx.q().ExecContext(ctx,
+				`UPDATE graph_edges SET properties = $2 WHERE id = $1`, p.ID, []byte(p.Properties),
+			); err != nil {
+				return fmt.Errorf("restore edge %d properties: %w", p.ID, err)
+			}
+		}
+		if _, err := tx.q().ExecContext(ctx,
+			`UPDATE graph_nodes SET properties = $2 WHERE id = $1`, primaryNode, []byte(snap.PrimaryProperties),
+		); err != nil {
+			return fmt.Errorf("restore primary node: %w", err)
+		}
+
+		if err := tx.restoreCandidateRows(ctx, "cv_files", m.PrimaryCandidateID, m.MergedCandidateID, snap.CVFileIDs); err != nil {
+			return err
+		}
+		if err := tx.restoreCandidateRows(ctx, "interviews", m.PrimaryCandidateID, m.MergedCandidateID, snap.InterviewIDs); err != nil {
+			return err
+		}
+		if err := tx.restoreCandidateRows(ctx, "candidate_notes", m.PrimaryCandidateID, m.MergedCandidateID, snap.NoteIDs); err != nil {
+			return err
+		}
+		if len(snap.AddedTags) > 0 {
+			if _, err := tx.q().ExecContext(ctx,
+				`DELETE FROM candidate_tags WHERE candidate_id = $1 AND tag = ANY($2)`, m.PrimaryCandidateID, snap.AddedTags,
+			); err != nil {
+				return fmt.Errorf("remove copied tags: %w", err)
+			}
+		}
+		if len(snap.AddedPoolIDs) > 0 {
+			if _, err := tx.q().ExecContext(ctx,
+				`DELETE FROM talent_pool_members WHERE candidate_id = $1 AND pool_id = ANY($2)`, m.PrimaryCandidateID, snap.AddedPoolIDs,
+			); err != nil {
+				return fmt.Errorf("remove copied pool memberships: %w", err)
+			}
+		}
+		if err := tx.setMergeFields(ctx, m.PrimaryCandidateID, snap.Primary); err != nil {
+			return err
+		}
+
+		if _, err := tx.q().ExecContext(ctx,
+			`UPDATE candidates SET deleted_at = NULL WHERE id = $1`, m.MergedCandidateID,
+		); err != nil {
+			return fmt.Errorf("restore merged candidate: %w", err)
+		}
+		if _, err := tx.q().ExecContext(ctx,
+			`UPDATE graph_nodes SET deleted_at = NULL WHERE id = $1`, mergedNode,
+		); err != nil {
+			return fmt.Errorf("restore merged node: %w", err)
+		}
+		if err := tx.SyncCandidateTextFields(ctx, m.PrimaryCandidateID, primaryNode); err != nil {
+			return fmt.Errorf("sync primary text fields: %w", err)
+		}
+		if err := tx.SyncCandidateTextFields(ctx, m.MergedCandidateID, mergedNode); err != nil {
+			return fmt.Errorf("sync merged text fields: %w", err)
+		}
+
+		var undoneAt time.Time
+		if err := tx.q().QueryRowContext(ctx, `
+			UPDATE candidate_merges SET undone_at = NOW(), undone_by = NULLIF($2, '')
+			WHERE id = $1
+			RETURNING undone_at
+		`, mergeID, actor).Scan(&undoneAt); err != nil {
+			return fmt.Errorf("mark merge undone: %w", err)
+		}
+		m.UndoneAt = &undoneAt
+		m.UndoneBy = actor
+		res = m
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ListCandidateMerges returns the merges a candidate took part in, on either
+// side, newest first.
+func (db *DB) ListCandidateMerges(ctx context.Context, candidateID int) ([]CandidateMerge, error) {
+	rows, err := db.r().QueryContext(ctx, `
+		SELECT `+candidateMergeColumns+`
+		FROM candidate_merges
+		WHERE (primary_candidate_id = $1 OR merged_candidate_id = $1)
+		  AND primary_candidate_id IN (SELECT id FROM candidates WHERE org_id = $2)
+		ORDER BY id DESC
+	`, candidateID, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("list candidate merges: %w", err)
+	}
+	defer rows.Close()
+
+	merges := []CandidateMerge{}
+	for rows.Next() {
+		m, _, err := scanCandidateMerge(rows)
+		if err != nil {
+			return nil, err
+		}
+		merges = append(merges, *m)
+	}
+	return merges, rows.Err()
+}
+
+const candidateMergeColumns = `id, primary_candidate_id, merged_candidate_id, primary_node_id, merged_node_id,
+		snapshot, COALESCE(actor, ''), created_at, undone_at, COALESCE(undone_by, '')`
+
+func (db *DB) lockCandidateMerge(ctx context.Context, mergeID int64) (*CandidateMerge, *mergeSnapshot, error) {
+	m, snap, err := scanCandidateMerge(db.q().QueryRowContext(ctx,
+		`SELECT `+candidateMergeColumns+` FROM candidate_merges
+		 WHERE id = $1 AND primary_candidate_id IN (SELECT id FROM candidates WHERE org_id = $2)
+		 FOR UPDATE`, mergeID, tenant.OrgID(ctx)))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, nil
+	}
+	return m, snap, err
+}
+
+func scanCandidateMerge(row interface{ Scan(...interface{}) error }) (*CandidateMerge, *mergeSnapshot, error) {
+	var m CandidateMerge
+	var primaryNode, mergedNode sql.NullInt64
+	var snapJSON []byte
+	var undoneAt sql.NullTime
+	if err := row.Scan(&m.ID, &m.PrimaryCandidateID, &m.MergedCandidateID, &primaryNode, &mergedNode,
+		&snapJSON, &m.Actor, &m.CreatedAt, &undoneAt, &m.UndoneBy); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, err
+		}
+		return nil, nil, fmt.Errorf("scan candidate merge: %w", err)
+	}
+	if primaryNode.Valid {
+		id := int(primaryNode.Int64)
+		m.PrimaryNodeID = &id
+	}
+	if mergedNode.Valid {
+		id := int(mergedNode.Int64)
+		m.MergedNodeID = &id
+	}
+	if undoneAt.Valid {
+		m.UndoneAt = &undoneAt.Time
+	}
+	var snap mergeSnapshot
+	if err := json.Unmarshal(snapJSON, &snap); err != nil {
+		return nil, nil, fmt.Errorf("decode merge %d snapshot: %w", m.ID, err)
+	}
+	m.applySnapshot(snap)
+	return &m, &snap, nil
+}
+
+func (m *CandidateMerge) applySnapshot(snap mergeSnapshot) {
+	m.NewestWins = "primary"
+	if snap.MergedWasNewer {
+		m.NewestWins = "merged"
+	}
+	m.EdgesMoved = len(snap.MovedEdgeIDs)
+	m.EdgesShared = snap.SharedEdges
+	m.CVFilesMoved = len(snap.CVFileIDs)
+	m.InterviewsMoved = len(snap.InterviewIDs)
+	m.NotesMoved = len(snap.NoteIDs)
+	m.TagsAdded = len(snap.AddedTags)
+	m.PoolsAdded = len(snap.AddedPoolIDs)
+}
+
+// lockMergeSides locks both candidate rows (in ID order, so concurrent
+// merges of the same pair can't deadlock) and returns [primary, merged], or
+// nil if either is missing.
+func (db *DB) lockMergeSides(ctx context.Context, primaryID, mergedID int) ([]mergeSide, error) {
+	rows, err := db.q().QueryContext(ctx, `
+		SELECT id, graph_node_id, deleted_at IS NOT NULL, name,
+		       COALESCE(email, ''), COALESCE(phone, ''), COALESCE(location, ''),
+		       COALESCE(linkedin_url, ''), COALESCE(education, ''), COALESCE(summary, ''),
+		       COALESCE(updated_at, created_at, NOW())
+		FROM candidates
+		WHERE id = ANY($1) AND org_id = $2
+		ORDER BY id
+		FOR UPDATE
+	`, []int{primaryID, mergedID}, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("lock candidates: %w", err)
+	}
+	byID := make(map[int]*mergeSide, 2)
+	for rows.Next() {
+		var s mergeSide
+		f := &s.fields
+		if err := rows.Scan(&s.id, &s.nodeID, &s.deleted, &f.Name, &f.Email, &f.Phone, &f.Location,
+			&f.LinkedInURL, &f.Education, &f.Summary, &s.updatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan candidate: %w", err)
+		}
+		byID[s.id] = &s
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("lock candidates: %w", err)
+	}
+	if len(byID) < 2 {
+		return nil, nil
+	}
+
+	rows, err = db.q().QueryContext(ctx, `
+		SELECT candidate_id, MAX(uploaded_at)
+		FROM cv_files
+		WHERE candidate_id = ANY($1)
+		GROUP BY candidate_id
+	`, []int{primaryID, mergedID})
+	if err != nil {
+		return nil, fmt.Errorf("latest cv uploads: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		var latest sql.NullTime
+		if err := rows.Scan(&id, &latest); err != nil {
+			return nil, fmt.Errorf("scan latest cv upload: %w", err)
+		}
+		if s, ok := byID[id]; ok {
+			s.latestCV = latest
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("latest cv uploads: %w", err)
+	}
+	return []mergeSide{*byID[primaryID], *byID[mergedID]}, nil
+}
+
+// mergeEdges moves the merged node's outgoing edges to the primary node.
+// An edge the primary already has (same type and target) stays behind on the
+// soft-deleted duplicate; when the duplicate is newer its non-empty edge
+// properties are copied onto the primary's edge first.
+func (db *DB) mergeEdges(ctx context.Context, primaryNode, mergedNode int, snap *mergeSnapshot) error {
+	type edge struct {
+		id     int
+		key    string
+		props  []byte
+		target int
+	}
+	load := func(nodeID int) ([]edge, error) {
+		rows, err := db.q().QueryContext(ctx, `
+			SELECT id, edge_type, target_node_id, COALESCE(properties, '{}'::jsonb)
+			FROM graph_edges
+			WHERE source_node_id = $1
+			FOR UPDATE
+		`, nodeID)
+		if err != nil {
+			return nil, fmt.Errorf("load edges of node %d: %w", nodeID, err)
+		}
+		defer rows.Close()
+		var edges []edge
+		for rows.Next() {
+			var e edge
+			var edgeType string
+			if err := rows.Scan(&e.id, &edgeType, &e.target, &e.props); err != nil {
+				return nil, fmt.Errorf("scan edge: %w", err)
+			}
+			e.key = fmt.Sprintf("%s:%d", edgeType, e.target)
+			edges = append(edges, e)
+		}
+		return edges, rows.Err()
+	}
+
+	primaryEdges, err := load(primaryNode)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]edge, len(primaryEdges))
+	for _, e := range primaryEdges {
+		existing[e.key] = e
+	}
+	mergedEdges, err := load(mergedNode)
+	if err != nil {
+		return err
+	}
+
+	snap.MovedEdgeIDs = []int{}
+	snap.PatchedEdges = []mergeEdgePatch{}
+	for _, e := range mergedEdges {
+		if e.target == primaryNode {
+			continue // an edge between the two duplicates would become a self-loop
+		}
+		same, ok := existing[e.key]
+		if !ok {
+			snap.MovedEdgeIDs = append(snap.MovedEdgeIDs, e.id)
+			continue
+		}
+		snap.SharedEdges++
+		if !snap.MergedWasNewer {
+			continue
+		}
+		props, err := overlayProperties(same.props, e.props)
+		if err != nil {
+			return fmt.Errorf("merge edge %d properties: %w", same.id, err)
+		}
+		if _, err := db.q().ExecContext(ctx,
+			`UPDATE graph_edges SET properties = $2 WHERE id = $1`, same.id, props,
+		); err != nil {
+			return fmt.Errorf("update edge %d: %w", same.id, err)
+		}
+		snap.PatchedEdges = append(snap.PatchedEdges, mergeEdgePatch{ID: same.id, Properties: same.props})
+	}
+	if len(snap.MovedEdgeIDs) > 0 {
+		if _, err := db.q().ExecContext(ctx,
+			`UPDATE graph_edges SET source_node_id = $2 WHERE id = ANY($1)`, snap.MovedEdgeIDs, primaryNode,
+		); err != nil {
+			return fmt.Errorf("move edges: %w", err)
+		}
+	}
+	return nil
+}
+
+// copyCandidateTags gives toID every tag of fromID it doesn't have yet and
+// returns the tags added.
+func (db *DB) copyCandidateTags(ctx context.Context, fromID, toID int) ([]string, error) {
+	rows, err := db.q().QueryContext(ctx, `
+		INSERT INTO candidate_tags (candidate_id, tag, created_by, created_at)
+		SELECT $2, tag, created_by, created_at FROM candidate_tags WHERE candidate_id = $1
+		ON CONFLICT (candidate_id, tag) DO NOTHING
+		RETURNING tag
+	`, fromID, toID)
+	if err != nil {
+		return nil, fmt.Errorf("copy candidate tags: %w", err)
+	}
+	defer rows.Close()
+	added := []string{}
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, fmt.Errorf("copy candidate tags: %w", err)
+		}
+		added = append(added, tag)
+	}
+	return added, rows.Err()
+}
+
+// moveCandidateRows repoints every row of table (cv_files, interviews or
+// candidate_notes) from one candidate to another and returns the IDs moved.
+func (db *DB) moveCandidateRows(ctx context.Context, table string, fromID, toID int) ([]int, error) {
+	return db.repointCandidateRows(ctx, table, fmt.Sprintf(
+		`UPDATE %s SET candidate_id = $2 WHERE candidate_id = $1 RETURNING id`, table), fromID, toID)
+}
+
+// restoreCandidateRows moves back only the given rows of table.
+func (db *DB) restoreCandidateRows(ctx context.Context, table string, fromID, toID int, ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := db.repointCandidateRows(ctx, table, fmt.Sprintf(
+		`UPDATE %s SET candidate_id = $2 WHERE candidate_id = $1 AND id = ANY($3) RETURNING id`, table), fromID, toID, ids)
+	return err
+}
+
+func (db *DB) repointCandidateRows(ctx context.Context, table, query string, args ...interface{}) ([]int, error) {
+	rows, err := db.q().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("move %s: %w", table, err)
+	}
+	defer rows.Close()
+	moved := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("move %s: %w", table, err)
+		}
+		moved = append(moved, id)
+	}
+	return moved, rows.Err()
+}
+
+func (db *DB) setMergeFields(ctx context.Context, candidateID int, f mergeCandidateFields) error {
+	_, err := db.q().ExecContext(ctx, `
+		UPDATE candidates
+		SET name = $2, email = NULLIF($3, ''), phone = NULLIF($4, ''), location = NULLIF($5, ''),
+		    linkedin_url = NULLIF($6, ''), education = NULLIF($7, ''), summary = NULLIF($8, '')
+		WHERE id = $1
+	`, candidateID, f.Name, f.Email, f.Phone, f.Location, f.LinkedInURL, f.Education, f.Summary)
+	if err != nil {
+		return fmt.Errorf("update candidate %d: %w", candidateID, err)
+	}
+	return nil
+}
+
+// mergeFields picks each field from the newer side unless it's empty there.
+func mergeFields(primary, merged mergeCandidateFields, mergedNewer bool) mergeCandidateFields {
+	older, newer := merged, primary
+	if mergedNewer {
+		older, newer = primary, merged
+	}
+	pick := func(n, o string) string {
+		if n != "" {
+			return n
+		}
+		return o
+	}
+	return mergeCandidateFields{
+		Name:        pick(newer.Name, older.Name),
+		Email:       pick(newer.Email, older.Email),
+		Phone:       pick(newer.Phone, older.Phone),
+		Location:    pick(newer.Location, older.Location),
+		LinkedInURL: pick(newer.LinkedInURL, older.LinkedInURL),
+		Education:   pick(newer.Education, older.Education),
+		Summary:     pick(newer.Summary, older.Summary),
+	}
+}
+
+// overlayProperties returns older with newer's keys on top, skipping null and
+// empty-string values in newer so a blank field doesn't erase a known one.
+func overlayProperties(older, newer []byte) ([]byte, error) {
+	out := map[string]interface{}{}
+	if len(older) > 0 {
+		if err := json.Unmarshal(older, &out); err != nil {
+			return nil, err
+		}
+		if out == nil {
+			out = map[string]interface{}{}
+		}
+	}
+	var top map[string]interface{}
+	if len(newer) > 0 {
+		if err := json.Unmarshal(newer, &top); err != nil {
+			return nil, err
+		}
+	}
+	for k, v := range top {
+		if v == nil || v == "" {
+			continue
+		}
+		out[k] = v
+	}
+	return json.Marshal(out)
+}
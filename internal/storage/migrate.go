@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/pressly/goose/v3"
+
+	"cv-search/migrations"
+)
+
+// ─── Schema migrations ───────────────────────────────────────────────────────
+
+func (db *DB) migrationProvider() (*goose.Provider, error) {
+	provider, err := goose.NewProvider(goose.DialectPostgres, db.connection, migrations.FS)
+	if err != nil {
+		return nil, fmt.Errorf("init migrations: %w", err)
+	}
+	return provider, nil
+}
+
+// Migrate applies every pending embedded migration (see migrations/).
+// Applied versions are tracked in goose_db_version.
+func (db *DB) Migrate(ctx context.Context) error {
+	provider, err := db.migrationProvider()
+	if err != nil {
+		return err
+	}
+	results, err := provider.Up(ctx)
+	if err != nil {
+		return fmt.Errorf("apply migrations: %w", err)
+	}
+	for _, r := range results {
+		log.Printf("[Migrate] Applied %s (%s)", r.Source.Path, r.Duration)
+	}
+	if len(results) == 0 {
+		log.Printf("[Migrate] Schema up to date")
+	}
+	return nil
+}
+
+// MigrationStatus describes one embedded migration and whether it is applied.
+type MigrationStatus struct {
+	Version int64
+	Path    string
+	Applied bool
+}
+
+// MigrationStatuses lists every embedded migration with its applied state.
+func (db *DB) MigrationStatuses(ctx context.Context) ([]MigrationStatus, error) {
+	provider, err := db.migrationProvider()
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := provider.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("migration status: %w", err)
+	}
+	out := make([]MigrationStatus, 0, len(statuses))
+	for _, s := range statuses {
+		out = append(out, MigrationStatus{
+			Version: s.Source.Version,
+			Path:    s.Source.Path,
+			Applied: s.State == goose.StateApplied,
+		})
+	}
+	return out, nil
+}
+
+// SchemaVersion returns the highest applied migration version, 0 on an
+// empty database.
+func (db *DB) SchemaVersion(ctx context.Context) (int64, error) {
+	statuses, err := db.MigrationStatuses(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var version int64
+	for _, s := range statuses {
+		if s.Applied && s.Version > version {
+			version = s.Version
+		}
+	}
+	return version, nil
+}
+
+// MigrateDownTo rolls back applied migrations down to (but not including)
+// version. The baseline (00001) has no Down section, so it can't be undone.
+func (db *DB) MigrateDownTo(ctx context.Context, version int64) error {
+	provider, err := db.migrationProvider()
+	if err != nil {
+		return err
+	}
+	results, err := provider.DownTo(ctx, version)
+	if err != nil {
+		return fmt.Errorf("roll back migrations: %w", err)
+	}
+	for _, r := range results {
+		log.Printf("[Migrate] Rolled back %s (%s)", r.Source.Path, r.Duration)
+	}
+	return nil
+}
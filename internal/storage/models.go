@@ -1,6 +1,9 @@
 package storage
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // Candidate represents a scraped/stored candidate.
 // Note: Keep this minimal for DB persistence; enrich elsewhere if needed.
@@ -30,6 +33,73 @@ type Interview struct {
 	UpdatedAt       time.Time `json:"updated_at"`
 }
 
+// CandidateNote is a free-text recruiter note on a candidate.
+type CandidateNote struct {
+	ID          int       `json:"id"`
+	CandidateID int       `json:"candidate_id"`
+	Body        string    `json:"body"`
+	Author      string    `json:"author,omitempty"` // API actor that wrote it
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CandidateTag is one tag on a candidate ("shortlisted-q3", "do-not-contact").
+type CandidateTag struct {
+	Tag       string    `json:"tag"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CandidateStatusChange is one change of a candidate's status.
+type CandidateStatusChange struct {
+	ID          int64     `json:"id"`
+	CandidateID int       `json:"candidate_id"`
+	FromStatus  string    `json:"from_status"`
+	ToStatus    string    `json:"to_status"`
+	Reason      string    `json:"reason,omitempty"`
+	ChangedBy   string    `json:"changed_by,omitempty"` // API actor
+	ChangedAt   time.Time `json:"changed_at"`
+}
+
+// ShareLink is a read-only link to a candidate's profile for someone without
+// an account. Its URL isn't stored: it is signed from ID and ExpiresAt.
+type ShareLink struct {
+	ID           int64      `json:"id"`
+	OrgID        int        `json:"-"`
+	CandidateID  int        `json:"candidate_id"`
+	Anonymized   bool       `json:"anonymized"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	CreatedBy    string     `json:"created_by,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	Views        int        `json:"views"`
+	LastViewedAt *time.Time `json:"last_viewed_at,omitempty"`
+}
+
+// TalentPool is a named shortlist of candidates, each at a pipeline stage.
+type TalentPool struct {
+	ID          int            `json:"id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	CreatedBy   string         `json:"created_by,omitempty"`
+	Size        int            `json:"size"`
+	Stages      map[string]int `json:"stages"` // member count per stage that has members
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// TalentPoolMember is a candidate in a talent pool.
+type TalentPoolMember struct {
+	CandidateID     int       `json:"candidate_id"`
+	Name            string    `json:"name"`
+	CurrentPosition string    `json:"current_position,omitempty"` // from graph_nodes.properties
+	Seniority       string    `json:"seniority,omitempty"`
+	Stage           string    `json:"stage"` // sourced, screened, interviewed, offered, hired, rejected
+	AddedBy         string    `json:"added_by,omitempty"`
+	AddedAt         time.Time `json:"added_at"`
+	StageChangedAt  time.Time `json:"stage_changed_at"`
+}
+
 // InterviewSummary is a lightweight view of an interview for embedding in search results.
 // Does not include raw notes to keep search responses lean.
 type InterviewSummary struct {
@@ -43,27 +113,110 @@ type InterviewSummary struct {
 
 // CandidateDetail is a full candidate profile including all interviews.
 type CandidateDetail struct {
-	ID              int         `json:"id"`
-	Name            string      `json:"name"`
-	Email           string      `json:"email,omitempty"`
-	Phone           string      `json:"phone,omitempty"`
-	Location        string      `json:"location,omitempty"`
-	GraphNodeID     *int        `json:"graph_node_id,omitempty"`
-	CurrentPosition string      `json:"current_position,omitempty"` // from graph_nodes.properties
-	Seniority       string      `json:"seniority,omitempty"`        // from graph_nodes.properties
-	Interviews      []Interview `json:"interviews"`
-	CreatedAt       time.Time   `json:"created_at"`
+	ID              int                  `json:"id"`
+	Name            string               `json:"name"`
+	Email           string               `json:"email,omitempty"`
+	Phone           string               `json:"phone,omitempty"`
+	LinkedInURL     string               `json:"linkedin_url,omitempty"`
+	Location        string               `json:"location,omitempty"`
+	Status          string               `json:"status"` // active | hired | archived | do_not_contact
+	StatusChangedAt *time.Time           `json:"status_changed_at,omitempty"`
+	GraphNodeID     *int                 `json:"graph_node_id,omitempty"`
+	CurrentPosition string               `json:"current_position,omitempty"` // from graph_nodes.properties
+	Seniority       string               `json:"seniority,omitempty"`        // from graph_nodes.properties
+	Skills          []CandidateSkill     `json:"skills,omitempty"`
+	Interviews      []Interview          `json:"interviews"`
+	Tags            []string             `json:"tags"`
+	Completeness    *ProfileCompleteness `json:"completeness,omitempty"`
+	CreatedAt       time.Time            `json:"created_at"`
+}
+
+// ProfileCompleteness is how complete a candidate's extracted profile is.
+type ProfileCompleteness struct {
+	Score   float64  `json:"score"`             // share of the checks passed, 0-1
+	Missing []string `json:"missing,omitempty"` // current_position | education | skill_years | location
+}
+
+// CandidateSkill is one candidate_skills row joined with its skill name.
+type CandidateSkill struct {
+	Name        string   `json:"name"`
+	Proficiency string   `json:"proficiency,omitempty"`
+	Years       *float64 `json:"years,omitempty"`
+}
+
+// ErasureResult reports what EraseCandidate removed.
+type ErasureResult struct {
+	CandidateID    int      `json:"candidate_id"`
+	CVFilesDeleted int      `json:"cv_files_deleted"`
+	FilePaths      []string `json:"-"` // blob keys of the stored CV files and photos, for the caller to remove
+	GraphNodeID    *int     `json:"graph_node_id,omitempty"`
+	GraphNode      string   `json:"graph_node,omitempty"` // deleted | anonymized
+}
+
+// CandidateMerge is one candidate_merges row: a duplicate candidate folded
+// into a primary one.
+type CandidateMerge struct {
+	ID                 int64      `json:"id"`
+	PrimaryCandidateID int        `json:"primary_candidate_id"`
+	MergedCandidateID  int        `json:"merged_candidate_id"`
+	PrimaryNodeID      *int       `json:"primary_node_id,omitempty"`
+	MergedNodeID       *int       `json:"merged_node_id,omitempty"`
+	NewestWins         string     `json:"newest_wins"` // primary | merged: whose properties took precedence
+	EdgesMoved         int        `json:"edges_moved"`
+	EdgesShared        int        `json:"edges_shared"` // edges both had; kept once on the primary
+	CVFilesMoved       int        `json:"cv_files_moved"`
+	InterviewsMoved    int        `json:"interviews_moved"`
+	NotesMoved         int        `json:"notes_moved"`
+	TagsAdded          int        `json:"tags_added"`  // the duplicate's tags the primary didn't have
+	PoolsAdded         int        `json:"pools_added"` // the duplicate's talent pools the primary wasn't in
+	Actor              string     `json:"actor,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UndoneAt           *time.Time `json:"undone_at,omitempty"`
+	UndoneBy           string     `json:"undone_by,omitempty"`
+}
+
+// CandidateImport is one candidate from another ATS's export (see
+// internal/importer). Empty fields are left alone when an existing candidate
+// is updated.
+type CandidateImport struct {
+	Source     string   `json:"source,omitempty"`      // candidates.import_source, e.g. "greenhouse"
+	ExternalID string   `json:"external_id,omitempty"` // the exporting system's candidate ID
+	Name       string   `json:"name"`
+	Email      string   `json:"email,omitempty"`
+	Phone      string   `json:"phone,omitempty"`
+	Location   string   `json:"location,omitempty"`
+	Experience string   `json:"experience,omitempty"`
+	Skills     []string `json:"skills,omitempty"`
+	ResumeURL  string   `json:"resume_url,omitempty"`
+}
+
+// PendingResume is a candidate whose resume_url still has to be downloaded.
+type PendingResume struct {
+	CandidateID int
+	OrgID       int
+	URL         string
+	Attempts    int // failed attempts so far
+}
+
+// CandidateContact is the contact details extracted from a CV. Empty
+// fields never overwrite what a candidate already has.
+type CandidateContact struct {
+	Email       string
+	Phone       string
+	LinkedInURL string
 }
 
 // CandidateListItem is a lightweight row for the candidate list endpoint.
 type CandidateListItem struct {
-	ID              int       `json:"id"`
-	Name            string    `json:"name"`
-	CurrentPosition string    `json:"current_position,omitempty"` // from graph_nodes.properties
-	Seniority       string    `json:"seniority,omitempty"`
-	InterviewCount  int       `json:"interview_count"`
-	LatestOutcome   string    `json:"latest_outcome,omitempty"`
-	CreatedAt       time.Time `json:"created_at"`
+	ID              int                  `json:"id"`
+	Name            string               `json:"name"`
+	CurrentPosition string               `json:"current_position,omitempty"` // from graph_nodes.properties
+	Seniority       string               `json:"seniority,omitempty"`
+	Status          string               `json:"status"`
+	InterviewCount  int                  `json:"interview_count"`
+	LatestOutcome   string               `json:"latest_outcome,omitempty"`
+	Completeness    *ProfileCompleteness `json:"completeness,omitempty"`
+	CreatedAt       time.Time            `json:"created_at"`
 }
 
 // SuggestionResult is a single autocomplete suggestion for the search box.
@@ -76,10 +229,35 @@ type SuggestionResult struct {
 type SimilarCandidate struct {
 	CandidateID     int      `json:"candidate_id"`
 	Name            string   `json:"name"`
+	PersonID        string   `json:"person_id"`
 	CurrentPosition string   `json:"current_position,omitempty"`
 	Seniority       string   `json:"seniority,omitempty"`
+	Status          string   `json:"status"`
 	TopSkills       []string `json:"top_skills,omitempty"`
-	Similarity      float64  `json:"similarity"`
+	Similarity      float64  `json:"similarity"`    // embedding cosine similarity (0 when found via graph only)
+	GraphOverlap    float64  `json:"graph_overlap"` // 0-1 share of the source's skills/companies/communities
+	Score           float64  `json:"score"`         // combined ranking score
+
+	SharedSkills      []string `json:"shared_skills,omitempty"`
+	SharedCompanies   []string `json:"shared_companies,omitempty"`
+	SharedCommunities []string `json:"shared_communities,omitempty"`
+}
+
+// GraphOverlap lists the graph neighbours one person shares with another.
+type GraphOverlap struct {
+	SharedSkills      []string
+	SharedCompanies   []string
+	SharedCommunities []string
+}
+
+// GraphOverlapResult is the source person's neighbourhood size (for
+// normalisation) plus the peers that share the most of it, keyed by
+// person node_id.
+type GraphOverlapResult struct {
+	SourceSkills      int
+	SourceCompanies   int
+	SourceCommunities int
+	Peers             map[string]*GraphOverlap
 }
 
 // Criteria used to search for candidates.
@@ -93,9 +271,53 @@ type Criteria struct {
 type CVFileInfo struct {
 	ID          int64
 	Filename    string
+	FilePath    string // BlobStore object key
+	FileType    string
 	FileSize    int64
 	UploadedAt  time.Time
 	CandidateID *int
+	OCRUsed     bool     // parsed text came from OCR (scanned document)
+	Anonymized  bool     // PII masked in parsed text and extraction (blind screening)
+	PhotoKey    string   // BlobStore key of the embedded photo, "" if none kept
+	Source      string   // where the CV came from, as given at upload
+	UploadTags  []string // candidate tags given at upload
+}
+
+// CVFileListItem is one uploaded CV in GET /api/cv.
+type CVFileListItem struct {
+	ID            int64     `json:"id"`
+	Filename      string    `json:"filename"`
+	FileType      string    `json:"file_type,omitempty"`
+	FileSize      int64     `json:"file_size"`
+	UploadedAt    time.Time `json:"uploaded_at"`
+	CandidateID   *int      `json:"candidate_id,omitempty"`
+	OCRUsed       bool      `json:"ocr_used"`
+	Anonymized    bool      `json:"anonymized"`
+	HasPhoto      bool      `json:"has_photo"`
+	QualityStatus string    `json:"quality_status"`          // pending, ok, needs_review
+	QualityScore  *float64  `json:"quality_score,omitempty"` // nil until scored
+	QualityIssues []string  `json:"quality_issues"`
+	Source        string    `json:"source,omitempty"`
+	UploadTags    []string  `json:"upload_tags"`
+}
+
+// CVChunk is one chunk of a CV's text (cv_chunks).
+type CVChunk struct {
+	Index  int
+	Text   string
+	Tokens int
+}
+
+// PreviousCVProfile is what a candidate's previous CV said, for change
+// detection on re-upload (see cv.DiffProfiles).
+type PreviousCVProfile struct {
+	CVFileID       int64
+	Seniority      string
+	Position       string
+	Skills         []string
+	Companies      []string
+	Certifications []string
+	Languages      []string
 }
 
 // CVUploadJob represents an async CV processing job
@@ -111,3 +333,443 @@ type CVUploadJob struct {
 	RetryCount   int
 	MaxRetries   int
 }
+
+// JobQueueStatus summarizes the CV processing backlog (cv_upload_jobs by
+// status and the Groq batches still running).
+type JobQueueStatus struct {
+	ByStatus      map[string]int `json:"by_status"`
+	OldestPending *time.Time     `json:"oldest_pending,omitempty"` // created_at of the oldest pending job
+	OpenBatches   int            `json:"open_batches"`             // llm_batch_jobs not yet completed/failed/expired/cancelled
+}
+
+// SearchExperiment is a named hybrid search configuration. Config is kept as
+// raw JSON here; the search layer decodes it (see graphrag.ExperimentConfig).
+type SearchExperiment struct {
+	ID          int             `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Config      json.RawMessage `json:"config"`
+	IsDefault   bool            `json:"is_default"`
+	Active      bool            `json:"active"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// SearchExperimentLog records which configuration served one search.
+type SearchExperimentLog struct {
+	SearchID       string // returned to the client, for feedback on the results
+	ExperimentName string // empty = built-in defaults
+	Query          string
+	Config         interface{} // effective config, marshalled to JSONB
+	ResultIDs      []int
+	Results        interface{} // per-result scores and features as served, marshalled to JSONB
+	DurationMS     int
+}
+
+// SearchLatency is how long an organization's recent hybrid searches took.
+type SearchLatency struct {
+	Searches int   `json:"searches"` // how many it is of
+	P50MS    int64 `json:"p50_ms"`
+	P90MS    int64 `json:"p90_ms"`
+}
+
+// SearchFeedback is a recruiter's verdict on one result of a logged search.
+type SearchFeedback struct {
+	ID            int64           `json:"id"`
+	SearchID      string          `json:"search_id"`
+	CandidateID   int             `json:"candidate_id"`
+	Label         string          `json:"label"`                    // good | bad | hired
+	ScoreOverride *float64        `json:"score_override,omitempty"` // 0-100, the LLM score's scale
+	Comment       string          `json:"comment,omitempty"`
+	Features      json.RawMessage `json:"features,omitempty"` // the result as served: rank, scores, features
+	CreatedBy     string          `json:"created_by,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+// SearchFeedbackExport is one feedback row with the search it was given on,
+// a training example for ranking weights and prompts.
+type SearchFeedbackExport struct {
+	SearchFeedback
+	Query       string          `json:"query"`
+	Experiment  string          `json:"experiment,omitempty"`
+	Config      json.RawMessage `json:"config,omitempty"` // the search's effective config
+	ResultCount int             `json:"result_count"`
+	SearchedAt  time.Time       `json:"searched_at"`
+}
+
+// SearchSession is a conversational search: an ordered chain of turns where
+// each follow-up is interpreted relative to the previous turn's results.
+type SearchSession struct {
+	ID        string              `json:"id"`
+	Turns     []SearchSessionTurn `json:"turns"`
+	CreatedAt time.Time           `json:"created_at"`
+	UpdatedAt time.Time           `json:"updated_at"`
+}
+
+// SearchSessionTurn is one query within a session. Results holds the served
+// candidate list as JSON (the API response shape).
+type SearchSessionTurn struct {
+	Turn           int             `json:"turn"`
+	Query          string          `json:"query"`
+	Action         string          `json:"action"` // search | filter
+	EffectiveQuery string          `json:"effective_query,omitempty"`
+	Explanation    string          `json:"explanation,omitempty"`
+	Results        json.RawMessage `json:"results"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// AuditEntry is one row of the audit trail. Details is free-form JSON
+// describing the change (filename, flags, counts).
+type AuditEntry struct {
+	ID         int64           `json:"id"`
+	Actor      string          `json:"actor"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id,omitempty"`
+	Details    json.RawMessage `json:"details,omitempty"`
+	IP         string          `json:"ip,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// AuditFilter narrows ListAuditLog. Zero values mean "any".
+type AuditFilter struct {
+	Actor      string
+	Action     string
+	EntityType string
+	EntityID   string
+	Since      *time.Time
+	Until      *time.Time
+	Limit      int
+	Offset     int
+}
+
+// GraphStats are node and edge counts from the stats views.
+type GraphStats struct {
+	TotalNodes int            `json:"total_nodes"`
+	TotalEdges int            `json:"total_edges"`
+	NodeTypes  map[string]int `json:"node_types"`
+	EdgeTypes  map[string]int `json:"edge_types"`
+}
+
+// GraphNode is one graph_nodes row without its embedding.
+type GraphNode struct {
+	ID         int             `json:"id"`
+	NodeType   string          `json:"node_type"` // person, skill, company, ...
+	NodeID     string          `json:"node_id"`   // e.g. "person_12", "skill_go"
+	Properties json.RawMessage `json:"properties,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// GraphEdge is one graph_edges row.
+type GraphEdge struct {
+	ID           int             `json:"id"`
+	SourceNodeID int             `json:"source_node_id"`
+	TargetNodeID int             `json:"target_node_id"`
+	EdgeType     string          `json:"edge_type"` // has_skill, worked_at, ...
+	Properties   json.RawMessage `json:"properties,omitempty"`
+}
+
+// Community is one detected graph_communities row.
+type Community struct {
+	ID          int       `json:"id"`
+	Level       int       `json:"level"`
+	CommunityID string    `json:"community_id"`
+	Title       string    `json:"title,omitempty"`
+	Summary     string    `json:"summary,omitempty"`
+	NodeCount   int       `json:"node_count"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CommunityChange is an entry of the community detection changelog.
+type CommunityChange struct {
+	ID               int64     `json:"id"`
+	CommunityID      int       `json:"community_id"` // graph_communities.id
+	Key              string    `json:"key"`          // graph_communities.community_id
+	Level            int       `json:"level"`
+	Change           string    `json:"change"` // created | drifted | dissolved
+	Reasons          []string  `json:"reasons"`
+	Title            string    `json:"title,omitempty"`
+	PreviousTitle    string    `json:"previous_title,omitempty"`
+	Size             int       `json:"size"`
+	PreviousSize     int       `json:"previous_size"`
+	Churn            float64   `json:"churn"`
+	Cohesion         float64   `json:"cohesion"`
+	PreviousCohesion float64   `json:"previous_cohesion"`
+	Resummarized     bool      `json:"resummarized"`
+	DetectedAt       time.Time `json:"detected_at"`
+}
+
+// SkillCount is the number of live candidates holding a skill.
+type SkillCount struct {
+	Skill string `json:"skill"`
+	Count int    `json:"count"`
+}
+
+// SkillTrendPoint is the number of candidates with a skill who first
+// appeared in a given month.
+type SkillTrendPoint struct {
+	Month string `json:"month"` // YYYY-MM
+	Skill string `json:"skill"`
+	Count int    `json:"count"`
+}
+
+// TrendingSkill compares the people mentioning a skill in two consecutive
+// periods of equal length.
+type TrendingSkill struct {
+	Skill            string     `json:"skill"`
+	Mentions         int        `json:"mentions"`          // current period
+	PreviousMentions int        `json:"previous_mentions"` // period before
+	GrowthPercent    *float64   `json:"growth_percent"`    // nil when new
+	New              bool       `json:"new,omitempty"`     // no mentions in the period before
+	FirstSeen        *time.Time `json:"first_seen,omitempty"`
+	LastSeen         *time.Time `json:"last_seen,omitempty"`
+}
+
+// SeniorityCount is the number of live candidates at a seniority level.
+type SeniorityCount struct {
+	Seniority string `json:"seniority"`
+	Count     int    `json:"count"`
+}
+
+// CommunitySize is a detected community with its live person member count.
+type CommunitySize struct {
+	ID          int    `json:"id"`
+	Level       int    `json:"level"`
+	CommunityID string `json:"community_id"`
+	Title       string `json:"title,omitempty"`
+	Members     int    `json:"members"`
+}
+
+// WeeklyUploads counts CV uploads in the week starting on Week (Monday).
+type WeeklyUploads struct {
+	Week       string `json:"week"` // YYYY-MM-DD
+	Uploads    int    `json:"uploads"`
+	Candidates int    `json:"candidates"` // distinct candidates the files are linked to
+}
+
+// LLMUsage is one day's LLM requests and tokens for a provider and model.
+// CostUSD is nil when the model has no known price.
+type LLMUsage struct {
+	Provider         string   `json:"provider"`
+	Model            string   `json:"model"`
+	Requests         int      `json:"requests"`
+	PromptTokens     int64    `json:"prompt_tokens"`
+	CompletionTokens int64    `json:"completion_tokens"`
+	CostUSD          *float64 `json:"cost_usd"`
+}
+
+// FailedJob is a CV processing job that failed for good.
+type FailedJob struct {
+	JobID    int64      `json:"job_id"`
+	CVFileID int64      `json:"cv_file_id"`
+	Filename string     `json:"filename"`
+	Error    string     `json:"error"`
+	FailedAt *time.Time `json:"failed_at,omitempty"`
+}
+
+// AdminOverview is the system status for the admin dashboard. "Today" is
+// since midnight in the database's time zone; counts are live, not from the
+// stats views.
+type AdminOverview struct {
+	UploadsToday int            `json:"uploads_today"`
+	JobsByStatus map[string]int `json:"jobs_by_status"`
+	// Jobs that finished (completed or failed) today, and the failed share.
+	ExtractionsToday    int     `json:"extractions_today"`
+	ExtractionErrorRate float64 `json:"extraction_error_rate"`
+	Nodes               int     `json:"nodes"`
+	Edges               int     `json:"edges"`
+	Communities         int     `json:"communities"`
+	// Live graph nodes and CV chunks still without an embedding.
+	EmbeddingBacklogNodes  int `json:"embedding_backlog_nodes"`
+	EmbeddingBacklogChunks int `json:"embedding_backlog_chunks"`
+	// LLMSpendTodayUSD sums the priced rows of LLMUsageToday;
+	// LLMSpendIncomplete is set when some model had no price.
+	LLMSpendTodayUSD   float64     `json:"llm_spend_today_usd"`
+	LLMSpendIncomplete bool        `json:"llm_spend_incomplete,omitempty"`
+	LLMUsageToday      []LLMUsage  `json:"llm_usage_today"`
+	RecentFailures     []FailedJob `json:"recent_failures"`
+}
+
+// Organization is a recruiting team sharing the deployment. Its API key is
+// stored hashed; HasAPIKey reports whether one is set.
+type Organization struct {
+	ID        int       `json:"id"`
+	Slug      string    `json:"slug"`
+	Name      string    `json:"name"`
+	HasAPIKey bool      `json:"has_api_key"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OrgAISettings is an organization's own LLM and embedding provider. An
+// empty provider keeps the deployment's for that part. The API keys are
+// sealed (internal/secret) before they reach the database and never leave
+// the API in the clear.
+type OrgAISettings struct {
+	OrgID               int       `json:"org_id"`
+	LLMProvider         string    `json:"llm_provider"`
+	LLMModel            string    `json:"llm_model"`
+	LLMAPIKey           []byte    `json:"-"`
+	EmbeddingProvider   string    `json:"embedding_provider"`
+	EmbeddingModel      string    `json:"embedding_model"`
+	EmbeddingDimensions int       `json:"embedding_dimensions"`
+	EmbeddingAPIKey     []byte    `json:"-"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// OrgQuotas are an organization's own quotas. A nil limit keeps the
+// deployment's default (QUOTA_* env), 0 is unlimited.
+type OrgQuotas struct {
+	OrgID             int       `json:"org_id"`
+	UploadsPerDay     *int      `json:"uploads_per_day"`
+	SearchesPerDay    *int      `json:"searches_per_day"`
+	LLMTokensPerMonth *int64    `json:"llm_tokens_per_month"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// OrgUsage is what an organization used today and this month, with when
+// those periods end (the database's calendar).
+type OrgUsage struct {
+	UploadsToday       int
+	SearchesToday      int
+	LLMTokensThisMonth int64
+	DayEnds            time.Time
+	MonthEnds          time.Time
+}
+
+// IdempotencyRecord is a request's Idempotency-Key and, once it finished,
+// its stored response. Status is 0 while the request is in progress.
+type IdempotencyRecord struct {
+	Key         string
+	Method      string
+	Path        string
+	Fingerprint string // sha256 of method, URL and body
+	Status      int
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+}
+
+// OrgIntegration is an organization's credentials for one ATS
+// (internal/integrations). The API key is sealed like OrgAISettings' keys.
+type OrgIntegration struct {
+	OrgID     int       `json:"org_id"`
+	Target    string    `json:"target"` // greenhouse, lever
+	APIKey    []byte    `json:"-"`
+	UserID    string    `json:"user_id"`          // ATS user the writes are made as
+	JobID     string    `json:"job_id,omitempty"` // job / posting to file candidates under
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// OrgCommunityPattern is one of an organization's own keyword communities
+// (graphrag.CommunityPattern), by PatternID.
+type OrgCommunityPattern struct {
+	PatternID string    `json:"id"`
+	Name      string    `json:"name"`
+	KeySkills []string  `json:"key_skills"`
+	KeyTitles []string  `json:"key_titles"`
+	UpdatedBy string    `json:"updated_by,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NotificationPreferences are one user's email notification settings in an
+// organization. UserID is the X-User-ID the user's requests carry.
+type NotificationPreferences struct {
+	OrgID         int        `json:"org_id"`
+	UserID        string     `json:"user_id"`
+	Email         string     `json:"email"`
+	BatchComplete bool       `json:"batch_complete"` // report when a bulk upload has been processed
+	WeeklyDigest  bool       `json:"weekly_digest"`  // new candidates and trending skills, weekly
+	LastDigestAt  *time.Time `json:"last_digest_at,omitempty"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	OrgName       string     `json:"-"` // filled by ListDueDigests
+}
+
+// BatchNotification is a bulk upload whose report is to be emailed once its
+// jobs are done.
+type BatchNotification struct {
+	BatchID        string
+	OrgID          int
+	Email          string
+	Files          int
+	JobIDs         []int64
+	Duplicates     int
+	UploadFailures []BatchFileFailure // files refused at upload
+	Attempts       int
+	CreatedAt      time.Time
+}
+
+// BatchFileFailure is a file of a bulk upload that failed, and why.
+type BatchFileFailure struct {
+	Filename string `json:"filename"`
+	Reason   string `json:"reason"`
+}
+
+// BatchJobOutcome is the state of one job of a bulk upload.
+type BatchJobOutcome struct {
+	JobID    int64
+	Filename string
+	Status   string // pending, processing, batch_submitted, completed, failed
+	Error    string
+}
+
+// WeeklyDigest is what an organization's weekly digest reports.
+type WeeklyDigest struct {
+	NewCandidates  int
+	Newest         []WeeklyDigestCandidate
+	TrendingSkills []SkillCount
+}
+
+// WeeklyDigestCandidate is a new candidate listed in a digest.
+type WeeklyDigestCandidate struct {
+	Name            string
+	CurrentPosition string
+}
+
+// CandidatePush records a candidate created in an external ATS.
+type CandidatePush struct {
+	ID          int       `json:"id"`
+	CandidateID int       `json:"candidate_id"`
+	Target      string    `json:"target"`
+	ExternalID  string    `json:"external_id"`
+	ExternalURL string    `json:"url,omitempty"`
+	PushedBy    string    `json:"pushed_by,omitempty"`
+	PushedAt    time.Time `json:"pushed_at"`
+}
+
+// GraphAnalytics is an organization's last computed graph analytics.
+type GraphAnalytics struct {
+	ComputedAt      *time.Time      `json:"computed_at,omitempty"` // nil if never computed
+	DurationMs      int64           `json:"duration_ms"`
+	CentralPeople   []CentralPerson `json:"central_people"`    // by PageRank
+	FeederCompanies []CompanyAlumni `json:"feeder_companies"`  // by former employees
+	TopSkillPairs   []SkillPair     `json:"top_skill_pairs"`   // by people holding both
+	RareSkillCombos []SkillPair     `json:"rare_skill_combos"` // common skills seldom held together, by lift
+}
+
+// CentralPerson is a person node with its degree and PageRank.
+type CentralPerson struct {
+	NodeID      int     `json:"node_id"`
+	Name        string  `json:"name"`
+	CandidateID *int64  `json:"candidate_id,omitempty"`
+	Degree      int     `json:"degree"`   // distinct neighbours
+	PageRank    float64 `json:"pagerank"` // 1 for the average node
+}
+
+// CompanyAlumni counts a company's current and former employees.
+type CompanyAlumni struct {
+	Company          string `json:"company"`
+	CurrentEmployees int    `json:"current_employees"`
+	FormerEmployees  int    `json:"former_employees"`
+}
+
+// SkillPair is two skills held by the same people. Lift is 1 if holding one
+// said nothing about holding the other, below 1 if they are seldom combined.
+type SkillPair struct {
+	SkillA           string  `json:"skill_a"`
+	SkillB           string  `json:"skill_b"`
+	Candidates       int     `json:"candidates"` // people with both
+	SkillACandidates int     `json:"skill_a_candidates"`
+	SkillBCandidates int     `json:"skill_b_candidates"`
+	Lift             float64 `json:"lift"`
+}
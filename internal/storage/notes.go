@@ -0,0 +1,202 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"cv-search/internal/tenant"
+)
+
+// ─── Candidate notes ─────────────────────────────────────────────────────────
+
+// CandidateInOrg reports whether candidateID is a live candidate of ctx's
+// organization.
+func (db *DB) CandidateInOrg(ctx context.Context, candidateID int) (bool, error) {
+	var ok bool
+	err := db.q().QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM candidates WHERE id = $1 AND org_id = $2 AND deleted_at IS NULL)
+	`, candidateID, tenant.OrgID(ctx)).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("check candidate %d: %w", candidateID, err)
+	}
+	return ok, nil
+}
+
+// ListCandidateNotes returns a candidate's notes, newest first. Returns
+// sql.ErrNoRows if the candidate does not exist in ctx's organization.
+func (db *DB) ListCandidateNotes(ctx context.Context, candidateID int) ([]CandidateNote, error) {
+	ok, err := db.CandidateInOrg(ctx, candidateID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	rows, err := db.q().QueryContext(ctx, `
+		SELECT id, candidate_id, body, author, created_at, updated_at
+		FROM candidate_notes
+		WHERE candidate_id = $1
+		ORDER BY created_at DESC, id DESC
+	`, candidateID)
+	if err != nil {
+		return nil, fmt.Errorf("list candidate notes: %w", err)
+	}
+	defer rows.Close()
+
+	notes := []CandidateNote{}
+	for rows.Next() {
+		var n CandidateNote
+		if err := rows.Scan(&n.ID, &n.CandidateID, &n.Body, &n.Author, &n.CreatedAt, &n.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan candidate note: %w", err)
+		}
+		notes = append(notes, n)
+	}
+	return notes, rows.Err()
+}
+
+// CreateCandidateNote adds a note by author to a candidate. Returns
+// sql.ErrNoRows if the candidate does not exist in ctx's organization.
+func (db *DB) CreateCandidateNote(ctx context.Context, candidateID int, body, author string) (*CandidateNote, error) {
+	n := CandidateNote{CandidateID: candidateID, Body: body, Author: author}
+	err := db.q().QueryRowContext(ctx, `
+		INSERT INTO candidate_notes (candidate_id, body, author)
+		SELECT id, $2, $3
+		FROM candidates WHERE id = $1 AND org_id = $4 AND deleted_at IS NULL
+		RETURNING id, created_at, updated_at
+	`, candidateID, body, author, tenant.OrgID(ctx)).Scan(&n.ID, &n.CreatedAt, &n.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create candidate note: %w", err)
+	}
+	return &n, nil
+}
+
+// UpdateCandidateNote replaces a note's body. Returns sql.ErrNoRows if the
+// note doesn't exist or doesn't belong to candidateID.
+func (db *DB) UpdateCandidateNote(ctx context.Context, noteID, candidateID int, body string) (*CandidateNote, error) {
+	var n CandidateNote
+	err := db.q().QueryRowContext(ctx, `
+		UPDATE candidate_notes SET body = $3, updated_at = NOW()
+		WHERE id = $1 AND candidate_id = $2
+		  AND candidate_id IN (SELECT id FROM candidates WHERE org_id = $4)
+		RETURNING id, candidate_id, body, author, created_at, updated_at
+	`, noteID, candidateID, body, tenant.OrgID(ctx),
+	).Scan(&n.ID, &n.CandidateID, &n.Body, &n.Author, &n.CreatedAt, &n.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("update candidate note: %w", err)
+	}
+	return &n, nil
+}
+
+// DeleteCandidateNote removes a note. Enforces candidateID ownership.
+func (db *DB) DeleteCandidateNote(ctx context.Context, noteID, candidateID int) error {
+	res, err := db.q().ExecContext(ctx, `
+		DELETE FROM candidate_notes
+		WHERE id = $1 AND candidate_id = $2
+		  AND candidate_id IN (SELECT id FROM candidates WHERE org_id = $3)
+	`, noteID, candidateID, tenant.OrgID(ctx))
+	if err != nil {
+		return fmt.Errorf("delete candidate note: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ─── Candidate tags ──────────────────────────────────────────────────────────
+
+// ListCandidateTags returns a candidate's tags in name order. Returns
+// sql.ErrNoRows if the candidate does not exist in ctx's organization.
+func (db *DB) ListCandidateTags(ctx context.Context, candidateID int) ([]CandidateTag, error) {
+	ok, err := db.CandidateInOrg(ctx, candidateID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	rows, err := db.q().QueryContext(ctx, `
+		SELECT tag, created_by, created_at FROM candidate_tags WHERE candidate_id = $1 ORDER BY tag
+	`, candidateID)
+	if err != nil {
+		return nil, fmt.Errorf("list candidate tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []CandidateTag{}
+	for rows.Next() {
+		var t CandidateTag
+		if err := rows.Scan(&t.Tag, &t.CreatedBy, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan candidate tag: %w", err)
+		}
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
+
+// AddCandidateTags tags a candidate; tags it already has are left as they
+// are. tags must already be normalized. Returns sql.ErrNoRows if the
+// candidate does not exist in ctx's organization.
+func (db *DB) AddCandidateTags(ctx context.Context, candidateID int, tags []string, actor string) error {
+	ok, err := db.CandidateInOrg(ctx, candidateID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return sql.ErrNoRows
+	}
+	if _, err := db.q().ExecContext(ctx, `
+		INSERT INTO candidate_tags (candidate_id, tag, created_by)
+		SELECT $1, t, $3 FROM unnest($2::text[]) AS t
+		ON CONFLICT (candidate_id, tag) DO NOTHING
+	`, candidateID, tags, actor); err != nil {
+		return fmt.Errorf("add candidate tags: %w", err)
+	}
+	return nil
+}
+
+// RemoveCandidateTag removes one tag from a candidate. Returns sql.ErrNoRows
+// if the candidate doesn't have it.
+func (db *DB) RemoveCandidateTag(ctx context.Context, candidateID int, tag string) error {
+	res, err := db.q().ExecContext(ctx, `
+		DELETE FROM candidate_tags
+		WHERE candidate_id = $1 AND tag = $2
+		  AND candidate_id IN (SELECT id FROM candidates WHERE org_id = $3)
+	`, candidateID, tag, tenant.OrgID(ctx))
+	if err != nil {
+		return fmt.Errorf("remove candidate tag: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (db *DB) getTagNamesByCandidate(ctx context.Context, candidateID int) ([]string, error) {
+	rows, err := db.q().QueryContext(ctx,
+		`SELECT tag FROM candidate_tags WHERE candidate_id = $1 ORDER BY tag`, candidateID)
+	if err != nil {
+		return nil, fmt.Errorf("get candidate tags: %w", err)
+	}
+	defer rows.Close()
+	tags := []string{}
+	for rows.Next() {
+		var t string
+		if err := rows.Scan(&t); err != nil {
+			return nil, fmt.Errorf("scan candidate tag: %w", err)
+		}
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
@@ -0,0 +1,272 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"cv-search/internal/tenant"
+)
+
+// ─── Notification preferences ────────────────────────────────────────────────
+
+// GetNotificationPreferences returns a user's settings in ctx's
+// organization, nil if they have none.
+func (db *DB) GetNotificationPreferences(ctx context.Context, userID string) (*NotificationPreferences, error) {
+	p := NotificationPreferences{OrgID: tenant.OrgID(ctx), UserID: userID}
+	err := db.q().QueryRowContext(ctx, `
+		SELECT email, batch_complete, weekly_digest, last_digest_at, updated_at
+		FROM notification_preferences
+		WHERE org_id = $1 AND user_id = $2
+	`, p.OrgID, userID).Scan(&p.Email, &p.BatchComplete, &p.WeeklyDigest, &p.LastDigestAt, &p.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get notification preferences of %q: %w", userID, err)
+	}
+	return &p, nil
+}
+
+// SaveNotificationPreferences replaces a user's settings in ctx's
+// organization and sets p.OrgID, p.LastDigestAt and p.UpdatedAt.
+func (db *DB) SaveNotificationPreferences(ctx context.Context, p *NotificationPreferences) error {
+	p.OrgID = tenant.OrgID(ctx)
+	err := db.q().QueryRowContext(ctx, `
+		INSERT INTO notification_preferences (org_id, user_id, email, batch_complete, weekly_digest, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (org_id, user_id) DO UPDATE SET
+			email          = EXCLUDED.email,
+			batch_complete = EXCLUDED.batch_complete,
+			weekly_digest  = EXCLUDED.weekly_digest,
+			updated_at     = NOW()
+		RETURNING last_digest_at, updated_at
+	`, p.OrgID, p.UserID, p.Email, p.BatchComplete, p.WeeklyDigest).Scan(&p.LastDigestAt, &p.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("save notification preferences of %q: %w", p.UserID, err)
+	}
+	return nil
+}
+
+// DeleteNotificationPreferences removes a user's settings in ctx's
+// organization, which stops all their emails. It reports false if they had
+// none.
+func (db *DB) DeleteNotificationPreferences(ctx context.Context, userID string) (bool, error) {
+	res, err := db.q().ExecContext(ctx,
+		`DELETE FROM notification_preferences WHERE org_id = $1 AND user_id = $2`, tenant.OrgID(ctx), userID)
+	if err != nil {
+		return false, fmt.Errorf("delete notification preferences of %q: %w", userID, err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// ─── Batch notifications ─────────────────────────────────────────────────────
+
+// CreateBatchNotification queues the report of a bulk upload in ctx's
+// organization.
+func (db *DB) CreateBatchNotification(ctx context.Context, n *BatchNotification) error {
+	failures := n.UploadFailures
+	if failures == nil {
+		failures = []BatchFileFailure{}
+	}
+	raw, err := json.Marshal(failures)
+	if err != nil {
+		return fmt.Errorf("create batch notification %s: %w", n.BatchID, err)
+	}
+	if n.JobIDs == nil {
+		n.JobIDs = []int64{}
+	}
+	n.OrgID = tenant.OrgID(ctx)
+	err = db.q().QueryRowContext(ctx, `
+		INSERT INTO batch_notifications (batch_id, org_id, email, files, job_ids, duplicates, upload_failures)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at
+	`, n.BatchID, n.OrgID, n.Email, n.Files, n.JobIDs, n.Duplicates, raw).Scan(&n.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("create batch notification %s: %w", n.BatchID, err)
+	}
+	return nil
+}
+
+// ListPendingBatchNotifications returns the unsent reports of every
+// organization that have failed fewer than maxAttempts sends, oldest first.
+func (db *DB) ListPendingBatchNotifications(ctx context.Context, maxAttempts int) ([]BatchNotification, error) {
+	rows, err := db.q().QueryContext(ctx, `
+		SELECT batch_id, org_id, email, files, to_json(job_ids), duplicates, upload_failures, attempts, created_at
+		FROM batch_notifications
+		WHERE sent_at IS NULL AND attempts < $1
+		ORDER BY created_at
+	`, maxAttempts)
+	if err != nil {
+		return nil, fmt.Errorf("list pending batch notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var out []BatchNotification
+	for rows.Next() {
+		var n BatchNotification
+		var jobIDs, failures []byte
+		if err := rows.Scan(&n.BatchID, &n.OrgID, &n.Email, &n.Files, &jobIDs, &n.Duplicates, &failures, &n.Attempts, &n.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan batch notification: %w", err)
+		}
+		if err := json.Unmarshal(jobIDs, &n.JobIDs); err != nil {
+			return nil, fmt.Errorf("batch notification %s job ids: %w", n.BatchID, err)
+		}
+		if err := json.Unmarshal(failures, &n.UploadFailures); err != nil {
+			return nil, fmt.Errorf("batch notification %s upload failures: %w", n.BatchID, err)
+		}
+		out = append(out, n)
+	}
+	return out, rows.Err()
+}
+
+// GetBatchJobOutcomes returns the state of a bulk upload's jobs. Jobs whose
+// CV was deleted since are missing.
+func (db *DB) GetBatchJobOutcomes(ctx context.Context, jobIDs []int64) ([]BatchJobOutcome, error) {
+	if len(jobIDs) == 0 {
+		return nil, nil
+	}
+	rows, err := db.q().QueryContext(ctx, `
+		SELECT j.id, f.filename, j.status, COALESCE(j.error_message, '')
+		FROM cv_upload_jobs j
+		JOIN cv_files f ON f.id = j.cv_file_id
+		WHERE j.id = ANY($1)
+		ORDER BY j.id
+	`, jobIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get batch job outcomes: %w", err)
+	}
+	defer rows.Close()
+
+	var out []BatchJobOutcome
+	for rows.Next() {
+		var o BatchJobOutcome
+		if err := rows.Scan(&o.JobID, &o.Filename, &o.Status, &o.Error); err != nil {
+			return nil, fmt.Errorf("scan batch job outcome: %w", err)
+		}
+		out = append(out, o)
+	}
+	return out, rows.Err()
+}
+
+// MarkBatchNotificationSent records that a report went out.
+func (db *DB) MarkBatchNotificationSent(ctx context.Context, batchID string) error {
+	if _, err := db.q().ExecContext(ctx,
+		`UPDATE batch_notifications SET sent_at = NOW(), attempts = attempts + 1 WHERE batch_id = $1`, batchID); err != nil {
+		return fmt.Errorf("mark batch notification %s sent: %w", batchID, err)
+	}
+	return nil
+}
+
+// RecordBatchNotificationFailure counts a failed send of a report.
+func (db *DB) RecordBatchNotificationFailure(ctx context.Context, batchID string) error {
+	if _, err := db.q().ExecContext(ctx,
+		`UPDATE batch_notifications SET attempts = attempts + 1 WHERE batch_id = $1`, batchID); err != nil {
+		return fmt.Errorf("record batch notification %s failure: %w", batchID, err)
+	}
+	return nil
+}
+
+// ─── Weekly digests ──────────────────────────────────────────────────────────
+
+// ListDueDigests returns the users of every organization who want a weekly
+// digest and haven't had one within interval, with their organization's
+// name.
+func (db *DB) ListDueDigests(ctx context.Context, interval time.Duration) ([]NotificationPreferences, error) {
+	rows, err := db.q().QueryContext(ctx, `
+		SELECT p.org_id, p.user_id, p.email, p.batch_complete, p.weekly_digest, p.last_digest_at, p.updated_at, o.name
+		FROM notification_preferences p
+		JOIN organizations o ON o.id = p.org_id
+		WHERE p.weekly_digest
+		  AND (p.last_digest_at IS NULL OR p.last_digest_at <= NOW() - make_interval(secs => $1))
+		ORDER BY p.org_id, p.user_id
+	`, interval.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("list due digests: %w", err)
+	}
+	defer rows.Close()
+
+	var out []NotificationPreferences
+	for rows.Next() {
+		var p NotificationPreferences
+		if err := rows.Scan(&p.OrgID, &p.UserID, &p.Email, &p.BatchComplete, &p.WeeklyDigest, &p.LastDigestAt, &p.UpdatedAt, &p.OrgName); err != nil {
+			return nil, fmt.Errorf("scan due digest: %w", err)
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// MarkDigestSent records that a user's digest went out.
+func (db *DB) MarkDigestSent(ctx context.Context, orgID int, userID string) error {
+	if _, err := db.q().ExecContext(ctx,
+		`UPDATE notification_preferences SET last_digest_at = NOW() WHERE org_id = $1 AND user_id = $2`, orgID, userID); err != nil {
+		return fmt.Errorf("mark digest of %q sent: %w", userID, err)
+	}
+	return nil
+}
+
+// GetWeeklyDigest summarizes ctx's organization's candidates added since:
+// how many, the newest limit of them and the skills most of them have.
+func (db *DB) GetWeeklyDigest(ctx context.Context, since time.Time, limit int) (*WeeklyDigest, error) {
+	orgID := tenant.OrgID(ctx)
+	d := &WeeklyDigest{}
+	if err := db.r().QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM candidates WHERE org_id = $1 AND deleted_at IS NULL AND created_at >= $2
+	`, orgID, since).Scan(&d.NewCandidates); err != nil {
+		return nil, fmt.Errorf("count new candidates: %w", err)
+	}
+	if d.NewCandidates == 0 {
+		return d, nil
+	}
+
+	rows, err := db.r().QueryContext(ctx, `
+		SELECT c.name, COALESCE(gn.properties->>'current_position', '')
+		FROM candidates c
+		LEFT JOIN graph_nodes gn ON gn.id = c.graph_node_id
+		WHERE c.org_id = $1 AND c.deleted_at IS NULL AND c.created_at >= $2
+		ORDER BY c.created_at DESC, c.id DESC
+		LIMIT $3
+	`, orgID, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list new candidates: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var c WeeklyDigestCandidate
+		if err := rows.Scan(&c.Name, &c.CurrentPosition); err != nil {
+			return nil, fmt.Errorf("scan new candidate: %w", err)
+		}
+		d.Newest = append(d.Newest, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	skillRows, err := db.r().QueryContext(ctx, `
+		SELECT s.properties->>'name' AS skill, COUNT(DISTINCT p.id)::int AS n
+		FROM graph_nodes p
+		JOIN graph_edges e ON e.source_node_id = p.id AND e.edge_type = 'HAS_SKILL'
+		JOIN graph_nodes s ON s.id = e.target_node_id AND s.node_type = 'skill'
+		WHERE p.node_type = 'person' AND p.org_id = $1 AND p.deleted_at IS NULL
+		  AND p.created_at >= $2 AND s.properties->>'name' IS NOT NULL
+		GROUP BY 1
+		ORDER BY n DESC, skill
+		LIMIT $3
+	`, orgID, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list trending skills: %w", err)
+	}
+	defer skillRows.Close()
+	for skillRows.Next() {
+		var s SkillCount
+		if err := skillRows.Scan(&s.Skill, &s.Count); err != nil {
+			return nil, fmt.Errorf("scan trending skill: %w", err)
+		}
+		d.TrendingSkills = append(d.TrendingSkills, s)
+	}
+	return d, skillRows.Err()
+}
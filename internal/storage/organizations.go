@@ -0,0 +1,208 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// ─── Organizations ───────────────────────────────────────────────────────────
+//
+// These are the only queries that aren't scoped to ctx's organization: they
+// manage the organizations themselves and resolve the org of an API key.
+
+// CreateOrganization inserts an organization. keyHash is the hex SHA-256 of
+// its API key, or "" for none. It returns nil if the slug is taken.
+func (db *DB) CreateOrganization(ctx context.Context, slug, name, keyHash string) (*Organization, error) {
+	var o Organization
+	err := db.q().QueryRowContext(ctx, `
+		INSERT INTO organizations (slug, name, api_key_hash)
+		VALUES ($1, $2, NULLIF($3, ''))
+		ON CONFLICT (slug) DO NOTHING
+		RETURNING id, slug, name, api_key_hash IS NOT NULL, created_at
+	`, slug, name, keyHash).Scan(&o.ID, &o.Slug, &o.Name, &o.HasAPIKey, &o.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create organization %q: %w", slug, err)
+	}
+	return &o, nil
+}
+
+// ListOrganizations returns every organization, oldest first.
+func (db *DB) ListOrganizations(ctx context.Context) ([]Organization, error) {
+	rows, err := db.r().QueryContext(ctx, `
+		SELECT id, slug, name, api_key_hash IS NOT NULL, created_at
+		FROM organizations
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("list organizations: %w", err)
+	}
+	defer rows.Close()
+
+	orgs := []Organization{}
+	for rows.Next() {
+		var o Organization
+		if err := rows.Scan(&o.ID, &o.Slug, &o.Name, &o.HasAPIKey, &o.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan organization: %w", err)
+		}
+		orgs = append(orgs, o)
+	}
+	return orgs, rows.Err()
+}
+
+// ListOrgIDs returns the ids of every organization, for maintenance passes
+// that run once per organization.
+func (db *DB) ListOrgIDs(ctx context.Context) ([]int, error) {
+	rows, err := db.r().QueryContext(ctx, `SELECT id FROM organizations ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("list organization ids: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan organization id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// SetOrganizationKey replaces an organization's API key hash. It reports
+// false if there is no organization with that id.
+func (db *DB) SetOrganizationKey(ctx context.Context, id int, keyHash string) (bool, error) {
+	res, err := db.q().ExecContext(ctx,
+		`UPDATE organizations SET api_key_hash = $2 WHERE id = $1`, id, keyHash)
+	if err != nil {
+		return false, fmt.Errorf("set organization %d key: %w", id, err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// OrgIDByKeyHash returns the organization whose API key hashes to keyHash,
+// 0 if none does. It reads the primary: a rotated or revoked key must stop
+// working at once, not when the replica catches up.
+func (db *DB) OrgIDByKeyHash(ctx context.Context, keyHash string) (int, error) {
+	var id int
+	err := db.q().QueryRowContext(ctx,
+		`SELECT id FROM organizations WHERE api_key_hash = $1`, keyHash).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("look up organization by key: %w", err)
+	}
+	return id, nil
+}
+
+// CVFileOrgID returns the organization a CV file belongs to, 0 if the file
+// doesn't exist. Workers that only know a file id (Groq batch results) use
+// it to scope the rest of their work.
+func (db *DB) CVFileOrgID(ctx context.Context, cvFileID int64) (int, error) {
+	var id int
+	err := db.q().QueryRowContext(ctx,
+		`SELECT org_id FROM cv_files WHERE id = $1`, cvFileID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("get organization of CV %d: %w", cvFileID, err)
+	}
+	return id, nil
+}
+
+// ─── Organization AI settings ────────────────────────────────────────────────
+
+// GetOrgAISettings returns an organization's LLM and embedding settings, nil
+// if it uses the deployment's. It reads the primary so a change is seen at
+// once.
+func (db *DB) GetOrgAISettings(ctx context.Context, orgID int) (*OrgAISettings, error) {
+	s := OrgAISettings{OrgID: orgID}
+	err := db.q().QueryRowContext(ctx, `
+		SELECT llm_provider, llm_model, llm_api_key,
+		       embedding_provider, embedding_model, embedding_dimensions, embedding_api_key,
+		       updated_at
+		FROM organization_ai_settings
+		WHERE org_id = $1
+	`, orgID).Scan(&s.LLMProvider, &s.LLMModel, &s.LLMAPIKey,
+		&s.EmbeddingProvider, &s.EmbeddingModel, &s.EmbeddingDimensions, &s.EmbeddingAPIKey,
+		&s.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get organization %d AI settings: %w", orgID, err)
+	}
+	return &s, nil
+}
+
+// SaveOrgAISettings replaces an organization's LLM and embedding settings
+// and sets s.UpdatedAt.
+func (db *DB) SaveOrgAISettings(ctx context.Context, s *OrgAISettings) error {
+	err := db.q().QueryRowContext(ctx, `
+		INSERT INTO organization_ai_settings
+			(org_id, llm_provider, llm_model, llm_api_key,
+			 embedding_provider, embedding_model, embedding_dimensions, embedding_api_key, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		ON CONFLICT (org_id) DO UPDATE SET
+			llm_provider         = EXCLUDED.llm_provider,
+			llm_model            = EXCLUDED.llm_model,
+			llm_api_key          = EXCLUDED.llm_api_key,
+			embedding_provider   = EXCLUDED.embedding_provider,
+			embedding_model      = EXCLUDED.embedding_model,
+			embedding_dimensions = EXCLUDED.embedding_dimensions,
+			embedding_api_key    = EXCLUDED.embedding_api_key,
+			updated_at           = NOW()
+		RETURNING updated_at
+	`, s.OrgID, s.LLMProvider, s.LLMModel, s.LLMAPIKey,
+		s.EmbeddingProvider, s.EmbeddingModel, s.EmbeddingDimensions, s.EmbeddingAPIKey,
+	).Scan(&s.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("save organization %d AI settings: %w", s.OrgID, err)
+	}
+	return nil
+}
+
+// DeleteOrgAISettings puts an organization back on the deployment's LLM and
+// embedding provider. It reports false if it had no settings of its own.
+func (db *DB) DeleteOrgAISettings(ctx context.Context, orgID int) (bool, error) {
+	res, err := db.q().ExecContext(ctx, `DELETE FROM organization_ai_settings WHERE org_id = $1`, orgID)
+	if err != nil {
+		return false, fmt.Errorf("delete organization %d AI settings: %w", orgID, err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// OrganizationExists reports whether there is an organization with that id.
+func (db *DB) OrganizationExists(ctx context.Context, orgID int) (bool, error) {
+	var ok bool
+	err := db.r().QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM organizations WHERE id = $1)`, orgID).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("check organization %d: %w", orgID, err)
+	}
+	return ok, nil
+}
+
+// OrgHasEmbeddings reports whether any of an organization's nodes, CV chunks
+// or communities has an embedding. Those were made with its current
+// embedding model, so the model can't change without re-embedding them.
+func (db *DB) OrgHasEmbeddings(ctx context.Context, orgID int) (bool, error) {
+	var ok bool
+	err := db.r().QueryRowContext(ctx, `
+		SELECT EXISTS(SELECT 1 FROM graph_nodes WHERE org_id = $1 AND embedding IS NOT NULL)
+		    OR EXISTS(SELECT 1 FROM graph_communities WHERE org_id = $1 AND embedding IS NOT NULL)
+		    OR EXISTS(SELECT 1 FROM cv_chunks c JOIN cv_files f ON f.id = c.cv_file_id
+		              WHERE f.org_id = $1 AND c.embedding IS NOT NULL)
+	`, orgID).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("check organization %d embeddings: %w", orgID, err)
+	}
+	return ok, nil
+}
@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"cv-search/internal/tenant"
+)
+
+// ─── LLM usage and admin overview ────────────────────────────────────────────
+
+// RecordLLMUsage adds one request's tokens to today's llm_usage row of ctx's
+// organization for the provider and model. costUSD is nil for a model
+// without a known price.
+func (db *DB) RecordLLMUsage(ctx context.Context, provider, model string, promptTokens, completionTokens int, costUSD *float64) error {
+	_, err := db.q().ExecContext(ctx, `
+		INSERT INTO llm_usage (day, org_id, provider, model, requests, prompt_tokens, completion_tokens, cost_usd)
+		VALUES (CURRENT_DATE, $6, $1, $2, 1, $3, $4, $5)
+		ON CONFLICT (day, org_id, provider, model) DO UPDATE SET
+			requests          = llm_usage.requests + 1,
+			prompt_tokens     = llm_usage.prompt_tokens + EXCLUDED.prompt_tokens,
+			completion_tokens = llm_usage.completion_tokens + EXCLUDED.completion_tokens,
+			cost_usd          = llm_usage.cost_usd + EXCLUDED.cost_usd
+	`, provider, model, promptTokens, completionTokens, costUSD, tenant.OrgID(ctx))
+	if err != nil {
+		return fmt.Errorf("record LLM usage: %w", err)
+	}
+	return nil
+}
+
+// GetAdminOverview gathers the admin dashboard's figures, with up to
+// failures of the most recent failed jobs.
+func (db *DB) GetAdminOverview(ctx context.Context, failures int) (*AdminOverview, error) {
+	o := &AdminOverview{JobsByStatus: map[string]int{}, LLMUsageToday: []LLMUsage{}, RecentFailures: []FailedJob{}}
+
+	var finishedToday, failedToday int
+	err := db.r().QueryRowContext(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM cv_files WHERE deleted_at IS NULL AND uploaded_at >= CURRENT_DATE),
+			(SELECT COUNT(*) FROM cv_upload_jobs WHERE status IN ('completed', 'failed') AND completed_at >= CURRENT_DATE),
+			(SELECT COUNT(*) FROM cv_upload_jobs WHERE status = 'failed' AND completed_at >= CURRENT_DATE),
+			(SELECT COUNT(*) FROM graph_nodes WHERE deleted_at IS NULL),
+			(SELECT COUNT(*) FROM graph_edges),
+			(SELECT COUNT(*) FROM graph_communities),
+			(SELECT COUNT(*) FROM graph_nodes WHERE deleted_at IS NULL AND embedding IS NULL),
+			(SELECT COUNT(*) FROM cv_chunks c JOIN cv_files f ON f.id = c.cv_file_id
+			  WHERE f.deleted_at IS NULL AND c.embedding IS NULL)
+	`).Scan(&o.UploadsToday, &finishedToday, &failedToday, &o.Nodes, &o.Edges, &o.Communities,
+		&o.EmbeddingBacklogNodes, &o.EmbeddingBacklogChunks)
+	if err != nil {
+		return nil, fmt.Errorf("admin overview counts: %w", err)
+	}
+	o.ExtractionsToday = finishedToday
+	if finishedToday > 0 {
+		o.ExtractionErrorRate = float64(failedToday) / float64(finishedToday)
+	}
+
+	rows, err := db.r().QueryContext(ctx, `SELECT status, COUNT(*) FROM cv_upload_jobs GROUP BY status`)
+	if err != nil {
+		return nil, fmt.Errorf("count jobs: %w", err)
+	}
+	for rows.Next() {
+		var status string
+		var n int
+		if err := rows.Scan(&status, &n); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan job count: %w", err)
+		}
+		o.JobsByStatus[status] = n
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("count jobs: %w", err)
+	}
+
+	rows, err = db.r().QueryContext(ctx, `
+		SELECT provider, model, SUM(requests)::int, SUM(prompt_tokens)::bigint, SUM(completion_tokens)::bigint,
+		       CASE WHEN COUNT(cost_usd) = COUNT(*) THEN SUM(cost_usd) END AS cost_usd
+		FROM llm_usage
+		WHERE day = CURRENT_DATE
+		GROUP BY provider, model
+		ORDER BY cost_usd DESC NULLS LAST, provider, model
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("LLM usage: %w", err)
+	}
+	for rows.Next() {
+		var u LLMUsage
+		if err := rows.Scan(&u.Provider, &u.Model, &u.Requests, &u.PromptTokens, &u.CompletionTokens, &u.CostUSD); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan LLM usage: %w", err)
+		}
+		if u.CostUSD != nil {
+			o.LLMSpendTodayUSD += *u.CostUSD
+		} else {
+			o.LLMSpendIncomplete = true
+		}
+		o.LLMUsageToday = append(o.LLMUsageToday, u)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("LLM usage: %w", err)
+	}
+
+	rows, err = db.r().QueryContext(ctx, `
+		SELECT j.id, j.cv_file_id, COALESCE(f.filename, ''), COALESCE(j.error_message, ''), j.completed_at
+		FROM cv_upload_jobs j
+		LEFT JOIN cv_files f ON f.id = j.cv_file_id
+		WHERE j.status = 'failed'
+		ORDER BY j.completed_at DESC NULLS LAST, j.id DESC
+		LIMIT $1
+	`, failures)
+	if err != nil {
+		return nil, fmt.Errorf("recent failures: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var f FailedJob
+		if err := rows.Scan(&f.JobID, &f.CVFileID, &f.Filename, &f.Error, &f.FailedAt); err != nil {
+			return nil, fmt.Errorf("scan failed job: %w", err)
+		}
+		o.RecentFailures = append(o.RecentFailures, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("recent failures: %w", err)
+	}
+	return o, nil
+}
@@ -0,0 +1,304 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"cv-search/internal/tenant"
+)
+
+// ─── Talent pools ────────────────────────────────────────────────────────────
+
+// ErrPoolNameTaken is returned when ctx's organization already has a pool
+// with the requested name.
+var ErrPoolNameTaken = errors.New("pool name already taken")
+
+// CreateTalentPool creates an empty pool in ctx's organization.
+func (db *DB) CreateTalentPool(ctx context.Context, name, description, actor string) (*TalentPool, error) {
+	p := TalentPool{Name: name, Description: description, CreatedBy: actor, Stages: map[string]int{}}
+	err := db.q().QueryRowContext(ctx, `
+		INSERT INTO talent_pools (org_id, name, description, created_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (org_id, name) DO NOTHING
+		RETURNING id, created_at, updated_at
+	`, tenant.OrgID(ctx), name, description, actor).Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrPoolNameTaken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create talent pool %q: %w", name, err)
+	}
+	return &p, nil
+}
+
+// ListTalentPools returns ctx's organization's pools by name, with their
+// member counts.
+func (db *DB) ListTalentPools(ctx context.Context) ([]TalentPool, error) {
+	return db.queryTalentPools(ctx, `WHERE org_id = $1 ORDER BY name`, tenant.OrgID(ctx))
+}
+
+// GetTalentPool returns a pool of ctx's organization with its member counts,
+// or nil if it doesn't exist.
+func (db *DB) GetTalentPool(ctx context.Context, poolID int) (*TalentPool, error) {
+	pools, err := db.queryTalentPools(ctx, `WHERE org_id = $1 AND id = $2`, tenant.OrgID(ctx), poolID)
+	if err != nil {
+		return nil, err
+	}
+	if len(pools) == 0 {
+		return nil, nil
+	}
+	return &pools[0], nil
+}
+
+func (db *DB) queryTalentPools(ctx context.Context, where string, args ...interface{}) ([]TalentPool, error) {
+	rows, err := db.q().QueryContext(ctx,
+		`SELECT id, name, description, created_by, created_at, updated_at FROM talent_pools `+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list talent pools: %w", err)
+	}
+	defer rows.Close()
+
+	pools := []TalentPool{}
+	index := map[int]int{}
+	ids := []int{}
+	for rows.Next() {
+		p := TalentPool{Stages: map[string]int{}}
+		if err := rows.Scan(&p.ID, &p.Name, &p.Description, &p.CreatedBy, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan talent pool: %w", err)
+		}
+		index[p.ID] = len(pools)
+		ids = append(ids, p.ID)
+		pools = append(pools, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list talent pools: %w", err)
+	}
+	if len(ids) == 0 {
+		return pools, nil
+	}
+
+	counts, err := db.q().QueryContext(ctx, `
+		SELECT m.pool_id, m.stage, COUNT(*)
+		FROM talent_pool_members m
+		JOIN candidates c ON c.id = m.candidate_id
+		WHERE m.pool_id = ANY($1) AND c.deleted_at IS NULL
+		GROUP BY m.pool_id, m.stage
+	`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("count talent pool members: %w", err)
+	}
+	defer counts.Close()
+	for counts.Next() {
+		var poolID, n int
+		var stage string
+		if err := counts.Scan(&poolID, &stage, &n); err != nil {
+			return nil, fmt.Errorf("scan talent pool count: %w", err)
+		}
+		p := &pools[index[poolID]]
+		p.Stages[stage] = n
+		p.Size += n
+	}
+	return pools, counts.Err()
+}
+
+// UpdateTalentPool renames a pool and replaces its description. Returns nil
+// if the pool doesn't exist, ErrPoolNameTaken if another pool has the name.
+func (db *DB) UpdateTalentPool(ctx context.Context, poolID int, name, description string) (*TalentPool, error) {
+	orgID := tenant.OrgID(ctx)
+	var taken bool
+	if err := db.q().QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM talent_pools WHERE org_id = $1 AND name = $2 AND id <> $3)
+	`, orgID, name, poolID).Scan(&taken); err != nil {
+		return nil, fmt.Errorf("check talent pool name: %w", err)
+	}
+	if taken {
+		return nil, ErrPoolNameTaken
+	}
+
+	res, err := db.q().ExecContext(ctx, `
+		UPDATE talent_pools SET name = $3, description = $4, updated_at = NOW()
+		WHERE id = $1 AND org_id = $2
+	`, poolID, orgID, name, description)
+	if err != nil {
+		return nil, fmt.Errorf("update talent pool %d: %w", poolID, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, nil
+	}
+	return db.GetTalentPool(ctx, poolID)
+}
+
+// DeleteTalentPool deletes a pool and its memberships (not the candidates).
+// Reports whether the pool existed.
+func (db *DB) DeleteTalentPool(ctx context.Context, poolID int) (bool, error) {
+	res, err := db.q().ExecContext(ctx,
+		`DELETE FROM talent_pools WHERE id = $1 AND org_id = $2`, poolID, tenant.OrgID(ctx))
+	if err != nil {
+		return false, fmt.Errorf("delete talent pool %d: %w", poolID, err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// ─── Talent pool members ─────────────────────────────────────────────────────
+
+// poolInOrg reports whether poolID is a pool of ctx's organization.
+func (db *DB) poolInOrg(ctx context.Context, poolID int) (bool, error) {
+	var ok bool
+	err := db.q().QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM talent_pools WHERE id = $1 AND org_id = $2)`, poolID, tenant.OrgID(ctx),
+	).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("check talent pool %d: %w", poolID, err)
+	}
+	return ok, nil
+}
+
+// ListTalentPoolMembers returns a pool's members, most recently moved first,
+// optionally only those at stage. Returns sql.ErrNoRows if the pool does not
+// exist in ctx's organization.
+func (db *DB) ListTalentPoolMembers(ctx context.Context, poolID int, stage string, limit, offset int) ([]TalentPoolMember, error) {
+	ok, err := db.poolInOrg(ctx, poolID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	rows, err := db.r().QueryContext(ctx, `
+		SELECT c.id, c.name,
+		       COALESCE(gn.properties->>'current_position', ''),
+		       COALESCE(gn.properties->>'seniority', ''),
+		       m.stage, m.added_by, m.added_at, m.stage_changed_at
+		FROM talent_pool_members m
+		JOIN candidates c ON c.id = m.candidate_id
+		LEFT JOIN graph_nodes gn ON gn.id = c.graph_node_id
+		WHERE m.pool_id = $1 AND c.deleted_at IS NULL
+		  AND ($2 = '' OR m.stage = $2)
+		ORDER BY m.stage_changed_at DESC, c.id
+		LIMIT $3 OFFSET $4
+	`, poolID, stage, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("list talent pool members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []TalentPoolMember{}
+	for rows.Next() {
+		var m TalentPoolMember
+		if err := rows.Scan(&m.CandidateID, &m.Name, &m.CurrentPosition, &m.Seniority,
+			&m.Stage, &m.AddedBy, &m.AddedAt, &m.StageChangedAt); err != nil {
+			return nil, fmt.Errorf("scan talent pool member: %w", err)
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+// AddTalentPoolMembers adds candidates to a pool at stage and returns the
+// IDs added. Candidates already in the pool keep their stage; IDs that
+// aren't live candidates of ctx's organization are skipped. Returns
+// sql.ErrNoRows if the pool does not exist in ctx's organization.
+func (db *DB) AddTalentPoolMembers(ctx context.Context, poolID int, candidateIDs []int, stage, actor string) ([]int, error) {
+	ok, err := db.poolInOrg(ctx, poolID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	rows, err := db.q().QueryContext(ctx, `
+		INSERT INTO talent_pool_members (pool_id, candidate_id, stage, added_by)
+		SELECT $1, id, $3, $4
+		FROM candidates WHERE id = ANY($2) AND org_id = $5 AND deleted_at IS NULL
+		ON CONFLICT (pool_id, candidate_id) DO NOTHING
+		RETURNING candidate_id
+	`, poolID, candidateIDs, stage, actor, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("add talent pool members: %w", err)
+	}
+	defer rows.Close()
+	added := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("add talent pool members: %w", err)
+		}
+		added = append(added, id)
+	}
+	return added, rows.Err()
+}
+
+// SetTalentPoolMemberStage moves a pool member to stage and returns the
+// stage it was at. Returns sql.ErrNoRows if the candidate isn't in the pool.
+func (db *DB) SetTalentPoolMemberStage(ctx context.Context, poolID, candidateID int, stage string) (string, error) {
+	var previous string
+	err := db.q().QueryRowContext(ctx, `
+		WITH old AS (
+			SELECT stage FROM talent_pool_members
+			WHERE pool_id = $1 AND candidate_id = $2
+			FOR UPDATE
+		)
+		UPDATE talent_pool_members m
+		SET stage = $3,
+		    stage_changed_at = CASE WHEN m.stage = $3 THEN m.stage_changed_at ELSE NOW() END
+		FROM old
+		WHERE m.pool_id = $1 AND m.candidate_id = $2
+		  AND m.pool_id IN (SELECT id FROM talent_pools WHERE org_id = $4)
+		RETURNING old.stage
+	`, poolID, candidateID, stage, tenant.OrgID(ctx)).Scan(&previous)
+	if err == sql.ErrNoRows {
+		return "", err
+	}
+	if err != nil {
+		return "", fmt.Errorf("set talent pool stage: %w", err)
+	}
+	return previous, nil
+}
+
+// RemoveTalentPoolMember takes a candidate out of a pool. Returns
+// sql.ErrNoRows if the candidate isn't in the pool.
+func (db *DB) RemoveTalentPoolMember(ctx context.Context, poolID, candidateID int) error {
+	res, err := db.q().ExecContext(ctx, `
+		DELETE FROM talent_pool_members
+		WHERE pool_id = $1 AND candidate_id = $2
+		  AND pool_id IN (SELECT id FROM talent_pools WHERE org_id = $3)
+	`, poolID, candidateID, tenant.OrgID(ctx))
+	if err != nil {
+		return fmt.Errorf("remove talent pool member: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// copyPoolMemberships puts toID in every pool fromID is in and toID isn't,
+// at fromID's stage, and returns the pool IDs added.
+func (db *DB) copyPoolMemberships(ctx context.Context, fromID, toID int) ([]int, error) {
+	rows, err := db.q().QueryContext(ctx, `
+		INSERT INTO talent_pool_members (pool_id, candidate_id, stage, added_by, added_at, stage_changed_at)
+		SELECT pool_id, $2, stage, added_by, added_at, stage_changed_at
+		FROM talent_pool_members WHERE candidate_id = $1
+		ON CONFLICT (pool_id, candidate_id) DO NOTHING
+		RETURNING pool_id
+	`, fromID, toID)
+	if err != nil {
+		return nil, fmt.Errorf("copy talent pool memberships: %w", err)
+	}
+	defer rows.Close()
+	added := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("copy talent pool memberships: %w", err)
+		}
+		added = append(added, id)
+	}
+	return added, rows.Err()
+}
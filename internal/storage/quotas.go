@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"cv-search/internal/tenant"
+)
+
+// ─── Usage and quotas ────────────────────────────────────────────────────────
+
+// AddOrgUsage counts uploads and searches for ctx's organization today.
+func (db *DB) AddOrgUsage(ctx context.Context, uploads, searches int) error {
+	_, err := db.q().ExecContext(ctx, `
+		INSERT INTO org_usage (org_id, day, uploads, searches)
+		VALUES ($1, CURRENT_DATE, $2, $3)
+		ON CONFLICT (org_id, day) DO UPDATE SET
+			uploads  = org_usage.uploads + EXCLUDED.uploads,
+			searches = org_usage.searches + EXCLUDED.searches
+	`, tenant.OrgID(ctx), uploads, searches)
+	if err != nil {
+		return fmt.Errorf("add organization usage: %w", err)
+	}
+	return nil
+}
+
+// GetOrgUsage returns ctx's organization's uploads and searches today and
+// its LLM tokens (prompt and completion) this month.
+func (db *DB) GetOrgUsage(ctx context.Context) (*OrgUsage, error) {
+	var u OrgUsage
+	err := db.q().QueryRowContext(ctx, `
+		SELECT
+			COALESCE((SELECT uploads FROM org_usage WHERE org_id = $1 AND day = CURRENT_DATE), 0),
+			COALESCE((SELECT searches FROM org_usage WHERE org_id = $1 AND day = CURRENT_DATE), 0),
+			COALESCE((SELECT SUM(prompt_tokens + completion_tokens) FROM llm_usage
+			          WHERE org_id = $1 AND day >= date_trunc('month', CURRENT_DATE)), 0)::bigint,
+			(CURRENT_DATE + 1)::timestamptz,
+			(date_trunc('month', CURRENT_DATE) + INTERVAL '1 month')::timestamptz
+	`, tenant.OrgID(ctx)).Scan(&u.UploadsToday, &u.SearchesToday, &u.LLMTokensThisMonth, &u.DayEnds, &u.MonthEnds)
+	if err != nil {
+		return nil, fmt.Errorf("get organization usage: %w", err)
+	}
+	return &u, nil
+}
+
+// ListOrgLLMUsageThisMonth returns ctx's organization's LLM requests and
+// tokens this month per provider and model, most expensive first.
+func (db *DB) ListOrgLLMUsageThisMonth(ctx context.Context) ([]LLMUsage, error) {
+	rows, err := db.r().QueryContext(ctx, `
+		SELECT provider, model, SUM(requests)::int, SUM(prompt_tokens)::bigint, SUM(completion_tokens)::bigint,
+		       CASE WHEN COUNT(cost_usd) = COUNT(*) THEN SUM(cost_usd) END AS cost_usd
+		FROM llm_usage
+		WHERE org_id = $1 AND day >= date_trunc('month', CURRENT_DATE)
+		GROUP BY provider, model
+		ORDER BY cost_usd DESC NULLS LAST, provider, model
+	`, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("list organization LLM usage: %w", err)
+	}
+	defer rows.Close()
+
+	usage := []LLMUsage{}
+	for rows.Next() {
+		var u LLMUsage
+		if err := rows.Scan(&u.Provider, &u.Model, &u.Requests, &u.PromptTokens, &u.CompletionTokens, &u.CostUSD); err != nil {
+			return nil, fmt.Errorf("scan organization LLM usage: %w", err)
+		}
+		usage = append(usage, u)
+	}
+	return usage, rows.Err()
+}
+
+// GetOrgQuotas returns an organization's own quotas, nil if it has none.
+func (db *DB) GetOrgQuotas(ctx context.Context, orgID int) (*OrgQuotas, error) {
+	q := OrgQuotas{OrgID: orgID}
+	err := db.q().QueryRowContext(ctx, `
+		SELECT uploads_per_day, searches_per_day, llm_tokens_per_month, updated_at
+		FROM organization_quotas
+		WHERE org_id = $1
+	`, orgID).Scan(&q.UploadsPerDay, &q.SearchesPerDay, &q.LLMTokensPerMonth, &q.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get organization %d quotas: %w", orgID, err)
+	}
+	return &q, nil
+}
+
+// SaveOrgQuotas replaces an organization's own quotas and sets q.UpdatedAt.
+func (db *DB) SaveOrgQuotas(ctx context.Context, q *OrgQuotas) error {
+	err := db.q().QueryRowContext(ctx, `
+		INSERT INTO organization_quotas (org_id, uploads_per_day, searches_per_day, llm_tokens_per_month, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (org_id) DO UPDATE SET
+			uploads_per_day      = EXCLUDED.uploads_per_day,
+			searches_per_day     = EXCLUDED.searches_per_day,
+			llm_tokens_per_month = EXCLUDED.llm_tokens_per_month,
+			updated_at           = NOW()
+		RETURNING updated_at
+	`, q.OrgID, q.UploadsPerDay, q.SearchesPerDay, q.LLMTokensPerMonth).Scan(&q.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("save organization %d quotas: %w", q.OrgID, err)
+	}
+	return nil
+}
+
+// DeleteOrgQuotas puts an organization back on the deployment's default
+// quotas. It reports false if it had none of its own.
+func (db *DB) DeleteOrgQuotas(ctx context.Context, orgID int) (bool, error) {
+	res, err := db.q().ExecContext(ctx, `DELETE FROM organization_quotas WHERE org_id = $1`, orgID)
+	if err != nil {
+		return false, fmt.Errorf("delete organization %d quotas: %w", orgID, err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// ─── Read replica ────────────────────────────────────────────────────────────
+//
+// Read-only traffic (search, stats, listings) can go to a streaming replica
+// so it doesn't compete with background writes for the primary's pool. The
+// read pool dials the replica and falls back to the primary whenever the
+// replica can't be reached, so a missing or failed replica only costs the
+// offload, never availability. Pooled connections are recycled after
+// ConnMaxLifetime, which is also how reads drift back once the replica
+// recovers.
+
+// NewDBWithReplica opens the primary pool and, when replicaDSN is non-empty,
+// a read pool routed to the replica. An unreachable replica at startup is
+// logged, not fatal: reads use the primary until it comes up.
+func NewDBWithReplica(primaryDSN, replicaDSN string) (*DB, error) {
+	db, err := NewDB(primaryDSN)
+	if err != nil || replicaDSN == "" {
+		return db, err
+	}
+
+	primaryCfg, err := pgx.ParseConfig(primaryDSN)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("parse database url: %w", err)
+	}
+	replicaCfg, err := pgx.ParseConfig(replicaDSN)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("parse replica database url: %w", err)
+	}
+
+	fc := &failoverConnector{
+		replica: stdlib.GetConnector(*replicaCfg),
+		primary: stdlib.GetConnector(*primaryCfg),
+	}
+	reads := sql.OpenDB(fc)
+	reads.SetMaxOpenConns(25)
+	reads.SetMaxIdleConns(10)
+	reads.SetConnMaxLifetime(5 * time.Minute)
+	reads.SetConnMaxIdleTime(1 * time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if conn, err := fc.replica.Connect(ctx); err != nil {
+		log.Printf("[DB] Read replica unreachable, reads use the primary until it recovers: %v", err)
+		fc.onPrimary.Store(true)
+	} else {
+		conn.Close()
+		log.Printf("[DB] Read replica connected (%s)", replicaCfg.Host)
+	}
+
+	db.reads = reads
+	return db, nil
+}
+
+// SetPoolLimits caps the open and idle connections of each pool (primary,
+// and the replica pool when there is one).
+func (db *DB) SetPoolLimits(maxOpen, maxIdle int) {
+	db.connection.SetMaxOpenConns(maxOpen)
+	db.connection.SetMaxIdleConns(maxIdle)
+	if db.HasReplica() {
+		db.reads.SetMaxOpenConns(maxOpen)
+		db.reads.SetMaxIdleConns(maxIdle)
+	}
+}
+
+// ReadConnection returns the pool for read-only raw queries: the replica
+// pool when one is configured, otherwise the primary. Never write through it.
+func (db *DB) ReadConnection() *sql.DB {
+	return db.reads
+}
+
+// HasReplica reports whether reads are routed to a separate replica pool.
+func (db *DB) HasReplica() bool {
+	return db.reads != db.connection
+}
+
+// r returns the querier for read-only statements. Inside a transaction it
+// stays on the transaction so callers read their own writes.
+func (db *DB) r() querier {
+	if db.tx != nil {
+		return db.tx
+	}
+	return db.reads
+}
+
+// failoverConnector dials the replica and falls back to the primary when the
+// replica refuses the connection.
+type failoverConnector struct {
+	replica   driver.Connector
+	primary   driver.Connector
+	onPrimary atomic.Bool // last dial fell back; only state changes are logged
+}
+
+func (c *failoverConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.replica.Connect(ctx)
+	if err == nil {
+		if c.onPrimary.CompareAndSwap(true, false) {
+			log.Println("[DB] Read replica reachable again, routing reads to it")
+		}
+		return conn, nil
+	}
+	if ctx.Err() != nil {
+		return nil, err
+	}
+	if c.onPrimary.CompareAndSwap(false, true) {
+		log.Printf("[DB] Read replica unavailable, failing reads over to the primary: %v", err)
+	}
+	return c.primary.Connect(ctx)
+}
+
+func (c *failoverConnector) Driver() driver.Driver {
+	return c.primary.Driver()
+}
@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"context"
+)
+
+// GraphRepo is the slice of *DB the search quality evaluation
+// (internal/eval) needs, so its engines can be run against a stub of the
+// graph instead of a live Postgres.
+type GraphRepo interface {
+	GetPersonEmbedding(ctx context.Context, graphNodeID int) ([]float32, error)
+	GetPersonNodeIDString(ctx context.Context, graphNodeID int) (string, error)
+	GetCandidateIDsByPersonNodeIDs(ctx context.Context, personNodeIDs []string) (map[string]int, error)
+	GetCandidatesByPersonNodeIDs(ctx context.Context, personNodeIDs []string, excludeNodeID string, similarities map[string]float64) ([]SimilarCandidate, error)
+	GetGraphOverlap(ctx context.Context, graphNodeID, limit int) (*GraphOverlapResult, error)
+	SuggestFromGraph(ctx context.Context, prefix string, limit int) ([]SuggestionResult, error)
+	GetTopSkillsForQueries(ctx context.Context, skillLimit, seniorityLimit int) (skills []string, seniorities []string, err error)
+}
+
+var _ GraphRepo = (*DB)(nil)
@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ─── Blob retention ──────────────────────────────────────────────────────────
+
+// TouchBlob records that key is about to be (re)written, resetting its orphan
+// clock. Call it before BlobStore.Put: a concurrent cleanup that already
+// claimed the key holds its row lock, so this waits until that delete is done.
+func (db *DB) TouchBlob(ctx context.Context, key string, size int64) error {
+	_, err := db.q().ExecContext(ctx, `
+		INSERT INTO cv_blobs (key, size) VALUES ($1, $2)
+		ON CONFLICT (key) DO UPDATE SET touched_at = NOW(), size = EXCLUDED.size
+	`, key, size)
+	if err != nil {
+		return fmt.Errorf("touch blob %s: %w", key, err)
+	}
+	return nil
+}
+
+// ListOrphanedBlobs returns up to limit blob keys that no cv_files row
+// references (as its file or photo) and that haven't been touched since cutoff.
+func (db *DB) ListOrphanedBlobs(ctx context.Context, cutoff time.Time, limit int) ([]string, error) {
+	rows, err := db.q().QueryContext(ctx, `
+		SELECT b.key FROM cv_blobs b
+		WHERE b.touched_at < $1
+		  AND NOT EXISTS (SELECT 1 FROM cv_files f WHERE f.file_path = b.key OR f.photo_key = b.key)
+		ORDER BY b.touched_at
+		LIMIT $2
+	`, cutoff, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list orphaned blobs: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []string{}
+	for rows.Next() {
+		var k string
+		if err := rows.Scan(&k); err != nil {
+			return nil, fmt.Errorf("scan orphaned blob: %w", err)
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+// ClaimOrphanedBlob locks key's row if it is still an orphan older than
+// cutoff. Only meaningful inside WithTx: the lock is held until the caller
+// has deleted the object and the row, and keeps TouchBlob from reusing the
+// key in between. Returns false if the key was reused, is locked by another
+// cleanup or doesn't exist.
+func (db *DB) ClaimOrphanedBlob(ctx context.Context, key string, cutoff time.Time) (bool, error) {
+	var k string
+	err := db.q().QueryRowContext(ctx, `
+		SELECT b.key FROM cv_blobs b
+		WHERE b.key = $1 AND b.touched_at < $2
+		  AND NOT EXISTS (SELECT 1 FROM cv_files f WHERE f.file_path = b.key OR f.photo_key = b.key)
+		FOR UPDATE SKIP LOCKED
+	`, key, cutoff).Scan(&k)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("claim orphaned blob %s: %w", key, err)
+	}
+	return true, nil
+}
+
+// DeleteBlobRecord forgets key after its object has been removed.
+func (db *DB) DeleteBlobRecord(ctx context.Context, key string) error {
+	if _, err := db.q().ExecContext(ctx, `DELETE FROM cv_blobs WHERE key = $1`, key); err != nil {
+		return fmt.Errorf("delete blob record %s: %w", key, err)
+	}
+	return nil
+}
+
+// ListPrunableCVVersions returns the CV files beyond the newest keep uploads
+// of each candidate. The file a candidate's person node was built from
+// (properties.cv_id) is never returned.
+func (db *DB) ListPrunableCVVersions(ctx context.Context, keep int) ([]int64, error) {
+	rows, err := db.q().QueryContext(ctx, `
+		WITH ranked AS (
+			SELECT f.id, f.candidate_id,
+			       row_number() OVER (PARTITION BY f.candidate_id ORDER BY f.uploaded_at DESC, f.id DESC) AS rn
+			FROM cv_files f
+			WHERE f.candidate_id IS NOT NULL
+		)
+		SELECT r.id FROM ranked r
+		WHERE r.rn > $1
+		  AND NOT EXISTS (
+			SELECT 1 FROM candidates c
+			JOIN graph_nodes n ON n.id = c.graph_node_id
+			WHERE c.id = r.candidate_id AND n.properties->>'cv_id' = r.id::text
+		  )
+		ORDER BY r.id
+	`, keep)
+	if err != nil {
+		return nil, fmt.Errorf("list prunable cv versions: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan prunable cv version: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// DeleteCVFiles hard-deletes CV file rows (entities and jobs cascade). Their
+// blobs become orphans for the retention cleanup. Returns the number deleted.
+func (db *DB) DeleteCVFiles(ctx context.Context, ids []int64) (int, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	res, err := db.q().ExecContext(ctx, `DELETE FROM cv_files WHERE id = ANY($1)`, ids)
+	if err != nil {
+		return 0, fmt.Errorf("delete cv files: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
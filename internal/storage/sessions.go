@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"cv-search/internal/tenant"
+)
+
+// ─── Search sessions ─────────────────────────────────────────────────────────
+
+// CreateSearchSession inserts an empty session with the given id for ctx's
+// organization.
+func (db *DB) CreateSearchSession(ctx context.Context, id string) error {
+	if _, err := db.q().ExecContext(ctx,
+		`INSERT INTO search_sessions (id, org_id) VALUES ($1, $2)`, id, tenant.OrgID(ctx)); err != nil {
+		return fmt.Errorf("create search session: %w", err)
+	}
+	return nil
+}
+
+// GetSearchSession returns a session with all of its turns in order, or nil
+// if ctx's organization has no session with that id.
+func (db *DB) GetSearchSession(ctx context.Context, id string) (*SearchSession, error) {
+	var s SearchSession
+	err := db.q().QueryRowContext(ctx,
+		`SELECT id, created_at, updated_at FROM search_sessions WHERE id = $1 AND org_id = $2`, id, tenant.OrgID(ctx)).
+		Scan(&s.ID, &s.CreatedAt, &s.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get search session %q: %w", id, err)
+	}
+
+	rows, err := db.q().QueryContext(ctx, `
+		SELECT turn, query, action, COALESCE(effective_query, ''), COALESCE(explanation, ''), results, created_at
+		FROM search_session_turns
+		WHERE session_id = $1
+		ORDER BY turn`, id)
+	if err != nil {
+		return nil, fmt.Errorf("list search session turns: %w", err)
+	}
+	defer rows.Close()
+
+	s.Turns = []SearchSessionTurn{}
+	for rows.Next() {
+		var t SearchSessionTurn
+		var results []byte
+		if err := rows.Scan(&t.Turn, &t.Query, &t.Action, &t.EffectiveQuery, &t.Explanation, &results, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan search session turn: %w", err)
+		}
+		t.Results = json.RawMessage(results)
+		s.Turns = append(s.Turns, t)
+	}
+	return &s, rows.Err()
+}
+
+// AppendSearchSessionTurn stores the next turn of a session and returns its
+// turn number. The turn number is allocated inside the INSERT so concurrent
+// follow-ups on the same session can't collide silently — the loser gets a
+// unique violation instead.
+func (db *DB) AppendSearchSessionTurn(ctx context.Context, sessionID string, t SearchSessionTurn) (int, error) {
+	results := t.Results
+	if len(results) == 0 {
+		results = json.RawMessage(`[]`)
+	}
+
+	var turn int
+	err := db.WithTx(ctx, func(tx *DB) error {
+		err := tx.q().QueryRowContext(ctx, `
+			INSERT INTO search_session_turns (session_id, turn, query, action, effective_query, explanation, results)
+			SELECT $1, COALESCE(MAX(turn), 0) + 1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6
+			FROM search_session_turns WHERE session_id = $1
+			RETURNING turn`,
+			sessionID, t.Query, t.Action, t.EffectiveQuery, t.Explanation, []byte(results)).Scan(&turn)
+		if err != nil {
+			return fmt.Errorf("append search session turn: %w", err)
+		}
+
+		if _, err := tx.q().ExecContext(ctx,
+			`UPDATE search_sessions SET updated_at = NOW() WHERE id = $1`, sessionID); err != nil {
+			return fmt.Errorf("touch search session: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return turn, nil
+}
@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"cv-search/internal/tenant"
+)
+
+// ─── Share links ─────────────────────────────────────────────────────────────
+
+const shareLinkColumns = `id, org_id, candidate_id, anonymized, expires_at, created_by, created_at, revoked_at, views, last_viewed_at`
+
+func scanShareLink(row interface{ Scan(...interface{}) error }) (ShareLink, error) {
+	var l ShareLink
+	var revokedAt, lastViewedAt sql.NullTime
+	err := row.Scan(&l.ID, &l.OrgID, &l.CandidateID, &l.Anonymized, &l.ExpiresAt, &l.CreatedBy, &l.CreatedAt, &revokedAt, &l.Views, &lastViewedAt)
+	if revokedAt.Valid {
+		l.RevokedAt = &revokedAt.Time
+	}
+	if lastViewedAt.Valid {
+		l.LastViewedAt = &lastViewedAt.Time
+	}
+	return l, err
+}
+
+// CreateShareLink adds a share link to a candidate's profile, valid until
+// expiresAt. Returns sql.ErrNoRows if the candidate does not exist in ctx's
+// organization.
+func (db *DB) CreateShareLink(ctx context.Context, candidateID int, anonymized bool, expiresAt time.Time, actor string) (*ShareLink, error) {
+	l, err := scanShareLink(db.q().QueryRowContext(ctx, `
+		INSERT INTO share_links (org_id, candidate_id, anonymized, expires_at, created_by)
+		SELECT org_id, id, $3, $4, $5
+		FROM candidates WHERE id = $1 AND org_id = $2 AND deleted_at IS NULL
+		RETURNING `+shareLinkColumns,
+		candidateID, tenant.OrgID(ctx), anonymized, expiresAt, actor))
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create share link: %w", err)
+	}
+	return &l, nil
+}
+
+// ListShareLinks returns a candidate's share links, newest first, revoked
+// and expired ones included. Returns sql.ErrNoRows if the candidate does not
+// exist in ctx's organization.
+func (db *DB) ListShareLinks(ctx context.Context, candidateID int) ([]ShareLink, error) {
+	ok, err := db.CandidateInOrg(ctx, candidateID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	rows, err := db.q().QueryContext(ctx, `
+		SELECT `+shareLinkColumns+`
+		FROM share_links
+		WHERE candidate_id = $1 AND org_id = $2
+		ORDER BY created_at DESC, id DESC
+	`, candidateID, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("list share links: %w", err)
+	}
+	defer rows.Close()
+
+	links := []ShareLink{}
+	for rows.Next() {
+		l, err := scanShareLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan share link: %w", err)
+		}
+		links = append(links, l)
+	}
+	return links, rows.Err()
+}
+
+// RevokeShareLink revokes a share link of candidateID. Returns sql.ErrNoRows
+// if the link doesn't exist in ctx's organization, doesn't belong to the
+// candidate or is already revoked.
+func (db *DB) RevokeShareLink(ctx context.Context, linkID int64, candidateID int) error {
+	res, err := db.q().ExecContext(ctx, `
+		UPDATE share_links SET revoked_at = NOW()
+		WHERE id = $1 AND candidate_id = $2 AND org_id = $3 AND revoked_at IS NULL
+	`, linkID, candidateID, tenant.OrgID(ctx))
+	if err != nil {
+		return fmt.Errorf("revoke share link: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ViewShareLink counts a view of a share link and returns it. It is not
+// scoped to ctx's organization: the link's token is what authorizes the
+// view, and the returned OrgID is the organization to read the profile in.
+// Returns sql.ErrNoRows if the link doesn't exist, is revoked or expired, or
+// its candidate was deleted.
+func (db *DB) ViewShareLink(ctx context.Context, linkID int64) (*ShareLink, error) {
+	l, err := scanShareLink(db.q().QueryRowContext(ctx, `
+		UPDATE share_links SET views = views + 1, last_viewed_at = NOW()
+		WHERE id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		  AND candidate_id IN (SELECT id FROM candidates WHERE deleted_at IS NULL)
+		RETURNING `+shareLinkColumns, linkID))
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("view share link %d: %w", linkID, err)
+	}
+	return &l, nil
+}
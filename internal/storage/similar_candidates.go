@@ -0,0 +1,226 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"cv-search/internal/tenant"
+)
+
+// GetPersonEmbedding reads the stored vector embedding for a person graph node,
+// returning it as []float32 for direct use in similarity search.
+// Returns nil if the node has no embedding.
+func (db *DB) GetPersonEmbedding(ctx context.Context, graphNodeID int) ([]float32, error) {
+	var embText sql.NullString
+	err := db.q().QueryRowContext(ctx, `
+		SELECT embedding::text
+		FROM graph_nodes
+		WHERE id = $1 AND org_id = $2 AND node_type = 'person' AND embedding IS NOT NULL AND deleted_at IS NULL
+	`, graphNodeID, tenant.OrgID(ctx)).Scan(&embText)
+	if err == sql.ErrNoRows || !embText.Valid {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get person embedding: %w", err)
+	}
+
+	// pgvector text format: "[0.1,0.2,...]"
+	text := strings.TrimPrefix(strings.TrimSuffix(strings.TrimSpace(embText.String), "]"), "[")
+	parts := strings.Split(text, ",")
+	result := make([]float32, 0, len(parts))
+	for _, p := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 32)
+		if err != nil {
+			continue
+		}
+		result = append(result, float32(v))
+	}
+	return result, nil
+}
+
+// GetCandidatesByPersonNodeIDs returns lightweight candidate info for the given person node_ids
+// (e.g. "person_2"). Used to enrich similar-candidate results with DB data.
+// excludeNodeID is filtered out from results (the source candidate).
+func (db *DB) GetCandidatesByPersonNodeIDs(ctx context.Context, personNodeIDs []string, excludeNodeID string, similarities map[string]float64) ([]SimilarCandidate, error) {
+	if len(personNodeIDs) == 0 {
+		return []SimilarCandidate{}, nil
+	}
+
+	placeholders := make([]string, len(personNodeIDs))
+	args := make([]interface{}, len(personNodeIDs))
+	for i, id := range personNodeIDs {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = id
+	}
+	inClause := strings.Join(placeholders, ",")
+
+	query := fmt.Sprintf(`
+		SELECT c.id, c.name,
+		       COALESCE(gn.properties->>'current_position', '') AS current_position,
+		       COALESCE(gn.properties->>'seniority', '')        AS seniority,
+		       c.status, gn.node_id
+		FROM candidates c
+		JOIN graph_nodes gn ON gn.id = c.graph_node_id
+		WHERE gn.node_id IN (%s)
+		  AND gn.node_id <> $%d
+		  AND gn.org_id = $%d
+		  AND c.deleted_at IS NULL
+	`, inClause, len(personNodeIDs)+1, len(personNodeIDs)+2)
+	args = append(args, excludeNodeID, tenant.OrgID(ctx))
+
+	rows, err := db.r().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("get candidates by node ids: %w", err)
+	}
+	defer rows.Close()
+
+	var results []SimilarCandidate
+	nodeIDs := make([]int, 0)
+	candidateNodeMap := make(map[int]int) // candidateID -> index in results
+
+	for rows.Next() {
+		var sc SimilarCandidate
+		var nodeID string
+		if err := rows.Scan(&sc.CandidateID, &sc.Name, &sc.CurrentPosition, &sc.Seniority, &sc.Status, &nodeID); err != nil {
+			continue
+		}
+		sc.PersonID = nodeID
+		sc.Similarity = similarities[nodeID]
+		_ = nodeIDs
+		candidateNodeMap[sc.CandidateID] = len(results)
+		results = append(results, sc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Enrich with top 5 skills per candidate
+	if len(results) > 0 {
+		candidateIDs := make([]string, len(results))
+		for i, r := range results {
+			candidateIDs[i] = fmt.Sprintf("$%d", i+1)
+			_ = r
+		}
+		idArgs := make([]interface{}, len(results))
+		for i, r := range results {
+			idArgs[i] = r.CandidateID
+		}
+
+		skillRows, err := db.r().QueryContext(ctx, fmt.Sprintf(`
+			SELECT c.id, s.properties->>'name'
+			FROM candidates c
+			JOIN graph_nodes gn ON gn.id = c.graph_node_id
+			JOIN graph_edges ge ON ge.source_node_id = gn.id
+			JOIN graph_nodes s  ON s.id = ge.target_node_id
+			WHERE c.id IN (%s)
+			  AND ge.edge_type = 'HAS_SKILL'
+			  AND s.node_type = 'skill'
+			ORDER BY c.id, ge.id
+		`, strings.Join(candidateIDs, ",")), idArgs...)
+		if err == nil {
+			defer skillRows.Close()
+			skillMap := make(map[int][]string)
+			for skillRows.Next() {
+				var cid int
+				var skill string
+				if err := skillRows.Scan(&cid, &skill); err == nil && skill != "" {
+					if len(skillMap[cid]) < 5 {
+						skillMap[cid] = append(skillMap[cid], skill)
+					}
+				}
+			}
+			for i := range results {
+				results[i].TopSkills = skillMap[results[i].CandidateID]
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// GetGraphOverlap finds the people who share the most skills, companies and
+// computed communities with the given person node (integer graph_nodes.id).
+// At most limit peers are returned, ranked by number of shared neighbours.
+func (db *DB) GetGraphOverlap(ctx context.Context, graphNodeID, limit int) (*GraphOverlapResult, error) {
+	res := &GraphOverlapResult{Peers: make(map[string]*GraphOverlap)}
+
+	err := db.r().QueryRowContext(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM graph_edges WHERE source_node_id = $1 AND edge_type = 'HAS_SKILL'),
+			(SELECT COUNT(DISTINCT target_node_id) FROM graph_edges WHERE source_node_id = $1 AND edge_type IN ('WORKS_AT', 'WORKED_AT')),
+			(SELECT COUNT(*) FROM community_members WHERE node_id = $1)
+	`, graphNodeID).Scan(&res.SourceSkills, &res.SourceCompanies, &res.SourceCommunities)
+	if err != nil {
+		return nil, fmt.Errorf("count source neighbours: %w", err)
+	}
+
+	rows, err := db.r().QueryContext(ctx, `
+		WITH shared AS (
+			SELECT DISTINCT o.node_id AS person, t.node_type AS kind, COALESCE(t.properties->>'name', '') AS name
+			FROM graph_edges se
+			JOIN graph_edges oe ON oe.target_node_id = se.target_node_id AND oe.source_node_id <> se.source_node_id
+			JOIN graph_nodes t  ON t.id = se.target_node_id
+			JOIN graph_nodes o  ON o.id = oe.source_node_id AND o.node_type = 'person' AND o.deleted_at IS NULL
+			WHERE se.source_node_id = $1
+			  AND se.edge_type IN ('HAS_SKILL', 'WORKS_AT', 'WORKED_AT')
+			  AND oe.edge_type IN ('HAS_SKILL', 'WORKS_AT', 'WORKED_AT')
+			UNION
+			SELECT o.node_id, 'community', COALESCE(gc.title, gc.community_id)
+			FROM community_members sm
+			JOIN community_members om ON om.community_id = sm.community_id AND om.node_id <> sm.node_id
+			JOIN graph_communities gc ON gc.id = sm.community_id
+			JOIN graph_nodes o ON o.id = om.node_id AND o.node_type = 'person' AND o.deleted_at IS NULL
+			WHERE sm.node_id = $1
+		),
+		top AS (
+			SELECT person FROM shared GROUP BY person ORDER BY COUNT(*) DESC LIMIT $2
+		)
+		SELECT s.person, s.kind, s.name
+		FROM shared s
+		JOIN top ON top.person = s.person
+	`, graphNodeID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("get graph overlap: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var person, kind, name string
+		if err := rows.Scan(&person, &kind, &name); err != nil {
+			return nil, fmt.Errorf("scan graph overlap: %w", err)
+		}
+		peer := res.Peers[person]
+		if peer == nil {
+			peer = &GraphOverlap{}
+			res.Peers[person] = peer
+		}
+		switch kind {
+		case "skill":
+			peer.SharedSkills = append(peer.SharedSkills, name)
+		case "company":
+			peer.SharedCompanies = append(peer.SharedCompanies, name)
+		case "community":
+			peer.SharedCommunities = append(peer.SharedCommunities, name)
+		}
+	}
+	return res, rows.Err()
+}
+
+// GetPersonNodeIDString returns the string node_id (e.g. "person_2") for an integer
+// graph_node_id that is stored on the candidates table. Returns "" if not found.
+func (db *DB) GetPersonNodeIDString(ctx context.Context, graphNodeID int) (string, error) {
+	var nodeID string
+	err := db.q().QueryRowContext(ctx, `
+		SELECT node_id FROM graph_nodes WHERE id = $1 AND org_id = $2 AND node_type = 'person' LIMIT 1
+	`, graphNodeID, tenant.OrgID(ctx)).Scan(&nodeID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("get person node_id string: %w", err)
+	}
+	return nodeID, nil
+}
@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ─── Snapshots ───────────────────────────────────────────────────────────────
+//
+// A snapshot is every row of SnapshotTables as JSON objects (to_jsonb), read
+// in one repeatable-read transaction and restored with
+// json_populate_recordset, so IDs, embeddings and timestamps come back as they
+// were. Jobs, logs, sessions and audit rows aren't part of it; blob contents
+// aren't either (cv_files keeps the keys). Neither are organizations: rows
+// keep their org_id, so the target must already have the same orgs. See
+// internal/snapshot for the archive format.
+
+// SnapshotTable is one table in a snapshot.
+type SnapshotTable struct {
+	Name string
+	// key orders the export; tables keyed by id get their sequence reset
+	// after a restore.
+	key string
+	// cleared columns are exported as null because they point at rows a
+	// snapshot doesn't hold.
+	cleared []string
+}
+
+// SnapshotTables lists the snapshot tables, parents before children, which
+// is the order they are restored in.
+var SnapshotTables = []SnapshotTable{
+	{Name: "candidates", key: "id"},
+	{Name: "skills", key: "id"},
+	{Name: "candidate_skills", key: "candidate_id, skill_id"},
+	{Name: "interviews", key: "id"},
+	{Name: "candidate_notes", key: "id"},
+	{Name: "candidate_tags", key: "candidate_id, tag"},
+	{Name: "talent_pools", key: "id"},
+	{Name: "talent_pool_members", key: "pool_id, candidate_id"},
+	{Name: "candidate_pushes", key: "id"},
+	{Name: "cv_files", key: "id", cleared: []string{"job_id"}},
+	{Name: "cv_entities", key: "id"},
+	{Name: "cv_chunks", key: "id"},
+	{Name: "graph_nodes", key: "id"},
+	{Name: "graph_edges", key: "id"},
+	{Name: "graph_communities", key: "id"},
+	{Name: "community_members", key: "id"},
+}
+
+// BeginSnapshotRead makes the transaction db is bound to (see WithTx) a
+// read-only repeatable-read one, so every table is exported as of the same
+// moment. It must be the first statement of the transaction.
+func (db *DB) BeginSnapshotRead(ctx context.Context) error {
+	if db.tx == nil {
+		return fmt.Errorf("snapshot read needs a transaction")
+	}
+	if _, err := db.tx.ExecContext(ctx, `SET TRANSACTION ISOLATION LEVEL REPEATABLE READ, READ ONLY`); err != nil {
+		return fmt.Errorf("set snapshot isolation: %w", err)
+	}
+	return nil
+}
+
+// ExportSnapshotTable calls fn with every row of t as a JSON object and
+// returns the number of rows.
+func (db *DB) ExportSnapshotTable(ctx context.Context, t SnapshotTable, fn func(row []byte) error) (int, error) {
+	expr := "to_jsonb(t)"
+	if len(t.cleared) > 0 {
+		pairs := make([]string, len(t.cleared))
+		for i, col := range t.cleared {
+			pairs[i] = fmt.Sprintf("'%s', NULL", col)
+		}
+		expr = fmt.Sprintf("(%s || jsonb_build_object(%s))", expr, strings.Join(pairs, ", "))
+	}
+	rows, err := db.q().QueryContext(ctx, fmt.Sprintf(`SELECT %s::text FROM %s t ORDER BY %s`, expr, t.Name, t.key))
+	if err != nil {
+		return 0, fmt.Errorf("export %s: %w", t.Name, err)
+	}
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		var row []byte
+		if err := rows.Scan(&row); err != nil {
+			return n, fmt.Errorf("export %s: %w", t.Name, err)
+		}
+		if err := fn(row); err != nil {
+			return n, err
+		}
+		n++
+	}
+	if err := rows.Err(); err != nil {
+		return n, fmt.Errorf("export %s: %w", t.Name, err)
+	}
+	return n, nil
+}
+
+// ImportSnapshotRows inserts rows (JSON objects from ExportSnapshotTable)
+// into t as they are, IDs included. Keys missing from a row are stored as
+// NULL rather than the column default, so the rows must come from the same
+// schema version.
+func (db *DB) ImportSnapshotRows(ctx context.Context, t SnapshotTable, rows [][]byte) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	batch := append(append([]byte{'['}, bytes.Join(rows, []byte{','})...), ']')
+	if _, err := db.q().ExecContext(ctx,
+		fmt.Sprintf(`INSERT INTO %[1]s SELECT * FROM json_populate_recordset(NULL::%[1]s, $1::json)`, t.Name),
+		string(batch),
+	); err != nil {
+		return fmt.Errorf("import %s: %w", t.Name, err)
+	}
+	return nil
+}
+
+// SnapshotRowCount returns how many rows the snapshot tables hold in total.
+func (db *DB) SnapshotRowCount(ctx context.Context) (int64, error) {
+	counts := make([]string, len(SnapshotTables))
+	for i, t := range SnapshotTables {
+		counts[i] = fmt.Sprintf("(SELECT COUNT(*) FROM %s)", t.Name)
+	}
+	var n int64
+	if err := db.q().QueryRowContext(ctx, "SELECT "+strings.Join(counts, " + ")).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count snapshot rows: %w", err)
+	}
+	return n, nil
+}
+
+// TruncateSnapshotTables empties the snapshot tables and, through CASCADE,
+// the tables that reference them (upload jobs, scores, merges).
+func (db *DB) TruncateSnapshotTables(ctx context.Context) error {
+	names := make([]string, len(SnapshotTables))
+	for i, t := range SnapshotTables {
+		names[i] = t.Name
+	}
+	if _, err := db.q().ExecContext(ctx,
+		"TRUNCATE "+strings.Join(names, ", ")+" RESTART IDENTITY CASCADE",
+	); err != nil {
+		return fmt.Errorf("truncate snapshot tables: %w", err)
+	}
+	return nil
+}
+
+// ResetSnapshotSequences moves each id sequence past the restored rows so
+// new inserts don't collide with them.
+func (db *DB) ResetSnapshotSequences(ctx context.Context) error {
+	for _, t := range SnapshotTables {
+		if t.key != "id" {
+			continue
+		}
+		if _, err := db.q().ExecContext(ctx, fmt.Sprintf(
+			`SELECT setval(pg_get_serial_sequence('%[1]s', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM %[1]s`, t.Name,
+		)); err != nil {
+			return fmt.Errorf("reset %s sequence: %w", t.Name, err)
+		}
+	}
+	return nil
+}
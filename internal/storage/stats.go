@@ -0,0 +1,341 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"cv-search/internal/tenant"
+)
+
+// ─── Dashboard statistics ────────────────────────────────────────────────────
+//
+// Reads come from the stats_* materialized views (migration 00007, per
+// organization since 00020), so they are as fresh as the last
+// RefreshStatsViews call rather than live.
+
+// statsViews lists the materialized views RefreshStatsViews rebuilds.
+var statsViews = []string{
+	"stats_node_counts",
+	"stats_edge_counts",
+	"stats_skill_popularity",
+	"stats_skill_trend",
+	"stats_skill_mentions",
+	"stats_seniority",
+	"stats_community_sizes",
+	"stats_uploads_weekly",
+}
+
+// RefreshStatsViews rebuilds every statistics view. CONCURRENTLY keeps the
+// old contents readable while a view is rebuilt. Runs on the primary.
+func (db *DB) RefreshStatsViews(ctx context.Context) error {
+	for _, v := range statsViews {
+		if _, err := db.q().ExecContext(ctx, `REFRESH MATERIALIZED VIEW CONCURRENTLY `+v); err != nil {
+			return fmt.Errorf("refresh %s: %w", v, err)
+		}
+	}
+	if _, err := db.q().ExecContext(ctx, `
+		INSERT INTO stats_refreshes (name, refreshed_at) VALUES ('dashboard', NOW())
+		ON CONFLICT (name) DO UPDATE SET refreshed_at = EXCLUDED.refreshed_at
+	`); err != nil {
+		return fmt.Errorf("record stats refresh: %w", err)
+	}
+	return nil
+}
+
+// StatsRefreshedAt returns when the statistics views were last refreshed, or
+// nil if that was never recorded.
+func (db *DB) StatsRefreshedAt(ctx context.Context) (*time.Time, error) {
+	var t time.Time
+	err := db.r().QueryRowContext(ctx,
+		`SELECT refreshed_at FROM stats_refreshes WHERE name = 'dashboard'`,
+	).Scan(&t)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get stats refresh time: %w", err)
+	}
+	return &t, nil
+}
+
+// GetGraphStats returns live node counts per type and edge counts per type.
+func (db *DB) GetGraphStats(ctx context.Context) (*GraphStats, error) {
+	stats := &GraphStats{NodeTypes: map[string]int{}, EdgeTypes: map[string]int{}}
+
+	for _, q := range []struct {
+		query string
+		dst   map[string]int
+		total *int
+	}{
+		{`SELECT node_type, node_count FROM stats_node_counts WHERE org_id = $1`, stats.NodeTypes, &stats.TotalNodes},
+		{`SELECT edge_type, edge_count FROM stats_edge_counts WHERE org_id = $1`, stats.EdgeTypes, &stats.TotalEdges},
+	} {
+		rows, err := db.r().QueryContext(ctx, q.query, tenant.OrgID(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("graph stats: %w", err)
+		}
+		for rows.Next() {
+			var typ string
+			var n int
+			if err := rows.Scan(&typ, &n); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("scan graph stats: %w", err)
+			}
+			q.dst[typ] = n
+			*q.total += n
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("graph stats: %w", err)
+		}
+	}
+	return stats, nil
+}
+
+// GetPopularSkills returns the skills held by the most live candidates.
+func (db *DB) GetPopularSkills(ctx context.Context, limit int) ([]SkillCount, error) {
+	rows, err := db.r().QueryContext(ctx, `
+		SELECT skill, candidate_count
+		FROM stats_skill_popularity
+		WHERE org_id = $2
+		ORDER BY candidate_count DESC, skill
+		LIMIT $1
+	`, limit, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("popular skills: %w", err)
+	}
+	defer rows.Close()
+
+	skills := []SkillCount{}
+	for rows.Next() {
+		var s SkillCount
+		if err := rows.Scan(&s.Skill, &s.Count); err != nil {
+			return nil, fmt.Errorf("scan popular skill: %w", err)
+		}
+		skills = append(skills, s)
+	}
+	return skills, rows.Err()
+}
+
+// GetSkillTrend returns monthly new-candidate counts per skill since the
+// given month. With names, only those skills (case-insensitive) are
+// returned; otherwise the top skills over the period, up to limit.
+func (db *DB) GetSkillTrend(ctx context.Context, since time.Time, names []string, limit int) ([]SkillTrendPoint, error) {
+	var rows *sql.Rows
+	var err error
+	if len(names) > 0 {
+		lowered := make([]string, len(names))
+		for i, n := range names {
+			lowered[i] = strings.ToLower(strings.TrimSpace(n))
+		}
+		rows, err = db.r().QueryContext(ctx, `
+			SELECT month, skill, candidate_count
+			FROM stats_skill_trend
+			WHERE org_id = $3 AND month >= $1 AND lower(skill) = ANY($2)
+			ORDER BY month, skill
+		`, since, lowered, tenant.OrgID(ctx))
+	} else {
+		rows, err = db.r().QueryContext(ctx, `
+			WITH top AS (
+				SELECT skill FROM stats_skill_trend
+				WHERE org_id = $3 AND month >= $1
+				GROUP BY skill
+				ORDER BY SUM(candidate_count) DESC, skill
+				LIMIT $2
+			)
+			SELECT month, skill, candidate_count
+			FROM stats_skill_trend
+			WHERE org_id = $3 AND month >= $1 AND skill IN (SELECT skill FROM top)
+			ORDER BY month, skill
+		`, since, limit, tenant.OrgID(ctx))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("skill trend: %w", err)
+	}
+	defer rows.Close()
+
+	points := []SkillTrendPoint{}
+	for rows.Next() {
+		var p SkillTrendPoint
+		var month time.Time
+		if err := rows.Scan(&month, &p.Skill, &p.Count); err != nil {
+			return nil, fmt.Errorf("scan skill trend: %w", err)
+		}
+		p.Month = month.Format("2006-01")
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
+
+// GetSeniorityDistribution returns live candidates per seniority level;
+// candidates without one are counted as "unknown".
+func (db *DB) GetSeniorityDistribution(ctx context.Context) ([]SeniorityCount, error) {
+	rows, err := db.r().QueryContext(ctx, `
+		SELECT seniority, candidate_count
+		FROM stats_seniority
+		WHERE org_id = $1
+		ORDER BY candidate_count DESC, seniority
+	`, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("seniority distribution: %w", err)
+	}
+	defer rows.Close()
+
+	out := []SeniorityCount{}
+	for rows.Next() {
+		var s SeniorityCount
+		if err := rows.Scan(&s.Seniority, &s.Count); err != nil {
+			return nil, fmt.Errorf("scan seniority: %w", err)
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+// GetCommunitySizes returns detected communities by live person members,
+// largest first.
+func (db *DB) GetCommunitySizes(ctx context.Context, limit int) ([]CommunitySize, error) {
+	rows, err := db.r().QueryContext(ctx, `
+		SELECT id, level, community_id, title, member_count
+		FROM stats_community_sizes
+		WHERE org_id = $2
+		ORDER BY member_count DESC, id
+		LIMIT $1
+	`, limit, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("community sizes: %w", err)
+	}
+	defer rows.Close()
+
+	out := []CommunitySize{}
+	for rows.Next() {
+		var c CommunitySize
+		if err := rows.Scan(&c.ID, &c.Level, &c.CommunityID, &c.Title, &c.Members); err != nil {
+			return nil, fmt.Errorf("scan community size: %w", err)
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// GetWeeklyUploads returns CV uploads per week since the given date, oldest
+// first. Weeks without uploads are omitted.
+func (db *DB) GetWeeklyUploads(ctx context.Context, since time.Time) ([]WeeklyUploads, error) {
+	rows, err := db.r().QueryContext(ctx, `
+		SELECT week, uploads, candidates
+		FROM stats_uploads_weekly
+		WHERE org_id = $2 AND week >= $1
+		ORDER BY week
+	`, since, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("weekly uploads: %w", err)
+	}
+	defer rows.Close()
+
+	out := []WeeklyUploads{}
+	for rows.Next() {
+		var u WeeklyUploads
+		var week time.Time
+		if err := rows.Scan(&week, &u.Uploads, &u.Candidates); err != nil {
+			return nil, fmt.Errorf("scan weekly uploads: %w", err)
+		}
+		u.Week = week.Format("2006-01-02")
+		out = append(out, u)
+	}
+	return out, rows.Err()
+}
+
+// GetTrendingSkills compares the people mentioning each skill in the
+// months [from, to) with the same number of months before from, and
+// returns the fastest growing skills with at least minMentions in the
+// current period (falling: the fastest shrinking, with at least minMentions
+// in the previous one), at most limit. Skills new in the current period
+// count as growing fastest.
+func (db *DB) GetTrendingSkills(ctx context.Context, from, to time.Time, minMentions int, falling bool, limit int) ([]TrendingSkill, error) {
+	previousFrom := from.AddDate(0, -monthsBetween(from, to), 0)
+	rows, err := db.r().QueryContext(ctx, `
+		WITH w AS (
+			SELECT skill,
+			       COALESCE(SUM(mentions) FILTER (WHERE month >= $2), 0)::int AS mentions,
+			       COALESCE(SUM(mentions) FILTER (WHERE month < $2), 0)::int AS previous_mentions
+			FROM stats_skill_mentions
+			WHERE org_id = $1 AND month >= $3 AND month < $4
+			GROUP BY skill
+		),
+		seen AS (
+			SELECT properties->>'name' AS skill, MIN(created_at) AS first_seen, MAX(last_seen_at) AS last_seen
+			FROM graph_nodes
+			WHERE org_id = $1 AND node_type = 'skill' AND deleted_at IS NULL
+			GROUP BY 1
+		)
+		SELECT w.skill, w.mentions, w.previous_mentions, seen.first_seen, seen.last_seen
+		FROM w
+		LEFT JOIN seen ON seen.skill = w.skill
+		WHERE CASE WHEN $5 THEN w.previous_mentions ELSE w.mentions END >= $6
+	`, tenant.OrgID(ctx), from, previousFrom, to, falling, minMentions)
+	if err != nil {
+		return nil, fmt.Errorf("trending skills: %w", err)
+	}
+	defer rows.Close()
+
+	skills := []TrendingSkill{}
+	for rows.Next() {
+		var s TrendingSkill
+		var firstSeen, lastSeen sql.NullTime
+		if err := rows.Scan(&s.Skill, &s.Mentions, &s.PreviousMentions, &firstSeen, &lastSeen); err != nil {
+			return nil, fmt.Errorf("scan trending skill: %w", err)
+		}
+		if firstSeen.Valid {
+			s.FirstSeen = &firstSeen.Time
+		}
+		if lastSeen.Valid {
+			s.LastSeen = &lastSeen.Time
+		}
+		if s.PreviousMentions > 0 {
+			g := math.Round(float64(s.Mentions-s.PreviousMentions)/float64(s.PreviousMentions)*1000) / 10
+			s.GrowthPercent = &g
+		} else {
+			s.New = true
+		}
+		skills = append(skills, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("trending skills: %w", err)
+	}
+
+	// growth orders skills by how much they grew; a new skill grew the most.
+	growth := func(s TrendingSkill) float64 {
+		if s.GrowthPercent == nil {
+			return math.Inf(1)
+		}
+		return *s.GrowthPercent
+	}
+	sort.Slice(skills, func(i, j int) bool {
+		a, b := skills[i], skills[j]
+		if growth(a) != growth(b) {
+			if falling {
+				return growth(a) < growth(b)
+			}
+			return growth(a) > growth(b)
+		}
+		if a.Mentions != b.Mentions {
+			return a.Mentions > b.Mentions
+		}
+		return a.Skill < b.Skill
+	})
+	if len(skills) > limit {
+		skills = skills[:limit]
+	}
+	return skills, nil
+}
+
+// monthsBetween returns the number of calendar months from from to to, both
+// first days of a month.
+func monthsBetween(from, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()-from.Month())
+}
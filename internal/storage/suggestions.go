@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cv-search/internal/tenant"
+)
+
+// SuggestFromGraph returns autocomplete suggestions matching the given prefix.
+// Searches skill and company node names, and person node current positions.
+// Deduplicates and caps the result at limit.
+func (db *DB) SuggestFromGraph(ctx context.Context, prefix string, limit int) ([]SuggestionResult, error) {
+	if prefix == "" || limit <= 0 {
+		return []SuggestionResult{}, nil
+	}
+	pattern := prefix + "%"
+
+	rows, err := db.r().QueryContext(ctx, `
+		(
+			SELECT properties->>'name' AS text, 'skill' AS type
+			FROM graph_nodes
+			WHERE node_type = 'skill' AND org_id = $3
+			  AND properties->>'name' ILIKE $1
+			  AND properties->>'name' IS NOT NULL
+			LIMIT $2
+		)
+		UNION
+		(
+			SELECT properties->>'name' AS text, 'company' AS type
+			FROM graph_nodes
+			WHERE node_type = 'company' AND org_id = $3
+			  AND properties->>'name' ILIKE $1
+			  AND properties->>'name' IS NOT NULL
+			LIMIT $2
+		)
+		UNION
+		(
+			SELECT DISTINCT properties->>'current_position' AS text, 'position' AS type
+			FROM graph_nodes
+			WHERE node_type = 'person' AND org_id = $3
+			  AND properties->>'current_position' ILIKE $1
+			  AND properties->>'current_position' IS NOT NULL
+			LIMIT $2
+		)
+		LIMIT $2
+	`, pattern, limit, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("suggest query: %w", err)
+	}
+	defer rows.Close()
+
+	seen := make(map[string]struct{})
+	var results []SuggestionResult
+	for rows.Next() {
+		var s SuggestionResult
+		if err := rows.Scan(&s.Text, &s.Type); err != nil {
+			continue
+		}
+		key := strings.ToLower(s.Text)
+		if _, dup := seen[key]; dup {
+			continue
+		}
+		seen[key] = struct{}{}
+		results = append(results, s)
+	}
+	return results, rows.Err()
+}
+
+// GetTopSkillsForQueries returns the most common skill names (by HAS_SKILL edge count)
+// and the most common seniority levels, for use in generating popular query suggestions.
+func (db *DB) GetTopSkillsForQueries(ctx context.Context, skillLimit, seniorityLimit int) (skills []string, seniorities []string, err error) {
+	// Top skills by popularity (most candidates have them)
+	skillRows, err := db.r().QueryContext(ctx, `
+		SELECT s.properties->>'name' AS skill_name
+		FROM graph_edges ge
+		JOIN graph_nodes s ON ge.target_node_id = s.id
+		WHERE ge.edge_type = 'HAS_SKILL' AND ge.org_id = $2
+		  AND s.node_type = 'skill'
+		  AND s.properties->>'name' IS NOT NULL
+		GROUP BY skill_name
+		ORDER BY COUNT(*) DESC
+		LIMIT $1
+	`, skillLimit, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, nil, fmt.Errorf("top skills query: %w", err)
+	}
+	defer skillRows.Close()
+	for skillRows.Next() {
+		var name string
+		if err := skillRows.Scan(&name); err == nil && name != "" {
+			skills = append(skills, name)
+		}
+	}
+
+	// Top seniority levels
+	senRows, err := db.r().QueryContext(ctx, `
+		SELECT properties->>'seniority', COUNT(*) AS cnt
+		FROM graph_nodes
+		WHERE node_type = 'person' AND org_id = $2
+		  AND properties->>'seniority' IS NOT NULL
+		  AND properties->>'seniority' != ''
+		GROUP BY properties->>'seniority'
+		ORDER BY cnt DESC
+		LIMIT $1
+	`, seniorityLimit, tenant.OrgID(ctx))
+	if err != nil {
+		return skills, nil, fmt.Errorf("seniority query: %w", err)
+	}
+	defer senRows.Close()
+	for senRows.Next() {
+		var s string
+		var cnt int
+		if err := senRows.Scan(&s, &cnt); err == nil && s != "" {
+			seniorities = append(seniorities, s)
+		}
+	}
+	return skills, seniorities, nil
+}
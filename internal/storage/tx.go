@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"cv-search/internal/tenant"
+)
+
+// ─── Transactions ────────────────────────────────────────────────────────────
+
+// querier is the subset of *sql.DB / *sql.Tx the storage methods use.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// q returns the open transaction when db is bound to one (inside WithTx),
+// otherwise the pool.
+func (db *DB) q() querier {
+	if db.tx != nil {
+		return db.tx
+	}
+	return db.connection
+}
+
+// InTx reports whether db is bound to a transaction.
+func (db *DB) InTx() bool { return db.tx != nil }
+
+// WithTx runs fn as one unit of work. fn receives a *DB bound to the
+// transaction, so every method called on it — including ones that open their
+// own transaction, like SyncCandidateTextFields — joins the same transaction.
+// It commits when fn returns nil and rolls back on error or panic. Calling
+// WithTx on an already-bound DB just runs fn in the outer transaction.
+//
+// GetConnection still returns the pool; raw queries made through it inside
+// fn are not part of the transaction.
+func (db *DB) WithTx(ctx context.Context, fn func(tx *DB) error) (err error) {
+	if db.tx != nil {
+		return fn(db)
+	}
+
+	tx, err := db.connection.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err = fn(&DB{connection: db.connection, reads: db.reads, tx: tx}); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+// SaveCVFileWithJob stores an uploaded CV and creates its pending processing
+// job atomically, so a failed job insert doesn't leave an orphaned cv_files
+// row that later uploads would report as a duplicate. candidateID may be nil
+// (the candidate is then found or created after extraction).
+func (db *DB) SaveCVFileWithJob(ctx context.Context, candidateID *int, filename, filePath, fileType, parsedText string, fileSize int64, contentHash string) (cvID int, jobID int64, err error) {
+	err = db.WithTx(ctx, func(tx *DB) error {
+		var txErr error
+		if cvID, txErr = tx.SaveCVFileWithHash(ctx, candidateID, filename, filePath, fileType, parsedText, fileSize, contentHash); txErr != nil {
+			return txErr
+		}
+		jobID, txErr = tx.CreateCVUploadJob(ctx, int64(cvID))
+		return txErr
+	})
+	return cvID, jobID, err
+}
+
+// LinkCandidateToCV connects a CV to its candidate and person node in one
+// transaction: the candidate the CV was saved for (imports), else the one
+// with the CV's email address, else the one for the person node, else a new
+// one. It fills the candidate's missing contact fields, points the CV file
+// at it and syncs the BM25 text fields from the node. graphNodeID may be 0
+// when no person node was built; the CV is then linked by email only, and
+// left unlinked without one. Returns the candidate ID (0 if unlinked).
+func (db *DB) LinkCandidateToCV(ctx context.Context, cvFileID int64, graphNodeID int, name string, contact CandidateContact) (int, error) {
+	var candidateID int
+	err := db.WithTx(ctx, func(tx *DB) error {
+		var txErr error
+		if graphNodeID > 0 {
+			if candidateID, txErr = tx.claimCVFileCandidate(ctx, cvFileID, graphNodeID); txErr != nil {
+				return txErr
+			}
+		}
+		if candidateID == 0 && contact.Email != "" {
+			if candidateID, txErr = tx.claimCandidateByEmail(ctx, contact.Email, graphNodeID); txErr != nil {
+				return txErr
+			}
+		}
+		if candidateID == 0 {
+			switch {
+			case graphNodeID > 0:
+				candidateID, txErr = tx.UpsertCandidateForGraphNode(ctx, graphNodeID, name)
+			case contact.Email != "":
+				candidateID, txErr = tx.insertCandidateForContact(ctx, name, contact)
+			default:
+				return nil
+			}
+			if txErr != nil {
+				return txErr
+			}
+		}
+		if txErr = tx.fillCandidateContact(ctx, candidateID, contact); txErr != nil {
+			return txErr
+		}
+		if txErr = tx.UpdateCVFileCandidateID(ctx, cvFileID, candidateID); txErr != nil {
+			return fmt.Errorf("link cv_file to candidate: %w", txErr)
+		}
+		if graphNodeID > 0 {
+			if txErr = tx.SyncCandidateTextFields(ctx, candidateID, graphNodeID); txErr != nil {
+				return fmt.Errorf("sync candidate text fields: %w", txErr)
+			}
+			if txErr = tx.setPersonNodeCandidate(ctx, graphNodeID, candidateID); txErr != nil {
+				return txErr
+			}
+		}
+		return nil
+	})
+	return candidateID, err
+}
+
+// setPersonNodeCandidate records the candidate a person node was linked to
+// and its status in the node's candidate_id and status properties (see
+// graphrag.PersonProperties).
+func (db *DB) setPersonNodeCandidate(ctx context.Context, graphNodeID, candidateID int) error {
+	if _, err := db.q().ExecContext(ctx, `
+		UPDATE graph_nodes SET properties = COALESCE(properties, '{}'::jsonb)
+			|| jsonb_build_object('candidate_id', $2::int, 'status', (SELECT status FROM candidates WHERE id = $2))
+		WHERE id = $1 AND org_id = $3
+	`, graphNodeID, candidateID, tenant.OrgID(ctx)); err != nil {
+		return fmt.Errorf("set candidate_id of person node %d: %w", graphNodeID, err)
+	}
+	return nil
+}
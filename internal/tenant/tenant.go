@@ -0,0 +1,30 @@
+// Package tenant carries the organization a request acts for through
+// context.Context, so storage and the graphrag engines can scope their
+// queries by org_id (migrations/00020_organizations.sql) without an org
+// parameter on every method.
+//
+// The API's org middleware sets it from the caller's API key; background
+// workers set it from the job they run. A context without an org belongs to
+// DefaultOrgID, the organization every pre-existing row was assigned to, so
+// single-team deployments and the CLI tools keep working unchanged.
+package tenant
+
+import "context"
+
+// DefaultOrgID is the organization seeded by the migration.
+const DefaultOrgID = 1
+
+type orgKey struct{}
+
+// WithOrg returns a copy of ctx scoped to the organization orgID.
+func WithOrg(ctx context.Context, orgID int) context.Context {
+	return context.WithValue(ctx, orgKey{}, orgID)
+}
+
+// OrgID returns the organization ctx is scoped to, DefaultOrgID if none.
+func OrgID(ctx context.Context) int {
+	if id, ok := ctx.Value(orgKey{}).(int); ok && id > 0 {
+		return id
+	}
+	return DefaultOrgID
+}
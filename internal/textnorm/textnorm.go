@@ -0,0 +1,206 @@
+// Package textnorm normalizes CV text, Turkish in particular, so mixed
+// Turkish/English CVs tokenize and extract the same way whatever encoding,
+// casing or conventions they were written with.
+//
+//   - Repair undoes broken encodings (UTF-8 read as Windows-1252, Turkish
+//     ISO-8859-9 read as Latin-1) and composes decomposed diacritics. It runs
+//     on every parsed CV.
+//   - Normalize is Repair plus the rewrites that help the LLM: Turkish month
+//     names in dates ("Ocak 2020" → "January 2020", "– Halen" → "– Present")
+//     and English glosses after Turkish degree names. It runs on the
+//     extraction prompt's CV text.
+//   - Fold is the search key: lower case with Turkish letters folded to ASCII
+//     (İ, I, ı → i; ş → s; ...). BM25 applies it to the query; the tr_fold
+//     SQL function (migrations/00016_turkish_text_fold.sql) is its twin on the
+//     indexed side.
+package textnorm
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// mojibake maps Turkish letters encoded as UTF-8 but decoded as
+// Windows-1252 (or Latin-1, whose C1 controls sit where Windows-1252 has
+// punctuation) back to the letter.
+var mojibake = func() *strings.Replacer {
+	var pairs []string
+	for _, r := range "çğıöşüÇĞİÖŞÜâîûÂÎÛ" {
+		var buf [4]byte
+		n := utf8.EncodeRune(buf[:], r)
+		latin1 := make([]rune, n)
+		cp1252 := make([]rune, n)
+		for i, b := range buf[:n] {
+			latin1[i] = rune(b)
+			cp1252[i] = windows1252(b)
+		}
+		pairs = append(pairs, string(cp1252), string(r))
+		if string(latin1) != string(cp1252) {
+			pairs = append(pairs, string(latin1), string(r))
+		}
+	}
+	return strings.NewReplacer(pairs...)
+}()
+
+// windows1252 decodes one Windows-1252 byte; only the 0x80-0x9F range
+// differs from Latin-1, and only the bytes that occur in UTF-8 Turkish
+// letters are listed.
+func windows1252(b byte) rune {
+	switch b {
+	case 0x87:
+		return '‡'
+	case 0x96:
+		return '–'
+	case 0x9C:
+		return 'œ'
+	case 0x9E:
+		return 'ž'
+	case 0x9F:
+		return 'Ÿ'
+	}
+	return rune(b)
+}
+
+// latin5 maps the letters where ISO-8859-9 (Turkish) differs from Latin-1,
+// as they appear when a Turkish document is decoded as Latin-1.
+var latin5 = strings.NewReplacer("ý", "ı", "þ", "ş", "ð", "ğ", "Ý", "İ", "Þ", "Ş", "Ð", "Ğ")
+
+// minLatin5Letters is how many ý/þ/ð a text needs before it's taken to be
+// misdecoded Turkish rather than, say, an Icelandic name.
+const minLatin5Letters = 3
+
+// Repair fixes the encoding damage Turkish CVs commonly arrive with and
+// returns NFC text.
+func Repair(text string) string {
+	if strings.ContainsAny(text, "ÃÄÅ") {
+		text = mojibake.Replace(text)
+	}
+	if !strings.ContainsAny(text, "ışğİŞĞ") {
+		n := 0
+		for _, r := range text {
+			if strings.ContainsRune("ýþðÝÞÐ", r) {
+				n++
+			}
+		}
+		if n >= minLatin5Letters {
+			text = latin5.Replace(text)
+		}
+	}
+	text = norm.NFC.String(text)
+	// "i̇" (i + combining dot above) is what lower-casing İ outside a
+	// Turkish locale produces; there is no precomposed form.
+	return strings.ReplaceAll(text, "i̇", "i")
+}
+
+// Normalize prepares CV text for the extraction prompt: Repair, then
+// Turkish dates and degree names in a form the LLM reads consistently.
+func Normalize(text string) string {
+	text = Repair(text)
+	text = normalizeDates(text)
+	return annotateDegrees(text)
+}
+
+// turkishMonths matches a Turkish month name (or its three-letter
+// abbreviation) followed by a year. Go's (?i) doesn't fold ı/İ with i/I,
+// hence the explicit classes here and below.
+var turkishMonths = regexp.MustCompile(`(?i)(^|[^\p{L}])(ocak|oca|[şs]ubat|[şs]ub|mart|n[iİ]san|n[iİ]s|may[ıi]s|haz[iİ]ran|haz|temmuz|tem|a[ğg]ustos|a[ğg]u|eyl[üu]l|eyl|ek[iİ]m|ek[iİ]|kas[ıi]m|kas|aral[ıi]k|ara)\.?\s+((?:19|20)\d\d)\b`)
+
+var monthNames = map[string]string{
+	"oca": "January", "sub": "February", "mar": "March", "nis": "April",
+	"may": "May", "haz": "June", "tem": "July", "agu": "August",
+	"eyl": "September", "eki": "October", "kas": "November", "ara": "December",
+}
+
+// stillEmployed matches the Turkish "to date" at the end of a date range.
+var stillEmployed = regexp.MustCompile(`(?i)([–—-]\s*)(halen|g[üu]n[üu]m[üu]z|h[âa]l[âa]|devam\s+ed[iİ]yor|[şs]u\s+an|[şs][iİ]md[iİ])([^\p{L}]|$)`)
+
+func normalizeDates(text string) string {
+	text = turkishMonths.ReplaceAllStringFunc(text, func(m string) string {
+		sub := turkishMonths.FindStringSubmatch(m)
+		key := string([]rune(Fold(sub[2]))[:3])
+		return sub[1] + monthNames[key] + " " + sub[3]
+	})
+	return stillEmployed.ReplaceAllString(text, "${1}Present${3}")
+}
+
+// degreeNames are Turkish degree names and their English gloss, longest
+// first so "Yüksek Lisans" isn't read as "Lisans".
+var degreeNames = []struct {
+	re    *regexp.Regexp
+	gloss string
+}{
+	{regexp.MustCompile(`(?i)(?:^|[^\p{L}])(y[üu]ksek\s+l[iİ]sans)`), "Master's degree"},
+	{regexp.MustCompile(`(?i)(?:^|[^\p{L}])([öo]n\s+l[iİ]sans)`), "Associate degree"},
+	{regexp.MustCompile(`(?i)(?:^|[^\p{L}])(doktora)`), "PhD"},
+	{regexp.MustCompile(`(?i)(?:^|[^\p{L}])(l[iİ]sans)`), "Bachelor's degree"},
+	{regexp.MustCompile(`(?i)(?:^|[^\p{L}])(l[iİ]se)`), "High school"},
+}
+
+// annotateDegrees writes the English gloss after each Turkish degree name:
+// "Yüksek Lisans (Master's degree)". Only capitalized names count; words
+// that merely start with one ("Lisanslı", "Lisesi") and names already
+// glossed are left alone.
+func annotateDegrees(text string) string {
+	for _, d := range degreeNames {
+		suffix := " (" + d.gloss + ")"
+		var b strings.Builder
+		last := 0
+		for _, loc := range d.re.FindAllStringSubmatchIndex(text, -1) {
+			start, end := loc[2], loc[3]
+			if r, _ := utf8.DecodeRuneInString(text[start:]); !unicode.IsUpper(r) {
+				continue // "lisans" in running text is a software license
+			}
+			if r, _ := utf8.DecodeRuneInString(text[end:]); unicode.IsLetter(r) {
+				continue
+			}
+			if strings.HasPrefix(text[end:], " (") {
+				continue
+			}
+			if d.gloss == "Bachelor's degree" && precededByQualifier(text[:start]) {
+				continue
+			}
+			b.WriteString(text[last:end])
+			b.WriteString(suffix)
+			last = end
+		}
+		b.WriteString(text[last:])
+		text = b.String()
+	}
+	return text
+}
+
+// precededByQualifier reports whether the word before a "Lisans" makes it
+// another degree ("Yüksek Lisans", "Ön Lisans").
+func precededByQualifier(before string) bool {
+	fields := strings.Fields(before)
+	if len(fields) == 0 {
+		return false
+	}
+	switch Fold(fields[len(fields)-1]) {
+	case "yuksek", "on":
+		return true
+	}
+	return false
+}
+
+// fold maps Turkish letters (and the circumflexed vowels of loanwords) to
+// their ASCII base. I → i because lower-casing it either way (i or ı) is
+// wrong for half of a mixed-language CV; folding both makes them equal.
+var fold = strings.NewReplacer(
+	"İ", "i", "I", "i", "ı", "i",
+	"Ş", "s", "ş", "s", "Ğ", "g", "ğ", "g",
+	"Ü", "u", "ü", "u", "Ö", "o", "ö", "o", "Ç", "c", "ç", "c",
+	"Â", "a", "â", "a", "Î", "i", "î", "i", "Û", "u", "û", "u",
+)
+
+// Fold returns the search key of s: lower case with Turkish letters folded
+// to ASCII, so "İSTANBUL", "Istanbul" and "istanbul", or "Yazılım" and
+// "yazilim", compare equal. Keep in sync with tr_fold in the database.
+func Fold(s string) string {
+	s = strings.ReplaceAll(norm.NFC.String(s), "i̇", "i")
+	return strings.ToLower(fold.Replace(s))
+}
@@ -1,7 +1,11 @@
+-- +goose Up
 -- =====================================================
 -- Complete Database Setup for CV Search & GraphRAG
 -- =====================================================
--- Run this file once to set up the entire database schema
+-- Baseline schema. Applied automatically on startup (AUTO_MIGRATE) or via
+-- cmd/tools/migrate. Every statement is idempotent, so databases that were
+-- set up by hand from the old complete_setup.sql can adopt it safely.
+-- Schema changes go in new numbered files (00002_*.sql, ...), not here.
 
 -- =====================================================
 -- 1. CANDIDATES TABLE (Core CV Data)
@@ -31,7 +35,28 @@ CREATE INDEX IF NOT EXISTS idx_candidates_location ON candidates(location);
 CREATE INDEX IF NOT EXISTS idx_candidates_created_at ON candidates(created_at);
 CREATE INDEX IF NOT EXISTS idx_candidates_search_vector ON candidates USING GIN(search_vector);
 
+-- Accent-insensitive, non-stemming text search config for Turkish / mixed-language CVs.
+-- "Geliştirici" and "gelistirici" both normalize to the same lexeme.
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+-- +goose StatementBegin
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = 'simple_unaccent') THEN
+        CREATE TEXT SEARCH CONFIGURATION simple_unaccent (COPY = simple);
+        ALTER TEXT SEARCH CONFIGURATION simple_unaccent
+            ALTER MAPPING FOR hword, hword_part, word WITH unaccent, simple;
+    END IF;
+END
+$$;
+-- +goose StatementEnd
+
 -- Full-text search trigger function
+-- Indexes both 'english' and 'simple_unaccent' lexemes so the BM25 searcher can
+-- query with either config (BM25_TEXT_SEARCH_CONFIG) without a reindex.
+-- After changing this function, refresh existing rows once with:
+--   UPDATE candidates SET name = name;
+-- +goose StatementBegin
 CREATE OR REPLACE FUNCTION candidates_search_vector_update() 
 RETURNS TRIGGER AS $$
 BEGIN
@@ -39,10 +64,15 @@ BEGIN
         setweight(to_tsvector('english', COALESCE(NEW.name, '')), 'A') ||
         setweight(to_tsvector('english', COALESCE(NEW.skills, '')), 'A') ||
         setweight(to_tsvector('english', COALESCE(NEW.experience, '')), 'B') ||
-        setweight(to_tsvector('english', COALESCE(NEW.location, '')), 'C');
+        setweight(to_tsvector('english', COALESCE(NEW.location, '')), 'C') ||
+        setweight(to_tsvector('simple_unaccent', COALESCE(NEW.name, '')), 'A') ||
+        setweight(to_tsvector('simple_unaccent', COALESCE(NEW.skills, '')), 'A') ||
+        setweight(to_tsvector('simple_unaccent', COALESCE(NEW.experience, '')), 'B') ||
+        setweight(to_tsvector('simple_unaccent', COALESCE(NEW.location, '')), 'C');
     RETURN NEW;
 END;
 $$ LANGUAGE plpgsql;
+-- +goose StatementEnd
 
 -- Trigger to auto-update search_vector
 DROP TRIGGER IF EXISTS tsvector_update ON candidates;
@@ -52,6 +82,7 @@ CREATE TRIGGER tsvector_update
     EXECUTE FUNCTION candidates_search_vector_update();
 
 -- Updated_at trigger function
+-- +goose StatementBegin
 CREATE OR REPLACE FUNCTION update_updated_at_column()
 RETURNS TRIGGER AS $$
 BEGIN
@@ -59,6 +90,7 @@ BEGIN
     RETURN NEW;
 END;
 $$ LANGUAGE plpgsql;
+-- +goose StatementEnd
 
 DROP TRIGGER IF EXISTS update_candidates_updated_at ON candidates;
 CREATE TRIGGER update_candidates_updated_at
@@ -153,6 +185,31 @@ COMMENT ON TABLE graph_nodes IS 'Graph nodes for GraphRAG (person, skill, compan
 COMMENT ON TABLE graph_edges IS 'Graph edges (has_skill, worked_at, etc.)';
 COMMENT ON COLUMN graph_nodes.embedding IS 'Vector embedding for semantic search (1536-dim)';
 
+-- =====================================================
+-- 4b. FUZZY MATCHING (pg_trgm)
+-- =====================================================
+-- Trigram similarity for names and companies, so "Aybars Yıldız" matches
+-- "Aybars Yildiz" and "Garanti" matches "Garanti BBVA".
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- unaccent() is only STABLE, so it can't back an index directly; this wrapper
+-- pins the dictionary and is safe to mark IMMUTABLE.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION immutable_unaccent(text)
+RETURNS text AS $$
+    SELECT public.unaccent('public.unaccent', lower($1))
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT;
+-- +goose StatementEnd
+
+CREATE INDEX IF NOT EXISTS idx_candidates_name_trgm
+    ON candidates USING GIN (immutable_unaccent(name) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_graph_nodes_company_name_trgm
+    ON graph_nodes USING GIN (immutable_unaccent(properties->>'name') gin_trgm_ops)
+    WHERE node_type = 'company';
+
+COMMENT ON FUNCTION immutable_unaccent(text) IS 'Lowercased, accent-folded text for trigram indexes';
+
 -- =====================================================
 -- 5. COMMUNITIES (Leiden Algorithm)
 -- =====================================================
@@ -261,6 +318,7 @@ COMMENT ON COLUMN cv_upload_jobs.status IS 'Job status: pending, processing, com
 COMMENT ON COLUMN cv_upload_jobs.progress IS 'JSON object tracking processing progress';
 
 -- Add foreign key constraint for cv_files.job_id (now that cv_upload_jobs exists)
+-- +goose StatementBegin
 DO $$ 
 BEGIN
     IF NOT EXISTS (
@@ -271,6 +329,7 @@ BEGIN
             FOREIGN KEY (job_id) REFERENCES cv_upload_jobs(id);
     END IF;
 END $$;
+-- +goose StatementEnd
 
 -- =====================================================
 -- 7b. GROQ BATCH API TRACKING (Bulk CV Extraction)
@@ -331,6 +390,87 @@ CREATE TRIGGER interviews_updated_at
 
 COMMENT ON TABLE interviews IS 'Interview records per candidate; multiple rounds and teams supported';
 
+-- =====================================================
+-- 9. SEARCH EXPERIMENTS (A/B Ranking Configurations)
+-- =====================================================
+-- Named hybrid search configurations (weights, FinalTopN, reranker, scoring
+-- prompt instructions). A request can pick one via "experiment"; otherwise
+-- the row with is_default = true (if any) is used. Every served search is
+-- logged so ranking quality can be compared offline per configuration.
+
+CREATE TABLE IF NOT EXISTS search_experiments (
+    id          SERIAL PRIMARY KEY,
+    name        TEXT NOT NULL UNIQUE,
+    description TEXT,
+    config      JSONB NOT NULL DEFAULT '{}'::jsonb,
+    is_default  BOOLEAN NOT NULL DEFAULT FALSE,
+    active      BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at  TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at  TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- At most one default experiment.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_search_experiments_default
+    ON search_experiments(is_default) WHERE is_default;
+
+DROP TRIGGER IF EXISTS search_experiments_updated_at ON search_experiments;
+CREATE TRIGGER search_experiments_updated_at
+    BEFORE UPDATE ON search_experiments
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS search_experiment_log (
+    id              BIGSERIAL PRIMARY KEY,
+    experiment_name TEXT,            -- NULL = built-in defaults
+    query           TEXT NOT NULL,
+    config          JSONB,           -- effective config after request overrides
+    result_ids      JSONB,           -- ranked candidates.id list as served
+    result_count    INTEGER DEFAULT 0,
+    duration_ms     INTEGER,
+    created_at      TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_search_experiment_log_name ON search_experiment_log(experiment_name, created_at);
+
+COMMENT ON TABLE search_experiments IS 'Named hybrid search configurations for A/B ranking experiments';
+COMMENT ON TABLE search_experiment_log IS 'Which configuration served each hybrid search, for offline comparison';
+
+-- =====================================================
+-- 10. SEARCH SESSIONS (Conversational Refinement)
+-- =====================================================
+-- A session is a chain of hybrid searches where follow-ups ("only the ones
+-- with AWS", "drop juniors") are interpreted by the LLM relative to the
+-- previous turn's result set. Each turn stores the served results so the
+-- next follow-up can filter them without re-running retrieval.
+
+CREATE TABLE IF NOT EXISTS search_sessions (
+    id          TEXT PRIMARY KEY,
+    created_at  TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at  TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+DROP TRIGGER IF EXISTS search_sessions_updated_at ON search_sessions;
+CREATE TRIGGER search_sessions_updated_at
+    BEFORE UPDATE ON search_sessions
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS search_session_turns (
+    id              BIGSERIAL PRIMARY KEY,
+    session_id      TEXT NOT NULL REFERENCES search_sessions(id) ON DELETE CASCADE,
+    turn            INTEGER NOT NULL,
+    query           TEXT NOT NULL,            -- what the user typed
+    action          TEXT NOT NULL,            -- search | filter
+    effective_query TEXT,                     -- standalone query actually searched (action = search)
+    explanation     TEXT,                     -- LLM's interpretation of the follow-up
+    results         JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_at      TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (session_id, turn)
+);
+
+COMMENT ON TABLE search_sessions IS 'Conversational search sessions (follow-up queries refine the previous result set)';
+COMMENT ON TABLE search_session_turns IS 'One row per query in a search session, with the results served';
+
 -- =====================================================
 -- SETUP COMPLETE
 -- =====================================================
@@ -342,4 +482,9 @@ COMMENT ON TABLE interviews IS 'Interview records per candidate; multiple rounds
 -- - candidate_scores
 -- - cv_upload_jobs (async processing)
 -- - interviews (per-candidate interview records)
--- Extensions: pgvector
+-- - search_experiments, search_experiment_log (A/B ranking configs)
+-- - search_sessions, search_session_turns (conversational search)
+-- Extensions: pgvector, unaccent (+ simple_unaccent text search config), pg_trgm
+
+-- +goose Down
+-- The baseline is not reversible: dropping it would drop every table.
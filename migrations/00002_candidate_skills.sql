@@ -0,0 +1,57 @@
+-- +goose Up
+-- =====================================================
+-- Relational skills: skills dimension + candidate_skills
+-- =====================================================
+-- candidates.skills stays as a denormalized comma-joined copy because the
+-- search_vector trigger (BM25) indexes it; candidate_skills is the source of
+-- truth for filtering.
+
+CREATE TABLE IF NOT EXISTS skills (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,                    -- display form of the first spelling seen
+    normalized_name TEXT NOT NULL UNIQUE,  -- lower(trim(name)), the lookup key
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS candidate_skills (
+    candidate_id INTEGER NOT NULL REFERENCES candidates(id) ON DELETE CASCADE,
+    skill_id INTEGER NOT NULL REFERENCES skills(id) ON DELETE CASCADE,
+    proficiency TEXT,                      -- beginner | intermediate | advanced | expert (as extracted)
+    years NUMERIC(4,1),
+    PRIMARY KEY (candidate_id, skill_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_candidate_skills_skill ON candidate_skills(skill_id);
+
+-- Backfill from the CSV column.
+INSERT INTO skills (name, normalized_name)
+SELECT DISTINCT ON (lower(trim(s))) trim(s), lower(trim(s))
+FROM candidates c, regexp_split_to_table(COALESCE(c.skills, ''), ',') AS s
+WHERE trim(s) <> ''
+ORDER BY lower(trim(s)), trim(s)
+ON CONFLICT (normalized_name) DO NOTHING;
+
+INSERT INTO candidate_skills (candidate_id, skill_id)
+SELECT DISTINCT c.id, sk.id
+FROM candidates c, regexp_split_to_table(COALESCE(c.skills, ''), ',') AS s
+JOIN skills sk ON sk.normalized_name = lower(trim(s))
+WHERE trim(s) <> ''
+ON CONFLICT DO NOTHING;
+
+-- Fill proficiency/years from the graph where the candidate is linked.
+UPDATE candidate_skills cs
+SET proficiency = NULLIF(e.properties->>'proficiency', ''),
+    years = CASE WHEN e.properties->>'years_of_experience' ~ '^[0-9]+(\.[0-9]+)?$'
+                 THEN (e.properties->>'years_of_experience')::numeric END
+FROM candidates c
+JOIN graph_edges e ON e.source_node_id = c.graph_node_id AND e.edge_type = 'HAS_SKILL'
+JOIN graph_nodes n ON n.id = e.target_node_id AND n.node_type = 'skill'
+JOIN skills sk ON sk.normalized_name = lower(trim(n.properties->>'name'))
+WHERE cs.candidate_id = c.id AND cs.skill_id = sk.id;
+
+COMMENT ON TABLE skills IS 'Skill dimension; one row per normalized skill name';
+COMMENT ON TABLE candidate_skills IS 'Candidate ↔ skill link with proficiency and years';
+
+-- +goose Down
+DROP TABLE IF EXISTS candidate_skills;
+DROP TABLE IF EXISTS skills;
@@ -0,0 +1,30 @@
+-- +goose Up
+-- =====================================================
+-- Soft deletes for candidates, CV files and graph nodes
+-- =====================================================
+-- Rows with deleted_at set are hidden from search, listings and similarity.
+-- Re-uploading the same CV (content_hash) or rebuilding the person node
+-- clears the flag again. GDPR erasure (POST /api/candidates/{id}/erase)
+-- goes further and hard-deletes the PII.
+
+ALTER TABLE candidates  ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE cv_files    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE graph_nodes ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- Nearly every query filters on "deleted_at IS NULL"; keep the deleted set
+-- small and indexed for the admin side instead.
+CREATE INDEX IF NOT EXISTS idx_candidates_deleted_at  ON candidates(deleted_at)  WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_cv_files_deleted_at    ON cv_files(deleted_at)    WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_graph_nodes_deleted_at ON graph_nodes(deleted_at) WHERE deleted_at IS NOT NULL;
+
+COMMENT ON COLUMN candidates.deleted_at IS 'Soft-delete timestamp; NULL = active';
+COMMENT ON COLUMN cv_files.deleted_at IS 'Soft-delete timestamp; NULL = active';
+COMMENT ON COLUMN graph_nodes.deleted_at IS 'Soft-delete timestamp (person nodes); NULL = active';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_graph_nodes_deleted_at;
+DROP INDEX IF EXISTS idx_cv_files_deleted_at;
+DROP INDEX IF EXISTS idx_candidates_deleted_at;
+ALTER TABLE graph_nodes DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE cv_files    DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE candidates  DROP COLUMN IF EXISTS deleted_at;
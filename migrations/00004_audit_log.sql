@@ -0,0 +1,27 @@
+-- +goose Up
+-- =====================================================
+-- Audit log of data mutations
+-- =====================================================
+-- Append-only record of who changed what and when. entity_id is TEXT so
+-- non-integer keys (experiment names, batch runs) fit alongside row IDs.
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    actor TEXT NOT NULL,                   -- user:<id> | key:<sha256 prefix> | system:<job> | anonymous
+    action TEXT NOT NULL,                  -- upload | delete | erase | reprocess | ...
+    entity_type TEXT NOT NULL,             -- cv_file | candidate | interview | experiment | ...
+    entity_id TEXT,
+    details JSONB,
+    ip TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_created ON audit_log(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log(entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor);
+CREATE INDEX IF NOT EXISTS idx_audit_log_action ON audit_log(action);
+
+COMMENT ON TABLE audit_log IS 'Append-only audit trail of data mutations';
+
+-- +goose Down
+DROP TABLE IF EXISTS audit_log;
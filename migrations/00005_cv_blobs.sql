@@ -0,0 +1,32 @@
+-- +goose Up
+-- =====================================================
+-- Content-addressed CV blobs + retention bookkeeping
+-- =====================================================
+-- Uploaded files are stored under their SHA-256 (cvs/sha256/<ab>/<hash>),
+-- so identical files share one object. cv_blobs tracks every key ever
+-- written; a blob no cv_files row points at is an orphan and is removed by
+-- the retention cleanup once touched_at is older than the orphan TTL (the
+-- TTL also covers the window between storing the file and inserting its row).
+
+CREATE TABLE IF NOT EXISTS cv_blobs (
+    key TEXT PRIMARY KEY,
+    size BIGINT,
+    touched_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()  -- last write or reuse
+);
+
+CREATE INDEX IF NOT EXISTS idx_cv_blobs_touched ON cv_blobs(touched_at);
+CREATE INDEX IF NOT EXISTS idx_cv_files_file_path ON cv_files(file_path);
+
+-- Existing uploads (pre content addressing) keep their keys.
+INSERT INTO cv_blobs (key, size)
+SELECT file_path, MAX(file_size)
+FROM cv_files
+WHERE COALESCE(file_path, '') <> ''
+GROUP BY file_path
+ON CONFLICT (key) DO NOTHING;
+
+COMMENT ON TABLE cv_blobs IS 'Blob store keys for uploaded CV files; unreferenced keys are orphans';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_cv_files_file_path;
+DROP TABLE IF EXISTS cv_blobs;
@@ -0,0 +1,30 @@
+-- +goose Up
+-- =====================================================
+-- Candidate merges (duplicate people)
+-- =====================================================
+-- One row per merge of a duplicate candidate into a primary one. snapshot
+-- holds everything the merge changed (primary's previous fields and node
+-- properties, moved edge / CV file / interview IDs), so the merge can be
+-- undone. The duplicate candidate and its person node are soft-deleted, not
+-- removed.
+
+CREATE TABLE IF NOT EXISTS candidate_merges (
+    id BIGSERIAL PRIMARY KEY,
+    primary_candidate_id INTEGER NOT NULL REFERENCES candidates(id) ON DELETE CASCADE,
+    merged_candidate_id INTEGER NOT NULL REFERENCES candidates(id) ON DELETE CASCADE,
+    primary_node_id INTEGER REFERENCES graph_nodes(id) ON DELETE SET NULL,
+    merged_node_id INTEGER REFERENCES graph_nodes(id) ON DELETE SET NULL,
+    snapshot JSONB NOT NULL,
+    actor TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    undone_at TIMESTAMP WITH TIME ZONE,
+    undone_by TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_candidate_merges_primary ON candidate_merges(primary_candidate_id);
+CREATE INDEX IF NOT EXISTS idx_candidate_merges_merged ON candidate_merges(merged_candidate_id);
+
+COMMENT ON TABLE candidate_merges IS 'Merges of duplicate candidates, with the snapshot needed to undo them';
+
+-- +goose Down
+DROP TABLE IF EXISTS candidate_merges;
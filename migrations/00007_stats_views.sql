@@ -0,0 +1,91 @@
+-- +goose Up
+-- =====================================================
+-- Dashboard statistics (materialized views)
+-- =====================================================
+-- The stats endpoints read these instead of aggregating graph_nodes /
+-- graph_edges on every request. The API refreshes them every
+-- STATS_REFRESH_MINUTES (REFRESH ... CONCURRENTLY, hence the unique indexes)
+-- and records the time in stats_refreshes.
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS stats_node_counts AS
+SELECT node_type, COUNT(*)::int AS node_count
+FROM graph_nodes
+WHERE deleted_at IS NULL
+GROUP BY node_type;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stats_node_counts ON stats_node_counts(node_type);
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS stats_edge_counts AS
+SELECT edge_type, COUNT(*)::int AS edge_count
+FROM graph_edges
+GROUP BY edge_type;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stats_edge_counts ON stats_edge_counts(edge_type);
+
+-- Live candidates per skill name.
+CREATE MATERIALIZED VIEW IF NOT EXISTS stats_skill_popularity AS
+SELECT s.properties->>'name' AS skill,
+       COUNT(DISTINCT e.source_node_id)::int AS candidate_count
+FROM graph_nodes s
+JOIN graph_edges e ON e.target_node_id = s.id AND e.edge_type = 'HAS_SKILL'
+JOIN graph_nodes p ON p.id = e.source_node_id AND p.deleted_at IS NULL
+WHERE s.node_type = 'skill' AND s.properties->>'name' IS NOT NULL
+GROUP BY s.properties->>'name';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stats_skill_popularity ON stats_skill_popularity(skill);
+
+-- New candidates per skill per month, by when the person node first appeared.
+CREATE MATERIALIZED VIEW IF NOT EXISTS stats_skill_trend AS
+SELECT date_trunc('month', p.created_at)::date AS month,
+       s.properties->>'name' AS skill,
+       COUNT(DISTINCT p.id)::int AS candidate_count
+FROM graph_nodes p
+JOIN graph_edges e ON e.source_node_id = p.id AND e.edge_type = 'HAS_SKILL'
+JOIN graph_nodes s ON s.id = e.target_node_id AND s.node_type = 'skill'
+WHERE p.node_type = 'person' AND p.deleted_at IS NULL
+  AND p.created_at IS NOT NULL AND s.properties->>'name' IS NOT NULL
+GROUP BY 1, 2;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stats_skill_trend ON stats_skill_trend(month, skill);
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS stats_seniority AS
+SELECT COALESCE(NULLIF(lower(trim(properties->>'seniority')), ''), 'unknown') AS seniority,
+       COUNT(*)::int AS candidate_count
+FROM graph_nodes
+WHERE node_type = 'person' AND deleted_at IS NULL
+GROUP BY 1;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stats_seniority ON stats_seniority(seniority);
+
+-- Live person members per detected community.
+CREATE MATERIALIZED VIEW IF NOT EXISTS stats_community_sizes AS
+SELECT c.id, c.level, c.community_id, COALESCE(c.title, '') AS title,
+       COUNT(p.id)::int AS member_count
+FROM graph_communities c
+LEFT JOIN community_members cm ON cm.community_id = c.id
+LEFT JOIN graph_nodes p ON p.id = cm.node_id AND p.node_type = 'person' AND p.deleted_at IS NULL
+GROUP BY c.id, c.level, c.community_id, c.title;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stats_community_sizes ON stats_community_sizes(id);
+
+-- CV uploads per ISO week (deleted files included: they were still uploads).
+CREATE MATERIALIZED VIEW IF NOT EXISTS stats_uploads_weekly AS
+SELECT date_trunc('week', uploaded_at)::date AS week,
+       COUNT(*)::int AS uploads,
+       COUNT(DISTINCT candidate_id)::int AS candidates
+FROM cv_files
+WHERE uploaded_at IS NOT NULL
+GROUP BY 1;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stats_uploads_weekly ON stats_uploads_weekly(week);
+
+CREATE TABLE IF NOT EXISTS stats_refreshes (
+    name TEXT PRIMARY KEY,
+    refreshed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+INSERT INTO stats_refreshes (name) VALUES ('dashboard') ON CONFLICT (name) DO NOTHING;
+
+COMMENT ON TABLE stats_refreshes IS 'Last refresh time of the stats_* materialized views';
+
+-- +goose Down
+DROP TABLE IF EXISTS stats_refreshes;
+DROP MATERIALIZED VIEW IF EXISTS stats_uploads_weekly;
+DROP MATERIALIZED VIEW IF EXISTS stats_community_sizes;
+DROP MATERIALIZED VIEW IF EXISTS stats_seniority;
+DROP MATERIALIZED VIEW IF EXISTS stats_skill_trend;
+DROP MATERIALIZED VIEW IF EXISTS stats_skill_popularity;
+DROP MATERIALIZED VIEW IF EXISTS stats_edge_counts;
+DROP MATERIALIZED VIEW IF EXISTS stats_node_counts;
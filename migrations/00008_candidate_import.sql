@@ -0,0 +1,34 @@
+-- +goose Up
+-- =====================================================
+-- Candidate import from other ATSes
+-- =====================================================
+-- Candidates created by POST /api/candidates/import keep where they came
+-- from (import_source + external_id, the other system's ID) so re-importing
+-- the same export updates them instead of creating duplicates. resume_url is
+-- the export's resume link; resume_file_path / resume_downloaded_at are set
+-- once it has been fetched into the blob store.
+
+ALTER TABLE candidates
+    ADD COLUMN IF NOT EXISTS import_source TEXT,
+    ADD COLUMN IF NOT EXISTS external_id TEXT,
+    ADD COLUMN IF NOT EXISTS resume_url TEXT,
+    ADD COLUMN IF NOT EXISTS resume_file_path TEXT,
+    ADD COLUMN IF NOT EXISTS resume_downloaded_at TIMESTAMP WITH TIME ZONE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_candidates_import_ref
+    ON candidates(import_source, external_id)
+    WHERE external_id IS NOT NULL AND deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_candidates_email_lower
+    ON candidates(lower(email))
+    WHERE email IS NOT NULL AND deleted_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_candidates_email_lower;
+DROP INDEX IF EXISTS idx_candidates_import_ref;
+ALTER TABLE candidates
+    DROP COLUMN IF EXISTS resume_downloaded_at,
+    DROP COLUMN IF EXISTS resume_file_path,
+    DROP COLUMN IF EXISTS resume_url,
+    DROP COLUMN IF EXISTS external_id,
+    DROP COLUMN IF EXISTS import_source;
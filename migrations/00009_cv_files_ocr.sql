@@ -0,0 +1,9 @@
+-- +goose Up
+-- Scanned (image-only) PDFs have no text layer; their parsed_text comes from
+-- the OCR fallback (OCR_BACKEND) instead, which is less reliable.
+ALTER TABLE cv_files ADD COLUMN IF NOT EXISTS ocr_used BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN cv_files.ocr_used IS 'parsed_text was produced by OCR (scanned document)';
+
+-- +goose Down
+ALTER TABLE cv_files DROP COLUMN IF EXISTS ocr_used;
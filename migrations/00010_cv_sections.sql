@@ -0,0 +1,10 @@
+-- +goose Up
+-- Section segmentation of parsed_text (summary, experience, education, ...),
+-- used to build the extraction prompt. NULL until segmented; see
+-- internal/cv/sections.go.
+ALTER TABLE cv_files ADD COLUMN IF NOT EXISTS sections JSONB;
+
+COMMENT ON COLUMN cv_files.sections IS 'JSON array of {kind, heading, text} sections of parsed_text';
+
+-- +goose Down
+ALTER TABLE cv_files DROP COLUMN IF EXISTS sections;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Blind screening: parsed_text / sections of these CVs had their PII
+-- (email, phone, address, birth date, photo references) masked before
+-- saving, and their extraction output is masked too. See ANONYMIZE_PII.
+ALTER TABLE cv_files ADD COLUMN IF NOT EXISTS anonymized BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN cv_files.anonymized IS 'PII was masked in parsed_text and extraction output (blind screening)';
+
+-- +goose Down
+ALTER TABLE cv_files DROP COLUMN IF EXISTS anonymized;
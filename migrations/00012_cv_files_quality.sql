@@ -0,0 +1,21 @@
+-- +goose Up
+-- Parse quality: scored after extraction from text length, garbled-text
+-- ratio and what the extraction found (name, contact, skills). Low-quality
+-- parses are 'needs_review' (GET /api/cv?quality=needs_review); see
+-- internal/cv/quality.go.
+ALTER TABLE cv_files ADD COLUMN IF NOT EXISTS quality_status TEXT NOT NULL DEFAULT 'pending'
+    CHECK (quality_status IN ('pending', 'ok', 'needs_review'));
+ALTER TABLE cv_files ADD COLUMN IF NOT EXISTS quality_score REAL;
+ALTER TABLE cv_files ADD COLUMN IF NOT EXISTS quality_issues TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_cv_files_needs_review ON cv_files (uploaded_at DESC)
+    WHERE quality_status = 'needs_review' AND deleted_at IS NULL;
+
+COMMENT ON COLUMN cv_files.quality_status IS 'pending, ok or needs_review (low-quality parse or extraction)';
+COMMENT ON COLUMN cv_files.quality_issues IS 'short_text, gibberish, missing_name, missing_contact, no_skills';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_cv_files_needs_review;
+ALTER TABLE cv_files DROP COLUMN IF EXISTS quality_issues;
+ALTER TABLE cv_files DROP COLUMN IF EXISTS quality_score;
+ALTER TABLE cv_files DROP COLUMN IF EXISTS quality_status;
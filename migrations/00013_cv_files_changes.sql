@@ -0,0 +1,14 @@
+-- +goose Up
+-- Change detection on re-upload: a CV from a candidate (matched by email)
+-- who already had one points at that previous version and stores what
+-- changed (new skills, new employers, seniority, ...). See
+-- internal/cv/changes.go and GET /api/cv/files/{id}/changes.
+ALTER TABLE cv_files ADD COLUMN IF NOT EXISTS previous_cv_file_id INTEGER REFERENCES cv_files(id) ON DELETE SET NULL;
+ALTER TABLE cv_files ADD COLUMN IF NOT EXISTS changes JSONB;
+
+COMMENT ON COLUMN cv_files.previous_cv_file_id IS 'Previous CV of the same candidate (by email), if any';
+COMMENT ON COLUMN cv_files.changes IS 'Structured diff against previous_cv_file_id (see cv.Changes)';
+
+-- +goose Down
+ALTER TABLE cv_files DROP COLUMN IF EXISTS changes;
+ALTER TABLE cv_files DROP COLUMN IF EXISTS previous_cv_file_id;
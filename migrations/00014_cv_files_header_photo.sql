@@ -0,0 +1,17 @@
+-- +goose Up
+-- Word CVs: fields read from the page header / document properties (name,
+-- title, contact, header table; see cv.DocumentHeader) seed the extraction.
+-- photo_key is the blob store key of the embedded candidate photo, only kept
+-- with KEEP_CV_PHOTOS=true; the retention cleanup treats it like file_path.
+ALTER TABLE cv_files ADD COLUMN IF NOT EXISTS header JSONB;
+ALTER TABLE cv_files ADD COLUMN IF NOT EXISTS photo_key TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_cv_files_photo_key ON cv_files(photo_key) WHERE photo_key IS NOT NULL;
+
+COMMENT ON COLUMN cv_files.header IS 'DOCX header fields {name, title, email, phone, linkedin_url, table}';
+COMMENT ON COLUMN cv_files.photo_key IS 'Blob store key of the embedded photo (opt-in, KEEP_CV_PHOTOS)';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_cv_files_photo_key;
+ALTER TABLE cv_files DROP COLUMN IF EXISTS photo_key;
+ALTER TABLE cv_files DROP COLUMN IF EXISTS header;
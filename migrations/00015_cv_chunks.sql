@@ -0,0 +1,31 @@
+-- +goose Up
+-- =====================================================
+-- CV text chunks
+-- =====================================================
+-- Long CVs are split at parse time into chunks of about 1000 tokens
+-- (cv.ChunkText, section headings kept). Extraction of CVs too long for one
+-- prompt runs over groups of chunks and merges the results; each chunk is
+-- embedded so vector search can match a passage deep inside a CV, scored as
+-- the candidate the CV belongs to.
+
+CREATE TABLE IF NOT EXISTS cv_chunks (
+    id BIGSERIAL PRIMARY KEY,
+    cv_file_id INTEGER NOT NULL REFERENCES cv_files(id) ON DELETE CASCADE,
+    chunk_index INTEGER NOT NULL,
+    text TEXT NOT NULL,
+    token_count INTEGER NOT NULL,
+    embedding vector(1536),
+    embedding_model TEXT,
+    embedding_created_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (cv_file_id, chunk_index)
+);
+
+CREATE INDEX IF NOT EXISTS idx_cv_chunks_embedding ON cv_chunks USING hnsw (embedding vector_cosine_ops);
+
+COMMENT ON TABLE cv_chunks IS 'CV text split into ~1000-token chunks for map-reduce extraction and per-chunk vector search';
+COMMENT ON COLUMN cv_chunks.token_count IS 'Estimated token count (about 4 characters per token)';
+COMMENT ON COLUMN cv_chunks.embedding IS 'Vector embedding of the chunk text (1536-dim)';
+
+-- +goose Down
+DROP TABLE IF EXISTS cv_chunks;
@@ -0,0 +1,63 @@
+-- +goose Up
+-- =====================================================
+-- Turkish folding for BM25
+-- =====================================================
+-- Mixed Turkish/English CVs spell the same word several ways ("YAZILIM",
+-- "Yazılım", "yazilim") and lower() of İ/I depends on the server locale, so
+-- the search_vector is built from tr_fold()ed text: lower case with Turkish
+-- letters folded to ASCII. The BM25 query side applies the same folding in
+-- Go (textnorm.Fold); keep the two in sync.
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION tr_fold(t TEXT)
+RETURNS TEXT AS $$
+    SELECT lower(translate(COALESCE(t, ''),
+        'İIıŞşĞğÜüÖöÇçÂâÎîÛû',
+        'iiissgguuooccaaiiuu'))
+$$ LANGUAGE SQL IMMUTABLE PARALLEL SAFE;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION candidates_search_vector_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', tr_fold(NEW.name)), 'A') ||
+        setweight(to_tsvector('english', tr_fold(NEW.skills)), 'A') ||
+        setweight(to_tsvector('english', tr_fold(NEW.experience)), 'B') ||
+        setweight(to_tsvector('english', tr_fold(NEW.location)), 'C') ||
+        setweight(to_tsvector('simple_unaccent', tr_fold(NEW.name)), 'A') ||
+        setweight(to_tsvector('simple_unaccent', tr_fold(NEW.skills)), 'A') ||
+        setweight(to_tsvector('simple_unaccent', tr_fold(NEW.experience)), 'B') ||
+        setweight(to_tsvector('simple_unaccent', tr_fold(NEW.location)), 'C');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+UPDATE candidates SET name = name;
+
+COMMENT ON FUNCTION tr_fold(TEXT) IS 'Search key: lower case, Turkish letters folded to ASCII (mirrors textnorm.Fold)';
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION candidates_search_vector_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', COALESCE(NEW.name, '')), 'A') ||
+        setweight(to_tsvector('english', COALESCE(NEW.skills, '')), 'A') ||
+        setweight(to_tsvector('english', COALESCE(NEW.experience, '')), 'B') ||
+        setweight(to_tsvector('english', COALESCE(NEW.location, '')), 'C') ||
+        setweight(to_tsvector('simple_unaccent', COALESCE(NEW.name, '')), 'A') ||
+        setweight(to_tsvector('simple_unaccent', COALESCE(NEW.skills, '')), 'A') ||
+        setweight(to_tsvector('simple_unaccent', COALESCE(NEW.experience, '')), 'B') ||
+        setweight(to_tsvector('simple_unaccent', COALESCE(NEW.location, '')), 'C');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+UPDATE candidates SET name = name;
+
+DROP FUNCTION IF EXISTS tr_fold(TEXT);
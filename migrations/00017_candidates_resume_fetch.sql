@@ -0,0 +1,24 @@
+-- +goose Up
+-- Background download of candidates.resume_url (imports whose download
+-- failed, candidates saved with a link only). resume_fetch_after is when the
+-- next attempt is due: imports set it a little ahead so their own download
+-- goes first, failures push it back. The fetcher gives up after
+-- RESUME_FETCH_MAX_ATTEMPTS; resume_fetch_error keeps the last failure.
+ALTER TABLE candidates
+    ADD COLUMN IF NOT EXISTS resume_fetch_attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS resume_fetch_after TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS resume_fetch_error TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_candidates_resume_pending
+    ON candidates(resume_fetch_after)
+    WHERE resume_url IS NOT NULL AND resume_downloaded_at IS NULL AND deleted_at IS NULL;
+
+COMMENT ON COLUMN candidates.resume_fetch_attempts IS 'Failed resume_url downloads so far';
+COMMENT ON COLUMN candidates.resume_fetch_after IS 'Earliest time of the next resume_url download attempt (NULL = now)';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_candidates_resume_pending;
+ALTER TABLE candidates
+    DROP COLUMN IF EXISTS resume_fetch_error,
+    DROP COLUMN IF EXISTS resume_fetch_after,
+    DROP COLUMN IF EXISTS resume_fetch_attempts;
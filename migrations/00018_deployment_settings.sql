@@ -0,0 +1,14 @@
+-- +goose Up
+-- Facts about this deployment that tools record and the API reads at
+-- startup, one JSON document per key. cmd/tools/offline-setup stores
+-- "offline" once the deployment has been verified to run on Ollama alone.
+CREATE TABLE IF NOT EXISTS deployment_settings (
+    key TEXT PRIMARY KEY,
+    value JSONB NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+COMMENT ON TABLE deployment_settings IS 'Per-deployment facts recorded by tools (e.g. offline capability), keyed by name';
+
+-- +goose Down
+DROP TABLE IF EXISTS deployment_settings;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- LLM token usage per day, provider and model, for the admin overview's
+-- spend figure. cost_usd is estimated when recorded (llm.Usage.CostUSD) and
+-- stays NULL for models without a known price.
+CREATE TABLE IF NOT EXISTS llm_usage (
+    day DATE NOT NULL,
+    provider TEXT NOT NULL,
+    model TEXT NOT NULL,
+    requests INTEGER NOT NULL DEFAULT 0,
+    prompt_tokens BIGINT NOT NULL DEFAULT 0,
+    completion_tokens BIGINT NOT NULL DEFAULT 0,
+    cost_usd DOUBLE PRECISION,
+    PRIMARY KEY (day, provider, model)
+);
+
+COMMENT ON TABLE llm_usage IS 'LLM requests and tokens per day/provider/model, with estimated cost';
+
+-- +goose Down
+DROP TABLE IF EXISTS llm_usage;
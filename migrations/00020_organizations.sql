@@ -0,0 +1,233 @@
+-- +goose Up
+-- =====================================================
+-- Organizations (multi-tenancy)
+-- =====================================================
+-- Several recruiting teams can share one deployment: every candidate, CV,
+-- graph node/edge, community, search session and logged search belongs to
+-- an organization, and the API scopes every query to the caller's (the org
+-- of its X-API-Key, see internal/tenant). Rows that existed before this
+-- migration belong to the seeded default organization (id 1), which is also
+-- what requests without a key and the CLI tools act for.
+--
+-- Per-org graphs: the natural keys of graph nodes ("skill_Go") and
+-- communities now include org_id, so two organizations never share a node,
+-- an edge or a community. Tables keyed by one of these rows (cv_chunks,
+-- cv_upload_jobs, interviews, community_members, ...) are scoped through it.
+
+CREATE TABLE IF NOT EXISTS organizations (
+    id SERIAL PRIMARY KEY,
+    slug TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    api_key_hash TEXT UNIQUE,  -- hex SHA-256 of the org's API key; NULL = no key issued
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+INSERT INTO organizations (id, slug, name) VALUES (1, 'default', 'Default')
+ON CONFLICT (id) DO NOTHING;
+SELECT setval(pg_get_serial_sequence('organizations', 'id'), GREATEST((SELECT MAX(id) FROM organizations), 1));
+
+ALTER TABLE candidates            ADD COLUMN IF NOT EXISTS org_id INTEGER NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE cv_files              ADD COLUMN IF NOT EXISTS org_id INTEGER NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE graph_nodes           ADD COLUMN IF NOT EXISTS org_id INTEGER NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE graph_edges           ADD COLUMN IF NOT EXISTS org_id INTEGER NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE graph_communities     ADD COLUMN IF NOT EXISTS org_id INTEGER NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE search_sessions       ADD COLUMN IF NOT EXISTS org_id INTEGER NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE search_experiment_log ADD COLUMN IF NOT EXISTS org_id INTEGER NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE audit_log             ADD COLUMN IF NOT EXISTS org_id INTEGER NOT NULL DEFAULT 1 REFERENCES organizations(id);
+
+CREATE INDEX IF NOT EXISTS idx_candidates_org        ON candidates(org_id);
+CREATE INDEX IF NOT EXISTS idx_cv_files_org          ON cv_files(org_id);
+CREATE INDEX IF NOT EXISTS idx_graph_nodes_org_type  ON graph_nodes(org_id, node_type);
+CREATE INDEX IF NOT EXISTS idx_graph_edges_org       ON graph_edges(org_id);
+CREATE INDEX IF NOT EXISTS idx_search_sessions_org   ON search_sessions(org_id);
+CREATE INDEX IF NOT EXISTS idx_search_experiment_log_org ON search_experiment_log(org_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_org         ON audit_log(org_id, created_at DESC);
+
+-- Natural keys become unique per organization.
+ALTER TABLE graph_nodes DROP CONSTRAINT IF EXISTS graph_nodes_node_type_node_id_key;
+ALTER TABLE graph_nodes ADD CONSTRAINT graph_nodes_org_node_key UNIQUE (org_id, node_type, node_id);
+
+ALTER TABLE graph_communities DROP CONSTRAINT IF EXISTS graph_communities_level_community_id_key;
+ALTER TABLE graph_communities ADD CONSTRAINT graph_communities_org_level_key UNIQUE (org_id, level, community_id);
+
+DROP INDEX IF EXISTS idx_cv_files_content_hash;
+CREATE UNIQUE INDEX idx_cv_files_content_hash ON cv_files(org_id, content_hash);
+
+DROP INDEX IF EXISTS idx_candidates_import_ref;
+CREATE UNIQUE INDEX idx_candidates_import_ref
+    ON candidates(org_id, import_source, external_id)
+    WHERE external_id IS NOT NULL AND deleted_at IS NULL;
+
+-- Dashboard statistics per organization (same views as 00007, grouped by
+-- org_id; the unique indexes keep REFRESH ... CONCURRENTLY working).
+DROP MATERIALIZED VIEW IF EXISTS stats_uploads_weekly;
+DROP MATERIALIZED VIEW IF EXISTS stats_community_sizes;
+DROP MATERIALIZED VIEW IF EXISTS stats_seniority;
+DROP MATERIALIZED VIEW IF EXISTS stats_skill_trend;
+DROP MATERIALIZED VIEW IF EXISTS stats_skill_popularity;
+DROP MATERIALIZED VIEW IF EXISTS stats_edge_counts;
+DROP MATERIALIZED VIEW IF EXISTS stats_node_counts;
+
+CREATE MATERIALIZED VIEW stats_node_counts AS
+SELECT org_id, node_type, COUNT(*)::int AS node_count
+FROM graph_nodes
+WHERE deleted_at IS NULL
+GROUP BY org_id, node_type;
+CREATE UNIQUE INDEX idx_stats_node_counts ON stats_node_counts(org_id, node_type);
+
+CREATE MATERIALIZED VIEW stats_edge_counts AS
+SELECT org_id, edge_type, COUNT(*)::int AS edge_count
+FROM graph_edges
+GROUP BY org_id, edge_type;
+CREATE UNIQUE INDEX idx_stats_edge_counts ON stats_edge_counts(org_id, edge_type);
+
+CREATE MATERIALIZED VIEW stats_skill_popularity AS
+SELECT s.org_id,
+       s.properties->>'name' AS skill,
+       COUNT(DISTINCT e.source_node_id)::int AS candidate_count
+FROM graph_nodes s
+JOIN graph_edges e ON e.target_node_id = s.id AND e.edge_type = 'HAS_SKILL'
+JOIN graph_nodes p ON p.id = e.source_node_id AND p.deleted_at IS NULL
+WHERE s.node_type = 'skill' AND s.properties->>'name' IS NOT NULL
+GROUP BY s.org_id, s.properties->>'name';
+CREATE UNIQUE INDEX idx_stats_skill_popularity ON stats_skill_popularity(org_id, skill);
+
+CREATE MATERIALIZED VIEW stats_skill_trend AS
+SELECT p.org_id,
+       date_trunc('month', p.created_at)::date AS month,
+       s.properties->>'name' AS skill,
+       COUNT(DISTINCT p.id)::int AS candidate_count
+FROM graph_nodes p
+JOIN graph_edges e ON e.source_node_id = p.id AND e.edge_type = 'HAS_SKILL'
+JOIN graph_nodes s ON s.id = e.target_node_id AND s.node_type = 'skill'
+WHERE p.node_type = 'person' AND p.deleted_at IS NULL
+  AND p.created_at IS NOT NULL AND s.properties->>'name' IS NOT NULL
+GROUP BY 1, 2, 3;
+CREATE UNIQUE INDEX idx_stats_skill_trend ON stats_skill_trend(org_id, month, skill);
+
+CREATE MATERIALIZED VIEW stats_seniority AS
+SELECT org_id,
+       COALESCE(NULLIF(lower(trim(properties->>'seniority')), ''), 'unknown') AS seniority,
+       COUNT(*)::int AS candidate_count
+FROM graph_nodes
+WHERE node_type = 'person' AND deleted_at IS NULL
+GROUP BY 1, 2;
+CREATE UNIQUE INDEX idx_stats_seniority ON stats_seniority(org_id, seniority);
+
+CREATE MATERIALIZED VIEW stats_community_sizes AS
+SELECT c.id, c.org_id, c.level, c.community_id, COALESCE(c.title, '') AS title,
+       COUNT(p.id)::int AS member_count
+FROM graph_communities c
+LEFT JOIN community_members cm ON cm.community_id = c.id
+LEFT JOIN graph_nodes p ON p.id = cm.node_id AND p.node_type = 'person' AND p.deleted_at IS NULL
+GROUP BY c.id, c.org_id, c.level, c.community_id, c.title;
+CREATE UNIQUE INDEX idx_stats_community_sizes ON stats_community_sizes(id);
+
+CREATE MATERIALIZED VIEW stats_uploads_weekly AS
+SELECT org_id,
+       date_trunc('week', uploaded_at)::date AS week,
+       COUNT(*)::int AS uploads,
+       COUNT(DISTINCT candidate_id)::int AS candidates
+FROM cv_files
+WHERE uploaded_at IS NOT NULL
+GROUP BY 1, 2;
+CREATE UNIQUE INDEX idx_stats_uploads_weekly ON stats_uploads_weekly(org_id, week);
+
+COMMENT ON TABLE organizations IS 'Recruiting teams sharing the deployment; every tenant row carries org_id';
+COMMENT ON COLUMN organizations.api_key_hash IS 'Hex SHA-256 of the API key that authenticates as this org';
+
+-- +goose Down
+-- Restores the single-tenant keys; fails if two organizations hold rows
+-- with the same natural key.
+DROP MATERIALIZED VIEW IF EXISTS stats_uploads_weekly;
+DROP MATERIALIZED VIEW IF EXISTS stats_community_sizes;
+DROP MATERIALIZED VIEW IF EXISTS stats_seniority;
+DROP MATERIALIZED VIEW IF EXISTS stats_skill_trend;
+DROP MATERIALIZED VIEW IF EXISTS stats_skill_popularity;
+DROP MATERIALIZED VIEW IF EXISTS stats_edge_counts;
+DROP MATERIALIZED VIEW IF EXISTS stats_node_counts;
+
+CREATE MATERIALIZED VIEW stats_node_counts AS
+SELECT node_type, COUNT(*)::int AS node_count
+FROM graph_nodes
+WHERE deleted_at IS NULL
+GROUP BY node_type;
+CREATE UNIQUE INDEX idx_stats_node_counts ON stats_node_counts(node_type);
+
+CREATE MATERIALIZED VIEW stats_edge_counts AS
+SELECT edge_type, COUNT(*)::int AS edge_count
+FROM graph_edges
+GROUP BY edge_type;
+CREATE UNIQUE INDEX idx_stats_edge_counts ON stats_edge_counts(edge_type);
+
+CREATE MATERIALIZED VIEW stats_skill_popularity AS
+SELECT s.properties->>'name' AS skill,
+       COUNT(DISTINCT e.source_node_id)::int AS candidate_count
+FROM graph_nodes s
+JOIN graph_edges e ON e.target_node_id = s.id AND e.edge_type = 'HAS_SKILL'
+JOIN graph_nodes p ON p.id = e.source_node_id AND p.deleted_at IS NULL
+WHERE s.node_type = 'skill' AND s.properties->>'name' IS NOT NULL
+GROUP BY s.properties->>'name';
+CREATE UNIQUE INDEX idx_stats_skill_popularity ON stats_skill_popularity(skill);
+
+CREATE MATERIALIZED VIEW stats_skill_trend AS
+SELECT date_trunc('month', p.created_at)::date AS month,
+       s.properties->>'name' AS skill,
+       COUNT(DISTINCT p.id)::int AS candidate_count
+FROM graph_nodes p
+JOIN graph_edges e ON e.source_node_id = p.id AND e.edge_type = 'HAS_SKILL'
+JOIN graph_nodes s ON s.id = e.target_node_id AND s.node_type = 'skill'
+WHERE p.node_type = 'person' AND p.deleted_at IS NULL
+  AND p.created_at IS NOT NULL AND s.properties->>'name' IS NOT NULL
+GROUP BY 1, 2;
+CREATE UNIQUE INDEX idx_stats_skill_trend ON stats_skill_trend(month, skill);
+
+CREATE MATERIALIZED VIEW stats_seniority AS
+SELECT COALESCE(NULLIF(lower(trim(properties->>'seniority')), ''), 'unknown') AS seniority,
+       COUNT(*)::int AS candidate_count
+FROM graph_nodes
+WHERE node_type = 'person' AND deleted_at IS NULL
+GROUP BY 1;
+CREATE UNIQUE INDEX idx_stats_seniority ON stats_seniority(seniority);
+
+CREATE MATERIALIZED VIEW stats_community_sizes AS
+SELECT c.id, c.level, c.community_id, COALESCE(c.title, '') AS title,
+       COUNT(p.id)::int AS member_count
+FROM graph_communities c
+LEFT JOIN community_members cm ON cm.community_id = c.id
+LEFT JOIN graph_nodes p ON p.id = cm.node_id AND p.node_type = 'person' AND p.deleted_at IS NULL
+GROUP BY c.id, c.level, c.community_id, c.title;
+CREATE UNIQUE INDEX idx_stats_community_sizes ON stats_community_sizes(id);
+
+CREATE MATERIALIZED VIEW stats_uploads_weekly AS
+SELECT date_trunc('week', uploaded_at)::date AS week,
+       COUNT(*)::int AS uploads,
+       COUNT(DISTINCT candidate_id)::int AS candidates
+FROM cv_files
+WHERE uploaded_at IS NOT NULL
+GROUP BY 1;
+CREATE UNIQUE INDEX idx_stats_uploads_weekly ON stats_uploads_weekly(week);
+
+DROP INDEX IF EXISTS idx_candidates_import_ref;
+CREATE UNIQUE INDEX idx_candidates_import_ref
+    ON candidates(import_source, external_id)
+    WHERE external_id IS NOT NULL AND deleted_at IS NULL;
+
+DROP INDEX IF EXISTS idx_cv_files_content_hash;
+CREATE UNIQUE INDEX idx_cv_files_content_hash ON cv_files(content_hash);
+
+ALTER TABLE graph_communities DROP CONSTRAINT IF EXISTS graph_communities_org_level_key;
+ALTER TABLE graph_communities ADD CONSTRAINT graph_communities_level_community_id_key UNIQUE (level, community_id);
+
+ALTER TABLE graph_nodes DROP CONSTRAINT IF EXISTS graph_nodes_org_node_key;
+ALTER TABLE graph_nodes ADD CONSTRAINT graph_nodes_node_type_node_id_key UNIQUE (node_type, node_id);
+
+ALTER TABLE audit_log             DROP COLUMN IF EXISTS org_id;
+ALTER TABLE search_experiment_log DROP COLUMN IF EXISTS org_id;
+ALTER TABLE search_sessions       DROP COLUMN IF EXISTS org_id;
+ALTER TABLE graph_communities     DROP COLUMN IF EXISTS org_id;
+ALTER TABLE graph_edges           DROP COLUMN IF EXISTS org_id;
+ALTER TABLE graph_nodes           DROP COLUMN IF EXISTS org_id;
+ALTER TABLE cv_files              DROP COLUMN IF EXISTS org_id;
+ALTER TABLE candidates            DROP COLUMN IF EXISTS org_id;
+DROP TABLE IF EXISTS organizations;
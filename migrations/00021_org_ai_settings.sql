@@ -0,0 +1,22 @@
+-- +goose Up
+-- An organization's own LLM and embedding provider, used instead of the
+-- deployment's (LLM_PROVIDER, EMBEDDING_PROVIDER, ...) for its searches and
+-- CV processing. An empty provider keeps the deployment's for that part.
+-- API keys are AES-256-GCM encrypted under SETTINGS_ENCRYPTION_KEY
+-- (internal/secret), never stored in the clear.
+CREATE TABLE IF NOT EXISTS organization_ai_settings (
+    org_id INTEGER PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+    llm_provider TEXT NOT NULL DEFAULT '',
+    llm_model TEXT NOT NULL DEFAULT '',
+    llm_api_key BYTEA,
+    embedding_provider TEXT NOT NULL DEFAULT '',
+    embedding_model TEXT NOT NULL DEFAULT '',
+    embedding_dimensions INTEGER NOT NULL DEFAULT 0,
+    embedding_api_key BYTEA,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE organization_ai_settings IS 'Per-organization LLM / embedding provider overrides (API keys encrypted)';
+
+-- +goose Down
+DROP TABLE IF EXISTS organization_ai_settings;
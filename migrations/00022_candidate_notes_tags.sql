@@ -0,0 +1,33 @@
+-- +goose Up
+-- Recruiter notes and tags on candidates ("shortlisted-q3", "contacted",
+-- "do-not-contact"). Both are scoped to an organization through their
+-- candidate. author / created_by is the API actor that wrote the row
+-- ("user:<X-User-ID>", "key:<hash>"). Tags are stored lowercase; hybrid
+-- search can require, exclude and boost them.
+CREATE TABLE IF NOT EXISTS candidate_notes (
+    id SERIAL PRIMARY KEY,
+    candidate_id INTEGER NOT NULL REFERENCES candidates(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    author TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_candidate_notes_candidate ON candidate_notes(candidate_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS candidate_tags (
+    candidate_id INTEGER NOT NULL REFERENCES candidates(id) ON DELETE CASCADE,
+    tag TEXT NOT NULL,
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (candidate_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_candidate_tags_tag ON candidate_tags(tag);
+
+COMMENT ON TABLE candidate_notes IS 'Free-text recruiter notes on a candidate';
+COMMENT ON TABLE candidate_tags IS 'Recruiter tags on a candidate, usable as hybrid search filters and boosts';
+
+-- +goose Down
+DROP TABLE IF EXISTS candidate_tags;
+DROP TABLE IF EXISTS candidate_notes;
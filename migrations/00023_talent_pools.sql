@@ -0,0 +1,37 @@
+-- +goose Up
+-- Talent pools: named shortlists of candidates, each member at a pipeline
+-- stage (sourced → screened → interviewed → offered → hired, or rejected at
+-- any point). A lightweight pipeline for search results without an external
+-- ATS. Pools belong to an organization; members must be candidates of the
+-- same organization.
+CREATE TABLE IF NOT EXISTS talent_pools (
+    id SERIAL PRIMARY KEY,
+    org_id INTEGER NOT NULL DEFAULT 1 REFERENCES organizations(id),
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (org_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS talent_pool_members (
+    pool_id INTEGER NOT NULL REFERENCES talent_pools(id) ON DELETE CASCADE,
+    candidate_id INTEGER NOT NULL REFERENCES candidates(id) ON DELETE CASCADE,
+    stage TEXT NOT NULL DEFAULT 'sourced'
+        CHECK (stage IN ('sourced', 'screened', 'interviewed', 'offered', 'hired', 'rejected')),
+    added_by TEXT NOT NULL DEFAULT '',
+    added_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    stage_changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (pool_id, candidate_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_talent_pool_members_candidate ON talent_pool_members(candidate_id);
+CREATE INDEX IF NOT EXISTS idx_talent_pool_members_stage ON talent_pool_members(pool_id, stage);
+
+COMMENT ON TABLE talent_pools IS 'Named candidate shortlists (per organization)';
+COMMENT ON TABLE talent_pool_members IS 'Candidates in a talent pool and their pipeline stage';
+
+-- +goose Down
+DROP TABLE IF EXISTS talent_pool_members;
+DROP TABLE IF EXISTS talent_pools;
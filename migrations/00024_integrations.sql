@@ -0,0 +1,34 @@
+-- +goose Up
+-- ATS integrations: an organization's Greenhouse / Lever credentials, used by
+-- POST /api/candidates/{id}/push to create the candidate in that ATS
+-- (internal/integrations). API keys are AES-256-GCM encrypted under
+-- SETTINGS_ENCRYPTION_KEY (internal/secret), like organization_ai_settings.
+CREATE TABLE IF NOT EXISTS organization_integrations (
+    org_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    target TEXT NOT NULL CHECK (target IN ('greenhouse', 'lever')),
+    api_key BYTEA NOT NULL,
+    user_id TEXT NOT NULL,
+    job_id TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (org_id, target)
+);
+
+-- One row per candidate and target: where the candidate was pushed, so a
+-- second push is refused unless forced (which overwrites the row).
+CREATE TABLE IF NOT EXISTS candidate_pushes (
+    id SERIAL PRIMARY KEY,
+    candidate_id INTEGER NOT NULL REFERENCES candidates(id) ON DELETE CASCADE,
+    target TEXT NOT NULL,
+    external_id TEXT NOT NULL,
+    external_url TEXT NOT NULL DEFAULT '',
+    pushed_by TEXT NOT NULL DEFAULT '',
+    pushed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (candidate_id, target)
+);
+
+COMMENT ON TABLE organization_integrations IS 'Per-organization ATS credentials (API keys encrypted)';
+COMMENT ON TABLE candidate_pushes IS 'Candidates pushed to an external ATS and their ID there';
+
+-- +goose Down
+DROP TABLE IF EXISTS candidate_pushes;
+DROP TABLE IF EXISTS organization_integrations;
@@ -0,0 +1,39 @@
+-- +goose Up
+-- Email notifications (internal/notify). A recruiter — the X-User-ID of
+-- their requests — sets an address and which emails they want, per
+-- organization: a report when their bulk upload has been processed, and a
+-- weekly digest of the organization's new candidates.
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    org_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL,
+    email TEXT NOT NULL,
+    batch_complete BOOLEAN NOT NULL DEFAULT TRUE,
+    weekly_digest BOOLEAN NOT NULL DEFAULT FALSE,
+    last_digest_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (org_id, user_id)
+);
+
+-- Bulk uploads waiting for their report: sent once every job is completed or
+-- failed. Files refused at upload are kept here, since they never got a job.
+CREATE TABLE IF NOT EXISTS batch_notifications (
+    batch_id TEXT PRIMARY KEY,
+    org_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    email TEXT NOT NULL,
+    files INTEGER NOT NULL,
+    job_ids BIGINT[] NOT NULL DEFAULT '{}',
+    duplicates INTEGER NOT NULL DEFAULT 0,
+    upload_failures JSONB NOT NULL DEFAULT '[]', -- [{"filename", "reason"}]
+    attempts INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_batch_notifications_pending ON batch_notifications(created_at) WHERE sent_at IS NULL;
+
+COMMENT ON TABLE notification_preferences IS 'Per-user email notification settings (per organization)';
+COMMENT ON TABLE batch_notifications IS 'Bulk uploads whose completion email is pending or sent';
+
+-- +goose Down
+DROP TABLE IF EXISTS batch_notifications;
+DROP TABLE IF EXISTS notification_preferences;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- What the uploader said about a CV besides the file: where it came from
+-- (source, e.g. "referral", "linkedin") and tags for its candidate. The tags
+-- are added to candidate_tags once extraction has linked the CV to a
+-- candidate (the candidate_id form field links it up front).
+ALTER TABLE cv_files ADD COLUMN IF NOT EXISTS source TEXT;
+ALTER TABLE cv_files ADD COLUMN IF NOT EXISTS upload_tags TEXT[] NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN cv_files.source IS 'Where the CV came from, as given at upload';
+COMMENT ON COLUMN cv_files.upload_tags IS 'Normalized candidate tags given at upload';
+
+-- +goose Down
+ALTER TABLE cv_files DROP COLUMN IF EXISTS upload_tags;
+ALTER TABLE cv_files DROP COLUMN IF EXISTS source;
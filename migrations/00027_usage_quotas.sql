@@ -0,0 +1,52 @@
+-- +goose Up
+-- Usage quotas per organization: uploads and searches per day, LLM tokens
+-- per month. LLM usage is now recorded per organization (the one the request
+-- or job ran for); rows from before belong to the default organization.
+ALTER TABLE llm_usage ADD COLUMN IF NOT EXISTS org_id INTEGER NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE llm_usage DROP CONSTRAINT IF EXISTS llm_usage_pkey;
+ALTER TABLE llm_usage ADD PRIMARY KEY (day, org_id, provider, model);
+CREATE INDEX IF NOT EXISTS idx_llm_usage_org_day ON llm_usage(org_id, day);
+
+-- Metered requests per organization and day.
+CREATE TABLE IF NOT EXISTS org_usage (
+    org_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    uploads INTEGER NOT NULL DEFAULT 0,   -- CVs stored
+    searches INTEGER NOT NULL DEFAULT 0,  -- searches answered
+    PRIMARY KEY (org_id, day)
+);
+
+-- An organization's own quotas. NULL keeps the deployment's default
+-- (QUOTA_* env), 0 is unlimited.
+CREATE TABLE IF NOT EXISTS organization_quotas (
+    org_id INTEGER PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+    uploads_per_day INTEGER,
+    searches_per_day INTEGER,
+    llm_tokens_per_month BIGINT,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE org_usage IS 'Uploads and searches per organization and day, for quotas and GET /api/usage';
+COMMENT ON TABLE organization_quotas IS 'Per-organization quota overrides (NULL = deployment default, 0 = unlimited)';
+
+-- +goose Down
+DROP TABLE IF EXISTS organization_quotas;
+DROP TABLE IF EXISTS org_usage;
+
+-- Fold the organizations' rows back into one per day/provider/model.
+DROP INDEX IF EXISTS idx_llm_usage_org_day;
+CREATE TEMP TABLE llm_usage_total AS
+SELECT day, provider, model,
+       SUM(requests)::int AS requests,
+       SUM(prompt_tokens)::bigint AS prompt_tokens,
+       SUM(completion_tokens)::bigint AS completion_tokens,
+       SUM(cost_usd) AS cost_usd
+FROM llm_usage
+GROUP BY day, provider, model;
+DELETE FROM llm_usage;
+ALTER TABLE llm_usage DROP CONSTRAINT IF EXISTS llm_usage_pkey;
+ALTER TABLE llm_usage DROP COLUMN IF EXISTS org_id;
+INSERT INTO llm_usage (day, provider, model, requests, prompt_tokens, completion_tokens, cost_usd)
+SELECT day, provider, model, requests, prompt_tokens, completion_tokens, cost_usd FROM llm_usage_total;
+ALTER TABLE llm_usage ADD PRIMARY KEY (day, provider, model);
+DROP TABLE llm_usage_total;
@@ -0,0 +1,24 @@
+-- +goose Up
+-- Idempotency-Key header of mutating requests: the first request with a key
+-- reserves it, its response is stored, and a retry with the same key and
+-- request gets that response back instead of running again.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    org_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    key TEXT NOT NULL,
+    method TEXT NOT NULL,
+    path TEXT NOT NULL,
+    fingerprint TEXT,                     -- sha256 of method, URL and body; NULL while in progress
+    status_code INTEGER,                  -- NULL while in progress
+    content_type TEXT,
+    body BYTEA,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (org_id, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created ON idempotency_keys(created_at);
+
+COMMENT ON TABLE idempotency_keys IS 'Idempotency-Key reservations and stored responses, replayed to retries';
+
+-- +goose Down
+DROP TABLE IF EXISTS idempotency_keys;
@@ -0,0 +1,251 @@
+-- +goose Up
+-- =====================================================
+-- Location normalization
+-- =====================================================
+-- CVs spell the same place many ways ("Istanbul", "İstanbul, Türkiye",
+-- "Istanbul/Remote", "Kadıköy"). Every known spelling is an alias, stored
+-- tr_fold()ed, of one canonical city or country with its coordinates; person
+-- nodes keep the location their CV resolves to, so searches match on the
+-- location itself or on the distance to it.
+
+CREATE TABLE IF NOT EXISTS locations (
+    id SERIAL PRIMARY KEY,
+    kind TEXT NOT NULL CHECK (kind IN ('city', 'country')),
+    name TEXT NOT NULL,
+    country TEXT NOT NULL,
+    country_code CHAR(2) NOT NULL,        -- ISO 3166-1 alpha-2
+    lat DOUBLE PRECISION NOT NULL,        -- a country's is its centroid
+    lon DOUBLE PRECISION NOT NULL,
+    UNIQUE (kind, country_code, name)
+);
+
+CREATE TABLE IF NOT EXISTS location_aliases (
+    alias TEXT PRIMARY KEY,               -- tr_fold()ed; matched against textnorm.Fold()ed input
+    location_id INTEGER NOT NULL REFERENCES locations(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_location_aliases_location ON location_aliases(location_id);
+
+INSERT INTO locations (kind, name, country, country_code, lat, lon) VALUES
+    -- Countries
+    ('country', 'Türkiye', 'Türkiye', 'TR', 39.0, 35.0),
+    ('country', 'Germany', 'Germany', 'DE', 51.1657, 10.4515),
+    ('country', 'Netherlands', 'Netherlands', 'NL', 52.1326, 5.2913),
+    ('country', 'United Kingdom', 'United Kingdom', 'GB', 55.3781, -3.4360),
+    ('country', 'Ireland', 'Ireland', 'IE', 53.4129, -8.2439),
+    ('country', 'France', 'France', 'FR', 46.2276, 2.2137),
+    ('country', 'Spain', 'Spain', 'ES', 40.4637, -3.7492),
+    ('country', 'Portugal', 'Portugal', 'PT', 39.3999, -8.2245),
+    ('country', 'Italy', 'Italy', 'IT', 41.8719, 12.5674),
+    ('country', 'Belgium', 'Belgium', 'BE', 50.5039, 4.4699),
+    ('country', 'Switzerland', 'Switzerland', 'CH', 46.8182, 8.2275),
+    ('country', 'Austria', 'Austria', 'AT', 47.5162, 14.5501),
+    ('country', 'Poland', 'Poland', 'PL', 51.9194, 19.1451),
+    ('country', 'Czechia', 'Czechia', 'CZ', 49.8175, 15.4730),
+    ('country', 'Hungary', 'Hungary', 'HU', 47.1625, 19.5033),
+    ('country', 'Romania', 'Romania', 'RO', 45.9432, 24.9668),
+    ('country', 'Bulgaria', 'Bulgaria', 'BG', 42.7339, 25.4858),
+    ('country', 'Ukraine', 'Ukraine', 'UA', 48.3794, 31.1656),
+    ('country', 'Sweden', 'Sweden', 'SE', 60.1282, 18.6435),
+    ('country', 'Norway', 'Norway', 'NO', 60.4720, 8.4689),
+    ('country', 'Denmark', 'Denmark', 'DK', 56.2639, 9.5018),
+    ('country', 'Finland', 'Finland', 'FI', 61.9241, 25.7482),
+    ('country', 'Estonia', 'Estonia', 'EE', 58.5953, 25.0136),
+    ('country', 'Azerbaijan', 'Azerbaijan', 'AZ', 40.1431, 47.5769),
+    ('country', 'United Arab Emirates', 'United Arab Emirates', 'AE', 23.4241, 53.8478),
+    ('country', 'United States', 'United States', 'US', 39.8283, -98.5795),
+    ('country', 'Canada', 'Canada', 'CA', 56.1304, -106.3468),
+
+    -- Türkiye
+    ('city', 'İstanbul', 'Türkiye', 'TR', 41.0082, 28.9784),
+    ('city', 'Ankara', 'Türkiye', 'TR', 39.9334, 32.8597),
+    ('city', 'İzmir', 'Türkiye', 'TR', 38.4237, 27.1428),
+    ('city', 'Bursa', 'Türkiye', 'TR', 40.1885, 29.0610),
+    ('city', 'Antalya', 'Türkiye', 'TR', 36.8969, 30.7133),
+    ('city', 'Kocaeli', 'Türkiye', 'TR', 40.7654, 29.9408),
+    ('city', 'Gebze', 'Türkiye', 'TR', 40.8027, 29.4307),
+    ('city', 'Sakarya', 'Türkiye', 'TR', 40.7569, 30.3781),
+    ('city', 'Tekirdağ', 'Türkiye', 'TR', 40.9780, 27.5110),
+    ('city', 'Edirne', 'Türkiye', 'TR', 41.6771, 26.5557),
+    ('city', 'Çanakkale', 'Türkiye', 'TR', 40.1553, 26.4142),
+    ('city', 'Balıkesir', 'Türkiye', 'TR', 39.6484, 27.8826),
+    ('city', 'Manisa', 'Türkiye', 'TR', 38.6191, 27.4289),
+    ('city', 'Aydın', 'Türkiye', 'TR', 37.8560, 27.8416),
+    ('city', 'Muğla', 'Türkiye', 'TR', 37.2153, 28.3636),
+    ('city', 'Denizli', 'Türkiye', 'TR', 37.7765, 29.0864),
+    ('city', 'Eskişehir', 'Türkiye', 'TR', 39.7767, 30.5206),
+    ('city', 'Bolu', 'Türkiye', 'TR', 40.7392, 31.6089),
+    ('city', 'Zonguldak', 'Türkiye', 'TR', 41.4564, 31.7987),
+    ('city', 'Isparta', 'Türkiye', 'TR', 37.7648, 30.5566),
+    ('city', 'Konya', 'Türkiye', 'TR', 37.8746, 32.4932),
+    ('city', 'Kayseri', 'Türkiye', 'TR', 38.7205, 35.4826),
+    ('city', 'Mersin', 'Türkiye', 'TR', 36.8121, 34.6415),
+    ('city', 'Adana', 'Türkiye', 'TR', 37.0000, 35.3213),
+    ('city', 'Hatay', 'Türkiye', 'TR', 36.2021, 36.1600),
+    ('city', 'Gaziantep', 'Türkiye', 'TR', 37.0662, 37.3833),
+    ('city', 'Malatya', 'Türkiye', 'TR', 38.3552, 38.3095),
+    ('city', 'Diyarbakır', 'Türkiye', 'TR', 37.9144, 40.2306),
+    ('city', 'Samsun', 'Türkiye', 'TR', 41.2867, 36.3300),
+    ('city', 'Trabzon', 'Türkiye', 'TR', 41.0015, 39.7178),
+    ('city', 'Erzurum', 'Türkiye', 'TR', 39.9043, 41.2679),
+
+    -- Abroad
+    ('city', 'London', 'United Kingdom', 'GB', 51.5074, -0.1278),
+    ('city', 'Dublin', 'Ireland', 'IE', 53.3498, -6.2603),
+    ('city', 'Berlin', 'Germany', 'DE', 52.5200, 13.4050),
+    ('city', 'Munich', 'Germany', 'DE', 48.1351, 11.5820),
+    ('city', 'Hamburg', 'Germany', 'DE', 53.5511, 9.9937),
+    ('city', 'Frankfurt', 'Germany', 'DE', 50.1109, 8.6821),
+    ('city', 'Amsterdam', 'Netherlands', 'NL', 52.3676, 4.9041),
+    ('city', 'Rotterdam', 'Netherlands', 'NL', 51.9244, 4.4777),
+    ('city', 'Brussels', 'Belgium', 'BE', 50.8503, 4.3517),
+    ('city', 'Paris', 'France', 'FR', 48.8566, 2.3522),
+    ('city', 'Madrid', 'Spain', 'ES', 40.4168, -3.7038),
+    ('city', 'Barcelona', 'Spain', 'ES', 41.3874, 2.1686),
+    ('city', 'Lisbon', 'Portugal', 'PT', 38.7223, -9.1393),
+    ('city', 'Milan', 'Italy', 'IT', 45.4642, 9.1900),
+    ('city', 'Rome', 'Italy', 'IT', 41.9028, 12.4964),
+    ('city', 'Zurich', 'Switzerland', 'CH', 47.3769, 8.5417),
+    ('city', 'Vienna', 'Austria', 'AT', 48.2082, 16.3738),
+    ('city', 'Warsaw', 'Poland', 'PL', 52.2297, 21.0122),
+    ('city', 'Kraków', 'Poland', 'PL', 50.0647, 19.9450),
+    ('city', 'Prague', 'Czechia', 'CZ', 50.0755, 14.4378),
+    ('city', 'Budapest', 'Hungary', 'HU', 47.4979, 19.0402),
+    ('city', 'Bucharest', 'Romania', 'RO', 44.4268, 26.1025),
+    ('city', 'Sofia', 'Bulgaria', 'BG', 42.6977, 23.3219),
+    ('city', 'Kyiv', 'Ukraine', 'UA', 50.4501, 30.5234),
+    ('city', 'Stockholm', 'Sweden', 'SE', 59.3293, 18.0686),
+    ('city', 'Oslo', 'Norway', 'NO', 59.9139, 10.7522),
+    ('city', 'Copenhagen', 'Denmark', 'DK', 55.6761, 12.5683),
+    ('city', 'Helsinki', 'Finland', 'FI', 60.1699, 24.9384),
+    ('city', 'Tallinn', 'Estonia', 'EE', 59.4370, 24.7536),
+    ('city', 'Baku', 'Azerbaijan', 'AZ', 40.4093, 49.8671),
+    ('city', 'Dubai', 'United Arab Emirates', 'AE', 25.2048, 55.2708),
+    ('city', 'New York', 'United States', 'US', 40.7128, -74.0060),
+    ('city', 'San Francisco', 'United States', 'US', 37.7749, -122.4194),
+    ('city', 'Seattle', 'United States', 'US', 47.6062, -122.3321),
+    ('city', 'Austin', 'United States', 'US', 30.2672, -97.7431),
+    ('city', 'Toronto', 'Canada', 'CA', 43.6532, -79.3832),
+    ('city', 'Vancouver', 'Canada', 'CA', 49.2827, -123.1207)
+ON CONFLICT DO NOTHING;
+
+-- Every location is an alias of itself
+INSERT INTO location_aliases (alias, location_id)
+SELECT tr_fold(name), id FROM locations
+ON CONFLICT DO NOTHING;
+
+-- Other spellings: English/Turkish/local names, districts and tech parks
+INSERT INTO location_aliases (alias, location_id)
+SELECT tr_fold(a.alias), l.id
+FROM (VALUES
+    ('country', 'TR', 'Türkiye', 'Turkey'),
+    ('country', 'TR', 'Türkiye', 'Türkiye Cumhuriyeti'),
+    ('country', 'DE', 'Germany', 'Deutschland'),
+    ('country', 'DE', 'Germany', 'Almanya'),
+    ('country', 'NL', 'Netherlands', 'The Netherlands'),
+    ('country', 'NL', 'Netherlands', 'Holland'),
+    ('country', 'NL', 'Netherlands', 'Hollanda'),
+    ('country', 'GB', 'United Kingdom', 'UK'),
+    ('country', 'GB', 'United Kingdom', 'England'),
+    ('country', 'GB', 'United Kingdom', 'Great Britain'),
+    ('country', 'GB', 'United Kingdom', 'İngiltere'),
+    ('country', 'GB', 'United Kingdom', 'Birleşik Krallık'),
+    ('country', 'IE', 'Ireland', 'İrlanda'),
+    ('country', 'FR', 'France', 'Fransa'),
+    ('country', 'ES', 'Spain', 'İspanya'),
+    ('country', 'PT', 'Portugal', 'Portekiz'),
+    ('country', 'IT', 'Italy', 'İtalya'),
+    ('country', 'BE', 'Belgium', 'Belçika'),
+    ('country', 'CH', 'Switzerland', 'İsviçre'),
+    ('country', 'AT', 'Austria', 'Avusturya'),
+    ('country', 'PL', 'Poland', 'Polonya'),
+    ('country', 'CZ', 'Czechia', 'Czech Republic'),
+    ('country', 'CZ', 'Czechia', 'Çekya'),
+    ('country', 'HU', 'Hungary', 'Macaristan'),
+    ('country', 'RO', 'Romania', 'Romanya'),
+    ('country', 'BG', 'Bulgaria', 'Bulgaristan'),
+    ('country', 'UA', 'Ukraine', 'Ukrayna'),
+    ('country', 'SE', 'Sweden', 'İsveç'),
+    ('country', 'NO', 'Norway', 'Norveç'),
+    ('country', 'DK', 'Denmark', 'Danimarka'),
+    ('country', 'FI', 'Finland', 'Finlandiya'),
+    ('country', 'EE', 'Estonia', 'Estonya'),
+    ('country', 'AZ', 'Azerbaijan', 'Azerbaycan'),
+    ('country', 'AE', 'United Arab Emirates', 'UAE'),
+    ('country', 'AE', 'United Arab Emirates', 'Birleşik Arap Emirlikleri'),
+    ('country', 'AE', 'United Arab Emirates', 'BAE'),
+    ('country', 'US', 'United States', 'USA'),
+    ('country', 'US', 'United States', 'United States of America'),
+    ('country', 'US', 'United States', 'ABD'),
+    ('country', 'US', 'United States', 'Amerika'),
+    ('country', 'CA', 'Canada', 'Kanada'),
+
+    ('city', 'TR', 'İstanbul', 'Constantinople'),
+    ('city', 'TR', 'İstanbul', 'Kadıköy'),
+    ('city', 'TR', 'İstanbul', 'Beşiktaş'),
+    ('city', 'TR', 'İstanbul', 'Şişli'),
+    ('city', 'TR', 'İstanbul', 'Maslak'),
+    ('city', 'TR', 'İstanbul', 'Levent'),
+    ('city', 'TR', 'İstanbul', 'Sarıyer'),
+    ('city', 'TR', 'İstanbul', 'Üsküdar'),
+    ('city', 'TR', 'İstanbul', 'Ataşehir'),
+    ('city', 'TR', 'İstanbul', 'Ümraniye'),
+    ('city', 'TR', 'İstanbul', 'Kartal'),
+    ('city', 'TR', 'İstanbul', 'Pendik'),
+    ('city', 'TR', 'İstanbul', 'Bakırköy'),
+    ('city', 'TR', 'İstanbul', 'Beylikdüzü'),
+    ('city', 'TR', 'İstanbul', 'Esenyurt'),
+    ('city', 'TR', 'İstanbul', 'Beyoğlu'),
+    ('city', 'TR', 'İstanbul', 'İTÜ Arı Teknokent'),
+    ('city', 'TR', 'Ankara', 'Çankaya'),
+    ('city', 'TR', 'Ankara', 'Yenimahalle'),
+    ('city', 'TR', 'Ankara', 'ODTÜ Teknokent'),
+    ('city', 'TR', 'Ankara', 'Bilkent Cyberpark'),
+    ('city', 'TR', 'İzmir', 'Smyrna'),
+    ('city', 'TR', 'İzmir', 'Bornova'),
+    ('city', 'TR', 'İzmir', 'Karşıyaka'),
+    ('city', 'TR', 'Kocaeli', 'İzmit'),
+    ('city', 'TR', 'Sakarya', 'Adapazarı'),
+    ('city', 'TR', 'Hatay', 'Antakya'),
+    ('city', 'TR', 'Muğla', 'Bodrum'),
+
+    ('city', 'GB', 'London', 'Londra'),
+    ('city', 'DE', 'Munich', 'München'),
+    ('city', 'DE', 'Munich', 'Münih'),
+    ('city', 'DE', 'Frankfurt', 'Frankfurt am Main'),
+    ('city', 'BE', 'Brussels', 'Bruxelles'),
+    ('city', 'BE', 'Brussels', 'Brüksel'),
+    ('city', 'PT', 'Lisbon', 'Lisboa'),
+    ('city', 'PT', 'Lisbon', 'Lizbon'),
+    ('city', 'IT', 'Milan', 'Milano'),
+    ('city', 'IT', 'Rome', 'Roma'),
+    ('city', 'CH', 'Zurich', 'Zürich'),
+    ('city', 'AT', 'Vienna', 'Wien'),
+    ('city', 'AT', 'Vienna', 'Viyana'),
+    ('city', 'PL', 'Warsaw', 'Warszawa'),
+    ('city', 'PL', 'Warsaw', 'Varşova'),
+    ('city', 'PL', 'Kraków', 'Krakow'),
+    ('city', 'CZ', 'Prague', 'Praha'),
+    ('city', 'CZ', 'Prague', 'Prag'),
+    ('city', 'RO', 'Bucharest', 'București'),
+    ('city', 'RO', 'Bucharest', 'Bükreş'),
+    ('city', 'BG', 'Sofia', 'Sofya'),
+    ('city', 'UA', 'Kyiv', 'Kiev'),
+    ('city', 'DK', 'Copenhagen', 'København'),
+    ('city', 'DK', 'Copenhagen', 'Kopenhag'),
+    ('city', 'AZ', 'Baku', 'Bakü'),
+    ('city', 'AZ', 'Baku', 'Bakı'),
+    ('city', 'US', 'New York', 'New York City'),
+    ('city', 'US', 'New York', 'NYC'),
+    ('city', 'US', 'San Francisco', 'SF'),
+    ('city', 'US', 'San Francisco', 'Bay Area')
+) AS a(kind, country_code, name, alias)
+JOIN locations l ON l.kind = a.kind AND l.country_code = a.country_code AND l.name = a.name
+ON CONFLICT DO NOTHING;
+
+COMMENT ON TABLE locations IS 'Canonical cities and countries with coordinates, for location matching and radius search';
+COMMENT ON TABLE location_aliases IS 'tr_fold()ed spellings of each location (names, translations, districts)';
+
+-- +goose Down
+DROP TABLE IF EXISTS location_aliases;
+DROP TABLE IF EXISTS locations;
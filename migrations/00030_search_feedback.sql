@@ -0,0 +1,34 @@
+-- +goose Up
+-- Recruiter feedback on hybrid search results, for tuning ranking weights and
+-- prompts against real outcomes. Each logged search gets an ID the response
+-- carries and the features of every result as served; feedback on a result
+-- keeps a copy of them, so each feedback row is a complete training example.
+ALTER TABLE search_experiment_log ADD COLUMN IF NOT EXISTS search_id TEXT;
+ALTER TABLE search_experiment_log ADD COLUMN IF NOT EXISTS results JSONB; -- per-result scores and features as served
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_search_experiment_log_search_id ON search_experiment_log(search_id) WHERE search_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS search_feedback (
+    id BIGSERIAL PRIMARY KEY,
+    org_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    search_id TEXT NOT NULL,
+    candidate_id INTEGER NOT NULL REFERENCES candidates(id) ON DELETE CASCADE,
+    label TEXT NOT NULL CHECK (label IN ('good', 'bad', 'hired')),
+    score_override DOUBLE PRECISION CHECK (score_override BETWEEN 0 AND 100), -- the score the recruiter would have given (LLM scale)
+    comment TEXT,
+    features JSONB,                       -- the result's entry of search_experiment_log.results
+    created_by TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (org_id, search_id, candidate_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_search_feedback_org_created ON search_feedback(org_id, created_at);
+
+COMMENT ON TABLE search_feedback IS 'Recruiter labels (good/bad/hired) and score overrides on search results, exported for ranking tuning';
+
+-- +goose Down
+DROP TABLE IF EXISTS search_feedback;
+DROP INDEX IF EXISTS idx_search_experiment_log_search_id;
+ALTER TABLE search_experiment_log DROP COLUMN IF EXISTS results;
+ALTER TABLE search_experiment_log DROP COLUMN IF EXISTS search_id;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- Read-only share links to a candidate profile, for hiring managers without
+-- an account. The URL carries a token signed with SHARE_LINK_SECRET over the
+-- link's id and expiry; the row is what can be revoked and counts the views.
+CREATE TABLE IF NOT EXISTS share_links (
+    id BIGSERIAL PRIMARY KEY,
+    org_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    candidate_id INTEGER NOT NULL REFERENCES candidates(id) ON DELETE CASCADE,
+    anonymized BOOLEAN NOT NULL DEFAULT FALSE, -- no name, employers or schools on the shared profile
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    views INTEGER NOT NULL DEFAULT 0,
+    last_viewed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_share_links_org_candidate ON share_links(org_id, candidate_id);
+
+COMMENT ON TABLE share_links IS 'Expiring, revocable read-only links to a candidate profile (GET /share/{token})';
+
+-- +goose Down
+DROP TABLE IF EXISTS share_links;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- An organization's own keyword communities: candidates without detected
+-- communities are placed by their skills, searches by their skills and job
+-- titles. Laid over graphrag.DefaultCommunities; a row with a default's
+-- pattern_id replaces that default for the organization.
+CREATE TABLE IF NOT EXISTS organization_community_patterns (
+    org_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    pattern_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    key_skills TEXT[] NOT NULL DEFAULT '{}',
+    key_titles TEXT[] NOT NULL DEFAULT '{}',
+    updated_by TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (org_id, pattern_id)
+);
+
+COMMENT ON TABLE organization_community_patterns IS 'Per-organization keyword community patterns, merged with the built-in defaults';
+
+-- +goose Down
+DROP TABLE IF EXISTS organization_community_patterns;
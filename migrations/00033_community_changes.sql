@@ -0,0 +1,30 @@
+-- +goose Up
+-- Changelog of community detection: each run matches its clusters to the
+-- previous communities of the level by shared members and records the ones
+-- created, dissolved, or drifted past COMMUNITY_DRIFT_* (size change, member
+-- churn, cohesion drop). Only created and drifted communities get a new LLM
+-- summary.
+CREATE TABLE IF NOT EXISTS community_changes (
+    id BIGSERIAL PRIMARY KEY,
+    org_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    community_id INTEGER NOT NULL REFERENCES graph_communities(id) ON DELETE CASCADE,
+    level INTEGER NOT NULL,
+    change TEXT NOT NULL CHECK (change IN ('created', 'drifted', 'dissolved')),
+    reasons TEXT[] NOT NULL DEFAULT '{}', -- size, churn, cohesion (drifted)
+    title TEXT NOT NULL DEFAULT '',
+    previous_title TEXT NOT NULL DEFAULT '',
+    size INTEGER NOT NULL DEFAULT 0,
+    previous_size INTEGER NOT NULL DEFAULT 0,
+    churn REAL NOT NULL DEFAULT 0,             -- 1 - Jaccard similarity of the old and new members
+    cohesion REAL NOT NULL DEFAULT 0,          -- mean membership strength
+    previous_cohesion REAL NOT NULL DEFAULT 0,
+    resummarized BOOLEAN NOT NULL DEFAULT FALSE,
+    detected_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_community_changes_org_detected ON community_changes(org_id, detected_at DESC);
+
+COMMENT ON TABLE community_changes IS 'Communities created, drifted or dissolved by each detection run';
+
+-- +goose Down
+DROP TABLE IF EXISTS community_changes;
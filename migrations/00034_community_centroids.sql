@@ -0,0 +1,19 @@
+-- +goose Up
+-- Mean of a community's member node embeddings, next to the embedding of its
+-- LLM summary: query relevance combines both (graphrag/community_relevance.go).
+-- Recomputed by every detection run and embedding model swap. Without a
+-- dimension, so a model swap to other dimensions doesn't need a migration.
+ALTER TABLE graph_communities ADD COLUMN IF NOT EXISTS centroid_embedding vector;
+
+UPDATE graph_communities c
+SET centroid_embedding = (
+    SELECT AVG(gn.embedding)
+    FROM community_members cm
+    JOIN graph_nodes gn ON gn.id = cm.node_id
+    WHERE cm.community_id = c.id AND gn.embedding IS NOT NULL AND gn.deleted_at IS NULL
+);
+
+COMMENT ON COLUMN graph_communities.centroid_embedding IS 'Mean of the live members'' node embeddings';
+
+-- +goose Down
+ALTER TABLE graph_communities DROP COLUMN IF EXISTS centroid_embedding;
@@ -0,0 +1,57 @@
+-- +goose Up
+-- Graph analytics, recomputed per organization by
+-- graphrag.ComputeGraphAnalytics (on demand or every
+-- GRAPH_ANALYTICS_INTERVAL_MINUTES) and served by /api/graph/analytics.
+
+-- Pairs of skills (skill_a < skill_b) held by the same people. lift is
+-- candidates * people with any skill / (skill_a_candidates * skill_b_candidates):
+-- 1 if the skills were independent, below 1 for rare combinations.
+CREATE TABLE IF NOT EXISTS graph_skill_cooccurrence (
+    org_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    skill_a TEXT NOT NULL,
+    skill_b TEXT NOT NULL,
+    candidates INTEGER NOT NULL,          -- people with both
+    skill_a_candidates INTEGER NOT NULL,  -- people with skill_a
+    skill_b_candidates INTEGER NOT NULL,  -- people with skill_b
+    lift REAL NOT NULL,
+    PRIMARY KEY (org_id, skill_a, skill_b)
+);
+
+CREATE INDEX IF NOT EXISTS idx_graph_skill_cooccurrence_org_candidates ON graph_skill_cooccurrence(org_id, candidates DESC);
+
+-- Current (WORKS_AT) and former (WORKED_AT) employees of each company.
+CREATE TABLE IF NOT EXISTS graph_company_alumni (
+    org_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    company TEXT NOT NULL,
+    current_employees INTEGER NOT NULL DEFAULT 0,
+    former_employees INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (org_id, company)
+);
+
+-- Degree (distinct neighbours) and PageRank (undirected, average node 1) of
+-- each person node.
+CREATE TABLE IF NOT EXISTS graph_person_centrality (
+    node_id INTEGER PRIMARY KEY REFERENCES graph_nodes(id) ON DELETE CASCADE,
+    org_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    degree INTEGER NOT NULL DEFAULT 0,
+    pagerank REAL NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_graph_person_centrality_org_pagerank ON graph_person_centrality(org_id, pagerank DESC);
+
+CREATE TABLE IF NOT EXISTS graph_analytics_runs (
+    org_id INTEGER PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+    computed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    duration_ms BIGINT NOT NULL DEFAULT 0
+);
+
+COMMENT ON TABLE graph_skill_cooccurrence IS 'Skill pairs held by the same people, with lift';
+COMMENT ON TABLE graph_company_alumni IS 'Current and former employees per company';
+COMMENT ON TABLE graph_person_centrality IS 'Degree and PageRank of person nodes';
+COMMENT ON TABLE graph_analytics_runs IS 'Last graph analytics computation per organization';
+
+-- +goose Down
+DROP TABLE IF EXISTS graph_analytics_runs;
+DROP TABLE IF EXISTS graph_person_centrality;
+DROP TABLE IF EXISTS graph_company_alumni;
+DROP TABLE IF EXISTS graph_skill_cooccurrence;
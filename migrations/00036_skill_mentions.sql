@@ -0,0 +1,36 @@
+-- +goose Up
+-- Trending skills (GET /api/graph/skills/trending).
+--
+-- graph_nodes.created_at is when a node was first seen; last_seen_at is
+-- bumped by the graph builder every time a CV mentions it again. Existing
+-- nodes take it from their newest edge.
+ALTER TABLE graph_nodes ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMP WITH TIME ZONE DEFAULT NOW();
+
+UPDATE graph_nodes n
+SET last_seen_at = GREATEST(
+    n.created_at,
+    (SELECT MAX(e.created_at) FROM graph_edges e WHERE e.target_node_id = n.id),
+    (SELECT MAX(e.created_at) FROM graph_edges e WHERE e.source_node_id = n.id)
+);
+
+COMMENT ON COLUMN graph_nodes.last_seen_at IS 'Last time a built CV mentioned the node (created_at is the first)';
+
+-- People mentioning each skill per month, by when the HAS_SKILL edge was
+-- built (the CV's upload or reprocessing), unlike stats_skill_trend which
+-- counts people by when they first appeared. Refreshed with the other
+-- stats_* views.
+CREATE MATERIALIZED VIEW IF NOT EXISTS stats_skill_mentions AS
+SELECT e.org_id,
+       date_trunc('month', e.created_at)::date AS month,
+       s.properties->>'name' AS skill,
+       COUNT(DISTINCT e.source_node_id)::int AS mentions
+FROM graph_edges e
+JOIN graph_nodes p ON p.id = e.source_node_id AND p.node_type = 'person' AND p.deleted_at IS NULL
+JOIN graph_nodes s ON s.id = e.target_node_id AND s.node_type = 'skill' AND s.deleted_at IS NULL
+WHERE e.edge_type = 'HAS_SKILL' AND e.created_at IS NOT NULL AND s.properties->>'name' IS NOT NULL
+GROUP BY 1, 2, 3;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stats_skill_mentions ON stats_skill_mentions(org_id, month, skill);
+
+-- +goose Down
+DROP MATERIALIZED VIEW IF EXISTS stats_skill_mentions;
+ALTER TABLE graph_nodes DROP COLUMN IF EXISTS last_seen_at;
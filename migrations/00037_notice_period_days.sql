@@ -0,0 +1,22 @@
+-- +goose Up
+-- Notice periods are kept in days (notice_period_days) next to the
+-- availability date CVs state (available_from, YYYY-MM-DD); person nodes
+-- built with notice_period_weeks are converted.
+UPDATE graph_nodes
+SET properties = (properties - 'notice_period_weeks')
+    || jsonb_build_object('notice_period_days', (properties->>'notice_period_weeks')::float * 7)
+WHERE node_type = 'person' AND jsonb_typeof(properties->'notice_period_weeks') = 'number';
+
+UPDATE graph_nodes
+SET properties = properties - 'notice_period_weeks'
+WHERE node_type = 'person' AND properties ? 'notice_period_weeks';
+
+-- +goose Down
+UPDATE graph_nodes
+SET properties = (properties - 'notice_period_days' - 'available_from')
+    || jsonb_build_object('notice_period_weeks', round(((properties->>'notice_period_days')::float / 7)::numeric, 1))
+WHERE node_type = 'person' AND jsonb_typeof(properties->'notice_period_days') = 'number';
+
+UPDATE graph_nodes
+SET properties = properties - 'notice_period_days' - 'available_from'
+WHERE node_type = 'person' AND (properties ? 'notice_period_days' OR properties ? 'available_from');
@@ -0,0 +1,50 @@
+-- +goose Up
+-- Candidate lifecycle status. Searches leave out hired, archived and
+-- do-not-contact candidates unless asked for them; the status is mirrored
+-- on the candidate's person node (properties->>'status') for the graph and
+-- vector searches. Every change is kept in candidate_status_history.
+ALTER TABLE candidates ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'active'
+    CHECK (status IN ('active', 'hired', 'archived', 'do_not_contact'));
+ALTER TABLE candidates ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_candidates_org_status ON candidates(org_id, status) WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS candidate_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    org_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    candidate_id INTEGER NOT NULL REFERENCES candidates(id) ON DELETE CASCADE,
+    from_status TEXT NOT NULL,
+    to_status TEXT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    changed_by TEXT NOT NULL DEFAULT '', -- API actor ("user:<X-User-ID>", "key:<hash>")
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_candidate_status_history_candidate ON candidate_status_history(candidate_id, changed_at DESC);
+
+COMMENT ON COLUMN candidates.status IS 'Lifecycle status: active | hired | archived | do_not_contact; only active candidates are searched by default';
+COMMENT ON TABLE candidate_status_history IS 'Every candidate status change, with who made it and why';
+
+-- Candidates tagged do-not-contact were kept out of searches by hand
+-- (exclude_tags); the status does it by default.
+WITH tagged AS (
+    UPDATE candidates c SET status = 'do_not_contact', status_changed_at = NOW()
+    WHERE c.status = 'active' AND c.deleted_at IS NULL
+      AND EXISTS (SELECT 1 FROM candidate_tags t WHERE t.candidate_id = c.id AND t.tag = 'do-not-contact')
+    RETURNING c.id, c.org_id
+)
+INSERT INTO candidate_status_history (org_id, candidate_id, from_status, to_status, reason, changed_by)
+SELECT org_id, id, 'active', 'do_not_contact', 'do-not-contact tag', 'migration' FROM tagged;
+
+UPDATE graph_nodes n SET properties = n.properties || jsonb_build_object('status', c.status)
+FROM candidates c
+WHERE c.graph_node_id = n.id AND c.status <> 'active' AND n.node_type = 'person';
+
+-- +goose Down
+UPDATE graph_nodes SET properties = properties - 'status'
+WHERE node_type = 'person' AND properties ? 'status';
+
+DROP TABLE IF EXISTS candidate_status_history;
+DROP INDEX IF EXISTS idx_candidates_org_status;
+ALTER TABLE candidates DROP COLUMN IF EXISTS status_changed_at;
+ALTER TABLE candidates DROP COLUMN IF EXISTS status;
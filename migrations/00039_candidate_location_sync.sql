@@ -0,0 +1,15 @@
+-- +goose Up
+-- Candidates' location is part of their BM25 search_vector but was only set
+-- by imports and merges; SyncCandidateTextFields now fills it from the
+-- person node's resolved location. Fill it for existing candidates too (the
+-- update fires the search_vector trigger).
+UPDATE candidates c SET location = gn.properties->>'location'
+FROM graph_nodes gn
+WHERE gn.id = c.graph_node_id
+  AND COALESCE(c.location, '') = ''
+  AND COALESCE(gn.properties->>'location', '') <> ''
+  AND c.deleted_at IS NULL;
+
+-- +goose Down
+-- Not reversible: locations filled here can't be told apart from ones set
+-- otherwise, so they stay.
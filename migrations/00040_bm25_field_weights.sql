@@ -0,0 +1,53 @@
+-- +goose Up
+-- =====================================================
+-- One weight label per BM25 field
+-- =====================================================
+-- Name and skills shared label A, so a search couldn't weigh them apart.
+-- Each field now has its own label: name A, skills B, experience C,
+-- location D. BM25 searches pass ts_rank one weight per label
+-- (graphrag.BM25FieldWeights); the defaults rank as before.
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION candidates_search_vector_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', tr_fold(NEW.name)), 'A') ||
+        setweight(to_tsvector('english', tr_fold(NEW.skills)), 'B') ||
+        setweight(to_tsvector('english', tr_fold(NEW.experience)), 'C') ||
+        setweight(to_tsvector('english', tr_fold(NEW.location)), 'D') ||
+        setweight(to_tsvector('simple_unaccent', tr_fold(NEW.name)), 'A') ||
+        setweight(to_tsvector('simple_unaccent', tr_fold(NEW.skills)), 'B') ||
+        setweight(to_tsvector('simple_unaccent', tr_fold(NEW.experience)), 'C') ||
+        setweight(to_tsvector('simple_unaccent', tr_fold(NEW.location)), 'D');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+UPDATE candidates SET name = name;
+
+COMMENT ON COLUMN candidates.search_vector IS 'tsvector for full-text search (BM25-style): name A, skills B, experience C, location D';
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION candidates_search_vector_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', tr_fold(NEW.name)), 'A') ||
+        setweight(to_tsvector('english', tr_fold(NEW.skills)), 'A') ||
+        setweight(to_tsvector('english', tr_fold(NEW.experience)), 'B') ||
+        setweight(to_tsvector('english', tr_fold(NEW.location)), 'C') ||
+        setweight(to_tsvector('simple_unaccent', tr_fold(NEW.name)), 'A') ||
+        setweight(to_tsvector('simple_unaccent', tr_fold(NEW.skills)), 'A') ||
+        setweight(to_tsvector('simple_unaccent', tr_fold(NEW.experience)), 'B') ||
+        setweight(to_tsvector('simple_unaccent', tr_fold(NEW.location)), 'C');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+UPDATE candidates SET name = name;
+
+COMMENT ON COLUMN candidates.search_vector IS 'tsvector for full-text search (BM25-style)';
@@ -0,0 +1,87 @@
+-- +goose Up
+-- =====================================================
+-- Skill and education aliases for graph search
+-- =====================================================
+-- The graph source matched a skill by its exact node key ('skill_Go'), so
+-- "Golang" never found a CV that says "Go", and an education by the exact
+-- institution. Both now match by name key: graph_alias_key() (tr_fold,
+-- without spaces, dots, dashes and underscores; mirrors graphrag.aliasKey),
+-- so "Node.js", "NodeJS" and "node js" are the same, and through
+-- graph_aliases, which maps other keys of a name to it: golang → Go,
+-- ODTÜ → Middle East Technical University. A search term matches every key
+-- of its name.
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION graph_alias_key(t TEXT)
+RETURNS TEXT AS $$
+    SELECT regexp_replace(tr_fold(t), '[[:space:]._-]+', '', 'g')
+$$ LANGUAGE SQL IMMUTABLE PARALLEL SAFE;
+-- +goose StatementEnd
+
+CREATE TABLE IF NOT EXISTS graph_aliases (
+    node_type TEXT NOT NULL,  -- skill | education
+    alias     TEXT NOT NULL,  -- graph_alias_key() of the alias
+    name      TEXT NOT NULL,  -- the name it stands for
+    PRIMARY KEY (node_type, alias)
+);
+
+CREATE INDEX IF NOT EXISTS idx_graph_aliases_name ON graph_aliases(node_type, graph_alias_key(name));
+
+-- The graph query finds skill and education nodes by name key
+CREATE INDEX IF NOT EXISTS idx_graph_nodes_skill_alias_key
+    ON graph_nodes(graph_alias_key(properties->>'name'))
+    WHERE node_type = 'skill';
+CREATE INDEX IF NOT EXISTS idx_graph_nodes_education_alias_key
+    ON graph_nodes(graph_alias_key(properties->>'institution'))
+    WHERE node_type = 'education';
+
+INSERT INTO graph_aliases (node_type, alias, name) VALUES
+    ('skill', 'golang', 'Go'),
+    ('skill', 'k8s', 'Kubernetes'),
+    ('skill', 'js', 'JavaScript'),
+    ('skill', 'ecmascript', 'JavaScript'),
+    ('skill', 'ts', 'TypeScript'),
+    ('skill', 'reactjs', 'React'),
+    ('skill', 'node', 'Node.js'),
+    ('skill', 'vue', 'Vue.js'),
+    ('skill', 'angularjs', 'Angular'),
+    ('skill', 'postgres', 'PostgreSQL'),
+    ('skill', 'psql', 'PostgreSQL'),
+    ('skill', 'mongo', 'MongoDB'),
+    ('skill', 'mssql', 'SQL Server'),
+    ('skill', 'microsoftsqlserver', 'SQL Server'),
+    ('skill', 'elastic', 'Elasticsearch'),
+    ('skill', 'csharp', 'C#'),
+    ('skill', 'cpp', 'C++'),
+    ('skill', 'objc', 'Objective-C'),
+    ('skill', 'dotnet', '.NET'),
+    ('skill', 'netcore', '.NET'),
+    ('skill', 'python3', 'Python'),
+    ('skill', 'amazonwebservices', 'AWS'),
+    ('skill', 'gcp', 'Google Cloud'),
+    ('skill', 'googlecloudplatform', 'Google Cloud'),
+    ('skill', 'azure', 'Microsoft Azure'),
+    ('skill', 'ml', 'Machine Learning'),
+    ('skill', 'makineogrenmesi', 'Machine Learning'),
+    ('skill', 'cicd', 'CI/CD'),
+    ('education', 'metu', 'Middle East Technical University'),
+    ('education', 'odtu', 'Middle East Technical University'),
+    ('education', 'ortadoguteknikuniversitesi', 'Middle East Technical University'),
+    ('education', 'itu', 'Istanbul Technical University'),
+    ('education', 'istanbulteknikuniversitesi', 'Istanbul Technical University'),
+    ('education', 'boun', 'Bogazici University'),
+    ('education', 'bogaziciuniversitesi', 'Bogazici University'),
+    ('education', 'bilkentuniversitesi', 'Bilkent University'),
+    ('education', 'hacettepeuniversitesi', 'Hacettepe University'),
+    ('education', 'ytu', 'Yildiz Technical University'),
+    ('education', 'yildizteknikuniversitesi', 'Yildiz Technical University')
+ON CONFLICT (node_type, alias) DO NOTHING;
+
+COMMENT ON TABLE graph_aliases IS 'Other name keys of skills and education institutions, for graph search (graphrag aliases.go)';
+COMMENT ON FUNCTION graph_alias_key(TEXT) IS 'Name key for graph search: tr_fold without spaces, dots, dashes, underscores (mirrors graphrag.aliasKey)';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_graph_nodes_education_alias_key;
+DROP INDEX IF EXISTS idx_graph_nodes_skill_alias_key;
+DROP TABLE IF EXISTS graph_aliases;
+DROP FUNCTION IF EXISTS graph_alias_key(TEXT);
@@ -0,0 +1,10 @@
+// Package migrations embeds the numbered goose SQL migrations so the binary
+// (and every tool) can bring a database up to date without the source tree.
+package migrations
+
+import "embed"
+
+// FS holds every *.sql migration in this directory, applied in version order.
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,246 @@
+// gRPC API for internal services: the core CV upload, job status, hybrid
+// search and candidate operations of the HTTP API without the JSON and
+// multipart overhead. Regenerate with `go generate ./pkg/cvsearchpb`.
+//
+// Calls carry the organization API key as `x-api-key` (or `authorization:
+// Bearer <key>`) metadata and, optionally, `x-user-id` for the audit log,
+// like the HTTP headers of the same names.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.25.3
+// source: cvsearch.proto
+
+package cvsearchpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	CVSearch_UploadCV_FullMethodName     = "/cvsearch.v1.CVSearch/UploadCV"
+	CVSearch_GetJob_FullMethodName       = "/cvsearch.v1.CVSearch/GetJob"
+	CVSearch_HybridSearch_FullMethodName = "/cvsearch.v1.CVSearch/HybridSearch"
+	CVSearch_GetCandidate_FullMethodName = "/cvsearch.v1.CVSearch/GetCandidate"
+)
+
+// CVSearchClient is the client API for CVSearch service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CVSearchClient interface {
+	// UploadCV stores and parses a CV and queues it for processing, like
+	// POST /api/cv/upload. A CV whose text was already uploaded returns the
+	// existing file with duplicate set and no job. Fails with RESOURCE_EXHAUSTED
+	// while the processing queue is past CV_QUEUE_REJECT_PERCENT.
+	UploadCV(ctx context.Context, in *UploadCVRequest, opts ...grpc.CallOption) (*UploadCVResponse, error)
+	// GetJob returns a processing job's status, like GET /api/cv/job/{id}.
+	GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*Job, error)
+	// HybridSearch runs BM25 + vector + graph retrieval with LLM reranking,
+	// like POST /api/search/hybrid.
+	HybridSearch(ctx context.Context, in *HybridSearchRequest, opts ...grpc.CallOption) (*HybridSearchResponse, error)
+	// GetCandidate returns a candidate with skills, interviews and tags, like
+	// GET /api/candidates/{id}.
+	GetCandidate(ctx context.Context, in *GetCandidateRequest, opts ...grpc.CallOption) (*Candidate, error)
+}
+
+type cVSearchClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCVSearchClient(cc grpc.ClientConnInterface) CVSearchClient {
+	return &cVSearchClient{cc}
+}
+
+func (c *cVSearchClient) UploadCV(ctx context.Context, in *UploadCVRequest, opts ...grpc.CallOption) (*UploadCVResponse, error) {
+	out := new(UploadCVResponse)
+	err := c.cc.Invoke(ctx, CVSearch_UploadCV_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cVSearchClient) GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*Job, error) {
+	out := new(Job)
+	err := c.cc.Invoke(ctx, CVSearch_GetJob_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cVSearchClient) HybridSearch(ctx context.Context, in *HybridSearchRequest, opts ...grpc.CallOption) (*HybridSearchResponse, error) {
+	out := new(HybridSearchResponse)
+	err := c.cc.Invoke(ctx, CVSearch_HybridSearch_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cVSearchClient) GetCandidate(ctx context.Context, in *GetCandidateRequest, opts ...grpc.CallOption) (*Candidate, error) {
+	out := new(Candidate)
+	err := c.cc.Invoke(ctx, CVSearch_GetCandidate_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CVSearchServer is the server API for CVSearch service.
+// All implementations must embed UnimplementedCVSearchServer
+// for forward compatibility
+type CVSearchServer interface {
+	// UploadCV stores and parses a CV and queues it for processing, like
+	// POST /api/cv/upload. A CV whose text was already uploaded returns the
+	// existing file with duplicate set and no job. Fails with RESOURCE_EXHAUSTED
+	// while the processing queue is past CV_QUEUE_REJECT_PERCENT.
+	UploadCV(context.Context, *UploadCVRequest) (*UploadCVResponse, error)
+	// GetJob returns a processing job's status, like GET /api/cv/job/{id}.
+	GetJob(context.Context, *GetJobRequest) (*Job, error)
+	// HybridSearch runs BM25 + vector + graph retrieval with LLM reranking,
+	// like POST /api/search/hybrid.
+	HybridSearch(context.Context, *HybridSearchRequest) (*HybridSearchResponse, error)
+	// GetCandidate returns a candidate with skills, interviews and tags, like
+	// GET /api/candidates/{id}.
+	GetCandidate(context.Context, *GetCandidateRequest) (*Candidate, error)
+	mustEmbedUnimplementedCVSearchServer()
+}
+
+// UnimplementedCVSearchServer must be embedded to have forward compatible implementations.
+type UnimplementedCVSearchServer struct {
+}
+
+func (UnimplementedCVSearchServer) UploadCV(context.Context, *UploadCVRequest) (*UploadCVResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UploadCV not implemented")
+}
+func (UnimplementedCVSearchServer) GetJob(context.Context, *GetJobRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetJob not implemented")
+}
+func (UnimplementedCVSearchServer) HybridSearch(context.Context, *HybridSearchRequest) (*HybridSearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HybridSearch not implemented")
+}
+func (UnimplementedCVSearchServer) GetCandidate(context.Context, *GetCandidateRequest) (*Candidate, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCandidate not implemented")
+}
+func (UnimplementedCVSearchServer) mustEmbedUnimplementedCVSearchServer() {}
+
+// UnsafeCVSearchServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CVSearchServer will
+// result in compilation errors.
+type UnsafeCVSearchServer interface {
+	mustEmbedUnimplementedCVSearchServer()
+}
+
+func RegisterCVSearchServer(s grpc.ServiceRegistrar, srv CVSearchServer) {
+	s.RegisterService(&CVSearch_ServiceDesc, srv)
+}
+
+func _CVSearch_UploadCV_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UploadCVRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CVSearchServer).UploadCV(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CVSearch_UploadCV_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CVSearchServer).UploadCV(ctx, req.(*UploadCVRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CVSearch_GetJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CVSearchServer).GetJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CVSearch_GetJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CVSearchServer).GetJob(ctx, req.(*GetJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CVSearch_HybridSearch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HybridSearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CVSearchServer).HybridSearch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CVSearch_HybridSearch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CVSearchServer).HybridSearch(ctx, req.(*HybridSearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CVSearch_GetCandidate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCandidateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CVSearchServer).GetCandidate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CVSearch_GetCandidate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CVSearchServer).GetCandidate(ctx, req.(*GetCandidateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CVSearch_ServiceDesc is the grpc.ServiceDesc for CVSearch service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CVSearch_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "cvsearch.v1.CVSearch",
+	HandlerType: (*CVSearchServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "UploadCV",
+			Handler:    _CVSearch_UploadCV_Handler,
+		},
+		{
+			MethodName: "GetJob",
+			Handler:    _CVSearch_GetJob_Handler,
+		},
+		{
+			MethodName: "HybridSearch",
+			Handler:    _CVSearch_HybridSearch_Handler,
+		},
+		{
+			MethodName: "GetCandidate",
+			Handler:    _CVSearch_GetCandidate_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "cvsearch.proto",
+}
@@ -0,0 +1,12 @@
+// Package cvsearchpb holds the protobuf messages and the generated gRPC
+// client and server for the cv-search gRPC API (see cvsearch.proto).
+//
+// A client in another service:
+//
+//	conn, err := grpc.Dial("cv-search:9090", grpc.WithTransportCredentials(insecure.NewCredentials()))
+//	client := cvsearchpb.NewCVSearchClient(conn)
+//	ctx = metadata.AppendToOutgoingContext(ctx, "x-api-key", orgKey)
+//	res, err := client.HybridSearch(ctx, &cvsearchpb.HybridSearchRequest{Query: "senior go developer"})
+package cvsearchpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative cvsearch.proto
@@ -1,6 +1,9 @@
 package http
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"time"
@@ -25,3 +28,74 @@ func (c *Client) Get(url string) (*http.Response, error) {
 func (c *Client) Post(url string, contentType string, body io.Reader) (*http.Response, error) {
 	return c.httpClient.Post(url, contentType, body)
 }
+
+// ErrTooLarge is returned by Download when the body exceeds maxBytes.
+var ErrTooLarge = errors.New("response body too large")
+
+// StatusError is a non-200 response to Download.
+type StatusError struct {
+	Code int
+}
+
+func (e *StatusError) Error() string { return fmt.Sprintf("HTTP %d", e.Code) }
+
+// Temporary reports whether retrying might succeed (429 and 5xx).
+func (e *StatusError) Temporary() bool {
+	return e.Code == http.StatusTooManyRequests || e.Code >= 500
+}
+
+// Download is a fetched response: its headers and body.
+type Download struct {
+	Header http.Header
+	Body   []byte
+}
+
+// Download GETs url and reads at most maxBytes of the body. Network errors,
+// 429 and 5xx responses are retried up to retries more times, waiting 1s,
+// 2s, 4s, ... in between; other failures return at once.
+func (c *Client) Download(ctx context.Context, url string, maxBytes int64, retries int) (*Download, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	backoff := time.Second
+	for attempt := 0; ; attempt++ {
+		d, err := c.download(req, maxBytes)
+		if err == nil {
+			return d, nil
+		}
+		var se *StatusError
+		retryable := !errors.Is(err, ErrTooLarge) && (!errors.As(err, &se) || se.Temporary())
+		if !retryable || attempt >= retries || ctx.Err() != nil {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (c *Client) download(req *http.Request, maxBytes int64) (*Download, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{Code: resp.StatusCode}
+	}
+	if resp.ContentLength > maxBytes {
+		return nil, ErrTooLarge
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > maxBytes {
+		return nil, ErrTooLarge
+	}
+	return &Download{Header: resp.Header, Body: body}, nil
+}
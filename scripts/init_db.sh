@@ -24,15 +24,13 @@ echo "================================================"
 
 # Get script directory
 SCRIPT_DIR="$( cd "$( dirname "${BASH_SOURCE[0]}" )" && pwd )"
-MIGRATION_FILE="$SCRIPT_DIR/../migrations/complete_setup.sql"
 
-if [ ! -f "$MIGRATION_FILE" ]; then
-    echo "❌ Migration file not found: $MIGRATION_FILE"
-    exit 1
-fi
+# Migrations are embedded in the Go binaries; the migrate tool records applied
+# versions in goose_db_version so the API server's AUTO_MIGRATE skips them.
+export DATABASE_URL="${DATABASE_URL:-postgres://localhost:5432/$DB_NAME?sslmode=disable}"
 
-echo "⚙️  Running: complete_setup.sql"
-psql "$DB_NAME" -f "$MIGRATION_FILE"
+echo "⚙️  Running: go run ./cmd/tools/migrate"
+(cd "$SCRIPT_DIR/.." && go run ./cmd/tools/migrate/ -action up)
 if [ $? -eq 0 ]; then
     echo "✅ Migration completed successfully!"
 else